@@ -38,8 +38,28 @@ const (
 	ArgApp = "app"
 	// ArgAppWithProjects will determine whether project ids should be fetched along with listed apps.
 	ArgAppWithProjects = "with-projects"
+	// ArgAppPreviewBranch is the git branch a preview app is deployed from.
+	ArgAppPreviewBranch = "branch"
+	// ArgAppPreviewTTL is how long a preview app is kept before cleanup deletes it.
+	ArgAppPreviewTTL = "ttl"
 	// ArgAppSpec is a path to an app spec.
 	ArgAppSpec = "spec"
+	// ArgAppSpecVar is a NAME=VALUE variable interpolated into an app spec.
+	ArgAppSpecVar = "var"
+	// ArgAppSpecVarFile is a values file of variables interpolated into an app spec.
+	ArgAppSpecVarFile = "var-file"
+	// ArgAppSpecVarEnv interpolates environment variables into an app spec.
+	ArgAppSpecVarEnv = "var-env"
+	// ArgAppSpecLintStrict fails app spec linting on warnings as well as errors.
+	ArgAppSpecLintStrict = "strict"
+	// ArgAppSpecLintMaxInstances is the most instances a component may run before linting warns about it.
+	ArgAppSpecLintMaxInstances = "max-instances"
+	// ArgAppComponent is the name of a component of an app.
+	ArgAppComponent = "component"
+	// ArgAppInstances is the number of instances a component of an app runs.
+	ArgAppInstances = "instances"
+	// ArgAppInstanceSize is the slug of the instance size a component of an app runs on.
+	ArgAppInstanceSize = "size"
 	// ArgAppLogType the type of log.
 	ArgAppLogType = "type"
 	// ArgAppDeployment is the deployment ID.
@@ -48,6 +68,12 @@ const (
 	ArgAppDevConfig = "dev-config"
 	// ArgBuildCommand is an optional build command to set for local development.
 	ArgBuildCommand = "build-command"
+	// ArgServerlessURLPath is a path appended to the serverless URL injected into local builds.
+	ArgServerlessURLPath = "serverless-url-path"
+	// ArgServerlessURLTrailingSlash ensures the injected serverless URL ends with a slash.
+	ArgServerlessURLTrailingSlash = "serverless-url-trailing-slash"
+	// ArgNoServerlessURL leaves the serverless URL out of a component's local build.
+	ArgNoServerlessURL = "no-serverless-url"
 	// ArgBuildpack is a buildpack id.
 	ArgBuildpack = "buildpack"
 	// ArgAppLogFollow follow logs.
@@ -84,6 +110,12 @@ const (
 	ArgNodePoolMaxNodes = "max-nodes"
 	// ArgNodePoolNodeIDs is a cluster's node pool nodes argument.
 	ArgNodePoolNodeIDs = "node-ids"
+	// ArgNodePoolRolling recycles a node pool's nodes one at a time, draining each first.
+	ArgNodePoolRolling = "rolling"
+	// ArgNodePoolDrainTimeout is how long to wait for a node to drain.
+	ArgNodePoolDrainTimeout = "drain-timeout"
+	// ArgNodePoolAutoScaleDisable turns off autoscaling for a node pool.
+	ArgNodePoolAutoScaleDisable = "disable"
 	// ArgMaintenanceWindow is a cluster's maintenance window argument
 	ArgMaintenanceWindow = "maintenance-window"
 	// ArgMajorVersion is a major version number.
@@ -98,6 +130,8 @@ const (
 	ArgCommandUpsert = "upsert"
 	// ArgCommandWait is a wait for a resource to be created argument.
 	ArgCommandWait = "wait"
+	// ArgIdempotencyKey is a key that makes retrying a create command safe.
+	ArgIdempotencyKey = "idempotency-key"
 	// ArgSetCurrentContext is a flag to set the new kubeconfig context as current.
 	ArgSetCurrentContext = "set-current-context"
 	// ArgDropletID is a droplet id argument.
@@ -122,10 +156,78 @@ const (
 	ArgImagePublic = "public"
 	// ArgImageSlug is an image slug argument.
 	ArgImageSlug = "image-slug"
+	// ArgCleanupDelete prompts to delete each orphaned resource that is found.
+	ArgCleanupDelete = "delete"
+	// ArgCleanupSnapshotAge is the age after which snapshots are reported as orphaned.
+	ArgCleanupSnapshotAge = "snapshot-age"
+	// ArgYes answers yes to every confirmation prompt.
+	ArgYes = "yes"
+	// ArgEventType filters watched events by action type.
+	ArgEventType = "type"
+	// ArgEventResourceType filters watched events by resource type.
+	ArgEventResourceType = "resource-type"
+	// ArgEventStatus filters watched events by action status.
+	ArgEventStatus = "status"
+	// ArgEventExec is a shell command to run for each watched event.
+	ArgEventExec = "exec"
+	// ArgEventWebhook is a URL to POST each watched event to.
+	ArgEventWebhook = "webhook"
+	// ArgEventStateFile is the file that tracks which events have been delivered.
+	ArgEventStateFile = "state-file"
+	// ArgServerlessDomainCertificate requests a Let's Encrypt certificate for a serverless custom domain.
+	ArgServerlessDomainCertificate = "certificate"
+	// ArgServerlessDomainVerify is a web function to invoke through a serverless custom domain to verify it.
+	ArgServerlessDomainVerify = "verify-function"
+	// ArgServerlessDisableTriggers imports the triggers of a functions namespace disabled.
+	ArgServerlessDisableTriggers = "disable-triggers"
+	// ArgServerlessFromArtifact is a tarball written by `doctl serverless pack` to deploy.
+	ArgServerlessFromArtifact = "from-artifact"
+	// ArgServerlessSignKey is a private key to sign a packed serverless artifact with.
+	ArgServerlessSignKey = "sign-key"
+	// ArgServerlessBuilder identifies what packed a serverless artifact in its provenance.
+	ArgServerlessBuilder = "builder"
+	// ArgServerlessVerifyKey is a public key to verify the signature of a serverless artifact with before deploying it.
+	ArgServerlessVerifyKey = "verify-key"
+	// ArgServerlessArtifact is a serverless artifact to verify a deployed function against.
+	ArgServerlessArtifact = "artifact"
+	// ArgServerlessKey is the public key a serverless artifact was signed with.
+	ArgServerlessKey = "key"
+	// ArgServerlessSignature is the signature of a serverless artifact.
+	ArgServerlessSignature = "signature"
+	// ArgServerlessPluginTimeout is how long a serverless plugin invocation may run.
+	ArgServerlessPluginTimeout = "plugin-timeout"
+	// ArgServerlessPromoteFrom is the functions namespace to promote functions from.
+	ArgServerlessPromoteFrom = "from"
+	// ArgServerlessPromoteTo is the functions namespace to promote functions to.
+	ArgServerlessPromoteTo = "to"
+	// ArgServerlessPromoteOnly limits a promotion to some packages or functions.
+	ArgServerlessPromoteOnly = "only"
+	// ArgServerlessKeepWarmInterval is how often a function is invoked to keep it warm.
+	ArgServerlessKeepWarmInterval = "interval"
+	// ArgServerlessSBOMFormat is the standard an SBOM is rendered in.
+	ArgServerlessSBOMFormat = "sbom-format"
 	// ArgInteractive is the argument to enable an interactive CLI.
 	ArgInteractive = "interactive"
 	// ArgIPAddress is an IP address argument.
 	ArgIPAddress = "ip-address"
+	// ArgDomainBootstrap is the Droplet or load balancer a new domain's apex records point to.
+	ArgDomainBootstrap = "bootstrap"
+	// ArgDomainWithWWW creates a www CNAME record for a new domain.
+	ArgDomainWithWWW = "with-www"
+	// ArgDomainWithMX is the mail provider whose MX records are created for a new domain.
+	ArgDomainWithMX = "with-mx"
+	// ArgEmailAuthProvider is the mail provider email authentication records are set up for.
+	ArgEmailAuthProvider = "provider"
+	// ArgEmailAuthDKIMKey is the path of the DKIM public key to publish.
+	ArgEmailAuthDKIMKey = "dkim-key"
+	// ArgEmailAuthDKIMSelector is the DKIM selector of a domain's key.
+	ArgEmailAuthDKIMSelector = "dkim-selector"
+	// ArgEmailAuthDMARCPolicy is the DMARC policy for mail that fails authentication.
+	ArgEmailAuthDMARCPolicy = "dmarc-policy"
+	// ArgEmailAuthDMARCReports is the address DMARC aggregate reports are sent to.
+	ArgEmailAuthDMARCReports = "dmarc-reports"
+	// ArgEmailAuthReplace replaces conflicting email authentication records.
+	ArgEmailAuthReplace = "replace"
 	// ArgDropletName is a droplet name argument.
 	ArgDropletName = "droplet-name"
 	// ArgEnvFile is an environment file to load variables from.
@@ -148,6 +250,40 @@ const (
 	ArgMonitoring = "enable-monitoring"
 	// ArgDropletAgent is an argument for enabling/disabling the Droplet agent.
 	ArgDropletAgent = "droplet-agent"
+	// ArgDropletDNS is the domain to create DNS records for new Droplets in.
+	ArgDropletDNS = "dns"
+	// ArgDropletDrainTimeout is how long to wait for connections to drain before decommissioning a Droplet.
+	ArgDropletDrainTimeout = "drain-timeout"
+	// ArgDropletSnapshot requests a snapshot of a Droplet before it is destroyed.
+	ArgDropletSnapshot = "snapshot"
+	// ArgDropletRolling reboots Droplets in batches rather than all at once.
+	ArgDropletRolling = "rolling"
+	// ArgDropletBatch is the number of Droplets rebooted at a time with --rolling.
+	ArgDropletBatch = "batch"
+	// ArgDropletWaitHealthy is the load balancer whose health check rebooted Droplets must pass.
+	ArgDropletWaitHealthy = "wait-healthy"
+	// ArgDropletRegions is a list of regions to create identical Droplets in.
+	ArgDropletRegions = "regions"
+	// ArgFromSnapshot is the name or ID of a Droplet snapshot to create Droplets from.
+	ArgFromSnapshot = "from-snapshot"
+	// ArgVerifySSH checks that new Droplets answer on SSH.
+	ArgVerifySSH = "verify-ssh"
+	// ArgVerifyHTTP is a port and path new Droplets must answer HTTP requests on.
+	ArgVerifyHTTP = "verify-http"
+	// ArgVerifyTimeout is how long to wait for new Droplets to pass their checks.
+	ArgVerifyTimeout = "verify-timeout"
+	// ArgDestroyOnFailure deletes new Droplets that fail their checks.
+	ArgDestroyOnFailure = "destroy-on-failure"
+	// ArgDropletDNSCleanup removes the DNS records of deleted Droplets.
+	ArgDropletDNSCleanup = "dns-cleanup"
+	// ArgPTRReportAll includes addresses whose reverse DNS is correct in the PTR report.
+	ArgPTRReportAll = "all"
+	// ArgIPv6AuditDomain limits the IPv6 audit to a domain.
+	ArgIPv6AuditDomain = "domain"
+	// ArgIPv6AuditCreateMissing creates the AAAA records the IPv6 audit finds missing.
+	ArgIPv6AuditCreateMissing = "create-missing"
+	// ArgIPv6AuditAll includes Droplets whose AAAA records are correct in the IPv6 audit.
+	ArgIPv6AuditAll = "all"
 	// ArgRecordData is a record data argument.
 	ArgRecordData = "record-data"
 	// ArgRecordID is a record id argument.
@@ -168,8 +304,64 @@ const (
 	ArgRecordFlags = "record-flags"
 	// ArgRecordTag is a record tag argument.
 	ArgRecordTag = "record-tag"
+	// ArgRecordSetWeight is the relative weight of a record set member.
+	ArgRecordSetWeight = "weight"
+	// ArgRecordTemplate is the path of a DNS record template.
+	ArgRecordTemplate = "template"
+	// ArgRecordTypes limits a bulk record command to records of these types.
+	ArgRecordTypes = "type"
+	// ArgRecordMatch limits a bulk record command to records whose names match a glob.
+	ArgRecordMatch = "match"
+	// ArgRecordNewTTL is the TTL to give records, in seconds.
+	ArgRecordNewTTL = "ttl"
+	// ArgRecordTTLAudit lists records with TTLs outside the recommended range instead of updating them.
+	ArgRecordTTLAudit = "audit"
+	// ArgRecordMinTTL is the lowest recommended TTL, in seconds.
+	ArgRecordMinTTL = "min-ttl"
+	// ArgRecordMaxTTL is the highest recommended TTL, in seconds.
+	ArgRecordMaxTTL = "max-ttl"
+	// ArgCAAIssuer is a certificate authority allowed to issue certificates for a domain.
+	ArgCAAIssuer = "issuer"
+	// ArgCAAWildcard also allows the issuers to issue wildcard certificates.
+	ArgCAAWildcard = "wildcard"
+	// ArgCAAIodef is the URL certificate authorities report refused requests to.
+	ArgCAAIodef = "iodef"
+	// ArgSRVService is the symbolic name of the service an SRV record is for.
+	ArgSRVService = "service"
+	// ArgSRVProto is the transport protocol of the service an SRV record is for.
+	ArgSRVProto = "proto"
+	// ArgSRVPriority is the priority of an SRV record's target.
+	ArgSRVPriority = "priority"
+	// ArgSRVWeight is the relative weight of an SRV record's target among targets with the same priority.
+	ArgSRVWeight = "weight"
+	// ArgSRVPort is the port the service listens on at an SRV record's target.
+	ArgSRVPort = "port"
+	// ArgSRVTarget is the host name of the server an SRV record points to.
+	ArgSRVTarget = "target"
+	// ArgRetargetFromIP is the address of the records to retarget.
+	ArgRetargetFromIP = "from-ip"
+	// ArgRetargetToIP is the address records are retargeted to.
+	ArgRetargetToIP = "to-ip"
+	// ArgRetargetDelete deletes the records instead of retargeting them.
+	ArgRetargetDelete = "delete"
+	// ArgRetargetDomains is the domains whose records are retargeted, or "all".
+	ArgRetargetDomains = "domains"
+	// ArgDomainImportAXFR is the nameserver a domain's zone is transferred from.
+	ArgDomainImportAXFR = "axfr"
+	// ArgDomainImportTSIGKey is the TSIG key, as [algorithm:]name:secret, that authenticates a zone transfer.
+	ArgDomainImportTSIGKey = "tsig-key"
+	// ArgDryRun shows the changes a command would make without making them.
+	ArgDryRun = "dry-run"
+	// ArgResume is the checkpoint file of an interrupted bulk operation to resume.
+	ArgResume = "resume"
+	// ArgNotify is where to send a notification when a long-running command ends.
+	ArgNotify = "notify"
 	// ArgRegionSlug is a region slug argument.
 	ArgRegionSlug = "region"
+	// ArgDefaultRegion is the config key for the region used by create commands when no region is given.
+	ArgDefaultRegion = "default-region"
+	// ArgRegionAliases is the config key for user-defined region aliases, such as `primary=nyc3`.
+	ArgRegionAliases = "region-aliases"
 	// ArgSchemaOnly is a schema only argument.
 	ArgSchemaOnly = "schema-only"
 	// ArgSizeSlug is a size slug argument.
@@ -190,6 +382,24 @@ const (
 	ArgSSHCommand = "ssh-command"
 	// ArgSSHRetryMax is a ssh argument.
 	ArgSSHRetryMax = "ssh-retry-max"
+	// ArgSSHStdin is a ssh option for the reader used as the command's input.
+	ArgSSHStdin = "ssh-stdin"
+	// ArgSSHStdout is a ssh option for the writer receiving the command's output.
+	ArgSSHStdout = "ssh-stdout"
+	// ArgSSHStderr is a ssh option for the writer receiving the command's error output.
+	ArgSSHStderr = "ssh-stderr"
+	// ArgSCPUpload is a scp option selecting the direction of the copy.
+	ArgSCPUpload = "scp-upload"
+	// ArgSCPLocalPaths is a scp option for the local files.
+	ArgSCPLocalPaths = "scp-local-paths"
+	// ArgSCPRemotePath is a scp option for the remote path.
+	ArgSCPRemotePath = "scp-remote-path"
+	// ArgSCPRecursive copies directories recursively.
+	ArgSCPRecursive = "recursive"
+	// ArgInterval is how often a command refreshes or polls for changes.
+	ArgInterval = "interval"
+	// ArgExecConcurrency is the number of Droplets a command runs on at the same time.
+	ArgExecConcurrency = "concurrency"
 	// ArgUserData is a user data argument.
 	ArgUserData = "user-data"
 	// ArgUserDataFile is a user data file location argument.
@@ -202,6 +412,16 @@ const (
 	ArgImageDistro = "image-distribution"
 	// ArgImageDescription is free text that describes the image.
 	ArgImageDescription = "image-description"
+	// ArgImageUploadDistribution is the distribution of an uploaded custom image.
+	ArgImageUploadDistribution = "distribution"
+	// ArgImageSpacesBucket is the Spaces bucket used to stage a local image file.
+	ArgImageSpacesBucket = "spaces-bucket"
+	// ArgImageTestBoot boots a throwaway Droplet from an uploaded image to verify it.
+	ArgImageTestBoot = "test-boot"
+	// ArgImagePruneOlderThan is the minimum age of the images to prune.
+	ArgImagePruneOlderThan = "older-than"
+	// ArgImageTestSize is the size of the Droplet used to test-boot an image.
+	ArgImageTestSize = "test-size"
 	// ArgKey is a key argument.
 	ArgKey = "key"
 	// ArgKeyName is a key name argument.
@@ -210,12 +430,24 @@ const (
 	ArgKeyPublicKey = "public-key"
 	// ArgKeyPublicKeyFile is a public key file argument.
 	ArgKeyPublicKeyFile = "public-key-file"
+	// ArgKeyPath is the path where a generated private key is written.
+	ArgKeyPath = "key-path"
+	// ArgKeyUpload uploads a generated public key to the account.
+	ArgKeyUpload = "upload"
+	// ArgKeyAddAgent adds a generated private key to the running ssh-agent.
+	ArgKeyAddAgent = "add-agent"
 	// ArgSSHUser is a SSH user argument.
 	ArgSSHUser = "ssh-user"
 	// ArgFormat is columns to include in output argument.
 	ArgFormat = "format"
 	// ArgNoHeader hides the output header.
 	ArgNoHeader = "no-header"
+	// ArgDiffSince is the path to earlier JSON output of a list command to compare against.
+	ArgDiffSince = "diff-since"
+	// ArgListLimit is the largest number of items a list command lists.
+	ArgListLimit = "limit"
+	// ArgListCursor is where a list command continues an earlier, limited listing.
+	ArgListCursor = "cursor"
 	// ArgPollTime is how long before the next poll argument.
 	ArgPollTime = "poll-timeout"
 	// ArgTagName is a tag name
@@ -238,6 +470,31 @@ const (
 	ArgVersion = "version"
 	// ArgVerbose enables verbose output
 	ArgVerbose = "verbose"
+	// ArgSummary enables the local API usage summary
+	ArgSummary = "summary"
+	// ArgConfigReadOnly prevents doctl from writing its config file.
+	ArgConfigReadOnly = "config-read-only"
+	// ArgReadOnly makes doctl refuse API calls that change anything.
+	ArgReadOnly = "read-only"
+	// ArgRecord is a directory to record API calls to.
+	ArgRecord = "record"
+	// ArgReplay is a directory of recorded API calls to replay instead of calling the API.
+	ArgReplay = "replay"
+	// ArgPolicyFile is the path of a policy file restricting which commands may run.
+	ArgPolicyFile = "policy-file"
+	// ArgNoConfig makes doctl ignore its config file.
+	ArgNoConfig = "no-config"
+	// ArgMessagesDir is the directory of the message catalogs and output templates that customize human output.
+	ArgMessagesDir = "messages-dir"
+	// ArgGitHubOutput writes results to $GITHUB_OUTPUT and errors as GitHub Actions annotations.
+	ArgGitHubOutput = "github-output"
+	// ArgHTTPMaxIdleConns is the number of idle API connections kept open for reuse.
+	ArgHTTPMaxIdleConns = "http-max-idle-conns"
+	// ArgHTTPMaxConnsPerHost is the number of connections opened to each API host.
+	ArgHTTPMaxConnsPerHost = "http-max-conns-per-host"
+
+	// ArgJQ is a jq expression used to filter JSON output.
+	ArgJQ = "jq"
 
 	// ArgOutput is an output type argument.
 	ArgOutput = "output"
@@ -259,6 +516,12 @@ const (
 	ArgUptimeAlertType = "type"
 	// ArgUptimeAlertThreshold the threshold at which an uptime alert will trigger.
 	ArgUptimeAlertThreshold = "threshold"
+	// ArgLimitThreshold is the usage percentage of an account limit above which `account limits` fails.
+	ArgLimitThreshold = "threshold"
+	// ArgSecurityHistorySince is how far back `account security-history` looks.
+	ArgSecurityHistorySince = "since"
+	// ArgSecurityHistoryAll includes every action in `account security-history`.
+	ArgSecurityHistoryAll = "all"
 	// ArgUptimeAlertComparison is the uptime alert comparator.
 	ArgUptimeAlertComparison = "comparison"
 	// ArgUptimeAlertEmails are the emails to send uptime alerts to.
@@ -286,6 +549,10 @@ const (
 	ArgVolumeList = "volumes"
 	// ArgVolumeSnapshotList is the IDs of many volume snapshots.
 	ArgVolumeSnapshotList = "snapshots"
+	// ArgSnapshotScheduleCron is the cron expression of a volume snapshot schedule.
+	ArgSnapshotScheduleCron = "cron"
+	// ArgSnapshotScheduleKeep is the number of scheduled snapshots to keep.
+	ArgSnapshotScheduleKeep = "keep"
 	// ArgLoadBalancerList is the IDs of many load balancers.
 	ArgLoadBalancerList = "load-balancers"
 
@@ -311,6 +578,16 @@ const (
 	// ArgCertificateType is a certificate type.
 	ArgCertificateType = "type"
 
+	// ArgLoadBalancerTemplate is the name of a template for a new load balancer.
+	ArgLoadBalancerTemplate = "template"
+	// ArgLoadBalancerCertificateID is the ID of the certificate used by a load balancer template.
+	ArgLoadBalancerCertificateID = "certificate-id"
+	// ArgLoadBalancerSpec is the path of a load balancer spec.
+	ArgLoadBalancerSpec = "spec"
+	// ArgLoadBalancerSaveSpec is the path to save a load balancer's spec to.
+	ArgLoadBalancerSaveSpec = "save-spec"
+	// ArgLoadBalancerWizard walks through creating a load balancer interactively.
+	ArgLoadBalancerWizard = "wizard"
 	// ArgLoadBalancerName is a name of the load balancer.
 	ArgLoadBalancerName = "name"
 	// ArgLoadBalancerAlgorithm is a load balancing algorithm.
@@ -327,6 +604,16 @@ const (
 	ArgStickySessions = "sticky-sessions"
 	// ArgHealthCheck is a list of health check settings for the load balancer.
 	ArgHealthCheck = "health-check"
+	// ArgLBCertificate is the certificate a load balancer should switch to.
+	ArgLBCertificate = "certificate"
+	// ArgLBOldCertificate is the certificate a load balancer should stop using.
+	ArgLBOldCertificate = "old-certificate"
+	// ArgLBRenewBefore is how long before expiry a load balancer certificate is replaced.
+	ArgLBRenewBefore = "renew-before"
+	// ArgLBDeleteOldCertificate deletes certificates after they are replaced.
+	ArgLBDeleteOldCertificate = "delete-old"
+	// ArgOnce runs a single pass of a command that otherwise keeps watching.
+	ArgOnce = "once"
 	// ArgForwardingRules is a list of forwarding rules for the load balancer.
 	ArgForwardingRules = "forwarding-rules"
 	// ArgHTTPIdleTimeoutSeconds is the http idle time out configuration for the load balancer
@@ -352,6 +639,16 @@ const (
 	ArgInboundRules = "inbound-rules"
 	// ArgOutboundRules is a list of outbound rules for the firewall.
 	ArgOutboundRules = "outbound-rules"
+	// ArgFirewall is the ID of a firewall.
+	ArgFirewall = "firewall"
+	// ArgFirewallSource is the source address of simulated inbound traffic.
+	ArgFirewallSource = "src"
+	// ArgFirewallDestination is the destination address of simulated outbound traffic.
+	ArgFirewallDestination = "dst"
+	// ArgFirewallPort is the port of simulated traffic.
+	ArgFirewallPort = "port"
+	// ArgFirewallProtocol is the protocol of simulated traffic.
+	ArgFirewallProtocol = "proto"
 
 	// ArgProjectID is the ID of a project.
 	ArgProjectID = "project-id"
@@ -386,6 +683,22 @@ const (
 	ArgDatabaseMaintenanceDay = "day"
 	// ArgDatabaseMaintenanceHour is the new hour for the maintenance window
 	ArgDatabaseMaintenanceHour = "hour"
+	// ArgDatabaseRedisEvictionPolicy is the policy used to evict keys from a Redis cluster
+	ArgDatabaseRedisEvictionPolicy = "eviction-policy"
+	// ArgDatabaseRedisPersistence is the persistence mode of a Redis cluster
+	ArgDatabaseRedisPersistence = "persistence"
+	// ArgDatabaseRedisTimeout is the idle time, in seconds, after which Redis closes client connections
+	ArgDatabaseRedisTimeout = "timeout"
+	// ArgOpenSearchIndexPattern is an index name or wildcard pattern of an OpenSearch cluster
+	ArgOpenSearchIndexPattern = "index-pattern"
+	// ArgOpenSearchRetention is how long OpenSearch indices are kept before they are deleted
+	ArgOpenSearchRetention = "retention"
+	// ArgOpenSearchPolicyID is the ID of an OpenSearch ISM policy
+	ArgOpenSearchPolicyID = "policy-id"
+	// ArgOpenSearchWatch keeps reporting OpenSearch cluster health until interrupted
+	ArgOpenSearchWatch = "watch"
+	// ArgDatabaseUpgradeAt is the time at which a database version upgrade should start
+	ArgDatabaseUpgradeAt = "at"
 	// ArgDatabasePoolUserName is the name of user for use with connection pool
 	ArgDatabasePoolUserName = "user"
 	// ArgDatabasePoolDBName is the database for use with connection pool
@@ -400,6 +713,8 @@ const (
 	ArgDatabasePrivateConnectionBool = "private"
 	// ArgDatabaseUserKafkaACLs will specify permissions on topics in kafka clsuter
 	ArgDatabaseUserKafkaACLs = "acl"
+	// ArgDatabaseUserResetPassword regenerates a database user's password
+	ArgDatabaseUserResetPassword = "reset-password"
 
 	// ArgDatabaseTopicReplicationFactor is the replication factor of a kafka topic
 	ArgDatabaseTopicReplicationFactor = "replication-factor"
@@ -478,6 +793,12 @@ const (
 	ArgRegistryReadOnly = "read-only"
 	// ArgRegistryNeverExpire indicates that a generated registry API token should never expire.
 	ArgRegistryNeverExpire = "never-expire"
+	// ArgRegistryApply applies a generated Kubernetes manifest to a cluster.
+	ArgRegistryApply = "apply"
+	// ArgRegistryCluster is the Kubernetes cluster a registry manifest is applied to.
+	ArgRegistryCluster = "cluster"
+	// ArgRegistryRefreshBefore is how long before they expire registry credentials in a cluster are refreshed.
+	ArgRegistryRefreshBefore = "refresh-before"
 	// ArgSubscriptionTier is a subscription tier slug.
 	ArgSubscriptionTier = "subscription-tier"
 	// ArgGCIncludeUntaggedManifests indicates that a garbage collection should delete
@@ -497,6 +818,10 @@ const (
 
 	// ArgOneClickType is the type of 1-Click
 	ArgOneClickType = "type"
+	// ArgOneClickCluster is the Kubernetes cluster to install 1-Click applications on.
+	ArgOneClickCluster = "cluster"
+	// ArgOneClickDroplet is the Droplet to install a 1-Click application on.
+	ArgOneClickDroplet = "droplet"
 
 	//ArgDangerous indicates whether to delete the cluster and all it's associated resources
 	ArgDangerous = "dangerous"
@@ -507,6 +832,13 @@ const (
 	// ArgDatabaseFirewallRuleUUID is the UUID for the firewall rules.
 	ArgDatabaseFirewallRuleUUID = "uuid"
 
+	// ArgDatabaseMetric is the database cluster metric to report.
+	ArgDatabaseMetric = "metric"
+	// ArgDatabaseSlowQueriesSince is how far back to look for slow queries.
+	ArgDatabaseSlowQueriesSince = "since"
+	// ArgDatabaseSlowQueriesTop is the number of slow queries to report.
+	ArgDatabaseSlowQueriesTop = "top"
+
 	// Monitoring Args
 
 	// ArgAlertPolicyDescription is the flag to pass in the alert policy description.
@@ -544,4 +876,60 @@ const (
 
 	// ArgTokenValidationServer is the server used to validate an OAuth token
 	ArgTokenValidationServer = "token-validation-server"
+	// ArgTokenName is the name of a personal access token.
+	ArgTokenName = "name"
+	// ArgTokenScopes is the list of scopes granted to a personal access token.
+	ArgTokenScopes = "scopes"
+	// ArgTokenExpiry is how long a personal access token remains valid.
+	ArgTokenExpiry = "expiry"
+	// ArgTokenKeepOld keeps the previous token valid after a rotation.
+	ArgTokenKeepOld = "keep-old"
+
+	// ArgTeamMemberRole is the role of a team member.
+	ArgTeamMemberRole = "role"
+
+	// ArgValidateFile is a path to a spec file to validate.
+	ArgValidateFile = "file"
+	// ArgValidateOffline restricts validation to local checks that need no API access.
+	ArgValidateOffline = "offline"
+	// ArgValidateType is the kind of spec being validated.
+	ArgValidateType = "type"
+	// ArgValidateCatalogs is the path of the cached catalogs that specs are validated against.
+	ArgValidateCatalogs = "catalogs"
+
+	// ArgSelfUpdateChannel is the release channel to update from.
+	ArgSelfUpdateChannel = "channel"
+	// ArgSelfUpdateCheck only reports whether an update is available.
+	ArgSelfUpdateCheck = "check"
+
+	// ArgServeListen is the address the RPC daemon listens on.
+	ArgServeListen = "listen"
+	// ArgServeRequireToken rejects RPC requests that do not carry their own access token.
+	ArgServeRequireToken = "require-token"
+	// ArgCacheProxyListen is the address the caching proxy listens on.
+	ArgCacheProxyListen = "listen"
+	// ArgCacheProxyUpstream is the API the caching proxy forwards requests to.
+	ArgCacheProxyUpstream = "upstream"
+	// ArgCacheProxyTTL is how long the caching proxy serves a response before revalidating it.
+	ArgCacheProxyTTL = "ttl"
+	// ArgCacheProxyMaxEntries is the number of responses the caching proxy keeps.
+	ArgCacheProxyMaxEntries = "max-entries"
+	// ArgCacheProxyReserve is the rate limit below which the caching proxy serves stale responses.
+	ArgCacheProxyReserve = "rate-limit-reserve"
+	// ArgCronJobName is the name of a scheduled job.
+	ArgCronJobName = "name"
+	// ArgCronScheduler is the operating system scheduler that runs a scheduled job.
+	ArgCronScheduler = "scheduler"
+	// ArgInventoryOutput is the file an inventory is written to.
+	ArgInventoryOutput = "output"
+	// ArgInventoryBaseline is the inventory that doctl inventory watch compares against.
+	ArgInventoryBaseline = "baseline"
+	// ArgInventoryAlert is a notifier that doctl inventory watch alerts of changes.
+	ArgInventoryAlert = "alert"
+	// ArgInventoryExitCode makes inventory commands exit with a non-zero status when resources changed.
+	ArgInventoryExitCode = "exit-code"
+	// ArgInventoryIgnoreType is a type of resource whose changes are ignored.
+	ArgInventoryIgnoreType = "ignore-type"
+	// ArgInventoryGraphFormat is the format doctl inventory graph writes graphs in.
+	ArgInventoryGraphFormat = "format"
 )
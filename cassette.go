@@ -0,0 +1,381 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package doctl
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// redacted replaces secrets in recorded interactions.
+const redacted = "REDACTED"
+
+// secretFields matches the names of fields whose values are redacted from
+// recorded interactions, such as the auth of a Docker config or the client key
+// of a kubeconfig.
+var secretFields = regexp.MustCompile(`(?i)(password|secret|token|private[-_]?key|access[-_]?key|api[-_]?key|client[-_]key|client[-_]certificate|credential|^auths?$)`)
+
+// secretEndpoints are the API paths whose bodies hold credentials in fields
+// not matched by secretFields. fields replaces secretFields for them. If it
+// is nil, every string in the body is a credential, and bodies that aren't
+// JSON are not recorded at all.
+var secretEndpoints = []struct {
+	path   *regexp.Regexp
+	fields *regexp.Regexp
+}{
+	{path: regexp.MustCompile(`^/v2/registry/docker-credentials`)},
+	{path: regexp.MustCompile(`^/v2/kubernetes/clusters/[^/]+/(kubeconfig|credentials)`)},
+	{path: regexp.MustCompile(`^/v2/functions/namespaces`), fields: regexp.MustCompile(secretFields.String() + `|^key$`)},
+}
+
+// secretLines matches "name: value" and "name=value" lines of bodies that
+// aren't JSON, such as YAML or env files.
+var secretLines = regexp.MustCompile(`(?m)^(\s*"?([\w.-]+)"?\s*[:=]\s*)(\S.*)$`)
+
+// Cassette is the recording of every call made to one API endpoint with the
+// same request. The responses are replayed in the order they were recorded.
+type Cassette struct {
+	Request   CassetteRequest    `json:"request"`
+	Responses []CassetteResponse `json:"responses"`
+}
+
+// CassetteRequest is a recorded API request.
+type CassetteRequest struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	JSON   json.RawMessage `json:"json,omitempty"`
+	Body   string          `json:"body,omitempty"`
+}
+
+// CassetteResponse is a recorded API response.
+type CassetteResponse struct {
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type,omitempty"`
+	JSON        json.RawMessage `json:"json,omitempty"`
+	Body        string          `json:"body,omitempty"`
+}
+
+// CassetteMissErr is returned in replay mode for API calls that were not
+// recorded.
+type CassetteMissErr struct {
+	Method string
+	URL    string
+	Dir    string
+}
+
+var _ error = &CassetteMissErr{}
+
+func (e *CassetteMissErr) Error() string {
+	return fmt.Sprintf("replay: no recorded response for %s %s in %s", e.Method, e.URL, e.Dir)
+}
+
+// cassetteTransport records API calls to, or replays them from, a directory
+// of cassettes. Secrets are redacted before anything is written, and requests
+// are matched on their redacted form, so replaying works without credentials.
+type cassetteTransport struct {
+	dir   string
+	token string
+	// wrap is the transport that records send requests to. It is nil when replaying.
+	wrap http.RoundTripper
+
+	mu sync.Mutex
+	// played counts the responses replayed from each cassette.
+	played map[string]int
+}
+
+func newRecordTransport(dir, token string, wrap http.RoundTripper) *cassetteTransport {
+	return &cassetteTransport{dir: dir, token: token, wrap: wrap}
+}
+
+func newReplayTransport(dir string) *cassetteTransport {
+	return &cassetteTransport{dir: dir, played: map[string]int{}}
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	creq, err := t.cassetteRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(t.dir, cassetteName(creq))
+
+	if t.wrap == nil {
+		return t.replay(req, creq, path)
+	}
+
+	resp, err := t.wrap.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	cresp := CassetteResponse{
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	cresp.JSON, cresp.Body = t.redact(req.URL.Path, body)
+
+	if err := t.record(path, creq, cresp); err != nil {
+		return nil, fmt.Errorf("record: %w", err)
+	}
+	return resp, nil
+}
+
+func (t *cassetteTransport) record(path string, creq CassetteRequest, cresp CassetteResponse) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, err := readCassette(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		c = &Cassette{Request: creq}
+	} else if err != nil {
+		return err
+	}
+	c.Responses = append(c.Responses, cresp)
+
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(t.dir, 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0600)
+}
+
+// replay returns the next recorded response to req. Once they have all been
+// replayed, the last one is repeated, so polling for a status ends.
+func (t *cassetteTransport) replay(req *http.Request, creq CassetteRequest, path string) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	c, err := readCassette(path)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && len(c.Responses) == 0) {
+		return nil, &CassetteMissErr{Method: creq.Method, URL: creq.URL, Dir: t.dir}
+	} else if err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+
+	t.mu.Lock()
+	i := t.played[path]
+	t.played[path]++
+	t.mu.Unlock()
+	if i >= len(c.Responses) {
+		i = len(c.Responses) - 1
+	}
+	cresp := c.Responses[i]
+
+	body := []byte(cresp.Body)
+	if len(cresp.JSON) > 0 {
+		body = cresp.JSON
+	}
+	header := http.Header{}
+	if cresp.ContentType != "" {
+		header.Set("Content-Type", cresp.ContentType)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", cresp.Status, http.StatusText(cresp.Status)),
+		StatusCode:    cresp.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// cassetteRequest returns the redacted form of req, leaving req's body
+// readable. The host is left out so cassettes work with any --api-url.
+func (t *cassetteTransport) cassetteRequest(req *http.Request) (CassetteRequest, error) {
+	creq := CassetteRequest{Method: req.Method, URL: req.URL.RequestURI()}
+	if req.Body == nil || req.Body == http.NoBody {
+		return creq, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return creq, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	creq.JSON, creq.Body = t.redact(req.URL.Path, body)
+	return creq, nil
+}
+
+// redact removes the access token and the values of secretFields from body,
+// including where they appear within other values, such as connection URIs.
+// Bodies of secretEndpoints lose their credentials too, and bodies that are
+// nothing but credentials are dropped if they aren't JSON. JSON bodies are
+// returned as JSON so cassettes are easy to read and edit.
+func (t *cassetteTransport) redact(path string, body []byte) (json.RawMessage, string) {
+	if t.token != "" {
+		body = bytes.ReplaceAll(body, []byte(t.token), []byte(redacted))
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, ""
+	}
+
+	fields, all := secretFields, false
+	for _, e := range secretEndpoints {
+		if e.path.MatchString(path) {
+			if e.fields == nil {
+				all = true
+			} else {
+				fields = e.fields
+			}
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		if all {
+			return nil, redacted
+		}
+		return nil, redactText(string(body), fields)
+	}
+	if all {
+		v = redactJSON(v, func(string) any { return redacted })
+		b, _ := json.Marshal(v)
+		return b, ""
+	}
+
+	var secrets []string
+	v = redactJSONFields(v, fields, func(s string) any {
+		secrets = append(secrets, s)
+		return redacted
+	})
+	if len(secrets) > 0 {
+		r := strings.NewReplacer(flatten(secrets, redacted)...)
+		v = redactJSON(v, func(s string) any { return r.Replace(s) })
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, redacted
+	}
+	return b, ""
+}
+
+// redactText redacts the values of the lines of body that name one of fields.
+func redactText(body string, fields *regexp.Regexp) string {
+	return secretLines.ReplaceAllStringFunc(body, func(line string) string {
+		m := secretLines.FindStringSubmatch(line)
+		if !fields.MatchString(m[2]) {
+			return line
+		}
+		return m[1] + redacted
+	})
+}
+
+// redactJSONFields calls f with the string values of fields in v and replaces
+// them with its result.
+func redactJSONFields(v any, fields *regexp.Regexp, f func(string) any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			if s, ok := val.(string); ok && s != "" && fields.MatchString(k) {
+				v[k] = f(s)
+			} else {
+				v[k] = redactJSONFields(val, fields, f)
+			}
+		}
+	case []any:
+		for i, val := range v {
+			v[i] = redactJSONFields(val, fields, f)
+		}
+	}
+	return v
+}
+
+// redactJSON calls f with every string value in v and replaces it with its
+// result.
+func redactJSON(v any, f func(string) any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			if s, ok := val.(string); ok {
+				v[k] = f(s)
+			} else {
+				v[k] = redactJSON(val, f)
+			}
+		}
+	case []any:
+		for i, val := range v {
+			if s, ok := val.(string); ok {
+				v[i] = f(s)
+			} else {
+				v[i] = redactJSON(val, f)
+			}
+		}
+	}
+	return v
+}
+
+// flatten returns the old, new pairs for a strings.Replacer replacing each
+// of olds with repl.
+func flatten(olds []string, repl string) []string {
+	pairs := make([]string, 0, 2*len(olds))
+	for _, o := range olds {
+		pairs = append(pairs, o, repl)
+	}
+	return pairs
+}
+
+// cassetteName names the cassette for creq after its path, for people
+// browsing the directory, and a hash of the whole request.
+func cassetteName(creq CassetteRequest) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", creq.Method, creq.URL)
+	h.Write(creq.JSON)
+	h.Write([]byte(creq.Body))
+
+	path := strings.Trim(strings.SplitN(creq.URL, "?", 2)[0], "/")
+	path = strings.NewReplacer("/", "_", ".", "_").Replace(path)
+	return fmt.Sprintf("%s_%s_%s.json", creq.Method, path, hex.EncodeToString(h.Sum(nil))[:12])
+}
+
+func readCassette(path string) (*Cassette, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("reading cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package doctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/digitalocean/godo"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	status := "new"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/droplets/1":
+			w.Write([]byte(`{"droplet":{"id":1,"name":"web","status":"` + status + `"}}`))
+			status = "active"
+		case "/v2/databases/abc":
+			w.Write([]byte(`{"database":{"id":"abc","connection":{"password":"hunter22","uri":"postgresql://doadmin:hunter22@db:25060/defaultdb","ssl":true}}}`))
+		default:
+			w.Write([]byte(`{"note":"called with secret-token"}`))
+		}
+	}))
+	defer srv.Close()
+
+	viper.Set("api-url", srv.URL)
+	viper.Set(ArgRecord, dir)
+	defer func() {
+		viper.Set("api-url", "")
+		viper.Set(ArgRecord, "")
+		viper.Set(ArgReplay, "")
+	}()
+
+	client, err := (&LiveConfig{}).GetGodoClient(false, true, "secret-token")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, _, err = client.Droplets.Get(ctx, 1)
+		require.NoError(t, err)
+	}
+	db, _, err := client.Databases.Get(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter22", db.Connection.Password)
+	_, _, err = client.Droplets.Create(ctx, &godo.DropletCreateRequest{Name: "web"})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		require.NoError(t, err)
+		assert.NotContains(t, string(b), "secret-token")
+		assert.NotContains(t, string(b), "hunter22")
+	}
+
+	// Replaying needs neither the API nor a token.
+	srv.Close()
+	viper.Set(ArgRecord, "")
+	viper.Set(ArgReplay, dir)
+
+	client, err = (&LiveConfig{}).GetGodoClient(false, true, "")
+	require.NoError(t, err)
+
+	var statuses []string
+	for i := 0; i < 3; i++ {
+		d, _, err := client.Droplets.Get(ctx, 1)
+		require.NoError(t, err)
+		statuses = append(statuses, d.Status)
+	}
+	assert.Equal(t, []string{"new", "active", "active"}, statuses)
+
+	db, _, err = client.Databases.Get(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, "REDACTED", db.Connection.Password)
+	assert.Equal(t, "postgresql://doadmin:REDACTED@db:25060/defaultdb", db.Connection.URI)
+	assert.True(t, db.Connection.SSL)
+
+	_, _, err = client.Droplets.Create(ctx, &godo.DropletCreateRequest{Name: "web"})
+	require.NoError(t, err)
+
+	_, _, err = client.Droplets.Create(ctx, &godo.DropletCreateRequest{Name: "api"})
+	var missErr *CassetteMissErr
+	require.True(t, errors.As(err, &missErr), "unexpected error: %v", err)
+	assert.Equal(t, http.MethodPost, missErr.Method)
+	assert.Equal(t, "/v2/droplets", missErr.URL)
+}
+
+func TestRecordAndReplayExclusive(t *testing.T) {
+	viper.Set(ArgRecord, "a")
+	viper.Set(ArgReplay, "b")
+	defer func() {
+		viper.Set(ArgRecord, "")
+		viper.Set(ArgReplay, "")
+	}()
+
+	_, err := (&LiveConfig{}).GetGodoClient(false, true, "token")
+	assert.EqualError(t, err, "--record and --replay cannot be used together")
+}
+
+func TestRecordRedactsCredentials(t *testing.T) {
+	tr := newRecordTransport(t.TempDir(), "secret-token", nil)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		secret string
+		want   string
+	}{
+		{
+			name:   "docker config",
+			path:   "/v2/registry/docker-credentials",
+			body:   `{"auths":{"registry.digitalocean.com":{"auth":"ZG86c2VjcmV0"}}}`,
+			secret: "ZG86c2VjcmV0",
+			want:   `{"auths":{"registry.digitalocean.com":{"auth":"REDACTED"}}}`,
+		},
+		{
+			name:   "kubernetes credentials",
+			path:   "/v2/kubernetes/clusters/abc/credentials",
+			body:   `{"server":"https://k8s","client_key_data":"a2V5","client_certificate_data":"Y2VydA==","expires_at":"2030-01-01T00:00:00Z"}`,
+			secret: "a2V5",
+		},
+		{
+			name:   "kubeconfig",
+			path:   "/v2/kubernetes/clusters/abc/kubeconfig",
+			body:   "apiVersion: v1\nusers:\n- name: admin\n  user:\n    token: dop_v1_abc\n",
+			secret: "dop_v1_abc",
+			want:   `"REDACTED"`,
+		},
+		{
+			name:   "functions namespace key",
+			path:   "/v2/functions/namespaces/fn-1",
+			body:   `{"namespace":{"uuid":"fn-1","key":"nskey123","label":"prod"}}`,
+			secret: "nskey123",
+			want:   `{"namespace":{"key":"REDACTED","label":"prod","uuid":"fn-1"}}`,
+		},
+		{
+			name:   "yaml body",
+			path:   "/v2/apps/propose",
+			body:   "name: web\nclient-key-data: a2V5\npassword: hunter22\n",
+			secret: "hunter22",
+			want:   `"name: web\nclient-key-data: REDACTED\npassword: REDACTED\n"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			js, text := tr.redact(tt.path, []byte(tt.body))
+			got := string(js)
+			if js == nil {
+				b, _ := json.Marshal(text)
+				got = string(b)
+			}
+			assert.NotContains(t, got, tt.secret)
+			if tt.want != "" {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRecordFileMode(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cassettes")
+	tr := newRecordTransport(dir, "", nil)
+	path := filepath.Join(dir, "c.json")
+
+	require.NoError(t, tr.record(path, CassetteRequest{Method: http.MethodGet, URL: "/v2/account"}, CassetteResponse{Status: 200}))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	info, err = os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+}
@@ -14,8 +14,12 @@ limitations under the License.
 package commands
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
 	"github.com/spf13/cobra"
 )
 
@@ -36,6 +40,18 @@ func OneClicks() *Command {
 	AddStringFlag(cmdOneClickList, doctl.ArgOneClickType, "", "", "The 1-Click type. Valid types are one of the following: kubernetes, droplet")
 	cmdOneClickList.Example = `The following example retrieves a list of 1-Click applications available for Droplets: doctl 1-click list --type droplet`
 
+	cmdOneClickInstall := CmdBuilder(cmd, RunOneClickInstall, "install <slug>...", "Install 1-Click applications on an existing resource", `Use this command to install 1-Click applications on an existing Kubernetes cluster or Droplet. Each slug is checked against the 1-Click catalog before anything is installed; use `+"`"+`doctl 1-click list`+"`"+` to browse the catalog.
+
+With `+"`"+`--cluster`+"`"+`, any number of Kubernetes 1-Click applications are installed on the cluster. The installation continues in the background after the command returns.
+
+With `+"`"+`--droplet`+"`"+`, the Droplet is rebuilt from the 1-Click application's image. Rebuilding erases all data on the Droplet, so you are asked for confirmation unless you pass `+"`"+`--force`+"`"+`. Only one application can be installed on a Droplet.`, Writer,
+		aliasOpt("in"), displayerType(&displayers.Action{}))
+	AddStringFlag(cmdOneClickInstall, doctl.ArgOneClickCluster, "", "", "The ID or name of the Kubernetes cluster to install the applications on")
+	AddStringFlag(cmdOneClickInstall, doctl.ArgOneClickDroplet, "", "", "The ID of the Droplet to install the application on")
+	AddBoolFlag(cmdOneClickInstall, doctl.ArgCommandWait, "", false, "Wait for a Droplet rebuild to complete before returning")
+	AddBoolFlag(cmdOneClickInstall, doctl.ArgForce, doctl.ArgShortForce, false, "Rebuild the Droplet without a confirmation prompt")
+	cmdOneClickInstall.Example = `The following example installs Loki and Netdata on the Kubernetes cluster ` + "`" + `example-cluster` + "`" + `: doctl 1-click install loki netdata --cluster example-cluster`
+
 	return cmd
 }
 
@@ -56,3 +72,121 @@ func RunOneClickList(c *CmdConfig) error {
 
 	return c.Display(items)
 }
+
+// RunOneClickInstall installs 1-Click applications on an existing cluster or Droplet.
+func RunOneClickInstall(c *CmdConfig) error {
+	if len(c.Args) < 1 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	slugs := c.Args
+
+	cluster, err := c.Doit.GetString(c.NS, doctl.ArgOneClickCluster)
+	if err != nil {
+		return err
+	}
+	droplet, err := c.Doit.GetString(c.NS, doctl.ArgOneClickDroplet)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case cluster != "" && droplet != "":
+		return fmt.Errorf("only one of --%s and --%s may be set", doctl.ArgOneClickCluster, doctl.ArgOneClickDroplet)
+	case cluster != "":
+		return installOneClicksOnCluster(c, cluster, slugs)
+	case droplet != "":
+		return installOneClickOnDroplet(c, droplet, slugs)
+	default:
+		return fmt.Errorf("either --%s or --%s must be set", doctl.ArgOneClickCluster, doctl.ArgOneClickDroplet)
+	}
+}
+
+// checkOneClickSlugs verifies that every slug is in the 1-Click catalog for the given type.
+func checkOneClickSlugs(c *CmdConfig, oneClickType string, slugs []string) error {
+	catalog, err := c.OneClicks().List(oneClickType)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(catalog))
+	for _, oc := range catalog {
+		known[oc.Slug] = true
+	}
+
+	var unknown []string
+	for _, slug := range slugs {
+		if !known[slug] {
+			unknown = append(unknown, slug)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown %s 1-Click application(s): %s; use `doctl 1-click list --type %s` to see the catalog",
+			oneClickType, strings.Join(unknown, ", "), oneClickType)
+	}
+	return nil
+}
+
+func installOneClicksOnCluster(c *CmdConfig, cluster string, slugs []string) error {
+	if err := checkOneClickSlugs(c, "kubernetes", slugs); err != nil {
+		return err
+	}
+
+	clusterID, err := clusterIDize(c, cluster)
+	if err != nil {
+		return err
+	}
+
+	notice("Installing %s on cluster %s", strings.Join(slugs, ", "), cluster)
+	message, err := c.OneClicks().InstallKubernetes(clusterID, slugs)
+	if err != nil {
+		return err
+	}
+
+	notice(message)
+	return nil
+}
+
+func installOneClickOnDroplet(c *CmdConfig, droplet string, slugs []string) error {
+	if len(slugs) > 1 {
+		return fmt.Errorf("only one 1-Click application can be installed on a Droplet")
+	}
+	slug := slugs[0]
+
+	id, err := ContextualAtoi(droplet, dropletIDResource)
+	if err != nil {
+		return err
+	}
+
+	if err := checkOneClickSlugs(c, "droplet", slugs); err != nil {
+		return err
+	}
+
+	force, err := c.Doit.GetBool(c.NS, doctl.ArgForce)
+	if err != nil {
+		return err
+	}
+	if !force && AskForConfirm(fmt.Sprintf("rebuild Droplet %d with %s? All data on the Droplet will be lost", id, slug)) != nil {
+		return errOperationAborted
+	}
+
+	wait, err := c.Doit.GetBool(c.NS, doctl.ArgCommandWait)
+	if err != nil {
+		return err
+	}
+
+	a, err := c.DropletActions().RebuildByImageSlug(id, slug)
+	if err != nil {
+		return err
+	}
+
+	if wait {
+		notice("Rebuilding Droplet %d with %s", id, slug)
+		a, err = actionWait(c, a.ID, 5)
+		if err != nil {
+			return err
+		}
+	}
+
+	item := &displayers.Action{Actions: do.Actions{*a}}
+	return c.Display(item)
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"testing"
 
+	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/do"
 	"github.com/digitalocean/godo"
 
@@ -25,7 +26,7 @@ var (
 func TestOneClickCommand(t *testing.T) {
 	cmd := OneClicks()
 	assert.NotNil(t, cmd)
-	assertCommandNames(t, cmd, "list")
+	assertCommandNames(t, cmd, "install", "list")
 }
 
 func TestOneClickListNoType(t *testing.T) {
@@ -35,3 +36,61 @@ func TestOneClickListNoType(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestOneClickInstallCluster(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		clusterID := "00000000-0000-4000-8000-000000000000"
+		tm.oneClick.EXPECT().List("kubernetes").Return(do.OneClicks{
+			{OneClick: &godo.OneClick{Slug: "loki", Type: "kubernetes"}},
+			{OneClick: &godo.OneClick{Slug: "netdata", Type: "kubernetes"}},
+		}, nil)
+		tm.oneClick.EXPECT().InstallKubernetes(clusterID, []string{"loki", "netdata"}).Return("installing", nil)
+
+		config.Args = append(config.Args, "loki", "netdata")
+		config.Doit.Set(config.NS, doctl.ArgOneClickCluster, clusterID)
+		config.Doit.Set(config.NS, doctl.ArgOneClickDroplet, "")
+
+		err := RunOneClickInstall(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestOneClickInstallUnknownSlug(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.oneClick.EXPECT().List("droplet").Return(testOneClickList, nil)
+
+		config.Args = append(config.Args, "missing")
+		config.Doit.Set(config.NS, doctl.ArgOneClickCluster, "")
+		config.Doit.Set(config.NS, doctl.ArgOneClickDroplet, "1")
+
+		err := RunOneClickInstall(config)
+		assert.ErrorContains(t, err, "unknown droplet 1-Click application(s): missing")
+	})
+}
+
+func TestOneClickInstallDroplet(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.oneClick.EXPECT().List("droplet").Return(testOneClickList, nil)
+		tm.dropletActions.EXPECT().RebuildByImageSlug(1, "test-slug").Return(&testAction, nil)
+
+		config.Args = append(config.Args, "test-slug")
+		config.Doit.Set(config.NS, doctl.ArgOneClickCluster, "")
+		config.Doit.Set(config.NS, doctl.ArgOneClickDroplet, "1")
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+		config.Doit.Set(config.NS, doctl.ArgCommandWait, false)
+
+		err := RunOneClickInstall(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestOneClickInstallRequiresTarget(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, "test-slug")
+		config.Doit.Set(config.NS, doctl.ArgOneClickCluster, "")
+		config.Doit.Set(config.NS, doctl.ArgOneClickDroplet, "")
+
+		err := RunOneClickInstall(config)
+		assert.ErrorContains(t, err, "must be set")
+	})
+}
@@ -15,7 +15,9 @@ package commands
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/commands/displayers"
 	"github.com/digitalocean/doctl/do"
 	"github.com/spf13/cobra"
@@ -55,6 +57,25 @@ Note that these details are per OAuth token and are bound to the token you used
 		aliasOpt("rl"), displayerType(&displayers.RateLimit{}))
 	cmdAccountRateLimit.Example = `The following example retrieves the number of API calls you have left for the hour: doctl account ratelimit --format Remaining`
 
+	cmdAccountLimits := CmdBuilder(cmd, RunAccountLimits, "limits", "Show resource limits and current usage", `Shows your account's limits on Droplets, volumes, and reserved IPs, along with how many of each you currently use.
+
+With `+"`"+`--threshold`+"`"+`, the command exits with a non-zero status when the usage of any resource reaches that percentage of its limit, so it can be run from monitoring jobs.`, Writer,
+		displayerType(&displayers.AccountLimits{}))
+	AddIntFlag(cmdAccountLimits, doctl.ArgLimitThreshold, "", 0, "Exit with a non-zero status when usage of any resource reaches this percentage of its limit. 0 disables the check")
+	cmdAccountLimits.Example = `The following example fails when any resource is at 80% of its limit or more: doctl account limits --threshold 80`
+
+	cmdAccountSecurityHistory := CmdBuilder(cmd, RunAccountSecurityHistory, "security-history", "List security-relevant events on your account", `Lists the events on your account that matter when auditing access to it, newest first:
+
+- API tokens created, with their scopes and expiry
+- Destructive actions, such as Droplets being destroyed, rebuilt, restored, or powered off, and volumes being detached
+- Access-related actions, such as root password resets, kernel changes, and reserved IP assignments
+
+Only events the API exposes are listed. Control panel logins and other sign-in activity are not available through the API; review them under Settings > Security in the control panel. Use `+"`"+`--output json`+"`"+` to feed the events to other tools.`, Writer,
+		aliasOpt("security", "sh"), displayerType(&displayers.AccountSecurityEvents{}))
+	AddDurationFlag(cmdAccountSecurityHistory, doctl.ArgSecurityHistorySince, "", 30*24*time.Hour, "How far back to list events")
+	AddBoolFlag(cmdAccountSecurityHistory, doctl.ArgSecurityHistoryAll, "", false, "List every action on the account, not only destructive and access-related ones")
+	cmdAccountSecurityHistory.Example = `The following example lists the security events of the last week as JSON: doctl account security-history --since 168h --output json`
+
 	return cmd
 }
 
@@ -78,7 +99,7 @@ func RunAccountRateLimit(c *CmdConfig) error {
 		accessToken := c.getContextAccessToken()
 		godoClient, err := c.Doit.GetGodoClient(Trace, false, accessToken)
 		if err != nil {
-			return fmt.Errorf("Unable to initialize DigitalOcean API client: %s", err)
+			return fmt.Errorf("Unable to initialize DigitalOcean API client: %w", err)
 		}
 
 		c.Account = func() do.AccountService { return do.NewAccountService(godoClient) }
@@ -91,3 +112,68 @@ func RunAccountRateLimit(c *CmdConfig) error {
 
 	return c.Display(&displayers.RateLimit{RateLimit: rl})
 }
+
+// RunAccountLimits shows the account's resource limits and the usage against them.
+func RunAccountLimits(c *CmdConfig) error {
+	threshold, err := c.Doit.GetInt(c.NS, doctl.ArgLimitThreshold)
+	if err != nil {
+		return err
+	}
+	if threshold < 0 || threshold > 100 {
+		return fmt.Errorf("--%s must be between 0 and 100", doctl.ArgLimitThreshold)
+	}
+
+	a, err := c.Account().Get()
+	if err != nil {
+		return err
+	}
+	droplets, err := c.Droplets().List()
+	if err != nil {
+		return err
+	}
+	volumes, err := c.Volumes().List()
+	if err != nil {
+		return err
+	}
+	ips, err := c.ReservedIPs().List()
+	if err != nil {
+		return err
+	}
+
+	ipLimit := a.ReservedIPLimit
+	if ipLimit == 0 {
+		ipLimit = a.FloatingIPLimit
+	}
+
+	limits := []displayers.AccountLimit{
+		accountLimit("droplets", len(droplets), a.DropletLimit),
+		accountLimit("volumes", len(volumes), a.VolumeLimit),
+		accountLimit("reserved_ips", len(ips), ipLimit),
+	}
+	if err := c.Display(&displayers.AccountLimits{Limits: limits}); err != nil {
+		return err
+	}
+
+	if threshold == 0 {
+		return nil
+	}
+	exceeded := false
+	for _, l := range limits {
+		if l.Limit > 0 && l.Percent >= float64(threshold) {
+			warn("%s usage is at %.0f%% of the limit (%d of %d)", l.Resource, l.Percent, l.Used, l.Limit)
+			exceeded = true
+		}
+	}
+	if exceeded {
+		return ErrExitSilently
+	}
+	return nil
+}
+
+func accountLimit(resource string, used, limit int) displayers.AccountLimit {
+	l := displayers.AccountLimit{Resource: resource, Used: used, Limit: limit}
+	if limit > 0 {
+		l.Percent = float64(used) * 100 / float64(limit)
+	}
+	return l
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/godo"
+)
+
+// securityActionCategories are the categories of the action types that
+// matter when auditing an account. Other actions are only listed with --all.
+var securityActionCategories = map[string]string{
+	"destroy":         "destructive",
+	"rebuild":         "destructive",
+	"restore":         "destructive",
+	"power_off":       "destructive",
+	"shutdown":        "destructive",
+	"disable_backups": "destructive",
+	"detach":          "destructive",
+	"password_reset":  "access",
+	"change_kernel":   "access",
+	"assign_ip":       "access",
+	"unassign_ip":     "access",
+}
+
+// RunAccountSecurityHistory lists the token creations and the destructive
+// or access-related actions on the account within the --since window.
+func RunAccountSecurityHistory(c *CmdConfig) error {
+	since, err := c.Doit.GetDuration(c.NS, doctl.ArgSecurityHistorySince)
+	if err != nil {
+		return err
+	}
+	all, err := c.Doit.GetBool(c.NS, doctl.ArgSecurityHistoryAll)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-since)
+
+	events := []displayers.AccountSecurityEvent{}
+
+	tokens, err := c.OAuth().ListTokens()
+	var errResp *godo.ErrorResponse
+	switch {
+	case errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusForbidden:
+		warn("Token creations are not listed because the current token cannot read tokens")
+	case err != nil:
+		return err
+	}
+	for _, t := range tokens {
+		if t.CreatedAt.Before(cutoff) {
+			continue
+		}
+		details := "scopes: " + strings.Join(t.Scopes, ",")
+		if t.ExpirySeconds != nil {
+			details += fmt.Sprintf("; expires after %s", time.Duration(*t.ExpirySeconds)*time.Second)
+		}
+		events = append(events, displayers.AccountSecurityEvent{
+			Time:     t.CreatedAt,
+			Category: "access",
+			Event:    "token_created",
+			Resource: fmt.Sprintf("token %s (%d)", t.Name, t.ID),
+			Details:  details,
+		})
+	}
+
+	actions, err := c.Actions().List()
+	if err != nil {
+		return err
+	}
+	for _, a := range actions {
+		if a.StartedAt == nil || a.StartedAt.Before(cutoff) {
+			continue
+		}
+		category, ok := securityActionCategories[a.Type]
+		if !ok {
+			if !all {
+				continue
+			}
+			category = "other"
+		}
+		events = append(events, displayers.AccountSecurityEvent{
+			Time:     a.StartedAt.Time,
+			Category: category,
+			Event:    a.Type,
+			Resource: fmt.Sprintf("%s %d", a.ResourceType, a.ResourceID),
+			Region:   a.RegionSlug,
+			Details:  a.Status,
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.After(events[j].Time) })
+
+	notice("Logins are not exposed by the API; review them under Settings > Security in the control panel")
+
+	return c.Display(&displayers.AccountSecurityEvents{Events: events})
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountSecurityHistory(t *testing.T) {
+	now := time.Now()
+	actions := do.Actions{
+		{Action: &godo.Action{ID: 1, Type: "destroy", Status: "completed", ResourceType: "droplet", ResourceID: 10, RegionSlug: "nyc1", StartedAt: &godo.Timestamp{Time: now.Add(-time.Hour)}}},
+		{Action: &godo.Action{ID: 2, Type: "reboot", Status: "completed", ResourceType: "droplet", ResourceID: 10, StartedAt: &godo.Timestamp{Time: now.Add(-2 * time.Hour)}}},
+		{Action: &godo.Action{ID: 3, Type: "password_reset", Status: "completed", ResourceType: "droplet", ResourceID: 11, StartedAt: &godo.Timestamp{Time: now.Add(-48 * time.Hour)}}},
+	}
+	tokens := []do.Token{
+		{ID: 5, Name: "ci", Scopes: []string{"droplet:read"}, CreatedAt: now.Add(-30 * time.Minute)},
+		{ID: 6, Name: "old", Scopes: []string{"account:read"}, CreatedAt: now.Add(-72 * time.Hour)},
+	}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.oauth.EXPECT().ListTokens().Return(tokens, nil)
+		tm.actions.EXPECT().List().Return(actions, nil)
+		config.Doit.Set(config.NS, doctl.ArgSecurityHistorySince, 24*time.Hour)
+
+		var out bytes.Buffer
+		config.Out = &out
+
+		require.NoError(t, RunAccountSecurityHistory(config))
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 3)
+		assert.Regexp(t, `access\s+token_created\s+token ci \(5\)\s+scopes: droplet:read`, lines[1])
+		assert.Regexp(t, `destructive\s+destroy\s+droplet 10\s+nyc1\s+completed`, lines[2])
+	})
+}
+
+func TestAccountSecurityHistoryAllWithoutTokenAccess(t *testing.T) {
+	now := time.Now()
+	actions := do.Actions{
+		{Action: &godo.Action{ID: 2, Type: "reboot", Status: "completed", ResourceType: "droplet", ResourceID: 10, StartedAt: &godo.Timestamp{Time: now.Add(-2 * time.Hour)}}},
+	}
+	forbidden := &godo.ErrorResponse{Response: &http.Response{StatusCode: http.StatusForbidden, Request: &http.Request{}}}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.oauth.EXPECT().ListTokens().Return(nil, forbidden)
+		tm.actions.EXPECT().List().Return(actions, nil)
+		config.Doit.Set(config.NS, doctl.ArgSecurityHistorySince, 24*time.Hour)
+		config.Doit.Set(config.NS, doctl.ArgSecurityHistoryAll, true)
+
+		var out bytes.Buffer
+		config.Out = &out
+
+		require.NoError(t, RunAccountSecurityHistory(config))
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.Regexp(t, `other\s+reboot\s+droplet 10`, lines[1])
+	})
+}
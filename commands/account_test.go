@@ -17,6 +17,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/do"
 	"github.com/digitalocean/godo"
 	"github.com/stretchr/testify/assert"
@@ -45,7 +46,7 @@ var testRateLimit = &do.RateLimit{
 func TestAccountCommand(t *testing.T) {
 	acctCmd := Account()
 	assert.NotNil(t, acctCmd)
-	assertCommandNames(t, acctCmd, "get", "ratelimit")
+	assertCommandNames(t, acctCmd, "get", "limits", "ratelimit", "security-history")
 }
 
 func TestAccountGet(t *testing.T) {
@@ -68,3 +69,42 @@ func TestAccountGetRateLimit(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestAccountLimits(t *testing.T) {
+	limited := &do.Account{Account: &godo.Account{DropletLimit: 10, VolumeLimit: 4, ReservedIPLimit: 3}}
+
+	tests := []struct {
+		name      string
+		threshold int
+		expectErr error
+	}{
+		{name: "no threshold"},
+		{name: "under threshold", threshold: 90},
+		{name: "over threshold", threshold: 75, expectErr: ErrExitSilently},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+				tm.account.EXPECT().Get().Return(limited, nil)
+				tm.droplets.EXPECT().List().Return(testDropletList, nil)
+				tm.volumes.EXPECT().List().Return([]do.Volume{testVolume, testVolume, testVolume}, nil)
+				tm.reservedIPs.EXPECT().List().Return(nil, nil)
+
+				config.Doit.Set(config.NS, doctl.ArgLimitThreshold, tt.threshold)
+
+				err := RunAccountLimits(config)
+				if tt.expectErr != nil {
+					assert.ErrorIs(t, err, tt.expectErr)
+				} else {
+					assert.NoError(t, err)
+				}
+			})
+		})
+	}
+}
+
+func TestAccountLimit(t *testing.T) {
+	assert.Equal(t, 75.0, accountLimit("volumes", 3, 4).Percent)
+	assert.Equal(t, 0.0, accountLimit("volumes", 3, 0).Percent)
+}
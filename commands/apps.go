@@ -31,6 +31,7 @@ import (
 	"github.com/digitalocean/godo"
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"sigs.k8s.io/yaml"
 )
 
@@ -59,6 +60,7 @@ func Apps() *Command {
 		displayerType(&displayers.Apps{}),
 	)
 	AddStringFlag(create, doctl.ArgAppSpec, "", "", `Path to an app spec in JSON or YAML format. Set to "-" to read from stdin.`, requiredOpt())
+	addAppSpecVarFlags(create)
 	AddBoolFlag(create, doctl.ArgCommandWait, "", false,
 		"Boolean that specifies whether to wait for an app to complete before returning control to the terminal")
 	AddBoolFlag(create, doctl.ArgCommandUpsert, "", false, "Boolean that specifies whether the app should be updated if it already exists")
@@ -104,6 +106,7 @@ Only basic information is included with the text output format. For complete app
 		displayerType(&displayers.Apps{}),
 	)
 	AddStringFlag(update, doctl.ArgAppSpec, "", "", `Path to an app spec in JSON or YAML format. Set to "-" to read from stdin.`, requiredOpt())
+	addAppSpecVarFlags(update)
 	AddBoolFlag(update, doctl.ArgCommandWait, "", false,
 		"Boolean that specifies whether to wait for an app to complete updating before allowing further terminal input. This can be helpful for scripting.")
 	update.Example = `The following example updates an app with the ID ` + "`" + `f81d4fae-7dec-11d0-a765-00a0c91e6bf6` + "`" + ` using an app spec located in a directory called ` + "`" + `/src/your-app.yaml` + "`" + `. Additionally, the command returns the updated app's ID, ingress information, and creation date: doctl apps update f81d4fae-7dec-11d0-a765-00a0c91e6bf6 --spec src/your-app.yaml --format ID,DefaultIngress,Created`
@@ -131,6 +134,7 @@ This permanently deletes the app and all of its associated deployments.`,
 		Writer,
 		aliasOpt("cd"),
 		displayerType(&displayers.Deployments{}),
+		notifyOpt(),
 	)
 	AddBoolFlag(deploymentCreate, doctl.ArgAppForceRebuild, "", false, "Force a re-build even if a previous build is eligible for reuse.")
 	AddBoolFlag(deploymentCreate, doctl.ArgCommandWait, "", false,
@@ -213,6 +217,7 @@ Only basic information is included with the text output format. For complete app
 	)
 	AddStringFlag(propose, doctl.ArgAppSpec, "", "", "Path to an app spec in JSON or YAML format. For more information about app specs, see the [app spec reference](https://www.digitalocean.com/docs/app-platform/concepts/app-spec)", requiredOpt())
 	AddStringFlag(propose, doctl.ArgApp, "", "", "An optional existing app ID. If specified, App Platform treats the spec as a proposed update to the existing app.")
+	addAppSpecVarFlags(propose)
 	propose.Example = `The following example proposes an app spec from the file directory ` + "`" + `src/your-app.yaml` + "`" + ` for a new app: doctl apps propose --spec src/your-app.yaml`
 
 	listAlerts := CmdBuilder(
@@ -268,7 +273,27 @@ Only basic information is included with the text output format. For complete app
 		doctl.ArgTriggerDeployment, "", true, "Specifies whether to trigger a new deployment to apply the upgrade.")
 	upgradeBuildpack.Example = `The following example upgrades an app's buildpack with the ID ` + "`" + `f81d4fae-7dec-11d0-a765-00a0c91e6bf6` + "`" + ` to the latest available version: doctl apps upgrade-buildpack f81d4fae-7dec-11d0-a765-00a0c91e6bf6 --buildpack f81d4fae-7dec-11d0-a765-00a0c91e6bf6`
 
+	scale := CmdBuilder(
+		cmd,
+		RunAppsScale,
+		"scale <app id>",
+		"Scale a component of an app",
+		`Changes the number of instances, or the instance size, of a service, worker or job of an app, and deploys the app with the change.
+
+Only the scaling fields of the component are changed in the app's live spec, so the rest of the spec doesn't have to be edited, or even known. The instance count of a component that autoscales can't be set; change its autoscaling limits in the spec instead. Use `+"`"+`doctl apps tier instance-size list`+"`"+` to list the instance sizes.`,
+		Writer,
+		displayerType(&displayers.Deployments{}),
+		notifyOpt(),
+	)
+	AddStringFlag(scale, doctl.ArgAppComponent, "", "", "The name of the component to scale", requiredOpt())
+	AddIntFlag(scale, doctl.ArgAppInstances, "", 0, "The number of instances the component runs")
+	AddStringFlag(scale, doctl.ArgAppInstanceSize, "", "", "The slug of the instance size the component runs on, such as professional-s")
+	AddBoolFlag(scale, doctl.ArgCommandWait, "", false, "Wait for the deployment to finish before returning")
+	scale.Example = `The following example scales the ` + "`" + `web` + "`" + ` component of an app to 4 instances of the ` + "`" + `professional-s` + "`" + ` size: doctl apps scale f81d4fae-7dec-11d0-a765-00a0c91e6bf6 --component web --instances 4 --size professional-s`
+
 	cmd.AddCommand(appsSpec())
+	cmd.AddCommand(appsPreview())
+	cmd.AddCommand(appsDeployment())
 	cmd.AddCommand(appsTier())
 
 	return cmd
@@ -281,7 +306,7 @@ func RunAppsCreate(c *CmdConfig) error {
 		return err
 	}
 
-	appSpec, err := apps.ReadAppSpec(os.Stdin, specPath)
+	appSpec, err := readAppSpec(c, specPath)
 	if err != nil {
 		return err
 	}
@@ -343,6 +368,9 @@ func RunAppsCreate(c *CmdConfig) error {
 
 	notice("App created")
 
+	if err := setGitHubOutputs(appGitHubOutputs(app, "")); err != nil {
+		return err
+	}
 	return c.Display(displayers.Apps{app})
 }
 
@@ -388,7 +416,7 @@ func RunAppsUpdate(c *CmdConfig) error {
 		return err
 	}
 
-	appSpec, err := apps.ReadAppSpec(os.Stdin, specPath)
+	appSpec, err := readAppSpec(c, specPath)
 	if err != nil {
 		return err
 	}
@@ -421,6 +449,9 @@ func RunAppsUpdate(c *CmdConfig) error {
 
 	notice("App updated")
 
+	if err := setGitHubOutputs(appGitHubOutputs(app, "")); err != nil {
+		return err
+	}
 	return c.Display(displayers.Apps{app})
 }
 
@@ -436,7 +467,7 @@ func RunAppsDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if !force && AskForConfirmDelete("App", 1) != nil {
+	if !force && AskForConfirmDelete("App", 1, id) != nil {
 		return errOperationAborted
 	}
 
@@ -469,6 +500,7 @@ func RunAppsCreateDeployment(c *CmdConfig) error {
 	if err != nil {
 		return err
 	}
+	c.addNotifyDetail("Deployment", deployment.ID)
 
 	var errs error
 
@@ -484,10 +516,14 @@ func RunAppsCreateDeployment(c *CmdConfig) error {
 			return errs
 		}
 		deployment, _ = c.Apps().GetDeployment(appID, deployment.ID)
+		c.addNotifyDetail("Phase", string(deployment.GetPhase()))
 	}
 
 	notice("Deployment created")
 
+	if err := setGitHubOutputs(map[string]string{"app_id": appID, "deployment_id": deployment.ID}); err != nil {
+		return err
+	}
 	return c.Display(displayers.Deployments{deployment})
 }
 
@@ -521,7 +557,7 @@ func waitForActiveDeployment(apps do.AppsService, appID string, deploymentID str
 		attempts++
 		time.Sleep(10 * time.Second)
 	}
-	return fmt.Errorf("timeout waiting to app (%s) deployment", appID)
+	return errTimeout("timeout waiting to app (%s) deployment", appID)
 }
 
 // RunAppsGetDeployment gets a deployment for an app.
@@ -615,6 +651,14 @@ func RunAppsGetLogs(c *CmdConfig) error {
 		return err
 	}
 
+	out := c.Out
+	if viper.GetString(doctl.ArgJQ) != "" || viper.GetString(doctl.ArgOutput) == "ndjson" {
+		// Log lines are written, and filtered by --jq, as JSON strings.
+		lw := &jsonLinesWriter{out: c.Out}
+		defer lw.Flush()
+		out = lw
+	}
+
 	if logs.LiveURL != "" {
 		url, err := url.Parse(logs.LiveURL)
 		if err != nil {
@@ -642,7 +686,7 @@ func RunAppsGetLogs(c *CmdConfig) error {
 			url.Scheme = "wss"
 		}
 
-		listener := c.Doit.Listen(url, token, schemaFunc, c.Out)
+		listener := c.Doit.Listen(url, token, schemaFunc, out)
 		err = listener.Start()
 		if err != nil {
 			return err
@@ -654,7 +698,7 @@ func RunAppsGetLogs(c *CmdConfig) error {
 		}
 		defer resp.Body.Close()
 
-		io.Copy(c.Out, resp.Body)
+		io.Copy(out, resp.Body)
 	} else {
 		warn("No logs found for app component")
 	}
@@ -674,7 +718,7 @@ func RunAppsPropose(c *CmdConfig) error {
 		return err
 	}
 
-	appSpec, err := apps.ReadAppSpec(os.Stdin, specPath)
+	appSpec, err := readAppSpec(c, specPath)
 	if err != nil {
 		return err
 	}
@@ -711,6 +755,22 @@ Optionally, pass a deployment ID to get the spec of that specific deployment.`,
 
 You may pass - as the filename to read from stdin.`, Writer, false)
 	AddBoolFlag(validateCmd, doctl.ArgSchemaOnly, "", false, "Only validate the spec schema and not the correctness of the spec.")
+	addAppSpecVarFlags(validateCmd)
+
+	lintCmd := cmdBuilderWithInit(cmd, RunAppsSpecLint, "lint <spec file>", "Check an application spec for likely mistakes", `Use this command to check a valid app spec (YAML or JSON) for likely mistakes. Each finding has a severity of `+"`"+`error`+"`"+` or `+"`"+`warning`+"`"+` and names the rule that produced it:
+
+- `+"`"+`multiple-sources`+"`"+` (error): a component sets more than one source, such as both `+"`"+`git`+"`"+` and `+"`"+`github`+"`"+`
+- `+"`"+`missing-health-check`+"`"+` (warning): a service has no health check
+- `+"`"+`build-time-secret`+"`"+` (warning): a secret is available at build time as well as run time
+- `+"`"+`too-many-instances`+"`"+` (warning): a component's instance count or autoscaling maximum is more than `+"`"+`--max-instances`+"`"+`
+
+The command exits with an error if there are any error findings, or any findings at all with `+"`"+`--strict`+"`"+`. Use `+"`"+`--output json`+"`"+` for machine-readable findings. The spec is not sent to the API.
+
+You may pass - as the filename to read from stdin.`, Writer, false, displayerType(&displayers.AppSpecLintFindings{}))
+	AddBoolFlag(lintCmd, doctl.ArgAppSpecLintStrict, "", false, "Exit with an error on warnings as well as errors")
+	AddIntFlag(lintCmd, doctl.ArgAppSpecLintMaxInstances, "", 10, "The most instances a component may run before it is reported. Set to 0 to disable the check.")
+	addAppSpecVarFlags(lintCmd)
+	lintCmd.Example = `The following example lints the app spec ` + "`" + `.do/app.yaml` + "`" + ` in CI, failing on any finding: doctl apps spec lint .do/app.yaml --strict --output json`
 
 	return cmd
 }
@@ -772,7 +832,7 @@ func RunAppsSpecValidate(c *CmdConfig) error {
 	}
 
 	specPath := c.Args[0]
-	appSpec, err := apps.ReadAppSpec(os.Stdin, specPath)
+	appSpec, err := readAppSpec(c, specPath)
 	if err != nil {
 		return err
 	}
@@ -945,6 +1005,64 @@ func RunAppUpdateAlertDestinations(c *CmdConfig) error {
 	return c.Display(displayers.AppAlerts([]*godo.AppAlert{alert}))
 }
 
+// addAppSpecVarFlags adds the flags that set the variables interpolated into an app spec.
+func addAppSpecVarFlags(cmd *Command) {
+	AddStringMapStringFlag(cmd, doctl.ArgAppSpecVar, "", map[string]string{}, "A variable to interpolate into the app spec in `NAME=VALUE` format, replacing each `${NAME}` reference. May be repeated.")
+	AddStringFlag(cmd, doctl.ArgAppSpecVarFile, "", "", "Path to a YAML or JSON values file of variables to interpolate into the app spec. Variables set with `--var` take precedence.")
+	AddBoolFlag(cmd, doctl.ArgAppSpecVarEnv, "", false, "Interpolate environment variables into the app spec. Variables set with `--var-file` or `--var` take precedence.")
+}
+
+// appSpecVars returns the variables to interpolate into an app spec, or nil if none of the
+// variable flags are set.
+func appSpecVars(c *CmdConfig) (map[string]string, error) {
+	flags, err := c.Doit.GetStringMapString(c.NS, doctl.ArgAppSpecVar)
+	if err != nil {
+		return nil, err
+	}
+	varFile, err := c.Doit.GetString(c.NS, doctl.ArgAppSpecVarFile)
+	if err != nil {
+		return nil, err
+	}
+	useEnv, err := c.Doit.GetBool(c.NS, doctl.ArgAppSpecVarEnv)
+	if err != nil {
+		return nil, err
+	}
+	if len(flags) == 0 && varFile == "" && !useEnv {
+		return nil, nil
+	}
+
+	vars := make(map[string]string)
+	if useEnv {
+		for _, kv := range os.Environ() {
+			if name, value, ok := strings.Cut(kv, "="); ok {
+				vars[name] = value
+			}
+		}
+	}
+	if varFile != "" {
+		values, err := apps.ReadAppSpecValues(varFile)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range values {
+			vars[name] = value
+		}
+	}
+	for name, value := range flags {
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+// readAppSpec reads the app spec at path, interpolating the variables set by the command's flags.
+func readAppSpec(c *CmdConfig, path string) (*godo.AppSpec, error) {
+	vars, err := appSpecVars(c)
+	if err != nil {
+		return nil, err
+	}
+	return apps.ReadAppSpecWithVars(os.Stdin, path, vars)
+}
+
 func readAppAlertDestination(stdin io.Reader, path string) (*godo.AlertDestinationUpdateRequest, error) {
 	var alertDestinations io.Reader
 	if path == "-" {
@@ -0,0 +1,278 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/cobra"
+)
+
+func appsDeployment() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "deployment",
+			Short: "Display commands for following app deployments",
+			Long:  "The subcommands of `doctl apps deployment` follow the progress of app deployments.",
+		},
+	}
+
+	watch := CmdBuilder(cmd, RunAppsDeploymentWatch, "watch <app id> [<deployment id>]", "Follow a deployment until it finishes", `Follows a deployment of an app, printing each phase it enters, such as building, deploying and active, and any step that fails.
+
+If no deployment ID is given, the app's in-progress deployment is followed, or its most recent deployment if none is in progress.
+
+When the deployment finishes, a summary with the time taken by each step is printed. If the deployment fails, the build logs of the failed components are printed and the command exits with an error, so that it can gate CI pipelines.`, Writer, notifyOpt())
+	AddDurationFlag(watch, doctl.ArgInterval, "", 5*time.Second, "How often to check the deployment's progress")
+	AddDurationFlag(watch, doctl.ArgTimeout, "", 30*time.Minute, "How long to wait for the deployment to finish. Set to 0 to wait indefinitely")
+	AddIntFlag(watch, doctl.ArgAppLogTail, "", 100, "The number of lines of build logs to print for each failed component")
+	watch.Example = `The following example deploys an app and waits for the deployment to finish: doctl apps create-deployment f81d4fae-7dec-11d0-a765-00a0c91e6bf6 && doctl apps deployment watch f81d4fae-7dec-11d0-a765-00a0c91e6bf6`
+
+	return cmd
+}
+
+// RunAppsDeploymentWatch follows a deployment until it is active or has failed.
+func RunAppsDeploymentWatch(c *CmdConfig) error {
+	if len(c.Args) < 1 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	if len(c.Args) > 2 {
+		return doctl.NewTooManyArgsErr(c.NS)
+	}
+	appID := c.Args[0]
+
+	interval, err := c.Doit.GetDuration(c.NS, doctl.ArgInterval)
+	if err != nil {
+		return err
+	}
+	timeout, err := c.Doit.GetDuration(c.NS, doctl.ArgTimeout)
+	if err != nil {
+		return err
+	}
+	tail, err := c.Doit.GetInt(c.NS, doctl.ArgAppLogTail)
+	if err != nil {
+		return err
+	}
+
+	var deploymentID string
+	if len(c.Args) == 2 {
+		deploymentID = c.Args[1]
+	} else if deploymentID, err = watchedDeploymentID(c, appID); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	var (
+		lastPhase godo.DeploymentPhase
+		reported  = map[string]bool{}
+		d         *godo.Deployment
+	)
+	for {
+		d, err = c.Apps().GetDeployment(appID, deploymentID)
+		if err != nil {
+			return err
+		}
+
+		if d.Phase != lastPhase {
+			lastPhase = d.Phase
+			fmt.Fprintf(c.Out, "%s %s%s\n", deploymentElapsed(d), d.Phase, deploymentStepCount(d))
+		}
+		for _, step := range failedDeploymentSteps(d) {
+			key := step.ComponentName + "/" + step.Name
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+			msg := deploymentStepLabel(step) + " failed"
+			if step.Reason != nil && step.Reason.Message != "" {
+				msg += ": " + step.Reason.Message
+			}
+			fmt.Fprintf(c.Out, "%s   %s\n", deploymentElapsed(d), msg)
+		}
+
+		if deploymentFinished(d.Phase) {
+			break
+		}
+		if timeout > 0 && time.Since(start) > timeout {
+			return errTimeout("timed out after %s waiting for deployment %s, which is %s", timeout, d.ID, d.Phase)
+		}
+		time.Sleep(interval)
+	}
+
+	fmt.Fprintln(c.Out)
+	printDeploymentSummary(c.Out, d)
+	c.addNotifyDetail("Deployment", d.ID)
+	c.addNotifyDetail("Phase", string(d.Phase))
+
+	if d.Phase == godo.DeploymentPhase_Active {
+		return nil
+	}
+
+	components := map[string]bool{}
+	for _, step := range failedDeploymentSteps(d) {
+		if step.ComponentName == "" || components[step.ComponentName] {
+			continue
+		}
+		components[step.ComponentName] = true
+		if err := printComponentBuildLogs(c, appID, d.ID, step.ComponentName, tail); err != nil {
+			warn("Could not get the build logs of %s: %v", step.ComponentName, err)
+		}
+	}
+	return fmt.Errorf("deployment %s finished in phase %s", d.ID, d.Phase)
+}
+
+// watchedDeploymentID picks the deployment to watch when none is given.
+func watchedDeploymentID(c *CmdConfig, appID string) (string, error) {
+	app, err := c.Apps().Get(appID)
+	if err != nil {
+		return "", err
+	}
+	if id := app.GetInProgressDeployment().GetID(); id != "" {
+		return id, nil
+	}
+	if id := app.GetPendingDeployment().GetID(); id != "" {
+		return id, nil
+	}
+
+	deployments, err := c.Apps().ListDeployments(appID)
+	if err != nil {
+		return "", err
+	}
+	if len(deployments) == 0 {
+		return "", fmt.Errorf("app %s has no deployments", appID)
+	}
+	return deployments[0].ID, nil
+}
+
+func deploymentFinished(phase godo.DeploymentPhase) bool {
+	switch phase {
+	case godo.DeploymentPhase_Active, godo.DeploymentPhase_Error,
+		godo.DeploymentPhase_Canceled, godo.DeploymentPhase_Superseded:
+		return true
+	}
+	return false
+}
+
+// deploymentElapsed is the time from the creation of the deployment to its
+// latest phase change, formatted as a log prefix.
+func deploymentElapsed(d *godo.Deployment) string {
+	elapsed := time.Duration(0)
+	if !d.CreatedAt.IsZero() && d.PhaseLastUpdatedAt.After(d.CreatedAt) {
+		elapsed = d.PhaseLastUpdatedAt.Sub(d.CreatedAt)
+	}
+	return fmt.Sprintf("[%s]", formatStepDuration(elapsed))
+}
+
+func deploymentStepCount(d *godo.Deployment) string {
+	if d.Progress == nil || d.Progress.TotalSteps == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d/%d steps done)", d.Progress.SuccessSteps, d.Progress.TotalSteps)
+}
+
+func deploymentStepLabel(step *godo.DeploymentProgressStep) string {
+	switch {
+	case step.MessageBase != "" && step.ComponentName != "":
+		return step.MessageBase + " " + step.ComponentName
+	case step.ComponentName != "":
+		return step.Name + " " + step.ComponentName
+	default:
+		return step.Name
+	}
+}
+
+// failedDeploymentSteps returns the innermost steps that failed.
+func failedDeploymentSteps(d *godo.Deployment) []*godo.DeploymentProgressStep {
+	if d.Progress == nil {
+		return nil
+	}
+	var failed []*godo.DeploymentProgressStep
+	var walk func(steps []*godo.DeploymentProgressStep)
+	walk = func(steps []*godo.DeploymentProgressStep) {
+		for _, step := range steps {
+			if step.Status != godo.DeploymentProgressStepStatus_Error {
+				continue
+			}
+			before := len(failed)
+			walk(step.Steps)
+			if len(failed) == before {
+				failed = append(failed, step)
+			}
+		}
+	}
+	walk(d.Progress.Steps)
+	return failed
+}
+
+func formatStepDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// printDeploymentSummary prints the outcome of a deployment and the time
+// taken by its steps and their components.
+func printDeploymentSummary(out io.Writer, d *godo.Deployment) {
+	total := ""
+	if !d.CreatedAt.IsZero() && d.PhaseLastUpdatedAt.After(d.CreatedAt) {
+		total = " after " + formatStepDuration(d.PhaseLastUpdatedAt.Sub(d.CreatedAt))
+	}
+	fmt.Fprintf(out, "Deployment %s %s%s\n", d.ID, d.Phase, total)
+	if d.Progress == nil {
+		return
+	}
+
+	var walk func(steps []*godo.DeploymentProgressStep, depth int)
+	walk = func(steps []*godo.DeploymentProgressStep, depth int) {
+		for _, step := range steps {
+			took := "-"
+			if !step.StartedAt.IsZero() && !step.EndedAt.IsZero() {
+				took = formatStepDuration(step.EndedAt.Sub(step.StartedAt))
+			}
+			label := deploymentStepLabel(step)
+			fmt.Fprintf(out, "%s%-*s %-8s %s\n", strings.Repeat("  ", depth+1), 32-2*depth, label, took, strings.ToLower(string(step.Status)))
+			if depth < 1 {
+				walk(step.Steps, depth+1)
+			}
+		}
+	}
+	walk(d.Progress.Steps, 0)
+}
+
+func printComponentBuildLogs(c *CmdConfig, appID, deploymentID, component string, tail int) error {
+	logs, err := c.Apps().GetLogs(appID, deploymentID, component, godo.AppLogTypeBuild, false, tail)
+	if err != nil {
+		return err
+	}
+	if len(logs.HistoricURLs) == 0 {
+		return fmt.Errorf("no logs found")
+	}
+
+	resp, err := http.Get(logs.HistoricURLs[0])
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching logs: %s", resp.Status)
+	}
+
+	fmt.Fprintf(c.Out, "\n==> Build logs for %s\n", component)
+	_, err = io.Copy(c.Out, resp.Body)
+	return err
+}
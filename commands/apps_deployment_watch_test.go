@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func watchTestDeployment(phase godo.DeploymentPhase, after time.Duration, steps ...*godo.DeploymentProgressStep) *godo.Deployment {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	d := &godo.Deployment{
+		ID:                 "dep-id",
+		Phase:              phase,
+		CreatedAt:          created,
+		PhaseLastUpdatedAt: created.Add(after),
+		Progress:           &godo.DeploymentProgress{TotalSteps: 2, Steps: steps},
+	}
+	for _, s := range steps {
+		if s.Status == godo.DeploymentProgressStepStatus_Success {
+			d.Progress.SuccessSteps++
+		}
+	}
+	return d
+}
+
+func watchTestStep(name, component string, status godo.DeploymentProgressStepStatus, took time.Duration, steps ...*godo.DeploymentProgressStep) *godo.DeploymentProgressStep {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return &godo.DeploymentProgressStep{
+		Name: name, ComponentName: component, Status: status, Steps: steps,
+		StartedAt: start, EndedAt: start.Add(took),
+	}
+}
+
+func TestAppsDeploymentWatch(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		build := watchTestStep("build", "", godo.DeploymentProgressStepStatus_Success, 90*time.Second,
+			watchTestStep("build", "web", godo.DeploymentProgressStepStatus_Success, 80*time.Second))
+		deploy := watchTestStep("deploy", "", godo.DeploymentProgressStepStatus_Success, 30*time.Second)
+
+		tm.apps.EXPECT().Get("app-id").Return(&godo.App{ID: "app-id", InProgressDeployment: &godo.Deployment{ID: "dep-id"}}, nil)
+		gomock.InOrder(
+			tm.apps.EXPECT().GetDeployment("app-id", "dep-id").Return(watchTestDeployment(godo.DeploymentPhase_Building, 5*time.Second), nil),
+			tm.apps.EXPECT().GetDeployment("app-id", "dep-id").Return(watchTestDeployment(godo.DeploymentPhase_Building, 5*time.Second), nil),
+			tm.apps.EXPECT().GetDeployment("app-id", "dep-id").Return(watchTestDeployment(godo.DeploymentPhase_Deploying, 95*time.Second, build), nil),
+			tm.apps.EXPECT().GetDeployment("app-id", "dep-id").Return(watchTestDeployment(godo.DeploymentPhase_Active, 125*time.Second, build, deploy), nil),
+		)
+
+		var out bytes.Buffer
+		config.Out = &out
+		config.Args = []string{"app-id"}
+		config.Doit.Set(config.NS, doctl.ArgInterval, time.Millisecond)
+
+		require.NoError(t, RunAppsDeploymentWatch(config))
+		assert.Equal(t, `[0m05s] BUILDING (0/2 steps done)
+[1m35s] DEPLOYING (1/2 steps done)
+[2m05s] ACTIVE (2/2 steps done)
+
+Deployment dep-id ACTIVE after 2m05s
+  build                            1m30s    success
+    build web                      1m20s    success
+  deploy                           0m30s    success
+`, out.String())
+	})
+}
+
+func TestAppsDeploymentWatchFailed(t *testing.T) {
+	logs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("npm ERR! missing script: build\n"))
+	}))
+	defer logs.Close()
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		failed := watchTestStep("build", "", godo.DeploymentProgressStepStatus_Error, 40*time.Second,
+			watchTestStep("build", "api", godo.DeploymentProgressStepStatus_Error, 40*time.Second))
+		failed.Steps[0].MessageBase = "Building service"
+		failed.Steps[0].Reason = &godo.DeploymentProgressStepReason{Message: "exit code 1"}
+
+		tm.apps.EXPECT().GetDeployment("app-id", "dep-id").Return(watchTestDeployment(godo.DeploymentPhase_Error, 45*time.Second, failed), nil)
+		tm.apps.EXPECT().GetLogs("app-id", "dep-id", "api", godo.AppLogTypeBuild, false, 100).Return(&godo.AppLogs{HistoricURLs: []string{logs.URL}}, nil)
+
+		var out bytes.Buffer
+		config.Out = &out
+		config.Args = []string{"app-id", "dep-id"}
+		config.Doit.Set(config.NS, doctl.ArgAppLogTail, 100)
+
+		err := RunAppsDeploymentWatch(config)
+		assert.EqualError(t, err, "deployment dep-id finished in phase ERROR")
+		assert.Equal(t, `[0m45s] ERROR (0/2 steps done)
+[0m45s]   Building service api failed: exit code 1
+
+Deployment dep-id ERROR after 0m45s
+  build                            0m40s    error
+    Building service api           0m40s    error
+
+==> Build logs for api
+npm ERR! missing script: build
+`, out.String())
+	})
+}
@@ -69,7 +69,12 @@ func AppsDev() *Command {
 			  The component name is optional unless running non-interactively.
 
 			  All command line flags as optional. You may specify flags to be applied to the current build
-			  or use the command %s to permanently configure default values.`,
+			  or use the command %s to permanently configure default values.
+
+			  When the app has a functions component, SERVERLESS_URL is set to the URL of the functions
+			  namespace doctl is connected to, followed by the optional --serverless-url-path. Static sites
+			  receive it at build time, and services and workers at run time. Declare SERVERLESS_URL in a
+			  component's envs to use your own value, or set --no-serverless-url to leave it out.`,
 			"`doctl app dev config`",
 		),
 		Writer,
@@ -119,6 +124,24 @@ func AppsDev() *Command {
 		"An optional registry name to tag built container images with.",
 	)
 
+	AddStringFlag(
+		build, doctl.ArgServerlessURLPath,
+		"", "",
+		"An optional path, such as /api, appended to the injected SERVERLESS_URL.",
+	)
+
+	AddBoolFlag(
+		build, doctl.ArgServerlessURLTrailingSlash,
+		"", false,
+		"Set to make the injected SERVERLESS_URL end with a slash.",
+	)
+
+	AddBoolFlag(
+		build, doctl.ArgNoServerlessURL,
+		"", false,
+		"Set to build the component without the injected SERVERLESS_URL.",
+	)
+
 	return cmd
 }
 
@@ -226,6 +249,25 @@ func RunAppsDevBuild(c *CmdConfig) error {
 		return fmt.Errorf("not supported")
 	}
 
+	// serverlessURLEnvArg passes SERVERLESS_URL to the container of a service or worker in the docker run example.
+	var serverlessURLEnvArg string
+	if scope, ok := serverlessURLScope(componentSpec.GetType()); ok && !component.NoServerlessURL && len(ws.Config.AppSpec.GetFunctions()) > 0 {
+		url, err := getServerlessURL(c, ws.Config.ServerlessURLPath, ws.Config.ServerlessURLTrailingSlash)
+		if err != nil {
+			return err
+		}
+		if url != "" && !hasEnv(componentSpec.GetEnvs(), serverlessURLEnv) {
+			addServerlessURL(ws.Config.AppSpec, url, func(name string) bool {
+				cc := ws.Config.Components[name]
+				return cc != nil && cc.NoServerlessURL
+			})
+			template.Print(`{{success checkmark}} using serverless URL {{highlight .}}{{nl}}`, url)
+			if scope == godo.AppVariableScope_RunTime {
+				serverlessURLEnvArg = fmt.Sprintf("-e %s=%s ", serverlessURLEnv, url)
+			}
+		}
+	}
+
 	if componentSpec.GetSourceDir() != "" {
 		sd := componentSpec.GetSourceDir()
 		stat, err := os.Stat(ws.Context(sd))
@@ -421,7 +463,7 @@ func RunAppsDevBuild(c *CmdConfig) error {
 	} else if userCanceled {
 		return fmt.Errorf("canceled")
 	} else if res.ExitCode == 0 {
-		var portEnv string
+		portEnv := serverlessURLEnvArg
 		var portArg string
 		if componentSpec.GetType() == godo.AppComponentTypeService {
 			svc := componentSpec.(*godo.AppServiceSpec)
@@ -429,7 +471,7 @@ func RunAppsDevBuild(c *CmdConfig) error {
 			if svc.HTTPPort != 0 {
 				port = int(svc.HTTPPort)
 			}
-			portEnv = fmt.Sprintf("-e PORT=%d ", port)
+			portEnv += fmt.Sprintf("-e PORT=%d ", port)
 			portArg = fmt.Sprintf("-p 8080:%d ", port)
 		} else if componentSpec.GetType() == godo.AppComponentTypeStaticSite {
 			// static site config is hard-coded in nginx to 8080 currently
@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/charm/template"
+	"github.com/digitalocean/doctl/commands/charm/text"
+	"github.com/digitalocean/godo"
+)
+
+// serverlessURLEnv is the variable static sites read the serverless URL from.
+const serverlessURLEnv = "SERVERLESS_URL"
+
+// getServerlessURL returns the URL of the functions namespace doctl is connected to, followed by urlPath. An empty URL
+// is returned if doctl isn't connected to a namespace.
+func getServerlessURL(c *CmdConfig, urlPath string, trailingSlash bool) (string, error) {
+	urlPath, err := cleanServerlessURLPath(urlPath)
+	if err != nil {
+		return "", err
+	}
+
+	creds, err := c.Serverless().ReadCredentials()
+	if err != nil || creds.APIHost == "" || creds.Namespace == "" {
+		template.Render(text.Warning, `{{pointerRight}} not connected to a functions namespace, building without {{highlight .}}{{nl}}`, serverlessURLEnv)
+		return "", nil
+	}
+
+	u := strings.TrimSuffix(creds.APIHost, "/") + "/api/v1/web/" + creds.Namespace + urlPath
+	if trailingSlash && !strings.HasSuffix(u, "/") {
+		u += "/"
+	}
+	return u, nil
+}
+
+// cleanServerlessURLPath validates a --serverless-url-path value and returns it without duplicate or trailing slashes.
+func cleanServerlessURLPath(p string) (string, error) {
+	if p == "" || p == "/" {
+		return "", nil
+	}
+	if !strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("invalid --%s %q: must start with /", doctl.ArgServerlessURLPath, p)
+	}
+	if _, err := url.PathUnescape(p); err != nil || strings.ContainsAny(p, "?# ") {
+		return "", fmt.Errorf("invalid --%s %q: must be a URL path without a query or fragment", doctl.ArgServerlessURLPath, p)
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == "." || segment == ".." {
+			return "", fmt.Errorf("invalid --%s %q: must not contain . or .. segments", doctl.ArgServerlessURLPath, p)
+		}
+	}
+	return path.Clean(p), nil
+}
+
+// serverlessURLScope returns the scope of the SERVERLESS_URL variable for a component type: static sites read it while
+// they are built and services and workers while they run. Other component types don't receive it.
+func serverlessURLScope(componentType godo.AppComponentType) (godo.AppVariableScope, bool) {
+	switch componentType {
+	case godo.AppComponentTypeStaticSite:
+		return godo.AppVariableScope_BuildTime, true
+	case godo.AppComponentTypeService, godo.AppComponentTypeWorker:
+		return godo.AppVariableScope_RunTime, true
+	}
+	return "", false
+}
+
+// addServerlessURL adds a SERVERLESS_URL variable to the static sites, services, and workers of the spec that don't
+// already declare one, except those optOut reports.
+func addServerlessURL(spec *godo.AppSpec, serverlessURL string, optOut func(component string) bool) {
+	_ = godo.ForEachAppSpecComponent(spec, func(c godo.AppBuildableComponentSpec) error {
+		scope, ok := serverlessURLScope(c.GetType())
+		if !ok || optOut(c.GetName()) || hasEnv(c.GetEnvs(), serverlessURLEnv) {
+			return nil
+		}
+		env := &godo.AppVariableDefinition{
+			Key:   serverlessURLEnv,
+			Value: serverlessURL,
+			Scope: scope,
+			Type:  godo.AppVariableType_General,
+		}
+		switch c := c.(type) {
+		case *godo.AppStaticSiteSpec:
+			c.Envs = append(c.Envs, env)
+		case *godo.AppServiceSpec:
+			c.Envs = append(c.Envs, env)
+		case *godo.AppWorkerSpec:
+			c.Envs = append(c.Envs, env)
+		}
+		return nil
+	})
+}
+
+// hasEnv reports whether envs declares the variable key.
+func hasEnv(envs []*godo.AppVariableDefinition, key string) bool {
+	for _, e := range envs {
+		if e.Key == key {
+			return true
+		}
+	}
+	return false
+}
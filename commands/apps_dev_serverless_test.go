@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetServerlessURL(t *testing.T) {
+	creds := do.ServerlessCredentials{APIHost: "https://faas-nyc1-2ef2e6cc.doserverless.co", Namespace: "fn-123"}
+
+	tests := []struct {
+		name          string
+		path          string
+		trailingSlash bool
+		want          string
+	}{
+		{name: "namespace root", want: "https://faas-nyc1-2ef2e6cc.doserverless.co/api/v1/web/fn-123"},
+		{name: "path prefix", path: "/api", want: "https://faas-nyc1-2ef2e6cc.doserverless.co/api/v1/web/fn-123/api"},
+		{name: "duplicate slashes", path: "//api//v1/", want: "https://faas-nyc1-2ef2e6cc.doserverless.co/api/v1/web/fn-123/api/v1"},
+		{name: "trailing slash", path: "/api", trailingSlash: true, want: "https://faas-nyc1-2ef2e6cc.doserverless.co/api/v1/web/fn-123/api/"},
+		{name: "root with trailing slash", path: "/", trailingSlash: true, want: "https://faas-nyc1-2ef2e6cc.doserverless.co/api/v1/web/fn-123/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+				tm.serverless.EXPECT().ReadCredentials().Return(creds, nil)
+
+				got, err := getServerlessURL(config, tt.path, tt.trailingSlash)
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			})
+		})
+	}
+
+	t.Run("not connected", func(t *testing.T) {
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			tm.serverless.EXPECT().ReadCredentials().Return(do.ServerlessCredentials{}, errors.New("no credentials"))
+
+			got, err := getServerlessURL(config, "/api", false)
+			require.NoError(t, err)
+			assert.Empty(t, got)
+		})
+	})
+
+	for _, p := range []string{"api", "/api?x=1", "/api#top", "/api/../admin"} {
+		t.Run("invalid "+p, func(t *testing.T) {
+			withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+				_, err := getServerlessURL(config, p, false)
+				assert.ErrorContains(t, err, "invalid --serverless-url-path")
+			})
+		})
+	}
+}
+
+func TestAddServerlessURL(t *testing.T) {
+	const url = "https://faas.example.com/api/v1/web/fn-123/api"
+	spec := &godo.AppSpec{
+		StaticSites: []*godo.AppStaticSiteSpec{
+			{Name: "web"},
+			{Name: "docs", Envs: []*godo.AppVariableDefinition{{Key: "SERVERLESS_URL", Value: "https://example.com"}}},
+		},
+		Services: []*godo.AppServiceSpec{{Name: "api"}, {Name: "admin"}},
+		Workers:  []*godo.AppWorkerSpec{{Name: "queue"}},
+		Jobs:     []*godo.AppJobSpec{{Name: "migrate"}},
+	}
+
+	addServerlessURL(spec, url, func(name string) bool { return name == "admin" })
+
+	assert.Equal(t, []*godo.AppVariableDefinition{{
+		Key:   "SERVERLESS_URL",
+		Value: url,
+		Scope: godo.AppVariableScope_BuildTime,
+		Type:  godo.AppVariableType_General,
+	}}, spec.StaticSites[0].Envs)
+	assert.Equal(t, []*godo.AppVariableDefinition{{Key: "SERVERLESS_URL", Value: "https://example.com"}}, spec.StaticSites[1].Envs)
+	runTime := []*godo.AppVariableDefinition{{
+		Key:   "SERVERLESS_URL",
+		Value: url,
+		Scope: godo.AppVariableScope_RunTime,
+		Type:  godo.AppVariableType_General,
+	}}
+	assert.Equal(t, runTime, spec.Services[0].Envs)
+	assert.Empty(t, spec.Services[1].Envs)
+	assert.Equal(t, runTime, spec.Workers[0].Envs)
+	assert.Empty(t, spec.Jobs[0].Envs)
+}
@@ -0,0 +1,340 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/cobra"
+)
+
+// Preview apps are marked with app-level environment variables, since app
+// specs have nowhere else to keep metadata.
+const (
+	appPreviewOfEnv      = "DOCTL_PREVIEW_OF"
+	appPreviewBranchEnv  = "DOCTL_PREVIEW_BRANCH"
+	appPreviewExpiresEnv = "DOCTL_PREVIEW_EXPIRES_AT"
+)
+
+// previewNow returns the current time. It is replaced for testing.
+var previewNow = time.Now
+
+var appNameInvalidRE = regexp.MustCompile(`[^a-z0-9-]+`)
+
+func appsPreview() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "preview",
+			Short: "Display commands for working with preview apps",
+			Long: `The subcommands of ` + "`" + `doctl apps preview` + "`" + ` deploy temporary copies of an app from a git branch, for example to review a pull request.
+
+A preview is a separate app created from your app spec, with every component, including functions components, built from the given branch. Custom domains are removed from the spec, so the preview is reached at its default ` + "`" + `ondigitalocean.app` + "`" + ` URL. Previews are named after the app and the branch, so running ` + "`" + `preview create` + "`" + ` again for the same branch redeploys the existing preview.`,
+		},
+	}
+
+	create := CmdBuilder(cmd, RunAppsPreviewCreate, "create", "Deploy a preview of an app from a branch", `Creates a preview app from the app spec, with all components built from the given branch, or updates it if it already exists. When the deployment is done, the preview's URL is printed, so that CI jobs can post it on the pull request.
+
+The preview expires after `+"`"+`--ttl`+"`"+`. Expired previews of the same app are deleted whenever a preview is created; use `+"`"+`doctl apps preview cleanup`+"`"+` to delete all expired previews.`, Writer, notifyOpt())
+	AddStringFlag(create, doctl.ArgAppSpec, "", "", `Path to an app spec in JSON or YAML format. Set to "-" to read from stdin.`, requiredOpt())
+	addAppSpecVarFlags(create)
+	AddStringFlag(create, doctl.ArgAppPreviewBranch, "", "", "The git branch to deploy", requiredOpt())
+	AddDurationFlag(create, doctl.ArgAppPreviewTTL, "", 72*time.Hour, "How long to keep the preview. Set to 0 to keep it until it is deleted")
+	AddStringFlag(create, doctl.ArgProjectID, "", "", "The ID of the project to assign the preview to. If not provided, the default project is used.")
+	create.Example = `The following example deploys a preview of the app in ` + "`" + `.do/app.yaml` + "`" + ` from the ` + "`" + `feature-x` + "`" + ` branch and saves its URL: PREVIEW_URL=$(doctl apps preview create --spec .do/app.yaml --branch feature-x)`
+
+	list := CmdBuilder(cmd, RunAppsPreviewList, "list", "List preview apps", `Lists the preview apps in your account, with the app and branch they were created from and when they expire.`, Writer,
+		aliasOpt("ls"), displayerType(&displayers.AppPreviews{}))
+	list.Example = `The following example lists preview apps: doctl apps preview list`
+
+	del := CmdBuilder(cmd, RunAppsPreviewDelete, "delete [<preview name|id>]", "Delete a preview app", `Deletes a preview app, given either its name or ID, or the app spec and branch it was created from.
+
+Only apps created by `+"`"+`doctl apps preview create`+"`"+` can be deleted with this command.`, Writer, aliasOpt("d", "rm"))
+	AddStringFlag(del, doctl.ArgAppSpec, "", "", `Path to the app spec the preview was created from. Set to "-" to read from stdin.`)
+	addAppSpecVarFlags(del)
+	AddStringFlag(del, doctl.ArgAppPreviewBranch, "", "", "The git branch the preview was created from")
+	AddBoolFlag(del, doctl.ArgForce, doctl.ArgShortForce, false, "Deletes the preview without a confirmation prompt")
+	del.Example = `The following example deletes the preview of the ` + "`" + `feature-x` + "`" + ` branch when its pull request is closed: doctl apps preview delete --spec .do/app.yaml --branch feature-x --force`
+
+	cleanup := CmdBuilder(cmd, RunAppsPreviewCleanup, "cleanup", "Delete expired preview apps", `Deletes every preview app whose TTL has passed.`, Writer)
+	cleanup.Example = `The following example deletes expired previews, for example from a scheduled CI job: doctl apps preview cleanup`
+
+	return cmd
+}
+
+// previewAppName derives the name of the preview app for a branch. App names
+// are at most 32 characters, so long names are truncated, and a hash of the
+// full names keeps truncated names apart.
+func previewAppName(appName, branch string) string {
+	slug := func(s string) string {
+		s = appNameInvalidRE.ReplaceAllString(strings.ToLower(s), "-")
+		return strings.Trim(s, "-")
+	}
+
+	sum := sha256.Sum256([]byte(appName + "\x00" + branch))
+	suffix := "-" + hex.EncodeToString(sum[:])[:6]
+
+	name := slug(appName) + "-" + slug(branch)
+	if max := 32 - len(suffix); len(name) > max {
+		name = name[:max]
+	}
+	return strings.TrimRight(name, "-") + suffix
+}
+
+// previewSpec turns an app spec into the spec of its preview for a branch.
+func previewSpec(spec *godo.AppSpec, branch string, expiresAt *time.Time) *godo.AppSpec {
+	baseName := spec.Name
+	spec.Name = previewAppName(baseName, branch)
+	// The preview can't take over the app's custom domains.
+	spec.Domains = nil
+
+	godo.ForEachAppSpecComponent(spec, func(c godo.AppBuildableComponentSpec) error {
+		if git := c.GetGit(); git != nil {
+			git.Branch = branch
+		}
+		if gh := c.GetGitHub(); gh != nil {
+			gh.Branch = branch
+		}
+		if gl := c.GetGitLab(); gl != nil {
+			gl.Branch = branch
+		}
+		return nil
+	})
+
+	var envs []*godo.AppVariableDefinition
+	for _, env := range spec.Envs {
+		if !strings.HasPrefix(env.Key, "DOCTL_PREVIEW_") {
+			envs = append(envs, env)
+		}
+	}
+	envs = append(envs,
+		&godo.AppVariableDefinition{Key: appPreviewOfEnv, Value: baseName, Scope: godo.AppVariableScope_RunTime},
+		&godo.AppVariableDefinition{Key: appPreviewBranchEnv, Value: branch, Scope: godo.AppVariableScope_RunTime},
+	)
+	if expiresAt != nil {
+		envs = append(envs, &godo.AppVariableDefinition{Key: appPreviewExpiresEnv, Value: expiresAt.UTC().Format(time.RFC3339), Scope: godo.AppVariableScope_RunTime})
+	}
+	spec.Envs = envs
+
+	return spec
+}
+
+// appPreview describes app if it is a preview app.
+func appPreview(app *godo.App) (displayers.AppPreview, bool) {
+	if app.Spec == nil {
+		return displayers.AppPreview{}, false
+	}
+	p := displayers.AppPreview{ID: app.ID, Name: app.Spec.Name, URL: app.LiveURL}
+	isPreview := false
+	for _, env := range app.Spec.Envs {
+		switch env.Key {
+		case appPreviewOfEnv:
+			p.App = env.Value
+			isPreview = true
+		case appPreviewBranchEnv:
+			p.Branch = env.Value
+		case appPreviewExpiresEnv:
+			if t, err := time.Parse(time.RFC3339, env.Value); err == nil {
+				p.ExpiresAt = &t
+			}
+		}
+	}
+	return p, isPreview
+}
+
+func listAppPreviews(c *CmdConfig) ([]displayers.AppPreview, error) {
+	list, err := c.Apps().List(false)
+	if err != nil {
+		return nil, err
+	}
+	var previews []displayers.AppPreview
+	for _, app := range list {
+		if p, ok := appPreview(app); ok {
+			previews = append(previews, p)
+		}
+	}
+	return previews, nil
+}
+
+// RunAppsPreviewCreate creates or updates the preview app for a branch.
+func RunAppsPreviewCreate(c *CmdConfig) error {
+	specPath, err := c.Doit.GetString(c.NS, doctl.ArgAppSpec)
+	if err != nil {
+		return err
+	}
+	branch, err := c.Doit.GetString(c.NS, doctl.ArgAppPreviewBranch)
+	if err != nil {
+		return err
+	}
+	ttl, err := c.Doit.GetDuration(c.NS, doctl.ArgAppPreviewTTL)
+	if err != nil {
+		return err
+	}
+	projectID, err := c.Doit.GetString(c.NS, doctl.ArgProjectID)
+	if err != nil {
+		return err
+	}
+
+	appSpec, err := readAppSpec(c, specPath)
+	if err != nil {
+		return err
+	}
+	baseName := appSpec.Name
+
+	var expiresAt *time.Time
+	if ttl > 0 {
+		t := previewNow().Add(ttl)
+		expiresAt = &t
+	}
+	spec := previewSpec(appSpec, branch, expiresAt)
+
+	app, err := c.Apps().Create(&godo.AppCreateRequest{Spec: spec, ProjectID: projectID})
+	if gerr, ok := err.(*godo.ErrorResponse); ok && gerr.Response.StatusCode == 409 {
+		notice("Preview %s already exists, updating", spec.Name)
+
+		list, err := c.Apps().List(false)
+		if err != nil {
+			return err
+		}
+		id, err := getIDByName(list, spec.Name)
+		if err != nil {
+			return err
+		}
+		app, err = c.Apps().Update(id, &godo.AppUpdateRequest{Spec: spec})
+		if err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	notice("Deploying preview %s, waiting for it to be running", spec.Name)
+	if err := waitForActiveDeployment(c.Apps(), app.ID, app.GetPendingDeployment().GetID()); err != nil {
+		return fmt.Errorf("preview deployment couldn't enter `running` state: %v", err)
+	}
+	app, err = c.Apps().Get(app.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := deleteExpiredPreviews(c, baseName); err != nil {
+		warn("Could not clean up expired previews: %v", err)
+	}
+
+	c.addNotifyDetail("App", app.ID)
+	c.addNotifyDetail("URL", app.LiveURL)
+	if err := setGitHubOutputs(appGitHubOutputs(app, "")); err != nil {
+		return err
+	}
+	fmt.Fprintln(c.Out, app.LiveURL)
+	return nil
+}
+
+// RunAppsPreviewList lists preview apps.
+func RunAppsPreviewList(c *CmdConfig) error {
+	previews, err := listAppPreviews(c)
+	if err != nil {
+		return err
+	}
+	return c.Display(displayers.AppPreviews(previews))
+}
+
+// RunAppsPreviewDelete deletes a preview app.
+func RunAppsPreviewDelete(c *CmdConfig) error {
+	specPath, err := c.Doit.GetString(c.NS, doctl.ArgAppSpec)
+	if err != nil {
+		return err
+	}
+	branch, err := c.Doit.GetString(c.NS, doctl.ArgAppPreviewBranch)
+	if err != nil {
+		return err
+	}
+	force, err := c.Doit.GetBool(c.NS, doctl.ArgForce)
+	if err != nil {
+		return err
+	}
+
+	var name string
+	switch {
+	case len(c.Args) == 1 && specPath == "" && branch == "":
+		name = c.Args[0]
+	case len(c.Args) == 0 && specPath != "" && branch != "":
+		appSpec, err := readAppSpec(c, specPath)
+		if err != nil {
+			return err
+		}
+		name = previewAppName(appSpec.Name, branch)
+	default:
+		return fmt.Errorf("specify either a preview name or ID, or both --%s and --%s", doctl.ArgAppSpec, doctl.ArgAppPreviewBranch)
+	}
+
+	previews, err := listAppPreviews(c)
+	if err != nil {
+		return err
+	}
+	var preview *displayers.AppPreview
+	for i := range previews {
+		if previews[i].Name == name || previews[i].ID == name {
+			preview = &previews[i]
+		}
+	}
+	if preview == nil {
+		return fmt.Errorf("no preview app named %s", name)
+	}
+
+	if !force && AskForConfirmDelete("preview app", 1, preview.Name) != nil {
+		return errOperationAborted
+	}
+	if err := c.Apps().Delete(preview.ID); err != nil {
+		return err
+	}
+	notice("Preview %s deleted", preview.Name)
+	return nil
+}
+
+// RunAppsPreviewCleanup deletes all expired preview apps.
+func RunAppsPreviewCleanup(c *CmdConfig) error {
+	return deleteExpiredPreviews(c, "")
+}
+
+// deleteExpiredPreviews deletes the expired previews of the named app, or of
+// all apps if appName is empty.
+func deleteExpiredPreviews(c *CmdConfig, appName string) error {
+	previews, err := listAppPreviews(c)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	now := previewNow()
+	for _, p := range previews {
+		if p.ExpiresAt == nil || p.ExpiresAt.After(now) || (appName != "" && p.App != appName) {
+			continue
+		}
+		if err := c.Apps().Delete(p.ID); err != nil {
+			errs = append(errs, fmt.Errorf("deleting %s: %v", p.Name, err))
+			continue
+		}
+		notice("Deleted expired preview %s", p.Name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const testPreviewSpec = `
+name: shop
+domains:
+  - domain: shop.example.com
+envs:
+  - key: LOG_LEVEL
+    value: debug
+services:
+  - name: web
+    github:
+      repo: example/shop
+      branch: main
+functions:
+  - name: api
+    git:
+      repo_clone_url: https://github.com/example/shop.git
+      branch: main
+`
+
+func writePreviewSpec(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testPreviewSpec), 0644))
+	return path
+}
+
+func previewApp(id, name, of, branch string, expiresAt time.Time) *godo.App {
+	return &godo.App{ID: id, Spec: &godo.AppSpec{Name: name, Envs: []*godo.AppVariableDefinition{
+		{Key: appPreviewOfEnv, Value: of},
+		{Key: appPreviewBranchEnv, Value: branch},
+		{Key: appPreviewExpiresEnv, Value: expiresAt.Format(time.RFC3339)},
+	}}}
+}
+
+func TestPreviewAppName(t *testing.T) {
+	name := previewAppName("shop", "feature/Checkout_v2")
+	assert.Regexp(t, `^shop-feature-checkout-v2-[0-9a-f]{6}$`, name)
+	assert.Equal(t, name, previewAppName("shop", "feature/Checkout_v2"))
+
+	long := previewAppName("a-rather-long-app-name", "dependabot/npm_and_yarn/lodash-4.17.21")
+	assert.LessOrEqual(t, len(long), 32)
+	assert.NotEqual(t, long, previewAppName("a-rather-long-app-name", "dependabot/npm_and_yarn/lodash-4.17.20"))
+}
+
+func TestAppsPreviewCreate(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	defer func(f func() time.Time) { previewNow = f }(previewNow)
+	previewNow = func() time.Time { return now }
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		name := previewAppName("shop", "feature-x")
+		pending := &godo.Deployment{ID: "dep", Progress: &godo.DeploymentProgress{SuccessSteps: 2, TotalSteps: 2}}
+
+		tm.apps.EXPECT().Create(gomock.Any()).DoAndReturn(func(req *godo.AppCreateRequest) (*godo.App, error) {
+			spec := req.Spec
+			assert.Equal(t, name, spec.Name)
+			assert.Empty(t, spec.Domains)
+			assert.Equal(t, "feature-x", spec.Services[0].GitHub.Branch)
+			assert.Equal(t, "feature-x", spec.Functions[0].Git.Branch)
+			assert.Equal(t, []*godo.AppVariableDefinition{
+				{Key: "LOG_LEVEL", Value: "debug"},
+				{Key: appPreviewOfEnv, Value: "shop", Scope: godo.AppVariableScope_RunTime},
+				{Key: appPreviewBranchEnv, Value: "feature-x", Scope: godo.AppVariableScope_RunTime},
+				{Key: appPreviewExpiresEnv, Value: "2024-05-02T12:00:00Z", Scope: godo.AppVariableScope_RunTime},
+			}, spec.Envs)
+			return &godo.App{ID: "preview-id", PendingDeployment: pending}, nil
+		})
+		tm.apps.EXPECT().GetDeployment("preview-id", "dep").Return(pending, nil)
+		tm.apps.EXPECT().Get("preview-id").Return(&godo.App{ID: "preview-id", LiveURL: "https://shop-preview.ondigitalocean.app"}, nil)
+
+		// Only the expired preview of the same app is cleaned up.
+		tm.apps.EXPECT().List(false).Return([]*godo.App{
+			{ID: "shop-id", Spec: &godo.AppSpec{Name: "shop"}},
+			previewApp("old", "shop-old", "shop", "old", now.Add(-time.Hour)),
+			previewApp("fresh", "shop-fresh", "shop", "fresh", now.Add(time.Hour)),
+			previewApp("other", "blog-old", "blog", "old", now.Add(-time.Hour)),
+		}, nil)
+		tm.apps.EXPECT().Delete("old").Return(nil)
+
+		var out bytes.Buffer
+		config.Out = &out
+		config.Doit.Set(config.NS, doctl.ArgAppSpec, writePreviewSpec(t))
+		config.Doit.Set(config.NS, doctl.ArgAppPreviewBranch, "feature-x")
+		config.Doit.Set(config.NS, doctl.ArgAppPreviewTTL, 24*time.Hour)
+
+		require.NoError(t, RunAppsPreviewCreate(config))
+		assert.Equal(t, "https://shop-preview.ondigitalocean.app\n", out.String())
+	})
+}
+
+func TestAppsPreviewDelete(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		name := previewAppName("shop", "feature-x")
+		tm.apps.EXPECT().List(false).Return([]*godo.App{
+			previewApp("preview-id", name, "shop", "feature-x", time.Now()),
+		}, nil)
+		tm.apps.EXPECT().Delete("preview-id").Return(nil)
+
+		config.Doit.Set(config.NS, doctl.ArgAppSpec, writePreviewSpec(t))
+		config.Doit.Set(config.NS, doctl.ArgAppPreviewBranch, "feature-x")
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+
+		require.NoError(t, RunAppsPreviewDelete(config))
+	})
+}
+
+func TestAppsPreviewDeleteOnlyPreviews(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.apps.EXPECT().List(false).Return([]*godo.App{
+			{ID: "shop-id", Spec: &godo.AppSpec{Name: "shop"}},
+		}, nil)
+
+		config.Args = []string{"shop"}
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+
+		assert.EqualError(t, RunAppsPreviewDelete(config), "no preview app named shop")
+	})
+}
+
+func TestAppsPreviewCleanup(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.apps.EXPECT().List(false).Return([]*godo.App{
+			previewApp("a", "shop-a", "shop", "a", time.Now().Add(-time.Minute)),
+			previewApp("b", "blog-b", "blog", "b", time.Now().Add(-time.Minute)),
+			previewApp("c", "blog-c", "blog", "c", time.Now().Add(time.Hour)),
+		}, nil)
+		tm.apps.EXPECT().Delete("a").Return(nil)
+		tm.apps.EXPECT().Delete("b").Return(nil)
+
+		require.NoError(t, RunAppsPreviewCleanup(config))
+	})
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/godo"
+)
+
+// appScaling points at the scaling fields of a component of an app spec.
+type appScaling struct {
+	count       *int64
+	size        *string
+	autoscaling *godo.AppAutoscalingSpec
+}
+
+// appComponentScaling returns the scaling fields of the named component of
+// spec. Only services, workers and jobs can be scaled.
+func appComponentScaling(spec *godo.AppSpec, name string) (appScaling, error) {
+	var (
+		scaling appScaling
+		found   godo.AppComponentSpec
+	)
+	_ = spec.ForEachAppComponentSpec(func(component godo.AppComponentSpec) error {
+		if component.GetName() != name {
+			return nil
+		}
+		found = component
+		switch c := component.(type) {
+		case *godo.AppServiceSpec:
+			scaling = appScaling{count: &c.InstanceCount, size: &c.InstanceSizeSlug, autoscaling: c.Autoscaling}
+		case *godo.AppWorkerSpec:
+			scaling = appScaling{count: &c.InstanceCount, size: &c.InstanceSizeSlug, autoscaling: c.Autoscaling}
+		case *godo.AppJobSpec:
+			scaling = appScaling{count: &c.InstanceCount, size: &c.InstanceSizeSlug}
+		}
+		return nil
+	})
+	if found == nil {
+		return scaling, fmt.Errorf("the app has no component named %q", name)
+	}
+	if scaling.count == nil {
+		return scaling, fmt.Errorf("component %s is a %s, which can't be scaled; only services, workers and jobs can", name, found.GetType())
+	}
+	return scaling, nil
+}
+
+// RunAppsScale changes the instance count or size of a component of an app,
+// which deploys it.
+func RunAppsScale(c *CmdConfig) error {
+	if len(c.Args) < 1 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	if len(c.Args) > 1 {
+		return doctl.NewTooManyArgsErr(c.NS)
+	}
+	appID := c.Args[0]
+
+	component, err := c.Doit.GetString(c.NS, doctl.ArgAppComponent)
+	if err != nil {
+		return err
+	}
+	instances, err := c.Doit.GetInt(c.NS, doctl.ArgAppInstances)
+	if err != nil {
+		return err
+	}
+	size, err := c.Doit.GetString(c.NS, doctl.ArgAppInstanceSize)
+	if err != nil {
+		return err
+	}
+	wait, err := c.Doit.GetBool(c.NS, doctl.ArgCommandWait)
+	if err != nil {
+		return err
+	}
+	if instances < 0 {
+		return fmt.Errorf("--%s must be at least 1", doctl.ArgAppInstances)
+	}
+	if instances == 0 && size == "" {
+		return fmt.Errorf("set --%s, --%s or both", doctl.ArgAppInstances, doctl.ArgAppInstanceSize)
+	}
+
+	app, err := c.Apps().Get(appID)
+	if err != nil {
+		return err
+	}
+	if app.Spec == nil {
+		return fmt.Errorf("app %s has no spec", appID)
+	}
+	spec := app.Spec
+	scaling, err := appComponentScaling(spec, component)
+	if err != nil {
+		return err
+	}
+	if instances > 0 && scaling.autoscaling != nil {
+		return fmt.Errorf("component %s autoscales between %d and %d instances, so its instance count can't be set; change its autoscaling in the app spec instead",
+			component, scaling.autoscaling.MinInstanceCount, scaling.autoscaling.MaxInstanceCount)
+	}
+
+	changed := false
+	if instances > 0 && *scaling.count != int64(instances) {
+		*scaling.count = int64(instances)
+		changed = true
+	}
+	if size != "" && *scaling.size != size {
+		*scaling.size = size
+		changed = true
+	}
+	c.addNotifyDetail("App", app.ID)
+	if !changed {
+		notice("Component %s is already scaled that way, so the app isn't deployed", component)
+		return nil
+	}
+
+	app, err = c.Apps().Update(appID, &godo.AppUpdateRequest{Spec: spec})
+	if err != nil {
+		return err
+	}
+	deployment := app.GetPendingDeployment()
+	if deployment == nil {
+		notice("Scaled component %s", component)
+		return nil
+	}
+	c.addNotifyDetail("Deployment", deployment.ID)
+
+	if wait {
+		notice("Scaling component %s, waiting for the deployment to finish", component)
+		if err := waitForActiveDeployment(c.Apps(), app.ID, deployment.ID); err != nil {
+			return fmt.Errorf("the deployment of app %s couldn't enter the active phase: %v", app.ID, err)
+		}
+		if deployment, err = c.Apps().GetDeployment(app.ID, deployment.ID); err != nil {
+			return err
+		}
+		notice("Scaled component %s", component)
+	} else {
+		notice("Scaling component %s in deployment %s", component, deployment.ID)
+	}
+	c.addNotifyDetail("Phase", string(deployment.Phase))
+	return c.Display(displayers.Deployments{deployment})
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scaleTestApp() *godo.App {
+	return &godo.App{ID: "app-1", Spec: &godo.AppSpec{
+		Name: "site",
+		Services: []*godo.AppServiceSpec{
+			{Name: "web", InstanceCount: 2, InstanceSizeSlug: "basic-xs", HTTPPort: 8080},
+			{Name: "api", InstanceSizeSlug: "basic-xs", Autoscaling: &godo.AppAutoscalingSpec{MinInstanceCount: 2, MaxInstanceCount: 6}},
+		},
+		StaticSites: []*godo.AppStaticSiteSpec{{Name: "docs"}},
+	}}
+}
+
+func TestRunAppsScale(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		want := scaleTestApp().Spec
+		want.Services[0].InstanceCount = 4
+		want.Services[0].InstanceSizeSlug = "professional-s"
+
+		tm.apps.EXPECT().Get("app-1").Return(scaleTestApp(), nil)
+		tm.apps.EXPECT().Update("app-1", &godo.AppUpdateRequest{Spec: want}).Return(&godo.App{
+			ID: "app-1", Spec: want, PendingDeployment: &godo.Deployment{ID: "dep-1", Phase: godo.DeploymentPhase_PendingDeploy},
+		}, nil)
+
+		config.Args = []string{"app-1"}
+		config.Doit.Set(config.NS, doctl.ArgAppComponent, "web")
+		config.Doit.Set(config.NS, doctl.ArgAppInstances, 4)
+		config.Doit.Set(config.NS, doctl.ArgAppInstanceSize, "professional-s")
+		require.NoError(t, RunAppsScale(config))
+	})
+}
+
+func TestRunAppsScaleUnchanged(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.apps.EXPECT().Get("app-1").Return(scaleTestApp(), nil)
+
+		config.Args = []string{"app-1"}
+		config.Doit.Set(config.NS, doctl.ArgAppComponent, "web")
+		config.Doit.Set(config.NS, doctl.ArgAppInstances, 2)
+		require.NoError(t, RunAppsScale(config))
+	})
+}
+
+func TestRunAppsScaleInvalid(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = []string{"app-1"}
+		config.Doit.Set(config.NS, doctl.ArgAppComponent, "web")
+		assert.ErrorContains(t, RunAppsScale(config), "set --instances, --size or both")
+
+		for component, want := range map[string]string{
+			"api":     "component api autoscales between 2 and 6 instances",
+			"docs":    "component docs is a static_site, which can't be scaled",
+			"missing": `the app has no component named "missing"`,
+		} {
+			tm.apps.EXPECT().Get("app-1").Return(scaleTestApp(), nil)
+			config.Doit.Set(config.NS, doctl.ArgAppComponent, component)
+			config.Doit.Set(config.NS, doctl.ArgAppInstances, 3)
+			assert.ErrorContains(t, RunAppsScale(config), want, component)
+		}
+	})
+}
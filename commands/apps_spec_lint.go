@@ -0,0 +1,159 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/godo"
+)
+
+const (
+	lintSeverityError   = "error"
+	lintSeverityWarning = "warning"
+
+	lintRuleMultipleSources    = "multiple-sources"
+	lintRuleMissingHealthCheck = "missing-health-check"
+	lintRuleBuildTimeSecret    = "build-time-secret"
+	lintRuleTooManyInstances   = "too-many-instances"
+	lintAppLevelComponentName  = "(app)"
+)
+
+// RunAppsSpecLint checks an app spec for issues that are valid but likely mistakes.
+// It works offline and fails if it finds any errors, or any findings at all with --strict.
+func RunAppsSpecLint(c *CmdConfig) error {
+	if len(c.Args) < 1 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	appSpec, err := readAppSpec(c, c.Args[0])
+	if err != nil {
+		return err
+	}
+	strict, err := c.Doit.GetBool(c.NS, doctl.ArgAppSpecLintStrict)
+	if err != nil {
+		return err
+	}
+	maxInstances, err := c.Doit.GetInt(c.NS, doctl.ArgAppSpecLintMaxInstances)
+	if err != nil {
+		return err
+	}
+
+	findings := lintAppSpec(appSpec, int64(maxInstances))
+	if err := c.Display(findings); err != nil {
+		return err
+	}
+
+	var errs, warnings int
+	for _, f := range findings {
+		if f.Severity == lintSeverityError {
+			errs++
+		} else {
+			warnings++
+		}
+	}
+	if errs > 0 || (strict && warnings > 0) {
+		return fmt.Errorf("app spec lint found %d error(s) and %d warning(s)", errs, warnings)
+	}
+	return nil
+}
+
+// lintAppSpec returns the findings for spec, in the order its components appear.
+func lintAppSpec(spec *godo.AppSpec, maxInstances int64) displayers.AppSpecLintFindings {
+	findings := displayers.AppSpecLintFindings{}
+	add := func(component, rule, severity, format string, a ...any) {
+		findings = append(findings, displayers.AppSpecLintFinding{
+			Component: component,
+			Rule:      rule,
+			Severity:  severity,
+			Message:   fmt.Sprintf(format, a...),
+		})
+	}
+
+	lintEnvs := func(component string, envs []*godo.AppVariableDefinition) {
+		for _, env := range envs {
+			if env.Type != godo.AppVariableType_Secret {
+				continue
+			}
+			switch env.Scope {
+			case "", godo.AppVariableScope_Unset, godo.AppVariableScope_RunAndBuildTime:
+				add(component, lintRuleBuildTimeSecret, lintSeverityWarning,
+					"secret %s is also available at build time; set its scope to RUN_TIME unless the build needs it", env.Key)
+			}
+		}
+	}
+	lintEnvs(lintAppLevelComponentName, spec.Envs)
+
+	_ = spec.ForEachAppComponentSpec(func(component godo.AppComponentSpec) error {
+		name := component.GetName()
+
+		if c, ok := component.(godo.AppBuildableComponentSpec); ok {
+			var sources []string
+			if c.GetGit() != nil {
+				sources = append(sources, "git")
+			}
+			if c.GetGitHub() != nil {
+				sources = append(sources, "github")
+			}
+			if c.GetGitLab() != nil {
+				sources = append(sources, "gitlab")
+			}
+			if c, ok := component.(godo.AppContainerComponentSpec); ok && c.GetImage() != nil {
+				sources = append(sources, "image")
+			}
+			if len(sources) > 1 {
+				add(name, lintRuleMultipleSources, lintSeverityError,
+					"%s component sets more than one source (%s); only one is deployed", component.GetType(), strings.Join(sources, ", "))
+			}
+
+			// Static sites are only built, so their variables are never exposed at run time.
+			if component.GetType() != godo.AppComponentTypeStaticSite {
+				lintEnvs(name, c.GetEnvs())
+			}
+		}
+
+		switch c := component.(type) {
+		case *godo.AppServiceSpec:
+			if c.HealthCheck == nil {
+				add(name, lintRuleMissingHealthCheck, lintSeverityWarning,
+					"service has no health check; deployments are considered healthy as soon as the container starts")
+			}
+			lintInstances(add, name, c.InstanceCount, c.Autoscaling, maxInstances)
+		case *godo.AppWorkerSpec:
+			lintInstances(add, name, c.InstanceCount, c.Autoscaling, maxInstances)
+		case *godo.AppJobSpec:
+			lintInstances(add, name, c.InstanceCount, nil, maxInstances)
+		}
+		return nil
+	})
+
+	return findings
+}
+
+func lintInstances(add func(component, rule, severity, format string, a ...any), component string, count int64, autoscaling *godo.AppAutoscalingSpec, maxInstances int64) {
+	if maxInstances <= 0 {
+		return
+	}
+	if count > maxInstances {
+		add(component, lintRuleTooManyInstances, lintSeverityWarning,
+			"instance count %d is more than %d", count, maxInstances)
+	}
+	if autoscaling != nil && autoscaling.MaxInstanceCount > maxInstances {
+		add(component, lintRuleTooManyInstances, lintSeverityWarning,
+			"autoscaling max instance count %d is more than %d", autoscaling.MaxInstanceCount, maxInstances)
+	}
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintAppSpec(t *testing.T) {
+	github := &godo.GitHubSourceSpec{Repo: "digitalocean/sample-golang", Branch: "main"}
+	spec := &godo.AppSpec{
+		Name: "test",
+		Envs: []*godo.AppVariableDefinition{
+			{Key: "API_KEY", Type: godo.AppVariableType_Secret},
+			{Key: "BUILD_TOKEN", Type: godo.AppVariableType_Secret, Scope: godo.AppVariableScope_BuildTime},
+		},
+		Services: []*godo.AppServiceSpec{
+			{
+				Name:          "web",
+				GitHub:        github,
+				InstanceCount: 20,
+				HealthCheck:   &godo.AppServiceSpecHealthCheck{HTTPPath: "/health"},
+				Envs: []*godo.AppVariableDefinition{
+					{Key: "DB_PASSWORD", Type: godo.AppVariableType_Secret, Scope: godo.AppVariableScope_RunTime},
+				},
+			},
+			{
+				Name:   "api",
+				GitHub: github,
+				Image:  &godo.ImageSourceSpec{RegistryType: godo.ImageSourceSpecRegistryType_DOCR, Repository: "api"},
+			},
+		},
+		Workers: []*godo.AppWorkerSpec{{
+			Name:        "worker",
+			GitHub:      github,
+			Autoscaling: &godo.AppAutoscalingSpec{MinInstanceCount: 1, MaxInstanceCount: 50},
+		}},
+		StaticSites: []*godo.AppStaticSiteSpec{{
+			Name:   "static",
+			GitHub: github,
+			Envs: []*godo.AppVariableDefinition{
+				{Key: "SITE_TOKEN", Type: godo.AppVariableType_Secret},
+			},
+		}},
+		Functions: []*godo.AppFunctionsSpec{{
+			Name:   "functions",
+			Git:    &godo.GitSourceSpec{RepoCloneURL: "https://github.com/digitalocean/sample-functions.git"},
+			GitHub: github,
+		}},
+	}
+
+	findings := lintAppSpec(spec, 10)
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Component+" "+f.Rule+" "+f.Severity)
+	}
+	assert.Equal(t, []string{
+		"(app) build-time-secret warning",
+		"web too-many-instances warning",
+		"api multiple-sources error",
+		"api missing-health-check warning",
+		"worker too-many-instances warning",
+		"functions multiple-sources error",
+	}, got)
+
+	t.Run("instance check disabled", func(t *testing.T) {
+		for _, f := range lintAppSpec(spec, 0) {
+			assert.NotEqual(t, lintRuleTooManyInstances, f.Rule)
+		}
+	})
+}
+
+func TestRunAppsSpecLint(t *testing.T) {
+	spec := `name: test
+services:
+- name: web
+  github:
+    repo: digitalocean/sample-golang
+    branch: main
+`
+
+	t.Run("warnings", func(t *testing.T) {
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			config.Args = append(config.Args, testTempFile(t, []byte(spec)))
+			config.Doit.Set(config.NS, doctl.ArgAppSpecLintMaxInstances, 10)
+			var buf bytes.Buffer
+			config.Out = &buf
+
+			err := RunAppsSpecLint(config)
+			require.NoError(t, err)
+			assert.Contains(t, buf.String(), lintRuleMissingHealthCheck)
+		})
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			config.Args = append(config.Args, testTempFile(t, []byte(spec)))
+			config.Doit.Set(config.NS, doctl.ArgAppSpecLintStrict, true)
+			config.Out = &bytes.Buffer{}
+
+			err := RunAppsSpecLint(config)
+			require.EqualError(t, err, "app spec lint found 0 error(s) and 1 warning(s)")
+		})
+	})
+}
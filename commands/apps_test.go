@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/url"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -34,11 +35,14 @@ func TestAppsCommand(t *testing.T) {
 		"logs",
 		"propose",
 		"spec",
+		"preview",
+		"deployment",
 		"tier",
 		"list-alerts",
 		"update-alert-destinations",
 		"list-buildpacks",
 		"upgrade-buildpack",
+		"scale",
 	)
 }
 
@@ -549,6 +553,7 @@ func TestRunAppSpecValidate(t *testing.T) {
 		name       string
 		spec       string
 		schemaOnly bool
+		vars       map[string]string
 		mock       func(tm *tcMocks)
 
 		wantError string
@@ -566,6 +571,13 @@ func TestRunAppSpecValidate(t *testing.T) {
 			schemaOnly: true,
 			wantOut:    validYAMLSpec,
 		},
+		{
+			name:       "valid yaml with vars",
+			spec:       strings.ReplaceAll(validYAMLSpec, "branch: main", "branch: ${BRANCH}"),
+			schemaOnly: true,
+			vars:       map[string]string{"BRANCH": "main"},
+			wantOut:    validYAMLSpec,
+		},
 		{
 			name: "valid json with ProposeApp req",
 			spec: validJSONSpec,
@@ -593,6 +605,7 @@ func TestRunAppSpecValidate(t *testing.T) {
 			withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
 				config.Args = append(config.Args, testTempFile(t, []byte(tc.spec)))
 				config.Doit.Set(config.NS, doctl.ArgSchemaOnly, tc.schemaOnly)
+				config.Doit.Set(config.NS, doctl.ArgAppSpecVar, tc.vars)
 				var buf bytes.Buffer
 				config.Out = &buf
 
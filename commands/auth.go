@@ -143,6 +143,8 @@ To create new contexts, see the help for `+"`"+`doctl auth init`+"`"+`.`, Writer
 	AddStringFlag(cmdAuthList, doctl.ArgFormat, "", "", "Columns for output in a comma-separated list. Possible values: `text`")
 	cmdAuthList.Example = `The following example lists the available contexts with the ` + "`" + `--format` + "`" + ` flag: doctl auth list`
 
+	cmd.AddCommand(authToken())
+
 	return cmd
 }
 
@@ -172,7 +174,7 @@ func RunAuthInit(retrieveUserTokenFunc func() (string, error)) func(c *CmdConfig
 
 		// need to initial the godo client since we've changed the configuration.
 		if err := c.initServices(c); err != nil {
-			return fmt.Errorf("Unable to initialize DigitalOcean API client with new token: %s", err)
+			return fmt.Errorf("Unable to initialize DigitalOcean API client with new token: %w", err)
 		}
 
 		server, err := c.Doit.GetString(c.NS, doctl.ArgTokenValidationServer)
@@ -286,27 +288,46 @@ func RunAuthSwitch(c *CmdConfig) error {
 }
 
 func writeConfig() error {
+	if viper.GetBool(doctl.ArgNoConfig) {
+		return errNoConfig
+	}
+	if viper.GetBool(doctl.ArgConfigReadOnly) {
+		return errConfigReadOnly
+	}
+
 	f, err := cfgFileWriter()
 	if err != nil {
 		return err
 	}
 
-	defer f.Close()
+	settings := withoutDirectoryConfig(viper.AllSettings())
+	if w, ok := f.(*atomicConfigWriter); ok {
+		// Another doctl process may have changed the config file since it was
+		// read, so only this command's changes are applied on top of it.
+		current, err := w.current()
+		if err != nil {
+			f.Close()
+			return err
+		}
+		settings = mergeConfigChanges(current, configAtLoad, settings)
+	}
 
-	b, err := yaml.Marshal(viper.AllSettings())
+	b, err := yaml.Marshal(settings)
 	if err != nil {
+		f.Close()
 		return errors.New("Unable to encode configuration to YAML format.")
 	}
 
 	_, err = f.Write(b)
 	if err != nil {
+		f.Close()
 		return errors.New("Unable to write configuration.")
 	}
 
-	return nil
+	return f.Close()
 }
 
-// defaultConfigFileWriter returns a writer to a newly created config.yaml file in the default config home.
+// defaultConfigFileWriter returns a writer that replaces the config file when it is closed.
 // When using the default config file path the default config home directory will be created; Otherwise
 // the custom config home directory must exist and be writable to the user issuing the auth command.
 func defaultConfigFileWriter() (io.WriteCloser, error) {
@@ -317,15 +338,11 @@ func defaultConfigFileWriter() (io.WriteCloser, error) {
 		configHome()
 	}
 
-	f, err := os.Create(cfgFile)
+	w, err := newAtomicConfigWriter(cfgFile)
 	if err != nil {
 		return nil, err
 	}
-	if err := os.Chmod(cfgFile, 0600); err != nil {
-		return nil, err
-	}
-
-	return f, nil
+	return w, nil
 }
 
 func getAuthContextList() []string {
@@ -0,0 +1,181 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// authToken creates the auth token commands.
+func authToken() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "token",
+			Short: "Display commands for creating and rotating API tokens",
+			Long: `The subcommands of ` + "`" + `doctl auth token` + "`" + ` create personal access tokens with custom scopes, so automation can run with only the permissions it needs.
+
+Scopes take the form ` + "`" + `<action>:<resource>` + "`" + `, for example ` + "`" + `read:droplet` + "`" + ` or ` + "`" + `create:domain` + "`" + `. See https://docs.digitalocean.com/reference/api/scopes/ for the full list. A token can never have more permissions than the token used to create it.`,
+		},
+	}
+
+	cmdTokenCreate := CmdBuilder(cmd, RunAuthTokenCreate, "create <name>", "Create a scoped API token", `Creates a personal access token with the given scopes and prints it. The token's secret is only shown once, so store it somewhere safe.`, Writer,
+		displayerType(&displayers.Token{}))
+	AddStringSliceFlag(cmdTokenCreate, doctl.ArgTokenScopes, "", []string{}, "The scopes to grant the token, for example `read:droplet,update:domain`", requiredOpt())
+	AddStringFlag(cmdTokenCreate, doctl.ArgTokenExpiry, "", "", "How long the token is valid, for example `30d` or `12h`. By default, the token never expires.")
+	cmdTokenCreate.Example = `The following example creates a token that can read Droplets and manage DNS records for 30 days: doctl auth token create ci-dns --scopes read:droplet,read:domain,update:domain --expiry 30d`
+
+	cmdTokenRotate := CmdBuilder(cmd, RunAuthTokenRotate, "rotate", "Replace the token of the current context with a new one", `Creates a new personal access token, stores it in the current authentication context, and revokes the token it replaces.
+
+Unless `+"`"+`--scopes`+"`"+` is set, the new token gets the same scopes as the current one. Set `+"`"+`--keep-old`+"`"+` to leave the previous token valid, for example while other systems still use it.`, Writer,
+		displayerType(&displayers.Token{}))
+	AddStringFlag(cmdTokenRotate, doctl.ArgTokenName, "", "", "The name of the new token. Defaults to `doctl-<context>-<date>`.")
+	AddStringSliceFlag(cmdTokenRotate, doctl.ArgTokenScopes, "", []string{}, "The scopes to grant the new token. Defaults to the scopes of the current token.")
+	AddStringFlag(cmdTokenRotate, doctl.ArgTokenExpiry, "", "", "How long the new token is valid, for example `90d`. By default, the token never expires.")
+	AddBoolFlag(cmdTokenRotate, doctl.ArgTokenKeepOld, "", false, "Do not revoke the previous token")
+	AddStringFlag(cmdTokenRotate, doctl.ArgTokenValidationServer, "", TokenValidationServer, "The server used to look up and revoke tokens")
+	cmdTokenRotate.Example = `The following example rotates the token of the ` + "`" + `ci` + "`" + ` context, giving the new token a 90 day expiry: doctl auth token rotate --context ci --expiry 90d`
+
+	return cmd
+}
+
+// RunAuthTokenCreate creates a scoped personal access token.
+func RunAuthTokenCreate(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+
+	tcr, err := buildTokenCreateRequest(c, c.Args[0])
+	if err != nil {
+		return err
+	}
+	if len(tcr.Scopes) == 0 {
+		return doctl.NewMissingArgsErr(fmt.Sprintf("%s.%s", c.NS, doctl.ArgTokenScopes))
+	}
+
+	t, err := c.OAuth().CreateToken(tcr)
+	if err != nil {
+		return err
+	}
+
+	return c.Display(&displayers.Token{Tokens: []do.Token{*t}})
+}
+
+// RunAuthTokenRotate replaces the token of the current context.
+func RunAuthTokenRotate(c *CmdConfig) error {
+	name, err := c.Doit.GetString(c.NS, doctl.ArgTokenName)
+	if err != nil {
+		return err
+	}
+	server, err := c.Doit.GetString(c.NS, doctl.ArgTokenValidationServer)
+	if err != nil {
+		return err
+	}
+	keepOld, err := c.Doit.GetBool(c.NS, doctl.ArgTokenKeepOld)
+	if err != nil {
+		return err
+	}
+
+	context := Context
+	if context == "" {
+		context = viper.GetString("context")
+	}
+	if name == "" {
+		name = fmt.Sprintf("doctl-%s-%s", context, time.Now().Format("2006-01-02"))
+	}
+
+	tcr, err := buildTokenCreateRequest(c, name)
+	if err != nil {
+		return err
+	}
+
+	oauth := c.OAuth()
+	if len(tcr.Scopes) == 0 {
+		info, err := oauth.TokenInfo(server)
+		if err != nil {
+			return fmt.Errorf("looking up the scopes of the current token: %w", err)
+		}
+		tcr.Scopes = info.Scopes
+	}
+
+	t, err := oauth.CreateToken(tcr)
+	if err != nil {
+		return err
+	}
+
+	oldToken := c.getContextAccessToken()
+	c.setContextAccessToken(t.AccessToken)
+	if err := writeConfig(); err != nil {
+		return fmt.Errorf("the new token was created but could not be saved: %w", err)
+	}
+	notice("Stored the new token in context %s", context)
+
+	if !keepOld && oldToken != "" {
+		if err := oauth.RevokeToken(oldToken, server); err != nil {
+			warn("Could not revoke the previous token: %v", err)
+		}
+	}
+
+	return c.Display(&displayers.Token{Tokens: []do.Token{*t}})
+}
+
+func buildTokenCreateRequest(c *CmdConfig, name string) (*do.TokenCreateRequest, error) {
+	scopes, err := c.Doit.GetStringSlice(c.NS, doctl.ArgTokenScopes)
+	if err != nil {
+		return nil, err
+	}
+	expiry, err := c.Doit.GetString(c.NS, doctl.ArgTokenExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	tcr := &do.TokenCreateRequest{Name: name, Scopes: scopes}
+	if expiry != "" {
+		d, err := parseTokenExpiry(expiry)
+		if err != nil {
+			return nil, err
+		}
+		seconds := int(d.Seconds())
+		tcr.ExpirySeconds = &seconds
+	}
+	return tcr, nil
+}
+
+// parseTokenExpiry parses a duration that may also be given in days, like "30d".
+func parseTokenExpiry(s string) (time.Duration, error) {
+	var (
+		d   time.Duration
+		err error
+	)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid expiry %q, must be a positive duration such as 30d or 12h", s)
+	}
+	return d, nil
+}
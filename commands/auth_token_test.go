@@ -0,0 +1,99 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAuthTokenCommand(t *testing.T) {
+	cmd := authToken()
+	assert.NotNil(t, cmd)
+	assertCommandNames(t, cmd, "create", "rotate")
+}
+
+func TestAuthTokenCreate(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		expiry := 30 * 24 * 60 * 60
+		tcr := &do.TokenCreateRequest{
+			Name:          "ci",
+			Scopes:        []string{"read:droplet", "update:domain"},
+			ExpirySeconds: &expiry,
+		}
+		tm.oauth.EXPECT().CreateToken(tcr).Return(&do.Token{ID: 1, Name: "ci", AccessToken: "dop_v1_new"}, nil)
+
+		config.Args = append(config.Args, "ci")
+		config.Doit.Set(config.NS, doctl.ArgTokenScopes, []string{"read:droplet", "update:domain"})
+		config.Doit.Set(config.NS, doctl.ArgTokenExpiry, "30d")
+
+		err := RunAuthTokenCreate(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestAuthTokenRotate(t *testing.T) {
+	cfw := cfgFileWriter
+	defer func() { cfgFileWriter = cfw }()
+	cfgFileWriter = func() (io.WriteCloser, error) { return &nopWriteCloser{Writer: io.Discard}, nil }
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		viper.Set(doctl.ArgAccessToken, "dop_v1_old")
+		defer viper.Set(doctl.ArgAccessToken, "")
+
+		var stored string
+		config.setContextAccessToken = func(token string) { stored = token }
+
+		tm.oauth.EXPECT().TokenInfo("https://example.com").Return(&do.OAuthTokenInfo{Scopes: []string{"read:droplet"}}, nil)
+		tm.oauth.EXPECT().CreateToken(gomock.Any()).DoAndReturn(func(tcr *do.TokenCreateRequest) (*do.Token, error) {
+			assert.Equal(t, "ci-next", tcr.Name)
+			assert.Equal(t, []string{"read:droplet"}, tcr.Scopes)
+			assert.Nil(t, tcr.ExpirySeconds)
+			return &do.Token{ID: 2, Name: tcr.Name, AccessToken: "dop_v1_new"}, nil
+		})
+		tm.oauth.EXPECT().RevokeToken("dop_v1_old", "https://example.com").Return(nil)
+
+		config.Doit.Set(config.NS, doctl.ArgTokenName, "ci-next")
+		config.Doit.Set(config.NS, doctl.ArgTokenScopes, []string{})
+		config.Doit.Set(config.NS, doctl.ArgTokenExpiry, "")
+		config.Doit.Set(config.NS, doctl.ArgTokenKeepOld, false)
+		config.Doit.Set(config.NS, doctl.ArgTokenValidationServer, "https://example.com")
+
+		err := RunAuthTokenRotate(config)
+		assert.NoError(t, err)
+		assert.Equal(t, "dop_v1_new", stored)
+	})
+}
+
+func TestParseTokenExpiry(t *testing.T) {
+	d, err := parseTokenExpiry("30d")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, d)
+
+	d, err = parseTokenExpiry("12h")
+	assert.NoError(t, err)
+	assert.Equal(t, 12*time.Hour, d)
+
+	_, err = parseTokenExpiry("-1d")
+	assert.Error(t, err)
+	_, err = parseTokenExpiry("soon")
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/pkg/bulk"
+)
+
+// The operations of the bulk commands. Those of tags and records are followed
+// by the tag or domain.
+const (
+	dropletCreateOperation = "compute droplet create"
+	dropletDeleteOperation = "compute droplet delete"
+	tagApplyOperation      = "tag apply"
+	tagRemoveOperation     = "tag remove"
+	recordDeleteOperation  = "compute domain records delete"
+)
+
+// checkpointDir returns the directory the checkpoints of bulk operations are
+// saved to. It is replaced for testing.
+var checkpointDir = func() string {
+	return filepath.Join(configHome(), "checkpoints")
+}
+
+// bulkOut is where the way to resume an unfinished bulk operation is printed.
+var bulkOut io.Writer = os.Stderr
+
+// newBulkExecutor returns the executor of a bulk operation on items. With
+// --resume, it continues the checkpointed operation instead, whose items are
+// those of the interrupted run. Operations on a single item aren't
+// checkpointed.
+func newBulkExecutor(c *CmdConfig, operation string, items []string) (*bulk.Executor, error) {
+	resume, err := c.Doit.GetString(c.NS, doctl.ArgResume)
+	if err != nil {
+		return nil, err
+	}
+	if resume != "" {
+		return bulk.Resume(resume, operation)
+	}
+
+	path := ""
+	if len(items) > 1 {
+		name := fmt.Sprintf("%s-%d.json", strings.ReplaceAll(c.NS, ".", "-"), time.Now().UnixNano())
+		path = filepath.Join(checkpointDir(), name)
+	}
+	return bulk.New(path, operation, items)
+}
+
+// runBulk runs a bulk operation until it is done or interrupted. The
+// checkpoint of an operation that is done is removed; otherwise, the way to
+// resume it is printed.
+func runBulk(e *bulk.Executor, run func(ctx context.Context) error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx); err != nil {
+		if e.Saved() {
+			fmt.Fprintf(bulkOut, "%d of %d items are not done. To retry them, run the command again with: --%s %s\n",
+				len(e.Remaining()), len(e.Items()), doctl.ArgResume, e.Path())
+		}
+		return err
+	}
+	if err := e.Remove(); err != nil {
+		warn("Could not remove the checkpoint %s: %v", e.Path(), err)
+	}
+	return nil
+}
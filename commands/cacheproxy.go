@@ -0,0 +1,130 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/pkg/cacheproxy"
+	"github.com/spf13/cobra"
+)
+
+// CacheProxy creates the cacheproxy commands.
+func CacheProxy() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:     "cacheproxy",
+			Short:   "Run a caching proxy of the API for CI fleets",
+			Long:    `The subcommands of ` + "`" + `doctl cacheproxy` + "`" + ` run a local proxy that caches the responses of read-only API requests, so that many parallel jobs reading the same resources don't exhaust the account's rate limit.`,
+			GroupID: configureDoctlGroup,
+		},
+	}
+
+	cmdServe := cmdBuilderWithInit(cmd, RunCacheProxyServe, "serve", "Serve a caching proxy of the API",
+		`Serves a proxy of the API that caches the responses of GET requests. Point doctl, or any other API client, at it with `+"`"+`--api-url`+"`"+`, for example `+"`"+`doctl compute droplet list --api-url http://127.0.0.1:8089`+"`"+`.
+
+A cached response is served without calling the API for `+"`"+`--ttl`+"`"+`. After that, it is revalidated with its ETag, and served again if the API reports it is unchanged. Concurrent requests for the same response wait for a single API request. When a token has fewer than `+"`"+`--rate-limit-reserve`+"`"+` requests left, according to the API's rate limit headers, or the API answers with 429 Too Many Requests or a server error, cached responses are served even though they are stale.
+
+Other requests, such as creates and deletes, are forwarded to the API uncached, and they remove the cached responses of their token, which they may have changed. The proxy never adds credentials: each request is sent with its own token, and cached responses are only served to requests with the same token. Responses report how they were served in the `+"`"+`X-Doctl-Cache`+"`"+` header, and `+"`"+`/-/stats`+"`"+` reports the proxy's hit counts as JSON.
+
+The proxy speaks plain HTTP, so tokens sent to it are not encrypted. Keep it on a loopback address, or on a network only the jobs can reach.`,
+		Writer, false)
+	AddStringFlag(cmdServe, doctl.ArgCacheProxyListen, "", "127.0.0.1:8089", "The host and port to listen on")
+	AddStringFlag(cmdServe, doctl.ArgCacheProxyUpstream, "", defaultAPIURL, "The API to forward requests to")
+	AddDurationFlag(cmdServe, doctl.ArgCacheProxyTTL, "", 30*time.Second, "How long a response is served before it is revalidated with the API")
+	AddIntFlag(cmdServe, doctl.ArgCacheProxyMaxEntries, "", 10000, "The number of responses to cache. The least recently used are removed first")
+	AddIntFlag(cmdServe, doctl.ArgCacheProxyReserve, "", 250, "Serve stale responses instead of revalidating them when a token has fewer requests than this left in its rate limit")
+	cmdServe.Example = `The following example serves a caching proxy that revalidates responses after a minute: doctl cacheproxy serve --listen 127.0.0.1:8089 --ttl 1m`
+
+	return cmd
+}
+
+// RunCacheProxyServe serves a caching proxy of the API until it is
+// interrupted.
+func RunCacheProxyServe(c *CmdConfig) error {
+	listen, err := c.Doit.GetString(c.NS, doctl.ArgCacheProxyListen)
+	if err != nil {
+		return err
+	}
+	upstream, err := c.Doit.GetString(c.NS, doctl.ArgCacheProxyUpstream)
+	if err != nil {
+		return err
+	}
+	ttl, err := c.Doit.GetDuration(c.NS, doctl.ArgCacheProxyTTL)
+	if err != nil {
+		return err
+	}
+	maxEntries, err := c.Doit.GetInt(c.NS, doctl.ArgCacheProxyMaxEntries)
+	if err != nil {
+		return err
+	}
+	reserve, err := c.Doit.GetInt(c.NS, doctl.ArgCacheProxyReserve)
+	if err != nil {
+		return err
+	}
+
+	u, err := url.Parse(upstream)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid upstream %q: it must be an http or https URL", upstream)
+	}
+	if maxEntries < 1 {
+		return fmt.Errorf("--%s must be at least 1", doctl.ArgCacheProxyMaxEntries)
+	}
+
+	l, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+	if host, _, err := net.SplitHostPort(listen); err == nil {
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			warn("The proxy listens on %s, so tokens sent to it over plain HTTP can be read on the network.", listen)
+		}
+	}
+
+	srv := &http.Server{
+		Handler: cacheproxy.New(cacheproxy.Options{
+			Upstream:   u,
+			TTL:        ttl,
+			MaxEntries: maxEntries,
+			Reserve:    reserve,
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+	go func() {
+		<-sigs
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}()
+
+	fmt.Fprintf(c.Out, "Caching %s on http://%s\n", u, l.Addr())
+	if err := srv.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheProxyServeInvalidFlags(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Doit.Set(config.NS, doctl.ArgCacheProxyUpstream, "api.digitalocean.com")
+		config.Doit.Set(config.NS, doctl.ArgCacheProxyMaxEntries, 10)
+		err := RunCacheProxyServe(config)
+		assert.EqualError(t, err, `invalid upstream "api.digitalocean.com": it must be an http or https URL`)
+
+		config.Doit.Set(config.NS, doctl.ArgCacheProxyUpstream, "https://api.digitalocean.com")
+		config.Doit.Set(config.NS, doctl.ArgCacheProxyMaxEntries, 0)
+		err = RunCacheProxyServe(config)
+		assert.EqualError(t, err, "--max-entries must be at least 1")
+	})
+}
@@ -250,7 +250,7 @@ func RunCDNDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if force || AskForConfirmDelete("CDN", 1) == nil {
+	if force || AskForConfirmDelete("CDN", 1, c.Args[0]) == nil {
 		id := c.Args[0]
 		return c.CDNs().Delete(id)
 	}
@@ -221,7 +221,7 @@ func RunCertificateDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if force || AskForConfirmDelete("certificate", 1) == nil {
+	if force || AskForConfirmDelete("certificate", 1, cID) == nil {
 		cs := c.Certificates()
 		if err := cs.Delete(cID); err != nil {
 			return err
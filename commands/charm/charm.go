@@ -11,6 +11,13 @@ import (
 var (
 	// ErrCanceled represents a user-initiated cancellation.
 	ErrCanceled = fmt.Errorf("canceled")
+
+	// ErrPromptsDisabled is returned by prompts when PromptsDisabled is set.
+	ErrPromptsDisabled = fmt.Errorf("this command needs to prompt for input, which isn't possible here; pass the input with flags instead")
+
+	// PromptsDisabled makes prompts fail instead of reading from the terminal,
+	// for when doctl has no user to prompt, such as in doctl serve.
+	PromptsDisabled bool
 )
 
 // Style is a styled component.
@@ -113,6 +113,9 @@ var resultTemplate = `
 
 // Prompt renders the prompt on the screen.
 func (p *Prompt) Prompt() (Choice, error) {
+	if charm.PromptsDisabled {
+		return No, charm.ErrPromptsDisabled
+	}
 	input := confirmation.New(p.text, fromChoice(p.choice))
 	tfs := template.Funcs(charm.Colors)
 	tfs["RenderResult"] = func(choice bool) bool {
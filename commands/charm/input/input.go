@@ -73,6 +73,9 @@ func init() {
 }
 
 func (i *Input) Prompt() (string, error) {
+	if charm.PromptsDisabled {
+		return "", charm.ErrPromptsDisabled
+	}
 	in := textinput.New(i.text)
 	in.Placeholder = i.placeholder
 	in.InitialValue = i.initialValue
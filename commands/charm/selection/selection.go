@@ -1,6 +1,9 @@
 package selection
 
-import "github.com/erikgeiser/promptkit/selection"
+import (
+	"github.com/digitalocean/doctl/commands/charm"
+	"github.com/erikgeiser/promptkit/selection"
+)
 
 type Selection struct {
 	options   []string
@@ -35,6 +38,9 @@ func WithPrompt(prompt string) Option {
 }
 
 func (s *Selection) Select() (string, error) {
+	if charm.PromptsDisabled {
+		return "", charm.ErrPromptsDisabled
+	}
 	sp := selection.New(s.prompt, s.options)
 	if !s.filtering {
 		sp.Filter = nil
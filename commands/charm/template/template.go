@@ -17,6 +17,9 @@ import (
 
 type FuncMap = template.FuncMap
 
+// Translate returns the text to render for a template's content. It is replaced to customize or translate output.
+var Translate = func(content string) string { return content }
+
 // Funcs returns template helpers.
 func Funcs(colors charm.ColorScheme) template.FuncMap {
 	return template.FuncMap{
@@ -90,7 +93,7 @@ func Funcs(colors charm.ColorScheme) template.FuncMap {
 func Render(w io.Writer, content string, data any) error {
 	tmpl := template.New("tmpl").Funcs(Funcs(charm.Colors))
 	var err error
-	tmpl, err = tmpl.Parse(content)
+	tmpl, err = tmpl.Parse(Translate(content))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,283 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/cobra"
+)
+
+// Cleanup creates the cleanup commands hierarchy.
+func Cleanup() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:     "cleanup",
+			Short:   "Display commands for finding unused resources",
+			Long:    "The subcommands of `doctl cleanup` find resources on your account that appear to be unused, and optionally delete them.",
+			GroupID: manageResourcesGroup,
+		},
+	}
+
+	cmdCleanupScan := CmdBuilder(cmd, RunCleanupScan, "scan", "Find orphaned resources", `Scans your account for resources that appear to be unused:
+
+- volumes that are not attached to a Droplet
+- reserved IPs that are not assigned to a Droplet
+- snapshots older than `+"`"+`--snapshot-age`+"`"+`
+- load balancers without Droplets, either listed or selected by tag
+- A and AAAA records in your DigitalOcean domains that point to an address no Droplet, reserved IP, or load balancer on the account uses
+
+DNS records for hosts outside of DigitalOcean are reported too, so review the list before deleting anything. Global load balancers, which route to endpoints rather than Droplets, are not reported.
+
+By default, the command only lists what it finds. With `+"`"+`--delete`+"`"+`, it asks whether to delete each resource in turn; with `+"`"+`--yes`+"`"+`, it deletes all of them without asking.`, Writer,
+		displayerType(&displayers.OrphanedResources{}))
+	AddDurationFlag(cmdCleanupScan, doctl.ArgCleanupSnapshotAge, "", 90*24*time.Hour, "Report snapshots older than this")
+	AddBoolFlag(cmdCleanupScan, doctl.ArgCleanupDelete, "", false, "Prompt to delete each resource that is found")
+	AddBoolFlag(cmdCleanupScan, doctl.ArgYes, "y", false, "Delete every resource that is found without prompting")
+	cmdCleanupScan.Example = `The following example lists unused resources, reporting snapshots older than 30 days: doctl cleanup scan --snapshot-age 720h`
+
+	return cmd
+}
+
+// cleanupNow returns the current time. It is replaced for testing.
+var cleanupNow = time.Now
+
+// orphan is an orphaned resource together with the call that deletes it.
+type orphan struct {
+	displayers.OrphanedResource
+	remove func() error
+}
+
+// RunCleanupScan finds orphaned resources and optionally deletes them.
+func RunCleanupScan(c *CmdConfig) error {
+	age, err := c.Doit.GetDuration(c.NS, doctl.ArgCleanupSnapshotAge)
+	if err != nil {
+		return err
+	}
+	del, err := c.Doit.GetBool(c.NS, doctl.ArgCleanupDelete)
+	if err != nil {
+		return err
+	}
+	yes, err := c.Doit.GetBool(c.NS, doctl.ArgYes)
+	if err != nil {
+		return err
+	}
+	if del && !yes && !Interactive {
+		return fmt.Errorf("`--%s` prompts for each resource; use `--%s` to delete without prompting", doctl.ArgCleanupDelete, doctl.ArgYes)
+	}
+
+	orphans, err := findOrphans(c, age, cleanupNow())
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	if del || yes {
+		for i, o := range orphans {
+			if !yes && AskForConfirm(fmt.Sprintf("delete %s %s (%s)", strings.ReplaceAll(o.Type, "_", " "), o.ID, o.Reason)) != nil {
+				continue
+			}
+			if err := o.remove(); err != nil {
+				warn("Could not delete %s %s: %v", o.Type, o.ID, err)
+				failed++
+				continue
+			}
+			orphans[i].Deleted = true
+		}
+	}
+
+	item := &displayers.OrphanedResources{Resources: make([]displayers.OrphanedResource, 0, len(orphans))}
+	for _, o := range orphans {
+		item.Resources = append(item.Resources, o.OrphanedResource)
+	}
+	if err := c.Display(item); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d resource(s) could not be deleted", failed)
+	}
+	return nil
+}
+
+// findOrphans lists the resources on the account that appear to be unused.
+func findOrphans(c *CmdConfig, snapshotAge time.Duration, now time.Time) ([]orphan, error) {
+	droplets, err := c.Droplets().List()
+	if err != nil {
+		return nil, err
+	}
+	volumes, err := c.Volumes().List()
+	if err != nil {
+		return nil, err
+	}
+	ips, err := c.ReservedIPs().List()
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := c.Snapshots().List()
+	if err != nil {
+		return nil, err
+	}
+	lbs, err := c.LoadBalancers().List()
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []orphan
+
+	for _, v := range volumes {
+		if len(v.DropletIDs) > 0 {
+			continue
+		}
+		id := v.ID
+		orphans = append(orphans, orphan{
+			OrphanedResource: displayers.OrphanedResource{
+				Type: "volume", ID: id, Name: v.Name, Region: regionSlug(v.Region), Reason: "not attached to a Droplet",
+			},
+			remove: func() error { return c.Volumes().DeleteVolume(id) },
+		})
+	}
+
+	// Addresses that DNS records may legitimately point to.
+	inUse := map[string]bool{}
+	for _, d := range droplets {
+		for _, ip := range dropletAddresses(d) {
+			inUse[ip] = true
+		}
+	}
+
+	for _, ip := range ips {
+		inUse[ip.IP] = true
+		if ip.Droplet != nil {
+			continue
+		}
+		addr := ip.IP
+		orphans = append(orphans, orphan{
+			OrphanedResource: displayers.OrphanedResource{
+				Type: "reserved_ip", ID: addr, Region: regionSlug(ip.Region), Reason: "not assigned to a Droplet",
+			},
+			remove: func() error { return c.ReservedIPs().Delete(addr) },
+		})
+	}
+
+	for _, s := range snapshots {
+		created, err := time.Parse(time.RFC3339, s.Created)
+		if err != nil || now.Sub(created) < snapshotAge {
+			continue
+		}
+		id := s.ID
+		orphans = append(orphans, orphan{
+			OrphanedResource: displayers.OrphanedResource{
+				Type: "snapshot", ID: id, Name: s.Name, Region: strings.Join(s.Regions, ","),
+				Reason: fmt.Sprintf("created %d days ago", int(now.Sub(created).Hours()/24)),
+			},
+			remove: func() error { return c.Snapshots().Delete(id) },
+		})
+	}
+
+	for _, lb := range lbs {
+		inUse[lb.IP] = true
+		if strings.EqualFold(lb.Type, "GLOBAL") {
+			continue
+		}
+
+		reason := ""
+		if lb.Tag != "" {
+			if !dropletsHaveTag(droplets, lb.Tag) {
+				reason = fmt.Sprintf("no Droplets have tag %s", lb.Tag)
+			}
+		} else if len(lb.DropletIDs) == 0 {
+			reason = "has no Droplets"
+		}
+		if reason == "" {
+			continue
+		}
+		id := lb.ID
+		orphans = append(orphans, orphan{
+			OrphanedResource: displayers.OrphanedResource{
+				Type: "load_balancer", ID: id, Name: lb.Name, Region: regionSlug(lb.Region), Reason: reason,
+			},
+			remove: func() error { return c.LoadBalancers().Delete(id) },
+		})
+	}
+
+	records, err := danglingDNSRecords(c, inUse)
+	if err != nil {
+		return nil, err
+	}
+	orphans = append(orphans, records...)
+
+	return orphans, nil
+}
+
+// danglingDNSRecords finds A and AAAA records that point to addresses not in inUse.
+func danglingDNSRecords(c *CmdConfig, inUse map[string]bool) ([]orphan, error) {
+	ds := c.Domains()
+	domains, err := ds.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []orphan
+	for _, domain := range domains {
+		records, err := ds.Records(domain.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range records {
+			if (r.Type != "A" && r.Type != "AAAA") || inUse[r.Data] {
+				continue
+			}
+			domainName, record := domain.Name, r.DomainRecord
+			orphans = append(orphans, orphan{
+				OrphanedResource: displayers.OrphanedResource{
+					Type: "dns_record", ID: strconv.Itoa(r.ID), Name: r.Name + "." + domainName,
+					Reason: fmt.Sprintf("%s record points to %s, which no Droplet, reserved IP, or load balancer uses", r.Type, r.Data),
+				},
+				remove: func() error {
+					if err := ds.DeleteRecord(domainName, record.ID); err != nil {
+						return err
+					}
+					recordDNSChange(domainName, dnsChangeDelete, record, nil)
+					return nil
+				},
+			})
+		}
+	}
+	return orphans, nil
+}
+
+func dropletsHaveTag(droplets do.Droplets, tag string) bool {
+	for _, d := range droplets {
+		if contains(d.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func regionSlug(r *godo.Region) string {
+	if r == nil {
+		return ""
+	}
+	return r.Slug
+}
@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanupCommand(t *testing.T) {
+	cmd := Cleanup()
+	assert.NotNil(t, cmd)
+	assertCommandNames(t, cmd, "scan")
+}
+
+func expectCleanupScan(tm *tcMocks) {
+	tm.droplets.EXPECT().List().Return(do.Droplets{testDroplet, {Droplet: &godo.Droplet{ID: 2, Tags: []string{"web"}}}}, nil)
+	tm.volumes.EXPECT().List().Return([]do.Volume{
+		{Volume: &godo.Volume{ID: "vol-attached", DropletIDs: []int{1}}},
+		{Volume: &godo.Volume{ID: "vol-free", Name: "data", Region: &godo.Region{Slug: "nyc3"}}},
+	}, nil)
+	tm.reservedIPs.EXPECT().List().Return(do.ReservedIPs{
+		{ReservedIP: &godo.ReservedIP{IP: "198.51.100.1", Droplet: &godo.Droplet{ID: 1}}},
+		{ReservedIP: &godo.ReservedIP{IP: "198.51.100.2", Region: &godo.Region{Slug: "nyc3"}}},
+	}, nil)
+	tm.snapshots.EXPECT().List().Return(do.Snapshots{
+		{Snapshot: &godo.Snapshot{ID: "snap-new", Created: "2024-05-01T00:00:00Z"}},
+		{Snapshot: &godo.Snapshot{ID: "snap-old", Name: "before-upgrade", Created: "2024-01-01T00:00:00Z", Regions: []string{"nyc3"}}},
+	}, nil)
+	tm.loadBalancers.EXPECT().List().Return(do.LoadBalancers{
+		{LoadBalancer: &godo.LoadBalancer{ID: "lb-ids", IP: "192.0.2.1", DropletIDs: []int{1}}},
+		{LoadBalancer: &godo.LoadBalancer{ID: "lb-tag", IP: "192.0.2.2", Tag: "web"}},
+		{LoadBalancer: &godo.LoadBalancer{ID: "lb-empty", Name: "old-lb", IP: "192.0.2.3"}},
+		{LoadBalancer: &godo.LoadBalancer{ID: "lb-global", Type: "GLOBAL"}},
+	}, nil)
+	tm.domains.EXPECT().List().Return(do.Domains{{Domain: &godo.Domain{Name: "example.com"}}}, nil)
+	tm.domains.EXPECT().Records("example.com").Return(do.DomainRecords{
+		{DomainRecord: &godo.DomainRecord{ID: 1, Type: "A", Name: "www", Data: "8.8.8.8"}},
+		{DomainRecord: &godo.DomainRecord{ID: 2, Type: "A", Name: "lb", Data: "192.0.2.1"}},
+		{DomainRecord: &godo.DomainRecord{ID: 3, Type: "A", Name: "ip", Data: "198.51.100.2"}},
+		{DomainRecord: &godo.DomainRecord{ID: 4, Type: "A", Name: "gone", Data: "203.0.113.5"}},
+		{DomainRecord: &godo.DomainRecord{ID: 5, Type: "CNAME", Name: "alias", Data: "www.example.com."}},
+	}, nil)
+}
+
+func TestFindOrphans(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		expectCleanupScan(tm)
+
+		now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+		orphans, err := findOrphans(config, 90*24*time.Hour, now)
+		require.NoError(t, err)
+
+		var found []string
+		for _, o := range orphans {
+			found = append(found, o.Type+":"+o.ID)
+		}
+		assert.Equal(t, []string{
+			"volume:vol-free",
+			"reserved_ip:198.51.100.2",
+			"snapshot:snap-old",
+			"load_balancer:lb-empty",
+			"dns_record:4",
+		}, found)
+		assert.Equal(t, "created 130 days ago", orphans[2].Reason)
+		assert.Equal(t, "gone.example.com", orphans[4].Name)
+	})
+}
+
+func TestCleanupScanYes(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		cleanupNow = func() time.Time { return time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC) }
+		defer func() { cleanupNow = time.Now }()
+
+		expectCleanupScan(tm)
+		tm.volumes.EXPECT().DeleteVolume("vol-free").Return(nil)
+		tm.reservedIPs.EXPECT().Delete("198.51.100.2").Return(nil)
+		tm.snapshots.EXPECT().Delete("snap-old").Return(nil)
+		tm.loadBalancers.EXPECT().Delete("lb-empty").Return(nil)
+		tm.domains.EXPECT().DeleteRecord("example.com", 4).Return(nil)
+
+		config.Doit.Set(config.NS, doctl.ArgCleanupSnapshotAge, 90*24*time.Hour)
+		config.Doit.Set(config.NS, doctl.ArgYes, true)
+
+		err := RunCleanupScan(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestCleanupScanListOnly(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		expectCleanupScan(tm)
+
+		config.Doit.Set(config.NS, doctl.ArgCleanupSnapshotAge, 24*time.Hour)
+
+		err := RunCleanupScan(config)
+		assert.NoError(t, err)
+	})
+}
@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
 
 	"github.com/spf13/cobra"
 )
@@ -33,6 +34,18 @@ type Command struct {
 
 	fmtCols []string
 
+	// displayer is the type of the command's structured output, if it has any.
+	displayer displayers.Displayable
+
+	// runner runs the command, and initCmd is whether it needs API access.
+	// They are kept so that commands can be run without exec'ing doctl; see serve.go.
+	runner  CmdRunner
+	initCmd bool
+
+	// topLevel is set for commands built without a parent. Their flags are
+	// named before they are added to the root, so their namespace is their name alone.
+	topLevel bool
+
 	childCommands []*Command
 
 	// overrideNS specifies a namespace to use in config.
@@ -78,7 +91,7 @@ func cmdBuilderWithInit(parent *Command, cr CmdRunner, cliText, shortdesc string
 		Long:  longdesc,
 	}
 
-	c := &Command{Command: cc}
+	c := &Command{Command: cc, runner: cr, initCmd: initCmd, topLevel: parent == nil}
 
 	if parent != nil {
 		parent.AddCommand(c)
@@ -87,20 +100,30 @@ func cmdBuilderWithInit(parent *Command, cr CmdRunner, cliText, shortdesc string
 	for _, co := range options {
 		co(c)
 	}
+	// Options may wrap the runner, such as notifyOpt.
+	cr = c.runner
 
 	// This must be defined after the options have been applied
 	// so that changes made by the options are accessible here.
 	c.Command.Run = func(cmd *cobra.Command, args []string) {
+		checkErr(checkPolicy(c, args))
+
+		w, closeOutput, err := jqOutput(out)
+		checkErr(err)
+
 		c, err := NewCmdConfig(
 			cmdNS(c),
 			&doctl.LiveConfig{},
-			out,
+			w,
 			args,
 			initCmd,
 		)
 		checkErr(err)
 
 		err = cr(c)
+		if cerr := closeOutput(); err == nil {
+			err = cerr
+		}
 		checkErr(err)
 	}
 
@@ -109,6 +132,10 @@ func cmdBuilderWithInit(parent *Command, cr CmdRunner, cliText, shortdesc string
 			strings.Join(cols, "`"+", "+"`"))
 		AddStringFlag(c, doctl.ArgFormat, "", "", formatHelp)
 		AddBoolFlag(c, doctl.ArgNoHeader, "", false, "Return raw data with no headers")
+
+		if c.Name() == "list" {
+			AddStringFlag(c, doctl.ArgDiffSince, "", "", "Path to the JSON output of an earlier run of this command, e.g. `--output json > state.json`. Prints the resources added, removed, and changed since then instead of the full list")
+		}
 	}
 
 	return c
@@ -16,11 +16,14 @@ package commands
 import (
 	"fmt"
 	"io"
+	"os"
 
 	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/commands/displayers"
 	"github.com/digitalocean/doctl/do"
 	"github.com/digitalocean/doctl/internal/apps/builder"
+	"github.com/digitalocean/doctl/pkg/notify"
+	"github.com/digitalocean/godo"
 	"github.com/spf13/viper"
 )
 
@@ -37,6 +40,13 @@ type CmdConfig struct {
 	removeContext           func(string) error
 	componentBuilderFactory builder.ComponentBuilderFactory
 
+	// notifyDetails are added to the notifications sent with --notify.
+	notifyDetails []notify.Field
+
+	// godoClient is the API client the services use, once they are
+	// initialized.
+	godoClient *godo.Client
+
 	// services
 	Keys              func() do.KeysService
 	Sizes             func() do.SizesService
@@ -72,6 +82,7 @@ type CmdConfig struct {
 	Monitoring        func() do.MonitoringService
 	Serverless        func() do.ServerlessService
 	OAuth             func() do.OAuthService
+	Teams             func() do.TeamsService
 }
 
 // NewCmdConfig creates an instance of a CmdConfig.
@@ -87,8 +98,9 @@ func NewCmdConfig(ns string, dc doctl.Config, out io.Writer, args []string, init
 			accessToken := c.getContextAccessToken()
 			godoClient, err := c.Doit.GetGodoClient(Trace, true, accessToken)
 			if err != nil {
-				return fmt.Errorf("Unable to initialize DigitalOcean API client: %s", err)
+				return fmt.Errorf("Unable to initialize DigitalOcean API client: %w", err)
 			}
+			c.godoClient = godoClient
 
 			c.Keys = func() do.KeysService { return do.NewKeysService(godoClient) }
 			c.Sizes = func() do.SizesService { return do.NewSizesService(godoClient) }
@@ -123,14 +135,27 @@ func NewCmdConfig(ns string, dc doctl.Config, out io.Writer, args []string, init
 			c.Apps = func() do.AppsService { return do.NewAppsService(godoClient) }
 			c.Monitoring = func() do.MonitoringService { return do.NewMonitoringService(godoClient) }
 			c.Serverless = func() do.ServerlessService {
-				return do.NewServerlessService(godoClient, getServerlessDirectory(), accessToken)
+				return do.NewServerlessService(godoClient, getServerlessDirectory(), accessToken, serverlessPluginTimeout())
 			}
 			c.OAuth = func() do.OAuthService { return do.NewOAuthService(godoClient) }
+			c.Teams = func() do.TeamsService { return do.NewTeamsService(godoClient) }
 
 			return nil
 		},
 
 		getContextAccessToken: func() string {
+			// A token given on the command line always wins, then the token of a
+			// context given on the command line, then DIGITALOCEAN_ACCESS_TOKEN,
+			// and finally the token of the config file's current context.
+			if flag := DoitCmd.PersistentFlags().Lookup(doctl.ArgAccessToken); flag != nil && flag.Changed {
+				return Token
+			}
+			if Context == "" {
+				if token, ok := os.LookupEnv(accessTokenEnv); ok && token != "" {
+					return token
+				}
+			}
+
 			context := Context
 			if context == "" {
 				context = viper.GetString("context")
@@ -204,6 +229,66 @@ type CmdRunner func(*CmdConfig) error
 
 // Display displays the output from a command.
 func (c *CmdConfig) Display(d displayers.Displayable) error {
+	diffSince, err := c.Doit.GetString(c.NS, doctl.ArgDiffSince)
+	if err != nil {
+		return err
+	}
+	if diffSince != "" {
+		return c.displayDiff(diffSince, d)
+	}
+
+	dc, err := c.displayer(d)
+	if err != nil {
+		return err
+	}
+	return dc.Display()
+}
+
+// DisplayPages returns a displayer for the pages of a long list, which are
+// displayed as they are fetched rather than once the list is complete. empty
+// is an empty list of the items, and is displayed if no page is. The
+// displayer must be closed once all of the pages are displayed.
+//
+// Pages can't be displayed as a diff, so with --diff-since, the pages are
+// collected with add and displayed as one list when the displayer is closed.
+func (c *CmdConfig) DisplayPages(empty displayers.Displayable, add func(page displayers.Displayable)) (*PageDisplayer, error) {
+	diffSince, err := c.Doit.GetString(c.NS, doctl.ArgDiffSince)
+	if err != nil {
+		return nil, err
+	}
+	if diffSince != "" {
+		return &PageDisplayer{
+			display: func(page displayers.Displayable) error { add(page); return nil },
+			close:   func() error { return c.displayDiff(diffSince, empty) },
+		}, nil
+	}
+
+	dc, err := c.displayer(empty)
+	if err != nil {
+		return nil, err
+	}
+	pd := &displayers.PageDisplayer{Displayer: *dc}
+	return &PageDisplayer{display: pd.DisplayPage, close: pd.Close}, nil
+}
+
+// PageDisplayer displays the pages of a long list. See CmdConfig.DisplayPages.
+type PageDisplayer struct {
+	display func(displayers.Displayable) error
+	close   func() error
+}
+
+// DisplayPage displays a page of the list.
+func (p *PageDisplayer) DisplayPage(page displayers.Displayable) error {
+	return p.display(page)
+}
+
+// Close finishes the output.
+func (p *PageDisplayer) Close() error {
+	return p.close()
+}
+
+// displayer returns a displayer for d with the output options of the command.
+func (c *CmdConfig) displayer(d displayers.Displayable) (*displayers.Displayer, error) {
 	dc := &displayers.Displayer{
 		Item: d,
 		Out:  c.Out,
@@ -211,19 +296,22 @@ func (c *CmdConfig) Display(d displayers.Displayable) error {
 
 	columnList, err := c.Doit.GetString(c.NS, doctl.ArgFormat)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	withHeaders, err := c.Doit.GetBool(c.NS, doctl.ArgNoHeader)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	dc.NoHeaders = withHeaders
 	dc.ColumnList = columnList
-	dc.OutputType = Output
+	dc.OutputType = viper.GetString(doctl.ArgOutput)
+	if dc.Template, err = outputTemplate(c.NS); err != nil {
+		return nil, err
+	}
 
-	return dc.Display()
+	return dc, nil
 }
 
 // An urner implements the URN method, wihich returns a valid uniform resource
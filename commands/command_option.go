@@ -33,6 +33,7 @@ func aliasOpt(aliases ...string) cmdOption {
 func displayerType(d displayers.Displayable) cmdOption {
 	return func(c *Command) {
 		c.fmtCols = d.Cols()
+		c.displayer = d
 	}
 }
 
@@ -15,6 +15,7 @@ package commands
 
 import (
 	"io"
+	"path/filepath"
 	"testing"
 
 	"github.com/digitalocean/doctl"
@@ -184,12 +185,32 @@ type tcMocks struct {
 	appBuilder            *builder.MockComponentBuilder
 	appDockerEngineClient *builder.MockDockerEngineClient
 	oauth                 *domocks.MockOAuthService
+	teams                 *domocks.MockTeamsService
 }
 
 func withTestClient(t *testing.T, tFn testFn) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	journalDir := t.TempDir()
+	origJournalPath := dnsJournalPath
+	dnsJournalPath = func() string { return filepath.Join(journalDir, "dns-journal.jsonl") }
+	defer func() { dnsJournalPath = origJournalPath }()
+
+	origCheckpointDir := checkpointDir
+	checkpointDir = func() string { return filepath.Join(journalDir, "checkpoints") }
+	defer func() { checkpointDir = origCheckpointDir }()
+
+	origCronDir := cronDir
+	cronDir = func() string { return filepath.Join(journalDir, "cron") }
+	defer func() { cronDir = origCronDir }()
+
+	// Services are mocked, so commands must not replace them with services
+	// whose API client doesn't retry.
+	origRetryMax := RetryMax
+	RetryMax = 0
+	defer func() { RetryMax = origRetryMax }()
+
 	tm := &tcMocks{
 		account:               domocks.NewMockAccountService(ctrl),
 		actions:               domocks.NewMockActionsService(ctrl),
@@ -230,6 +251,7 @@ func withTestClient(t *testing.T, tFn testFn) {
 		appBuilder:            builder.NewMockComponentBuilder(ctrl),
 		appDockerEngineClient: builder.NewMockDockerEngineClient(ctrl),
 		oauth:                 domocks.NewMockOAuthService(ctrl),
+		teams:                 domocks.NewMockTeamsService(ctrl),
 	}
 
 	testConfig := doctl.NewTestConfig()
@@ -285,6 +307,7 @@ func withTestClient(t *testing.T, tFn testFn) {
 		Monitoring:        func() do.MonitoringService { return tm.monitoring },
 		Serverless:        func() do.ServerlessService { return tm.serverless },
 		OAuth:             func() do.OAuthService { return tm.oauth },
+		Teams:             func() do.TeamsService { return tm.teams },
 	}
 
 	tFn(config, tm)
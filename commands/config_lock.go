@@ -0,0 +1,221 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// errConfigReadOnly is returned when a command needs to change the config file
+// while --config-read-only is set.
+var errConfigReadOnly = errors.New("the config file is read-only (--config-read-only is set)")
+
+// errNoConfig is returned when a command needs to change the config file
+// while --no-config is set.
+var errNoConfig = errors.New("the config file is disabled (--no-config is set)")
+
+var (
+	// configLockTimeout is how long to wait for another doctl process to
+	// finish writing the config file. It is replaced for testing.
+	configLockTimeout = 10 * time.Second
+
+	// staleConfigLockAge is the age after which a lock is assumed to have been
+	// left behind by a process that died while holding it.
+	staleConfigLockAge = time.Minute
+)
+
+// configAtLoad holds the contents of the config file when it was read, so
+// that only the settings a command has changed since are written back.
+var configAtLoad map[string]any
+
+// atomicConfigWriter holds the config lock from when it is created until it is
+// closed, so the config file can be re-read, merged, and replaced without
+// another doctl process changing it in between. The buffered contents are
+// written to a temporary file that is renamed over the config file, so other
+// processes reading the config see either the old or the new file.
+type atomicConfigWriter struct {
+	path   string
+	buf    bytes.Buffer
+	unlock func()
+}
+
+// newAtomicConfigWriter takes the config lock and returns a writer that
+// replaces the config file at path when it is closed.
+func newAtomicConfigWriter(path string) (*atomicConfigWriter, error) {
+	unlock, err := lockConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return &atomicConfigWriter{path: path, unlock: unlock}, nil
+}
+
+// current returns the settings in the config file as it is now.
+func (w *atomicConfigWriter) current() (map[string]any, error) {
+	return readConfigFile(w.path)
+}
+
+// readConfigFile returns the settings in the config file at path, or none if
+// it doesn't exist.
+func readConfigFile(path string) (map[string]any, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]any{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	settings := map[string]any{}
+	if err := yaml.Unmarshal(b, &settings); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+func (w *atomicConfigWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+// Close replaces the config file with what was written, if anything, and
+// releases the config lock.
+func (w *atomicConfigWriter) Close() error {
+	defer w.unlock()
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	return writeFileAtomic(w.path, w.buf.Bytes(), 0600)
+}
+
+// mergeConfigChanges applies the changes between the settings as they were
+// loaded and as they are now to the current contents of the config file, so
+// that changes made by another doctl process in the meantime are kept.
+func mergeConfigChanges(current, loaded, settings map[string]any) map[string]any {
+	keys := map[string]bool{}
+	for key := range loaded {
+		keys[key] = true
+	}
+	for key := range settings {
+		keys[key] = true
+	}
+
+	for key := range keys {
+		value, inSettings := settings[key]
+		_, inLoaded := loaded[key]
+		loadedMap, loadedIsMap := configMap(loaded[key])
+		settingsMap, settingsIsMap := configMap(value)
+
+		switch {
+		case (settingsIsMap && (loadedIsMap || !inLoaded)) || (loadedIsMap && !inSettings):
+			// Maps such as auth-contexts are merged key by key.
+			currentMap, ok := configMap(current[key])
+			if !ok {
+				currentMap = map[string]any{}
+			}
+			if merged := mergeConfigChanges(currentMap, loadedMap, settingsMap); len(merged) > 0 {
+				current[key] = merged
+			} else {
+				delete(current, key)
+			}
+		case !inSettings:
+			delete(current, key)
+		case !reflect.DeepEqual(value, loaded[key]):
+			current[key] = value
+		}
+	}
+	return current
+}
+
+// configMap returns v as a map with string keys, if it is a map.
+func configMap(v any) (map[string]any, bool) {
+	if v == nil {
+		return nil, false
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map {
+		return nil, false
+	}
+	m := make(map[string]any, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		m[fmt.Sprint(iter.Key().Interface())] = iter.Value().Interface()
+	}
+	return m, true
+}
+
+// lockConfig takes the lock on the config file at path, waiting for other
+// processes holding it. The returned function releases the lock.
+func lockConfig(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(configLockTimeout)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("locking the config file: %w", err)
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleConfigLockAge {
+			os.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errTimeout("timed out waiting for another doctl process to release %s; remove it if no other doctl process is running", lockPath)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// writeFileAtomic replaces the file at path with data by renaming a temporary
+// file over it.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, perm)
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestAtomicConfigWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0644))
+
+	w, err := newAtomicConfigWriter(path)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("access-token: new\n"))
+	require.NoError(t, err)
+
+	// Nothing is written until the writer is closed.
+	got, _ := os.ReadFile(path)
+	assert.Equal(t, "old", string(got))
+
+	require.NoError(t, w.Close())
+	got, _ = os.ReadFile(path)
+	assert.Equal(t, "access-token: new\n", string(got))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	assert.Len(t, entries, 1, "temporary and lock files are removed")
+}
+
+func TestAtomicConfigWriterConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w, err := newAtomicConfigWriter(path)
+			if !assert.NoError(t, err) {
+				return
+			}
+			fmt.Fprintf(w, "writer: %d\n", i)
+			assert.NoError(t, w.Close())
+		}(i)
+	}
+	wg.Wait()
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Regexp(t, `^writer: \d\n$`, string(got))
+}
+
+func TestWriteConfigKeepsConcurrentChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("auth-contexts:\n  a: token-a\n  b: token-b\ncontext: a\n"), 0600))
+
+	origConfig, origLoaded := viper.GetString("config"), configAtLoad
+	t.Cleanup(func() {
+		viper.Set("config", origConfig)
+		viper.Set("auth-contexts", nil)
+		viper.Set("context", nil)
+		configAtLoad = origLoaded
+	})
+	viper.Set("config", path)
+	viper.Set("auth-contexts", map[string]any{"a": "token-a", "b": "token-b"})
+	viper.Set("context", "a")
+	configAtLoad, _ = readConfigFile(path)
+
+	// Another process adds a context and switches to it after this one has
+	// read the config file.
+	require.NoError(t, os.WriteFile(path, []byte("auth-contexts:\n  a: token-a\n  b: token-b\n  c: token-c\ncontext: c\nother: kept\n"), 0600))
+
+	// This process removes a context and changes a token.
+	viper.Set("auth-contexts", map[string]string{"a": "token-a2"})
+	require.NoError(t, writeConfig())
+
+	var got map[string]any
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(b, &got))
+	assert.Equal(t, map[string]any{"a": "token-a2", "c": "token-c"}, got["auth-contexts"])
+	assert.Equal(t, "c", got["context"])
+	assert.Equal(t, "kept", got["other"])
+}
+
+func TestMergeConfigChanges(t *testing.T) {
+	current := map[string]any{"output": "json", "x": 1, "m": map[any]any{"k": "v"}}
+	loaded := map[string]any{"x": 1, "y": 2, "m": map[string]any{"k": "v"}}
+	settings := map[string]any{"x": 3, "m": map[string]any{"k": "v", "l": "w"}}
+
+	assert.Equal(t, map[string]any{
+		"output": "json",
+		"x":      3,
+		"m":      map[string]any{"k": "v", "l": "w"},
+	}, mergeConfigChanges(current, loaded, settings))
+}
+
+func TestLockConfig(t *testing.T) {
+	origTimeout, origStale := configLockTimeout, staleConfigLockAge
+	t.Cleanup(func() { configLockTimeout, staleConfigLockAge = origTimeout, origStale })
+	configLockTimeout = 100 * time.Millisecond
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	unlock, err := lockConfig(path)
+	require.NoError(t, err)
+
+	_, err = lockConfig(path)
+	assert.ErrorContains(t, err, "timed out waiting")
+
+	unlock()
+	unlock, err = lockConfig(path)
+	require.NoError(t, err)
+
+	// A lock left behind by a dead process is taken over.
+	staleConfigLockAge = 0
+	unlock2, err := lockConfig(path)
+	require.NoError(t, err)
+	unlock2()
+	unlock()
+}
+
+func TestWriteConfigReadOnly(t *testing.T) {
+	viper.Set(doctl.ArgConfigReadOnly, true)
+	defer viper.Set(doctl.ArgConfigReadOnly, false)
+
+	cfw := cfgFileWriter
+	defer func() { cfgFileWriter = cfw }()
+	cfgFileWriter = func() (io.WriteCloser, error) {
+		t.Fatal("the config file should not be opened")
+		return nil, nil
+	}
+
+	assert.Equal(t, errConfigReadOnly, writeConfig())
+}
@@ -1,20 +1,62 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/charm"
 	"github.com/digitalocean/doctl/commands/charm/confirm"
+	"github.com/digitalocean/doctl/commands/charm/input"
 	"github.com/digitalocean/doctl/commands/charm/template"
+	"github.com/fatih/color"
+	"github.com/spf13/pflag"
 )
 
+// confirmTarget is a resource listed in the summary shown before a destructive
+// command asks for confirmation.
+type confirmTarget struct {
+	ID      string
+	Name    string
+	Details string
+}
+
+// promptTypedConfirm asks the user to type a value. It is replaced for testing.
+var promptTypedConfirm = func(prompt string) (string, error) {
+	return input.New(prompt).Prompt()
+}
+
+// normalizeYesFlag lets --yes stand for --force on the commands that have a
+// --force flag, so that every destructive command accepts either.
+func normalizeYesFlag(f *pflag.FlagSet, name string) pflag.NormalizedName {
+	if name == doctl.ArgYes && f.Lookup(doctl.ArgForce) != nil {
+		return doctl.ArgForce
+	}
+	return pflag.NormalizedName(name)
+}
+
+// confirmationRequired is returned by commands that need confirmation when
+// they can't prompt for it. Commands run by doctl serve have nobody to show a
+// warning to, so it is their error instead.
+func confirmationRequired() error {
+	const msg = "Requires confirmation. Use the `--force` (or `--yes`) flag to continue without confirmation."
+	if charm.PromptsDisabled {
+		return errors.New(msg)
+	}
+	warn(msg)
+	return ErrExitSilently
+}
+
 // AskForConfirm parses and verifies user input for confirmation.
 func AskForConfirm(message string) error {
 	if !Interactive {
-		warn("Requires confirmation. Use the `--force` flag to continue without confirmation.")
-		return ErrExitSilently
+		return confirmationRequired()
 	}
 	choice, err := confirm.New(
-		template.String("Are you sure you want to {{.}}", message),
+		template.String("Are you sure you want to {{.}}", localize(message)),
 		confirm.WithDefaultChoice(confirm.No),
 	).Prompt()
 	if err != nil {
@@ -30,8 +72,17 @@ func AskForConfirm(message string) error {
 
 // AskForConfirmDelete builds a message to ask the user to confirm deleting
 // one or multiple resources and then sends it through to AskForConfirm to
-// parses and verifies user input.
-func AskForConfirmDelete(resourceType string, count int) error {
+// parses and verifies user input. The IDs or names of the resources, if
+// given, are listed first.
+func AskForConfirmDelete(resourceType string, count int, ids ...string) error {
+	if Interactive && len(ids) > 0 {
+		targets := make([]confirmTarget, len(ids))
+		for i, id := range ids {
+			targets[i] = confirmTarget{ID: id}
+		}
+		writeConfirmSummary(color.Output, resourceType, targets)
+	}
+
 	message := fmt.Sprintf("delete this %s?", resourceType)
 	if count > 1 {
 		resourceType = resourceType + "s"
@@ -45,3 +96,74 @@ func AskForConfirmDelete(resourceType string, count int) error {
 
 	return nil
 }
+
+// AskForConfirmDeleteTyped asks the user to confirm deleting a high-risk
+// resource, such as a database cluster or a domain, by typing its name after
+// a summary of it is shown.
+func AskForConfirmDeleteTyped(resourceType string, target confirmTarget) error {
+	if !Interactive {
+		return confirmationRequired()
+	}
+	writeConfirmSummary(color.Output, resourceType, []confirmTarget{target})
+
+	name := target.Name
+	if name == "" {
+		name = target.ID
+	}
+	typed, err := promptTypedConfirm(typedConfirmPrompt(resourceType, name))
+	if err != nil {
+		return err
+	}
+	return checkTypedConfirm(typed, resourceType, name)
+}
+
+// typedConfirmPrompt asks for the name of the resource to delete.
+func typedConfirmPrompt(resourceType, name string) string {
+	return fmt.Sprintf("This can't be undone. Type the name of the %s, %s, to delete it: ", resourceType, name)
+}
+
+// checkTypedConfirm checks the name typed in answer to typedConfirmPrompt.
+func checkTypedConfirm(typed, resourceType, name string) error {
+	if strings.TrimSpace(typed) != name {
+		return fmt.Errorf("%q does not match the name of the %s", typed, resourceType)
+	}
+	return nil
+}
+
+// writeConfirmSummary writes a table of the resources a destructive command
+// acts on. Columns no resource has a value for are left out.
+func writeConfirmSummary(w io.Writer, resourceType string, targets []confirmTarget) {
+	var hasID, hasName, hasDetails bool
+	for _, t := range targets {
+		hasID = hasID || t.ID != ""
+		hasName = hasName || t.Name != ""
+		hasDetails = hasDetails || t.Details != ""
+	}
+
+	noun := resourceType
+	if len(targets) > 1 {
+		noun = fmt.Sprintf("%d %ss", len(targets), resourceType)
+	}
+	fmt.Fprintf(w, "The following %s will be deleted:\n\n", noun)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	row := func(id, name, details string) {
+		var cols []string
+		if hasID {
+			cols = append(cols, id)
+		}
+		if hasName {
+			cols = append(cols, name)
+		}
+		if hasDetails {
+			cols = append(cols, details)
+		}
+		fmt.Fprintf(tw, "  %s\n", strings.Join(cols, "\t"))
+	}
+	row("ID", "Name", "Details")
+	for _, t := range targets {
+		row(t.ID, t.Name, t.Details)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+}
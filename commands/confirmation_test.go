@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/charm"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withTypedConfirm runs f interactively with typed as the user's answer to typed confirmations.
+func withTypedConfirm(t *testing.T, typed string, f func()) {
+	origInteractive, origPrompt := Interactive, promptTypedConfirm
+	t.Cleanup(func() { Interactive, promptTypedConfirm = origInteractive, origPrompt })
+	Interactive = true
+	promptTypedConfirm = func(string) (string, error) { return typed, nil }
+	f()
+}
+
+func TestWriteConfirmSummary(t *testing.T) {
+	var buf bytes.Buffer
+	writeConfirmSummary(&buf, "Droplet", []confirmTarget{{ID: "1"}, {ID: "22"}})
+	assert.Equal(t, "The following 2 Droplets will be deleted:\n\n  ID\n  1\n  22\n\n", buf.String())
+
+	buf.Reset()
+	writeConfirmSummary(&buf, "domain", []confirmTarget{{Name: "example.com", Details: "3 records"}})
+	assert.Equal(t, "The following domain will be deleted:\n\n  Name          Details\n  example.com   3 records\n\n", buf.String())
+}
+
+func TestAskForConfirmDeleteTyped(t *testing.T) {
+	target := confirmTarget{ID: "f81d4fae", Name: "prod-db"}
+
+	withTypedConfirm(t, "prod-db", func() {
+		assert.NoError(t, AskForConfirmDeleteTyped("database cluster", target))
+	})
+	withTypedConfirm(t, "yes", func() {
+		assert.Error(t, AskForConfirmDeleteTyped("database cluster", target))
+	})
+
+	origInteractive := Interactive
+	t.Cleanup(func() { Interactive = origInteractive })
+	Interactive = false
+	assert.Equal(t, ErrExitSilently, AskForConfirmDeleteTyped("database cluster", target))
+
+	// Without anybody to warn, the warning is the error.
+	t.Cleanup(func() { charm.PromptsDisabled = false })
+	charm.PromptsDisabled = true
+	assert.ErrorContains(t, AskForConfirmDeleteTyped("database cluster", target), "Requires confirmation")
+	assert.ErrorContains(t, AskForConfirm("delete this Droplet?"), "Requires confirmation")
+}
+
+func TestNormalizeYesFlag(t *testing.T) {
+	withForce := pflag.NewFlagSet("delete", pflag.ContinueOnError)
+	force := withForce.Bool(doctl.ArgForce, false, "")
+	withForce.SetNormalizeFunc(normalizeYesFlag)
+	require.NoError(t, withForce.Parse([]string{"--yes"}))
+	assert.True(t, *force)
+
+	withoutForce := pflag.NewFlagSet("list", pflag.ContinueOnError)
+	withoutForce.SetNormalizeFunc(normalizeYesFlag)
+	assert.Error(t, withoutForce.Parse([]string{"--yes"}))
+}
+
+func TestDatabasesDeleteTypedConfirmation(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		withTypedConfirm(t, testDBCluster.Name, func() {
+			tm.databases.EXPECT().Get(testDBCluster.ID).Return(&testDBCluster, nil)
+			tm.databases.EXPECT().Delete(testDBCluster.ID).Return(nil)
+			config.Args = append(config.Args, testDBCluster.ID)
+
+			assert.NoError(t, RunDatabaseDelete(config))
+		})
+	})
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		withTypedConfirm(t, "wrong", func() {
+			tm.databases.EXPECT().Get(testDBCluster.ID).Return(&testDBCluster, nil)
+			config.Args = append(config.Args, testDBCluster.ID)
+
+			assert.Equal(t, errOperationAborted, RunDatabaseDelete(config))
+		})
+	})
+}
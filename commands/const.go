@@ -25,6 +25,7 @@ const (
 	flagSaveEnv      = "save-env"
 	flagSaveEnvJSON  = "save-env-json"
 	flagSaveAs       = "save-as"
+	flagSaveMetadata = "save-metadata"
 	flagWeb          = "web"
 	flagNoWait       = "no-wait"
 	flagParamFile    = "param-file"
@@ -64,4 +65,7 @@ const (
 	flagJSON         = "json"
 	keywordWeb       = "web"
 	flagNoTriggers   = "no-triggers"
+	flagCanary       = "canary"
+	flagKind         = "kind"
+	flagSBOM         = "sbom"
 )
@@ -0,0 +1,372 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/pkg/scheduler"
+	"github.com/spf13/cobra"
+)
+
+// cronMaxLogSize is the size at which the log of a job is rotated.
+const cronMaxLogSize = 1 << 20
+
+// cronDir returns the directory of the scheduled jobs and their logs. It is
+// replaced for testing.
+var cronDir = func() string {
+	return filepath.Join(configHome(), "cron")
+}
+
+// newScheduler returns the operating system scheduler of kind. It is
+// replaced for testing.
+var newScheduler = func(kind string) (scheduler.Scheduler, error) {
+	return scheduler.New(kind, scheduler.Options{})
+}
+
+// cronExecutable returns the path jobs run doctl with. A doctl on the PATH
+// that is the running executable is preferred, since a package manager's
+// link to a versioned install outlives upgrades. It is replaced for testing.
+var cronExecutable = func() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if p, err := exec.LookPath("doctl"); err == nil {
+		if abs, err := filepath.Abs(p); err == nil {
+			a, errA := os.Stat(abs)
+			b, errB := os.Stat(exe)
+			if errA == nil && errB == nil && os.SameFile(a, b) {
+				return abs, nil
+			}
+		}
+	}
+	return exe, nil
+}
+
+// cronJob is a scheduled job, as it is saved in the jobs file.
+type cronJob struct {
+	Name      string    `json:"name"`
+	Schedule  string    `json:"schedule"`
+	Scheduler string    `json:"scheduler"`
+	Args      []string  `json:"args"`
+	Created   time.Time `json:"created"`
+}
+
+// Cron creates the cron commands.
+func Cron() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "cron",
+			Short: "Run doctl commands on a schedule",
+			Long: `The subcommands of ` + "`" + `doctl cron` + "`" + ` schedule doctl commands, such as snapshots, DNS syncs or cleanup scans, with the scheduler of the operating system: cron, systemd timers, launchd or Windows Task Scheduler.
+
+Each run of a job is logged, with the command's output, to a file in doctl's configuration directory.`,
+			GroupID: configureDoctlGroup,
+		},
+	}
+
+	cmdInstall := cmdBuilderWithInit(cmd, RunCronInstall, "install <schedule> -- <command>...", "Schedule a doctl command",
+		`Schedules a doctl command, which is given after `+"`"+`--`+"`"+` without the leading `+"`"+`doctl`+"`"+`. The schedule is a cron schedule of five fields, minute, hour, day of month, month and day of week, such as `+"`"+`30 2 * * 1-5`+"`"+`, or one of `+"`"+`@hourly`+"`"+`, `+"`"+`@daily`+"`"+`, `+"`"+`@weekly`+"`"+`, `+"`"+`@monthly`+"`"+` and `+"`"+`@yearly`+"`"+`.
+
+The job is installed in the user's crontab on Linux and other Unix systems, as a launch agent on macOS, and as a task of Task Scheduler on Windows. Use `+"`"+`--scheduler systemd`+"`"+` to install it as a systemd user timer instead. Task Scheduler supports fewer schedules than cron, and systemd timers can't run on a day of the month or a day of the week; schedules they can't express are rejected.
+
+Jobs run without a terminal, so commands that ask for confirmation need `+"`"+`--force`+"`"+`, and commands that should use a context other than the default need `+"`"+`--context`+"`"+`.`,
+		Writer, false, displayerType(&displayers.CronJobs{}))
+	AddStringFlag(cmdInstall, doctl.ArgCronJobName, "", "", "The name of the job. Defaults to the command's name, such as compute-image-prune")
+	AddStringFlag(cmdInstall, doctl.ArgCronScheduler, "", scheduler.Default(runtime.GOOS), "The scheduler to install the job in: cron, systemd, launchd or schtasks")
+	cmdInstall.Example = `The following example deletes images and snapshots unused for 30 days every night at 2:30: doctl cron install '30 2 * * *' --name prune-images -- compute image prune --older-than 720h --force`
+
+	cmdList := cmdBuilderWithInit(cmd, RunCronList, "list", "List scheduled jobs",
+		`Lists the jobs scheduled with `+"`"+`doctl cron install`+"`"+`, with where they are logged. A job whose status is `+"`"+`missing`+"`"+` was removed from its scheduler by other means; remove it with `+"`"+`doctl cron remove`+"`"+`.`,
+		Writer, false, aliasOpt("ls"), displayerType(&displayers.CronJobs{}))
+	cmdList.Example = `The following example lists scheduled jobs: doctl cron list`
+
+	cmdRemove := cmdBuilderWithInit(cmd, RunCronRemove, "remove <name>...", "Remove scheduled jobs",
+		`Removes jobs from their scheduler. Their logs are kept.`,
+		Writer, false, aliasOpt("rm"))
+	cmdRemove.Example = `The following example removes the job named ` + "`" + `prune-images` + "`" + `: doctl cron remove prune-images`
+
+	cmdBuilderWithInit(cmd, RunCronRun, "run <name>", "Run a scheduled job",
+		`Runs a scheduled job and logs its output. The schedulers run jobs with this command.`,
+		Writer, false, hiddenCmd())
+
+	return cmd
+}
+
+// cronJobsPath is the file the scheduled jobs are saved in.
+func cronJobsPath() string {
+	return filepath.Join(cronDir(), "jobs.json")
+}
+
+// cronLogPath is the log of a job.
+func cronLogPath(name string) string {
+	return filepath.Join(cronDir(), "logs", name+".log")
+}
+
+func readCronJobs() ([]cronJob, error) {
+	b, err := os.ReadFile(cronJobsPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var jobs []cronJob
+	if err := json.Unmarshal(b, &jobs); err != nil {
+		return nil, fmt.Errorf("reading %s: %v", cronJobsPath(), err)
+	}
+	return jobs, nil
+}
+
+func writeCronJobs(jobs []cronJob) error {
+	b, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cronDir(), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(cronJobsPath(), append(b, '\n'), 0600)
+}
+
+func findCronJob(jobs []cronJob, name string) (int, error) {
+	for i, j := range jobs {
+		if j.Name == name {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("no job is named %q; see doctl cron list", name)
+}
+
+func displayCronJobs(c *CmdConfig, jobs []cronJob, status map[string]string) error {
+	out := make([]displayers.CronJob, len(jobs))
+	for i, j := range jobs {
+		out[i] = displayers.CronJob{
+			Name:      j.Name,
+			Schedule:  j.Schedule,
+			Scheduler: j.Scheduler,
+			Args:      j.Args,
+			Status:    status[j.Name],
+			Log:       cronLogPath(j.Name),
+		}
+	}
+	return c.Display(&displayers.CronJobs{Jobs: out})
+}
+
+// RunCronInstall schedules a doctl command.
+func RunCronInstall(c *CmdConfig) error {
+	if len(c.Args) < 2 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	schedule, err := scheduler.Parse(c.Args[0])
+	if err != nil {
+		return err
+	}
+	args := c.Args[1:]
+	if args[0] == "doctl" {
+		args = args[1:]
+	}
+	cmd, _ := splitCommandArgs(newRootCommand(), args)
+	if cmd.runner == nil {
+		return fmt.Errorf("%q is not a doctl command; give the command after --, without doctl, such as: doctl cron install @daily -- compute droplet list", strings.Join(args, " "))
+	}
+	path := strings.Fields(cmd.CommandPath())[1:]
+	if path[0] == "cron" {
+		return errors.New("doctl cron commands can't be scheduled")
+	}
+
+	kind, err := c.Doit.GetString(c.NS, doctl.ArgCronScheduler)
+	if err != nil {
+		return err
+	}
+	name, err := c.Doit.GetString(c.NS, doctl.ArgCronJobName)
+	if err != nil {
+		return err
+	}
+
+	jobs, err := readCronJobs()
+	if err != nil {
+		return err
+	}
+	taken := func(n string) bool {
+		_, err := findCronJob(jobs, n)
+		return err == nil
+	}
+	if name == "" {
+		base := strings.Join(path, "-")
+		name = base
+		for i := 2; taken(name); i++ {
+			name = fmt.Sprintf("%s-%d", base, i)
+		}
+	} else if taken(name) {
+		return fmt.Errorf("a job named %q already exists; remove it with doctl cron remove %s, or choose another --%s", name, name, doctl.ArgCronJobName)
+	}
+	if err := scheduler.ValidName(name); err != nil {
+		return err
+	}
+
+	s, err := newScheduler(kind)
+	if err != nil {
+		return err
+	}
+	exe, err := cronExecutable()
+	if err != nil {
+		return err
+	}
+	job := cronJob{Name: name, Schedule: schedule.String(), Scheduler: kind, Args: args, Created: time.Now().UTC()}
+	if err := s.Install(scheduler.Job{Name: name, Schedule: schedule, Command: []string{exe, "cron", "run", name}}); err != nil {
+		return fmt.Errorf("installing the job in %s: %v", kind, err)
+	}
+	if err := writeCronJobs(append(jobs, job)); err != nil {
+		s.Remove(name)
+		return err
+	}
+	return displayCronJobs(c, []cronJob{job}, map[string]string{name: "installed"})
+}
+
+// RunCronList lists the scheduled jobs, and whether their schedulers still
+// have them.
+func RunCronList(c *CmdConfig) error {
+	jobs, err := readCronJobs()
+	if err != nil {
+		return err
+	}
+
+	status := map[string]string{}
+	installed := map[string]map[string]bool{}
+	for _, j := range jobs {
+		names, ok := installed[j.Scheduler]
+		if !ok {
+			names = map[string]bool{}
+			installed[j.Scheduler] = names
+			s, err := newScheduler(j.Scheduler)
+			if err == nil {
+				var list []string
+				if list, err = s.Installed(); err == nil {
+					for _, n := range list {
+						names[n] = true
+					}
+				}
+			}
+			if err != nil {
+				warn("Could not list the jobs of %s: %v", j.Scheduler, err)
+				installed[j.Scheduler] = nil
+				names = nil
+			}
+		}
+		switch {
+		case names == nil:
+			status[j.Name] = "unknown"
+		case names[j.Name]:
+			status[j.Name] = "installed"
+		default:
+			status[j.Name] = "missing"
+		}
+	}
+	return displayCronJobs(c, jobs, status)
+}
+
+// RunCronRemove removes scheduled jobs.
+func RunCronRemove(c *CmdConfig) error {
+	if len(c.Args) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	jobs, err := readCronJobs()
+	if err != nil {
+		return err
+	}
+	for _, name := range c.Args {
+		if _, err := findCronJob(jobs, name); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range c.Args {
+		i, _ := findCronJob(jobs, name)
+		s, err := newScheduler(jobs[i].Scheduler)
+		if err != nil {
+			return err
+		}
+		if err := s.Remove(name); err != nil {
+			return fmt.Errorf("removing the job %s from %s: %v", name, jobs[i].Scheduler, err)
+		}
+		jobs = append(jobs[:i], jobs[i+1:]...)
+		if err := writeCronJobs(jobs); err != nil {
+			return err
+		}
+		notice("Removed the job %s. Its log is kept at %s.", name, cronLogPath(name))
+	}
+	return nil
+}
+
+// RunCronRun runs a scheduled job, appending its output to the job's log.
+func RunCronRun(c *CmdConfig) error {
+	if err := ensureOneArg(c); err != nil {
+		return err
+	}
+	name := c.Args[0]
+	jobs, err := readCronJobs()
+	if err != nil {
+		return err
+	}
+	i, err := findCronJob(jobs, name)
+	if err != nil {
+		return err
+	}
+	job := jobs[i]
+
+	path := cronLogPath(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > cronMaxLogSize {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return err
+		}
+	}
+	log, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	exe, err := cronExecutable()
+	if err != nil {
+		return err
+	}
+	started := time.Now()
+	fmt.Fprintf(log, "[%s] Running doctl %s\n", started.Format(time.RFC3339), strings.Join(job.Args, " "))
+	cmd := exec.Command(exe, job.Args...)
+	cmd.Stdout = log
+	cmd.Stderr = log
+	err = cmd.Run()
+	took := time.Since(started).Round(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(log, "[%s] Failed after %s: %v\n", time.Now().Format(time.RFC3339), took, err)
+		return fmt.Errorf("the job %s failed: %v; see %s", name, err, path)
+	}
+	fmt.Fprintf(log, "[%s] Finished in %s\n", time.Now().Format(time.RFC3339), took)
+	return nil
+}
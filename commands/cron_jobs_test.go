@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/pkg/scheduler"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScheduler is a scheduler that keeps its jobs in memory.
+type fakeScheduler struct {
+	jobs map[string]scheduler.Job
+}
+
+func (f *fakeScheduler) Install(j scheduler.Job) error {
+	f.jobs[j.Name] = j
+	return nil
+}
+
+func (f *fakeScheduler) Remove(name string) error {
+	delete(f.jobs, name)
+	return nil
+}
+
+func (f *fakeScheduler) Installed() ([]string, error) {
+	var names []string
+	for n := range f.jobs {
+		names = append(names, n)
+	}
+	return names, nil
+}
+
+// stubCron replaces the scheduler with a fake, and doctl with script.
+func stubCron(t *testing.T, script string) *fakeScheduler {
+	fake := &fakeScheduler{jobs: map[string]scheduler.Job{}}
+	prevScheduler, prevExecutable := newScheduler, cronExecutable
+	t.Cleanup(func() { newScheduler, cronExecutable = prevScheduler, prevExecutable })
+
+	newScheduler = func(kind string) (scheduler.Scheduler, error) {
+		if kind != scheduler.Cron {
+			return scheduler.New(kind, scheduler.Options{})
+		}
+		return fake, nil
+	}
+	exe := filepath.Join(t.TempDir(), "doctl")
+	require.NoError(t, os.WriteFile(exe, []byte("#!/bin/sh\n"+script), 0755))
+	cronExecutable = func() (string, error) { return exe, nil }
+	return fake
+}
+
+func cronJSON(t *testing.T, config *CmdConfig) *bytes.Buffer {
+	prev := viper.GetString(doctl.ArgOutput)
+	viper.Set(doctl.ArgOutput, "json")
+	t.Cleanup(func() { viper.Set(doctl.ArgOutput, prev) })
+	var buf bytes.Buffer
+	config.Out = &buf
+	return &buf
+}
+
+func TestCronInstall(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		fake := stubCron(t, "")
+		exe, _ := cronExecutable()
+		buf := cronJSON(t, config)
+
+		config.Args = []string{"30 2 * * *", "doctl", "compute", "droplet", "list", "--format", "ID"}
+		config.Doit.Set(config.NS, doctl.ArgCronScheduler, scheduler.Cron)
+		require.NoError(t, RunCronInstall(config))
+
+		job := fake.jobs["compute-droplet-list"]
+		assert.Equal(t, []string{exe, "cron", "run", "compute-droplet-list"}, job.Command)
+		assert.Equal(t, "30 2 * * *", job.Schedule.Cron())
+
+		var out []displayers.CronJob
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		assert.Equal(t, []displayers.CronJob{{
+			Name: "compute-droplet-list", Schedule: "30 2 * * *", Scheduler: "cron",
+			Args: []string{"compute", "droplet", "list", "--format", "ID"}, Status: "installed",
+			Log: filepath.Join(cronDir(), "logs", "compute-droplet-list.log"),
+		}}, out)
+
+		// A second job of the same command gets a name of its own.
+		config.Args = []string{"@hourly", "compute", "droplet", "list"}
+		require.NoError(t, RunCronInstall(config))
+		assert.Contains(t, fake.jobs, "compute-droplet-list-2")
+
+		config.Doit.Set(config.NS, doctl.ArgCronJobName, "compute-droplet-list")
+		err := RunCronInstall(config)
+		assert.ErrorContains(t, err, `a job named "compute-droplet-list" already exists`)
+
+		jobs, err := readCronJobs()
+		require.NoError(t, err)
+		assert.Len(t, jobs, 2)
+	})
+}
+
+func TestCronInstallInvalid(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		fake := stubCron(t, "")
+		config.Doit.Set(config.NS, doctl.ArgCronScheduler, scheduler.Cron)
+
+		for args, want := range map[string]string{
+			"@reboot compute droplet list": "unknown schedule",
+			"@daily droplets list":         `"droplets list" is not a doctl command`,
+			"@daily compute":               `"compute" is not a doctl command`,
+			"@daily cron list":             "can't be scheduled",
+		} {
+			config.Args = strings.Fields(args)
+			err := RunCronInstall(config)
+			assert.ErrorContains(t, err, want, args)
+		}
+
+		config.Args = []string{"@daily", "account", "get"}
+		config.Doit.Set(config.NS, doctl.ArgCronJobName, "Nightly")
+		assert.ErrorContains(t, RunCronInstall(config), "invalid job name")
+		assert.Empty(t, fake.jobs)
+	})
+}
+
+func TestCronListAndRemove(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		fake := stubCron(t, "")
+		config.Doit.Set(config.NS, doctl.ArgCronScheduler, scheduler.Cron)
+		for _, name := range []string{"a", "b"} {
+			config.Args = []string{"@daily", "account", "get"}
+			config.Doit.Set(config.NS, doctl.ArgCronJobName, name)
+			require.NoError(t, RunCronInstall(config))
+		}
+		delete(fake.jobs, "b")
+
+		buf := cronJSON(t, config)
+		config.Args = nil
+		require.NoError(t, RunCronList(config))
+		var out []displayers.CronJob
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		require.Len(t, out, 2)
+		assert.Equal(t, "installed", out[0].Status)
+		assert.Equal(t, "missing", out[1].Status)
+
+		config.Args = []string{"a", "c"}
+		assert.ErrorContains(t, RunCronRemove(config), `no job is named "c"`)
+		assert.Contains(t, fake.jobs, "a")
+
+		config.Args = []string{"b", "a"}
+		require.NoError(t, RunCronRemove(config))
+		assert.Empty(t, fake.jobs)
+		jobs, err := readCronJobs()
+		require.NoError(t, err)
+		assert.Empty(t, jobs)
+	})
+}
+
+func TestCronRun(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		stubCron(t, `echo "ran with $*"; echo oops >&2; [ "$1" = account ]`)
+		config.Doit.Set(config.NS, doctl.ArgCronScheduler, scheduler.Cron)
+		config.Args = []string{"@daily", "account", "get"}
+		config.Doit.Set(config.NS, doctl.ArgCronJobName, "ok")
+		require.NoError(t, RunCronInstall(config))
+		config.Args = []string{"@daily", "balance", "get"}
+		config.Doit.Set(config.NS, doctl.ArgCronJobName, "fails")
+		require.NoError(t, RunCronInstall(config))
+
+		config.Args = []string{"ok"}
+		require.NoError(t, RunCronRun(config))
+		log, err := os.ReadFile(cronLogPath("ok"))
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(log)), "\n")
+		require.Len(t, lines, 4)
+		assert.Contains(t, lines[0], "] Running doctl account get")
+		assert.Equal(t, "ran with account get", lines[1])
+		assert.Equal(t, "oops", lines[2])
+		assert.Contains(t, lines[3], "] Finished in ")
+
+		config.Args = []string{"fails"}
+		err = RunCronRun(config)
+		assert.ErrorContains(t, err, "the job fails failed: exit status 1")
+		log, err = os.ReadFile(cronLogPath("fails"))
+		require.NoError(t, err)
+		assert.Contains(t, string(log), "] Failed after ")
+
+		// A large log is rotated before the next run.
+		require.NoError(t, os.WriteFile(cronLogPath("ok"), bytes.Repeat([]byte("x"), cronMaxLogSize+1), 0600))
+		config.Args = []string{"ok"}
+		require.NoError(t, RunCronRun(config))
+		info, err := os.Stat(cronLogPath("ok") + ".1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(cronMaxLogSize+1), info.Size())
+		log, err = os.ReadFile(cronLogPath("ok"))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(log), "["))
+	})
+}
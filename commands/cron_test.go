@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronNext(t *testing.T) {
+	// A Friday.
+	from := time.Date(2024, 5, 10, 14, 30, 20, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 5, 10, 14, 31, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2024, 5, 11, 2, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 5, 10, 14, 45, 0, 0, time.UTC)},
+		{"30 14 * * *", time.Date(2024, 5, 11, 14, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * 1-5", time.Date(2024, 5, 10, 17, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// With both day fields restricted, either may match.
+		{"0 0 31 * 1", time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := parseCron(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.next(from))
+		})
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+	} {
+		_, err := parseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
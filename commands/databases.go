@@ -76,7 +76,7 @@ func Databases() *Command {
 	cmdDatabaseCreate := CmdBuilder(cmd, RunDatabaseCreate, "create <name>", "Create a database cluster", `Creates a database cluster with the specified name.
 
 You can customize the configuration using the listed flags, all of which are optional. Without any flags set, the command creates a single-node, single-CPU PostgreSQL database cluster.`, Writer,
-		aliasOpt("c"))
+		aliasOpt("c"), notifyOpt())
 	AddIntFlag(cmdDatabaseCreate, doctl.ArgDatabaseNumNodes, "", defaultDatabaseNodeCount, nodeNumberDetails)
 	AddStringFlag(cmdDatabaseCreate, doctl.ArgRegionSlug, "", defaultDatabaseRegion, "The data center region where the database cluster resides, such as `nyc1` or `sfo2`.")
 	AddStringFlag(cmdDatabaseCreate, doctl.ArgSizeSlug, "", defaultDatabaseNodeSize, nodeSizeDetails)
@@ -134,17 +134,45 @@ For PostgreSQL and MySQL clusters, you can also provide a disk size in MiB to sc
 	cmdDatabaseResize.Example = `The following example resizes a PostgreSQL or MySQL database to have two nodes, 16 vCPUs, 64 GB of memory, and 2048 GiB of storage space: doctl databases resize ca9f591d-9999-5555-a0ef-1c02d1d1e352 --num-nodes 2 --size db-s-16vcpu-64gb --storage-size-mib 2048000`
 
 	cmdDatabaseMigrate := CmdBuilder(cmd, RunDatabaseMigrate, "migrate <database-cluster-id>", "Migrate a database cluster to a new region", `Migrates the specified database cluster to a new region.`, Writer,
-		aliasOpt("m"))
+		aliasOpt("m"), notifyOpt())
 	AddStringFlag(cmdDatabaseMigrate, doctl.ArgRegionSlug, "", "", "The region to which the database cluster should be migrated, such as `sfo2` or `nyc3`.", requiredOpt())
 	AddStringFlag(cmdDatabaseMigrate, doctl.ArgPrivateNetworkUUID, "", "", "The UUID of a VPC network to create the database cluster in. The command uses the region's default VPC network if not specified.")
 
-	cmdDatabaseFork := CmdBuilder(cmd, RunDatabaseFork, "fork <name>", "Create a new database cluster by forking an existing database cluster.", `Creates a new database cluster from an existing cluster. The forked database contains all of the data from the original database at the time the fork is created.`, Writer, aliasOpt("f"))
+	cmdDatabaseFork := CmdBuilder(cmd, RunDatabaseFork, "fork <name>", "Create a new database cluster by forking an existing database cluster.", `Creates a new database cluster from an existing cluster. The forked database contains all of the data from the original database at the time the fork is created.`, Writer, aliasOpt("f"), notifyOpt())
 	AddStringFlag(cmdDatabaseFork, doctl.ArgDatabaseRestoreFromClusterID, "", "", "The ID of an existing database cluster from which the new database will be forked from", requiredOpt())
 	AddStringFlag(cmdDatabaseFork, doctl.ArgDatabaseRestoreFromTimestamp, "", "", "The timestamp of an existing database cluster backup in UTC combined date and time format (2006-01-02 15:04:05 +0000 UTC). The most recent backup is used if excluded.")
 	AddBoolFlag(cmdDatabaseFork, doctl.ArgCommandWait, "", false, "A boolean that specifies whether to wait for a database to complete before returning control to the terminal")
 
 	cmdDatabaseFork.Example = `The following example forks a database cluster with the ID ` + "`" + `f81d4fae-7dec-11d0-a765-00a0c91e6bf6` + "`" + ` to create a new database cluster. The command also uses the ` + "`" + `--restore-from-timestamp` + "`" + ` flag to specifically fork the database from a cluster backup that was created on 2023 November 7: doctl databases fork new-db-cluster --restore-from-cluster-id f81d4fae-7dec-11d0-a765-00a0c91e6bf6 --restore-from-timestamp 2023-11-07 12:34:56 +0000 UTC`
 
+	cmdDatabaseUpgrade := CmdBuilder(cmd, RunDatabaseUpgrade, "upgrade <database-cluster-id>", "Upgrade a database cluster to a new major version", `Upgrades the specified database cluster to a new major engine version. Upgrades can't be reversed.
+
+Before starting the upgrade, the command checks the target version against the versions offered for the cluster's engine, and warns when the upgrade skips intermediate major versions. Use the `+"`"+`--at`+"`"+` flag to wait until a given time, such as the start of your maintenance window, before the upgrade is requested; the command must keep running until then.`, Writer,
+		aliasOpt("up"), notifyOpt())
+	AddStringFlag(cmdDatabaseUpgrade, doctl.ArgVersion, "", "", "The major engine version to upgrade to, such as `16`", requiredOpt())
+	AddStringFlag(cmdDatabaseUpgrade, doctl.ArgDatabaseUpgradeAt, "", "", "The time to start the upgrade, in RFC3339 format, such as `2024-05-05T03:00:00Z`. The upgrade starts immediately if excluded.")
+	AddBoolFlag(cmdDatabaseUpgrade, doctl.ArgForce, doctl.ArgShortForce, false, "Upgrade the database cluster without a confirmation prompt")
+	cmdDatabaseUpgrade.Example = `The following example upgrades a PostgreSQL database cluster with the ID ` + "`" + `ca9f591d-f38h-5555-a0ef-1c02d1d1e35` + "`" + ` to version 16 at 03:00 UTC on 2024 May 5: doctl databases upgrade ca9f591d-f38h-5555-a0ef-1c02d1d1e35 --version 16 --at 2024-05-05T03:00:00Z`
+
+	cmdDatabaseMetrics := CmdBuilder(cmd, RunDatabaseMetrics, "metrics <database-cluster-id>", "Show the current metrics of a database cluster", `Scrapes the metrics endpoints of the specified database cluster and shows the current value of each metric on each host:
+
+- `+"`"+`connections`+"`"+`: The number of open client connections, per database for PostgreSQL
+- `+"`"+`cpu`+"`"+`: The percentage of CPU in use
+- `+"`"+`disk`+"`"+`: The percentage of disk space in use, per mount point
+
+The endpoints are authenticated with the metrics credentials of your account, which you can retrieve with the DigitalOcean API.`, Writer,
+		aliasOpt("m"), displayerType(&displayers.DatabaseMetrics{}))
+	AddStringFlag(cmdDatabaseMetrics, doctl.ArgDatabaseMetric, "", "", "The metric to show: `connections`, `cpu`, or `disk`. All of them are shown if excluded.")
+	cmdDatabaseMetrics.Example = `The following example shows the CPU usage of a database cluster with the ID ` + "`" + `ca9f591d-f38h-5555-a0ef-1c02d1d1e35` + "`" + `: doctl databases metrics ca9f591d-f38h-5555-a0ef-1c02d1d1e35 --metric cpu`
+
+	cmdDatabaseSlowQueries := CmdBuilder(cmd, RunDatabaseSlowQueries, "slow-queries <database-cluster-id>", "Show the queries taking the most time on a database cluster", `Connects to the specified PostgreSQL or MySQL database cluster as its admin user with the `+"`"+`psql`+"`"+` or `+"`"+`mysql`+"`"+` client, which must be installed, and shows the statements that took the most time in total. The server's certificate is verified against the cluster's CA certificate. Literals are replaced with `+"`"+`?`+"`"+`, so statements that differ only in their parameters are reported together.
+
+For PostgreSQL, the statistics come from the `+"`"+`pg_stat_statements`+"`"+` extension and cover the time since they were last reset, so `+"`"+`--since`+"`"+` does not apply. For MySQL, they come from the slow query log of the last `+"`"+`--since`+"`"+`, which lists the statements that took longer than the cluster's `+"`"+`long_query_time`+"`"+` setting while `+"`"+`slow_query_log`+"`"+` is enabled.`, Writer,
+		aliasOpt("sq"), displayerType(&displayers.DatabaseSlowQueries{}))
+	AddDurationFlag(cmdDatabaseSlowQueries, doctl.ArgDatabaseSlowQueriesSince, "", time.Hour, "How far back to look in the MySQL slow query log")
+	AddIntFlag(cmdDatabaseSlowQueries, doctl.ArgDatabaseSlowQueriesTop, "", 10, "The number of statements to show")
+	cmdDatabaseSlowQueries.Example = `The following example shows the five MySQL statements that took the most time in the last day: doctl databases slow-queries ca9f591d-f38h-5555-a0ef-1c02d1d1e35 --since 24h --top 5`
+
 	cmd.AddCommand(databaseReplica())
 	cmd.AddCommand(databaseMaintenanceWindow())
 	cmd.AddCommand(databaseUser())
@@ -155,6 +183,9 @@ For PostgreSQL and MySQL clusters, you can also provide a disk size in MiB to sc
 	cmd.AddCommand(databaseOptions())
 	cmd.AddCommand(databaseConfiguration())
 	cmd.AddCommand(databaseTopic())
+	cmd.AddCommand(databaseKafka())
+	cmd.AddCommand(databaseRedis())
+	cmd.AddCommand(databaseOpenSearch())
 	cmd.AddCommand(databaseEvents())
 
 	return cmd
@@ -204,6 +235,7 @@ func RunDatabaseCreate(c *CmdConfig) error {
 	if err != nil {
 		return err
 	}
+	c.addNotifyDetail("Database", db.ID)
 
 	wait, err := c.Doit.GetBool(c.NS, doctl.ArgCommandWait)
 	if err != nil {
@@ -241,7 +273,7 @@ func RunDatabaseCreate(c *CmdConfig) error {
 func buildDatabaseCreateRequestFromArgs(c *CmdConfig) (*godo.DatabaseCreateRequest, error) {
 	r := &godo.DatabaseCreateRequest{Name: c.Args[0]}
 
-	region, err := c.Doit.GetString(c.NS, doctl.ArgRegionSlug)
+	region, err := regionFromArgs(c, doctl.ArgRegionSlug)
 	if err != nil {
 		return nil, err
 	}
@@ -333,6 +365,7 @@ func RunDatabaseFork(c *CmdConfig) error {
 	if err != nil {
 		return err
 	}
+	c.addNotifyDetail("Database", db.ID)
 
 	wait, err := c.Doit.GetBool(c.NS, doctl.ArgCommandWait)
 	if err != nil {
@@ -423,12 +456,23 @@ func RunDatabaseDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if force || AskForConfirmDelete("database cluster", 1) == nil {
-		id := c.Args[0]
-		return c.Databases().Delete(id)
+	id := c.Args[0]
+	if !force {
+		db, err := c.Databases().Get(id)
+		if err != nil {
+			return err
+		}
+		target := confirmTarget{
+			ID:      db.ID,
+			Name:    db.Name,
+			Details: fmt.Sprintf("%s %s, %d nodes of %s in %s", db.EngineSlug, db.VersionSlug, db.NumNodes, db.SizeSlug, db.RegionSlug),
+		}
+		if AskForConfirmDeleteTyped("database cluster", target) != nil {
+			return errOperationAborted
+		}
 	}
 
-	return errOperationAborted
+	return c.Databases().Delete(id)
 }
 
 func displayDatabases(c *CmdConfig, short bool, dbs ...do.Database) error {
@@ -540,6 +584,142 @@ func RunDatabaseMigrate(c *CmdConfig) error {
 	return c.Databases().Migrate(id, r)
 }
 
+// databaseUpgradeSleep is replaced in tests to avoid waiting for --at.
+var databaseUpgradeSleep = time.Sleep
+
+// RunDatabaseUpgrade upgrades a database cluster to a new major version
+func RunDatabaseUpgrade(c *CmdConfig) error {
+	if len(c.Args) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	id := c.Args[0]
+
+	version, err := c.Doit.GetString(c.NS, doctl.ArgVersion)
+	if err != nil {
+		return err
+	}
+
+	at, err := c.Doit.GetString(c.NS, doctl.ArgDatabaseUpgradeAt)
+	if err != nil {
+		return err
+	}
+	var startAt time.Time
+	if at != "" {
+		startAt, err = time.Parse(time.RFC3339, at)
+		if err != nil {
+			return fmt.Errorf("invalid --%s value %q: must be an RFC3339 timestamp", doctl.ArgDatabaseUpgradeAt, at)
+		}
+	}
+
+	force, err := c.Doit.GetBool(c.NS, doctl.ArgForce)
+	if err != nil {
+		return err
+	}
+
+	database, err := c.Databases().Get(id)
+	if err != nil {
+		return err
+	}
+
+	options, err := c.Databases().ListOptions()
+	if err != nil {
+		return err
+	}
+
+	warnings, err := databaseUpgradePreflight(database, options, version)
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		warn(w)
+	}
+
+	if !force && AskForConfirm(fmt.Sprintf("upgrade %s from %s version %s to %s? Upgrades can't be reversed.", database.Name, database.EngineSlug, database.VersionSlug, version)) != nil {
+		return errOperationAborted
+	}
+
+	if wait := time.Until(startAt); wait > 0 {
+		notice("Waiting until %s to start the upgrade", startAt.Format(time.RFC3339))
+		databaseUpgradeSleep(wait)
+	}
+
+	if err := c.Databases().UpgradeMajorVersion(id, &godo.UpgradeVersionRequest{Version: version}); err != nil {
+		return err
+	}
+
+	c.addNotifyDetail("Version", version)
+	notice("Upgrade of %s to version %s started", database.Name, version)
+	return nil
+}
+
+// databaseUpgradePreflight checks that version is offered for the cluster's
+// engine and is newer than the running version. It returns warnings for
+// upgrades that are allowed but deserve attention.
+func databaseUpgradePreflight(database *do.Database, options *do.DatabaseOptions, version string) ([]string, error) {
+	engineOptions, ok := databaseEngineOptions(options, database.EngineSlug)
+	if !ok {
+		return nil, fmt.Errorf("no version options are available for the %s engine", database.EngineSlug)
+	}
+
+	offered := false
+	for _, v := range engineOptions.Versions {
+		if v == version {
+			offered = true
+			break
+		}
+	}
+	if !offered {
+		return nil, fmt.Errorf("version %s is not available for %s; available versions: %s", version, database.EngineSlug, strings.Join(engineOptions.Versions, ", "))
+	}
+
+	current, errCurrent := strconv.Atoi(database.VersionSlug)
+	target, errTarget := strconv.Atoi(version)
+	if errCurrent != nil || errTarget != nil {
+		return []string{fmt.Sprintf("Could not compare version %s with the running version %s; make sure the upgrade path is supported.", version, database.VersionSlug)}, nil
+	}
+	if target <= current {
+		return nil, fmt.Errorf("version %s is not newer than the running version %s", version, database.VersionSlug)
+	}
+
+	var warnings []string
+	var skipped []string
+	for _, v := range engineOptions.Versions {
+		n, err := strconv.Atoi(v)
+		if err == nil && n > current && n < target {
+			skipped = append(skipped, v)
+		}
+	}
+	if len(skipped) > 0 {
+		warnings = append(warnings, fmt.Sprintf("This upgrade skips version(s) %s; review the release notes of each skipped version for incompatible changes.", strings.Join(skipped, ", ")))
+	}
+	if database.MaintenanceWindow != nil && database.MaintenanceWindow.Pending {
+		warnings = append(warnings, "Maintenance updates are pending for this cluster; they will be applied before the upgrade completes.")
+	}
+
+	return warnings, nil
+}
+
+// databaseEngineOptions returns the options for engine, as named by the
+// engine slugs accepted by the API.
+func databaseEngineOptions(options *do.DatabaseOptions, engine string) (godo.DatabaseEngineOptions, bool) {
+	switch engine {
+	case "mongodb":
+		return options.MongoDBOptions, true
+	case "mysql":
+		return options.MySQLOptions, true
+	case "pg":
+		return options.PostgresSQLOptions, true
+	case "redis":
+		return options.RedisOptions, true
+	case "kafka":
+		return options.KafkaOptions, true
+	case "opensearch":
+		return options.OpensearchOptions, true
+	}
+	return godo.DatabaseEngineOptions{}, false
+}
+
 func buildDatabaseMigrateRequestFromArgs(c *CmdConfig) (*godo.DatabaseMigrateRequest, error) {
 	r := &godo.DatabaseMigrateRequest{}
 
@@ -547,7 +727,10 @@ func buildDatabaseMigrateRequestFromArgs(c *CmdConfig) (*godo.DatabaseMigrateReq
 	if err != nil {
 		return nil, err
 	}
-	r.Region = region
+	r.Region, err = resolveRegionAlias(c, region)
+	if err != nil {
+		return nil, err
+	}
 
 	privateNetworkUUID, err := c.Doit.GetString(c.NS, doctl.ArgPrivateNetworkUUID)
 	if err != nil {
@@ -588,7 +771,7 @@ Maintenance windows are hour-long blocks of time during which DigitalOcean perfo
 
 To change the maintenance window for your database cluster, specify a day of the week and an hour of that day during which you would prefer such maintenance would occur.
 
-To see a list of your databases and their IDs, run `+"`"+`doctl databases list`+"`"+`.`, Writer, aliasOpt("u"))
+To see a list of your databases and their IDs, run `+"`"+`doctl databases list`+"`"+`.`, Writer, aliasOpt("u", "set"))
 	AddStringFlag(cmdDatabaseCreate, doctl.ArgDatabaseMaintenanceDay, "", "",
 		"The day of the week the maintenance window occurs, for example: 'tuesday')", requiredOpt())
 	AddStringFlag(cmdDatabaseCreate, doctl.ArgDatabaseMaintenanceHour, "", "",
@@ -644,16 +827,42 @@ func buildDatabaseUpdateMaintenanceRequestFromArgs(c *CmdConfig) (*godo.Database
 		return nil, err
 	}
 	r.Day = strings.ToLower(day)
+	if !isWeekday(r.Day) {
+		return nil, fmt.Errorf("invalid --%s value %q: must be a day of the week, such as tuesday", doctl.ArgDatabaseMaintenanceDay, day)
+	}
 
 	hour, err := c.Doit.GetString(c.NS, doctl.ArgDatabaseMaintenanceHour)
 	if err != nil {
 		return nil, err
 	}
+	if !isMaintenanceHour(hour) {
+		return nil, fmt.Errorf("invalid --%s value %q: must be a UTC hour in 24-hour format, such as 16:00", doctl.ArgDatabaseMaintenanceHour, hour)
+	}
 	r.Hour = hour
 
 	return r, nil
 }
 
+func isWeekday(day string) bool {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.ToLower(d.String()) == day {
+			return true
+		}
+	}
+	return false
+}
+
+// isMaintenanceHour accepts hours as shown by the API, such as 16:00 or
+// 16:00:00.
+func isMaintenanceHour(hour string) bool {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if _, err := time.Parse(layout, hour); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
 func databaseUser() *Command {
 	cmd := &Command{
 		Command: &cobra.Command{
@@ -696,6 +905,16 @@ To retrieve a list of your databases and their IDs, call `+"`"+`doctl databases
 	AddStringSliceFlag(cmdDatabaseUserCreate, doctl.ArgDatabaseUserKafkaACLs, "", []string{}, databaseKafkaACLsTxt)
 	cmdDatabaseUserCreate.Example = `The following example creates a new user with the username ` + "`" + `example-user` + "`" + ` for a database cluster with the ID ` + "`" + `ca9f591d-f38h-5555-a0ef-1c02d1d1e35` + "`" + `: doctl databases user create ca9f591d-f38h-5555-a0ef-1c02d1d1e35 example-user`
 
+	cmdDatabaseUserUpdate := CmdBuilder(cmd, RunDatabaseUserUpdate, "update <database-cluster-id> <user-name>",
+		"Update a database user", `Updates the specified database user. Use the `+"`"+`--reset-password`+"`"+` flag to regenerate the user's password. The new password is printed once in the command's output, so store it before clearing your terminal. For MySQL users, the `+"`"+`--mysql-auth-plugin`+"`"+` flag changes the authorization plugin used with the new password. For Kafka users, the `+"`"+`--acl`+"`"+` flag replaces the user's topic permissions.
+
+To retrieve a list of your databases and their IDs, call `+"`"+`doctl databases list`+"`"+`.`, Writer, aliasOpt("u"), displayerType(&displayers.DatabaseUsers{}))
+	AddBoolFlag(cmdDatabaseUserUpdate, doctl.ArgDatabaseUserResetPassword, "", false, "Regenerate the user's password and print the new credential")
+	AddStringFlag(cmdDatabaseUserUpdate, doctl.ArgDatabaseUserMySQLAuthPlugin, "", "",
+		"Sets authorization plugin for a MySQL user when resetting its password. Possible values: `caching_sha2_password` or `mysql_native_password`")
+	AddStringSliceFlag(cmdDatabaseUserUpdate, doctl.ArgDatabaseUserKafkaACLs, "", []string{}, databaseKafkaACLsTxt)
+	cmdDatabaseUserUpdate.Example = `The following example regenerates the password for the user with the username ` + "`" + `example-user` + "`" + ` for a database cluster with the ID ` + "`" + `ca9f591d-f38h-5555-a0ef-1c02d1d1e35` + "`" + `: doctl databases user update ca9f591d-f38h-5555-a0ef-1c02d1d1e35 example-user --reset-password`
+
 	cmdDatabaseUserResetAuth := CmdBuilder(cmd, RunDatabaseUserResetAuth, "reset <database-cluster-id> <user-name> <new-auth-mode>",
 		"Resets a user's auth", "Resets the auth password or the MySQL authorization plugin for a given user and returns the user's new credentials. When resetting MySQL auth, valid values for `<new-auth-mode>` are `caching_sha2_password` and `mysql_native_password`.", Writer, aliasOpt("rs"))
 	cmdDatabaseUserResetAuth.Example = `The following example resets the auth plugin for the user with the username ` + "`" + `example-user` + "`" + ` for a database cluster with the ID ` + "`" + `ca9f591d-f38h-5555-a0ef-1c02d1d1e35` + "`" + ` to ` + "`" + `mysql_native_password` + "`" + `: doctl databases user reset ca9f591d-f38h-5555-a0ef-1c02d1d1e35 example-user mysql_native_password`
@@ -763,6 +982,9 @@ func RunDatabaseUserCreate(c *CmdConfig) error {
 		return err
 	}
 
+	if err := validateMySQLAuthPlugin(authMode); err != nil {
+		return err
+	}
 	if authMode != "" {
 		req.MySQLSettings = &godo.DatabaseMySQLUserSettings{
 			AuthPlugin: authMode,
@@ -847,6 +1069,86 @@ func RunDatabaseUserResetAuth(c *CmdConfig) error {
 	return displayDatabaseUsers(c, *user)
 }
 
+// RunDatabaseUserUpdate updates a database user, optionally regenerating its password
+func RunDatabaseUserUpdate(c *CmdConfig) error {
+	if len(c.Args) < 2 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	var (
+		databaseID = c.Args[0]
+		userName   = c.Args[1]
+	)
+
+	resetPassword, err := c.Doit.GetBool(c.NS, doctl.ArgDatabaseUserResetPassword)
+	if err != nil {
+		return err
+	}
+
+	authMode, err := c.Doit.GetString(c.NS, doctl.ArgDatabaseUserMySQLAuthPlugin)
+	if err != nil {
+		return err
+	}
+	if err := validateMySQLAuthPlugin(authMode); err != nil {
+		return err
+	}
+
+	kafkaAcls, err := buildDatabaseCreateKafkaUserACls(c)
+	if err != nil {
+		return err
+	}
+
+	if !resetPassword && len(kafkaAcls) == 0 {
+		return fmt.Errorf("nothing to update: specify --%s or --%s", doctl.ArgDatabaseUserResetPassword, doctl.ArgDatabaseUserKafkaACLs)
+	}
+	if authMode != "" && !resetPassword {
+		return fmt.Errorf("--%s can only be used with --%s", doctl.ArgDatabaseUserMySQLAuthPlugin, doctl.ArgDatabaseUserResetPassword)
+	}
+
+	var user *do.DatabaseUser
+	if len(kafkaAcls) != 0 {
+		user, err = c.Databases().UpdateUser(databaseID, userName, &godo.DatabaseUpdateUserRequest{
+			Settings: &godo.DatabaseUserSettings{ACL: kafkaAcls},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if resetPassword {
+		req := &godo.DatabaseResetUserAuthRequest{}
+		if authMode == "" {
+			// Resetting a MySQL user requires an auth plugin, so keep the
+			// one the user already has.
+			current, err := c.Databases().GetUser(databaseID, userName)
+			if err != nil {
+				return err
+			}
+			if current.MySQLSettings != nil {
+				authMode = current.MySQLSettings.AuthPlugin
+			}
+		}
+		if authMode != "" {
+			req.MySQLSettings = &godo.DatabaseMySQLUserSettings{AuthPlugin: authMode}
+		}
+
+		user, err = c.Databases().ResetUserAuth(databaseID, userName, req)
+		if err != nil {
+			return err
+		}
+	}
+
+	return displayDatabaseUsers(c, *user)
+}
+
+func validateMySQLAuthPlugin(plugin string) error {
+	switch plugin {
+	case "", "caching_sha2_password", "mysql_native_password":
+		return nil
+	}
+	return fmt.Errorf("invalid MySQL auth plugin %q: must be caching_sha2_password or mysql_native_password", plugin)
+}
+
 // RunDatabaseUserDelete deletes a database user
 func RunDatabaseUserDelete(c *CmdConfig) error {
 	if len(c.Args) < 2 {
@@ -858,7 +1160,7 @@ func RunDatabaseUserDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if force || AskForConfirmDelete("database user", 1) == nil {
+	if force || AskForConfirmDelete("database user", 1, c.Args[1]) == nil {
 		databaseID := c.Args[0]
 		userID := c.Args[1]
 		return c.Databases().DeleteUser(databaseID, userID)
@@ -1020,19 +1322,8 @@ func RunDatabaseSlugOptions(c *CmdConfig) error {
 	}
 
 	layouts := make([]godo.DatabaseLayout, 0)
-	switch engine {
-	case "mongodb":
-		layouts = options.MongoDBOptions.Layouts
-	case "mysql":
-		layouts = options.MySQLOptions.Layouts
-	case "pg":
-		layouts = options.PostgresSQLOptions.Layouts
-	case "redis":
-		layouts = options.RedisOptions.Layouts
-	case "kafka":
-		layouts = options.KafkaOptions.Layouts
-	case "opensearch":
-		layouts = options.OpensearchOptions.Layouts
+	if engineOptions, ok := databaseEngineOptions(options, engine); ok {
+		layouts = engineOptions.Layouts
 	}
 
 	return displayDatabaseLayoutOptions(c, layouts)
@@ -1107,6 +1398,18 @@ We recommend starting with a pool size of about half your available connections
 		"The name of the specific database within the database cluster", requiredOpt())
 	cmdDatabasePoolCreate.Example = `The following example creates a connection pool named ` + "`" + `example-pool` + "`" + ` for a database cluster with the ID ` + "`" + `ca9f591d-f38h-5555-a0ef-1c02d1d1e35` + "`" + `. The command uses the ` + "`" + `--size` + "`" + ` flag to set the pool size to 10 and sets the user to the database's default user: doctl databases pool create ca9f591d-f38h-5555-a0ef-1c02d1d1e35 example-pool --size 10`
 
+	cmdDatabasePoolUpdate := CmdBuilder(cmd, RunDatabasePoolUpdate,
+		"update <database-cluster-id> <pool-name>", "Update a connection pool for a database", `Updates the mode, size, user, or target database of the specified connection pool. Settings that are not specified keep their current values.`+getPoolDetails, Writer,
+		aliasOpt("u"), displayerType(&displayers.DatabasePools{}))
+	AddStringFlag(cmdDatabasePoolUpdate, doctl.ArgDatabasePoolMode, "",
+		"", "The pool mode for the connection pool, such as `session`, `transaction`, and `statement`")
+	AddIntFlag(cmdDatabasePoolUpdate, doctl.ArgDatabasePoolSize, "", 0, "pool size")
+	AddStringFlag(cmdDatabasePoolUpdate, doctl.ArgDatabasePoolUserName, "", "",
+		"The username for the database user")
+	AddStringFlag(cmdDatabasePoolUpdate, doctl.ArgDatabasePoolDBName, "", "",
+		"The name of the specific database within the database cluster")
+	cmdDatabasePoolUpdate.Example = `The following example switches a connection pool named ` + "`" + `example-pool` + "`" + ` to transaction mode with 10 connections: doctl databases pool update ca9f591d-f38h-5555-a0ef-1c02d1d1e35 example-pool --mode transaction --size 10`
+
 	cmdDatabasePoolDelete := CmdBuilder(cmd, RunDatabasePoolDelete,
 		"delete <database-cluster-id> <pool-name>", "Delete a connection pool for a database", `Deletes the specified connection pool for the specified database cluster.`+getPoolDetails, Writer,
 		aliasOpt("rm"))
@@ -1202,6 +1505,88 @@ func buildDatabaseCreatePoolRequestFromArgs(c *CmdConfig) (*godo.DatabaseCreateP
 	return req, nil
 }
 
+// RunDatabasePoolUpdate updates a database pool, keeping the current value
+// of any setting that isn't specified
+func RunDatabasePoolUpdate(c *CmdConfig) error {
+	if len(c.Args) < 2 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	var (
+		databaseID = c.Args[0]
+		poolName   = c.Args[1]
+	)
+
+	pool, err := c.Databases().GetPool(databaseID, poolName)
+	if err != nil {
+		return err
+	}
+
+	req := &godo.DatabaseUpdatePoolRequest{
+		User:     pool.User,
+		Size:     pool.Size,
+		Database: pool.Database,
+		Mode:     pool.Mode,
+	}
+
+	if c.Doit.IsSet(doctl.ArgDatabasePoolMode) {
+		mode, err := c.Doit.GetString(c.NS, doctl.ArgDatabasePoolMode)
+		if err != nil {
+			return err
+		}
+		if err := validateDatabasePoolMode(mode); err != nil {
+			return err
+		}
+		req.Mode = mode
+	}
+
+	if c.Doit.IsSet(doctl.ArgDatabasePoolSize) {
+		size, err := c.Doit.GetInt(c.NS, doctl.ArgDatabasePoolSize)
+		if err != nil {
+			return err
+		}
+		if size < 1 {
+			return fmt.Errorf("--%s must be at least 1", doctl.ArgDatabasePoolSize)
+		}
+		req.Size = size
+	}
+
+	if c.Doit.IsSet(doctl.ArgDatabasePoolUserName) {
+		user, err := c.Doit.GetString(c.NS, doctl.ArgDatabasePoolUserName)
+		if err != nil {
+			return err
+		}
+		req.User = user
+	}
+
+	if c.Doit.IsSet(doctl.ArgDatabasePoolDBName) {
+		db, err := c.Doit.GetString(c.NS, doctl.ArgDatabasePoolDBName)
+		if err != nil {
+			return err
+		}
+		req.Database = db
+	}
+
+	if err := c.Databases().UpdatePool(databaseID, poolName, req); err != nil {
+		return err
+	}
+
+	pool, err = c.Databases().GetPool(databaseID, poolName)
+	if err != nil {
+		return err
+	}
+
+	return displayDatabasePools(c, *pool)
+}
+
+func validateDatabasePoolMode(mode string) error {
+	switch mode {
+	case "session", "transaction", "statement":
+		return nil
+	}
+	return fmt.Errorf("invalid pool mode %q: must be session, transaction, or statement", mode)
+}
+
 // RunDatabasePoolDelete deletes a database pool
 func RunDatabasePoolDelete(c *CmdConfig) error {
 	if len(c.Args) < 2 {
@@ -1213,7 +1598,7 @@ func RunDatabasePoolDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if force || AskForConfirmDelete("database pool", 1) == nil {
+	if force || AskForConfirmDelete("database pool", 1, c.Args[1]) == nil {
 		databaseID := c.Args[0]
 		poolID := c.Args[1]
 		return c.Databases().DeletePool(databaseID, poolID)
@@ -1330,13 +1715,20 @@ func RunDatabaseDBDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if force || AskForConfirmDelete("database", 1) == nil {
-		databaseID := c.Args[0]
-		dbID := c.Args[1]
-		return c.Databases().DeleteDB(databaseID, dbID)
+	databaseID := c.Args[0]
+	dbID := c.Args[1]
+	if !force {
+		db, err := c.Databases().Get(databaseID)
+		if err != nil {
+			return err
+		}
+		target := confirmTarget{Name: dbID, Details: fmt.Sprintf("in %s database cluster %s", db.EngineSlug, db.Name)}
+		if AskForConfirmDeleteTyped("database", target) != nil {
+			return errOperationAborted
+		}
 	}
 
-	return errOperationAborted
+	return c.Databases().DeleteDB(databaseID, dbID)
 }
 
 func displayDatabaseDBs(c *CmdConfig, dbs ...do.DatabaseDB) error {
@@ -1481,7 +1873,7 @@ func buildDatabaseCreateReplicaRequestFromArgs(c *CmdConfig) (*godo.DatabaseCrea
 	}
 	r.Size = size
 
-	region, err := c.Doit.GetString(c.NS, doctl.ArgRegionSlug)
+	region, err := regionFromArgs(c, doctl.ArgRegionSlug)
 	if err != nil {
 		return nil, err
 	}
@@ -1507,7 +1899,7 @@ func RunDatabaseReplicaDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if force || AskForConfirmDelete("database replica", 1) == nil {
+	if force || AskForConfirmDelete("database replica", 1, c.Args[1]) == nil {
 		databaseID := c.Args[0]
 		replicaID := c.Args[1]
 		return c.Databases().DeleteReplica(databaseID, replicaID)
@@ -1671,7 +2063,7 @@ func RunDatabaseTopicDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if force || AskForConfirmDelete("kafka topic", 1) == nil {
+	if force || AskForConfirmDelete("kafka topic", 1, c.Args[1]) == nil {
 		databaseID := c.Args[0]
 		topicName := c.Args[1]
 		return c.Databases().DeleteTopic(databaseID, topicName)
@@ -1913,7 +2305,7 @@ This command lists the following details for each partition of a given topic in
 	cmdDatabaseTopicCreate := CmdBuilder(cmd, RunDatabaseTopicCreate, "create <database-uuid> <topic-name>", "Creates a topic for a given kafka database",
 		"This command creates a kafka topic for the specified kafka database cluster, giving it the specified name. Example: doctl databases topics create <database-uuid> <topic-name> --replication_factor 2 --partition_count 4", Writer, aliasOpt("c"))
 	cmdDatabaseTopicUpdate := CmdBuilder(cmd, RunDatabaseTopicUpdate, "update <database-uuid> <topic-name>", "Updates a topic for a given kafka database",
-		"This command updates a kafka topic for the specified kafka database cluster. Example: doctl databases topics update <database-uuid> <topic-name>", Writer, aliasOpt("u"))
+		"This command updates a kafka topic for the specified kafka database cluster. Example: doctl databases topics update <database-uuid> <topic-name>", Writer, aliasOpt("u", "update-config"))
 	cmdsWithConfig := []*Command{cmdDatabaseTopicCreate, cmdDatabaseTopicUpdate}
 	for _, c := range cmdsWithConfig {
 		AddIntFlag(c, doctl.ArgDatabaseTopicReplicationFactor, "", 2, "Specifies the number of nodes to replicate data across the kafka cluster")
@@ -2268,7 +2660,7 @@ func waitForDatabaseReady(dbs do.DatabasesService, dbID string) error {
 		time.Sleep(10 * time.Second)
 	}
 
-	return fmt.Errorf(
+	return errTimeout(
 		"timeout waiting for database (%s) to enter `online` state",
 		dbID,
 	)
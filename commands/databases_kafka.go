@@ -0,0 +1,237 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/cobra"
+)
+
+// kafkaACLPermissions are the permissions a Kafka user can be granted on a
+// topic.
+var kafkaACLPermissions = []string{"admin", "consume", "produce", "produceconsume"}
+
+func databaseKafka() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "kafka",
+			Short: "Display commands to administer managed Kafka clusters",
+			Long: `The subcommands under ` + "`" + `doctl databases kafka` + "`" + ` manage the topics of Kafka database clusters and the topic permissions, or ACLs, of their users.
+
+Kafka users are created with ` + "`" + `doctl databases user create` + "`" + `, which returns the credentials to connect with.`,
+		},
+	}
+
+	cmd.AddCommand(databaseTopic())
+	cmd.AddCommand(databaseKafkaACL())
+
+	return cmd
+}
+
+func databaseKafkaACL() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "acl",
+			Short: "Display commands to manage topic permissions of Kafka users",
+			Long: `The subcommands under ` + "`" + `doctl databases kafka acl` + "`" + ` grant and revoke topic permissions for users of a Kafka database cluster.
+
+Each ACL pairs a topic, or a topic pattern such as ` + "`" + `logs.*` + "`" + `, with one of the permissions ` + "`" + `admin` + "`" + `, ` + "`" + `consume` + "`" + `, ` + "`" + `produce` + "`" + `, or ` + "`" + `produceconsume` + "`" + `.`,
+		},
+	}
+
+	cmdACLList := CmdBuilder(cmd, RunDatabaseKafkaACLList, "list <database-cluster-id>", "List the ACLs of Kafka users",
+		`Lists the topic permissions of each user of the specified Kafka database cluster.`, Writer, aliasOpt("ls"), displayerType(&displayers.DatabaseKafkaACLs{}))
+	AddStringFlag(cmdACLList, doctl.ArgDatabasePoolUserName, "", "", "Only list the ACLs of this user")
+	cmdACLList.Example = `The following example lists the ACLs of the user ` + "`" + `example-user` + "`" + `: doctl databases kafka acl list ca9f591d-f38h-5555-a0ef-1c02d1d1e35 --user example-user`
+
+	cmdACLAdd := CmdBuilder(cmd, RunDatabaseKafkaACLAdd, "add <database-cluster-id> <user-name>", "Grant topic permissions to a Kafka user",
+		`Adds ACLs to the specified Kafka user, keeping the ACLs the user already has.`, Writer, aliasOpt("a"), displayerType(&displayers.DatabaseKafkaACLs{}))
+	AddStringSliceFlag(cmdACLAdd, doctl.ArgDatabaseUserKafkaACLs, "", []string{}, "A comma-separated list of ACLs to add, in `topic:permission` format", requiredOpt())
+	cmdACLAdd.Example = `The following example allows the user ` + "`" + `example-user` + "`" + ` to consume from the ` + "`" + `orders` + "`" + ` topic: doctl databases kafka acl add ca9f591d-f38h-5555-a0ef-1c02d1d1e35 example-user --acl orders:consume`
+
+	cmdACLRemove := CmdBuilder(cmd, RunDatabaseKafkaACLRemove, "remove <database-cluster-id> <user-name> <acl-id|topic:permission>...", "Revoke topic permissions from a Kafka user",
+		`Removes the specified ACLs from a Kafka user. ACLs can be given by ID, as shown by `+"`"+`doctl databases kafka acl list`+"`"+`, or in `+"`"+`topic:permission`+"`"+` format.`, Writer, aliasOpt("rm"), displayerType(&displayers.DatabaseKafkaACLs{}))
+	cmdACLRemove.Example = `The following example revokes the ` + "`" + `orders:consume` + "`" + ` ACL from the user ` + "`" + `example-user` + "`" + `: doctl databases kafka acl remove ca9f591d-f38h-5555-a0ef-1c02d1d1e35 example-user orders:consume`
+
+	return cmd
+}
+
+// RunDatabaseKafkaACLList lists the ACLs of the users of a Kafka cluster
+func RunDatabaseKafkaACLList(c *CmdConfig) error {
+	if len(c.Args) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	databaseID := c.Args[0]
+
+	userName, err := c.Doit.GetString(c.NS, doctl.ArgDatabasePoolUserName)
+	if err != nil {
+		return err
+	}
+
+	var users do.DatabaseUsers
+	if userName != "" {
+		user, err := c.Databases().GetUser(databaseID, userName)
+		if err != nil {
+			return err
+		}
+		users = do.DatabaseUsers{*user}
+	} else {
+		users, err = c.Databases().ListUsers(databaseID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return displayDatabaseKafkaACLs(c, users...)
+}
+
+// RunDatabaseKafkaACLAdd grants topic permissions to a Kafka user
+func RunDatabaseKafkaACLAdd(c *CmdConfig) error {
+	if len(c.Args) < 2 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	var (
+		databaseID = c.Args[0]
+		userName   = c.Args[1]
+	)
+
+	added, err := buildDatabaseCreateKafkaUserACls(c)
+	if err != nil {
+		return err
+	}
+	if len(added) == 0 {
+		return fmt.Errorf("at least one --%s is required", doctl.ArgDatabaseUserKafkaACLs)
+	}
+	for _, acl := range added {
+		if err := validateKafkaACLPermission(acl.Permission); err != nil {
+			return err
+		}
+	}
+
+	user, err := c.Databases().GetUser(databaseID, userName)
+	if err != nil {
+		return err
+	}
+
+	acls := kafkaUserACLs(user)
+	for _, acl := range added {
+		if !hasKafkaACL(acls, acl.Topic, acl.Permission) {
+			acls = append(acls, acl)
+		}
+	}
+
+	return updateKafkaUserACLs(c, databaseID, userName, acls)
+}
+
+// RunDatabaseKafkaACLRemove revokes topic permissions from a Kafka user
+func RunDatabaseKafkaACLRemove(c *CmdConfig) error {
+	if len(c.Args) < 3 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	var (
+		databaseID = c.Args[0]
+		userName   = c.Args[1]
+	)
+
+	user, err := c.Databases().GetUser(databaseID, userName)
+	if err != nil {
+		return err
+	}
+
+	acls := kafkaUserACLs(user)
+	for _, ref := range c.Args[2:] {
+		var kept []*godo.KafkaACL
+		for _, acl := range acls {
+			if acl.ID != ref && acl.Topic+":"+acl.Permission != ref {
+				kept = append(kept, acl)
+			}
+		}
+		if len(kept) == len(acls) {
+			return fmt.Errorf("user %s has no ACL matching %q", userName, ref)
+		}
+		acls = kept
+	}
+
+	if len(acls) == 0 {
+		// An empty ACL list is omitted from the update request, so it
+		// can't be used to revoke a user's last permission.
+		return fmt.Errorf("cannot remove every ACL of user %s; delete the user or grant another ACL first", userName)
+	}
+
+	return updateKafkaUserACLs(c, databaseID, userName, acls)
+}
+
+func updateKafkaUserACLs(c *CmdConfig, databaseID, userName string, acls []*godo.KafkaACL) error {
+	// IDs are assigned by the API; send only what defines each ACL.
+	req := &godo.DatabaseUpdateUserRequest{Settings: &godo.DatabaseUserSettings{}}
+	for _, acl := range acls {
+		req.Settings.ACL = append(req.Settings.ACL, &godo.KafkaACL{Topic: acl.Topic, Permission: acl.Permission})
+	}
+
+	user, err := c.Databases().UpdateUser(databaseID, userName, req)
+	if err != nil {
+		return err
+	}
+
+	return displayDatabaseKafkaACLs(c, *user)
+}
+
+func kafkaUserACLs(user *do.DatabaseUser) []*godo.KafkaACL {
+	if user.Settings == nil {
+		return nil
+	}
+	return user.Settings.ACL
+}
+
+func hasKafkaACL(acls []*godo.KafkaACL, topic, permission string) bool {
+	for _, acl := range acls {
+		if acl.Topic == topic && acl.Permission == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func validateKafkaACLPermission(permission string) error {
+	for _, p := range kafkaACLPermissions {
+		if p == permission {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid Kafka ACL permission %q: must be one of %s", permission, strings.Join(kafkaACLPermissions, ", "))
+}
+
+func displayDatabaseKafkaACLs(c *CmdConfig, users ...do.DatabaseUser) error {
+	item := &displayers.DatabaseKafkaACLs{ACLs: []displayers.DatabaseKafkaUserACL{}}
+	for _, u := range users {
+		for _, acl := range kafkaUserACLs(&u) {
+			item.ACLs = append(item.ACLs, displayers.DatabaseKafkaUserACL{
+				User:       u.Name,
+				ID:         acl.ID,
+				Topic:      acl.Topic,
+				Permission: acl.Permission,
+			})
+		}
+	}
+	return c.Display(item)
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+)
+
+func testKafkaUser(acls ...*godo.KafkaACL) *do.DatabaseUser {
+	return &do.DatabaseUser{DatabaseUser: &godo.DatabaseUser{
+		Name:     "kafka-user",
+		Role:     "normal",
+		Settings: &godo.DatabaseUserSettings{ACL: acls},
+	}}
+}
+
+func TestDatabaseKafkaCommand(t *testing.T) {
+	cmd := databaseKafka()
+	assert.NotNil(t, cmd)
+	assertCommandNames(t, cmd, "topics", "acl")
+	assertCommandNames(t, databaseKafkaACL(), "list", "add", "remove")
+}
+
+func TestDatabaseKafkaACLList(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		users := do.DatabaseUsers{
+			*testKafkaUser(&godo.KafkaACL{ID: "acl-1", Topic: "orders", Permission: "consume"}),
+			testDBUser,
+		}
+		tm.databases.EXPECT().ListUsers(testDBCluster.ID).Return(users, nil)
+
+		var out bytes.Buffer
+		config.Out = &out
+		config.Args = append(config.Args, testDBCluster.ID)
+
+		err := RunDatabaseKafkaACLList(config)
+		assert.NoError(t, err)
+		assert.Equal(t, `User          ID       Topic     Permission
+kafka-user    acl-1    orders    consume
+`, out.String())
+	})
+}
+
+func TestDatabaseKafkaACLAdd(t *testing.T) {
+	// Existing ACLs are kept and duplicates are skipped
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		user := testKafkaUser(&godo.KafkaACL{ID: "acl-1", Topic: "orders", Permission: "consume"})
+		r := &godo.DatabaseUpdateUserRequest{Settings: &godo.DatabaseUserSettings{ACL: []*godo.KafkaACL{
+			{Topic: "orders", Permission: "consume"},
+			{Topic: "logs.*", Permission: "produce"},
+		}}}
+		tm.databases.EXPECT().GetUser(testDBCluster.ID, "kafka-user").Return(user, nil)
+		tm.databases.EXPECT().UpdateUser(testDBCluster.ID, "kafka-user", r).Return(user, nil)
+
+		config.Args = append(config.Args, testDBCluster.ID, "kafka-user")
+		config.Doit.Set(config.NS, doctl.ArgDatabaseUserKafkaACLs, []string{"orders:consume", "logs.*:produce"})
+
+		err := RunDatabaseKafkaACLAdd(config)
+		assert.NoError(t, err)
+	})
+
+	// Invalid permission
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, testDBCluster.ID, "kafka-user")
+		config.Doit.Set(config.NS, doctl.ArgDatabaseUserKafkaACLs, []string{"orders:read"})
+
+		err := RunDatabaseKafkaACLAdd(config)
+		assert.EqualError(t, err, `invalid Kafka ACL permission "read": must be one of admin, consume, produce, produceconsume`)
+	})
+}
+
+func TestDatabaseKafkaACLRemove(t *testing.T) {
+	user := testKafkaUser(
+		&godo.KafkaACL{ID: "acl-1", Topic: "orders", Permission: "consume"},
+		&godo.KafkaACL{ID: "acl-2", Topic: "logs.*", Permission: "produce"},
+		&godo.KafkaACL{ID: "acl-3", Topic: "audit", Permission: "admin"},
+	)
+
+	// Remove by ID and by topic:permission
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		r := &godo.DatabaseUpdateUserRequest{Settings: &godo.DatabaseUserSettings{ACL: []*godo.KafkaACL{
+			{Topic: "logs.*", Permission: "produce"},
+		}}}
+		tm.databases.EXPECT().GetUser(testDBCluster.ID, "kafka-user").Return(user, nil)
+		tm.databases.EXPECT().UpdateUser(testDBCluster.ID, "kafka-user", r).Return(user, nil)
+
+		config.Args = append(config.Args, testDBCluster.ID, "kafka-user", "acl-1", "audit:admin")
+
+		err := RunDatabaseKafkaACLRemove(config)
+		assert.NoError(t, err)
+	})
+
+	// Unknown ACL
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.databases.EXPECT().GetUser(testDBCluster.ID, "kafka-user").Return(user, nil)
+
+		config.Args = append(config.Args, testDBCluster.ID, "kafka-user", "orders:admin")
+
+		err := RunDatabaseKafkaACLRemove(config)
+		assert.EqualError(t, err, `user kafka-user has no ACL matching "orders:admin"`)
+	})
+
+	// Last ACL
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.databases.EXPECT().GetUser(testDBCluster.ID, "kafka-user").Return(user, nil)
+
+		config.Args = append(config.Args, testDBCluster.ID, "kafka-user", "acl-1", "acl-2", "acl-3")
+
+		err := RunDatabaseKafkaACLRemove(config)
+		assert.EqualError(t, err, "cannot remove every ACL of user kafka-user; delete the user or grant another ACL first")
+	})
+}
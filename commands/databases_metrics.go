@@ -0,0 +1,626 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+)
+
+// databaseMetricsHTTPClient returns the client used to scrape the metrics
+// endpoints of a cluster, trusting the cluster's CA certificate. It is
+// replaced in tests to trust a test server.
+var databaseMetricsHTTPClient = func(ca []byte) (*http.Client, error) {
+	pool, err := clusterCertPool(ca)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+	}, nil
+}
+
+// fetchSlowQueries reads the slow queries of a cluster. It is replaced in
+// tests.
+var fetchSlowQueries = querySlowQueries
+
+// databaseMetric describes how a metric reported by `doctl databases
+// metrics` is read from the samples exposed by a cluster.
+type databaseMetric struct {
+	// samples are the names of the samples holding the metric. Each engine
+	// exposes one of them.
+	samples []string
+	// match selects the samples with these label values.
+	match map[string]string
+	// label is the label that tells apart the samples of the metric, such as
+	// the database or the mount point.
+	label string
+	// value converts a sample value, if set.
+	value func(float64) float64
+	unit  string
+}
+
+var databaseMetrics = map[string]databaseMetric{
+	"connections": {
+		samples: []string{"pg_stat_database_numbackends", "mysql_threads_connected", "redis_clients", "mongodb_connections_current"},
+		label:   "datname",
+	},
+	"cpu": {
+		samples: []string{"cpu_usage_idle"},
+		match:   map[string]string{"cpu": "cpu-total"},
+		value:   func(idle float64) float64 { return 100 - idle },
+		unit:    "%",
+	},
+	"disk": {
+		samples: []string{"disk_used_percent"},
+		label:   "path",
+		unit:    "%",
+	},
+}
+
+// databaseMetricNames are the keys of databaseMetrics, in the order they are
+// reported.
+var databaseMetricNames = []string{"connections", "cpu", "disk"}
+
+// RunDatabaseMetrics reports the current metrics of a database cluster.
+func RunDatabaseMetrics(c *CmdConfig) error {
+	if len(c.Args) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	metric, err := c.Doit.GetString(c.NS, doctl.ArgDatabaseMetric)
+	if err != nil {
+		return err
+	}
+	names := databaseMetricNames
+	if metric != "" {
+		if _, ok := databaseMetrics[metric]; !ok {
+			return fmt.Errorf("invalid --%s %q: must be one of %s", doctl.ArgDatabaseMetric, metric, strings.Join(databaseMetricNames, ", "))
+		}
+		names = []string{metric}
+	}
+
+	database, err := c.Databases().Get(c.Args[0])
+	if err != nil {
+		return err
+	}
+	if len(database.MetricsEndpoints) == 0 {
+		return fmt.Errorf("database cluster %s has no metrics endpoints", database.Name)
+	}
+
+	creds, err := c.Databases().GetMetricsCredentials()
+	if err != nil {
+		return err
+	}
+	ca, err := c.Databases().GetCA(database.ID)
+	if err != nil {
+		return err
+	}
+	client, err := databaseMetricsHTTPClient(ca)
+	if err != nil {
+		return err
+	}
+
+	metrics := []displayers.DatabaseMetric{}
+	for _, ep := range database.MetricsEndpoints {
+		addr := net.JoinHostPort(ep.Host, strconv.Itoa(ep.Port))
+		samples, err := scrapeDatabaseMetrics(client, "https://"+addr+"/metrics", creds)
+		if err != nil {
+			return fmt.Errorf("scraping metrics from %s: %w", addr, err)
+		}
+		for _, name := range names {
+			metrics = append(metrics, databaseMetricValues(ep.Host, name, samples)...)
+		}
+	}
+
+	return c.Display(&displayers.DatabaseMetrics{Metrics: metrics})
+}
+
+// promSample is a sample in the Prometheus text exposition format.
+type promSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+func scrapeDatabaseMetrics(client *http.Client, url string, creds *do.DatabaseMetricsCredentials) ([]promSample, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(creds.BasicAuthUsername, creds.BasicAuthPassword)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metrics endpoint returned %s", resp.Status)
+	}
+	return parsePromText(resp.Body)
+}
+
+// parsePromText parses samples in the Prometheus text exposition format.
+// Samples whose value is not a number are skipped.
+func parsePromText(r io.Reader) ([]promSample, error) {
+	var samples []promSample
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		s := promSample{labels: map[string]string{}}
+		end := strings.IndexAny(line, "{ \t")
+		if end <= 0 {
+			return nil, fmt.Errorf("line %d: missing sample value", n)
+		}
+		s.name, line = line[:end], line[end:]
+
+		if strings.HasPrefix(line, "{") {
+			rest, err := parsePromLabels(line[1:], s.labels)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			line = rest
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("line %d: missing sample value", n)
+		}
+		v, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+		s.value = v
+		samples = append(samples, s)
+	}
+	return samples, scanner.Err()
+}
+
+// parsePromLabels parses the labels following the opening brace of a sample
+// into labels, and returns the rest of the line after the closing brace.
+func parsePromLabels(s string, labels map[string]string) (string, error) {
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if strings.HasPrefix(s, "}") {
+			return s[1:], nil
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 || len(s) < eq+2 || s[eq+1] != '"' {
+			return "", errors.New("malformed labels")
+		}
+		name := strings.TrimSpace(s[:eq])
+		s = s[eq+2:]
+
+		var value strings.Builder
+		closed := false
+		for i := 0; i < len(s); i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				if s[i] == 'n' {
+					value.WriteByte('\n')
+				} else {
+					value.WriteByte(s[i])
+				}
+				continue
+			}
+			if s[i] == '"' {
+				s, closed = s[i+1:], true
+				break
+			}
+			value.WriteByte(s[i])
+		}
+		if !closed {
+			return "", fmt.Errorf("unterminated value of label %q", name)
+		}
+		labels[name] = value.String()
+	}
+}
+
+// databaseMetricValues returns the values of the named metric in samples.
+func databaseMetricValues(host, name string, samples []promSample) []displayers.DatabaseMetric {
+	m := databaseMetrics[name]
+
+	var values []displayers.DatabaseMetric
+	for _, s := range samples {
+		if !contains(m.samples, s.name) || !promLabelsMatch(s.labels, m.match) {
+			continue
+		}
+		v := s.value
+		if m.value != nil {
+			v = m.value(v)
+		}
+		values = append(values, displayers.DatabaseMetric{
+			Host:   host,
+			Metric: name,
+			Label:  s.labels[m.label],
+			Value:  v,
+			Unit:   m.unit,
+		})
+	}
+	sort.SliceStable(values, func(i, j int) bool { return values[i].Label < values[j].Label })
+	return values
+}
+
+func promLabelsMatch(labels, match map[string]string) bool {
+	for k, v := range match {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// slowQuery is a statement read from the query statistics or slow query log
+// of a cluster.
+type slowQuery struct {
+	query   string
+	calls   int64
+	totalMS float64
+}
+
+// RunDatabaseSlowQueries reports the statements of a database cluster that
+// took the most time.
+func RunDatabaseSlowQueries(c *CmdConfig) error {
+	if len(c.Args) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	since, err := c.Doit.GetDuration(c.NS, doctl.ArgDatabaseSlowQueriesSince)
+	if err != nil {
+		return err
+	}
+	top, err := c.Doit.GetInt(c.NS, doctl.ArgDatabaseSlowQueriesTop)
+	if err != nil {
+		return err
+	}
+	if top < 1 {
+		return fmt.Errorf("--%s must be at least 1", doctl.ArgDatabaseSlowQueriesTop)
+	}
+
+	database, err := c.Databases().Get(c.Args[0])
+	if err != nil {
+		return err
+	}
+	switch database.EngineSlug {
+	case "pg":
+		if c.Doit.IsSet(doctl.ArgDatabaseSlowQueriesSince) {
+			warn("PostgreSQL reports query statistics since they were last reset; --%s is ignored", doctl.ArgDatabaseSlowQueriesSince)
+		}
+	case "mysql":
+	default:
+		return fmt.Errorf("slow query reports are only available for PostgreSQL and MySQL clusters, and %s runs %s", database.Name, database.EngineSlug)
+	}
+	if database.Connection == nil || database.Connection.Host == "" {
+		return fmt.Errorf("database cluster %s has no connection details yet", database.Name)
+	}
+
+	ca, err := c.Databases().GetCA(database.ID)
+	if err != nil {
+		return err
+	}
+	queries, err := fetchSlowQueries(database.EngineSlug, database.Connection, ca, since)
+	if err != nil {
+		return err
+	}
+
+	return c.Display(&displayers.DatabaseSlowQueries{Queries: topSlowQueries(queries, top)})
+}
+
+// topSlowQueries groups queries by their normalized text and returns the top
+// groups by total execution time.
+func topSlowQueries(queries []slowQuery, top int) []displayers.DatabaseSlowQuery {
+	byText := map[string]*displayers.DatabaseSlowQuery{}
+	var groups []*displayers.DatabaseSlowQuery
+	for _, q := range queries {
+		text := normalizeQuery(q.query)
+		g, ok := byText[text]
+		if !ok {
+			g = &displayers.DatabaseSlowQuery{Query: text}
+			byText[text] = g
+			groups = append(groups, g)
+		}
+		g.Calls += q.calls
+		g.TotalMS += q.totalMS
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].TotalMS > groups[j].TotalMS })
+	if len(groups) > top {
+		groups = groups[:top]
+	}
+
+	out := make([]displayers.DatabaseSlowQuery, 0, len(groups))
+	for _, g := range groups {
+		if g.Calls > 0 {
+			g.MeanMS = g.TotalMS / float64(g.Calls)
+		}
+		out = append(out, *g)
+	}
+	return out
+}
+
+// queryListRE matches lists of placeholders, such as the values of an IN
+// clause, which are reported as a single placeholder.
+var queryListRE = regexp.MustCompile(`\(\?(?:\s*,\s*\?)+\)`)
+
+// normalizeQuery replaces the literals and placeholders in a SQL statement
+// with ?, and collapses whitespace, so statements that differ only in their
+// parameters are reported together.
+func normalizeQuery(q string) string {
+	var b strings.Builder
+	isIdent := func(c byte) bool {
+		return c == '_' || c == '$' || c == '.' || (c >= '0' && c <= '9') || (c|0x20 >= 'a' && c|0x20 <= 'z')
+	}
+
+	for i := 0; i < len(q); {
+		c := q[i]
+		switch {
+		case c == '\'':
+			i++
+			for i < len(q) {
+				if q[i] == '\\' {
+					i += 2
+					continue
+				}
+				if q[i] == '\'' {
+					if i+1 < len(q) && q[i+1] == '\'' {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+			i++
+			b.WriteByte('?')
+		case c == '$' && i+1 < len(q) && q[i+1] >= '0' && q[i+1] <= '9' && (i == 0 || !isIdent(q[i-1])):
+			i++
+			for i < len(q) && q[i] >= '0' && q[i] <= '9' {
+				i++
+			}
+			b.WriteByte('?')
+		case c >= '0' && c <= '9' && (i == 0 || !isIdent(q[i-1])):
+			for i < len(q) && (isIdent(q[i]) || ((q[i] == '-' || q[i] == '+') && (q[i-1]|0x20) == 'e')) {
+				i++
+			}
+			b.WriteByte('?')
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			for i < len(q) && (q[i] == ' ' || q[i] == '\t' || q[i] == '\n' || q[i] == '\r') {
+				i++
+			}
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	return queryListRE.ReplaceAllString(strings.TrimSpace(b.String()), "(?)")
+}
+
+// runDatabaseClient runs a database client such as psql with args and env
+// added to the environment, and returns its output. It is replaced in tests.
+var runDatabaseClient = func(name string, args, env []string) (string, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("%s was not found; install it to read the slow queries of a database cluster", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Env = append(os.Environ(), env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", name, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.String(), nil
+}
+
+// querySlowQueries reads the query statistics of a PostgreSQL cluster, or
+// the slow query log of a MySQL cluster since the given duration ago, with
+// the psql or mysql client. The server's certificate is verified against the
+// cluster's CA certificate.
+func querySlowQueries(engine string, conn *godo.DatabaseConnection, ca []byte, since time.Duration) ([]slowQuery, error) {
+	caFile, err := os.CreateTemp("", "doctl-ca-*.crt")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(caFile.Name())
+	_, err = caFile.Write(ca)
+	if cerr := caFile.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch engine {
+	case "pg":
+		return queryPostgresSlowQueries(conn, caFile.Name())
+	case "mysql":
+		return queryMySQLSlowQueries(conn, caFile.Name(), since)
+	default:
+		return nil, fmt.Errorf("slow query reports are not available for %s clusters", engine)
+	}
+}
+
+// psqlFieldSep and psqlRecordSep separate the fields and records printed by
+// psql, since statements may contain tabs and newlines.
+const (
+	psqlFieldSep  = "\x1f"
+	psqlRecordSep = "\x1e"
+)
+
+func queryPostgresSlowQueries(conn *godo.DatabaseConnection, caFile string) ([]slowQuery, error) {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s dbname=%s sslmode=verify-full sslrootcert=%s",
+		conn.Host, conn.Port, conn.User, conn.Database, caFile)
+	env := []string{"PGPASSWORD=" + conn.Password}
+	psql := func(query string) ([][]string, error) {
+		out, err := runDatabaseClient("psql", []string{
+			"--no-psqlrc", "--quiet", "--no-align", "--tuples-only",
+			"--field-separator", psqlFieldSep, "--record-separator", psqlRecordSep,
+			"--set", "ON_ERROR_STOP=1", "--command", query, dsn,
+		}, env)
+		if err != nil {
+			return nil, err
+		}
+		return splitRows(strings.TrimSuffix(out, "\n"), psqlRecordSep, psqlFieldSep), nil
+	}
+
+	rows, err := psql("SHOW server_version_num")
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) != 1 || len(rows[0]) != 1 {
+		return nil, errors.New("unable to read the PostgreSQL server version")
+	}
+	version, err := strconv.Atoi(rows[0][0])
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the PostgreSQL server version: %w", err)
+	}
+	// pg_stat_statements renamed total_time to total_exec_time in PostgreSQL 13.
+	column := "total_exec_time"
+	if version < 130000 {
+		column = "total_time"
+	}
+
+	rows, err = psql(fmt.Sprintf("SELECT query, calls, %[1]s FROM pg_stat_statements ORDER BY %[1]s DESC LIMIT 1000", column))
+	if err != nil {
+		if strings.Contains(err.Error(), `relation "pg_stat_statements" does not exist`) {
+			return nil, errors.New("the pg_stat_statements extension is not enabled; enable it with `CREATE EXTENSION pg_stat_statements;`")
+		}
+		return nil, err
+	}
+	return parseSlowQueries(rows)
+}
+
+func queryMySQLSlowQueries(conn *godo.DatabaseConnection, caFile string, since time.Duration) ([]slowQuery, error) {
+	env := []string{"MYSQL_PWD=" + conn.Password}
+	mysql := func(query string) ([][]string, error) {
+		out, err := runDatabaseClient("mysql", []string{
+			"--batch", "--skip-column-names", "--raw",
+			"--host", conn.Host, "--port", strconv.Itoa(conn.Port), "--user", conn.User,
+			"--ssl-mode=VERIFY_IDENTITY", "--ssl-ca", caFile,
+			"--execute", query, conn.Database,
+		}, env)
+		if err != nil {
+			return nil, err
+		}
+		return splitRows(strings.TrimSuffix(out, "\n"), "\n", "\t"), nil
+	}
+
+	rows, err := mysql("SELECT @@global.slow_query_log, @@global.log_output, @@global.long_query_time")
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) != 1 || len(rows[0]) != 3 {
+		return nil, errors.New("unable to read the slow query log settings")
+	}
+	enabled, output, longQueryTime := rows[0][0], rows[0][1], rows[0][2]
+	if enabled != "1" {
+		return nil, errors.New("the slow query log is disabled; enable the cluster's `slow_query_log` setting with `doctl databases configuration update`")
+	}
+	if !strings.Contains(strings.ToUpper(output), "TABLE") {
+		return nil, fmt.Errorf("the slow query log is written to %s rather than to the mysql.slow_log table, so it can't be read", output)
+	}
+
+	// With --raw, line breaks and tabs in statements would split their rows,
+	// so they are replaced with spaces.
+	rows, err = mysql(fmt.Sprintf(`SELECT REPLACE(REPLACE(REPLACE(CONVERT(sql_text USING utf8mb4), '\r', ' '), '\n', ' '), '\t', ' '), 1, (TIME_TO_SEC(query_time) + MICROSECOND(query_time) / 1000000) * 1000 FROM mysql.slow_log WHERE start_time >= NOW() - INTERVAL %d SECOND`, int64(since.Seconds())))
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no statements were logged in the slow query log in the last %s; only statements taking longer than the cluster's `long_query_time` of %ss are logged", since, longQueryTime)
+	}
+	return parseSlowQueries(rows)
+}
+
+// splitRows splits the output of a database client into records and fields.
+func splitRows(out, recordSep, fieldSep string) [][]string {
+	if out == "" {
+		return nil
+	}
+	var rows [][]string
+	for _, record := range strings.Split(out, recordSep) {
+		rows = append(rows, strings.Split(record, fieldSep))
+	}
+	return rows
+}
+
+// parseSlowQueries parses rows of a statement, its number of calls and the
+// total time it took in milliseconds.
+func parseSlowQueries(rows [][]string) ([]slowQuery, error) {
+	queries := make([]slowQuery, 0, len(rows))
+	for _, row := range rows {
+		if len(row) != 3 {
+			return nil, fmt.Errorf("unexpected query statistics row with %d fields", len(row))
+		}
+		calls, err := strconv.ParseInt(row[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		totalMS, err := strconv.ParseFloat(row[2], 64)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, slowQuery{query: row[0], calls: calls, totalMS: totalMS})
+	}
+	return queries, nil
+}
+
+// clusterCertPool returns the system certificate pool with a cluster's CA
+// certificate added.
+func clusterCertPool(ca []byte) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if len(ca) > 0 && !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("unable to parse the database cluster's CA certificate")
+	}
+	return pool, nil
+}
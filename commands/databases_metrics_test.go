@@ -0,0 +1,269 @@
+package commands
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDBMetrics = `# HELP cpu_usage_idle Telegraf collected metric
+# TYPE cpu_usage_idle gauge
+cpu_usage_idle{cpu="cpu-total",host="db-1"} 87.5
+cpu_usage_idle{cpu="cpu0",host="db-1"} 80
+disk_used_percent{device="vda1",path="/"} 42.25
+pg_stat_database_numbackends{datname="defaultdb",host="db-1"} 12
+pg_stat_database_numbackends{datname="app \"prod\"",host="db-1"} 3 1715000000000
+pg_stat_database_xact_commit{datname="defaultdb"} NaN
+`
+
+func TestParsePromText(t *testing.T) {
+	samples, err := parsePromText(strings.NewReader(testDBMetrics))
+	require.NoError(t, err)
+	require.Len(t, samples, 6)
+	assert.Equal(t, promSample{name: "cpu_usage_idle", labels: map[string]string{"cpu": "cpu-total", "host": "db-1"}, value: 87.5}, samples[0])
+	assert.Equal(t, `app "prod"`, samples[4].labels["datname"])
+	assert.Equal(t, 3.0, samples[4].value)
+
+	_, err = parsePromText(strings.NewReader(`up{job="db 1`))
+	assert.Error(t, err)
+}
+
+func TestDatabaseMetrics(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "metrics", user)
+		assert.Equal(t, "secret", pass)
+		assert.Equal(t, "/metrics", r.URL.Path)
+		w.Write([]byte(testDBMetrics))
+	}))
+	defer server.Close()
+
+	client := databaseMetricsHTTPClient
+	databaseMetricsHTTPClient = func(ca []byte) (*http.Client, error) {
+		assert.Equal(t, []byte("ca"), ca)
+		return server.Client(), nil
+	}
+	defer func() { databaseMetricsHTTPClient = client }()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+	cluster := &do.Database{Database: &godo.Database{
+		ID:               testDBCluster.ID,
+		Name:             "metrics-db",
+		MetricsEndpoints: []*godo.ServiceAddress{{Host: u.Hostname(), Port: port}},
+	}}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.databases.EXPECT().Get(testDBCluster.ID).Return(cluster, nil)
+		tm.databases.EXPECT().GetMetricsCredentials().Return(&do.DatabaseMetricsCredentials{
+			DatabaseMetricsCredentials: &godo.DatabaseMetricsCredentials{BasicAuthUsername: "metrics", BasicAuthPassword: "secret"},
+		}, nil)
+		tm.databases.EXPECT().GetCA(testDBCluster.ID).Return([]byte("ca"), nil)
+
+		var out bytes.Buffer
+		config.Out = &out
+		config.Args = append(config.Args, testDBCluster.ID)
+
+		require.NoError(t, RunDatabaseMetrics(config))
+		assert.Equal(t, `Host         Metric         Label         Value
+127.0.0.1    connections    app "prod"    3
+127.0.0.1    connections    defaultdb     12
+127.0.0.1    cpu                          12.5%
+127.0.0.1    disk           /             42.2%
+`, out.String())
+	})
+}
+
+func TestDatabaseMetricsInvalidMetric(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, testDBCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgDatabaseMetric, "memory")
+
+		err := RunDatabaseMetrics(config)
+		assert.EqualError(t, err, `invalid --metric "memory": must be one of connections, cpu, disk`)
+	})
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT * FROM users WHERE id = 42", "SELECT * FROM users WHERE id = ?"},
+		{"SELECT *\n  FROM  t1\tWHERE name = 'O''Brien' AND note = 'a\\'b'", "SELECT * FROM t1 WHERE name = ? AND note = ?"},
+		{"SELECT * FROM orders WHERE id IN ($1, $2, $3) AND total > $4", "SELECT * FROM orders WHERE id IN (?) AND total > ?"},
+		{"UPDATE stats SET ratio = 1.5e-3, v2 = -7 WHERE col_1 = 0x1F", "UPDATE stats SET ratio = ?, v2 = -? WHERE col_1 = ?"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, normalizeQuery(tt.query), tt.query)
+	}
+}
+
+func TestDatabaseSlowQueries(t *testing.T) {
+	mysqlCluster := &do.Database{Database: &godo.Database{
+		ID:         testDBCluster.ID,
+		Name:       "shop",
+		EngineSlug: "mysql",
+		Connection: testGODOConnection,
+	}}
+
+	fetch := fetchSlowQueries
+	fetchSlowQueries = func(engine string, conn *godo.DatabaseConnection, ca []byte, since time.Duration) ([]slowQuery, error) {
+		assert.Equal(t, "mysql", engine)
+		assert.Equal(t, 24*time.Hour, since)
+		return []slowQuery{
+			{query: "SELECT * FROM carts WHERE user_id = 1", calls: 1, totalMS: 1200},
+			{query: "SELECT * FROM carts WHERE user_id = 2", calls: 1, totalMS: 800},
+			{query: "DELETE FROM sessions WHERE expires < '2024-05-01'", calls: 1, totalMS: 1500},
+			{query: "SELECT 1", calls: 1, totalMS: 2},
+		}, nil
+	}
+	defer func() { fetchSlowQueries = fetch }()
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.databases.EXPECT().Get(testDBCluster.ID).Return(mysqlCluster, nil)
+		tm.databases.EXPECT().GetCA(testDBCluster.ID).Return([]byte("ca"), nil)
+
+		var out bytes.Buffer
+		config.Out = &out
+		config.Args = append(config.Args, testDBCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgDatabaseSlowQueriesSince, 24*time.Hour)
+		config.Doit.Set(config.NS, doctl.ArgDatabaseSlowQueriesTop, 2)
+
+		require.NoError(t, RunDatabaseSlowQueries(config))
+		assert.Equal(t, `Calls    Total (ms)    Mean (ms)    Query
+2        2000.0        1000.0       SELECT * FROM carts WHERE user_id = ?
+1        1500.0        1500.0       DELETE FROM sessions WHERE expires < ?
+`, out.String())
+	})
+}
+
+func TestDatabaseSlowQueriesUnsupportedEngine(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.databases.EXPECT().Get(testKafkaDBCluster.ID).Return(&testKafkaDBCluster, nil)
+		config.Args = append(config.Args, testKafkaDBCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgDatabaseSlowQueriesTop, 10)
+
+		err := RunDatabaseSlowQueries(config)
+		assert.EqualError(t, err, "slow query reports are only available for PostgreSQL and MySQL clusters, and kafka-db-cluster runs kafka")
+	})
+}
+
+// fakeDatabaseClient replaces runDatabaseClient with a client that answers
+// queries containing a key of responses, and records the queries it runs.
+func fakeDatabaseClient(t *testing.T, responses map[string]string) *[]string {
+	var queries []string
+	run := runDatabaseClient
+	runDatabaseClient = func(name string, args, env []string) (string, error) {
+		query := args[len(args)-2]
+		queries = append(queries, query)
+		for k, v := range responses {
+			if strings.Contains(query, k) {
+				return v, nil
+			}
+		}
+		t.Fatalf("unexpected %s query: %s", name, query)
+		return "", nil
+	}
+	t.Cleanup(func() { runDatabaseClient = run })
+	return &queries
+}
+
+func TestQuerySlowQueriesPostgres(t *testing.T) {
+	t.Run("verifies the server against the cluster CA", func(t *testing.T) {
+		run := runDatabaseClient
+		defer func() { runDatabaseClient = run }()
+		runDatabaseClient = func(name string, args, env []string) (string, error) {
+			assert.Equal(t, "psql", name)
+			assert.Contains(t, args[len(args)-1], "sslmode=verify-full sslrootcert=")
+			assert.Equal(t, []string{"PGPASSWORD=foobaz"}, env)
+			if strings.Contains(args[len(args)-2], "server_version_num") {
+				return "130004\n", nil
+			}
+			return "", nil
+		}
+		_, err := querySlowQueries("pg", testGODOConnection, []byte("ca"), 0)
+		assert.NoError(t, err)
+	})
+
+	t.Run("uses total_exec_time from PostgreSQL 13", func(t *testing.T) {
+		queries := fakeDatabaseClient(t, map[string]string{
+			"server_version_num": "150002\n",
+			"pg_stat_statements": "SELECT *\n FROM t\x1f4\x1f12.5\x1eSELECT 1\x1f1\x1f0.1\n",
+		})
+		got, err := querySlowQueries("pg", testGODOConnection, nil, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []slowQuery{{query: "SELECT *\n FROM t", calls: 4, totalMS: 12.5}, {query: "SELECT 1", calls: 1, totalMS: 0.1}}, got)
+		assert.Contains(t, (*queries)[1], "total_exec_time")
+	})
+
+	t.Run("falls back to total_time before PostgreSQL 13", func(t *testing.T) {
+		queries := fakeDatabaseClient(t, map[string]string{
+			"server_version_num": "120015\n",
+			"pg_stat_statements": "",
+		})
+		got, err := querySlowQueries("pg", testGODOConnection, nil, 0)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+		assert.Equal(t, "SELECT query, calls, total_time FROM pg_stat_statements ORDER BY total_time DESC LIMIT 1000", (*queries)[1])
+	})
+}
+
+func TestQuerySlowQueriesMySQL(t *testing.T) {
+	t.Run("reads the slow query log", func(t *testing.T) {
+		queries := fakeDatabaseClient(t, map[string]string{
+			"@@global.slow_query_log": "1\tFILE,TABLE\t10.000000\n",
+			"mysql.slow_log":          "SELECT * FROM carts\t1\t1200.5000\n",
+		})
+		got, err := querySlowQueries("mysql", testGODOConnection, nil, 24*time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, []slowQuery{{query: "SELECT * FROM carts", calls: 1, totalMS: 1200.5}}, got)
+		assert.Contains(t, (*queries)[1], "INTERVAL 86400 SECOND")
+	})
+
+	tests := []struct {
+		name     string
+		settings string
+		log      string
+		err      string
+	}{
+		{
+			name:     "disabled",
+			settings: "0\tTABLE\t10.000000\n",
+			err:      "the slow query log is disabled; enable the cluster's `slow_query_log` setting with `doctl databases configuration update`",
+		},
+		{
+			name:     "not written to a table",
+			settings: "1\tFILE\t10.000000\n",
+			err:      "the slow query log is written to FILE rather than to the mysql.slow_log table, so it can't be read",
+		},
+		{
+			name:     "empty",
+			settings: "1\tTABLE\t10.000000\n",
+			err:      "no statements were logged in the slow query log in the last 1h0m0s; only statements taking longer than the cluster's `long_query_time` of 10.000000s are logged",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeDatabaseClient(t, map[string]string{
+				"@@global.slow_query_log": tt.settings,
+				"mysql.slow_log":          tt.log,
+			})
+			_, err := querySlowQueries("mysql", testGODOConnection, nil, time.Hour)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,377 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/spf13/cobra"
+)
+
+// openSearchHTTPClient is replaced in tests to trust a test server.
+var openSearchHTTPClient = http.DefaultClient
+
+var openSearchRetentionRE = regexp.MustCompile(`^[1-9][0-9]*[dhm]$`)
+
+func databaseOpenSearch() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:     "opensearch",
+			Aliases: []string{"os"},
+			Short:   "Display commands to administer managed OpenSearch clusters",
+			Long: `The subcommands under ` + "`" + `doctl databases opensearch` + "`" + ` call the OpenSearch API of a managed OpenSearch cluster, authenticating with the cluster's admin credentials.
+
+They list indices, apply Index State Management (ISM) retention policies, and report cluster health.`,
+		},
+	}
+
+	cmdIndices := CmdBuilder(cmd, RunDatabaseOpenSearchIndices, "indices <database-cluster-id>", "List the indices of an OpenSearch cluster",
+		`Lists the indices of the specified OpenSearch cluster with their health, status, document count, and size in bytes.`, Writer,
+		aliasOpt("index", "ls"), displayerType(&displayers.OpenSearchIndices{}))
+	AddStringFlag(cmdIndices, doctl.ArgOpenSearchIndexPattern, "", "", "Only list indices matching this name or wildcard pattern, such as `logs-*`")
+	cmdIndices.Example = `The following example lists the log indices of an OpenSearch cluster: doctl databases opensearch indices ca9f591d-f38h-5555-a0ef-1c02d1d1e35 --index-pattern "logs-*"`
+
+	ism := &Command{
+		Command: &cobra.Command{
+			Use:   "ism",
+			Short: "Display commands to manage Index State Management policies",
+			Long:  `The subcommands under ` + "`" + `doctl databases opensearch ism` + "`" + ` manage Index State Management (ISM) policies, which OpenSearch uses to delete indices once they reach a given age.`,
+		},
+	}
+	cmd.AddCommand(ism)
+
+	cmdISMApply := CmdBuilder(ism, RunDatabaseOpenSearchISMApply, "apply <database-cluster-id>", "Apply a retention policy to OpenSearch indices",
+		`Creates or updates an ISM policy that deletes indices matching `+"`"+`--index-pattern`+"`"+` once they are older than `+"`"+`--retention`+"`"+`. The policy is attached to the matching indices that already exist, and to new matching indices as they are created.`, Writer,
+		aliasOpt("a"))
+	AddStringFlag(cmdISMApply, doctl.ArgOpenSearchIndexPattern, "", "", "The name or wildcard pattern of the indices to apply the policy to, such as `logs-*`", requiredOpt())
+	AddStringFlag(cmdISMApply, doctl.ArgOpenSearchRetention, "", "", "How long to keep indices before deleting them, such as `30d`, `12h`, or `90m`", requiredOpt())
+	AddStringFlag(cmdISMApply, doctl.ArgOpenSearchPolicyID, "", "", "The ID of the policy. Defaults to `doctl-retention-` followed by the retention")
+	cmdISMApply.Example = `The following example deletes log indices after 30 days: doctl databases opensearch ism apply ca9f591d-f38h-5555-a0ef-1c02d1d1e35 --index-pattern "logs-*" --retention 30d`
+
+	cmdHealth := CmdBuilder(cmd, RunDatabaseOpenSearchHealth, "health <database-cluster-id>", "Show the health of an OpenSearch cluster",
+		`Shows the health status and shard allocation of the specified OpenSearch cluster. With `+"`"+`--watch`+"`"+`, the command keeps polling and prints a line each time the health changes, until interrupted.`, Writer,
+		aliasOpt("h"), displayerType(&displayers.OpenSearchClusterHealth{}))
+	AddBoolFlag(cmdHealth, doctl.ArgOpenSearchWatch, "w", false, "Keep reporting health changes until interrupted")
+	AddDurationFlag(cmdHealth, doctl.ArgInterval, "", 10*time.Second, "How often to poll the cluster health with --watch")
+	cmdHealth.Example = `The following example follows the health of an OpenSearch cluster: doctl databases opensearch health ca9f591d-f38h-5555-a0ef-1c02d1d1e35 --watch`
+
+	return cmd
+}
+
+// openSearchClient makes requests to the OpenSearch API of a managed cluster.
+type openSearchClient struct {
+	client   *http.Client
+	base     string
+	user     string
+	password string
+}
+
+// openSearchAPIError is a response from the OpenSearch API with an error
+// status.
+type openSearchAPIError struct {
+	status  int
+	message string
+}
+
+func (e *openSearchAPIError) Error() string {
+	return fmt.Sprintf("opensearch API returned %d: %s", e.status, e.message)
+}
+
+func isOpenSearchAPIStatus(err error, status int) bool {
+	apiErr, ok := err.(*openSearchAPIError)
+	return ok && apiErr.status == status
+}
+
+func newOpenSearchClient(c *CmdConfig, databaseID string) (*openSearchClient, error) {
+	database, err := c.Databases().Get(databaseID)
+	if err != nil {
+		return nil, err
+	}
+	if database.EngineSlug != "opensearch" {
+		return nil, fmt.Errorf("database cluster %s runs %s, not opensearch", database.Name, database.EngineSlug)
+	}
+	conn := database.Connection
+	if conn == nil || conn.Host == "" {
+		return nil, fmt.Errorf("database cluster %s has no connection details yet", database.Name)
+	}
+
+	return &openSearchClient{
+		client:   doctl.ReadOnlyClient(openSearchHTTPClient),
+		base:     fmt.Sprintf("https://%s:%d", conn.Host, conn.Port),
+		user:     conn.User,
+		password: conn.Password,
+	}, nil
+}
+
+func (o *openSearchClient) do(method, path string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, o.base+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(o.user, o.password)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error struct {
+				Reason string `json:"reason"`
+			} `json:"error"`
+		}
+		msg := string(respBody)
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error.Reason != "" {
+			msg = apiErr.Error.Reason
+		}
+		return &openSearchAPIError{status: resp.StatusCode, message: msg}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+// RunDatabaseOpenSearchIndices lists the indices of an OpenSearch cluster
+func RunDatabaseOpenSearchIndices(c *CmdConfig) error {
+	if len(c.Args) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	pattern, err := c.Doit.GetString(c.NS, doctl.ArgOpenSearchIndexPattern)
+	if err != nil {
+		return err
+	}
+
+	client, err := newOpenSearchClient(c, c.Args[0])
+	if err != nil {
+		return err
+	}
+
+	path := "/_cat/indices"
+	if pattern != "" {
+		path += "/" + url.PathEscape(pattern)
+	}
+	indices := []displayers.OpenSearchIndex{}
+	if err := client.do(http.MethodGet, path+"?format=json&bytes=b&s=index", nil, &indices); err != nil {
+		return err
+	}
+
+	return c.Display(&displayers.OpenSearchIndices{Indices: indices})
+}
+
+// RunDatabaseOpenSearchISMApply creates or updates a retention policy and
+// attaches it to the matching indices
+func RunDatabaseOpenSearchISMApply(c *CmdConfig) error {
+	if len(c.Args) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	pattern, err := c.Doit.GetString(c.NS, doctl.ArgOpenSearchIndexPattern)
+	if err != nil {
+		return err
+	}
+	retention, err := c.Doit.GetString(c.NS, doctl.ArgOpenSearchRetention)
+	if err != nil {
+		return err
+	}
+	if !openSearchRetentionRE.MatchString(retention) {
+		return fmt.Errorf("invalid --%s value %q: must be a number of days, hours, or minutes, such as 30d", doctl.ArgOpenSearchRetention, retention)
+	}
+	policyID, err := c.Doit.GetString(c.NS, doctl.ArgOpenSearchPolicyID)
+	if err != nil {
+		return err
+	}
+	if policyID == "" {
+		policyID = "doctl-retention-" + retention
+	}
+
+	client, err := newOpenSearchClient(c, c.Args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := putOpenSearchRetentionPolicy(client, policyID, pattern, retention); err != nil {
+		return err
+	}
+
+	var added struct {
+		UpdatedIndices int  `json:"updated_indices"`
+		Failures       bool `json:"failures"`
+		FailedIndices  []struct {
+			IndexName string `json:"index_name"`
+			Reason    string `json:"reason"`
+		} `json:"failed_indices"`
+	}
+	if err := client.do(http.MethodPost, "/_plugins/_ism/add/"+url.PathEscape(pattern), map[string]string{"policy_id": policyID}, &added); err != nil {
+		return err
+	}
+	for _, f := range added.FailedIndices {
+		warn("Policy %s was not attached to %s: %s", policyID, f.IndexName, f.Reason)
+	}
+
+	notice("Policy %s deletes indices matching %s after %s; attached to %d existing indices", policyID, pattern, retention, added.UpdatedIndices)
+	return nil
+}
+
+// putOpenSearchRetentionPolicy creates the policy, or replaces it if it
+// already exists.
+func putOpenSearchRetentionPolicy(client *openSearchClient, policyID, pattern, retention string) error {
+	policy := map[string]any{
+		"policy": map[string]any{
+			"description":   fmt.Sprintf("Delete %s indices after %s. Managed by doctl.", pattern, retention),
+			"default_state": "hot",
+			"states": []any{
+				map[string]any{
+					"name":    "hot",
+					"actions": []any{},
+					"transitions": []any{
+						map[string]any{
+							"state_name": "delete",
+							"conditions": map[string]string{"min_index_age": retention},
+						},
+					},
+				},
+				map[string]any{
+					"name":        "delete",
+					"actions":     []any{map[string]any{"delete": map[string]any{}}},
+					"transitions": []any{},
+				},
+			},
+			"ism_template": []any{
+				map[string]any{"index_patterns": []string{pattern}},
+			},
+		},
+	}
+
+	path := "/_plugins/_ism/policies/" + url.PathEscape(policyID)
+
+	// Updating an existing policy requires its current sequence number and
+	// primary term.
+	var existing struct {
+		SeqNo       int `json:"_seq_no"`
+		PrimaryTerm int `json:"_primary_term"`
+	}
+	err := client.do(http.MethodGet, path, nil, &existing)
+	switch {
+	case err == nil:
+		path += "?if_seq_no=" + strconv.Itoa(existing.SeqNo) + "&if_primary_term=" + strconv.Itoa(existing.PrimaryTerm)
+	case !isOpenSearchAPIStatus(err, http.StatusNotFound):
+		return err
+	}
+
+	return client.do(http.MethodPut, path, policy, nil)
+}
+
+// RunDatabaseOpenSearchHealth shows the health of an OpenSearch cluster
+func RunDatabaseOpenSearchHealth(c *CmdConfig) error {
+	if len(c.Args) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	watch, err := c.Doit.GetBool(c.NS, doctl.ArgOpenSearchWatch)
+	if err != nil {
+		return err
+	}
+	interval, err := c.Doit.GetDuration(c.NS, doctl.ArgInterval)
+	if err != nil {
+		return err
+	}
+	if watch && interval < time.Second {
+		return fmt.Errorf("--%s must be at least 1s", doctl.ArgInterval)
+	}
+
+	client, err := newOpenSearchClient(c, c.Args[0])
+	if err != nil {
+		return err
+	}
+
+	if !watch {
+		health, err := openSearchClusterHealth(client)
+		if err != nil {
+			return err
+		}
+		return c.Display(&displayers.OpenSearchClusterHealth{Health: *health})
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	return watchOpenSearchHealth(c.Out, client, interval, sigs)
+}
+
+func openSearchClusterHealth(client *openSearchClient) (*displayers.OpenSearchHealth, error) {
+	var health displayers.OpenSearchHealth
+	if err := client.do(http.MethodGet, "/_cluster/health", nil, &health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// watchOpenSearchHealth prints a line whenever the cluster health changes,
+// until stop receives.
+func watchOpenSearchHealth(out io.Writer, client *openSearchClient, interval time.Duration, stop <-chan os.Signal) error {
+	var last *displayers.OpenSearchHealth
+	for {
+		health, err := openSearchClusterHealth(client)
+		if err != nil {
+			return err
+		}
+		if last == nil || *health != *last {
+			fmt.Fprintf(out, "%s  %-6s  nodes=%d  active=%d (%.1f%%)  relocating=%d  initializing=%d  unassigned=%d\n",
+				time.Now().Format(time.TimeOnly), health.Status, health.NumberOfNodes, health.ActiveShards, health.ActiveShardsPercent,
+				health.RelocatingShards, health.InitializingShards, health.UnassignedShards)
+			last = health
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withOpenSearchServer serves handler over TLS and returns a cluster that
+// connects to it.
+func withOpenSearchServer(t *testing.T, handler http.HandlerFunc) *do.Database {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "doadmin", user)
+		assert.Equal(t, "secret", pass)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	client := openSearchHTTPClient
+	openSearchHTTPClient = server.Client()
+	t.Cleanup(func() { openSearchHTTPClient = client })
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+
+	return &do.Database{Database: &godo.Database{
+		ID:         testDBCluster.ID,
+		Name:       "search",
+		EngineSlug: "opensearch",
+		Connection: &godo.DatabaseConnection{Host: u.Hostname(), Port: port, User: "doadmin", Password: "secret"},
+	}}
+}
+
+func TestDatabaseOpenSearchCommand(t *testing.T) {
+	cmd := databaseOpenSearch()
+	assert.NotNil(t, cmd)
+	assertCommandNames(t, cmd, "indices", "ism", "health")
+}
+
+func TestDatabaseOpenSearchIndices(t *testing.T) {
+	cluster := withOpenSearchServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_cat/indices/logs-*", r.URL.Path)
+		w.Write([]byte(`[{"index":"logs-2024.05.01","health":"green","status":"open","docs.count":"1200","store.size":"48213"}]`))
+	})
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.databases.EXPECT().Get(testDBCluster.ID).Return(cluster, nil)
+
+		var out bytes.Buffer
+		config.Out = &out
+		config.Args = append(config.Args, testDBCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgOpenSearchIndexPattern, "logs-*")
+
+		require.NoError(t, RunDatabaseOpenSearchIndices(config))
+		assert.Equal(t, `Index              Health    Status    Docs    Size
+logs-2024.05.01    green     open      1200    48213
+`, out.String())
+	})
+}
+
+func TestDatabaseOpenSearchIndicesWrongEngine(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.databases.EXPECT().Get(testDBCluster.ID).Return(&testDBCluster, nil)
+
+		config.Args = append(config.Args, testDBCluster.ID)
+
+		err := RunDatabaseOpenSearchIndices(config)
+		assert.EqualError(t, err, "database cluster sunny-db-cluster runs pg, not opensearch")
+	})
+}
+
+func TestDatabaseOpenSearchISMApply(t *testing.T) {
+	var policy map[string]any
+	cluster := withOpenSearchServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/_plugins/_ism/policies/doctl-retention-30d":
+			w.Write([]byte(`{"_id":"doctl-retention-30d","_seq_no":7,"_primary_term":2}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/_plugins/_ism/policies/doctl-retention-30d":
+			assert.Equal(t, "if_seq_no=7&if_primary_term=2", r.URL.RawQuery)
+			body, _ := io.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(body, &policy))
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/_plugins/_ism/add/logs-*":
+			body, _ := io.ReadAll(r.Body)
+			assert.JSONEq(t, `{"policy_id":"doctl-retention-30d"}`, string(body))
+			w.Write([]byte(`{"updated_indices":3,"failures":false,"failed_indices":[]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.databases.EXPECT().Get(testDBCluster.ID).Return(cluster, nil)
+
+		config.Args = append(config.Args, testDBCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgOpenSearchIndexPattern, "logs-*")
+		config.Doit.Set(config.NS, doctl.ArgOpenSearchRetention, "30d")
+
+		require.NoError(t, RunDatabaseOpenSearchISMApply(config))
+
+		states := policy["policy"].(map[string]any)["states"].([]any)
+		transition := states[0].(map[string]any)["transitions"].([]any)[0].(map[string]any)
+		assert.Equal(t, map[string]any{"min_index_age": "30d"}, transition["conditions"])
+	})
+}
+
+func TestDatabaseOpenSearchISMApplyInvalidRetention(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, testDBCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgOpenSearchIndexPattern, "logs-*")
+		config.Doit.Set(config.NS, doctl.ArgOpenSearchRetention, "1 month")
+
+		err := RunDatabaseOpenSearchISMApply(config)
+		assert.EqualError(t, err, `invalid --retention value "1 month": must be a number of days, hours, or minutes, such as 30d`)
+	})
+}
+
+func TestWatchOpenSearchHealth(t *testing.T) {
+	statuses := []string{"yellow", "yellow", "green"}
+	stop := make(chan os.Signal, 1)
+	requests := 0
+	cluster := withOpenSearchServer(t, func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[requests]
+		requests++
+		if requests == len(statuses) {
+			stop <- os.Interrupt
+		}
+		json.NewEncoder(w).Encode(map[string]any{"status": status, "number_of_nodes": 3, "active_shards": 10})
+	})
+
+	client := &openSearchClient{
+		client:   openSearchHTTPClient,
+		base:     "https://" + cluster.Connection.Host + ":" + strconv.Itoa(cluster.Connection.Port),
+		user:     "doadmin",
+		password: "secret",
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, watchOpenSearchHealth(&out, client, time.Millisecond, stop))
+	assert.Regexp(t, `^\d\d:\d\d:\d\d  yellow  nodes=3  active=10 \(0.0%\)  relocating=0  initializing=0  unassigned=0
+\d\d:\d\d:\d\d  green   nodes=3  active=10 \(0.0%\)  relocating=0  initializing=0  unassigned=0
+$`, out.String())
+}
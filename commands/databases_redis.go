@@ -0,0 +1,170 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// redisEvictionPolicies are the eviction policies accepted by the Redis
+	// configuration endpoint.
+	redisEvictionPolicies = []string{"noeviction", "allkeys-lru", "allkeys-random", "volatile-lru", "volatile-random", "volatile-ttl"}
+	// redisPersistenceModes are the persistence modes of a Redis cluster.
+	redisPersistenceModes = []string{"off", "rdb"}
+)
+
+func databaseRedis() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:     "redis",
+			Aliases: []string{"valkey"},
+			Short:   "Display commands to administer managed Redis clusters",
+			Long:    `The subcommands under ` + "`" + `doctl databases redis` + "`" + ` manage settings specific to Redis database clusters.`,
+		},
+	}
+
+	config := &Command{
+		Command: &cobra.Command{
+			Use:     "config",
+			Aliases: []string{"cfg"},
+			Short:   "Display commands to view and change the configuration of Redis clusters",
+			Long:    `The subcommands under ` + "`" + `doctl databases redis config` + "`" + ` view and change the eviction policy, persistence, and client timeout of a Redis database cluster.`,
+		},
+	}
+	cmd.AddCommand(config)
+
+	cmdConfigGet := CmdBuilder(config, RunDatabaseRedisConfigGet, "get <database-cluster-id>", "Retrieve the configuration of a Redis cluster",
+		`Retrieves the advanced configuration of the specified Redis database cluster.`, Writer, aliasOpt("g"), displayerType(&displayers.RedisConfiguration{}),
+		overrideCmdNS("redis-config"))
+	cmdConfigGet.Example = `The following example retrieves the configuration of a Redis cluster with the ID ` + "`" + `ca9f591d-f38h-5555-a0ef-1c02d1d1e35` + "`" + `: doctl databases redis config get ca9f591d-f38h-5555-a0ef-1c02d1d1e35`
+
+	cmdConfigSet := CmdBuilder(config, RunDatabaseRedisConfigSet, "set <database-cluster-id>", "Change the configuration of a Redis cluster",
+		`Changes the specified settings of a Redis database cluster. Settings that are not specified keep their current values.`, Writer, aliasOpt("s"), displayerType(&displayers.RedisConfiguration{}),
+		// A "config" namespace would clash with the config file setting.
+		overrideCmdNS("redis-config"))
+	AddStringFlag(cmdConfigSet, doctl.ArgDatabaseRedisEvictionPolicy, "", "",
+		"The policy used to evict keys when memory is full. Possible values: "+strings.Join(redisEvictionPolicies, ", "))
+	AddStringFlag(cmdConfigSet, doctl.ArgDatabaseRedisPersistence, "", "",
+		"Whether to persist data to disk. Possible values: `off` or `rdb`")
+	AddIntFlag(cmdConfigSet, doctl.ArgDatabaseRedisTimeout, "", 0,
+		"The idle time, in seconds, after which client connections are closed. `0` disables the timeout")
+	cmdConfigSet.Example = `The following example sets a Redis cluster's eviction policy to ` + "`" + `allkeys-lru` + "`" + ` and disables persistence: doctl databases redis config set ca9f591d-f38h-5555-a0ef-1c02d1d1e35 --eviction-policy allkeys-lru --persistence off`
+
+	return cmd
+}
+
+// RunDatabaseRedisConfigGet retrieves the configuration of a Redis cluster
+func RunDatabaseRedisConfigGet(c *CmdConfig) error {
+	if len(c.Args) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	config, err := c.Databases().GetRedisConfiguration(c.Args[0])
+	if err != nil {
+		return err
+	}
+
+	return c.Display(&displayers.RedisConfiguration{RedisConfig: *config})
+}
+
+// RunDatabaseRedisConfigSet changes the configuration of a Redis cluster
+func RunDatabaseRedisConfigSet(c *CmdConfig) error {
+	if len(c.Args) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	id := c.Args[0]
+
+	conf, err := buildRedisConfigFromArgs(c)
+	if err != nil {
+		return err
+	}
+
+	database, err := c.Databases().Get(id)
+	if err != nil {
+		return err
+	}
+	if database.EngineSlug != "redis" && database.EngineSlug != "valkey" {
+		return fmt.Errorf("database cluster %s runs %s, not redis", database.Name, database.EngineSlug)
+	}
+
+	confJSON, err := json.Marshal(conf)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Databases().UpdateRedisConfiguration(id, string(confJSON)); err != nil {
+		return err
+	}
+
+	return RunDatabaseRedisConfigGet(c)
+}
+
+func buildRedisConfigFromArgs(c *CmdConfig) (*godo.RedisConfig, error) {
+	conf := &godo.RedisConfig{}
+	changed := false
+
+	if c.Doit.IsSet(doctl.ArgDatabaseRedisEvictionPolicy) {
+		policy, err := c.Doit.GetString(c.NS, doctl.ArgDatabaseRedisEvictionPolicy)
+		if err != nil {
+			return nil, err
+		}
+		// Accept the underscored names used by the older eviction policy
+		// endpoint too.
+		policy = strings.ReplaceAll(policy, "_", "-")
+		if !contains(redisEvictionPolicies, policy) {
+			return nil, fmt.Errorf("invalid --%s value %q: must be one of %s", doctl.ArgDatabaseRedisEvictionPolicy, policy, strings.Join(redisEvictionPolicies, ", "))
+		}
+		conf.RedisMaxmemoryPolicy = &policy
+		changed = true
+	}
+
+	if c.Doit.IsSet(doctl.ArgDatabaseRedisPersistence) {
+		persistence, err := c.Doit.GetString(c.NS, doctl.ArgDatabaseRedisPersistence)
+		if err != nil {
+			return nil, err
+		}
+		if !contains(redisPersistenceModes, persistence) {
+			return nil, fmt.Errorf("invalid --%s value %q: must be one of %s", doctl.ArgDatabaseRedisPersistence, persistence, strings.Join(redisPersistenceModes, ", "))
+		}
+		conf.RedisPersistence = &persistence
+		changed = true
+	}
+
+	if c.Doit.IsSet(doctl.ArgDatabaseRedisTimeout) {
+		timeout, err := c.Doit.GetInt(c.NS, doctl.ArgDatabaseRedisTimeout)
+		if err != nil {
+			return nil, err
+		}
+		if timeout < 0 {
+			return nil, fmt.Errorf("--%s must not be negative", doctl.ArgDatabaseRedisTimeout)
+		}
+		conf.RedisTimeout = &timeout
+		changed = true
+	}
+
+	if !changed {
+		return nil, fmt.Errorf("nothing to update: specify --%s, --%s, or --%s", doctl.ArgDatabaseRedisEvictionPolicy, doctl.ArgDatabaseRedisPersistence, doctl.ArgDatabaseRedisTimeout)
+	}
+
+	return conf, nil
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabaseRedisCommand(t *testing.T) {
+	cmd := databaseRedis()
+	assert.NotNil(t, cmd)
+	assertCommandNames(t, cmd, "config")
+}
+
+func TestDatabaseRedisConfigSet(t *testing.T) {
+	redisCluster := *testDBCluster.Database
+	redisCluster.EngineSlug = "redis"
+	redisDB := &do.Database{Database: &redisCluster}
+
+	// Only the specified settings are sent
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		policy := "allkeys-lru"
+		tm.databases.EXPECT().Get(testDBCluster.ID).Return(redisDB, nil)
+		tm.databases.EXPECT().UpdateRedisConfiguration(testDBCluster.ID, `{"redis_maxmemory_policy":"allkeys-lru","redis_timeout":300}`).Return(nil)
+		tm.databases.EXPECT().GetRedisConfiguration(testDBCluster.ID).Return(&do.RedisConfig{RedisConfig: &godo.RedisConfig{RedisMaxmemoryPolicy: &policy}}, nil)
+
+		config.Args = append(config.Args, testDBCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgDatabaseRedisEvictionPolicy, "allkeys_lru")
+		config.Doit.Set(config.NS, doctl.ArgDatabaseRedisTimeout, 300)
+
+		err := RunDatabaseRedisConfigSet(config)
+		assert.NoError(t, err)
+	})
+
+	// Not a Redis cluster
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.databases.EXPECT().Get(testDBCluster.ID).Return(&testDBCluster, nil)
+
+		config.Args = append(config.Args, testDBCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgDatabaseRedisPersistence, "rdb")
+
+		err := RunDatabaseRedisConfigSet(config)
+		assert.EqualError(t, err, "database cluster sunny-db-cluster runs pg, not redis")
+	})
+
+	// Invalid values
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, testDBCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgDatabaseRedisPersistence, "aof")
+
+		err := RunDatabaseRedisConfigSet(config)
+		assert.EqualError(t, err, `invalid --persistence value "aof": must be one of off, rdb`)
+	})
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, testDBCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgDatabaseRedisEvictionPolicy, "lru")
+
+		err := RunDatabaseRedisConfigSet(config)
+		assert.EqualError(t, err, `invalid --eviction-policy value "lru": must be one of noeviction, allkeys-lru, allkeys-random, volatile-lru, volatile-random, volatile-ttl`)
+	})
+
+	// Nothing to update
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, testDBCluster.ID)
+
+		err := RunDatabaseRedisConfigSet(config)
+		assert.EqualError(t, err, "nothing to update: specify --eviction-policy, --persistence, or --timeout")
+	})
+}
@@ -262,6 +262,12 @@ func TestDatabasesCommand(t *testing.T) {
 		"sql-mode",
 		"configuration",
 		"topics",
+		"kafka",
+		"redis",
+		"opensearch",
+		"upgrade",
+		"metrics",
+		"slow-queries",
 	)
 }
 
@@ -282,6 +288,7 @@ func TestDatabaseUserCommand(t *testing.T) {
 		"get",
 		"reset",
 		"create",
+		"update",
 		"delete",
 	)
 }
@@ -293,6 +300,7 @@ func TestDatabasePoolCommand(t *testing.T) {
 		"list",
 		"get",
 		"create",
+		"update",
 		"delete",
 	)
 }
@@ -860,6 +868,102 @@ func TestDatabaseUpdateMaintenance(t *testing.T) {
 	})
 }
 
+func TestDatabaseUpdateMaintenanceValidation(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, testDBCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgDatabaseMaintenanceDay, "someday")
+		config.Doit.Set(config.NS, doctl.ArgDatabaseMaintenanceHour, "03:00")
+
+		err := RunDatabaseMaintenanceUpdate(config)
+		assert.EqualError(t, err, `invalid --day value "someday": must be a day of the week, such as tuesday`)
+	})
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, testDBCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgDatabaseMaintenanceDay, "Sunday")
+		config.Doit.Set(config.NS, doctl.ArgDatabaseMaintenanceHour, "3am")
+
+		err := RunDatabaseMaintenanceUpdate(config)
+		assert.EqualError(t, err, `invalid --hour value "3am": must be a UTC hour in 24-hour format, such as 16:00`)
+	})
+}
+
+func TestDatabaseUpgrade(t *testing.T) {
+	options := &do.DatabaseOptions{DatabaseOptions: &godo.DatabaseOptions{
+		PostgresSQLOptions: godo.DatabaseEngineOptions{Versions: []string{"11", "12", "13", "14"}},
+	}}
+
+	// Successful call at a scheduled time
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var slept time.Duration
+		databaseUpgradeSleep = func(d time.Duration) { slept = d }
+		defer func() { databaseUpgradeSleep = time.Sleep }()
+
+		tm.databases.EXPECT().Get(testDBCluster.ID).Return(&testDBCluster, nil)
+		tm.databases.EXPECT().ListOptions().Return(options, nil)
+		tm.databases.EXPECT().UpgradeMajorVersion(testDBCluster.ID, &godo.UpgradeVersionRequest{Version: "12"}).Return(nil)
+
+		config.Args = append(config.Args, testDBCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgVersion, "12")
+		config.Doit.Set(config.NS, doctl.ArgDatabaseUpgradeAt, time.Now().Add(time.Hour).Format(time.RFC3339))
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+
+		err := RunDatabaseUpgrade(config)
+		assert.NoError(t, err)
+		assert.InDelta(t, time.Hour, slept, float64(time.Minute))
+	})
+
+	// Version not offered
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.databases.EXPECT().Get(testDBCluster.ID).Return(&testDBCluster, nil)
+		tm.databases.EXPECT().ListOptions().Return(options, nil)
+
+		config.Args = append(config.Args, testDBCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgVersion, "16")
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+
+		err := RunDatabaseUpgrade(config)
+		assert.EqualError(t, err, "version 16 is not available for pg; available versions: 11, 12, 13, 14")
+	})
+
+	// Downgrade
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.databases.EXPECT().Get(testDBCluster.ID).Return(&testDBCluster, nil)
+		tm.databases.EXPECT().ListOptions().Return(options, nil)
+
+		config.Args = append(config.Args, testDBCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgVersion, "11")
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+
+		err := RunDatabaseUpgrade(config)
+		assert.EqualError(t, err, "version 11 is not newer than the running version 11")
+	})
+
+	// Invalid timestamp
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, testDBCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgVersion, "12")
+		config.Doit.Set(config.NS, doctl.ArgDatabaseUpgradeAt, "tomorrow")
+
+		err := RunDatabaseUpgrade(config)
+		assert.EqualError(t, err, `invalid --at value "tomorrow": must be an RFC3339 timestamp`)
+	})
+}
+
+func TestDatabaseUpgradePreflight(t *testing.T) {
+	options := &do.DatabaseOptions{DatabaseOptions: &godo.DatabaseOptions{
+		PostgresSQLOptions: godo.DatabaseEngineOptions{Versions: []string{"11", "12", "13", "14"}},
+	}}
+
+	warnings, err := databaseUpgradePreflight(&testDBCluster, options, "14")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"This upgrade skips version(s) 12, 13; review the release notes of each skipped version for incompatible changes."}, warnings)
+
+	warnings, err = databaseUpgradePreflight(&testDBCluster, options, "12")
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
 func TestDatabasesUserGet(t *testing.T) {
 	// Successful call
 	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
@@ -1026,6 +1130,75 @@ func TestDatabaseResetUserAuth(t *testing.T) {
 	})
 }
 
+func TestDatabaseUserUpdate(t *testing.T) {
+	// Reset a MySQL user's password, keeping its auth plugin
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		current := *testDBUser.DatabaseUser
+		current.MySQLSettings = &godo.DatabaseMySQLUserSettings{AuthPlugin: godo.SQLAuthPluginNative}
+		r := &godo.DatabaseResetUserAuthRequest{
+			MySQLSettings: &godo.DatabaseMySQLUserSettings{AuthPlugin: godo.SQLAuthPluginNative},
+		}
+
+		tm.databases.EXPECT().GetUser(testDBCluster.ID, testDBUser.Name).Return(&do.DatabaseUser{DatabaseUser: &current}, nil)
+		tm.databases.EXPECT().ResetUserAuth(testDBCluster.ID, testDBUser.Name, r).Return(&testDBUser, nil)
+
+		config.Args = append(config.Args, testDBCluster.ID, testDBUser.Name)
+		config.Doit.Set(config.NS, doctl.ArgDatabaseUserResetPassword, true)
+
+		err := RunDatabaseUserUpdate(config)
+		assert.NoError(t, err)
+	})
+
+	// Reset with a new auth plugin
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		r := &godo.DatabaseResetUserAuthRequest{
+			MySQLSettings: &godo.DatabaseMySQLUserSettings{AuthPlugin: godo.SQLAuthPluginCachingSHA2},
+		}
+		tm.databases.EXPECT().ResetUserAuth(testDBCluster.ID, testDBUser.Name, r).Return(&testDBUser, nil)
+
+		config.Args = append(config.Args, testDBCluster.ID, testDBUser.Name)
+		config.Doit.Set(config.NS, doctl.ArgDatabaseUserResetPassword, true)
+		config.Doit.Set(config.NS, doctl.ArgDatabaseUserMySQLAuthPlugin, godo.SQLAuthPluginCachingSHA2)
+
+		err := RunDatabaseUserUpdate(config)
+		assert.NoError(t, err)
+	})
+
+	// Update Kafka ACLs
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		r := &godo.DatabaseUpdateUserRequest{
+			Settings: &godo.DatabaseUserSettings{
+				ACL: []*godo.KafkaACL{{Topic: "events", Permission: "consume"}},
+			},
+		}
+		tm.databases.EXPECT().UpdateUser(testDBCluster.ID, testDBUser.Name, r).Return(&testDBUser, nil)
+
+		config.Args = append(config.Args, testDBCluster.ID, testDBUser.Name)
+		config.Doit.Set(config.NS, doctl.ArgDatabaseUserKafkaACLs, []string{"events:consume"})
+
+		err := RunDatabaseUserUpdate(config)
+		assert.NoError(t, err)
+	})
+
+	// Nothing to update
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, testDBCluster.ID, testDBUser.Name)
+
+		err := RunDatabaseUserUpdate(config)
+		assert.EqualError(t, err, "nothing to update: specify --reset-password or --acl")
+	})
+
+	// Invalid auth plugin
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, testDBCluster.ID, testDBUser.Name)
+		config.Doit.Set(config.NS, doctl.ArgDatabaseUserResetPassword, true)
+		config.Doit.Set(config.NS, doctl.ArgDatabaseUserMySQLAuthPlugin, "sha256_password")
+
+		err := RunDatabaseUserUpdate(config)
+		assert.EqualError(t, err, `invalid MySQL auth plugin "sha256_password": must be caching_sha2_password or mysql_native_password`)
+	})
+}
+
 func TestDatabasesUserDelete(t *testing.T) {
 	// Successful
 	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
@@ -1170,6 +1343,50 @@ func TestDatabasePoolCreate_InboundUser(t *testing.T) {
 	})
 }
 
+func TestDatabasePoolUpdate(t *testing.T) {
+	// Successful call keeps unspecified settings
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		r := &godo.DatabaseUpdatePoolRequest{
+			User:     testDBPool.User,
+			Size:     25,
+			Database: testDBPool.Database,
+			Mode:     "session",
+		}
+		tm.databases.EXPECT().GetPool(testDBCluster.ID, testDBPool.Name).Return(&testDBPool, nil).Times(2)
+		tm.databases.EXPECT().UpdatePool(testDBCluster.ID, testDBPool.Name, r).Return(nil)
+
+		config.Args = append(config.Args, testDBCluster.ID, testDBPool.Name)
+		config.Doit.Set(config.NS, doctl.ArgDatabasePoolMode, "session")
+		config.Doit.Set(config.NS, doctl.ArgDatabasePoolSize, 25)
+
+		err := RunDatabasePoolUpdate(config)
+		assert.NoError(t, err)
+	})
+
+	// Invalid mode
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.databases.EXPECT().GetPool(testDBCluster.ID, testDBPool.Name).Return(&testDBPool, nil)
+
+		config.Args = append(config.Args, testDBCluster.ID, testDBPool.Name)
+		config.Doit.Set(config.NS, doctl.ArgDatabasePoolMode, "batch")
+
+		err := RunDatabasePoolUpdate(config)
+		assert.EqualError(t, err, `invalid pool mode "batch": must be session, transaction, or statement`)
+	})
+
+	// Error
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.databases.EXPECT().GetPool(testDBCluster.ID, testDBPool.Name).Return(&testDBPool, nil)
+		tm.databases.EXPECT().UpdatePool(testDBCluster.ID, testDBPool.Name, gomock.AssignableToTypeOf(&godo.DatabaseUpdatePoolRequest{})).Return(errTest)
+
+		config.Args = append(config.Args, testDBCluster.ID, testDBPool.Name)
+		config.Doit.Set(config.NS, doctl.ArgDatabasePoolSize, 5)
+
+		err := RunDatabasePoolUpdate(config)
+		assert.EqualError(t, err, errTest.Error())
+	})
+}
+
 func TestDatabasesPoolDelete(t *testing.T) {
 	// Successful
 	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
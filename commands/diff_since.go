@@ -0,0 +1,184 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/spf13/viper"
+)
+
+// diffKeys are the fields that identify a resource, in order of preference.
+var diffKeys = []string{"id", "uuid", "slug", "urn", "name"}
+
+// displayDiff displays the resources in d that were added, removed, or
+// changed since the snapshot at path was saved.
+func (c *CmdConfig) displayDiff(path string, d displayers.Displayable) error {
+	before, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading --%s snapshot: %w", doctl.ArgDiffSince, err)
+	}
+
+	var after bytes.Buffer
+	if err := d.JSON(&after); err != nil {
+		return err
+	}
+
+	diff, err := diffResources(before, after.Bytes())
+	if err != nil {
+		return err
+	}
+
+	noHeaders, err := c.Doit.GetBool(c.NS, doctl.ArgNoHeader)
+	if err != nil {
+		return err
+	}
+
+	dc := &displayers.Displayer{
+		Item:       diff,
+		Out:        c.Out,
+		NoHeaders:  noHeaders,
+		OutputType: viper.GetString(doctl.ArgOutput),
+	}
+	return dc.Display()
+}
+
+// diffResources compares two JSON lists of resources, matching resources
+// up by the first of diffKeys that all of them have.
+func diffResources(before, after []byte) (*displayers.ResourceDiff, error) {
+	old, err := decodeResources(before)
+	if err != nil {
+		return nil, fmt.Errorf("parsing --%s snapshot: %w", doctl.ArgDiffSince, err)
+	}
+	cur, err := decodeResources(after)
+	if err != nil {
+		return nil, err
+	}
+
+	key := ""
+	for _, k := range diffKeys {
+		if hasKey(old, k) && hasKey(cur, k) {
+			key = k
+			break
+		}
+	}
+	if key == "" {
+		return nil, fmt.Errorf("--%s: unable to identify resources; they have none of the fields %v", doctl.ArgDiffSince, diffKeys)
+	}
+
+	oldByKey := make(map[string]map[string]any, len(old))
+	for _, r := range old {
+		oldByKey[fmt.Sprint(r[key])] = r
+	}
+
+	diff := &displayers.ResourceDiff{Changes: []displayers.ResourceChange{}}
+	seen := make(map[string]bool, len(cur))
+	for _, r := range cur {
+		id := fmt.Sprint(r[key])
+		seen[id] = true
+
+		o, ok := oldByKey[id]
+		if !ok {
+			diff.Changes = append(diff.Changes, resourceChange("added", id, nil, r))
+			continue
+		}
+		if !reflect.DeepEqual(o, r) {
+			diff.Changes = append(diff.Changes, resourceChange("changed", id, o, r))
+		}
+	}
+	for _, r := range old {
+		id := fmt.Sprint(r[key])
+		if !seen[id] {
+			diff.Changes = append(diff.Changes, resourceChange("removed", id, r, nil))
+		}
+	}
+
+	return diff, nil
+}
+
+func resourceChange(change, id string, before, after map[string]any) displayers.ResourceChange {
+	rc := displayers.ResourceChange{
+		Change: change,
+		ID:     id,
+		Before: before,
+		After:  after,
+	}
+
+	r := after
+	if r == nil {
+		r = before
+	}
+	if name, ok := r["name"].(string); ok {
+		rc.Name = name
+	}
+
+	if before != nil && after != nil {
+		for k, v := range after {
+			if !reflect.DeepEqual(before[k], v) {
+				rc.Fields = append(rc.Fields, k)
+			}
+		}
+		for k := range before {
+			if _, ok := after[k]; !ok {
+				rc.Fields = append(rc.Fields, k)
+			}
+		}
+		sort.Strings(rc.Fields)
+	}
+
+	return rc
+}
+
+// decodeResources decodes JSON output as a list of resources. Commands that
+// find a single resource output it on its own rather than in a list.
+func decodeResources(b []byte) ([]map[string]any, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || bytes.Equal(b, []byte("null")) {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	if b[0] == '{' {
+		var r map[string]any
+		if err := dec.Decode(&r); err != nil {
+			return nil, err
+		}
+		return []map[string]any{r}, nil
+	}
+
+	var rs []map[string]any
+	if err := dec.Decode(&rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// hasKey reports whether every resource has the field k.
+func hasKey(rs []map[string]any, k string) bool {
+	for _, r := range rs {
+		if _, ok := r[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDiffResources(t *testing.T) {
+	before := []byte(`[
+  {"id": 1, "name": "web", "status": "active", "size_slug": "s-1vcpu-1gb"},
+  {"id": 2, "name": "db", "status": "active"}
+]`)
+	after := []byte(`[
+  {"id": 1, "name": "web", "status": "off", "size_slug": "s-2vcpu-2gb"},
+  {"id": 3, "name": "worker", "status": "new"}
+]`)
+
+	diff, err := diffResources(before, after)
+	require.NoError(t, err)
+	require.Len(t, diff.Changes, 3)
+
+	assert.Equal(t, "changed", diff.Changes[0].Change)
+	assert.Equal(t, "1", diff.Changes[0].ID)
+	assert.Equal(t, []string{"size_slug", "status"}, diff.Changes[0].Fields)
+
+	assert.Equal(t, "added", diff.Changes[1].Change)
+	assert.Equal(t, "worker", diff.Changes[1].Name)
+	assert.Nil(t, diff.Changes[1].Before)
+
+	assert.Equal(t, "removed", diff.Changes[2].Change)
+	assert.Equal(t, "2", diff.Changes[2].ID)
+	assert.Nil(t, diff.Changes[2].After)
+}
+
+func TestDiffResourcesKey(t *testing.T) {
+	diff, err := diffResources([]byte(`[{"name": "example.com", "ttl": 1800}]`), []byte(`[{"name": "example.com", "ttl": 3600}]`))
+	require.NoError(t, err)
+	require.Len(t, diff.Changes, 1)
+	assert.Equal(t, "example.com", diff.Changes[0].ID)
+
+	diff, err = diffResources([]byte(`null`), []byte(`[]`))
+	require.NoError(t, err)
+	assert.Empty(t, diff.Changes)
+
+	_, err = diffResources([]byte(`[{"ttl": 1800}]`), []byte(`[]`))
+	assert.ErrorContains(t, err, "unable to identify resources")
+
+	_, err = diffResources([]byte(`Name`), []byte(`[]`))
+	assert.ErrorContains(t, err, "parsing --diff-since snapshot")
+}
+
+func TestDropletsListDiffSince(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		path := filepath.Join(t.TempDir(), "state.json")
+		require.NoError(t, os.WriteFile(path, []byte(`[{"id": 1, "name": "a-droplet"}, {"id": 7, "name": "gone"}]`), 0600))
+
+		tm.droplets.EXPECT().ListPages("", 0, gomock.Any()).DoAndReturn(listDropletPages(testDropletList))
+
+		var out bytes.Buffer
+		config.Out = &out
+		config.Doit.Set(config.NS, doctl.ArgDiffSince, path)
+
+		require.NoError(t, RunDropletList(config))
+		assert.Contains(t, out.String(), "Changed Fields")
+		assert.Regexp(t, `changed\s+1\s+a-droplet`, out.String())
+		assert.Regexp(t, `removed\s+7\s+gone`, out.String())
+	})
+}
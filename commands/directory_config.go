@@ -0,0 +1,169 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
+)
+
+// directoryConfigName is the name of the per-directory config file, which is looked for in the working directory
+// and its parents.
+const directoryConfigName = ".doctl.yaml"
+
+// directoryConfig pins settings for the commands run in a directory tree, such as a repository.
+type directoryConfig struct {
+	// Context is the authentication context to use.
+	Context string `json:"context,omitempty"`
+	// Project is the UUID of the project to create resources in.
+	Project string `json:"project,omitempty"`
+	// Region is the region to create resources in.
+	Region string `json:"region,omitempty"`
+	// Output is the output format.
+	Output string `json:"output,omitempty"`
+}
+
+// directoryOverride is a setting of the config file replaced by the per-directory config file.
+type directoryOverride struct {
+	key      string
+	value    any
+	original any
+}
+
+// directoryOverrides are the settings replaced by the per-directory config file. They are restored when the config
+// file is written, so that the settings of a directory don't leak into the config file.
+var directoryOverrides []directoryOverride
+
+// findDirectoryConfig returns the path of the nearest per-directory config file of dir or its parents, or an empty
+// path if there is none.
+func findDirectoryConfig(dir string) string {
+	for {
+		path := filepath.Join(dir, directoryConfigName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// readDirectoryConfig reads a per-directory config file. Unknown settings, such as tokens, are refused.
+func readDirectoryConfig(path string) (*directoryConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &directoryConfig{}
+	if err := yaml.UnmarshalStrict(b, cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// initDirectoryConfig applies the nearest per-directory config file, if any, on top of the config file. Flags and
+// environment variables still take precedence over it.
+func initDirectoryConfig() {
+	directoryOverrides = nil
+	if viper.GetBool(doctl.ArgNoConfig) {
+		return
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	path := findDirectoryConfig(wd)
+	if path == "" {
+		return
+	}
+	cfg, err := readDirectoryConfig(path)
+	if err != nil {
+		log.Fatalln("Config initialization failed:", err)
+	}
+	if Verbose {
+		notice("Using the settings of %s", path)
+	}
+	applyDirectoryConfig(DoitCmd, cfg)
+}
+
+// applyDirectoryConfig merges the settings of a per-directory config file into the config of the commands under
+// root. The region and project are the defaults of every --region and --project-id flag.
+func applyDirectoryConfig(root *Command, cfg *directoryConfig) {
+	settings := map[string]any{}
+	if cfg.Context != "" {
+		settings[doctl.ArgContext] = strings.ToLower(cfg.Context)
+	}
+	if cfg.Output != "" {
+		settings[doctl.ArgOutput] = cfg.Output
+	}
+	var walk func(cmd *Command)
+	walk = func(cmd *Command) {
+		if cfg.Region != "" && cmd.Flags().Lookup(doctl.ArgRegionSlug) != nil {
+			settings[flagName(cmd, doctl.ArgRegionSlug)] = cfg.Region
+		}
+		if cfg.Project != "" && cmd.Flags().Lookup(doctl.ArgProjectID) != nil {
+			settings[flagName(cmd, doctl.ArgProjectID)] = cfg.Project
+		}
+		for _, child := range cmd.ChildCommands() {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	nested := map[string]any{}
+	for key, value := range settings {
+		directoryOverrides = append(directoryOverrides, directoryOverride{key: key, value: value, original: viper.Get(key)})
+		setSettingPath(nested, key, value)
+	}
+	viper.MergeConfigMap(nested)
+}
+
+// withoutDirectoryConfig returns the settings to write to the config file: settings replaced by the per-directory
+// config file get back their values from the config file, unless a command has changed them since.
+func withoutDirectoryConfig(settings map[string]any) map[string]any {
+	for _, o := range directoryOverrides {
+		if reflect.DeepEqual(viper.Get(o.key), o.value) {
+			setSettingPath(settings, o.key, o.original)
+		}
+	}
+	return settings
+}
+
+// setSettingPath sets a dotted key of nested settings, or removes it if value is nil.
+func setSettingPath(settings map[string]any, key string, value any) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := settings[part].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			settings[part] = next
+		}
+		settings = next
+	}
+	if value == nil {
+		delete(settings, parts[len(parts)-1])
+		return
+	}
+	settings[parts[len(parts)-1]] = value
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDirectoryConfig(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "repo", "src", "pkg")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+	assert.Empty(t, findDirectoryConfig(nested))
+
+	path := filepath.Join(root, "repo", directoryConfigName)
+	require.NoError(t, os.WriteFile(path, []byte("context: work\n"), 0644))
+	assert.Equal(t, path, findDirectoryConfig(nested))
+	assert.Equal(t, path, findDirectoryConfig(filepath.Join(root, "repo")))
+	assert.Empty(t, findDirectoryConfig(root))
+}
+
+func TestReadDirectoryConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, directoryConfigName)
+	require.NoError(t, os.WriteFile(path, []byte("context: work\nproject: 8f1e2f6b\nregion: sfo3\noutput: json\n"), 0644))
+
+	cfg, err := readDirectoryConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, &directoryConfig{Context: "work", Project: "8f1e2f6b", Region: "sfo3", Output: "json"}, cfg)
+
+	require.NoError(t, os.WriteFile(path, []byte("access-token: secret\n"), 0644))
+	_, err = readDirectoryConfig(path)
+	assert.Error(t, err)
+}
+
+func TestApplyDirectoryConfig(t *testing.T) {
+	t.Cleanup(func() {
+		directoryOverrides = nil
+		viper.ReadConfig(bytes.NewReader(nil))
+	})
+
+	root := &Command{Command: &cobra.Command{Use: "dirconfig"}}
+	create := CmdBuilder(root, nil, "create", "", "", Writer)
+	AddStringFlag(create, doctl.ArgRegionSlug, "", "nyc1", "")
+	AddStringFlag(create, doctl.ArgProjectID, "", "", "")
+	list := CmdBuilder(root, nil, "list", "", "", Writer)
+	AddStringFlag(list, doctl.ArgRegionSlug, "", "", "")
+
+	applyDirectoryConfig(root, &directoryConfig{Region: "sfo3", Project: "8f1e2f6b"})
+	assert.Equal(t, "sfo3", viper.GetString("dirconfig.create.region"))
+	assert.Equal(t, "8f1e2f6b", viper.GetString("dirconfig.create.project-id"))
+	assert.Equal(t, "sfo3", viper.GetString("dirconfig.list.region"))
+
+	// A flag still takes precedence.
+	require.NoError(t, list.Flags().Set(doctl.ArgRegionSlug, "ams3"))
+	assert.Equal(t, "ams3", viper.GetString("dirconfig.list.region"))
+
+	// The settings of the directory aren't written to the config file.
+	settings := withoutDirectoryConfig(viper.AllSettings())
+	dirconfig := settings["dirconfig"].(map[string]any)
+	assert.Equal(t, "nyc1", dirconfig["create"].(map[string]any)["region"])
+	assert.Equal(t, "", dirconfig["create"].(map[string]any)["project-id"])
+	assert.Equal(t, "ams3", dirconfig["list"].(map[string]any)["region"])
+}
@@ -14,7 +14,9 @@ limitations under the License.
 package displayers
 
 import (
+	"fmt"
 	"io"
+	"time"
 
 	"github.com/digitalocean/doctl/do"
 )
@@ -55,3 +57,87 @@ func (a *Account) KV() []map[string]any {
 
 	return []map[string]any{x}
 }
+
+// AccountLimit is the usage of a resource against the account's limit for it.
+type AccountLimit struct {
+	Resource string  `json:"resource"`
+	Used     int     `json:"used"`
+	Limit    int     `json:"limit"`
+	Percent  float64 `json:"percent"`
+}
+
+type AccountLimits struct {
+	Limits []AccountLimit
+}
+
+var _ Displayable = &AccountLimits{}
+
+func (a *AccountLimits) JSON(out io.Writer) error {
+	return writeJSON(a.Limits, out)
+}
+
+func (a *AccountLimits) Cols() []string {
+	return []string{
+		"Resource", "Used", "Limit", "Percent",
+	}
+}
+
+func (a *AccountLimits) ColMap() map[string]string {
+	return map[string]string{
+		"Resource": "Resource", "Used": "Used", "Limit": "Limit", "Percent": "Percent Used",
+	}
+}
+
+func (a *AccountLimits) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(a.Limits))
+	for _, l := range a.Limits {
+		out = append(out, map[string]any{
+			"Resource": l.Resource, "Used": l.Used, "Limit": l.Limit,
+			"Percent": fmt.Sprintf("%.0f%%", l.Percent),
+		})
+	}
+	return out
+}
+
+// AccountSecurityEvent is an event in the security history of an account.
+type AccountSecurityEvent struct {
+	Time     time.Time `json:"time"`
+	Category string    `json:"category"`
+	Event    string    `json:"event"`
+	Resource string    `json:"resource"`
+	Region   string    `json:"region,omitempty"`
+	Details  string    `json:"details,omitempty"`
+}
+
+type AccountSecurityEvents struct {
+	Events []AccountSecurityEvent
+}
+
+var _ Displayable = &AccountSecurityEvents{}
+
+func (a *AccountSecurityEvents) JSON(out io.Writer) error {
+	return writeJSON(a.Events, out)
+}
+
+func (a *AccountSecurityEvents) Cols() []string {
+	return []string{
+		"Time", "Category", "Event", "Resource", "Region", "Details",
+	}
+}
+
+func (a *AccountSecurityEvents) ColMap() map[string]string {
+	return map[string]string{
+		"Time": "Time", "Category": "Category", "Event": "Event", "Resource": "Resource", "Region": "Region", "Details": "Details",
+	}
+}
+
+func (a *AccountSecurityEvents) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(a.Events))
+	for _, e := range a.Events {
+		out = append(out, map[string]any{
+			"Time": e.Time.Format(time.RFC3339), "Category": e.Category, "Event": e.Event,
+			"Resource": e.Resource, "Region": e.Region, "Details": e.Details,
+		})
+	}
+	return out
+}
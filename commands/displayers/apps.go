@@ -6,6 +6,7 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/digitalocean/godo"
 )
@@ -502,3 +503,101 @@ func (b Buildpacks) JSON(w io.Writer) error {
 	e.SetIndent("", "  ")
 	return e.Encode(b)
 }
+
+// AppPreview is a temporary copy of an app deployed from a branch.
+type AppPreview struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	App       string     `json:"app"`
+	Branch    string     `json:"branch"`
+	URL       string     `json:"url,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type AppPreviews []AppPreview
+
+var _ Displayable = (*AppPreviews)(nil)
+
+func (p AppPreviews) Cols() []string {
+	return []string{"ID", "Name", "App", "Branch", "URL", "ExpiresAt"}
+}
+
+func (p AppPreviews) ColMap() map[string]string {
+	return map[string]string{
+		"ID":        "ID",
+		"Name":      "Name",
+		"App":       "App",
+		"Branch":    "Branch",
+		"URL":       "URL",
+		"ExpiresAt": "Expires At",
+	}
+}
+
+func (p AppPreviews) KV() []map[string]any {
+	out := make([]map[string]any, len(p))
+	for i, preview := range p {
+		expires := ""
+		if preview.ExpiresAt != nil {
+			expires = preview.ExpiresAt.Format(time.RFC3339)
+		}
+		out[i] = map[string]any{
+			"ID":        preview.ID,
+			"Name":      preview.Name,
+			"App":       preview.App,
+			"Branch":    preview.Branch,
+			"URL":       preview.URL,
+			"ExpiresAt": expires,
+		}
+	}
+	return out
+}
+
+func (p AppPreviews) JSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(p)
+}
+
+// AppSpecLintFinding is an issue found in an app spec by `doctl apps spec lint`.
+type AppSpecLintFinding struct {
+	Component string `json:"component"`
+	Rule      string `json:"rule"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+}
+
+type AppSpecLintFindings []AppSpecLintFinding
+
+var _ Displayable = (*AppSpecLintFindings)(nil)
+
+func (f AppSpecLintFindings) Cols() []string {
+	return []string{"Severity", "Component", "Rule", "Message"}
+}
+
+func (f AppSpecLintFindings) ColMap() map[string]string {
+	return map[string]string{
+		"Severity":  "Severity",
+		"Component": "Component",
+		"Rule":      "Rule",
+		"Message":   "Message",
+	}
+}
+
+func (f AppSpecLintFindings) KV() []map[string]any {
+	out := make([]map[string]any, len(f))
+	for i, finding := range f {
+		out[i] = map[string]any{
+			"Severity":  finding.Severity,
+			"Component": finding.Component,
+			"Rule":      finding.Rule,
+			"Message":   finding.Message,
+		}
+	}
+	return out
+}
+
+func (f AppSpecLintFindings) JSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(f)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"io"
+)
+
+// OrphanedResource is a resource that appears to be unused.
+type OrphanedResource struct {
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Region  string `json:"region,omitempty"`
+	Reason  string `json:"reason"`
+	Deleted bool   `json:"deleted"`
+}
+
+type OrphanedResources struct {
+	Resources []OrphanedResource
+}
+
+var _ Displayable = &OrphanedResources{}
+
+func (o *OrphanedResources) JSON(out io.Writer) error {
+	return writeJSON(o.Resources, out)
+}
+
+func (o *OrphanedResources) Cols() []string {
+	return []string{
+		"Type",
+		"ID",
+		"Name",
+		"Region",
+		"Reason",
+		"Deleted",
+	}
+}
+
+func (o *OrphanedResources) ColMap() map[string]string {
+	return map[string]string{
+		"Type":    "Type",
+		"ID":      "ID",
+		"Name":    "Name",
+		"Region":  "Region",
+		"Reason":  "Reason",
+		"Deleted": "Deleted",
+	}
+}
+
+func (o *OrphanedResources) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(o.Resources))
+
+	for _, r := range o.Resources {
+		out = append(out, map[string]any{
+			"Type":    r.Type,
+			"ID":      r.ID,
+			"Name":    r.Name,
+			"Region":  r.Region,
+			"Reason":  r.Reason,
+			"Deleted": r.Deleted,
+		})
+	}
+
+	return out
+}
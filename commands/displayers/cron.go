@@ -0,0 +1,62 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"io"
+	"strings"
+)
+
+// CronJob is a doctl command that is run on a schedule by the operating
+// system's scheduler.
+type CronJob struct {
+	Name      string   `json:"name"`
+	Schedule  string   `json:"schedule"`
+	Scheduler string   `json:"scheduler"`
+	Args      []string `json:"args"`
+	Status    string   `json:"status,omitempty"`
+	Log       string   `json:"log"`
+}
+
+type CronJobs struct {
+	Jobs []CronJob
+}
+
+var _ Displayable = &CronJobs{}
+
+func (j *CronJobs) JSON(out io.Writer) error {
+	return writeJSON(j.Jobs, out)
+}
+
+func (j *CronJobs) Cols() []string {
+	return []string{"Name", "Schedule", "Scheduler", "Command", "Status", "Log"}
+}
+
+func (j *CronJobs) ColMap() map[string]string {
+	return map[string]string{
+		"Name": "Name", "Schedule": "Schedule", "Scheduler": "Scheduler",
+		"Command": "Command", "Status": "Status", "Log": "Log",
+	}
+}
+
+func (j *CronJobs) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(j.Jobs))
+	for _, job := range j.Jobs {
+		out = append(out, map[string]any{
+			"Name": job.Name, "Schedule": job.Schedule, "Scheduler": job.Scheduler,
+			"Command": "doctl " + strings.Join(job.Args, " "), "Status": job.Status, "Log": job.Log,
+		})
+	}
+	return out
+}
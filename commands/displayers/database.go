@@ -14,6 +14,7 @@ limitations under the License.
 package displayers
 
 import (
+	"fmt"
 	"io"
 	"sort"
 	"strconv"
@@ -741,6 +742,58 @@ func (dt *DatabaseKafkaTopics) KV() []map[string]any {
 	return out
 }
 
+// DatabaseKafkaUserACL is a Kafka ACL together with the user it belongs to.
+type DatabaseKafkaUserACL struct {
+	User       string `json:"user"`
+	ID         string `json:"id"`
+	Topic      string `json:"topic"`
+	Permission string `json:"permission"`
+}
+
+type DatabaseKafkaACLs struct {
+	ACLs []DatabaseKafkaUserACL
+}
+
+var _ Displayable = &DatabaseKafkaACLs{}
+
+func (da *DatabaseKafkaACLs) JSON(out io.Writer) error {
+	return writeJSON(da.ACLs, out)
+}
+
+func (da *DatabaseKafkaACLs) Cols() []string {
+	return []string{
+		"User",
+		"ID",
+		"Topic",
+		"Permission",
+	}
+}
+
+func (da *DatabaseKafkaACLs) ColMap() map[string]string {
+	return map[string]string{
+		"User":       "User",
+		"ID":         "ID",
+		"Topic":      "Topic",
+		"Permission": "Permission",
+	}
+}
+
+func (da *DatabaseKafkaACLs) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(da.ACLs))
+
+	for _, a := range da.ACLs {
+		o := map[string]any{
+			"User":       a.User,
+			"ID":         a.ID,
+			"Topic":      a.Topic,
+			"Permission": a.Permission,
+		}
+		out = append(out, o)
+	}
+
+	return out
+}
+
 type DatabaseKafkaTopicPartitions struct {
 	DatabaseTopicPartitions []*godo.TopicPartition
 }
@@ -1691,3 +1744,227 @@ func (dr *DatabaseEvents) KV() []map[string]any {
 	}
 	return out
 }
+
+// DatabaseMetric is a current value of a database cluster metric, as
+// reported by one of the cluster's metrics endpoints.
+type DatabaseMetric struct {
+	Host   string  `json:"host"`
+	Metric string  `json:"metric"`
+	Label  string  `json:"label,omitempty"`
+	Value  float64 `json:"value"`
+	Unit   string  `json:"unit,omitempty"`
+}
+
+type DatabaseMetrics struct {
+	Metrics []DatabaseMetric
+}
+
+var _ Displayable = &DatabaseMetrics{}
+
+func (dm *DatabaseMetrics) JSON(out io.Writer) error {
+	return writeJSON(dm.Metrics, out)
+}
+
+func (dm *DatabaseMetrics) Cols() []string {
+	return []string{
+		"Host",
+		"Metric",
+		"Label",
+		"Value",
+	}
+}
+
+func (dm *DatabaseMetrics) ColMap() map[string]string {
+	return map[string]string{
+		"Host":   "Host",
+		"Metric": "Metric",
+		"Label":  "Label",
+		"Value":  "Value",
+	}
+}
+
+func (dm *DatabaseMetrics) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(dm.Metrics))
+
+	for _, m := range dm.Metrics {
+		value := strconv.FormatFloat(m.Value, 'f', -1, 64)
+		if m.Unit == "%" {
+			value = fmt.Sprintf("%.1f%%", m.Value)
+		}
+		o := map[string]any{
+			"Host":   m.Host,
+			"Metric": m.Metric,
+			"Label":  m.Label,
+			"Value":  value,
+		}
+		out = append(out, o)
+	}
+	return out
+}
+
+// DatabaseSlowQuery is a normalized statement and the time spent running it.
+type DatabaseSlowQuery struct {
+	Query   string  `json:"query"`
+	Calls   int64   `json:"calls"`
+	TotalMS float64 `json:"total_ms"`
+	MeanMS  float64 `json:"mean_ms"`
+}
+
+type DatabaseSlowQueries struct {
+	Queries []DatabaseSlowQuery
+}
+
+var _ Displayable = &DatabaseSlowQueries{}
+
+func (dq *DatabaseSlowQueries) JSON(out io.Writer) error {
+	return writeJSON(dq.Queries, out)
+}
+
+func (dq *DatabaseSlowQueries) Cols() []string {
+	return []string{
+		"Calls",
+		"TotalMS",
+		"MeanMS",
+		"Query",
+	}
+}
+
+func (dq *DatabaseSlowQueries) ColMap() map[string]string {
+	return map[string]string{
+		"Calls":   "Calls",
+		"TotalMS": "Total (ms)",
+		"MeanMS":  "Mean (ms)",
+		"Query":   "Query",
+	}
+}
+
+func (dq *DatabaseSlowQueries) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(dq.Queries))
+
+	for _, q := range dq.Queries {
+		o := map[string]any{
+			"Calls":   q.Calls,
+			"TotalMS": fmt.Sprintf("%.1f", q.TotalMS),
+			"MeanMS":  fmt.Sprintf("%.1f", q.MeanMS),
+			"Query":   q.Query,
+		}
+		out = append(out, o)
+	}
+	return out
+}
+
+// OpenSearchIndex is an index of a managed OpenSearch cluster, as reported
+// by its _cat/indices API.
+type OpenSearchIndex struct {
+	Index     string `json:"index"`
+	Health    string `json:"health"`
+	Status    string `json:"status"`
+	DocsCount string `json:"docs.count"`
+	StoreSize string `json:"store.size"`
+}
+
+type OpenSearchIndices struct {
+	Indices []OpenSearchIndex
+}
+
+var _ Displayable = &OpenSearchIndices{}
+
+func (oi *OpenSearchIndices) JSON(out io.Writer) error {
+	return writeJSON(oi.Indices, out)
+}
+
+func (oi *OpenSearchIndices) Cols() []string {
+	return []string{
+		"Index",
+		"Health",
+		"Status",
+		"Docs",
+		"Size",
+	}
+}
+
+func (oi *OpenSearchIndices) ColMap() map[string]string {
+	return map[string]string{
+		"Index":  "Index",
+		"Health": "Health",
+		"Status": "Status",
+		"Docs":   "Docs",
+		"Size":   "Size",
+	}
+}
+
+func (oi *OpenSearchIndices) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(oi.Indices))
+
+	for _, i := range oi.Indices {
+		o := map[string]any{
+			"Index":  i.Index,
+			"Health": i.Health,
+			"Status": i.Status,
+			"Docs":   i.DocsCount,
+			"Size":   i.StoreSize,
+		}
+		out = append(out, o)
+	}
+
+	return out
+}
+
+// OpenSearchHealth is the health of a managed OpenSearch cluster, as
+// reported by its _cluster/health API.
+type OpenSearchHealth struct {
+	Status              string  `json:"status"`
+	NumberOfNodes       int     `json:"number_of_nodes"`
+	ActiveShards        int     `json:"active_shards"`
+	RelocatingShards    int     `json:"relocating_shards"`
+	InitializingShards  int     `json:"initializing_shards"`
+	UnassignedShards    int     `json:"unassigned_shards"`
+	ActiveShardsPercent float64 `json:"active_shards_percent_as_number"`
+}
+
+type OpenSearchClusterHealth struct {
+	Health OpenSearchHealth
+}
+
+var _ Displayable = &OpenSearchClusterHealth{}
+
+func (oh *OpenSearchClusterHealth) JSON(out io.Writer) error {
+	return writeJSON(oh.Health, out)
+}
+
+func (oh *OpenSearchClusterHealth) Cols() []string {
+	return []string{
+		"Status",
+		"Nodes",
+		"ActiveShards",
+		"RelocatingShards",
+		"InitializingShards",
+		"UnassignedShards",
+		"ActiveShardsPercent",
+	}
+}
+
+func (oh *OpenSearchClusterHealth) ColMap() map[string]string {
+	return map[string]string{
+		"Status":              "Status",
+		"Nodes":               "Nodes",
+		"ActiveShards":        "Active Shards",
+		"RelocatingShards":    "Relocating",
+		"InitializingShards":  "Initializing",
+		"UnassignedShards":    "Unassigned",
+		"ActiveShardsPercent": "Active %",
+	}
+}
+
+func (oh *OpenSearchClusterHealth) KV() []map[string]any {
+	h := oh.Health
+	return []map[string]any{{
+		"Status":              h.Status,
+		"Nodes":               h.NumberOfNodes,
+		"ActiveShards":        h.ActiveShards,
+		"RelocatingShards":    h.RelocatingShards,
+		"InitializingShards":  h.InitializingShards,
+		"UnassignedShards":    h.UnassignedShards,
+		"ActiveShardsPercent": strconv.FormatFloat(h.ActiveShardsPercent, 'f', 1, 64),
+	}}
+}
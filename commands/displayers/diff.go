@@ -0,0 +1,73 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"io"
+	"strings"
+)
+
+// ResourceChange is a resource that was added, removed, or changed since an
+// earlier listing.
+type ResourceChange struct {
+	Change string         `json:"change"`
+	ID     string         `json:"id"`
+	Name   string         `json:"name,omitempty"`
+	Fields []string       `json:"fields,omitempty"`
+	Before map[string]any `json:"before,omitempty"`
+	After  map[string]any `json:"after,omitempty"`
+}
+
+type ResourceDiff struct {
+	Changes []ResourceChange
+}
+
+var _ Displayable = &ResourceDiff{}
+
+func (d *ResourceDiff) JSON(out io.Writer) error {
+	return writeJSON(d.Changes, out)
+}
+
+func (d *ResourceDiff) Cols() []string {
+	return []string{
+		"Change",
+		"ID",
+		"Name",
+		"Fields",
+	}
+}
+
+func (d *ResourceDiff) ColMap() map[string]string {
+	return map[string]string{
+		"Change": "Change",
+		"ID":     "ID",
+		"Name":   "Name",
+		"Fields": "Changed Fields",
+	}
+}
+
+func (d *ResourceDiff) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(d.Changes))
+
+	for _, c := range d.Changes {
+		out = append(out, map[string]any{
+			"Change": c.Change,
+			"ID":     c.ID,
+			"Name":   c.Name,
+			"Fields": strings.Join(c.Fields, ","),
+		})
+	}
+
+	return out
+}
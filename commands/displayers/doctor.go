@@ -0,0 +1,54 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import "io"
+
+// DoctorCheck is the result of one check of doctl's environment.
+type DoctorCheck struct {
+	Check  string `json:"check"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Remedy string `json:"remedy,omitempty"`
+}
+
+type DoctorChecks struct {
+	Checks []DoctorCheck
+}
+
+var _ Displayable = &DoctorChecks{}
+
+func (d *DoctorChecks) JSON(out io.Writer) error {
+	return writeJSON(d.Checks, out)
+}
+
+func (d *DoctorChecks) Cols() []string {
+	return []string{"Check", "Status", "Detail", "Remedy"}
+}
+
+func (d *DoctorChecks) ColMap() map[string]string {
+	return map[string]string{
+		"Check": "Check", "Status": "Status", "Detail": "Detail", "Remedy": "Remedy",
+	}
+}
+
+func (d *DoctorChecks) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(d.Checks))
+	for _, c := range d.Checks {
+		out = append(out, map[string]any{
+			"Check": c.Check, "Status": c.Status, "Detail": c.Detail, "Remedy": c.Remedy,
+		})
+	}
+	return out
+}
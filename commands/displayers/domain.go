@@ -14,9 +14,13 @@ limitations under the License.
 package displayers
 
 import (
+	"fmt"
 	"io"
+	"sort"
+	"time"
 
 	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
 )
 
 type Domain struct {
@@ -104,3 +108,449 @@ func (dr *DomainRecord) KV() []map[string]any {
 
 	return out
 }
+
+// DomainNameserver describes where a nameserver of a domain is listed.
+type DomainNameserver struct {
+	Nameserver   string `json:"nameserver"`
+	Delegated    bool   `json:"delegated"`
+	InZone       bool   `json:"in_zone"`
+	DigitalOcean bool   `json:"digitalocean"`
+}
+
+type DomainDelegation struct {
+	Nameservers []DomainNameserver
+}
+
+var _ Displayable = &DomainDelegation{}
+
+func (dd *DomainDelegation) JSON(out io.Writer) error {
+	return writeJSON(dd.Nameservers, out)
+}
+
+func (dd *DomainDelegation) Cols() []string {
+	return []string{"Nameserver", "Delegated", "InZone", "DigitalOcean"}
+}
+
+func (dd *DomainDelegation) ColMap() map[string]string {
+	return map[string]string{
+		"Nameserver": "Nameserver", "Delegated": "Delegated",
+		"InZone": "In Zone", "DigitalOcean": "DigitalOcean",
+	}
+}
+
+func (dd *DomainDelegation) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(dd.Nameservers))
+
+	for _, ns := range dd.Nameservers {
+		o := map[string]any{
+			"Nameserver": ns.Nameserver, "Delegated": ns.Delegated,
+			"InZone": ns.InZone, "DigitalOcean": ns.DigitalOcean,
+		}
+		out = append(out, o)
+	}
+
+	return out
+}
+
+// DNSChange is an entry of the local DNS record change journal.
+type DNSChange struct {
+	ID        string             `json:"id"`
+	Time      time.Time          `json:"time"`
+	Actor     string             `json:"actor"`
+	Domain    string             `json:"domain"`
+	Operation string             `json:"operation"`
+	Old       *godo.DomainRecord `json:"old,omitempty"`
+	New       *godo.DomainRecord `json:"new,omitempty"`
+}
+
+type DNSChanges struct {
+	Changes []DNSChange
+}
+
+var _ Displayable = &DNSChanges{}
+
+func (dc *DNSChanges) JSON(out io.Writer) error {
+	return writeJSON(dc.Changes, out)
+}
+
+func (dc *DNSChanges) Cols() []string {
+	return []string{"ID", "Time", "Actor", "Operation", "RecordID", "Type", "Name", "OldData", "NewData"}
+}
+
+func (dc *DNSChanges) ColMap() map[string]string {
+	return map[string]string{
+		"ID": "Change ID", "Time": "Time", "Actor": "Actor", "Operation": "Operation",
+		"RecordID": "Record ID", "Type": "Type", "Name": "Name", "OldData": "Old Data", "NewData": "New Data",
+	}
+}
+
+func (dc *DNSChanges) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(dc.Changes))
+
+	for _, c := range dc.Changes {
+		o := map[string]any{
+			"ID": c.ID, "Time": c.Time, "Actor": c.Actor, "Operation": c.Operation,
+			"OldData": "", "NewData": "",
+		}
+		for _, r := range []*godo.DomainRecord{c.Old, c.New} {
+			if r != nil {
+				o["RecordID"], o["Type"], o["Name"] = r.ID, r.Type, r.Name
+			}
+		}
+		if c.Old != nil {
+			o["OldData"] = c.Old.Data
+		}
+		if c.New != nil {
+			o["NewData"] = c.New.Data
+		}
+		out = append(out, o)
+	}
+
+	return out
+}
+
+// RecordSetMember is an address of a round-robin record set.
+type RecordSetMember struct {
+	Name   string  `json:"name"`
+	Type   string  `json:"type"`
+	Data   string  `json:"data"`
+	Weight int     `json:"weight"`
+	Share  float64 `json:"share"`
+	TTL    int     `json:"ttl"`
+}
+
+type RecordSet struct {
+	Members []RecordSetMember
+}
+
+var _ Displayable = &RecordSet{}
+
+func (rs *RecordSet) JSON(out io.Writer) error {
+	return writeJSON(rs.Members, out)
+}
+
+func (rs *RecordSet) Cols() []string {
+	return []string{"Name", "Type", "Data", "Weight", "Share", "TTL"}
+}
+
+func (rs *RecordSet) ColMap() map[string]string {
+	return map[string]string{
+		"Name": "Name", "Type": "Type", "Data": "Data",
+		"Weight": "Weight", "Share": "Share", "TTL": "TTL",
+	}
+}
+
+func (rs *RecordSet) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(rs.Members))
+
+	for _, m := range rs.Members {
+		o := map[string]any{
+			"Name": m.Name, "Type": m.Type, "Data": m.Data,
+			"Weight": m.Weight, "Share": fmt.Sprintf("%.0f%%", m.Share), "TTL": m.TTL,
+		}
+		out = append(out, o)
+	}
+
+	return out
+}
+
+// RecordTemplateChange is a change made to sync a domain with a record template.
+type RecordTemplateChange struct {
+	Action string `json:"action"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Data   string `json:"data"`
+}
+
+type RecordTemplateChanges struct {
+	Changes []RecordTemplateChange
+}
+
+var _ Displayable = &RecordTemplateChanges{}
+
+func (rc *RecordTemplateChanges) JSON(out io.Writer) error {
+	return writeJSON(rc.Changes, out)
+}
+
+func (rc *RecordTemplateChanges) Cols() []string {
+	return []string{"Action", "Name", "Type", "Data"}
+}
+
+func (rc *RecordTemplateChanges) ColMap() map[string]string {
+	return map[string]string{
+		"Action": "Action", "Name": "Name", "Type": "Type", "Data": "Data",
+	}
+}
+
+func (rc *RecordTemplateChanges) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(rc.Changes))
+
+	for _, c := range rc.Changes {
+		o := map[string]any{
+			"Action": c.Action, "Name": c.Name, "Type": c.Type, "Data": c.Data,
+		}
+		out = append(out, o)
+	}
+
+	return out
+}
+
+// The statuses of an EmailAuthCheck.
+const (
+	EmailAuthPass    = "pass"
+	EmailAuthWarn    = "warn"
+	EmailAuthFail    = "fail"
+	EmailAuthSkipped = "skipped"
+)
+
+// EmailAuthCheck is the result of evaluating an email authentication record.
+type EmailAuthCheck struct {
+	Record string `json:"record"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Value  string `json:"value,omitempty"`
+}
+
+type EmailAuthChecks struct {
+	Checks []EmailAuthCheck
+}
+
+var _ Displayable = &EmailAuthChecks{}
+
+func (ec *EmailAuthChecks) JSON(out io.Writer) error {
+	return writeJSON(ec.Checks, out)
+}
+
+func (ec *EmailAuthChecks) Cols() []string {
+	return []string{"Record", "Name", "Status", "Detail"}
+}
+
+func (ec *EmailAuthChecks) ColMap() map[string]string {
+	return map[string]string{
+		"Record": "Record", "Name": "Name", "Status": "Status",
+		"Detail": "Detail", "Value": "Value",
+	}
+}
+
+func (ec *EmailAuthChecks) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(ec.Checks))
+
+	for _, c := range ec.Checks {
+		o := map[string]any{
+			"Record": c.Record, "Name": c.Name, "Status": c.Status,
+			"Detail": c.Detail, "Value": c.Value,
+		}
+		out = append(out, o)
+	}
+
+	return out
+}
+
+// RecordTTL is a record whose TTL is changed to NewTTL by set-ttl, or, in an
+// audit, a record whose TTL is outside the recommended range, which NewTTL
+// is the nearest TTL in.
+type RecordTTL struct {
+	ID     int    `json:"id"`
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Data   string `json:"data"`
+	TTL    int    `json:"ttl"`
+	NewTTL int    `json:"new_ttl"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type RecordTTLs struct {
+	Records []RecordTTL
+	Audit   bool
+}
+
+var _ Displayable = &RecordTTLs{}
+
+func (rt *RecordTTLs) JSON(out io.Writer) error {
+	return writeJSON(rt.Records, out)
+}
+
+func (rt *RecordTTLs) Cols() []string {
+	if rt.Audit {
+		return []string{"ID", "Type", "Name", "TTL", "NewTTL", "Reason"}
+	}
+	return []string{"ID", "Type", "Name", "Data", "TTL", "NewTTL"}
+}
+
+func (rt *RecordTTLs) ColMap() map[string]string {
+	newTTL := "New TTL"
+	if rt.Audit {
+		newTTL = "Recommended TTL"
+	}
+	return map[string]string{
+		"ID": "ID", "Type": "Type", "Name": "Name", "Data": "Data",
+		"TTL": "TTL", "NewTTL": newTTL, "Reason": "Reason",
+	}
+}
+
+func (rt *RecordTTLs) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(rt.Records))
+
+	for _, r := range rt.Records {
+		o := map[string]any{
+			"ID": r.ID, "Type": r.Type, "Name": r.Name, "Data": r.Data,
+			"TTL": r.TTL, "NewTTL": r.NewTTL, "Reason": r.Reason,
+		}
+		out = append(out, o)
+	}
+
+	return out
+}
+
+// RecordRetarget is a record that is retargeted to another address or
+// deleted, and the result of doing so.
+type RecordRetarget struct {
+	Domain string `json:"domain"`
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Action string `json:"action"`
+	From   string `json:"from"`
+	To     string `json:"to,omitempty"`
+	Result string `json:"result"`
+}
+
+type RecordRetargets struct {
+	Records []RecordRetarget
+}
+
+var _ Displayable = &RecordRetargets{}
+
+func (rr *RecordRetargets) JSON(out io.Writer) error {
+	return writeJSON(rr.Records, out)
+}
+
+func (rr *RecordRetargets) Cols() []string {
+	return []string{"Domain", "ID", "Name", "Type", "Action", "From", "To", "Result"}
+}
+
+func (rr *RecordRetargets) ColMap() map[string]string {
+	return map[string]string{
+		"Domain": "Domain", "ID": "ID", "Name": "Name", "Type": "Type",
+		"Action": "Action", "From": "From", "To": "To", "Result": "Result",
+	}
+}
+
+func (rr *RecordRetargets) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(rr.Records))
+
+	for _, r := range rr.Records {
+		o := map[string]any{
+			"Domain": r.Domain, "ID": r.ID, "Name": r.Name, "Type": r.Type,
+			"Action": r.Action, "From": r.From, "To": r.To, "Result": r.Result,
+		}
+		out = append(out, o)
+	}
+
+	return out
+}
+
+// DomainReport summarizes the records of a domain and the ones that are
+// likely mistakes.
+type DomainReport struct {
+	Domain   string                `json:"domain"`
+	Counts   map[string]int        `json:"counts"`
+	Findings []DomainReportFinding `json:"findings"`
+}
+
+// DomainReportFinding is a record, or set of records, found by a check of a
+// domain report.
+type DomainReportFinding struct {
+	Check  string `json:"check"`
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Data   string `json:"data,omitempty"`
+	Detail string `json:"detail"`
+}
+
+var _ Displayable = &DomainReport{}
+
+func (dr *DomainReport) JSON(out io.Writer) error {
+	return writeJSON(dr, out)
+}
+
+func (dr *DomainReport) Cols() []string {
+	return []string{"Check", "Type", "Name", "Data", "Detail"}
+}
+
+func (dr *DomainReport) ColMap() map[string]string {
+	return map[string]string{
+		"Check": "Check", "Type": "Type", "Name": "Name", "Data": "Data", "Detail": "Detail",
+	}
+}
+
+// KV lists the record count of each type, followed by the findings.
+func (dr *DomainReport) KV() []map[string]any {
+	types := make([]string, 0, len(dr.Counts))
+	for t := range dr.Counts {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	out := make([]map[string]any, 0, len(types)+len(dr.Findings))
+	for _, t := range types {
+		o := map[string]any{
+			"Check": "count", "Type": t, "Name": "", "Data": "",
+			"Detail": fmt.Sprintf("%d records", dr.Counts[t]),
+		}
+		out = append(out, o)
+	}
+	for _, f := range dr.Findings {
+		o := map[string]any{
+			"Check": f.Check, "Type": f.Type, "Name": f.Name, "Data": f.Data, "Detail": f.Detail,
+		}
+		out = append(out, o)
+	}
+
+	return out
+}
+
+// DomainImportRecord is a record of a transferred zone and what an import
+// does with it.
+type DomainImportRecord struct {
+	Action string `json:"action"`
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Data   string `json:"data"`
+	TTL    int    `json:"ttl"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type DomainImport struct {
+	Records []DomainImportRecord
+}
+
+var _ Displayable = &DomainImport{}
+
+func (di *DomainImport) JSON(out io.Writer) error {
+	return writeJSON(di.Records, out)
+}
+
+func (di *DomainImport) Cols() []string {
+	return []string{"Action", "Type", "Name", "Data", "TTL", "Detail"}
+}
+
+func (di *DomainImport) ColMap() map[string]string {
+	return map[string]string{
+		"Action": "Action", "Type": "Type", "Name": "Name", "Data": "Data", "TTL": "TTL", "Detail": "Detail",
+	}
+}
+
+func (di *DomainImport) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(di.Records))
+
+	for _, r := range di.Records {
+		o := map[string]any{
+			"Action": r.Action, "Type": r.Type, "Name": r.Name, "Data": r.Data, "TTL": r.TTL, "Detail": r.Detail,
+		}
+		out = append(out, o)
+	}
+
+	return out
+}
@@ -72,3 +72,190 @@ func (d *Droplet) KV() []map[string]any {
 
 	return out
 }
+
+// DropletPTR is the reverse DNS status of one of a Droplet's public addresses.
+type DropletPTR struct {
+	ID      int      `json:"id"`
+	Name    string   `json:"name"`
+	IP      string   `json:"ip"`
+	PTR     []string `json:"ptr"`
+	Forward []string `json:"forward"`
+	Status  string   `json:"status"`
+}
+
+type DropletPTRs struct {
+	PTRs []DropletPTR
+}
+
+var _ Displayable = &DropletPTRs{}
+
+func (dp *DropletPTRs) JSON(out io.Writer) error {
+	return writeJSON(dp.PTRs, out)
+}
+
+func (dp *DropletPTRs) Cols() []string {
+	return []string{"ID", "Name", "IP", "PTR", "Forward", "Status"}
+}
+
+func (dp *DropletPTRs) ColMap() map[string]string {
+	return map[string]string{
+		"ID": "ID", "Name": "Name", "IP": "IP", "PTR": "PTR",
+		"Forward": "Forward Addresses", "Status": "Status",
+	}
+}
+
+func (dp *DropletPTRs) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(dp.PTRs))
+	for _, p := range dp.PTRs {
+		m := map[string]any{
+			"ID": p.ID, "Name": p.Name, "IP": p.IP,
+			"PTR": strings.Join(p.PTR, ","), "Forward": strings.Join(p.Forward, ","),
+			"Status": p.Status,
+		}
+		out = append(out, m)
+	}
+
+	return out
+}
+
+// DropletIPv6Record is the status of the AAAA record for one of a Droplet's
+// DNS names.
+type DropletIPv6Record struct {
+	ID     int      `json:"id"`
+	Name   string   `json:"name"`
+	IPv6   string   `json:"ipv6"`
+	Domain string   `json:"domain,omitempty"`
+	Record string   `json:"record,omitempty"`
+	TTL    int      `json:"ttl,omitempty"`
+	AAAA   []string `json:"aaaa,omitempty"`
+	Status string   `json:"status"`
+}
+
+type DropletIPv6Records struct {
+	Records []DropletIPv6Record
+}
+
+var _ Displayable = &DropletIPv6Records{}
+
+func (dr *DropletIPv6Records) JSON(out io.Writer) error {
+	return writeJSON(dr.Records, out)
+}
+
+func (dr *DropletIPv6Records) Cols() []string {
+	return []string{"ID", "Name", "IPv6", "Domain", "Record", "AAAA", "Status"}
+}
+
+func (dr *DropletIPv6Records) ColMap() map[string]string {
+	return map[string]string{
+		"ID": "ID", "Name": "Name", "IPv6": "IPv6 Address", "Domain": "Domain",
+		"Record": "Record", "AAAA": "AAAA Records", "Status": "Status",
+	}
+}
+
+func (dr *DropletIPv6Records) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(dr.Records))
+	for _, r := range dr.Records {
+		m := map[string]any{
+			"ID": r.ID, "Name": r.Name, "IPv6": r.IPv6, "Domain": r.Domain,
+			"Record": r.Record, "AAAA": strings.Join(r.AAAA, ","), "Status": r.Status,
+		}
+		out = append(out, m)
+	}
+
+	return out
+}
+
+// DropletTransfer is the result of copying files to or from one Droplet.
+type DropletTransfer struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Host   string `json:"host"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type DropletTransfers struct {
+	Transfers []DropletTransfer
+}
+
+var _ Displayable = &DropletTransfers{}
+
+func (dt *DropletTransfers) JSON(out io.Writer) error {
+	return writeJSON(dt.Transfers, out)
+}
+
+func (dt *DropletTransfers) Cols() []string {
+	return []string{"ID", "Name", "Host", "Status", "Error"}
+}
+
+func (dt *DropletTransfers) ColMap() map[string]string {
+	return map[string]string{
+		"ID": "ID", "Name": "Name", "Host": "Host", "Status": "Status", "Error": "Error",
+	}
+}
+
+func (dt *DropletTransfers) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(dt.Transfers))
+	for _, t := range dt.Transfers {
+		m := map[string]any{
+			"ID": t.ID, "Name": t.Name, "Host": t.Host, "Status": t.Status, "Error": t.Error,
+		}
+		out = append(out, m)
+	}
+
+	return out
+}
+
+// DropletUsage is the recent resource usage of a Droplet. Values are nil
+// when no metrics are available.
+type DropletUsage struct {
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	CPU      *float64 `json:"cpu_percent"`
+	Memory   *float64 `json:"memory_percent"`
+	Disk     *float64 `json:"disk_percent"`
+	Inbound  *float64 `json:"public_inbound_mbps"`
+	Outbound *float64 `json:"public_outbound_mbps"`
+}
+
+type DropletUsages struct {
+	Usages []DropletUsage
+}
+
+var _ Displayable = &DropletUsages{}
+
+func (du *DropletUsages) JSON(out io.Writer) error {
+	return writeJSON(du.Usages, out)
+}
+
+func (du *DropletUsages) Cols() []string {
+	return []string{"ID", "Name", "CPU", "Memory", "Disk", "Inbound", "Outbound"}
+}
+
+func (du *DropletUsages) ColMap() map[string]string {
+	return map[string]string{
+		"ID": "ID", "Name": "Name", "CPU": "CPU", "Memory": "Memory", "Disk": "Disk",
+		"Inbound": "Public In (Mbps)", "Outbound": "Public Out (Mbps)",
+	}
+}
+
+func (du *DropletUsages) KV() []map[string]any {
+	format := func(v *float64, suffix string) string {
+		if v == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.1f%s", *v, suffix)
+	}
+
+	out := make([]map[string]any, 0, len(du.Usages))
+	for _, u := range du.Usages {
+		m := map[string]any{
+			"ID": u.ID, "Name": u.Name,
+			"CPU": format(u.CPU, "%"), "Memory": format(u.Memory, "%"), "Disk": format(u.Disk, "%"),
+			"Inbound": format(u.Inbound, ""), "Outbound": format(u.Outbound, ""),
+		}
+		out = append(out, m)
+	}
+
+	return out
+}
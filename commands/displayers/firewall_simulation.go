@@ -0,0 +1,92 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"io"
+)
+
+// FirewallVerdict is the outcome of simulating traffic through a firewall.
+type FirewallVerdict struct {
+	FirewallID string `json:"firewall_id"`
+	Direction  string `json:"direction"`
+	Protocol   string `json:"protocol"`
+	Port       int    `json:"port,omitempty"`
+	Address    string `json:"address"`
+	Allowed    bool   `json:"allowed"`
+	// Rule is the matching rule, in the format of --inbound-rules and --outbound-rules.
+	Rule string `json:"rule,omitempty"`
+	// MatchedBy is the source or destination of Rule that the address matched.
+	MatchedBy string `json:"matched_by,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+type FirewallSimulation struct {
+	Verdicts []FirewallVerdict
+}
+
+var _ Displayable = &FirewallSimulation{}
+
+func (f *FirewallSimulation) JSON(out io.Writer) error {
+	return writeJSON(f.Verdicts, out)
+}
+
+func (f *FirewallSimulation) Cols() []string {
+	return []string{
+		"Allowed",
+		"Direction",
+		"Protocol",
+		"Port",
+		"Address",
+		"Rule",
+		"MatchedBy",
+		"Reason",
+	}
+}
+
+func (f *FirewallSimulation) ColMap() map[string]string {
+	return map[string]string{
+		"Allowed":   "Allowed",
+		"Direction": "Direction",
+		"Protocol":  "Protocol",
+		"Port":      "Port",
+		"Address":   "Address",
+		"Rule":      "Rule",
+		"MatchedBy": "Matched By",
+		"Reason":    "Reason",
+	}
+}
+
+func (f *FirewallSimulation) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(f.Verdicts))
+
+	for _, v := range f.Verdicts {
+		port := any(v.Port)
+		if v.Port == 0 {
+			port = ""
+		}
+		out = append(out, map[string]any{
+			"Allowed":   v.Allowed,
+			"Direction": v.Direction,
+			"Protocol":  v.Protocol,
+			"Port":      port,
+			"Address":   v.Address,
+			"Rule":      v.Rule,
+			"MatchedBy": v.MatchedBy,
+			"Reason":    v.Reason,
+		})
+	}
+
+	return out
+}
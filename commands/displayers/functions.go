@@ -24,6 +24,8 @@ import (
 // Functions is the type of the displayer for functions list
 type Functions struct {
 	Info []whisk.Action
+	// APIHost is the API host of the namespace, used to compute invocation URLs.
+	APIHost string
 }
 
 var _ Displayable = &Functions{}
@@ -36,7 +38,7 @@ func (i *Functions) JSON(out io.Writer) error {
 // Cols is the displayer Cols method specialized for functions list
 func (i *Functions) Cols() []string {
 	return []string{
-		"Update", "Version", "Runtime", "Function",
+		"Update", "Version", "Runtime", "Memory", "Timeout", "Web", "Function",
 	}
 }
 
@@ -46,7 +48,11 @@ func (i *Functions) ColMap() map[string]string {
 		"Update":   "Latest Update",
 		"Runtime":  "Runtime Kind",
 		"Version":  "Latest Version",
+		"Memory":   "Memory (MB)",
+		"Timeout":  "Timeout",
+		"Web":      "Web",
 		"Function": "Function Name",
+		"URL":      "Invocation URL",
 	}
 }
 
@@ -58,7 +64,17 @@ func (i *Functions) KV() []map[string]any {
 			"Update":   time.UnixMilli(ii.Updated).Format("01/02 03:04:05"),
 			"Runtime":  findRuntime(ii.Annotations),
 			"Version":  ii.Version,
+			"Memory":   "",
+			"Timeout":  "",
+			"Web":      isWebFunction(ii.Annotations),
 			"Function": computeFunctionName(ii.Name, ii.Namespace),
+			"URL":      computeFunctionURL(i.APIHost, ii),
+		}
+		if ii.Limits != nil && ii.Limits.Memory != nil {
+			x["Memory"] = *ii.Limits.Memory
+		}
+		if ii.Limits != nil && ii.Limits.Timeout != nil {
+			x["Timeout"] = (time.Duration(*ii.Limits.Timeout) * time.Millisecond).String()
 		}
 		out = append(out, x)
 	}
@@ -85,3 +101,32 @@ func computeFunctionName(simpleName string, namespace string) string {
 	}
 	return simpleName
 }
+
+// isWebFunction reports whether a function is web-exported, according to its annotations
+func isWebFunction(annots whisk.KeyValueArr) bool {
+	for i := range annots {
+		if annots[i].Key == "web-export" {
+			web, _ := annots[i].Value.(bool)
+			return web
+		}
+	}
+	return false
+}
+
+// computeFunctionURL computes the URL a function is invoked at: its web URL if it is web-exported
+// and its REST API URL otherwise. It returns an empty string if the API host is not known.
+func computeFunctionURL(apiHost string, action whisk.Action) string {
+	if apiHost == "" {
+		return ""
+	}
+	apiHost = strings.TrimSuffix(apiHost, "/")
+	nsparts := strings.SplitN(action.Namespace, "/", 2)
+	if isWebFunction(action.Annotations) {
+		pkg := "default"
+		if len(nsparts) > 1 {
+			pkg = nsparts[1]
+		}
+		return apiHost + "/api/v1/web/" + nsparts[0] + "/" + pkg + "/" + action.Name
+	}
+	return apiHost + "/api/v1/namespaces/" + nsparts[0] + "/actions/" + computeFunctionName(action.Name, action.Namespace)
+}
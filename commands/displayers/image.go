@@ -14,7 +14,9 @@ limitations under the License.
 package displayers
 
 import (
+	"fmt"
 	"io"
+	"strings"
 
 	"github.com/digitalocean/doctl/do"
 )
@@ -61,3 +63,50 @@ func (gi *Image) KV() []map[string]any {
 
 	return out
 }
+
+// PrunableImage is an image or snapshot that nothing appears to use.
+type PrunableImage struct {
+	ID      int      `json:"id"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Regions []string `json:"regions"`
+	Created string   `json:"created_at"`
+	AgeDays int      `json:"age_days"`
+	SizeGB  float64  `json:"size_gigabytes"`
+	// MonthlyCost is the estimated monthly cost of storing the image, in USD.
+	MonthlyCost float64 `json:"monthly_cost"`
+	Deleted     bool    `json:"deleted"`
+}
+
+type PrunableImages struct {
+	Images []PrunableImage
+}
+
+var _ Displayable = &PrunableImages{}
+
+func (pi *PrunableImages) JSON(out io.Writer) error {
+	return writeJSON(pi.Images, out)
+}
+
+func (pi *PrunableImages) Cols() []string {
+	return []string{"ID", "Name", "Type", "Regions", "Created", "AgeDays", "SizeGB", "MonthlyCost", "Deleted"}
+}
+
+func (pi *PrunableImages) ColMap() map[string]string {
+	return map[string]string{
+		"ID": "ID", "Name": "Name", "Type": "Type", "Regions": "Regions", "Created": "Created At",
+		"AgeDays": "Age (days)", "SizeGB": "Size (GiB)", "MonthlyCost": "Monthly Cost", "Deleted": "Deleted",
+	}
+}
+
+func (pi *PrunableImages) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(pi.Images))
+	for _, i := range pi.Images {
+		out = append(out, map[string]any{
+			"ID": i.ID, "Name": i.Name, "Type": i.Type, "Regions": strings.Join(i.Regions, ","),
+			"Created": i.Created, "AgeDays": i.AgeDays, "SizeGB": fmt.Sprintf("%.2f", i.SizeGB),
+			"MonthlyCost": fmt.Sprintf("$%.2f", i.MonthlyCost), "Deleted": i.Deleted,
+		})
+	}
+	return out
+}
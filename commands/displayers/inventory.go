@@ -0,0 +1,71 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"io"
+	"strings"
+)
+
+// InventoryChange is a resource, or a relationship between resources, that
+// was added, removed or changed between two inventories.
+type InventoryChange struct {
+	// Change is added, removed or changed.
+	Change string `json:"change"`
+	// Type is the type of the resource, or relationship.
+	Type string `json:"type"`
+	URN  string `json:"urn,omitempty"`
+	Name string `json:"name,omitempty"`
+	// Fields are the fields of a changed resource that differ.
+	Fields []string `json:"fields,omitempty"`
+	// From, Relationship and To describe a relationship.
+	From         string `json:"from,omitempty"`
+	Relationship string `json:"relationship,omitempty"`
+	To           string `json:"to,omitempty"`
+}
+
+type InventoryChanges struct {
+	Changes []InventoryChange
+}
+
+var _ Displayable = &InventoryChanges{}
+
+func (c *InventoryChanges) JSON(out io.Writer) error {
+	return writeJSON(c.Changes, out)
+}
+
+func (c *InventoryChanges) Cols() []string {
+	return []string{"Change", "Type", "URN", "Name", "Details"}
+}
+
+func (c *InventoryChanges) ColMap() map[string]string {
+	return map[string]string{
+		"Change": "Change", "Type": "Type", "URN": "URN", "Name": "Name", "Details": "Details",
+	}
+}
+
+func (c *InventoryChanges) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(c.Changes))
+	for _, ch := range c.Changes {
+		m := map[string]any{
+			"Change": ch.Change, "Type": ch.Type, "URN": ch.URN, "Name": ch.Name,
+			"Details": strings.Join(ch.Fields, ","),
+		}
+		if ch.Relationship != "" {
+			m["URN"], m["Details"] = ch.From, ch.Relationship+" "+ch.To
+		}
+		out = append(out, m)
+	}
+	return out
+}
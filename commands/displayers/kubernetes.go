@@ -1,6 +1,7 @@
 package displayers
 
 import (
+	"fmt"
 	"io"
 	"strings"
 
@@ -341,3 +342,78 @@ func flattenAssociatedResourceIDs(resources []*godo.AssociatedResource) (out []s
 
 	return out
 }
+
+// KubernetesNamespaceCost is the share of a cluster's node costs attributed
+// to a namespace by the resources its pods request. Namespace is empty for
+// the capacity no pod requests.
+type KubernetesNamespaceCost struct {
+	Namespace      string   `json:"namespace"`
+	Pods           int      `json:"pods"`
+	CPURequests    float64  `json:"cpu_requests"`
+	MemoryRequests float64  `json:"memory_requests_bytes"`
+	CPUUsed        *float64 `json:"cpu_used,omitempty"`
+	MemoryUsed     *float64 `json:"memory_used_bytes,omitempty"`
+	MonthlyCost    float64  `json:"monthly_cost"`
+}
+
+type KubernetesCosts struct {
+	Costs []KubernetesNamespaceCost
+}
+
+var _ Displayable = &KubernetesCosts{}
+
+func (kc *KubernetesCosts) JSON(out io.Writer) error {
+	return writeJSON(kc.Costs, out)
+}
+
+func (kc *KubernetesCosts) Cols() []string {
+	return []string{
+		"Namespace",
+		"Pods",
+		"CPURequests",
+		"CPUUsed",
+		"MemoryRequests",
+		"MemoryUsed",
+		"MonthlyCost",
+	}
+}
+
+func (kc *KubernetesCosts) ColMap() map[string]string {
+	return map[string]string{
+		"Namespace":      "Namespace",
+		"Pods":           "Pods",
+		"CPURequests":    "CPU Requests",
+		"CPUUsed":        "CPU Used",
+		"MemoryRequests": "Memory Requests",
+		"MemoryUsed":     "Memory Used",
+		"MonthlyCost":    "Est. Monthly Cost",
+	}
+}
+
+func (kc *KubernetesCosts) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(kc.Costs))
+
+	for _, c := range kc.Costs {
+		namespace := c.Namespace
+		if namespace == "" {
+			namespace = "(idle)"
+		}
+		o := map[string]any{
+			"Namespace":      namespace,
+			"Pods":           c.Pods,
+			"CPURequests":    fmt.Sprintf("%.2f", c.CPURequests),
+			"CPUUsed":        "-",
+			"MemoryRequests": fmt.Sprintf("%.0f MiB", c.MemoryRequests/(1<<20)),
+			"MemoryUsed":     "-",
+			"MonthlyCost":    fmt.Sprintf("$%.2f", c.MonthlyCost),
+		}
+		if c.CPUUsed != nil {
+			o["CPUUsed"] = fmt.Sprintf("%.2f", *c.CPUUsed)
+		}
+		if c.MemoryUsed != nil {
+			o["MemoryUsed"] = fmt.Sprintf("%.0f MiB", *c.MemoryUsed/(1<<20))
+		}
+		out = append(out, o)
+	}
+	return out
+}
@@ -21,8 +21,12 @@ import (
 	"reflect"
 	"strings"
 	"text/tabwriter"
+	"text/template"
 )
 
+// Localize returns the text to show for a column header. It is replaced to customize or translate output.
+var Localize = func(s string) string { return s }
+
 // Displayable is a displayable entity. These are used for printing results.
 type Displayable interface {
 	Cols() []string
@@ -36,12 +40,14 @@ type Displayer struct {
 	OutputType string
 	ColumnList string
 	NoHeaders  bool
+	// Template, if set, renders text output instead of the table. See TemplateData.
+	Template *template.Template
 
 	Item Displayable
 	Out  io.Writer
 }
 
-// Display ends up rendering the content in one of two formats (text|json)
+// Display ends up rendering the content in one of three formats (text|json|ndjson)
 func (d *Displayer) Display() error {
 	switch d.OutputType {
 	case "json":
@@ -50,6 +56,11 @@ func (d *Displayer) Display() error {
 			return err
 		}
 		return d.Item.JSON(d.Out)
+	case "ndjson":
+		if containsOnlyNilSlice(d.Item) {
+			return nil
+		}
+		return DisplayNDJSON(d.Item, d.Out)
 	case "text":
 		var cols []string
 		for _, c := range strings.Split(strings.Join(strings.Fields(d.ColumnList), ""), ",") {
@@ -58,6 +69,9 @@ func (d *Displayer) Display() error {
 			}
 		}
 
+		if d.Template != nil {
+			return DisplayTemplate(d.Item, d.Out, d.Template, cols)
+		}
 		return DisplayText(d.Item, d.Out, d.NoHeaders, cols)
 	default:
 		return fmt.Errorf("unknown output type")
@@ -70,27 +84,46 @@ func DisplayText(item Displayable, out io.Writer, noHeaders bool, includeCols []
 	w := new(tabwriter.Writer)
 	w.Init(out, 0, 0, 4, ' ', 0)
 
-	cols := item.Cols()
-	if len(includeCols) > 0 && includeCols[0] != "" {
-		cols = includeCols
+	cols := textColumns(item, includeCols)
+	if !noHeaders {
+		if err := writeTextHeader(w, item, cols); err != nil {
+			return err
+		}
 	}
+	writeTextRows(w, item, cols)
 
-	if !noHeaders {
-		headers := make([]string, 0, len(cols))
-		for _, k := range cols {
-			col := item.ColMap()[k]
-			if col == "" {
-				return fmt.Errorf("unknown column %q", k)
-			}
+	return w.Flush()
+}
+
+// textColumns returns the columns of item to show: includeCols, if any, or
+// else all of them.
+func textColumns(item Displayable, includeCols []string) []string {
+	if len(includeCols) > 0 && includeCols[0] != "" {
+		return includeCols
+	}
+	return item.Cols()
+}
 
-			headers = append(headers, col)
+func writeTextHeader(w io.Writer, item Displayable, cols []string) error {
+	headers := make([]string, 0, len(cols))
+	for _, k := range cols {
+		col := item.ColMap()[k]
+		if col == "" {
+			return fmt.Errorf("unknown column %q", k)
 		}
-		fmt.Fprintln(w, strings.Join(headers, "\t"))
+
+		headers = append(headers, Localize(col))
 	}
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	return nil
+}
 
+func writeTextRows(w io.Writer, item Displayable, cols []string) {
+	values := make([]any, 0, len(cols))
+	formats := make([]string, 0, len(cols))
 	for _, r := range item.KV() {
-		values := make([]any, 0, len(cols))
-		formats := make([]string, 0, len(cols))
+		values = values[:0]
+		formats = formats[:0]
 
 		for _, col := range cols {
 			v := r[col]
@@ -113,8 +146,206 @@ func DisplayText(item Displayable, out io.Writer, noHeaders bool, includeCols []
 		format := strings.Join(formats, "\t")
 		fmt.Fprintf(w, format+"\n", values...)
 	}
+}
 
-	return w.Flush()
+// DisplayNDJSON writes each record of an item to the passed in io.Writer as
+// JSON on a line of its own.
+func DisplayNDJSON(item Displayable, out io.Writer) error {
+	var buf bytes.Buffer
+	return displayNDJSON(item, out, &buf)
+}
+
+// displayNDJSON is DisplayNDJSON with a buffer that is reused across calls.
+func displayNDJSON(item Displayable, out io.Writer, buf *bytes.Buffer) error {
+	records, err := jsonRecords(item, buf)
+	if err != nil {
+		return err
+	}
+
+	var line bytes.Buffer
+	for _, r := range records {
+		line.Reset()
+		if err := json.Compact(&line, r); err != nil {
+			return err
+		}
+		line.WriteByte('\n')
+		if _, err := line.WriteTo(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonRecords returns the elements of the JSON of an item that is a list, or
+// else the JSON of the item itself. buf is used to write the JSON to, so the
+// records are only valid until it is reused.
+func jsonRecords(item Displayable, buf *bytes.Buffer) ([]json.RawMessage, error) {
+	buf.Reset()
+	if err := item.JSON(buf); err != nil {
+		return nil, err
+	}
+
+	b := bytes.TrimSpace(buf.Bytes())
+	records := []json.RawMessage{b}
+	if len(b) > 0 && b[0] == '[' {
+		records = nil
+		if err := json.Unmarshal(b, &records); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// PageDisplayer displays the pages of a long list as they are fetched, so
+// that the whole list doesn't have to be kept. The output is the same as
+// that of a Displayer for the whole list: JSON output is a single array, and
+// text output is aligned across pages. Text is written when the displayer is
+// closed; JSON and NDJSON output are written page by page.
+type PageDisplayer struct {
+	// Displayer has the display options. Its Item is an empty list, which
+	// is displayed if no page is.
+	Displayer
+
+	started bool
+	cols    []string
+	text    *tabwriter.Writer
+	rows    []map[string]any
+	buf     bytes.Buffer
+}
+
+// DisplayPage displays a page of the list.
+func (d *PageDisplayer) DisplayPage(item Displayable) error {
+	if !d.started {
+		if err := d.start(); err != nil {
+			return err
+		}
+	}
+
+	switch d.OutputType {
+	case "json":
+		records, err := jsonRecords(item, &d.buf)
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			sep := ",\n  "
+			if !d.started {
+				sep = "[\n  "
+				d.started = true
+			}
+			if _, err := io.WriteString(d.Out, sep); err != nil {
+				return err
+			}
+			var indented bytes.Buffer
+			if err := json.Indent(&indented, r, "  ", "  "); err != nil {
+				return err
+			}
+			if _, err := indented.WriteTo(d.Out); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "ndjson":
+		d.started = true
+		if containsOnlyNilSlice(item) {
+			return nil
+		}
+		return displayNDJSON(item, d.Out, &d.buf)
+	case "text":
+		d.started = true
+		if d.Template != nil {
+			d.rows = append(d.rows, item.KV()...)
+			return nil
+		}
+		writeTextRows(d.text, item, d.cols)
+		return nil
+	default:
+		return fmt.Errorf("unknown output type")
+	}
+}
+
+// start checks the columns and writes the headers of text output.
+func (d *PageDisplayer) start() error {
+	if d.OutputType != "text" {
+		return nil
+	}
+	d.cols = nil
+	for _, c := range strings.Split(strings.Join(strings.Fields(d.ColumnList), ""), ",") {
+		if c != "" {
+			d.cols = append(d.cols, c)
+		}
+	}
+	d.cols = textColumns(d.Item, d.cols)
+	if d.Template != nil {
+		return nil
+	}
+
+	d.text = new(tabwriter.Writer)
+	d.text.Init(d.Out, 0, 0, 4, ' ', 0)
+	if d.NoHeaders {
+		return nil
+	}
+	return writeTextHeader(d.text, d.Item, d.cols)
+}
+
+// Close finishes the output.
+func (d *PageDisplayer) Close() error {
+	if !d.started {
+		if d.OutputType == "text" {
+			if err := d.start(); err != nil {
+				return err
+			}
+		} else {
+			return d.Displayer.Display()
+		}
+	}
+
+	switch {
+	case d.OutputType == "json":
+		_, err := io.WriteString(d.Out, "\n]")
+		return err
+	case d.OutputType == "text" && d.Template != nil:
+		headers := make(map[string]string, len(d.cols))
+		for _, k := range d.cols {
+			col := d.Item.ColMap()[k]
+			if col == "" {
+				return fmt.Errorf("unknown column %q", k)
+			}
+			headers[k] = Localize(col)
+		}
+		return d.Template.Execute(d.Out, TemplateData{Cols: d.cols, Headers: headers, Rows: d.rows})
+	case d.OutputType == "text":
+		return d.text.Flush()
+	}
+	return nil
+}
+
+// TemplateData is what a template overriding text output is executed with.
+type TemplateData struct {
+	// Cols are the keys of the columns to show.
+	Cols []string
+	// Headers maps the keys of the columns to their headers.
+	Headers map[string]string
+	// Rows are the values of the columns of each item.
+	Rows []map[string]any
+}
+
+// DisplayTemplate writes the output of a template for an item to the passed in
+// io.Writer.
+func DisplayTemplate(item Displayable, out io.Writer, tmpl *template.Template, includeCols []string) error {
+	cols := item.Cols()
+	if len(includeCols) > 0 && includeCols[0] != "" {
+		cols = includeCols
+	}
+	headers := make(map[string]string, len(cols))
+	for _, k := range cols {
+		col := item.ColMap()[k]
+		if col == "" {
+			return fmt.Errorf("unknown column %q", k)
+		}
+		headers[k] = Localize(col)
+	}
+	return tmpl.Execute(out, TemplateData{Cols: cols, Headers: headers, Rows: item.KV()})
 }
 
 func writeJSON(item any, w io.Writer) error {
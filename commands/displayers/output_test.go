@@ -3,10 +3,13 @@ package displayers
 import (
 	"bytes"
 	"testing"
+	"text/template"
 
 	"github.com/digitalocean/doctl/do"
 
+	"github.com/digitalocean/godo"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDisplayerDisplay(t *testing.T) {
@@ -46,3 +49,102 @@ func TestDisplayerDisplay(t *testing.T) {
 		})
 	}
 }
+
+func TestDisplayerTextLocalized(t *testing.T) {
+	orig := Localize
+	t.Cleanup(func() { Localize = orig })
+	Localize = func(s string) string {
+		if s == "Name" {
+			return "Nom"
+		}
+		return s
+	}
+
+	item := &Volume{Volumes: []do.Volume{{Volume: &godo.Volume{ID: "vol-1", Name: "data"}}}}
+
+	out := &bytes.Buffer{}
+	displayer := Displayer{OutputType: "text", ColumnList: "ID,Name", Item: item, Out: out}
+	require.NoError(t, displayer.Display())
+	assert.Equal(t, "ID       Nom\nvol-1    data\n", out.String())
+
+	out.Reset()
+	displayer.Template = template.Must(template.New("volume.list").Parse(`{{range .Rows}}{{index $.Headers "Name"}}={{.Name}}{{"\n"}}{{end}}`))
+	require.NoError(t, displayer.Display())
+	assert.Equal(t, "Nom=data\n", out.String())
+}
+
+func TestDisplayerNDJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     Displayable
+		expected string
+	}{
+		{
+			name: "each item of a list is written on a line",
+			item: &Volume{Volumes: []do.Volume{
+				{Volume: &godo.Volume{ID: "vol-1", Name: "data"}},
+				{Volume: &godo.Volume{ID: "vol-2", Name: "logs"}},
+			}},
+			expected: "{\"id\":\"vol-1\",\"region\":null,\"name\":\"data\",\"size_gigabytes\":0,\"description\":\"\",\"droplet_ids\":null,\"created_at\":\"0001-01-01T00:00:00Z\",\"filesystem_type\":\"\",\"filesystem_label\":\"\",\"tags\":null}\n" +
+				"{\"id\":\"vol-2\",\"region\":null,\"name\":\"logs\",\"size_gigabytes\":0,\"description\":\"\",\"droplet_ids\":null,\"created_at\":\"0001-01-01T00:00:00Z\",\"filesystem_type\":\"\",\"filesystem_label\":\"\",\"tags\":null}\n",
+		},
+		{
+			name:     "a single item is written on a line",
+			item:     &Account{Account: &do.Account{Account: &godo.Account{UUID: "8f1e2f6b", Email: "sammy@example.com"}}},
+			expected: "{\"email\":\"sammy@example.com\",\"uuid\":\"8f1e2f6b\"}\n",
+		},
+		{
+			name: "an empty list writes nothing",
+			item: &Volume{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			displayer := Displayer{OutputType: "ndjson", Item: tt.item, Out: out}
+			require.NoError(t, displayer.Display())
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestPageDisplayer(t *testing.T) {
+	volumes := []do.Volume{
+		{Volume: &godo.Volume{ID: "vol-1", Name: "data"}},
+		{Volume: &godo.Volume{ID: "vol-2", Name: "a-much-longer-name"}},
+		{Volume: &godo.Volume{ID: "vol-3", Name: "logs"}},
+	}
+	pages := [][]do.Volume{volumes[:1], {}, volumes[1:]}
+	tmpl := template.Must(template.New("volume.list").Parse(`{{range .Rows}}{{.ID}}{{"\n"}}{{end}}`))
+
+	for _, d := range []Displayer{
+		{OutputType: "json"},
+		{OutputType: "ndjson"},
+		{OutputType: "text", ColumnList: "ID,Name"},
+		{OutputType: "text", NoHeaders: true},
+		{OutputType: "text", Template: tmpl},
+	} {
+		// Paged output is the same as the output of the whole list, and
+		// of an empty list when there are no pages.
+		for _, list := range [][][]do.Volume{pages, nil} {
+			var all []do.Volume
+			for _, page := range list {
+				all = append(all, page...)
+			}
+			want := &bytes.Buffer{}
+			whole := d
+			whole.Item, whole.Out = &Volume{Volumes: all}, want
+			require.NoError(t, whole.Display())
+
+			got := &bytes.Buffer{}
+			pd := &PageDisplayer{Displayer: d}
+			pd.Item, pd.Out = &Volume{}, got
+			for _, page := range list {
+				require.NoError(t, pd.DisplayPage(&Volume{Volumes: page}))
+			}
+			require.NoError(t, pd.Close())
+			assert.Equal(t, want.String(), got.String(), "%s output of %d pages", d.OutputType, len(list))
+		}
+	}
+}
@@ -324,6 +324,10 @@ func (t *RegistrySubscriptionTiers) JSON(out io.Writer) error {
 	return writeJSON(t, out)
 }
 
+func (t *RegistrySubscriptionTiers) jsonValue() any {
+	return t
+}
+
 func (t *RegistrySubscriptionTiers) Cols() []string {
 	return []string{
 		"Name",
@@ -380,6 +384,10 @@ func (t *RegistryAvailableRegions) JSON(out io.Writer) error {
 	return writeJSON(t, out)
 }
 
+func (t *RegistryAvailableRegions) jsonValue() any {
+	return t
+}
+
 func (t *RegistryAvailableRegions) Cols() []string {
 	return []string{
 		"Slug",
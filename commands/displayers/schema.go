@@ -0,0 +1,210 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// jsonSchemaDraft is the JSON Schema dialect produced by JSONSchema.
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonValuer is implemented by displayers whose JSON output is not the value
+// of their first field.
+type jsonValuer interface {
+	jsonValue() any
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// JSONSchema returns a JSON Schema describing the JSON output of d.
+//
+// Most displayers wrap the value they write as JSON in their first field, so
+// the schema is generated from the type of that field, following the rules of
+// encoding/json. Displayers that are not structs are written as they are.
+func JSONSchema(d Displayable, title string) map[string]any {
+	g := &schemaGenerator{defs: map[string]any{}}
+	schema := map[string]any{
+		"$schema": jsonSchemaDraft,
+		"title":   title,
+	}
+	for k, v := range g.schema(jsonOutputType(d)) {
+		schema[k] = v
+	}
+	if len(g.defs) > 0 {
+		schema["$defs"] = g.defs
+	}
+	return schema
+}
+
+// jsonOutputType returns the type of the value d writes as JSON.
+func jsonOutputType(d Displayable) reflect.Type {
+	if v, ok := d.(jsonValuer); ok {
+		return reflect.TypeOf(v.jsonValue())
+	}
+	t := reflect.TypeOf(d)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct && t.NumField() > 0 {
+		return t.Field(0).Type
+	}
+	return t
+}
+
+type schemaGenerator struct {
+	// defs holds the schemas of named struct types, keyed by package and type name.
+	defs map[string]any
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if isTimeType(t) {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		// The output of custom marshalers can't be known without calling them.
+		return map[string]any{}
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := defName(t)
+		if _, ok := g.defs[name]; !ok {
+			// Reserve the name first, since the type may refer to itself.
+			g.defs[name] = nil
+			g.defs[name] = g.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + defRef(name)}
+	default:
+		return map[string]any{}
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	g.addFields(t, properties, &required)
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addFields adds the properties of the fields of t, flattening embedded
+// structs the way encoding/json does.
+func (g *schemaGenerator) addFields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct && !isTimeType(ft) {
+			g.addFields(ft, properties, required)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		switch ft.Kind() {
+		case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		if hasTagOption(opts, "string") {
+			properties[name] = map[string]any{"type": "string"}
+		} else {
+			properties[name] = g.schema(f.Type)
+		}
+		if !hasTagOption(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// isTimeType reports whether t is time.Time or a struct that only embeds it,
+// such as godo.Timestamp.
+func isTimeType(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+	return t.Kind() == reflect.Struct && t.NumField() == 1 && t.Field(0).Anonymous && t.Field(0).Type == timeType
+}
+
+func hasTagOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+func defName(t reflect.Type) string {
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+// defRef escapes a definition name for use in a JSON pointer.
+func defRef(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
@@ -0,0 +1,89 @@
+package displayers
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+)
+
+type schemaTestNode struct {
+	Name     string            `json:"name"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Children []*schemaTestNode `json:"children,omitempty"`
+	Created  godo.Timestamp    `json:"created"`
+	Count    int64             `json:"count,string"`
+	Secret   string            `json:"-"`
+	internal bool
+}
+
+type schemaTestEmbedded struct {
+	*schemaTestNode
+	Updated *time.Time `json:"updated,omitempty"`
+}
+
+// schemaTestNodes is written as JSON as it is.
+type schemaTestNodes []*schemaTestNode
+
+func (schemaTestNodes) Cols() []string            { return nil }
+func (schemaTestNodes) ColMap() map[string]string { return nil }
+func (schemaTestNodes) KV() []map[string]any      { return nil }
+func (schemaTestNodes) JSON(io.Writer) error      { return nil }
+
+// schemaTestEmbeddeds is written as JSON by writing its first field.
+type schemaTestEmbeddeds struct {
+	Items []schemaTestEmbedded
+	Short bool
+}
+
+func (*schemaTestEmbeddeds) Cols() []string            { return nil }
+func (*schemaTestEmbeddeds) ColMap() map[string]string { return nil }
+func (*schemaTestEmbeddeds) KV() []map[string]any      { return nil }
+func (*schemaTestEmbeddeds) JSON(io.Writer) error      { return nil }
+
+func TestJSONSchema(t *testing.T) {
+	nodeSchema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":     map[string]any{"type": "string"},
+			"labels":   map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+			"children": map[string]any{"type": "array", "items": map[string]any{"$ref": "#/$defs/displayers.schemaTestNode"}},
+			"created":  map[string]any{"type": "string", "format": "date-time"},
+			"count":    map[string]any{"type": "string"},
+		},
+		"required": []string{"name", "created", "count"},
+	}
+
+	t.Run("slice displayer", func(t *testing.T) {
+		schema := JSONSchema(schemaTestNodes{}, "doctl test")
+		assert.Equal(t, map[string]any{
+			"$schema": jsonSchemaDraft,
+			"title":   "doctl test",
+			"type":    "array",
+			"items":   map[string]any{"$ref": "#/$defs/displayers.schemaTestNode"},
+			"$defs": map[string]any{
+				"displayers.schemaTestNode": nodeSchema,
+			},
+		}, schema)
+	})
+
+	t.Run("struct displayer", func(t *testing.T) {
+		schema := JSONSchema(&schemaTestEmbeddeds{}, "doctl test")
+		assert.Equal(t, "array", schema["type"])
+		assert.Equal(t, map[string]any{"$ref": "#/$defs/displayers.schemaTestEmbedded"}, schema["items"])
+
+		defs := schema["$defs"].(map[string]any)
+		embedded := defs["displayers.schemaTestEmbedded"].(map[string]any)
+		properties := embedded["properties"].(map[string]any)
+		assert.Equal(t, map[string]any{"type": "string"}, properties["name"])
+		assert.Equal(t, map[string]any{"type": "string", "format": "date-time"}, properties["updated"])
+		assert.Equal(t, []string{"name", "created", "count"}, embedded["required"])
+	})
+
+	t.Run("json value", func(t *testing.T) {
+		schema := JSONSchema(&RegistryAvailableRegions{}, "doctl test")
+		assert.Equal(t, "#/$defs/displayers.RegistryAvailableRegions", schema["$ref"])
+	})
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"io"
+
+	"github.com/digitalocean/doctl/do"
+)
+
+// TeamContext is the team reached through an authentication context.
+type TeamContext struct {
+	Context string      `json:"context"`
+	Current bool        `json:"current"`
+	Account *do.Account `json:"account"`
+}
+
+type Teams struct {
+	Teams []TeamContext
+}
+
+var _ Displayable = &Teams{}
+
+func (t *Teams) JSON(out io.Writer) error {
+	return writeJSON(t.Teams, out)
+}
+
+func (t *Teams) Cols() []string {
+	return []string{
+		"Context", "Team", "TeamUUID", "Email", "Current",
+	}
+}
+
+func (t *Teams) ColMap() map[string]string {
+	return map[string]string{
+		"Context": "Context", "Team": "Team", "TeamUUID": "Team UUID",
+		"Email": "User Email", "Current": "Current",
+	}
+}
+
+func (t *Teams) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(t.Teams))
+	for _, tc := range t.Teams {
+		x := map[string]any{
+			"Context": tc.Context, "Email": tc.Account.Email, "Current": tc.Current,
+			"Team": "", "TeamUUID": "",
+		}
+		if tc.Account.Team != nil {
+			x["Team"] = tc.Account.Team.Name
+			x["TeamUUID"] = tc.Account.Team.UUID
+		}
+		out = append(out, x)
+	}
+
+	return out
+}
+
+type TeamMembers struct {
+	TeamMembers []do.TeamMember
+}
+
+var _ Displayable = &TeamMembers{}
+
+func (t *TeamMembers) JSON(out io.Writer) error {
+	return writeJSON(t.TeamMembers, out)
+}
+
+func (t *TeamMembers) Cols() []string {
+	return []string{
+		"UUID", "Email", "Name", "Role", "Status",
+	}
+}
+
+func (t *TeamMembers) ColMap() map[string]string {
+	return map[string]string{
+		"UUID": "UUID", "Email": "Email", "Name": "Name", "Role": "Role", "Status": "Status",
+	}
+}
+
+func (t *TeamMembers) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(t.TeamMembers))
+	for _, m := range t.TeamMembers {
+		out = append(out, map[string]any{
+			"UUID": m.UUID, "Email": m.Email, "Name": m.Name, "Role": m.Role, "Status": m.Status,
+		})
+	}
+
+	return out
+}
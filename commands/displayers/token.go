@@ -0,0 +1,60 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl/do"
+)
+
+type Token struct {
+	Tokens []do.Token
+}
+
+var _ Displayable = &Token{}
+
+func (t *Token) JSON(out io.Writer) error {
+	return writeJSON(t.Tokens, out)
+}
+
+func (t *Token) Cols() []string {
+	return []string{
+		"ID", "Name", "Scopes", "Expiry", "Token",
+	}
+}
+
+func (t *Token) ColMap() map[string]string {
+	return map[string]string{
+		"ID": "ID", "Name": "Name", "Scopes": "Scopes", "Expiry": "Expires In", "Token": "Token",
+	}
+}
+
+func (t *Token) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(t.Tokens))
+	for _, tok := range t.Tokens {
+		expiry := "never"
+		if tok.ExpirySeconds != nil {
+			expiry = (time.Duration(*tok.ExpirySeconds) * time.Second).String()
+		}
+		out = append(out, map[string]any{
+			"ID": tok.ID, "Name": tok.Name, "Scopes": strings.Join(tok.Scopes, ","),
+			"Expiry": expiry, "Token": tok.AccessToken,
+		})
+	}
+
+	return out
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package displayers
+
+import (
+	"io"
+	"strings"
+)
+
+// SpecValidation is the result of validating a single spec file.
+type SpecValidation struct {
+	File   string   `json:"file"`
+	Type   string   `json:"type"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type SpecValidations struct {
+	Results []SpecValidation
+}
+
+var _ Displayable = &SpecValidations{}
+
+func (v *SpecValidations) JSON(out io.Writer) error {
+	return writeJSON(v.Results, out)
+}
+
+func (v *SpecValidations) Cols() []string {
+	return []string{
+		"File", "Type", "Valid", "Errors",
+	}
+}
+
+func (v *SpecValidations) ColMap() map[string]string {
+	return map[string]string{
+		"File": "File", "Type": "Type", "Valid": "Valid", "Errors": "Errors",
+	}
+}
+
+func (v *SpecValidations) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(v.Results))
+
+	for _, r := range v.Results {
+		o := map[string]any{
+			"File": r.File, "Type": r.Type, "Valid": len(r.Errors) == 0, "Errors": strings.Join(r.Errors, "; "),
+		}
+
+		out = append(out, o)
+	}
+
+	return out
+}
@@ -18,6 +18,7 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/digitalocean/doctl/do"
 )
@@ -77,3 +78,54 @@ func (a *Volume) KV() []map[string]any {
 	}
 	return out
 }
+
+// VolumeSnapshotSchedule is a saved schedule for snapshotting a volume.
+type VolumeSnapshotSchedule struct {
+	ID         string    `json:"id"`
+	VolumeID   string    `json:"volume_id"`
+	VolumeName string    `json:"volume_name"`
+	Region     string    `json:"region"`
+	Cron       string    `json:"cron"`
+	Keep       int       `json:"keep"`
+	LastRun    time.Time `json:"last_run,omitempty"`
+	NextRun    time.Time `json:"next_run,omitempty"`
+}
+
+type VolumeSnapshotSchedules struct {
+	Schedules []VolumeSnapshotSchedule
+}
+
+var _ Displayable = &VolumeSnapshotSchedules{}
+
+func (vs *VolumeSnapshotSchedules) JSON(out io.Writer) error {
+	return writeJSON(vs.Schedules, out)
+}
+
+func (vs *VolumeSnapshotSchedules) Cols() []string {
+	return []string{"ID", "VolumeID", "VolumeName", "Region", "Cron", "Keep", "LastRun", "NextRun"}
+}
+
+func (vs *VolumeSnapshotSchedules) ColMap() map[string]string {
+	return map[string]string{
+		"ID": "ID", "VolumeID": "Volume ID", "VolumeName": "Volume Name", "Region": "Region",
+		"Cron": "Cron", "Keep": "Keep", "LastRun": "Last Run", "NextRun": "Next Run",
+	}
+}
+
+func (vs *VolumeSnapshotSchedules) KV() []map[string]any {
+	out := make([]map[string]any, 0, len(vs.Schedules))
+	for _, s := range vs.Schedules {
+		lastRun, nextRun := "", ""
+		if !s.LastRun.IsZero() {
+			lastRun = s.LastRun.Local().Format(time.RFC3339)
+		}
+		if !s.NextRun.IsZero() {
+			nextRun = s.NextRun.Local().Format(time.RFC3339)
+		}
+		out = append(out, map[string]any{
+			"ID": s.ID, "VolumeID": s.VolumeID, "VolumeName": s.VolumeName, "Region": s.Region,
+			"Cron": s.Cron, "Keep": s.Keep, "LastRun": lastRun, "NextRun": nextRun,
+		})
+	}
+	return out
+}
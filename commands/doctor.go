@@ -0,0 +1,353 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/viper"
+)
+
+// The statuses of doctor checks.
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+	doctorSkip = "skip"
+)
+
+const (
+	// defaultAPIURL is the API doctl calls unless --api-url is set.
+	defaultAPIURL = "https://api.digitalocean.com/"
+	// doctorMaxSkew is the clock skew beyond which the clock is reported.
+	doctorMaxSkew = time.Minute
+	// doctorTokenExpiryWarning is how soon a token must expire to be reported.
+	doctorTokenExpiryWarning = 7 * 24 * time.Hour
+)
+
+// doctorProbe requests url and returns the response and how long it took. It
+// is replaced for testing.
+var doctorProbe = func(url string) (*http.Response, time.Duration, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	start := time.Now()
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp.Body.Close()
+	return resp, time.Since(start), nil
+}
+
+// doctorLookPath finds an executable. It is replaced for testing.
+var doctorLookPath = exec.LookPath
+
+// doctorToolVersion returns the first line a tool prints when it is run with
+// args, which is its version. It is replaced for testing.
+var doctorToolVersion = func(path string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, args...).CombinedOutput()
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// doctorServerlessDir returns the directory of the serverless plugin. It is
+// replaced for testing.
+var doctorServerlessDir = getServerlessDirectory
+
+// doctorTools are the optional tools that some commands run, with the
+// arguments that print their versions.
+var doctorTools = []struct {
+	name    string
+	args    []string
+	purpose string
+	install string
+}{
+	{"kubectl", []string{"version", "--client"}, "manage Kubernetes clusters", "kubectl from https://kubernetes.io/docs/tasks/tools/"},
+	{"ssh", []string{"-V"}, "use `doctl compute ssh` and `doctl compute droplet exec`", "an OpenSSH client from your package manager"},
+}
+
+// Doctor creates the doctor command.
+func Doctor() *Command {
+	cmd := cmdBuilderWithInit(nil, RunDoctor, "doctor", "Diagnose problems with doctl's setup",
+		`Checks the environment doctl runs in and reports each check as `+"`"+`pass`+"`"+`, `+"`"+`warn`+"`"+`, `+"`"+`fail`+"`"+`, or `+"`"+`skip`+"`"+`, with the steps to fix the ones that don't pass:
+
+- whether the API can be reached, and how long a request takes
+- whether the local clock agrees with the API's
+- whether the config file can only be read by you, since it holds access tokens
+- whether an access token is configured, whether the API accepts it, and its scopes and expiry
+- whether the optional tools some commands need, kubectl, ssh, and the serverless plugin, are installed, and their versions
+
+The command exits with a non-zero status if a check fails. Warnings don't fail it.`,
+		Writer, false, displayerType(&displayers.DoctorChecks{}))
+	cmd.GroupID = configureDoctlGroup
+	cmd.Example = `The following example checks doctl's setup and prints the results as JSON: doctl doctor --output json`
+
+	return cmd
+}
+
+// RunDoctor runs the checks of doctl's environment and reports them.
+func RunDoctor(c *CmdConfig) error {
+	var checks []displayers.DoctorCheck
+	checks = append(checks, doctorAPIChecks()...)
+	checks = append(checks, doctorConfigCheck())
+	checks = append(checks, doctorTokenChecks(c)...)
+	for _, tool := range doctorTools {
+		checks = append(checks, doctorToolCheck(tool.name, tool.args, tool.purpose, tool.install))
+	}
+	checks = append(checks, doctorServerlessCheck())
+
+	if err := c.Display(&displayers.DoctorChecks{Checks: checks}); err != nil {
+		return err
+	}
+	for _, check := range checks {
+		if check.Status == doctorFail {
+			return ErrExitSilently
+		}
+	}
+	return nil
+}
+
+// doctorAPIChecks checks that the API can be reached and that the local
+// clock agrees with the Date of its response.
+func doctorAPIChecks() []displayers.DoctorCheck {
+	apiURL := viper.GetString("api-url")
+	if apiURL == "" {
+		apiURL = defaultAPIURL
+	}
+	reach := displayers.DoctorCheck{Check: "API reachability"}
+	skew := displayers.DoctorCheck{Check: "Clock skew"}
+
+	resp, latency, err := doctorProbe(apiURL)
+	if err != nil {
+		reach.Status = doctorFail
+		reach.Detail = err.Error()
+		reach.Remedy = fmt.Sprintf("Check your network connection, proxy settings (HTTPS_PROXY), and firewall, and that %s is the right API URL", apiURL)
+		skew.Status = doctorSkip
+		skew.Detail = "The API could not be reached"
+		return []displayers.DoctorCheck{reach, skew}
+	}
+	reach.Status = doctorPass
+	reach.Detail = fmt.Sprintf("%s answered in %s", apiURL, latency.Round(time.Millisecond))
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		skew.Status = doctorSkip
+		skew.Detail = "The API's response has no Date"
+		return []displayers.DoctorCheck{reach, skew}
+	}
+	// The Date is when the response was made, about half the latency ago,
+	// and has a resolution of a second.
+	offset := time.Until(date.Add(latency / 2)).Round(time.Second)
+	skew.Status = doctorPass
+	skew.Detail = fmt.Sprintf("The local clock is %s from the API's", absDuration(offset))
+	if absDuration(offset) > doctorMaxSkew {
+		skew.Status = doctorWarn
+		skew.Remedy = "Synchronize the clock with NTP. A wrong clock can make TLS certificates, token expiry, and signed requests fail"
+	}
+	return []displayers.DoctorCheck{reach, skew}
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// doctorConfigCheck checks that the config file, which holds access tokens,
+// is only readable by its owner.
+func doctorConfigCheck() displayers.DoctorCheck {
+	check := displayers.DoctorCheck{Check: "Config file"}
+	path := viper.GetString("config")
+
+	info, err := os.Stat(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		check.Status = doctorPass
+		check.Detail = fmt.Sprintf("%s doesn't exist yet; it is created by `doctl auth init`", path)
+		return check
+	case err != nil:
+		check.Status = doctorFail
+		check.Detail = err.Error()
+		check.Remedy = fmt.Sprintf("Make %s readable by you, or choose another config file with --config", path)
+		return check
+	}
+
+	check.Status = doctorPass
+	check.Detail = fmt.Sprintf("%s (%s)", path, info.Mode().Perm())
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		check.Status = doctorWarn
+		check.Detail = fmt.Sprintf("%s can be read by other users (%s), and it holds access tokens", path, info.Mode().Perm())
+		check.Remedy = fmt.Sprintf("Run: chmod 600 %s", path)
+	}
+	return check
+}
+
+// doctorTokenChecks checks the access token of the current context.
+func doctorTokenChecks(c *CmdConfig) []displayers.DoctorCheck {
+	contextName := Context
+	if contextName == "" {
+		contextName = viper.GetString("context")
+	}
+	token := displayers.DoctorCheck{Check: "Access token"}
+	scopes := displayers.DoctorCheck{Check: "Token scopes"}
+
+	if c.getContextAccessToken() == "" {
+		token.Status = doctorFail
+		token.Detail = fmt.Sprintf("No access token is configured for the context %s", contextName)
+		token.Remedy = "Run `doctl auth init`, or set DIGITALOCEAN_ACCESS_TOKEN"
+		scopes.Status = doctorSkip
+		return []displayers.DoctorCheck{token, scopes}
+	}
+	if err := c.initServices(c); err != nil {
+		token.Status = doctorFail
+		token.Detail = err.Error()
+		scopes.Status = doctorSkip
+		return []displayers.DoctorCheck{token, scopes}
+	}
+
+	account, err := c.Account().Get()
+	var errResp *godo.ErrorResponse
+	switch {
+	case errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusUnauthorized:
+		token.Status = doctorFail
+		token.Detail = fmt.Sprintf("The API rejected the token of the context %s; it may be revoked or expired", contextName)
+		token.Remedy = "Create a new token in the control panel and run `doctl auth init`"
+		scopes.Status = doctorSkip
+		return []displayers.DoctorCheck{token, scopes}
+	case err != nil:
+		token.Status = doctorFail
+		token.Detail = err.Error()
+		scopes.Status = doctorSkip
+		return []displayers.DoctorCheck{token, scopes}
+	}
+	token.Status = doctorPass
+	token.Detail = fmt.Sprintf("The token of the context %s is valid for %s", contextName, account.Email)
+	if account.Status != "" && account.Status != "active" {
+		token.Status = doctorWarn
+		token.Detail = fmt.Sprintf("The account %s is %s: %s", account.Email, account.Status, account.StatusMessage)
+		token.Remedy = "Resolve the account's status in the control panel"
+	}
+
+	scopes = doctorScopesCheck(c)
+	return []displayers.DoctorCheck{token, scopes}
+}
+
+// doctorScopesCheck reports the scopes and expiry of the access token.
+func doctorScopesCheck(c *CmdConfig) displayers.DoctorCheck {
+	check := displayers.DoctorCheck{Check: "Token scopes"}
+
+	info, err := c.OAuth().TokenInfo("")
+	if err != nil {
+		check.Status = doctorWarn
+		check.Detail = fmt.Sprintf("The token's scopes could not be looked up: %v", err)
+		return check
+	}
+
+	check.Status = doctorPass
+	check.Detail = "scopes: " + strings.Join(info.Scopes, ", ")
+	if readOnlyScopes(info.Scopes) {
+		check.Status = doctorWarn
+		check.Detail += "; the token can only read, so commands that change resources fail"
+		check.Remedy = "Create a token with write scopes, for example with `doctl auth token create`, if you need to make changes"
+	}
+	if info.ExpiresInSeconds > 0 {
+		expires := time.Duration(info.ExpiresInSeconds) * time.Second
+		check.Detail += fmt.Sprintf("; expires in %s", expires.Round(time.Minute))
+		if expires < doctorTokenExpiryWarning {
+			check.Status = doctorWarn
+			check.Remedy = "Replace the token before it expires with `doctl auth token rotate`"
+		}
+	}
+	return check
+}
+
+// readOnlyScopes reports whether scopes only allow reading.
+func readOnlyScopes(scopes []string) bool {
+	if len(scopes) == 0 {
+		return false
+	}
+	for _, s := range scopes {
+		if s != "read" && !strings.HasSuffix(s, ":read") {
+			return false
+		}
+	}
+	return true
+}
+
+// doctorToolCheck checks that an optional tool is installed and reports its
+// version.
+func doctorToolCheck(name string, args []string, purpose, install string) displayers.DoctorCheck {
+	check := displayers.DoctorCheck{Check: name}
+
+	path, err := doctorLookPath(name)
+	if err != nil {
+		check.Status = doctorWarn
+		check.Detail = fmt.Sprintf("%s is not installed; it is needed to %s", name, purpose)
+		check.Remedy = "Install " + install
+		return check
+	}
+	version, err := doctorToolVersion(path, args...)
+	if err != nil {
+		check.Status = doctorWarn
+		check.Detail = fmt.Sprintf("%s doesn't run: %v", path, err)
+		check.Remedy = "Reinstall " + install
+		return check
+	}
+	check.Status = doctorPass
+	check.Detail = fmt.Sprintf("%s (%s)", version, path)
+	return check
+}
+
+// doctorServerlessCheck checks the serverless plugin (nim) that the
+// `doctl serverless` commands run.
+func doctorServerlessCheck() displayers.DoctorCheck {
+	check := displayers.DoctorCheck{Check: "serverless plugin"}
+
+	dir := doctorServerlessDir()
+	if _, err := os.Stat(dir); err != nil {
+		check.Status = doctorWarn
+		check.Detail = "The serverless plugin is not installed; it is needed by `doctl serverless`"
+		check.Remedy = "Run `doctl serverless install` if you use Functions"
+		return check
+	}
+
+	version := strings.TrimSpace(do.GetCurrentServerlessVersion(dir))
+	check.Status = doctorPass
+	check.Detail = fmt.Sprintf("version %s (%s)", version, dir)
+	current, err := semver.ParseTolerant(version)
+	minimum, minErr := semver.ParseTolerant(do.GetMinServerlessVersion())
+	if err != nil || (minErr == nil && current.LT(minimum)) {
+		check.Status = doctorWarn
+		check.Detail = fmt.Sprintf("version %s is older than the %s doctl needs", version, do.GetMinServerlessVersion())
+		check.Remedy = "Run `doctl serverless upgrade`"
+	}
+	return check
+}
@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubDoctor replaces the probes of the environment: the API's clock is
+// skew ahead of the local one, only the tools in installed are found, and
+// the serverless plugin has version, if it isn't empty.
+func stubDoctor(t *testing.T, skew time.Duration, installed map[string]string, version string) {
+	prevProbe, prevLookPath, prevToolVersion, prevDir := doctorProbe, doctorLookPath, doctorToolVersion, doctorServerlessDir
+	t.Cleanup(func() {
+		doctorProbe, doctorLookPath, doctorToolVersion, doctorServerlessDir = prevProbe, prevLookPath, prevToolVersion, prevDir
+	})
+
+	doctorProbe = func(url string) (*http.Response, time.Duration, error) {
+		h := http.Header{}
+		h.Set("Date", time.Now().Add(skew).UTC().Format(http.TimeFormat))
+		return &http.Response{StatusCode: http.StatusOK, Header: h}, 40 * time.Millisecond, nil
+	}
+	doctorLookPath = func(name string) (string, error) {
+		if _, ok := installed[name]; ok {
+			return "/usr/bin/" + name, nil
+		}
+		return "", errors.New("not found")
+	}
+	doctorToolVersion = func(path string, args ...string) (string, error) {
+		return installed[filepath.Base(path)], nil
+	}
+
+	dir := filepath.Join(t.TempDir(), "sandbox")
+	doctorServerlessDir = func() string { return dir }
+	if version != "" {
+		require.NoError(t, os.MkdirAll(dir, 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "version"), []byte(version), 0600))
+	}
+}
+
+func runDoctor(t *testing.T, config *CmdConfig) (map[string]displayers.DoctorCheck, error) {
+	prev := viper.GetString(doctl.ArgOutput)
+	viper.Set(doctl.ArgOutput, "json")
+	t.Cleanup(func() { viper.Set(doctl.ArgOutput, prev) })
+
+	var buf bytes.Buffer
+	config.Out = &buf
+	err := RunDoctor(config)
+
+	var list []displayers.DoctorCheck
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &list))
+	checks := map[string]displayers.DoctorCheck{}
+	for _, c := range list {
+		checks[c.Check] = c
+	}
+	return checks, err
+}
+
+func setDoctorConfig(t *testing.T, mode os.FileMode) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("access-token: x\n"), mode))
+	prev := viper.GetString("config")
+	viper.Set("config", path)
+	t.Cleanup(func() { viper.Set("config", prev) })
+	return path
+}
+
+func setDoctorToken(t *testing.T, token string) {
+	prev := viper.GetString(doctl.ArgAccessToken)
+	viper.Set(doctl.ArgAccessToken, token)
+	t.Cleanup(func() { viper.Set(doctl.ArgAccessToken, prev) })
+}
+
+func TestDoctorPasses(t *testing.T) {
+	stubDoctor(t, 2*time.Second, map[string]string{"kubectl": "Client Version: v1.31.0", "ssh": "OpenSSH_9.6p1"}, do.GetMinServerlessVersion())
+	setDoctorConfig(t, 0600)
+	setDoctorToken(t, "token")
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.account.EXPECT().Get().Return(&do.Account{Account: &godo.Account{Email: "user@example.com", Status: "active"}}, nil)
+		tm.oauth.EXPECT().TokenInfo("").Return(&do.OAuthTokenInfo{Scopes: []string{"droplet:read", "droplet:create"}}, nil)
+
+		checks, err := runDoctor(t, config)
+		require.NoError(t, err)
+		for _, c := range checks {
+			assert.Equal(t, doctorPass, c.Status, c.Check)
+		}
+		assert.Len(t, checks, 8)
+		assert.Equal(t, "scopes: droplet:read, droplet:create", checks["Token scopes"].Detail)
+		assert.Equal(t, "Client Version: v1.31.0 (/usr/bin/kubectl)", checks["kubectl"].Detail)
+	})
+}
+
+func TestDoctorWarnings(t *testing.T) {
+	stubDoctor(t, -5*time.Minute, nil, "4.0.0")
+	path := setDoctorConfig(t, 0644)
+	setDoctorToken(t, "token")
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.account.EXPECT().Get().Return(&do.Account{Account: &godo.Account{Email: "user@example.com", Status: "active"}}, nil)
+		tm.oauth.EXPECT().TokenInfo("").Return(&do.OAuthTokenInfo{Scopes: []string{"read"}, ExpiresInSeconds: 3600}, nil)
+
+		checks, err := runDoctor(t, config)
+		require.NoError(t, err)
+		assert.Equal(t, doctorWarn, checks["Clock skew"].Status)
+		assert.Equal(t, doctorWarn, checks["Config file"].Status)
+		assert.Equal(t, "Run: chmod 600 "+path, checks["Config file"].Remedy)
+		assert.Equal(t, doctorWarn, checks["Token scopes"].Status)
+		assert.Contains(t, checks["Token scopes"].Detail, "the token can only read")
+		assert.Equal(t, "Replace the token before it expires with `doctl auth token rotate`", checks["Token scopes"].Remedy)
+		assert.Equal(t, doctorWarn, checks["kubectl"].Status)
+		assert.Equal(t, doctorWarn, checks["ssh"].Status)
+		assert.Equal(t, "Run `doctl serverless upgrade`", checks["serverless plugin"].Remedy)
+	})
+}
+
+func TestDoctorFailures(t *testing.T) {
+	stubDoctor(t, 0, nil, "")
+	setDoctorConfig(t, 0600)
+
+	t.Run("no token", func(t *testing.T) {
+		setDoctorToken(t, "")
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			checks, err := runDoctor(t, config)
+			assert.Equal(t, ErrExitSilently, err)
+			assert.Equal(t, doctorFail, checks["Access token"].Status)
+			assert.Equal(t, doctorSkip, checks["Token scopes"].Status)
+		})
+	})
+
+	t.Run("rejected token", func(t *testing.T) {
+		setDoctorToken(t, "revoked")
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			tm.account.EXPECT().Get().Return(nil, &godo.ErrorResponse{Response: &http.Response{StatusCode: http.StatusUnauthorized}})
+
+			checks, err := runDoctor(t, config)
+			assert.Equal(t, ErrExitSilently, err)
+			assert.Contains(t, checks["Access token"].Detail, "The API rejected the token")
+		})
+	})
+
+	t.Run("unreachable API", func(t *testing.T) {
+		setDoctorToken(t, "")
+		doctorProbe = func(url string) (*http.Response, time.Duration, error) {
+			return nil, 0, errors.New("dial tcp: lookup api.digitalocean.com: no such host")
+		}
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			checks, err := runDoctor(t, config)
+			assert.Equal(t, ErrExitSilently, err)
+			assert.Equal(t, doctorFail, checks["API reachability"].Status)
+			assert.Equal(t, doctorSkip, checks["Clock skew"].Status)
+		})
+	})
+}
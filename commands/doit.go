@@ -14,6 +14,7 @@ limitations under the License.
 package commands
 
 import (
+	"bytes"
 	"fmt"
 	"log"
 	"os"
@@ -30,6 +31,7 @@ import (
 
 const (
 	defaultConfigName    = "config.yaml" // default name of config file
+	accessTokenEnv       = "DIGITALOCEAN_ACCESS_TOKEN"
 	manageResourcesGroup = "manageResources"
 	configureDoctlGroup  = "configureDoctl"
 	viewBillingGroup     = "viewBilling"
@@ -54,18 +56,40 @@ var (
 	Output string
 	//Token global authorization token
 	Token string
+	//JQ is a jq expression that filters the JSON output
+	JQ string
 	//Trace toggles http tracing output
 	Trace bool
 	//Verbose toggle verbose output on and off
 	Verbose bool
 	//Interactive toggle interactive behavior
 	Interactive bool
+	//Summary toggles the API usage summary printed after a command
+	Summary bool
+	//ConfigReadOnly prevents the config file from being written
+	ConfigReadOnly bool
+	//ReadOnly rejects API calls that would change anything
+	ReadOnly bool
+	//Record is the directory API calls are recorded to
+	Record string
+	//Replay is the directory recorded API calls are replayed from
+	Replay string
+	//PolicyFile is the path of the policy file restricting which commands may run
+	PolicyFile string
+	//NoConfig ignores the config file
+	NoConfig bool
+	//GitHubOutput integrates with GitHub Actions
+	GitHubOutput bool
 
 	// Retry settings to pass through to godo.RetryConfig
 	RetryMax     int
 	RetryWaitMax int
 	RetryWaitMin int
 
+	// Connection pool settings of the shared API transport
+	MaxIdleConns    int
+	MaxConnsPerHost int
+
 	requiredColor = color.New(color.Bold).SprintfFunc()
 )
 
@@ -85,9 +109,12 @@ func init() {
 	rootPFlagSet.StringVarP(&Token, doctl.ArgAccessToken, "t", "", "API V2 access token")
 	viper.BindPFlag(doctl.ArgAccessToken, rootPFlagSet.Lookup(doctl.ArgAccessToken))
 
-	rootPFlagSet.StringVarP(&Output, doctl.ArgOutput, "o", "text", "Desired output format [text|json]")
+	rootPFlagSet.StringVarP(&Output, doctl.ArgOutput, "o", "text", "Desired output format [text|json|ndjson]")
 	viper.BindPFlag("output", rootPFlagSet.Lookup(doctl.ArgOutput))
 
+	rootPFlagSet.StringVarP(&JQ, doctl.ArgJQ, "", "", "Filter JSON output using a jq `expression`, such as .[].name. Implies --output json")
+	viper.BindPFlag(doctl.ArgJQ, rootPFlagSet.Lookup(doctl.ArgJQ))
+
 	rootPFlagSet.StringVarP(&Context, doctl.ArgContext, "", "", "Specify a custom authentication context name")
 	DoitCmd.RegisterFlagCompletionFunc(doctl.ArgContext, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return getAuthContextList(), cobra.ShellCompDirectiveNoFileComp
@@ -104,6 +131,30 @@ func init() {
 	}
 	rootPFlagSet.BoolVarP(&Interactive, doctl.ArgInteractive, "", interactive, interactiveHelpText)
 
+	rootPFlagSet.BoolVarP(&Summary, doctl.ArgSummary, "", false, "Print a summary of API calls, retries, latency, and rate limit usage after the command completes. The summary is never sent anywhere")
+	viper.BindPFlag(doctl.ArgSummary, rootPFlagSet.Lookup(doctl.ArgSummary))
+
+	rootPFlagSet.BoolVarP(&ConfigReadOnly, doctl.ArgConfigReadOnly, "", false, "Never write the config file. Commands that need to change it fail instead. Useful for parallel jobs sharing a config")
+	viper.BindPFlag(doctl.ArgConfigReadOnly, rootPFlagSet.Lookup(doctl.ArgConfigReadOnly))
+
+	rootPFlagSet.BoolVarP(&ReadOnly, doctl.ArgReadOnly, "", false, "Refuse API calls that create, change, or delete anything, including calls to the clusters, databases, functions, and Spaces it hosts")
+	viper.BindPFlag(doctl.ArgReadOnly, rootPFlagSet.Lookup(doctl.ArgReadOnly))
+
+	rootPFlagSet.StringVarP(&Record, doctl.ArgRecord, "", "", "Record API calls, with secrets redacted, to cassette files in this `directory` for use with --replay")
+	viper.BindPFlag(doctl.ArgRecord, rootPFlagSet.Lookup(doctl.ArgRecord))
+
+	rootPFlagSet.StringVarP(&Replay, doctl.ArgReplay, "", "", "Answer API calls from the cassettes recorded to this `directory` with --record instead of calling the API")
+	viper.BindPFlag(doctl.ArgReplay, rootPFlagSet.Lookup(doctl.ArgReplay))
+
+	rootPFlagSet.StringVarP(&PolicyFile, doctl.ArgPolicyFile, "", "", "Refuse to run commands denied by the policy file at this `path`. See doctl policy --help")
+	viper.BindPFlag(doctl.ArgPolicyFile, rootPFlagSet.Lookup(doctl.ArgPolicyFile))
+
+	rootPFlagSet.BoolVarP(&NoConfig, doctl.ArgNoConfig, "", false, "Ignore the config file and never write it. All settings come from flags and environment variables")
+	viper.BindPFlag(doctl.ArgNoConfig, rootPFlagSet.Lookup(doctl.ArgNoConfig))
+
+	rootPFlagSet.BoolVarP(&GitHubOutput, doctl.ArgGitHubOutput, "", false, "Write key results, such as IDs and URLs, to $GITHUB_OUTPUT and report errors as GitHub Actions annotations")
+	viper.BindPFlag(doctl.ArgGitHubOutput, rootPFlagSet.Lookup(doctl.ArgGitHubOutput))
+
 	rootPFlagSet.IntVar(&RetryMax, "http-retry-max", 5, "Set maximum number of retries for requests that fail with a 429 or 500-level error")
 	viper.BindPFlag("http-retry-max", rootPFlagSet.Lookup("http-retry-max"))
 
@@ -115,9 +166,16 @@ func init() {
 	viper.BindPFlag("http-retry-wait-min", rootPFlagSet.Lookup("http-retry-wait-min"))
 	DoitCmd.PersistentFlags().MarkHidden("http-retry-wait-min")
 
+	rootPFlagSet.IntVar(&MaxIdleConns, doctl.ArgHTTPMaxIdleConns, doctl.DefaultMaxIdleConns, "Set the number of idle API connections kept open for reuse by later requests")
+	viper.BindPFlag(doctl.ArgHTTPMaxIdleConns, rootPFlagSet.Lookup(doctl.ArgHTTPMaxIdleConns))
+
+	rootPFlagSet.IntVar(&MaxConnsPerHost, doctl.ArgHTTPMaxConnsPerHost, doctl.DefaultMaxConnsPerHost, "Set the number of connections opened to each API host. Requests beyond it wait for a free connection. Use 0 for no limit")
+	viper.BindPFlag(doctl.ArgHTTPMaxConnsPerHost, rootPFlagSet.Lookup(doctl.ArgHTTPMaxConnsPerHost))
+
 	addCommands()
+	DoitCmd.SetGlobalNormalizationFunc(normalizeYesFlag)
 
-	cobra.OnInitialize(initConfig)
+	cobra.OnInitialize(initConfig, initDirectoryConfig, initMessages)
 }
 
 func initConfig() {
@@ -133,11 +191,18 @@ func initConfig() {
 	viper.SetDefault(doctl.ArgContext, doctl.ArgDefaultContext)
 	Context = strings.ToLower(Context)
 
+	if viper.GetBool(doctl.ArgNoConfig) {
+		// Drop any settings read from the config file before the flags were parsed.
+		viper.ReadConfig(bytes.NewReader(nil))
+		return
+	}
+
 	if _, err := os.Stat(cfgFile); err == nil {
 		if err := viper.ReadInConfig(); err != nil {
 			log.Fatalln("Config initialization failed:", err)
 		}
 	}
+	configAtLoad, _ = readConfigFile(cfgFile)
 }
 
 // in case we ever want to change this, or let folks configure it...
@@ -159,36 +224,64 @@ func configHome() string {
 // Execute executes the current command using DoitCmd.
 func Execute() {
 	if err := DoitCmd.Execute(); err != nil {
+		githubAnnotate("error", err.Error())
 		if !strings.Contains(err.Error(), "unknown command") {
 			fmt.Println(err)
 		}
-		os.Exit(-1)
+		// Errors from cobra itself are unknown commands and invalid flags.
+		os.Exit(ExitValidation)
 	}
+	printSummary(os.Stderr)
 }
 
 // AddCommands adds sub commands to the base command.
 func addCommands() {
+	addSubcommands(DoitCmd)
+}
+
+// newRootCommand builds a new command tree without doctl's global flags.
+// Building it also binds the config to the new tree's flags.
+func newRootCommand() *Command {
+	root := &Command{Command: &cobra.Command{Use: DoitCmd.Use}}
+	addSubcommands(root)
+	return root
+}
 
-	DoitCmd.AddGroup(&cobra.Group{ID: manageResourcesGroup, Title: "Manage DigitalOcean Resources:"})
-	DoitCmd.AddGroup(&cobra.Group{ID: configureDoctlGroup, Title: "Configure doctl:"})
-	DoitCmd.AddGroup(&cobra.Group{ID: viewBillingGroup, Title: "View Billing:"})
-
-	DoitCmd.AddCommand(Account())
-	DoitCmd.AddCommand(Apps())
-	DoitCmd.AddCommand(Auth())
-	DoitCmd.AddCommand(Balance())
-	DoitCmd.AddCommand(BillingHistory())
-	DoitCmd.AddCommand(Invoices())
-	DoitCmd.AddCommand(computeCmd())
-	DoitCmd.AddCommand(Kubernetes())
-	DoitCmd.AddCommand(Databases())
-	DoitCmd.AddCommand(Projects())
-	DoitCmd.AddCommand(Version())
-	DoitCmd.AddCommand(Registry())
-	DoitCmd.AddCommand(VPCs())
-	DoitCmd.AddCommand(OneClicks())
-	DoitCmd.AddCommand(Monitoring())
-	DoitCmd.AddCommand(Serverless())
+// addSubcommands adds the command groups and sub commands of doctl to root.
+func addSubcommands(root *Command) {
+	root.AddGroup(&cobra.Group{ID: manageResourcesGroup, Title: "Manage DigitalOcean Resources:"})
+	root.AddGroup(&cobra.Group{ID: configureDoctlGroup, Title: "Configure doctl:"})
+	root.AddGroup(&cobra.Group{ID: viewBillingGroup, Title: "View Billing:"})
+
+	root.AddCommand(Account())
+	root.AddCommand(Apps())
+	root.AddCommand(Auth())
+	root.AddCommand(Balance())
+	root.AddCommand(Cleanup())
+	root.AddCommand(BillingHistory())
+	root.AddCommand(Invoices())
+	root.AddCommand(computeCmd())
+	root.AddCommand(Events())
+	root.AddCommand(Kubernetes())
+	root.AddCommand(Databases())
+	root.AddCommand(Projects())
+	root.AddCommand(Version())
+	root.AddCommand(Registry())
+	root.AddCommand(VPCs())
+	root.AddCommand(OneClicks())
+	root.AddCommand(Monitoring())
+	root.AddCommand(Serverless())
+	root.AddCommand(Teams())
+	root.AddCommand(SelfUpdate())
+	root.AddCommand(Validate())
+	root.AddCommand(Schema())
+	root.AddCommand(Serve())
+	root.AddCommand(TUI())
+	root.AddCommand(Policy())
+	root.AddCommand(Doctor())
+	root.AddCommand(CacheProxy())
+	root.AddCommand(Cron())
+	root.AddCommand(Inventory())
 }
 
 func computeCmd() *Command {
@@ -225,6 +318,7 @@ func computeCmd() *Command {
 	// SSH is different since it doesn't have any subcommands. In this case, let's
 	// give it a parent at init time.
 	SSH(cmd)
+	SCP(cmd)
 
 	return cmd
 }
@@ -337,6 +431,9 @@ func flagName(cmd *Command, name string) string {
 }
 
 func cmdNS(cmd *Command) string {
+	if cmd.topLevel {
+		return cmd.Name()
+	}
 	if cmd.Parent() != nil {
 		if cmd.overrideNS != "" {
 			return fmt.Sprintf("%s.%s", cmd.overrideNS, cmd.Name())
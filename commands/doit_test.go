@@ -1,10 +1,16 @@
 package commands
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/digitalocean/doctl"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFlagName(t *testing.T) {
@@ -81,6 +87,15 @@ func TestCmdNS(t *testing.T) {
 			cmd:      CmdBuilder(parent, testFn, "run", "Run it", "", Writer, overrideCmdNS("doctl")),
 			expected: "doctl.run",
 		},
+		{
+			name: "built without parent",
+			cmd: func() *Command {
+				cmd := CmdBuilder(nil, testFn, "serve", "Serve it", "", Writer)
+				parent.AddCommand(cmd)
+				return cmd
+			}(),
+			expected: "serve",
+		},
 	}
 
 	for _, tt := range tests {
@@ -89,3 +104,48 @@ func TestCmdNS(t *testing.T) {
 		})
 	}
 }
+
+func TestInitConfigNoConfig(t *testing.T) {
+	cfgFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(cfgFile, []byte("no-config-test: from-file\n"), 0600))
+
+	origConfig := viper.GetString("config")
+	t.Cleanup(func() {
+		viper.Set("config", origConfig)
+		viper.Set(doctl.ArgNoConfig, false)
+		viper.ReadConfig(bytes.NewReader(nil))
+	})
+	viper.Set("config", cfgFile)
+
+	initConfig()
+	assert.Equal(t, "from-file", viper.GetString("no-config-test"))
+
+	viper.Set(doctl.ArgNoConfig, true)
+	initConfig()
+	assert.Empty(t, viper.GetString("no-config-test"))
+	assert.Equal(t, errNoConfig, writeConfig())
+}
+
+func TestAccessTokenPrecedence(t *testing.T) {
+	origContext := Context
+	t.Cleanup(func() {
+		Context = origContext
+		viper.Set("context", doctl.ArgDefaultContext)
+		viper.Set("auth-contexts", map[string]string{})
+		viper.Set(doctl.ArgAccessToken, "")
+	})
+	viper.Set("context", "work")
+	viper.Set("auth-contexts", map[string]string{"work": "work-token", "home": "home-token"})
+
+	config, err := NewCmdConfig("test", doctl.NewTestConfig(), &bytes.Buffer{}, nil, false)
+	require.NoError(t, err)
+
+	Context = ""
+	assert.Equal(t, "work-token", config.getContextAccessToken(), "the config file's current context")
+
+	t.Setenv(accessTokenEnv, "env-token")
+	assert.Equal(t, "env-token", config.getContextAccessToken(), "the environment over the config file")
+
+	Context = "home"
+	assert.Equal(t, "home-token", config.getContextAccessToken(), "--context over the environment")
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+)
+
+// mxRecord is a mail server of a mail provider.
+type mxRecord struct {
+	host     string
+	priority int
+}
+
+// mxPresets are the mail servers of common mail providers, which
+// `domain create --with-mx` creates MX records for. A host may contain
+// {domain}, the domain with its dots replaced by dashes.
+var mxPresets = map[string][]mxRecord{
+	"google": {
+		{"aspmx.l.google.com.", 1},
+		{"alt1.aspmx.l.google.com.", 5},
+		{"alt2.aspmx.l.google.com.", 5},
+		{"alt3.aspmx.l.google.com.", 10},
+		{"alt4.aspmx.l.google.com.", 10},
+	},
+	"microsoft": {
+		{"{domain}.mail.protection.outlook.com.", 0},
+	},
+	"fastmail": {
+		{"in1-smtp.messagingengine.com.", 10},
+		{"in2-smtp.messagingengine.com.", 20},
+	},
+	"zoho": {
+		{"mx.zoho.com.", 10},
+		{"mx2.zoho.com.", 20},
+		{"mx3.zoho.com.", 50},
+	},
+	"proton": {
+		{"mail.protonmail.ch.", 10},
+		{"mailsec.protonmail.ch.", 20},
+	},
+}
+
+// mxPresetNames returns the names of the MX presets, sorted.
+func mxPresetNames() []string {
+	names := make([]string, 0, len(mxPresets))
+	for name := range mxPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// bootstrapAddresses returns the public addresses of the Droplet or load
+// balancer given with --bootstrap, as droplet:<id> or lb:<id>, keyed by the
+// type of record that points to them.
+func bootstrapAddresses(c *CmdConfig, target string) (map[string]string, error) {
+	kind, id, ok := strings.Cut(target, ":")
+	if !ok || id == "" {
+		return nil, fmt.Errorf("`--%s` must be droplet:<id> or lb:<id>, not %q", doctl.ArgDomainBootstrap, target)
+	}
+
+	switch kind {
+	case "droplet":
+		dropletID, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Droplet ID %q", id)
+		}
+		d, err := c.Droplets().Get(dropletID)
+		if err != nil {
+			return nil, err
+		}
+		addrs := dropletAddresses(*d)
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("Droplet %d has no public addresses", d.ID)
+		}
+		return addrs, nil
+	case "lb":
+		lb, err := c.LoadBalancers().Get(id)
+		if err != nil {
+			return nil, err
+		}
+		if lb.IP == "" {
+			return nil, fmt.Errorf("load balancer %s has no IP address yet", lb.ID)
+		}
+		return map[string]string{"A": lb.IP}, nil
+	default:
+		return nil, fmt.Errorf("`--%s` must be droplet:<id> or lb:<id>, not %q", doctl.ArgDomainBootstrap, target)
+	}
+}
+
+// bootstrapRecords returns the initial records of a new domain: A and AAAA
+// records for the apex pointing to addrs, a www CNAME record for the apex,
+// and the MX records of a mail provider.
+func bootstrapRecords(domain string, addrs map[string]string, www bool, mx string) ([]*do.DomainRecordEditRequest, error) {
+	var records []*do.DomainRecordEditRequest
+	for _, typ := range []string{"A", "AAAA"} {
+		if ip, ok := addrs[typ]; ok {
+			records = append(records, &do.DomainRecordEditRequest{Type: typ, Name: "@", Data: ip})
+		}
+	}
+	if www {
+		records = append(records, &do.DomainRecordEditRequest{Type: "CNAME", Name: "www", Data: domain + "."})
+	}
+	if mx != "" {
+		preset, ok := mxPresets[strings.ToLower(mx)]
+		if !ok {
+			return nil, fmt.Errorf("unknown mail provider %q; `--%s` must be one of %s", mx, doctl.ArgDomainWithMX, strings.Join(mxPresetNames(), ", "))
+		}
+		for _, r := range preset {
+			host := strings.ReplaceAll(r.host, "{domain}", strings.ReplaceAll(domain, ".", "-"))
+			records = append(records, &do.DomainRecordEditRequest{Type: "MX", Name: "@", Data: host, Priority: r.priority})
+		}
+	}
+	return records, nil
+}
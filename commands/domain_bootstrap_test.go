@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrapRecords(t *testing.T) {
+	records, err := bootstrapRecords("example.com", map[string]string{"A": "198.51.100.1", "AAAA": "2001:db8::1"}, true, "microsoft")
+	require.NoError(t, err)
+	assert.Equal(t, []*do.DomainRecordEditRequest{
+		{Type: "A", Name: "@", Data: "198.51.100.1"},
+		{Type: "AAAA", Name: "@", Data: "2001:db8::1"},
+		{Type: "CNAME", Name: "www", Data: "example.com."},
+		{Type: "MX", Name: "@", Data: "example-com.mail.protection.outlook.com.", Priority: 0},
+	}, records)
+
+	records, err = bootstrapRecords("example.com", nil, false, "Google")
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+	assert.Equal(t, &do.DomainRecordEditRequest{Type: "MX", Name: "@", Data: "aspmx.l.google.com.", Priority: 1}, records[0])
+
+	records, err = bootstrapRecords("example.com", nil, false, "")
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	_, err = bootstrapRecords("example.com", nil, false, "sendmail")
+	assert.ErrorContains(t, err, "fastmail, google, microsoft, proton, zoho")
+}
+
+func TestDomainsCreateBootstrapDroplet(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.droplets.EXPECT().Get(1).Return(&testDroplet, nil)
+		tm.domains.EXPECT().Create(&godo.DomainCreateRequest{Name: "example.com"}).Return(&testDomain, nil)
+		for _, r := range []*do.DomainRecordEditRequest{
+			{Type: "A", Name: "@", Data: "8.8.8.8"},
+			{Type: "CNAME", Name: "www", Data: "example.com."},
+			{Type: "MX", Name: "@", Data: "in1-smtp.messagingengine.com.", Priority: 10},
+			{Type: "MX", Name: "@", Data: "in2-smtp.messagingengine.com.", Priority: 20},
+		} {
+			tm.domains.EXPECT().CreateRecord("example.com", r).Return(&testRecord, nil)
+		}
+
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgDomainBootstrap, "droplet:1")
+		config.Doit.Set(config.NS, doctl.ArgDomainWithWWW, true)
+		config.Doit.Set(config.NS, doctl.ArgDomainWithMX, "fastmail")
+
+		err := RunDomainCreate(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestDomainsCreateBootstrapLoadBalancer(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		lb := &do.LoadBalancer{LoadBalancer: &godo.LoadBalancer{ID: "4de7ac8b", IP: "203.0.113.10"}}
+		tm.loadBalancers.EXPECT().Get("4de7ac8b").Return(lb, nil)
+		tm.domains.EXPECT().Create(&godo.DomainCreateRequest{Name: "example.com"}).Return(&testDomain, nil)
+		tm.domains.EXPECT().CreateRecord("example.com", &do.DomainRecordEditRequest{Type: "A", Name: "@", Data: "203.0.113.10"}).Return(&testRecord, nil)
+
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgDomainBootstrap, "lb:4de7ac8b")
+
+		err := RunDomainCreate(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestDomainsCreateBootstrapInvalid(t *testing.T) {
+	for _, target := range []string{"droplet", "droplet:web", "volume:1"} {
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			config.Args = append(config.Args, "example.com")
+			config.Doit.Set(config.NS, doctl.ArgDomainBootstrap, target)
+
+			// Nothing is created.
+			err := RunDomainCreate(config)
+			assert.Error(t, err, target)
+		})
+	}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgIPAddress, "198.51.100.1")
+		config.Doit.Set(config.NS, doctl.ArgDomainBootstrap, "droplet:1")
+
+		err := RunDomainCreate(config)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/spf13/cobra"
+)
+
+// caaTags are the CAA property tags DigitalOcean DNS supports.
+var caaTags = []string{"issue", "issuewild", "iodef"}
+
+// caaTTL is the TTL of the records created by caa ensure.
+const caaTTL = 3600
+
+// caaParameterPattern matches a parameter of an issue or issuewild value.
+var caaParameterPattern = regexp.MustCompile(`^[a-zA-Z0-9]+=[\x21-\x3a\x3c-\x7e]*$`)
+
+// CAA creates the CAA record commands hierarchy.
+func CAA() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "caa",
+			Short: "Manage the certificate authorities allowed to issue certificates for a domain",
+			Long: `Use the subcommands of ` + "`" + `doctl compute domain caa` + "`" + ` to manage a domain's Certification Authority Authorization (CAA) records, which list the certificate authorities allowed to issue certificates for the domain.
+
+Once a domain has an ` + "`" + `issue` + "`" + ` record, certificate authorities that are not listed refuse to issue certificates for it. ` + "`" + `issuewild` + "`" + ` records do the same for wildcard certificates, and ` + "`" + `iodef` + "`" + ` records tell certificate authorities where to report refused requests.`,
+		},
+	}
+
+	cmdCAAEnsure := CmdBuilder(cmd, RunCAAEnsure, "ensure <domain>", "Allow certificate authorities to issue certificates for a domain", `Creates the CAA records that allow each `+"`"+`--issuer`+"`"+` to issue certificates for a domain, and with `+"`"+`--wildcard`+"`"+`, wildcard certificates. Records that already exist are left alone, and existing records for other certificate authorities are not changed or removed. Every record created is written to the DNS change journal.`, Writer,
+		displayerType(&displayers.DomainRecord{}))
+	AddStringSliceFlag(cmdCAAEnsure, doctl.ArgCAAIssuer, "", []string{}, "The domain name of a certificate authority to allow, for example `letsencrypt.org`", requiredOpt())
+	AddBoolFlag(cmdCAAEnsure, doctl.ArgCAAWildcard, "", false, "Also allow the certificate authorities to issue wildcard certificates")
+	AddStringFlag(cmdCAAEnsure, doctl.ArgCAAIodef, "", "", "A `mailto:` or `https:` URL to report refused certificate requests to")
+	AddStringFlag(cmdCAAEnsure, doctl.ArgRecordName, "", "@", "The name of the records, for example `@` or `shop`")
+	cmdCAAEnsure.Example = `The following command allows Let's Encrypt to issue certificates, including wildcard certificates, for example.com: doctl compute domain caa ensure example.com --issuer letsencrypt.org --wildcard`
+
+	return cmd
+}
+
+// validateCAARecord checks the flags, tag, and value of a CAA record, and
+// returns the tag in lower case.
+func validateCAARecord(flags int, tag, value string) (string, error) {
+	if flags < 0 || flags > 255 {
+		return "", fmt.Errorf("CAA flags must be between 0 and 255, not %d", flags)
+	}
+	tag = strings.ToLower(tag)
+	if !contains(caaTags, tag) {
+		return "", fmt.Errorf("invalid CAA tag %q; valid tags are %s", tag, strings.Join(caaTags, ", "))
+	}
+	if tag == "iodef" {
+		return tag, validateCAAIodef(value)
+	}
+	return tag, validateCAAIssuer(value)
+}
+
+// validateCAAIssuer checks the value of an issue or issuewild record: the
+// domain name of a certificate authority, followed by optional parameters.
+// A value with no domain name, such as ";", allows no certificate authority.
+func validateCAAIssuer(value string) error {
+	issuer, params, _ := strings.Cut(value, ";")
+	issuer = strings.TrimSpace(issuer)
+	if issuer != "" && !hostNamePattern.MatchString(issuer) {
+		return fmt.Errorf("invalid certificate authority %q in CAA value %q", issuer, value)
+	}
+	for _, p := range strings.Fields(strings.ReplaceAll(params, ";", " ")) {
+		if !caaParameterPattern.MatchString(p) {
+			return fmt.Errorf("invalid parameter %q in CAA value %q", p, value)
+		}
+	}
+	return nil
+}
+
+// validateCAAIodef checks the value of an iodef record, which is a mailto,
+// http, or https URL.
+func validateCAAIodef(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid CAA iodef URL %q: %w", value, err)
+	}
+	switch u.Scheme {
+	case "mailto":
+		if !strings.Contains(u.Opaque, "@") {
+			return fmt.Errorf("invalid CAA iodef URL %q: no email address", value)
+		}
+	case "http", "https":
+		if u.Host == "" {
+			return fmt.Errorf("invalid CAA iodef URL %q: no host", value)
+		}
+	default:
+		return fmt.Errorf("invalid CAA iodef URL %q: must be a mailto, http, or https URL", value)
+	}
+	return nil
+}
+
+// caaKey identifies CAA records that allow the same thing, whatever their
+// flags or the case of their values.
+func caaKey(tag, value string) string {
+	tag = strings.ToLower(tag)
+	value = strings.ToLower(strings.TrimSpace(value))
+	if tag != "iodef" {
+		issuer, params, ok := strings.Cut(value, ";")
+		value = strings.TrimSuffix(strings.TrimSpace(issuer), ".")
+		if ok {
+			value += ";" + strings.Join(strings.Fields(params), " ")
+		}
+	}
+	return tag + " " + value
+}
+
+// RunCAAEnsure creates the CAA records that allow certificate authorities to
+// issue certificates for a domain.
+func RunCAAEnsure(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	domain := c.Args[0]
+
+	issuers, err := c.Doit.GetStringSlice(c.NS, doctl.ArgCAAIssuer)
+	if err != nil {
+		return err
+	}
+	wildcard, err := c.Doit.GetBool(c.NS, doctl.ArgCAAWildcard)
+	if err != nil {
+		return err
+	}
+	iodef, err := c.Doit.GetString(c.NS, doctl.ArgCAAIodef)
+	if err != nil {
+		return err
+	}
+	name, err := c.Doit.GetString(c.NS, doctl.ArgRecordName)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = "@"
+	}
+	if len(issuers) == 0 {
+		return fmt.Errorf("at least one `--%s` is required", doctl.ArgCAAIssuer)
+	}
+
+	var want []*do.DomainRecordEditRequest
+	add := func(tag, value string) error {
+		if _, err := validateCAARecord(0, tag, value); err != nil {
+			return err
+		}
+		want = append(want, &do.DomainRecordEditRequest{Type: "CAA", Name: name, Data: value, Tag: tag, TTL: caaTTL})
+		return nil
+	}
+	for _, issuer := range issuers {
+		if err := add("issue", issuer); err != nil {
+			return err
+		}
+		if wildcard {
+			if err := add("issuewild", issuer); err != nil {
+				return err
+			}
+		}
+	}
+	if iodef != "" {
+		if err := add("iodef", iodef); err != nil {
+			return err
+		}
+	}
+
+	ds := c.Domains()
+	records, err := ds.Records(domain)
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	hasIssuewild := false
+	for _, r := range records {
+		if r.Type == "CAA" && r.Name == name {
+			existing[caaKey(r.Tag, r.Data)] = true
+			hasIssuewild = hasIssuewild || strings.EqualFold(r.Tag, "issuewild")
+		}
+	}
+
+	created := make([]do.DomainRecord, 0, len(want))
+	for _, req := range want {
+		if existing[caaKey(req.Tag, req.Data)] {
+			continue
+		}
+		r, err := ds.CreateRecord(domain, req)
+		if err != nil {
+			return err
+		}
+		recordDNSChange(domain, dnsChangeCreate, nil, r.DomainRecord)
+		created = append(created, *r)
+	}
+
+	if len(created) == 0 {
+		notice("%s already has the CAA records.", domain)
+	}
+	if !wildcard && hasIssuewild {
+		warn("%s has issuewild records, so the new issuers can't issue wildcard certificates. Use `--%s` to allow them.", domain, doctl.ArgCAAWildcard)
+	}
+
+	return displayDomainRecords(c, created...)
+}
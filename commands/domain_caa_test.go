@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCAARecord(t *testing.T) {
+	valid := []struct {
+		flags      int
+		tag, value string
+	}{
+		{0, "issue", "letsencrypt.org"},
+		{128, "ISSUE", "letsencrypt.org."},
+		{0, "issuewild", "sectigo.com; validationmethods=dns-01"},
+		{0, "issue", ";"},
+		{0, "iodef", "mailto:security@example.com"},
+		{0, "iodef", "https://example.com/caa"},
+	}
+	for _, tt := range valid {
+		tag, err := validateCAARecord(tt.flags, tt.tag, tt.value)
+		assert.NoError(t, err, tt.value)
+		assert.Equal(t, strings.ToLower(tt.tag), tag)
+	}
+
+	invalid := []struct {
+		flags      int
+		tag, value string
+	}{
+		{256, "issue", "letsencrypt.org"},
+		{0, "issuer", "letsencrypt.org"},
+		{0, "issue", "https://letsencrypt.org"},
+		{0, "issue", "-letsencrypt.org"},
+		{0, "issue", "letsencrypt.org; validationmethods"},
+		{0, "iodef", "security@example.com"},
+		{0, "iodef", "ftp://example.com"},
+		{0, "iodef", "mailto:"},
+	}
+	for _, tt := range invalid {
+		_, err := validateCAARecord(tt.flags, tt.tag, tt.value)
+		assert.Error(t, err, tt.value)
+	}
+}
+
+func TestRecordsCreateCAA(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgRecordType, "CAA")
+		config.Doit.Set(config.NS, doctl.ArgRecordName, "@")
+		config.Doit.Set(config.NS, doctl.ArgRecordData, "letsencrypt.org")
+		config.Doit.Set(config.NS, doctl.ArgRecordTag, "isue")
+
+		err := RunRecordCreate(config)
+		assert.ErrorContains(t, err, "issue, issuewild, iodef")
+	})
+}
+
+func TestRecordsUpdateCAA(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		old := &do.DomainRecord{DomainRecord: &godo.DomainRecord{ID: 1, Type: "CAA", Name: "@", Data: "letsencrypt.org", Tag: "issue"}}
+		tm.domains.EXPECT().Record("example.com", 1).Return(old, nil)
+
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgRecordID, 1)
+		config.Doit.Set(config.NS, doctl.ArgRecordTag, "iodef")
+
+		// The record's value isn't a URL, so it can't become an iodef record.
+		err := RunRecordUpdate(config)
+		assert.ErrorContains(t, err, "iodef")
+	})
+}
+
+func TestCAAEnsure(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.domains.EXPECT().Records("example.com").Return(do.DomainRecords{
+			{DomainRecord: &godo.DomainRecord{ID: 1, Type: "CAA", Name: "@", Data: "LetsEncrypt.org.", Tag: "issue"}},
+			{DomainRecord: &godo.DomainRecord{ID: 2, Type: "CAA", Name: "@", Data: "digicert.com", Tag: "issue"}},
+			{DomainRecord: &godo.DomainRecord{ID: 3, Type: "CAA", Name: "shop", Data: "sectigo.com", Tag: "issue"}},
+		}, nil)
+		for _, r := range []*do.DomainRecordEditRequest{
+			{Type: "CAA", Name: "@", Data: "letsencrypt.org", Tag: "issuewild", TTL: caaTTL},
+			{Type: "CAA", Name: "@", Data: "sectigo.com", Tag: "issue", TTL: caaTTL},
+			{Type: "CAA", Name: "@", Data: "sectigo.com", Tag: "issuewild", TTL: caaTTL},
+			{Type: "CAA", Name: "@", Data: "mailto:security@example.com", Tag: "iodef", TTL: caaTTL},
+		} {
+			tm.domains.EXPECT().CreateRecord("example.com", r).Return(&testRecord, nil)
+		}
+
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgCAAIssuer, []string{"letsencrypt.org", "sectigo.com"})
+		config.Doit.Set(config.NS, doctl.ArgCAAWildcard, true)
+		config.Doit.Set(config.NS, doctl.ArgCAAIodef, "mailto:security@example.com")
+
+		err := RunCAAEnsure(config)
+		require.NoError(t, err)
+	})
+}
+
+func TestCAAEnsureInvalidIssuer(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgCAAIssuer, []string{"https://letsencrypt.org"})
+
+		// Nothing is looked up or created.
+		err := RunCAAEnsure(config)
+		assert.Error(t, err)
+	})
+}
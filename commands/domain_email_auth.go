@@ -0,0 +1,708 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/spf13/cobra"
+)
+
+// emailAuthProvider describes how a mail provider sends mail for a domain.
+type emailAuthProvider struct {
+	// spfInclude is the domain whose SPF record lists the provider's servers.
+	spfInclude string
+	// dkimSelector is the selector the provider signs mail with by default,
+	// if it has one.
+	dkimSelector string
+}
+
+var emailAuthProviders = map[string]emailAuthProvider{
+	"gmail":   {spfInclude: "_spf.google.com", dkimSelector: "google"},
+	"ses":     {spfInclude: "amazonses.com"},
+	"mailgun": {spfInclude: "mailgun.org", dkimSelector: "smtp"},
+}
+
+// emailAuthTTL is the TTL of the records created by email-auth setup.
+const emailAuthTTL = 3600
+
+// maxSPFLookups is the number of DNS lookups an SPF record may cause before
+// receivers treat it as an error (RFC 7208, section 4.6.4).
+const maxSPFLookups = 10
+
+// quotedStringPattern matches the quoted character strings of a TXT record in
+// a zone file.
+var quotedStringPattern = regexp.MustCompile(`"([^"]*)"`)
+
+// lookupTXT returns the TXT records of a name in public DNS. It is replaced for testing.
+var lookupTXT = net.LookupTXT
+
+// EmailAuth creates the email authentication commands hierarchy.
+func EmailAuth() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "email-auth",
+			Short: "Set up and check SPF, DKIM, and DMARC records",
+			Long: `Use the subcommands of ` + "`" + `doctl compute domain email-auth` + "`" + ` to create and check the TXT records that let receivers verify mail sent for a domain:
+
+- SPF, which lists the servers allowed to send mail for the domain
+- DKIM, which publishes the public key mail is signed with
+- DMARC, which tells receivers what to do with mail that fails SPF and DKIM
+
+The supported mail providers are ` + "`" + strings.Join(emailAuthProviderNames(), "`, `") + "`" + `.`,
+		},
+	}
+
+	cmdSetup := CmdBuilder(cmd, RunEmailAuthSetup, "setup <domain>", "Create SPF, DKIM, and DMARC records for a mail provider", `Creates the SPF, DKIM, and DMARC TXT records needed to send mail for a domain through a mail provider.
+
+The SPF record includes the provider's servers. If the domain already has an SPF record, the provider is added to it. The DKIM record publishes the public key in the `+"`"+`--dkim-key`+"`"+` file, which can be a PEM public key, the base64 key alone, or the complete `+"`"+`v=DKIM1`+"`"+` record your provider gives you. The record is created under the provider's default selector unless `+"`"+`--dkim-selector`+"`"+` is set. Without `+"`"+`--dkim-key`+"`"+`, no DKIM record is created. The DMARC record starts with the `+"`"+`none`+"`"+` policy, which only monitors mail, unless `+"`"+`--dmarc-policy`+"`"+` is set.
+
+Records that already have the wanted values are left alone. If the domain has a record that conflicts with one to be created, nothing is changed unless `+"`"+`--replace`+"`"+` is set, in which case the conflicting record is updated. The changes are recorded in the history of `+"`"+`doctl compute domain records history`+"`"+`.`, Writer,
+		displayerType(&displayers.DomainRecord{}))
+	AddStringFlag(cmdSetup, doctl.ArgEmailAuthProvider, "", "", "The mail provider: "+strings.Join(emailAuthProviderNames(), ", "), requiredOpt())
+	AddStringFlag(cmdSetup, doctl.ArgEmailAuthDKIMKey, "", "", "The path of the file with the DKIM public key")
+	AddStringFlag(cmdSetup, doctl.ArgEmailAuthDKIMSelector, "", "", "The DKIM selector. Defaults to the provider's selector.")
+	AddStringFlag(cmdSetup, doctl.ArgEmailAuthDMARCPolicy, "", "none", "The DMARC policy for mail that fails authentication: none, quarantine, or reject")
+	AddStringFlag(cmdSetup, doctl.ArgEmailAuthDMARCReports, "", "", "The email address to send DMARC aggregate reports to")
+	AddBoolFlag(cmdSetup, doctl.ArgEmailAuthReplace, "", false, "Update records that conflict with the ones to be created")
+	cmdSetup.Example = `The following command creates the SPF, DKIM, and DMARC records for sending mail for example.com through Google Workspace: doctl compute domain email-auth setup example.com --provider gmail --dkim-key google-dkim.txt`
+
+	cmdCheck := CmdBuilder(cmd, RunEmailAuthCheck, "check <domain>", "Check a domain's SPF, DKIM, and DMARC records", `Looks up a domain's SPF, DKIM, and DMARC records in public DNS and evaluates them.
+
+The SPF check fails if there is not exactly one SPF record, if it allows any server to send mail, if it causes more than `+fmt.Sprint(maxSPFLookups)+` DNS lookups, or if it doesn't include the servers of the `+"`"+`--provider`+"`"+`. The DKIM check fails if the key of the selector is missing, revoked, or too short to be trusted. It is skipped if neither `+"`"+`--dkim-selector`+"`"+` nor a provider with a default selector is given. The DMARC check fails if there is not exactly one valid DMARC record, and warns if its policy only monitors mail.
+
+The command exits with a non-zero status if any check fails. Changes to DNS records can take up to the records' TTL to be visible in public DNS.`, Writer,
+		displayerType(&displayers.EmailAuthChecks{}))
+	AddStringFlag(cmdCheck, doctl.ArgEmailAuthProvider, "", "", "The mail provider the SPF record must include: "+strings.Join(emailAuthProviderNames(), ", "))
+	AddStringFlag(cmdCheck, doctl.ArgEmailAuthDKIMSelector, "", "", "The DKIM selector to check. Defaults to the provider's selector.")
+	cmdCheck.Example = `The following command checks the email authentication records of example.com: doctl compute domain email-auth check example.com --provider gmail`
+
+	return cmd
+}
+
+func emailAuthProviderNames() []string {
+	names := make([]string, 0, len(emailAuthProviders))
+	for name := range emailAuthProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func getEmailAuthProvider(name string) (emailAuthProvider, error) {
+	p, ok := emailAuthProviders[strings.ToLower(name)]
+	if !ok {
+		return emailAuthProvider{}, fmt.Errorf("unknown mail provider %q; valid providers are %s", name, strings.Join(emailAuthProviderNames(), ", "))
+	}
+	return p, nil
+}
+
+// emailAuthRecord is a TXT record created by email-auth setup.
+type emailAuthRecord struct {
+	kind string
+	name string
+	data string
+}
+
+// emailAuthChange is a change email-auth setup makes to a domain: the creation
+// of a record, or the update of old.
+type emailAuthChange struct {
+	old *do.DomainRecord
+	req *do.DomainRecordEditRequest
+}
+
+// RunEmailAuthSetup creates the SPF, DKIM, and DMARC records of a domain.
+func RunEmailAuthSetup(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	domain := strings.ToLower(strings.TrimSuffix(c.Args[0], "."))
+
+	providerName, err := c.Doit.GetString(c.NS, doctl.ArgEmailAuthProvider)
+	if err != nil {
+		return err
+	}
+	provider, err := getEmailAuthProvider(providerName)
+	if err != nil {
+		return err
+	}
+	keyPath, err := c.Doit.GetString(c.NS, doctl.ArgEmailAuthDKIMKey)
+	if err != nil {
+		return err
+	}
+	selector, err := c.Doit.GetString(c.NS, doctl.ArgEmailAuthDKIMSelector)
+	if err != nil {
+		return err
+	}
+	policy, err := c.Doit.GetString(c.NS, doctl.ArgEmailAuthDMARCPolicy)
+	if err != nil {
+		return err
+	}
+	reports, err := c.Doit.GetString(c.NS, doctl.ArgEmailAuthDMARCReports)
+	if err != nil {
+		return err
+	}
+	replace, err := c.Doit.GetBool(c.NS, doctl.ArgEmailAuthReplace)
+	if err != nil {
+		return err
+	}
+
+	dmarc, err := dmarcRecordData(policy, reports)
+	if err != nil {
+		return err
+	}
+	want := []emailAuthRecord{{kind: "SPF", name: "@", data: "v=spf1 include:" + provider.spfInclude + " ~all"}}
+	if keyPath != "" {
+		if selector == "" {
+			selector = provider.dkimSelector
+		}
+		if selector == "" {
+			return fmt.Errorf("%s has no default DKIM selector; use `--%s` to set one", strings.ToLower(providerName), doctl.ArgEmailAuthDKIMSelector)
+		}
+		key, err := os.ReadFile(keyPath)
+		if err != nil {
+			return err
+		}
+		dkim, err := dkimRecordData(key)
+		if err != nil {
+			return fmt.Errorf("%s: %w", keyPath, err)
+		}
+		want = append(want, emailAuthRecord{kind: "DKIM", name: selector + "._domainkey", data: dkim})
+	} else {
+		warn("No DKIM record is created without `--%s`. Mail from %s may not pass DMARC until one is added.", doctl.ArgEmailAuthDKIMKey, domain)
+	}
+	want = append(want, emailAuthRecord{kind: "DMARC", name: "_dmarc", data: dmarc})
+
+	ds := c.Domains()
+	records, err := ds.Records(domain)
+	if err != nil {
+		return err
+	}
+
+	// Every record is checked for conflicts before any is changed.
+	var changes []emailAuthChange
+	var conflicts []string
+	for _, w := range want {
+		change, err := planEmailAuthRecord(records, w, replace)
+		if err != nil {
+			conflicts = append(conflicts, err.Error())
+			continue
+		}
+		if change == nil {
+			notice("The %s record of %s is already set up.", w.kind, domain)
+			continue
+		}
+		changes = append(changes, *change)
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%s has conflicting records:\n  %s", domain, strings.Join(conflicts, "\n  "))
+	}
+
+	made := make([]do.DomainRecord, 0, len(changes))
+	for _, change := range changes {
+		if change.old != nil {
+			r, err := ds.EditRecord(domain, change.old.ID, change.req)
+			if err != nil {
+				return err
+			}
+			recordDNSChange(domain, dnsChangeUpdate, change.old.DomainRecord, r.DomainRecord)
+			made = append(made, *r)
+			continue
+		}
+		r, err := ds.CreateRecord(domain, change.req)
+		if err != nil {
+			return err
+		}
+		recordDNSChange(domain, dnsChangeCreate, nil, r.DomainRecord)
+		made = append(made, *r)
+	}
+
+	return displayDomainRecords(c, made...)
+}
+
+// planEmailAuthRecord returns the change that gives a domain with records the
+// record w, or nil if it already has it. It returns an error if the domain
+// has a record that conflicts with w and replace is false.
+func planEmailAuthRecord(records do.DomainRecords, w emailAuthRecord, replace bool) (*emailAuthChange, error) {
+	var existing []do.DomainRecord
+	for _, r := range records {
+		if r.Type == "TXT" && r.Name == w.name && isEmailAuthRecord(w.kind, txtData(r.Data)) {
+			existing = append(existing, r)
+		}
+	}
+
+	req := &do.DomainRecordEditRequest{Type: "TXT", Name: w.name, Data: w.data, TTL: emailAuthTTL}
+	switch len(existing) {
+	case 0:
+		return &emailAuthChange{req: req}, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("%s has %d %s records; remove all but one of them first", w.name, len(existing), w.kind)
+	}
+
+	old := existing[0]
+	data := txtData(old.Data)
+	hint := fmt.Sprintf("use `--%s` to replace it", doctl.ArgEmailAuthReplace)
+	if w.kind == "SPF" {
+		// The provider is added to an existing SPF record, since a domain
+		// can only have one.
+		include := strings.Fields(w.data)[1]
+		merged := spfWithInclude(data, include)
+		if merged == data {
+			return nil, nil
+		}
+		req.Data = merged
+		hint = fmt.Sprintf("use `--%s` to add %s to it", doctl.ArgEmailAuthReplace, include)
+	} else if data == w.data {
+		return nil, nil
+	}
+	if !replace {
+		return nil, fmt.Errorf("%s has the %s record %q; %s", w.name, w.kind, data, hint)
+	}
+	req.TTL = old.TTL
+	return &emailAuthChange{old: &old, req: req}, nil
+}
+
+// isEmailAuthRecord reports whether TXT record data is a record of kind.
+// Every TXT record under a DKIM selector is taken to be a DKIM record.
+func isEmailAuthRecord(kind, data string) bool {
+	switch kind {
+	case "SPF":
+		return isSPF(data)
+	case "DMARC":
+		return isDMARC(data)
+	}
+	return true
+}
+
+// txtData returns the data of a TXT record without the quotes around it.
+func txtData(data string) string {
+	data = strings.TrimSpace(data)
+	if len(data) >= 2 && strings.HasPrefix(data, `"`) && strings.HasSuffix(data, `"`) {
+		// Character strings split by a zone file ("..." "...") are joined.
+		return strings.ReplaceAll(data[1:len(data)-1], `" "`, "")
+	}
+	return data
+}
+
+func isSPF(data string) bool {
+	return strings.EqualFold(data, "v=spf1") || strings.HasPrefix(strings.ToLower(data), "v=spf1 ")
+}
+
+func isDMARC(data string) bool {
+	tags := dkimTags(data)
+	return len(tags) > 0 && strings.EqualFold(tags[0][0], "v") && strings.EqualFold(tags[0][1], "DMARC1")
+}
+
+// spfWithInclude returns an SPF record that includes include, adding it
+// before the record's all mechanism if the record doesn't include it yet.
+func spfWithInclude(spf, include string) string {
+	terms := strings.Fields(spf)
+	for _, t := range terms {
+		if strings.EqualFold(strings.TrimLeft(t, "+"), include) {
+			return spf
+		}
+	}
+	for i, t := range terms {
+		if strings.EqualFold(strings.TrimLeft(t, "+-~?"), "all") || strings.HasPrefix(strings.ToLower(t), "redirect=") {
+			terms = append(terms[:i], append([]string{include}, terms[i:]...)...)
+			return strings.Join(terms, " ")
+		}
+	}
+	return strings.Join(append(terms, include), " ")
+}
+
+// dmarcRecordData returns the data of a DMARC record with policy, which sends
+// aggregate reports to reports if it isn't empty.
+func dmarcRecordData(policy, reports string) (string, error) {
+	switch policy {
+	case "none", "quarantine", "reject":
+	default:
+		return "", fmt.Errorf("invalid DMARC policy %q; valid policies are none, quarantine, and reject", policy)
+	}
+	data := "v=DMARC1; p=" + policy
+	if reports != "" {
+		if !strings.Contains(reports, "@") {
+			return "", fmt.Errorf("invalid DMARC report address %q", reports)
+		}
+		data += "; rua=mailto:" + strings.TrimPrefix(reports, "mailto:")
+	}
+	return data, nil
+}
+
+// dkimRecordData returns the data of the DKIM record that publishes key,
+// which is a PEM public key, a base64 public key, or a DKIM record.
+func dkimRecordData(key []byte) (string, error) {
+	text := strings.TrimSpace(string(key))
+	if block, _ := pem.Decode([]byte(text)); block != nil {
+		return dkimRecordFromPEM(block)
+	}
+
+	if strings.Contains(text, `"`) {
+		// A record as the quoted character strings of a zone file.
+		var parts []string
+		for _, m := range quotedStringPattern.FindAllStringSubmatch(text, -1) {
+			parts = append(parts, m[1])
+		}
+		text = strings.Join(parts, "")
+	}
+	if strings.Contains(text, ";") || strings.HasPrefix(strings.ToLower(text), "v=") {
+		data := strings.Join(strings.Fields(text), " ")
+		if _, _, err := parseDKIMRecord(data); err != nil {
+			return "", err
+		}
+		return data, nil
+	}
+
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+	if err != nil {
+		return "", errors.New("not a PEM or base64 public key, or a DKIM record")
+	}
+	return dkimRecordFromDER(der)
+}
+
+func dkimRecordFromPEM(block *pem.Block) (string, error) {
+	switch block.Type {
+	case "PUBLIC KEY":
+		return dkimRecordFromDER(block.Bytes)
+	case "RSA PUBLIC KEY":
+		pub, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return "", err
+		}
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			return "", err
+		}
+		return dkimRecordFromDER(der)
+	}
+	if strings.Contains(block.Type, "PRIVATE KEY") {
+		return "", errors.New("is a private key; give the public key instead")
+	}
+	return "", fmt.Errorf("unsupported PEM block %q", block.Type)
+}
+
+func dkimRecordFromDER(der []byte) (string, error) {
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return "", err
+	}
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return "v=DKIM1; k=rsa; p=" + base64.StdEncoding.EncodeToString(der), nil
+	case ed25519.PublicKey:
+		// Ed25519 keys are published without the PKIX wrapping (RFC 8463).
+		return "v=DKIM1; k=ed25519; p=" + base64.StdEncoding.EncodeToString(pub), nil
+	}
+	return "", fmt.Errorf("unsupported DKIM key type %T", pub)
+}
+
+// dkimTags splits the tag=value list of a DKIM or DMARC record.
+func dkimTags(data string) [][2]string {
+	var tags [][2]string
+	for _, part := range strings.Split(data, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		tags = append(tags, [2]string{strings.TrimSpace(name), strings.Join(strings.Fields(value), "")})
+	}
+	return tags
+}
+
+// parseDKIMRecord returns the key type and the size in bits of the key of a
+// DKIM record. The size of Ed25519 keys is 256.
+func parseDKIMRecord(data string) (string, int, error) {
+	keyType, key := "rsa", ""
+	hasKey := false
+	for _, tag := range dkimTags(data) {
+		switch strings.ToLower(tag[0]) {
+		case "v":
+			if tag[1] != "DKIM1" {
+				return "", 0, fmt.Errorf("unsupported DKIM version %q", tag[1])
+			}
+		case "k":
+			keyType = strings.ToLower(tag[1])
+		case "p":
+			key, hasKey = tag[1], true
+		}
+	}
+	if !hasKey {
+		return "", 0, errors.New("DKIM record has no public key")
+	}
+	if key == "" {
+		return "", 0, errors.New("DKIM key is revoked")
+	}
+	der, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return "", 0, fmt.Errorf("DKIM key is not valid base64: %w", err)
+	}
+
+	switch keyType {
+	case "rsa":
+		pub, err := x509.ParsePKIXPublicKey(der)
+		if err != nil {
+			// Some signers publish PKCS #1 keys.
+			rsaPub, pkcs1Err := x509.ParsePKCS1PublicKey(der)
+			if pkcs1Err != nil {
+				return "", 0, fmt.Errorf("DKIM key is not a valid RSA key: %w", err)
+			}
+			pub = rsaPub
+		}
+		rsaPub, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return "", 0, fmt.Errorf("DKIM key is a %T, not an RSA key", pub)
+		}
+		return keyType, rsaPub.N.BitLen(), nil
+	case "ed25519":
+		if len(der) != ed25519.PublicKeySize {
+			return "", 0, errors.New("DKIM key is not a valid Ed25519 key")
+		}
+		return keyType, 256, nil
+	}
+	return "", 0, fmt.Errorf("unsupported DKIM key type %q", keyType)
+}
+
+// RunEmailAuthCheck evaluates the SPF, DKIM, and DMARC records of a domain in
+// public DNS.
+func RunEmailAuthCheck(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	domain := strings.ToLower(strings.TrimSuffix(c.Args[0], "."))
+
+	providerName, err := c.Doit.GetString(c.NS, doctl.ArgEmailAuthProvider)
+	if err != nil {
+		return err
+	}
+	selector, err := c.Doit.GetString(c.NS, doctl.ArgEmailAuthDKIMSelector)
+	if err != nil {
+		return err
+	}
+	var provider emailAuthProvider
+	if providerName != "" {
+		provider, err = getEmailAuthProvider(providerName)
+		if err != nil {
+			return err
+		}
+	}
+	if selector == "" {
+		selector = provider.dkimSelector
+	}
+
+	checks := []displayers.EmailAuthCheck{
+		checkSPF(domain, provider.spfInclude),
+		checkDKIM(domain, selector),
+		checkDMARC(domain),
+	}
+	if err := c.Display(&displayers.EmailAuthChecks{Checks: checks}); err != nil {
+		return err
+	}
+
+	for _, check := range checks {
+		if check.Status == displayers.EmailAuthFail {
+			return ErrExitSilently
+		}
+	}
+	return nil
+}
+
+// lookupEmailAuthRecords returns the TXT records of name that match. A name
+// that doesn't exist has no records.
+func lookupEmailAuthRecords(name string, match func(string) bool) ([]string, error) {
+	txts, err := lookupTXT(name)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var matched []string
+	for _, txt := range txts {
+		if match(txt) {
+			matched = append(matched, txt)
+		}
+	}
+	return matched, nil
+}
+
+func checkSPF(domain, include string) displayers.EmailAuthCheck {
+	check := displayers.EmailAuthCheck{Record: "SPF", Name: domain, Status: displayers.EmailAuthFail}
+	records, err := lookupEmailAuthRecords(domain, isSPF)
+	switch {
+	case err != nil:
+		check.Detail = err.Error()
+		return check
+	case len(records) == 0:
+		check.Detail = "no SPF record"
+		return check
+	case len(records) > 1:
+		check.Detail = fmt.Sprintf("%d SPF records; receivers treat more than one as an error", len(records))
+		return check
+	}
+
+	check.Value = records[0]
+	var problems, warnings []string
+	lookups, included, final := 0, include == "", false
+	for _, term := range strings.Fields(records[0])[1:] {
+		mechanism := strings.ToLower(strings.TrimLeft(term, "+-~?"))
+		name, _, _ := strings.Cut(mechanism, ":")
+		name, _, _ = strings.Cut(name, "/")
+		if strings.HasPrefix(mechanism, "redirect=") {
+			lookups++
+			final = true
+		}
+		if name == "include" || name == "a" || name == "mx" || name == "ptr" || name == "exists" {
+			lookups++
+		}
+		if include != "" && mechanism == "include:"+strings.ToLower(include) {
+			included = true
+		}
+		if mechanism == "all" {
+			final = true
+			switch term[0] {
+			case '-', '~':
+			case '?':
+				warnings = append(warnings, "?all leaves unlisted senders neutral")
+			default:
+				problems = append(problems, "+all lets any server send mail")
+			}
+		}
+	}
+	if !included {
+		problems = append(problems, "does not include "+include)
+	}
+	if lookups > maxSPFLookups {
+		problems = append(problems, fmt.Sprintf("at least %d DNS lookups, more than the limit of %d", lookups, maxSPFLookups))
+	}
+	if !final {
+		warnings = append(warnings, "no all mechanism; unlisted senders are neutral")
+	}
+	return emailAuthResult(check, problems, warnings, "one SPF record")
+}
+
+func checkDKIM(domain, selector string) displayers.EmailAuthCheck {
+	if selector == "" {
+		return displayers.EmailAuthCheck{
+			Record: "DKIM", Name: "*._domainkey." + domain, Status: displayers.EmailAuthSkipped,
+			Detail: fmt.Sprintf("no selector; use --%s or --%s", doctl.ArgEmailAuthDKIMSelector, doctl.ArgEmailAuthProvider),
+		}
+	}
+
+	name := selector + "._domainkey." + domain
+	check := displayers.EmailAuthCheck{Record: "DKIM", Name: name, Status: displayers.EmailAuthFail}
+	records, err := lookupEmailAuthRecords(name, func(string) bool { return true })
+	switch {
+	case err != nil:
+		check.Detail = err.Error()
+		return check
+	case len(records) == 0:
+		check.Detail = "no DKIM record for selector " + selector
+		return check
+	case len(records) > 1:
+		check.Detail = fmt.Sprintf("%d TXT records; a selector must have exactly one", len(records))
+		return check
+	}
+
+	check.Value = records[0]
+	keyType, bits, err := parseDKIMRecord(records[0])
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	var problems, warnings []string
+	switch {
+	case keyType == "rsa" && bits < 1024:
+		problems = append(problems, fmt.Sprintf("%d-bit RSA key is too short; receivers ignore it", bits))
+	case keyType == "rsa" && bits < 2048:
+		warnings = append(warnings, fmt.Sprintf("%d-bit RSA key; 2048 bits is recommended", bits))
+	}
+	return emailAuthResult(check, problems, warnings, fmt.Sprintf("%d-bit %s key", bits, strings.ToUpper(keyType)))
+}
+
+func checkDMARC(domain string) displayers.EmailAuthCheck {
+	name := "_dmarc." + domain
+	check := displayers.EmailAuthCheck{Record: "DMARC", Name: name, Status: displayers.EmailAuthFail}
+	records, err := lookupEmailAuthRecords(name, isDMARC)
+	switch {
+	case err != nil:
+		check.Detail = err.Error()
+		return check
+	case len(records) == 0:
+		check.Detail = "no DMARC record"
+		return check
+	case len(records) > 1:
+		check.Detail = fmt.Sprintf("%d DMARC records; receivers ignore all of them", len(records))
+		return check
+	}
+
+	check.Value = records[0]
+	policy, reports := "", false
+	for _, tag := range dkimTags(records[0]) {
+		switch strings.ToLower(tag[0]) {
+		case "p":
+			policy = strings.ToLower(tag[1])
+		case "rua":
+			reports = true
+		}
+	}
+	var problems, warnings []string
+	switch policy {
+	case "quarantine", "reject":
+	case "none":
+		warnings = append(warnings, "policy none only monitors mail")
+	case "":
+		problems = append(problems, "no policy")
+	default:
+		problems = append(problems, fmt.Sprintf("invalid policy %q", policy))
+	}
+	if !reports {
+		warnings = append(warnings, "no rua address for aggregate reports")
+	}
+	return emailAuthResult(check, problems, warnings, "policy "+policy)
+}
+
+// emailAuthResult sets the status and detail of check from the problems and
+// warnings found, or to ok if there are none.
+func emailAuthResult(check displayers.EmailAuthCheck, problems, warnings []string, ok string) displayers.EmailAuthCheck {
+	switch {
+	case len(problems) > 0:
+		check.Status = displayers.EmailAuthFail
+		check.Detail = strings.Join(append(problems, warnings...), "; ")
+	case len(warnings) > 0:
+		check.Status = displayers.EmailAuthWarn
+		check.Detail = strings.Join(warnings, "; ")
+	default:
+		check.Status = displayers.EmailAuthPass
+		check.Detail = ok
+	}
+	return check
+}
@@ -0,0 +1,257 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDKIMKey(t *testing.T, bits int) (der []byte, b64 string) {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+	der, err = x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	return der, base64.StdEncoding.EncodeToString(der)
+}
+
+func withTXTRecords(t *testing.T, records map[string][]string) {
+	orig := lookupTXT
+	t.Cleanup(func() { lookupTXT = orig })
+	lookupTXT = func(name string) ([]string, error) {
+		txts, ok := records[name]
+		if !ok {
+			return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+		}
+		return txts, nil
+	}
+}
+
+func TestDKIMRecordData(t *testing.T) {
+	der, b64 := testDKIMKey(t, 1024)
+	want := "v=DKIM1; k=rsa; p=" + b64
+
+	data, err := dkimRecordData(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	require.NoError(t, err)
+	assert.Equal(t, want, data)
+
+	data, err = dkimRecordData([]byte(b64[:40] + "\n" + b64[40:] + "\n"))
+	require.NoError(t, err)
+	assert.Equal(t, want, data)
+
+	zone := "google._domainkey IN TXT ( \"v=DKIM1; k=rsa; \"\n  \"p=" + b64[:100] + "\" \"" + b64[100:] + "\" )"
+	data, err = dkimRecordData([]byte(zone))
+	require.NoError(t, err)
+	assert.Equal(t, want, data)
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edDER, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	data, err = dkimRecordData(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: edDER}))
+	require.NoError(t, err)
+	assert.Equal(t, "v=DKIM1; k=ed25519; p="+base64.StdEncoding.EncodeToString(pub), data)
+
+	_, err = dkimRecordData(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("secret")}))
+	assert.ErrorContains(t, err, "private key")
+
+	_, err = dkimRecordData([]byte("v=DKIM1; k=rsa; p="))
+	assert.ErrorContains(t, err, "revoked")
+
+	_, err = dkimRecordData([]byte("not a key"))
+	assert.Error(t, err)
+}
+
+func TestSPFWithInclude(t *testing.T) {
+	tests := []struct {
+		spf, expected string
+	}{
+		{"v=spf1 ~all", "v=spf1 include:_spf.google.com ~all"},
+		{"v=spf1 ip4:192.0.2.1 -all", "v=spf1 ip4:192.0.2.1 include:_spf.google.com -all"},
+		{"v=spf1 mx redirect=_spf.example.com", "v=spf1 mx include:_spf.google.com redirect=_spf.example.com"},
+		{"v=spf1 a", "v=spf1 a include:_spf.google.com"},
+		{"v=spf1 +include:_spf.google.com ~all", "v=spf1 +include:_spf.google.com ~all"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, spfWithInclude(tt.spf, "include:_spf.google.com"), tt.spf)
+	}
+}
+
+func TestEmailAuthSetup(t *testing.T) {
+	_, b64 := testDKIMKey(t, 1024)
+	keyPath := filepath.Join(t.TempDir(), "dkim.txt")
+	require.NoError(t, os.WriteFile(keyPath, []byte(b64), 0600))
+
+	spf := do.DomainRecord{DomainRecord: &godo.DomainRecord{ID: 7, Type: "TXT", Name: "@", Data: "v=spf1 include:mailgun.org ~all", TTL: 1800}}
+	records := do.DomainRecords{
+		spf,
+		{DomainRecord: &godo.DomainRecord{ID: 8, Type: "TXT", Name: "@", Data: "google-site-verification=abc"}},
+	}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.domains.EXPECT().Records("example.com").Return(records, nil)
+
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgEmailAuthProvider, "gmail")
+		config.Doit.Set(config.NS, doctl.ArgEmailAuthDKIMKey, keyPath)
+		config.Doit.Set(config.NS, doctl.ArgEmailAuthDMARCPolicy, "none")
+
+		// The existing SPF record conflicts, so nothing is changed.
+		err := RunEmailAuthSetup(config)
+		assert.ErrorContains(t, err, "--replace")
+	})
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.domains.EXPECT().Records("example.com").Return(records, nil)
+		tm.domains.EXPECT().Record("example.com", 7).Return(&spf, nil).AnyTimes()
+		tm.domains.EXPECT().EditRecord("example.com", 7, &do.DomainRecordEditRequest{
+			Type: "TXT", Name: "@", Data: "v=spf1 include:mailgun.org include:_spf.google.com ~all", TTL: 1800,
+		}).Return(&testRecord, nil)
+		tm.domains.EXPECT().CreateRecord("example.com", &do.DomainRecordEditRequest{
+			Type: "TXT", Name: "google._domainkey", Data: "v=DKIM1; k=rsa; p=" + b64, TTL: emailAuthTTL,
+		}).Return(&testRecord, nil)
+		tm.domains.EXPECT().CreateRecord("example.com", &do.DomainRecordEditRequest{
+			Type: "TXT", Name: "_dmarc", Data: "v=DMARC1; p=quarantine; rua=mailto:dmarc@example.com", TTL: emailAuthTTL,
+		}).Return(&testRecord, nil)
+
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgEmailAuthProvider, "gmail")
+		config.Doit.Set(config.NS, doctl.ArgEmailAuthDKIMKey, keyPath)
+		config.Doit.Set(config.NS, doctl.ArgEmailAuthDMARCPolicy, "quarantine")
+		config.Doit.Set(config.NS, doctl.ArgEmailAuthDMARCReports, "dmarc@example.com")
+		config.Doit.Set(config.NS, doctl.ArgEmailAuthReplace, true)
+
+		err := RunEmailAuthSetup(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestEmailAuthSetupAlreadyDone(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.domains.EXPECT().Records("example.com").Return(do.DomainRecords{
+			{DomainRecord: &godo.DomainRecord{ID: 7, Type: "TXT", Name: "@", Data: "v=spf1 include:mailgun.org -all"}},
+			{DomainRecord: &godo.DomainRecord{ID: 9, Type: "TXT", Name: "_dmarc", Data: `"v=DMARC1; p=none"`}},
+		}, nil)
+
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgEmailAuthProvider, "mailgun")
+		config.Doit.Set(config.NS, doctl.ArgEmailAuthDMARCPolicy, "none")
+
+		err := RunEmailAuthSetup(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestEmailAuthSetupInvalid(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgEmailAuthProvider, "sendmail")
+
+		err := RunEmailAuthSetup(config)
+		assert.ErrorContains(t, err, "gmail, mailgun, ses")
+	})
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgEmailAuthProvider, "ses")
+		config.Doit.Set(config.NS, doctl.ArgEmailAuthDKIMKey, "dkim.pem")
+		config.Doit.Set(config.NS, doctl.ArgEmailAuthDMARCPolicy, "none")
+
+		err := RunEmailAuthSetup(config)
+		assert.ErrorContains(t, err, "--dkim-selector")
+	})
+}
+
+func TestEmailAuthCheck(t *testing.T) {
+	_, b64 := testDKIMKey(t, 2048)
+	prev := viper.GetString(doctl.ArgOutput)
+	viper.Set(doctl.ArgOutput, "json")
+	t.Cleanup(func() { viper.Set(doctl.ArgOutput, prev) })
+
+	t.Run("pass", func(t *testing.T) {
+		withTXTRecords(t, map[string][]string{
+			"example.com":                   {"google-site-verification=abc", "v=spf1 include:_spf.google.com -all"},
+			"google._domainkey.example.com": {"v=DKIM1; k=rsa; p=" + b64},
+			"_dmarc.example.com":            {"v=DMARC1; p=reject; rua=mailto:dmarc@example.com"},
+		})
+
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			var buf bytes.Buffer
+			config.Out = &buf
+			config.Args = append(config.Args, "example.com")
+			config.Doit.Set(config.NS, doctl.ArgEmailAuthProvider, "gmail")
+
+			err := RunEmailAuthCheck(config)
+			require.NoError(t, err)
+
+			var checks []displayers.EmailAuthCheck
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &checks))
+			require.Len(t, checks, 3)
+			for _, c := range checks {
+				assert.Equal(t, displayers.EmailAuthPass, c.Status, c.Record)
+			}
+			assert.Equal(t, "2048-bit RSA key", checks[1].Detail)
+		})
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		withTXTRecords(t, map[string][]string{
+			"example.com":        {"v=spf1 +all"},
+			"_dmarc.example.com": {"v=DMARC1; p=none"},
+		})
+
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			var buf bytes.Buffer
+			config.Out = &buf
+			config.Args = append(config.Args, "example.com")
+			config.Doit.Set(config.NS, doctl.ArgEmailAuthProvider, "gmail")
+
+			err := RunEmailAuthCheck(config)
+			assert.Equal(t, ErrExitSilently, err)
+
+			var checks []displayers.EmailAuthCheck
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &checks))
+			require.Len(t, checks, 3)
+			assert.Equal(t, displayers.EmailAuthFail, checks[0].Status)
+			assert.Contains(t, checks[0].Detail, "+all")
+			assert.Contains(t, checks[0].Detail, "_spf.google.com")
+			assert.Equal(t, displayers.EmailAuthFail, checks[1].Status)
+			assert.Equal(t, displayers.EmailAuthWarn, checks[2].Status)
+		})
+	})
+}
+
+func TestCheckSPFLookups(t *testing.T) {
+	withTXTRecords(t, map[string][]string{
+		"example.com": {"v=spf1 include:a.example include:b.example include:c.example a mx ptr exists:d.example include:e.example include:f.example include:g.example include:h.example ~all", "v=spf1 -all"},
+		"example.org": {"v=spf1 include:a.example include:b.example include:c.example a mx ptr exists:d.example include:e.example include:f.example include:g.example include:h.example ~all"},
+	})
+
+	check := checkSPF("example.com", "")
+	assert.Equal(t, displayers.EmailAuthFail, check.Status)
+	assert.Contains(t, check.Detail, "2 SPF records")
+
+	check = checkSPF("example.org", "")
+	assert.Equal(t, displayers.EmailAuthFail, check.Status)
+	assert.Contains(t, check.Detail, "11 DNS lookups")
+
+	check = checkDKIM("example.org", "")
+	assert.Equal(t, displayers.EmailAuthSkipped, check.Status)
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/doctl/pkg/axfr"
+	"github.com/digitalocean/godo"
+)
+
+// The actions of a domain import.
+const (
+	importCreate  = "create"
+	importCreated = "created"
+	importExists  = "exists"
+	importSkip    = "skip"
+	importFailed  = "failed"
+)
+
+// axfrTransfer transfers a zone from its nameserver. It is replaced for testing.
+var axfrTransfer = axfr.Transfer
+
+// importRecord is a record of a transferred zone, with the request that
+// creates it unless it is skipped.
+type importRecord struct {
+	displayers.DomainImportRecord
+	req *do.DomainRecordEditRequest
+}
+
+// RunDomainImport transfers a domain's zone from its current nameserver and
+// creates its records in DigitalOcean DNS.
+func RunDomainImport(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	domain := strings.ToLower(strings.TrimSuffix(c.Args[0], "."))
+
+	server, err := c.Doit.GetString(c.NS, doctl.ArgDomainImportAXFR)
+	if err != nil {
+		return err
+	}
+	tsigKey, err := c.Doit.GetString(c.NS, doctl.ArgDomainImportTSIGKey)
+	if err != nil {
+		return err
+	}
+	dryRun, err := c.Doit.GetBool(c.NS, doctl.ArgDryRun)
+	if err != nil {
+		return err
+	}
+
+	var opts axfr.Options
+	if tsigKey != "" {
+		if opts.Key, err = axfr.ParseTSIGKey(tsigKey); err != nil {
+			return err
+		}
+	}
+	zone, err := axfrTransfer(context.Background(), server, domain, opts)
+	if err != nil {
+		return fmt.Errorf("transferring %s from %s: %w", domain, server, err)
+	}
+
+	ds := c.Domains()
+	exists := true
+	_, err = ds.Get(domain)
+	var errResp *godo.ErrorResponse
+	switch {
+	case errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound:
+		exists = false
+	case err != nil:
+		return err
+	}
+
+	// Records the domain already has, or that the zone has twice, such as
+	// the SPF and TXT records of the same policy, are created once.
+	seen := map[string]bool{}
+	if exists {
+		existing, err := ds.Records(domain)
+		if err != nil {
+			return err
+		}
+		for _, r := range existing {
+			seen[importKey(domain, r.DomainRecord)] = true
+		}
+	}
+
+	records := make([]importRecord, 0, len(zone))
+	skipped := 0
+	for _, rr := range zone {
+		r := convertZoneRecord(domain, rr)
+		if r.req != nil {
+			key := importKey(domain, &godo.DomainRecord{
+				Type: r.req.Type, Name: r.req.Name, Data: r.req.Data, Priority: r.req.Priority,
+				Port: *r.req.Port, Weight: r.req.Weight, Tag: r.req.Tag,
+			})
+			if seen[key] {
+				r.Action = importExists
+				r.req = nil
+			}
+			seen[key] = true
+		}
+		if r.Action == importSkip {
+			skipped++
+		}
+		records = append(records, r)
+	}
+
+	display := func() error {
+		out := make([]displayers.DomainImportRecord, len(records))
+		for i, r := range records {
+			out[i] = r.DomainImportRecord
+		}
+		return c.Display(&displayers.DomainImport{Records: out})
+	}
+	if dryRun {
+		return display()
+	}
+
+	for _, r := range records {
+		if r.Action == importSkip {
+			warn("Skipping %s record %s: %s", r.Type, r.Name, r.Detail)
+		}
+	}
+	if !exists {
+		if _, err := ds.Create(&godo.DomainCreateRequest{Name: domain}); err != nil {
+			return err
+		}
+		notice("Created the domain %s.", domain)
+	}
+
+	failed, attempted := 0, 0
+	for i := range records {
+		r := &records[i]
+		if r.req == nil {
+			continue
+		}
+		attempted++
+		dr, err := ds.CreateRecord(domain, r.req)
+		if err != nil {
+			r.Action = importFailed
+			r.Detail = err.Error()
+			failed++
+			continue
+		}
+		recordDNSChange(domain, dnsChangeCreate, nil, dr.DomainRecord)
+		r.Action = importCreated
+	}
+
+	if err := display(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d records could not be created", failed, attempted)
+	}
+	if skipped > 0 {
+		notice("%d records of the zone were skipped and must be recreated by hand if they are needed.", skipped)
+	}
+	return nil
+}
+
+// convertZoneRecord converts a record of a transferred zone to the request
+// that creates it in DigitalOcean DNS, or explains why it is skipped.
+func convertZoneRecord(domain string, rr axfr.Record) importRecord {
+	r := importRecord{DomainImportRecord: displayers.DomainImportRecord{
+		Action: importSkip,
+		Type:   axfr.TypeName(rr.Type),
+		Data:   zoneRecordData(rr),
+		TTL:    int(rr.TTL),
+	}}
+
+	name := strings.TrimSuffix(rr.Name, ".")
+	switch {
+	case name == domain:
+		r.Name = "@"
+	case strings.HasSuffix(name, "."+domain):
+		r.Name = strings.TrimSuffix(name, "."+domain)
+	default:
+		r.Name = rr.Name
+		r.Detail = fmt.Sprintf("the record is outside of %s", domain)
+		return r
+	}
+
+	req := &do.DomainRecordEditRequest{Type: r.Type, Name: r.Name, Data: rr.Data, TTL: r.TTL}
+	switch rr.Type {
+	case axfr.TypeSOA:
+		r.Detail = "DigitalOcean DNS creates the SOA record of the domain"
+		return r
+	case axfr.TypeNS:
+		if r.Name == "@" {
+			r.Detail = "DigitalOcean DNS serves the domain with its own NS records"
+			return r
+		}
+	case axfr.TypeCNAME:
+		if r.Name == "@" {
+			r.Detail = "a CNAME record can't be at the apex of a domain"
+			return r
+		}
+	case axfr.TypeA, axfr.TypeAAAA, axfr.TypeTXT:
+	case axfr.TypeSPF:
+		req.Type = "TXT"
+		r.Type = "TXT"
+		r.Detail = "converted from the obsolete SPF type"
+	case axfr.TypeMX:
+		req.Priority = rr.Priority
+	case axfr.TypeSRV:
+		req.Priority = rr.Priority
+		req.Weight = rr.Weight
+	case axfr.TypeCAA:
+		tag, err := validateCAARecord(rr.Flags, rr.Tag, rr.Data)
+		if err != nil {
+			r.Detail = err.Error()
+			return r
+		}
+		req.Flags = rr.Flags
+		req.Tag = tag
+	default:
+		r.Detail = fmt.Sprintf("DigitalOcean DNS doesn't support %s records", r.Type)
+		return r
+	}
+	if rr.Class != axfr.ClassIN {
+		r.Detail = fmt.Sprintf("only records of class IN are supported, not %d", rr.Class)
+		return r
+	}
+
+	port := rr.Port
+	req.Port = &port
+	if req.TTL < minRecordTTL {
+		req.TTL = minRecordTTL
+		r.Detail = fmt.Sprintf("TTL raised from %d to %d seconds", r.TTL, minRecordTTL)
+		r.TTL = minRecordTTL
+	}
+	r.Action = importCreate
+	r.req = req
+	return r
+}
+
+// zoneRecordData returns the data of a record as it is written in zone files.
+func zoneRecordData(rr axfr.Record) string {
+	switch rr.Type {
+	case axfr.TypeMX:
+		return fmt.Sprintf("%d %s", rr.Priority, rr.Data)
+	case axfr.TypeSRV:
+		return fmt.Sprintf("%d %d %d %s", rr.Priority, rr.Weight, rr.Port, rr.Data)
+	case axfr.TypeCAA:
+		return fmt.Sprintf("%d %s %q", rr.Flags, rr.Tag, rr.Data)
+	case axfr.TypeTXT, axfr.TypeSPF:
+		quoted := make([]string, len(rr.Strings))
+		for i, s := range rr.Strings {
+			quoted[i] = fmt.Sprintf("%q", s)
+		}
+		return strings.Join(quoted, " ")
+	}
+	if rr.Data == "" && len(rr.Raw) > 0 {
+		return fmt.Sprintf("%d bytes", len(rr.Raw))
+	}
+	return rr.Data
+}
+
+// importKey identifies records that are the same in domain, whether their
+// host names are relative or fully qualified.
+func importKey(domain string, r *godo.DomainRecord) string {
+	data := r.Data
+	switch r.Type {
+	case "CNAME", "MX", "NS", "SRV":
+		data = recordFQDN(domain, data)
+	case "A", "AAAA":
+		data = strings.ToLower(data)
+	}
+	return fmt.Sprintf("%s %s %s %d %d %d %s", r.Type, r.Name, data, r.Priority, r.Port, r.Weight, strings.ToLower(r.Tag))
+}
@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/doctl/pkg/axfr"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var testZoneRecords = []axfr.Record{
+	{Name: "example.com.", Type: axfr.TypeSOA, Class: axfr.ClassIN, TTL: 3600, Data: "ns1.oldprovider.com."},
+	{Name: "example.com.", Type: axfr.TypeNS, Class: axfr.ClassIN, TTL: 3600, Data: "ns1.oldprovider.com."},
+	{Name: "dev.example.com.", Type: axfr.TypeNS, Class: axfr.ClassIN, TTL: 3600, Data: "ns.dev.example.net."},
+	{Name: "www.example.com.", Type: axfr.TypeA, Class: axfr.ClassIN, TTL: 300, Data: "192.0.2.1"},
+	{Name: "api.example.com.", Type: axfr.TypeA, Class: axfr.ClassIN, TTL: 10, Data: "192.0.2.2"},
+	{Name: "example.com.", Type: axfr.TypeMX, Class: axfr.ClassIN, TTL: 3600, Data: "mail.example.com.", Priority: 10},
+	{Name: "example.com.", Type: axfr.TypeTXT, Class: axfr.ClassIN, TTL: 300, Data: "v=spf1 mx -all", Strings: []string{"v=spf1 mx -all"}},
+	{Name: "example.com.", Type: axfr.TypeSPF, Class: axfr.ClassIN, TTL: 300, Data: "v=spf1 mx -all", Strings: []string{"v=spf1 mx -all"}},
+	{Name: "_sip._tcp.example.com.", Type: axfr.TypeSRV, Class: axfr.ClassIN, TTL: 300, Data: "sip.example.com.", Priority: 10, Weight: 5, Port: 5060},
+	{Name: "example.com.", Type: axfr.TypeCAA, Class: axfr.ClassIN, TTL: 300, Data: "letsencrypt.org", Tag: "issue"},
+	{Name: "_25._tcp.mail.example.com.", Type: 52, Class: axfr.ClassIN, TTL: 300, Raw: []byte{3, 1, 1, 0xab}},
+}
+
+func stubAXFR(t *testing.T, records []axfr.Record) {
+	prev := axfrTransfer
+	axfrTransfer = func(ctx context.Context, server, zone string, opts axfr.Options) ([]axfr.Record, error) {
+		assert.Equal(t, "ns1.oldprovider.com", server)
+		assert.Equal(t, "example.com", zone)
+		return records, nil
+	}
+	t.Cleanup(func() { axfrTransfer = prev })
+}
+
+func TestConvertZoneRecord(t *testing.T) {
+	var actions, details []string
+	for _, rr := range testZoneRecords {
+		r := convertZoneRecord("example.com", rr)
+		actions = append(actions, r.Action)
+		details = append(details, r.Detail)
+	}
+	assert.Equal(t, []string{
+		importSkip, importSkip, importCreate, importCreate, importCreate, importCreate,
+		importCreate, importCreate, importCreate, importCreate, importSkip,
+	}, actions)
+	assert.Equal(t, "TTL raised from 10 to 30 seconds", details[4])
+	assert.Equal(t, "converted from the obsolete SPF type", details[7])
+	assert.Equal(t, "DigitalOcean DNS doesn't support TLSA records", details[10])
+
+	r := convertZoneRecord("example.com", testZoneRecords[8])
+	port := 5060
+	assert.Equal(t, &do.DomainRecordEditRequest{
+		Type: "SRV", Name: "_sip._tcp", Data: "sip.example.com.", Priority: 10, Port: &port, TTL: 300, Weight: 5,
+	}, r.req)
+	assert.Equal(t, "10 5 5060 sip.example.com.", r.Data)
+
+	r = convertZoneRecord("example.com", axfr.Record{Name: "example.com.", Type: axfr.TypeCNAME, Class: axfr.ClassIN, Data: "example.net."})
+	assert.Equal(t, importSkip, r.Action)
+	r = convertZoneRecord("example.com", axfr.Record{Name: "example.com.", Type: axfr.TypeCAA, Class: axfr.ClassIN, Tag: "contactemail", Data: "x@example.com"})
+	assert.Equal(t, importSkip, r.Action)
+}
+
+func TestDomainImport(t *testing.T) {
+	stubAXFR(t, testZoneRecords)
+	prev := viper.GetString(doctl.ArgOutput)
+	viper.Set(doctl.ArgOutput, "json")
+	t.Cleanup(func() { viper.Set(doctl.ArgOutput, prev) })
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var buf bytes.Buffer
+		config.Out = &buf
+		tm.domains.EXPECT().Get("example.com").Return(&testDomain, nil)
+		tm.domains.EXPECT().Records("example.com").Return(do.DomainRecords{
+			{DomainRecord: &godo.DomainRecord{ID: 1, Type: "A", Name: "www", Data: "192.0.2.1", TTL: 1800}},
+			{DomainRecord: &godo.DomainRecord{ID: 2, Type: "MX", Name: "@", Data: "mail.example.com", Priority: 10, TTL: 1800}},
+		}, nil)
+		var created []string
+		tm.domains.EXPECT().CreateRecord("example.com", gomock.Any()).DoAndReturn(
+			func(domain string, req *do.DomainRecordEditRequest) (*do.DomainRecord, error) {
+				created = append(created, req.Type+" "+req.Name)
+				return &testRecord, nil
+			}).Times(5)
+
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgDomainImportAXFR, "ns1.oldprovider.com")
+
+		err := RunDomainImport(config)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"NS dev", "A api", "TXT @", "SRV _sip._tcp", "CAA @"}, created)
+
+		var records []displayers.DomainImportRecord
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &records))
+		var actions []string
+		for _, r := range records {
+			actions = append(actions, r.Action)
+		}
+		assert.Equal(t, []string{
+			importSkip, importSkip, importCreated, importExists, importCreated, importExists,
+			importCreated, importExists, importCreated, importCreated, importSkip,
+		}, actions)
+	})
+}
+
+func TestDomainImportNewDomain(t *testing.T) {
+	stubAXFR(t, testZoneRecords[:4])
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.domains.EXPECT().Get("example.com").Return(nil, &godo.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}})
+		tm.domains.EXPECT().Create(&godo.DomainCreateRequest{Name: "example.com"}).Return(&testDomain, nil)
+		tm.domains.EXPECT().CreateRecord("example.com", gomock.Any()).Return(&testRecord, nil).Times(2)
+
+		config.Args = append(config.Args, "example.com.")
+		config.Doit.Set(config.NS, doctl.ArgDomainImportAXFR, "ns1.oldprovider.com")
+
+		err := RunDomainImport(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestDomainImportDryRun(t *testing.T) {
+	stubAXFR(t, testZoneRecords)
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.domains.EXPECT().Get("example.com").Return(nil, &godo.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}})
+
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgDomainImportAXFR, "ns1.oldprovider.com")
+		config.Doit.Set(config.NS, doctl.ArgDryRun, true)
+
+		err := RunDomainImport(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestDomainImportInvalidTSIGKey(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgDomainImportAXFR, "ns1.oldprovider.com")
+		config.Doit.Set(config.NS, doctl.ArgDomainImportTSIGKey, "hmac-sha3:transfer:c2VjcmV0")
+
+		err := RunDomainImport(config)
+		assert.ErrorContains(t, err, "unsupported TSIG algorithm")
+	})
+}
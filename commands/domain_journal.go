@@ -0,0 +1,265 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/viper"
+)
+
+const (
+	dnsChangeCreate = "create"
+	dnsChangeUpdate = "update"
+	dnsChangeDelete = "delete"
+)
+
+// dnsJournalPath returns the file DNS record changes are journaled to. It is replaced for testing.
+var dnsJournalPath = func() string {
+	return filepath.Join(configHome(), "dns-journal.jsonl")
+}
+
+// recordDNSChange appends a DNS record change to the journal. Journaling is best
+// effort: a failure is reported but never fails the command that made the change.
+func recordDNSChange(domain, op string, old, new *godo.DomainRecord) *displayers.DNSChange {
+	change := &displayers.DNSChange{
+		ID:        newDNSChangeID(),
+		Time:      time.Now().UTC(),
+		Actor:     dnsChangeActor(),
+		Domain:    domain,
+		Operation: op,
+		Old:       old,
+		New:       new,
+	}
+
+	if err := appendDNSChange(change); err != nil {
+		warn("Could not record the change in the DNS journal: %v", err)
+	}
+	return change
+}
+
+// journaledRecord fetches a record before it is changed, for the journal.
+// Like journaling, this is best effort: if the record can't be fetched, a
+// warning is printed and nil is returned, so that the change is journaled
+// without the old record instead of not being made.
+func journaledRecord(ds do.DomainsService, domain string, id int) *godo.DomainRecord {
+	r, err := ds.Record(domain, id)
+	if err != nil {
+		warn("Could not fetch record %d of %s for the DNS journal, so the change can't be reverted: %v", id, domain, err)
+		return nil
+	}
+	return r.DomainRecord
+}
+
+// dnsJournalMu serializes the changes appended by commands that change
+// records concurrently.
+var dnsJournalMu sync.Mutex
+
+func appendDNSChange(change *displayers.DNSChange) error {
+	b, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	dnsJournalMu.Lock()
+	defer dnsJournalMu.Unlock()
+
+	f, err := os.OpenFile(dnsJournalPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// readDNSChanges returns the journaled changes, oldest first.
+func readDNSChanges() ([]displayers.DNSChange, error) {
+	f, err := os.Open(dnsJournalPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var changes []displayers.DNSChange
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var change displayers.DNSChange
+		if err := json.Unmarshal(scanner.Bytes(), &change); err != nil {
+			return nil, fmt.Errorf("reading DNS journal: %w", err)
+		}
+		changes = append(changes, change)
+	}
+	return changes, scanner.Err()
+}
+
+func newDNSChangeID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// dnsChangeActor identifies who made a change: the local user and the auth context used.
+func dnsChangeActor() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+
+	context := Context
+	if context == "" {
+		context = viper.GetString("context")
+	}
+	if context == "" {
+		context = doctl.ArgDefaultContext
+	}
+	return name + "@" + context
+}
+
+// editRequestFromRecord builds a request that recreates a record's current values.
+func editRequestFromRecord(r *godo.DomainRecord) *do.DomainRecordEditRequest {
+	port := r.Port
+	return &do.DomainRecordEditRequest{
+		Type:     r.Type,
+		Name:     r.Name,
+		Data:     r.Data,
+		Priority: r.Priority,
+		Port:     &port,
+		TTL:      r.TTL,
+		Weight:   r.Weight,
+		Flags:    r.Flags,
+		Tag:      r.Tag,
+	}
+}
+
+// RunRecordHistory lists the journaled changes to a domain's records.
+func RunRecordHistory(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	domain := c.Args[0]
+
+	changes, err := readDNSChanges()
+	if err != nil {
+		return err
+	}
+
+	var matching []displayers.DNSChange
+	for _, change := range changes {
+		if change.Domain == domain {
+			matching = append(matching, change)
+		}
+	}
+
+	return c.Display(&displayers.DNSChanges{Changes: matching})
+}
+
+// RunRecordRevert undoes a journaled change to a DNS record.
+func RunRecordRevert(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	id := c.Args[0]
+
+	force, err := c.Doit.GetBool(c.NS, doctl.ArgForce)
+	if err != nil {
+		return err
+	}
+
+	changes, err := readDNSChanges()
+	if err != nil {
+		return err
+	}
+
+	var change *displayers.DNSChange
+	for i := range changes {
+		if changes[i].ID == id {
+			change = &changes[i]
+		}
+	}
+	if change == nil {
+		return fmt.Errorf("no change with ID %s in the DNS journal", id)
+	}
+
+	if !force && AskForConfirm(fmt.Sprintf("revert the %s of this %s record in %s?", change.Operation, changeRecordType(change), change.Domain)) != nil {
+		return errOperationAborted
+	}
+
+	if change.Old == nil && change.Operation != dnsChangeCreate {
+		return fmt.Errorf("change %s can't be reverted, since the record wasn't known before it", id)
+	}
+
+	ds := c.Domains()
+	var reverted *displayers.DNSChange
+	switch change.Operation {
+	case dnsChangeCreate:
+		if err := ds.DeleteRecord(change.Domain, change.New.ID); err != nil {
+			return err
+		}
+		reverted = recordDNSChange(change.Domain, dnsChangeDelete, change.New, nil)
+	case dnsChangeDelete:
+		r, err := ds.CreateRecord(change.Domain, editRequestFromRecord(change.Old))
+		if err != nil {
+			return err
+		}
+		reverted = recordDNSChange(change.Domain, dnsChangeCreate, nil, r.DomainRecord)
+	case dnsChangeUpdate:
+		current, err := ds.Record(change.Domain, change.Old.ID)
+		if err != nil {
+			return err
+		}
+		r, err := ds.EditRecord(change.Domain, change.Old.ID, editRequestFromRecord(change.Old))
+		if err != nil {
+			return err
+		}
+		reverted = recordDNSChange(change.Domain, dnsChangeUpdate, current.DomainRecord, r.DomainRecord)
+	default:
+		return fmt.Errorf("unknown operation %q in change %s", change.Operation, id)
+	}
+
+	return c.Display(&displayers.DNSChanges{Changes: []displayers.DNSChange{*reverted}})
+}
+
+func changeRecordType(change *displayers.DNSChange) string {
+	if change.Old != nil {
+		return change.Old.Type
+	}
+	if change.New != nil {
+		return change.New.Type
+	}
+	return ""
+}
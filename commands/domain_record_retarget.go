@@ -0,0 +1,220 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+)
+
+// The results of a record retarget.
+const (
+	retargetPlanned = "planned"
+	retargetDone    = "done"
+)
+
+// retargetKey identifies a record across domains.
+type retargetKey struct {
+	domain string
+	id     int
+}
+
+// RunRecordRetarget points the A or AAAA records of one or more domains that
+// point at an address to another address, or deletes them.
+func RunRecordRetarget(c *CmdConfig) error {
+	fromIP, err := c.Doit.GetString(c.NS, doctl.ArgRetargetFromIP)
+	if err != nil {
+		return err
+	}
+	toIP, err := c.Doit.GetString(c.NS, doctl.ArgRetargetToIP)
+	if err != nil {
+		return err
+	}
+	del, err := c.Doit.GetBool(c.NS, doctl.ArgRetargetDelete)
+	if err != nil {
+		return err
+	}
+	domains, err := c.Doit.GetStringSlice(c.NS, doctl.ArgRetargetDomains)
+	if err != nil {
+		return err
+	}
+	dryRun, err := c.Doit.GetBool(c.NS, doctl.ArgDryRun)
+	if err != nil {
+		return err
+	}
+	force, err := c.Doit.GetBool(c.NS, doctl.ArgForce)
+	if err != nil {
+		return err
+	}
+	concurrency, err := c.Doit.GetInt(c.NS, doctl.ArgExecConcurrency)
+	if err != nil {
+		return err
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	recordType, err := recordSetType(fromIP)
+	if err != nil {
+		return err
+	}
+	from := net.ParseIP(fromIP)
+	switch {
+	case del == (toIP != ""):
+		return fmt.Errorf("exactly one of `--%s` and `--%s` is required", doctl.ArgRetargetToIP, doctl.ArgRetargetDelete)
+	case toIP != "":
+		toType, err := recordSetType(toIP)
+		if err != nil {
+			return err
+		}
+		if toType != recordType {
+			return fmt.Errorf("%s and %s are not both IPv4 or both IPv6 addresses", fromIP, toIP)
+		}
+	}
+
+	ds := c.Domains()
+	if len(domains) == 0 || (len(domains) == 1 && domains[0] == "all") {
+		list, err := ds.List()
+		if err != nil {
+			return err
+		}
+		domains = make([]string, len(list))
+		for i, d := range list {
+			domains[i] = d.Name
+		}
+	}
+
+	// The records of every domain are looked up before any is changed.
+	var (
+		mu       sync.Mutex
+		matches  []displayers.RecordRetarget
+		selected = map[retargetKey]do.DomainRecord{}
+		scanErr  error
+	)
+	forEachConcurrently(len(domains), concurrency, func(i int) {
+		records, err := ds.Records(domains[i])
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if scanErr == nil {
+				scanErr = fmt.Errorf("listing the records of %s: %w", domains[i], err)
+			}
+			return
+		}
+		for _, r := range records {
+			if r.Type != recordType || !from.Equal(net.ParseIP(r.Data)) {
+				continue
+			}
+			match := displayers.RecordRetarget{Domain: domains[i], ID: r.ID, Name: r.Name, Type: r.Type, From: r.Data, To: toIP, Result: retargetPlanned}
+			if del {
+				match.Action = dnsChangeDelete
+			} else {
+				match.Action = dnsChangeUpdate
+			}
+			matches = append(matches, match)
+			selected[retargetKey{domains[i], r.ID}] = r
+		}
+	})
+	if scanErr != nil {
+		return scanErr
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Domain != matches[j].Domain {
+			return matches[i].Domain < matches[j].Domain
+		}
+		if matches[i].Name != matches[j].Name {
+			return matches[i].Name < matches[j].Name
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
+	if len(matches) == 0 {
+		notice("No %s records in %d domains point at %s.", recordType, len(domains), fromIP)
+	}
+	if dryRun || len(matches) == 0 {
+		return c.Display(&displayers.RecordRetargets{Records: matches})
+	}
+
+	if del && !force {
+		ids := make([]string, len(matches))
+		for i, m := range matches {
+			ids[i] = strconv.Itoa(m.ID)
+		}
+		if err := AskForConfirmDelete("domain record", len(matches), ids...); err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+	forEachConcurrently(len(matches), concurrency, func(i int) {
+		m := &matches[i]
+		old := selected[retargetKey{m.Domain, m.ID}]
+		var err error
+		if del {
+			err = ds.DeleteRecord(m.Domain, m.ID)
+			if err == nil {
+				recordDNSChange(m.Domain, dnsChangeDelete, old.DomainRecord, nil)
+			}
+		} else {
+			req := editRequestFromRecord(old.DomainRecord)
+			req.Data = toIP
+			var r *do.DomainRecord
+			r, err = ds.EditRecord(m.Domain, m.ID, req)
+			if err == nil {
+				recordDNSChange(m.Domain, dnsChangeUpdate, old.DomainRecord, r.DomainRecord)
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			m.Result = err.Error()
+			failed++
+			return
+		}
+		m.Result = retargetDone
+	})
+
+	if err := c.Display(&displayers.RecordRetargets{Records: matches}); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d records could not be changed", failed, len(matches))
+	}
+	return nil
+}
+
+// forEachConcurrently calls fn with each index below n, running at most
+// concurrency calls at once, and returns when all of them have returned.
+func forEachConcurrently(n, concurrency int, fn func(i int)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
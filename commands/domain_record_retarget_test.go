@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func retargetTestRecords() (do.DomainRecords, do.DomainRecords) {
+	return do.DomainRecords{
+		aRecord(1, "@", "198.51.100.7"),
+		aRecord(2, "www", "198.51.100.9"),
+		{DomainRecord: &godo.DomainRecord{ID: 3, Type: "TXT", Name: "@", Data: "198.51.100.7"}},
+	}, do.DomainRecords{
+		aRecord(4, "api", "198.51.100.7"),
+	}
+}
+
+func TestRecordsRetarget(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		example, other := retargetTestRecords()
+		port := 0
+		tm.domains.EXPECT().List().Return(do.Domains{
+			{Domain: &godo.Domain{Name: "example.com"}},
+			{Domain: &godo.Domain{Name: "example.org"}},
+		}, nil)
+		tm.domains.EXPECT().Records("example.com").Return(example, nil)
+		tm.domains.EXPECT().Records("example.org").Return(other, nil)
+		tm.domains.EXPECT().EditRecord("example.com", 1, &do.DomainRecordEditRequest{Type: "A", Name: "@", Data: "198.51.100.8", Port: &port, TTL: 300}).Return(&testRecord, nil)
+		tm.domains.EXPECT().EditRecord("example.org", 4, &do.DomainRecordEditRequest{Type: "A", Name: "api", Data: "198.51.100.8", Port: &port, TTL: 300}).Return(&testRecord, nil)
+
+		config.Doit.Set(config.NS, doctl.ArgRetargetFromIP, "198.51.100.7")
+		config.Doit.Set(config.NS, doctl.ArgRetargetToIP, "198.51.100.8")
+		config.Doit.Set(config.NS, doctl.ArgRetargetDomains, []string{"all"})
+		config.Doit.Set(config.NS, doctl.ArgExecConcurrency, 2)
+
+		err := RunRecordRetarget(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestRecordsRetargetDelete(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		example, _ := retargetTestRecords()
+		tm.domains.EXPECT().Records("example.com").Return(example, nil)
+		tm.domains.EXPECT().DeleteRecord("example.com", 1).Return(nil)
+
+		config.Doit.Set(config.NS, doctl.ArgRetargetFromIP, "198.51.100.7")
+		config.Doit.Set(config.NS, doctl.ArgRetargetDelete, true)
+		config.Doit.Set(config.NS, doctl.ArgRetargetDomains, []string{"example.com"})
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+
+		err := RunRecordRetarget(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestRecordsRetargetDryRun(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		example, other := retargetTestRecords()
+		tm.domains.EXPECT().Records("example.com").Return(example, nil)
+		tm.domains.EXPECT().Records("example.org").Return(other, nil)
+
+		config.Doit.Set(config.NS, doctl.ArgRetargetFromIP, "198.51.100.7")
+		config.Doit.Set(config.NS, doctl.ArgRetargetToIP, "198.51.100.8")
+		config.Doit.Set(config.NS, doctl.ArgRetargetDomains, []string{"example.com", "example.org"})
+		config.Doit.Set(config.NS, doctl.ArgDryRun, true)
+
+		err := RunRecordRetarget(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestRecordsRetargetFailure(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		example, other := retargetTestRecords()
+		port := 0
+		tm.domains.EXPECT().Records("example.com").Return(example, nil)
+		tm.domains.EXPECT().Records("example.org").Return(other, nil)
+		tm.domains.EXPECT().EditRecord("example.com", 1, gomock.Any()).Return(nil, errors.New("boom"))
+		tm.domains.EXPECT().EditRecord("example.org", 4, &do.DomainRecordEditRequest{Type: "A", Name: "api", Data: "198.51.100.8", Port: &port, TTL: 300}).Return(&testRecord, nil)
+
+		config.Doit.Set(config.NS, doctl.ArgRetargetFromIP, "198.51.100.7")
+		config.Doit.Set(config.NS, doctl.ArgRetargetToIP, "198.51.100.8")
+		config.Doit.Set(config.NS, doctl.ArgRetargetDomains, []string{"example.com", "example.org"})
+
+		// The other record is still changed.
+		err := RunRecordRetarget(config)
+		assert.ErrorContains(t, err, "1 of 2 records")
+	})
+}
+
+func TestRecordsRetargetInvalid(t *testing.T) {
+	for _, set := range []func(*CmdConfig){
+		func(config *CmdConfig) {},
+		func(config *CmdConfig) {
+			config.Doit.Set(config.NS, doctl.ArgRetargetToIP, "198.51.100.8")
+			config.Doit.Set(config.NS, doctl.ArgRetargetDelete, true)
+		},
+		func(config *CmdConfig) { config.Doit.Set(config.NS, doctl.ArgRetargetToIP, "2001:db8::1") },
+		func(config *CmdConfig) { config.Doit.Set(config.NS, doctl.ArgRetargetFromIP, "web-1") },
+	} {
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			config.Doit.Set(config.NS, doctl.ArgRetargetFromIP, "198.51.100.7")
+			set(config)
+			config.Doit.Set(config.NS, doctl.ArgRetargetDomains, []string{"example.com"})
+
+			err := RunRecordRetarget(config)
+			assert.Error(t, err)
+		})
+	}
+}
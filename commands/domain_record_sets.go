@@ -0,0 +1,327 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/spf13/cobra"
+)
+
+// maxRecordSetWeight bounds the number of identical records created for one member.
+const maxRecordSetWeight = 20
+
+// RecordSet creates the record-set commands hierarchy.
+func RecordSet() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "record-set",
+			Short: "Manage round-robin sets of A and AAAA records",
+			Long: `Use the subcommands of ` + "`" + `doctl compute domain record-set` + "`" + ` to manage all A and AAAA records with the same name as one round-robin set, for simple DNS-based load distribution.
+
+Each member of a set is an IP address. DNS has no notion of weights for A and AAAA records, so a member's weight is emulated by the number of identical records that point to it: a member with weight 3 is returned roughly three times as often as a member with weight 1. Weights are limited to ` + fmt.Sprint(maxRecordSetWeight) + `.`,
+		},
+	}
+
+	cmdRecordSetList := CmdBuilder(cmd, RunRecordSetList, "list <domain>", "List the members of record sets", `Lists the members of the A and AAAA record sets of a domain, with each member's weight and its share of the set's responses.`, Writer,
+		aliasOpt("ls"), displayerType(&displayers.RecordSet{}))
+	AddStringFlag(cmdRecordSetList, doctl.ArgRecordName, "", "", "Only list the set with this name, for example `www` or `@`")
+	cmdRecordSetList.Example = `The following command lists the members of the ` + "`" + `www` + "`" + ` set of example.com: doctl compute domain record-set list example.com --record-name www`
+
+	cmdRecordSetAdd := CmdBuilder(cmd, RunRecordSetAdd, "add <domain> <ip-address>...", "Add members to a record set", `Adds IP addresses to a record set, creating the set if it does not exist. IPv4 addresses are added as A records and IPv6 addresses as AAAA records.`, Writer,
+		displayerType(&displayers.RecordSet{}))
+	AddStringFlag(cmdRecordSetAdd, doctl.ArgRecordName, "", "", "The name of the set, for example `www` or `@`", requiredOpt())
+	AddIntFlag(cmdRecordSetAdd, doctl.ArgRecordSetWeight, "", 1, "The weight of the new members")
+	AddIntFlag(cmdRecordSetAdd, doctl.ArgRecordTTL, "", 300, "The Time To Live (TTL) of the new records, in seconds")
+	cmdRecordSetAdd.Example = `The following command adds two servers to the ` + "`" + `www` + "`" + ` set of example.com: doctl compute domain record-set add example.com 198.51.100.10 198.51.100.11 --record-name www`
+
+	cmdRecordSetRemove := CmdBuilder(cmd, RunRecordSetRemove, "remove <domain> <ip-address>...", "Remove members from a record set", `Removes IP addresses from a record set by deleting all of their records.`, Writer,
+		aliasOpt("rm"), displayerType(&displayers.RecordSet{}))
+	AddStringFlag(cmdRecordSetRemove, doctl.ArgRecordName, "", "", "The name of the set", requiredOpt())
+	cmdRecordSetRemove.Example = `The following command removes a server from the ` + "`" + `www` + "`" + ` set of example.com: doctl compute domain record-set remove example.com 198.51.100.11 --record-name www`
+
+	cmdRecordSetWeight := CmdBuilder(cmd, RunRecordSetWeight, "set-weight <domain> <ip-address>", "Change the weight of a record set member", `Changes the weight of a record set member by creating or deleting identical records until the member has as many records as its weight.`, Writer,
+		displayerType(&displayers.RecordSet{}))
+	AddStringFlag(cmdRecordSetWeight, doctl.ArgRecordName, "", "", "The name of the set", requiredOpt())
+	AddIntFlag(cmdRecordSetWeight, doctl.ArgRecordSetWeight, "", 1, "The new weight of the member", requiredOpt())
+	cmdRecordSetWeight.Example = `The following command sends three times as much traffic to 198.51.100.10 as to a member with weight 1: doctl compute domain record-set set-weight example.com 198.51.100.10 --record-name www --weight 3`
+
+	return cmd
+}
+
+// recordSetType returns the record type used for an address.
+func recordSetType(addr string) (string, error) {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return "", fmt.Errorf("%q is not an IP address", addr)
+	}
+	if ip.To4() != nil {
+		return "A", nil
+	}
+	return "AAAA", nil
+}
+
+func isRecordSetType(t string) bool {
+	return t == "A" || t == "AAAA"
+}
+
+// recordSetMembers groups a set's records by address.
+func recordSetMembers(records do.DomainRecords, name string) map[string][]do.DomainRecord {
+	members := map[string][]do.DomainRecord{}
+	for _, r := range records {
+		if r.Name == name && isRecordSetType(r.Type) {
+			members[r.Data] = append(members[r.Data], r)
+		}
+	}
+	return members
+}
+
+// RunRecordSetList lists record set members.
+func RunRecordSetList(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	domain := c.Args[0]
+
+	name, err := c.Doit.GetString(c.NS, doctl.ArgRecordName)
+	if err != nil {
+		return err
+	}
+
+	records, err := c.Domains().Records(domain)
+	if err != nil {
+		return err
+	}
+
+	names := map[string]bool{}
+	for _, r := range records {
+		if isRecordSetType(r.Type) && (name == "" || r.Name == name) {
+			names[r.Name] = true
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for n := range names {
+		sorted = append(sorted, n)
+	}
+	sort.Strings(sorted)
+
+	var members []displayers.RecordSetMember
+	for _, n := range sorted {
+		members = append(members, buildRecordSetMembers(records, n)...)
+	}
+
+	return c.Display(&displayers.RecordSet{Members: members})
+}
+
+func buildRecordSetMembers(records do.DomainRecords, name string) []displayers.RecordSetMember {
+	set := recordSetMembers(records, name)
+
+	total := 0
+	addrs := make([]string, 0, len(set))
+	for addr, rs := range set {
+		total += len(rs)
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	members := make([]displayers.RecordSetMember, 0, len(addrs))
+	for _, addr := range addrs {
+		rs := set[addr]
+		members = append(members, displayers.RecordSetMember{
+			Name:   name,
+			Type:   rs[0].Type,
+			Data:   addr,
+			Weight: len(rs),
+			Share:  float64(len(rs)) / float64(total) * 100,
+			TTL:    rs[0].TTL,
+		})
+	}
+	return members
+}
+
+// RunRecordSetAdd adds members to a record set.
+func RunRecordSetAdd(c *CmdConfig) error {
+	if len(c.Args) < 2 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	domain, addrs := c.Args[0], c.Args[1:]
+
+	name, err := c.Doit.GetString(c.NS, doctl.ArgRecordName)
+	if err != nil {
+		return err
+	}
+	weight, err := c.Doit.GetInt(c.NS, doctl.ArgRecordSetWeight)
+	if err != nil {
+		return err
+	}
+	ttl, err := c.Doit.GetInt(c.NS, doctl.ArgRecordTTL)
+	if err != nil {
+		return err
+	}
+	if err := checkRecordSetWeight(weight); err != nil {
+		return err
+	}
+
+	ds := c.Domains()
+	records, err := ds.Records(domain)
+	if err != nil {
+		return err
+	}
+	set := recordSetMembers(records, name)
+
+	for _, addr := range addrs {
+		if _, err := recordSetType(addr); err != nil {
+			return err
+		}
+		if len(set[addr]) > 0 {
+			return fmt.Errorf("%s is already a member of the %s set; use set-weight to change its weight", addr, name)
+		}
+	}
+
+	for _, addr := range addrs {
+		for i := 0; i < weight; i++ {
+			if err := createRecordSetRecord(ds, domain, name, addr, ttl); err != nil {
+				return err
+			}
+		}
+	}
+
+	return displayRecordSet(c, ds, domain, name)
+}
+
+// RunRecordSetRemove removes members from a record set.
+func RunRecordSetRemove(c *CmdConfig) error {
+	if len(c.Args) < 2 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	domain, addrs := c.Args[0], c.Args[1:]
+
+	name, err := c.Doit.GetString(c.NS, doctl.ArgRecordName)
+	if err != nil {
+		return err
+	}
+
+	ds := c.Domains()
+	records, err := ds.Records(domain)
+	if err != nil {
+		return err
+	}
+	set := recordSetMembers(records, name)
+
+	for _, addr := range addrs {
+		if len(set[addr]) == 0 {
+			return fmt.Errorf("%s is not a member of the %s set", addr, name)
+		}
+	}
+
+	for _, addr := range addrs {
+		for _, r := range set[addr] {
+			if err := deleteRecordSetRecord(ds, domain, r); err != nil {
+				return err
+			}
+		}
+	}
+
+	return displayRecordSet(c, ds, domain, name)
+}
+
+// RunRecordSetWeight changes the weight of a record set member.
+func RunRecordSetWeight(c *CmdConfig) error {
+	if len(c.Args) != 2 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	domain, addr := c.Args[0], c.Args[1]
+
+	name, err := c.Doit.GetString(c.NS, doctl.ArgRecordName)
+	if err != nil {
+		return err
+	}
+	weight, err := c.Doit.GetInt(c.NS, doctl.ArgRecordSetWeight)
+	if err != nil {
+		return err
+	}
+	if err := checkRecordSetWeight(weight); err != nil {
+		return err
+	}
+
+	ds := c.Domains()
+	records, err := ds.Records(domain)
+	if err != nil {
+		return err
+	}
+	current := recordSetMembers(records, name)[addr]
+	if len(current) == 0 {
+		return fmt.Errorf("%s is not a member of the %s set; use add to add it", addr, name)
+	}
+
+	for i := len(current); i < weight; i++ {
+		if err := createRecordSetRecord(ds, domain, name, addr, current[0].TTL); err != nil {
+			return err
+		}
+	}
+	for i := weight; i < len(current); i++ {
+		if err := deleteRecordSetRecord(ds, domain, current[i]); err != nil {
+			return err
+		}
+	}
+
+	return displayRecordSet(c, ds, domain, name)
+}
+
+func checkRecordSetWeight(weight int) error {
+	if weight < 1 || weight > maxRecordSetWeight {
+		return fmt.Errorf("weight must be between 1 and %d", maxRecordSetWeight)
+	}
+	return nil
+}
+
+func createRecordSetRecord(ds do.DomainsService, domain, name, addr string, ttl int) error {
+	rType, err := recordSetType(addr)
+	if err != nil {
+		return err
+	}
+	r, err := ds.CreateRecord(domain, &do.DomainRecordEditRequest{
+		Type: rType,
+		Name: name,
+		Data: addr,
+		TTL:  ttl,
+	})
+	if err != nil {
+		return err
+	}
+	recordDNSChange(domain, dnsChangeCreate, nil, r.DomainRecord)
+	return nil
+}
+
+func deleteRecordSetRecord(ds do.DomainsService, domain string, r do.DomainRecord) error {
+	if err := ds.DeleteRecord(domain, r.ID); err != nil {
+		return err
+	}
+	recordDNSChange(domain, dnsChangeDelete, r.DomainRecord, nil)
+	return nil
+}
+
+func displayRecordSet(c *CmdConfig, ds do.DomainsService, domain, name string) error {
+	records, err := ds.Records(domain)
+	if err != nil {
+		return err
+	}
+	return c.Display(&displayers.RecordSet{Members: buildRecordSetMembers(records, name)})
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func aRecord(id int, name, data string) do.DomainRecord {
+	return do.DomainRecord{DomainRecord: &godo.DomainRecord{ID: id, Type: "A", Name: name, Data: data, TTL: 300}}
+}
+
+func TestRecordSetCommand(t *testing.T) {
+	cmd := RecordSet()
+	assert.NotNil(t, cmd)
+	assertCommandNames(t, cmd, "add", "list", "remove", "set-weight")
+}
+
+func TestBuildRecordSetMembers(t *testing.T) {
+	records := do.DomainRecords{
+		aRecord(1, "www", "192.0.2.1"),
+		aRecord(2, "www", "192.0.2.1"),
+		aRecord(3, "www", "192.0.2.1"),
+		aRecord(4, "www", "192.0.2.2"),
+		aRecord(5, "api", "192.0.2.3"),
+		{DomainRecord: &godo.DomainRecord{ID: 6, Type: "TXT", Name: "www", Data: "hello"}},
+	}
+
+	members := buildRecordSetMembers(records, "www")
+	require.Len(t, members, 2)
+	assert.Equal(t, "192.0.2.1", members[0].Data)
+	assert.Equal(t, 3, members[0].Weight)
+	assert.Equal(t, 75.0, members[0].Share)
+	assert.Equal(t, 1, members[1].Weight)
+}
+
+func TestRecordSetAdd(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		existing := do.DomainRecords{aRecord(1, "www", "192.0.2.1")}
+		tm.domains.EXPECT().Records("example.com").Return(existing, nil)
+		tm.domains.EXPECT().CreateRecord("example.com", &do.DomainRecordEditRequest{
+			Type: "AAAA", Name: "www", Data: "2001:db8::1", TTL: 60,
+		}).Times(2).Return(&do.DomainRecord{DomainRecord: &godo.DomainRecord{ID: 2}}, nil)
+		tm.domains.EXPECT().Records("example.com").Return(existing, nil)
+
+		config.Args = append(config.Args, "example.com", "2001:db8::1")
+		config.Doit.Set(config.NS, doctl.ArgRecordName, "www")
+		config.Doit.Set(config.NS, doctl.ArgRecordSetWeight, 2)
+		config.Doit.Set(config.NS, doctl.ArgRecordTTL, 60)
+
+		err := RunRecordSetAdd(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestRecordSetAddExistingMember(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.domains.EXPECT().Records("example.com").Return(do.DomainRecords{aRecord(1, "www", "192.0.2.1")}, nil)
+
+		config.Args = append(config.Args, "example.com", "192.0.2.1")
+		config.Doit.Set(config.NS, doctl.ArgRecordName, "www")
+		config.Doit.Set(config.NS, doctl.ArgRecordSetWeight, 1)
+
+		err := RunRecordSetAdd(config)
+		assert.ErrorContains(t, err, "already a member")
+	})
+}
+
+func TestRecordSetWeight(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		records := do.DomainRecords{
+			aRecord(1, "www", "192.0.2.1"),
+			aRecord(2, "www", "192.0.2.1"),
+			aRecord(3, "www", "192.0.2.1"),
+		}
+		tm.domains.EXPECT().Records("example.com").Return(records, nil).Times(2)
+		tm.domains.EXPECT().DeleteRecord("example.com", gomock.Any()).Times(2).Return(nil)
+
+		config.Args = append(config.Args, "example.com", "192.0.2.1")
+		config.Doit.Set(config.NS, doctl.ArgRecordName, "www")
+		config.Doit.Set(config.NS, doctl.ArgRecordSetWeight, 1)
+
+		err := RunRecordSetWeight(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestRecordSetRemove(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		records := do.DomainRecords{
+			aRecord(1, "www", "192.0.2.1"),
+			aRecord(2, "www", "192.0.2.2"),
+		}
+		tm.domains.EXPECT().Records("example.com").Return(records, nil)
+		tm.domains.EXPECT().DeleteRecord("example.com", 2).Return(nil)
+		tm.domains.EXPECT().Records("example.com").Return(records[:1], nil)
+
+		config.Args = append(config.Args, "example.com", "192.0.2.2")
+		config.Doit.Set(config.NS, doctl.ArgRecordName, "www")
+
+		err := RunRecordSetRemove(config)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+)
+
+const (
+	// minRecordTTL is the lowest TTL the API accepts.
+	minRecordTTL = 30
+	// recommendedMinTTL and recommendedMaxTTL bound the TTLs that are
+	// neither so low that resolvers query too often, nor so high that
+	// changes take too long to be seen.
+	recommendedMinTTL = 300
+	recommendedMaxTTL = 86400
+)
+
+// recordFilter selects the records a bulk record command acts on.
+type recordFilter struct {
+	types []string
+	match string
+}
+
+func getRecordFilter(c *CmdConfig) (recordFilter, error) {
+	types, err := c.Doit.GetStringSlice(c.NS, doctl.ArgRecordTypes)
+	if err != nil {
+		return recordFilter{}, err
+	}
+	match, err := c.Doit.GetString(c.NS, doctl.ArgRecordMatch)
+	if err != nil {
+		return recordFilter{}, err
+	}
+	if _, err := path.Match(match, ""); err != nil {
+		return recordFilter{}, fmt.Errorf("invalid `--%s` pattern %q: %w", doctl.ArgRecordMatch, match, err)
+	}
+	for i, t := range types {
+		types[i] = strings.ToUpper(t)
+	}
+	return recordFilter{types: types, match: match}, nil
+}
+
+// selects reports whether the filter selects r. SOA records are only
+// selected if their type is given.
+func (f recordFilter) selects(r do.DomainRecord) bool {
+	if len(f.types) > 0 && !contains(f.types, r.Type) {
+		return false
+	}
+	if len(f.types) == 0 && r.Type == "SOA" {
+		return false
+	}
+	if f.match == "" {
+		return true
+	}
+	ok, _ := path.Match(f.match, r.Name)
+	return ok
+}
+
+// bulkUpdateRecords applies update to the edit requests of records and saves
+// the records that it changes, recording each change in the journal. It
+// returns the updated records.
+func bulkUpdateRecords(ds do.DomainsService, domain string, records do.DomainRecords, update func(*do.DomainRecordEditRequest)) (do.DomainRecords, error) {
+	updated := make(do.DomainRecords, 0, len(records))
+	for _, r := range records {
+		req := editRequestFromRecord(r.DomainRecord)
+		update(req)
+		if reflect.DeepEqual(req, editRequestFromRecord(r.DomainRecord)) {
+			continue
+		}
+
+		nr, err := ds.EditRecord(domain, r.ID, req)
+		if err != nil {
+			return updated, fmt.Errorf("updating record %d: %w", r.ID, err)
+		}
+		recordDNSChange(domain, dnsChangeUpdate, r.DomainRecord, nr.DomainRecord)
+		updated = append(updated, *nr)
+	}
+	return updated, nil
+}
+
+// RunRecordSetTTL sets the TTL of the selected records of a domain, or lists
+// the ones with TTLs outside the recommended range.
+func RunRecordSetTTL(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	domain := c.Args[0]
+
+	filter, err := getRecordFilter(c)
+	if err != nil {
+		return err
+	}
+	audit, err := c.Doit.GetBool(c.NS, doctl.ArgRecordTTLAudit)
+	if err != nil {
+		return err
+	}
+	ttl, err := c.Doit.GetInt(c.NS, doctl.ArgRecordNewTTL)
+	if err != nil {
+		return err
+	}
+	dryRun, err := c.Doit.GetBool(c.NS, doctl.ArgDryRun)
+	if err != nil {
+		return err
+	}
+
+	if audit {
+		return auditRecordTTLs(c, domain, filter)
+	}
+	if ttl == 0 {
+		return fmt.Errorf("`--%s` is required unless `--%s` is set", doctl.ArgRecordNewTTL, doctl.ArgRecordTTLAudit)
+	}
+	if ttl < minRecordTTL {
+		return fmt.Errorf("TTL must be at least %d seconds", minRecordTTL)
+	}
+
+	ds := c.Domains()
+	records, err := ds.Records(domain)
+	if err != nil {
+		return err
+	}
+
+	var selected do.DomainRecords
+	var changes []displayers.RecordTTL
+	for _, r := range records {
+		if !filter.selects(r) || r.TTL == ttl {
+			continue
+		}
+		selected = append(selected, r)
+		changes = append(changes, displayers.RecordTTL{ID: r.ID, Type: r.Type, Name: r.Name, Data: r.Data, TTL: r.TTL, NewTTL: ttl})
+	}
+
+	if !dryRun {
+		if _, err := bulkUpdateRecords(ds, domain, selected, func(req *do.DomainRecordEditRequest) { req.TTL = ttl }); err != nil {
+			return err
+		}
+	}
+	if len(changes) == 0 {
+		notice("No records of %s need a TTL of %d.", domain, ttl)
+	}
+
+	return c.Display(&displayers.RecordTTLs{Records: changes})
+}
+
+// auditRecordTTLs lists the selected records of a domain whose TTLs are
+// outside the recommended range.
+func auditRecordTTLs(c *CmdConfig, domain string, filter recordFilter) error {
+	minTTL, err := c.Doit.GetInt(c.NS, doctl.ArgRecordMinTTL)
+	if err != nil {
+		return err
+	}
+	maxTTL, err := c.Doit.GetInt(c.NS, doctl.ArgRecordMaxTTL)
+	if err != nil {
+		return err
+	}
+	if minTTL > maxTTL {
+		return fmt.Errorf("`--%s` must not be greater than `--%s`", doctl.ArgRecordMinTTL, doctl.ArgRecordMaxTTL)
+	}
+
+	records, err := c.Domains().Records(domain)
+	if err != nil {
+		return err
+	}
+
+	var findings []displayers.RecordTTL
+	for _, r := range records {
+		if !filter.selects(r) {
+			continue
+		}
+		finding := displayers.RecordTTL{ID: r.ID, Type: r.Type, Name: r.Name, Data: r.Data, TTL: r.TTL}
+		switch {
+		case r.TTL < minTTL:
+			finding.NewTTL = minTTL
+			finding.Reason = fmt.Sprintf("below %d; resolvers query it often", minTTL)
+		case r.TTL > maxTTL:
+			finding.NewTTL = maxTTL
+			finding.Reason = fmt.Sprintf("above %d; changes take long to be seen", maxTTL)
+		default:
+			continue
+		}
+		findings = append(findings, finding)
+	}
+
+	return c.Display(&displayers.RecordTTLs{Records: findings, Audit: true})
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTTLRecords = do.DomainRecords{
+	{DomainRecord: &godo.DomainRecord{ID: 1, Type: "SOA", Name: "@", Data: "1800", TTL: 1800}},
+	{DomainRecord: &godo.DomainRecord{ID: 2, Type: "A", Name: "api", Data: "192.0.2.1", TTL: 3600}},
+	{DomainRecord: &godo.DomainRecord{ID: 3, Type: "A", Name: "api-eu", Data: "192.0.2.2", TTL: 300}},
+	{DomainRecord: &godo.DomainRecord{ID: 4, Type: "AAAA", Name: "api", Data: "2001:db8::1", TTL: 3600}},
+	{DomainRecord: &godo.DomainRecord{ID: 5, Type: "A", Name: "www", Data: "192.0.2.3", TTL: 60}},
+	{DomainRecord: &godo.DomainRecord{ID: 6, Type: "MX", Name: "@", Data: "mail.example.com.", Priority: 10, TTL: 604800}},
+}
+
+func TestRecordFilter(t *testing.T) {
+	var selected []int
+	f := recordFilter{types: []string{"A", "AAAA"}, match: "api*"}
+	for _, r := range testTTLRecords {
+		if f.selects(r) {
+			selected = append(selected, r.ID)
+		}
+	}
+	assert.Equal(t, []int{2, 3, 4}, selected)
+
+	selected = nil
+	for _, r := range testTTLRecords {
+		if (recordFilter{}).selects(r) {
+			selected = append(selected, r.ID)
+		}
+	}
+	assert.Equal(t, []int{2, 3, 4, 5, 6}, selected)
+}
+
+func TestRecordsSetTTL(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		port := 0
+		tm.domains.EXPECT().Records("example.com").Return(testTTLRecords, nil)
+		tm.domains.EXPECT().EditRecord("example.com", 2, &do.DomainRecordEditRequest{
+			Type: "A", Name: "api", Data: "192.0.2.1", Port: &port, TTL: 300,
+		}).Return(&testRecord, nil)
+
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgRecordTypes, []string{"a"})
+		config.Doit.Set(config.NS, doctl.ArgRecordMatch, "api*")
+		config.Doit.Set(config.NS, doctl.ArgRecordNewTTL, 300)
+
+		// api-eu already has the TTL, so only api is updated.
+		err := RunRecordSetTTL(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestRecordsSetTTLKeepsOtherFields(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		port := 0
+		tm.domains.EXPECT().Records("example.com").Return(testTTLRecords, nil)
+		tm.domains.EXPECT().EditRecord("example.com", 6, &do.DomainRecordEditRequest{
+			Type: "MX", Name: "@", Data: "mail.example.com.", Priority: 10, Port: &port, TTL: 3600,
+		}).Return(&testRecord, nil)
+
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgRecordTypes, []string{"MX"})
+		config.Doit.Set(config.NS, doctl.ArgRecordNewTTL, 3600)
+
+		err := RunRecordSetTTL(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestRecordsSetTTLDryRun(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.domains.EXPECT().Records("example.com").Return(testTTLRecords, nil)
+
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgRecordNewTTL, 300)
+		config.Doit.Set(config.NS, doctl.ArgDryRun, true)
+
+		err := RunRecordSetTTL(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestRecordsSetTTLInvalid(t *testing.T) {
+	for _, set := range []func(*CmdConfig){
+		func(config *CmdConfig) {},
+		func(config *CmdConfig) { config.Doit.Set(config.NS, doctl.ArgRecordNewTTL, 10) },
+		func(config *CmdConfig) {
+			config.Doit.Set(config.NS, doctl.ArgRecordNewTTL, 300)
+			config.Doit.Set(config.NS, doctl.ArgRecordMatch, "api[")
+		},
+	} {
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			config.Args = append(config.Args, "example.com")
+			set(config)
+
+			err := RunRecordSetTTL(config)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRecordsSetTTLAudit(t *testing.T) {
+	prev := viper.GetString(doctl.ArgOutput)
+	viper.Set(doctl.ArgOutput, "json")
+	t.Cleanup(func() { viper.Set(doctl.ArgOutput, prev) })
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var buf bytes.Buffer
+		config.Out = &buf
+		tm.domains.EXPECT().Records("example.com").Return(testTTLRecords, nil)
+
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgRecordTTLAudit, true)
+		config.Doit.Set(config.NS, doctl.ArgRecordMinTTL, recommendedMinTTL)
+		config.Doit.Set(config.NS, doctl.ArgRecordMaxTTL, recommendedMaxTTL)
+
+		err := RunRecordSetTTL(config)
+		require.NoError(t, err)
+
+		var findings []displayers.RecordTTL
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &findings))
+		require.Len(t, findings, 2)
+		assert.Equal(t, 5, findings[0].ID)
+		assert.Equal(t, recommendedMinTTL, findings[0].NewTTL)
+		assert.Equal(t, 6, findings[1].ID)
+		assert.Equal(t, recommendedMaxTTL, findings[1].NewTTL)
+	})
+}
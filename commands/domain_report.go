@@ -0,0 +1,215 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+)
+
+// The checks of a domain report.
+const (
+	reportDuplicate     = "duplicate"
+	reportDanglingCNAME = "dangling-cname"
+	reportPrivateIP     = "private-ip"
+	reportLongTXT       = "long-txt"
+)
+
+// maxTXTResponseSize is the size, in bytes, of the TXT records of a name above
+// which answers may not fit in a UDP response, which is 1232 bytes with the
+// EDNS buffer size most resolvers use.
+const maxTXTResponseSize = 1232
+
+// RunDomainReport summarizes the records of a domain and reports the ones
+// that are likely mistakes.
+func RunDomainReport(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	domain := strings.ToLower(strings.TrimSuffix(c.Args[0], "."))
+
+	records, err := c.Domains().Records(domain)
+	if err != nil {
+		return err
+	}
+
+	report := &displayers.DomainReport{Domain: domain, Counts: map[string]int{}, Findings: []displayers.DomainReportFinding{}}
+	for _, r := range records {
+		report.Counts[r.Type]++
+	}
+	report.Findings = append(report.Findings, reportDuplicates(records)...)
+	report.Findings = append(report.Findings, reportDanglingCNAMEs(domain, records)...)
+	report.Findings = append(report.Findings, reportPrivateIPs(records)...)
+	report.Findings = append(report.Findings, reportLongTXTs(records)...)
+
+	return c.Display(report)
+}
+
+// reportDuplicates finds records with the same type, name, and data.
+func reportDuplicates(records do.DomainRecords) []displayers.DomainReportFinding {
+	groups := map[string][]do.DomainRecord{}
+	var keys []string
+	for _, r := range records {
+		key := r.Type + " " + r.Name + " " + strings.ToLower(strings.TrimSuffix(r.Data, "."))
+		if r.Type == "MX" || r.Type == "SRV" {
+			key += fmt.Sprintf(" %d %d %d", r.Priority, r.Weight, r.Port)
+		}
+		if r.Type == "CAA" {
+			key += " " + r.Tag
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], r)
+	}
+
+	var findings []displayers.DomainReportFinding
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+		ids := make([]string, len(group))
+		for i, r := range group {
+			ids[i] = strconv.Itoa(r.ID)
+		}
+		detail := fmt.Sprintf("%d identical records (IDs %s)", len(group), strings.Join(ids, ", "))
+		if isRecordSetType(group[0].Type) {
+			detail += "; intended only if this is a weighted record set"
+		}
+		findings = append(findings, displayers.DomainReportFinding{
+			Check: reportDuplicate, Type: group[0].Type, Name: group[0].Name, Data: group[0].Data, Detail: detail,
+		})
+	}
+	return findings
+}
+
+// reportDanglingCNAMEs finds CNAME records whose targets don't exist: names
+// in the zone with no records, or names outside it that don't resolve.
+func reportDanglingCNAMEs(domain string, records do.DomainRecords) []displayers.DomainReportFinding {
+	names := map[string]bool{}
+	for _, r := range records {
+		names[recordFQDN(domain, r.Name)] = true
+	}
+
+	var findings []displayers.DomainReportFinding
+	for _, r := range records {
+		if r.Type != "CNAME" {
+			continue
+		}
+		target := recordFQDN(domain, r.Data)
+		finding := displayers.DomainReportFinding{Check: reportDanglingCNAME, Type: r.Type, Name: r.Name, Data: r.Data}
+		if target == domain || strings.HasSuffix(target, "."+domain) {
+			if !names[target] {
+				finding.Detail = fmt.Sprintf("%s has no records in the zone", target)
+				findings = append(findings, finding)
+			}
+			continue
+		}
+
+		_, err := lookupHost(target)
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			finding.Detail = fmt.Sprintf("%s does not exist in public DNS", target)
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// recordFQDN returns the fully qualified name, without a trailing dot, of a
+// record name or target in domain's zone. "@" is the domain itself, and names
+// without a trailing dot are relative to it.
+func recordFQDN(domain, name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case name == "@":
+		return domain
+	case strings.HasSuffix(name, "."):
+		return strings.TrimSuffix(name, ".")
+	case name == domain || strings.HasSuffix(name, "."+domain):
+		return name
+	}
+	return name + "." + domain
+}
+
+// reportPrivateIPs finds A and AAAA records pointing to addresses that aren't
+// reachable from the internet.
+func reportPrivateIPs(records do.DomainRecords) []displayers.DomainReportFinding {
+	var findings []displayers.DomainReportFinding
+	for _, r := range records {
+		if !isRecordSetType(r.Type) {
+			continue
+		}
+		ip := net.ParseIP(r.Data)
+		var kind string
+		switch {
+		case ip == nil:
+			continue
+		case ip.IsPrivate():
+			kind = "a private"
+		case ip.IsLoopback():
+			kind = "a loopback"
+		case ip.IsLinkLocalUnicast():
+			kind = "a link-local"
+		case ip.IsUnspecified():
+			kind = "the unspecified"
+		default:
+			continue
+		}
+		findings = append(findings, displayers.DomainReportFinding{
+			Check: reportPrivateIP, Type: r.Type, Name: r.Name, Data: r.Data,
+			Detail: fmt.Sprintf("%s is %s address, unreachable from the internet", r.Data, kind),
+		})
+	}
+	return findings
+}
+
+// reportLongTXTs finds names whose TXT records are too large to be answered
+// reliably over UDP.
+func reportLongTXTs(records do.DomainRecords) []displayers.DomainReportFinding {
+	sizes := map[string]int{}
+	counts := map[string]int{}
+	for _, r := range records {
+		if r.Type == "TXT" {
+			sizes[r.Name] += len(txtData(r.Data))
+			counts[r.Name]++
+		}
+	}
+	names := make([]string, 0, len(sizes))
+	for name := range sizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var findings []displayers.DomainReportFinding
+	for _, name := range names {
+		if sizes[name] <= maxTXTResponseSize {
+			continue
+		}
+		findings = append(findings, displayers.DomainReportFinding{
+			Check: reportLongTXT, Type: "TXT", Name: name,
+			Detail: fmt.Sprintf("%d TXT records with %d bytes of data; answers over %d bytes may need TCP, which some resolvers don't use", counts[name], sizes[name], maxTXTResponseSize),
+		})
+	}
+	return findings
+}
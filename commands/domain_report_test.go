@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordFQDN(t *testing.T) {
+	assert.Equal(t, "example.com", recordFQDN("example.com", "@"))
+	assert.Equal(t, "www.example.com", recordFQDN("example.com", "www"))
+	assert.Equal(t, "www.example.com", recordFQDN("example.com", "WWW.example.com."))
+	assert.Equal(t, "shop.example.net", recordFQDN("example.com", "shop.example.net."))
+}
+
+func TestDomainReport(t *testing.T) {
+	orig := lookupHost
+	t.Cleanup(func() { lookupHost = orig })
+	lookupHost = func(host string) ([]string, error) {
+		if host == "gone.example.net" {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return []string{"203.0.113.1"}, nil
+	}
+
+	prev := viper.GetString(doctl.ArgOutput)
+	viper.Set(doctl.ArgOutput, "json")
+	t.Cleanup(func() { viper.Set(doctl.ArgOutput, prev) })
+
+	record := func(id int, typ, name, data string) do.DomainRecord {
+		return do.DomainRecord{DomainRecord: &godo.DomainRecord{ID: id, Type: typ, Name: name, Data: data}}
+	}
+	records := do.DomainRecords{
+		record(1, "A", "@", "203.0.113.10"),
+		record(2, "A", "www", "203.0.113.10"),
+		record(3, "A", "www", "203.0.113.10"),
+		record(4, "A", "db", "10.0.0.5"),
+		record(5, "CNAME", "blog", "www.example.com."),
+		record(6, "CNAME", "shop", "store.example.com."),
+		record(7, "CNAME", "docs", "gone.example.net."),
+		record(8, "CNAME", "status", "status.example.net."),
+		record(9, "TXT", "@", strings.Repeat("a", 800)),
+		record(10, "TXT", "@", strings.Repeat("b", 800)),
+		record(11, "TXT", "_dmarc", "v=DMARC1; p=none"),
+	}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var buf bytes.Buffer
+		config.Out = &buf
+		tm.domains.EXPECT().Records("example.com").Return(records, nil)
+
+		config.Args = append(config.Args, "example.com")
+
+		err := RunDomainReport(config)
+		require.NoError(t, err)
+
+		var report displayers.DomainReport
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+		assert.Equal(t, map[string]int{"A": 4, "CNAME": 4, "TXT": 3}, report.Counts)
+
+		var checks []string
+		for _, f := range report.Findings {
+			checks = append(checks, f.Check+" "+f.Name)
+		}
+		assert.Equal(t, []string{
+			"duplicate www",
+			"dangling-cname shop",
+			"dangling-cname docs",
+			"private-ip db",
+			"long-txt @",
+		}, checks)
+	})
+}
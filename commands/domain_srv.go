@@ -0,0 +1,190 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/spf13/cobra"
+)
+
+// srvServicePattern matches service names (RFC 6335, section 5.1): at most
+// 15 letters, digits, and hyphens, with at least one letter, and no hyphen at
+// either end or next to another.
+var srvServicePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// srvProtocols are the protocols SRV records are commonly published for.
+var srvProtocols = []string{"tcp", "udp", "tls", "sctp"}
+
+// SRV creates the SRV record commands hierarchy.
+func SRV() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "srv",
+			Short: "Manage SRV records for services",
+			Long:  "Use the subcommands of `doctl compute domain srv` to manage the SRV records that tell clients which servers and ports provide a service, such as SIP or XMPP, for a domain.",
+		},
+	}
+
+	cmdSRVAdd := CmdBuilder(cmd, RunSRVAdd, "add <domain>", "Add an SRV record for a service", `Adds an SRV record for a service to a domain. The record's name is built from the service and protocol, for example `+"`"+`_sip._tcp`+"`"+`, followed by `+"`"+`--record-name`+"`"+` if the service is offered for a subdomain.
+
+Clients try the targets with the lowest `+"`"+`--priority`+"`"+` first, and pick among targets with the same priority in proportion to their `+"`"+`--weight`+"`"+`. A `+"`"+`--target`+"`"+` of `+"`"+`.`+"`"+` means that the service is not available for the domain. The record is not created if the domain already has it, and is written to the DNS change journal otherwise.`, Writer,
+		displayerType(&displayers.DomainRecord{}))
+	AddStringFlag(cmdSRVAdd, doctl.ArgSRVService, "", "", "The name of the service, for example `sip` or `xmpp-client`", requiredOpt())
+	AddStringFlag(cmdSRVAdd, doctl.ArgSRVProto, "", "tcp", "The protocol of the service: "+strings.Join(srvProtocols, ", "))
+	AddIntFlag(cmdSRVAdd, doctl.ArgSRVPriority, "", 10, "The priority of the target. Lower values are tried first.")
+	AddIntFlag(cmdSRVAdd, doctl.ArgSRVWeight, "", 5, "The weight of the target among targets with the same priority")
+	AddIntFlag(cmdSRVAdd, doctl.ArgSRVPort, "", 0, "The port of the service on the target", requiredOpt())
+	AddStringFlag(cmdSRVAdd, doctl.ArgSRVTarget, "", "", "The host name of the server that provides the service", requiredOpt())
+	AddStringFlag(cmdSRVAdd, doctl.ArgRecordName, "", "", "The subdomain the service is offered for, if not the domain itself")
+	AddIntFlag(cmdSRVAdd, doctl.ArgRecordTTL, "", 1800, "The record's Time To Live (TTL) value, in seconds")
+	cmdSRVAdd.Example = `The following command adds an SRV record for SIP over TCP on port 5060 of host.example.com: doctl compute domain srv add example.com --service sip --proto tcp --priority 10 --weight 5 --port 5060 --target host.example.com`
+
+	return cmd
+}
+
+// srvRecordName returns the name of the SRV record of service over proto
+// for the subdomain name, or for the domain itself if name is empty or "@".
+func srvRecordName(service, proto, name string) (string, error) {
+	service = strings.ToLower(strings.TrimPrefix(service, "_"))
+	proto = strings.ToLower(strings.TrimPrefix(proto, "_"))
+	if len(service) > 15 || !srvServicePattern.MatchString(service) || strings.Trim(service, "0123456789-") == "" {
+		return "", fmt.Errorf("invalid service name %q; it must be at most 15 letters, digits, and hyphens, with at least one letter", service)
+	}
+	if !contains(srvProtocols, proto) {
+		return "", fmt.Errorf("invalid protocol %q; valid protocols are %s", proto, strings.Join(srvProtocols, ", "))
+	}
+
+	recordName := "_" + service + "._" + proto
+	if name != "" && name != "@" {
+		if !hostNamePattern.MatchString(name) || strings.HasSuffix(name, ".") {
+			return "", fmt.Errorf("invalid subdomain %q", name)
+		}
+		recordName += "." + name
+	}
+	return recordName, nil
+}
+
+// srvRecordData checks the fields of an SRV record and returns its target as
+// a fully qualified name.
+func srvRecordData(priority, weight, port int, target string) (string, error) {
+	for _, f := range []struct {
+		name  string
+		value int
+	}{{doctl.ArgSRVPriority, priority}, {doctl.ArgSRVWeight, weight}, {doctl.ArgSRVPort, port}} {
+		if f.value < 0 || f.value > 65535 {
+			return "", fmt.Errorf("`--%s` must be between 0 and 65535, not %d", f.name, f.value)
+		}
+	}
+	if target == "." {
+		return target, nil
+	}
+	if port == 0 {
+		return "", fmt.Errorf("`--%s` is required unless the target is \".\"", doctl.ArgSRVPort)
+	}
+	if !hostNamePattern.MatchString(target) || net.ParseIP(strings.TrimSuffix(target, ".")) != nil {
+		return "", fmt.Errorf("invalid target %q; it must be a host name, not an IP address or URL", target)
+	}
+	if !strings.HasSuffix(target, ".") {
+		target += "."
+	}
+	return strings.ToLower(target), nil
+}
+
+// RunSRVAdd adds an SRV record for a service to a domain.
+func RunSRVAdd(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	domain := c.Args[0]
+
+	service, err := c.Doit.GetString(c.NS, doctl.ArgSRVService)
+	if err != nil {
+		return err
+	}
+	proto, err := c.Doit.GetString(c.NS, doctl.ArgSRVProto)
+	if err != nil {
+		return err
+	}
+	priority, err := c.Doit.GetInt(c.NS, doctl.ArgSRVPriority)
+	if err != nil {
+		return err
+	}
+	weight, err := c.Doit.GetInt(c.NS, doctl.ArgSRVWeight)
+	if err != nil {
+		return err
+	}
+	port, err := c.Doit.GetInt(c.NS, doctl.ArgSRVPort)
+	if err != nil {
+		return err
+	}
+	target, err := c.Doit.GetString(c.NS, doctl.ArgSRVTarget)
+	if err != nil {
+		return err
+	}
+	name, err := c.Doit.GetString(c.NS, doctl.ArgRecordName)
+	if err != nil {
+		return err
+	}
+	ttl, err := c.Doit.GetInt(c.NS, doctl.ArgRecordTTL)
+	if err != nil {
+		return err
+	}
+
+	recordName, err := srvRecordName(service, proto, name)
+	if err != nil {
+		return err
+	}
+	data, err := srvRecordData(priority, weight, port, target)
+	if err != nil {
+		return err
+	}
+
+	ds := c.Domains()
+	records, err := ds.Records(domain)
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		if r.Type == "SRV" && r.Name == recordName && normalizeHost(r.Data) == normalizeHost(data) &&
+			r.Priority == priority && r.Weight == weight && r.Port == port {
+			notice("%s already has the SRV record %s.", domain, recordName)
+			return displayDomainRecords(c, r)
+		}
+	}
+
+	req := &do.DomainRecordEditRequest{
+		Type:     "SRV",
+		Name:     recordName,
+		Data:     data,
+		Priority: priority,
+		Port:     &port,
+		TTL:      ttl,
+		Weight:   weight,
+	}
+	r, err := ds.CreateRecord(domain, req)
+	if err != nil {
+		return err
+	}
+	recordDNSChange(domain, dnsChangeCreate, nil, r.DomainRecord)
+
+	return displayDomainRecords(c, *r)
+}
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSRVRecordName(t *testing.T) {
+	name, err := srvRecordName("sip", "TCP", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "_sip._tcp", name)
+
+	name, err = srvRecordName("_xmpp-client", "_tcp", "chat")
+	assert.NoError(t, err)
+	assert.Equal(t, "_xmpp-client._tcp.chat", name)
+
+	for _, tt := range []struct{ service, proto, name string }{
+		{"", "tcp", ""},
+		{"a-very-long-service", "tcp", ""},
+		{"sip-", "tcp", ""},
+		{"5060", "tcp", ""},
+		{"sip", "http", ""},
+		{"sip", "tcp", "eu."},
+	} {
+		_, err := srvRecordName(tt.service, tt.proto, tt.name)
+		assert.Error(t, err, tt)
+	}
+}
+
+func TestSRVRecordData(t *testing.T) {
+	data, err := srvRecordData(10, 5, 5060, "Host.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "host.example.com.", data)
+
+	data, err = srvRecordData(0, 0, 0, ".")
+	assert.NoError(t, err)
+	assert.Equal(t, ".", data)
+
+	for _, tt := range []struct {
+		priority, weight, port int
+		target                 string
+	}{
+		{-1, 5, 5060, "host.example.com"},
+		{10, 70000, 5060, "host.example.com"},
+		{10, 5, 0, "host.example.com"},
+		{10, 5, 5060, "192.0.2.1"},
+		{10, 5, 5060, "sip://host.example.com"},
+	} {
+		_, err := srvRecordData(tt.priority, tt.weight, tt.port, tt.target)
+		assert.Error(t, err, tt)
+	}
+}
+
+func TestSRVAdd(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		port := 5060
+		tm.domains.EXPECT().Records("example.com").Return(do.DomainRecords{
+			{DomainRecord: &godo.DomainRecord{ID: 1, Type: "SRV", Name: "_sip._tcp", Data: "other.example.com.", Priority: 10, Weight: 5, Port: 5060}},
+		}, nil)
+		tm.domains.EXPECT().CreateRecord("example.com", &do.DomainRecordEditRequest{
+			Type: "SRV", Name: "_sip._tcp", Data: "host.example.com.", Priority: 10, Port: &port, TTL: 1800, Weight: 5,
+		}).Return(&testRecord, nil)
+
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgSRVService, "sip")
+		config.Doit.Set(config.NS, doctl.ArgSRVProto, "tcp")
+		config.Doit.Set(config.NS, doctl.ArgSRVPriority, 10)
+		config.Doit.Set(config.NS, doctl.ArgSRVWeight, 5)
+		config.Doit.Set(config.NS, doctl.ArgSRVPort, 5060)
+		config.Doit.Set(config.NS, doctl.ArgSRVTarget, "host.example.com")
+		config.Doit.Set(config.NS, doctl.ArgRecordTTL, 1800)
+
+		err := RunSRVAdd(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestSRVAddExisting(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.domains.EXPECT().Records("example.com").Return(do.DomainRecords{
+			{DomainRecord: &godo.DomainRecord{ID: 1, Type: "SRV", Name: "_sip._tcp", Data: "host.example.com", Priority: 10, Weight: 5, Port: 5060}},
+		}, nil)
+
+		config.Args = append(config.Args, "example.com")
+		config.Doit.Set(config.NS, doctl.ArgSRVService, "sip")
+		config.Doit.Set(config.NS, doctl.ArgSRVProto, "tcp")
+		config.Doit.Set(config.NS, doctl.ArgSRVPriority, 10)
+		config.Doit.Set(config.NS, doctl.ArgSRVWeight, 5)
+		config.Doit.Set(config.NS, doctl.ArgSRVPort, 5060)
+		config.Doit.Set(config.NS, doctl.ArgSRVTarget, "host.example.com")
+
+		// Nothing is created.
+		err := RunSRVAdd(config)
+		assert.NoError(t, err)
+	})
+}
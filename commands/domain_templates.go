@@ -0,0 +1,262 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"sigs.k8s.io/yaml"
+)
+
+// recordTemplate describes records to generate for every region a set of resources runs in.
+type recordTemplate struct {
+	Domain string `json:"domain"`
+	TTL    int    `json:"ttl"`
+	// Labels maps region slugs to the label used for them in record names.
+	// Regions that are not listed use their slug without the trailing digits.
+	Labels  map[string]string      `json:"labels"`
+	Records []recordTemplateRecord `json:"records"`
+}
+
+type recordTemplateRecord struct {
+	// Name is a Go template for the record name, with the fields .Region and .Label.
+	Name            string `json:"name"`
+	Type            string `json:"type"`
+	DropletTag      string `json:"droplet_tag"`
+	LoadBalancerTag string `json:"load_balancer_tag"`
+}
+
+type recordTemplateRegion struct {
+	Region string
+	Label  string
+}
+
+type recordKey struct {
+	Name, Type, Data string
+}
+
+func loadRecordTemplate(path string) (*recordTemplate, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &recordTemplate{TTL: 300}
+	if err := yaml.UnmarshalStrict(b, t); err != nil {
+		return nil, fmt.Errorf("parsing template %s: %w", path, err)
+	}
+
+	if t.Domain == "" {
+		return nil, errors.New("the template must set a domain")
+	}
+	for i, r := range t.Records {
+		if r.Name == "" {
+			return nil, fmt.Errorf("record %d: a name is required", i+1)
+		}
+		if r.Type != "A" && r.Type != "AAAA" {
+			return nil, fmt.Errorf("record %d: type must be A or AAAA", i+1)
+		}
+		if (r.DropletTag == "") == (r.LoadBalancerTag == "") {
+			return nil, fmt.Errorf("record %d: exactly one of droplet_tag and load_balancer_tag must be set", i+1)
+		}
+		if r.LoadBalancerTag != "" && r.Type != "A" {
+			return nil, fmt.Errorf("record %d: load balancers only have A records", i+1)
+		}
+	}
+	return t, nil
+}
+
+func (t *recordTemplate) label(region string) string {
+	if l, ok := t.Labels[region]; ok {
+		return l
+	}
+	return strings.TrimRight(region, "0123456789")
+}
+
+func renderRecordName(name string, region recordTemplateRegion) (string, error) {
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(name)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, region); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RunRecordApplyTemplate syncs a domain's records with a record template.
+func RunRecordApplyTemplate(c *CmdConfig) error {
+	path, err := c.Doit.GetString(c.NS, doctl.ArgRecordTemplate)
+	if err != nil {
+		return err
+	}
+	dryRun, err := c.Doit.GetBool(c.NS, doctl.ArgDryRun)
+	if err != nil {
+		return err
+	}
+
+	t, err := loadRecordTemplate(path)
+	if err != nil {
+		return err
+	}
+
+	regions, err := c.Regions().List()
+	if err != nil {
+		return err
+	}
+
+	// Names the template manages in any region, so that records of regions
+	// with no resources left are removed as well.
+	managed := map[string]bool{}
+	desired := map[recordKey]bool{}
+	for _, rt := range t.Records {
+		for _, r := range regions {
+			name, err := renderRecordName(rt.Name, recordTemplateRegion{Region: r.Slug, Label: t.label(r.Slug)})
+			if err != nil {
+				return err
+			}
+			managed[name+"/"+rt.Type] = true
+		}
+
+		addrs, err := templateSourceAddresses(c, rt)
+		if err != nil {
+			return err
+		}
+		for region, ips := range addrs {
+			name, err := renderRecordName(rt.Name, recordTemplateRegion{Region: region, Label: t.label(region)})
+			if err != nil {
+				return err
+			}
+			managed[name+"/"+rt.Type] = true
+			for _, ip := range ips {
+				desired[recordKey{Name: name, Type: rt.Type, Data: ip}] = true
+			}
+		}
+	}
+
+	ds := c.Domains()
+	records, err := ds.Records(t.Domain)
+	if err != nil {
+		return err
+	}
+
+	var changes []displayers.RecordTemplateChange
+	existing := map[recordKey]bool{}
+	for _, r := range records {
+		key := recordKey{Name: r.Name, Type: r.Type, Data: r.Data}
+		if !managed[r.Name+"/"+r.Type] {
+			continue
+		}
+		if desired[key] && !existing[key] {
+			existing[key] = true
+			changes = append(changes, displayers.RecordTemplateChange{Action: "keep", Name: r.Name, Type: r.Type, Data: r.Data})
+			continue
+		}
+
+		changes = append(changes, displayers.RecordTemplateChange{Action: "delete", Name: r.Name, Type: r.Type, Data: r.Data})
+		if !dryRun {
+			if err := ds.DeleteRecord(t.Domain, r.ID); err != nil {
+				return err
+			}
+			recordDNSChange(t.Domain, dnsChangeDelete, r.DomainRecord, nil)
+		}
+	}
+
+	missing := make([]recordKey, 0, len(desired))
+	for key := range desired {
+		if !existing[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		if missing[i].Name != missing[j].Name {
+			return missing[i].Name < missing[j].Name
+		}
+		return missing[i].Data < missing[j].Data
+	})
+
+	for _, key := range missing {
+		changes = append(changes, displayers.RecordTemplateChange{Action: "create", Name: key.Name, Type: key.Type, Data: key.Data})
+		if dryRun {
+			continue
+		}
+		r, err := ds.CreateRecord(t.Domain, &do.DomainRecordEditRequest{
+			Type: key.Type,
+			Name: key.Name,
+			Data: key.Data,
+			TTL:  t.TTL,
+		})
+		if err != nil {
+			return err
+		}
+		recordDNSChange(t.Domain, dnsChangeCreate, nil, r.DomainRecord)
+	}
+
+	return c.Display(&displayers.RecordTemplateChanges{Changes: changes})
+}
+
+// templateSourceAddresses returns the public addresses of a template record's
+// resources, grouped by region.
+func templateSourceAddresses(c *CmdConfig, rt recordTemplateRecord) (map[string][]string, error) {
+	addrs := map[string][]string{}
+
+	if rt.DropletTag != "" {
+		droplets, err := c.Droplets().ListByTag(rt.DropletTag)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range droplets {
+			if d.Region == nil || d.Networks == nil {
+				continue
+			}
+			var ip string
+			if rt.Type == "AAAA" {
+				ip, err = d.PublicIPv6()
+			} else {
+				ip, err = d.PublicIPv4()
+			}
+			if err != nil {
+				return nil, err
+			}
+			if ip != "" {
+				addrs[d.Region.Slug] = append(addrs[d.Region.Slug], ip)
+			}
+		}
+	}
+
+	if rt.LoadBalancerTag != "" {
+		lbs, err := c.LoadBalancers().List()
+		if err != nil {
+			return nil, err
+		}
+		for _, lb := range lbs {
+			if lb.Region == nil || lb.IP == "" || !contains(lb.Tags, rt.LoadBalancerTag) {
+				continue
+			}
+			addrs[lb.Region.Slug] = append(addrs[lb.Region.Slug], lb.IP)
+		}
+	}
+
+	return addrs, nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRecordTemplate = `domain: example.com
+ttl: 120
+labels:
+  ams3: eu
+records:
+  - name: "{{.Label}}"
+    type: A
+    droplet_tag: web
+`
+
+func writeRecordTemplate(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "geo.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func templateDroplet(id int, region, ip string) do.Droplet {
+	return do.Droplet{Droplet: &godo.Droplet{
+		ID:     id,
+		Region: &godo.Region{Slug: region},
+		Networks: &godo.Networks{
+			V4: []godo.NetworkV4{{IPAddress: ip, Type: "public"}},
+		},
+	}}
+}
+
+func TestLoadRecordTemplate(t *testing.T) {
+	tmpl, err := loadRecordTemplate(writeRecordTemplate(t, testRecordTemplate))
+	require.NoError(t, err)
+	assert.Equal(t, 120, tmpl.TTL)
+	assert.Equal(t, "eu", tmpl.label("ams3"))
+	assert.Equal(t, "nyc", tmpl.label("nyc3"))
+
+	_, err = loadRecordTemplate(writeRecordTemplate(t, "domain: example.com\nrecords:\n  - name: www\n    type: AAAA\n    load_balancer_tag: web\n"))
+	assert.ErrorContains(t, err, "load balancers only have A records")
+
+	_, err = loadRecordTemplate(writeRecordTemplate(t, "domain: example.com\nrecords:\n  - name: www\n    type: A\n"))
+	assert.ErrorContains(t, err, "exactly one of droplet_tag and load_balancer_tag")
+}
+
+func TestRecordApplyTemplate(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.regions.EXPECT().List().Return(do.Regions{
+			{Region: &godo.Region{Slug: "nyc3"}},
+			{Region: &godo.Region{Slug: "ams3"}},
+			{Region: &godo.Region{Slug: "sfo3"}},
+		}, nil)
+		tm.droplets.EXPECT().ListByTag("web").Return(do.Droplets{
+			templateDroplet(1, "nyc3", "192.0.2.1"),
+			templateDroplet(2, "ams3", "192.0.2.2"),
+		}, nil)
+		tm.domains.EXPECT().Records("example.com").Return(do.DomainRecords{
+			aRecord(10, "nyc", "192.0.2.1"),
+			aRecord(11, "sfo", "192.0.2.9"),
+			aRecord(12, "www", "192.0.2.9"),
+		}, nil)
+		tm.domains.EXPECT().DeleteRecord("example.com", 11).Return(nil)
+		tm.domains.EXPECT().CreateRecord("example.com", &do.DomainRecordEditRequest{
+			Type: "A", Name: "eu", Data: "192.0.2.2", TTL: 120,
+		}).Return(&do.DomainRecord{DomainRecord: &godo.DomainRecord{ID: 13, Type: "A", Name: "eu", Data: "192.0.2.2"}}, nil)
+
+		config.Doit.Set(config.NS, doctl.ArgRecordTemplate, writeRecordTemplate(t, testRecordTemplate))
+		config.Doit.Set(config.NS, doctl.ArgDryRun, false)
+
+		err := RunRecordApplyTemplate(config)
+		assert.NoError(t, err)
+
+		changes, err := readDNSChanges()
+		require.NoError(t, err)
+		assert.Len(t, changes, 2)
+	})
+}
+
+func TestRecordApplyTemplateDryRun(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.regions.EXPECT().List().Return(do.Regions{{Region: &godo.Region{Slug: "nyc3"}}}, nil)
+		tm.droplets.EXPECT().ListByTag("web").Return(do.Droplets{templateDroplet(1, "nyc3", "192.0.2.1")}, nil)
+		tm.domains.EXPECT().Records("example.com").Return(do.DomainRecords{aRecord(10, "nyc", "192.0.2.5")}, nil)
+
+		config.Doit.Set(config.NS, doctl.ArgRecordTemplate, writeRecordTemplate(t, testRecordTemplate))
+		config.Doit.Set(config.NS, doctl.ArgDryRun, true)
+
+		err := RunRecordApplyTemplate(config)
+		assert.NoError(t, err)
+	})
+}
@@ -14,9 +14,14 @@ limitations under the License.
 package commands
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/commands/displayers"
@@ -25,6 +30,28 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// digitalOceanNameservers are the nameservers that serve domains managed by DigitalOcean DNS.
+var digitalOceanNameservers = []string{"ns1.digitalocean.com", "ns2.digitalocean.com", "ns3.digitalocean.com"}
+
+// lookupNS returns the nameservers of a domain in public DNS. It is replaced for testing.
+var lookupNS = func(domain string) ([]string, error) {
+	records, err := net.LookupNS(domain)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, len(records))
+	for i, r := range records {
+		hosts[i] = r.Host
+	}
+	return hosts, nil
+}
+
+// hostNamePattern matches host names, with or without a trailing dot.
+var hostNamePattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?)*\.?$`)
+
+// lookupHost resolves a host name in public DNS. It is replaced for testing.
+var lookupHost = net.LookupHost
+
 // Domain creates the domain commands hierarchy.
 func Domain() *Command {
 	cmd := &Command{
@@ -35,9 +62,16 @@ func Domain() *Command {
 		},
 	}
 
-	cmdDomainCreate := CmdBuilder(cmd, RunDomainCreate, "create <domain>", "Add a domain to your account", `Adds a domain to your account that you can assign to Droplets, load balancers, and other resources.`, Writer,
+	cmdDomainCreate := CmdBuilder(cmd, RunDomainCreate, "create <domain>", "Add a domain to your account", `Adds a domain to your account that you can assign to Droplets, load balancers, and other resources.
+
+The domain can be created with an initial set of records. `+"`"+`--bootstrap`+"`"+` points the domain's apex at a Droplet, with A and AAAA records for its public addresses, or at a load balancer, with an A record for its IP address. `+"`"+`--with-www`+"`"+` adds a `+"`"+`www`+"`"+` CNAME record for the apex, and `+"`"+`--with-mx`+"`"+` adds the MX records of a mail provider: `+"`"+`google`+"`"+`, `+"`"+`microsoft`+"`"+`, `+"`"+`fastmail`+"`"+`, `+"`"+`zoho`+"`"+`, or `+"`"+`proton`+"`"+`. The records are recorded in the history of `+"`"+`doctl compute domain records history`+"`"+`.
+
+For example, `+"`"+`doctl compute domain create example.com --bootstrap droplet:386734086 --with-www --with-mx google`+"`"+` creates example.com pointing at the Droplet with the ID 386734086, with a www record and Google Workspace's MX records.`, Writer,
 		aliasOpt("c"), displayerType(&displayers.Domain{}))
 	AddStringFlag(cmdDomainCreate, doctl.ArgIPAddress, "", "", "Creates an A record for a IPv4 address")
+	AddStringFlag(cmdDomainCreate, doctl.ArgDomainBootstrap, "", "", "The Droplet or load balancer to point the domain at, as `droplet:<id>` or `lb:<id>`")
+	AddBoolFlag(cmdDomainCreate, doctl.ArgDomainWithWWW, "", false, "Creates a www CNAME record for the domain")
+	AddStringFlag(cmdDomainCreate, doctl.ArgDomainWithMX, "", "", "The mail provider to create MX records for: google, microsoft, fastmail, zoho, or proton")
 	cmdDomainCreate.Example = `The following command creates a domain named example.com and adds an A record to the domain: doctl compute domain create example.com --ip-address 198.51.100.215`
 
 	cmdDomainList := CmdBuilder(cmd, RunDomainList, "list", "List all domains on your account", `Retrieves a list of domains on your account.`, Writer,
@@ -52,6 +86,36 @@ func Domain() *Command {
 	AddBoolFlag(cmdRunDomainDelete, doctl.ArgForce, doctl.ArgShortForce, false, "Deletes the domain without a confirmation prompt")
 	cmdRunDomainDelete.Example = `The following command deletes the domain example.com: doctl compute domain delete example.com`
 
+	cmdDomainVerify := CmdBuilder(cmd, RunDomainVerify, "verify <domain>", "Check that a domain is delegated to DigitalOcean", `Checks whether a domain on your account is delegated to DigitalOcean's nameservers.
+
+The command compares the nameservers listed for the domain in public DNS, which are set at your domain registrar, with DigitalOcean's nameservers and with the NS records in the domain's DigitalOcean zone. It reports nameservers that are missing or unexpected, nameservers inside the domain that need glue records, and the exact nameservers to set at your registrar. The command exits with a non-zero status if any problem is found.
+
+Changes at a registrar can take up to 48 hours to be visible in public DNS.`, Writer,
+		displayerType(&displayers.DomainDelegation{}))
+	cmdDomainVerify.Example = `The following command checks the delegation of example.com: doctl compute domain verify example.com`
+
+	cmdDomainReport := CmdBuilder(cmd, RunDomainReport, "report <domain>", "Report on the health of a domain's records", `Counts a domain's records by type and reports the records that are likely mistakes:
+
+- `+"`"+`duplicate`+"`"+`: records with the same type, name, and data. Identical A and AAAA records are expected in weighted record sets.
+- `+"`"+`dangling-cname`+"`"+`: CNAME records whose targets have no records in the zone or don't exist in public DNS.
+- `+"`"+`private-ip`+"`"+`: A and AAAA records pointing at private, loopback, or link-local addresses, which can't be reached from the internet.
+- `+"`"+`long-txt`+"`"+`: names whose TXT records hold more than `+fmt.Sprint(maxTXTResponseSize)+` bytes, whose answers may not fit in a UDP response.
+
+In text output, the record counts are listed as `+"`"+`count`+"`"+` rows before the findings.`, Writer,
+		displayerType(&displayers.DomainReport{}))
+	cmdDomainReport.Example = `The following command reports on the records of example.com as JSON: doctl compute domain report example.com --output json`
+
+	cmdDomainImport := CmdBuilder(cmd, RunDomainImport, "import <domain>", "Import a domain's records from its current nameserver", `Imports a domain's records from the nameserver that currently serves it, with a zone transfer (AXFR). The nameserver must allow transfers to your address, or to requests signed with the TSIG key given with `+"`"+`--tsig-key`+"`"+`, in the form `+"`"+`[algorithm:]name:secret`+"`"+` used by `+"`"+`dig -y`+"`"+`. The algorithm defaults to `+"`"+`hmac-sha256`+"`"+`.
+
+The domain is added to your account if it isn't already. A, AAAA, CAA, CNAME, MX, NS, SRV, and TXT records are created, and obsolete SPF records are created as TXT records. The SOA and apex NS records are replaced by DigitalOcean's own, and records of other types are skipped, since DigitalOcean DNS doesn't support them. Skipped records are listed before any change is made, and records the domain already has are not created again. TTLs below `+fmt.Sprint(minRecordTTL)+` seconds are raised to `+fmt.Sprint(minRecordTTL)+`.
+
+Use `+"`"+`--dry-run`+"`"+` to list what would be imported without changing anything. Every record created is written to the DNS change journal.`, Writer,
+		displayerType(&displayers.DomainImport{}))
+	AddStringFlag(cmdDomainImport, doctl.ArgDomainImportAXFR, "", "", "The nameserver to transfer the zone from, as a host name or IP address with an optional port", requiredOpt())
+	AddStringFlag(cmdDomainImport, doctl.ArgDomainImportTSIGKey, "", "", "The TSIG key that authenticates the transfer, as `[algorithm:]name:secret` with a base64 secret")
+	AddBoolFlag(cmdDomainImport, doctl.ArgDryRun, "", false, "Lists the records that would be imported or skipped without changing anything")
+	cmdDomainImport.Example = `The following command lists the records of example.com that would be imported from ns1.oldprovider.com: doctl compute domain import example.com --axfr ns1.oldprovider.com --tsig-key hmac-sha256:transfer:c2VjcmV0c2VjcmV0c2VjcmV0 --dry-run`
+
 	cmdRecord := &Command{
 		Command: &cobra.Command{
 			Use:   "records",
@@ -60,12 +124,16 @@ func Domain() *Command {
 		},
 	}
 	cmd.AddCommand(cmdRecord)
+	cmd.AddCommand(RecordSet())
+	cmd.AddCommand(EmailAuth())
+	cmd.AddCommand(CAA())
+	cmd.AddCommand(SRV())
 
 	cmdRecordList := CmdBuilder(cmdRecord, RunRecordList, "list <domain>", "List the DNS records for a domain", `Lists the DNS records for a domain.`, Writer,
 		aliasOpt("ls"), displayerType(&displayers.DomainRecord{}))
 	cmdRecordList.Example = `The following command lists the DNS records for the domain example.com. The command also uses the ` + "`" + `--format` + "`" + ` flag to only return each record's ID, type, and TTL: doctl compute domain records list example.com --format ID,Type,TTL`
 
-	cmdRecordCreate := CmdBuilder(cmdRecord, RunRecordCreate, "create <domain>", "Create a DNS record", `Create DNS records for a domain.`, Writer,
+	cmdRecordCreate := CmdBuilder(cmdRecord, RunRecordCreate, "create <domain>", "Create a DNS record", `Create DNS records for a domain. The flags, tag, and value of CAA records are checked before the record is created.`, Writer,
 		aliasOpt("c"), displayerType(&displayers.DomainRecord{}))
 	AddStringFlag(cmdRecordCreate, doctl.ArgRecordType, "", "", `The type of DNS record. Valid values are: `+"`"+`A`+"`"+`, `+"`"+`AAAA`+"`"+`, `+"`"+`CAA`+"`"+`, `+"`"+`CNAME`+"`"+`, `+"`"+`MX`+"`"+`, `+"`"+`NS`+"`"+`, `+"`"+`SOA`+"`"+`, `+"`"+`SRV`+"`"+`, and `+"`"+`TXT`+"`"+`.`)
 	AddStringFlag(cmdRecordCreate, doctl.ArgRecordName, "", "", "The host name, alias, or service being defined by the record")
@@ -79,12 +147,15 @@ func Domain() *Command {
 
 	cmdRecordCreate.Example = `The following command creates an A record for the domain example.com: doctl compute domain records create example.com --record-type A --record-name example.com --record-data 198.51.100.215`
 
-	cmdRunRecordDelete := CmdBuilder(cmdRecord, RunRecordDelete, "delete <domain> <record-id>...", "Delete a DNS record", `Deletes DNS records for a domain.`, Writer,
+	cmdRunRecordDelete := CmdBuilder(cmdRecord, RunRecordDelete, "delete <domain> <record-id>...", "Delete a DNS record", `Deletes DNS records for a domain.
+
+Progress is checkpointed when several records are deleted. If the command is interrupted or some records fail, it prints a checkpoint file to run it again with, using `+"`"+`--resume`+"`"+`, which deletes only the records that remain.`, Writer,
 		aliasOpt("d", "rm"))
 	AddBoolFlag(cmdRunRecordDelete, doctl.ArgForce, doctl.ArgShortForce, false, "Delete record without confirmation prompt")
+	AddStringFlag(cmdRunRecordDelete, doctl.ArgResume, "", "", "Resume an interrupted deletion from its checkpoint file. The record IDs are those of the interrupted run")
 	cmdRunRecordDelete.Example = `The following command deletes a DNS record with the ID ` + "`" + `98858421` + "`" + ` from the domain ` + "`" + `example.com` + "`" + `: doctl compute domain records delete example.com 98858421`
 
-	cmdRecordUpdate := CmdBuilder(cmdRecord, RunRecordUpdate, "update <domain>", "Update a DNS record", `Updates or changes the properties of DNS records for a domain.`, Writer,
+	cmdRecordUpdate := CmdBuilder(cmdRecord, RunRecordUpdate, "update <domain>", "Update a DNS record", `Updates or changes the properties of DNS records for a domain. The flags, tag, and value of CAA records are checked before the record is updated.`, Writer,
 		aliasOpt("u"), displayerType(&displayers.DomainRecord{}))
 	AddIntFlag(cmdRecordUpdate, doctl.ArgRecordID, "", 0, "The record's ID")
 	AddStringFlag(cmdRecordUpdate, doctl.ArgRecordType, "", "", "The type of DNS record")
@@ -99,6 +170,67 @@ func Domain() *Command {
 
 	cmdRecordUpdate.Example = `The following command updates the record with the ID ` + "`" + `98858421` + "`" + ` for the domain ` + "`" + `example.com` + "`" + `: doctl compute domain records update example.com --record-id 98858421 --record-name example.com --record-data 198.51.100.215`
 
+	cmdRecordHistory := CmdBuilder(cmdRecord, RunRecordHistory, "history <domain>", "List the changes doctl has made to a domain's records", `Lists the changes made to a domain's DNS records through doctl on this computer, oldest first.
+
+Every record created, updated, or deleted with doctl is written to a journal in doctl's configuration directory, along with the record's previous and new values, the time of the change, and the local user and authentication context that made it. Changes made in the control panel or with other tools are not included.`, Writer,
+		displayerType(&displayers.DNSChanges{}))
+	cmdRecordHistory.Example = `The following command lists the journaled changes to the records of example.com: doctl compute domain records history example.com`
+
+	cmdRecordRevert := CmdBuilder(cmdRecord, RunRecordRevert, "revert <change-id>", "Undo a change doctl made to a DNS record", `Undoes a journaled change to a DNS record. A created record is deleted, a deleted record is created again, and an updated record gets its previous values back. The revert itself is recorded in the journal as a new change.
+
+A record that is created again gets a new ID. Use `+"`"+`doctl compute domain records history`+"`"+` to find change IDs.`, Writer,
+		displayerType(&displayers.DNSChanges{}))
+	AddBoolFlag(cmdRecordRevert, doctl.ArgForce, doctl.ArgShortForce, false, "Revert the change without a confirmation prompt")
+	cmdRecordRevert.Example = `The following command reverts the change with the ID ` + "`" + `3f9a1c2e` + "`" + `: doctl compute domain records revert 3f9a1c2e`
+
+	cmdRecordApplyTemplate := CmdBuilder(cmdRecord, RunRecordApplyTemplate, "apply-template", "Create per-region DNS records from a template", `Creates and removes A and AAAA records so that a domain has one set of records per region for the Droplets or load balancers selected by a tag. Record names are Go templates that can use `+"`"+`{{.Region}}`+"`"+` (the region slug, e.g. `+"`"+`nyc3`+"`"+`) and `+"`"+`{{.Label}}`+"`"+` (the region's label, e.g. `+"`"+`nyc`+"`"+`). Region labels default to the region slug without its trailing digits and can be overridden in the template.
+
+A template looks like this:
+
+    domain: example.com
+    ttl: 300
+    labels:
+      ams3: eu
+    records:
+      - name: "{{.Label}}"
+        type: A
+        droplet_tag: web
+      - name: "api.{{.Label}}"
+        type: A
+        load_balancer_tag: api
+
+Records whose names the template produces for any region are managed by the template: records pointing at addresses that are no longer in use are deleted. Other records are left untouched. Every change is written to the DNS change journal.`, Writer,
+		displayerType(&displayers.RecordTemplateChanges{}))
+	AddStringFlag(cmdRecordApplyTemplate, doctl.ArgRecordTemplate, "", "", "The path to the record template", requiredOpt())
+	AddBoolFlag(cmdRecordApplyTemplate, doctl.ArgDryRun, "", false, "Show the changes without making them")
+	cmdRecordApplyTemplate.Example = `The following command shows the changes needed to sync example.com with the template in ` + "`" + `geo.yaml` + "`" + `: doctl compute domain records apply-template --template geo.yaml --dry-run`
+
+	cmdRecordSetTTL := CmdBuilder(cmdRecord, RunRecordSetTTL, "set-ttl <domain>", "Change the TTL of many DNS records at once", `Sets the Time To Live (TTL) of all of a domain's records that match `+"`"+`--type`+"`"+` and `+"`"+`--match`+"`"+`, a glob such as `+"`"+`api*`+"`"+` that is matched against record names, with `+"`"+`@`+"`"+` for the domain itself. SOA records are only changed if `+"`"+`--type SOA`+"`"+` is given. Every change is written to the DNS change journal, so it can be undone with `+"`"+`doctl compute domain records revert`+"`"+`.
+
+With `+"`"+`--audit`+"`"+`, no records are changed. Instead, the matching records with TTLs outside the recommended range are listed, with the nearest recommended TTL. The range is `+fmt.Sprint(recommendedMinTTL)+` to `+fmt.Sprint(recommendedMaxTTL)+` seconds unless `+"`"+`--min-ttl`+"`"+` or `+"`"+`--max-ttl`+"`"+` is set. Lower TTLs make resolvers query the records more often, and higher TTLs make changes take longer to be seen.`, Writer,
+		displayerType(&displayers.RecordTTLs{}))
+	AddStringSliceFlag(cmdRecordSetTTL, doctl.ArgRecordTypes, "", []string{}, "Only change records of these types, for example `A,AAAA`")
+	AddStringFlag(cmdRecordSetTTL, doctl.ArgRecordMatch, "", "", "Only change records whose names match this glob, for example `api*`")
+	AddIntFlag(cmdRecordSetTTL, doctl.ArgRecordNewTTL, "", 0, "The new TTL of the records, in seconds")
+	AddBoolFlag(cmdRecordSetTTL, doctl.ArgDryRun, "", false, "Show the changes without making them")
+	AddBoolFlag(cmdRecordSetTTL, doctl.ArgRecordTTLAudit, "", false, "List the records with TTLs outside the recommended range instead of changing them")
+	AddIntFlag(cmdRecordSetTTL, doctl.ArgRecordMinTTL, "", recommendedMinTTL, "The lowest recommended TTL for `--audit`, in seconds")
+	AddIntFlag(cmdRecordSetTTL, doctl.ArgRecordMaxTTL, "", recommendedMaxTTL, "The highest recommended TTL for `--audit`, in seconds")
+	cmdRecordSetTTL.Example = `The following command sets the TTL of the A records of example.com whose names start with ` + "`" + `api` + "`" + ` to 300 seconds: doctl compute domain records set-ttl example.com --type A --match 'api*' --ttl 300`
+
+	cmdRecordRetarget := CmdBuilder(cmdRecord, RunRecordRetarget, "retarget", "Point the records of a failed server at another address", `Finds the A or AAAA records that point at `+"`"+`--from-ip`+"`"+`, such as the address of a failed Droplet, in all of your domains or in the `+"`"+`--domains`+"`"+` given, and points them at `+"`"+`--to-ip`+"`"+` instead, or deletes them with `+"`"+`--delete`+"`"+`. Other records are not changed.
+
+The records of every domain are looked up before any record is changed, and `+"`"+`--dry-run`+"`"+` lists the records without changing them. Up to `+"`"+`--concurrency`+"`"+` domains are looked up, and records changed, at the same time. Every change is written to the DNS change journal, so it can be undone with `+"`"+`doctl compute domain records revert`+"`"+`. The command exits with a non-zero status if any record could not be changed.`, Writer,
+		displayerType(&displayers.RecordRetargets{}))
+	AddStringFlag(cmdRecordRetarget, doctl.ArgRetargetFromIP, "", "", "The address of the records to change", requiredOpt())
+	AddStringFlag(cmdRecordRetarget, doctl.ArgRetargetToIP, "", "", "The address to point the records at")
+	AddBoolFlag(cmdRecordRetarget, doctl.ArgRetargetDelete, "", false, "Delete the records instead of pointing them at another address")
+	AddStringSliceFlag(cmdRecordRetarget, doctl.ArgRetargetDomains, "", []string{"all"}, "The domains to change records in, or `all`")
+	AddBoolFlag(cmdRecordRetarget, doctl.ArgDryRun, "", false, "List the records without changing them")
+	AddIntFlag(cmdRecordRetarget, doctl.ArgExecConcurrency, "", 5, "The number of domains to look up, or records to change, at the same time")
+	AddBoolFlag(cmdRecordRetarget, doctl.ArgForce, doctl.ArgShortForce, false, "Delete the records without a confirmation prompt")
+	cmdRecordRetarget.Example = `The following command points all records in all domains that point at 198.51.100.7 at 198.51.100.8: doctl compute domain records retarget --from-ip 198.51.100.7 --to-ip 198.51.100.8`
+
 	return cmd
 }
 
@@ -116,6 +248,34 @@ func RunDomainCreate(c *CmdConfig) error {
 	if err != nil {
 		return err
 	}
+	bootstrap, err := c.Doit.GetString(c.NS, doctl.ArgDomainBootstrap)
+	if err != nil {
+		return err
+	}
+	www, err := c.Doit.GetBool(c.NS, doctl.ArgDomainWithWWW)
+	if err != nil {
+		return err
+	}
+	mx, err := c.Doit.GetString(c.NS, doctl.ArgDomainWithMX)
+	if err != nil {
+		return err
+	}
+	if ipAddress != "" && bootstrap != "" {
+		return fmt.Errorf("Only one of `--%s` or `--%s` may be specified.", doctl.ArgIPAddress, doctl.ArgDomainBootstrap)
+	}
+
+	// The records are worked out before the domain is created, so that a
+	// mistake doesn't leave a domain without them.
+	var addrs map[string]string
+	if bootstrap != "" {
+		if addrs, err = bootstrapAddresses(c, bootstrap); err != nil {
+			return err
+		}
+	}
+	records, err := bootstrapRecords(domainName, addrs, www, mx)
+	if err != nil {
+		return err
+	}
 
 	req := &godo.DomainCreateRequest{
 		Name:      domainName,
@@ -127,6 +287,17 @@ func RunDomainCreate(c *CmdConfig) error {
 		return err
 	}
 
+	for _, rec := range records {
+		r, err := ds.CreateRecord(domainName, rec)
+		if err != nil {
+			return fmt.Errorf("domain %s was created, but creating its %s record for %s failed: %w", domainName, rec.Type, rec.Data, err)
+		}
+		recordDNSChange(domainName, dnsChangeCreate, nil, r.DomainRecord)
+	}
+	if len(records) > 0 {
+		notice("Created %d records in %s", len(records), domainName)
+	}
+
 	return c.Display(&displayers.Domain{Domains: do.Domains{*d}})
 }
 
@@ -180,18 +351,23 @@ func RunDomainDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if force || AskForConfirmDelete("domain", 1) == nil {
-		ds := c.Domains()
+	if len(name) < 1 {
+		return errors.New("Invalid domain name.")
+	}
 
-		if len(name) < 1 {
-			return errors.New("Invalid domain name.")
+	ds := c.Domains()
+	if !force {
+		records, err := ds.Records(name)
+		if err != nil {
+			return err
+		}
+		target := confirmTarget{Name: name, Details: fmt.Sprintf("%d records, which stop resolving", len(records))}
+		if AskForConfirmDeleteTyped("domain", target) != nil {
+			return errOperationAborted
 		}
-
-		err := ds.Delete(name)
-		return err
 	}
 
-	return errOperationAborted
+	return ds.Delete(name)
 }
 
 // RunRecordList list records for a domain.
@@ -286,11 +462,18 @@ func RunRecordCreate(c *CmdConfig) error {
 	if len(drcr.Type) == 0 {
 		return errors.New("Record request is missing type.")
 	}
+	if strings.EqualFold(drcr.Type, "CAA") {
+		drcr.Tag, err = validateCAARecord(drcr.Flags, drcr.Tag, drcr.Data)
+		if err != nil {
+			return err
+		}
+	}
 
 	r, err := ds.CreateRecord(name, drcr)
 	if err != nil {
 		return err
 	}
+	recordDNSChange(name, dnsChangeCreate, nil, r.DomainRecord)
 
 	return displayDomainRecords(c, *r)
 
@@ -298,7 +481,7 @@ func RunRecordCreate(c *CmdConfig) error {
 
 // RunRecordDelete deletes a domain record.
 func RunRecordDelete(c *CmdConfig) error {
-	if len(c.Args) < 2 {
+	if len(c.Args) < 1 {
 		return doctl.NewMissingArgsErr(c.NS)
 	}
 
@@ -308,30 +491,42 @@ func RunRecordDelete(c *CmdConfig) error {
 	}
 
 	domainName, ids := c.Args[0], c.Args[1:]
-	if len(ids) < 1 {
+	for _, i := range ids {
+		if _, err := strconv.Atoi(i); err != nil {
+			return fmt.Errorf("Invalid record id %q", i)
+		}
+	}
+
+	e, err := newBulkExecutor(c, recordDeleteOperation+" "+domainName, ids)
+	if err != nil {
+		return err
+	}
+	remaining := e.Remaining()
+	if len(remaining) < 1 {
 		return doctl.NewMissingArgsErr(c.NS)
 	}
 
-	if force || AskForConfirmDelete("domain record", len(ids)) == nil {
-		ds := c.Domains()
+	if !force && AskForConfirmDelete("domain record", len(remaining), remaining...) != nil {
+		return errOperationAborted
+	}
 
-		for _, i := range ids {
-			id, err := strconv.Atoi(i)
+	ds := c.Domains()
+	return runBulk(e, func(ctx context.Context) error {
+		return e.Run(ctx, func(item string) (string, error) {
+			id, err := strconv.Atoi(item)
 			if err != nil {
-				return fmt.Errorf("Invalid record id %q", i)
+				return "", fmt.Errorf("Invalid record id %q", item)
 			}
 
+			old := journaledRecord(ds, domainName, id)
 			err = ds.DeleteRecord(domainName, id)
 			if err != nil {
-				return err
+				return "", err
 			}
-		}
-	} else {
-		return errOperationAborted
-	}
-
-	return nil
-
+			recordDNSChange(domainName, dnsChangeDelete, old, nil)
+			return "", nil
+		})
+	})
 }
 
 // RunRecordUpdate updates a domain record.
@@ -406,10 +601,34 @@ func RunRecordUpdate(c *CmdConfig) error {
 		Tag:      rTag,
 	}
 
+	old := journaledRecord(ds, domainName, recordID)
+	if strings.EqualFold(drcr.Type, "CAA") || (drcr.Type == "" && old != nil && old.Type == "CAA") {
+		// The tag and value are checked together, so missing ones are
+		// taken from the record. If it couldn't be fetched, only a
+		// complete tag and value are checked, and the API checks the rest.
+		tag, data := drcr.Tag, drcr.Data
+		if old != nil && tag == "" {
+			tag = old.Tag
+		}
+		if old != nil && data == "" {
+			data = old.Data
+		}
+		if tag != "" && data != "" {
+			tag, err = validateCAARecord(drcr.Flags, tag, data)
+		}
+		if err != nil {
+			return err
+		}
+		if drcr.Tag != "" {
+			drcr.Tag = tag
+		}
+	}
+
 	r, err := ds.EditRecord(domainName, recordID, drcr)
 	if err != nil {
 		return err
 	}
+	recordDNSChange(domainName, dnsChangeUpdate, old, r.DomainRecord)
 
 	return displayDomainRecords(c, *r)
 }
@@ -430,3 +649,104 @@ func displayDomainRecords(c *CmdConfig, records ...do.DomainRecord) error {
 	item := &displayers.DomainRecord{DomainRecords: do.DomainRecords(records), Short: short}
 	return c.Display(item)
 }
+
+// RunDomainVerify checks a domain's delegation to DigitalOcean's nameservers.
+func RunDomainVerify(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	name := strings.ToLower(strings.TrimSuffix(c.Args[0], "."))
+
+	ds := c.Domains()
+	if _, err := ds.Get(name); err != nil {
+		return err
+	}
+
+	records, err := ds.Records(name)
+	if err != nil {
+		return err
+	}
+	zone := map[string]bool{}
+	for _, r := range records {
+		if r.Type == "NS" && r.Name == "@" {
+			zone[normalizeHost(r.Data)] = true
+		}
+	}
+
+	var problems []string
+
+	delegated := map[string]bool{}
+	hosts, err := lookupNS(name)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("no nameservers found for %s in public DNS: %v", name, err))
+	}
+	for _, h := range hosts {
+		delegated[normalizeHost(h)] = true
+	}
+
+	isDO := map[string]bool{}
+	for _, ns := range digitalOceanNameservers {
+		isDO[ns] = true
+	}
+
+	all := map[string]bool{}
+	for _, set := range []map[string]bool{delegated, zone, isDO} {
+		for ns := range set {
+			all[ns] = true
+		}
+	}
+	names := make([]string, 0, len(all))
+	for ns := range all {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+
+	nameservers := make([]displayers.DomainNameserver, 0, len(names))
+	for _, ns := range names {
+		nameservers = append(nameservers, displayers.DomainNameserver{
+			Nameserver:   ns,
+			Delegated:    delegated[ns],
+			InZone:       zone[ns],
+			DigitalOcean: isDO[ns],
+		})
+
+		switch {
+		case isDO[ns] && !delegated[ns] && len(delegated) > 0:
+			problems = append(problems, fmt.Sprintf("%s is not listed at the registrar", ns))
+		case delegated[ns] && !isDO[ns]:
+			problems = append(problems, fmt.Sprintf("%s is listed at the registrar but is not a DigitalOcean nameserver", ns))
+		}
+		if delegated[ns] != zone[ns] && len(delegated) > 0 {
+			if zone[ns] {
+				problems = append(problems, fmt.Sprintf("%s has an NS record in the zone but is not delegated by the parent", ns))
+			} else {
+				problems = append(problems, fmt.Sprintf("%s is delegated by the parent but has no NS record in the zone", ns))
+			}
+		}
+		if delegated[ns] && strings.HasSuffix(ns, "."+name) {
+			if _, err := lookupHost(ns); err != nil {
+				problems = append(problems, fmt.Sprintf("%s is inside %s and needs glue records at the registrar", ns, name))
+			}
+		}
+	}
+
+	if err := c.Display(&displayers.DomainDelegation{Nameservers: nameservers}); err != nil {
+		return err
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	for _, p := range problems {
+		warn("%s", p)
+	}
+	notice("To use DigitalOcean DNS for %s, set exactly these nameservers at your registrar:\n  %s",
+		name, strings.Join(digitalOceanNameservers, "\n  "))
+	return ErrExitSilently
+}
+
+func normalizeHost(h string) string {
+	return strings.ToLower(strings.TrimSuffix(h, "."))
+}
@@ -14,12 +14,15 @@ limitations under the License.
 package commands
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/do"
 	"github.com/digitalocean/godo"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -34,7 +37,7 @@ var (
 func TestDomainsCommand(t *testing.T) {
 	cmd := Domain()
 	assert.NotNil(t, cmd)
-	assertCommandNames(t, cmd, "create", "list", "get", "delete", "records")
+	assertCommandNames(t, cmd, "caa", "create", "list", "get", "delete", "email-auth", "import", "record-set", "records", "report", "srv", "verify")
 }
 
 func TestDomainsCreate(t *testing.T) {
@@ -140,6 +143,7 @@ func TestRecordCreate_RequiredArguments(t *testing.T) {
 
 func TestRecordsDelete(t *testing.T) {
 	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.domains.EXPECT().Record("example.com", 1).Return(&testRecord, nil)
 		tm.domains.EXPECT().DeleteRecord("example.com", 1).Return(nil)
 
 		config.Args = append(config.Args, "example.com", "1")
@@ -151,10 +155,32 @@ func TestRecordsDelete(t *testing.T) {
 	})
 }
 
+func TestRecordsDeleteUnfetchable(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.domains.EXPECT().Record("example.com", 1).Return(nil, errors.New("method not allowed"))
+		tm.domains.EXPECT().DeleteRecord("example.com", 1).Return(nil)
+
+		config.Args = append(config.Args, "example.com", "1")
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+		require.NoError(t, RunRecordDelete(config))
+
+		// The change is journaled without the old record, so it can't be
+		// reverted.
+		changes, err := readDNSChanges()
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		assert.Nil(t, changes[0].Old)
+
+		config.Args = []string{changes[0].ID}
+		assert.ErrorContains(t, RunRecordRevert(config), "can't be reverted")
+	})
+}
+
 func TestRecordsUpdate(t *testing.T) {
 	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
 		port := 0
 		dcer := &do.DomainRecordEditRequest{Type: "A", Name: "foo.example.com.", Data: "192.168.1.1", Priority: 0, Port: &port, TTL: 0, Weight: 0}
+		tm.domains.EXPECT().Record("example.com", 1).Return(&testRecord, nil)
 		tm.domains.EXPECT().EditRecord("example.com", 1, dcer).Return(&testRecord, nil)
 
 		config.Doit.Set(config.NS, doctl.ArgRecordID, 1)
@@ -169,3 +195,86 @@ func TestRecordsUpdate(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func withNSLookups(t *testing.T, ns []string, nsErr error) {
+	origNS, origHost := lookupNS, lookupHost
+	lookupNS = func(string) ([]string, error) { return ns, nsErr }
+	lookupHost = func(string) ([]string, error) { return nil, errors.New("no such host") }
+	t.Cleanup(func() { lookupNS, lookupHost = origNS, origHost })
+}
+
+func TestDomainVerify(t *testing.T) {
+	zoneNS := do.DomainRecords{
+		{DomainRecord: &godo.DomainRecord{Type: "NS", Name: "@", Data: "ns1.digitalocean.com"}},
+		{DomainRecord: &godo.DomainRecord{Type: "NS", Name: "@", Data: "ns2.digitalocean.com"}},
+		{DomainRecord: &godo.DomainRecord{Type: "NS", Name: "@", Data: "ns3.digitalocean.com"}},
+	}
+
+	t.Run("delegated", func(t *testing.T) {
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			withNSLookups(t, []string{"ns1.digitalocean.com.", "NS2.digitalocean.com.", "ns3.digitalocean.com."}, nil)
+			tm.domains.EXPECT().Get("example.com").Return(&testDomain, nil)
+			tm.domains.EXPECT().Records("example.com").Return(zoneNS, nil)
+
+			var buf bytes.Buffer
+			config.Out = &buf
+			config.Args = append(config.Args, "example.com")
+
+			err := RunDomainVerify(config)
+			assert.NoError(t, err)
+			assert.Contains(t, buf.String(), "ns2.digitalocean.com")
+		})
+	})
+
+	t.Run("registrar mismatch", func(t *testing.T) {
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			withNSLookups(t, []string{"ns1.digitalocean.com.", "ns1.example.com."}, nil)
+			tm.domains.EXPECT().Get("example.com").Return(&testDomain, nil)
+			tm.domains.EXPECT().Records("example.com").Return(zoneNS, nil)
+
+			var buf bytes.Buffer
+			config.Out = &buf
+			config.Args = append(config.Args, "example.com")
+
+			err := RunDomainVerify(config)
+			assert.ErrorIs(t, err, ErrExitSilently)
+			assert.Contains(t, buf.String(), "ns1.example.com")
+		})
+	})
+}
+
+func TestRecordsHistoryAndRevert(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		old := &godo.DomainRecord{ID: 7, Type: "A", Name: "www", Data: "192.0.2.1", TTL: 1800}
+		updated := &godo.DomainRecord{ID: 7, Type: "A", Name: "www", Data: "192.0.2.2", TTL: 1800}
+		recordDNSChange("example.com", dnsChangeUpdate, old, updated)
+		recordDNSChange("example.org", dnsChangeDelete, old, nil)
+
+		changes, err := readDNSChanges()
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		assert.Equal(t, "192.0.2.1", changes[0].Old.Data)
+		assert.NotEmpty(t, changes[0].Actor)
+
+		var buf bytes.Buffer
+		config.Out = &buf
+		config.Args = []string{"example.com"}
+		require.NoError(t, RunRecordHistory(config))
+		assert.Contains(t, buf.String(), changes[0].ID)
+		assert.NotContains(t, buf.String(), changes[1].ID)
+
+		port := 0
+		tm.domains.EXPECT().Record("example.com", 7).Return(&do.DomainRecord{DomainRecord: updated}, nil)
+		tm.domains.EXPECT().EditRecord("example.com", 7, &do.DomainRecordEditRequest{
+			Type: "A", Name: "www", Data: "192.0.2.1", Port: &port, TTL: 1800,
+		}).Return(&do.DomainRecord{DomainRecord: old}, nil)
+
+		config.Args = []string{changes[0].ID}
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+		require.NoError(t, RunRecordRevert(config))
+
+		changes, err = readDNSChanges()
+		require.NoError(t, err)
+		assert.Len(t, changes, 3)
+	})
+}
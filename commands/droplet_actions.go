@@ -14,6 +14,8 @@ limitations under the License.
 package commands
 
 import (
+	"strconv"
+
 	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/commands/displayers"
 	"github.com/digitalocean/doctl/do"
@@ -47,6 +49,8 @@ func performAction(c *CmdConfig, fn actionFn) error {
 
 	}
 
+	c.addNotifyDetail("Action", strconv.Itoa(a.ID))
+	c.addNotifyDetail("Status", a.Status)
 	item := &displayers.Action{Actions: do.Actions{*a}}
 	return c.Display(item)
 }
@@ -149,7 +153,7 @@ Once you have manually enabled private networking for a Droplet, the Droplet req
 		"restore <droplet-id>", "Restore a Droplet from a backup", `Restores a Droplet from a backup image. You must pass an image ID that is a backup of the current Droplet instance. The operation leaves any embedded SSH keys intact.
 		
 		To retrieve a list of backup images, use the `+"`"+`doctl compute image list`+"`"+` command.`, Writer,
-		displayerType(&displayers.Action{}))
+		displayerType(&displayers.Action{}), notifyOpt())
 	AddIntFlag(cmdDropletActionRestore, doctl.ArgImageID, "", 0, "The ID of the image to restore the Droplet from", requiredOpt())
 	AddBoolFlag(cmdDropletActionRestore, doctl.ArgCommandWait, "", false, "Instruct the terminal to wait for the action to complete before returning access to the user")
 	cmdDropletActionRestore.Example = `The following example restores a Droplet with the ID ` + "`" + `386734086` + "`" + ` from a backup image with the ID ` + "`" + `146288445` + "`" + `: doctl compute droplet-action restore 386734086 --image-id 146288445`
@@ -165,7 +169,7 @@ For a list of size slugs, use the ` + "`" + `doctl compute size list` + "`" + `
 This command automatically powers off the Droplet before resizing it.`
 	cmdDropletActionResize := CmdBuilder(cmd, RunDropletActionResize,
 		"resize <droplet-id>", "Resize a Droplet", dropletResizeDesc, Writer,
-		displayerType(&displayers.Action{}))
+		displayerType(&displayers.Action{}), notifyOpt())
 	AddBoolFlag(cmdDropletActionResize, doctl.ArgResizeDisk, "", false, "Resize the Droplet's disk size in addition to its RAM and CPUs")
 	AddStringFlag(cmdDropletActionResize, doctl.ArgSizeSlug, "", "", "A slug indicating the new size for the Droplet, for example `s-2vcpu-2gb`. Run `doctl compute size list` for a list of valid sizes.", requiredOpt())
 	AddBoolFlag(cmdDropletActionResize, doctl.ArgCommandWait, "", false, "Instruct the terminal to wait for the action to complete before returning access to the user")
@@ -175,7 +179,7 @@ This command automatically powers off the Droplet before resizing it.`
 		"rebuild <droplet-id>", "Rebuild a Droplet", `Rebuilds a Droplet from an image, such as an Ubuntu base image or a backup image of the Droplet. Set the image attribute to an image ID or slug.
 
 To retrieve a list of images on your account, use the `+"`"+`doctl compute image list`+"`"+` command. To retrieve a list of base images, use the `+"`"+`doctl compute image list-distribution`+"`"+` command.`, Writer,
-		displayerType(&displayers.Action{}))
+		displayerType(&displayers.Action{}), notifyOpt())
 	AddStringFlag(cmdDropletActionRebuild, doctl.ArgImage, "", "", "An image ID or slug", requiredOpt())
 	AddBoolFlag(cmdDropletActionRebuild, doctl.ArgCommandWait, "", false, "Instruct the terminal to wait for the action to complete before returning access to the user")
 	cmdDropletActionRebuild.Example = `The following example rebuilds a Droplet with the ID ` + "`" + `386734086` + "`" + ` from the image with the ID ` + "`" + `146288445` + "`" + `: doctl compute droplet-action rebuild 386734086 --image 146288445`
@@ -0,0 +1,193 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+)
+
+// decommissionSleep waits for connections to drain. It is replaced for testing.
+var decommissionSleep = time.Sleep
+
+// RunDropletDecommission takes a Droplet out of service and then deletes it.
+func RunDropletDecommission(c *CmdConfig) error {
+	if len(c.Args) != 1 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	drain, err := c.Doit.GetDuration(c.NS, doctl.ArgDropletDrainTimeout)
+	if err != nil {
+		return err
+	}
+	snapshot, err := c.Doit.GetBool(c.NS, doctl.ArgDropletSnapshot)
+	if err != nil {
+		return err
+	}
+	snapshotName, err := c.Doit.GetString(c.NS, doctl.ArgSnapshotName)
+	if err != nil {
+		return err
+	}
+	force, err := c.Doit.GetBool(c.NS, doctl.ArgForce)
+	if err != nil {
+		return err
+	}
+
+	ds := c.Droplets()
+	var id int
+	err = matchDroplets(c.Args, ds, func(ids []int) error {
+		if len(ids) != 1 {
+			return fmt.Errorf("%q matches %d Droplets; use the Droplet's ID instead", c.Args[0], len(ids))
+		}
+		id = ids[0]
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	d, err := ds.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if !force && AskForConfirm(fmt.Sprintf("decommission and permanently delete Droplet %s (%d)?", d.Name, d.ID)) != nil {
+		return errOperationAborted
+	}
+
+	removed, err := removeDropletFromLoadBalancers(c, *d)
+	if err != nil {
+		return err
+	}
+	if removed > 0 && drain > 0 {
+		notice("Waiting %s for connections to drain", drain)
+		decommissionSleep(drain)
+	}
+
+	if err := unassignDropletReservedIPs(c, d.ID); err != nil {
+		return err
+	}
+
+	for _, volumeID := range d.VolumeIDs {
+		a, err := c.VolumeActions().Detach(volumeID, d.ID)
+		if err != nil {
+			return fmt.Errorf("detaching volume %s: %w", volumeID, err)
+		}
+		if _, err := actionWait(c, a.ID, 5); err != nil {
+			return err
+		}
+		notice("Detached volume %s", volumeID)
+	}
+
+	if snapshot {
+		if snapshotName == "" {
+			snapshotName = d.Name + "-decommissioned"
+		}
+		a, err := c.DropletActions().Snapshot(d.ID, snapshotName)
+		if err != nil {
+			return err
+		}
+		notice("Taking snapshot %s", snapshotName)
+		a, err = actionWait(c, a.ID, 5)
+		if err != nil {
+			return err
+		}
+		if a.Status != godo.ActionCompleted {
+			return fmt.Errorf("snapshot %s did not complete (status %s); Droplet %d was not destroyed", snapshotName, a.Status, d.ID)
+		}
+	}
+
+	deleted, err := deleteDropletAddressRecords(c, *d)
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		notice("Deleted %d DNS record(s) pointing to the Droplet", deleted)
+	}
+
+	if err := ds.Delete(d.ID); err != nil {
+		return fmt.Errorf("Unable to delete Droplet %d: %v", d.ID, err)
+	}
+	notice("Droplet %s (%d) was decommissioned", d.Name, d.ID)
+	return nil
+}
+
+// removeDropletFromLoadBalancers removes a Droplet from every load balancer
+// that sends it traffic and returns how many there were. Load balancers that
+// select Droplets by tag are left alone; the tag is removed from the Droplet instead.
+func removeDropletFromLoadBalancers(c *CmdConfig, d do.Droplet) (int, error) {
+	lbs, err := c.LoadBalancers().List()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, lb := range lbs {
+		if lb.Tag != "" {
+			if !contains(d.Tags, lb.Tag) {
+				continue
+			}
+			err := c.Tags().UntagResources(lb.Tag, &godo.UntagResourcesRequest{
+				Resources: []godo.Resource{{ID: fmt.Sprint(d.ID), Type: godo.DropletResourceType}},
+			})
+			if err != nil {
+				return removed, fmt.Errorf("removing tag %s for load balancer %s: %w", lb.Tag, lb.Name, err)
+			}
+			notice("Removed tag %s so that load balancer %s stops sending traffic", lb.Tag, lb.Name)
+			removed++
+			continue
+		}
+
+		for _, dropletID := range lb.DropletIDs {
+			if dropletID != d.ID {
+				continue
+			}
+			if err := c.LoadBalancers().RemoveDroplets(lb.ID, d.ID); err != nil {
+				return removed, fmt.Errorf("removing Droplet from load balancer %s: %w", lb.Name, err)
+			}
+			notice("Removed Droplet from load balancer %s", lb.Name)
+			removed++
+			break
+		}
+	}
+	return removed, nil
+}
+
+// unassignDropletReservedIPs unassigns the reserved IPs assigned to a Droplet,
+// keeping them on the account.
+func unassignDropletReservedIPs(c *CmdConfig, dropletID int) error {
+	ips, err := c.ReservedIPs().List()
+	if err != nil {
+		return err
+	}
+
+	for _, ip := range ips {
+		if ip.Droplet == nil || ip.Droplet.ID != dropletID {
+			continue
+		}
+		a, err := c.ReservedIPActions().Unassign(ip.IP)
+		if err != nil {
+			return fmt.Errorf("unassigning reserved IP %s: %w", ip.IP, err)
+		}
+		if _, err := actionWait(c, a.ID, 5); err != nil {
+			return err
+		}
+		notice("Unassigned reserved IP %s", ip.IP)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDropletDecommission(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var slept time.Duration
+		decommissionSleep = func(d time.Duration) { slept = d }
+		defer func() { decommissionSleep = time.Sleep }()
+
+		d := do.Droplet{Droplet: &godo.Droplet{
+			ID:        1,
+			Name:      "web-1",
+			Tags:      []string{"web"},
+			VolumeIDs: []string{"vol-1"},
+			Networks: &godo.Networks{
+				V4: []godo.NetworkV4{{IPAddress: "203.0.113.10", Type: "public"}},
+			},
+		}}
+		completed := &do.Action{Action: &godo.Action{ID: 7, Status: godo.ActionCompleted}}
+
+		tm.droplets.EXPECT().Get(1).Return(&d, nil)
+		tm.loadBalancers.EXPECT().List().Return(do.LoadBalancers{
+			{LoadBalancer: &godo.LoadBalancer{ID: "lb-ids", Name: "by-ids", DropletIDs: []int{1, 2}}},
+			{LoadBalancer: &godo.LoadBalancer{ID: "lb-tag", Name: "by-tag", Tag: "web"}},
+			{LoadBalancer: &godo.LoadBalancer{ID: "lb-other", Name: "other", DropletIDs: []int{2}}},
+		}, nil)
+		tm.loadBalancers.EXPECT().RemoveDroplets("lb-ids", 1).Return(nil)
+		tm.tags.EXPECT().UntagResources("web", &godo.UntagResourcesRequest{
+			Resources: []godo.Resource{{ID: "1", Type: godo.DropletResourceType}},
+		}).Return(nil)
+		tm.reservedIPs.EXPECT().List().Return(do.ReservedIPs{
+			{ReservedIP: &godo.ReservedIP{IP: "198.51.100.1", Droplet: &godo.Droplet{ID: 1}}},
+			{ReservedIP: &godo.ReservedIP{IP: "198.51.100.2"}},
+		}, nil)
+		tm.reservedIPActions.EXPECT().Unassign("198.51.100.1").Return(completed, nil)
+		tm.volumeActions.EXPECT().Detach("vol-1", 1).Return(completed, nil)
+		tm.dropletActions.EXPECT().Snapshot(1, "web-1-decommissioned").Return(completed, nil)
+		tm.actions.EXPECT().Get(7).Return(completed, nil).Times(3)
+		tm.domains.EXPECT().List().Return(do.Domains{{Domain: &godo.Domain{Name: "example.com"}}}, nil)
+		tm.domains.EXPECT().Records("example.com").Return(do.DomainRecords{
+			{DomainRecord: &godo.DomainRecord{ID: 10, Type: "A", Name: "www", Data: "203.0.113.10"}},
+			{DomainRecord: &godo.DomainRecord{ID: 11, Type: "A", Name: "api", Data: "203.0.113.99"}},
+		}, nil)
+		tm.domains.EXPECT().DeleteRecord("example.com", 10).Return(nil)
+		tm.droplets.EXPECT().Delete(1).Return(nil)
+
+		config.Args = append(config.Args, "1")
+		config.Doit.Set(config.NS, doctl.ArgDropletDrainTimeout, time.Minute)
+		config.Doit.Set(config.NS, doctl.ArgDropletSnapshot, true)
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+
+		err := RunDropletDecommission(config)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Minute, slept)
+	})
+}
+
+func TestDropletDecommissionStopsOnFailedSnapshot(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		d := do.Droplet{Droplet: &godo.Droplet{ID: 1, Name: "web-1"}}
+
+		tm.droplets.EXPECT().Get(1).Return(&d, nil)
+		tm.loadBalancers.EXPECT().List().Return(nil, nil)
+		tm.reservedIPs.EXPECT().List().Return(nil, nil)
+		tm.dropletActions.EXPECT().Snapshot(1, "final").Return(&do.Action{Action: &godo.Action{ID: 7}}, nil)
+		tm.actions.EXPECT().Get(7).Return(&do.Action{Action: &godo.Action{ID: 7, Status: "errored"}}, nil)
+
+		config.Args = append(config.Args, "1")
+		config.Doit.Set(config.NS, doctl.ArgDropletSnapshot, true)
+		config.Doit.Set(config.NS, doctl.ArgSnapshotName, "final")
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+
+		err := RunDropletDecommission(config)
+		assert.ErrorContains(t, err, "was not destroyed")
+	})
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/digitalocean/doctl/do"
+)
+
+// dropletRecordName returns the name of a Droplet's records relative to domain.
+// A Droplet named after a host in the domain, e.g. web1.example.com, gets the
+// record web1 rather than web1.example.com.example.com.
+func dropletRecordName(dropletName, domain string) string {
+	name := strings.TrimSuffix(strings.ToLower(dropletName), ".")
+	domain = strings.ToLower(domain)
+	if name == domain {
+		return "@"
+	}
+	return strings.TrimSuffix(name, "."+domain)
+}
+
+// dropletAddresses returns the public addresses of a Droplet keyed by the
+// type of record that points to them.
+func dropletAddresses(d do.Droplet) map[string]string {
+	addrs := map[string]string{}
+	if d.Networks == nil {
+		return addrs
+	}
+	if ip, err := d.PublicIPv4(); err == nil && ip != "" {
+		addrs["A"] = ip
+	}
+	if ip, err := d.PublicIPv6(); err == nil && ip != "" {
+		addrs["AAAA"] = ip
+	}
+	return addrs
+}
+
+// createDropletDNSRecords creates A and AAAA records in domain for a Droplet's public addresses.
+func createDropletDNSRecords(c *CmdConfig, domain string, d do.Droplet) error {
+	addrs := dropletAddresses(d)
+	if len(addrs) == 0 {
+		warn("Droplet %d has no public addresses; no DNS records were created", d.ID)
+		return nil
+	}
+
+	ds := c.Domains()
+	name := dropletRecordName(d.Name, domain)
+	for _, typ := range []string{"A", "AAAA"} {
+		ip, ok := addrs[typ]
+		if !ok {
+			continue
+		}
+		r, err := ds.CreateRecord(domain, &do.DomainRecordEditRequest{Type: typ, Name: name, Data: ip})
+		if err != nil {
+			return fmt.Errorf("creating %s record for Droplet %d: %w", typ, d.ID, err)
+		}
+		recordDNSChange(domain, dnsChangeCreate, nil, r.DomainRecord)
+	}
+	return nil
+}
+
+// deleteDropletDNSRecords deletes the A and AAAA records, in any of the account's
+// domains, that are named after one of the Droplets and point to its addresses.
+func deleteDropletDNSRecords(c *CmdConfig, droplets do.Droplets) error {
+	if len(droplets) == 0 {
+		return nil
+	}
+
+	ds := c.Domains()
+	domains, err := ds.List()
+	if err != nil {
+		return err
+	}
+
+	for _, domain := range domains {
+		records, err := ds.Records(domain.Name)
+		if err != nil {
+			return err
+		}
+
+		for _, r := range records {
+			for _, d := range droplets {
+				if r.Name != dropletRecordName(d.Name, domain.Name) || dropletAddresses(d)[r.Type] != r.Data {
+					continue
+				}
+				if err := ds.DeleteRecord(domain.Name, r.ID); err != nil {
+					return fmt.Errorf("deleting %s record %s.%s: %w", r.Type, r.Name, domain.Name, err)
+				}
+				recordDNSChange(domain.Name, dnsChangeDelete, r.DomainRecord, nil)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// deleteDropletAddressRecords deletes the A and AAAA records, in any of the
+// account's domains and under any name, that point to a Droplet's public addresses.
+func deleteDropletAddressRecords(c *CmdConfig, d do.Droplet) (int, error) {
+	addrs := dropletAddresses(d)
+	if len(addrs) == 0 {
+		return 0, nil
+	}
+
+	ds := c.Domains()
+	domains, err := ds.List()
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, domain := range domains {
+		records, err := ds.Records(domain.Name)
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, r := range records {
+			if ip, ok := addrs[r.Type]; !ok || ip != r.Data {
+				continue
+			}
+			if err := ds.DeleteRecord(domain.Name, r.ID); err != nil {
+				return deleted, fmt.Errorf("deleting %s record %s.%s: %w", r.Type, r.Name, domain.Name, err)
+			}
+			recordDNSChange(domain.Name, dnsChangeDelete, r.DomainRecord, nil)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
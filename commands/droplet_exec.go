@@ -0,0 +1,197 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/doctl/pkg/ssh"
+)
+
+// execStderr receives the prefixed error output of droplet exec. It is replaced for testing.
+var execStderr io.Writer = os.Stderr
+
+// RunDropletExec runs a command over SSH on every Droplet with one of the given tags.
+func RunDropletExec(c *CmdConfig) error {
+	if len(c.Args) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	command := strings.Join(c.Args, " ")
+
+	tags, err := c.Doit.GetStringSlice(c.NS, doctl.ArgTag)
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return fmt.Errorf("at least one --%s is required", doctl.ArgTag)
+	}
+
+	user, err := c.Doit.GetString(c.NS, doctl.ArgSSHUser)
+	if err != nil {
+		return err
+	}
+	keyPath, err := c.Doit.GetString(c.NS, doctl.ArgsSSHKeyPath)
+	if err != nil {
+		return err
+	}
+	port, err := c.Doit.GetInt(c.NS, doctl.ArgsSSHPort)
+	if err != nil {
+		return err
+	}
+	privateIP, err := c.Doit.GetBool(c.NS, doctl.ArgsSSHPrivateIP)
+	if err != nil {
+		return err
+	}
+	concurrency, err := c.Doit.GetInt(c.NS, doctl.ArgExecConcurrency)
+	if err != nil {
+		return err
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var droplets do.Droplets
+	seen := map[int]bool{}
+	for _, tag := range tags {
+		list, err := c.Droplets().ListByTag(tag)
+		if err != nil {
+			return err
+		}
+		for _, d := range list {
+			if !seen[d.ID] {
+				seen[d.ID] = true
+				droplets = append(droplets, d)
+			}
+		}
+	}
+	if len(droplets) == 0 {
+		return fmt.Errorf("no Droplets are tagged %s", strings.Join(tags, " or "))
+	}
+
+	width := 0
+	for _, d := range droplets {
+		if len(d.Name) > width {
+			width = len(d.Name)
+		}
+	}
+
+	var (
+		outMu  sync.Mutex
+		wg     sync.WaitGroup
+		failMu sync.Mutex
+		failed []string
+	)
+	sem := make(chan struct{}, concurrency)
+
+	for _, d := range droplets {
+		wg.Add(1)
+		go func(d do.Droplet) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			prefix := fmt.Sprintf("[%-*s] ", width, d.Name)
+			stdout := &prefixWriter{mu: &outMu, out: c.Out, prefix: prefix}
+			stderr := &prefixWriter{mu: &outMu, out: execStderr, prefix: prefix}
+
+			err := execOnDroplet(c, d, command, user, keyPath, port, privateIP, stdout, stderr)
+			stdout.Flush()
+			stderr.Flush()
+			if err != nil {
+				outMu.Lock()
+				fmt.Fprintf(execStderr, "%s%v\n", prefix, err)
+				outMu.Unlock()
+
+				failMu.Lock()
+				failed = append(failed, d.Name)
+				failMu.Unlock()
+			}
+		}(d)
+	}
+	wg.Wait()
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("the command failed on %d of %d Droplets: %s", len(failed), len(droplets), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func execOnDroplet(c *CmdConfig, d do.Droplet, command, user, keyPath string, port int, privateIP bool, stdout, stderr io.Writer) error {
+	ip, err := privateIPElsePub(&d, privateIP)
+	if err != nil {
+		return err
+	}
+	if ip == "" {
+		return fmt.Errorf("no address to connect to")
+	}
+	if user == "" {
+		user = defaultSSHUser(&d)
+	}
+
+	opts := ssh.Options{
+		doctl.ArgsSSHAgentForwarding: false,
+		doctl.ArgSSHCommand:          command,
+		doctl.ArgSSHRetryMax:         0,
+		// The Droplets share doctl's terminal, so none of them may read from it.
+		doctl.ArgSSHStdin:  strings.NewReader(""),
+		doctl.ArgSSHStdout: stdout,
+		doctl.ArgSSHStderr: stderr,
+	}
+	return c.Doit.SSH(user, ip, keyPath, port, opts).Run()
+}
+
+// prefixWriter writes each complete line written to it to out, preceded by
+// prefix. Writers sharing a mutex never interleave their lines.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.writeLine(w.buf[:i+1])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes a final line that isn't terminated by a newline.
+func (w *prefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.writeLine(append(w.buf, '\n'))
+		w.buf = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintf(w.out, "%s%s", w.prefix, line)
+}
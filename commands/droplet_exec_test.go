@@ -0,0 +1,114 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/doctl/pkg/runner"
+	"github.com/digitalocean/doctl/pkg/ssh"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type runnerFunc func() error
+
+func (f runnerFunc) Run() error { return f() }
+
+func execTestDroplet(id int, name, ip string) do.Droplet {
+	return do.Droplet{Droplet: &godo.Droplet{
+		ID:       id,
+		Name:     name,
+		Image:    &godo.Image{},
+		Networks: &godo.Networks{V4: []godo.NetworkV4{{IPAddress: ip, Type: "public"}}},
+	}}
+}
+
+func TestDropletExec(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		web1 := execTestDroplet(1, "web1", "192.0.2.1")
+		web2 := execTestDroplet(2, "web-two", "192.0.2.2")
+		tm.droplets.EXPECT().ListByTag("web").Return(do.Droplets{web1, web2}, nil)
+		tm.droplets.EXPECT().ListByTag("api").Return(do.Droplets{web2}, nil)
+
+		var mu sync.Mutex
+		var hosts []string
+		tc := config.Doit.(*doctl.TestConfig)
+		tc.SSHFn = func(user, host, keyPath string, port int, opts ssh.Options) runner.Runner {
+			mu.Lock()
+			hosts = append(hosts, host)
+			mu.Unlock()
+			assert.Equal(t, "systemctl restart nginx", opts[doctl.ArgSSHCommand])
+			return runnerFunc(func() error {
+				fmt.Fprintf(opts[doctl.ArgSSHStdout].(io.Writer), "restarted on\n%s", host)
+				if host == "192.0.2.2" {
+					fmt.Fprint(opts[doctl.ArgSSHStderr].(io.Writer), "warning\n")
+					return errors.New("exit status 1")
+				}
+				return nil
+			})
+		}
+
+		stderr := &bytes.Buffer{}
+		origStderr := execStderr
+		execStderr = stderr
+		defer func() { execStderr = origStderr }()
+
+		out := &bytes.Buffer{}
+		config.Out = out
+		config.Args = append(config.Args, "systemctl", "restart", "nginx")
+		config.Doit.Set(config.NS, doctl.ArgTag, []string{"web", "api"})
+		config.Doit.Set(config.NS, doctl.ArgSSHUser, "root")
+		config.Doit.Set(config.NS, doctl.ArgsSSHPort, 22)
+		config.Doit.Set(config.NS, doctl.ArgExecConcurrency, 2)
+
+		err := RunDropletExec(config)
+		assert.EqualError(t, err, "the command failed on 1 of 2 Droplets: web-two")
+
+		sort.Strings(hosts)
+		assert.Equal(t, []string{"192.0.2.1", "192.0.2.2"}, hosts)
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		sort.Strings(lines)
+		assert.Equal(t, []string{
+			"[web-two] 192.0.2.2",
+			"[web-two] restarted on",
+			"[web1   ] 192.0.2.1",
+			"[web1   ] restarted on",
+		}, lines)
+		assert.Contains(t, stderr.String(), "[web-two] warning\n")
+		assert.Contains(t, stderr.String(), "[web-two] exit status 1\n")
+	})
+}
+
+func TestDropletExecRequiresTag(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, "uptime")
+		config.Doit.Set(config.NS, doctl.ArgTag, []string{})
+
+		err := RunDropletExec(config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--tag")
+	})
+}
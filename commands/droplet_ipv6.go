@@ -0,0 +1,205 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+)
+
+const (
+	ipv6StatusOK         = "ok"
+	ipv6StatusDisabled   = "IPv6 not enabled"
+	ipv6StatusNoName     = "no A record to match"
+	ipv6StatusMissing    = "no AAAA record"
+	ipv6StatusMismatched = "AAAA record points elsewhere"
+	ipv6StatusCreated    = "AAAA record created"
+)
+
+// RunDropletEnableIPv6 enables IPv6 on Droplets that don't have it yet.
+func RunDropletEnableIPv6(c *CmdConfig) error {
+	tags, err := c.Doit.GetStringSlice(c.NS, doctl.ArgTag)
+	if err != nil {
+		return err
+	}
+	if len(c.Args) == 0 && len(tags) == 0 {
+		return fmt.Errorf("Specify Droplets by ID or name, or with `--%s`.", doctl.ArgTag)
+	}
+	wait, err := c.Doit.GetBool(c.NS, doctl.ArgCommandWait)
+	if err != nil {
+		return err
+	}
+
+	droplets, err := topDroplets(c.Droplets(), c.Args, tags)
+	if err != nil {
+		return err
+	}
+	if len(droplets) == 0 {
+		return fmt.Errorf("no Droplets are tagged %s", strings.Join(tags, " or "))
+	}
+
+	das := c.DropletActions()
+	var actions do.Actions
+	for _, d := range droplets {
+		if ip, _ := d.PublicIPv6(); ip != "" {
+			notice("Droplet %s (%d) already has IPv6 address %s", d.Name, d.ID, ip)
+			continue
+		}
+		a, err := das.EnableIPv6(d.ID)
+		if err != nil {
+			return fmt.Errorf("enabling IPv6 on Droplet %s (%d): %w", d.Name, d.ID, err)
+		}
+		actions = append(actions, *a)
+	}
+
+	if wait {
+		for i, a := range actions {
+			done, err := actionWait(c, a.ID, 5)
+			if err != nil {
+				return err
+			}
+			if done.Status == "errored" {
+				return fmt.Errorf("enabling IPv6 on Droplet %d failed; see `doctl compute droplet-action get %d --action-id %d`", done.ResourceID, done.ResourceID, done.ID)
+			}
+			actions[i] = *done
+		}
+	}
+
+	return c.Display(&displayers.Action{Actions: actions})
+}
+
+// RunDropletIPv6Audit lists the Droplets whose IPv6 addresses are missing
+// from DNS. Each A record pointing to a Droplet should have an AAAA record of
+// the same name pointing to its IPv6 address.
+func RunDropletIPv6Audit(c *CmdConfig) error {
+	tags, err := c.Doit.GetStringSlice(c.NS, doctl.ArgTag)
+	if err != nil {
+		return err
+	}
+	domains, err := c.Doit.GetStringSlice(c.NS, doctl.ArgIPv6AuditDomain)
+	if err != nil {
+		return err
+	}
+	create, err := c.Doit.GetBool(c.NS, doctl.ArgIPv6AuditCreateMissing)
+	if err != nil {
+		return err
+	}
+	all, err := c.Doit.GetBool(c.NS, doctl.ArgIPv6AuditAll)
+	if err != nil {
+		return err
+	}
+
+	droplets, err := topDroplets(c.Droplets(), c.Args, tags)
+	if err != nil {
+		return err
+	}
+
+	ds := c.Domains()
+	if len(domains) == 0 {
+		list, err := ds.List()
+		if err != nil {
+			return err
+		}
+		for _, d := range list {
+			domains = append(domains, d.Name)
+		}
+	}
+	records := make(map[string]do.DomainRecords, len(domains))
+	for _, domain := range domains {
+		rs, err := ds.Records(domain)
+		if err != nil {
+			return err
+		}
+		records[domain] = rs
+	}
+
+	var report []displayers.DropletIPv6Record
+	for _, d := range droplets {
+		for _, r := range dropletIPv6Records(d, domains, records) {
+			if create && r.Status == ipv6StatusMissing {
+				created, err := ds.CreateRecord(r.Domain, &do.DomainRecordEditRequest{Type: "AAAA", Name: r.Record, Data: r.IPv6, TTL: r.TTL})
+				if err != nil {
+					return fmt.Errorf("creating AAAA record %s.%s: %w", r.Record, r.Domain, err)
+				}
+				recordDNSChange(r.Domain, dnsChangeCreate, nil, created.DomainRecord)
+				r.Status = ipv6StatusCreated
+			}
+			if all || r.Status != ipv6StatusOK {
+				report = append(report, r)
+			}
+		}
+	}
+	return c.Display(&displayers.DropletIPv6Records{Records: report})
+}
+
+// dropletIPv6Records checks that each A record pointing to a Droplet has a
+// matching AAAA record. A Droplet named after a host in one of the domains is
+// expected to have records of that name even if it has no A record.
+func dropletIPv6Records(d do.Droplet, domains []string, records map[string]do.DomainRecords) []displayers.DropletIPv6Record {
+	addrs := dropletAddresses(d)
+	row := displayers.DropletIPv6Record{ID: d.ID, Name: d.Name, IPv6: addrs["AAAA"]}
+
+	type recordName struct{ domain, name string }
+	var names []recordName
+	ttls := map[recordName]int{}
+	for _, domain := range domains {
+		for _, r := range records[domain] {
+			n := recordName{domain, r.Name}
+			if r.Type == "A" && r.Data == addrs["A"] && addrs["A"] != "" {
+				if _, ok := ttls[n]; !ok {
+					names = append(names, n)
+				}
+				ttls[n] = r.TTL
+			}
+		}
+		if n := (recordName{domain, dropletRecordName(d.Name, domain)}); n.name != strings.ToLower(d.Name) {
+			if _, ok := ttls[n]; !ok {
+				names = append(names, n)
+				ttls[n] = 0
+			}
+		}
+	}
+
+	if row.IPv6 == "" {
+		row.Status = ipv6StatusDisabled
+		return []displayers.DropletIPv6Record{row}
+	}
+	if len(names) == 0 {
+		row.Status = ipv6StatusNoName
+		return []displayers.DropletIPv6Record{row}
+	}
+
+	out := make([]displayers.DropletIPv6Record, 0, len(names))
+	for _, n := range names {
+		r := row
+		r.Domain, r.Record, r.TTL = n.domain, n.name, ttls[n]
+		r.Status = ipv6StatusMissing
+		for _, rec := range records[n.domain] {
+			if rec.Type != "AAAA" || rec.Name != n.name {
+				continue
+			}
+			r.Status = ipv6StatusMismatched
+			r.AAAA = append(r.AAAA, rec.Data)
+			if rec.Data == row.IPv6 {
+				r.Status = ipv6StatusOK
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
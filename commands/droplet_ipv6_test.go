@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testIPv6Droplet = do.Droplet{
+	Droplet: &godo.Droplet{
+		ID:   2,
+		Name: "web1.example.com",
+		Networks: &godo.Networks{
+			V4: []godo.NetworkV4{{IPAddress: "192.0.2.10", Type: "public"}},
+			V6: []godo.NetworkV6{{IPAddress: "2001:db8::10", Type: "public"}},
+		},
+	},
+}
+
+func TestDropletEnableIPv6(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.droplets.EXPECT().ListByTag("web").Return(do.Droplets{testDroplet, testIPv6Droplet}, nil)
+		tm.dropletActions.EXPECT().EnableIPv6(1).Return(&do.Action{Action: &godo.Action{ID: 10, Status: "in-progress"}}, nil)
+		tm.actions.EXPECT().Get(10).Return(&do.Action{Action: &godo.Action{ID: 10, Status: "completed"}}, nil)
+
+		config.Doit.Set(config.NS, doctl.ArgTag, []string{"web"})
+		config.Doit.Set(config.NS, doctl.ArgCommandWait, true)
+
+		assert.NoError(t, RunDropletEnableIPv6(config))
+	})
+}
+
+func TestDropletEnableIPv6NoTargets(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		assert.EqualError(t, RunDropletEnableIPv6(config), "Specify Droplets by ID or name, or with `--tag`.")
+	})
+}
+
+func TestDropletIPv6Records(t *testing.T) {
+	records := map[string]do.DomainRecords{
+		"example.com": {
+			{DomainRecord: &godo.DomainRecord{Type: "A", Name: "www", Data: "192.0.2.10", TTL: 600}},
+			{DomainRecord: &godo.DomainRecord{Type: "AAAA", Name: "www", Data: "2001:db8::10"}},
+			{DomainRecord: &godo.DomainRecord{Type: "A", Name: "api", Data: "192.0.2.10", TTL: 300}},
+			{DomainRecord: &godo.DomainRecord{Type: "AAAA", Name: "api", Data: "2001:db8::99"}},
+		},
+		"example.org": {
+			{DomainRecord: &godo.DomainRecord{Type: "A", Name: "@", Data: "192.0.2.10", TTL: 1800}},
+		},
+	}
+	domains := []string{"example.com", "example.org"}
+
+	got := dropletIPv6Records(testIPv6Droplet, domains, records)
+	want := []displayers.DropletIPv6Record{
+		{ID: 2, Name: "web1.example.com", IPv6: "2001:db8::10", Domain: "example.com", Record: "www", TTL: 600, AAAA: []string{"2001:db8::10"}, Status: ipv6StatusOK},
+		{ID: 2, Name: "web1.example.com", IPv6: "2001:db8::10", Domain: "example.com", Record: "api", TTL: 300, AAAA: []string{"2001:db8::99"}, Status: ipv6StatusMismatched},
+		{ID: 2, Name: "web1.example.com", IPv6: "2001:db8::10", Domain: "example.com", Record: "web1", Status: ipv6StatusMissing},
+		{ID: 2, Name: "web1.example.com", IPv6: "2001:db8::10", Domain: "example.org", Record: "@", TTL: 1800, Status: ipv6StatusMissing},
+	}
+	assert.Equal(t, want, got)
+
+	got = dropletIPv6Records(testDroplet, domains, records)
+	assert.Equal(t, []displayers.DropletIPv6Record{{ID: 1, Name: "a-droplet", Status: ipv6StatusDisabled}}, got)
+}
+
+func TestDropletIPv6AuditCreateMissing(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.droplets.EXPECT().ListByTag("web").Return(do.Droplets{testIPv6Droplet}, nil)
+		tm.domains.EXPECT().Records("example.org").Return(do.DomainRecords{
+			{DomainRecord: &godo.DomainRecord{Type: "A", Name: "@", Data: "192.0.2.10", TTL: 1800}},
+		}, nil)
+		tm.domains.EXPECT().CreateRecord("example.org", &do.DomainRecordEditRequest{Type: "AAAA", Name: "@", Data: "2001:db8::10", TTL: 1800}).
+			Return(&do.DomainRecord{DomainRecord: &godo.DomainRecord{ID: 7, Type: "AAAA", Name: "@", Data: "2001:db8::10", TTL: 1800}}, nil)
+
+		config.Doit.Set(config.NS, doctl.ArgTag, []string{"web"})
+		config.Doit.Set(config.NS, doctl.ArgIPv6AuditDomain, []string{"example.org"})
+		config.Doit.Set(config.NS, doctl.ArgIPv6AuditCreateMissing, true)
+
+		require.NoError(t, RunDropletIPv6Audit(config))
+	})
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+)
+
+// lookupAddr returns the PTR records of an address in public DNS. It is replaced for testing.
+var lookupAddr = net.LookupAddr
+
+const (
+	ptrStatusOK         = "ok"
+	ptrStatusMissing    = "no PTR record"
+	ptrStatusNoForward  = "PTR name does not resolve"
+	ptrStatusMismatched = "PTR name resolves elsewhere"
+)
+
+// RunDropletSetPTR sets the reverse DNS name of a Droplet by renaming it.
+func RunDropletSetPTR(c *CmdConfig) error {
+	if len(c.Args) != 2 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	hostname := strings.TrimSuffix(strings.ToLower(c.Args[1]), ".")
+	if !strings.Contains(hostname, ".") {
+		return fmt.Errorf("%q is not a fully qualified domain name; PTR records are only created for Droplets named after one", c.Args[1])
+	}
+
+	wait, err := c.Doit.GetBool(c.NS, doctl.ArgCommandWait)
+	if err != nil {
+		return err
+	}
+
+	ds := c.Droplets()
+	var id int
+	err = matchDroplets(c.Args[:1], ds, func(ids []int) error {
+		id = ids[0]
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	a, err := c.DropletActions().Rename(id, hostname)
+	if err != nil {
+		return err
+	}
+	if !wait {
+		notice("Droplet %d is being renamed to %s; its PTR records will follow once the rename completes", id, hostname)
+		return nil
+	}
+	if _, err := actionWait(c, a.ID, 5); err != nil {
+		return err
+	}
+
+	d, err := ds.Get(id)
+	if err != nil {
+		return err
+	}
+
+	ptrs := dropletPTRs(*d)
+	for _, p := range ptrs {
+		if p.Status != ptrStatusOK {
+			warn("PTR for %s: %s. Reverse DNS can take a while to update, and %s needs an A or AAAA record pointing to %s.", p.IP, p.Status, hostname, p.IP)
+		}
+	}
+	return c.Display(&displayers.DropletPTRs{PTRs: ptrs})
+}
+
+// RunDropletPTRReport lists the Droplets whose reverse DNS doesn't match their forward records.
+func RunDropletPTRReport(c *CmdConfig) error {
+	all, err := c.Doit.GetBool(c.NS, doctl.ArgPTRReportAll)
+	if err != nil {
+		return err
+	}
+
+	droplets, err := c.Droplets().List()
+	if err != nil {
+		return err
+	}
+
+	var report []displayers.DropletPTR
+	for _, d := range droplets {
+		for _, p := range dropletPTRs(d) {
+			if all || p.Status != ptrStatusOK {
+				report = append(report, p)
+			}
+		}
+	}
+	return c.Display(&displayers.DropletPTRs{PTRs: report})
+}
+
+// dropletPTRs checks that the PTR records of each of a Droplet's public addresses
+// name a host that resolves back to that address.
+func dropletPTRs(d do.Droplet) []displayers.DropletPTR {
+	var out []displayers.DropletPTR
+	for _, typ := range []string{"A", "AAAA"} {
+		ip, ok := dropletAddresses(d)[typ]
+		if !ok {
+			continue
+		}
+
+		p := displayers.DropletPTR{ID: d.ID, Name: d.Name, IP: ip, Status: ptrStatusMissing}
+		names, err := lookupAddr(ip)
+		if err != nil || len(names) == 0 {
+			out = append(out, p)
+			continue
+		}
+
+		p.Status = ptrStatusNoForward
+		for _, name := range names {
+			name = strings.TrimSuffix(name, ".")
+			p.PTR = append(p.PTR, name)
+
+			addrs, err := lookupHost(name)
+			if err != nil {
+				continue
+			}
+			p.Forward = append(p.Forward, addrs...)
+			p.Status = ptrStatusMismatched
+			if contains(addrs, ip) {
+				p.Status = ptrStatusOK
+				break
+			}
+		}
+		out = append(out, p)
+	}
+	return out
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+)
+
+// lbHealthSleep waits between health checks. It is replaced for testing.
+var lbHealthSleep = time.Sleep
+
+// RunDropletReboot reboots Droplets, all at once or in batches that each
+// wait for a load balancer's health check to pass before the next one starts.
+func RunDropletReboot(c *CmdConfig) error {
+	tags, err := c.Doit.GetStringSlice(c.NS, doctl.ArgTag)
+	if err != nil {
+		return err
+	}
+	if len(c.Args) == 0 && len(tags) == 0 {
+		return fmt.Errorf("Specify Droplets by ID or name, or with `--%s`.", doctl.ArgTag)
+	}
+	rolling, err := c.Doit.GetBool(c.NS, doctl.ArgDropletRolling)
+	if err != nil {
+		return err
+	}
+	batch, err := c.Doit.GetInt(c.NS, doctl.ArgDropletBatch)
+	if err != nil {
+		return err
+	}
+	if batch < 1 {
+		return fmt.Errorf("--%s must be at least 1", doctl.ArgDropletBatch)
+	}
+	lbID, err := c.Doit.GetString(c.NS, doctl.ArgDropletWaitHealthy)
+	if err != nil {
+		return err
+	}
+	timeout, err := c.Doit.GetDuration(c.NS, doctl.ArgTimeout)
+	if err != nil {
+		return err
+	}
+
+	droplets, err := topDroplets(c.Droplets(), c.Args, tags)
+	if err != nil {
+		return err
+	}
+	if len(droplets) == 0 {
+		return fmt.Errorf("no Droplets are tagged %s", strings.Join(tags, " or "))
+	}
+	if !rolling {
+		batch = len(droplets)
+	}
+
+	var hc *godo.HealthCheck
+	if lbID != "" {
+		lb, err := c.LoadBalancers().Get(lbID)
+		if err != nil {
+			return err
+		}
+		if lb.HealthCheck == nil {
+			return fmt.Errorf("load balancer %s has no health check", lb.Name)
+		}
+		hc = lb.HealthCheck
+		for _, d := range droplets {
+			if !(lb.Tag != "" && contains(d.Tags, lb.Tag)) && !containsInt(lb.DropletIDs, d.ID) {
+				warn("Droplet %s (%d) is not behind load balancer %s", d.Name, d.ID, lb.Name)
+			}
+		}
+	}
+
+	das := c.DropletActions()
+	var actions do.Actions
+	for start := 0; start < len(droplets); start += batch {
+		group := droplets[start:min(start+batch, len(droplets))]
+		left := len(droplets) - start - len(group)
+		if rolling {
+			notice("Rebooting %s (%d of %d Droplets)", dropletNames(group), start+len(group), len(droplets))
+		}
+
+		var pending do.Actions
+		for _, d := range group {
+			a, err := das.Reboot(d.ID)
+			if err != nil {
+				return fmt.Errorf("rebooting Droplet %s (%d): %w", d.Name, d.ID, err)
+			}
+			pending = append(pending, *a)
+		}
+		for _, a := range pending {
+			done, err := actionWait(c, a.ID, 5)
+			if err != nil {
+				return err
+			}
+			if done.Status == "errored" {
+				return fmt.Errorf("rebooting Droplet %d failed; see `doctl compute droplet-action get %d --action-id %d`; %d Droplets were not rebooted", done.ResourceID, done.ResourceID, done.ID, left)
+			}
+			actions = append(actions, *done)
+		}
+
+		if hc == nil {
+			continue
+		}
+		for _, d := range group {
+			if err := waitLoadBalancerHealthy(d, *hc, timeout); err != nil {
+				return fmt.Errorf("%v; %d Droplets were not rebooted", err, left)
+			}
+		}
+		notice("%s passed the health check", dropletNames(group))
+	}
+
+	return c.Display(&displayers.Action{Actions: actions})
+}
+
+// waitLoadBalancerHealthy runs a load balancer's health check against the
+// public IPv4 address of d until it passes as many times in a row as the load
+// balancer requires.
+func waitLoadBalancerHealthy(d do.Droplet, hc godo.HealthCheck, timeout time.Duration) error {
+	ip, err := d.PublicIPv4()
+	if err != nil {
+		return err
+	}
+	if ip == "" {
+		return fmt.Errorf("Droplet %s (%d) has no public IPv4 address to check", d.Name, d.ID)
+	}
+
+	interval := time.Duration(max(hc.CheckIntervalSeconds, 1)) * time.Second
+	responseTimeout := time.Duration(max(hc.ResponseTimeoutSeconds, 1)) * time.Second
+	threshold := max(hc.HealthyThreshold, 1)
+	deadline := time.Now().Add(timeout)
+
+	passed := 0
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), responseTimeout)
+		err := checkLoadBalancerHealth(ctx, hc, ip)
+		cancel()
+		if err == nil {
+			passed++
+			if passed >= threshold {
+				return nil
+			}
+		} else {
+			passed = 0
+		}
+		if time.Now().After(deadline) {
+			return errTimeout("Droplet %s (%d) did not pass the health check within %s: %v", d.Name, d.ID, timeout, err)
+		}
+		lbHealthSleep(interval)
+	}
+}
+
+// checkLoadBalancerHealth makes one health check request the way the load
+// balancer does: a TCP connection, or an HTTP(S) GET answered with a status
+// below 400.
+func checkLoadBalancerHealth(ctx context.Context, hc godo.HealthCheck, ip string) error {
+	port := strconv.Itoa(hc.Port)
+	switch hc.Protocol {
+	case "tcp":
+		conn, err := dialVerify(ctx, "tcp", net.JoinHostPort(ip, port))
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case "http":
+		return checkHTTP(ctx, ip, port, hc.Path)
+	case "https":
+		client := &http.Client{
+			Transport: &http.Transport{
+				DialContext: dialVerify,
+				// Load balancers don't verify the certificates of their backends.
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+net.JoinHostPort(ip, port)+hc.Path, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("GET %s returned %s", hc.Path, resp.Status)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported health check protocol %q", hc.Protocol)
+	}
+}
+
+func dropletNames(droplets do.Droplets) string {
+	names := make([]string, len(droplets))
+	for i, d := range droplets {
+		names[i] = d.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func containsInt(list []int, v int) bool {
+	for _, i := range list {
+		if i == v {
+			return true
+		}
+	}
+	return false
+}
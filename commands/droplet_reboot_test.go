@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func rebootTestDroplets(n int) do.Droplets {
+	var droplets do.Droplets
+	for i := 1; i <= n; i++ {
+		droplets = append(droplets, do.Droplet{Droplet: &godo.Droplet{
+			ID:   i,
+			Name: "web-" + strconv.Itoa(i),
+			Tags: []string{"web"},
+			Networks: &godo.Networks{
+				V4: []godo.NetworkV4{{IPAddress: "192.0.2." + strconv.Itoa(i), Type: "public"}},
+			},
+		}})
+	}
+	return droplets
+}
+
+func withLBHealthSleep(t *testing.T) {
+	prev := lbHealthSleep
+	lbHealthSleep = func(time.Duration) {}
+	t.Cleanup(func() { lbHealthSleep = prev })
+}
+
+func TestDropletRebootRolling(t *testing.T) {
+	var healthChecks atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/health", r.URL.Path)
+		healthChecks.Add(1)
+	}))
+	defer server.Close()
+	withVerifyTargets(t, "", strings.TrimPrefix(server.URL, "http://"))
+	withLBHealthSleep(t)
+
+	lb := &do.LoadBalancer{LoadBalancer: &godo.LoadBalancer{
+		ID:   "4de7ac8b-495b-4884-9a69-1050c6793cd6",
+		Name: "web-lb",
+		Tag:  "web",
+		HealthCheck: &godo.HealthCheck{
+			Protocol:             "http",
+			Port:                 80,
+			Path:                 "/health",
+			CheckIntervalSeconds: 10,
+			HealthyThreshold:     2,
+		},
+	}}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.droplets.EXPECT().ListByTag("web").Return(rebootTestDroplets(3), nil)
+		tm.loadBalancers.EXPECT().Get(lb.ID).Return(lb, nil)
+
+		action := func(id int) *do.Action {
+			return &do.Action{Action: &godo.Action{ID: 100 + id, ResourceID: id, Status: "in-progress"}}
+		}
+		gomock.InOrder(
+			tm.dropletActions.EXPECT().Reboot(1).Return(action(1), nil),
+			tm.dropletActions.EXPECT().Reboot(2).Return(action(2), nil),
+			tm.dropletActions.EXPECT().Reboot(3).DoAndReturn(func(int) (*do.Action, error) {
+				// Both Droplets of the first batch passed the check twice.
+				assert.Equal(t, int32(4), healthChecks.Load())
+				return action(3), nil
+			}),
+		)
+		for id := 1; id <= 3; id++ {
+			tm.actions.EXPECT().Get(100+id).Return(&do.Action{Action: &godo.Action{ID: 100 + id, ResourceID: id, Status: "completed"}}, nil)
+		}
+
+		config.Doit.Set(config.NS, doctl.ArgTag, []string{"web"})
+		config.Doit.Set(config.NS, doctl.ArgDropletRolling, true)
+		config.Doit.Set(config.NS, doctl.ArgDropletBatch, 2)
+		config.Doit.Set(config.NS, doctl.ArgDropletWaitHealthy, lb.ID)
+		config.Doit.Set(config.NS, doctl.ArgTimeout, time.Minute)
+
+		require.NoError(t, RunDropletReboot(config))
+		assert.Equal(t, int32(6), healthChecks.Load())
+	})
+}
+
+func TestDropletRebootRollingUnhealthy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	withVerifyTargets(t, "", strings.TrimPrefix(server.URL, "http://"))
+	withLBHealthSleep(t)
+
+	lb := &do.LoadBalancer{LoadBalancer: &godo.LoadBalancer{
+		ID:          "4de7ac8b-495b-4884-9a69-1050c6793cd6",
+		Name:        "web-lb",
+		DropletIDs:  []int{1, 2},
+		HealthCheck: &godo.HealthCheck{Protocol: "http", Port: 80, Path: "/health"},
+	}}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.droplets.EXPECT().ListByTag("web").Return(rebootTestDroplets(2), nil)
+		tm.loadBalancers.EXPECT().Get(lb.ID).Return(lb, nil)
+		tm.dropletActions.EXPECT().Reboot(1).Return(&do.Action{Action: &godo.Action{ID: 101, ResourceID: 1}}, nil)
+		tm.actions.EXPECT().Get(101).Return(&do.Action{Action: &godo.Action{ID: 101, ResourceID: 1, Status: "completed"}}, nil)
+
+		config.Doit.Set(config.NS, doctl.ArgTag, []string{"web"})
+		config.Doit.Set(config.NS, doctl.ArgDropletRolling, true)
+		config.Doit.Set(config.NS, doctl.ArgDropletBatch, 1)
+		config.Doit.Set(config.NS, doctl.ArgDropletWaitHealthy, lb.ID)
+		config.Doit.Set(config.NS, doctl.ArgTimeout, time.Duration(0))
+
+		err := RunDropletReboot(config)
+		assert.EqualError(t, err, "Droplet web-1 (1) did not pass the health check within 0s: GET /health returned 503 Service Unavailable; 1 Droplets were not rebooted")
+	})
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/viper"
+)
+
+// topWindow is how far back droplet top looks for samples. Rates such as CPU
+// usage are averaged over the whole window.
+const topWindow = 5 * time.Minute
+
+// RunDropletTop shows the resource usage of Droplets, refreshing it until interrupted.
+func RunDropletTop(c *CmdConfig) error {
+	tags, err := c.Doit.GetStringSlice(c.NS, doctl.ArgTag)
+	if err != nil {
+		return err
+	}
+	interval, err := c.Doit.GetDuration(c.NS, doctl.ArgInterval)
+	if err != nil {
+		return err
+	}
+	if interval < time.Second {
+		return fmt.Errorf("--%s must be at least 1s", doctl.ArgInterval)
+	}
+
+	droplets, err := topDroplets(c.Droplets(), c.Args, tags)
+	if err != nil {
+		return err
+	}
+	if len(droplets) == 0 {
+		return fmt.Errorf("no Droplets were found")
+	}
+
+	// JSON output is a single snapshot, for use in scripts.
+	if viper.GetString(doctl.ArgOutput) == "json" {
+		usages, err := dropletUsages(c.Monitoring(), droplets, time.Now())
+		if err != nil {
+			return err
+		}
+		return c.Display(&displayers.DropletUsages{Usages: usages})
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	for {
+		usages, err := dropletUsages(c.Monitoring(), droplets, time.Now())
+		if err != nil {
+			return err
+		}
+		// Move the cursor home and clear the screen before each refresh.
+		fmt.Fprint(c.Out, "\033[H\033[2J")
+		if err := c.Display(&displayers.DropletUsages{Usages: usages}); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.Out, "\nUpdated %s. Press Ctrl-C to exit.\n", time.Now().Format(time.Kitchen))
+
+		select {
+		case <-sigs:
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// topDroplets finds the Droplets named by ID or name in args, or with one
+// of the tags. If neither is given, all Droplets are used.
+func topDroplets(ds do.DropletsService, args, tags []string) (do.Droplets, error) {
+	var (
+		out  do.Droplets
+		seen = map[int]bool{}
+	)
+	add := func(d do.Droplet) {
+		if !seen[d.ID] {
+			seen[d.ID] = true
+			out = append(out, d)
+		}
+	}
+
+	for _, tag := range tags {
+		list, err := ds.ListByTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range list {
+			add(d)
+		}
+	}
+
+	var all do.Droplets
+	for _, arg := range args {
+		if id, err := strconv.Atoi(arg); err == nil {
+			d, err := ds.Get(id)
+			if err != nil {
+				return nil, err
+			}
+			add(*d)
+			continue
+		}
+
+		if all == nil {
+			list, err := ds.List()
+			if err != nil {
+				return nil, err
+			}
+			all = list
+		}
+		found := false
+		for _, d := range all {
+			if d.Name == arg {
+				add(d)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("could not find Droplet %s", arg)
+		}
+	}
+
+	if len(args) == 0 && len(tags) == 0 {
+		return ds.List()
+	}
+	return out, nil
+}
+
+// dropletUsages fetches the recent resource usage of each Droplet.
+func dropletUsages(ms do.MonitoringService, droplets do.Droplets, now time.Time) ([]displayers.DropletUsage, error) {
+	usages := make([]displayers.DropletUsage, len(droplets))
+	errs := make([]error, len(droplets))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 10)
+	for i, d := range droplets {
+		wg.Add(1)
+		go func(i int, d do.Droplet) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			usages[i], errs[i] = dropletUsage(ms, d, now)
+		}(i, d)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return usages, nil
+}
+
+func dropletUsage(ms do.MonitoringService, d do.Droplet, now time.Time) (displayers.DropletUsage, error) {
+	u := displayers.DropletUsage{ID: d.ID, Name: d.Name}
+	req := &godo.DropletMetricsRequest{
+		HostID: strconv.Itoa(d.ID),
+		Start:  now.Add(-topWindow),
+		End:    now,
+	}
+
+	cpu, err := ms.GetDropletCPU(req)
+	if err != nil {
+		return u, err
+	}
+	u.CPU = cpuPercent(cpu)
+
+	total, err := ms.GetDropletTotalMemory(req)
+	if err != nil {
+		return u, err
+	}
+	avail, err := ms.GetDropletAvailableMemory(req)
+	if err != nil {
+		return u, err
+	}
+	u.Memory = usedPercent(latestSum(total), latestSum(avail))
+
+	size, err := ms.GetDropletFilesystemSize(req)
+	if err != nil {
+		return u, err
+	}
+	free, err := ms.GetDropletFilesystemFree(req)
+	if err != nil {
+		return u, err
+	}
+	u.Disk = usedPercent(latestSum(size), latestSum(free))
+
+	for _, dir := range []string{"inbound", "outbound"} {
+		bw, err := ms.GetDropletBandwidth(&godo.DropletBandwidthMetricsRequest{
+			DropletMetricsRequest: *req,
+			Interface:             "public",
+			Direction:             dir,
+		})
+		if err != nil {
+			return u, err
+		}
+		if dir == "inbound" {
+			u.Inbound = latestSum(bw)
+		} else {
+			u.Outbound = latestSum(bw)
+		}
+	}
+
+	return u, nil
+}
+
+// cpuPercent computes the CPU usage over the samples from the per-mode CPU
+// time counters: the share of the elapsed CPU time not spent idle.
+func cpuPercent(resp *godo.MetricsResponse) *float64 {
+	var total, idle float64
+	for _, s := range resp.Data.Result {
+		if len(s.Values) < 2 {
+			continue
+		}
+		delta := float64(s.Values[len(s.Values)-1].Value - s.Values[0].Value)
+		if delta < 0 {
+			// The counter was reset, e.g. by a reboot.
+			continue
+		}
+		total += delta
+		if s.Metric["mode"] == "idle" {
+			idle += delta
+		}
+	}
+	if total <= 0 {
+		return nil
+	}
+	pct := (total - idle) / total * 100
+	return &pct
+}
+
+// latestSum adds up the most recent sample of each series, or returns nil
+// if there are none, e.g. because the Droplet does not run the metrics agent.
+func latestSum(resp *godo.MetricsResponse) *float64 {
+	var sum float64
+	found := false
+	for _, s := range resp.Data.Result {
+		if len(s.Values) == 0 {
+			continue
+		}
+		sum += float64(s.Values[len(s.Values)-1].Value)
+		found = true
+	}
+	if !found {
+		return nil
+	}
+	return &sum
+}
+
+// usedPercent returns the percentage of total that is not available.
+func usedPercent(total, available *float64) *float64 {
+	if total == nil || available == nil || *total <= 0 {
+		return nil
+	}
+	pct := (*total - *available) / *total * 100
+	return &pct
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/digitalocean/godo/metrics"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func topMetrics(series ...metrics.SampleStream) *godo.MetricsResponse {
+	return &godo.MetricsResponse{Status: "success", Data: godo.MetricsData{ResultType: "matrix", Result: series}}
+}
+
+func topSeries(labels map[string]string, values ...float64) metrics.SampleStream {
+	s := metrics.SampleStream{Metric: metrics.Metric{}}
+	for k, v := range labels {
+		s.Metric[metrics.LabelName(k)] = metrics.LabelValue(v)
+	}
+	for i, v := range values {
+		s.Values = append(s.Values, metrics.SamplePair{Timestamp: metrics.Time(i * 60000), Value: metrics.SampleValue(v)})
+	}
+	return s
+}
+
+func TestCPUPercent(t *testing.T) {
+	resp := topMetrics(
+		topSeries(map[string]string{"mode": "idle"}, 100, 175),
+		topSeries(map[string]string{"mode": "user"}, 50, 70),
+		topSeries(map[string]string{"mode": "system"}, 10, 15),
+		// A reset counter is ignored.
+		topSeries(map[string]string{"mode": "iowait"}, 10, 0),
+	)
+	pct := cpuPercent(resp)
+	require.NotNil(t, pct)
+	assert.InDelta(t, 25.0, *pct, 0.001)
+
+	assert.Nil(t, cpuPercent(topMetrics()))
+}
+
+func TestDropletTopJSON(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		prev := viper.GetString(doctl.ArgOutput)
+		viper.Set(doctl.ArgOutput, "json")
+		defer viper.Set(doctl.ArgOutput, prev)
+
+		web := execTestDroplet(7, "web", "192.0.2.7")
+		bare := execTestDroplet(8, "bare", "192.0.2.8")
+		tm.droplets.EXPECT().ListByTag("web").Return(do.Droplets{web, bare}, nil)
+
+		// Droplets without the metrics agent have no series.
+		byHost := func(web *godo.MetricsResponse) func(*godo.DropletMetricsRequest) (*godo.MetricsResponse, error) {
+			return func(r *godo.DropletMetricsRequest) (*godo.MetricsResponse, error) {
+				if r.HostID == "7" {
+					return web, nil
+				}
+				return topMetrics(), nil
+			}
+		}
+		tm.monitoring.EXPECT().GetDropletCPU(gomock.Any()).DoAndReturn(byHost(topMetrics(
+			topSeries(map[string]string{"mode": "idle"}, 0, 90),
+			topSeries(map[string]string{"mode": "user"}, 0, 10),
+		))).Times(2)
+		tm.monitoring.EXPECT().GetDropletTotalMemory(gomock.Any()).DoAndReturn(byHost(topMetrics(topSeries(nil, 1000, 1000)))).Times(2)
+		tm.monitoring.EXPECT().GetDropletAvailableMemory(gomock.Any()).DoAndReturn(byHost(topMetrics(topSeries(nil, 300, 250)))).Times(2)
+		tm.monitoring.EXPECT().GetDropletFilesystemSize(gomock.Any()).DoAndReturn(byHost(topMetrics(
+			topSeries(map[string]string{"mountpoint": "/"}, 100),
+			topSeries(map[string]string{"mountpoint": "/mnt"}, 100),
+		))).Times(2)
+		tm.monitoring.EXPECT().GetDropletFilesystemFree(gomock.Any()).DoAndReturn(byHost(topMetrics(
+			topSeries(map[string]string{"mountpoint": "/"}, 50),
+			topSeries(map[string]string{"mountpoint": "/mnt"}, 100),
+		))).Times(2)
+		tm.monitoring.EXPECT().GetDropletBandwidth(gomock.Any()).DoAndReturn(func(r *godo.DropletBandwidthMetricsRequest) (*godo.MetricsResponse, error) {
+			if r.HostID != "7" {
+				return topMetrics(), nil
+			}
+			assert.Equal(t, "public", r.Interface)
+			if r.Direction == "inbound" {
+				return topMetrics(topSeries(nil, 1, 2.5)), nil
+			}
+			return topMetrics(topSeries(nil, 0.5)), nil
+		}).Times(4)
+
+		var out bytes.Buffer
+		config.Out = &out
+		config.Doit.Set(config.NS, doctl.ArgTag, []string{"web"})
+		config.Doit.Set(config.NS, doctl.ArgInterval, 10*time.Second)
+		require.NoError(t, RunDropletTop(config))
+
+		var got []map[string]any
+		require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+		require.Len(t, got, 2)
+		assert.Equal(t, map[string]any{
+			"id": 7.0, "name": "web",
+			"cpu_percent": 10.0, "memory_percent": 75.0, "disk_percent": 25.0,
+			"public_inbound_mbps": 2.5, "public_outbound_mbps": 0.5,
+		}, got[0])
+		assert.Equal(t, map[string]any{
+			"id": 8.0, "name": "bare",
+			"cpu_percent": nil, "memory_percent": nil, "disk_percent": nil,
+			"public_inbound_mbps": nil, "public_outbound_mbps": nil,
+		}, got[1])
+	})
+}
+
+func TestDropletTopUnknownDroplet(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.droplets.EXPECT().List().Return(testDropletList, nil)
+
+		config.Args = []string{"missing"}
+		config.Doit.Set(config.NS, doctl.ArgInterval, 10*time.Second)
+		assert.EqualError(t, RunDropletTop(config), "could not find Droplet missing")
+	})
+}
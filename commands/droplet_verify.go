@@ -0,0 +1,232 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+)
+
+// dialVerify connects to a Droplet for a verification check. It is replaced for testing.
+var dialVerify = (&net.Dialer{Timeout: 10 * time.Second}).DialContext
+
+// verifyPollInterval is how long to wait before retrying a check that failed.
+var verifyPollInterval = 5 * time.Second
+
+// dropletCheck is a reachability check run against a new Droplet.
+type dropletCheck struct {
+	name string
+	run  func(ctx context.Context, ip string) error
+}
+
+// dropletChecks returns the checks requested with --verify-ssh and --verify-http.
+func dropletChecks(verifySSH bool, verifyHTTP string) ([]dropletCheck, error) {
+	var checks []dropletCheck
+	if verifySSH {
+		checks = append(checks, dropletCheck{name: "SSH", run: checkSSH})
+	}
+	if verifyHTTP != "" {
+		port, path, err := parseVerifyHTTP(verifyHTTP)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, dropletCheck{
+			name: "HTTP " + verifyHTTP,
+			run: func(ctx context.Context, ip string) error {
+				return checkHTTP(ctx, ip, port, path)
+			},
+		})
+	}
+	return checks, nil
+}
+
+// parseVerifyHTTP parses a --verify-http value of the form [:port][/path].
+func parseVerifyHTTP(s string) (string, string, error) {
+	port := "80"
+	if strings.HasPrefix(s, ":") {
+		p, rest, _ := strings.Cut(s[1:], "/")
+		if n, err := strconv.Atoi(p); err != nil || n < 1 || n > 65535 {
+			return "", "", fmt.Errorf("invalid --%s %q: %q is not a port", doctl.ArgVerifyHTTP, s, p)
+		}
+		port, s = p, "/"+rest
+	}
+	if !strings.HasPrefix(s, "/") {
+		return "", "", fmt.Errorf("invalid --%s %q: expected a port and path, such as `:80/health`", doctl.ArgVerifyHTTP, s)
+	}
+	return port, s, nil
+}
+
+// checkSSH checks that an SSH server answers on port 22.
+func checkSSH(ctx context.Context, ip string) error {
+	conn, err := dialVerify(ctx, "tcp", net.JoinHostPort(ip, "22"))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	banner, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("reading SSH banner: %w", err)
+	}
+	if !strings.HasPrefix(banner, "SSH-") {
+		return fmt.Errorf("unexpected SSH banner %q", strings.TrimSpace(banner))
+	}
+	return nil
+}
+
+// checkHTTP checks that a GET of path returns a status below 400.
+func checkHTTP(ctx context.Context, ip, port, path string) error {
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{DialContext: dialVerify},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+net.JoinHostPort(ip, port)+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("GET %s returned %s", path, resp.Status)
+	}
+	return nil
+}
+
+// verifyDroplet runs the checks against the public IPv4 address of d, retrying
+// each until it passes or the timeout is reached.
+func verifyDroplet(d do.Droplet, checks []dropletCheck, timeout time.Duration) error {
+	ip, err := d.PublicIPv4()
+	if err != nil {
+		return err
+	}
+	if ip == "" {
+		return fmt.Errorf("Droplet %s (%d) has no public IPv4 address to verify", d.Name, d.ID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for _, check := range checks {
+		for {
+			err := check.run(ctx, ip)
+			if err == nil {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return errTimeout("Droplet %s (%d) failed the %s check within %s: %v", d.Name, d.ID, check.name, timeout, err)
+			case <-time.After(verifyPollInterval):
+			}
+		}
+	}
+	return nil
+}
+
+// verifyDroplets verifies the new Droplets, deleting the ones that fail if
+// destroy is set.
+func verifyDroplets(c *CmdConfig, droplets do.Droplets, checks []dropletCheck, timeout time.Duration, destroy bool) error {
+	errs := make([]error, len(droplets))
+	done := make(chan struct{})
+	for i, d := range droplets {
+		go func(i int, d do.Droplet) {
+			errs[i] = verifyDroplet(d, checks, timeout)
+			done <- struct{}{}
+		}(i, d)
+	}
+	for range droplets {
+		<-done
+	}
+
+	var firstErr error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if destroy {
+			d := droplets[i]
+			if derr := c.Droplets().Delete(d.ID); derr != nil {
+				warn("Droplet %s (%d) failed verification and could not be deleted: %v", d.Name, d.ID, derr)
+			} else {
+				notice("Droplet %s (%d) failed verification and was deleted", d.Name, d.ID)
+			}
+		}
+	}
+	return firstErr
+}
+
+// snapshotImage returns the image to create a Droplet from the Droplet
+// snapshot with the given name or ID. The snapshot must be available in each
+// of the regions.
+func snapshotImage(c *CmdConfig, snapshot string, regions []string) (godo.DropletCreateImage, error) {
+	snapshots, err := c.Snapshots().ListDroplet()
+	if err != nil {
+		return godo.DropletCreateImage{}, err
+	}
+
+	var matches do.Snapshots
+	for _, s := range snapshots {
+		if s.Name == snapshot || s.ID == snapshot {
+			matches = append(matches, s)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return godo.DropletCreateImage{}, withExitCode(ExitNotFound, fmt.Errorf("no Droplet snapshot named %q", snapshot))
+	case 1:
+	default:
+		return godo.DropletCreateImage{}, fmt.Errorf("%d Droplet snapshots are named %q; use the snapshot's ID instead", len(matches), snapshot)
+	}
+	s := matches[0]
+
+	for _, r := range regions {
+		if r == "" {
+			continue
+		}
+		available := false
+		for _, sr := range s.Regions {
+			if sr == r {
+				available = true
+				break
+			}
+		}
+		if !available {
+			return godo.DropletCreateImage{}, fmt.Errorf("snapshot %q is not available in %s, only in %s; transfer it with `doctl compute image-action transfer`", snapshot, r, strings.Join(s.Regions, ", "))
+		}
+	}
+
+	id, err := strconv.Atoi(s.ID)
+	if err != nil {
+		return godo.DropletCreateImage{}, fmt.Errorf("snapshot %q has an unexpected ID %q", snapshot, s.ID)
+	}
+	return godo.DropletCreateImage{ID: id}, nil
+}
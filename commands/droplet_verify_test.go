@@ -0,0 +1,179 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// withVerifyTargets sends the verification checks for port 22 to sshAddr and
+// for port 80 to httpAddr, whatever the Droplet's address.
+func withVerifyTargets(t *testing.T, sshAddr, httpAddr string) {
+	prevDial, prevInterval := dialVerify, verifyPollInterval
+	t.Cleanup(func() { dialVerify, verifyPollInterval = prevDial, prevInterval })
+
+	verifyPollInterval = 10 * time.Millisecond
+	dialVerify = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		_, port, _ := net.SplitHostPort(addr)
+		if port == "22" {
+			addr = sshAddr
+		} else {
+			addr = httpAddr
+		}
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}
+}
+
+func fakeSSHServer(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("SSH-2.0-OpenSSH_8.9\r\n"))
+			conn.Close()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestParseVerifyHTTP(t *testing.T) {
+	tests := []struct {
+		in, port, path, err string
+	}{
+		{in: ":80/health", port: "80", path: "/health"},
+		{in: ":8080", port: "8080", path: "/"},
+		{in: "/ready?full=1", port: "80", path: "/ready?full=1"},
+		{in: ":http/health", err: `"http" is not a port`},
+		{in: "health", err: "expected a port and path"},
+	}
+
+	for _, tt := range tests {
+		port, path, err := parseVerifyHTTP(tt.in)
+		if tt.err != "" {
+			assert.ErrorContains(t, err, tt.err, tt.in)
+			continue
+		}
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.port, port, tt.in)
+		assert.Equal(t, tt.path, path, tt.in)
+	}
+}
+
+func TestDropletCreateFromSnapshot(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		dcr := &godo.DropletCreateRequest{
+			Name:    "web",
+			Region:  "dev1",
+			Size:    "1gb",
+			Image:   godo.DropletCreateImage{ID: 2},
+			SSHKeys: []godo.DropletCreateSSHKey{},
+		}
+		tm.snapshots.EXPECT().ListDroplet().Return(testSnapshotList, nil)
+		tm.droplets.EXPECT().Create(dcr, false).Return(&testDroplet, nil)
+
+		config.Args = append(config.Args, "web")
+		config.Doit.Set(config.NS, doctl.ArgRegionSlug, "dev1")
+		config.Doit.Set(config.NS, doctl.ArgSizeSlug, "1gb")
+		config.Doit.Set(config.NS, doctl.ArgFromSnapshot, "test-snapshot-2")
+
+		assert.NoError(t, RunDropletCreate(config))
+	})
+}
+
+func TestDropletCreateFromSnapshotErrors(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, "web")
+		config.Doit.Set(config.NS, doctl.ArgRegionSlug, "dev1")
+		config.Doit.Set(config.NS, doctl.ArgSizeSlug, "1gb")
+
+		assert.Equal(t, doctl.NewMissingArgsErr(config.NS+"."+doctl.ArgImage), RunDropletCreate(config))
+
+		config.Doit.Set(config.NS, doctl.ArgImage, "ubuntu-22-04-x64")
+		config.Doit.Set(config.NS, doctl.ArgFromSnapshot, "test-snapshot")
+		assert.EqualError(t, RunDropletCreate(config), "Only one of `--image` or `--from-snapshot` may be specified.")
+
+		config.Doit.Set(config.NS, doctl.ArgImage, "")
+		tm.snapshots.EXPECT().ListDroplet().Return(testSnapshotList, nil).Times(2)
+		assert.ErrorContains(t, RunDropletCreate(config), `snapshot "test-snapshot" is not available in dev1, only in dev0`)
+
+		config.Doit.Set(config.NS, doctl.ArgFromSnapshot, "golden")
+		err := RunDropletCreate(config)
+		assert.EqualError(t, err, `no Droplet snapshot named "golden"`)
+		assert.Equal(t, ExitNotFound, exitCode(err))
+	})
+}
+
+func TestDropletCreateVerify(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/health" {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer srv.Close()
+		withVerifyTargets(t, fakeSSHServer(t), srv.Listener.Addr().String())
+
+		tm.droplets.EXPECT().Create(gomock.Any(), true).Return(&testDroplet, nil)
+
+		config.Args = append(config.Args, "web")
+		config.Doit.Set(config.NS, doctl.ArgSizeSlug, "1gb")
+		config.Doit.Set(config.NS, doctl.ArgImage, "ubuntu-22-04-x64")
+		config.Doit.Set(config.NS, doctl.ArgVerifySSH, true)
+		config.Doit.Set(config.NS, doctl.ArgVerifyHTTP, ":80/health")
+		config.Doit.Set(config.NS, doctl.ArgVerifyTimeout, time.Second)
+
+		assert.NoError(t, RunDropletCreate(config))
+	})
+}
+
+func TestDropletCreateVerifyFailure(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+		withVerifyTargets(t, fakeSSHServer(t), srv.Listener.Addr().String())
+
+		tm.droplets.EXPECT().Create(gomock.Any(), true).Return(&testDroplet, nil)
+		tm.droplets.EXPECT().Delete(testDroplet.ID).Return(nil)
+
+		config.Args = append(config.Args, "web")
+		config.Doit.Set(config.NS, doctl.ArgSizeSlug, "1gb")
+		config.Doit.Set(config.NS, doctl.ArgImage, "ubuntu-22-04-x64")
+		config.Doit.Set(config.NS, doctl.ArgVerifyHTTP, ":80/health")
+		config.Doit.Set(config.NS, doctl.ArgVerifyTimeout, 50*time.Millisecond)
+		config.Doit.Set(config.NS, doctl.ArgDestroyOnFailure, true)
+
+		err := RunDropletCreate(config)
+		assert.ErrorContains(t, err, "failed the HTTP :80/health check within 50ms: GET /health returned 503 Service Unavailable")
+		assert.Equal(t, ExitTimeout, exitCode(err))
+	})
+}
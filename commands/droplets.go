@@ -14,22 +14,33 @@ limitations under the License.
 package commands
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"text/template"
+	"time"
 
 	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/commands/displayers"
 	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/doctl/pkg/bulk"
 	"github.com/digitalocean/godo"
 	"github.com/gobwas/glob"
 	"github.com/spf13/cobra"
 )
 
+// dropletPollInterval is how often a Droplet being created is checked. It is
+// replaced for testing.
+var dropletPollInterval = 5 * time.Second
+
 // Droplet creates the droplet command.
 func Droplet() *Command {
 	cmd := &Command{
@@ -69,7 +80,13 @@ func Droplet() *Command {
 
 To retrieve a list of size slugs, use the ` + "`" + `doctl compute size list` + "`" + ` command. To retrieve a list of image slugs, use the ` + "`" + `doctl compute image list` + "`" + ` command.
 
-If you do not specify a region, the Droplet is created in the default region for your account. If you do not specify any SSH keys, we email a temporary password to your account's email address.`
+If you do not specify a region, the Droplet is created in the default region for your account. If you do not specify any SSH keys, we email a temporary password to your account's email address.
+
+To deploy the same Droplet to several regions at once, use ` + "`" + `--regions` + "`" + ` instead of ` + "`" + `--region` + "`" + `. One Droplet per region is created concurrently, named after the region, such as ` + "`" + `web-nyc3` + "`" + ` and ` + "`" + `web-fra1` + "`" + `, and all of them are tagged with the base name so they can be managed as a group. Combined with ` + "`" + `--dns` + "`" + `, each regional Droplet gets its own A and AAAA records, such as ` + "`" + `web-nyc3.example.com` + "`" + `, which a latency-aware resolver or load balancer can target.
+
+To retry creates safely, pass an ` + "`" + `--idempotency-key` + "`" + `. If a create with a key fails on the server or the network, doctl checks whether the Droplet was made anyway before retrying, so that retries don't create duplicates. Running the whole command again with the same key, for example in a CI pipeline that retries failed jobs, is safe too.
+
+Progress is checkpointed when several Droplets are created. If the command is interrupted or some creates fail, it prints a checkpoint file to run the same command again with, using ` + "`" + `--resume` + "`" + `, which creates only the Droplets that remain.`
 
 	cmdDropletCreate := CmdBuilder(cmd, RunDropletCreate, "create <droplet-name>...", "Create a new Droplet", dropletCreateLongDesc, Writer,
 		aliasOpt("c"), displayerType(&displayers.Droplet{}))
@@ -78,26 +95,38 @@ If you do not specify a region, the Droplet is created in the default region for
 	AddStringFlag(cmdDropletCreate, doctl.ArgUserDataFile, "", "", "The path to a file containing a shell script or Cloud-init YAML file to run on the Droplet's first boot. Example: `path/to/file.yaml`")
 	AddBoolFlag(cmdDropletCreate, doctl.ArgCommandWait, "", false, "Instructs the terminal to wait for the action to complete before returning access to the user")
 	AddStringFlag(cmdDropletCreate, doctl.ArgRegionSlug, "", "", "A slug specifying the region to create the Droplet in, such as `nyc1`. Use the `doctl compute region list` command for a list of valid regions.")
+	AddStringSliceFlag(cmdDropletCreate, doctl.ArgDropletRegions, "", []string{}, "A list of regions to create an identical Droplet in, such as `nyc3,fra1,sgp1`. Cannot be combined with `--region`, `--volumes`, or `--vpc-uuid`.")
 	AddStringFlag(cmdDropletCreate, doctl.ArgSizeSlug, "", "", "A slug indicating the Droplet's number of vCPUs, RAM, and disk size. For example, `s-1vcpu-1gb` specifies a Droplet with one vCPU and 1 GiB of RAM. The disk size is defined by the slug's plan. Run `doctl compute size list` for a list of valid size slugs and their disk sizes.",
 		requiredOpt())
 	AddBoolFlag(cmdDropletCreate, doctl.ArgBackups, "", false, "Enables backups for the Droplet. Backups are created on a weekly basis.")
 	AddBoolFlag(cmdDropletCreate, doctl.ArgIPv6, "", false, "Enables IPv6 support and assigns an IPv6 address to the Droplet")
 	AddBoolFlag(cmdDropletCreate, doctl.ArgPrivateNetworking, "", false, "Enables private networking for the Droplet by provisioning it inside of your account's default VPC for the region")
 	AddBoolFlag(cmdDropletCreate, doctl.ArgMonitoring, "", false, "Installs the DigitalOcean agent for additional monitoring")
-	AddStringFlag(cmdDropletCreate, doctl.ArgImage, "", "", "An ID or slug specifying the image to use to create the Droplet, such as `ubuntu-20-04-x64`. Use the commands under `doctl compute image` to find additional images.",
-		requiredOpt())
+	AddStringFlag(cmdDropletCreate, doctl.ArgImage, "", "", "An ID or slug specifying the image to use to create the Droplet, such as `ubuntu-20-04-x64`. Use the commands under `doctl compute image` to find additional images. Required unless `--from-snapshot` is given.")
+	AddStringFlag(cmdDropletCreate, doctl.ArgFromSnapshot, "", "", "The name or ID of a Droplet snapshot to create the Droplet from, instead of `--image`. The snapshot must be available in the Droplet's region.")
 	AddStringFlag(cmdDropletCreate, doctl.ArgTagName, "", "", "Applies a tag to the Droplet")
 	AddStringFlag(cmdDropletCreate, doctl.ArgVPCUUID, "", "", "The UUID of a non-default VPC to create the Droplet in")
 	AddStringFlag(cmdDropletCreate, doctl.ArgProjectID, "", "", "The UUID of the project to assign the Droplet to")
 	AddStringSliceFlag(cmdDropletCreate, doctl.ArgTagNames, "", []string{}, "Applies a list of tags to the Droplet")
 	AddBoolFlag(cmdDropletCreate, doctl.ArgDropletAgent, "", false, "Specifies whether or not the Droplet monitoring agent should be installed. By default, the agent is installed on new Droplets but installation errors are ignored. Set `--droplet-agent=false` to prevent installation. Set to `true` to make installation errors fatal.")
 	AddStringSliceFlag(cmdDropletCreate, doctl.ArgVolumeList, "", []string{}, "A list of block storage volume IDs to attach to the Droplet")
+	AddStringFlag(cmdDropletCreate, doctl.ArgDropletDNS, "", "", "A domain managed by DigitalOcean to create A and AAAA records in, named after the Droplet. Implies `--wait`.")
+	AddBoolFlag(cmdDropletCreate, doctl.ArgVerifySSH, "", false, "Check that the Droplet answers on SSH port 22 before reporting success. Implies `--wait`.")
+	AddStringFlag(cmdDropletCreate, doctl.ArgVerifyHTTP, "", "", "Check that an HTTP GET of this port and path on the Droplet, such as `:80/health`, succeeds before reporting success. Implies `--wait`.")
+	AddDurationFlag(cmdDropletCreate, doctl.ArgVerifyTimeout, "", 5*time.Minute, "How long to wait for the Droplet to pass the `--verify-ssh` and `--verify-http` checks")
+	AddBoolFlag(cmdDropletCreate, doctl.ArgDestroyOnFailure, "", false, "Delete Droplets that fail the `--verify-ssh` or `--verify-http` checks")
+	AddStringFlag(cmdDropletCreate, doctl.ArgIdempotencyKey, "", "", "A key that identifies this request, such as a CI job ID. Droplets already created with the key, for example by an earlier run that failed, are returned instead of created again. The Droplets are tagged with `doctl-idempotency:` followed by the key.")
+	AddStringFlag(cmdDropletCreate, doctl.ArgResume, "", "", "Resume an interrupted create from its checkpoint file. The names and flags must be those of the interrupted run")
 	cmdDropletCreate.Example = `The following example creates a Droplet named ` + "`" + `example-droplet` + "`" + ` with a two vCPUs, two GiB of RAM, and 20 GBs of disk space. The Droplet is created in the ` + "`" + `nyc1` + "`" + ` region and is based on the ` + "`" + `ubuntu-20-04-x64` + "`" + ` image. Additionally, the command uses the ` + "`" + `--user-data` + "`" + ` flag to run a Bash script the first time the Droplet boots up: doctl compute droplet create example-droplet --size s-2vcpu-2gb --image ubuntu-20-04-x64 --region nyc1 --user-data $'#!/bin/bash\n touch /root/example.txt; sudo apt update;sudo snap install doctl'`
 
-	cmdRunDropletDelete := CmdBuilder(cmd, RunDropletDelete, "delete <droplet-id|droplet-name>...", "Permanently delete a Droplet", `Permanently deletes a Droplet. This is irreversible.`, Writer,
+	cmdRunDropletDelete := CmdBuilder(cmd, RunDropletDelete, "delete <droplet-id|droplet-name>...", "Permanently delete a Droplet", `Permanently deletes a Droplet. This is irreversible.
+
+Progress is checkpointed when several Droplets are deleted. If the command is interrupted or some Droplets fail, it prints a checkpoint file to run it again with, using `+"`"+`--resume`+"`"+`, which deletes only the Droplets that remain.`, Writer,
 		aliasOpt("d", "del", "rm"))
 	AddBoolFlag(cmdRunDropletDelete, doctl.ArgForce, doctl.ArgShortForce, false, "Deletes the Droplet without a confirmation prompt")
 	AddStringFlag(cmdRunDropletDelete, doctl.ArgTagName, "", "", "Tag name")
+	AddBoolFlag(cmdRunDropletDelete, doctl.ArgDropletDNSCleanup, "", false, "Deletes the A and AAAA records named after the Droplet that point to its addresses")
+	AddStringFlag(cmdRunDropletDelete, doctl.ArgResume, "", "", "Resume an interrupted deletion from its checkpoint file. The Droplets are those of the interrupted run")
 	cmdRunDropletDelete.Example = `The following example deletes a Droplet with the ID ` + "`" + `386734086` + "`" + `: doctl compute droplet delete 386734086`
 
 	cmdRunDropletGet := CmdBuilder(cmd, RunDropletGet, "get <droplet-id|droplet-name>", "Retrieve information about a Droplet", `Retrieves information about a Droplet, including:`+dropletDetails, Writer,
@@ -109,10 +138,13 @@ If you do not specify a region, the Droplet is created in the default region for
 		aliasOpt("k"), displayerType(&displayers.Kernel{}))
 	cmdDropletKernels.Example = `The following example retrieves a list of available kernels for a Droplet with the ID ` + "`" + `386734086` + "`" + `: doctl compute droplet kernels 386734086`
 
-	cmdRunDropletList := CmdBuilder(cmd, RunDropletList, "list [GLOB]", "List Droplets on your account", `Retrieves a list of Droplets on your account, including the following information about each:`+dropletDetails, Writer,
+	cmdRunDropletList := CmdBuilder(cmd, RunDropletList, "list [GLOB]", "List Droplets on your account", `Retrieves a list of Droplets on your account, including the following information about each:`+dropletDetails+`
+Droplets are fetched a page at a time and displayed as they arrive, so that long lists don't have to be held in memory. To page through Droplets explicitly, use `+"`"+`--limit`+"`"+`: if more Droplets match, a cursor is written to standard error, and passing it to `+"`"+`--cursor`+"`"+` lists the next ones. The cursor is a position in the list, so Droplets created or deleted between calls can shift what the next call lists.`, Writer,
 		aliasOpt("ls"), displayerType(&displayers.Droplet{}))
 	AddStringFlag(cmdRunDropletList, doctl.ArgRegionSlug, "", "", "Retrieves a list of Droplets in a specified region")
 	AddStringFlag(cmdRunDropletList, doctl.ArgTagName, "", "", "Retrieves a list of Droplets with the specified tag name")
+	AddIntFlag(cmdRunDropletList, doctl.ArgListLimit, "", 0, "The largest number of Droplets to list. If more match, a cursor to list them with is written to standard error.")
+	AddStringFlag(cmdRunDropletList, doctl.ArgListCursor, "", "", "Continues an earlier listing with the cursor it wrote to standard error")
 	cmdRunDropletList.Example = `The following example retrieves a list of all Droplets in the ` + "`" + `nyc1` + "`" + ` region: doctl compute droplet list --region nyc1`
 
 	cmdDropletNeighbors := CmdBuilder(cmd, RunDropletNeighbors, "neighbors <droplet-id>", "List a Droplet's neighbors on your account", `Lists your Droplets that are on the same physical hardware, including the following details:`+dropletDetails, Writer,
@@ -132,6 +164,85 @@ If you do not specify a region, the Droplet is created in the default region for
 	AddStringSliceFlag(cmdRunDropletUntag, doctl.ArgTagName, "", []string{}, "The tag name to remove from Droplet")
 	cmdRunDropletUntag.Example = `The following example removes the tag ` + "`" + `frontend` + "`" + ` from a Droplet with the ID ` + "`" + `386734086` + "`" + `: doctl compute droplet untag 386734086 --tag-name frontend`
 
+	cmdDropletExec := CmdBuilder(cmd, RunDropletExec, "exec <command>...", "Run a command on many Droplets over SSH", `Runs a shell command over SSH on every Droplet with one of the given tags, on several Droplets at a time.
+
+Each line of output is prefixed with the name of the Droplet it came from. The command does not get any input. doctl exits with an error if the command could not be run or exited with a non-zero status on any Droplet. Put `+"`"+`--`+"`"+` before the command so that its flags are not taken as doctl flags.`, Writer)
+	AddStringSliceFlag(cmdDropletExec, doctl.ArgTag, "", []string{}, "Runs the command on the Droplets with this tag. Can be repeated", requiredOpt())
+	AddStringFlag(cmdDropletExec, doctl.ArgSSHUser, "", "root", "SSH user for connection")
+	AddStringFlag(cmdDropletExec, doctl.ArgsSSHKeyPath, "", defaultSSHKeyPath("id_rsa"), "Path to SSH private key")
+	AddIntFlag(cmdDropletExec, doctl.ArgsSSHPort, "", 22, "The remote port sshd is running on")
+	AddBoolFlag(cmdDropletExec, doctl.ArgsSSHPrivateIP, "", false, "Connect to the Droplets' private IP addresses")
+	AddIntFlag(cmdDropletExec, doctl.ArgExecConcurrency, "", 10, "The number of Droplets to run the command on at the same time")
+	cmdDropletExec.Example = `The following example restarts nginx on all Droplets tagged ` + "`" + `web` + "`" + `, five at a time: doctl compute droplet exec --tag web --concurrency 5 -- 'systemctl restart nginx'`
+
+	cmdDropletSetPTR := CmdBuilder(cmd, RunDropletSetPTR, "set-ptr <droplet-id|droplet-name> <hostname>", "Set the reverse DNS name of a Droplet", `Sets the PTR records of a Droplet's public IPv4 and IPv6 addresses to a fully qualified domain name.
+
+DigitalOcean creates PTR records from the Droplet's name, so this command renames the Droplet to the hostname. With `+"`"+`--wait`+"`"+`, it then checks that the addresses resolve back to the hostname and that the hostname resolves to the addresses. The forward A and AAAA records must exist separately, for example created with `+"`"+`doctl compute domain records create`+"`"+`.`, Writer,
+		displayerType(&displayers.DropletPTRs{}))
+	AddBoolFlag(cmdDropletSetPTR, doctl.ArgCommandWait, "", true, "Wait for the rename to complete and verify the resulting reverse DNS")
+	cmdDropletSetPTR.Example = `The following example sets the reverse DNS name of the Droplet with the ID ` + "`" + `386734086` + "`" + ` to ` + "`" + `mail.example.com` + "`" + `: doctl compute droplet set-ptr 386734086 mail.example.com`
+
+	cmdDropletDecommission := CmdBuilder(cmd, RunDropletDecommission, "decommission <droplet-id|droplet-name>", "Gracefully take a Droplet out of service and delete it", `Takes a Droplet out of service step by step before permanently deleting it:
+
+- removes it from every load balancer it belongs to, untagging it for load balancers that select Droplets by tag
+- waits for `+"`"+`--drain-timeout`+"`"+` so that open connections can finish
+- unassigns its reserved IPs and detaches its volumes, which are kept
+- takes a snapshot, if `+"`"+`--snapshot`+"`"+` is set
+- deletes the A and AAAA records in your DigitalOcean domains that point to its addresses
+- destroys the Droplet
+
+If a step fails, the command stops and the Droplet is not destroyed.`, Writer)
+	AddDurationFlag(cmdDropletDecommission, doctl.ArgDropletDrainTimeout, "", 30*time.Second, "How long to wait for connections to drain after removing the Droplet from load balancers")
+	AddBoolFlag(cmdDropletDecommission, doctl.ArgDropletSnapshot, "", false, "Take a snapshot of the Droplet before destroying it")
+	AddStringFlag(cmdDropletDecommission, doctl.ArgSnapshotName, "", "", "The name of the snapshot. Defaults to the Droplet's name followed by `-decommissioned`")
+	AddBoolFlag(cmdDropletDecommission, doctl.ArgForce, doctl.ArgShortForce, false, "Decommission the Droplet without a confirmation prompt")
+	cmdDropletDecommission.Example = `The following example drains the Droplet ` + "`" + `web-1` + "`" + ` for two minutes, snapshots it, and deletes it: doctl compute droplet decommission web-1 --drain-timeout 2m --snapshot`
+
+	cmdDropletReboot := CmdBuilder(cmd, RunDropletReboot, "reboot [<droplet-id|droplet-name>...]", "Reboot many Droplets, optionally in rolling batches", `Reboots each Droplet given by ID or name or with `+"`"+`--tag`+"`"+`, waiting for the reboots to complete.
+
+With `+"`"+`--rolling`+"`"+`, the Droplets are rebooted `+"`"+`--batch`+"`"+` at a time. With `+"`"+`--wait-healthy`+"`"+`, each batch must pass the health check of the given load balancer before the next one is rebooted, and the command stops if a batch does not pass within `+"`"+`--timeout`+"`"+`. The API doesn't report the health of individual Droplets, so doctl runs the load balancer's health check itself, using its protocol, port, path, interval, and healthy threshold, against each Droplet's public IPv4 address. Firewalls must allow these checks from where doctl runs.`, Writer,
+		displayerType(&displayers.Action{}))
+	AddStringSliceFlag(cmdDropletReboot, doctl.ArgTag, "", []string{}, "Reboots the Droplets with this tag. Can be repeated")
+	AddBoolFlag(cmdDropletReboot, doctl.ArgDropletRolling, "", false, "Reboot the Droplets in batches rather than all at once")
+	AddIntFlag(cmdDropletReboot, doctl.ArgDropletBatch, "", 1, "The number of Droplets to reboot at a time with `--rolling`")
+	AddStringFlag(cmdDropletReboot, doctl.ArgDropletWaitHealthy, "", "", "The ID of a load balancer whose health check each batch must pass before the next one is rebooted")
+	AddDurationFlag(cmdDropletReboot, doctl.ArgTimeout, "", 10*time.Minute, "How long to wait for each batch to pass the health check")
+	cmdDropletReboot.Example = `The following example reboots the Droplets tagged ` + "`" + `web` + "`" + ` two at a time, waiting for each pair to pass the health check of a load balancer before moving on: doctl compute droplet reboot --tag web --rolling --batch 2 --wait-healthy 4de7ac8b-495b-4884-9a69-1050c6793cd6`
+
+	cmdDropletPTRReport := CmdBuilder(cmd, RunDropletPTRReport, "ptr-report", "List Droplets with mismatched reverse DNS", `Lists the public addresses of your Droplets whose PTR record is missing, names a host that does not resolve, or names a host that resolves to other addresses.
+
+Mail servers in particular often reject mail from hosts whose reverse and forward DNS do not match.`, Writer,
+		displayerType(&displayers.DropletPTRs{}))
+	AddBoolFlag(cmdDropletPTRReport, doctl.ArgPTRReportAll, "", false, "Include addresses whose reverse DNS matches")
+
+	cmdDropletEnableIPv6 := CmdBuilder(cmd, RunDropletEnableIPv6, "enable-ipv6 [<droplet-id|droplet-name>...]", "Enable IPv6 on many Droplets", `Enables IPv6 on each Droplet given by ID or name or with `+"`"+`--tag`+"`"+`, skipping Droplets that already have it.
+
+IPv6 addresses are not added to DNS. Run `+"`"+`doctl compute droplet ipv6-audit --create-missing`+"`"+` afterwards to add AAAA records alongside the Droplets' A records.`, Writer,
+		displayerType(&displayers.Action{}))
+	AddStringSliceFlag(cmdDropletEnableIPv6, doctl.ArgTag, "", []string{}, "Enables IPv6 on the Droplets with this tag. Can be repeated")
+	AddBoolFlag(cmdDropletEnableIPv6, doctl.ArgCommandWait, "", false, "Wait for IPv6 to be enabled on every Droplet")
+	cmdDropletEnableIPv6.Example = `The following example enables IPv6 on all Droplets tagged ` + "`" + `web` + "`" + ` and waits for it to finish: doctl compute droplet enable-ipv6 --tag web --wait`
+
+	cmdDropletIPv6Audit := CmdBuilder(cmd, RunDropletIPv6Audit, "ipv6-audit [<droplet-id|droplet-name>...]", "List Droplets missing AAAA records", `Lists the Droplets whose IPv6 addresses are missing from DNS. Every A record in your domains that points to a Droplet should have an AAAA record of the same name that points to the Droplet's IPv6 address. A Droplet named after a host in one of the domains, such as `+"`"+`web1.example.com`+"`"+`, should have both records under that name.
+
+Droplets without IPv6 are listed too; enable it with `+"`"+`doctl compute droplet enable-ipv6`+"`"+`. AAAA records that point to other addresses are reported but never changed.`, Writer,
+		displayerType(&displayers.DropletIPv6Records{}))
+	AddStringSliceFlag(cmdDropletIPv6Audit, doctl.ArgTag, "", []string{}, "Audits the Droplets with this tag. Can be repeated")
+	AddStringSliceFlag(cmdDropletIPv6Audit, doctl.ArgIPv6AuditDomain, "", []string{}, "Checks only the records in this domain. Can be repeated. Defaults to all of your domains")
+	AddBoolFlag(cmdDropletIPv6Audit, doctl.ArgIPv6AuditCreateMissing, "", false, "Creates the missing AAAA records, with the TTL of the matching A record")
+	AddBoolFlag(cmdDropletIPv6Audit, doctl.ArgIPv6AuditAll, "", false, "Include records that are correct")
+	cmdDropletIPv6Audit.Example = `The following example adds the missing AAAA records for the Droplets tagged ` + "`" + `web` + "`" + ` in the domain ` + "`" + `example.com` + "`" + `: doctl compute droplet ipv6-audit --tag web --domain example.com --create-missing`
+
+	cmdDropletTop := CmdBuilder(cmd, RunDropletTop, "top [<droplet-id|name>...]", "Show the resource usage of Droplets", `Shows the CPU, memory, and disk usage and the public bandwidth of Droplets, refreshing the table until you press Ctrl-C.
+
+Specify Droplets by ID or name, or with `+"`"+`--tag`+"`"+`. With neither, all Droplets are shown. CPU usage is averaged over the last five minutes; the other columns show the latest sample. Droplets that do not run the metrics agent show no values.
+
+With `+"`"+`--output json`+"`"+`, a single snapshot is printed, for use in scripts.`, Writer,
+		displayerType(&displayers.DropletUsages{}))
+	AddStringSliceFlag(cmdDropletTop, doctl.ArgTag, "", []string{}, "Shows the Droplets with this tag. Can be repeated")
+	AddDurationFlag(cmdDropletTop, doctl.ArgInterval, "", 10*time.Second, "How often to refresh the table")
+	cmdDropletTop.Example = `The following example shows the resource usage of the Droplets tagged ` + "`" + `web` + "`" + `, refreshing every 30 seconds: doctl compute droplet top --tag web --interval 30s`
+
 	cmd.AddCommand(dropletOneClicks())
 
 	return cmd
@@ -181,7 +292,7 @@ func RunDropletCreate(c *CmdConfig) error {
 		return doctl.NewMissingArgsErr(c.NS)
 	}
 
-	region, err := c.Doit.GetString(c.NS, doctl.ArgRegionSlug)
+	region, err := regionFromArgs(c, doctl.ArgRegionSlug)
 	if err != nil {
 		return err
 	}
@@ -257,6 +368,26 @@ func RunDropletCreate(c *CmdConfig) error {
 	}
 	volumes := extractVolumes(volumeList)
 
+	regions, err := c.Doit.GetStringSlice(c.NS, doctl.ArgDropletRegions)
+	if err != nil {
+		return err
+	}
+	if len(regions) > 0 {
+		if c.Doit.IsSet(doctl.ArgRegionSlug) {
+			return fmt.Errorf("Only one of `--%s` or `--%s` may be specified.", doctl.ArgRegionSlug, doctl.ArgDropletRegions)
+		}
+		if len(volumeList) > 0 || vpcUUID != "" {
+			return fmt.Errorf("Volumes and VPCs belong to a single region and cannot be used with `--%s`.", doctl.ArgDropletRegions)
+		}
+		for i, r := range regions {
+			if regions[i], err = resolveRegionAlias(c, strings.TrimSpace(r)); err != nil {
+				return err
+			}
+		}
+	} else {
+		warnDropletRegionMismatch(c, region, volumeList, vpcUUID)
+	}
+
 	filename, err := c.Doit.GetString(c.NS, doctl.ArgUserDataFile)
 	if err != nil {
 		return err
@@ -272,6 +403,18 @@ func RunDropletCreate(c *CmdConfig) error {
 		return err
 	}
 
+	fromSnapshot, err := c.Doit.GetString(c.NS, doctl.ArgFromSnapshot)
+	if err != nil {
+		return err
+	}
+	switch {
+	case imageStr != "" && fromSnapshot != "":
+		return fmt.Errorf("Only one of `--%s` or `--%s` may be specified.", doctl.ArgImage, doctl.ArgFromSnapshot)
+	case imageStr == "" && fromSnapshot == "":
+		// --from-snapshot can stand in for it, but --image is the usual choice.
+		return doctl.NewMissingArgsErr(fmt.Sprintf("%s.%s", c.NS, doctl.ArgImage))
+	}
+
 	createImage := godo.DropletCreateImage{Slug: imageStr}
 
 	i, err := strconv.Atoi(imageStr)
@@ -284,15 +427,106 @@ func RunDropletCreate(c *CmdConfig) error {
 		return err
 	}
 
+	verifySSH, err := c.Doit.GetBool(c.NS, doctl.ArgVerifySSH)
+	if err != nil {
+		return err
+	}
+	verifyHTTP, err := c.Doit.GetString(c.NS, doctl.ArgVerifyHTTP)
+	if err != nil {
+		return err
+	}
+	checks, err := dropletChecks(verifySSH, verifyHTTP)
+	if err != nil {
+		return err
+	}
+	if len(checks) > 0 {
+		// The checks need the Droplet's address.
+		wait = true
+	}
+	verifyTimeout, err := c.Doit.GetDuration(c.NS, doctl.ArgVerifyTimeout)
+	if err != nil {
+		return err
+	}
+	destroy, err := c.Doit.GetBool(c.NS, doctl.ArgDestroyOnFailure)
+	if err != nil {
+		return err
+	}
+
+	dnsDomain, err := c.Doit.GetString(c.NS, doctl.ArgDropletDNS)
+	if err != nil {
+		return err
+	}
+	if dnsDomain != "" {
+		// The records can only be created once the Droplet has its addresses.
+		wait = true
+	}
+
+	idem, err := getIdempotency(c)
+	if err != nil {
+		return err
+	}
+	withoutAutomaticRetries(c, idem, func(godoClient *godo.Client) {
+		c.Droplets = func() do.DropletsService { return do.NewDropletsService(godoClient) }
+	})
+
 	ds := c.Droplets()
 
-	var wg sync.WaitGroup
+	placements := dropletPlacements(c.Args, region, regions)
+
+	if fromSnapshot != "" {
+		var placementRegions []string
+		for _, p := range placements {
+			placementRegions = append(placementRegions, p.region)
+		}
+		if createImage, err = snapshotImage(c, fromSnapshot, placementRegions); err != nil {
+			return err
+		}
+	}
+
+	// Droplets may share a name, so the second web is "web (2)".
+	names := make([]string, len(placements))
+	counts := map[string]int{}
+	for i, p := range placements {
+		counts[p.name]++
+		names[i] = p.name
+		if n := counts[p.name]; n > 1 {
+			names[i] = fmt.Sprintf("%s (%d)", p.name, n)
+		}
+	}
+	e, err := newBulkExecutor(c, dropletCreateOperation, names)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(e.Items(), names) {
+		return fmt.Errorf("the checkpoint is of creating %s; give the same names and regions to resume it", strings.Join(e.Items(), ", "))
+	}
+	// The Droplets that were created before the run was interrupted.
 	var createdList do.Droplets
-	errs := make(chan error, len(c.Args))
-	for _, name := range c.Args {
+	for _, name := range names {
+		if result, ok := e.Result(name); ok {
+			id, err := strconv.Atoi(result)
+			if err != nil {
+				return fmt.Errorf("the checkpoint has an invalid Droplet ID %q", result)
+			}
+			d, err := ds.Get(id)
+			if err != nil {
+				return err
+			}
+			createdList = append(createdList, *d)
+		}
+	}
+
+	var mu sync.Mutex
+	requests := make(map[string]*godo.DropletCreateRequest, len(placements))
+	for i, p := range placements {
+		tags := tagNames
+		if p.group != "" {
+			tags = append(append([]string{}, tagNames...), p.group)
+		}
+
 		dcr := &godo.DropletCreateRequest{
-			Name:              name,
-			Region:            region,
+			Name:              p.name,
+			Region:            p.region,
 			Size:              size,
 			Image:             createImage,
 			Volumes:           volumes,
@@ -303,34 +537,42 @@ func RunDropletCreate(c *CmdConfig) error {
 			SSHKeys:           sshKeys,
 			UserData:          userData,
 			VPCUUID:           vpcUUID,
-			Tags:              tagNames,
+			Tags:              idem.tags(tags),
 		}
 
 		if agent != nil {
 			dcr.WithDropletAgent = agent
 		}
-
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-
-			d, err := ds.Create(dcr, wait)
+		requests[names[i]] = dcr
+	}
+
+	e.Concurrency = len(placements)
+	err = runBulk(e, func(ctx context.Context) error {
+		return e.Run(ctx, func(name string) (string, error) {
+			dcr := requests[name]
+			d, err := idempotentCreate(idem, func() (*do.Droplet, error) {
+				return findCreatedDroplet(ds, idem, dcr, wait)
+			}, func() (*do.Droplet, error) {
+				return ds.Create(dcr, wait)
+			})
 			if err != nil {
-				errs <- err
-				return
+				return "", err
 			}
 
+			mu.Lock()
 			createdList = append(createdList, *d)
-		}()
+			mu.Unlock()
+			return strconv.Itoa(d.ID), nil
+		})
+	})
+	if err != nil {
+		return err
 	}
 
-	wg.Wait()
-	close(errs)
-
 	item := &displayers.Droplet{Droplets: createdList}
 
-	for err := range errs {
-		if err != nil {
+	if len(checks) > 0 {
+		if err := verifyDroplets(c, createdList, checks, verifyTimeout, destroy); err != nil {
 			return err
 		}
 	}
@@ -341,9 +583,58 @@ func RunDropletCreate(c *CmdConfig) error {
 		}
 	}
 
+	if dnsDomain != "" {
+		for _, createdDroplet := range createdList {
+			if err := createDropletDNSRecords(c, dnsDomain, createdDroplet); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := setGitHubOutputs(dropletGitHubOutputs(createdList)); err != nil {
+		return err
+	}
 	return c.Display(item)
 }
 
+// findCreatedDroplet looks for a Droplet already created for dcr with the
+// idempotency key, such as by a create that failed after the Droplet was made.
+func findCreatedDroplet(ds do.DropletsService, idem idempotency, dcr *godo.DropletCreateRequest, wait bool) (*do.Droplet, error) {
+	list, err := ds.ListByTag(idempotencyTagPrefix + idem.key)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range list {
+		if d.Name != dcr.Name || (dcr.Region != "" && d.Region != nil && d.Region.Slug != dcr.Region) {
+			continue
+		}
+		if !idem.owns(d.Tags) {
+			continue
+		}
+		notice("Droplet %s was already created, with ID %d", d.Name, d.ID)
+		if wait && d.Status == "new" {
+			return waitForActiveDroplet(ds, d.ID)
+		}
+		return &d, nil
+	}
+	return nil, nil
+}
+
+// waitForActiveDroplet polls a Droplet until it is no longer being created.
+func waitForActiveDroplet(ds do.DropletsService, id int) (*do.Droplet, error) {
+	for {
+		d, err := ds.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		if d.Status != "new" {
+			return d, nil
+		}
+		time.Sleep(dropletPollInterval)
+	}
+}
+
 // RunDropletTag adds a tag to a droplet.
 func RunDropletTag(c *CmdConfig) error {
 	ds := c.Droplets()
@@ -446,6 +737,46 @@ func extractUserData(userData, filename string) (string, error) {
 	return userData, nil
 }
 
+// dropletPlacement is a single Droplet to create: its name, region and, for
+// multi-region deployments, the tag shared by all of its regional copies.
+type dropletPlacement struct {
+	name   string
+	region string
+	group  string
+}
+
+// dropletPlacements expands the Droplet names given to `droplet create` into
+// one placement per name, or one per name and region when regions is set.
+func dropletPlacements(names []string, region string, regions []string) []dropletPlacement {
+	var placements []dropletPlacement
+	for _, name := range names {
+		if len(regions) == 0 {
+			placements = append(placements, dropletPlacement{name: name, region: region})
+			continue
+		}
+		for _, r := range regions {
+			placements = append(placements, dropletPlacement{
+				name:   name + "-" + r,
+				region: r,
+				group:  dropletGroupTag(name),
+			})
+		}
+	}
+	return placements
+}
+
+// dropletGroupTag derives a valid tag name from a Droplet name by replacing
+// the characters tags do not allow.
+func dropletGroupTag(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == ':', r == '-', r == '_':
+			return r
+		}
+		return '-'
+	}, name)
+}
+
 func extractVolumes(volumeList []string) []godo.DropletCreateVolume {
 	var volumes []godo.DropletCreateVolume
 
@@ -496,7 +827,17 @@ func RunDropletDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if len(c.Args) < 1 && tagName == "" {
+	dnsCleanup, err := c.Doit.GetBool(c.NS, doctl.ArgDropletDNSCleanup)
+	if err != nil {
+		return err
+	}
+
+	resume, err := c.Doit.GetString(c.NS, doctl.ArgResume)
+	if err != nil {
+		return err
+	}
+
+	if len(c.Args) < 1 && tagName == "" && resume == "" {
 		return doctl.NewMissingArgsErr(c.NS)
 	} else if len(c.Args) > 0 && tagName != "" {
 		return fmt.Errorf("Please specify Droplet identifier or a tag name.")
@@ -522,24 +863,71 @@ func RunDropletDelete(c *CmdConfig) error {
 		}
 
 		if force || AskForConfirm(fmt.Sprintf("delete %d %s tagged \"%s\"? [affected %s: %s]", len(list), resourceType, tagName, resourceType, affectedIDs)) == nil {
-			return ds.DeleteByTag(tagName)
+			if err := ds.DeleteByTag(tagName); err != nil {
+				return err
+			}
+			if dnsCleanup {
+				return deleteDropletDNSRecords(c, list)
+			}
+			return nil
 		}
 		return errOperationAborted
 	}
 
-	if force || AskForConfirmDelete("Droplet", len(c.Args)) == nil {
-
-		fn := func(ids []int) error {
-			for _, id := range ids {
-				if err := ds.Delete(id); err != nil {
-					return fmt.Errorf("Unable to delete Droplet %d: %v", id, err)
-				}
+	// A resumed deletion is of the Droplets of the interrupted run that
+	// remain, which can't be matched by name once some are deleted.
+	var e *bulk.Executor
+	targets := c.Args
+	if resume != "" {
+		if e, err = newBulkExecutor(c, dropletDeleteOperation, nil); err != nil {
+			return err
+		}
+		targets = e.Remaining()
+	}
+	if !force && AskForConfirmDelete("Droplet", len(targets), targets...) != nil {
+		return errOperationAborted
+	}
+	if e == nil {
+		err := matchDroplets(c.Args, ds, func(ids []int) error {
+			items := make([]string, len(ids))
+			for i, id := range ids {
+				items[i] = strconv.Itoa(id)
 			}
-			return nil
+			e, err = newBulkExecutor(c, dropletDeleteOperation, items)
+			return err
+		})
+		if err != nil {
+			return err
 		}
-		return matchDroplets(c.Args, ds, fn)
 	}
-	return errOperationAborted
+
+	// A failure to clean up the records of a deleted Droplet is reported,
+	// but the Droplet is done and not deleted again on resume.
+	var cleanupErrs []error
+	err = runBulk(e, func(ctx context.Context) error {
+		return e.Run(ctx, func(item string) (string, error) {
+			id, err := strconv.Atoi(item)
+			if err != nil {
+				return "", fmt.Errorf("the checkpoint has an invalid Droplet ID %q", item)
+			}
+			var deleted *do.Droplet
+			if dnsCleanup {
+				if deleted, err = ds.Get(id); err != nil {
+					return "", err
+				}
+			}
+			if err := ds.Delete(id); err != nil {
+				return "", fmt.Errorf("Unable to delete Droplet %d: %v", id, err)
+			}
+			if dnsCleanup {
+				if err := deleteDropletDNSRecords(c, do.Droplets{*deleted}); err != nil {
+					cleanupErrs = append(cleanupErrs, err)
+				}
+			}
+			return "", nil
+		})
+	})
+	return errors.Join(append([]error{err}, cleanupErrs...)...)
 }
 
 type matchDropletsFn func(ids []int) error
@@ -641,6 +1029,33 @@ func RunDropletKernels(c *CmdConfig) error {
 	return c.Display(item)
 }
 
+// listCursorOut is where list commands write the cursor of the next items.
+// It is replaced for testing.
+var listCursorOut io.Writer = os.Stderr
+
+// encodeListCursor returns the cursor that continues a listing with the item
+// at offset.
+func encodeListCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+// decodeListCursor returns the offset a cursor continues a listing at. An
+// empty cursor starts at the beginning.
+func decodeListCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if s, ok := strings.CutPrefix(string(b), "offset:"); ok {
+			if offset, err := strconv.Atoi(s); err == nil && offset >= 0 {
+				return offset, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid cursor %q; use the cursor printed by an earlier listing", cursor)
+}
+
 // RunDropletList returns a list of droplets.
 func RunDropletList(c *CmdConfig) error {
 
@@ -656,6 +1071,23 @@ func RunDropletList(c *CmdConfig) error {
 		return err
 	}
 
+	limit, err := c.Doit.GetInt(c.NS, doctl.ArgListLimit)
+	if err != nil {
+		return err
+	}
+	if limit < 0 {
+		return fmt.Errorf("`--%s` must be a positive number", doctl.ArgListLimit)
+	}
+
+	cursor, err := c.Doit.GetString(c.NS, doctl.ArgListCursor)
+	if err != nil {
+		return err
+	}
+	offset, err := decodeListCursor(cursor)
+	if err != nil {
+		return err
+	}
+
 	matches := make([]glob.Glob, 0, len(c.Args))
 	for _, globStr := range c.Args {
 		g, err := glob.Compile(globStr)
@@ -666,43 +1098,75 @@ func RunDropletList(c *CmdConfig) error {
 		matches = append(matches, g)
 	}
 
-	var matchedList do.Droplets
-
-	var list do.Droplets
-	if tagName == "" {
-		list, err = ds.List()
-	} else {
-		list, err = ds.ListByTag(tagName)
+	match := func(droplet do.Droplet) bool {
+		if region != "" && region != droplet.Region.Slug {
+			return false
+		}
+		if len(matches) == 0 {
+			return true
+		}
+		for _, m := range matches {
+			if m.Match(droplet.Name) {
+				return true
+			}
+		}
+		return false
 	}
+
+	// Each page of Droplets is displayed as it is fetched rather than once
+	// all of them are, and the slice of matching Droplets is reused.
+	all := &displayers.Droplet{}
+	pages, err := c.DisplayPages(all, func(page displayers.Displayable) {
+		all.Droplets = append(all.Droplets, page.(*displayers.Droplet).Droplets...)
+	})
 	if err != nil {
 		return err
 	}
 
-	for _, droplet := range list {
-		var skip = true
-		if len(matches) == 0 {
-			skip = false
-		} else {
-			for _, m := range matches {
-				if m.Match(droplet.Name) {
-					skip = false
-				}
+	var (
+		matched do.Droplets
+		listed  int
+		scanned int
+		next    string
+	)
+	err = ds.ListPages(tagName, offset, func(list do.Droplets) error {
+		matched = matched[:0]
+		for i, droplet := range list {
+			if !match(droplet) {
+				continue
+			}
+			// The listing stops at the first Droplet past the limit, so
+			// that a cursor is only given if there are more Droplets.
+			if limit > 0 && listed == limit {
+				next = encodeListCursor(offset + scanned + i)
+				break
 			}
+			matched = append(matched, droplet)
+			listed++
 		}
+		scanned += len(list)
 
-		if !skip && region != "" {
-			if region != droplet.Region.Slug {
-				skip = true
+		if len(matched) > 0 {
+			if err := pages.DisplayPage(&displayers.Droplet{Droplets: matched}); err != nil {
+				return err
 			}
 		}
-
-		if !skip {
-			matchedList = append(matchedList, droplet)
+		if next != "" {
+			return do.ErrStopPaging
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := pages.Close(); err != nil {
+		return err
 	}
 
-	item := &displayers.Droplet{Droplets: matchedList}
-	return c.Display(item)
+	if next != "" {
+		fmt.Fprintf(listCursorOut, "More Droplets are available. To list them, use: --%s %s\n", doctl.ArgListCursor, next)
+	}
+	return nil
 }
 
 // RunDropletNeighbors returns a list of droplet neighbors.
@@ -15,14 +15,20 @@ package commands
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
 	"github.com/digitalocean/doctl/do"
 	"github.com/digitalocean/godo"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 var (
@@ -53,7 +59,7 @@ var (
 func TestDropletCommand(t *testing.T) {
 	cmd := Droplet()
 	assert.NotNil(t, cmd)
-	assertCommandNames(t, cmd, "1-click", "actions", "backups", "create", "delete", "get", "kernels", "list", "neighbors", "snapshots", "tag", "untag")
+	assertCommandNames(t, cmd, "1-click", "actions", "backups", "create", "decommission", "delete", "enable-ipv6", "exec", "get", "ipv6-audit", "kernels", "list", "neighbors", "ptr-report", "reboot", "set-ptr", "snapshots", "tag", "top", "untag")
 }
 
 func TestDropletActionList(t *testing.T) {
@@ -101,6 +107,8 @@ func TestDropletCreate(t *testing.T) {
 			Tags:              []string{"one", "two"},
 		}
 		tm.droplets.EXPECT().Create(dcr, false).Return(&testDroplet, nil)
+		tm.volumes.EXPECT().Get(volumeUUID).Return(&do.Volume{Volume: &godo.Volume{ID: volumeUUID, Region: &godo.Region{Slug: "dev0"}}}, nil)
+		tm.vpcs.EXPECT().Get(vpcUUID).Return(&do.VPC{VPC: &godo.VPC{RegionSlug: "dev0"}}, nil)
 
 		config.Args = append(config.Args, "droplet")
 
@@ -117,6 +125,57 @@ func TestDropletCreate(t *testing.T) {
 	})
 }
 
+func TestDropletCreateMultiRegion(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		for _, region := range []string{"nyc3", "fra1", "ams3"} {
+			dcr := &godo.DropletCreateRequest{
+				Name:    "web-" + region,
+				Region:  region,
+				Size:    "1gb",
+				Image:   godo.DropletCreateImage{Slug: "image"},
+				SSHKeys: []godo.DropletCreateSSHKey{},
+				Tags:    []string{"prod", "web"},
+			}
+			tm.droplets.EXPECT().Create(dcr, false).Return(&testDroplet, nil)
+		}
+
+		config.Args = append(config.Args, "web")
+		config.Doit.Set("", doctl.ArgRegionAliases, map[string]string{"backup": "ams3"})
+		config.Doit.Set(config.NS, doctl.ArgDropletRegions, []string{"nyc3", "fra1", "backup"})
+		config.Doit.Set(config.NS, doctl.ArgSizeSlug, "1gb")
+		config.Doit.Set(config.NS, doctl.ArgImage, "image")
+		config.Doit.Set(config.NS, doctl.ArgTagNames, []string{"prod"})
+
+		err := RunDropletCreate(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestDropletCreateMultiRegionConflicts(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, "web")
+		config.Doit.Set(config.NS, doctl.ArgDropletRegions, []string{"nyc3", "fra1"})
+		config.Doit.Set(config.NS, doctl.ArgRegionSlug, "nyc3")
+
+		err := RunDropletCreate(config)
+		assert.ErrorContains(t, err, "--regions")
+	})
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, "web")
+		config.Doit.Set(config.NS, doctl.ArgDropletRegions, []string{"nyc3", "fra1"})
+		config.Doit.Set(config.NS, doctl.ArgVolumeList, []string{"test-volume"})
+
+		err := RunDropletCreate(config)
+		assert.ErrorContains(t, err, "single region")
+	})
+}
+
+func TestDropletGroupTag(t *testing.T) {
+	assert.Equal(t, "web", dropletGroupTag("web"))
+	assert.Equal(t, "web1-example-com", dropletGroupTag("web1.example.com"))
+}
+
 func TestDropletCreateWithTag(t *testing.T) {
 	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
 		dcr := &godo.DropletCreateRequest{
@@ -280,6 +339,36 @@ func TestDropletDeleteRepeatedID(t *testing.T) {
 	})
 }
 
+func TestDropletDeleteResume(t *testing.T) {
+	var out bytes.Buffer
+	prev := bulkOut
+	bulkOut = &out
+	t.Cleanup(func() { bulkOut = prev })
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		gomock.InOrder(
+			tm.droplets.EXPECT().Delete(1).Return(nil),
+			tm.droplets.EXPECT().Delete(2).Return(errors.New("rate limited")),
+			tm.droplets.EXPECT().Delete(3).Return(nil),
+			tm.droplets.EXPECT().Delete(2).Return(nil),
+		)
+
+		config.Args = append(config.Args, "1", "2", "3")
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+
+		err := RunDropletDelete(config)
+		assert.EqualError(t, err, "Unable to delete Droplet 2: rate limited")
+		assert.Contains(t, out.String(), "1 of 3 items are not done. To retry them, run the command again with: --resume ")
+		checkpoint := strings.TrimSpace(out.String()[strings.Index(out.String(), "--resume ")+len("--resume "):])
+
+		config.Args = nil
+		config.Doit.Set(config.NS, doctl.ArgResume, checkpoint)
+		err = RunDropletDelete(config)
+		assert.NoError(t, err)
+		assert.NoFileExists(t, checkpoint)
+	})
+}
+
 func TestDropletDeleteByName(t *testing.T) {
 	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
 		tm.droplets.EXPECT().List().Return(testDropletList, nil)
@@ -378,18 +467,70 @@ func TestDropletSnapshotList(t *testing.T) {
 	})
 }
 
+// listDropletPages returns the ListPages of a mock that lists pages of
+// Droplets, starting at the offset it is passed.
+func listDropletPages(pages ...do.Droplets) func(string, int, func(do.Droplets) error) error {
+	return func(_ string, offset int, fn func(do.Droplets) error) error {
+		for _, page := range pages {
+			if offset >= len(page) {
+				offset -= len(page)
+				continue
+			}
+			err := fn(page[offset:])
+			offset = 0
+			if errors.Is(err, do.ErrStopPaging) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 func TestDropletsList(t *testing.T) {
 	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
-		tm.droplets.EXPECT().List().Return(testDropletList, nil)
+		tm.droplets.EXPECT().ListPages("", 0, gomock.Any()).DoAndReturn(listDropletPages(testDropletList))
 
 		err := RunDropletList(config)
 		assert.NoError(t, err)
 	})
 }
 
+func TestDropletsListNDJSON(t *testing.T) {
+	prev := viper.GetString(doctl.ArgOutput)
+	viper.Set(doctl.ArgOutput, "ndjson")
+	defer viper.Set(doctl.ArgOutput, prev)
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var out bytes.Buffer
+		config.Out = &out
+
+		// Each page is written before the next one is fetched.
+		tm.droplets.EXPECT().ListPages("my-tag", 0, gomock.Any()).DoAndReturn(func(_ string, _ int, fn func(do.Droplets) error) error {
+			if err := fn(do.Droplets{testDroplet}); err != nil {
+				return err
+			}
+			assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+			return fn(do.Droplets{anotherTestDroplet})
+		})
+
+		config.Doit.Set(config.NS, doctl.ArgTagName, "my-tag")
+
+		err := RunDropletList(config)
+		assert.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[0], `"name":"a-droplet"`)
+		assert.Contains(t, lines[1], `"name":"another-droplet"`)
+	})
+}
+
 func TestDropletsListByTag(t *testing.T) {
 	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
-		tm.droplets.EXPECT().ListByTag("my-tag").Return(testDropletList, nil)
+		tm.droplets.EXPECT().ListPages("my-tag", 0, gomock.Any()).DoAndReturn(listDropletPages(testDropletList))
 
 		config.Doit.Set(config.NS, doctl.ArgTagName, "my-tag")
 
@@ -398,6 +539,82 @@ func TestDropletsListByTag(t *testing.T) {
 	})
 }
 
+func TestDropletsListJSON(t *testing.T) {
+	prev := viper.GetString(doctl.ArgOutput)
+	viper.Set(doctl.ArgOutput, "json")
+	t.Cleanup(func() { viper.Set(doctl.ArgOutput, prev) })
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var out bytes.Buffer
+		config.Out = &out
+		tm.droplets.EXPECT().ListPages("", 0, gomock.Any()).DoAndReturn(
+			listDropletPages(do.Droplets{testDroplet}, do.Droplets{anotherTestDroplet}))
+
+		err := RunDropletList(config)
+		require.NoError(t, err)
+
+		// The pages are written as a single array.
+		var want bytes.Buffer
+		require.NoError(t, (&displayers.Droplet{Droplets: do.Droplets{testDroplet, anotherTestDroplet}}).JSON(&want))
+		assert.Equal(t, want.String(), out.String())
+	})
+}
+
+func TestDropletsListLimit(t *testing.T) {
+	prevOut := listCursorOut
+	var cursorOut bytes.Buffer
+	listCursorOut = &cursorOut
+	t.Cleanup(func() { listCursorOut = prevOut })
+
+	d := *testDroplet.Droplet
+	d.ID, d.Name = 4, "a-third-droplet"
+	third := do.Droplet{Droplet: &d}
+	pages := []do.Droplets{{testDroplet, anotherTestDroplet}, {third}}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var out bytes.Buffer
+		config.Out = &out
+		tm.droplets.EXPECT().ListPages("", 0, gomock.Any()).DoAndReturn(listDropletPages(pages...))
+
+		config.Args = append(config.Args, "a-*")
+		config.Doit.Set(config.NS, doctl.ArgListLimit, 1)
+
+		// another-droplet doesn't match, so the cursor is for a-third-droplet.
+		err := RunDropletList(config)
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "a-droplet")
+		assert.NotContains(t, out.String(), "a-third-droplet")
+		assert.Equal(t, "More Droplets are available. To list them, use: --cursor "+encodeListCursor(2)+"\n", cursorOut.String())
+	})
+
+	cursorOut.Reset()
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var out bytes.Buffer
+		config.Out = &out
+		tm.droplets.EXPECT().ListPages("", 2, gomock.Any()).DoAndReturn(listDropletPages(pages...))
+
+		config.Args = append(config.Args, "a-*")
+		config.Doit.Set(config.NS, doctl.ArgListLimit, 1)
+		config.Doit.Set(config.NS, doctl.ArgListCursor, encodeListCursor(2))
+
+		err := RunDropletList(config)
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "a-third-droplet")
+		assert.Empty(t, cursorOut.String())
+	})
+}
+
+func TestDecodeListCursor(t *testing.T) {
+	offset, err := decodeListCursor(encodeListCursor(400))
+	require.NoError(t, err)
+	assert.Equal(t, 400, offset)
+
+	for _, cursor := range []string{"400", "b2Zmc2V0Oi0x", "!"} {
+		_, err := decodeListCursor(cursor)
+		assert.Error(t, err, cursor)
+	}
+}
+
 func TestDropletsTag(t *testing.T) {
 	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
 		trr := &godo.TagResourcesRequest{
@@ -582,3 +799,118 @@ func TestDropletCreateWithAgent(t *testing.T) {
 		})
 	}
 }
+
+func TestDropletCreateWithDNS(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		dcr := &godo.DropletCreateRequest{
+			Name:    "a-droplet",
+			Region:  "dev0",
+			Size:    "1gb",
+			Image:   godo.DropletCreateImage{Slug: "image"},
+			SSHKeys: []godo.DropletCreateSSHKey{},
+			Tags:    []string{},
+		}
+		tm.droplets.EXPECT().Create(dcr, true).Return(&testDroplet, nil)
+		tm.domains.EXPECT().CreateRecord("example.com", &do.DomainRecordEditRequest{
+			Type: "A", Name: "a-droplet", Data: "8.8.8.8",
+		}).Return(&do.DomainRecord{DomainRecord: &godo.DomainRecord{ID: 7, Type: "A", Name: "a-droplet", Data: "8.8.8.8"}}, nil)
+
+		config.Args = append(config.Args, "a-droplet")
+		config.Doit.Set(config.NS, doctl.ArgRegionSlug, "dev0")
+		config.Doit.Set(config.NS, doctl.ArgSizeSlug, "1gb")
+		config.Doit.Set(config.NS, doctl.ArgImage, "image")
+		config.Doit.Set(config.NS, doctl.ArgTagNames, []string{})
+		config.Doit.Set(config.NS, doctl.ArgDropletDNS, "example.com")
+
+		err := RunDropletCreate(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestDropletDeleteWithDNSCleanup(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.droplets.EXPECT().Get(1).Return(&testDroplet, nil)
+		tm.droplets.EXPECT().Delete(1).Return(nil)
+		tm.domains.EXPECT().List().Return(do.Domains{{Domain: &godo.Domain{Name: "example.com"}}}, nil)
+		tm.domains.EXPECT().Records("example.com").Return(do.DomainRecords{
+			{DomainRecord: &godo.DomainRecord{ID: 7, Type: "A", Name: "a-droplet", Data: "8.8.8.8"}},
+			{DomainRecord: &godo.DomainRecord{ID: 8, Type: "A", Name: "a-droplet", Data: "192.0.2.1"}},
+			{DomainRecord: &godo.DomainRecord{ID: 9, Type: "A", Name: "www", Data: "8.8.8.8"}},
+		}, nil)
+		tm.domains.EXPECT().DeleteRecord("example.com", 7).Return(nil)
+
+		config.Args = append(config.Args, strconv.Itoa(testDroplet.ID))
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+		config.Doit.Set(config.NS, doctl.ArgDropletDNSCleanup, true)
+
+		err := RunDropletDelete(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestDropletRecordName(t *testing.T) {
+	assert.Equal(t, "web1", dropletRecordName("web1", "example.com"))
+	assert.Equal(t, "web1", dropletRecordName("web1.example.com", "example.com"))
+	assert.Equal(t, "@", dropletRecordName("example.com", "example.com"))
+}
+
+func stubReverseDNS(t *testing.T, ptr map[string][]string, hosts map[string][]string) {
+	origAddr, origHost := lookupAddr, lookupHost
+	lookupAddr = func(ip string) ([]string, error) {
+		if names, ok := ptr[ip]; ok {
+			return names, nil
+		}
+		return nil, errors.New("no PTR")
+	}
+	lookupHost = func(name string) ([]string, error) {
+		if addrs, ok := hosts[name]; ok {
+			return addrs, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	t.Cleanup(func() { lookupAddr, lookupHost = origAddr, origHost })
+}
+
+func TestDropletSetPTR(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		stubReverseDNS(t,
+			map[string][]string{"8.8.8.8": {"mail.example.com."}},
+			map[string][]string{"mail.example.com": {"8.8.8.8"}})
+
+		tm.dropletActions.EXPECT().Rename(1, "mail.example.com").Return(&testAction, nil)
+		tm.actions.EXPECT().Get(testAction.ID).Return(&testAction, nil)
+		tm.droplets.EXPECT().Get(1).Return(&testDroplet, nil)
+
+		config.Args = append(config.Args, "1", "mail.example.com.")
+		config.Doit.Set(config.NS, doctl.ArgCommandWait, true)
+
+		err := RunDropletSetPTR(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestDropletSetPTRRequiresFQDN(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, "1", "mail")
+		config.Doit.Set(config.NS, doctl.ArgCommandWait, true)
+
+		err := RunDropletSetPTR(config)
+		assert.ErrorContains(t, err, "not a fully qualified domain name")
+	})
+}
+
+func TestDropletPTRs(t *testing.T) {
+	stubReverseDNS(t,
+		map[string][]string{"8.8.8.8": {"web.example.com."}, "8.8.4.4": {"old.example.com."}},
+		map[string][]string{"web.example.com": {"8.8.8.8"}, "old.example.com": {"192.0.2.1"}})
+
+	droplet := func(ip string) do.Droplet {
+		return do.Droplet{Droplet: &godo.Droplet{ID: 1, Networks: &godo.Networks{
+			V4: []godo.NetworkV4{{IPAddress: ip, Type: "public"}},
+		}}}
+	}
+
+	assert.Equal(t, ptrStatusOK, dropletPTRs(droplet("8.8.8.8"))[0].Status)
+	assert.Equal(t, ptrStatusMismatched, dropletPTRs(droplet("8.8.4.4"))[0].Status)
+	assert.Equal(t, ptrStatusMissing, dropletPTRs(droplet("192.0.2.7"))[0].Status)
+}
@@ -16,24 +16,31 @@ package commands
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
 	"testing"
 
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
 	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
 )
 
 func Test_checkErr(t *testing.T) {
-	defer func(a func()) { errAction = a }(errAction)
+	defer func(a func(int)) { errAction = a }(errAction)
 	defer func(a io.Writer) { color.Output = a }(color.Output)
 
 	var b bytes.Buffer
 	w := bufio.NewWriter(&b)
 	color.Output = w
 
-	errAction = func() {
+	errAction = func(int) {
 	}
 
 	e := errors.New("an error")
@@ -44,3 +51,56 @@ func Test_checkErr(t *testing.T) {
 	re := regexp.MustCompile(`an error`)
 	assert.True(t, re.Match(b.Bytes()))
 }
+
+func Test_exitCode(t *testing.T) {
+	apiErr := func(status int) error {
+		return testAPIErr(status, "api error")
+	}
+
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{errors.New("an error"), ExitError},
+		{ErrExitSilently, ExitError},
+		{doctl.NewMissingArgsErr("droplet.get"), ExitValidation},
+		{fmt.Errorf("creating droplet: %w", apiErr(http.StatusUnprocessableEntity)), ExitValidation},
+		{apiErr(http.StatusNotFound), ExitNotFound},
+		{apiErr(http.StatusTooManyRequests), ExitRateLimited},
+		{apiErr(http.StatusUnauthorized), ExitAuth},
+		{apiErr(http.StatusInternalServerError), ExitError},
+		{fmt.Errorf("Unable to initialize DigitalOcean API client: %w", doctl.ErrMissingAccessToken), ExitAuth},
+		{&doctl.ReadOnlyErr{Method: http.MethodDelete, Path: "/v2/droplets/1"}, ExitAuth},
+		{errTimeout("timed out waiting for droplet %d", 1), ExitTimeout},
+		{context.DeadlineExceeded, ExitTimeout},
+		{&do.ServerlessPluginError{Message: "The supplied authentication is invalid", Kind: do.ErrServerlessAuth}, ExitAuth},
+		{&do.ServerlessPluginError{Message: "Too many requests in the last minute", Kind: do.ErrServerlessQuota}, ExitRateLimited},
+		{&do.ServerlessPluginError{Message: "YAMLException: bad indentation", Kind: do.ErrServerlessProjectConfig}, ExitValidation},
+		{&do.ServerlessPluginError{Message: "something else"}, ExitError},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.code, exitCode(tt.err), tt.err.Error())
+	}
+}
+
+func Test_checkErrExitCode(t *testing.T) {
+	defer func(a func(int)) { errAction = a }(errAction)
+	defer func(a io.Writer) { color.Output = a }(color.Output)
+	color.Output = io.Discard
+
+	var code int
+	errAction = func(c int) {
+		code = c
+	}
+
+	checkErr(testAPIErr(http.StatusNotFound, "droplet not found"))
+	assert.Equal(t, ExitNotFound, code)
+}
+
+func testAPIErr(status int, msg string) error {
+	return &godo.ErrorResponse{
+		Response: &http.Response{StatusCode: status, Request: httptest.NewRequest(http.MethodGet, "/v2/droplets/1", nil)},
+		Message:  msg,
+	}
+}
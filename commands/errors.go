@@ -14,12 +14,17 @@ limitations under the License.
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 
 	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
 	"github.com/fatih/color"
 	"github.com/shiena/ansicolor"
 	"github.com/spf13/viper"
@@ -28,13 +33,9 @@ import (
 var (
 	errOperationAborted = fmt.Errorf("Operation aborted.")
 
-	colorErr    = color.RedString("Error")
-	colorWarn   = color.YellowString("Warning")
-	colorNotice = color.GreenString("Notice")
-
 	// errAction specifies what should happen when an error occurs
-	errAction = func() {
-		os.Exit(1)
+	errAction = func(code int) {
+		os.Exit(code)
 	}
 
 	// ErrExitSilently instructs doctl to exit silently with a bad status code. This can be used to fail a command
@@ -45,6 +46,83 @@ var (
 	ErrExitSilently = fmt.Errorf("")
 )
 
+// Exit codes doctl exits with when a command fails, so that scripts can tell
+// kinds of failure apart. See exitCode.
+const (
+	// ExitError is any failure not covered by the codes below.
+	ExitError = 1
+	// ExitValidation is invalid arguments or flags, or a request the API rejected as invalid.
+	ExitValidation = 2
+	// ExitNotFound is a resource that doesn't exist.
+	ExitNotFound = 3
+	// ExitRateLimited is the API's rate limit being exceeded.
+	ExitRateLimited = 4
+	// ExitAuth is a missing or invalid access token, or a call that isn't permitted.
+	ExitAuth = 5
+	// ExitTimeout is giving up waiting for something to finish.
+	ExitTimeout = 6
+)
+
+// exitCodeErr is an error with the exit code doctl should exit with.
+type exitCodeErr struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeErr) Error() string { return e.err.Error() }
+func (e *exitCodeErr) Unwrap() error { return e.err }
+
+// withExitCode sets the code doctl exits with if err makes a command fail.
+func withExitCode(code int, err error) error {
+	return &exitCodeErr{code: code, err: err}
+}
+
+// errTimeout returns a timeout error, for commands that give up waiting.
+func errTimeout(format string, a ...any) error {
+	return withExitCode(ExitTimeout, fmt.Errorf(format, a...))
+}
+
+// exitCode returns the exit code for err: the one set with withExitCode, or
+// else one derived from the kind of error.
+func exitCode(err error) int {
+	var codeErr *exitCodeErr
+	if errors.As(err, &codeErr) {
+		return codeErr.code
+	}
+
+	var errResp *godo.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil {
+		switch errResp.Response.StatusCode {
+		case http.StatusBadRequest, http.StatusUnprocessableEntity:
+			return ExitValidation
+		case http.StatusNotFound:
+			return ExitNotFound
+		case http.StatusTooManyRequests:
+			return ExitRateLimited
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return ExitAuth
+		}
+	}
+
+	var (
+		missingErr  *doctl.MissingArgsErr
+		tooManyErr  *doctl.TooManyArgsErr
+		readOnlyErr *doctl.ReadOnlyErr
+		netErr      net.Error
+	)
+	switch {
+	case errors.As(err, &missingErr), errors.As(err, &tooManyErr), errors.Is(err, do.ErrServerlessProjectConfig):
+		return ExitValidation
+	case errors.Is(err, doctl.ErrMissingAccessToken), errors.As(err, &readOnlyErr), errors.Is(err, do.ErrServerlessAuth):
+		return ExitAuth
+	case errors.Is(err, do.ErrServerlessQuota):
+		return ExitRateLimited
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ExitTimeout
+	}
+	return ExitError
+}
+
 func init() {
 	color.Output = ansicolor.NewAnsiColorWriter(os.Stderr)
 }
@@ -62,17 +140,21 @@ func checkErr(err error) {
 		return
 	}
 
+	printSummary(os.Stderr)
+
 	if errors.Is(err, ErrExitSilently) {
-		errAction()
+		errAction(exitCode(err))
 		return
 	}
 
+	githubAnnotate("error", err.Error())
+
 	output := viper.GetString("output")
 
 	switch output {
 	default:
-		fmt.Fprintf(color.Output, "%s: %v\n", colorErr, err)
-	case "json":
+		fmt.Fprintf(color.Output, "%s: %s\n", color.RedString(localize("Error")), localize(err.Error()))
+	case "json", "ndjson":
 		es := outputErrors{
 			Errors: []outputError{
 				{Detail: err.Error()},
@@ -83,7 +165,7 @@ func checkErr(err error) {
 		fmt.Println(string(b))
 	}
 
-	errAction()
+	errAction(exitCode(err))
 }
 
 func ensureOneArg(c *CmdConfig) error {
@@ -98,12 +180,12 @@ func ensureOneArg(c *CmdConfig) error {
 }
 
 func warn(msg string, args ...any) {
-	fmt.Fprintf(color.Output, "%s: %s\n", colorWarn, fmt.Sprintf(msg, args...))
+	fmt.Fprintf(color.Output, "%s: %s\n", color.YellowString(localize("Warning")), fmt.Sprintf(localize(msg), args...))
 }
 func warnConfirm(msg string, args ...any) {
-	fmt.Fprintf(color.Output, "%s: %s", colorWarn, fmt.Sprintf(msg, args...))
+	fmt.Fprintf(color.Output, "%s: %s", color.YellowString(localize("Warning")), fmt.Sprintf(localize(msg), args...))
 }
 
 func notice(msg string, args ...any) {
-	fmt.Fprintf(color.Output, "%s: %s\n", colorNotice, fmt.Sprintf(msg, args...))
+	fmt.Fprintf(color.Output, "%s: %s\n", color.GreenString(localize("Notice")), fmt.Sprintf(localize(msg), args...))
 }
@@ -0,0 +1,316 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// eventsWebhookClient delivers events to webhooks. It is replaced for testing.
+var eventsWebhookClient = &http.Client{Timeout: 30 * time.Second}
+
+// Events creates the events commands hierarchy.
+func Events() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:     "events",
+			Short:   "Display commands for reacting to account events",
+			Long:    "The subcommands of `doctl events` react to events on your account, such as Droplets being created or actions failing.",
+			GroupID: manageResourcesGroup,
+		},
+	}
+
+	cmdEventsWatch := CmdBuilder(cmd, RunEventsWatch, "watch", "Run handlers when account events occur", `Polls your account's action feed and, for each new event that matches the filters, runs a shell command with `+"`"+`--exec`+"`"+`, sends a POST request with `+"`"+`--webhook`+"`"+`, or both. With `+"`"+`--output ndjson`+"`"+`, each event is also written to standard output as a line of JSON, so that events can be piped to another program.
+
+An event is an action reaching a status: a Droplet create action that completes and one that errors are separate events. The shell command receives the action as JSON on standard input and in the `+"`"+`DOCTL_EVENT_ID`+"`"+`, `+"`"+`DOCTL_EVENT_TYPE`+"`"+`, `+"`"+`DOCTL_EVENT_STATUS`+"`"+`, `+"`"+`DOCTL_EVENT_RESOURCE_TYPE`+"`"+`, `+"`"+`DOCTL_EVENT_RESOURCE_ID`+"`"+`, and `+"`"+`DOCTL_EVENT_REGION`+"`"+` environment variables. The webhook receives the same JSON as its body.
+
+Delivery is at least once. Events are only marked as delivered, in `+"`"+`--state-file`+"`"+`, once every handler succeeds; an event whose handler fails is retried on the next poll. On the first run, events that already happened are recorded without running any handlers.`, Writer)
+	AddStringSliceFlag(cmdEventsWatch, doctl.ArgEventType, "", []string{}, "Only handle actions of these types, such as `create` or `destroy`")
+	AddStringSliceFlag(cmdEventsWatch, doctl.ArgEventResourceType, "", []string{}, "Only handle actions on these resource types, such as `droplet` or `volume`")
+	AddStringSliceFlag(cmdEventsWatch, doctl.ArgEventStatus, "", []string{godo.ActionCompleted, "errored"}, "Only handle actions that reach these statuses: `in-progress`, `completed`, or `errored`")
+	AddStringFlag(cmdEventsWatch, doctl.ArgEventExec, "", "", "A shell command to run for each event")
+	AddStringFlag(cmdEventsWatch, doctl.ArgEventWebhook, "", "", "A URL to POST each event to as JSON")
+	AddStringFlag(cmdEventsWatch, doctl.ArgEventStateFile, "", "", "The file that records delivered events. Defaults to `events-state.json` in the doctl config directory")
+	AddDurationFlag(cmdEventsWatch, doctl.ArgInterval, "", 30*time.Second, "How often to poll for new events")
+	AddBoolFlag(cmdEventsWatch, doctl.ArgOnce, "", false, "Poll once and exit instead of watching")
+	cmdEventsWatch.Example = `The following example posts every failed Droplet action to a webhook: doctl events watch --resource-type droplet --status errored --webhook https://hooks.example.com/doctl`
+
+	return cmd
+}
+
+// eventsState tracks delivery progress. Every action with an ID up to Cursor
+// has been fully handled; Seen holds the last delivered status of newer ones.
+type eventsState struct {
+	Cursor int            `json:"cursor"`
+	Seen   map[int]string `json:"seen"`
+}
+
+// eventFilter selects the actions that handlers run for.
+type eventFilter struct {
+	types         []string
+	resourceTypes []string
+	statuses      []string
+}
+
+func (f eventFilter) matches(a do.Action) bool {
+	return (len(f.types) == 0 || contains(f.types, a.Type)) &&
+		(len(f.resourceTypes) == 0 || contains(f.resourceTypes, a.ResourceType)) &&
+		(len(f.statuses) == 0 || contains(f.statuses, a.Status))
+}
+
+// eventHandler runs the configured handlers for an event.
+type eventHandler func(a do.Action) error
+
+// RunEventsWatch polls the action feed and runs handlers for matching events.
+func RunEventsWatch(c *CmdConfig) error {
+	var f eventFilter
+	var err error
+	if f.types, err = c.Doit.GetStringSlice(c.NS, doctl.ArgEventType); err != nil {
+		return err
+	}
+	if f.resourceTypes, err = c.Doit.GetStringSlice(c.NS, doctl.ArgEventResourceType); err != nil {
+		return err
+	}
+	if f.statuses, err = c.Doit.GetStringSlice(c.NS, doctl.ArgEventStatus); err != nil {
+		return err
+	}
+	command, err := c.Doit.GetString(c.NS, doctl.ArgEventExec)
+	if err != nil {
+		return err
+	}
+	webhook, err := c.Doit.GetString(c.NS, doctl.ArgEventWebhook)
+	if err != nil {
+		return err
+	}
+	// With NDJSON output, each event is also written as a line of JSON.
+	stream := viper.GetString(doctl.ArgOutput) == "ndjson"
+	if command == "" && webhook == "" && !stream {
+		return fmt.Errorf("at least one of `--%s` or `--%s` is required, unless the output is ndjson", doctl.ArgEventExec, doctl.ArgEventWebhook)
+	}
+	statePath, err := c.Doit.GetString(c.NS, doctl.ArgEventStateFile)
+	if err != nil {
+		return err
+	}
+	if statePath == "" {
+		statePath = filepath.Join(configHome(), "events-state.json")
+	}
+	interval, err := c.Doit.GetDuration(c.NS, doctl.ArgInterval)
+	if err != nil {
+		return err
+	}
+	if interval < time.Second {
+		return fmt.Errorf("--%s must be at least 1s", doctl.ArgInterval)
+	}
+	once, err := c.Doit.GetBool(c.NS, doctl.ArgOnce)
+	if err != nil {
+		return err
+	}
+
+	handle := func(a do.Action) error {
+		if stream {
+			if err := c.Display(&displayers.Action{Actions: do.Actions{a}}); err != nil {
+				return err
+			}
+		}
+		if command != "" {
+			if err := runEventCommand(c.Out, command, a); err != nil {
+				return fmt.Errorf("command failed: %w", err)
+			}
+		}
+		if webhook != "" {
+			if err := postEventWebhook(webhook, a); err != nil {
+				return fmt.Errorf("webhook failed: %w", err)
+			}
+		}
+		return nil
+	}
+
+	state, err := readEventsState(statePath)
+	if err != nil {
+		return err
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	for {
+		actions, err := c.Actions().List()
+		if err != nil {
+			return err
+		}
+		state = processEvents(state, actions, f, handle)
+		if err := writeEventsState(statePath, state); err != nil {
+			return err
+		}
+		if once {
+			return nil
+		}
+
+		select {
+		case <-sigs:
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// processEvents runs handle for each matching event not yet delivered and
+// returns the updated state. A nil state starts from the newest action
+// without handling anything, so that old events are not replayed.
+func processEvents(state *eventsState, actions do.Actions, f eventFilter, handle eventHandler) *eventsState {
+	if state == nil {
+		state = &eventsState{Seen: map[int]string{}}
+		for _, a := range actions {
+			if a.ID > state.Cursor {
+				state.Cursor = a.ID
+			}
+		}
+		return state
+	}
+	if state.Seen == nil {
+		state.Seen = map[int]string{}
+	}
+
+	// The feed lists the newest actions first; handle them in the order they happened.
+	pending := make(do.Actions, 0, len(actions))
+	for _, a := range actions {
+		if a.ID > state.Cursor {
+			pending = append(pending, a)
+		}
+	}
+	for i, j := 0, len(pending)-1; i < j; i, j = i+1, j-1 {
+		pending[i], pending[j] = pending[j], pending[i]
+	}
+
+	for _, a := range pending {
+		if state.Seen[a.ID] == a.Status {
+			continue
+		}
+		if f.matches(a) {
+			if err := handle(a); err != nil {
+				warn("Event %d (%s %s %s) will be retried: %v", a.ID, a.ResourceType, a.Type, a.Status, err)
+				continue
+			}
+			notice("Handled event %d (%s %s %s)", a.ID, a.ResourceType, a.Type, a.Status)
+		}
+		state.Seen[a.ID] = a.Status
+	}
+
+	// Advance the cursor past every action that has finished and been delivered.
+	for _, a := range pending {
+		if a.Status == "in-progress" || state.Seen[a.ID] != a.Status {
+			break
+		}
+		state.Cursor = a.ID
+		delete(state.Seen, a.ID)
+	}
+	return state
+}
+
+func runEventCommand(out io.Writer, command string, a do.Action) error {
+	body, err := json.Marshal(a.Action)
+	if err != nil {
+		return err
+	}
+
+	region := a.RegionSlug
+	if region == "" && a.Region != nil {
+		region = a.Region.Slug
+	}
+
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"DOCTL_EVENT_ID="+strconv.Itoa(a.ID),
+		"DOCTL_EVENT_TYPE="+a.Type,
+		"DOCTL_EVENT_STATUS="+a.Status,
+		"DOCTL_EVENT_RESOURCE_TYPE="+a.ResourceType,
+		"DOCTL_EVENT_RESOURCE_ID="+strconv.Itoa(a.ResourceID),
+		"DOCTL_EVENT_REGION="+region,
+	)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func postEventWebhook(url string, a do.Action) error {
+	body, err := json.Marshal(a.Action)
+	if err != nil {
+		return err
+	}
+
+	resp, err := eventsWebhookClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return nil
+}
+
+// readEventsState reads the delivery state, returning nil if there is none yet.
+func readEventsState(path string) (*eventsState, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state eventsState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// writeEventsState replaces the state file atomically so that an interrupted
+// write never loses track of delivered events.
+func writeEventsState(path string, state *eventsState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
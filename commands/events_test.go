@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventsCommand(t *testing.T) {
+	cmd := Events()
+	assert.NotNil(t, cmd)
+	assertCommandNames(t, cmd, "watch")
+}
+
+func testEventAction(id int, typ, status string) do.Action {
+	return do.Action{Action: &godo.Action{ID: id, Type: typ, Status: status, ResourceType: "droplet", ResourceID: 100 + id}}
+}
+
+func TestProcessEvents(t *testing.T) {
+	var handled []int
+	handle := func(a do.Action) error {
+		handled = append(handled, a.ID)
+		return nil
+	}
+	f := eventFilter{types: []string{"create"}, statuses: []string{"completed", "errored"}}
+
+	// The first poll only records where the feed starts.
+	state := processEvents(nil, do.Actions{testEventAction(10, "create", "completed")}, f, handle)
+	assert.Empty(t, handled)
+	assert.Equal(t, 10, state.Cursor)
+
+	state = processEvents(state, do.Actions{
+		testEventAction(13, "create", "completed"),
+		testEventAction(12, "reboot", "completed"),
+		testEventAction(11, "create", "in-progress"),
+		testEventAction(10, "create", "completed"),
+	}, f, handle)
+	assert.Equal(t, []int{13}, handled)
+	assert.Equal(t, 10, state.Cursor, "the cursor stops at the in-progress action")
+
+	state = processEvents(state, do.Actions{
+		testEventAction(13, "create", "completed"),
+		testEventAction(12, "reboot", "completed"),
+		testEventAction(11, "create", "errored"),
+	}, f, handle)
+	assert.Equal(t, []int{13, 11}, handled)
+	assert.Equal(t, 13, state.Cursor)
+	assert.Empty(t, state.Seen)
+}
+
+func TestProcessEventsRetriesFailures(t *testing.T) {
+	attempts := 0
+	handle := func(a do.Action) error {
+		attempts++
+		if attempts == 1 {
+			return errors.New("unreachable")
+		}
+		return nil
+	}
+	actions := do.Actions{testEventAction(2, "create", "completed")}
+
+	state := &eventsState{Cursor: 1}
+	state = processEvents(state, actions, eventFilter{}, handle)
+	assert.Equal(t, 1, state.Cursor)
+
+	state = processEvents(state, actions, eventFilter{}, handle)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, 2, state.Cursor)
+
+	state = processEvents(state, actions, eventFilter{}, handle)
+	assert.Equal(t, 2, attempts, "delivered events are not handled again")
+}
+
+func TestEventsWatchWebhook(t *testing.T) {
+	var received []godo.Action
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a godo.Action
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&a))
+		received = append(received, a)
+	}))
+	defer server.Close()
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, writeEventsState(statePath, &eventsState{Cursor: 1}))
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.actions.EXPECT().List().Return(do.Actions{
+			testEventAction(3, "destroy", "completed"),
+			testEventAction(2, "create", "errored"),
+		}, nil)
+
+		config.Doit.Set(config.NS, doctl.ArgEventStatus, []string{"errored"})
+		config.Doit.Set(config.NS, doctl.ArgEventWebhook, server.URL)
+		config.Doit.Set(config.NS, doctl.ArgEventStateFile, statePath)
+		config.Doit.Set(config.NS, doctl.ArgInterval, "30s")
+		config.Doit.Set(config.NS, doctl.ArgOnce, true)
+
+		err := RunEventsWatch(config)
+		require.NoError(t, err)
+	})
+
+	require.Len(t, received, 1)
+	assert.Equal(t, 2, received[0].ID)
+
+	state, err := readEventsState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, 3, state.Cursor)
+}
+
+func TestEventsWatchNDJSON(t *testing.T) {
+	prev := viper.GetString(doctl.ArgOutput)
+	viper.Set(doctl.ArgOutput, "ndjson")
+	defer viper.Set(doctl.ArgOutput, prev)
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, writeEventsState(statePath, &eventsState{Cursor: 1}))
+
+	var out bytes.Buffer
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.actions.EXPECT().List().Return(do.Actions{
+			testEventAction(3, "destroy", "completed"),
+			testEventAction(2, "create", "errored"),
+		}, nil)
+
+		config.Out = &out
+		config.Doit.Set(config.NS, doctl.ArgEventStateFile, statePath)
+		config.Doit.Set(config.NS, doctl.ArgInterval, "30s")
+		config.Doit.Set(config.NS, doctl.ArgOnce, true)
+
+		err := RunEventsWatch(config)
+		require.NoError(t, err)
+	})
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	var ids []int
+	for _, line := range lines {
+		var a godo.Action
+		require.NoError(t, json.Unmarshal([]byte(line), &a))
+		ids = append(ids, a.ID)
+	}
+	assert.Equal(t, []int{2, 3}, ids)
+}
+
+func TestEventsWatchRequiresHandler(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		err := RunEventsWatch(config)
+		assert.ErrorContains(t, err, "--exec")
+	})
+}
@@ -0,0 +1,362 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+)
+
+// fwTraffic is the traffic a firewall simulation evaluates.
+type fwTraffic struct {
+	inbound  bool
+	protocol string
+	port     int
+	// addr is the source of inbound traffic or the destination of outbound traffic.
+	addr net.IP
+}
+
+// fwPeer is the DigitalOcean resource that a simulated address belongs to.
+type fwPeer struct {
+	dropletID int
+	tags      []string
+	lbID      string
+}
+
+// fwEndpoints are the sources of an inbound rule or the destinations of an
+// outbound rule.
+type fwEndpoints struct {
+	addresses     []string
+	tags          []string
+	dropletIDs    []int
+	lbUIDs        []string
+	kubernetesIDs []string
+}
+
+// RunFirewallSimulate reports whether a firewall would allow traffic, and
+// which rule allows it.
+func RunFirewallSimulate(c *CmdConfig) error {
+	id, err := c.Doit.GetString(c.NS, doctl.ArgFirewall)
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	t, err := buildFirewallTraffic(c)
+	if err != nil {
+		return err
+	}
+
+	fw, err := c.Firewalls().Get(id)
+	if err != nil {
+		return err
+	}
+
+	// The address is only looked up if a rule refers to resources.
+	var peer *fwPeer
+	lookup := func() (*fwPeer, error) {
+		if peer == nil {
+			p, err := resolveFirewallPeer(c, t.addr)
+			if err != nil {
+				return nil, err
+			}
+			peer = p
+		}
+		return peer, nil
+	}
+
+	v, err := simulateFirewall(fw, t, lookup)
+	if err != nil {
+		return err
+	}
+	return c.Display(&displayers.FirewallSimulation{Verdicts: []displayers.FirewallVerdict{v}})
+}
+
+func buildFirewallTraffic(c *CmdConfig) (fwTraffic, error) {
+	src, err := c.Doit.GetString(c.NS, doctl.ArgFirewallSource)
+	if err != nil {
+		return fwTraffic{}, err
+	}
+	dst, err := c.Doit.GetString(c.NS, doctl.ArgFirewallDestination)
+	if err != nil {
+		return fwTraffic{}, err
+	}
+	if src != "" && dst != "" {
+		return fwTraffic{}, fmt.Errorf("Only one of `--%s` or `--%s` may be specified.", doctl.ArgFirewallSource, doctl.ArgFirewallDestination)
+	}
+	if src == "" && dst == "" {
+		return fwTraffic{}, fmt.Errorf("One of `--%s` or `--%s` is required.", doctl.ArgFirewallSource, doctl.ArgFirewallDestination)
+	}
+
+	t := fwTraffic{inbound: src != ""}
+	addr := src
+	if !t.inbound {
+		addr = dst
+	}
+	t.addr = net.ParseIP(addr)
+	if t.addr == nil {
+		return fwTraffic{}, fmt.Errorf("%q is not an IP address", addr)
+	}
+
+	proto, err := c.Doit.GetString(c.NS, doctl.ArgFirewallProtocol)
+	if err != nil {
+		return fwTraffic{}, err
+	}
+	t.protocol = strings.ToLower(proto)
+	switch t.protocol {
+	case "tcp", "udp":
+	case "icmp":
+		return t, nil
+	default:
+		return fwTraffic{}, fmt.Errorf("unsupported protocol %q; use tcp, udp, or icmp", proto)
+	}
+
+	t.port, err = c.Doit.GetInt(c.NS, doctl.ArgFirewallPort)
+	if err != nil {
+		return fwTraffic{}, err
+	}
+	if t.port < 1 || t.port > 65535 {
+		return fwTraffic{}, fmt.Errorf("`--%s` must be between 1 and 65535 for %s traffic", doctl.ArgFirewallPort, t.protocol)
+	}
+	return t, nil
+}
+
+// simulateFirewall evaluates fw's rules against t. Cloud firewalls drop any
+// traffic that no rule allows, so the first matching rule decides the verdict.
+func simulateFirewall(fw *do.Firewall, t fwTraffic, lookup func() (*fwPeer, error)) (displayers.FirewallVerdict, error) {
+	v := displayers.FirewallVerdict{
+		FirewallID: fw.ID,
+		Direction:  "outbound",
+		Protocol:   t.protocol,
+		Port:       t.port,
+		Address:    t.addr.String(),
+	}
+	if t.inbound {
+		v.Direction = "inbound"
+	}
+
+	type rule struct {
+		protocol, ports string
+		endpoints       fwEndpoints
+	}
+	var rules []rule
+	if t.inbound {
+		for _, r := range fw.InboundRules {
+			rules = append(rules, rule{r.Protocol, r.PortRange, inboundEndpoints(r.Sources)})
+		}
+	} else {
+		for _, r := range fw.OutboundRules {
+			rules = append(rules, rule{r.Protocol, r.PortRange, outboundEndpoints(r.Destinations)})
+		}
+	}
+
+	var unresolved []string
+	for _, r := range rules {
+		if !strings.EqualFold(r.protocol, t.protocol) {
+			continue
+		}
+		if t.protocol != "icmp" {
+			ok, err := portInRange(t.port, r.ports)
+			if err != nil {
+				return v, err
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		matched, skipped, err := matchEndpoints(r.endpoints, t.addr, lookup)
+		if err != nil {
+			return v, err
+		}
+		unresolved = append(unresolved, skipped...)
+		if matched == "" {
+			continue
+		}
+
+		v.Allowed = true
+		v.Rule = formatFirewallRule(r.protocol, r.ports, r.endpoints)
+		v.MatchedBy = matched
+		v.Reason = fmt.Sprintf("allowed by %s rule", v.Direction)
+		return v, nil
+	}
+
+	v.Reason = fmt.Sprintf("no %s rule allows this traffic, so it is dropped", v.Direction)
+	if len(unresolved) > 0 {
+		v.Reason += fmt.Sprintf("; %s could not be evaluated and may allow it", strings.Join(unresolved, ", "))
+	}
+	return v, nil
+}
+
+func inboundEndpoints(s *godo.Sources) fwEndpoints {
+	if s == nil {
+		return fwEndpoints{}
+	}
+	return fwEndpoints{s.Addresses, s.Tags, s.DropletIDs, s.LoadBalancerUIDs, s.KubernetesIDs}
+}
+
+func outboundEndpoints(d *godo.Destinations) fwEndpoints {
+	if d == nil {
+		return fwEndpoints{}
+	}
+	return fwEndpoints{d.Addresses, d.Tags, d.DropletIDs, d.LoadBalancerUIDs, d.KubernetesIDs}
+}
+
+// portInRange reports whether port is within a rule's ports, which are a
+// single port, a range such as 8000-9000, or all ports.
+func portInRange(port int, ports string) (bool, error) {
+	switch ports {
+	case "", "0", "all":
+		return true, nil
+	}
+
+	lo, hi, isRange := strings.Cut(ports, "-")
+	if !isRange {
+		hi = lo
+	}
+	l, err := strconv.Atoi(lo)
+	if err != nil {
+		return false, fmt.Errorf("firewall rule has invalid ports %q", ports)
+	}
+	h, err := strconv.Atoi(hi)
+	if err != nil {
+		return false, fmt.Errorf("firewall rule has invalid ports %q", ports)
+	}
+	return port >= l && port <= h, nil
+}
+
+// matchEndpoints returns the endpoint that addr matches, in the key:value
+// format of firewall rules, and the endpoints that could not be evaluated.
+func matchEndpoints(e fwEndpoints, addr net.IP, lookup func() (*fwPeer, error)) (string, []string, error) {
+	for _, a := range e.addresses {
+		if _, cidr, err := net.ParseCIDR(a); err == nil {
+			if cidr.Contains(addr) {
+				return "address:" + a, nil, nil
+			}
+		} else if ip := net.ParseIP(a); ip != nil && ip.Equal(addr) {
+			return "address:" + a, nil, nil
+		}
+	}
+
+	// Kubernetes nodes are Droplets tagged with their cluster's ID.
+	tags := append([]string(nil), e.tags...)
+	var unresolved []string
+	for _, id := range e.kubernetesIDs {
+		tags = append(tags, "k8s:"+id)
+		unresolved = append(unresolved, "kubernetes_id:"+id)
+	}
+	if len(tags) == 0 && len(e.dropletIDs) == 0 && len(e.lbUIDs) == 0 {
+		return "", nil, nil
+	}
+
+	peer, err := lookup()
+	if err != nil {
+		return "", nil, err
+	}
+	if peer == nil {
+		// The address may still belong to a cluster's pods or control plane.
+		return "", unresolved, nil
+	}
+
+	for _, id := range e.dropletIDs {
+		if id == peer.dropletID {
+			return "droplet_id:" + strconv.Itoa(id), nil, nil
+		}
+	}
+	for _, tag := range tags {
+		for _, pt := range peer.tags {
+			if tag == pt {
+				if id, ok := strings.CutPrefix(tag, "k8s:"); ok {
+					return "kubernetes_id:" + id, nil, nil
+				}
+				return "tag:" + tag, nil, nil
+			}
+		}
+	}
+	for _, uid := range e.lbUIDs {
+		if uid == peer.lbID {
+			return "load_balancer_uid:" + uid, nil, nil
+		}
+	}
+	return "", nil, nil
+}
+
+// resolveFirewallPeer finds the Droplet or load balancer with the address ip,
+// returning nil if it is neither.
+func resolveFirewallPeer(c *CmdConfig, ip net.IP) (*fwPeer, error) {
+	droplets, err := c.Droplets().List()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range droplets {
+		if d.Networks == nil {
+			continue
+		}
+		for _, n := range d.Networks.V4 {
+			if ip.Equal(net.ParseIP(n.IPAddress)) {
+				return &fwPeer{dropletID: d.ID, tags: d.Tags}, nil
+			}
+		}
+		for _, n := range d.Networks.V6 {
+			if ip.Equal(net.ParseIP(n.IPAddress)) {
+				return &fwPeer{dropletID: d.ID, tags: d.Tags}, nil
+			}
+		}
+	}
+
+	lbs, err := c.LoadBalancers().List()
+	if err != nil {
+		return nil, err
+	}
+	for _, lb := range lbs {
+		if ip.Equal(net.ParseIP(lb.IP)) {
+			return &fwPeer{lbID: lb.ID}, nil
+		}
+	}
+	return nil, nil
+}
+
+// formatFirewallRule formats a rule as it is given to --inbound-rules and
+// --outbound-rules.
+func formatFirewallRule(protocol, ports string, e fwEndpoints) string {
+	parts := []string{"protocol:" + protocol}
+	if protocol != "icmp" {
+		parts = append(parts, "ports:"+ports)
+	}
+	for _, a := range e.addresses {
+		parts = append(parts, "address:"+a)
+	}
+	for _, t := range e.tags {
+		parts = append(parts, "tag:"+t)
+	}
+	for _, id := range e.dropletIDs {
+		parts = append(parts, "droplet_id:"+strconv.Itoa(id))
+	}
+	for _, uid := range e.lbUIDs {
+		parts = append(parts, "load_balancer_uid:"+uid)
+	}
+	for _, id := range e.kubernetesIDs {
+		parts = append(parts, "kubernetes_id:"+id)
+	}
+	return strings.Join(parts, ",")
+}
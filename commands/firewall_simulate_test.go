@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"net"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSimFirewall = do.Firewall{
+	Firewall: &godo.Firewall{
+		ID: "fw-1",
+		InboundRules: []godo.InboundRule{
+			{Protocol: "tcp", PortRange: "22", Sources: &godo.Sources{Addresses: []string{"192.0.2.0/24"}}},
+			{Protocol: "tcp", PortRange: "8000-9000", Sources: &godo.Sources{Tags: []string{"frontend"}}},
+			{Protocol: "tcp", PortRange: "5432", Sources: &godo.Sources{KubernetesIDs: []string{"k8s-1"}}},
+			{Protocol: "icmp", Sources: &godo.Sources{Addresses: []string{"0.0.0.0/0", "::/0"}}},
+		},
+		OutboundRules: []godo.OutboundRule{
+			{Protocol: "udp", PortRange: "all", Destinations: &godo.Destinations{Addresses: []string{"198.51.100.7"}}},
+		},
+	},
+}
+
+func TestSimulateFirewall(t *testing.T) {
+	frontend := &fwPeer{dropletID: 1, tags: []string{"frontend"}}
+	node := &fwPeer{dropletID: 2, tags: []string{"k8s:k8s-1", "k8s"}}
+
+	tests := []struct {
+		name      string
+		traffic   fwTraffic
+		peer      *fwPeer
+		allowed   bool
+		matchedBy string
+		reason    string
+	}{
+		{
+			name:      "address in CIDR",
+			traffic:   fwTraffic{inbound: true, protocol: "tcp", port: 22, addr: net.ParseIP("192.0.2.55")},
+			allowed:   true,
+			matchedBy: "address:192.0.2.0/24",
+		},
+		{
+			name:    "port not open",
+			traffic: fwTraffic{inbound: true, protocol: "tcp", port: 443, addr: net.ParseIP("192.0.2.55")},
+			reason:  "no inbound rule allows this traffic, so it is dropped",
+		},
+		{
+			name:      "tagged Droplet in port range",
+			traffic:   fwTraffic{inbound: true, protocol: "tcp", port: 8080, addr: net.ParseIP("10.0.0.5")},
+			peer:      frontend,
+			allowed:   true,
+			matchedBy: "tag:frontend",
+		},
+		{
+			name:      "Kubernetes node",
+			traffic:   fwTraffic{inbound: true, protocol: "tcp", port: 5432, addr: net.ParseIP("10.0.0.6")},
+			peer:      node,
+			allowed:   true,
+			matchedBy: "kubernetes_id:k8s-1",
+		},
+		{
+			name:    "unknown address and Kubernetes source",
+			traffic: fwTraffic{inbound: true, protocol: "tcp", port: 5432, addr: net.ParseIP("10.0.0.7")},
+			reason:  "no inbound rule allows this traffic, so it is dropped; kubernetes_id:k8s-1 could not be evaluated and may allow it",
+		},
+		{
+			name:      "ICMP over IPv6",
+			traffic:   fwTraffic{inbound: true, protocol: "icmp", addr: net.ParseIP("2001:db8::1")},
+			allowed:   true,
+			matchedBy: "address:::/0",
+		},
+		{
+			name:      "outbound to all ports",
+			traffic:   fwTraffic{protocol: "udp", port: 53, addr: net.ParseIP("198.51.100.7")},
+			allowed:   true,
+			matchedBy: "address:198.51.100.7",
+		},
+		{
+			name:    "outbound protocol not allowed",
+			traffic: fwTraffic{protocol: "tcp", port: 53, addr: net.ParseIP("198.51.100.7")},
+			reason:  "no outbound rule allows this traffic, so it is dropped",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := simulateFirewall(&testSimFirewall, tt.traffic, func() (*fwPeer, error) { return tt.peer, nil })
+			require.NoError(t, err)
+			assert.Equal(t, tt.allowed, v.Allowed)
+			assert.Equal(t, tt.matchedBy, v.MatchedBy)
+			if tt.reason != "" {
+				assert.Equal(t, tt.reason, v.Reason)
+			}
+		})
+	}
+}
+
+func TestFirewallSimulate(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.firewalls.EXPECT().Get("fw-1").Return(&testSimFirewall, nil)
+		tm.droplets.EXPECT().List().Return(do.Droplets{{Droplet: &godo.Droplet{
+			ID:       1,
+			Tags:     []string{"frontend"},
+			Networks: &godo.Networks{V4: []godo.NetworkV4{{IPAddress: "10.0.0.5", Type: "private"}}},
+		}}}, nil)
+
+		config.Doit.Set(config.NS, doctl.ArgFirewall, "fw-1")
+		config.Doit.Set(config.NS, doctl.ArgFirewallSource, "10.0.0.5")
+		config.Doit.Set(config.NS, doctl.ArgFirewallPort, 8443)
+		config.Doit.Set(config.NS, doctl.ArgFirewallProtocol, "tcp")
+
+		assert.NoError(t, RunFirewallSimulate(config))
+	})
+}
+
+func TestFirewallSimulateErrors(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Doit.Set(config.NS, doctl.ArgFirewall, "fw-1")
+		config.Doit.Set(config.NS, doctl.ArgFirewallProtocol, "tcp")
+		assert.EqualError(t, RunFirewallSimulate(config), "One of `--src` or `--dst` is required.")
+
+		config.Doit.Set(config.NS, doctl.ArgFirewallSource, "example.com")
+		assert.EqualError(t, RunFirewallSimulate(config), `"example.com" is not an IP address`)
+
+		config.Doit.Set(config.NS, doctl.ArgFirewallSource, "192.0.2.1")
+		assert.EqualError(t, RunFirewallSimulate(config), "`--port` must be between 1 and 65535 for tcp traffic")
+
+		config.Doit.Set(config.NS, doctl.ArgFirewallProtocol, "sctp")
+		assert.EqualError(t, RunFirewallSimulate(config), `unsupported protocol "sctp"; use tcp, udp, or icmp`)
+	})
+}
@@ -125,6 +125,18 @@ Use a quoted string of space-separated values for multiple rules.`
 	AddStringFlag(cmdRemoveRules, doctl.ArgOutboundRules, "", "", outboundRulesTxt)
 	cmdRemoveRules.Example = `The following example removes an inbound rule and an outbound rule from a cloud firewall with the ID ` + "`" + `f81d4fae-7dec-11d0-a765-00a0c91e6bf6` + "`" + `: doctl compute firewall remove-rules f81d4fae-7dec-11d0-a765-00a0c91e6bf6 --inbound-rules "protocol:tcp,ports:22,droplet_id:386734086" --outbound-rules "protocol:tcp,ports:22,address:0.0.0.0/0"`
 
+	cmdSimulate := CmdBuilder(cmd, RunFirewallSimulate, "simulate", "Check whether a cloud firewall allows traffic", `Evaluates a cloud firewall's rules locally and reports whether they would allow the given traffic and, if so, which rule allows it. Use it to debug connectivity problems before changing live rules.
+
+Use `+"`"+`--src`+"`"+` to simulate inbound traffic from an address to the firewall's Droplets, or `+"`"+`--dst`+"`"+` to simulate outbound traffic from them to an address. Rules that refer to Droplets, tags, load balancers, or Kubernetes clusters are matched by looking up which of your resources has the address.
+
+Cloud firewalls drop all traffic that no rule allows, so traffic is allowed if any rule matches it.`, Writer, displayerType(&displayers.FirewallSimulation{}))
+	AddStringFlag(cmdSimulate, doctl.ArgFirewall, "", "", "The ID of the firewall to evaluate", requiredOpt())
+	AddStringFlag(cmdSimulate, doctl.ArgFirewallSource, "", "", "The source IP address of inbound traffic")
+	AddStringFlag(cmdSimulate, doctl.ArgFirewallDestination, "", "", "The destination IP address of outbound traffic")
+	AddIntFlag(cmdSimulate, doctl.ArgFirewallPort, "", 0, "The destination port of the traffic. Not used for ICMP.")
+	AddStringFlag(cmdSimulate, doctl.ArgFirewallProtocol, "", "tcp", "The protocol of the traffic. Possible values: `tcp`, `udp`, `icmp`")
+	cmdSimulate.Example = `The following example checks whether the cloud firewall with the ID ` + "`" + `f81d4fae-7dec-11d0-a765-00a0c91e6bf6` + "`" + ` allows HTTPS connections from ` + "`" + `203.0.113.10` + "`" + `: doctl compute firewall simulate --firewall f81d4fae-7dec-11d0-a765-00a0c91e6bf6 --src 203.0.113.10 --port 443 --proto tcp`
+
 	return cmd
 }
 
@@ -230,7 +242,7 @@ func RunFirewallDelete(c *CmdConfig) error {
 	}
 
 	fs := c.Firewalls()
-	if force || AskForConfirmDelete("firewall", len(c.Args)) == nil {
+	if force || AskForConfirmDelete("firewall", len(c.Args), c.Args...) == nil {
 		for _, id := range c.Args {
 			if err := fs.Delete(id); err != nil {
 				return err
@@ -26,7 +26,7 @@ var (
 func TestFirewallCommand(t *testing.T) {
 	cmd := Firewall()
 	assert.NotNil(t, cmd)
-	assertCommandNames(t, cmd, "get", "create", "update", "list", "list-by-droplet", "delete", "add-droplets", "remove-droplets", "add-tags", "remove-tags", "add-rules", "remove-rules")
+	assertCommandNames(t, cmd, "get", "create", "update", "list", "list-by-droplet", "delete", "add-droplets", "remove-droplets", "add-tags", "remove-tags", "add-rules", "remove-rules", "simulate")
 }
 
 func TestFirewallGet(t *testing.T) {
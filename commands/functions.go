@@ -41,15 +41,45 @@ func Functions() *Command {
 		},
 	}
 
+	deploy := CmdBuilder(cmd, RunFunctionsDeploy, "deploy <functionName> <sourceFile>", "Deploy the code of a single function",
+		`Deploys the code in a source file, or a zip file for functions with several files, as a function in your functions namespace.
+An existing function keeps its parameters, limits, and annotations; a new function needs the `+"`"+`--kind`+"`"+` flag.
+
+With `+"`"+`--canary`+"`"+`, the new code is deployed alongside the current version instead of replacing it, and the given percentage
+of invocations is routed to it. The current version is kept as `+"`"+`<functionName>--stable`+"`"+` and the new one as
+`+"`"+`<functionName>--canary`+"`"+`, while `+"`"+`<functionName>`+"`"+` becomes a small router function that invokes one of them, which adds
+an activation to every invocation. Deploy again with `+"`"+`--canary`+"`"+` to change the code or the percentage, then use
+`+"`"+`doctl serverless functions canary promote`+"`"+` or `+"`"+`abort`+"`"+` to finish.`,
+		Writer)
+	AddStringFlag(deploy, flagCanary, "", "", "Deploys the code as a canary receiving the given percentage of invocations, such as `10%`")
+	AddStringFlag(deploy, flagKind, "", "", "The runtime of the function, such as `nodejs:18`. Defaults to the runtime of the deployed function.")
+	deploy.Example = `The following example routes 10% of the invocations of "example/hello" to the code in ` + "`" + `hello.js` + "`" + `: doctl serverless functions deploy example/hello hello.js --canary 10%`
+
+	canary := &Command{
+		Command: &cobra.Command{
+			Use:   "canary",
+			Short: "Finish canary deployments of functions",
+			Long:  `The subcommands of ` + "`" + `doctl serverless functions canary` + "`" + ` end a canary deployment started with ` + "`" + `doctl serverless functions deploy --canary` + "`" + `.`,
+		},
+	}
+	cmd.AddCommand(canary)
+	CmdBuilder(canary, RunFunctionsCanaryPromote, "promote <functionName>", "Route all invocations to the canary",
+		`Replaces the router of a canary deployment with the canary version of the function and removes the stable and canary copies.`,
+		Writer)
+	CmdBuilder(canary, RunFunctionsCanaryAbort, "abort <functionName>", "Restore the stable version of a function",
+		`Replaces the router of a canary deployment with the stable version of the function and removes the stable and canary copies.`,
+		Writer)
+
 	get := CmdBuilder(cmd, RunFunctionsGet, "get <functionName>", "Retrieve the metadata or code of a deployed function",
 		`Retrieves the code or metadata of a deployed function.`,
 		Writer)
 	AddBoolFlag(get, "url", "r", false, "Retrieves function URL")
-	AddBoolFlag(get, "code", "", false, "Retrieves the functions code. This does not work if the function is saved as a zip file.")
+	AddBoolFlag(get, "code", "", false, "Retrieves the functions code. Code deployed as a zip file cannot be displayed; save it with `--save` or `--save-as` instead.")
 	AddStringFlag(get, "save-env", "E", "", "Saves the function's environment variables to a local file as key-value pairs")
 	AddStringFlag(get, "save-env-json", "J", "", "Saves the function's environment variables to a local file as JSON")
 	AddBoolFlag(get, "save", "", false, "Saves the function's code to a local file")
 	AddStringFlag(get, "save-as", "", "", "Saves the file as the specified name")
+	AddStringFlag(get, flagSaveMetadata, "", "", "Saves the function's runtime, entry point, limits, annotations, and parameters to a local file as JSON, for use as a backup together with the saved code")
 	get.Example = `The following example retrieves the code for a function named "example/helloWorld" and saves it to a file named ` + "`" + `local-helloWorld.py` + "`" + `: doctl serverless functions get example/helloWorld --code --save-as local-helloWorld.py`
 
 	invoke := CmdBuilder(cmd, RunFunctionsInvoke, "invoke <functionName>", "Invokes a function",
@@ -64,7 +94,9 @@ You can provide inputs and inspect outputs.`,
 	invoke.Example = `The following example invokes a function named "example/helloWorld" with the parameters ` + "`" + `name:John,place:NY` + "`" + `: doctl serverless functions invoke example/helloWorld --param name:John,place:NY`
 
 	list := CmdBuilder(cmd, RunFunctionsList, "list [<packageName>]", "Lists the functions in your functions namespace",
-		`Lists the functions in your functions namespace.`,
+		`Lists the functions in your functions namespace, with their runtime, memory limit, timeout, whether they are web functions, and when they were last updated.
+
+The `+"`"+`URL`+"`"+` column, which you can add with `+"`"+`--format`+"`"+`, shows the URL each function is invoked at: its web URL for web functions and its REST API URL otherwise.`,
 		Writer, aliasOpt("ls"), displayerType(&displayers.Functions{}))
 	AddStringFlag(list, "limit", "l", "", "Returns the specified number of functions in the result, starting with the most recently updated function.")
 	AddStringFlag(list, "skip", "s", "", "Excludes the specified number of functions from the result, starting with the most recently updated function. For example, if you specify `2`, the most recently updated function and the function updated before that are excluded from the result.")
@@ -88,6 +120,7 @@ func RunFunctionsGet(c *CmdConfig) error {
 	saveAsFlag, _ := c.Doit.GetString(c.NS, flagSaveAs)
 	saveEnvFlag, _ := c.Doit.GetString(c.NS, flagSaveEnv)
 	saveEnvJSONFlag, _ := c.Doit.GetString(c.NS, flagSaveEnvJSON)
+	saveMetadataFlag, _ := c.Doit.GetString(c.NS, flagSaveMetadata)
 	fetchCode := codeFlag || saveFlag || saveAsFlag != ""
 
 	sls := c.Serverless()
@@ -105,12 +138,31 @@ func RunFunctionsGet(c *CmdConfig) error {
 		return err
 	}
 
+	// The code, environment, and metadata can be saved together, e.g. to back up a function.
+	saved := false
 	if saveFlag || saveAsFlag != "" {
-		return doSaveFunctionCode(action, saveFlag, saveAsFlag)
+		if err := doSaveFunctionCode(action, saveFlag, saveAsFlag); err != nil {
+			return err
+		}
+		saved = true
 	}
 
 	if saveEnvFlag != "" || saveEnvJSONFlag != "" {
-		return doSaveFunctionEnvironment(saveEnvFlag, saveEnvJSONFlag, parms)
+		if err := doSaveFunctionEnvironment(saveEnvFlag, saveEnvJSONFlag, parms); err != nil {
+			return err
+		}
+		saved = true
+	}
+
+	if saveMetadataFlag != "" {
+		if err := doSaveFunctionMetadata(saveMetadataFlag, action); err != nil {
+			return err
+		}
+		saved = true
+	}
+
+	if saved {
+		return nil
 	}
 
 	if codeFlag {
@@ -118,7 +170,7 @@ func RunFunctionsGet(c *CmdConfig) error {
 			_, err = fmt.Fprintln(c.Out, *action.Exec.Code)
 			return err
 		}
-		return errors.New("Binary code cannot be displayed on the console")
+		return errors.New("Binary code cannot be displayed on the console; use --save or --save-as to save it as a zip file")
 	}
 
 	output := do.ServerlessOutput{Entity: action}
@@ -153,6 +205,47 @@ func doSaveFunctionCode(action whisk.Action, save bool, saveAs string) error {
 	return nil
 }
 
+// functionMetadata is the configuration of a deployed function, apart from its code.
+type functionMetadata struct {
+	Name        string            `json:"name"`
+	Package     string            `json:"package,omitempty"`
+	Kind        string            `json:"kind"`
+	Main        string            `json:"main,omitempty"`
+	Binary      bool              `json:"binary"`
+	Limits      *whisk.Limits     `json:"limits,omitempty"`
+	Annotations whisk.KeyValueArr `json:"annotations,omitempty"`
+	Parameters  whisk.KeyValueArr `json:"parameters,omitempty"`
+}
+
+// newFunctionMetadata extracts the metadata of a function.
+func newFunctionMetadata(action whisk.Action) functionMetadata {
+	md := functionMetadata{
+		Name:        action.Name,
+		Limits:      action.Limits,
+		Annotations: action.Annotations,
+		Parameters:  action.Parameters,
+	}
+	if parts := strings.SplitN(action.Namespace, "/", 2); len(parts) == 2 {
+		md.Package = parts[1]
+	}
+	if action.Exec != nil {
+		md.Kind = action.Exec.Kind
+		md.Main = action.Exec.Main
+		md.Binary = action.Exec.Binary != nil && *action.Exec.Binary
+	}
+	return md
+}
+
+// doSaveFunctionMetadata saves the metadata of a function to file as JSON. The file may
+// contain secrets passed as parameters, so it is only readable by the current user.
+func doSaveFunctionMetadata(path string, action whisk.Action) error {
+	data, err := json.MarshalIndent(newFunctionMetadata(action), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
 // doSaveFunctionEnvironment saves the environment variables for a function to file,
 // either as key-value pairs or JSON.  Could do both if both are specified.
 func doSaveFunctionEnvironment(saveEnv string, saveEnvJSON string, parms []do.FunctionParameter) error {
@@ -310,7 +403,9 @@ func RunFunctionsList(c *CmdConfig) error {
 	if nameSort || nameName {
 		sortFunctionList(list)
 	}
-	return c.Display(&displayers.Functions{Info: list})
+	// The API host is only used for the optional URL column, so a failure to read it is not fatal.
+	apiHost, _ := c.Serverless().GetConnectedAPIHost()
+	return c.Display(&displayers.Functions{Info: list, APIHost: apiHost})
 }
 
 // sortFunctionList performs a sort of a function list (by name)
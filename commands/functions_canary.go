@@ -0,0 +1,273 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/apache/openwhisk-client-go/whisk"
+	"github.com/digitalocean/doctl"
+)
+
+// canaryAnnotation marks a function that has been replaced by a canary router.
+// Its value is a JSON encoded canaryState.
+const canaryAnnotation = "doctl-canary"
+
+// canaryRouterCode is the code of the function that splits invocations between
+// the stable and canary versions of a function.
+const canaryRouterCode = `const openwhisk = require('openwhisk')
+
+const stable = %q
+const canary = %q
+const weight = %d
+
+async function main(params) {
+  const name = Math.random() * 100 < weight ? canary : stable
+  return openwhisk().actions.invoke({ name, params, blocking: true, result: true })
+}
+
+exports.main = main
+`
+
+// canaryState describes a canary deployment in progress.
+type canaryState struct {
+	Weight int    `json:"weight"`
+	Stable string `json:"stable"`
+	Canary string `json:"canary"`
+}
+
+// canaryStateOf returns the canary deployment routed by action, or nil if action is not a canary router.
+func canaryStateOf(action whisk.Action) *canaryState {
+	v, ok := action.Annotations.GetValue(canaryAnnotation).(string)
+	if !ok {
+		return nil
+	}
+	state := &canaryState{}
+	if err := json.Unmarshal([]byte(v), state); err != nil {
+		return nil
+	}
+	return state
+}
+
+// parseCanaryWeight parses a percentage such as "10%" or "10".
+func parseCanaryWeight(s string) (int, error) {
+	w, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(s), "%"))
+	if err != nil || w < 1 || w > 99 {
+		return 0, fmt.Errorf("invalid canary weight %q: must be a percentage between 1%% and 99%%", s)
+	}
+	return w, nil
+}
+
+// readFunctionSource reads the code of a function from a file. Zip files are
+// sent base64 encoded, as the API expects for binary functions.
+func readFunctionSource(path string) (string, bool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		return base64.StdEncoding.EncodeToString(b), true, nil
+	}
+	return string(b), false, nil
+}
+
+// withoutAnnotations returns a copy of annotations without the given keys.
+func withoutAnnotations(annotations whisk.KeyValueArr, keys ...string) whisk.KeyValueArr {
+	out := whisk.KeyValueArr{}
+	for _, kv := range annotations {
+		drop := false
+		for _, k := range keys {
+			if kv.Key == k {
+				drop = true
+			}
+		}
+		if !drop {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
+// copyFunction returns a version of action under a new name. The exec
+// annotation is computed by the server and is not copied.
+func copyFunction(action whisk.Action, name string, exec *whisk.Exec) whisk.Action {
+	return whisk.Action{
+		Name:        name,
+		Exec:        exec,
+		Annotations: withoutAnnotations(action.Annotations, "exec", canaryAnnotation),
+		Parameters:  action.Parameters,
+		Limits:      action.Limits,
+	}
+}
+
+// canaryRouter returns the function that replaces original while a canary is in progress.
+func canaryRouter(original whisk.Action, name string, state canaryState) (whisk.Action, error) {
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		return whisk.Action{}, err
+	}
+	code := fmt.Sprintf(canaryRouterCode, state.Stable, state.Canary, state.Weight)
+
+	annotations := withoutAnnotations(original.Annotations, "exec", canaryAnnotation, "provide-api-key")
+	annotations = append(annotations,
+		whisk.KeyValue{Key: "provide-api-key", Value: true},
+		whisk.KeyValue{Key: canaryAnnotation, Value: string(encoded)},
+	)
+	return whisk.Action{
+		Name:        name,
+		Exec:        &whisk.Exec{Kind: "nodejs:default", Code: &code},
+		Annotations: annotations,
+		Limits:      original.Limits,
+	}, nil
+}
+
+// RunFunctionsDeploy supports the 'serverless functions deploy' command
+func RunFunctionsDeploy(c *CmdConfig) error {
+	if len(c.Args) != 2 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	name, source := c.Args[0], c.Args[1]
+	canary, _ := c.Doit.GetString(c.NS, flagCanary)
+	kind, _ := c.Doit.GetString(c.NS, flagKind)
+
+	code, binary, err := readFunctionSource(source)
+	if err != nil {
+		return err
+	}
+
+	sls := c.Serverless()
+	existing, _, getErr := sls.GetFunction(name, canary != "")
+	state := canaryStateOf(existing)
+
+	if canary == "" {
+		if getErr == nil && state != nil {
+			return fmt.Errorf("a canary of %s is in progress; promote or abort it before deploying", name)
+		}
+		if getErr != nil && kind == "" {
+			return fmt.Errorf("the --kind flag is required to create a new function: %w", getErr)
+		}
+		if kind == "" {
+			kind = existing.Exec.Kind
+		}
+		_, err := sls.PutFunction(copyFunction(existing, name, &whisk.Exec{Kind: kind, Code: &code, Binary: &binary}))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(c.Out, "Deployed %s\n", name)
+		return nil
+	}
+
+	if getErr != nil {
+		return fmt.Errorf("a canary can only be deployed for an existing function: %w", getErr)
+	}
+	weight, err := parseCanaryWeight(canary)
+	if err != nil {
+		return err
+	}
+
+	// The function being routed is the stable version of a canary in progress,
+	// or the deployed function otherwise.
+	stable := existing
+	if state == nil {
+		state = &canaryState{Stable: name + "--stable", Canary: name + "--canary"}
+		if _, err := sls.PutFunction(copyFunction(existing, state.Stable, existing.Exec)); err != nil {
+			return err
+		}
+	} else {
+		stable, _, err = sls.GetFunction(state.Stable, false)
+		if err != nil {
+			return err
+		}
+	}
+	state.Weight = weight
+
+	if kind == "" {
+		kind = stable.Exec.Kind
+	}
+	if _, err := sls.PutFunction(copyFunction(stable, state.Canary, &whisk.Exec{Kind: kind, Code: &code, Binary: &binary})); err != nil {
+		return err
+	}
+
+	router, err := canaryRouter(stable, name, *state)
+	if err != nil {
+		return err
+	}
+	if _, err := sls.PutFunction(router); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.Out, "Deployed a canary of %s receiving %d%% of invocations\n", name, weight)
+	return nil
+}
+
+// RunFunctionsCanaryPromote supports the 'serverless functions canary promote' command
+func RunFunctionsCanaryPromote(c *CmdConfig) error {
+	return finishCanary(c, true)
+}
+
+// RunFunctionsCanaryAbort supports the 'serverless functions canary abort' command
+func RunFunctionsCanaryAbort(c *CmdConfig) error {
+	return finishCanary(c, false)
+}
+
+// finishCanary replaces the router of a canary deployment with either the
+// canary or the stable version of the function, and removes both versions.
+func finishCanary(c *CmdConfig, promote bool) error {
+	if err := ensureOneArg(c); err != nil {
+		return err
+	}
+	name := c.Args[0]
+
+	sls := c.Serverless()
+	router, _, err := sls.GetFunction(name, false)
+	if err != nil {
+		return err
+	}
+	state := canaryStateOf(router)
+	if state == nil {
+		return errors.New("no canary of " + name + " is in progress")
+	}
+
+	keep := state.Stable
+	if promote {
+		keep = state.Canary
+	}
+	version, _, err := sls.GetFunction(keep, true)
+	if err != nil {
+		return err
+	}
+	if _, err := sls.PutFunction(copyFunction(version, name, version.Exec)); err != nil {
+		return err
+	}
+
+	for _, n := range []string{state.Stable, state.Canary} {
+		if err := sls.DeleteFunction(n, false); err != nil {
+			return err
+		}
+	}
+
+	if promote {
+		fmt.Fprintf(c.Out, "Promoted the canary of %s\n", name)
+	} else {
+		fmt.Fprintf(c.Out, "Aborted the canary of %s\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/openwhisk-client-go/whisk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func canaryTestFunction(name, code string) whisk.Action {
+	return whisk.Action{
+		Name:        name,
+		Exec:        &whisk.Exec{Kind: "nodejs:18", Code: &code},
+		Annotations: whisk.KeyValueArr{{Key: "web-export", Value: true}, {Key: "exec", Value: "nodejs:18"}},
+		Parameters:  whisk.KeyValueArr{{Key: "greeting", Value: "hi"}},
+	}
+}
+
+func TestParseCanaryWeight(t *testing.T) {
+	w, err := parseCanaryWeight("10%")
+	require.NoError(t, err)
+	assert.Equal(t, 10, w)
+
+	for _, bad := range []string{"0%", "100", "ten"} {
+		_, err := parseCanaryWeight(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestFunctionsDeployCanary(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		source := filepath.Join(t.TempDir(), "hello.js")
+		require.NoError(t, os.WriteFile(source, []byte("new code"), 0644))
+
+		current := canaryTestFunction("hello", "old code")
+		tm.serverless.EXPECT().GetFunction("hello", true).Return(current, nil, nil)
+
+		var put []whisk.Action
+		tm.serverless.EXPECT().PutFunction(gomock.Any()).Times(3).DoAndReturn(func(a whisk.Action) (whisk.Action, error) {
+			put = append(put, a)
+			return a, nil
+		})
+
+		buf := &bytes.Buffer{}
+		config.Out = buf
+		config.Args = append(config.Args, "hello", source)
+		config.Doit.Set(config.NS, flagCanary, "10%")
+
+		err := RunFunctionsDeploy(config)
+		require.NoError(t, err)
+		assert.Equal(t, "Deployed a canary of hello receiving 10% of invocations\n", buf.String())
+
+		require.Len(t, put, 3)
+		assert.Equal(t, "hello--stable", put[0].Name)
+		assert.Equal(t, "old code", *put[0].Exec.Code)
+		assert.Nil(t, put[0].Annotations.GetValue("exec"))
+		assert.Equal(t, "hello--canary", put[1].Name)
+		assert.Equal(t, "new code", *put[1].Exec.Code)
+		assert.Equal(t, current.Parameters, put[1].Parameters)
+
+		router := put[2]
+		assert.Equal(t, "hello", router.Name)
+		assert.Equal(t, true, router.Annotations.GetValue("web-export"))
+		assert.Equal(t, &canaryState{Weight: 10, Stable: "hello--stable", Canary: "hello--canary"}, canaryStateOf(router))
+		assert.Contains(t, *router.Exec.Code, `const canary = "hello--canary"`)
+	})
+}
+
+func TestFunctionsDeployRefusesDuringCanary(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		source := filepath.Join(t.TempDir(), "hello.js")
+		require.NoError(t, os.WriteFile(source, []byte("new code"), 0644))
+
+		router, err := canaryRouter(canaryTestFunction("hello", ""), "hello", canaryState{Weight: 5, Stable: "hello--stable", Canary: "hello--canary"})
+		require.NoError(t, err)
+		tm.serverless.EXPECT().GetFunction("hello", false).Return(router, nil, nil)
+
+		config.Args = append(config.Args, "hello", source)
+
+		err = RunFunctionsDeploy(config)
+		assert.ErrorContains(t, err, "a canary of hello is in progress")
+	})
+}
+
+func TestFunctionsCanaryPromote(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		router, err := canaryRouter(canaryTestFunction("hello", ""), "hello", canaryState{Weight: 5, Stable: "hello--stable", Canary: "hello--canary"})
+		require.NoError(t, err)
+		canary := canaryTestFunction("hello--canary", "new code")
+
+		tm.serverless.EXPECT().GetFunction("hello", false).Return(router, nil, nil)
+		tm.serverless.EXPECT().GetFunction("hello--canary", true).Return(canary, nil, nil)
+		tm.serverless.EXPECT().PutFunction(copyFunction(canary, "hello", canary.Exec)).Return(canary, nil)
+		tm.serverless.EXPECT().DeleteFunction("hello--stable", false).Return(nil)
+		tm.serverless.EXPECT().DeleteFunction("hello--canary", false).Return(nil)
+
+		config.Args = append(config.Args, "hello")
+
+		err = RunFunctionsCanaryPromote(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestFunctionsCanaryAbortWithoutCanary(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.serverless.EXPECT().GetFunction("hello", false).Return(canaryTestFunction("hello", ""), nil, nil)
+
+		config.Args = append(config.Args, "hello")
+
+		err := RunFunctionsCanaryAbort(config)
+		assert.ErrorContains(t, err, "no canary of hello is in progress")
+	})
+}
@@ -15,7 +15,10 @@ package commands
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -29,7 +32,7 @@ import (
 func TestFunctionsCommand(t *testing.T) {
 	cmd := Functions()
 	assert.NotNil(t, cmd)
-	expected := []string{"get", "invoke", "list"}
+	expected := []string{"canary", "deploy", "get", "invoke", "list"}
 
 	names := []string{}
 	for _, c := range cmd.Commands() {
@@ -169,6 +172,52 @@ func TestFunctionsGet(t *testing.T) {
 	}
 }
 
+func TestFunctionsGetBackup(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		dir := t.TempDir()
+		zipPath := filepath.Join(dir, "fn.zip")
+		metadataPath := filepath.Join(dir, "fn.json")
+
+		code := base64.StdEncoding.EncodeToString([]byte("PK zip contents"))
+		binary := true
+		memory := 512
+		action := whisk.Action{
+			Name:      "hello",
+			Namespace: "fn-aaa/sample",
+			Exec:      &whisk.Exec{Kind: "python:3.11", Code: &code, Binary: &binary, Main: "main"},
+			Limits:    &whisk.Limits{Memory: &memory},
+			Parameters: whisk.KeyValueArr{
+				{Key: "GREETING", Value: "hi"},
+			},
+		}
+		tm.serverless.EXPECT().GetFunction("hello", true).Return(action, nil, nil)
+
+		config.Args = append(config.Args, "hello")
+		config.Doit.Set(config.NS, "code", true)
+		config.Doit.Set(config.NS, "save-as", zipPath)
+		config.Doit.Set(config.NS, "save-metadata", metadataPath)
+
+		err := RunFunctionsGet(config)
+		require.NoError(t, err)
+
+		contents, err := os.ReadFile(zipPath)
+		require.NoError(t, err)
+		assert.Equal(t, "PK zip contents", string(contents))
+
+		contents, err = os.ReadFile(metadataPath)
+		require.NoError(t, err)
+		var md functionMetadata
+		require.NoError(t, json.Unmarshal(contents, &md))
+		assert.Equal(t, "hello", md.Name)
+		assert.Equal(t, "sample", md.Package)
+		assert.Equal(t, "python:3.11", md.Kind)
+		assert.Equal(t, "main", md.Main)
+		assert.True(t, md.Binary)
+		assert.Equal(t, 512, *md.Limits.Memory)
+		assert.Equal(t, "GREETING", md.Parameters[0].Key)
+	})
+}
+
 func TestFunctionsInvoke(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -270,9 +319,9 @@ func TestFunctionsList(t *testing.T) {
 			name:  "no flags or args",
 			skip:  0,
 			limit: 0,
-			expectedOutput: `%DATE1%    0.0.1    nodejs:14    daily/hello
-%DATE2%    0.0.2    nodejs:14    daily/goodbye
-%DATE3%    0.0.3    nodejs:14    sometimes/meAgain
+			expectedOutput: `%DATE1%    0.0.1    nodejs:14    256    3s    true     daily/hello
+%DATE2%    0.0.2    nodejs:14                 false    daily/goodbye
+%DATE3%    0.0.3    nodejs:14                 false    sometimes/meAgain
 `,
 		},
 		{
@@ -280,8 +329,8 @@ func TestFunctionsList(t *testing.T) {
 			doctlArg: "daily",
 			skip:     0,
 			limit:    0,
-			expectedOutput: `%DATE1%    0.0.1    nodejs:14    daily/hello
-%DATE2%    0.0.2    nodejs:14    daily/goodbye
+			expectedOutput: `%DATE1%    0.0.1    nodejs:14    256    3s    true     daily/hello
+%DATE2%    0.0.2    nodejs:14                 false    daily/goodbye
 `,
 		},
 		{
@@ -296,16 +345,16 @@ func TestFunctionsList(t *testing.T) {
 			doctlFlags:     map[string]string{"limit": "1"},
 			skip:           0,
 			limit:          1,
-			expectedOutput: "%DATE1%    0.0.1    nodejs:14    daily/hello\n",
+			expectedOutput: "%DATE1%    0.0.1    nodejs:14    256    3s    true    daily/hello\n",
 		},
 		{
 			name:       "name flag",
 			doctlFlags: map[string]string{"name": ""},
 			skip:       0,
 			limit:      0,
-			expectedOutput: `%DATE2%    0.0.2    nodejs:14    daily/goodbye
-%DATE1%    0.0.1    nodejs:14    daily/hello
-%DATE3%    0.0.3    nodejs:14    sometimes/meAgain
+			expectedOutput: `%DATE2%    0.0.2    nodejs:14                 false    daily/goodbye
+%DATE1%    0.0.1    nodejs:14    256    3s    true     daily/hello
+%DATE3%    0.0.3    nodejs:14                 false    sometimes/meAgain
 `,
 		},
 		{
@@ -313,9 +362,9 @@ func TestFunctionsList(t *testing.T) {
 			doctlFlags: map[string]string{"name-sort": ""},
 			skip:       0,
 			limit:      0,
-			expectedOutput: `%DATE2%    0.0.2    nodejs:14    daily/goodbye
-%DATE1%    0.0.1    nodejs:14    daily/hello
-%DATE3%    0.0.3    nodejs:14    sometimes/meAgain
+			expectedOutput: `%DATE2%    0.0.2    nodejs:14                 false    daily/goodbye
+%DATE1%    0.0.1    nodejs:14    256    3s    true     daily/hello
+%DATE3%    0.0.3    nodejs:14                 false    sometimes/meAgain
 `,
 		},
 		{
@@ -323,23 +372,29 @@ func TestFunctionsList(t *testing.T) {
 			doctlFlags: map[string]string{"skip": "1"},
 			skip:       1,
 			limit:      0,
-			expectedOutput: `%DATE2%    0.0.2    nodejs:14    daily/goodbye
-%DATE3%    0.0.3    nodejs:14    sometimes/meAgain
+			expectedOutput: `%DATE2%    0.0.2    nodejs:14            false    daily/goodbye
+%DATE3%    0.0.3    nodejs:14            false    sometimes/meAgain
 `,
 		},
 	}
 
+	memory, timeout := 256, 3000
 	theList := []whisk.Action{
 		{
 			Name:      "hello",
 			Namespace: "theNamespace/daily",
 			Updated:   timestamps[0],
 			Version:   "0.0.1",
+			Limits:    &whisk.Limits{Memory: &memory, Timeout: &timeout},
 			Annotations: whisk.KeyValueArr{
 				whisk.KeyValue{
 					Key:   "exec",
 					Value: "nodejs:14",
 				},
+				whisk.KeyValue{
+					Key:   "web-export",
+					Value: true,
+				},
 			},
 		},
 		{
@@ -393,6 +448,7 @@ func TestFunctionsList(t *testing.T) {
 					answer = answer[0:tt.limit]
 				}
 				tm.serverless.EXPECT().ListFunctions(tt.doctlArg, tt.skip, tt.limit).Return(answer, nil)
+				tm.serverless.EXPECT().GetConnectedAPIHost().Return("https://api.example.com", nil).AnyTimes()
 
 				err := RunFunctionsList(config)
 				require.NoError(t, err)
@@ -423,3 +479,32 @@ func selectPackage(masterList []whisk.Action, pkg string) []whisk.Action {
 	}
 	return answer
 }
+
+func TestFunctionsListURLColumn(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		buf := &bytes.Buffer{}
+		config.Out = buf
+
+		tm.serverless.EXPECT().ListFunctions("", 0, 0).Return([]whisk.Action{
+			{
+				Name:        "hello",
+				Namespace:   "fn-aaa/sample",
+				Annotations: whisk.KeyValueArr{{Key: "web-export", Value: true}},
+			},
+			{
+				Name:      "private",
+				Namespace: "fn-aaa",
+			},
+		}, nil)
+		tm.serverless.EXPECT().GetConnectedAPIHost().Return("https://faas.example.com", nil)
+
+		config.Doit.Set(config.NS, "no-header", true)
+		config.Doit.Set(config.NS, "format", "Function,URL")
+
+		err := RunFunctionsList(config)
+		require.NoError(t, err)
+		assert.Equal(t, `sample/hello    https://faas.example.com/api/v1/web/fn-aaa/sample/hello
+private         https://faas.example.com/api/v1/namespaces/fn-aaa/actions/private
+`, buf.String())
+	})
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/viper"
+)
+
+// githubOutputEnv names the file GitHub Actions reads step outputs from.
+const githubOutputEnv = "GITHUB_OUTPUT"
+
+var (
+	// githubAnnotations receives the workflow commands that create GitHub
+	// Actions annotations. The runner reads them from stdout.
+	githubAnnotations io.Writer = os.Stdout
+
+	githubOutputWarnOnce sync.Once
+)
+
+func githubOutputEnabled() bool {
+	return viper.GetBool(doctl.ArgGitHubOutput)
+}
+
+// githubAnnotate emits a GitHub Actions annotation of the given level
+// (error, warning or notice) if --github-output is set.
+func githubAnnotate(level, msg string) {
+	if !githubOutputEnabled() {
+		return
+	}
+	// Workflow command data is percent-encoded, so that it stays on one line.
+	msg = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(msg)
+	fmt.Fprintf(githubAnnotations, "::%s::%s\n", level, msg)
+}
+
+// setGitHubOutputs writes step outputs to $GITHUB_OUTPUT if --github-output is
+// set. Empty values are skipped.
+func setGitHubOutputs(outputs map[string]string) error {
+	if !githubOutputEnabled() {
+		return nil
+	}
+	path := os.Getenv(githubOutputEnv)
+	if path == "" {
+		githubOutputWarnOnce.Do(func() {
+			warn("--%s is set, but $%s is not; outputs are only written when running in GitHub Actions", doctl.ArgGitHubOutput, githubOutputEnv)
+		})
+		return nil
+	}
+
+	keys := make([]string, 0, len(outputs))
+	for k, v := range outputs {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		v := outputs[k]
+		if !strings.ContainsAny(v, "\r\n") {
+			fmt.Fprintf(&b, "%s=%s\n", k, v)
+			continue
+		}
+		// Multiline values need a delimiter that can't appear in the value.
+		delim, err := githubOutputDelimiter()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "%s<<%s\n%s\n%s\n", k, delim, v, delim)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("writing GitHub Actions outputs: %v", err)
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		f.Close()
+		return fmt.Errorf("writing GitHub Actions outputs: %v", err)
+	}
+	return f.Close()
+}
+
+func githubOutputDelimiter() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "ghadelimiter_" + hex.EncodeToString(b), nil
+}
+
+// appGitHubOutputs are the step outputs describing an app.
+func appGitHubOutputs(app *godo.App, deploymentID string) map[string]string {
+	url := app.LiveURL
+	if url == "" {
+		url = app.DefaultIngress
+	}
+	if deploymentID == "" {
+		deploymentID = app.GetPendingDeployment().GetID()
+	}
+	if deploymentID == "" {
+		deploymentID = app.GetActiveDeployment().GetID()
+	}
+	return map[string]string{
+		"app_id":        app.ID,
+		"app_url":       url,
+		"deployment_id": deploymentID,
+	}
+}
+
+// dropletGitHubOutputs are the step outputs describing created Droplets. The
+// singular outputs describe the first Droplet, for the common case of one.
+func dropletGitHubOutputs(droplets do.Droplets) map[string]string {
+	var ids, ips []string
+	for _, d := range droplets {
+		ids = append(ids, fmt.Sprint(d.ID))
+		ip, _ := d.PublicIPv4()
+		ips = append(ips, ip)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	outputs := map[string]string{
+		"droplet_id":  ids[0],
+		"droplet_ip":  ips[0],
+		"droplet_ids": strings.Join(ids, ","),
+	}
+	// Addresses are only known once the Droplets are active.
+	if allIPs := strings.Join(ips, ","); strings.Trim(allIPs, ",") != "" {
+		outputs["droplet_ips"] = allIPs
+	}
+	return outputs
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func enableGitHubOutput(t *testing.T) string {
+	viper.Set(doctl.ArgGitHubOutput, true)
+	t.Cleanup(func() { viper.Set(doctl.ArgGitHubOutput, false) })
+
+	path := filepath.Join(t.TempDir(), "output")
+	t.Setenv(githubOutputEnv, path)
+	return path
+}
+
+func TestSetGitHubOutputs(t *testing.T) {
+	path := enableGitHubOutput(t)
+	require.NoError(t, os.WriteFile(path, []byte("earlier=step\n"), 0644))
+
+	require.NoError(t, setGitHubOutputs(map[string]string{
+		"app_id":  "1234",
+		"empty":   "",
+		"details": "line one\nline two",
+	}))
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^earlier=step
+app_id=1234
+details<<(ghadelimiter_[0-9a-f]{32})
+line one
+line two
+ghadelimiter_[0-9a-f]{32}
+$`), string(got))
+}
+
+func TestSetGitHubOutputsDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output")
+	t.Setenv(githubOutputEnv, path)
+
+	require.NoError(t, setGitHubOutputs(map[string]string{"app_id": "1234"}))
+	assert.NoFileExists(t, path)
+}
+
+func TestGitHubAnnotate(t *testing.T) {
+	enableGitHubOutput(t)
+
+	var out bytes.Buffer
+	defer func(w io.Writer) { githubAnnotations = w }(githubAnnotations)
+	githubAnnotations = &out
+
+	githubAnnotate("error", "100% failed:\nnot found")
+	assert.Equal(t, "::error::100%25 failed:%0Anot found\n", out.String())
+}
+
+func TestAppsCreateDeploymentGitHubOutputs(t *testing.T) {
+	path := enableGitHubOutput(t)
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.apps.EXPECT().CreateDeployment("app-id", false).Return(&godo.Deployment{ID: "dep-id"}, nil)
+
+		config.Args = []string{"app-id"}
+		require.NoError(t, RunAppsCreateDeployment(config))
+	})
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "app_id=app-id\ndeployment_id=dep-id\n", string(got))
+}
+
+func TestDropletGitHubOutputs(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"droplet_id":  "1",
+		"droplet_ip":  "192.0.2.1",
+		"droplet_ids": "1,2",
+		"droplet_ips": "192.0.2.1,192.0.2.2",
+	}, dropletGitHubOutputs(do.Droplets{
+		execTestDroplet(1, "web1", "192.0.2.1"),
+		execTestDroplet(2, "web2", "192.0.2.2"),
+	}))
+
+	// Droplets that weren't waited for have no address yet.
+	pending := do.Droplet{Droplet: &godo.Droplet{ID: 3, Networks: &godo.Networks{}}}
+	assert.Equal(t, map[string]string{
+		"droplet_id":  "3",
+		"droplet_ip":  "",
+		"droplet_ids": "3",
+	}, dropletGitHubOutputs(do.Droplets{pending}))
+}
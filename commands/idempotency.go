@@ -0,0 +1,137 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/godo"
+)
+
+// idempotencyTagPrefix prefixes the tag recording the idempotency key a
+// resource was created with. The API has no idempotency keys of its own, so
+// they are emulated with tags: before a create with a key is tried, resources
+// with the key's tag are looked up, and one that is found is used instead of
+// creating another.
+const idempotencyTagPrefix = "doctl-idempotency:"
+
+// idempotentCreateAttempts is how many times a create with a key that fails
+// with a transient error is tried.
+const idempotentCreateAttempts = 4
+
+// idempotentRetryWait is how long to wait before retrying a create the first
+// time. It doubles with each retry. It is replaced for testing.
+var idempotentRetryWait = 2 * time.Second
+
+// idempotencyKeyPattern matches the keys that make valid tags.
+var idempotencyKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9_\-:]{1,64}$`)
+
+// idempotency identifies the resources created by a create command with an
+// idempotency key, so that a create that failed with a transient error, but
+// may have succeeded, can be retried without making a duplicate.
+type idempotency struct {
+	// key is the key given with --idempotency-key, if any. A resource
+	// created with it is tagged with it, so that it is found by a retry or a
+	// later run of the command.
+	key string
+}
+
+// getIdempotency returns the idempotency of the create command.
+func getIdempotency(c *CmdConfig) (idempotency, error) {
+	key, err := c.Doit.GetString(c.NS, doctl.ArgIdempotencyKey)
+	if err != nil {
+		return idempotency{}, err
+	}
+	if key != "" && !idempotencyKeyPattern.MatchString(key) {
+		return idempotency{}, fmt.Errorf("`--%s` may only contain letters, numbers, colons, dashes, and underscores, and must be at most 64 characters", doctl.ArgIdempotencyKey)
+	}
+	return idempotency{key: key}, nil
+}
+
+// tags returns the tags of a resource to create: tags, and the tag of the
+// key, if one was given.
+func (i idempotency) tags(tags []string) []string {
+	if i.key == "" {
+		return tags
+	}
+	return append(append([]string{}, tags...), idempotencyTagPrefix+i.key)
+}
+
+// owns reports whether a resource with tags was created with the key. A
+// resource is never owned without a key, since it can't be told apart from
+// others with the same name.
+func (i idempotency) owns(tags []string) bool {
+	return i.key != "" && contains(tags, idempotencyTagPrefix+i.key)
+}
+
+// idempotentCreate calls create. With a key, find looks for a resource
+// already created with it before each attempt, which is returned instead of
+// creating another, and a create that fails with a transient error is
+// retried. Without a key, create is called once, and retried only by the API
+// client.
+func idempotentCreate[T any](i idempotency, find func() (*T, error), create func() (*T, error)) (*T, error) {
+	if i.key == "" {
+		return create()
+	}
+
+	wait := idempotentRetryWait
+	for attempt := 1; ; attempt++ {
+		found, err := find()
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			return found, nil
+		}
+
+		created, err := create()
+		if err == nil || attempt == idempotentCreateAttempts || !transientError(err) {
+			return created, err
+		}
+		warn("Retrying in %s after a transient error: %v", wait, err)
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// transientError reports whether a request that failed with err may succeed
+// if it is retried: it was rate limited, failed on the server, or failed on
+// the network.
+func transientError(err error) bool {
+	var errResp *godo.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil {
+		code := errResp.Response.StatusCode
+		return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// withoutAutomaticRetries passes set the command's API client with its
+// automatic retries turned off, if a key was given, since a create retried
+// without looking for the resource first could make a duplicate.
+// idempotentCreate retries creates with a key instead.
+func withoutAutomaticRetries(c *CmdConfig, i idempotency, set func(*godo.Client)) {
+	if RetryMax == 0 || i.key == "" || c.godoClient == nil {
+		return
+	}
+	set(doctl.WithoutRetries(c.godoClient))
+}
@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// withoutRetryWait makes idempotentCreate retry immediately for the rest of the test.
+func withoutRetryWait(t *testing.T) {
+	orig := idempotentRetryWait
+	t.Cleanup(func() { idempotentRetryWait = orig })
+	idempotentRetryWait = 0
+}
+
+func TestGetIdempotency(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		idem, err := getIdempotency(config)
+		require.NoError(t, err)
+		assert.Empty(t, idem.key)
+		assert.Equal(t, []string{"web"}, idem.tags([]string{"web"}))
+
+		config.Doit.Set(config.NS, doctl.ArgIdempotencyKey, "ci-1234:deploy")
+		idem, err = getIdempotency(config)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"web", "doctl-idempotency:ci-1234:deploy"}, idem.tags([]string{"web"}))
+		assert.True(t, idem.owns([]string{"doctl-idempotency:ci-1234:deploy"}))
+		assert.False(t, idem.owns([]string{"web"}))
+
+		config.Doit.Set(config.NS, doctl.ArgIdempotencyKey, "not a tag")
+		_, err = getIdempotency(config)
+		assert.Error(t, err)
+	})
+}
+
+func TestIdempotencyOwnsWithoutKey(t *testing.T) {
+	assert.False(t, idempotency{}.owns(nil))
+	assert.False(t, idempotency{}.owns([]string{"doctl-idempotency:"}))
+}
+
+func TestTransientError(t *testing.T) {
+	assert.True(t, transientError(testAPIErr(http.StatusServiceUnavailable, "unavailable")))
+	assert.True(t, transientError(testAPIErr(http.StatusTooManyRequests, "slow down")))
+	assert.False(t, transientError(testAPIErr(http.StatusUnprocessableEntity, "invalid size")))
+	assert.True(t, transientError(&net.OpError{Op: "read", Err: errors.New("connection reset")}))
+	assert.True(t, transientError(io.ErrUnexpectedEOF))
+	assert.False(t, transientError(errors.New("invalid")))
+}
+
+func TestIdempotentCreate(t *testing.T) {
+	withoutRetryWait(t)
+	created := &do.Droplet{Droplet: &godo.Droplet{ID: 1}}
+	idem := idempotency{key: "ci-1234"}
+
+	t.Run("retries transient errors, looking for the resource first", func(t *testing.T) {
+		var finds, creates int
+		d, err := idempotentCreate(idem, func() (*do.Droplet, error) {
+			finds++
+			return nil, nil
+		}, func() (*do.Droplet, error) {
+			creates++
+			if creates == 1 {
+				return nil, testAPIErr(http.StatusInternalServerError, "server error")
+			}
+			return created, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, created, d)
+		assert.Equal(t, 2, finds)
+		assert.Equal(t, 2, creates)
+	})
+
+	t.Run("returns a resource created by a failed attempt", func(t *testing.T) {
+		var creates int
+		d, err := idempotentCreate(idem, func() (*do.Droplet, error) {
+			if creates == 0 {
+				return nil, nil
+			}
+			return created, nil
+		}, func() (*do.Droplet, error) {
+			creates++
+			return nil, testAPIErr(http.StatusBadGateway, "bad gateway")
+		})
+		require.NoError(t, err)
+		assert.Equal(t, created, d)
+		assert.Equal(t, 1, creates)
+	})
+
+	t.Run("doesn't retry other errors", func(t *testing.T) {
+		var creates int
+		_, err := idempotentCreate(idem, func() (*do.Droplet, error) {
+			return nil, nil
+		}, func() (*do.Droplet, error) {
+			creates++
+			return nil, testAPIErr(http.StatusUnprocessableEntity, "invalid size")
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, creates)
+	})
+
+	t.Run("gives up after the last attempt", func(t *testing.T) {
+		var creates int
+		_, err := idempotentCreate(idem, func() (*do.Droplet, error) {
+			return nil, nil
+		}, func() (*do.Droplet, error) {
+			creates++
+			return nil, testAPIErr(http.StatusServiceUnavailable, "unavailable")
+		})
+		assert.Error(t, err)
+		assert.Equal(t, idempotentCreateAttempts, creates)
+	})
+
+	t.Run("looks for the resource before creating it", func(t *testing.T) {
+		d, err := idempotentCreate(idem, func() (*do.Droplet, error) {
+			return created, nil
+		}, func() (*do.Droplet, error) {
+			t.Fatal("unexpected create")
+			return nil, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, created, d)
+	})
+
+	t.Run("without a key, creates once without looking", func(t *testing.T) {
+		var creates int
+		_, err := idempotentCreate(idempotency{}, func() (*do.Droplet, error) {
+			t.Fatal("unexpected find")
+			return nil, nil
+		}, func() (*do.Droplet, error) {
+			creates++
+			return nil, testAPIErr(http.StatusServiceUnavailable, "unavailable")
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, creates)
+	})
+}
+
+func TestDropletCreateIdempotencyKey(t *testing.T) {
+	withoutRetryWait(t)
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		dcr := &godo.DropletCreateRequest{
+			Name:    "web",
+			Region:  "nyc3",
+			Size:    "s-1vcpu-1gb",
+			Image:   godo.DropletCreateImage{Slug: "ubuntu-24-04-x64"},
+			SSHKeys: []godo.DropletCreateSSHKey{},
+			Tags:    []string{"doctl-idempotency:ci-1234"},
+		}
+		made := *testDroplet.Droplet
+		made.Name = "web"
+		made.Region = &godo.Region{Slug: "nyc3"}
+		made.Tags = []string{"doctl-idempotency:ci-1234"}
+
+		// The create fails after the Droplet was made, so the retry finds it.
+		gomock.InOrder(
+			tm.droplets.EXPECT().ListByTag("doctl-idempotency:ci-1234").Return(nil, nil),
+			tm.droplets.EXPECT().Create(dcr, false).Return(nil, testAPIErr(http.StatusGatewayTimeout, "timeout")),
+			tm.droplets.EXPECT().ListByTag("doctl-idempotency:ci-1234").Return(do.Droplets{{Droplet: &made}}, nil),
+		)
+
+		config.Args = append(config.Args, "web")
+		config.Doit.Set(config.NS, doctl.ArgRegionSlug, "nyc3")
+		config.Doit.Set(config.NS, doctl.ArgSizeSlug, "s-1vcpu-1gb")
+		config.Doit.Set(config.NS, doctl.ArgImage, "ubuntu-24-04-x64")
+		config.Doit.Set(config.NS, doctl.ArgIdempotencyKey, "ci-1234")
+
+		err := RunDropletCreate(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestVolumeCreateIdempotencyKey(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tcr := godo.VolumeCreateRequest{
+			Name:          "test-volume",
+			SizeGigaBytes: 100,
+			Region:        "atlantis",
+			Tags:          []string{"doctl-idempotency:ci-1234"},
+		}
+		tm.volumes.EXPECT().List().Return(testVolumeList, nil)
+		tm.volumes.EXPECT().CreateVolume(&tcr).Return(&testVolume, nil)
+
+		config.Args = append(config.Args, "test-volume")
+		config.Doit.Set(config.NS, doctl.ArgVolumeRegion, "atlantis")
+		config.Doit.Set(config.NS, doctl.ArgVolumeSize, "100GiB")
+		config.Doit.Set(config.NS, doctl.ArgIdempotencyKey, "ci-1234")
+
+		// testVolume has the name, but not the key's tag, so it isn't reused.
+		err := RunVolumeCreate(config)
+		assert.NoError(t, err)
+	})
+}
@@ -106,6 +106,10 @@ func RunImageActionsTransfer(c *CmdConfig) error {
 	if err != nil {
 		return err
 	}
+	region, err = resolveRegionAlias(c, region)
+	if err != nil {
+		return err
+	}
 
 	req := &godo.ActionRequest{
 		"type":   "transfer",
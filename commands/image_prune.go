@@ -0,0 +1,140 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+)
+
+// imageStoragePrice is the monthly price of storing a snapshot or custom
+// image, in USD per GiB.
+const imageStoragePrice = 0.06
+
+// RunImagesPrune deletes the custom images and snapshots that no Droplet was
+// created from and that have not been used recently.
+func RunImagesPrune(c *CmdConfig) error {
+	olderThan, err := c.Doit.GetDuration(c.NS, doctl.ArgImagePruneOlderThan)
+	if err != nil {
+		return err
+	}
+	dryRun, err := c.Doit.GetBool(c.NS, doctl.ArgDryRun)
+	if err != nil {
+		return err
+	}
+	force, err := c.Doit.GetBool(c.NS, doctl.ArgForce)
+	if err != nil {
+		return err
+	}
+
+	images, err := prunableImages(c, olderThan, cleanupNow())
+	if err != nil {
+		return err
+	}
+
+	var savings float64
+	for _, i := range images {
+		savings += i.MonthlyCost
+	}
+	if len(images) == 0 || dryRun {
+		if len(images) > 0 {
+			notice("Deleting these %d image(s) would save about $%.2f per month", len(images), savings)
+		}
+		return c.Display(&displayers.PrunableImages{Images: images})
+	}
+
+	if !force && AskForConfirm(fmt.Sprintf("delete %d image(s), saving about $%.2f per month", len(images), savings)) != nil {
+		return errOperationAborted
+	}
+
+	failed := 0
+	is := c.Images()
+	for n, i := range images {
+		if err := is.Delete(i.ID); err != nil {
+			warn("Could not delete image %d: %v", i.ID, err)
+			failed++
+			continue
+		}
+		images[n].Deleted = true
+	}
+	if err := c.Display(&displayers.PrunableImages{Images: images}); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d image(s) could not be deleted", failed)
+	}
+	return nil
+}
+
+// prunableImages lists the custom images and snapshots older than olderThan
+// that no existing Droplet was created from and that no action has touched
+// within olderThan.
+func prunableImages(c *CmdConfig, olderThan time.Duration, now time.Time) ([]displayers.PrunableImage, error) {
+	images, err := c.Images().ListUser(false)
+	if err != nil {
+		return nil, err
+	}
+	droplets, err := c.Droplets().List()
+	if err != nil {
+		return nil, err
+	}
+	actions, err := c.Actions().List()
+	if err != nil {
+		return nil, err
+	}
+
+	inUse := map[int]bool{}
+	for _, d := range droplets {
+		if d.Image != nil {
+			inUse[d.Image.ID] = true
+		}
+	}
+	cutoff := now.Add(-olderThan)
+	for _, a := range actions {
+		if a.ResourceType == "image" && a.StartedAt != nil && a.StartedAt.After(cutoff) {
+			inUse[a.ResourceID] = true
+		}
+	}
+
+	out := []displayers.PrunableImage{}
+	for _, i := range images {
+		if i.Type != "snapshot" && i.Type != "custom" {
+			continue
+		}
+		if inUse[i.ID] {
+			continue
+		}
+		created, err := time.Parse(time.RFC3339, i.Created)
+		if err != nil || created.After(cutoff) {
+			continue
+		}
+
+		// Each region an image has been transferred to stores a copy.
+		out = append(out, displayers.PrunableImage{
+			ID:          i.ID,
+			Name:        i.Name,
+			Type:        i.Type,
+			Regions:     i.Regions,
+			Created:     i.Created,
+			AgeDays:     int(now.Sub(created).Hours() / 24),
+			SizeGB:      i.SizeGigaBytes,
+			MonthlyCost: i.SizeGigaBytes * imageStoragePrice * float64(max(len(i.Regions), 1)),
+		})
+	}
+	return out, nil
+}
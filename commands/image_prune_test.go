@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	pruneNow = time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+
+	pruneImages = do.Images{
+		// Old and unused, stored in two regions.
+		{Image: &godo.Image{ID: 1, Name: "old-snap", Type: "snapshot", Regions: []string{"nyc1", "sfo3"}, SizeGigaBytes: 10, Created: "2023-01-01T00:00:00Z"}},
+		// Too new.
+		{Image: &godo.Image{ID: 2, Name: "new-snap", Type: "snapshot", Regions: []string{"nyc1"}, SizeGigaBytes: 10, Created: "2024-05-01T00:00:00Z"}},
+		// A Droplet was created from it.
+		{Image: &godo.Image{ID: 3, Name: "golden", Type: "custom", Regions: []string{"nyc1"}, SizeGigaBytes: 5, Created: "2023-01-01T00:00:00Z"}},
+		// Transferred recently.
+		{Image: &godo.Image{ID: 4, Name: "moved", Type: "snapshot", Regions: []string{"nyc1"}, SizeGigaBytes: 5, Created: "2023-01-01T00:00:00Z"}},
+		// Backups are never pruned.
+		{Image: &godo.Image{ID: 5, Name: "backup", Type: "backup", Regions: []string{"nyc1"}, SizeGigaBytes: 5, Created: "2023-01-01T00:00:00Z"}},
+		{Image: &godo.Image{ID: 6, Name: "old-custom", Type: "custom", Regions: []string{"nyc1"}, SizeGigaBytes: 2.5, Created: "2023-06-01T00:00:00Z"}},
+	}
+)
+
+func expectPruneLookups(tm *tcMocks) {
+	tm.images.EXPECT().ListUser(false).Return(pruneImages, nil)
+	tm.droplets.EXPECT().List().Return(do.Droplets{
+		{Droplet: &godo.Droplet{ID: 10, Image: &godo.Image{ID: 3}}},
+	}, nil)
+	tm.actions.EXPECT().List().Return(do.Actions{
+		{Action: &godo.Action{ID: 20, Type: "transfer", ResourceType: "image", ResourceID: 4, StartedAt: &godo.Timestamp{Time: pruneNow.Add(-48 * time.Hour)}}},
+		{Action: &godo.Action{ID: 21, Type: "transfer", ResourceType: "image", ResourceID: 6, StartedAt: &godo.Timestamp{Time: pruneNow.Add(-200 * 24 * time.Hour)}}},
+	}, nil)
+}
+
+func TestPrunableImages(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		expectPruneLookups(tm)
+
+		got, err := prunableImages(config, 90*24*time.Hour, pruneNow)
+		require.NoError(t, err)
+		assert.Equal(t, []displayers.PrunableImage{
+			{ID: 1, Name: "old-snap", Type: "snapshot", Regions: []string{"nyc1", "sfo3"}, Created: "2023-01-01T00:00:00Z", AgeDays: 495, SizeGB: 10, MonthlyCost: 1.2},
+			{ID: 6, Name: "old-custom", Type: "custom", Regions: []string{"nyc1"}, Created: "2023-06-01T00:00:00Z", AgeDays: 344, SizeGB: 2.5, MonthlyCost: 0.15},
+		}, got)
+	})
+}
+
+func TestImagesPrune(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		cleanupNow = func() time.Time { return pruneNow }
+		defer func() { cleanupNow = time.Now }()
+
+		expectPruneLookups(tm)
+		tm.images.EXPECT().Delete(1).Return(nil)
+		tm.images.EXPECT().Delete(6).Return(nil)
+
+		config.Doit.Set(config.NS, doctl.ArgImagePruneOlderThan, 90*24*time.Hour)
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+
+		assert.NoError(t, RunImagesPrune(config))
+	})
+}
+
+func TestImagesPruneDryRun(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		cleanupNow = func() time.Time { return pruneNow }
+		defer func() { cleanupNow = time.Now }()
+
+		expectPruneLookups(tm)
+
+		config.Doit.Set(config.NS, doctl.ArgImagePruneOlderThan, 90*24*time.Hour)
+		config.Doit.Set(config.NS, doctl.ArgDryRun, true)
+
+		assert.NoError(t, RunImagesPrune(config))
+	})
+}
@@ -15,15 +15,39 @@ package commands
 
 import (
 	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/commands/displayers"
 	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/doctl/pkg/spaces"
 	"github.com/digitalocean/godo"
 	"github.com/spf13/cobra"
 )
 
+// imageUploader stages local image files in Spaces so they can be imported.
+type imageUploader interface {
+	Upload(bucket, key string, r io.ReaderAt, size int64) error
+	PresignGet(bucket, key string, expires time.Duration) (string, error)
+	Delete(bucket, key string) error
+}
+
+// newImageUploader returns the uploader for a Spaces region. It is replaced for testing.
+var newImageUploader = func(region, accessKey, secretKey string) imageUploader {
+	client := spaces.New(region, accessKey, secretKey)
+	client.HTTP = doctl.ReadOnlyClient(client.HTTP)
+	return client
+}
+
+// imagePollInterval is how often an importing image's status is checked.
+var imagePollInterval = 10 * time.Second
+
 // Images creates an image command.
 func Images() *Command {
 	cmd := &Command{
@@ -90,12 +114,38 @@ Currently, there are five types of images: snapshots, backups, custom images, di
 
 	cmdRunImagesCreate := CmdBuilder(cmd, RunImagesCreate, "create <image-name>", "Create custom image", `Creates an image in your DigitalOcean account. Specify a URL to download the image from and the region to store the image in. You can add additional metadata to the image using the optional flags.`, Writer)
 	AddStringFlag(cmdRunImagesCreate, doctl.ArgImageExternalURL, "", "", "The URL to retrieve the image from", requiredOpt())
-	AddStringFlag(cmdRunImagesCreate, doctl.ArgRegionSlug, "", "", "The slug of the region you want to store the image in. For a list of region slugs, use the `doctl compute region list` command.", requiredOpt())
+	AddStringFlag(cmdRunImagesCreate, doctl.ArgRegionSlug, "", "", "The slug of the region you want to store the image in. For a list of region slugs, use the `doctl compute region list` command. Defaults to the configured `default-region`.")
 	AddStringFlag(cmdRunImagesCreate, doctl.ArgImageDistro, "", "Unknown", "A custom image distribution slug to apply to the image")
 	AddStringFlag(cmdRunImagesCreate, doctl.ArgImageDescription, "", "", "An optional description of the image")
 	AddStringSliceFlag(cmdRunImagesCreate, doctl.ArgTagNames, "", []string{}, "A list of tag names to apply to the image")
+
+	cmdImagesUpload := CmdBuilder(cmd, RunImagesUpload, "upload <file-or-url>", "Upload a custom image from a local file or URL", `Imports a custom image into your DigitalOcean account from a local file or a URL.
+
+Custom images can only be imported from a URL, so local files are first uploaded to a Spaces bucket you own, given with `+"`"+`--spaces-bucket`+"`"+`. The bucket must be in the same region as the image. The upload uses your Spaces access keys, read from the `+"`"+`SPACES_ACCESS_KEY_ID`+"`"+` and `+"`"+`SPACES_SECRET_ACCESS_KEY`+"`"+` environment variables, and the object stays private: DigitalOcean imports it through a short-lived presigned URL.
+
+With `+"`"+`--wait`+"`"+`, the command polls until the image is available and then removes the staged file from Spaces. With `+"`"+`--test-boot`+"`"+`, it also creates a Droplet from the new image, waits for it to become active, and deletes it again to verify that the image boots.`, Writer,
+		displayerType(&displayers.Image{}))
+	AddStringFlag(cmdImagesUpload, doctl.ArgRegionSlug, "", "", "The slug of the region you want to store the image in. For a list of region slugs, use the `doctl compute region list` command. Defaults to the configured `default-region`.")
+	AddStringFlag(cmdImagesUpload, doctl.ArgImageName, "", "", "The name of the image. Defaults to the file name.")
+	AddStringFlag(cmdImagesUpload, doctl.ArgImageUploadDistribution, "", "Unknown", "A custom image distribution slug to apply to the image")
+	AddStringFlag(cmdImagesUpload, doctl.ArgImageDescription, "", "", "An optional description of the image")
+	AddStringSliceFlag(cmdImagesUpload, doctl.ArgTagNames, "", []string{}, "A list of tag names to apply to the image")
+	AddStringFlag(cmdImagesUpload, doctl.ArgImageSpacesBucket, "", "", "The Spaces bucket used to stage a local image file")
+	AddBoolFlag(cmdImagesUpload, doctl.ArgCommandWait, "", false, "Wait for the image to be available before returning")
+	AddBoolFlag(cmdImagesUpload, doctl.ArgImageTestBoot, "", false, "Boot a temporary Droplet from the image to verify it works. Implies `--wait`.")
+	AddStringFlag(cmdImagesUpload, doctl.ArgImageTestSize, "", "s-1vcpu-1gb", "The size slug of the Droplet used for `--test-boot`")
+	cmdImagesUpload.Example = `The following example uploads a local qcow2 file through the ` + "`" + `my-images` + "`" + ` bucket and verifies that it boots: doctl compute image upload ./debian.qcow2 --region nyc3 --distribution Debian --spaces-bucket my-images --test-boot`
 	cmdRunImagesCreate.Example = `The following example creates a custom image named ` + "`" + `Example Image` + "`" + ` from a URL and stores it in the ` + "`" + `nyc1` + "`" + ` region: doctl compute image create "Example Image" --image-url "https://example.com/image.iso" --region nyc1`
 
+	cmdImagesPrune := CmdBuilder(cmd, RunImagesPrune, "prune", "Delete unused custom images and snapshots", `Deletes the custom images and Droplet snapshots on your account that appear to be unused. An image is considered unused if it is older than `+"`"+`--older-than`+"`"+`, no existing Droplet was created from it, and no action, such as a transfer, has been taken on it within that time.
+
+The command lists the images with the approximate monthly cost of storing each one, based on $0.06 per GiB per region, and asks for confirmation before deleting them. Backups are never pruned.`, Writer,
+		displayerType(&displayers.PrunableImages{}))
+	AddDurationFlag(cmdImagesPrune, doctl.ArgImagePruneOlderThan, "", 90*24*time.Hour, "Only prune images older than this, and not used within this time")
+	AddBoolFlag(cmdImagesPrune, doctl.ArgDryRun, "", false, "List the images that would be deleted without deleting them")
+	AddBoolFlag(cmdImagesPrune, doctl.ArgForce, doctl.ArgShortForce, false, "Delete the images without a confirmation prompt")
+	cmdImagesPrune.Example = `The following example lists the images and snapshots unused for 30 days and how much deleting them would save: doctl compute image prune --older-than 720h --dry-run`
+
 	return cmd
 }
 
@@ -252,7 +302,7 @@ func RunImagesDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if force || AskForConfirmDelete("image", len(c.Args)) == nil {
+	if force || AskForConfirmDelete("image", len(c.Args), c.Args...) == nil {
 
 		for _, el := range c.Args {
 			id, err := strconv.Atoi(el)
@@ -299,10 +349,13 @@ func buildCustomImageRequestFromArgs(c *CmdConfig, r *godo.CustomImageCreateRequ
 	if err != nil {
 		return err
 	}
-	region, err := c.Doit.GetString(c.NS, doctl.ArgRegionSlug)
+	region, err := regionFromArgs(c, doctl.ArgRegionSlug)
 	if err != nil {
 		return err
 	}
+	if region == "" {
+		return doctl.NewMissingArgsErr(c.NS + "." + doctl.ArgRegionSlug)
+	}
 	distro, err := c.Doit.GetString(c.NS, doctl.ArgImageDistro)
 	if err != nil {
 		return err
@@ -325,3 +378,222 @@ func buildCustomImageRequestFromArgs(c *CmdConfig, r *godo.CustomImageCreateRequ
 
 	return nil
 }
+
+// RunImagesUpload imports a custom image from a local file or URL.
+func RunImagesUpload(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	source := c.Args[0]
+
+	region, err := regionFromArgs(c, doctl.ArgRegionSlug)
+	if err != nil {
+		return err
+	}
+	if region == "" {
+		return doctl.NewMissingArgsErr(c.NS + "." + doctl.ArgRegionSlug)
+	}
+	name, err := c.Doit.GetString(c.NS, doctl.ArgImageName)
+	if err != nil {
+		return err
+	}
+	distro, err := c.Doit.GetString(c.NS, doctl.ArgImageUploadDistribution)
+	if err != nil {
+		return err
+	}
+	desc, err := c.Doit.GetString(c.NS, doctl.ArgImageDescription)
+	if err != nil {
+		return err
+	}
+	tags, err := c.Doit.GetStringSlice(c.NS, doctl.ArgTagNames)
+	if err != nil {
+		return err
+	}
+	bucket, err := c.Doit.GetString(c.NS, doctl.ArgImageSpacesBucket)
+	if err != nil {
+		return err
+	}
+	wait, err := c.Doit.GetBool(c.NS, doctl.ArgCommandWait)
+	if err != nil {
+		return err
+	}
+	testBoot, err := c.Doit.GetBool(c.NS, doctl.ArgImageTestBoot)
+	if err != nil {
+		return err
+	}
+	testSize, err := c.Doit.GetString(c.NS, doctl.ArgImageTestSize)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		name = imageNameFromSource(source)
+	}
+
+	imageURL := source
+	var cleanup func()
+	if !isRemoteImage(source) {
+		imageURL, cleanup, err = stageImageFile(source, region, bucket)
+		if err != nil {
+			return err
+		}
+	}
+
+	is := c.Images()
+	i, err := is.Create(&godo.CustomImageCreateRequest{
+		Name:         name,
+		Url:          imageURL,
+		Region:       region,
+		Distribution: distro,
+		Description:  desc,
+		Tags:         tags,
+	})
+	if err != nil {
+		if cleanup != nil {
+			cleanup()
+		}
+		return err
+	}
+
+	if wait || testBoot {
+		notice("Waiting for image %d to be imported", i.ID)
+		i, err = waitForImageAvailable(is, i.ID)
+		if cleanup != nil {
+			cleanup()
+		}
+		if err != nil {
+			return err
+		}
+	} else if cleanup != nil {
+		notice("The image file stays in bucket %s until you delete it; use --wait to remove it automatically once the import completes", bucket)
+	}
+
+	if testBoot {
+		if err := testBootImage(c.Droplets(), i, region, testSize); err != nil {
+			return err
+		}
+	}
+
+	item := &displayers.Image{Images: do.Images{*i}}
+	return c.Display(item)
+}
+
+func isRemoteImage(source string) bool {
+	u, err := url.Parse(source)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+// imageNameFromSource derives an image name from the last path element of a file or URL.
+func imageNameFromSource(source string) string {
+	if u, err := url.Parse(source); err == nil && isRemoteImage(source) {
+		source = u.Path
+	}
+	base := filepath.Base(source)
+	for _, ext := range []string{".gz", ".bz2", ".xz"} {
+		base = strings.TrimSuffix(base, ext)
+	}
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// stageImageFile uploads a local image to Spaces and returns a presigned URL it can be
+// imported from, along with a function that removes the staged file.
+func stageImageFile(path, region, bucket string) (string, func(), error) {
+	if bucket == "" {
+		return "", nil, fmt.Errorf("uploading a local file requires a Spaces bucket; set --%s", doctl.ArgImageSpacesBucket)
+	}
+	accessKey := os.Getenv("SPACES_ACCESS_KEY_ID")
+	secretKey := os.Getenv("SPACES_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return "", nil, fmt.Errorf("uploading a local file requires the SPACES_ACCESS_KEY_ID and SPACES_SECRET_ACCESS_KEY environment variables")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", nil, err
+	}
+
+	uploader := newImageUploader(region, accessKey, secretKey)
+	key := fmt.Sprintf("doctl-images/%d-%s", time.Now().Unix(), filepath.Base(path))
+
+	notice("Uploading %s to %s/%s", path, bucket, key)
+	if err := uploader.Upload(bucket, key, f, info.Size()); err != nil {
+		return "", nil, err
+	}
+
+	cleanup := func() {
+		if err := uploader.Delete(bucket, key); err != nil {
+			warn("Could not remove %s/%s: %v", bucket, key, err)
+		}
+	}
+
+	u, err := uploader.PresignGet(bucket, key, 6*time.Hour)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return u, cleanup, nil
+}
+
+// waitForImageAvailable polls an image until it has been imported.
+func waitForImageAvailable(is do.ImagesService, id int) (*do.Image, error) {
+	failCount := 0
+	for {
+		i, err := is.GetByID(id)
+		if err != nil {
+			// Allow for transient API failures
+			failCount++
+			if failCount >= maxAPIFailures {
+				return nil, err
+			}
+			time.Sleep(imagePollInterval)
+			continue
+		}
+		failCount = 0
+
+		switch i.Status {
+		case "available":
+			return i, nil
+		case "deleted":
+			return nil, fmt.Errorf("image %d was deleted while importing", id)
+		}
+		if i.ErrorMessage != "" {
+			return nil, fmt.Errorf("image %d failed to import: %s", id, i.ErrorMessage)
+		}
+		time.Sleep(imagePollInterval)
+	}
+}
+
+// testBootImage creates a temporary Droplet from an image, waits for it to become
+// active, and deletes it again.
+func testBootImage(ds do.DropletsService, i *do.Image, region, size string) error {
+	notice("Booting a test Droplet from image %d", i.ID)
+	d, err := ds.Create(&godo.DropletCreateRequest{
+		Name:   fmt.Sprintf("image-test-%d", i.ID),
+		Region: region,
+		Size:   size,
+		Image:  godo.DropletCreateImage{ID: i.ID},
+	}, true)
+	if d != nil {
+		defer func() {
+			if err := ds.Delete(d.ID); err != nil {
+				warn("Could not delete test Droplet %d: %v", d.ID, err)
+			}
+		}()
+	}
+	if err != nil {
+		return fmt.Errorf("test boot of image %d failed: %w", i.ID, err)
+	}
+
+	if d.Status != "active" {
+		return fmt.Errorf("test boot of image %d failed: Droplet %d is %s", i.ID, d.ID, d.Status)
+	}
+	notice("Test Droplet %d booted successfully and is being deleted", d.ID)
+	return nil
+}
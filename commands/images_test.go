@@ -14,18 +14,25 @@ limitations under the License.
 package commands
 
 import (
+	"io"
+	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
 	"github.com/digitalocean/godo"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 func TestImageCommand(t *testing.T) {
 	cmd := Images()
 	assert.NotNil(t, cmd)
-	assertCommandNames(t, cmd, "create", "delete", "get", "list", "list-application", "list-distribution", "list-user", "update")
+	assertCommandNames(t, cmd, "create", "delete", "get", "list", "list-application", "list-distribution", "list-user", "prune", "update", "upload")
 }
 
 func TestImagesList(t *testing.T) {
@@ -151,3 +158,117 @@ func TestImagesCreate(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+type fakeImageUploader struct {
+	uploaded map[string][]byte
+	deleted  []string
+}
+
+func (f *fakeImageUploader) Upload(bucket, key string, r io.ReaderAt, size int64) error {
+	buf := make([]byte, size)
+	if _, err := r.ReadAt(buf, 0); err != nil && err != io.EOF {
+		return err
+	}
+	f.uploaded[bucket+"/"+key] = buf
+	return nil
+}
+
+func (f *fakeImageUploader) PresignGet(bucket, key string, expires time.Duration) (string, error) {
+	return "https://nyc3.example.com/" + bucket + "/" + key + "?signed", nil
+}
+
+func (f *fakeImageUploader) Delete(bucket, key string) error {
+	f.deleted = append(f.deleted, bucket+"/"+key)
+	return nil
+}
+
+func setUploadFlags(config *CmdConfig, bucket string, wait, testBoot bool) {
+	config.Doit.Set(config.NS, doctl.ArgRegionSlug, "nyc3")
+	config.Doit.Set(config.NS, doctl.ArgImageName, "")
+	config.Doit.Set(config.NS, doctl.ArgImageUploadDistribution, "Debian")
+	config.Doit.Set(config.NS, doctl.ArgImageSpacesBucket, bucket)
+	config.Doit.Set(config.NS, doctl.ArgTagNames, []string{"custom"})
+	config.Doit.Set(config.NS, doctl.ArgCommandWait, wait)
+	config.Doit.Set(config.NS, doctl.ArgImageTestBoot, testBoot)
+	config.Doit.Set(config.NS, doctl.ArgImageTestSize, "s-1vcpu-1gb")
+}
+
+func TestImagesUploadURL(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		r := &godo.CustomImageCreateRequest{
+			Name:         "debian-12",
+			Url:          "https://example.com/images/debian-12.qcow2.gz",
+			Region:       "nyc3",
+			Distribution: "Debian",
+			Tags:         []string{"custom"},
+		}
+		tm.images.EXPECT().Create(r).Return(&testImage, nil)
+
+		config.Args = append(config.Args, r.Url)
+		setUploadFlags(config, "", false, false)
+
+		err := RunImagesUpload(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestImagesUploadFile(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		path := filepath.Join(t.TempDir(), "disk.img")
+		require.NoError(t, os.WriteFile(path, []byte("image"), 0600))
+
+		t.Setenv("SPACES_ACCESS_KEY_ID", "key")
+		t.Setenv("SPACES_SECRET_ACCESS_KEY", "secret")
+
+		uploader := &fakeImageUploader{uploaded: map[string][]byte{}}
+		origUploader, origInterval := newImageUploader, imagePollInterval
+		newImageUploader = func(region, accessKey, secretKey string) imageUploader {
+			assert.Equal(t, "nyc3", region)
+			return uploader
+		}
+		imagePollInterval = 0
+		defer func() { newImageUploader, imagePollInterval = origUploader, origInterval }()
+
+		pending := do.Image{Image: &godo.Image{ID: testImage.ID, Status: "pending"}}
+		available := do.Image{Image: &godo.Image{ID: testImage.ID, Status: "available"}}
+		tm.images.EXPECT().Create(gomock.Any()).DoAndReturn(func(r *godo.CustomImageCreateRequest) (*do.Image, error) {
+			assert.Equal(t, "disk", r.Name)
+			assert.Contains(t, r.Url, "?signed")
+			return &pending, nil
+		})
+		gomock.InOrder(
+			tm.images.EXPECT().GetByID(testImage.ID).Return(&pending, nil),
+			tm.images.EXPECT().GetByID(testImage.ID).Return(&available, nil),
+		)
+
+		active := do.Droplet{Droplet: &godo.Droplet{ID: 42, Status: "active"}}
+		tm.droplets.EXPECT().Create(gomock.Any(), true).DoAndReturn(func(r *godo.DropletCreateRequest, wait bool) (*do.Droplet, error) {
+			assert.Equal(t, testImage.ID, r.Image.ID)
+			return &active, nil
+		})
+		tm.droplets.EXPECT().Delete(42).Return(nil)
+
+		config.Args = append(config.Args, path)
+		setUploadFlags(config, "my-images", false, true)
+
+		err := RunImagesUpload(config)
+		require.NoError(t, err)
+		assert.Len(t, uploader.uploaded, 1)
+		assert.Len(t, uploader.deleted, 1)
+	})
+}
+
+func TestImagesUploadFileRequiresBucket(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, "disk.img")
+		setUploadFlags(config, "", false, false)
+
+		err := RunImagesUpload(config)
+		assert.ErrorContains(t, err, "--spaces-bucket")
+	})
+}
+
+func TestImageNameFromSource(t *testing.T) {
+	assert.Equal(t, "debian", imageNameFromSource("/tmp/debian.qcow2"))
+	assert.Equal(t, "debian", imageNameFromSource("https://example.com/x/debian.img.bz2?token=1"))
+}
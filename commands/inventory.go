@@ -0,0 +1,833 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/doctl/pkg/inventory"
+	"github.com/digitalocean/doctl/pkg/notify"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/cobra"
+)
+
+// inventoryRecordConcurrency is how many domains have their records listed
+// at once.
+const inventoryRecordConcurrency = 4
+
+// Inventory creates the inventory commands.
+func Inventory() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:     "inventory",
+			Short:   "Display commands that take an inventory of your account",
+			Long:    `The subcommands of ` + "`" + `doctl inventory` + "`" + ` export every resource of your account, and the relationships between them, as a single document for audits and configuration management databases, detect changes between inventories, and draw the relationships as graphs.`,
+			GroupID: manageResourcesGroup,
+		},
+	}
+
+	cmdExport := CmdBuilder(cmd, RunInventoryExport, "export", "Export an inventory of your account",
+		`Lists the Droplets, volumes, reserved IPs, load balancers, certificates, domains and their records, database clusters, apps, functions namespaces and SSH keys of your account, all at once, and writes them as one JSON document.
+
+Every resource has the same fields: a URN that identifies it, such as `+"`"+`do:droplet:386734086`+"`"+`, its type, ID, name, region, tags and creation time, and attributes specific to its type. Secrets, such as database passwords, are never exported. The document also relates resources to each other: Droplets to their attached volumes, load balancers to their Droplets and certificates, domains to their records, A and AAAA records to the Droplets, load balancers or reserved IPs with their addresses, reserved IPs to their Droplets, and apps to their databases, domains and functions namespaces.
+
+Resources are sorted, so that inventories of an account that hasn't changed are identical. If a type of resource can't be listed, the others are exported anyway, the failure is recorded in the document's `+"`"+`errors`+"`"+`, and the command exits with an error.`,
+		Writer)
+	AddStringFlag(cmdExport, doctl.ArgInventoryOutput, "", "", "The file to write the inventory to. Defaults to standard output")
+	cmdExport.Example = `The following example exports an inventory of your account to ` + "`" + `inventory.json` + "`" + `: doctl inventory export --output inventory.json`
+
+	ignoreTypeDesc := "A type of resource to ignore the changes of, such as domain_record. Repeat the flag to ignore more than one. Types: " + strings.Join(inventory.Types, ", ")
+
+	cmdDiff := cmdBuilderWithInit(cmd, RunInventoryDiff, "diff <old inventory> <new inventory>", "Compare two inventories",
+		`Compares two inventories exported with `+"`"+`doctl inventory export`+"`"+`, and lists the resources that were added, removed or changed, and the relationships that were added or removed. For changed resources, the fields that differ are listed.
+
+Types of resources that could not be listed for either inventory are left out of the comparison, with a warning, so that a failure to list them isn't mistaken for their removal.`,
+		Writer, false, displayerType(&displayers.InventoryChanges{}))
+	AddBoolFlag(cmdDiff, doctl.ArgInventoryExitCode, "", false, "Exit with a non-zero status when the inventories differ")
+	AddStringSliceFlag(cmdDiff, doctl.ArgInventoryIgnoreType, "", nil, ignoreTypeDesc)
+	cmdDiff.Example = `The following example lists the changes to your account since yesterday's inventory: doctl inventory export --output today.json && doctl inventory diff yesterday.json today.json`
+
+	cmdWatch := CmdBuilder(cmd, RunInventoryWatch, "watch", "Alert when resources appear or disappear",
+		`Exports an inventory of your account periodically, and compares it with the previous one to detect resources that appeared or disappeared, such as Droplets created or destroyed in the control panel instead of by your automation.
+
+The first inventory is compared with the one in the `+"`"+`--baseline`+"`"+` file, or, without it, is only taken as the baseline. Each change is printed, and sent to the `+"`"+`--alert`+"`"+` notifiers, once. With `+"`"+`--exit-code`+"`"+`, the command exits with a non-zero status at the first change instead, so that it can be run from scheduled jobs.
+
+Changes to the fields of resources that remain, such as the status of a Droplet, are not alerted of. Use `+"`"+`doctl inventory diff`+"`"+` to list those.`,
+		Writer, displayerType(&displayers.InventoryChanges{}))
+	AddStringFlag(cmdWatch, doctl.ArgInventoryBaseline, "", "", "An inventory exported with doctl inventory export to compare the first inventory with")
+	AddDurationFlag(cmdWatch, doctl.ArgInterval, "", 15*time.Minute, "How often to export an inventory")
+	AddStringSliceFlag(cmdWatch, doctl.ArgInventoryAlert, "", nil,
+		"Send an alert of changes to a `target`: slack://hooks.slack.com/services/... for a Slack incoming webhook, webhook://host/path for a JSON POST to https://host/path, or desktop. Repeat the flag to send more than one")
+	AddBoolFlag(cmdWatch, doctl.ArgInventoryExitCode, "", false, "Exit with a non-zero status when resources appear or disappear, instead of watching on")
+	AddStringSliceFlag(cmdWatch, doctl.ArgInventoryIgnoreType, "", nil, ignoreTypeDesc)
+	cmdWatch.Example = `The following example checks hourly for resources that appear or disappear, and posts them to a Slack channel: doctl inventory watch --interval 1h --alert slack://hooks.slack.com/services/T000/B000/XXXX`
+
+	cmdGraph := cmdBuilderWithInit(cmd, RunInventoryGraph, "graph [<inventory>]", "Draw the relationships between resources",
+		`Writes the relationships between the resources of an inventory as a graph, such as Droplets and their volumes, load balancers and the Droplets they balance, DNS records and the addresses they resolve to, and apps and their databases and functions namespaces. Only resources that are related to others are drawn.
+
+The inventory is read from a file exported with `+"`"+`doctl inventory export`+"`"+`, or, without one, exported from your account.
+
+The graph is written in the DOT language of Graphviz, or with `+"`"+`--format mermaid`+"`"+`, as a Mermaid flowchart, which GitHub and GitLab render in Markdown.`,
+		Writer, false)
+	AddStringFlag(cmdGraph, doctl.ArgInventoryGraphFormat, "", inventory.FormatDOT, "The format of the graph: "+strings.Join(inventory.GraphFormats, " or "))
+	cmdGraph.Example = `The following example draws the relationships of your resources as an SVG image with Graphviz: doctl inventory graph | dot -Tsvg -o inventory.svg`
+
+	return cmd
+}
+
+// inventoryListing is what was listed for an inventory, and the types of
+// resources that could not be listed.
+type inventoryListing struct {
+	droplets      do.Droplets
+	volumes       []do.Volume
+	reservedIPs   do.ReservedIPs
+	loadBalancers do.LoadBalancers
+	certificates  do.Certificates
+	domains       do.Domains
+	records       map[string]do.DomainRecords
+	databases     do.Databases
+	apps          []*godo.App
+	namespaces    []do.OutputNamespace
+	keys          do.SSHKeys
+
+	errors map[string]error
+}
+
+// listInventory lists every type of resource at once.
+func listInventory(c *CmdConfig) *inventoryListing {
+	l := &inventoryListing{records: map[string]do.DomainRecords{}, errors: map[string]error{}}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	fail := func(typ string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := l.errors[typ]; !ok {
+			l.errors[typ] = err
+		}
+	}
+	list := func(typ string, fn func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(); err != nil {
+				fail(typ, err)
+			}
+		}()
+	}
+
+	list(inventory.TypeDroplet, func() (err error) { l.droplets, err = c.Droplets().List(); return })
+	list(inventory.TypeVolume, func() (err error) { l.volumes, err = c.Volumes().List(); return })
+	list(inventory.TypeReservedIP, func() (err error) { l.reservedIPs, err = c.ReservedIPs().List(); return })
+	list(inventory.TypeLoadBalancer, func() (err error) { l.loadBalancers, err = c.LoadBalancers().List(); return })
+	list(inventory.TypeCertificate, func() (err error) { l.certificates, err = c.Certificates().List(); return })
+	list(inventory.TypeDatabase, func() (err error) { l.databases, err = c.Databases().List(); return })
+	list(inventory.TypeApp, func() (err error) { l.apps, err = c.Apps().List(false); return })
+	list(inventory.TypeFunctionsNamespace, func() error {
+		resp, err := c.Serverless().ListNamespaces(context.TODO())
+		l.namespaces = resp.Namespaces
+		return err
+	})
+	list(inventory.TypeSSHKey, func() (err error) { l.keys, err = c.Keys().List(); return })
+	list(inventory.TypeDomain, func() error {
+		domains, err := c.Domains().List()
+		if err != nil {
+			return err
+		}
+		l.domains = domains
+
+		sem := make(chan struct{}, inventoryRecordConcurrency)
+		var rwg sync.WaitGroup
+		for _, d := range domains {
+			rwg.Add(1)
+			sem <- struct{}{}
+			go func(domain string) {
+				defer func() { <-sem; rwg.Done() }()
+				records, err := c.Domains().Records(domain)
+				if err != nil {
+					fail(inventory.TypeDomainRecord, fmt.Errorf("%s: %v", domain, err))
+					return
+				}
+				mu.Lock()
+				l.records[domain] = records
+				mu.Unlock()
+			}(d.Name)
+		}
+		rwg.Wait()
+		return nil
+	})
+
+	wg.Wait()
+	return l
+}
+
+// buildInventory builds the document of what was listed.
+func buildInventory(l *inventoryListing, now time.Time) *inventory.Document {
+	doc := inventory.New(now)
+	// addresses maps IP addresses to the resources that have them, for the
+	// records that resolve to them.
+	addresses := map[string]string{}
+
+	for _, d := range l.droplets {
+		r := inventory.Resource{
+			URN:       d.URN(),
+			Type:      inventory.TypeDroplet,
+			ID:        strconv.Itoa(d.ID),
+			Name:      d.Name,
+			Tags:      d.Tags,
+			CreatedAt: d.Created,
+			Attributes: map[string]any{
+				"size": d.SizeSlug, "status": d.Status, "vcpus": d.Vcpus, "memory": d.Memory, "disk": d.Disk,
+			},
+		}
+		if d.Region != nil {
+			r.Region = d.Region.Slug
+		}
+		if d.Image != nil {
+			image := d.Image.Slug
+			if image == "" {
+				image = d.Image.Name
+			}
+			r.Attributes["image"] = image
+		}
+		if d.VPCUUID != "" {
+			r.Attributes["vpc_uuid"] = d.VPCUUID
+		}
+		for attr, ip := range map[string]func() (string, error){
+			"public_ipv4": d.PublicIPv4, "public_ipv6": d.PublicIPv6, "private_ipv4": d.PrivateIPv4,
+		} {
+			if addr, err := ip(); err == nil && addr != "" {
+				r.Attributes[attr] = addr
+				if attr != "private_ipv4" {
+					addresses[addr] = r.URN
+				}
+			}
+		}
+		doc.Add(r)
+		for _, id := range d.VolumeIDs {
+			doc.Relate(r.URN, inventory.Attaches, godo.ToURN("Volume", id))
+		}
+	}
+
+	for _, v := range l.volumes {
+		r := inventory.Resource{
+			URN:       v.URN(),
+			Type:      inventory.TypeVolume,
+			ID:        v.ID,
+			Name:      v.Name,
+			Tags:      v.Tags,
+			CreatedAt: inventoryTime(v.CreatedAt),
+			Attributes: map[string]any{
+				"size_gigabytes": v.SizeGigaBytes, "filesystem_type": v.FilesystemType,
+			},
+		}
+		if v.Region != nil {
+			r.Region = v.Region.Slug
+		}
+		doc.Add(r)
+		for _, id := range v.DropletIDs {
+			doc.Relate(godo.ToURN("Droplet", id), inventory.Attaches, r.URN)
+		}
+	}
+
+	for _, lb := range l.loadBalancers {
+		r := inventory.Resource{
+			URN:       lb.URN(),
+			Type:      inventory.TypeLoadBalancer,
+			ID:        lb.ID,
+			Name:      lb.Name,
+			Tags:      lb.Tags,
+			CreatedAt: lb.Created,
+			Attributes: map[string]any{
+				"ip": lb.IP, "status": lb.Status,
+			},
+		}
+		if lb.Region != nil {
+			r.Region = lb.Region.Slug
+		}
+		if lb.Tag != "" {
+			r.Attributes["droplet_tag"] = lb.Tag
+		}
+		if lb.IP != "" {
+			addresses[lb.IP] = r.URN
+		}
+		doc.Add(r)
+		for _, id := range lb.DropletIDs {
+			doc.Relate(r.URN, inventory.Balances, godo.ToURN("Droplet", id))
+		}
+		for _, rule := range lb.ForwardingRules {
+			if rule.CertificateID != "" {
+				doc.Relate(r.URN, inventory.Uses, godo.ToURN("Certificate", rule.CertificateID))
+			}
+		}
+	}
+
+	// Reserved IPs come after Droplets and load balancers, so that records
+	// with their addresses resolve to them rather than to their Droplets.
+	for _, ip := range l.reservedIPs {
+		r := inventory.Resource{
+			URN:        ip.URN(),
+			Type:       inventory.TypeReservedIP,
+			ID:         ip.IP,
+			Name:       ip.IP,
+			Attributes: map[string]any{"locked": ip.Locked},
+		}
+		if ip.Region != nil {
+			r.Region = ip.Region.Slug
+		}
+		addresses[ip.IP] = r.URN
+		doc.Add(r)
+		if ip.Droplet != nil {
+			doc.Relate(r.URN, inventory.AssignedTo, ip.Droplet.URN())
+		}
+	}
+
+	for _, cert := range l.certificates {
+		doc.Add(inventory.Resource{
+			URN:       godo.ToURN("Certificate", cert.ID),
+			Type:      inventory.TypeCertificate,
+			ID:        cert.ID,
+			Name:      cert.Name,
+			CreatedAt: cert.Created,
+			Attributes: map[string]any{
+				"type": cert.Type, "state": cert.State, "dns_names": cert.DNSNames, "not_after": cert.NotAfter,
+			},
+		})
+	}
+
+	for _, d := range l.domains {
+		doc.Add(inventory.Resource{
+			URN:        d.URN(),
+			Type:       inventory.TypeDomain,
+			ID:         d.Name,
+			Name:       d.Name,
+			Attributes: map[string]any{"ttl": d.TTL},
+		})
+		for _, rec := range l.records[d.Name] {
+			name := d.Name
+			if rec.Name != "@" {
+				name = rec.Name + "." + d.Name
+			}
+			attrs := map[string]any{"domain": d.Name, "type": rec.Type, "data": rec.Data, "ttl": rec.TTL}
+			switch rec.Type {
+			case "MX":
+				attrs["priority"] = rec.Priority
+			case "SRV":
+				attrs["priority"], attrs["port"], attrs["weight"] = rec.Priority, rec.Port, rec.Weight
+			case "CAA":
+				attrs["flags"], attrs["tag"] = rec.Flags, rec.Tag
+			}
+			r := inventory.Resource{
+				URN:        godo.ToURN("DomainRecord", rec.ID),
+				Type:       inventory.TypeDomainRecord,
+				ID:         strconv.Itoa(rec.ID),
+				Name:       name,
+				Attributes: attrs,
+			}
+			doc.Add(r)
+			doc.Relate(d.URN(), inventory.Contains, r.URN)
+			if target, ok := addresses[rec.Data]; ok && (rec.Type == "A" || rec.Type == "AAAA") {
+				doc.Relate(r.URN, inventory.ResolvesTo, target)
+			}
+		}
+	}
+
+	databases := map[string]string{}
+	for _, db := range l.databases {
+		r := inventory.Resource{
+			URN:       db.URN(),
+			Type:      inventory.TypeDatabase,
+			ID:        db.ID,
+			Name:      db.Name,
+			Region:    db.RegionSlug,
+			Tags:      db.Tags,
+			CreatedAt: inventoryTime(db.CreatedAt),
+			Attributes: map[string]any{
+				"engine": db.EngineSlug, "version": db.VersionSlug, "size": db.SizeSlug,
+				"num_nodes": db.NumNodes, "status": db.Status,
+			},
+		}
+		if db.PrivateNetworkUUID != "" {
+			r.Attributes["vpc_uuid"] = db.PrivateNetworkUUID
+		}
+		databases[db.Name] = r.URN
+		doc.Add(r)
+	}
+
+	for _, app := range l.apps {
+		r := inventory.Resource{
+			URN:        app.URN(),
+			Type:       inventory.TypeApp,
+			ID:         app.ID,
+			CreatedAt:  inventoryTime(app.CreatedAt),
+			Attributes: map[string]any{},
+		}
+		if app.Spec != nil {
+			r.Name = app.Spec.Name
+		}
+		if app.Region != nil {
+			r.Region = app.Region.Slug
+		}
+		if app.LiveURL != "" {
+			r.Attributes["live_url"] = app.LiveURL
+		}
+		if app.TierSlug != "" {
+			r.Attributes["tier"] = app.TierSlug
+		}
+		doc.Add(r)
+		if app.Spec == nil {
+			continue
+		}
+		for _, db := range app.Spec.Databases {
+			if urn, ok := databases[db.ClusterName]; ok && db.ClusterName != "" {
+				doc.Relate(r.URN, inventory.Uses, urn)
+			}
+		}
+		for _, d := range app.Spec.Domains {
+			if zone := appDomainZone(d, l.domains); zone != "" {
+				doc.Relate(r.URN, inventory.Serves, godo.ToURN("Domain", zone))
+			}
+		}
+		// The namespaces of apps' functions aren't listed with the others,
+		// so they are added here.
+		if app.ActiveDeployment == nil {
+			continue
+		}
+		for _, fn := range app.ActiveDeployment.Functions {
+			if fn.Namespace == "" {
+				continue
+			}
+			urn := godo.ToURN("FunctionsNamespace", fn.Namespace)
+			doc.Add(inventory.Resource{
+				URN:        urn,
+				Type:       inventory.TypeFunctionsNamespace,
+				ID:         fn.Namespace,
+				Name:       fn.Namespace,
+				Region:     r.Region,
+				Attributes: map[string]any{"app_id": app.ID},
+			})
+			doc.Relate(r.URN, inventory.Uses, urn)
+		}
+	}
+
+	for _, ns := range l.namespaces {
+		urn := godo.ToURN("FunctionsNamespace", ns.Namespace)
+		if _, ok := doc.Resource(urn); ok {
+			continue
+		}
+		// The key of a namespace is a secret, so it is left out.
+		doc.Add(inventory.Resource{
+			URN:        urn,
+			Type:       inventory.TypeFunctionsNamespace,
+			ID:         ns.Namespace,
+			Name:       ns.Label,
+			Region:     ns.Region,
+			Attributes: map[string]any{"api_host": ns.APIHost},
+		})
+	}
+
+	for _, k := range l.keys {
+		doc.Add(inventory.Resource{
+			URN:        godo.ToURN("SSHKey", k.ID),
+			Type:       inventory.TypeSSHKey,
+			ID:         strconv.Itoa(k.ID),
+			Name:       k.Name,
+			Attributes: map[string]any{"fingerprint": k.Fingerprint},
+		})
+	}
+
+	for _, typ := range inventory.Types {
+		if err, ok := l.errors[typ]; ok {
+			doc.Errors = append(doc.Errors, inventory.Error{Type: typ, Error: err.Error()})
+		}
+	}
+	doc.Normalize()
+	return doc
+}
+
+// appDomainZone returns the domain of the account that an app's domain is
+// in, or "" if it isn't in one.
+func appDomainZone(d *godo.AppDomainSpec, domains do.Domains) string {
+	if d.Zone != "" {
+		return d.Zone
+	}
+	zone := ""
+	for _, domain := range domains {
+		if (d.Domain == domain.Name || strings.HasSuffix(d.Domain, "."+domain.Name)) && len(domain.Name) > len(zone) {
+			zone = domain.Name
+		}
+	}
+	return zone
+}
+
+// inventoryTime formats the creation time of a resource, or returns "" if
+// it isn't known.
+func inventoryTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// RunInventoryExport exports an inventory of the account.
+func RunInventoryExport(c *CmdConfig) error {
+	output, err := c.Doit.GetString(c.NS, doctl.ArgInventoryOutput)
+	if err != nil {
+		return err
+	}
+
+	doc := buildInventory(listInventory(c), time.Now())
+
+	if output == "" {
+		if err := doc.Write(c.Out); err != nil {
+			return err
+		}
+	} else {
+		if err := writeInventoryFile(output, doc); err != nil {
+			return err
+		}
+		notice("Wrote the inventory of %d resources to %s", len(doc.Resources), output)
+	}
+
+	if len(doc.Errors) > 0 {
+		for _, e := range doc.Errors {
+			warn("Could not list the resources of type %s: %s", e.Type, e.Error)
+		}
+		return fmt.Errorf("the inventory is incomplete: %d types of resources could not be listed", len(doc.Errors))
+	}
+	return nil
+}
+
+// writeInventoryFile writes a document to path, replacing it only once it
+// is complete. The file is only readable by the user, since it describes
+// the whole account.
+func writeInventoryFile(path string, doc *inventory.Document) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := doc.Write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// inventoryAlertMaxNames is how many resources that appeared, and how many
+// that disappeared, an alert names. The others are counted.
+const inventoryAlertMaxNames = 20
+
+// readInventoryFile reads the inventory at path.
+func readInventoryFile(path string) (*inventory.Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	doc, err := inventory.Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return doc, nil
+}
+
+// inventoryIgnoredTypes returns the types of resources set with
+// --ignore-type.
+func inventoryIgnoredTypes(c *CmdConfig) (map[string]bool, error) {
+	types, err := c.Doit.GetStringSlice(c.NS, doctl.ArgInventoryIgnoreType)
+	if err != nil {
+		return nil, err
+	}
+	ignored := map[string]bool{}
+	for _, t := range types {
+		if !slices.Contains(inventory.Types, t) {
+			return nil, fmt.Errorf("unknown type of resource %q; it must be one of %s", t, strings.Join(inventory.Types, ", "))
+		}
+		ignored[t] = true
+	}
+	return ignored, nil
+}
+
+// inventoryChanges lists a diff for display, leaving out ignored types of
+// resources, and relationships to them. Unless all is set, only added and
+// removed resources are listed.
+func inventoryChanges(d *inventory.Diff, ignored map[string]bool, all bool) []displayers.InventoryChange {
+	changes := []displayers.InventoryChange{}
+	resource := func(change string, r inventory.Resource) {
+		if !ignored[r.Type] {
+			changes = append(changes, displayers.InventoryChange{Change: change, Type: r.Type, URN: r.URN, Name: r.Name})
+		}
+	}
+	for _, r := range d.Removed {
+		resource("removed", r)
+	}
+	for _, r := range d.Added {
+		resource("added", r)
+	}
+	if !all {
+		return changes
+	}
+	for _, ch := range d.Changed {
+		if !ignored[ch.New.Type] {
+			changes = append(changes, displayers.InventoryChange{
+				Change: "changed", Type: ch.New.Type, URN: ch.New.URN, Name: ch.New.Name, Fields: ch.Fields,
+			})
+		}
+	}
+	relationship := func(change string, r inventory.Relationship) {
+		if ignored[inventoryURNType(r.From)] || ignored[inventoryURNType(r.To)] {
+			return
+		}
+		changes = append(changes, displayers.InventoryChange{
+			Change: change, Type: "relationship", From: r.From, Relationship: r.Type, To: r.To,
+		})
+	}
+	for _, r := range d.RemovedRelationships {
+		relationship("removed", r)
+	}
+	for _, r := range d.AddedRelationships {
+		relationship("added", r)
+	}
+	return changes
+}
+
+// inventoryURNTypes maps the types in URNs to inventory types.
+var inventoryURNTypes = map[string]string{
+	"droplet":            inventory.TypeDroplet,
+	"volume":             inventory.TypeVolume,
+	"domain":             inventory.TypeDomain,
+	"domainrecord":       inventory.TypeDomainRecord,
+	"app":                inventory.TypeApp,
+	"functionsnamespace": inventory.TypeFunctionsNamespace,
+	"dbaas":              inventory.TypeDatabase,
+	"loadbalancer":       inventory.TypeLoadBalancer,
+	"certificate":        inventory.TypeCertificate,
+	"sshkey":             inventory.TypeSSHKey,
+	"reservedip":         inventory.TypeReservedIP,
+}
+
+// inventoryURNType returns the inventory type of the resource a URN names,
+// or "" if it isn't known.
+func inventoryURNType(urn string) string {
+	parts := strings.SplitN(urn, ":", 3)
+	if len(parts) != 3 {
+		return ""
+	}
+	return inventoryURNTypes[parts[1]]
+}
+
+// warnInventorySkipped warns of the types of resources a diff left out.
+func warnInventorySkipped(d *inventory.Diff) {
+	for _, t := range d.Skipped {
+		warn("The resources of type %s are left out of the comparison, since they could not be listed", t)
+	}
+}
+
+// RunInventoryDiff compares two inventories.
+func RunInventoryDiff(c *CmdConfig) error {
+	if len(c.Args) < 2 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	if len(c.Args) > 2 {
+		return doctl.NewTooManyArgsErr(c.NS)
+	}
+	exitCode, err := c.Doit.GetBool(c.NS, doctl.ArgInventoryExitCode)
+	if err != nil {
+		return err
+	}
+	ignored, err := inventoryIgnoredTypes(c)
+	if err != nil {
+		return err
+	}
+	old, err := readInventoryFile(c.Args[0])
+	if err != nil {
+		return err
+	}
+	new, err := readInventoryFile(c.Args[1])
+	if err != nil {
+		return err
+	}
+
+	diff := inventory.Compare(old, new)
+	warnInventorySkipped(diff)
+	changes := inventoryChanges(diff, ignored, true)
+	if err := c.Display(&displayers.InventoryChanges{Changes: changes}); err != nil {
+		return err
+	}
+	if exitCode && len(changes) > 0 {
+		warn("The inventories differ by %d changes", len(changes))
+		return ErrExitSilently
+	}
+	return nil
+}
+
+// RunInventoryGraph writes the relationships of an inventory as a graph.
+func RunInventoryGraph(c *CmdConfig) error {
+	if len(c.Args) > 1 {
+		return doctl.NewTooManyArgsErr(c.NS)
+	}
+	format, err := c.Doit.GetString(c.NS, doctl.ArgInventoryGraphFormat)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(inventory.GraphFormats, format) {
+		return fmt.Errorf("unknown graph format %q; it must be one of %s", format, strings.Join(inventory.GraphFormats, ", "))
+	}
+
+	var doc *inventory.Document
+	if len(c.Args) == 1 {
+		if doc, err = readInventoryFile(c.Args[0]); err != nil {
+			return err
+		}
+	} else {
+		if err := c.initServices(c); err != nil {
+			return err
+		}
+		doc = buildInventory(listInventory(c), time.Now())
+	}
+	for _, e := range doc.Errors {
+		warn("The resources of type %s could not be listed, so they are missing from the graph: %s", e.Type, e.Error)
+	}
+	return doc.WriteGraph(c.Out, format)
+}
+
+// RunInventoryWatch exports inventories periodically, and alerts of the
+// resources that appear or disappear between them.
+func RunInventoryWatch(c *CmdConfig) error {
+	baselinePath, err := c.Doit.GetString(c.NS, doctl.ArgInventoryBaseline)
+	if err != nil {
+		return err
+	}
+	interval, err := c.Doit.GetDuration(c.NS, doctl.ArgInterval)
+	if err != nil {
+		return err
+	}
+	specs, err := c.Doit.GetStringSlice(c.NS, doctl.ArgInventoryAlert)
+	if err != nil {
+		return err
+	}
+	exitCode, err := c.Doit.GetBool(c.NS, doctl.ArgInventoryExitCode)
+	if err != nil {
+		return err
+	}
+	ignored, err := inventoryIgnoredTypes(c)
+	if err != nil {
+		return err
+	}
+	var notifiers notify.Multi
+	for _, spec := range specs {
+		n, err := notify.Parse(spec)
+		if err != nil {
+			return err
+		}
+		notifiers = append(notifiers, n)
+	}
+
+	var baseline *inventory.Document
+	if baselinePath != "" {
+		if baseline, err = readInventoryFile(baselinePath); err != nil {
+			return err
+		}
+	}
+
+	for {
+		started := time.Now()
+		doc := buildInventory(listInventory(c), started)
+		for _, e := range doc.Errors {
+			warn("Could not list the resources of type %s: %s", e.Type, e.Error)
+		}
+
+		if baseline == nil {
+			notice("Took the baseline inventory of %d resources", len(doc.Resources))
+		} else {
+			diff := inventory.Compare(baseline, doc)
+			changes := inventoryChanges(diff, ignored, false)
+			if len(changes) > 0 {
+				if err := c.Display(&displayers.InventoryChanges{Changes: changes}); err != nil {
+					return err
+				}
+				if len(notifiers) > 0 {
+					alertInventoryChanges(notifiers, started, changes)
+				}
+				if exitCode {
+					warn("%d resources appeared or disappeared", len(changes))
+					return ErrExitSilently
+				}
+			}
+			doc.Fill(baseline)
+		}
+		baseline = doc
+
+		time.Sleep(interval)
+	}
+}
+
+// alertInventoryChanges sends an alert of changes. Failures to send it are
+// reported, and watching goes on.
+func alertInventoryChanges(notifiers notify.Multi, started time.Time, changes []displayers.InventoryChange) {
+	var appeared, disappeared []string
+	for _, ch := range changes {
+		name := ch.URN
+		if ch.Name != "" {
+			name += " (" + ch.Name + ")"
+		}
+		if ch.Change == "removed" {
+			disappeared = append(disappeared, name)
+		} else {
+			appeared = append(appeared, name)
+		}
+	}
+	names := func(urns []string) string {
+		if len(urns) > inventoryAlertMaxNames {
+			return strings.Join(urns[:inventoryAlertMaxNames], ", ") + fmt.Sprintf(" and %d more", len(urns)-inventoryAlertMaxNames)
+		}
+		return strings.Join(urns, ", ")
+	}
+	var details []notify.Field
+	if len(appeared) > 0 {
+		details = append(details, notify.Field{Name: "Appeared", Value: names(appeared)})
+	}
+	if len(disappeared) > 0 {
+		details = append(details, notify.Field{Name: "Disappeared", Value: names(disappeared)})
+	}
+
+	e := notify.Event{
+		Command:  "doctl inventory watch",
+		Started:  started,
+		Duration: time.Since(started),
+		Err:      fmt.Errorf("%d resources appeared and %d disappeared", len(appeared), len(disappeared)),
+		Details:  details,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	if err := notifiers.Notify(ctx, e); err != nil {
+		fmt.Fprintf(notifyOut, "Warning: %v\n", err)
+	}
+}
@@ -0,0 +1,237 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/doctl/pkg/inventory"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func expectInventory(tm *tcMocks, appsErr error) {
+	tm.droplets.EXPECT().List().Return(do.Droplets{{Droplet: &godo.Droplet{
+		ID: 1, Name: "web", Region: &godo.Region{Slug: "nyc3"}, VolumeIDs: []string{"vol-1"},
+		Networks: &godo.Networks{V4: []godo.NetworkV4{{IPAddress: "192.0.2.1", Type: "public"}}},
+	}}}, nil)
+	tm.volumes.EXPECT().List().Return([]do.Volume{{Volume: &godo.Volume{ID: "vol-1", Name: "data", DropletIDs: []int{1}}}}, nil)
+	tm.reservedIPs.EXPECT().List().Return(do.ReservedIPs{{ReservedIP: &godo.ReservedIP{
+		IP: "192.0.2.9", Droplet: &godo.Droplet{ID: 1},
+	}}}, nil)
+	tm.loadBalancers.EXPECT().List().Return(do.LoadBalancers{{LoadBalancer: &godo.LoadBalancer{
+		ID: "lb-1", Name: "front", IP: "192.0.2.5", DropletIDs: []int{1},
+		ForwardingRules: []godo.ForwardingRule{{EntryProtocol: "https", CertificateID: "cert-1"}},
+	}}}, nil)
+	tm.certificates.EXPECT().List().Return(do.Certificates{{Certificate: &godo.Certificate{ID: "cert-1", Name: "example"}}}, nil)
+	tm.domains.EXPECT().List().Return(do.Domains{{Domain: &godo.Domain{Name: "example.com"}}}, nil)
+	tm.domains.EXPECT().Records("example.com").Return(do.DomainRecords{
+		{DomainRecord: &godo.DomainRecord{ID: 11, Type: "A", Name: "@", Data: "192.0.2.5"}},
+		{DomainRecord: &godo.DomainRecord{ID: 12, Type: "A", Name: "www", Data: "192.0.2.9"}},
+		{DomainRecord: &godo.DomainRecord{ID: 13, Type: "A", Name: "old", Data: "198.51.100.1"}},
+	}, nil)
+	tm.databases.EXPECT().List().Return(do.Databases{{Database: &godo.Database{
+		ID: "db-1", Name: "main", EngineSlug: "pg",
+		Connection: &godo.DatabaseConnection{Password: "secret"},
+	}}}, nil)
+	if appsErr != nil {
+		tm.apps.EXPECT().List(false).Return(nil, appsErr)
+	} else {
+		tm.apps.EXPECT().List(false).Return([]*godo.App{{ID: "app-1", Spec: &godo.AppSpec{
+			Name:      "site",
+			Databases: []*godo.AppDatabaseSpec{{Name: "db", ClusterName: "main"}},
+			Domains:   []*godo.AppDomainSpec{{Domain: "app.example.com"}},
+		}, ActiveDeployment: &godo.Deployment{Functions: []*godo.DeploymentFunctions{{Name: "api", Namespace: "ap-1234"}}}}}, nil)
+	}
+	tm.serverless.EXPECT().ListNamespaces(gomock.Any()).Return(do.NamespaceListResponse{Namespaces: []do.OutputNamespace{
+		{Namespace: "fn-5678", Label: "jobs", Region: "nyc1", Key: "secret"},
+	}}, nil)
+	tm.keys.EXPECT().List().Return(do.SSHKeys{{Key: &godo.Key{ID: 7, Name: "laptop"}}}, nil)
+}
+
+func TestInventoryExport(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		expectInventory(tm, nil)
+		var buf bytes.Buffer
+		config.Out = &buf
+
+		require.NoError(t, RunInventoryExport(config))
+		assert.NotContains(t, buf.String(), "secret")
+		doc, err := inventory.Read(&buf)
+		require.NoError(t, err)
+
+		assert.Len(t, doc.Resources, 14)
+		app, ok := doc.Resource("do:app:app-1")
+		require.True(t, ok)
+		assert.Equal(t, "site", app.Name)
+		record, ok := doc.Resource("do:domainrecord:12")
+		require.True(t, ok)
+		assert.Equal(t, "www.example.com", record.Name)
+
+		assert.Equal(t, []inventory.Relationship{
+			{From: "do:app:app-1", Type: inventory.Serves, To: "do:domain:example.com"},
+			{From: "do:app:app-1", Type: inventory.Uses, To: "do:dbaas:db-1"},
+			{From: "do:app:app-1", Type: inventory.Uses, To: "do:functionsnamespace:ap-1234"},
+			{From: "do:domain:example.com", Type: inventory.Contains, To: "do:domainrecord:11"},
+			{From: "do:domain:example.com", Type: inventory.Contains, To: "do:domainrecord:12"},
+			{From: "do:domain:example.com", Type: inventory.Contains, To: "do:domainrecord:13"},
+			{From: "do:domainrecord:11", Type: inventory.ResolvesTo, To: "do:loadbalancer:lb-1"},
+			{From: "do:domainrecord:12", Type: inventory.ResolvesTo, To: "do:reservedip:192.0.2.9"},
+			{From: "do:droplet:1", Type: inventory.Attaches, To: "do:volume:vol-1"},
+			{From: "do:loadbalancer:lb-1", Type: inventory.Balances, To: "do:droplet:1"},
+			{From: "do:loadbalancer:lb-1", Type: inventory.Uses, To: "do:certificate:cert-1"},
+			{From: "do:reservedip:192.0.2.9", Type: inventory.AssignedTo, To: "do:droplet:1"},
+		}, doc.Relationships)
+	})
+}
+
+func TestInventoryExportIncomplete(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		expectInventory(tm, errors.New("forbidden"))
+		path := filepath.Join(t.TempDir(), "inventory.json")
+		config.Doit.Set(config.NS, doctl.ArgInventoryOutput, path)
+
+		err := RunInventoryExport(config)
+		assert.ErrorContains(t, err, "the inventory is incomplete")
+
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		doc, err := inventory.Read(f)
+		require.NoError(t, err)
+		assert.Equal(t, []inventory.Error{{Type: inventory.TypeApp, Error: "forbidden"}}, doc.Errors)
+		assert.Len(t, doc.Resources, 12)
+
+		info, err := f.Stat()
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+}
+
+func writeTestInventory(t *testing.T, doc *inventory.Document) string {
+	path := filepath.Join(t.TempDir(), "inventory.json")
+	require.NoError(t, writeInventoryFile(path, doc))
+	return path
+}
+
+func TestInventoryDiff(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		old := inventory.New(time.Now())
+		old.Add(
+			inventory.Resource{URN: "do:droplet:1", Type: inventory.TypeDroplet, Name: "web", Attributes: map[string]any{"size": "s-1vcpu-1gb"}},
+			inventory.Resource{URN: "do:droplet:2", Type: inventory.TypeDroplet, Name: "old"},
+			inventory.Resource{URN: "do:sshkey:7", Type: inventory.TypeSSHKey},
+		)
+		new := inventory.New(time.Now())
+		new.Add(
+			inventory.Resource{URN: "do:droplet:1", Type: inventory.TypeDroplet, Name: "web", Attributes: map[string]any{"size": "s-2vcpu-2gb"}},
+			inventory.Resource{URN: "do:volume:a", Type: inventory.TypeVolume, Name: "data"},
+		)
+		new.Relate("do:droplet:1", inventory.Attaches, "do:volume:a")
+
+		buf := cronJSON(t, config)
+		config.Args = []string{writeTestInventory(t, old), writeTestInventory(t, new)}
+		config.Doit.Set(config.NS, doctl.ArgInventoryIgnoreType, []string{inventory.TypeSSHKey})
+		require.NoError(t, RunInventoryDiff(config))
+
+		var out []displayers.InventoryChange
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		assert.Equal(t, []displayers.InventoryChange{
+			{Change: "removed", Type: inventory.TypeDroplet, URN: "do:droplet:2", Name: "old"},
+			{Change: "added", Type: inventory.TypeVolume, URN: "do:volume:a", Name: "data"},
+			{Change: "changed", Type: inventory.TypeDroplet, URN: "do:droplet:1", Name: "web", Fields: []string{"attributes.size"}},
+			{Change: "added", Type: "relationship", From: "do:droplet:1", Relationship: inventory.Attaches, To: "do:volume:a"},
+		}, out)
+
+		config.Doit.Set(config.NS, doctl.ArgInventoryExitCode, true)
+		assert.Equal(t, ErrExitSilently, RunInventoryDiff(config))
+		config.Args = []string{config.Args[0], config.Args[0]}
+		assert.NoError(t, RunInventoryDiff(config))
+
+		config.Doit.Set(config.NS, doctl.ArgInventoryIgnoreType, []string{"droplets"})
+		assert.ErrorContains(t, RunInventoryDiff(config), `unknown type of resource "droplets"`)
+	})
+}
+
+func TestInventoryWatch(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var alert map[string]any
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		}))
+		defer srv.Close()
+
+		// The baseline has a Droplet that is gone, and lacks the other
+		// resources.
+		baseline := inventory.New(time.Now())
+		baseline.Add(
+			inventory.Resource{URN: "do:droplet:1", Type: inventory.TypeDroplet, Name: "web"},
+			inventory.Resource{URN: "do:droplet:9", Type: inventory.TypeDroplet, Name: "manual"},
+		)
+		expectInventory(tm, nil)
+
+		buf := cronJSON(t, config)
+		config.Doit.Set(config.NS, doctl.ArgInventoryBaseline, writeTestInventory(t, baseline))
+		config.Doit.Set(config.NS, doctl.ArgInterval, time.Duration(0))
+		config.Doit.Set(config.NS, doctl.ArgInventoryExitCode, true)
+		config.Doit.Set(config.NS, doctl.ArgInventoryIgnoreType, []string{inventory.TypeDomainRecord})
+		config.Doit.Set(config.NS, doctl.ArgInventoryAlert, []string{"webhook+http://" + strings.TrimPrefix(srv.URL, "http://") + "/hook"})
+		assert.Equal(t, ErrExitSilently, RunInventoryWatch(config))
+
+		var out []displayers.InventoryChange
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		require.Len(t, out, 11)
+		assert.Equal(t, displayers.InventoryChange{Change: "removed", Type: inventory.TypeDroplet, URN: "do:droplet:9", Name: "manual"}, out[0])
+		for _, ch := range out[1:] {
+			assert.Equal(t, "added", ch.Change)
+			assert.NotEqual(t, inventory.TypeDomainRecord, ch.Type)
+		}
+
+		assert.Equal(t, "doctl inventory watch", alert["command"])
+		assert.Equal(t, "10 resources appeared and 1 disappeared", alert["error"])
+		details := alert["details"].(map[string]any)
+		assert.Equal(t, "do:droplet:9 (manual)", details["Disappeared"])
+		assert.Contains(t, details["Appeared"], "do:volume:vol-1 (data), do:reservedip:192.0.2.9 (192.0.2.9)")
+	})
+}
+
+func TestInventoryGraph(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		expectInventory(tm, nil)
+		var buf bytes.Buffer
+		config.Out = &buf
+		config.Doit.Set(config.NS, doctl.ArgInventoryGraphFormat, inventory.FormatMermaid)
+		require.NoError(t, RunInventoryGraph(config))
+		assert.Contains(t, buf.String(), `["site<br/>app"]`)
+		assert.Contains(t, buf.String(), "-->|uses|")
+		// Unrelated resources, such as SSH keys, are left out.
+		assert.NotContains(t, buf.String(), "laptop")
+
+		doc := inventory.New(time.Now())
+		doc.Add(
+			inventory.Resource{URN: "do:droplet:1", Type: inventory.TypeDroplet, Name: "web"},
+			inventory.Resource{URN: "do:volume:a", Type: inventory.TypeVolume, Name: "data"},
+		)
+		doc.Relate("do:droplet:1", inventory.Attaches, "do:volume:a")
+		buf.Reset()
+		config.Args = []string{writeTestInventory(t, doc)}
+		config.Doit.Set(config.NS, doctl.ArgInventoryGraphFormat, inventory.FormatDOT)
+		require.NoError(t, RunInventoryGraph(config))
+		assert.Contains(t, buf.String(), `"do:droplet:1" -> "do:volume:a" [label="attaches"];`)
+
+		config.Doit.Set(config.NS, doctl.ArgInventoryGraphFormat, "svg")
+		assert.ErrorContains(t, RunInventoryGraph(config), `unknown graph format "svg"`)
+	})
+}
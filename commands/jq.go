@@ -0,0 +1,181 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/digitalocean/doctl"
+	"github.com/itchyny/gojq"
+	"github.com/spf13/viper"
+)
+
+// jqOutput returns the writer a command should write its output to. If --jq
+// is set, output is switched to JSON, unless it is NDJSON, and filtered
+// through the expression before it is written to out. The returned func must
+// be called once the command is done, and reports any error filtering the
+// output.
+func jqOutput(out io.Writer) (io.Writer, func() error, error) {
+	expr := viper.GetString(doctl.ArgJQ)
+	if expr == "" {
+		return out, func() error { return nil }, nil
+	}
+
+	code, err := compileJQ(expr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if viper.GetString(doctl.ArgOutput) != "ndjson" {
+		viper.Set(doctl.ArgOutput, "json")
+	}
+
+	w := newJQWriter(out, code)
+	return w, w.Close, nil
+}
+
+func compileJQ(expr string) (*gojq.Code, error) {
+	q, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s expression: %w", doctl.ArgJQ, err)
+	}
+	code, err := gojq.Compile(q)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s expression: %w", doctl.ArgJQ, err)
+	}
+	return code, nil
+}
+
+// jqWriter filters the stream of JSON values written to it. Each value is
+// filtered as soon as it is complete, so commands that keep writing output,
+// such as those with --follow, are filtered as they go.
+type jqWriter struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+func newJQWriter(out io.Writer, code *gojq.Code) *jqWriter {
+	pr, pw := io.Pipe()
+	w := &jqWriter{pw: pw, done: make(chan error, 1)}
+
+	go func() {
+		err := runJQ(pr, out, code)
+		// Fail the command's writes rather than leaving them blocked.
+		pr.CloseWithError(err)
+		w.done <- err
+	}()
+	return w
+}
+
+func (w *jqWriter) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+// Close waits for the output written so far to be filtered.
+func (w *jqWriter) Close() error {
+	w.pw.Close()
+	return <-w.done
+}
+
+func runJQ(in io.Reader, out io.Writer, code *gojq.Code) error {
+	dec := json.NewDecoder(in)
+	dec.UseNumber()
+	for {
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("--%s can only be used with commands that output JSON: %w", doctl.ArgJQ, err)
+		}
+
+		iter := code.Run(v)
+		for {
+			r, ok := iter.Next()
+			if !ok {
+				break
+			}
+			if err, ok := r.(error); ok {
+				return fmt.Errorf("--%s: %w", doctl.ArgJQ, err)
+			}
+			if err := writeJQResult(out, r); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeJQResult writes strings as they are, like jq -r, so they can be used
+// in scripts, and anything else as indented JSON.
+func writeJQResult(out io.Writer, v any) error {
+	if s, ok := v.(string); ok {
+		_, err := fmt.Fprintln(out, s)
+		return err
+	}
+
+	b, err := gojq.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err = buf.WriteTo(out)
+	return err
+}
+
+// jsonLinesWriter writes each line written to it as a JSON string, so that
+// text output, such as logs, can be filtered with --jq.
+type jsonLinesWriter struct {
+	out  io.Writer
+	line []byte
+}
+
+func (w *jsonLinesWriter) Write(p []byte) (int, error) {
+	w.line = append(w.line, p...)
+	for {
+		i := bytes.IndexByte(w.line, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if err := w.writeLine(w.line[:i]); err != nil {
+			return 0, err
+		}
+		w.line = w.line[i+1:]
+	}
+}
+
+// Flush writes the last line, if it wasn't terminated by a newline.
+func (w *jsonLinesWriter) Flush() error {
+	if len(w.line) == 0 {
+		return nil
+	}
+	err := w.writeLine(w.line)
+	w.line = nil
+	return err
+}
+
+func (w *jsonLinesWriter) writeLine(line []byte) error {
+	b, err := json.Marshal(string(bytes.TrimSuffix(line, []byte("\r"))))
+	if err != nil {
+		return err
+	}
+	_, err = w.out.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withJQ sets --jq to expr while f runs.
+func withJQ(t *testing.T, expr string, f func()) {
+	t.Helper()
+	prevJQ, prevOutput := viper.GetString(doctl.ArgJQ), viper.GetString(doctl.ArgOutput)
+	viper.Set(doctl.ArgJQ, expr)
+	defer func() {
+		viper.Set(doctl.ArgJQ, prevJQ)
+		viper.Set(doctl.ArgOutput, prevOutput)
+	}()
+	f()
+}
+
+func TestJQOutput(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		withJQ(t, ".[].networks.v4[0].ip_address", func() {
+			var out bytes.Buffer
+			w, closeOutput, err := jqOutput(&out)
+			require.NoError(t, err)
+			assert.Equal(t, "json", viper.GetString(doctl.ArgOutput))
+
+			config.Out = w
+			require.NoError(t, config.Display(&displayers.Droplet{Droplets: testDropletList}))
+			require.NoError(t, closeOutput())
+			assert.Equal(t, "8.8.8.8\n8.8.8.9\n", out.String())
+		})
+	})
+}
+
+func TestJQOutputValues(t *testing.T) {
+	withJQ(t, "{id: .id, big: .big}", func() {
+		var out bytes.Buffer
+		w, closeOutput, err := jqOutput(&out)
+		require.NoError(t, err)
+
+		fmt.Fprint(w, `{"id": 1, "big": 12345678901234567890}`)
+		require.NoError(t, closeOutput())
+		assert.Equal(t, "{\n  \"big\": 12345678901234567890,\n  \"id\": 1\n}\n", out.String())
+	})
+}
+
+func TestJQOutputStream(t *testing.T) {
+	withJQ(t, `select(startswith("web"))`, func() {
+		var out bytes.Buffer
+		w, closeOutput, err := jqOutput(&out)
+		require.NoError(t, err)
+
+		lw := &jsonLinesWriter{out: w}
+		fmt.Fprint(lw, "web started\nworker started\nweb ")
+		fmt.Fprint(lw, "listening\r\nweb stopping")
+		require.NoError(t, lw.Flush())
+		require.NoError(t, closeOutput())
+		assert.Equal(t, "web started\nweb listening\nweb stopping\n", out.String())
+	})
+}
+
+func TestJQOutputErrors(t *testing.T) {
+	withJQ(t, ".[", func() {
+		_, _, err := jqOutput(&bytes.Buffer{})
+		assert.ErrorContains(t, err, "invalid --jq expression")
+	})
+
+	withJQ(t, ".name", func() {
+		w, closeOutput, err := jqOutput(&bytes.Buffer{})
+		require.NoError(t, err)
+		fmt.Fprint(w, "Notification sent\n")
+		assert.ErrorContains(t, closeOutput(), "--jq can only be used with commands that output JSON")
+	})
+
+	withJQ(t, ".name", func() {
+		w, closeOutput, err := jqOutput(&bytes.Buffer{})
+		require.NoError(t, err)
+		fmt.Fprint(w, `[1]`)
+		_, err = fmt.Fprint(w, `[2]`)
+		assert.Error(t, err)
+		assert.EqualError(t, closeOutput(), "--jq: expected an object but got: array ([1])")
+	})
+}
+
+func TestJQOutputUnset(t *testing.T) {
+	withJQ(t, "", func() {
+		var out bytes.Buffer
+		w, closeOutput, err := jqOutput(&out)
+		require.NoError(t, err)
+		assert.Same(t, &out, w)
+		assert.NoError(t, closeOutput())
+	})
+}
@@ -18,6 +18,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
@@ -33,6 +35,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -200,6 +203,7 @@ func (p *kubeconfigProvider) ConfigPath() string {
 // KubernetesCommandService is used to execute Kubernetes commands.
 type KubernetesCommandService struct {
 	KubeconfigProvider KubeconfigProvider
+	NodeDrainer        func(kubeconfig *clientcmdapi.Config) (NodeDrainer, error)
 }
 
 func kubernetesCommandService() *KubernetesCommandService {
@@ -207,6 +211,7 @@ func kubernetesCommandService() *KubernetesCommandService {
 		KubeconfigProvider: &kubeconfigProvider{
 			pathOptions: clientcmd.NewDefaultPathOptions(),
 		},
+		NodeDrainer: newAPINodeDrainer,
 	}
 }
 
@@ -382,6 +387,14 @@ Retrieves the following details for a Kubernetes cluster:
 		Writer, aliasOpt("ar"), displayerType(&displayers.KubernetesAssociatedResources{}))
 	cmdKubeClusterListAssociatedResources.Example = `The following example retrieves the associated resources for a cluster named ` + "`" + `example-cluster` + "`" + ` and uses the ` + "`" + `--format` + "`" + ` flag to return only the associated volumes: doctl kubernetes cluster list-associated-resources example-cluster --format Volumes`
 
+	cmdKubeClusterCost := CmdBuilder(cmd, k8sCmdService.RunKubernetesClusterCost, "cost <id|name>", "Estimate the monthly cost of a Kubernetes cluster by namespace", `Estimates the monthly cost of the nodes of a Kubernetes cluster, using the prices of their Droplet sizes, and attributes it to namespaces by the CPU and memory their pods request. Each pod is charged the average of the fractions of its node's allocatable CPU and memory that it requests. The cost of the capacity that no pod requests is reported as `+"`"+`(idle)`+"`"+`.
+
+The command reads the cluster's nodes and pods from its Kubernetes API. If the cluster serves the resource metrics API, for example through metrics-server, the CPU and memory that each namespace actually uses is shown as well.
+
+The estimate covers the cluster's nodes only, not its control plane, load balancers, or volumes.`, Writer,
+		aliasOpt("costs"), displayerType(&displayers.KubernetesCosts{}))
+	cmdKubeClusterCost.Example = `The following example estimates the costs of a cluster named ` + "`" + `example-cluster` + "`" + `: doctl kubernetes cluster cost example-cluster`
+
 	return cmd
 }
 
@@ -515,11 +528,30 @@ Creates a new node pool for the specified cluster. The command requires values f
 		"The maximum number of nodes in the node pool when autoscaling is enabled")
 	cmdKubeNodePoolUpdate.Example = `The following example updates a node pool named ` + "`" + `example-pool` + "`" + ` in a cluster named ` + "`" + `example-cluster` + "`" + `: doctl kubernetes cluster node-pool update example-cluster example-pool --count 5 --taint "key1=value1:NoSchedule" --taint "key2:NoExecute"`
 
-	recycleDesc := "DEPRECATED: Use `replace-node`. Recycle nodes in a node pool"
 	cmdKubeNodePoolRecycle := CmdBuilder(cmd, k8sCmdService.RunKubernetesNodePoolRecycle,
-		"recycle <cluster-id|cluster-name> <pool-id|pool-name>", recycleDesc, recycleDesc, Writer, aliasOpt("r"), hiddenCmd())
+		"recycle <cluster-id|cluster-name> <pool-id|pool-name>", "Replace the nodes in a node pool", `Replaces nodes in a node pool with new ones.
+
+With the `+"`"+`--rolling`+"`"+` flag, the nodes are replaced one at a time. Each node is cordoned and drained through the cluster's Kubernetes API before it is replaced, and the next node is only touched once its replacement is running. Pods are evicted, so PodDisruptionBudgets are respected; DaemonSet pods are left in place. If a node does not drain within `+"`"+`--drain-timeout`+"`"+`, the command stops and leaves the node cordoned.
+
+Without `+"`"+`--rolling`+"`"+`, this uses the DEPRECATED recycle API, which replaces all the nodes at once. Use `+"`"+`replace-node`+"`"+` to replace a single node instead.`, Writer, aliasOpt("r"))
 	AddStringFlag(cmdKubeNodePoolRecycle, doctl.ArgNodePoolNodeIDs, "", "",
 		"ID or name of the nodes in the node pool to recycle")
+	AddBoolFlag(cmdKubeNodePoolRecycle, doctl.ArgNodePoolRolling, "", false,
+		"Replaces the nodes one at a time, draining each node first")
+	AddDurationFlag(cmdKubeNodePoolRecycle, doctl.ArgNodePoolDrainTimeout, "", 5*time.Minute,
+		"With --rolling, how long to wait for the pods on each node to be evicted")
+	cmdKubeNodePoolRecycle.Example = `The following example replaces every node in a node pool named ` + "`" + `example-pool` + "`" + ` one at a time: doctl kubernetes cluster node-pool recycle example-cluster example-pool --rolling --drain-timeout 5m`
+
+	cmdKubeNodePoolAutoscale := CmdBuilder(cmd, k8sCmdService.RunKubernetesNodePoolAutoscale,
+		"autoscale <cluster-id|cluster-name> <pool-id|pool-name>", "Change the autoscaling limits of a node pool",
+		`Enables autoscaling on a node pool, or changes its minimum and maximum number of nodes, without changing any other setting of the pool or replacing its nodes. A limit that is not given keeps its current value.`, Writer)
+	AddIntFlag(cmdKubeNodePoolAutoscale, doctl.ArgNodePoolMinNodes, "", 0,
+		"The minimum number of nodes in the node pool")
+	AddIntFlag(cmdKubeNodePoolAutoscale, doctl.ArgNodePoolMaxNodes, "", 0,
+		"The maximum number of nodes in the node pool")
+	AddBoolFlag(cmdKubeNodePoolAutoscale, doctl.ArgNodePoolAutoScaleDisable, "", false,
+		"Disables autoscaling on the node pool")
+	cmdKubeNodePoolAutoscale.Example = `The following example lets a node pool named ` + "`" + `example-pool` + "`" + ` scale between 2 and 10 nodes: doctl kubernetes cluster node-pool autoscale example-cluster example-pool --min-nodes 2 --max-nodes 10`
 
 	cmdKubeNodePoolDelete := CmdBuilder(cmd, k8sCmdService.RunKubernetesNodePoolDelete,
 		"delete <cluster-id|cluster-name> <pool-id|pool-name>",
@@ -569,6 +601,19 @@ Removes container registry support from the specified Kubernetes cluster(s).`,
 		Writer, aliasOpt("rm"))
 	cmdKubeRegistryRemove.Example = `The following example removes container registry support from a cluster named ` + "`" + `example-cluster` + "`" + `: doctl kubernetes cluster registry remove example-cluster`
 
+	cmdKubeRegistryRefresh := CmdBuilder(cmd, k8sCmdService.RunKubernetesRegistryRefresh,
+		"refresh <cluster-id|cluster-name>", "Rotate the registry credentials in a Kubernetes cluster before they expire", `
+Creates or updates a secret holding read-only registry credentials that expire after `+"`"+`--expiry-seconds`+"`"+`, and records their expiry on the secret. When the secret already exists, new credentials are only generated once the current ones expire within `+"`"+`--refresh-before`+"`"+`, so the command is meant to be run on a schedule, such as from cron or a CI job, at least that often. No controller or sidecar needs to run in the cluster.
+
+Unless the secret is in the `+"`"+`kube-system`+"`"+` namespace, where the DOSecret operator takes care of this, the namespace's default service account is also patched to use the secret as an image pull secret.`,
+		Writer, aliasOpt("r"))
+	AddStringFlag(cmdKubeRegistryRefresh, doctl.ArgObjectName, "", "", "The secret's name. Defaults to the registry name prefixed with `registry-`")
+	AddStringFlag(cmdKubeRegistryRefresh, doctl.ArgObjectNamespace, "", "kube-system", "The Kubernetes namespace to hold the secret")
+	AddIntFlag(cmdKubeRegistryRefresh, doctl.ArgRegistryExpirySeconds, "", 7*24*60*60, "The length of time new credentials are valid for, in seconds")
+	AddDurationFlag(cmdKubeRegistryRefresh, doctl.ArgRegistryRefreshBefore, "", 48*time.Hour, "Refresh the credentials once they expire within this long")
+	AddBoolFlag(cmdKubeRegistryRefresh, doctl.ArgForce, doctl.ArgShortForce, false, "Refresh the credentials even if they are not about to expire")
+	cmdKubeRegistryRefresh.Example = `The following crontab entry checks the registry credentials of a cluster named ` + "`" + `example-cluster` + "`" + ` every day at 03:00 and rotates them when they expire within two days: 0 3 * * * doctl kubernetes cluster registry refresh example-cluster --namespace apps`
+
 	return cmd
 }
 
@@ -964,7 +1009,7 @@ func (s *KubernetesCommandService) RunKubernetesClusterDelete(c *CmdConfig) erro
 			return err
 		}
 
-		if force || AskForConfirmDelete("Kubernetes cluster", 1) == nil {
+		if force || AskForConfirmDelete("Kubernetes cluster", 1, cluster) == nil {
 			// continue
 		} else {
 			return fmt.Errorf("Operation aborted")
@@ -1037,7 +1082,7 @@ func (s *KubernetesCommandService) RunKubernetesClusterDeleteSelective(c *CmdCon
 		return err
 	}
 
-	if force || AskForConfirmDelete("Kubernetes cluster", 1) == nil {
+	if force || AskForConfirmDelete("Kubernetes cluster", 1, clusterIDorName) == nil {
 		// continue
 	} else {
 		return fmt.Errorf("Operation aborted")
@@ -1437,7 +1482,9 @@ func (s *KubernetesCommandService) RunKubernetesNodePoolUpdate(c *CmdConfig) err
 	return displayNodePools(c, *nodePool)
 }
 
-// RunKubernetesNodePoolRecycle DEPRECATED: will be removed in v2.0, please use delete-node or replace-node
+// RunKubernetesNodePoolRecycle replaces the nodes of a node pool. Without
+// --rolling it uses the recycle API, which is DEPRECATED: it will be removed
+// in v2.0, please use delete-node or replace-node.
 func (s *KubernetesCommandService) RunKubernetesNodePoolRecycle(c *CmdConfig) error {
 	if len(c.Args) != 2 {
 		return doctl.NewMissingArgsErr(c.NS)
@@ -1451,6 +1498,14 @@ func (s *KubernetesCommandService) RunKubernetesNodePoolRecycle(c *CmdConfig) er
 		return err
 	}
 
+	rolling, err := c.Doit.GetBool(c.NS, doctl.ArgNodePoolRolling)
+	if err != nil {
+		return err
+	}
+	if rolling {
+		return s.rollingRecycleNodePool(c, clusterID, poolID)
+	}
+
 	r := new(godo.KubernetesNodePoolRecycleNodesRequest)
 	if err := buildNodePoolRecycleRequestFromArgs(c, clusterID, poolID, r); err != nil {
 		return err
@@ -1478,7 +1533,7 @@ func (s *KubernetesCommandService) RunKubernetesNodePoolDelete(c *CmdConfig) err
 	if err != nil {
 		return err
 	}
-	if force || AskForConfirmDelete("Kubernetes node pool", 1) == nil {
+	if force || AskForConfirmDelete("Kubernetes node pool", 1, c.Args[1]) == nil {
 		kube := c.Kubernetes()
 		if err := kube.DeleteNodePool(clusterID, poolID); err != nil {
 			return err
@@ -1610,8 +1665,92 @@ func (s *KubernetesCommandService) RunKubernetesRegistryRemove(c *CmdConfig) err
 	return kube.RemoveRegistry(r)
 }
 
+// registryExpiresAtAnnotation records on a registry secret when its
+// credentials expire.
+const registryExpiresAtAnnotation = "doctl.digitalocean.com/registry-credentials-expire-at"
+
+// RunKubernetesRegistryRefresh rotates the registry credentials in a
+// cluster's pull secret when they are about to expire.
+func (s *KubernetesCommandService) RunKubernetesRegistryRefresh(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	name, err := c.Doit.GetString(c.NS, doctl.ArgObjectName)
+	if err != nil {
+		return err
+	}
+	namespace, err := c.Doit.GetString(c.NS, doctl.ArgObjectNamespace)
+	if err != nil {
+		return err
+	}
+	expirySeconds, err := c.Doit.GetInt(c.NS, doctl.ArgRegistryExpirySeconds)
+	if err != nil {
+		return err
+	}
+	refreshBefore, err := c.Doit.GetDuration(c.NS, doctl.ArgRegistryRefreshBefore)
+	if err != nil {
+		return err
+	}
+	force, err := c.Doit.GetBool(c.NS, doctl.ArgForce)
+	if err != nil {
+		return err
+	}
+	expiry := time.Duration(expirySeconds) * time.Second
+	if expiry <= refreshBefore {
+		return fmt.Errorf("--%s must be longer than --%s, or the credentials are refreshed on every run", doctl.ArgRegistryExpirySeconds, doctl.ArgRegistryRefreshBefore)
+	}
+
+	clusterID, err := clusterIDize(c, c.Args[0])
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name, err = defaultRegistrySecretName(c)
+		if err != nil {
+			return err
+		}
+	}
+	kube, err := kubeAPIClientForCluster(c, clusterID)
+	if err != nil {
+		return err
+	}
+
+	body, err := kube.do(http.MethodGet, fmt.Sprintf("/api/v1/namespaces/%s/secrets/%s", url.PathEscape(namespace), url.PathEscape(name)), "", nil)
+	switch {
+	case isKubeAPIStatus(err, http.StatusNotFound):
+	case err != nil:
+		return err
+	case !force:
+		var existing corev1.Secret
+		if err := json.Unmarshal(body, &existing); err != nil {
+			return err
+		}
+		expires, err := time.Parse(time.RFC3339, existing.Annotations[registryExpiresAtAnnotation])
+		if err == nil && time.Now().Add(refreshBefore).Before(expires) {
+			fmt.Fprintf(c.Out, "Secret %s/%s is valid until %s; not refreshed\n", namespace, name, expires.Format(time.RFC3339))
+			return nil
+		}
+	}
+
+	expires := time.Now().Add(expiry).UTC()
+	creds, err := c.Registry().DockerCredentials(&godo.RegistryDockerCredentialsRequest{
+		ExpirySeconds: godo.PtrTo(expirySeconds),
+	})
+	if err != nil {
+		return err
+	}
+	secret := registrySecret(name, namespace, creds.DockerConfigJSON)
+	secret.Annotations[registryExpiresAtAnnotation] = expires.Format(time.RFC3339)
+	if err := applyRegistrySecretWith(c, kube, secret); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "Credentials are valid until %s\n", expires.Format(time.RFC3339))
+	return nil
+}
+
 func buildClusterCreateRequestFromArgs(c *CmdConfig, r *godo.KubernetesClusterCreateRequest, defaultNodeSize string, defaultNodeCount int) error {
-	region, err := c.Doit.GetString(c.NS, doctl.ArgRegionSlug)
+	region, err := regionFromArgs(c, doctl.ArgRegionSlug)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,93 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/digitalocean/doctl"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// kubeAPIClient makes raw requests to a cluster's Kubernetes API, for the few
+// calls doctl makes itself instead of leaving them to kubectl.
+type kubeAPIClient struct {
+	client *http.Client
+	host   string
+}
+
+func newKubeAPIClient(kubeconfig *clientcmdapi.Config) (*kubeAPIClient, error) {
+	restConfig, err := clientcmd.NewDefaultClientConfig(*kubeconfig, nil).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := rest.HTTPClientFor(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &kubeAPIClient{client: doctl.ReadOnlyClient(client), host: restConfig.Host}, nil
+}
+
+// kubeAPIError is a response from the Kubernetes API with an error status.
+type kubeAPIError struct {
+	status  int
+	message string
+}
+
+func (e *kubeAPIError) Error() string {
+	return fmt.Sprintf("kubernetes API returned %d: %s", e.status, e.message)
+}
+
+func isKubeAPIStatus(err error, status int) bool {
+	apiErr, ok := err.(*kubeAPIError)
+	return ok && apiErr.status == status
+}
+
+func (k *kubeAPIClient) do(method, path, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, k.host+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		var status metav1.Status
+		msg := string(respBody)
+		if json.Unmarshal(respBody, &status) == nil && status.Message != "" {
+			msg = status.Message
+		}
+		return nil, &kubeAPIError{status: resp.StatusCode, message: msg}
+	}
+	return respBody, nil
+}
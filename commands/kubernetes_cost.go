@@ -0,0 +1,237 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/digitalocean/doctl/commands/displayers"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// podMetricsList is the response of the pods endpoint of the resource
+// metrics API, served by metrics-server.
+type podMetricsList struct {
+	Items []struct {
+		Metadata struct {
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Containers []struct {
+			Usage corev1.ResourceList `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+// namespaceUsage is the CPU, in cores, and memory, in bytes, used by the pods
+// of a namespace.
+type namespaceUsage struct {
+	cpu    float64
+	memory float64
+}
+
+// RunKubernetesClusterCost estimates the monthly cost of a cluster's nodes
+// attributed to each namespace.
+func (s *KubernetesCommandService) RunKubernetesClusterCost(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	clusterID, err := clusterIDize(c, c.Args[0])
+	if err != nil {
+		return err
+	}
+
+	cluster, err := c.Kubernetes().Get(clusterID)
+	if err != nil {
+		return err
+	}
+	sizes, err := c.Sizes().List()
+	if err != nil {
+		return err
+	}
+	prices := make(map[string]float64, len(sizes))
+	for _, size := range sizes {
+		prices[size.Slug] = size.PriceMonthly
+	}
+	nodeSizes := map[string]string{}
+	for _, pool := range cluster.NodePools {
+		for _, node := range pool.Nodes {
+			nodeSizes[node.Name] = pool.Size
+		}
+	}
+
+	kube, err := kubeAPIClientForCluster(c, clusterID)
+	if err != nil {
+		return err
+	}
+	var nodes corev1.NodeList
+	if err := kube.getJSON("/api/v1/nodes", &nodes); err != nil {
+		return err
+	}
+	var pods corev1.PodList
+	if err := kube.getJSON("/api/v1/pods", &pods); err != nil {
+		return err
+	}
+
+	var usage map[string]namespaceUsage
+	var metrics podMetricsList
+	if err := kube.getJSON("/apis/metrics.k8s.io/v1beta1/pods", &metrics); err != nil {
+		if !isKubeAPIStatus(err, http.StatusNotFound) && !isKubeAPIStatus(err, http.StatusServiceUnavailable) {
+			return err
+		}
+		notice("Usage is not shown because the cluster doesn't serve the resource metrics API; install metrics-server to report it")
+	} else {
+		usage = map[string]namespaceUsage{}
+		for _, pod := range metrics.Items {
+			u := usage[pod.Metadata.Namespace]
+			for _, container := range pod.Containers {
+				u.cpu += container.Usage.Cpu().AsApproximateFloat64()
+				u.memory += container.Usage.Memory().AsApproximateFloat64()
+			}
+			usage[pod.Metadata.Namespace] = u
+		}
+	}
+
+	nodePrices := make(map[string]float64, len(nodes.Items))
+	for _, node := range nodes.Items {
+		size, ok := nodeSizes[node.Name]
+		if !ok {
+			size = node.Labels[corev1.LabelInstanceTypeStable]
+		}
+		price, ok := prices[size]
+		if !ok {
+			warn("The price of node %s is unknown, so it is left out of the costs", node.Name)
+		}
+		nodePrices[node.Name] = price
+	}
+
+	costs, requested := kubernetesNamespaceCosts(nodes.Items, pods.Items, nodePrices, usage)
+
+	var total float64
+	for _, price := range nodePrices {
+		total += price
+	}
+	notice("%d nodes cost an estimated $%.2f per month, of which pods request %.0f%% of the CPU and %.0f%% of the memory", len(nodes.Items), total, 100*requested.cpu, 100*requested.memory)
+
+	return c.Display(&displayers.KubernetesCosts{Costs: costs})
+}
+
+// kubernetesNamespaceCosts attributes the price of each node to the
+// namespaces of the pods running on it, by the average of the fractions of
+// the node's allocatable CPU and memory that each pod requests. Capacity no
+// pod requests is reported as idle, after the namespaces in decreasing order
+// of cost. The fractions of the cluster's CPU and memory that are requested
+// are returned too.
+func kubernetesNamespaceCosts(nodes []corev1.Node, pods []corev1.Pod, nodePrices map[string]float64, usage map[string]namespaceUsage) ([]displayers.KubernetesNamespaceCost, namespaceUsage) {
+	allocatable := make(map[string]namespaceUsage, len(nodes))
+	idle := displayers.KubernetesNamespaceCost{}
+	var capacity namespaceUsage
+	for _, node := range nodes {
+		a := namespaceUsage{
+			cpu:    node.Status.Allocatable.Cpu().AsApproximateFloat64(),
+			memory: node.Status.Allocatable.Memory().AsApproximateFloat64(),
+		}
+		allocatable[node.Name] = a
+		capacity.cpu += a.cpu
+		capacity.memory += a.memory
+		idle.CPURequests += a.cpu
+		idle.MemoryRequests += a.memory
+		idle.MonthlyCost += nodePrices[node.Name]
+	}
+
+	byNamespace := map[string]*displayers.KubernetesNamespaceCost{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		a, ok := allocatable[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+
+		cost, ok := byNamespace[pod.Namespace]
+		if !ok {
+			cost = &displayers.KubernetesNamespaceCost{Namespace: pod.Namespace}
+			byNamespace[pod.Namespace] = cost
+		}
+		cpu, memory := podRequests(pod)
+		cost.Pods++
+		cost.CPURequests += cpu
+		cost.MemoryRequests += memory
+		idle.CPURequests -= cpu
+		idle.MemoryRequests -= memory
+
+		var share float64
+		if a.cpu > 0 {
+			share += cpu / a.cpu / 2
+		}
+		if a.memory > 0 {
+			share += memory / a.memory / 2
+		}
+		c := share * nodePrices[pod.Spec.NodeName]
+		cost.MonthlyCost += c
+		idle.MonthlyCost -= c
+	}
+
+	costs := make([]displayers.KubernetesNamespaceCost, 0, len(byNamespace)+1)
+	for namespace, cost := range byNamespace {
+		if u, ok := usage[namespace]; ok {
+			cost.CPUUsed, cost.MemoryUsed = &u.cpu, &u.memory
+		}
+		costs = append(costs, *cost)
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].MonthlyCost != costs[j].MonthlyCost {
+			return costs[i].MonthlyCost > costs[j].MonthlyCost
+		}
+		return costs[i].Namespace < costs[j].Namespace
+	})
+	costs = append(costs, idle)
+
+	var requested namespaceUsage
+	if capacity.cpu > 0 {
+		requested.cpu = 1 - idle.CPURequests/capacity.cpu
+	}
+	if capacity.memory > 0 {
+		requested.memory = 1 - idle.MemoryRequests/capacity.memory
+	}
+	return costs, requested
+}
+
+// podRequests returns the CPU, in cores, and memory, in bytes, that the
+// scheduler reserves for a pod: the larger of the sum of its containers'
+// requests and the largest request of an init container.
+func podRequests(pod corev1.Pod) (float64, float64) {
+	var cpu, memory float64
+	for _, container := range pod.Spec.Containers {
+		cpu += container.Resources.Requests.Cpu().AsApproximateFloat64()
+		memory += container.Resources.Requests.Memory().AsApproximateFloat64()
+	}
+	for _, container := range pod.Spec.InitContainers {
+		cpu = max(cpu, container.Resources.Requests.Cpu().AsApproximateFloat64())
+		memory = max(memory, container.Resources.Requests.Memory().AsApproximateFloat64())
+	}
+	return cpu, memory
+}
+
+// getJSON decodes the response to a GET of path into v.
+func (k *kubeAPIClient) getJSON(path string, v any) error {
+	body, err := k.do(http.MethodGet, path, "", nil)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCostNodes = `{"items":[
+  {"metadata":{"name":"pool-a"},"status":{"allocatable":{"cpu":"2","memory":"4Gi"}}},
+  {"metadata":{"name":"pool-b"},"status":{"allocatable":{"cpu":"2","memory":"4Gi"}}}
+]}`
+	testCostPods = `{"items":[
+  {"metadata":{"name":"web-1","namespace":"web"},"spec":{"nodeName":"pool-a","containers":[{"name":"web","resources":{"requests":{"cpu":"500m","memory":"1Gi"}}}]},"status":{"phase":"Running"}},
+  {"metadata":{"name":"web-2","namespace":"web"},"spec":{"nodeName":"pool-b","initContainers":[{"name":"migrate","resources":{"requests":{"cpu":"1","memory":"256Mi"}}}],"containers":[{"name":"web","resources":{"requests":{"cpu":"500m","memory":"1Gi"}}}]},"status":{"phase":"Running"}},
+  {"metadata":{"name":"dns","namespace":"kube-system"},"spec":{"nodeName":"pool-a","containers":[{"name":"dns","resources":{"requests":{"cpu":"250m","memory":"512Mi"}}}]},"status":{"phase":"Running"}},
+  {"metadata":{"name":"job","namespace":"batch"},"spec":{"nodeName":"pool-b","containers":[{"name":"job","resources":{"requests":{"cpu":"2","memory":"4Gi"}}}]},"status":{"phase":"Succeeded"}},
+  {"metadata":{"name":"pending","namespace":"batch"},"spec":{"containers":[{"name":"job","resources":{"requests":{"cpu":"2","memory":"4Gi"}}}]},"status":{"phase":"Pending"}}
+]}`
+	testCostMetrics = `{"items":[
+  {"metadata":{"name":"web-1","namespace":"web"},"containers":[{"name":"web","usage":{"cpu":"100m","memory":"200Mi"}}]},
+  {"metadata":{"name":"dns","namespace":"kube-system"},"containers":[{"name":"dns","usage":{"cpu":"50m","memory":"100Mi"}}]}
+]}`
+)
+
+func TestKubernetesClusterCost(t *testing.T) {
+	for _, metrics := range []bool{true, false} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v1/nodes":
+				w.Write([]byte(testCostNodes))
+			case "/api/v1/pods":
+				w.Write([]byte(testCostPods))
+			case "/apis/metrics.k8s.io/v1beta1/pods":
+				if !metrics {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write([]byte(testCostMetrics))
+			default:
+				t.Errorf("unexpected request to %s", r.URL.Path)
+			}
+		}))
+		defer server.Close()
+
+		defer func(f func(*CmdConfig, string) (*kubeAPIClient, error)) { kubeAPIClientForCluster = f }(kubeAPIClientForCluster)
+		kubeAPIClientForCluster = func(_ *CmdConfig, clusterID string) (*kubeAPIClient, error) {
+			assert.Equal(t, testCluster.ID, clusterID)
+			return &kubeAPIClient{client: server.Client(), host: server.URL}, nil
+		}
+
+		cluster := &do.KubernetesCluster{KubernetesCluster: &godo.KubernetesCluster{
+			ID: testCluster.ID,
+			NodePools: []*godo.KubernetesNodePool{{
+				Size:  "s-2vcpu-4gb",
+				Nodes: []*godo.KubernetesNode{{Name: "pool-a"}, {Name: "pool-b"}},
+			}},
+		}}
+
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			tm.kubernetes.EXPECT().Get(testCluster.ID).Return(cluster, nil)
+			tm.sizes.EXPECT().List().Return(do.Sizes{{Size: &godo.Size{Slug: "s-2vcpu-4gb", PriceMonthly: 24}}}, nil)
+
+			var out bytes.Buffer
+			config.Out = &out
+			config.Args = append(config.Args, testCluster.ID)
+
+			require.NoError(t, kubernetesCommandService().RunKubernetesClusterCost(config))
+			want := `Namespace      Pods    CPU Requests    CPU Used    Memory Requests    Memory Used    Est. Monthly Cost
+web            2       1.50            0.10        2048 MiB           200 MiB        $15.00
+kube-system    1       0.25            0.05        512 MiB            100 MiB        $3.00
+(idle)         0       2.25            -           5632 MiB           -              $30.00
+`
+			if !metrics {
+				want = `Namespace      Pods    CPU Requests    CPU Used    Memory Requests    Memory Used    Est. Monthly Cost
+web            2       1.50            -           2048 MiB           -              $15.00
+kube-system    1       0.25            -           512 MiB            -              $3.00
+(idle)         0       2.25            -           5632 MiB           -              $30.00
+`
+			}
+			assert.Equal(t, want, out.String())
+		})
+	}
+}
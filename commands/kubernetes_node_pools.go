@@ -0,0 +1,317 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/godo"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	// nodePoolPollInterval is how often the node pool is checked while waiting
+	// for a replacement node. It is replaced for testing.
+	nodePoolPollInterval = 10 * time.Second
+	// nodeReplaceTimeout bounds the wait for a replacement node to be running.
+	nodeReplaceTimeout = 20 * time.Minute
+)
+
+// NodeDrainer cordons and drains nodes through a cluster's Kubernetes API.
+type NodeDrainer interface {
+	// Cordon marks the node unschedulable.
+	Cordon(node string) error
+	// Drain evicts the pods on the node, returning an error if any are
+	// left once the timeout has passed.
+	Drain(node string, timeout time.Duration) error
+}
+
+func newAPINodeDrainer(kubeconfig *clientcmdapi.Config) (NodeDrainer, error) {
+	client, err := newKubeAPIClient(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return &apiNodeDrainer{kubeAPIClient: client, pollInterval: 5 * time.Second}, nil
+}
+
+// apiNodeDrainer drains nodes the way kubectl drain does: the node is
+// patched unschedulable, and each of its pods is evicted so that
+// PodDisruptionBudgets are respected.
+type apiNodeDrainer struct {
+	*kubeAPIClient
+	pollInterval time.Duration
+}
+
+func (d *apiNodeDrainer) Cordon(node string) error {
+	body := []byte(`{"spec":{"unschedulable":true}}`)
+	_, err := d.do(http.MethodPatch, "/api/v1/nodes/"+url.PathEscape(node), "application/strategic-merge-patch+json", body)
+	return err
+}
+
+func (d *apiNodeDrainer) Drain(node string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		pods, err := d.evictablePods(node)
+		if err != nil {
+			return err
+		}
+		if len(pods) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errTimeout("timed out after %s with %d pods left, including %s/%s", timeout, len(pods), pods[0].Namespace, pods[0].Name)
+		}
+
+		for _, pod := range pods {
+			if pod.DeletionTimestamp != nil {
+				continue
+			}
+			if err := d.evict(pod); err != nil {
+				return err
+			}
+		}
+		time.Sleep(d.pollInterval)
+	}
+}
+
+// evictablePods lists the pods on the node that a drain has to remove.
+// DaemonSet pods would just be recreated, mirror pods are managed by the
+// kubelet, and finished pods hold no workload, so those are left alone.
+func (d *apiNodeDrainer) evictablePods(node string) ([]corev1.Pod, error) {
+	path := "/api/v1/pods?fieldSelector=" + url.QueryEscape("spec.nodeName="+node)
+	body, err := d.do(http.MethodGet, path, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	var list corev1.PodList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+
+	var pods []corev1.Pod
+	for _, pod := range list.Items {
+		if _, mirror := pod.Annotations[corev1.MirrorPodAnnotationKey]; mirror {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		daemon := false
+		for _, ref := range pod.OwnerReferences {
+			if ref.Kind == "DaemonSet" {
+				daemon = true
+			}
+		}
+		if !daemon {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+func (d *apiNodeDrainer) evict(pod corev1.Pod) error {
+	eviction := policyv1.Eviction{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "policy/v1", Kind: "Eviction"},
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+	}
+	body, err := json.Marshal(eviction)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/eviction", url.PathEscape(pod.Namespace), url.PathEscape(pod.Name))
+	_, err = d.do(http.MethodPost, path, "application/json", body)
+	// A PodDisruptionBudget may not allow the eviction yet, in which case
+	// it is retried on the next pass; a pod that is gone needs no eviction.
+	if isKubeAPIStatus(err, http.StatusTooManyRequests) || isKubeAPIStatus(err, http.StatusNotFound) {
+		return nil
+	}
+	return err
+}
+
+// rollingRecycleNodePool replaces the nodes of a pool one at a time. Each
+// node is cordoned and drained before it is replaced, and the next node is
+// only touched once the replacement is running.
+func (s *KubernetesCommandService) rollingRecycleNodePool(c *CmdConfig, clusterID, poolID string) error {
+	drainTimeout, err := c.Doit.GetDuration(c.NS, doctl.ArgNodePoolDrainTimeout)
+	if err != nil {
+		return err
+	}
+
+	kube := c.Kubernetes()
+	pool, err := kube.GetNodePool(clusterID, poolID)
+	if err != nil {
+		return err
+	}
+
+	nodes := pool.Nodes
+	r := new(godo.KubernetesNodePoolRecycleNodesRequest)
+	if err := buildNodePoolRecycleRequestFromArgs(c, clusterID, poolID, r); err != nil {
+		return err
+	}
+	if len(r.Nodes) > 0 {
+		nodes = nil
+		for _, id := range r.Nodes {
+			node := findNode(pool.Nodes, id)
+			if node == nil {
+				return fmt.Errorf("node %s is not in node pool %s", id, pool.Name)
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("node pool %s has no nodes", pool.Name)
+	}
+
+	kubeconfig, err := s.KubeconfigProvider.Remote(kube, clusterID, 0)
+	if err != nil {
+		return err
+	}
+	drainer, err := s.NodeDrainer(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	size := len(pool.Nodes)
+	for i, node := range nodes {
+		notice("Recycling node %s (%d of %d)", node.Name, i+1, len(nodes))
+		if err := drainer.Cordon(node.Name); err != nil {
+			return fmt.Errorf("cordoning node %s: %v", node.Name, err)
+		}
+		if err := drainer.Drain(node.Name, drainTimeout); err != nil {
+			return fmt.Errorf("draining node %s: %v; the node is still cordoned", node.Name, err)
+		}
+
+		// The node was drained above, so DigitalOcean must not drain it again.
+		err := kube.DeleteNode(clusterID, poolID, node.ID, &godo.KubernetesNodeDeleteRequest{
+			Replace:   true,
+			SkipDrain: true,
+		})
+		if err != nil {
+			return err
+		}
+		if err := waitForNodeReplacement(c, clusterID, poolID, node.ID, size); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.Out, "Replaced node %s\n", node.Name)
+	}
+	return nil
+}
+
+func findNode(nodes []*godo.KubernetesNode, id string) *godo.KubernetesNode {
+	for _, n := range nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// waitForNodeReplacement waits until the old node is gone and the pool is
+// back to size with every node running.
+func waitForNodeReplacement(c *CmdConfig, clusterID, poolID, oldNodeID string, size int) error {
+	deadline := time.Now().Add(nodeReplaceTimeout)
+	for {
+		pool, err := c.Kubernetes().GetNodePool(clusterID, poolID)
+		if err != nil {
+			return err
+		}
+
+		running := 0
+		for _, n := range pool.Nodes {
+			if n.Status != nil && n.Status.State == "running" {
+				running++
+			}
+		}
+		if findNode(pool.Nodes, oldNodeID) == nil && running >= size && running == len(pool.Nodes) {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return errTimeout("timed out waiting for the replacement of node %s", oldNodeID)
+		}
+		time.Sleep(nodePoolPollInterval)
+	}
+}
+
+// RunKubernetesNodePoolAutoscale changes the autoscaling limits of a node pool in place.
+func (s *KubernetesCommandService) RunKubernetesNodePoolAutoscale(c *CmdConfig) error {
+	if len(c.Args) != 2 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	clusterID, err := clusterIDize(c, c.Args[0])
+	if err != nil {
+		return err
+	}
+	kube := c.Kubernetes()
+	poolID, err := poolIDize(kube, clusterID, c.Args[1])
+	if err != nil {
+		return err
+	}
+
+	disable, err := c.Doit.GetBool(c.NS, doctl.ArgNodePoolAutoScaleDisable)
+	if err != nil {
+		return err
+	}
+	minNodes, err := c.Doit.GetIntPtr(c.NS, doctl.ArgNodePoolMinNodes)
+	if err != nil {
+		return err
+	}
+	maxNodes, err := c.Doit.GetIntPtr(c.NS, doctl.ArgNodePoolMaxNodes)
+	if err != nil {
+		return err
+	}
+
+	pool, err := kube.GetNodePool(clusterID, poolID)
+	if err != nil {
+		return err
+	}
+	r := &godo.KubernetesNodePoolUpdateRequest{Name: pool.Name}
+
+	if disable {
+		if minNodes != nil || maxNodes != nil {
+			return fmt.Errorf("--%s cannot be combined with --%s or --%s", doctl.ArgNodePoolAutoScaleDisable, doctl.ArgNodePoolMinNodes, doctl.ArgNodePoolMaxNodes)
+		}
+		r.AutoScale = godo.PtrTo(false)
+	} else {
+		if minNodes == nil && maxNodes == nil {
+			return fmt.Errorf("at least one of --%s and --%s is required", doctl.ArgNodePoolMinNodes, doctl.ArgNodePoolMaxNodes)
+		}
+		if minNodes == nil {
+			minNodes = godo.PtrTo(pool.MinNodes)
+		}
+		if maxNodes == nil {
+			maxNodes = godo.PtrTo(pool.MaxNodes)
+		}
+		if *minNodes < 0 || *maxNodes < 1 || *minNodes > *maxNodes {
+			return fmt.Errorf("invalid autoscaling limits: min %d, max %d", *minNodes, *maxNodes)
+		}
+		r.AutoScale = godo.PtrTo(true)
+		r.MinNodes = minNodes
+		r.MaxNodes = maxNodes
+	}
+
+	nodePool, err := kube.UpdateNodePool(clusterID, poolID, r)
+	if err != nil {
+		return err
+	}
+	return displayNodePools(c, *nodePool)
+}
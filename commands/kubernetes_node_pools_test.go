@@ -0,0 +1,220 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+type fakeNodeDrainer struct {
+	calls    []string
+	drainErr error
+}
+
+func (f *fakeNodeDrainer) Cordon(node string) error {
+	f.calls = append(f.calls, "cordon "+node)
+	return nil
+}
+
+func (f *fakeNodeDrainer) Drain(node string, timeout time.Duration) error {
+	f.calls = append(f.calls, "drain "+node+" "+timeout.String())
+	return f.drainErr
+}
+
+func rollingTestPool(nodes ...*godo.KubernetesNode) *do.KubernetesNodePool {
+	return &do.KubernetesNodePool{KubernetesNodePool: &godo.KubernetesNodePool{
+		ID:    testNodePool.ID,
+		Name:  testNodePool.Name,
+		Count: 2,
+		Nodes: nodes,
+	}}
+}
+
+func rollingTestNode(id, state string) *godo.KubernetesNode {
+	return &godo.KubernetesNode{ID: id, Name: "node-" + id, Status: &godo.KubernetesNodeStatus{State: state}}
+}
+
+func TestKubernetesNodePool_RollingRecycle(t *testing.T) {
+	defer func(d time.Duration) { nodePoolPollInterval = d }(nodePoolPollInterval)
+	nodePoolPollInterval = 0
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		a, b := rollingTestNode("a", "running"), rollingTestNode("b", "running")
+		c, d := rollingTestNode("c", "running"), rollingTestNode("d", "running")
+
+		gomock.InOrder(
+			tm.kubernetes.EXPECT().GetNodePool(testCluster.ID, testNodePool.ID).Return(rollingTestPool(a, b), nil),
+			tm.kubernetes.EXPECT().DeleteNode(testCluster.ID, testNodePool.ID, "a", &godo.KubernetesNodeDeleteRequest{Replace: true, SkipDrain: true}).Return(nil),
+			// The replacement is still provisioning on the first check.
+			tm.kubernetes.EXPECT().GetNodePool(testCluster.ID, testNodePool.ID).Return(rollingTestPool(b, rollingTestNode("c", "provisioning")), nil),
+			tm.kubernetes.EXPECT().GetNodePool(testCluster.ID, testNodePool.ID).Return(rollingTestPool(b, c), nil),
+			tm.kubernetes.EXPECT().DeleteNode(testCluster.ID, testNodePool.ID, "b", &godo.KubernetesNodeDeleteRequest{Replace: true, SkipDrain: true}).Return(nil),
+			tm.kubernetes.EXPECT().GetNodePool(testCluster.ID, testNodePool.ID).Return(rollingTestPool(c, d), nil),
+		)
+
+		drainer := &fakeNodeDrainer{}
+		svc := testK8sCmdService()
+		svc.NodeDrainer = func(*clientcmdapi.Config) (NodeDrainer, error) { return drainer, nil }
+
+		config.Args = append(config.Args, testCluster.ID, testNodePool.ID)
+		config.Doit.Set(config.NS, doctl.ArgNodePoolRolling, true)
+		config.Doit.Set(config.NS, doctl.ArgNodePoolDrainTimeout, 5*time.Minute)
+
+		require.NoError(t, svc.RunKubernetesNodePoolRecycle(config))
+		assert.Equal(t, []string{"cordon node-a", "drain node-a 5m0s", "cordon node-b", "drain node-b 5m0s"}, drainer.calls)
+	})
+}
+
+func TestKubernetesNodePool_RollingRecycleDrainFails(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		a, b := rollingTestNode("a", "running"), rollingTestNode("b", "running")
+		tm.kubernetes.EXPECT().GetNodePool(testCluster.ID, testNodePool.ID).Return(rollingTestPool(a, b), nil).Times(2)
+
+		drainer := &fakeNodeDrainer{drainErr: errors.New("timed out")}
+		svc := testK8sCmdService()
+		svc.NodeDrainer = func(*clientcmdapi.Config) (NodeDrainer, error) { return drainer, nil }
+
+		config.Args = append(config.Args, testCluster.ID, testNodePool.ID)
+		config.Doit.Set(config.NS, doctl.ArgNodePoolRolling, true)
+		config.Doit.Set(config.NS, doctl.ArgNodePoolNodeIDs, "node-b")
+
+		err := svc.RunKubernetesNodePoolRecycle(config)
+		assert.EqualError(t, err, "draining node node-b: timed out; the node is still cordoned")
+		assert.Equal(t, []string{"cordon node-b", "drain node-b 0s"}, drainer.calls)
+	})
+}
+
+func TestAPINodeDrainer(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		patched   string
+		evictions int
+		evicted   bool
+	)
+	pods := func() corev1.PodList {
+		list := corev1.PodList{Items: []corev1.Pod{{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "kube-system",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "agent"}}},
+		}, {
+			ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: "default"},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}}}
+		if !evicted {
+			list.Items = append(list.Items, corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}})
+		}
+		return list
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodPatch && r.URL.Path == "/api/v1/nodes/node-a":
+			assert.Equal(t, "application/strategic-merge-patch+json", r.Header.Get("Content-Type"))
+			body, _ := io.ReadAll(r.Body)
+			patched = string(body)
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/pods":
+			assert.Equal(t, "spec.nodeName=node-a", r.URL.Query().Get("fieldSelector"))
+			json.NewEncoder(w).Encode(pods())
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/namespaces/default/pods/web/eviction":
+			evictions++
+			if evictions == 1 {
+				// The first eviction is refused by a PodDisruptionBudget.
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"kind":"Status","message":"Cannot evict pod"}`))
+				return
+			}
+			evicted = true
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	d := &apiNodeDrainer{kubeAPIClient: &kubeAPIClient{client: server.Client(), host: server.URL}}
+	require.NoError(t, d.Cordon("node-a"))
+	assert.JSONEq(t, `{"spec":{"unschedulable":true}}`, patched)
+
+	require.NoError(t, d.Drain("node-a", time.Minute))
+	assert.Equal(t, 2, evictions)
+
+	evicted = false
+	err := d.Drain("node-a", 0)
+	assert.EqualError(t, err, "timed out after 0s with 1 pods left, including default/web")
+}
+
+func TestKubernetesNodePool_Autoscale(t *testing.T) {
+	pool := do.KubernetesNodePool{KubernetesNodePool: &godo.KubernetesNodePool{
+		ID: testNodePool.ID, Name: testNodePool.Name, AutoScale: true, MinNodes: 1, MaxNodes: 3,
+	}}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.kubernetes.EXPECT().GetNodePool(testCluster.ID, testNodePool.ID).Return(&pool, nil)
+		tm.kubernetes.EXPECT().UpdateNodePool(testCluster.ID, testNodePool.ID, &godo.KubernetesNodePoolUpdateRequest{
+			Name:      testNodePool.Name,
+			AutoScale: godo.PtrTo(true),
+			MinNodes:  godo.PtrTo(1),
+			MaxNodes:  godo.PtrTo(10),
+		}).Return(&pool, nil)
+
+		config.Args = append(config.Args, testCluster.ID, testNodePool.ID)
+		config.Doit.Set(config.NS, doctl.ArgNodePoolMaxNodes, 10)
+
+		assert.NoError(t, testK8sCmdService().RunKubernetesNodePoolAutoscale(config))
+	})
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.kubernetes.EXPECT().GetNodePool(testCluster.ID, testNodePool.ID).Return(&pool, nil)
+		tm.kubernetes.EXPECT().UpdateNodePool(testCluster.ID, testNodePool.ID, &godo.KubernetesNodePoolUpdateRequest{
+			Name:      testNodePool.Name,
+			AutoScale: godo.PtrTo(false),
+		}).Return(&pool, nil)
+
+		config.Args = append(config.Args, testCluster.ID, testNodePool.ID)
+		config.Doit.Set(config.NS, doctl.ArgNodePoolAutoScaleDisable, true)
+
+		assert.NoError(t, testK8sCmdService().RunKubernetesNodePoolAutoscale(config))
+	})
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.kubernetes.EXPECT().GetNodePool(testCluster.ID, testNodePool.ID).Return(&pool, nil)
+
+		config.Args = append(config.Args, testCluster.ID, testNodePool.ID)
+		config.Doit.Set(config.NS, doctl.ArgNodePoolMinNodes, 5)
+
+		err := testK8sCmdService().RunKubernetesNodePoolAutoscale(config)
+		assert.EqualError(t, err, "invalid autoscaling limits: min 5, max 3")
+	})
+}
@@ -1,9 +1,14 @@
 package commands
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/do"
@@ -11,6 +16,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
@@ -207,6 +213,7 @@ func TestKubernetesClusterCommand(t *testing.T) {
 		"registry",
 		"delete-selective",
 		"list-associated-resources",
+		"cost",
 	)
 }
 
@@ -219,6 +226,7 @@ func TestKubernetesNodePoolCommand(t *testing.T) {
 		"create",
 		"update",
 		"recycle",
+		"autoscale",
 		"delete",
 		"delete-node",
 		"replace-node",
@@ -1307,6 +1315,84 @@ func TestKubernetes_DOCRIntegration(t *testing.T) {
 	})
 }
 
+func TestKubernetesRegistryRefresh(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiresAt time.Duration
+		refreshed bool
+	}{
+		{name: "valid", expiresAt: 5 * 24 * time.Hour},
+		{name: "expiring", expiresAt: 12 * time.Hour, refreshed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var applied corev1.Secret
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method + " " + r.URL.Path {
+				case "GET /api/v1/namespaces/apps/secrets/pull":
+					existing := corev1.Secret{}
+					existing.Annotations = map[string]string{
+						registryExpiresAtAnnotation: time.Now().Add(tt.expiresAt).Format(time.RFC3339),
+					}
+					json.NewEncoder(w).Encode(existing)
+				case "PUT /api/v1/namespaces/apps/secrets/pull":
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&applied))
+					w.Write([]byte(`{}`))
+				case "PATCH /api/v1/namespaces/apps/serviceaccounts/default":
+					w.Write([]byte(`{}`))
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			defer func(f func(*CmdConfig, string) (*kubeAPIClient, error)) { kubeAPIClientForCluster = f }(kubeAPIClientForCluster)
+			kubeAPIClientForCluster = func(_ *CmdConfig, clusterID string) (*kubeAPIClient, error) {
+				assert.Equal(t, testCluster.ID, clusterID)
+				return &kubeAPIClient{client: server.Client(), host: server.URL}, nil
+			}
+
+			withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+				if tt.refreshed {
+					tm.registry.EXPECT().DockerCredentials(&godo.RegistryDockerCredentialsRequest{
+						ExpirySeconds: godo.PtrTo(7 * 24 * 60 * 60),
+					}).Return(testDockerCredentials, nil)
+				}
+
+				var out bytes.Buffer
+				config.Out = &out
+				config.Args = append(config.Args, testCluster.ID)
+				config.Doit.Set(config.NS, doctl.ArgObjectName, "pull")
+				config.Doit.Set(config.NS, doctl.ArgObjectNamespace, "apps")
+				config.Doit.Set(config.NS, doctl.ArgRegistryExpirySeconds, 7*24*60*60)
+				config.Doit.Set(config.NS, doctl.ArgRegistryRefreshBefore, 48*time.Hour)
+
+				require.NoError(t, testK8sCmdService().RunKubernetesRegistryRefresh(config))
+				if !tt.refreshed {
+					assert.Contains(t, out.String(), "Secret apps/pull is valid until")
+					return
+				}
+				assert.Equal(t, testDockerCredentials.DockerConfigJSON, applied.Data[".dockerconfigjson"])
+				expires, err := time.Parse(time.RFC3339, applied.Annotations[registryExpiresAtAnnotation])
+				require.NoError(t, err)
+				assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), expires, time.Minute)
+				assert.Contains(t, out.String(), "Applied secret apps/pull\nPatched service account apps/default\nCredentials are valid until")
+			})
+		})
+	}
+}
+
+func TestKubernetesRegistryRefreshExpiryTooShort(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, testCluster.ID)
+		config.Doit.Set(config.NS, doctl.ArgRegistryExpirySeconds, 3600)
+		config.Doit.Set(config.NS, doctl.ArgRegistryRefreshBefore, 48*time.Hour)
+
+		err := testK8sCmdService().RunKubernetesRegistryRefresh(config)
+		assert.EqualError(t, err, "--expiry-seconds must be longer than --refresh-before, or the credentials are refreshed on every run")
+	})
+}
+
 type nilCluster struct {
 	do.KubernetesService
 }
@@ -0,0 +1,322 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+)
+
+var (
+	// certificatePollInterval and certificateVerifyTimeout bound how long
+	// doctl waits for a new certificate to be issued; tests shorten them.
+	certificatePollInterval  = 10 * time.Second
+	certificateVerifyTimeout = 10 * time.Minute
+	// lbCertificateNow is replaced in tests to control certificate expiry.
+	lbCertificateNow = time.Now
+)
+
+func addLoadBalancerCertificateCommands(cmd *Command) {
+	cmdRotate := CmdBuilder(cmd, RunLoadBalancerRotateCertificate, "rotate-certificate <id>",
+		"Replace the TLS certificate used by a load balancer", `Switches the forwarding rules and domains of a load balancer from one certificate to another in a single update, so HTTPS traffic is served throughout the change.
+
+By default every certificate used by the load balancer is replaced. Use `+"`"+`--old-certificate`+"`"+` to replace only one of them. If the new certificate is still being issued, the command waits until it is verified. The command warns when the new certificate doesn't cover a DNS name of a certificate it replaces.`, Writer,
+		displayerType(&displayers.LoadBalancer{}))
+	AddStringFlag(cmdRotate, doctl.ArgLBCertificate, "", "", "The ID of the certificate to switch to", requiredOpt())
+	AddStringFlag(cmdRotate, doctl.ArgLBOldCertificate, "", "", "The ID of the certificate to replace. All certificates are replaced if excluded")
+	AddBoolFlag(cmdRotate, doctl.ArgCommandWait, "", false, "Wait for the load balancer to become active after the update")
+	cmdRotate.Example = `The following example switches a load balancer to the certificate with the ID ` + "`" + `892071a0-bb95-49bc-8021-3afd67a210bf` + "`" + `: doctl compute load-balancer rotate-certificate 4de7ac8b-495b-4884-9a69-1050c6793cd6 --certificate 892071a0-bb95-49bc-8021-3afd67a210bf`
+
+	cmdWatch := CmdBuilder(cmd, RunLoadBalancerWatchCertificates, "watch-certificates <id>",
+		"Replace load balancer certificates before they expire", `Periodically checks the certificates used by a load balancer. When one expires within `+"`"+`--renew-before`+"`"+`, the command issues a Let's Encrypt certificate for the same DNS names and rotates the load balancer to it.
+
+Issuing Let's Encrypt certificates requires the DNS names to be managed by DigitalOcean DNS. Use `+"`"+`--once`+"`"+` to run a single check, for example from cron.`, Writer)
+	AddDurationFlag(cmdWatch, doctl.ArgLBRenewBefore, "", 30*24*time.Hour, "Replace certificates that expire within this duration")
+	AddDurationFlag(cmdWatch, doctl.ArgInterval, "", 12*time.Hour, "How often to check the certificates")
+	AddBoolFlag(cmdWatch, doctl.ArgOnce, "", false, "Check the certificates once and exit")
+	AddBoolFlag(cmdWatch, doctl.ArgLBDeleteOldCertificate, "", false, "Delete certificates after they are replaced")
+	cmdWatch.Example = `The following example checks a load balancer's certificates once and replaces any that expire within two weeks: doctl compute load-balancer watch-certificates 4de7ac8b-495b-4884-9a69-1050c6793cd6 --renew-before 336h --once`
+}
+
+// RunLoadBalancerRotateCertificate switches a load balancer to a new certificate.
+func RunLoadBalancerRotateCertificate(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	lbID := c.Args[0]
+
+	newID, err := c.Doit.GetString(c.NS, doctl.ArgLBCertificate)
+	if err != nil {
+		return err
+	}
+	oldID, err := c.Doit.GetString(c.NS, doctl.ArgLBOldCertificate)
+	if err != nil {
+		return err
+	}
+	wait, err := c.Doit.GetBool(c.NS, doctl.ArgCommandWait)
+	if err != nil {
+		return err
+	}
+
+	lbs := c.LoadBalancers()
+	lb, err := lbs.Get(lbID)
+	if err != nil {
+		return err
+	}
+
+	newCert, err := waitForVerifiedCertificate(c.Certificates(), newID)
+	if err != nil {
+		return err
+	}
+
+	replaced := loadBalancerCertificateIDs(lb)
+	if oldID != "" {
+		if !contains(replaced, oldID) {
+			return fmt.Errorf("load balancer %s doesn't use certificate %s", lb.Name, oldID)
+		}
+		replaced = []string{oldID}
+	}
+	for _, id := range replaced {
+		if id == newID {
+			continue
+		}
+		old, err := c.Certificates().Get(id)
+		if err != nil {
+			return err
+		}
+		if missing := uncoveredDNSNames(old.DNSNames, newCert.DNSNames); len(missing) > 0 {
+			warn("Certificate %s doesn't cover %s, which certificate %s did", newCert.Name, strings.Join(missing, ", "), old.Name)
+		}
+	}
+
+	lb, err = rotateLoadBalancerCertificates(lbs, lb, replaced, newID)
+	if err != nil {
+		return err
+	}
+
+	if wait {
+		notice("Load balancer update is in progress, waiting for load balancer to become active")
+		if err := waitForActiveLoadBalancer(lbs, lb.ID); err != nil {
+			return fmt.Errorf("load balancer couldn't enter `active` state: %v", err)
+		}
+		lb, err = lbs.Get(lb.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	notice("Load balancer %s now uses certificate %s", lb.Name, newCert.Name)
+	return c.Display(&displayers.LoadBalancer{LoadBalancers: do.LoadBalancers{*lb}})
+}
+
+// RunLoadBalancerWatchCertificates replaces a load balancer's certificates
+// with Let's Encrypt certificates before they expire.
+func RunLoadBalancerWatchCertificates(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	lbID := c.Args[0]
+
+	renewBefore, err := c.Doit.GetDuration(c.NS, doctl.ArgLBRenewBefore)
+	if err != nil {
+		return err
+	}
+	interval, err := c.Doit.GetDuration(c.NS, doctl.ArgInterval)
+	if err != nil {
+		return err
+	}
+	once, err := c.Doit.GetBool(c.NS, doctl.ArgOnce)
+	if err != nil {
+		return err
+	}
+	deleteOld, err := c.Doit.GetBool(c.NS, doctl.ArgLBDeleteOldCertificate)
+	if err != nil {
+		return err
+	}
+	if !once && interval < time.Minute {
+		return fmt.Errorf("--%s must be at least 1m", doctl.ArgInterval)
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	for {
+		if err := renewExpiringLoadBalancerCertificates(c, lbID, renewBefore, deleteOld); err != nil {
+			return err
+		}
+		if once {
+			return nil
+		}
+
+		select {
+		case <-sigs:
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// renewExpiringLoadBalancerCertificates makes one pass over the load
+// balancer's certificates, replacing those that expire within renewBefore.
+func renewExpiringLoadBalancerCertificates(c *CmdConfig, lbID string, renewBefore time.Duration, deleteOld bool) error {
+	lbs := c.LoadBalancers()
+	certs := c.Certificates()
+
+	lb, err := lbs.Get(lbID)
+	if err != nil {
+		return err
+	}
+
+	now := lbCertificateNow()
+	for _, id := range loadBalancerCertificateIDs(lb) {
+		cert, err := certs.Get(id)
+		if err != nil {
+			return err
+		}
+		notAfter, err := time.Parse(time.RFC3339, cert.NotAfter)
+		if err != nil {
+			return fmt.Errorf("certificate %s has an unreadable expiry %q: %v", cert.Name, cert.NotAfter, err)
+		}
+		if notAfter.Sub(now) > renewBefore {
+			continue
+		}
+
+		notice("Certificate %s expires %s, issuing a replacement", cert.Name, notAfter.Format(time.RFC3339))
+		issued, err := certs.Create(&godo.CertificateRequest{
+			Name:     fmt.Sprintf("%s-%s", strings.TrimSuffix(cert.Name, "-"+notAfter.Format("20060102")), now.Format("20060102")),
+			DNSNames: cert.DNSNames,
+			Type:     "lets_encrypt",
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := waitForVerifiedCertificate(certs, issued.ID); err != nil {
+			return err
+		}
+
+		lb, err = rotateLoadBalancerCertificates(lbs, lb, []string{cert.ID}, issued.ID)
+		if err != nil {
+			return err
+		}
+		notice("Load balancer %s now uses certificate %s", lb.Name, issued.Name)
+
+		if deleteOld {
+			if err := certs.Delete(cert.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// rotateLoadBalancerCertificates points every forwarding rule and domain
+// using one of oldIDs at newID, in a single update.
+func rotateLoadBalancerCertificates(lbs do.LoadBalancersService, lb *do.LoadBalancer, oldIDs []string, newID string) (*do.LoadBalancer, error) {
+	r := lb.AsRequest()
+	// Droplets of tagged load balancers are listed too, but the API
+	// rejects requests with both.
+	if r.Tag != "" {
+		r.DropletIDs = nil
+	}
+
+	changed := 0
+	for i, rule := range r.ForwardingRules {
+		if rule.CertificateID != "" && rule.CertificateID != newID && contains(oldIDs, rule.CertificateID) {
+			r.ForwardingRules[i].CertificateID = newID
+			changed++
+		}
+	}
+	for _, domain := range r.Domains {
+		if domain.CertificateID != "" && domain.CertificateID != newID && contains(oldIDs, domain.CertificateID) {
+			domain.CertificateID = newID
+			changed++
+		}
+	}
+	if changed == 0 {
+		return nil, fmt.Errorf("load balancer %s has no certificates to replace", lb.Name)
+	}
+
+	return lbs.Update(lb.ID, r)
+}
+
+// loadBalancerCertificateIDs returns the distinct certificates used by a
+// load balancer's forwarding rules and domains.
+func loadBalancerCertificateIDs(lb *do.LoadBalancer) []string {
+	var ids []string
+	add := func(id string) {
+		if id != "" && !contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	for _, rule := range lb.ForwardingRules {
+		add(rule.CertificateID)
+	}
+	for _, domain := range lb.Domains {
+		add(domain.CertificateID)
+	}
+	return ids
+}
+
+// waitForVerifiedCertificate waits until a certificate has been issued.
+func waitForVerifiedCertificate(certs do.CertificatesService, id string) (*do.Certificate, error) {
+	deadline := time.Now().Add(certificateVerifyTimeout)
+	for {
+		cert, err := certs.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		switch cert.State {
+		case "verified":
+			return cert, nil
+		case "error":
+			return nil, fmt.Errorf("certificate %s could not be issued", cert.Name)
+		}
+		if time.Now().After(deadline) {
+			return nil, errTimeout("timeout waiting for certificate %s to be verified", cert.Name)
+		}
+		time.Sleep(certificatePollInterval)
+	}
+}
+
+// uncoveredDNSNames returns the names in want that no name in have covers,
+// treating a wildcard as covering one level of subdomain.
+func uncoveredDNSNames(want, have []string) []string {
+	var missing []string
+	for _, w := range want {
+		covered := false
+		for _, h := range have {
+			if h == w || (strings.HasPrefix(h, "*.") && strings.Count(w, ".") == strings.Count(h, ".") && strings.HasSuffix(w, h[1:])) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			missing = append(missing, w)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func testCertLoadBalancer(tag string, certIDs ...string) *do.LoadBalancer {
+	lb := &godo.LoadBalancer{
+		ID:         "lb-id",
+		Name:       "web-lb",
+		Region:     &godo.Region{Slug: "nyc1"},
+		Tag:        tag,
+		DropletIDs: []int{1, 2},
+		ForwardingRules: []godo.ForwardingRule{
+			{EntryProtocol: "http", EntryPort: 80, TargetProtocol: "http", TargetPort: 80},
+		},
+	}
+	for i, id := range certIDs {
+		lb.ForwardingRules = append(lb.ForwardingRules, godo.ForwardingRule{
+			EntryProtocol: "https", EntryPort: 443 + i, TargetProtocol: "http", TargetPort: 80, CertificateID: id,
+		})
+	}
+	return &do.LoadBalancer{LoadBalancer: lb}
+}
+
+func testLBCertificate(id, state, notAfter string, dnsNames ...string) *do.Certificate {
+	return &do.Certificate{Certificate: &godo.Certificate{
+		ID: id, Name: id + "-name", State: state, NotAfter: notAfter, DNSNames: dnsNames,
+	}}
+}
+
+func TestLoadBalancerRotateCertificate(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		lb := testCertLoadBalancer("web", "old-cert")
+		tm.loadBalancers.EXPECT().Get("lb-id").Return(lb, nil)
+		tm.certificates.EXPECT().Get("new-cert").Return(testLBCertificate("new-cert", "verified", "", "*.example.com"), nil)
+		tm.certificates.EXPECT().Get("old-cert").Return(testLBCertificate("old-cert", "verified", "", "www.example.com", "example.com"), nil)
+		tm.loadBalancers.EXPECT().Update("lb-id", gomock.Any()).DoAndReturn(func(id string, r *godo.LoadBalancerRequest) (*do.LoadBalancer, error) {
+			assert.Nil(t, r.DropletIDs, "tagged load balancers must not send droplet IDs")
+			assert.Equal(t, "", r.ForwardingRules[0].CertificateID)
+			assert.Equal(t, "new-cert", r.ForwardingRules[1].CertificateID)
+			return lb, nil
+		})
+
+		config.Args = append(config.Args, "lb-id")
+		config.Doit.Set(config.NS, doctl.ArgLBCertificate, "new-cert")
+
+		require.NoError(t, RunLoadBalancerRotateCertificate(config))
+	})
+}
+
+func TestLoadBalancerRotateCertificateOldNotUsed(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.loadBalancers.EXPECT().Get("lb-id").Return(testCertLoadBalancer("", "old-cert"), nil)
+		tm.certificates.EXPECT().Get("new-cert").Return(testLBCertificate("new-cert", "verified", ""), nil)
+
+		config.Args = append(config.Args, "lb-id")
+		config.Doit.Set(config.NS, doctl.ArgLBCertificate, "new-cert")
+		config.Doit.Set(config.NS, doctl.ArgLBOldCertificate, "other-cert")
+
+		err := RunLoadBalancerRotateCertificate(config)
+		assert.EqualError(t, err, "load balancer web-lb doesn't use certificate other-cert")
+	})
+}
+
+func TestLoadBalancerRotateCertificateWaitsForIssue(t *testing.T) {
+	interval := certificatePollInterval
+	certificatePollInterval = time.Millisecond
+	defer func() { certificatePollInterval = interval }()
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		lb := testCertLoadBalancer("", "old-cert")
+		tm.loadBalancers.EXPECT().Get("lb-id").Return(lb, nil)
+		gomock.InOrder(
+			tm.certificates.EXPECT().Get("new-cert").Return(testLBCertificate("new-cert", "pending", ""), nil),
+			tm.certificates.EXPECT().Get("new-cert").Return(testLBCertificate("new-cert", "error", ""), nil),
+		)
+
+		config.Args = append(config.Args, "lb-id")
+		config.Doit.Set(config.NS, doctl.ArgLBCertificate, "new-cert")
+
+		err := RunLoadBalancerRotateCertificate(config)
+		assert.EqualError(t, err, "certificate new-cert-name could not be issued")
+	})
+}
+
+func TestLoadBalancerWatchCertificatesOnce(t *testing.T) {
+	now := lbCertificateNow
+	lbCertificateNow = func() time.Time { return time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { lbCertificateNow = now }()
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		lb := testCertLoadBalancer("", "expiring", "fresh")
+		tm.loadBalancers.EXPECT().Get("lb-id").Return(lb, nil)
+		tm.certificates.EXPECT().Get("expiring").Return(testLBCertificate("expiring", "verified", "2024-05-10T00:00:00Z", "example.com"), nil)
+		tm.certificates.EXPECT().Get("fresh").Return(testLBCertificate("fresh", "verified", "2024-08-01T00:00:00Z", "api.example.com"), nil)
+		tm.certificates.EXPECT().Create(&godo.CertificateRequest{
+			Name:     "expiring-name-20240501",
+			DNSNames: []string{"example.com"},
+			Type:     "lets_encrypt",
+		}).Return(testLBCertificate("issued", "pending", ""), nil)
+		tm.certificates.EXPECT().Get("issued").Return(testLBCertificate("issued", "verified", "2024-07-30T00:00:00Z", "example.com"), nil)
+		tm.loadBalancers.EXPECT().Update("lb-id", gomock.Any()).DoAndReturn(func(id string, r *godo.LoadBalancerRequest) (*do.LoadBalancer, error) {
+			assert.Equal(t, "issued", r.ForwardingRules[1].CertificateID)
+			assert.Equal(t, "fresh", r.ForwardingRules[2].CertificateID)
+			return lb, nil
+		})
+		tm.certificates.EXPECT().Delete("expiring").Return(nil)
+
+		config.Args = append(config.Args, "lb-id")
+		config.Doit.Set(config.NS, doctl.ArgLBRenewBefore, 30*24*time.Hour)
+		config.Doit.Set(config.NS, doctl.ArgOnce, true)
+		config.Doit.Set(config.NS, doctl.ArgLBDeleteOldCertificate, true)
+
+		require.NoError(t, RunLoadBalancerWatchCertificates(config))
+	})
+}
+
+func TestUncoveredDNSNames(t *testing.T) {
+	assert.Empty(t, uncoveredDNSNames([]string{"www.example.com"}, []string{"*.example.com"}))
+	assert.Equal(t, []string{"example.com", "a.b.example.com"},
+		uncoveredDNSNames([]string{"example.com", "a.b.example.com", "api.example.com"}, []string{"*.example.com"}))
+}
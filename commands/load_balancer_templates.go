@@ -0,0 +1,371 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/charm/confirm"
+	"github.com/digitalocean/doctl/commands/charm/input"
+	"github.com/digitalocean/doctl/commands/charm/selection"
+	"github.com/digitalocean/godo"
+	"sigs.k8s.io/yaml"
+)
+
+// lbTemplate is a starting point for a load balancer's configuration.
+type lbTemplate struct {
+	description string
+	// needsCertificate is set for templates that terminate TLS.
+	needsCertificate bool
+	build            func(certificateID string) *godo.LoadBalancerRequest
+}
+
+func lbHTTPHealthCheck() *godo.HealthCheck {
+	return &godo.HealthCheck{
+		Protocol:               "http",
+		Port:                   80,
+		Path:                   "/",
+		CheckIntervalSeconds:   10,
+		ResponseTimeoutSeconds: 5,
+		HealthyThreshold:       5,
+		UnhealthyThreshold:     3,
+	}
+}
+
+var lbTemplates = map[string]lbTemplate{
+	"http": {
+		description: "HTTP on port 80 to port 80 on the Droplets",
+		build: func(string) *godo.LoadBalancerRequest {
+			return &godo.LoadBalancerRequest{
+				ForwardingRules: []godo.ForwardingRule{
+					{EntryProtocol: "http", EntryPort: 80, TargetProtocol: "http", TargetPort: 80},
+				},
+				HealthCheck: lbHTTPHealthCheck(),
+			}
+		},
+	},
+	"sticky-http": {
+		description: "HTTP on port 80 with cookie-based sticky sessions",
+		build: func(string) *godo.LoadBalancerRequest {
+			return &godo.LoadBalancerRequest{
+				ForwardingRules: []godo.ForwardingRule{
+					{EntryProtocol: "http", EntryPort: 80, TargetProtocol: "http", TargetPort: 80},
+				},
+				HealthCheck:    lbHTTPHealthCheck(),
+				StickySessions: &godo.StickySessions{Type: "cookies", CookieName: "DO-LB", CookieTtlSeconds: 300},
+			}
+		},
+	},
+	"https-redirect": {
+		description:      "HTTPS on port 443, terminated with a certificate, to HTTP on port 80, with HTTP redirected to HTTPS",
+		needsCertificate: true,
+		build: func(certificateID string) *godo.LoadBalancerRequest {
+			return &godo.LoadBalancerRequest{
+				ForwardingRules: []godo.ForwardingRule{
+					{EntryProtocol: "http", EntryPort: 80, TargetProtocol: "http", TargetPort: 80},
+					{EntryProtocol: "https", EntryPort: 443, TargetProtocol: "http", TargetPort: 80, CertificateID: certificateID},
+				},
+				HealthCheck:         lbHTTPHealthCheck(),
+				RedirectHttpToHttps: true,
+			}
+		},
+	},
+	"https-passthrough": {
+		description: "HTTPS on port 443 passed through to the Droplets, which terminate TLS",
+		build: func(string) *godo.LoadBalancerRequest {
+			return &godo.LoadBalancerRequest{
+				ForwardingRules: []godo.ForwardingRule{
+					{EntryProtocol: "https", EntryPort: 443, TargetProtocol: "https", TargetPort: 443, TlsPassthrough: true},
+				},
+				HealthCheck: &godo.HealthCheck{
+					Protocol:               "tcp",
+					Port:                   443,
+					CheckIntervalSeconds:   10,
+					ResponseTimeoutSeconds: 5,
+					HealthyThreshold:       5,
+					UnhealthyThreshold:     3,
+				},
+			}
+		},
+	},
+}
+
+// lbTemplateNames returns the names of the load balancer templates, sorted.
+func lbTemplateNames() []string {
+	names := make([]string, 0, len(lbTemplates))
+	for name := range lbTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lbTemplatesDetail describes the templates for the create command's help.
+func lbTemplatesDetail() string {
+	var b strings.Builder
+	b.WriteString("\n\nUse `--template` to start from one of these configurations:\n\n")
+	for _, name := range lbTemplateNames() {
+		fmt.Fprintf(&b, "- `%s`: %s\n", name, lbTemplates[name].description)
+	}
+	b.WriteString("\nUse `--wizard` to be walked through the configuration instead, and `--save-spec` to keep it for reuse with `--spec`.")
+	return b.String()
+}
+
+// buildLBTemplate returns the configuration of the named template.
+func buildLBTemplate(name, certificateID string) (*godo.LoadBalancerRequest, error) {
+	t, ok := lbTemplates[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown load balancer template %q; valid templates are %s", name, strings.Join(lbTemplateNames(), ", "))
+	}
+	if t.needsCertificate && certificateID == "" {
+		return nil, fmt.Errorf("the %s template terminates TLS and needs `--%s`", name, doctl.ArgLoadBalancerCertificateID)
+	}
+	return t.build(certificateID), nil
+}
+
+// readLBSpec reads a load balancer spec, as written by --save-spec, from a
+// JSON or YAML file, or from stdin if path is "-".
+func readLBSpec(path string) (*godo.LoadBalancerRequest, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if path == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading load balancer spec: %w", err)
+	}
+
+	r := new(godo.LoadBalancerRequest)
+	if err := yaml.UnmarshalStrict(b, r); err != nil {
+		return nil, fmt.Errorf("parsing load balancer spec %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// writeLBSpec saves r as a YAML spec that --spec can create it from again.
+func writeLBSpec(path string, r *godo.LoadBalancerRequest) error {
+	b, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0644)
+}
+
+// mergeLBRequest fills in the settings of r that weren't given with the ones
+// from base. Settings given as flags take precedence over a spec or template.
+func mergeLBRequest(r, base *godo.LoadBalancerRequest) {
+	rv, bv := reflect.ValueOf(r).Elem(), reflect.ValueOf(base).Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Field(i)
+		if f.IsZero() || (f.Kind() == reflect.Pointer && f.Elem().IsZero()) {
+			if b := bv.Field(i); !b.IsZero() {
+				f.Set(b)
+			}
+		}
+	}
+}
+
+// lbPrompter asks the questions of the load balancer wizard.
+type lbPrompter interface {
+	Input(prompt, initial string, validate func(string) error) (string, error)
+	Select(prompt string, options []string) (string, error)
+	Confirm(prompt string) (bool, error)
+}
+
+type charmLBPrompter struct{}
+
+func (charmLBPrompter) Input(prompt, initial string, validate func(string) error) (string, error) {
+	opts := []input.Option{input.WithInitialValue(initial)}
+	if validate != nil {
+		opts = append(opts, input.WithValidator(validate))
+	}
+	return input.New(prompt, opts...).Prompt()
+}
+
+func (charmLBPrompter) Select(prompt string, options []string) (string, error) {
+	return selection.New(options, selection.WithPrompt(prompt), selection.WithFiltering(false)).Select()
+}
+
+func (charmLBPrompter) Confirm(prompt string) (bool, error) {
+	choice, err := confirm.New(prompt, confirm.WithDefaultChoice(confirm.Yes)).Prompt()
+	return choice == confirm.Yes, err
+}
+
+// lbPrompt is the prompter used by the wizard. It is replaced for testing.
+var lbPrompt lbPrompter = charmLBPrompter{}
+
+const lbCustomTemplate = "custom"
+
+func validatePort(s string) error {
+	if n, err := strconv.Atoi(s); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("not a port")
+	}
+	return nil
+}
+
+func validatePositive(s string) error {
+	if n, err := strconv.Atoi(s); err != nil || n < 1 {
+		return fmt.Errorf("not a positive number")
+	}
+	return nil
+}
+
+func validateRequired(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return input.ErrRequired
+	}
+	return nil
+}
+
+// runLBWizard asks for the settings of a new load balancer, starting from the
+// ones already in r.
+func runLBWizard(p lbPrompter, r *godo.LoadBalancerRequest) error {
+	var err error
+	if r.Name, err = p.Input("Name:", r.Name, validateRequired); err != nil {
+		return err
+	}
+	if r.Region, err = p.Input("Region, such as nyc1:", r.Region, validateRequired); err != nil {
+		return err
+	}
+
+	options := append([]string{lbCustomTemplate}, lbTemplateNames()...)
+	tmpl, err := p.Select("Start from a template, or configure it yourself:", options)
+	if err != nil {
+		return err
+	}
+
+	if tmpl != lbCustomTemplate {
+		certificateID := ""
+		if lbTemplates[tmpl].needsCertificate {
+			if certificateID, err = p.Input("Certificate ID:", "", validateRequired); err != nil {
+				return err
+			}
+		}
+		base, err := buildLBTemplate(tmpl, certificateID)
+		if err != nil {
+			return err
+		}
+		r.ForwardingRules, r.HealthCheck, r.StickySessions = nil, nil, nil
+		mergeLBRequest(r, base)
+	} else if err := runLBWizardCustom(p, r); err != nil {
+		return err
+	}
+
+	r.Tag, err = p.Input("Tag of the Droplets to balance (optional):", r.Tag, nil)
+	return err
+}
+
+func runLBWizardCustom(p lbPrompter, r *godo.LoadBalancerRequest) error {
+	protocols := []string{"http", "https", "http2", "http3", "tcp", "udp"}
+
+	r.ForwardingRules = nil
+	hasTLS := false
+	for {
+		var fr godo.ForwardingRule
+		var err error
+
+		if fr.EntryProtocol, err = p.Select("Entry protocol:", protocols); err != nil {
+			return err
+		}
+		if fr.EntryPort, err = promptPort(p, "Entry port:"); err != nil {
+			return err
+		}
+
+		switch fr.EntryProtocol {
+		case "https", "http2", "http3":
+			hasTLS = true
+			if fr.TlsPassthrough, err = p.Confirm("Pass TLS through to the Droplets?"); err != nil {
+				return err
+			}
+			if !fr.TlsPassthrough {
+				if fr.CertificateID, err = p.Input("Certificate ID:", "", validateRequired); err != nil {
+					return err
+				}
+			}
+		}
+
+		if fr.TargetProtocol, err = p.Select("Target protocol:", protocols); err != nil {
+			return err
+		}
+		if fr.TargetPort, err = promptPort(p, "Target port:"); err != nil {
+			return err
+		}
+		r.ForwardingRules = append(r.ForwardingRules, fr)
+
+		more, err := p.Confirm("Add another forwarding rule?")
+		if err != nil {
+			return err
+		}
+		if !more {
+			break
+		}
+	}
+
+	hc := &godo.HealthCheck{CheckIntervalSeconds: 10, ResponseTimeoutSeconds: 5, HealthyThreshold: 5, UnhealthyThreshold: 3}
+	var err error
+	if hc.Protocol, err = p.Select("Health check protocol:", []string{"http", "https", "tcp"}); err != nil {
+		return err
+	}
+	if hc.Port, err = promptPort(p, "Health check port:"); err != nil {
+		return err
+	}
+	if hc.Protocol != "tcp" {
+		if hc.Path, err = p.Input("Health check path:", "/", validateRequired); err != nil {
+			return err
+		}
+	}
+	r.HealthCheck = hc
+
+	sticky, err := p.Select("Sticky sessions:", []string{"none", "cookies"})
+	if err != nil {
+		return err
+	}
+	r.StickySessions = &godo.StickySessions{Type: sticky}
+	if sticky == "cookies" {
+		if r.StickySessions.CookieName, err = p.Input("Cookie name:", "DO-LB", validateRequired); err != nil {
+			return err
+		}
+		ttl, err := p.Input("Cookie TTL in seconds:", "300", validatePositive)
+		if err != nil {
+			return err
+		}
+		r.StickySessions.CookieTtlSeconds, _ = strconv.Atoi(ttl)
+	}
+
+	if hasTLS {
+		if r.RedirectHttpToHttps, err = p.Confirm("Redirect HTTP on port 80 to HTTPS on port 443?"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func promptPort(p lbPrompter, prompt string) (int, error) {
+	s, err := p.Input(prompt, "", validatePort)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(s)
+}
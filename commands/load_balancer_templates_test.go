@@ -0,0 +1,194 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// scriptedLBPrompter answers the wizard's questions in order.
+type scriptedLBPrompter struct {
+	t       *testing.T
+	answers []string
+}
+
+func (p *scriptedLBPrompter) next(prompt string) string {
+	require.NotEmpty(p.t, p.answers, "unexpected prompt %q", prompt)
+	a := p.answers[0]
+	p.answers = p.answers[1:]
+	return a
+}
+
+func (p *scriptedLBPrompter) Input(prompt, initial string, validate func(string) error) (string, error) {
+	a := p.next(prompt)
+	if validate != nil {
+		require.NoError(p.t, validate(a), prompt)
+	}
+	return a, nil
+}
+
+func (p *scriptedLBPrompter) Select(prompt string, options []string) (string, error) {
+	a := p.next(prompt)
+	require.Contains(p.t, options, a, prompt)
+	return a, nil
+}
+
+func (p *scriptedLBPrompter) Confirm(prompt string) (bool, error) {
+	return p.next(prompt) == "yes", nil
+}
+
+func withLBPrompter(t *testing.T, answers ...string) *scriptedLBPrompter {
+	p := &scriptedLBPrompter{t: t, answers: answers}
+	prev := lbPrompt
+	lbPrompt = p
+	t.Cleanup(func() {
+		lbPrompt = prev
+		assert.Empty(t, p.answers, "unused answers")
+	})
+	return p
+}
+
+func TestLoadBalancerCreateTemplate(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.loadBalancers.EXPECT().Create(gomock.Any()).DoAndReturn(func(r *godo.LoadBalancerRequest) (*do.LoadBalancer, error) {
+			assert.Equal(t, "web-lb", r.Name)
+			assert.True(t, r.RedirectHttpToHttps)
+			assert.Equal(t, []godo.ForwardingRule{
+				{EntryProtocol: "http", EntryPort: 80, TargetProtocol: "http", TargetPort: 80},
+				{EntryProtocol: "https", EntryPort: 443, TargetProtocol: "http", TargetPort: 80, CertificateID: "cert-1"},
+			}, r.ForwardingRules)
+			// Flags take precedence over the template.
+			assert.Equal(t, &godo.HealthCheck{Protocol: "tcp", Port: 8080}, r.HealthCheck)
+			return &testLoadBalancer, nil
+		})
+
+		config.Doit.Set(config.NS, doctl.ArgLoadBalancerName, "web-lb")
+		config.Doit.Set(config.NS, doctl.ArgRegionSlug, "nyc1")
+		config.Doit.Set(config.NS, doctl.ArgLoadBalancerTemplate, "https-redirect")
+		config.Doit.Set(config.NS, doctl.ArgLoadBalancerCertificateID, "cert-1")
+		config.Doit.Set(config.NS, doctl.ArgHealthCheck, "protocol:tcp,port:8080")
+
+		assert.NoError(t, RunLoadBalancerCreate(config))
+	})
+}
+
+func TestLoadBalancerCreateTemplateErrors(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Doit.Set(config.NS, doctl.ArgLoadBalancerName, "web-lb")
+		config.Doit.Set(config.NS, doctl.ArgLoadBalancerTemplate, "https-redirect")
+		assert.EqualError(t, RunLoadBalancerCreate(config), "the https-redirect template terminates TLS and needs `--certificate-id`")
+
+		config.Doit.Set(config.NS, doctl.ArgLoadBalancerTemplate, "grpc")
+		assert.EqualError(t, RunLoadBalancerCreate(config), `unknown load balancer template "grpc"; valid templates are http, https-passthrough, https-redirect, sticky-http`)
+
+		config.Doit.Set(config.NS, doctl.ArgLoadBalancerTemplate, "")
+		config.Doit.Set(config.NS, doctl.ArgLoadBalancerName, "")
+		assert.ErrorContains(t, RunLoadBalancerCreate(config), "A name is required.")
+	})
+}
+
+func TestLoadBalancerCreateSpec(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "lb.yaml")
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var saved *godo.LoadBalancerRequest
+		tm.loadBalancers.EXPECT().Create(gomock.Any()).DoAndReturn(func(r *godo.LoadBalancerRequest) (*do.LoadBalancer, error) {
+			saved = r
+			return &testLoadBalancer, nil
+		})
+
+		config.Doit.Set(config.NS, doctl.ArgLoadBalancerName, "web-lb")
+		config.Doit.Set(config.NS, doctl.ArgRegionSlug, "nyc1")
+		config.Doit.Set(config.NS, doctl.ArgLoadBalancerTemplate, "sticky-http")
+		config.Doit.Set(config.NS, doctl.ArgLoadBalancerSaveSpec, specPath)
+
+		require.NoError(t, RunLoadBalancerCreate(config))
+
+		spec, err := readLBSpec(specPath)
+		require.NoError(t, err)
+		// Empty lists are omitted from the spec.
+		saved.DropletIDs = nil
+		assert.Equal(t, saved, spec)
+	})
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.loadBalancers.EXPECT().Create(gomock.Any()).DoAndReturn(func(r *godo.LoadBalancerRequest) (*do.LoadBalancer, error) {
+			assert.Equal(t, "api-lb", r.Name)
+			assert.Equal(t, "nyc1", r.Region)
+			assert.Equal(t, "DO-LB", r.StickySessions.CookieName)
+			return &testLoadBalancer, nil
+		})
+
+		config.Doit.Set(config.NS, doctl.ArgLoadBalancerName, "api-lb")
+		config.Doit.Set(config.NS, doctl.ArgLoadBalancerSpec, specPath)
+
+		assert.NoError(t, RunLoadBalancerCreate(config))
+	})
+}
+
+func TestLoadBalancerCreateWizard(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		withLBPrompter(t,
+			"web-lb", "nyc3", "custom",
+			"https", "443", "no", "cert-1", "http", "8080", "no",
+			"http", "8080", "/healthz",
+			"cookies", "SESSION", "600",
+			"yes",
+			"web",
+			"yes",
+		)
+
+		tm.loadBalancers.EXPECT().Create(gomock.Any()).DoAndReturn(func(r *godo.LoadBalancerRequest) (*do.LoadBalancer, error) {
+			assert.Equal(t, "web-lb", r.Name)
+			assert.Equal(t, "nyc3", r.Region)
+			assert.Equal(t, "web", r.Tag)
+			assert.Equal(t, []godo.ForwardingRule{
+				{EntryProtocol: "https", EntryPort: 443, TargetProtocol: "http", TargetPort: 8080, CertificateID: "cert-1"},
+			}, r.ForwardingRules)
+			assert.Equal(t, "/healthz", r.HealthCheck.Path)
+			assert.Equal(t, 8080, r.HealthCheck.Port)
+			assert.Equal(t, &godo.StickySessions{Type: "cookies", CookieName: "SESSION", CookieTtlSeconds: 600}, r.StickySessions)
+			assert.True(t, r.RedirectHttpToHttps)
+			return &testLoadBalancer, nil
+		})
+
+		config.Doit.Set(config.NS, doctl.ArgLoadBalancerWizard, true)
+		assert.NoError(t, RunLoadBalancerCreate(config))
+	})
+}
+
+func TestLoadBalancerCreateWizardSpecOnly(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "lb.yaml")
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		withLBPrompter(t, "web-lb", "nyc3", "http", "", "no")
+
+		config.Doit.Set(config.NS, doctl.ArgLoadBalancerWizard, true)
+		config.Doit.Set(config.NS, doctl.ArgLoadBalancerSaveSpec, specPath)
+		require.NoError(t, RunLoadBalancerCreate(config))
+
+		spec, err := readLBSpec(specPath)
+		require.NoError(t, err)
+		assert.Equal(t, "web-lb", spec.Name)
+		assert.Equal(t, lbHTTPHealthCheck(), spec.HealthCheck)
+	})
+}
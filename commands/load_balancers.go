@@ -55,9 +55,19 @@ With the load-balancer command, you can list, create, or delete load balancers,
 		aliasOpt("g"), displayerType(&displayers.LoadBalancer{}))
 
 	cmdLoadBalancerCreate := CmdBuilder(cmd, RunLoadBalancerCreate, "create",
-		"Create a new load balancer", "Use this command to create a new load balancer on your account. Valid forwarding rules are:\n"+forwardingDetail, Writer, aliasOpt("c"))
+		"Create a new load balancer", "Use this command to create a new load balancer on your account. Valid forwarding rules are:\n"+forwardingDetail+lbTemplatesDetail(), Writer, aliasOpt("c"))
 	AddStringFlag(cmdLoadBalancerCreate, doctl.ArgLoadBalancerName, "", "",
-		"The load balancer's name", requiredOpt())
+		"The load balancer's name. Required unless it is given by `--spec` or `--wizard`")
+	AddStringFlag(cmdLoadBalancerCreate, doctl.ArgLoadBalancerTemplate, "", "",
+		"A template for the forwarding rules, health check, and sticky sessions. Possible values: `"+strings.Join(lbTemplateNames(), "`, `")+"`. Settings given as flags take precedence")
+	AddStringFlag(cmdLoadBalancerCreate, doctl.ArgLoadBalancerCertificateID, "", "",
+		"The ID of the certificate to terminate TLS with, for templates that need one")
+	AddStringFlag(cmdLoadBalancerCreate, doctl.ArgLoadBalancerSpec, "", "",
+		"Path to a load balancer spec in JSON or YAML, such as one saved with `--save-spec`, or `-` to read it from stdin. Settings given as flags take precedence")
+	AddStringFlag(cmdLoadBalancerCreate, doctl.ArgLoadBalancerSaveSpec, "", "",
+		"Save the load balancer's full spec to this path, for reuse with `--spec`")
+	AddBoolFlag(cmdLoadBalancerCreate, doctl.ArgLoadBalancerWizard, "", false,
+		"Interactively walk through the forwarding rules, health check, and sticky sessions before creating the load balancer")
 	AddStringFlag(cmdLoadBalancerCreate, doctl.ArgRegionSlug, "", "",
 		"The load balancer's region, e.g.: `nyc1`")
 	AddStringFlag(cmdLoadBalancerCreate, doctl.ArgSizeSlug, "", "",
@@ -88,6 +98,7 @@ With the load-balancer command, you can list, create, or delete load balancers,
 	AddBoolFlag(cmdLoadBalancerCreate, doctl.ArgCommandWait, "", false, "Boolean that specifies whether to wait for a load balancer to complete before returning control to the terminal")
 	AddStringFlag(cmdLoadBalancerCreate, doctl.ArgProjectID, "", "", "Indicates which project to associate the Load Balancer with. If not specified, the Load Balancer will be placed in your default project.")
 	AddIntFlag(cmdLoadBalancerCreate, doctl.ArgHTTPIdleTimeoutSeconds, "", 0, "HTTP idle timeout that configures the idle timeout for http connections on the load balancer")
+	AddStringFlag(cmdLoadBalancerCreate, doctl.ArgIdempotencyKey, "", "", "A key that identifies this request, such as a CI job ID. A load balancer already created with the key, for example by an earlier run that failed, is returned instead of created again. The load balancer is tagged with `doctl-idempotency:` followed by the key.")
 	AddStringSliceFlag(cmdLoadBalancerCreate, doctl.ArgAllowList, "", []string{},
 		"A comma-separated list of ALLOW rules for the load balancer, e.g.: `ip:1.2.3.4,cidr:1.2.0.0/16`")
 	AddStringSliceFlag(cmdLoadBalancerCreate, doctl.ArgDenyList, "", []string{},
@@ -186,6 +197,8 @@ With the load-balancer command, you can list, create, or delete load balancers,
 		"Purge the global load balancer CDN cache without a confirmation prompt "+
 			"(NOTE: this is a closed beta feature, contact DigitalOcean support to review its public availability.)")
 
+	addLoadBalancerCertificateCommands(cmd)
+
 	return cmd
 }
 
@@ -226,8 +239,88 @@ func RunLoadBalancerCreate(c *CmdConfig) error {
 		return err
 	}
 
+	specPath, err := c.Doit.GetString(c.NS, doctl.ArgLoadBalancerSpec)
+	if err != nil {
+		return err
+	}
+	if specPath != "" {
+		spec, err := readLBSpec(specPath)
+		if err != nil {
+			return err
+		}
+		mergeLBRequest(r, spec)
+	}
+
+	tmpl, err := c.Doit.GetString(c.NS, doctl.ArgLoadBalancerTemplate)
+	if err != nil {
+		return err
+	}
+	if tmpl != "" {
+		certificateID, err := c.Doit.GetString(c.NS, doctl.ArgLoadBalancerCertificateID)
+		if err != nil {
+			return err
+		}
+		base, err := buildLBTemplate(tmpl, certificateID)
+		if err != nil {
+			return err
+		}
+		mergeLBRequest(r, base)
+	}
+
+	wizard, err := c.Doit.GetBool(c.NS, doctl.ArgLoadBalancerWizard)
+	if err != nil {
+		return err
+	}
+	if wizard {
+		if err := runLBWizard(lbPrompt, r); err != nil {
+			return err
+		}
+	}
+
+	if r.Name == "" {
+		return fmt.Errorf("A name is required. Use `--%s`, `--%s`, or `--%s`.", doctl.ArgLoadBalancerName, doctl.ArgLoadBalancerSpec, doctl.ArgLoadBalancerWizard)
+	}
+	if r.Region == "" && !strings.EqualFold(r.Type, "GLOBAL") {
+		region, err := defaultRegion(c)
+		if err != nil {
+			return err
+		}
+		r.Region = region
+	}
+
+	savePath, err := c.Doit.GetString(c.NS, doctl.ArgLoadBalancerSaveSpec)
+	if err != nil {
+		return err
+	}
+	if savePath != "" {
+		if err := writeLBSpec(savePath, r); err != nil {
+			return err
+		}
+		notice("Load balancer spec saved to %s", savePath)
+	}
+
+	if wizard {
+		create, err := lbPrompt.Confirm("Create the load balancer now?")
+		if err != nil || !create {
+			return err
+		}
+	}
+
+	idem, err := getIdempotency(c)
+	if err != nil {
+		return err
+	}
+	r.Tags = idem.tags(r.Tags)
+	withoutAutomaticRetries(c, idem, func(godoClient *godo.Client) {
+		c.LoadBalancers = func() do.LoadBalancersService { return do.NewLoadBalancersService(godoClient) }
+	})
+
 	lbs := c.LoadBalancers()
-	lb, err := lbs.Create(r)
+	lb, err := idempotentCreate(idem, func() (*do.LoadBalancer, error) {
+		return findCreatedLoadBalancer(lbs, idem, r)
+	}, func() (*do.LoadBalancer, error) {
+		return lbs.Create(r)
+	})
 	if err != nil {
 		return err
 	}
@@ -258,6 +351,26 @@ func RunLoadBalancerCreate(c *CmdConfig) error {
 	return c.Display(item)
 }
 
+// findCreatedLoadBalancer looks for a load balancer already created for r
+// with the idempotency key, such as by a create that failed after the load
+// balancer was made.
+func findCreatedLoadBalancer(lbs do.LoadBalancersService, idem idempotency, r *godo.LoadBalancerRequest) (*do.LoadBalancer, error) {
+	list, err := lbs.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, lb := range list {
+		if lb.Name != r.Name || (r.Region != "" && lb.Region != nil && lb.Region.Slug != r.Region) {
+			continue
+		}
+		if idem.owns(lb.Tags) {
+			notice("Load balancer %s was already created, with ID %s", lb.Name, lb.ID)
+			return &lb, nil
+		}
+	}
+	return nil, nil
+}
+
 // RunLoadBalancerUpdate updates an existing load balancer with new configuration.
 func RunLoadBalancerUpdate(c *CmdConfig) error {
 	if len(c.Args) == 0 {
@@ -293,7 +406,7 @@ func RunLoadBalancerDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if force || AskForConfirmDelete("load balancer", 1) == nil {
+	if force || AskForConfirmDelete("load balancer", 1, lbID) == nil {
 		lbs := c.LoadBalancers()
 		if err := lbs.Delete(lbID); err != nil {
 			return err
@@ -510,7 +623,10 @@ func buildRequestFromArgs(c *CmdConfig, r *godo.LoadBalancerRequest) error {
 	if err != nil {
 		return err
 	}
-	r.Region = region
+	r.Region, err = resolveRegionAlias(c, region)
+	if err != nil {
+		return err
+	}
 
 	size, err := c.Doit.GetString(c.NS, doctl.ArgSizeSlug)
 	if err != nil {
@@ -732,7 +848,7 @@ func waitForActiveLoadBalancer(lbs do.LoadBalancersService, lbID string) error {
 		time.Sleep(10 * time.Second)
 	}
 
-	return fmt.Errorf(
+	return errTimeout(
 		"timeout waiting for load balancer (%s) to become active",
 		lbID,
 	)
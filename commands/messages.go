@@ -0,0 +1,133 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/charm"
+	charmtemplate "github.com/digitalocean/doctl/commands/charm/template"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
+)
+
+// messageCatalogFile is the file of the messages directory holding the customizations that apply in every language.
+const messageCatalogFile = "messages.yaml"
+
+// messageCatalog maps the English text of doctl's human-readable output to the text to show instead. It is empty
+// unless the messages directory has catalogs.
+var messageCatalog = map[string]string{}
+
+// localize returns the text to show for s: its entry in the message catalog, or s itself.
+func localize(s string) string {
+	if t, ok := messageCatalog[s]; ok {
+		return t
+	}
+	return s
+}
+
+// messagesDir returns the directory of message catalogs and output templates.
+func messagesDir() string {
+	if dir := viper.GetString(doctl.ArgMessagesDir); dir != "" {
+		return dir
+	}
+	return filepath.Join(defaultConfigHome(), "messages")
+}
+
+// messageLanguages returns the catalogs to read for the user's locale, from the least to the most specific: for
+// example, fr and fr_FR for a locale of fr_FR.UTF-8.
+func messageLanguages() []string {
+	var locale string
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale = os.Getenv(env); locale != "" {
+			break
+		}
+	}
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return nil
+	}
+	lang, _, hasRegion := strings.Cut(locale, "_")
+	if !hasRegion {
+		return []string{lang}
+	}
+	return []string{lang, locale}
+}
+
+// loadMessageCatalog reads the message catalogs of dir: messages.yaml, then a catalog for each language, whose
+// entries take precedence. Missing catalogs are skipped.
+func loadMessageCatalog(dir string, languages []string) (map[string]string, error) {
+	catalog := map[string]string{}
+	files := []string{messageCatalogFile}
+	for _, lang := range languages {
+		files = append(files, lang+".yaml")
+	}
+	for _, name := range files {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries := map[string]string{}
+		if err := yaml.Unmarshal(b, &entries); err != nil {
+			return nil, fmt.Errorf("reading message catalog %s: %w", filepath.Join(dir, name), err)
+		}
+		for k, v := range entries {
+			catalog[k] = v
+		}
+	}
+	return catalog, nil
+}
+
+// initMessages loads the message catalogs and routes the text of templates and column headers through them.
+func initMessages() {
+	catalog, err := loadMessageCatalog(messagesDir(), messageLanguages())
+	if err != nil {
+		warn("Showing doctl's own messages: %v", err)
+		catalog = map[string]string{}
+	}
+	messageCatalog = catalog
+	charmtemplate.Translate = localize
+	displayers.Localize = localize
+}
+
+// outputTemplate returns the template overriding the text output of the command with namespace ns, if the messages
+// directory has one: templates/<ns>.tmpl, such as templates/droplet.list.tmpl.
+func outputTemplate(ns string) (*template.Template, error) {
+	path := filepath.Join(messagesDir(), "templates", ns+".tmpl")
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	funcs := charmtemplate.Funcs(charm.Colors)
+	funcs["localize"] = localize
+	tmpl, err := template.New(filepath.Base(path)).Funcs(funcs).Parse(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("reading output template %s: %w", path, err)
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageLanguages(t *testing.T) {
+	tests := []struct {
+		all, messages, lang string
+		expected            []string
+	}{
+		{lang: "fr_FR.UTF-8", expected: []string{"fr", "fr_FR"}},
+		{lang: "de", expected: []string{"de"}},
+		{lang: "sr_RS@latin", expected: []string{"sr", "sr_RS"}},
+		{messages: "es_MX.UTF-8", lang: "fr_FR.UTF-8", expected: []string{"es", "es_MX"}},
+		{all: "ja_JP", messages: "es_MX", lang: "fr_FR", expected: []string{"ja", "ja_JP"}},
+		{lang: "C.UTF-8"},
+		{lang: "POSIX"},
+		{},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.all)
+		t.Setenv("LC_MESSAGES", tt.messages)
+		t.Setenv("LANG", tt.lang)
+		assert.Equal(t, tt.expected, messageLanguages())
+	}
+}
+
+func TestLoadMessageCatalog(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, messageCatalogFile), []byte("Error: Failure\nWarning: Caution\nNotice: Note\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fr.yaml"), []byte("Error: Erreur\nWarning: Avertissement\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fr_CA.yaml"), []byte("Warning: Mise en garde\n"), 0644))
+
+	catalog, err := loadMessageCatalog(dir, []string{"fr", "fr_CA"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Error": "Erreur", "Warning": "Mise en garde", "Notice": "Note"}, catalog)
+
+	catalog, err = loadMessageCatalog(dir, []string{"de", "de_DE"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Error": "Failure", "Warning": "Caution", "Notice": "Note"}, catalog)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "es.yaml"), []byte("- not a catalog\n"), 0644))
+	_, err = loadMessageCatalog(dir, []string{"es"})
+	assert.Error(t, err)
+}
+
+func TestLocalize(t *testing.T) {
+	orig := messageCatalog
+	t.Cleanup(func() { messageCatalog = orig })
+	messageCatalog = map[string]string{"Warning": "Avertissement"}
+
+	assert.Equal(t, "Avertissement", localize("Warning"))
+	assert.Equal(t, "Error", localize("Error"))
+}
+
+func TestOutputTemplate(t *testing.T) {
+	dir := t.TempDir()
+	viper.Set(doctl.ArgMessagesDir, dir)
+	t.Cleanup(func() { viper.Set(doctl.ArgMessagesDir, "") })
+
+	tmpl, err := outputTemplate("droplet.list")
+	require.NoError(t, err)
+	assert.Nil(t, tmpl)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "templates"), 0755))
+	path := filepath.Join(dir, "templates", "droplet.list.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(`{{range .Rows}}{{localize "Droplet"}} {{.Name}}{{"\n"}}{{end}}`), 0644))
+
+	orig := messageCatalog
+	t.Cleanup(func() { messageCatalog = orig })
+	messageCatalog = map[string]string{"Droplet": "Serveur"}
+
+	tmpl, err = outputTemplate("droplet.list")
+	require.NoError(t, err)
+	require.NotNil(t, tmpl)
+
+	var buf bytes.Buffer
+	require.NoError(t, tmpl.Execute(&buf, map[string]any{"Rows": []map[string]any{{"Name": "web-1"}}}))
+	assert.Equal(t, "Serveur web-1\n", buf.String())
+
+	require.NoError(t, os.WriteFile(path, []byte(`{{range .Rows}`), 0644))
+	_, err = outputTemplate("droplet.list")
+	assert.Error(t, err)
+}
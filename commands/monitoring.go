@@ -430,7 +430,7 @@ func RunCmdAlertPolicyDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if force || AskForConfirmDelete("alert policy", len(c.Args)) == nil {
+	if force || AskForConfirmDelete("alert policy", len(c.Args), c.Args...) == nil {
 		for id := range c.Args {
 			uuid := c.Args[id]
 			ms := c.Monitoring()
@@ -70,6 +70,23 @@ You are prompted for confirmation unless `+"`"+`--force`+"`"+` is specified.`,
 		`Use `+"`"+`doctl serverless namespaces list-regions`+"`"+` to list the values that are accepted
 in the `+"`"+`--region`+"`"+` flag of `+"`"+`doctl serverless namespaces create`+"`"+`.`,
 		Writer)
+
+	export := CmdBuilder(cmd, RunNamespacesExport, "export", "Exports the connected namespace to a file",
+		`Use `+"`"+`doctl serverless namespaces export`+"`"+` to back up the functions namespace you are connected to.
+The packages, functions (code and configuration), triggers, and rules of the namespace are written to a gzipped tar file,
+which `+"`"+`doctl serverless namespaces import`+"`"+` restores into the same or another namespace.
+The file contains the parameters of your functions, which may include secrets, and is only readable by you.`,
+		Writer)
+	AddStringFlag(export, doctl.ArgOutput, "", "", "the file to write the export to", requiredOpt())
+	export.Example = `The following example exports the connected namespace to ` + "`" + `ns-backup.tar.gz` + "`" + `: doctl serverless namespaces export --output ns-backup.tar.gz`
+
+	importCmd := CmdBuilder(cmd, RunNamespacesImport, "import <file>", "Imports an export into the connected namespace",
+		`Use `+"`"+`doctl serverless namespaces import`+"`"+` to restore a file written by `+"`"+`doctl serverless namespaces export`+"`"+`
+into the functions namespace you are connected to, which may belong to another account or region.
+Packages, functions, triggers, and rules with the same names as imported ones are replaced; nothing else in the namespace is changed.`,
+		Writer)
+	AddBoolFlag(importCmd, doctl.ArgServerlessDisableTriggers, "", false, "import triggers disabled, e.g. while the original namespace is still running them")
+	importCmd.Example = `The following example migrates the functions of one namespace to another: doctl serverless connect old-ns && doctl serverless namespaces export --output ns-backup.tar.gz && doctl serverless connect new-ns && doctl serverless namespaces import ns-backup.tar.gz`
 	return cmd
 }
 
@@ -144,7 +161,7 @@ func RunNamespacesDelete(c *CmdConfig) error {
 	force, _ := c.Doit.GetBool(c.NS, "force")
 	if !force {
 		fmt.Fprintf(c.Out, "Deleting namespace '%s' with label '%s'.\n", id, label)
-		if AskForConfirmDelete("namespace", 1) != nil {
+		if AskForConfirmDelete("namespace", 1, id) != nil {
 			return fmt.Errorf("deletion of '%s' not confirmed, doing nothing", id)
 		}
 	}
@@ -0,0 +1,459 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/apache/openwhisk-client-go/whisk"
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+)
+
+const (
+	// namespaceExportVersion is the version of the namespace export archive format.
+	namespaceExportVersion = 1
+	// namespaceExportManifest is the name of the archive entry describing the namespace.
+	namespaceExportManifest = "namespace.json"
+	// namespaceExportPageSize is the largest page of functions the API returns.
+	namespaceExportPageSize = 200
+)
+
+// namespaceExport is the manifest of a namespace export archive. Function code is stored
+// in separate archive entries, named by the Code member of each function.
+type namespaceExport struct {
+	Version    int                `json:"version"`
+	Namespace  string             `json:"namespace"`
+	Label      string             `json:"label,omitempty"`
+	APIHost    string             `json:"api_host"`
+	ExportedAt time.Time          `json:"exported_at"`
+	Packages   []exportedPackage  `json:"packages"`
+	Functions  []exportedFunction `json:"functions"`
+	Triggers   []exportedTrigger  `json:"triggers"`
+	Rules      []exportedRule     `json:"rules"`
+}
+
+type exportedPackage struct {
+	Name        string            `json:"name"`
+	Publish     *bool             `json:"publish,omitempty"`
+	Annotations whisk.KeyValueArr `json:"annotations,omitempty"`
+	Parameters  whisk.KeyValueArr `json:"parameters,omitempty"`
+}
+
+type exportedFunction struct {
+	functionMetadata
+	// Code is the archive entry holding the function's code; empty for sequences.
+	Code string `json:"code,omitempty"`
+	// Components are the functions of a sequence.
+	Components []string `json:"components,omitempty"`
+	// Environment lists the parameters that are environment variables.
+	Environment []string `json:"environment,omitempty"`
+}
+
+type exportedTrigger struct {
+	Name             string                      `json:"name"`
+	Function         string                      `json:"function"`
+	Type             string                      `json:"type"`
+	IsEnabled        bool                        `json:"is_enabled"`
+	ScheduledDetails *do.TriggerScheduledDetails `json:"scheduled_details,omitempty"`
+}
+
+type exportedRule struct {
+	Name     string `json:"name"`
+	Trigger  string `json:"trigger"`
+	Function string `json:"function"`
+	Status   string `json:"status,omitempty"`
+}
+
+// RunNamespacesExport supports the 'serverless namespaces export' command
+func RunNamespacesExport(c *CmdConfig) error {
+	if len(c.Args) > 0 {
+		return doctl.NewTooManyArgsErr(c.NS)
+	}
+	output, _ := c.Doit.GetString(c.NS, doctl.ArgOutput)
+	if output == "" {
+		return errors.New("the '--output' flag is required")
+	}
+	ss := c.Serverless()
+	if err := ss.CheckServerlessStatus(); err != nil {
+		return err
+	}
+	creds, err := ss.ReadCredentials()
+	if err != nil {
+		return err
+	}
+	export := namespaceExport{
+		Version:    namespaceExportVersion,
+		Namespace:  creds.Namespace,
+		Label:      creds.Label,
+		APIHost:    creds.APIHost,
+		ExportedAt: time.Now().UTC(),
+	}
+	code := map[string][]byte{}
+
+	pkgs, err := ss.ListPackages()
+	if err != nil {
+		return err
+	}
+	for _, listed := range pkgs {
+		pkg, err := ss.GetPackage(listed.Name)
+		if err != nil {
+			return err
+		}
+		export.Packages = append(export.Packages, exportedPackage{
+			Name:        pkg.Name,
+			Publish:     pkg.Publish,
+			Annotations: pkg.Annotations,
+			Parameters:  pkg.Parameters,
+		})
+	}
+
+	names, err := listAllFunctions(ss)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		fn, err := exportFunction(ss, name, code)
+		if err != nil {
+			return err
+		}
+		export.Functions = append(export.Functions, fn)
+	}
+
+	// Intentionally ignore errors when listing triggers, the trigger API is behind a
+	// feature flag and will return an error for users not enabled.
+	triggers, err := ss.ListTriggers(context.TODO(), "")
+	if err == nil {
+		for _, trig := range triggers {
+			export.Triggers = append(export.Triggers, exportedTrigger{
+				Name:             trig.Name,
+				Function:         trig.Function,
+				Type:             trig.Type,
+				IsEnabled:        trig.IsEnabled,
+				ScheduledDetails: trig.ScheduledDetails,
+			})
+		}
+	}
+
+	rules, err := ss.ListRules()
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		export.Rules = append(export.Rules, exportedRule{
+			Name:     rule.Name,
+			Trigger:  ruleEntityName(rule.Trigger),
+			Function: ruleEntityName(rule.Action),
+			Status:   rule.Status,
+		})
+	}
+
+	if err := writeNamespaceExport(output, export, code); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "Exported %d packages, %d functions, %d triggers and %d rules of namespace '%s' to %s\n",
+		len(export.Packages), len(export.Functions), len(export.Triggers), len(export.Rules), export.Namespace, output)
+	return nil
+}
+
+// exportFunction retrieves a function with its code, adding the code to the archive entries.
+func exportFunction(ss do.ServerlessService, name string, code map[string][]byte) (exportedFunction, error) {
+	action, parms, err := ss.GetFunction(name, true)
+	if err != nil {
+		return exportedFunction{}, err
+	}
+	action.Annotations = withoutAnnotations(action.Annotations, "exec")
+	fn := exportedFunction{functionMetadata: newFunctionMetadata(action)}
+	for _, parm := range parms {
+		if parm.Init {
+			fn.Environment = append(fn.Environment, parm.Key)
+		}
+	}
+	if action.Exec == nil {
+		return fn, nil
+	}
+	if action.Exec.Kind == "sequence" {
+		fn.Components = action.Exec.Components
+		return fn, nil
+	}
+	if action.Exec.Code == nil {
+		return exportedFunction{}, fmt.Errorf("the code of function '%s' could not be retrieved", name)
+	}
+	entry := "functions/" + name + fileExtensionForKind(action.Exec.Kind)
+	contents := []byte(*action.Exec.Code)
+	if fn.Binary {
+		entry = "functions/" + name + ".zip"
+		contents, err = base64.StdEncoding.DecodeString(*action.Exec.Code)
+		if err != nil {
+			return exportedFunction{}, err
+		}
+	}
+	fn.Code = entry
+	code[entry] = contents
+	return fn, nil
+}
+
+// listAllFunctions returns the package-qualified names of all the functions of a namespace.
+func listAllFunctions(ss do.ServerlessService) ([]string, error) {
+	names := []string{}
+	for skip := 0; ; skip += namespaceExportPageSize {
+		fns, err := ss.ListFunctions("", skip, namespaceExportPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, fn := range fns {
+			names = append(names, qualifiedFunctionName(fn))
+		}
+		if len(fns) < namespaceExportPageSize {
+			return names, nil
+		}
+	}
+}
+
+// qualifiedFunctionName returns the package-qualified name of a listed function.
+func qualifiedFunctionName(action whisk.Action) string {
+	if parts := strings.SplitN(action.Namespace, "/", 2); len(parts) == 2 {
+		return parts[1] + "/" + action.Name
+	}
+	return action.Name
+}
+
+// ruleEntityName returns the package-qualified name of the trigger or function of a rule,
+// which the API returns as an object with a path (namespace and package) and a name.
+func ruleEntityName(entity any) string {
+	switch e := entity.(type) {
+	case string:
+		return e
+	case map[string]any:
+		name, _ := e["name"].(string)
+		path, _ := e["path"].(string)
+		if parts := strings.SplitN(path, "/", 2); len(parts) == 2 {
+			return parts[1] + "/" + name
+		}
+		return name
+	}
+	return ""
+}
+
+// writeNamespaceExport writes the manifest and code of an export as a gzipped tar file. The
+// file may contain secrets passed as parameters, so it is only readable by the current user.
+func writeNamespaceExport(path string, export namespaceExport, code map[string][]byte) error {
+	manifest, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	write := func(name string, contents []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0600, Size: int64(len(contents)), ModTime: export.ExportedAt}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(contents)
+		return err
+	}
+	if err := write(namespaceExportManifest, append(manifest, '\n')); err != nil {
+		return err
+	}
+	for _, fn := range export.Functions {
+		if fn.Code == "" {
+			continue
+		}
+		if err := write(fn.Code, code[fn.Code]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// RunNamespacesImport supports the 'serverless namespaces import' command
+func RunNamespacesImport(c *CmdConfig) error {
+	if err := ensureOneArg(c); err != nil {
+		return err
+	}
+	disableTriggers, _ := c.Doit.GetBool(c.NS, doctl.ArgServerlessDisableTriggers)
+	export, code, err := readNamespaceExport(c.Args[0])
+	if err != nil {
+		return err
+	}
+	ss := c.Serverless()
+	if err := ss.CheckServerlessStatus(); err != nil {
+		return err
+	}
+	creds, err := ss.ReadCredentials()
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range export.Packages {
+		_, err := ss.PutPackage(whisk.Package{
+			Name:        pkg.Name,
+			Publish:     pkg.Publish,
+			Annotations: pkg.Annotations,
+			Parameters:  pkg.Parameters,
+		})
+		if err != nil {
+			return fmt.Errorf("importing package '%s': %w", pkg.Name, err)
+		}
+	}
+
+	for _, fn := range export.Functions {
+		action, err := importedAction(fn, code)
+		if err != nil {
+			return err
+		}
+		if _, err := ss.PutFunction(action); err != nil {
+			return fmt.Errorf("importing function '%s': %w", action.Name, err)
+		}
+		if len(fn.Environment) > 0 {
+			warn("The environment variables of function '%s' (%s) were imported as parameters", action.Name, strings.Join(fn.Environment, ", "))
+		}
+	}
+
+	if len(export.Triggers) > 0 {
+		ctx := context.TODO()
+		existing, err := ss.ListTriggers(ctx, "")
+		if err != nil {
+			return err
+		}
+		exists := map[string]bool{}
+		for _, trig := range existing {
+			exists[trig.Name] = true
+		}
+		for _, trig := range export.Triggers {
+			enabled := trig.IsEnabled && !disableTriggers
+			if exists[trig.Name] {
+				_, err = ss.UpdateTrigger(ctx, trig.Name, &do.UpdateTriggerRequest{IsEnabled: enabled, ScheduledDetails: trig.ScheduledDetails})
+			} else {
+				_, err = ss.CreateTrigger(ctx, &do.CreateTriggerRequest{
+					Name:             trig.Name,
+					Type:             trig.Type,
+					Function:         trig.Function,
+					IsEnabled:        enabled,
+					ScheduledDetails: trig.ScheduledDetails,
+				})
+			}
+			if err != nil {
+				return fmt.Errorf("importing trigger '%s': %w", trig.Name, err)
+			}
+		}
+	}
+
+	for _, rule := range export.Rules {
+		_, err := ss.PutRule(whisk.Rule{
+			Name:    rule.Name,
+			Trigger: "/_/" + rule.Trigger,
+			Action:  "/_/" + rule.Function,
+			Status:  rule.Status,
+		})
+		if err != nil {
+			return fmt.Errorf("importing rule '%s': %w", rule.Name, err)
+		}
+	}
+
+	fmt.Fprintf(c.Out, "Imported %d packages, %d functions, %d triggers and %d rules into namespace '%s'\n",
+		len(export.Packages), len(export.Functions), len(export.Triggers), len(export.Rules), creds.Namespace)
+	return nil
+}
+
+// importedAction rebuilds a function from its exported metadata and code.
+func importedAction(fn exportedFunction, code map[string][]byte) (whisk.Action, error) {
+	name := fn.Name
+	if fn.Package != "" {
+		name = fn.Package + "/" + fn.Name
+	}
+	exec := &whisk.Exec{Kind: fn.Kind, Main: fn.Main}
+	if fn.Kind == "sequence" {
+		exec.Components = fn.Components
+	} else {
+		contents, ok := code[fn.Code]
+		if !ok {
+			return whisk.Action{}, fmt.Errorf("the archive has no code for function '%s'", name)
+		}
+		source := string(contents)
+		if fn.Binary {
+			source = base64.StdEncoding.EncodeToString(contents)
+			exec.Binary = &fn.Binary
+		}
+		exec.Code = &source
+	}
+	return whisk.Action{
+		Name:        name,
+		Exec:        exec,
+		Limits:      fn.Limits,
+		Annotations: fn.Annotations,
+		Parameters:  fn.Parameters,
+	}, nil
+}
+
+// readNamespaceExport reads the manifest and code entries of a namespace export archive.
+func readNamespaceExport(path string) (namespaceExport, map[string][]byte, error) {
+	var export namespaceExport
+	f, err := os.Open(path)
+	if err != nil {
+		return export, nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return export, nil, fmt.Errorf("%s is not a namespace export: %w", path, err)
+	}
+	defer gz.Close()
+
+	entries := map[string][]byte{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return export, nil, err
+		}
+		contents, err := io.ReadAll(tr)
+		if err != nil {
+			return export, nil, err
+		}
+		entries[hdr.Name] = contents
+	}
+	manifest, ok := entries[namespaceExportManifest]
+	if !ok {
+		return export, nil, fmt.Errorf("%s is not a namespace export: %s is missing", path, namespaceExportManifest)
+	}
+	if err := json.Unmarshal(manifest, &export); err != nil {
+		return export, nil, err
+	}
+	if export.Version != namespaceExportVersion {
+		return export, nil, fmt.Errorf("%s has unsupported export version %d", path, export.Version)
+	}
+	return export, entries, nil
+}
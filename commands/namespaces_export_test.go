@@ -0,0 +1,143 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/openwhisk-client-go/whisk"
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNamespacesExportImport(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "ns-backup.tar.gz")
+	source := "function main() {}"
+	zipped := base64.StdEncoding.EncodeToString([]byte("PK zip bytes"))
+	binary := true
+	schedule := &do.TriggerScheduledDetails{Cron: "* * * * *"}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		buf := &bytes.Buffer{}
+		config.Out = buf
+		config.Doit.Set(config.NS, doctl.ArgOutput, archive)
+
+		tm.serverless.EXPECT().CheckServerlessStatus().Return(nil)
+		tm.serverless.EXPECT().ReadCredentials().Return(do.ServerlessCredentials{Namespace: "fn-old", APIHost: "https://old.example.com"}, nil)
+		tm.serverless.EXPECT().ListPackages().Return([]whisk.Package{{Name: "sample"}}, nil)
+		tm.serverless.EXPECT().GetPackage("sample").Return(whisk.Package{
+			Name:       "sample",
+			Parameters: whisk.KeyValueArr{{Key: "shared", Value: "yes"}},
+		}, nil)
+		tm.serverless.EXPECT().ListFunctions("", 0, 200).Return([]whisk.Action{
+			{Name: "hello", Namespace: "fn-old/sample"},
+			{Name: "bundle", Namespace: "fn-old"},
+		}, nil)
+		tm.serverless.EXPECT().GetFunction("sample/hello", true).Return(whisk.Action{
+			Name:        "hello",
+			Namespace:   "fn-old/sample",
+			Exec:        &whisk.Exec{Kind: "nodejs:18", Code: &source},
+			Annotations: whisk.KeyValueArr{{Key: "web-export", Value: true}, {Key: "exec", Value: "nodejs:18"}},
+			Parameters:  whisk.KeyValueArr{{Key: "TOKEN", Value: "secret"}},
+		}, []do.FunctionParameter{{Key: "TOKEN", Value: "secret", Init: true}}, nil)
+		tm.serverless.EXPECT().GetFunction("bundle", true).Return(whisk.Action{
+			Name:      "bundle",
+			Namespace: "fn-old",
+			Exec:      &whisk.Exec{Kind: "python:3.11", Main: "run", Code: &zipped, Binary: &binary},
+		}, nil, nil)
+		tm.serverless.EXPECT().ListTriggers(context.TODO(), "").Return([]do.ServerlessTrigger{
+			{Name: "every-minute", Function: "sample/hello", Type: "SCHEDULED", IsEnabled: true, ScheduledDetails: schedule},
+		}, nil)
+		tm.serverless.EXPECT().ListRules().Return([]whisk.Rule{{
+			Name:    "on-event",
+			Trigger: map[string]any{"name": "event", "path": "fn-old"},
+			Action:  map[string]any{"name": "hello", "path": "fn-old/sample"},
+			Status:  "active",
+		}}, nil)
+
+		require.NoError(t, RunNamespacesExport(config))
+		assert.Equal(t, "Exported 1 packages, 2 functions, 1 triggers and 1 rules of namespace 'fn-old' to "+archive+"\n", buf.String())
+
+		info, err := os.Stat(archive)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		buf := &bytes.Buffer{}
+		config.Out = buf
+		config.Args = []string{archive}
+		config.Doit.Set(config.NS, doctl.ArgServerlessDisableTriggers, true)
+
+		tm.serverless.EXPECT().CheckServerlessStatus().Return(nil)
+		tm.serverless.EXPECT().ReadCredentials().Return(do.ServerlessCredentials{Namespace: "fn-new"}, nil)
+		tm.serverless.EXPECT().PutPackage(whisk.Package{
+			Name:       "sample",
+			Parameters: whisk.KeyValueArr{{Key: "shared", Value: "yes"}},
+		}).Return(whisk.Package{}, nil)
+
+		var put []whisk.Action
+		tm.serverless.EXPECT().PutFunction(gomock.Any()).Times(2).DoAndReturn(func(a whisk.Action) (whisk.Action, error) {
+			put = append(put, a)
+			return a, nil
+		})
+		tm.serverless.EXPECT().ListTriggers(context.TODO(), "").Return(nil, nil)
+		tm.serverless.EXPECT().CreateTrigger(context.TODO(), &do.CreateTriggerRequest{
+			Name:             "every-minute",
+			Type:             "SCHEDULED",
+			Function:         "sample/hello",
+			IsEnabled:        false,
+			ScheduledDetails: schedule,
+		}).Return(do.ServerlessTrigger{}, nil)
+		tm.serverless.EXPECT().PutRule(whisk.Rule{
+			Name:    "on-event",
+			Trigger: "/_/event",
+			Action:  "/_/sample/hello",
+			Status:  "active",
+		}).Return(whisk.Rule{}, nil)
+
+		require.NoError(t, RunNamespacesImport(config))
+		assert.Equal(t, "Imported 1 packages, 2 functions, 1 triggers and 1 rules into namespace 'fn-new'\n", buf.String())
+
+		require.Len(t, put, 2)
+		assert.Equal(t, "sample/hello", put[0].Name)
+		assert.Equal(t, source, *put[0].Exec.Code)
+		assert.Equal(t, whisk.KeyValueArr{{Key: "web-export", Value: true}}, put[0].Annotations)
+		assert.Equal(t, whisk.KeyValueArr{{Key: "TOKEN", Value: "secret"}}, put[0].Parameters)
+
+		assert.Equal(t, "bundle", put[1].Name)
+		assert.Equal(t, "run", put[1].Exec.Main)
+		assert.Equal(t, zipped, *put[1].Exec.Code)
+		assert.True(t, *put[1].Exec.Binary)
+	})
+}
+
+func TestNamespacesImportInvalidFile(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		notArchive := filepath.Join(t.TempDir(), "ns-backup.tar.gz")
+		require.NoError(t, os.WriteFile(notArchive, []byte("not an archive"), 0600))
+		config.Args = []string{notArchive}
+
+		err := RunNamespacesImport(config)
+		assert.ErrorContains(t, err, "is not a namespace export")
+	})
+}
@@ -27,7 +27,7 @@ import (
 func TestNamespacesCommand(t *testing.T) {
 	cmd := Namespaces()
 	assert.NotNil(t, cmd)
-	expected := []string{"create", "delete", "list", "list-regions"}
+	expected := []string{"create", "delete", "export", "import", "list", "list-regions"}
 
 	names := []string{}
 	for _, c := range cmd.Commands() {
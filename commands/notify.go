@@ -0,0 +1,98 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/pkg/notify"
+)
+
+// notifyTimeout is how long sending the notifications of a command may take.
+const notifyTimeout = 15 * time.Second
+
+// notifyOut is where failures to send notifications are reported.
+var notifyOut io.Writer = os.Stderr
+
+// notifyOpt adds the --notify flag to a long-running command. When it is
+// set, a notification with a summary of the command is sent when it ends,
+// whether it succeeded or not.
+func notifyOpt() cmdOption {
+	return func(c *Command) {
+		AddStringSliceFlag(c, doctl.ArgNotify, "", nil,
+			"Send a notification when the command ends to a `target`: slack://hooks.slack.com/services/... for a Slack incoming webhook, webhook://host/path for a JSON POST to https://host/path, or desktop. Repeat the flag to send more than one")
+		run := c.runner
+		c.runner = func(config *CmdConfig) error {
+			return runWithNotify(c, config, run)
+		}
+	}
+}
+
+// runWithNotify runs a command, and sends the notifications set with
+// --notify when it ends.
+func runWithNotify(c *Command, config *CmdConfig, run CmdRunner) error {
+	specs, err := config.Doit.GetStringSlice(config.NS, doctl.ArgNotify)
+	if err != nil {
+		return err
+	}
+	if len(specs) == 0 {
+		return run(config)
+	}
+	// Bad specs are reported before the command runs, not after it ends.
+	var notifiers notify.Multi
+	for _, spec := range specs {
+		n, err := notify.Parse(spec)
+		if err != nil {
+			return err
+		}
+		notifiers = append(notifiers, n)
+	}
+
+	started := time.Now()
+	err = run(config)
+
+	e := notify.Event{
+		Command:  strings.Join(append([]string{c.CommandPath()}, config.Args...), " "),
+		Started:  started,
+		Duration: time.Since(started),
+		Err:      err,
+		Details:  config.notifyDetails,
+	}
+	if errors.Is(err, ErrExitSilently) {
+		e.Err = errors.New("see the command's output for details")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	if nerr := notifiers.Notify(ctx, e); nerr != nil {
+		fmt.Fprintf(notifyOut, "Warning: %v\n", nerr)
+	}
+	return err
+}
+
+// addNotifyDetail adds a detail, such as the ID of the resource the command
+// acted on, to the notifications sent when the command ends. Empty values are
+// skipped.
+func (c *CmdConfig) addNotifyDetail(name, value string) {
+	if value == "" {
+		return
+	}
+	c.notifyDetails = append(c.notifyDetails, notify.Field{Name: name, Value: value})
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dropletActionRestoreCmd returns the droplet-action restore command, whose
+// runner is wrapped by notifyOpt.
+func dropletActionRestoreCmd(t *testing.T) *Command {
+	for _, c := range DropletAction().ChildCommands() {
+		if c.Name() == "restore" {
+			return c
+		}
+	}
+	t.Fatal("droplet-action restore not found")
+	return nil
+}
+
+func notifyServer(t *testing.T) (string, *[]map[string]any) {
+	var got []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		var p map[string]any
+		assert.NoError(t, json.Unmarshal(b, &p))
+		got = append(got, p)
+	}))
+	t.Cleanup(srv.Close)
+	return "webhook+http://" + strings.TrimPrefix(srv.URL, "http://") + "/hook", &got
+}
+
+func TestNotifyOnSuccess(t *testing.T) {
+	spec, got := notifyServer(t)
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.dropletActions.EXPECT().Restore(1, 2).Return(&testAction, nil)
+
+		config.Args = append(config.Args, "1")
+		config.Doit.Set(config.NS, doctl.ArgImageID, 2)
+		config.Doit.Set(config.NS, doctl.ArgNotify, []string{spec})
+
+		err := dropletActionRestoreCmd(t).runner(config)
+		require.NoError(t, err)
+
+		require.Len(t, *got, 1)
+		p := (*got)[0]
+		assert.Equal(t, "droplet-action restore 1", p["command"])
+		assert.Equal(t, "succeeded", p["status"])
+		assert.Equal(t, map[string]any{"Action": "1"}, p["details"])
+	})
+}
+
+func TestNotifyOnFailure(t *testing.T) {
+	spec, got := notifyServer(t)
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.dropletActions.EXPECT().Restore(1, 2).Return(nil, errors.New("no such image"))
+
+		config.Args = append(config.Args, "1")
+		config.Doit.Set(config.NS, doctl.ArgImageID, 2)
+		config.Doit.Set(config.NS, doctl.ArgNotify, []string{spec})
+
+		err := dropletActionRestoreCmd(t).runner(config)
+		assert.EqualError(t, err, "no such image")
+
+		require.Len(t, *got, 1)
+		assert.Equal(t, "failed", (*got)[0]["status"])
+		assert.Equal(t, "no such image", (*got)[0]["error"])
+	})
+}
+
+func TestNotifyInvalidSpec(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, "1")
+		config.Doit.Set(config.NS, doctl.ArgImageID, 2)
+		config.Doit.Set(config.NS, doctl.ArgNotify, []string{"pager://oncall"})
+
+		// The command doesn't run if a notifier is invalid.
+		err := dropletActionRestoreCmd(t).runner(config)
+		assert.ErrorContains(t, err, `unknown notifier "pager://oncall"`)
+	})
+}
+
+func TestNotifyFailureIsAWarning(t *testing.T) {
+	var out bytes.Buffer
+	prev := notifyOut
+	notifyOut = &out
+	t.Cleanup(func() { notifyOut = prev })
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.dropletActions.EXPECT().Restore(1, 2).Return(&testAction, nil)
+
+		config.Args = append(config.Args, "1")
+		config.Doit.Set(config.NS, doctl.ArgImageID, 2)
+		config.Doit.Set(config.NS, doctl.ArgNotify, []string{"webhook+http://127.0.0.1:1/hook"})
+
+		err := dropletActionRestoreCmd(t).runner(config)
+		assert.NoError(t, err)
+		assert.Contains(t, out.String(), "Warning: sending the webhook notification")
+	})
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
+)
+
+// Policy creates the policy commands.
+func Policy() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "policy",
+			Short: "Display commands for working with command policies",
+			Long: `A policy file restricts which doctl commands may be run, for example by a wrapper script on a shared automation host. doctl reads it from the path given by ` + "`" + `--policy-file` + "`" + `, ` + "`" + `DIGITALOCEAN_POLICY_FILE` + "`" + `, or the ` + "`" + `policy-file` + "`" + ` config setting, and refuses to run commands the policy denies. If the file can't be read, no command is run.
+
+A policy file has the following format:
+
+    allow:
+      commands:
+        - compute droplet list
+        - apps *
+      resources:
+        - staging-*
+    deny:
+      commands:
+        - apps delete
+      resources:
+        - prod-*
+
+Command patterns are command paths without the leading ` + "`" + `doctl` + "`" + `. Each word may contain ` + "`" + `*` + "`" + ` and ` + "`" + `?` + "`" + ` wildcards, and a pattern also matches the subcommands of the commands it matches. Resource patterns are matched against the arguments and flag values of the command, such as the names and IDs of the resources it acts on.
+
+A command is denied if it matches a denied command pattern, or if any of its arguments or the value of any of its flags matches a denied resource pattern. When allowed commands or resources are listed, a command must also match one of the allowed command patterns, and each of its arguments must match one of the allowed resource patterns, as must the values of its flags that name or identify resources: flags whose names end in ` + "`" + `id` + "`" + `, ` + "`" + `ids` + "`" + `, ` + "`" + `name` + "`" + `, ` + "`" + `names` + "`" + `, ` + "`" + `tag` + "`" + `, ` + "`" + `tags` + "`" + `, ` + "`" + `uuid` + "`" + `, ` + "`" + `urn` + "`" + `, or ` + "`" + `urns` + "`" + `, such as ` + "`" + `--droplet-id` + "`" + ` and ` + "`" + `--tag-name` + "`" + `.
+
+Resources named only inside files or standard input, such as app specs, are not checked. A policy only restricts the commands run by a doctl whose flags, environment, and config file are controlled by the wrapper.`,
+			GroupID: configureDoctlGroup,
+		},
+	}
+
+	testCmd := cmdBuilderWithInit(cmd, RunPolicyTest, "test <command>", "Test whether the policy allows a command",
+		`Use this command to check whether the policy allows a doctl command, without running it. Pass the command as it would be run, without the leading `+"`"+`doctl`+"`"+`. Separate it with `+"`"+`--`+"`"+` if it has flags.
+
+The command fails if the policy denies the command.`,
+		Writer, false)
+	testCmd.Example = `The following example tests whether the policy in ` + "`" + `policy.yaml` + "`" + ` allows deleting the Droplet ` + "`" + `prod-web` + "`" + `: doctl policy test --policy-file policy.yaml -- compute droplet delete prod-web --force`
+
+	return cmd
+}
+
+// RunPolicyTest checks whether the policy allows a command.
+func RunPolicyTest(c *CmdConfig) error {
+	if len(c.Args) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	p, err := loadPolicy(viper.GetString(doctl.ArgPolicyFile))
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		fmt.Fprintln(c.Out, "No policy file is set; all commands are allowed")
+		return nil
+	}
+
+	// Parsing the command's flags sets them, so the command is parsed by a
+	// new command tree rather than the running one.
+	cmd, args := splitCommandArgs(newRootCommand(), c.Args)
+	if cmd.runner == nil {
+		return fmt.Errorf("%s is not a command that can be run", cmd.CommandPath())
+	}
+	if err := cmd.ParseFlags(args); err != nil {
+		return err
+	}
+
+	if err := p.check(cmd, cmd.Flags().Args()); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "%s is allowed by the policy\n", cmd.CommandPath())
+	return nil
+}
+
+// commandPolicy is the contents of a policy file.
+type commandPolicy struct {
+	Allow policyRules `json:"allow"`
+	Deny  policyRules `json:"deny"`
+}
+
+type policyRules struct {
+	Commands  []string `json:"commands"`
+	Resources []string `json:"resources"`
+}
+
+// loadPolicy reads the policy file at filePath. It returns nil if filePath is empty.
+func loadPolicy(filePath string) (*commandPolicy, error) {
+	if filePath == "" {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading policy file: %w", err)
+	}
+	var p commandPolicy
+	if err := yaml.UnmarshalStrict(b, &p); err != nil {
+		return nil, fmt.Errorf("parsing policy file %s: %w", filePath, err)
+	}
+
+	for _, patterns := range [][]string{p.Allow.Commands, p.Allow.Resources, p.Deny.Commands, p.Deny.Resources} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("parsing policy file %s: invalid pattern %q", filePath, pattern)
+			}
+		}
+	}
+	return &p, nil
+}
+
+// checkPolicy returns an error if the policy file denies running cmd with args.
+// The policy test command is always allowed, so policies can be verified.
+func checkPolicy(cmd *Command, args []string) error {
+	if cmd.Name() == "test" && cmd.HasParent() && cmd.Parent().Name() == "policy" {
+		return nil
+	}
+
+	p, err := loadPolicy(viper.GetString(doctl.ArgPolicyFile))
+	if err != nil || p == nil {
+		return err
+	}
+	return p.check(cmd, args)
+}
+
+func (p *commandPolicy) check(cmd *Command, args []string) error {
+	words := policyCommandPath(cmd.Command)
+	denied := func(format string, a ...any) error {
+		return withExitCode(ExitAuth, fmt.Errorf("%s is denied by the policy: "+format, append([]any{cmd.CommandPath()}, a...)...))
+	}
+
+	if pattern, ok := matchCommand(p.Deny.Commands, words); ok {
+		return denied("it matches the denied command %q", pattern)
+	}
+	if _, ok := matchCommand(p.Allow.Commands, words); len(p.Allow.Commands) > 0 && !ok {
+		return denied("it is not one of the allowed commands")
+	}
+
+	for _, r := range policyResources(cmd.Command, args, true) {
+		if pattern, ok := matchResource(p.Deny.Resources, r); ok {
+			return denied("%q matches the denied resource %q", r, pattern)
+		}
+	}
+	if len(p.Allow.Resources) > 0 {
+		for _, r := range policyResources(cmd.Command, args, false) {
+			if _, ok := matchResource(p.Allow.Resources, r); !ok {
+				return denied("%q is not one of the allowed resources", r)
+			}
+		}
+	}
+	return nil
+}
+
+// resourceFlagSuffixes are the last words of the names of flags that name or
+// identify resources, such as droplet-id and tag-name.
+var resourceFlagSuffixes = map[string]bool{
+	"id": true, "ids": true, "name": true, "names": true, "tag": true, "tags": true,
+	"uuid": true, "urn": true, "urns": true,
+}
+
+// policyResources returns the values cmd acts on: args, and the values of the
+// non-boolean flags set on cmd itself. Unless allFlags is set, only flags that
+// name or identify resources are included.
+func policyResources(cmd *cobra.Command, args []string, allFlags bool) []string {
+	resources := append([]string(nil), args...)
+	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if !f.Changed || f.Value.Type() == "bool" {
+			return
+		}
+		words := strings.Split(f.Name, "-")
+		if !allFlags && !resourceFlagSuffixes[words[len(words)-1]] {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			resources = append(resources, sv.GetSlice()...)
+			return
+		}
+		resources = append(resources, f.Value.String())
+	})
+	return resources
+}
+
+// policyCommandPath returns the names of cmd and its parents, without the root.
+func policyCommandPath(cmd *cobra.Command) []string {
+	var words []string
+	for ; cmd.HasParent(); cmd = cmd.Parent() {
+		words = append([]string{cmd.Name()}, words...)
+	}
+	return words
+}
+
+// matchCommand returns the first pattern that matches the command path words
+// or one of its parents.
+func matchCommand(patterns []string, words []string) (string, bool) {
+	for _, pattern := range patterns {
+		pwords := strings.Fields(pattern)
+		if len(pwords) == 0 || len(pwords) > len(words) {
+			continue
+		}
+		matched := true
+		for i, pw := range pwords {
+			if ok, _ := path.Match(pw, words[i]); !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// matchResource returns the first pattern that matches arg.
+func matchResource(patterns []string, arg string) (string, bool) {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, arg); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
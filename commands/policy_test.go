@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `allow:
+  commands:
+  - compute droplet
+  - apps *
+  resources:
+  - staging-*
+  - "1*"
+deny:
+  commands:
+  - apps delete
+  resources:
+  - staging-db
+`
+
+func TestPolicyCheck(t *testing.T) {
+	p, err := loadPolicy(testTempFile(t, []byte(testPolicy)))
+	require.NoError(t, err)
+
+	tcs := []struct {
+		args []string
+		err  string
+	}{
+		{args: []string{"compute", "droplet", "list"}},
+		{args: []string{"compute", "droplet", "delete", "staging-web", "123"}},
+		{args: []string{"apps", "spec", "validate", "staging-spec.yaml"}},
+		{args: []string{"compute", "d", "rm", "staging-web"}},
+		{
+			args: []string{"apps", "delete", "staging-web"},
+			err:  `doctl apps delete is denied by the policy: it matches the denied command "apps delete"`,
+		},
+		{
+			args: []string{"compute", "volume", "list"},
+			err:  "doctl compute volume list is denied by the policy: it is not one of the allowed commands",
+		},
+		{
+			args: []string{"compute", "droplet", "delete", "staging-db"},
+			err:  `doctl compute droplet delete is denied by the policy: "staging-db" matches the denied resource "staging-db"`,
+		},
+		{
+			args: []string{"compute", "droplet", "delete", "staging-web", "prod-web"},
+			err:  `doctl compute droplet delete is denied by the policy: "prod-web" is not one of the allowed resources`,
+		},
+	}
+
+	for _, tc := range tcs {
+		cmd, args := splitCommandArgs(DoitCmd, tc.args)
+		err := p.check(cmd, args)
+		if tc.err == "" {
+			assert.NoError(t, err, tc.args)
+		} else {
+			assert.EqualError(t, err, tc.err, tc.args)
+		}
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	p, err := loadPolicy("")
+	assert.NoError(t, err)
+	assert.Nil(t, p)
+
+	_, err = loadPolicy(testTempFile(t, []byte("allow:\n  command:\n  - apps\n")))
+	assert.ErrorContains(t, err, `unknown field "command"`)
+
+	_, err = loadPolicy(testTempFile(t, []byte("deny:\n  resources:\n  - \"prod-[\"\n")))
+	assert.ErrorContains(t, err, `invalid pattern "prod-["`)
+}
+
+func TestRunPolicyTest(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		prev := viper.GetString(doctl.ArgPolicyFile)
+		viper.Set(doctl.ArgPolicyFile, testTempFile(t, []byte(testPolicy)))
+		defer viper.Set(doctl.ArgPolicyFile, prev)
+
+		var out bytes.Buffer
+		config.Out = &out
+		config.Args = []string{"compute", "droplet", "delete", "staging-web", "--force"}
+		require.NoError(t, RunPolicyTest(config))
+		assert.Equal(t, "doctl compute droplet delete is allowed by the policy\n", out.String())
+
+		config.Args = []string{"apps", "delete", "staging-web", "--force"}
+		assert.EqualError(t, RunPolicyTest(config), `doctl apps delete is denied by the policy: it matches the denied command "apps delete"`)
+
+		// Flag values are checked as well as arguments.
+		config.Args = []string{"compute", "droplet", "delete", "--tag-name", "staging-db", "--force"}
+		assert.EqualError(t, RunPolicyTest(config), `doctl compute droplet delete is denied by the policy: "staging-db" matches the denied resource "staging-db"`)
+
+		config.Args = []string{"compute", "droplet", "delete", "--tag-name", "prod-web", "--force"}
+		assert.EqualError(t, RunPolicyTest(config), `doctl compute droplet delete is denied by the policy: "prod-web" is not one of the allowed resources`)
+
+		// Flags that don't name resources don't need to be allowed.
+		out.Reset()
+		config.Args = []string{"compute", "droplet", "list", "--region", "nyc1", "--tag-name", "staging-web"}
+		require.NoError(t, RunPolicyTest(config))
+		assert.Equal(t, "doctl compute droplet list is allowed by the policy\n", out.String())
+
+		// The policy can always be tested.
+		assert.NoError(t, checkPolicy(childCommand(childCommand(DoitCmd, "policy"), "test"), nil))
+	})
+}
@@ -218,7 +218,7 @@ func RunProjectsDelete(c *CmdConfig) error {
 	}
 
 	ps := c.Projects()
-	if force || AskForConfirmDelete("project", len(c.Args)) == nil {
+	if force || AskForConfirmDelete("project", len(c.Args), c.Args...) == nil {
 		for _, id := range c.Args {
 			if err := ps.Delete(id); err != nil {
 				return err
@@ -14,6 +14,10 @@ limitations under the License.
 package commands
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/commands/displayers"
 	"github.com/spf13/cobra"
 )
@@ -24,7 +28,9 @@ func Region() *Command {
 		Command: &cobra.Command{
 			Use:   "region",
 			Short: "Display commands to list datacenter regions",
-			Long:  "The subcommands of `doctl compute region` retrieve information about DigitalOcean datacenter regions.",
+			Long: `The subcommands of ` + "`" + `doctl compute region` + "`" + ` retrieve information about DigitalOcean datacenter regions.
+
+Create commands use the ` + "`" + `default-region` + "`" + ` config key (or the ` + "`" + `DIGITALOCEAN_DEFAULT_REGION` + "`" + ` environment variable) when no ` + "`" + `--region` + "`" + ` flag is given. The ` + "`" + `region-aliases` + "`" + ` config key defines names that can be used in place of region slugs, as a list of ` + "`" + `alias=slug` + "`" + ` entries such as ` + "`" + `primary=nyc3` + "`" + `.`,
 		},
 	}
 
@@ -50,3 +56,80 @@ func RunRegionList(c *CmdConfig) error {
 	image := &displayers.Region{Regions: list}
 	return c.Display(image)
 }
+
+// resolveRegionAlias maps a user-defined region alias, configured with the
+// `region-aliases` config key (for example `primary=nyc3`), to its region
+// slug. Regions that are not aliases are returned unchanged.
+func resolveRegionAlias(c *CmdConfig, region string) (string, error) {
+	if region == "" {
+		return region, nil
+	}
+
+	aliases, err := c.Doit.GetStringMapString("", doctl.ArgRegionAliases)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s configuration: %v", doctl.ArgRegionAliases, err)
+	}
+
+	if slug, ok := aliases[region]; ok && slug != "" {
+		return strings.TrimSpace(slug), nil
+	}
+	return region, nil
+}
+
+// defaultRegion returns the region configured with the `default-region`
+// config key, with any alias resolved.
+func defaultRegion(c *CmdConfig) (string, error) {
+	region, err := c.Doit.GetString("", doctl.ArgDefaultRegion)
+	if err != nil {
+		return "", err
+	}
+	return resolveRegionAlias(c, strings.TrimSpace(region))
+}
+
+// regionFromArgs returns the region for a create command. The region flag
+// takes precedence; when it is not passed, the configured default region
+// replaces the flag's own default. Aliases are resolved in either case.
+func regionFromArgs(c *CmdConfig, key string) (string, error) {
+	region, err := c.Doit.GetString(c.NS, key)
+	if err != nil {
+		return "", err
+	}
+
+	if !c.Doit.IsSet(key) {
+		def, err := defaultRegion(c)
+		if err != nil {
+			return "", err
+		}
+		if def != "" {
+			return def, nil
+		}
+	}
+
+	return resolveRegionAlias(c, region)
+}
+
+// warnDropletRegionMismatch warns when volumes or a VPC being attached to a
+// new Droplet live in a different region than the Droplet itself. Only
+// volumes given by ID are looked up, since the API looks volumes given by
+// name up in the Droplet's region. Lookup failures are ignored since the API
+// will reject invalid references anyway.
+func warnDropletRegionMismatch(c *CmdConfig, region string, volumes []string, vpcUUID string) {
+	if region == "" {
+		return
+	}
+
+	for _, ref := range volumes {
+		if !looksLikeUUID(ref) {
+			continue
+		}
+		if v, err := c.Volumes().Get(ref); err == nil && v.Region != nil && v.Region.Slug != region {
+			warn("Volume %s is in region %s but the Droplet is being created in %s; volumes can only be attached to Droplets in the same region.", ref, v.Region.Slug, region)
+		}
+	}
+
+	if vpcUUID != "" {
+		if vpc, err := c.VPCs().Get(vpcUUID); err == nil && vpc.RegionSlug != "" && vpc.RegionSlug != region {
+			warn("VPC %s is in region %s but the Droplet is being created in %s.", vpcUUID, vpc.RegionSlug, region)
+		}
+	}
+}
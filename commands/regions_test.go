@@ -16,6 +16,7 @@ package commands
 import (
 	"testing"
 
+	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/do"
 	"github.com/digitalocean/godo"
 	"github.com/stretchr/testify/assert"
@@ -40,3 +41,85 @@ func TestRegionsList(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestRegionFromArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		flag     string
+		def      string
+		aliases  map[string]string
+		expected string
+	}{
+		{name: "flag only", flag: "sfo3", expected: "sfo3"},
+		{name: "flag wins over default", flag: "sfo3", def: "nyc3", expected: "sfo3"},
+		{name: "default when flag unset", def: "nyc3", expected: "nyc3"},
+		{name: "flag alias", flag: "primary", aliases: map[string]string{"primary": "nyc3"}, expected: "nyc3"},
+		{name: "default alias", def: "backup", aliases: map[string]string{"backup": "ams3"}, expected: "ams3"},
+		{name: "nothing set", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+				if tt.flag != "" {
+					config.Doit.Set(config.NS, doctl.ArgRegionSlug, tt.flag)
+				}
+				if tt.def != "" {
+					config.Doit.Set("", doctl.ArgDefaultRegion, tt.def)
+				}
+				if tt.aliases != nil {
+					config.Doit.Set("", doctl.ArgRegionAliases, tt.aliases)
+				}
+
+				region, err := regionFromArgs(config, doctl.ArgRegionSlug)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, region)
+			})
+		})
+	}
+}
+
+func TestDropletCreateDefaultRegion(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		dcr := &godo.DropletCreateRequest{
+			Name:    "droplet",
+			Region:  "nyc3",
+			Size:    "1gb",
+			Image:   godo.DropletCreateImage{Slug: "image"},
+			SSHKeys: []godo.DropletCreateSSHKey{},
+			Volumes: []godo.DropletCreateVolume{{Name: "test-volume"}},
+		}
+		tm.droplets.EXPECT().Create(dcr, false).Return(&testDroplet, nil)
+
+		config.Args = append(config.Args, "droplet")
+		config.Doit.Set("", doctl.ArgDefaultRegion, "primary")
+		config.Doit.Set("", doctl.ArgRegionAliases, map[string]string{"primary": "nyc3"})
+		config.Doit.Set(config.NS, doctl.ArgSizeSlug, "1gb")
+		config.Doit.Set(config.NS, doctl.ArgImage, "image")
+		config.Doit.Set(config.NS, doctl.ArgVolumeList, []string{"test-volume"})
+
+		err := RunDropletCreate(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestVPCCreateDefaultRegion(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.vpcs.EXPECT().Create(&godo.VPCCreateRequest{Name: "vpc-name", RegionSlug: "ams3"}).Return(&testVPC, nil)
+
+		config.Doit.Set(config.NS, doctl.ArgVPCName, "vpc-name")
+		config.Doit.Set("", doctl.ArgDefaultRegion, "ams3")
+
+		err := RunVPCCreate(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestVPCCreateMissingRegion(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Doit.Set(config.NS, doctl.ArgVPCName, "vpc-name")
+
+		err := RunVPCCreate(config)
+		assert.Error(t, err)
+	})
+}
@@ -18,6 +18,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"strings"
@@ -113,6 +115,8 @@ By default, the secret manifest is applied to all the namespaces for the Kuberne
 You can redirect the command's output to a file to save the manifest for later use or pipe it directly to ` + "`" + `kubectl` + "`" + ` to create the secret in your cluster:
 
     doctl registry kubernetes-manifest | kubectl apply -f -
+
+Alternatively, use the ` + "`" + `--apply` + "`" + ` and ` + "`" + `--cluster` + "`" + ` flags to create or update the secret in a DigitalOcean Kubernetes cluster directly, without kubectl. Unless the secret is in the ` + "`" + `kube-system` + "`" + ` namespace, where the DOSecret operator takes care of this, the namespace's default service account is also patched to use the secret as an image pull secret.
 `
 	cmdRunKubernetesManifest := CmdBuilder(cmd, RunKubernetesManifest, "kubernetes-manifest",
 		"Generate a Kubernetes secret manifest for a registry.",
@@ -121,7 +125,13 @@ You can redirect the command's output to a file to save the manifest for later u
 		"The secret's name. Defaults to the registry name prefixed with \"registry-\"")
 	AddStringFlag(cmdRunKubernetesManifest, doctl.ArgObjectNamespace, "",
 		"kube-system", "The Kubernetes namespace to hold the secret")
-	cmdRunKubernetesManifest.Example = `The following example generates a secret manifest for a registry named ` + "`" + `example-registry` + "`" + ` and applies it to the ` + "`" + `kube-system` + "`" + ` namespace: doctl registry kubernetes-manifest example-registry --namespace=kube-system`
+	AddBoolFlag(cmdRunKubernetesManifest, doctl.ArgRegistryApply, "", false,
+		"Applies the secret to the cluster given by `--cluster` instead of printing it")
+	AddStringFlag(cmdRunKubernetesManifest, doctl.ArgRegistryCluster, "", "",
+		"The ID or name of the Kubernetes cluster to apply the secret to")
+	cmdRunKubernetesManifest.Example = `The following example generates a secret manifest for a registry named ` + "`" + `example-registry` + "`" + ` and applies it to the ` + "`" + `kube-system` + "`" + ` namespace: doctl registry kubernetes-manifest example-registry --namespace=kube-system
+
+The following example creates the secret in the ` + "`" + `apps` + "`" + ` namespace of a cluster named ` + "`" + `example-cluster` + "`" + ` and lets the namespace's default service account pull from the registry: doctl registry kubernetes-manifest --apply --namespace apps --cluster example-cluster`
 
 	dockerConfigDesc := `Outputs a JSON-formatted Docker configuration that you can use to configure a Docker client to authenticate with your private container registry. This configuration is useful for configuring third-party tools that need access to your registry. For configuring your local Docker client use ` + "`" + `doctl registry login` + "`" + ` instead, as it preserves the configuration of any other registries you have authenticated to.
 
@@ -354,7 +364,7 @@ func RunRegistryCreate(c *CmdConfig) error {
 	if err != nil {
 		return err
 	}
-	region, err := c.Doit.GetString(c.NS, doctl.ArgRegionSlug)
+	region, err := regionFromArgs(c, doctl.ArgRegionSlug)
 	if err != nil {
 		return err
 	}
@@ -497,14 +507,27 @@ func RunKubernetesManifest(c *CmdConfig) error {
 	if err != nil {
 		return err
 	}
+	apply, err := c.Doit.GetBool(c.NS, doctl.ArgRegistryApply)
+	if err != nil {
+		return err
+	}
+	cluster, err := c.Doit.GetString(c.NS, doctl.ArgRegistryCluster)
+	if err != nil {
+		return err
+	}
+	if apply && cluster == "" {
+		return fmt.Errorf("--%s is required with --%s", doctl.ArgRegistryCluster, doctl.ArgRegistryApply)
+	}
+	if !apply && cluster != "" {
+		return fmt.Errorf("--%s can only be used with --%s", doctl.ArgRegistryCluster, doctl.ArgRegistryApply)
+	}
 
 	// if no secret name supplied, use the registry name
 	if secretName == "" {
-		reg, err := c.Registry().Get()
+		secretName, err = defaultRegistrySecretName(c)
 		if err != nil {
 			return err
 		}
-		secretName = "registry-" + reg.Name
 	}
 
 	// fetch docker config
@@ -514,39 +537,117 @@ func RunKubernetesManifest(c *CmdConfig) error {
 	if err != nil {
 		return err
 	}
+	secret := registrySecret(secretName, secretNamespace, dockerCreds.DockerConfigJSON)
+
+	if apply {
+		return applyRegistrySecret(c, cluster, secret)
+	}
+
+	serializer := k8sjson.NewSerializerWithOptions(
+		k8sjson.DefaultMetaFactory, nil, nil,
+		k8sjson.SerializerOptions{
+			Yaml:   true,
+			Pretty: true,
+			Strict: true,
+		},
+	)
+
+	return serializer.Encode(secret, c.Out)
+}
+
+// defaultRegistrySecretName returns the default name of the secret holding the
+// registry's credentials: the registry name prefixed with "registry-".
+func defaultRegistrySecretName(c *CmdConfig) (string, error) {
+	reg, err := c.Registry().Get()
+	if err != nil {
+		return "", err
+	}
+	return "registry-" + reg.Name, nil
+}
+
+// registrySecret returns the manifest of a secret holding a Docker config
+// for the registry.
+func registrySecret(name, namespace string, dockerConfigJSON []byte) *k8sapiv1.Secret {
 	annotations := map[string]string{}
 
-	if secretNamespace == k8smetav1.NamespaceSystem {
-		annotations[DOSecretOperatorAnnotation] = secretName
+	if namespace == k8smetav1.NamespaceSystem {
+		annotations[DOSecretOperatorAnnotation] = name
 	}
 
-	// create the manifest for the secret
-	secret := &k8sapiv1.Secret{
+	return &k8sapiv1.Secret{
 		TypeMeta: k8smetav1.TypeMeta{
 			Kind:       "Secret",
 			APIVersion: "v1",
 		},
 		ObjectMeta: k8smetav1.ObjectMeta{
-			Name:        secretName,
-			Namespace:   secretNamespace,
+			Name:        name,
+			Namespace:   namespace,
 			Annotations: annotations,
 		},
 		Type: k8sapiv1.SecretTypeDockerConfigJson,
 		Data: map[string][]byte{
-			".dockerconfigjson": dockerCreds.DockerConfigJSON,
+			".dockerconfigjson": dockerConfigJSON,
 		},
 	}
+}
 
-	serializer := k8sjson.NewSerializerWithOptions(
-		k8sjson.DefaultMetaFactory, nil, nil,
-		k8sjson.SerializerOptions{
-			Yaml:   true,
-			Pretty: true,
-			Strict: true,
-		},
-	)
+// kubeAPIClientForCluster connects to the Kubernetes API of a DOKS cluster.
+// It is replaced for testing.
+var kubeAPIClientForCluster = func(c *CmdConfig, clusterID string) (*kubeAPIClient, error) {
+	kubeconfig, err := kubernetesCommandService().KubeconfigProvider.Remote(c.Kubernetes(), clusterID, 0)
+	if err != nil {
+		return nil, err
+	}
+	return newKubeAPIClient(kubeconfig)
+}
 
-	return serializer.Encode(secret, c.Out)
+// applyRegistrySecret creates or replaces the secret in the cluster and, outside of
+// kube-system, adds it to the image pull secrets of the namespace's default service account.
+func applyRegistrySecret(c *CmdConfig, cluster string, secret *k8sapiv1.Secret) error {
+	clusterID, err := clusterIDize(c, cluster)
+	if err != nil {
+		return err
+	}
+	kube, err := kubeAPIClientForCluster(c, clusterID)
+	if err != nil {
+		return err
+	}
+	return applyRegistrySecretWith(c, kube, secret)
+}
+
+func applyRegistrySecretWith(c *CmdConfig, kube *kubeAPIClient, secret *k8sapiv1.Secret) error {
+	body, err := json.Marshal(secret)
+	if err != nil {
+		return err
+	}
+	secrets := fmt.Sprintf("/api/v1/namespaces/%s/secrets", url.PathEscape(secret.Namespace))
+	_, err = kube.do(http.MethodPut, secrets+"/"+url.PathEscape(secret.Name), "application/json", body)
+	if isKubeAPIStatus(err, http.StatusNotFound) {
+		_, err = kube.do(http.MethodPost, secrets, "application/json", body)
+	}
+	if err != nil {
+		return fmt.Errorf("applying secret %s/%s: %v", secret.Namespace, secret.Name, err)
+	}
+	fmt.Fprintf(c.Out, "Applied secret %s/%s\n", secret.Namespace, secret.Name)
+
+	if secret.Namespace == k8smetav1.NamespaceSystem {
+		return nil
+	}
+
+	// imagePullSecrets is merged by name, so the patch keeps any other secrets
+	// and is a no-op if this one is already there.
+	patch, err := json.Marshal(map[string]any{
+		"imagePullSecrets": []k8sapiv1.LocalObjectReference{{Name: secret.Name}},
+	})
+	if err != nil {
+		return err
+	}
+	sa := fmt.Sprintf("/api/v1/namespaces/%s/serviceaccounts/default", url.PathEscape(secret.Namespace))
+	if _, err := kube.do(http.MethodPatch, sa, "application/strategic-merge-patch+json", patch); err != nil {
+		return fmt.Errorf("patching service account %s/default: %v", secret.Namespace, err)
+	}
+	fmt.Fprintf(c.Out, "Patched service account %s/default\n", secret.Namespace)
+	return nil
 }
 
 // RunDockerConfig generates credentials and prints a Docker config that can be
@@ -15,8 +15,12 @@ package commands
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
@@ -635,6 +639,69 @@ func TestRegistryKubernetesManifest(t *testing.T) {
 	})
 }
 
+func TestRegistryKubernetesManifestApply(t *testing.T) {
+	var requests []string
+	var created k8sapiv1.Secret
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		switch r.Method + " " + r.URL.Path {
+		case "PUT /api/v1/namespaces/apps/secrets/registry-" + testRegistry.Name:
+			// The secret doesn't exist yet, so it is created instead.
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"kind":"Status","message":"not found"}`))
+		case "POST /api/v1/namespaces/apps/secrets":
+			assert.NoError(t, json.Unmarshal(body, &created))
+			w.WriteHeader(http.StatusCreated)
+			w.Write(body)
+		case "PATCH /api/v1/namespaces/apps/serviceaccounts/default":
+			assert.Equal(t, "application/strategic-merge-patch+json", r.Header.Get("Content-Type"))
+			assert.JSONEq(t, `{"imagePullSecrets":[{"name":"registry-`+testRegistry.Name+`"}]}`, string(body))
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	defer func(f func(*CmdConfig, string) (*kubeAPIClient, error)) { kubeAPIClientForCluster = f }(kubeAPIClientForCluster)
+	kubeAPIClientForCluster = func(_ *CmdConfig, clusterID string) (*kubeAPIClient, error) {
+		assert.Equal(t, testCluster.ID, clusterID)
+		return &kubeAPIClient{client: server.Client(), host: server.URL}, nil
+	}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.registry.EXPECT().Get().Return(&testRegistry, nil)
+		tm.registry.EXPECT().DockerCredentials(&godo.RegistryDockerCredentialsRequest{
+			ReadWrite: false,
+		}).Return(testDockerCredentials, nil)
+
+		config.Doit.Set(config.NS, doctl.ArgObjectNamespace, "apps")
+		config.Doit.Set(config.NS, doctl.ArgRegistryApply, true)
+		config.Doit.Set(config.NS, doctl.ArgRegistryCluster, testCluster.ID)
+
+		var out bytes.Buffer
+		config.Out = &out
+		assert.NoError(t, RunKubernetesManifest(config))
+
+		assert.Equal(t, []string{
+			"PUT /api/v1/namespaces/apps/secrets/registry-" + testRegistry.Name,
+			"POST /api/v1/namespaces/apps/secrets",
+			"PATCH /api/v1/namespaces/apps/serviceaccounts/default",
+		}, requests)
+		assert.Equal(t, k8sapiv1.SecretTypeDockerConfigJson, created.Type)
+		assert.Equal(t, testDockerCredentials.DockerConfigJSON, created.Data[".dockerconfigjson"])
+		assert.Equal(t, "Applied secret apps/registry-"+testRegistry.Name+"\nPatched service account apps/default\n", out.String())
+	})
+}
+
+func TestRegistryKubernetesManifestApplyNeedsCluster(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Doit.Set(config.NS, doctl.ArgRegistryApply, true)
+		assert.EqualError(t, RunKubernetesManifest(config), "--cluster is required with --apply")
+	})
+}
+
 func TestRegistryLogin(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -76,6 +76,14 @@ func RunReservedIPCreate(c *CmdConfig) error {
 	dropletID, _ := c.Doit.GetInt(c.NS, doctl.ArgDropletID)
 	projectID, _ := c.Doit.GetString(c.NS, doctl.ArgProjectID)
 
+	if region == "" && dropletID == 0 {
+		region, _ = defaultRegion(c)
+	}
+	region, err := resolveRegionAlias(c, region)
+	if err != nil {
+		return err
+	}
+
 	if region == "" && dropletID == 0 {
 		return doctl.NewMissingArgsErr("Region and Droplet ID can't both be blank.")
 	}
@@ -142,7 +150,7 @@ func RunReservedIPDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if force || AskForConfirmDelete("reserved IP", 1) == nil {
+	if force || AskForConfirmDelete("reserved IP", 1, c.Args[0]) == nil {
 		ip := c.Args[0]
 		return ris.Delete(ip)
 	}
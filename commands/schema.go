@@ -0,0 +1,78 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+)
+
+// Schema creates the schema command.
+func Schema() *Command {
+	cmd := cmdBuilderWithInit(nil, RunSchema, "schema <command>", "Print the JSON Schema of a command's output",
+		`Use this command to print a JSON Schema describing the output of another doctl command when run with `+"`"+`--output json`+"`"+`.
+
+The schema is generated from the types doctl uses to display the command's output, so tooling can validate or generate code against it. Pass the command as it would be run, without its arguments or flags. Only commands that support `+"`"+`--format`+"`"+` have structured output.`,
+		Writer, false)
+	cmd.GroupID = configureDoctlGroup
+	cmd.Example = `The following example prints the schema of the output of ` + "`" + `doctl compute droplet list` + "`" + `: doctl schema compute droplet list`
+
+	return cmd
+}
+
+// RunSchema prints the JSON Schema of a command's output.
+func RunSchema(c *CmdConfig) error {
+	if len(c.Args) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	cmd, err := findCommand(DoitCmd, c.Args)
+	if err != nil {
+		return err
+	}
+	if cmd.displayer == nil {
+		return fmt.Errorf("%s has no structured output", cmd.CommandPath())
+	}
+
+	e := json.NewEncoder(c.Out)
+	e.SetIndent("", "  ")
+	return e.Encode(displayers.JSONSchema(cmd.displayer, cmd.CommandPath()))
+}
+
+// findCommand finds the command at path below root, matching names and aliases.
+func findCommand(root *Command, path []string) (*Command, error) {
+	cmd := root
+	for i, name := range path {
+		cmd = childCommand(cmd, name)
+		if cmd == nil {
+			return nil, fmt.Errorf("unknown command %q", strings.Join(path[:i+1], " "))
+		}
+	}
+	return cmd, nil
+}
+
+// childCommand returns the child command of cmd with the given name or alias, or nil.
+func childCommand(cmd *Command, name string) *Command {
+	for _, child := range cmd.ChildCommands() {
+		if child.Name() == name || slices.Contains(child.Aliases, name) {
+			return child
+		}
+	}
+	return nil
+}
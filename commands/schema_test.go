@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSchema(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var buf bytes.Buffer
+		config.Out = &buf
+		config.Args = []string{"compute", "d", "list"}
+
+		err := RunSchema(config)
+		require.NoError(t, err)
+
+		var schema map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &schema))
+		assert.Equal(t, "doctl compute droplet list", schema["title"])
+		assert.Equal(t, "array", schema["type"])
+	})
+
+	t.Run("no structured output", func(t *testing.T) {
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			config.Args = []string{"compute"}
+			err := RunSchema(config)
+			assert.EqualError(t, err, "doctl compute has no structured output")
+		})
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			config.Args = []string{"compute", "nope"}
+			err := RunSchema(config)
+			assert.EqualError(t, err, `unknown command "compute nope"`)
+		})
+	})
+}
+
+func TestSchemaAllCommands(t *testing.T) {
+	var walk func(cmd *Command)
+	walk = func(cmd *Command) {
+		if cmd.displayer != nil {
+			schema := displayers.JSONSchema(cmd.displayer, cmd.CommandPath())
+			_, err := json.Marshal(schema)
+			assert.NoError(t, err, cmd.CommandPath())
+		}
+		for _, child := range cmd.ChildCommands() {
+			walk(child)
+		}
+	}
+	walk(DoitCmd)
+}
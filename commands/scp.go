@@ -0,0 +1,285 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/doctl/pkg/ssh"
+)
+
+// SCP creates the scp command.
+func SCP(parent *Command) *Command {
+	scpDesc := fmt.Sprintf(`Copy files between the local machine and one or more Droplets using scp.
+
+A remote location is written `+"`"+`[user@]<droplet-id|name>:<path>`+"`"+`. Either the last argument is remote and every other argument is a local file to upload, or the first argument is remote and the last argument is the local destination of the download.
+
+To push files to every Droplet with a tag, omit the Droplet from the destination (`+"`"+`:<path>`+"`"+`) and pass one or more `+"`"+`--%s`+"`"+` flags. The copies run in parallel, up to `+"`"+`--%s`+"`"+` at a time, and the result for each Droplet is shown when they finish.
+
+SSH keys, users and ports are resolved the same way as for `+"`"+`doctl compute ssh`+"`"+`.
+`, doctl.ArgTag, doctl.ArgExecConcurrency)
+
+	cmdSCP := CmdBuilder(parent, RunSCP, "scp <source>... <target>", "Copy files to and from Droplets", scpDesc, Writer,
+		displayerType(&displayers.DropletTransfers{}))
+	AddStringSliceFlag(cmdSCP, doctl.ArgTag, "", []string{}, "Upload to every Droplet with this tag. May be repeated")
+	AddStringFlag(cmdSCP, doctl.ArgSSHUser, "", "root", "SSH user for connection")
+	AddStringFlag(cmdSCP, doctl.ArgsSSHKeyPath, "", defaultSSHKeyPath("id_rsa"), "Path to SSH private key")
+	AddIntFlag(cmdSCP, doctl.ArgsSSHPort, "", 22, "The remote port sshd is running on")
+	AddBoolFlag(cmdSCP, doctl.ArgsSSHPrivateIP, "", false, "Connect to the Droplets' private IP addresses")
+	AddBoolFlag(cmdSCP, doctl.ArgSCPRecursive, "r", false, "Copy directories recursively")
+	AddIntFlag(cmdSCP, doctl.ArgExecConcurrency, "", 10, "Maximum number of Droplets to copy to at once")
+	cmdSCP.Example = `The following example uploads a file to a Droplet named ` + "`" + `web1` + "`" + `: doctl compute scp nginx.conf web1:/etc/nginx/nginx.conf
+
+The following example uploads a file to every Droplet tagged ` + "`" + `web` + "`" + `: doctl compute scp --tag web nginx.conf :/etc/nginx/nginx.conf
+
+The following example downloads a log file: doctl compute scp root@web1:/var/log/syslog ./web1-syslog`
+
+	return cmdSCP
+}
+
+// scpRemote is a remote location given as [user@]droplet:path.
+type scpRemote struct {
+	user    string
+	droplet string
+	path    string
+}
+
+// parseSCPRemote reports whether arg names a remote location. As with scp,
+// an argument is local if it has no colon or a slash comes before the first
+// colon; a single drive letter before the colon is also treated as local.
+func parseSCPRemote(arg string) (scpRemote, bool) {
+	i := strings.Index(arg, ":")
+	if i < 0 || strings.Contains(arg[:i], "/") || strings.Contains(arg[:i], `\`) {
+		return scpRemote{}, false
+	}
+	if i == 1 && unicode.IsLetter(rune(arg[0])) && len(arg) > 2 && (arg[2] == '\\' || arg[2] == '/') {
+		return scpRemote{}, false
+	}
+
+	r := scpRemote{droplet: arg[:i], path: arg[i+1:]}
+	if at := strings.LastIndex(r.droplet, "@"); at >= 0 {
+		r.user, r.droplet = r.droplet[:at], r.droplet[at+1:]
+	}
+	return r, true
+}
+
+// RunSCP copies files between the local machine and Droplets.
+func RunSCP(c *CmdConfig) error {
+	if len(c.Args) < 2 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	var (
+		remote scpRemote
+		local  []string
+		upload bool
+	)
+	first, firstRemote := parseSCPRemote(c.Args[0])
+	last, lastRemote := parseSCPRemote(c.Args[len(c.Args)-1])
+	switch {
+	case lastRemote:
+		upload = true
+		remote = last
+		local = c.Args[:len(c.Args)-1]
+	case firstRemote && len(c.Args) == 2:
+		remote = first
+		local = c.Args[1:]
+	case firstRemote:
+		return errors.New("only one remote file can be downloaded at a time")
+	default:
+		return errors.New("one of the arguments must be a remote location ([user@]droplet:path)")
+	}
+	for _, l := range local {
+		if _, ok := parseSCPRemote(l); ok {
+			return fmt.Errorf("%s: copying between Droplets is not supported", l)
+		}
+	}
+	if remote.path == "" {
+		return errors.New("the remote path must not be empty")
+	}
+
+	tags, err := c.Doit.GetStringSlice(c.NS, doctl.ArgTag)
+	if err != nil {
+		return err
+	}
+	user, err := c.Doit.GetString(c.NS, doctl.ArgSSHUser)
+	if err != nil {
+		return err
+	}
+	if remote.user != "" {
+		user = remote.user
+	}
+	keyPath, err := c.Doit.GetString(c.NS, doctl.ArgsSSHKeyPath)
+	if err != nil {
+		return err
+	}
+	port, err := c.Doit.GetInt(c.NS, doctl.ArgsSSHPort)
+	if err != nil {
+		return err
+	}
+	privateIP, err := c.Doit.GetBool(c.NS, doctl.ArgsSSHPrivateIP)
+	if err != nil {
+		return err
+	}
+	recursive, err := c.Doit.GetBool(c.NS, doctl.ArgSCPRecursive)
+	if err != nil {
+		return err
+	}
+	concurrency, err := c.Doit.GetInt(c.NS, doctl.ArgExecConcurrency)
+	if err != nil {
+		return err
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var droplets do.Droplets
+	switch {
+	case len(tags) > 0 && !upload:
+		return fmt.Errorf("--%s can only be used when uploading", doctl.ArgTag)
+	case len(tags) > 0 && remote.droplet != "":
+		return fmt.Errorf("with --%s, the destination must be :<path> without a Droplet", doctl.ArgTag)
+	case len(tags) > 0:
+		seen := map[int]bool{}
+		for _, tag := range tags {
+			list, err := c.Droplets().ListByTag(tag)
+			if err != nil {
+				return err
+			}
+			for _, d := range list {
+				if !seen[d.ID] {
+					seen[d.ID] = true
+					droplets = append(droplets, d)
+				}
+			}
+		}
+		if len(droplets) == 0 {
+			return fmt.Errorf("no Droplets are tagged %s", strings.Join(tags, " or "))
+		}
+	case remote.droplet == "":
+		return fmt.Errorf("the remote location must name a Droplet or --%s must be given", doctl.ArgTag)
+	default:
+		d, err := findSCPDroplet(c.Droplets(), remote.droplet)
+		if err != nil {
+			return err
+		}
+		droplets = do.Droplets{*d}
+	}
+
+	results := make([]displayers.DropletTransfer, len(droplets))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, d := range droplets {
+		wg.Add(1)
+		go func(i int, d do.Droplet) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = copyWithDroplet(c, d, user, keyPath, port, privateIP, ssh.Options{
+				doctl.ArgSCPUpload:     upload,
+				doctl.ArgSCPLocalPaths: local,
+				doctl.ArgSCPRemotePath: remote.path,
+				doctl.ArgSCPRecursive:  recursive,
+			})
+		}(i, d)
+	}
+	wg.Wait()
+
+	if err := c.Display(&displayers.DropletTransfers{Transfers: results}); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Status != "ok" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("the copy failed on %d of %d Droplets", failed, len(results))
+	}
+	return nil
+}
+
+// findSCPDroplet finds a Droplet by ID or by name. Unlike ssh, an ambiguous
+// name is an error, since the copy could otherwise go to the wrong Droplet.
+func findSCPDroplet(ds do.DropletsService, idOrName string) (*do.Droplet, error) {
+	if id, err := strconv.Atoi(idOrName); err == nil {
+		return ds.Get(id)
+	}
+
+	list, err := ds.List()
+	if err != nil {
+		return nil, err
+	}
+	var found *do.Droplet
+	for i := range list {
+		if list[i].Name != idOrName {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("more than one Droplet is named %s; use its ID instead", idOrName)
+		}
+		found = &list[i]
+	}
+	if found == nil {
+		return nil, fmt.Errorf("could not find Droplet %s", idOrName)
+	}
+	return found, nil
+}
+
+func copyWithDroplet(c *CmdConfig, d do.Droplet, user, keyPath string, port int, privateIP bool, opts ssh.Options) displayers.DropletTransfer {
+	result := displayers.DropletTransfer{ID: d.ID, Name: d.Name}
+
+	ip, err := privateIPElsePub(&d, privateIP)
+	if err == nil && ip == "" {
+		err = errors.New("no address to connect to")
+	}
+	if err != nil {
+		result.Status, result.Error = "failed", err.Error()
+		return result
+	}
+	result.Host = ip
+	if user == "" {
+		user = defaultSSHUser(&d)
+	}
+
+	var stderr bytes.Buffer
+	opts[doctl.ArgSSHStdout] = io.Discard
+	opts[doctl.ArgSSHStderr] = &stderr
+	if err := c.Doit.SCP(user, ip, keyPath, port, opts).Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if i := strings.LastIndex(msg, "\n"); i >= 0 {
+			msg = msg[i+1:]
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		result.Status, result.Error = "failed", msg
+		return result
+	}
+	result.Status = "ok"
+	return result
+}
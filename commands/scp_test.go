@@ -0,0 +1,161 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/doctl/pkg/runner"
+	"github.com/digitalocean/doctl/pkg/ssh"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSCPCommand(t *testing.T) {
+	parent := &Command{
+		Command: &cobra.Command{
+			Use:   "compute",
+			Short: "compute commands",
+			Long:  "compute commands are for controlling and managing infrastructure",
+		},
+	}
+	cmd := SCP(parent)
+	assert.NotNil(t, cmd)
+	assertCommandNames(t, cmd)
+}
+
+func TestParseSCPRemote(t *testing.T) {
+	tests := []struct {
+		arg    string
+		remote bool
+		want   scpRemote
+	}{
+		{arg: "web1:/etc/hosts", remote: true, want: scpRemote{droplet: "web1", path: "/etc/hosts"}},
+		{arg: "core@123:notes.txt", remote: true, want: scpRemote{user: "core", droplet: "123", path: "notes.txt"}},
+		{arg: ":/etc/hosts", remote: true, want: scpRemote{path: "/etc/hosts"}},
+		{arg: "nginx.conf"},
+		{arg: "./a:b"},
+		{arg: `C:\Users\me\file`},
+		{arg: "C:/Users/me/file"},
+	}
+	for _, tt := range tests {
+		got, ok := parseSCPRemote(tt.arg)
+		assert.Equal(t, tt.remote, ok, tt.arg)
+		assert.Equal(t, tt.want, got, tt.arg)
+	}
+}
+
+func TestSCPUploadByName(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.droplets.EXPECT().List().Return(do.Droplets{execTestDroplet(1, "web1", "192.0.2.1")}, nil)
+
+		tc := config.Doit.(*doctl.TestConfig)
+		tc.SCPFn = func(user, host, keyPath string, port int, opts ssh.Options) runner.Runner {
+			assert.Equal(t, "deploy", user)
+			assert.Equal(t, "192.0.2.1", host)
+			assert.Equal(t, true, opts[doctl.ArgSCPUpload])
+			assert.Equal(t, []string{"a.conf", "b.conf"}, opts[doctl.ArgSCPLocalPaths])
+			assert.Equal(t, "/etc/app/", opts[doctl.ArgSCPRemotePath])
+			return runnerFunc(func() error { return nil })
+		}
+
+		config.Args = []string{"a.conf", "b.conf", "deploy@web1:/etc/app/"}
+		require.NoError(t, RunSCP(config))
+	})
+}
+
+func TestSCPDownloadByID(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.droplets.EXPECT().Get(testDroplet.ID).Return(&testDroplet, nil)
+
+		tc := config.Doit.(*doctl.TestConfig)
+		tc.SCPFn = func(user, host, keyPath string, port int, opts ssh.Options) runner.Runner {
+			assert.Equal(t, false, opts[doctl.ArgSCPUpload])
+			assert.Equal(t, []string{"syslog"}, opts[doctl.ArgSCPLocalPaths])
+			assert.Equal(t, "/var/log/syslog", opts[doctl.ArgSCPRemotePath])
+			return runnerFunc(func() error { return nil })
+		}
+
+		config.Args = []string{fmt.Sprintf("%d:/var/log/syslog", testDroplet.ID), "syslog"}
+		require.NoError(t, RunSCP(config))
+	})
+}
+
+func TestSCPUploadByTag(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		web1 := execTestDroplet(1, "web1", "192.0.2.1")
+		web2 := execTestDroplet(2, "web2", "192.0.2.2")
+		tm.droplets.EXPECT().ListByTag("web").Return(do.Droplets{web1, web2}, nil)
+
+		var mu sync.Mutex
+		var hosts []string
+		tc := config.Doit.(*doctl.TestConfig)
+		tc.SCPFn = func(user, host, keyPath string, port int, opts ssh.Options) runner.Runner {
+			mu.Lock()
+			hosts = append(hosts, host)
+			mu.Unlock()
+			stderr := opts[doctl.ArgSSHStderr].(io.Writer)
+			return runnerFunc(func() error {
+				if host == "192.0.2.2" {
+					fmt.Fprintln(stderr, "scp: /etc/app/: Permission denied")
+					return errors.New("exit status 1")
+				}
+				return nil
+			})
+		}
+
+		config.Doit.Set(config.NS, doctl.ArgTag, []string{"web"})
+		config.Args = []string{"app.conf", ":/etc/app/"}
+		err := RunSCP(config)
+		assert.EqualError(t, err, "the copy failed on 1 of 2 Droplets")
+
+		sort.Strings(hosts)
+		assert.Equal(t, []string{"192.0.2.1", "192.0.2.2"}, hosts)
+	})
+}
+
+func TestSCPInvalidArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		tags []string
+		err  string
+	}{
+		{name: "no remote", args: []string{"a", "b"}, err: "one of the arguments must be a remote location ([user@]droplet:path)"},
+		{name: "multiple downloads", args: []string{"web1:a", "web1:b", "c"}, err: "only one remote file can be downloaded at a time"},
+		{name: "remote to remote", args: []string{"web1:a", "web2:b"}, err: "web1:a: copying between Droplets is not supported"},
+		{name: "tag download", args: []string{":a", "b"}, tags: []string{"web"}, err: "--tag can only be used when uploading"},
+		{name: "tag with droplet", args: []string{"a", "web1:b"}, tags: []string{"web"}, err: "with --tag, the destination must be :<path> without a Droplet"},
+		{name: "no droplet", args: []string{"a", ":b"}, err: "the remote location must name a Droplet or --tag must be given"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+				if tt.tags != nil {
+					config.Doit.Set(config.NS, doctl.ArgTag, tt.tags)
+				}
+				config.Args = tt.args
+				assert.EqualError(t, RunSCP(config), tt.err)
+			})
+		})
+	}
+}
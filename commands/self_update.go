@@ -0,0 +1,136 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/blang/semver"
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/pkg/selfupdate"
+)
+
+// newUpdater returns the updater used by self-update, which verifies releases
+// with the signing key doctl was built with. It is replaced for testing.
+var newUpdater = func() (*selfupdate.Updater, error) {
+	u := selfupdate.New()
+	if doctl.ReleaseSigningKey != "" {
+		key, err := selfupdate.ParsePublicKey(doctl.ReleaseSigningKey)
+		if err != nil {
+			return nil, err
+		}
+		u.PublicKey = key
+	}
+	return u, nil
+}
+
+// currentExecutable returns the path of the running doctl binary. It is replaced for testing.
+var currentExecutable = func() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exe)
+}
+
+// SelfUpdate creates the self-update command.
+func SelfUpdate() *Command {
+	cmd := cmdBuilderWithInit(nil, RunSelfUpdate, "self-update", "Update doctl to the latest release",
+		`Use this command to replace the running doctl binary with the latest release from the selected channel.
+
+The `+"`"+`stable`+"`"+` channel tracks full releases, while `+"`"+`beta`+"`"+` also includes prereleases. The downloaded archive is verified against the SHA-256 checksums published with the release, and the checksums must be signed with the release signing key built into doctl, before anything is installed. The new binary is swapped in atomically and must pass a self test; if it doesn't, the previous binary is restored.
+
+doctl installations managed by a package manager (Homebrew, snap, apt, etc.) should be updated with that package manager instead.`,
+		Writer, false)
+	cmd.GroupID = configureDoctlGroup
+
+	AddStringFlag(cmd, doctl.ArgSelfUpdateChannel, "", selfupdate.ChannelStable, "The release channel to update from. Possible values: `stable` or `beta`")
+	AddBoolFlag(cmd, doctl.ArgSelfUpdateCheck, "", false, "Only report whether an update is available")
+	cmd.Example = `The following example updates doctl to the latest prerelease: doctl self-update --channel beta`
+
+	return cmd
+}
+
+// RunSelfUpdate updates the running doctl binary.
+func RunSelfUpdate(c *CmdConfig) error {
+	channel, err := c.Doit.GetString(c.NS, doctl.ArgSelfUpdateChannel)
+	if err != nil {
+		return err
+	}
+	checkOnly, err := c.Doit.GetBool(c.NS, doctl.ArgSelfUpdateCheck)
+	if err != nil {
+		return err
+	}
+
+	updater, err := newUpdater()
+	if err != nil {
+		return err
+	}
+	release, err := updater.Latest(channel)
+	if err != nil {
+		return err
+	}
+
+	current := doctl.DoitVersion.String()
+	if !isNewerRelease(release.Version(), current) {
+		fmt.Fprintf(c.Out, "doctl %s is already up to date on the %s channel\n", current, channel)
+		return nil
+	}
+
+	if checkOnly {
+		fmt.Fprintf(c.Out, "doctl %s is available (current version %s)\n", release.Version(), current)
+		return nil
+	}
+
+	target, err := currentExecutable()
+	if err != nil {
+		return fmt.Errorf("locating the doctl binary: %w", err)
+	}
+
+	tmp, err := os.MkdirTemp("", "doctl-update")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	binary, err := updater.Download(release, tmp)
+	if err != nil {
+		return err
+	}
+
+	if err := selfupdate.Replace(target, binary, selfupdate.VersionSelfTest); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.Out, "Updated doctl from %s to %s\n", current, release.Version())
+	return nil
+}
+
+// isNewerRelease reports whether the release version is newer than the running version.
+// Development builds are always considered out of date.
+func isNewerRelease(release, current string) bool {
+	rv, err := semver.Make(release)
+	if err != nil {
+		return false
+	}
+	cv, err := semver.Make(current)
+	if err != nil {
+		return true
+	}
+	// Released binaries carry a "-release" label, which semver treats as a prerelease.
+	cv.Pre = nil
+	return rv.GT(cv)
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/pkg/selfupdate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsNewerRelease(t *testing.T) {
+	assert.True(t, isNewerRelease("1.2.0", "1.1.0-release"))
+	assert.True(t, isNewerRelease("1.2.0-beta.1", "1.1.0-release"))
+	assert.True(t, isNewerRelease("1.2.0", "0.0.0-dev"))
+	assert.False(t, isNewerRelease("1.2.0", "1.2.0-release"))
+	assert.False(t, isNewerRelease("1.1.0", "1.2.0-release"))
+	assert.False(t, isNewerRelease("not-a-version", "1.2.0-release"))
+}
+
+func TestRunSelfUpdateCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]selfupdate.Release{{TagName: "v99.0.0"}})
+	}))
+	defer srv.Close()
+
+	origUpdater := newUpdater
+	newUpdater = func() (*selfupdate.Updater, error) {
+		u := selfupdate.New()
+		u.ReleasesURL = srv.URL
+		return u, nil
+	}
+	defer func() { newUpdater = origUpdater }()
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var buf bytes.Buffer
+		config.Out = &buf
+		config.Doit.Set(config.NS, doctl.ArgSelfUpdateChannel, selfupdate.ChannelStable)
+		config.Doit.Set(config.NS, doctl.ArgSelfUpdateCheck, true)
+
+		err := RunSelfUpdate(config)
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "doctl 99.0.0 is available")
+	})
+}
+
+func TestSelfUpdateFlags(t *testing.T) {
+	// self-update is built without a parent, so its flags must be read from
+	// the namespace they were bound under.
+	cmd := childCommand(newRootCommand(), "self-update")
+	require.NoError(t, cmd.ParseFlags([]string{"--channel", "beta", "--check"}))
+
+	config := &doctl.LiveConfig{}
+	channel, err := config.GetString(cmdNS(cmd), doctl.ArgSelfUpdateChannel)
+	require.NoError(t, err)
+	assert.Equal(t, selfupdate.ChannelBeta, channel)
+	check, err := config.GetBool(cmdNS(cmd), doctl.ArgSelfUpdateCheck)
+	require.NoError(t, err)
+	assert.True(t, check)
+}
@@ -0,0 +1,413 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/charm"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/spf13/viper"
+)
+
+// JSON-RPC 2.0 error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcCommandFailed  = -32000
+)
+
+// Serve creates the serve command.
+func Serve() *Command {
+	cmd := cmdBuilderWithInit(nil, RunServe, "serve", "Serve doctl commands over a local JSON-RPC API",
+		`Use this command to run doctl as a daemon that other tools, such as editors and TUIs, can call without starting a doctl process for each operation.
+
+The daemon speaks JSON-RPC 2.0, one JSON message per line, on a Unix socket (`+"`"+`unix:///path/to/socket`+"`"+`) or a loopback TCP address (`+"`"+`tcp://127.0.0.1:port`+"`"+`). Unix sockets are created readable and writable only by the current user. It supports two methods:
+
+- `+"`"+`run`+"`"+` runs a doctl command. Its params are `+"`"+`args`+"`"+`, the command line without the leading `+"`"+`doctl`+"`"+`, and optionally `+"`"+`access_token`+"`"+` or `+"`"+`context`+"`"+`. The result's `+"`"+`output`+"`"+` is the command's JSON output, or its text output for commands without structured output.
+- `+"`"+`schema`+"`"+` returns the JSON Schema of a command's output, like `+"`"+`doctl schema`+"`"+`. Its only param is `+"`"+`args`+"`"+`.
+
+A request that carries an access token is run with that token, so a single daemon can serve clients that authenticate separately. Other requests use the daemon's own credentials, unless `+"`"+`--require-token`+"`"+` is set. Any local user can connect to a TCP address, so TCP listeners require `+"`"+`--require-token`+"`"+`. Global flags such as `+"`"+`--access-token`+"`"+` and `+"`"+`--output`+"`"+` are not accepted in `+"`"+`args`+"`"+`.
+
+Requests are run one at a time, so commands that run until they are interrupted, such as `+"`"+`events watch`+"`"+` or `+"`"+`apps logs --follow`+"`"+`, and commands that act on the daemon's terminal, files, or binary, such as `+"`"+`compute ssh`+"`"+`, `+"`"+`auth init`+"`"+`, or `+"`"+`self-update`+"`"+`, are refused.`,
+		Writer, false)
+	cmd.GroupID = configureDoctlGroup
+	AddStringFlag(cmd, doctl.ArgServeListen, "", "", "The address to listen on, either `unix:///path/to/socket` or `tcp://127.0.0.1:port`", requiredOpt())
+	AddBoolFlag(cmd, doctl.ArgServeRequireToken, "", false, "Reject requests that do not carry their own access token")
+	cmd.Example = `The following example serves doctl on a Unix socket and lists Droplets through it: doctl serve --listen unix:///tmp/doctl.sock & echo '{"jsonrpc": "2.0", "id": 1, "method": "run", "params": {"args": ["compute", "droplet", "list"]}}' | nc -U /tmp/doctl.sock`
+
+	return cmd
+}
+
+// RunServe serves doctl commands until it is interrupted.
+func RunServe(c *CmdConfig) error {
+	listen, err := c.Doit.GetString(c.NS, doctl.ArgServeListen)
+	if err != nil {
+		return err
+	}
+	requireToken, err := c.Doit.GetBool(c.NS, doctl.ArgServeRequireToken)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(listen, "tcp://") && !requireToken {
+		return fmt.Errorf("listening on a TCP address requires --%s, since any local user can connect to it", doctl.ArgServeRequireToken)
+	}
+
+	l, err := serveListener(listen)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	// Commands run by the daemon always write JSON, and can't prompt or read
+	// the daemon's standard input.
+	viper.Set(doctl.ArgOutput, "json")
+	Interactive = false
+	charm.PromptsDisabled = true
+	if devNull, err := os.Open(os.DevNull); err == nil {
+		defer devNull.Close()
+		os.Stdin = devNull
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+	go func() {
+		<-sigs
+		l.Close()
+	}()
+
+	fmt.Fprintf(c.Out, "Serving doctl on %s\n", listen)
+	s := &rpcServer{requireToken: requireToken}
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// serveListener listens on a unix:// or tcp:// address. TCP addresses must be
+// on the loopback interface, since requests may use the daemon's credentials.
+func serveListener(address string) (net.Listener, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid listen address %q: %w", address, err)
+	}
+
+	switch u.Scheme {
+	case "unix":
+		path := u.Path
+		if path == "" {
+			return nil, fmt.Errorf("invalid listen address %q: missing socket path", address)
+		}
+		if _, err := os.Stat(path); err == nil {
+			if conn, err := net.Dial("unix", path); err == nil {
+				conn.Close()
+				return nil, fmt.Errorf("%s is already in use", path)
+			}
+			// A stale socket left behind by a daemon that didn't exit cleanly.
+			if err := os.Remove(path); err != nil {
+				return nil, err
+			}
+		}
+		l, err := net.Listen("unix", path)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Chmod(path, 0600); err != nil {
+			l.Close()
+			return nil, err
+		}
+		return l, nil
+	case "tcp":
+		host := u.Hostname()
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return nil, fmt.Errorf("invalid listen address %q: TCP addresses must be on the loopback interface", address)
+		}
+		return net.Listen("tcp", u.Host)
+	default:
+		return nil, fmt.Errorf("invalid listen address %q: the scheme must be unix or tcp", address)
+	}
+}
+
+// rpcRefusedCommands are the commands, and the groups of commands, that the
+// daemon doesn't run: they never finish, use the daemon's terminal, or change
+// its configuration, files, or binary for every client.
+var rpcRefusedCommands = []string{
+	"apps deployment watch",
+	"apps dev",
+	"auth init",
+	"auth remove",
+	"auth switch",
+	"auth token rotate",
+	"cacheproxy",
+	"compute droplet exec",
+	"compute droplet top",
+	"compute load-balancer watch-certificates",
+	"compute plugin run",
+	"compute ssh",
+	"cron",
+	"events watch",
+	"inventory watch",
+	"kubernetes cluster kubeconfig",
+	"registry login",
+	"self-update",
+	"serve",
+	"serverless connect",
+	"serverless install",
+	"serverless uninstall",
+	"serverless upgrade",
+	"serverless watch",
+	"tui",
+}
+
+// rpcRefused reports whether cmd can't be run by the daemon, either because
+// it is one of rpcRefusedCommands or because it was asked to follow output.
+func rpcRefused(cmd *Command) bool {
+	path := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	for _, refused := range rpcRefusedCommands {
+		if path == refused || strings.HasPrefix(path, refused+" ") {
+			return true
+		}
+	}
+	follow := cmd.Flags().Lookup(flagFollow)
+	return follow != nil && follow.Changed && follow.Value.String() == "true"
+}
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcRunParams struct {
+	Args        []string `json:"args"`
+	AccessToken string   `json:"access_token,omitempty"`
+	Context     string   `json:"context,omitempty"`
+}
+
+type rpcRunResult struct {
+	Output any `json:"output"`
+}
+
+// rpcServer serves JSON-RPC requests. Commands read their flags and
+// credentials from global state, so requests are run one at a time.
+type rpcServer struct {
+	requireToken bool
+
+	mu sync.Mutex
+}
+
+// serveConn serves the requests on conn until it is closed.
+func (s *rpcServer) serveConn(conn io.ReadWriteCloser) {
+	defer conn.Close()
+
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+	for {
+		var req rpcRequest
+		if err := dec.Decode(&req); err != nil {
+			if !errors.Is(err, io.EOF) {
+				// The stream can't be resynchronized after a syntax error.
+				enc.Encode(rpcResponse{
+					JSONRPC: "2.0",
+					ID:      json.RawMessage("null"),
+					Error:   &rpcError{Code: rpcParseError, Message: err.Error()},
+				})
+			}
+			return
+		}
+
+		res := s.handle(&req)
+		if req.ID == nil {
+			// Notifications get no response.
+			continue
+		}
+		if err := enc.Encode(res); err != nil {
+			return
+		}
+	}
+}
+
+func (s *rpcServer) handle(req *rpcRequest) rpcResponse {
+	res := rpcResponse{JSONRPC: "2.0", ID: req.ID}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		res.Error = &rpcError{Code: rpcInvalidRequest, Message: "invalid JSON-RPC 2.0 request"}
+		return res
+	}
+
+	var params rpcRunParams
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			res.Error = &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+			return res
+		}
+	}
+	if len(params.Args) == 0 {
+		res.Error = &rpcError{Code: rpcInvalidParams, Message: "args is required"}
+		return res
+	}
+
+	var (
+		result any
+		err    error
+	)
+	switch req.Method {
+	case "run":
+		if s.requireToken && params.AccessToken == "" {
+			res.Error = &rpcError{Code: rpcInvalidParams, Message: "access_token is required"}
+			return res
+		}
+		result, err = s.run(params)
+	case "schema":
+		result, err = s.schema(params)
+	default:
+		res.Error = &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("unknown method %q", req.Method)}
+		return res
+	}
+	if err != nil {
+		res.Error = &rpcError{Code: rpcCommandFailed, Message: err.Error()}
+		return res
+	}
+	res.Result = result
+	return res
+}
+
+// run runs a command as doctl would if it were given params.Args.
+func (s *rpcServer) run(params rpcRunParams) (result any, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer useCredentials(params.AccessToken, params.Context)()
+
+	// Flags keep their values once parsed, so each request is parsed by a new
+	// command tree.
+	cmd, args := splitCommandArgs(newRootCommand(), params.Args)
+	if cmd.runner == nil {
+		return nil, fmt.Errorf("%s can't be run over RPC", cmd.CommandPath())
+	}
+	// The flags are checked as cobra checks them when it runs a command.
+	if err := cmd.ParseFlags(args); err != nil {
+		return nil, err
+	}
+	if rpcRefused(cmd) {
+		return nil, fmt.Errorf("%s can't be run over RPC", cmd.CommandPath())
+	}
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return nil, err
+	}
+	if err := cmd.ValidateFlagGroups(); err != nil {
+		return nil, err
+	}
+	if err := checkPolicy(cmd, cmd.Flags().Args()); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	config, err := NewCmdConfig(cmdNS(cmd), &doctl.LiveConfig{}, &out, cmd.Flags().Args(), cmd.initCmd)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s: %v", cmd.CommandPath(), r)
+		}
+	}()
+	if err := cmd.runner(config); err != nil {
+		return nil, err
+	}
+
+	if json.Valid(out.Bytes()) {
+		return rpcRunResult{Output: json.RawMessage(bytes.TrimSpace(out.Bytes()))}, nil
+	}
+	return rpcRunResult{Output: out.String()}, nil
+}
+
+// schema returns the JSON Schema of a command's output.
+func (s *rpcServer) schema(params rpcRunParams) (any, error) {
+	cmd, err := findCommand(DoitCmd, params.Args)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.displayer == nil {
+		return nil, fmt.Errorf("%s has no structured output", cmd.CommandPath())
+	}
+	return displayers.JSONSchema(cmd.displayer, cmd.CommandPath()), nil
+}
+
+// useCredentials makes commands use the given access token or context, as if
+// they had been passed as flags, and returns a func that restores the previous ones.
+func useCredentials(token, context string) (restore func()) {
+	flag := DoitCmd.PersistentFlags().Lookup(doctl.ArgAccessToken)
+	prevToken, prevChanged, prevContext := Token, flag.Changed, Context
+
+	if token != "" {
+		Token = token
+		flag.Changed = true
+	}
+	if context != "" {
+		Context = strings.ToLower(context)
+	}
+
+	return func() {
+		Token, flag.Changed, Context = prevToken, prevChanged, prevContext
+	}
+}
+
+// splitCommandArgs splits args into the command they name below root and the
+// command's own arguments and flags.
+func splitCommandArgs(root *Command, args []string) (*Command, []string) {
+	cmd := root
+	for len(args) > 0 {
+		next := childCommand(cmd, args[0])
+		if next == nil {
+			break
+		}
+		cmd, args = next, args[1:]
+	}
+	return cmd, args
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rpcCall sends a request to s and returns the decoded response.
+func rpcCall(t *testing.T, s *rpcServer, req string) map[string]any {
+	t.Helper()
+	client, server := net.Pipe()
+	go s.serveConn(server)
+	defer client.Close()
+
+	_, err := client.Write([]byte(req + "\n"))
+	require.NoError(t, err)
+
+	line, err := bufio.NewReader(client).ReadBytes('\n')
+	require.NoError(t, err)
+	var res map[string]any
+	require.NoError(t, json.Unmarshal(line, &res))
+	return res
+}
+
+func TestRPCServer(t *testing.T) {
+	prev := viper.GetString(doctl.ArgOutput)
+	viper.Set(doctl.ArgOutput, "json")
+	defer viper.Set(doctl.ArgOutput, prev)
+
+	spec := testTempFile(t, []byte("name: test\nservices:\n- name: web\n  image:\n    registry_type: DOCR\n    repository: web\n"))
+
+	t.Run("run", func(t *testing.T) {
+		res := rpcCall(t, &rpcServer{}, `{"jsonrpc": "2.0", "id": 1, "method": "run", "params": {"args": ["apps", "spec", "lint", "`+spec+`", "--max-instances", "5"]}}`)
+		require.Nil(t, res["error"])
+		assert.Equal(t, float64(1), res["id"])
+		assert.Equal(t, map[string]any{
+			"output": []any{map[string]any{
+				"component": "web",
+				"rule":      lintRuleMissingHealthCheck,
+				"severity":  lintSeverityWarning,
+				"message":   "service has no health check; deployments are considered healthy as soon as the container starts",
+			}},
+		}, res["result"])
+	})
+
+	t.Run("command error", func(t *testing.T) {
+		res := rpcCall(t, &rpcServer{}, `{"jsonrpc": "2.0", "id": 2, "method": "run", "params": {"args": ["apps", "spec", "lint", "`+spec+`", "--strict"]}}`)
+		assert.Equal(t, map[string]any{
+			"code":    float64(rpcCommandFailed),
+			"message": "app spec lint found 0 error(s) and 1 warning(s)",
+		}, res["error"])
+	})
+
+	t.Run("not runnable", func(t *testing.T) {
+		res := rpcCall(t, &rpcServer{}, `{"jsonrpc": "2.0", "id": 3, "method": "run", "params": {"args": ["compute"]}}`)
+		assert.Equal(t, "doctl compute can't be run over RPC", res["error"].(map[string]any)["message"])
+	})
+
+	t.Run("refused", func(t *testing.T) {
+		for _, args := range []string{`"serve", "--listen", "unix:///tmp/x.sock"`, `"tui"`, `"events", "watch"`, `"compute", "ssh", "web"`, `"auth", "init"`, `"cron", "install", "x"`, `"apps", "logs", "app-id", "--follow"`} {
+			res := rpcCall(t, &rpcServer{}, `{"jsonrpc": "2.0", "id": 3, "method": "run", "params": {"args": [`+args+`]}}`)
+			assert.Contains(t, res["error"].(map[string]any)["message"], "can't be run over RPC", args)
+		}
+	})
+
+	t.Run("require token", func(t *testing.T) {
+		res := rpcCall(t, &rpcServer{requireToken: true}, `{"jsonrpc": "2.0", "id": 4, "method": "run", "params": {"args": ["apps", "list"]}}`)
+		assert.Equal(t, map[string]any{
+			"code":    float64(rpcInvalidParams),
+			"message": "access_token is required",
+		}, res["error"])
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		res := rpcCall(t, &rpcServer{}, `{"jsonrpc": "2.0", "id": "a", "method": "schema", "params": {"args": ["apps", "list"]}}`)
+		require.Nil(t, res["error"])
+		assert.Equal(t, "a", res["id"])
+		assert.Equal(t, "doctl apps list", res["result"].(map[string]any)["title"])
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		res := rpcCall(t, &rpcServer{}, `{"jsonrpc": "2.0", "id": 5, "method": "exec", "params": {"args": ["apps", "list"]}}`)
+		assert.Equal(t, float64(rpcMethodNotFound), res["error"].(map[string]any)["code"])
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		res := rpcCall(t, &rpcServer{}, `{"jsonrpc": ]`)
+		assert.Nil(t, res["id"])
+		assert.Equal(t, float64(rpcParseError), res["error"].(map[string]any)["code"])
+	})
+}
+
+func TestUseCredentials(t *testing.T) {
+	prevToken, prevContext := Token, Context
+	restore := useCredentials("request-token", "Other")
+	assert.Equal(t, "request-token", Token)
+	assert.Equal(t, "other", Context)
+	assert.True(t, DoitCmd.PersistentFlags().Lookup(doctl.ArgAccessToken).Changed)
+
+	restore()
+	assert.Equal(t, prevToken, Token)
+	assert.Equal(t, prevContext, Context)
+}
+
+func TestRunServeTCPRequiresToken(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Doit.Set(config.NS, doctl.ArgServeListen, "tcp://127.0.0.1:0")
+
+		err := RunServe(config)
+		assert.ErrorContains(t, err, "requires --require-token")
+	})
+}
+
+func TestServeListener(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doctl.sock")
+	l, err := serveListener("unix://" + path)
+	require.NoError(t, err)
+	defer l.Close()
+
+	_, err = serveListener("unix://" + path)
+	assert.EqualError(t, err, path+" is already in use")
+
+	_, err = serveListener("tcp://0.0.0.0:0")
+	assert.ErrorContains(t, err, "TCP addresses must be on the loopback interface")
+
+	_, err = serveListener("http://localhost:8080")
+	assert.ErrorContains(t, err, "the scheme must be unix or tcp")
+}
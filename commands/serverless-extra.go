@@ -19,6 +19,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/do"
 	"gopkg.in/yaml.v3"
 )
@@ -41,7 +42,25 @@ to choose your sample language for `+"`"+`doctl serverless init`+"`"+`.`,
 	deploy := CmdBuilder(cmd, RunServerlessExtraDeploy, "deploy <directory>", "Deploy a functions project to your functions namespace",
 		`At any time you can use `+"`"+`doctl serverless deploy`+"`"+` to upload the contents of a functions project in your file system for
 testing in your serverless namespace.  The project must be organized in the fashion expected by an App Platform Functions
-component.  The `+"`"+`doctl serverless init`+"`"+` command will create a properly organized directory for you to work in.`,
+component.  The `+"`"+`doctl serverless init`+"`"+` command will create a properly organized directory for you to work in.
+The deployment runs with only the environment variables it needs, such as PATH, HOME, proxy and locale settings, and
+the settings of npm, yarn, Go, and pip, so the settings of a separate nim install don't affect it.
+
+The project's `+"`"+`project.yml`+"`"+` can declare `+"`"+`preDeploy`+"`"+` and `+"`"+`postDeploy`+"`"+` hooks, which are run in order before and
+after a successful deployment. Each hook is either a shell command (`+"`"+`run`+"`"+`) run in the project directory, or an HTTP
+request (`+"`"+`http`+"`"+`, with `+"`"+`url`+"`"+`, `+"`"+`method`+"`"+`, `+"`"+`headers`+"`"+`, and `+"`"+`body`+"`"+`) whose fields are Go templates using
+`+"`"+`.Namespace`+"`"+`, `+"`"+`.APIHost`+"`"+`, and `+"`"+`.Functions`+"`"+` (a map of web function names such as `+"`"+`sample/hello`+"`"+` to their URLs).
+Shell hooks get the same information in DOCTL_FUNCTIONS_NAMESPACE, DOCTL_FUNCTIONS_API_HOST, DOCTL_FUNCTION_URLS (JSON),
+and one DOCTL_FUNCTION_URL_<PACKAGE>_<FUNCTION> variable per web function. A failing hook aborts the deployment pipeline:
+a failed pre-deploy hook prevents the deployment and a failed post-deploy hook makes the command fail.
+
+  preDeploy:
+    - run: npm test
+  postDeploy:
+    - run: ./smoke-test.sh "$DOCTL_FUNCTION_URL_SAMPLE_HELLO"
+    - http:
+        url: https://hooks.example.com/deployed
+        body: '{"url": "{{index .Functions "sample/hello"}}"}'`,
 		Writer)
 	AddStringFlag(deploy, "env", "", "", "Path to runtime environment file")
 	AddStringFlag(deploy, "build-env", "", "", "Path to build-time environment file")
@@ -57,6 +76,44 @@ component.  The `+"`"+`doctl serverless init`+"`"+` command will create a proper
 	AddBoolFlag(deploy, "incremental", "", false, "Deploy only changes since last deploy")
 	AddBoolFlag(deploy, "no-triggers", "", false, "")
 	deploy.Flags().MarkHidden("no-triggers")
+	AddBoolFlag(deploy, flagSBOM, "", false, "Record an SBOM of the project's dependencies, retrievable with `doctl serverless sbom <deploy-id>`")
+	AddStringFlag(deploy, doctl.ArgServerlessFromArtifact, "", "", "Deploy a tarball written by the pack command instead of a project directory")
+	AddStringFlag(deploy, doctl.ArgServerlessVerifyKey, "", "", "A PEM-encoded ECDSA public key to check the signature of the artifact with before deploying it")
+
+	pack := cmdBuilderWithInit(cmd, RunServerlessPack, "pack <directory>", "Package a functions project into a deployable artifact",
+		`The `+"`"+`doctl serverless pack`+"`"+` command builds the functions of a project and packages them into a single gzipped tarball,
+which `+"`"+`doctl serverless deploy --from-artifact`+"`"+` deploys. This lets a CI pipeline build once and deploy the identical
+bytes to several namespaces.
+
+The `+"`"+`--include`+"`"+` and `+"`"+`--exclude`+"`"+` flags select packages and functions as they do for `+"`"+`doctl serverless deploy`+"`"+`.
+Functions with a `+"`"+`build.sh`+"`"+` script (`+"`"+`build.cmd`+"`"+` on Windows) are built when packing, and the script is left out of
+the artifact so that they are deployed as built. Web content and the project's `+"`"+`.env`+"`"+` file are never packed; give
+environment files with `+"`"+`--env`+"`"+` when deploying. Packing the same files always produces the same artifact, whose
+SHA-256 digest is shown.
+
+The artifact records its provenance: the git commit the project was checked out at, if any, and the builder given with
+`+"`"+`--builder`+"`"+`. When it is deployed, the provenance and the artifact's digest are recorded in the `+"`"+`provenance`+"`"+` annotation
+of each function. With `+"`"+`--sign-key`+"`"+`, the artifact is also signed with an unencrypted PEM-encoded ECDSA private key and the
+signature written next to it with a `+"`"+`.sig`+"`"+` suffix. The signature is compatible with `+"`"+`cosign verify-blob`+"`"+`, and is checked by
+`+"`"+`doctl serverless deploy --verify-key`+"`"+` and `+"`"+`doctl serverless verify`+"`"+`.`,
+		Writer, false)
+	AddStringFlag(pack, doctl.ArgOutput, "o", "", "The file to write the artifact to", requiredOpt())
+	AddStringFlag(pack, flagInclude, "", "", "Functions and/or packages to include")
+	AddStringFlag(pack, flagExclude, "", "", "Functions and/or packages to exclude")
+	AddStringFlag(pack, doctl.ArgServerlessSignKey, "", "", "A PEM-encoded ECDSA private key to sign the artifact with")
+	AddStringFlag(pack, doctl.ArgServerlessBuilder, "", "", "What is packing the artifact, such as a CI pipeline, to record in its provenance (default: the doctl version)")
+	pack.Example = `The following example packs a project in CI and deploys the artifact to the connected namespace: doctl serverless pack ./my-project -o bundle.tar.gz && doctl serverless deploy --from-artifact bundle.tar.gz`
+
+	verify := CmdBuilder(cmd, RunServerlessVerify, "verify <function>", "Check that a deployed function matches a signed artifact",
+		`The `+"`"+`doctl serverless verify`+"`"+` command checks that the code of a deployed function is the code in a signed artifact written by
+`+"`"+`doctl serverless pack --sign-key`+"`"+`. It checks the signature of the artifact, that the function's `+"`"+`provenance`+"`"+` annotation records
+the artifact as the one it was deployed from, and that every file of the deployed code is in the artifact with the same contents.
+The provenance of the artifact is shown.`,
+		Writer)
+	AddStringFlag(verify, doctl.ArgServerlessArtifact, "", "", "The artifact the function was deployed from", requiredOpt())
+	AddStringFlag(verify, doctl.ArgServerlessKey, "", "", "The PEM-encoded ECDSA public key the artifact was signed with", requiredOpt())
+	AddStringFlag(verify, doctl.ArgServerlessSignature, "", "", "The signature of the artifact (default: the artifact with a .sig suffix)")
+	verify.Example = `The following example checks function ` + "`" + `sample/hello` + "`" + ` against a signed artifact: doctl serverless verify sample/hello --artifact bundle.tar.gz --key cosign.pub`
 
 	getMetadata := cmdBuilderWithInit(cmd, RunServerlessExtraGetMetadata, "get-metadata <directory>", "Obtain metadata of a functions project",
 		`The `+"`"+`doctl serverless get-metadata`+"`"+` command produces a JSON structure that summarizes the contents of a functions
@@ -166,10 +223,57 @@ You may deploy it by running the command shown on the next line:
 // RunServerlessExtraDeploy supports the 'serverless deploy' command
 func RunServerlessExtraDeploy(c *CmdConfig) error {
 	adjustIncludeAndExclude(c)
+	artifact, _ := c.Doit.GetString(c.NS, doctl.ArgServerlessFromArtifact)
+	if artifact != "" {
+		if len(c.Args) > 0 {
+			return fmt.Errorf("a project directory and --%s are mutually exclusive", doctl.ArgServerlessFromArtifact)
+		}
+		dir, digest, err := extractArtifact(artifact)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		provenance, err := readArtifactProvenance(dir)
+		if err != nil {
+			return err
+		}
+		provenance.Artifact = "sha256:" + digest
+		if verifyKey, _ := c.Doit.GetString(c.NS, doctl.ArgServerlessVerifyKey); verifyKey != "" {
+			if provenance.Signer, err = verifyArtifactSignature(artifact, verifyKey, artifact+".sig"); err != nil {
+				return err
+			}
+		}
+		if err := annotateProvenance(dir, provenance); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.Out, "Deploying %s (sha256:%s)\n", artifact, digest)
+		c.Args = []string{dir}
+	}
 	err := ensureOneArg(c)
 	if err != nil {
 		return err
 	}
+	hooks, err := loadDeployHooks(c.Args[0])
+	if err != nil {
+		return err
+	}
+	// The dependencies are scanned before deploying, so that the SBOM describes what was deployed
+	// even if a build step changes the project.
+	var record *deployRecord
+	if sbom, _ := c.Doit.GetBool(c.NS, flagSBOM); sbom {
+		if record, err = newDeployRecord(c.Args[0]); err != nil {
+			return err
+		}
+	}
+	if len(hooks.PreDeploy) > 0 {
+		hc, err := newDeployHookContext(c, "preDeploy", false)
+		if err != nil {
+			return err
+		}
+		if err := runDeployHooks(c, c.Args[0], hooks.PreDeploy, hc); err != nil {
+			return err
+		}
+	}
 	// In a snap, local build will not work so ensure that builds (if any) will run remotely
 	_, isSnap := os.LookupEnv("SNAP")
 	if isSnap {
@@ -193,7 +297,26 @@ func RunServerlessExtraDeploy(c *CmdConfig) error {
 	}
 	if err == nil {
 		// Normal error-free return
-		return c.PrintServerlessTextOutput(output)
+		if err := c.PrintServerlessTextOutput(output); err != nil {
+			return err
+		}
+		if record != nil {
+			if creds, err := c.Serverless().ReadCredentials(); err == nil {
+				record.Namespace = creds.Namespace
+			}
+			if err := saveDeployRecord(record); err != nil {
+				return err
+			}
+			fmt.Fprintf(c.Out, "Recorded an SBOM of %d dependencies for deploy %s ('doctl serverless sbom %s' to retrieve it)\n", len(record.Components), record.ID, record.ID)
+		}
+		if len(hooks.PostDeploy) == 0 {
+			return nil
+		}
+		hc, err := newDeployHookContext(c, "postDeploy", true)
+		if err != nil {
+			return err
+		}
+		return runDeployHooks(c, c.Args[0], hooks.PostDeploy, hc)
 	}
 	// When there is an error but also a transcript, display the transcript before return the error
 	// This is "best effort" so we ignore any error returns from the print statement
@@ -226,7 +349,7 @@ func RunServerlessExtraGetMetadata(c *CmdConfig) error {
 	// service is not initialized and we create the necessary object manually.  This permits execution with no credentials as needed
 	// in some contexts (e.g. App Platform detection).
 	args := getFlatArgsArray(c, []string{flagJSON, flagNoTriggers}, []string{flagEnv, flagInclude, flagExclude})
-	sls := do.NewServerlessService(nil, getServerlessDirectory(), "")
+	sls := do.NewServerlessService(nil, getServerlessDirectory(), "", serverlessPluginTimeout())
 	output, err := serverlessExecNoCheck(sls, cmdGetMetadata, args)
 	if err != nil {
 		return err
@@ -23,11 +23,14 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/charm"
 	"github.com/digitalocean/doctl/commands/charm/template"
 	"github.com/digitalocean/doctl/do"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var (
@@ -73,6 +76,10 @@ Other ` + "`" + `doctl serverless` + "`" + ` commands are used to develop, test,
 		},
 	}
 
+	cmd.PersistentFlags().Duration(doctl.ArgServerlessPluginTimeout, 30*time.Minute,
+		"How long a serverless operation, such as a deploy, may run before it is stopped. 0 means no limit. Streaming commands, such as watch, are not limited")
+	viper.BindPFlag(doctl.ArgServerlessPluginTimeout, cmd.PersistentFlags().Lookup(doctl.ArgServerlessPluginTimeout))
+
 	cmdBuilderWithInit(cmd, RunServerlessInstall, "install", "Installs the serverless support",
 		`This command installs additional software under `+"`"+`doctl`+"`"+` needed to make the other serverless commands work.
 The install operation is long-running, and a network connection is required.`,
@@ -134,8 +141,32 @@ the entire packages are removed.`, Writer)
 	undeploy.Flags().MarkHidden("apihost")
 	undeploy.Flags().MarkHidden("auth")
 
+	promote := CmdBuilder(cmd, RunServerlessPromote, "promote", "Copies deployed functions from one namespace to another",
+		`This command copies deployed functions, with their code, configuration, and packages, from one functions namespace
+to another, so that exactly what was tested is promoted instead of being rebuilt from source. The namespaces are given
+by their full label or id and need not be the one you are connected to. A preview of the new and changed functions is
+shown first, and you are prompted for confirmation unless `+"`"+`--force`+"`"+` is specified.`,
+		Writer)
+	AddStringFlag(promote, doctl.ArgServerlessPromoteFrom, "", "", "the namespace to promote functions from", requiredOpt())
+	AddStringFlag(promote, doctl.ArgServerlessPromoteTo, "", "", "the namespace to promote functions to", requiredOpt())
+	AddStringSliceFlag(promote, doctl.ArgServerlessPromoteOnly, "", []string{}, "promote only these packages or functions, in `pkgName` or `pkgName/fnName` form")
+	AddBoolFlag(promote, doctl.ArgDryRun, "", false, "only show the preview")
+	AddBoolFlag(promote, doctl.ArgForce, doctl.ArgShortForce, false, "Promote without confirmation prompt")
+	promote.Example = `The following example promotes the ` + "`" + `api` + "`" + ` package from the ` + "`" + `dev-ns` + "`" + ` namespace to the ` + "`" + `prod-ns` + "`" + ` namespace: doctl serverless promote --from dev-ns --to prod-ns --only api`
+
+	sbom := CmdBuilder(cmd, RunServerlessSBOM, "sbom <deploy-id>", "Retrieves the SBOM of a deployment",
+		`This command retrieves the software bill of materials (SBOM) recorded by `+"`"+`doctl serverless deploy --sbom`+"`"+`.
+The SBOM lists the dependencies declared in the package.json (or package-lock.json), go.mod, and requirements.txt
+files of the project when it was deployed. Deploy records are kept in the doctl configuration directory of the
+machine that deployed the project.`,
+		Writer)
+	AddStringFlag(sbom, doctl.ArgServerlessSBOMFormat, "", "cyclonedx", "the SBOM standard to use: `cyclonedx` or `spdx`")
+	sbom.Example = `The following example saves the SBOM of a deployment in SPDX format: doctl serverless sbom 20240102T030405Z-a1b2c3 --sbom-format spdx > sbom.spdx.json`
+
 	cmd.AddCommand(Activations())
+	cmd.AddCommand(ServerlessDomains())
 	cmd.AddCommand(Functions())
+	cmd.AddCommand(KeepWarm())
 	cmd.AddCommand(Namespaces())
 	cmd.AddCommand(Triggers())
 	ServerlessExtras(cmd)
@@ -164,7 +195,7 @@ func RunServerlessInstall(c *CmdConfig) error {
 		} else {
 			serverlessDir = getServerlessDirectory()
 		}
-		serverless = do.NewServerlessService(nil, serverlessDir, "")
+		serverless = do.NewServerlessService(nil, serverlessDir, "", serverlessPluginTimeout())
 		status = do.ErrServerlessNotInstalled
 	} else {
 		if err := c.initServices(c); err != nil {
@@ -290,6 +321,9 @@ func connectFromList(ctx context.Context, sls do.ServerlessService, list []do.Ou
 	if len(list) == 1 {
 		ns = list[0]
 	} else {
+		if charm.PromptsDisabled {
+			return fmt.Errorf("%d namespaces match; pass the ID or label of one of them", len(list))
+		}
 		ns = chooseFromList(list, out)
 		if ns.Namespace == "" {
 			return nil
@@ -317,7 +351,7 @@ func chooseFromList(list []do.OutputNamespace, out io.Writer) do.OutputNamespace
 		fmt.Fprintln(out, "Choose a namespace by number or 'x' to exit")
 		choice, err := connectChoiceReader.ReadString('\n')
 		if err != nil {
-			continue
+			return do.OutputNamespace{}
 		}
 		choice = strings.TrimSpace(choice)
 		if choice == "x" {
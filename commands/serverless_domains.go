@@ -0,0 +1,195 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/cobra"
+)
+
+// serverlessDomainHTTPClient invokes functions through a custom domain. It is replaced for testing.
+var serverlessDomainHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// ServerlessDomains generates the serverless 'domains' subtree for addition to the doctl command
+func ServerlessDomains() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "domains",
+			Short: "Manage custom domains for your functions namespace",
+			Long: `The subcommands of ` + "`" + `doctl serverless domains` + "`" + ` point custom domains managed by DigitalOcean DNS
+at the API host of the functions namespace you are connected to.`,
+			Aliases: []string{"domain"},
+		},
+	}
+
+	add := CmdBuilder(cmd, RunServerlessDomainsAdd, "add <domain>", "Point a custom domain at your functions namespace",
+		`Points a custom domain at the API host of the functions namespace you are connected to:
+
+- creates a CNAME record for the domain, which must be a subdomain of a domain managed by DigitalOcean DNS
+- requests a Let's Encrypt certificate for the domain, unless `+"`"+`--certificate=false`+"`"+` is set
+- invokes a web function through the domain over HTTPS, if `+"`"+`--verify-function`+"`"+` is set
+
+Functions namespaces serve their own certificate and cannot be given a custom one, so HTTPS requests to the domain only succeed once the certificate is attached to a load balancer or CDN that forwards to the namespace. The verification step reports whether that is the case.`,
+		Writer)
+	AddBoolFlag(add, doctl.ArgServerlessDomainCertificate, "", true, "Request a Let's Encrypt certificate for the domain")
+	AddBoolFlag(add, doctl.ArgCommandWait, "", true, "Wait for the certificate to be issued")
+	AddStringFlag(add, doctl.ArgServerlessDomainVerify, "", "", "A web function, such as `sample/hello`, to invoke through the domain to verify it")
+	add.Example = `The following example points ` + "`" + `api.example.com` + "`" + ` at the connected namespace and checks that ` + "`" + `sample/hello` + "`" + ` can be invoked through it: doctl serverless domains add api.example.com --verify-function sample/hello`
+
+	return cmd
+}
+
+// RunServerlessDomainsAdd points a custom domain at the connected namespace's API host.
+func RunServerlessDomainsAdd(c *CmdConfig) error {
+	if err := ensureOneArg(c); err != nil {
+		return err
+	}
+	domain := strings.TrimSuffix(strings.ToLower(c.Args[0]), ".")
+
+	certificate, err := c.Doit.GetBool(c.NS, doctl.ArgServerlessDomainCertificate)
+	if err != nil {
+		return err
+	}
+	wait, err := c.Doit.GetBool(c.NS, doctl.ArgCommandWait)
+	if err != nil {
+		return err
+	}
+	verify, err := c.Doit.GetString(c.NS, doctl.ArgServerlessDomainVerify)
+	if err != nil {
+		return err
+	}
+
+	ss := c.Serverless()
+	if err := ss.CheckServerlessStatus(); err != nil {
+		return err
+	}
+	creds, err := ss.ReadCredentials()
+	if err != nil {
+		return err
+	}
+	apiHost, err := url.Parse(creds.APIHost)
+	if err != nil || apiHost.Hostname() == "" {
+		return fmt.Errorf("the connected namespace has an invalid API host %q", creds.APIHost)
+	}
+	target := apiHost.Hostname() + "."
+
+	zone, name, err := managedZoneFor(c.Domains(), domain)
+	if err != nil {
+		return err
+	}
+	if err := ensureCNAMERecord(c.Domains(), zone, name, target); err != nil {
+		return err
+	}
+
+	if certificate {
+		cert, err := c.Certificates().Create(&godo.CertificateRequest{
+			Name:     "functions-" + strings.ReplaceAll(domain, ".", "-"),
+			DNSNames: []string{domain},
+			Type:     "lets_encrypt",
+		})
+		if err != nil {
+			return err
+		}
+		if wait {
+			if cert, err = waitForVerifiedCertificate(c.Certificates(), cert.ID); err != nil {
+				return err
+			}
+		}
+		notice("Certificate %s (%s) was requested for %s; attach it to a load balancer or CDN in front of the namespace to serve HTTPS", cert.Name, cert.ID, domain)
+	}
+
+	if verify == "" {
+		return nil
+	}
+	endpoint := fmt.Sprintf("https://%s/api/v1/web/%s/%s", domain, creds.Namespace, strings.Trim(verify, "/"))
+	if err := verifyServerlessDomain(endpoint); err != nil {
+		return fmt.Errorf("invoking %s failed: %w", endpoint, err)
+	}
+	notice("Invoked %s successfully", endpoint)
+	return nil
+}
+
+// managedZoneFor returns the domain managed by DigitalOcean DNS that fqdn
+// belongs to, preferring the most specific one, and the name of fqdn relative to it.
+func managedZoneFor(ds do.DomainsService, fqdn string) (string, string, error) {
+	domains, err := ds.List()
+	if err != nil {
+		return "", "", err
+	}
+
+	zone := ""
+	for _, d := range domains {
+		name := strings.ToLower(d.Name)
+		if (fqdn == name || strings.HasSuffix(fqdn, "."+name)) && len(name) > len(zone) {
+			zone = name
+		}
+	}
+	if zone == "" {
+		return "", "", fmt.Errorf("%s is not in a domain managed by DigitalOcean DNS", fqdn)
+	}
+	if zone == fqdn {
+		return "", "", fmt.Errorf("%s is the apex of its domain, which cannot have a CNAME record; use a subdomain instead", fqdn)
+	}
+	return zone, strings.TrimSuffix(fqdn, "."+zone), nil
+}
+
+// ensureCNAMERecord creates a CNAME record unless an identical one exists,
+// refusing to shadow other records with the same name.
+func ensureCNAMERecord(ds do.DomainsService, zone, name, target string) error {
+	records, err := ds.Records(zone)
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		if !strings.EqualFold(r.Name, name) {
+			continue
+		}
+		if r.Type == "CNAME" && strings.EqualFold(strings.TrimSuffix(r.Data, ".")+".", target) {
+			notice("CNAME record %s.%s already points to %s", name, zone, target)
+			return nil
+		}
+		return fmt.Errorf("%s.%s already has a %s record; remove it before adding the domain", name, zone, r.Type)
+	}
+
+	r, err := ds.CreateRecord(zone, &do.DomainRecordEditRequest{Type: "CNAME", Name: name, Data: target, TTL: 1800})
+	if err != nil {
+		return err
+	}
+	recordDNSChange(zone, dnsChangeCreate, nil, r.DomainRecord)
+	notice("Created CNAME record %s.%s pointing to %s", name, zone, target)
+	return nil
+}
+
+func verifyServerlessDomain(endpoint string) error {
+	resp, err := serverlessDomainHTTPClient.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("the function returned %s", resp.Status)
+	}
+	return nil
+}
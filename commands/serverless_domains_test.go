@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerlessDomainsCommand(t *testing.T) {
+	cmd := ServerlessDomains()
+	assert.NotNil(t, cmd)
+	assertCommandNames(t, cmd, "add")
+}
+
+func TestServerlessDomainsAdd(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.serverless.EXPECT().CheckServerlessStatus().Return(nil)
+		tm.serverless.EXPECT().ReadCredentials().Return(do.ServerlessCredentials{
+			APIHost:   "https://faas-nyc1-2ef2e6cc.doserverless.co",
+			Namespace: "fn-aaa",
+		}, nil)
+		tm.domains.EXPECT().List().Return(do.Domains{
+			{Domain: &godo.Domain{Name: "example.com"}},
+			{Domain: &godo.Domain{Name: "dev.example.com"}},
+		}, nil)
+		tm.domains.EXPECT().Records("dev.example.com").Return(do.DomainRecords{
+			{DomainRecord: &godo.DomainRecord{ID: 1, Type: "A", Name: "www", Data: "192.0.2.1"}},
+		}, nil)
+		tm.domains.EXPECT().CreateRecord("dev.example.com", &do.DomainRecordEditRequest{
+			Type: "CNAME", Name: "api", Data: "faas-nyc1-2ef2e6cc.doserverless.co.", TTL: 1800,
+		}).Return(&do.DomainRecord{DomainRecord: &godo.DomainRecord{ID: 2, Type: "CNAME", Name: "api"}}, nil)
+		tm.certificates.EXPECT().Create(&godo.CertificateRequest{
+			Name:     "functions-api-dev-example-com",
+			DNSNames: []string{"api.dev.example.com"},
+			Type:     "lets_encrypt",
+		}).Return(&do.Certificate{Certificate: &godo.Certificate{ID: "cert-1", Name: "functions-api-dev-example-com"}}, nil)
+
+		config.Args = append(config.Args, "api.dev.example.com")
+		config.Doit.Set(config.NS, doctl.ArgServerlessDomainCertificate, true)
+
+		err := RunServerlessDomainsAdd(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestServerlessDomainsAddConflictingRecord(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.serverless.EXPECT().CheckServerlessStatus().Return(nil)
+		tm.serverless.EXPECT().ReadCredentials().Return(do.ServerlessCredentials{APIHost: "https://faas.example.net"}, nil)
+		tm.domains.EXPECT().List().Return(do.Domains{{Domain: &godo.Domain{Name: "example.com"}}}, nil)
+		tm.domains.EXPECT().Records("example.com").Return(do.DomainRecords{
+			{DomainRecord: &godo.DomainRecord{ID: 1, Type: "A", Name: "api", Data: "192.0.2.1"}},
+		}, nil)
+
+		config.Args = append(config.Args, "api.example.com")
+
+		err := RunServerlessDomainsAdd(config)
+		assert.ErrorContains(t, err, "already has a A record")
+	})
+}
+
+func TestManagedZoneFor(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.domains.EXPECT().List().Return(do.Domains{{Domain: &godo.Domain{Name: "example.com"}}}, nil).Times(2)
+
+		_, _, err := managedZoneFor(config.Domains(), "example.com")
+		assert.ErrorContains(t, err, "apex")
+
+		_, _, err = managedZoneFor(config.Domains(), "api.example.org")
+		assert.ErrorContains(t, err, "not in a domain managed")
+	})
+}
+
+func TestVerifyServerlessDomain(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/web/fn-aaa/sample/hello" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := serverlessDomainHTTPClient
+	serverlessDomainHTTPClient = server.Client()
+	defer func() { serverlessDomainHTTPClient = client }()
+
+	require.NoError(t, verifyServerlessDomain(server.URL+"/api/v1/web/fn-aaa/sample/hello"))
+	assert.ErrorContains(t, verifyServerlessDomain(server.URL+"/api/v1/web/fn-aaa/missing"), "404")
+}
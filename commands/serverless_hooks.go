@@ -0,0 +1,238 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// deployHooks are the commands run around 'serverless deploy', declared in the
+// preDeploy and postDeploy sections of a project's project.yml.
+type deployHooks struct {
+	PreDeploy  []deployHook `yaml:"preDeploy"`
+	PostDeploy []deployHook `yaml:"postDeploy"`
+}
+
+// deployHook is either a shell command or an HTTP request.
+type deployHook struct {
+	Run  string          `yaml:"run"`
+	HTTP *deployHookHTTP `yaml:"http"`
+}
+
+// deployHookHTTP is an HTTP request made by a hook. The URL, headers, and body
+// are Go templates executed with a deployHookContext.
+type deployHookHTTP struct {
+	Method  string            `yaml:"method"`
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+	Body    string            `yaml:"body"`
+}
+
+// deployHookContext is what hooks know about the deployment.
+type deployHookContext struct {
+	Phase     string
+	Namespace string
+	APIHost   string
+	// Functions maps the qualified name of each web function, e.g. sample/hello,
+	// to its URL. It is only populated after the deployment.
+	Functions map[string]string
+}
+
+// deployHookClient is the client used for HTTP hooks. It is replaced for testing.
+var deployHookClient = &http.Client{Timeout: 30 * time.Second}
+
+var nonEnvChars = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// loadDeployHooks reads the hooks of the project in dir. A project without a
+// project.yml has no hooks.
+func loadDeployHooks(dir string) (*deployHooks, error) {
+	b, err := os.ReadFile(filepath.Join(dir, "project.yml"))
+	if errors.Is(err, os.ErrNotExist) {
+		return &deployHooks{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hooks := &deployHooks{}
+	if err := yaml.Unmarshal(b, hooks); err != nil {
+		return nil, fmt.Errorf("reading deploy hooks: %w", err)
+	}
+	for i, h := range append(hooks.PreDeploy, hooks.PostDeploy...) {
+		if (h.Run == "") == (h.HTTP == nil) {
+			return nil, fmt.Errorf("deploy hook %d: exactly one of run and http must be set", i+1)
+		}
+		if h.HTTP != nil && h.HTTP.URL == "" {
+			return nil, fmt.Errorf("deploy hook %d: http hooks need a url", i+1)
+		}
+	}
+	return hooks, nil
+}
+
+// newDeployHookContext describes the namespace being deployed to and, when
+// withFunctions is set, the URLs of its web functions.
+func newDeployHookContext(c *CmdConfig, phase string, withFunctions bool) (*deployHookContext, error) {
+	sls := c.Serverless()
+	creds, err := sls.ReadCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	hc := &deployHookContext{
+		Phase:     phase,
+		Namespace: creds.Namespace,
+		APIHost:   creds.APIHost,
+		Functions: map[string]string{},
+	}
+	if !withFunctions {
+		return hc, nil
+	}
+
+	actions, err := sls.ListFunctions("", 0, 200)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range actions {
+		if !a.WebAction() {
+			continue
+		}
+		name := a.Name
+		if parts := strings.SplitN(a.Namespace, "/", 2); len(parts) == 2 {
+			name = parts[1] + "/" + a.Name
+		}
+		hc.Functions[name] = computeURL(a, creds.APIHost)
+	}
+	return hc, nil
+}
+
+// env returns the environment variables passed to shell hooks.
+func (hc *deployHookContext) env() []string {
+	env := []string{
+		"DOCTL_DEPLOY_PHASE=" + hc.Phase,
+		"DOCTL_FUNCTIONS_NAMESPACE=" + hc.Namespace,
+		"DOCTL_FUNCTIONS_API_HOST=" + hc.APIHost,
+	}
+
+	names := make([]string, 0, len(hc.Functions))
+	for name := range hc.Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key := nonEnvChars.ReplaceAllString(strings.ToUpper(name), "_")
+		env = append(env, "DOCTL_FUNCTION_URL_"+key+"="+hc.Functions[name])
+	}
+
+	if len(hc.Functions) > 0 {
+		all, _ := json.Marshal(hc.Functions)
+		env = append(env, "DOCTL_FUNCTION_URLS="+string(all))
+	}
+	return env
+}
+
+// runDeployHooks runs hooks in order, stopping at the first one that fails.
+func runDeployHooks(c *CmdConfig, dir string, hooks []deployHook, hc *deployHookContext) error {
+	for i, h := range hooks {
+		var err error
+		if h.Run != "" {
+			fmt.Fprintf(c.Out, "Running %s hook: %s\n", hc.Phase, h.Run)
+			err = runShellDeployHook(c.Out, dir, h.Run, hc)
+		} else {
+			fmt.Fprintf(c.Out, "Running %s hook: %s %s\n", hc.Phase, httpHookMethod(h.HTTP), h.HTTP.URL)
+			err = runHTTPDeployHook(h.HTTP, hc)
+		}
+		if err != nil {
+			return fmt.Errorf("%s hook %d failed: %w", hc.Phase, i+1, err)
+		}
+	}
+	return nil
+}
+
+func runShellDeployHook(out io.Writer, dir, command string, hc *deployHookContext) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), hc.env()...)
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func httpHookMethod(h *deployHookHTTP) string {
+	if h.Method == "" {
+		return http.MethodPost
+	}
+	return strings.ToUpper(h.Method)
+}
+
+func runHTTPDeployHook(h *deployHookHTTP, hc *deployHookContext) error {
+	url, err := renderHookTemplate(h.URL, hc)
+	if err != nil {
+		return err
+	}
+	body, err := renderHookTemplate(h.Body, hc)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(httpHookMethod(h), url, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range h.Headers {
+		v, err := renderHookTemplate(v, hc)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(k, v)
+	}
+
+	resp, err := deployHookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return nil
+}
+
+func renderHookTemplate(text string, hc *deployHookContext) (string, error) {
+	tmpl, err := template.New("hook").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, hc); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// keepWarmTriggerPrefix starts the name of every keep-warm trigger.
+	keepWarmTriggerPrefix = "keep-warm-"
+	// keepWarmParameter is the parameter with which keep-warm triggers invoke functions.
+	keepWarmParameter = "__keep_warm"
+)
+
+// KeepWarm generates the serverless 'keep-warm' subtree for addition to the doctl command
+func KeepWarm() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "keep-warm",
+			Short: "Keep functions warm to reduce cold-start latency",
+			Long: `The subcommands of ` + "`" + `doctl serverless keep-warm` + "`" + ` manage scheduled triggers that invoke functions at a regular interval,
+so that a warm container is usually ready for latency-sensitive requests.
+
+Keep-warm invocations pass the parameter ` + "`" + keepWarmParameter + "`" + ` set to true. By convention, a function should return
+immediately when it receives this parameter, so that keep-warm invocations cost as little as possible and have no side effects.`,
+			Aliases: []string{"keepwarm"},
+		},
+	}
+
+	enable := CmdBuilder(cmd, RunKeepWarmEnable, "enable <function>", "Keeps a function warm",
+		`Use `+"`"+`doctl serverless keep-warm enable`+"`"+` to invoke a function, given in `+"`"+`pkgName/fnName`+"`"+` form, at a regular interval.
+The interval is a whole number of minutes below one hour, or a whole number of hours below one day.
+Enabling a function that is already kept warm changes its interval.`,
+		Writer, displayerType(&displayers.Triggers{}))
+	AddStringFlag(enable, doctl.ArgServerlessKeepWarmInterval, "", "5m", "how often to invoke the function, e.g. `5m` or `1h`")
+	enable.Example = `The following example invokes the function ` + "`" + `api/search` + "`" + ` every three minutes: doctl serverless keep-warm enable api/search --interval 3m`
+
+	CmdBuilder(cmd, RunKeepWarmDisable, "disable <function>", "Stops keeping a function warm",
+		`Use `+"`"+`doctl serverless keep-warm disable`+"`"+` to remove the keep-warm trigger of a function.`,
+		Writer)
+
+	CmdBuilder(cmd, RunKeepWarmList, "list", "Lists the functions kept warm",
+		`Use `+"`"+`doctl serverless keep-warm list`+"`"+` to list the keep-warm triggers of the connected namespace.`,
+		Writer, aliasOpt("ls"), displayerType(&displayers.Triggers{}))
+
+	return cmd
+}
+
+// RunKeepWarmEnable supports the 'serverless keep-warm enable' command
+func RunKeepWarmEnable(c *CmdConfig) error {
+	if err := ensureOneArg(c); err != nil {
+		return err
+	}
+	fn := strings.Trim(c.Args[0], "/")
+	interval, _ := c.Doit.GetString(c.NS, doctl.ArgServerlessKeepWarmInterval)
+	cron, err := keepWarmCron(interval)
+	if err != nil {
+		return err
+	}
+
+	ss := c.Serverless()
+	// Fail early, rather than creating a trigger that invokes a missing function.
+	if _, _, err := ss.GetFunction(fn, false); err != nil {
+		return err
+	}
+	ctx := context.TODO()
+	existing, err := ss.ListTriggers(ctx, fn)
+	if err != nil {
+		return err
+	}
+	name := keepWarmTriggerName(fn)
+	details := &do.TriggerScheduledDetails{Cron: cron, Body: map[string]any{keepWarmParameter: true}}
+	var trigger do.ServerlessTrigger
+	if triggerNamed(existing, name) {
+		trigger, err = ss.UpdateTrigger(ctx, name, &do.UpdateTriggerRequest{IsEnabled: true, ScheduledDetails: details})
+	} else {
+		trigger, err = ss.CreateTrigger(ctx, &do.CreateTriggerRequest{
+			Name:             name,
+			Type:             "SCHEDULED",
+			Function:         fn,
+			IsEnabled:        true,
+			ScheduledDetails: details,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return c.Display(&displayers.Triggers{List: []do.ServerlessTrigger{trigger}})
+}
+
+// RunKeepWarmDisable supports the 'serverless keep-warm disable' command
+func RunKeepWarmDisable(c *CmdConfig) error {
+	if err := ensureOneArg(c); err != nil {
+		return err
+	}
+	fn := strings.Trim(c.Args[0], "/")
+	ss := c.Serverless()
+	ctx := context.TODO()
+	existing, err := ss.ListTriggers(ctx, fn)
+	if err != nil {
+		return err
+	}
+	name := keepWarmTriggerName(fn)
+	if !triggerNamed(existing, name) {
+		return fmt.Errorf("function '%s' is not kept warm", fn)
+	}
+	if err := ss.DeleteTrigger(ctx, name); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "Function '%s' is no longer kept warm\n", fn)
+	return nil
+}
+
+// RunKeepWarmList supports the 'serverless keep-warm list' command
+func RunKeepWarmList(c *CmdConfig) error {
+	if len(c.Args) > 0 {
+		return doctl.NewTooManyArgsErr(c.NS)
+	}
+	list, err := c.Serverless().ListTriggers(context.TODO(), "")
+	if err != nil {
+		return err
+	}
+	warm := []do.ServerlessTrigger{}
+	for _, trigger := range list {
+		if trigger.Name == keepWarmTriggerName(trigger.Function) {
+			warm = append(warm, trigger)
+		}
+	}
+	return c.Display(&displayers.Triggers{List: warm})
+}
+
+// keepWarmTriggerName returns the name of the keep-warm trigger of a function.
+func keepWarmTriggerName(fn string) string {
+	return keepWarmTriggerPrefix + strings.ReplaceAll(fn, "/", "-")
+}
+
+// triggerNamed reports whether a list of triggers contains one with the given name.
+func triggerNamed(triggers []do.ServerlessTrigger, name string) bool {
+	for _, trigger := range triggers {
+		if trigger.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// keepWarmCron converts a keep-warm interval into a cron expression. Cron schedules can only
+// divide an hour into minutes or a day into hours, so other intervals are rejected.
+func keepWarmCron(interval string) (string, error) {
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		return "", fmt.Errorf("invalid interval '%s': %w", interval, err)
+	}
+	switch {
+	case d >= time.Minute && d < time.Hour && d%time.Minute == 0:
+		return fmt.Sprintf("*/%d * * * *", int(d.Minutes())), nil
+	case d >= time.Hour && d < 24*time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("0 */%d * * *", int(d.Hours())), nil
+	}
+	return "", fmt.Errorf("invalid interval '%s': use whole minutes below 1h or whole hours below 24h", interval)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/apache/openwhisk-client-go/whisk"
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeepWarmCommand(t *testing.T) {
+	cmd := KeepWarm()
+	assert.NotNil(t, cmd)
+	assertCommandNames(t, cmd, "enable", "disable", "list")
+}
+
+func TestKeepWarmCron(t *testing.T) {
+	tests := map[string]string{
+		"1m":  "*/1 * * * *",
+		"5m":  "*/5 * * * *",
+		"2h":  "0 */2 * * *",
+		"60m": "0 */1 * * *",
+	}
+	for interval, expected := range tests {
+		cron, err := keepWarmCron(interval)
+		require.NoError(t, err, interval)
+		assert.Equal(t, expected, cron, interval)
+	}
+	for _, bad := range []string{"30s", "90m", "24h", "five"} {
+		_, err := keepWarmCron(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestKeepWarmEnable(t *testing.T) {
+	details := &do.TriggerScheduledDetails{Cron: "*/3 * * * *", Body: map[string]any{"__keep_warm": true}}
+	tests := []struct {
+		name     string
+		existing []do.ServerlessTrigger
+	}{
+		{name: "new trigger"},
+		{name: "existing trigger", existing: []do.ServerlessTrigger{{Name: "keep-warm-api-search", Function: "api/search"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+				config.Out = &bytes.Buffer{}
+				config.Args = []string{"api/search"}
+				config.Doit.Set(config.NS, doctl.ArgServerlessKeepWarmInterval, "3m")
+
+				ctx := context.TODO()
+				tm.serverless.EXPECT().GetFunction("api/search", false).Return(whisk.Action{}, nil, nil)
+				tm.serverless.EXPECT().ListTriggers(ctx, "api/search").Return(tt.existing, nil)
+				if tt.existing == nil {
+					tm.serverless.EXPECT().CreateTrigger(ctx, &do.CreateTriggerRequest{
+						Name:             "keep-warm-api-search",
+						Type:             "SCHEDULED",
+						Function:         "api/search",
+						IsEnabled:        true,
+						ScheduledDetails: details,
+					}).Return(do.ServerlessTrigger{ScheduledDetails: details}, nil)
+				} else {
+					tm.serverless.EXPECT().UpdateTrigger(ctx, "keep-warm-api-search", &do.UpdateTriggerRequest{
+						IsEnabled:        true,
+						ScheduledDetails: details,
+					}).Return(do.ServerlessTrigger{ScheduledDetails: details}, nil)
+				}
+
+				require.NoError(t, RunKeepWarmEnable(config))
+			})
+		})
+	}
+}
+
+func TestKeepWarmDisable(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		buf := &bytes.Buffer{}
+		config.Out = buf
+		config.Args = []string{"api/search"}
+
+		ctx := context.TODO()
+		tm.serverless.EXPECT().ListTriggers(ctx, "api/search").Return([]do.ServerlessTrigger{{Name: "keep-warm-api-search"}}, nil)
+		tm.serverless.EXPECT().DeleteTrigger(ctx, "keep-warm-api-search").Return(nil)
+
+		require.NoError(t, RunKeepWarmDisable(config))
+		assert.Equal(t, "Function 'api/search' is no longer kept warm\n", buf.String())
+	})
+}
+
+func TestKeepWarmList(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		buf := &bytes.Buffer{}
+		config.Out = buf
+
+		schedule := &do.TriggerScheduledDetails{Cron: "*/5 * * * *"}
+		tm.serverless.EXPECT().ListTriggers(context.TODO(), "").Return([]do.ServerlessTrigger{
+			{Name: "keep-warm-api-search", Function: "api/search", IsEnabled: true, ScheduledDetails: schedule},
+			{Name: "nightly", Function: "api/report", IsEnabled: true, ScheduledDetails: schedule},
+		}, nil)
+
+		require.NoError(t, RunKeepWarmList(config))
+		assert.Contains(t, buf.String(), "keep-warm-api-search")
+		assert.NotContains(t, buf.String(), "nightly")
+	})
+}
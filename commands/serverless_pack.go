@@ -0,0 +1,374 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl"
+)
+
+// packSkipEntries are the top-level entries of a project that are never packed: version control and deployer
+// state, web content (which `doctl serverless deploy` doesn't deploy), and the .env file, which holds secrets and
+// is given with `--env` when deploying instead.
+var packSkipEntries = map[string]bool{
+	".git": true, ".deployed": true, ".nimbella": true, keywordWeb: true, ".env": true, provenanceFile: true,
+}
+
+// packBuildScript returns the name of the build script of a function on the current platform.
+func packBuildScript() string {
+	if runtime.GOOS == "windows" {
+		return "build.cmd"
+	}
+	return "build.sh"
+}
+
+// runBuildScript runs the build script of a function in its directory. It is replaced for testing.
+var runBuildScript = func(dir, script string, out io.Writer) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/c", script)
+	} else {
+		cmd = exec.Command("sh", script)
+	}
+	cmd.Dir = dir
+	cmd.Stdout = out
+	cmd.Stderr = out
+	return cmd.Run()
+}
+
+// RunServerlessPack supports the 'serverless pack' command
+func RunServerlessPack(c *CmdConfig) error {
+	if err := ensureOneArg(c); err != nil {
+		return err
+	}
+	output, _ := c.Doit.GetString(c.NS, doctl.ArgOutput)
+	include, _ := c.Doit.GetString(c.NS, flagInclude)
+	exclude, _ := c.Doit.GetString(c.NS, flagExclude)
+	signKey, _ := c.Doit.GetString(c.NS, doctl.ArgServerlessSignKey)
+	builder, _ := c.Doit.GetString(c.NS, doctl.ArgServerlessBuilder)
+
+	project := c.Args[0]
+	if _, err := os.Stat(filepath.Join(project, "packages")); err != nil {
+		return fmt.Errorf("%s is not a functions project: it has no packages directory", project)
+	}
+
+	staging, err := os.MkdirTemp("", "doctl-pack-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	functions, err := stageProject(project, staging, splitPackList(include), splitPackList(exclude))
+	if err != nil {
+		return err
+	}
+	if len(functions) == 0 {
+		return fmt.Errorf("no functions of %s are selected", project)
+	}
+
+	script := packBuildScript()
+	for _, fn := range functions {
+		dir := filepath.Join(staging, "packages", filepath.FromSlash(fn))
+		if _, err := os.Stat(filepath.Join(dir, script)); err != nil {
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Building %s\n", fn)
+		if err := runBuildScript(dir, script, os.Stderr); err != nil {
+			return fmt.Errorf("building %s: %w", fn, err)
+		}
+		// The function is deployed as built, so the deployer must not build it again.
+		if err := os.Remove(filepath.Join(dir, script)); err != nil {
+			return err
+		}
+	}
+
+	provenance, err := json.Marshal(newArtifactProvenance(project, builder))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(staging, provenanceFile), provenance, 0644); err != nil {
+		return err
+	}
+
+	digest, err := writeArtifact(staging, output)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "Packed %d functions into %s (sha256:%s)\n", len(functions), output, digest)
+	if signKey != "" {
+		signer, err := signArtifact(output, signKey, output+".sig")
+		if err != nil {
+			return fmt.Errorf("signing %s: %w", output, err)
+		}
+		fmt.Fprintf(c.Out, "Signed %s with key %s into %s.sig\n", output, signer, output)
+	}
+	return nil
+}
+
+// splitPackList splits an --include or --exclude value into package and function names.
+func splitPackList(s string) []string {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.Trim(strings.TrimSpace(name), "/"); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// stageProject copies the parts of a project to be deployed to staging: its top-level files and directories, and
+// the functions selected by the include and exclude lists, whose names are returned in `pkgName/fnName` form.
+func stageProject(project, staging string, include, exclude []string) ([]string, error) {
+	var functions []string
+	err := filepath.WalkDir(project, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(project, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) == 1 && packSkipEntries[parts[0]] {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if parts[0] == "packages" && len(parts) == 3 {
+			fn := packFunctionName(parts[1], parts[2], d.IsDir())
+			if !strings.HasPrefix(parts[2], ".") {
+				if (len(include) > 0 && !promotionSelected(fn, include)) || (len(exclude) > 0 && promotionSelected(fn, exclude)) {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				functions = append(functions, fn)
+			}
+		}
+		return copyProjectEntry(path, filepath.Join(staging, rel), d)
+	})
+	return functions, err
+}
+
+// packFunctionName returns the name, in `pkgName/fnName` form, of the function deployed from an entry of a package
+// directory: a directory or a file without its extension.
+func packFunctionName(pkg, entry string, isDir bool) string {
+	if isDir {
+		return pkg + "/" + entry
+	}
+	return pkg + "/" + strings.TrimSuffix(entry, filepath.Ext(entry))
+}
+
+// copyProjectEntry copies a file, directory, or symbolic link of a project.
+func copyProjectEntry(src, dst string, d fs.DirEntry) error {
+	info, err := d.Info()
+	if err != nil {
+		return err
+	}
+	switch {
+	case d.IsDir():
+		return os.MkdirAll(dst, 0755)
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	case info.Mode().IsRegular():
+		in, err := os.Open(src)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	}
+	return nil
+}
+
+// writeArtifact writes the contents of dir to a gzipped tarball at path and returns its SHA-256 digest. The
+// tarball only depends on the names, contents, and executable bits of the files: entries are in lexical order and
+// carry no timestamps or owners, so packing the same files always produces the same bytes.
+func writeArtifact(dir, path string) (string, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	gz := gzip.NewWriter(io.MultiWriter(f, h))
+	tw := tar.NewWriter(gz)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		hdr := &tar.Header{
+			Name:    filepath.ToSlash(rel),
+			Mode:    0644,
+			ModTime: time.Unix(0, 0),
+			Format:  tar.FormatPAX,
+		}
+		switch {
+		case d.IsDir():
+			hdr.Typeflag, hdr.Name, hdr.Mode = tar.TypeDir, hdr.Name+"/", 0755
+		case info.Mode()&fs.ModeSymlink != 0:
+			hdr.Typeflag, hdr.Mode = tar.TypeSymlink, 0777
+			if hdr.Linkname, err = os.Readlink(path); err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			hdr.Typeflag, hdr.Size = tar.TypeReg, info.Size()
+			if info.Mode()&0111 != 0 {
+				hdr.Mode = 0755
+			}
+		default:
+			return nil
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		_, err = io.Copy(tw, in)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := tw.Close(); err != nil {
+		return "", err
+	}
+	if err := gz.Close(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), f.Close()
+}
+
+// extractArtifact extracts a tarball written by `doctl serverless pack` to a new temporary directory, which the
+// caller removes, and returns the directory and the tarball's SHA-256 digest.
+func extractArtifact(path string) (string, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	dir, err := os.MkdirTemp("", "doctl-artifact-")
+	if err != nil {
+		return "", "", err
+	}
+	h := sha256.New()
+	if err := extractTarball(io.TeeReader(f, h), dir); err != nil {
+		os.RemoveAll(dir)
+		return "", "", fmt.Errorf("extracting %s: %w", path, err)
+	}
+	// Read any trailing bytes, so the digest covers the whole file.
+	if _, err := io.Copy(h, f); err != nil {
+		os.RemoveAll(dir)
+		return "", "", err
+	}
+	return dir, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// extractTarball extracts a gzipped tarball to dir, refusing entries that would be written outside it.
+func extractTarball(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(hdr.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("entry %q is outside the artifact", hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeSymlink:
+			if filepath.IsAbs(hdr.Linkname) || !filepath.IsLocal(filepath.Join(filepath.Dir(name), filepath.FromSlash(hdr.Linkname))) {
+				return fmt.Errorf("link %q points outside the artifact", hdr.Name)
+			}
+			err = os.Symlink(hdr.Linkname, target)
+		case tar.TypeReg:
+			err = extractFile(tr, target, hdr.FileInfo().Mode().Perm())
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// extractFile writes the current entry of a tarball to path.
+func extractFile(tr *tar.Reader, path string, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, tr); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
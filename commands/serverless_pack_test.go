@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePackProject writes a functions project with the given files to a temporary directory.
+func writePackProject(t *testing.T, files map[string]string) string {
+	project := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(project, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return project
+}
+
+func TestStageProject(t *testing.T) {
+	project := writePackProject(t, map[string]string{
+		"project.yml":                     "packages: []",
+		".env":                            "SECRET=1",
+		".deployed/versions.json":         "{}",
+		"web/index.html":                  "<html/>",
+		"packages/admin/reset/index.js":   "exports.main = () => ({})",
+		"packages/sample/hello/index.js":  "exports.main = () => ({})",
+		"packages/sample/goodbye.py":      "def main(args): return {}",
+		"packages/sample/.include":        "index.js",
+		"packages/sample/hello/README.md": "hello",
+	})
+
+	tests := []struct {
+		name      string
+		include   []string
+		exclude   []string
+		functions []string
+	}{
+		{name: "all", functions: []string{"admin/reset", "sample/goodbye", "sample/hello"}},
+		{name: "include package", include: []string{"sample"}, functions: []string{"sample/goodbye", "sample/hello"}},
+		{name: "exclude function", exclude: []string{"sample/hello"}, functions: []string{"admin/reset", "sample/goodbye"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			staging := t.TempDir()
+			functions, err := stageProject(project, staging, tt.include, tt.exclude)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.functions, functions)
+
+			assert.FileExists(t, filepath.Join(staging, "project.yml"))
+			assert.FileExists(t, filepath.Join(staging, "packages", "sample", ".include"))
+			for _, skipped := range []string{".env", ".deployed", "web"} {
+				assert.NoFileExists(t, filepath.Join(staging, skipped))
+			}
+		})
+	}
+}
+
+func TestWriteArtifactDeterministic(t *testing.T) {
+	dir := writePackProject(t, map[string]string{
+		"project.yml":                    "packages: []",
+		"packages/sample/hello/index.js": "exports.main = () => ({})",
+	})
+	first := filepath.Join(t.TempDir(), "first.tar.gz")
+	digest, err := writeArtifact(dir, first)
+	require.NoError(t, err)
+
+	// Timestamps don't change the artifact.
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "project.yml"), time.Now(), time.Now().Add(time.Hour)))
+	second := filepath.Join(t.TempDir(), "second.tar.gz")
+	again, err := writeArtifact(dir, second)
+	require.NoError(t, err)
+	assert.Equal(t, digest, again)
+
+	sum, err := os.ReadFile(first)
+	require.NoError(t, err)
+	other, err := os.ReadFile(second)
+	require.NoError(t, err)
+	assert.Equal(t, sum, other)
+}
+
+func TestExtractArtifact(t *testing.T) {
+	dir := writePackProject(t, map[string]string{
+		"project.yml":                    "packages: []",
+		"packages/sample/hello/index.js": "exports.main = () => ({})",
+	})
+	artifact := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	digest, err := writeArtifact(dir, artifact)
+	require.NoError(t, err)
+
+	extracted, got, err := extractArtifact(artifact)
+	require.NoError(t, err)
+	defer os.RemoveAll(extracted)
+	assert.Equal(t, digest, got)
+
+	content, err := os.ReadFile(filepath.Join(extracted, "packages", "sample", "hello", "index.js"))
+	require.NoError(t, err)
+	assert.Equal(t, "exports.main = () => ({})", string(content))
+}
+
+func TestExtractTarballRejectsEscapes(t *testing.T) {
+	tests := []struct {
+		name string
+		hdr  tar.Header
+	}{
+		{name: "parent path", hdr: tar.Header{Name: "../evil.js", Typeflag: tar.TypeReg, Mode: 0644}},
+		{name: "absolute path", hdr: tar.Header{Name: "/tmp/evil.js", Typeflag: tar.TypeReg, Mode: 0644}},
+		{name: "escaping link", hdr: tar.Header{Name: "packages/link", Typeflag: tar.TypeSymlink, Linkname: "../../etc/passwd"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			tw := tar.NewWriter(gz)
+			require.NoError(t, tw.WriteHeader(&tt.hdr))
+			require.NoError(t, tw.Close())
+			require.NoError(t, gz.Close())
+
+			err := extractTarball(&buf, t.TempDir())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRunServerlessPack(t *testing.T) {
+	project := writePackProject(t, map[string]string{
+		"project.yml":                                "packages: []",
+		"packages/sample/hello/index.js":             "exports.main = () => ({})",
+		"packages/sample/hello/" + packBuildScript(): "npm install",
+		"packages/sample/goodbye.py":                 "def main(args): return {}",
+	})
+
+	var built []string
+	defer func(orig func(string, string, io.Writer) error) { runBuildScript = orig }(runBuildScript)
+	runBuildScript = func(dir, script string, out io.Writer) error {
+		built = append(built, filepath.Base(dir))
+		return os.WriteFile(filepath.Join(dir, "bundle.js"), []byte("built"), 0644)
+	}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var out bytes.Buffer
+		config.Out = &out
+		artifact := filepath.Join(t.TempDir(), "bundle.tar.gz")
+		config.Args = []string{project}
+		config.Doit.Set(config.NS, doctl.ArgOutput, artifact)
+
+		require.NoError(t, RunServerlessPack(config))
+		assert.Equal(t, []string{"hello"}, built)
+		assert.Contains(t, out.String(), "Packed 2 functions into "+artifact)
+
+		extracted, _, err := extractArtifact(artifact)
+		require.NoError(t, err)
+		defer os.RemoveAll(extracted)
+		assert.FileExists(t, filepath.Join(extracted, "packages", "sample", "hello", "bundle.js"))
+		assert.NoFileExists(t, filepath.Join(extracted, "packages", "sample", "hello", packBuildScript()))
+	})
+}
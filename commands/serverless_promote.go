@@ -0,0 +1,232 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/apache/openwhisk-client-go/whisk"
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+)
+
+// promotion is a function to be copied from one namespace to another.
+type promotion struct {
+	name    string
+	fn      exportedFunction
+	changes []string // nil if the function is new in the target namespace
+}
+
+// RunServerlessPromote supports the 'serverless promote' command
+func RunServerlessPromote(c *CmdConfig) error {
+	if len(c.Args) > 0 {
+		return doctl.NewTooManyArgsErr(c.NS)
+	}
+	from, _ := c.Doit.GetString(c.NS, doctl.ArgServerlessPromoteFrom)
+	to, _ := c.Doit.GetString(c.NS, doctl.ArgServerlessPromoteTo)
+	only, _ := c.Doit.GetStringSlice(c.NS, doctl.ArgServerlessPromoteOnly)
+	force, _ := c.Doit.GetBool(c.NS, doctl.ArgForce)
+	dryRun, _ := c.Doit.GetBool(c.NS, doctl.ArgDryRun)
+	if from == "" || to == "" {
+		return errors.New("the '--from' and '--to' flags are both required")
+	}
+
+	ss := c.Serverless()
+	if err := ss.CheckServerlessStatus(); err != nil {
+		return err
+	}
+	ctx := context.TODO()
+	source, err := namespaceCredentials(ctx, ss, from)
+	if err != nil {
+		return err
+	}
+	target, err := namespaceCredentials(ctx, ss, to)
+	if err != nil {
+		return err
+	}
+	if source.Namespace == target.Namespace {
+		return errors.New("the '--from' and '--to' namespaces must be different")
+	}
+
+	// Snapshot the selected functions, and their packages, in the source namespace.
+	useNamespace(ss, source)
+	names, err := listAllFunctions(ss)
+	if err != nil {
+		return err
+	}
+	sourceCode := map[string][]byte{}
+	promotions := []*promotion{}
+	sourcePackages := map[string]whisk.Package{}
+	for _, name := range names {
+		if !promotionSelected(name, only) {
+			continue
+		}
+		fn, err := exportFunction(ss, name, sourceCode)
+		if err != nil {
+			return err
+		}
+		promotions = append(promotions, &promotion{name: name, fn: fn})
+		if _, ok := sourcePackages[fn.Package]; fn.Package != "" && !ok {
+			pkg, err := ss.GetPackage(fn.Package)
+			if err != nil {
+				return err
+			}
+			sourcePackages[fn.Package] = pkg
+		}
+	}
+	if len(promotions) == 0 {
+		return fmt.Errorf("no functions in namespace '%s' match %s", source.Namespace, strings.Join(only, ", "))
+	}
+
+	// Compare them with what is deployed in the target namespace.
+	useNamespace(ss, target)
+	newPackages := []string{}
+	for name := range sourcePackages {
+		if _, err := ss.GetPackage(name); err != nil {
+			newPackages = append(newPackages, name)
+		}
+	}
+	sort.Strings(newPackages)
+	targetCode := map[string][]byte{}
+	for _, p := range promotions {
+		deployed, err := exportFunction(ss, p.name, targetCode)
+		if err != nil {
+			continue
+		}
+		p.changes = functionChanges(p.fn, deployed, sourceCode[p.fn.Code], targetCode[deployed.Code])
+	}
+
+	fmt.Fprintf(c.Out, "Promoting from '%s' to '%s':\n", source.Namespace, target.Namespace)
+	pending := 0
+	for _, name := range newPackages {
+		fmt.Fprintf(c.Out, "  + %s (new package)\n", name)
+	}
+	for _, p := range promotions {
+		switch {
+		case p.changes == nil:
+			fmt.Fprintf(c.Out, "  + %s (new)\n", p.name)
+			pending++
+		case len(p.changes) > 0:
+			fmt.Fprintf(c.Out, "  ~ %s (%s)\n", p.name, strings.Join(p.changes, ", "))
+			pending++
+		default:
+			fmt.Fprintf(c.Out, "  = %s (unchanged)\n", p.name)
+		}
+	}
+	if pending == 0 {
+		fmt.Fprintln(c.Out, "Nothing to promote")
+		return nil
+	}
+	if dryRun {
+		return nil
+	}
+	if !force && AskForConfirm(fmt.Sprintf("promote %d functions to '%s'?", pending, target.Namespace)) != nil {
+		return fmt.Errorf("promotion to '%s' not confirmed, doing nothing", target.Namespace)
+	}
+
+	for _, name := range newPackages {
+		pkg := sourcePackages[name]
+		_, err := ss.PutPackage(whisk.Package{
+			Name:        pkg.Name,
+			Publish:     pkg.Publish,
+			Annotations: pkg.Annotations,
+			Parameters:  pkg.Parameters,
+		})
+		if err != nil {
+			return fmt.Errorf("promoting package '%s': %w", name, err)
+		}
+	}
+	for _, p := range promotions {
+		if p.changes != nil && len(p.changes) == 0 {
+			continue
+		}
+		action, err := importedAction(p.fn, sourceCode)
+		if err != nil {
+			return err
+		}
+		if _, err := ss.PutFunction(action); err != nil {
+			return fmt.Errorf("promoting function '%s': %w", p.name, err)
+		}
+		if len(p.fn.Environment) > 0 {
+			warn("The environment variables of function '%s' (%s) were promoted as parameters", p.name, strings.Join(p.fn.Environment, ", "))
+		}
+	}
+	fmt.Fprintf(c.Out, "Promoted %d functions to '%s'\n", pending, target.Namespace)
+	return nil
+}
+
+// namespaceCredentials returns the credentials of the namespace whose label or id is exactly arg.
+func namespaceCredentials(ctx context.Context, ss do.ServerlessService, arg string) (do.ServerlessCredentials, error) {
+	matches, err := getMatchingNamespaces(ctx, ss, arg)
+	if err != nil {
+		return do.ServerlessCredentials{}, err
+	}
+	for _, ns := range matches {
+		if arg == ns.Label || arg == ns.Namespace {
+			return ss.GetNamespace(ctx, ns.Namespace)
+		}
+	}
+	return do.ServerlessCredentials{}, fmt.Errorf("'%s' does not exactly match the label or id of any of your namespaces", arg)
+}
+
+// useNamespace directs subsequent function and package requests to the namespace of creds.
+func useNamespace(ss do.ServerlessService, creds do.ServerlessCredentials) {
+	ss.SetEffectiveCredentials(creds.Credentials[creds.APIHost][creds.Namespace].Auth, creds.APIHost)
+}
+
+// promotionSelected reports whether a function is selected by the '--only' flag, whose values
+// are package-qualified function names or package names. All functions are selected if it is empty.
+func promotionSelected(name string, only []string) bool {
+	if len(only) == 0 {
+		return true
+	}
+	pkg := ""
+	if i := strings.Index(name, "/"); i >= 0 {
+		pkg = name[:i]
+	}
+	for _, o := range only {
+		o = strings.Trim(o, "/")
+		if o == name || o == pkg {
+			return true
+		}
+	}
+	return false
+}
+
+// functionChanges lists the aspects in which a promoted function differs from the deployed one.
+func functionChanges(promoted, deployed exportedFunction, promotedCode, deployedCode []byte) []string {
+	changes := []string{}
+	if !bytes.Equal(promotedCode, deployedCode) || !reflect.DeepEqual(promoted.Components, deployed.Components) {
+		changes = append(changes, "code")
+	}
+	if promoted.Kind != deployed.Kind || promoted.Main != deployed.Main || promoted.Binary != deployed.Binary {
+		changes = append(changes, "runtime")
+	}
+	if !reflect.DeepEqual(promoted.Limits, deployed.Limits) {
+		changes = append(changes, "limits")
+	}
+	if !reflect.DeepEqual(promoted.Annotations, deployed.Annotations) {
+		changes = append(changes, "annotations")
+	}
+	if !reflect.DeepEqual(promoted.Parameters, deployed.Parameters) {
+		changes = append(changes, "parameters")
+	}
+	return changes
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/apache/openwhisk-client-go/whisk"
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPromotionSelected(t *testing.T) {
+	assert.True(t, promotionSelected("api/hello", nil))
+	assert.True(t, promotionSelected("api/hello", []string{"api"}))
+	assert.True(t, promotionSelected("api/hello", []string{"/api/hello"}))
+	assert.False(t, promotionSelected("api/hello", []string{"api/bye"}))
+	assert.False(t, promotionSelected("hello", []string{"api"}))
+}
+
+func TestServerlessPromote(t *testing.T) {
+	oldCode := "old"
+	newCode := "new"
+	fn := func(name, ns, code string) whisk.Action {
+		return whisk.Action{Name: name, Namespace: ns, Exec: &whisk.Exec{Kind: "nodejs:18", Code: &code}}
+	}
+	creds := func(ns string) do.ServerlessCredentials {
+		host := "https://" + ns + ".example.com"
+		return do.ServerlessCredentials{
+			Namespace:   ns,
+			APIHost:     host,
+			Credentials: map[string]map[string]do.ServerlessCredential{host: {ns: {Auth: ns + "-auth"}}},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		dryRun         bool
+		expectedOutput string
+	}{
+		{
+			name:           "dry run",
+			dryRun:         true,
+			expectedOutput: "Promoting from 'fn-dev' to 'fn-prod':\n  + api (new package)\n  + api/hello (new)\n  ~ api/bye (code)\n",
+		},
+		{
+			name:           "promote",
+			expectedOutput: "Promoting from 'fn-dev' to 'fn-prod':\n  + api (new package)\n  + api/hello (new)\n  ~ api/bye (code)\nPromoted 2 functions to 'fn-prod'\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+				buf := &bytes.Buffer{}
+				config.Out = buf
+				config.Doit.Set(config.NS, doctl.ArgServerlessPromoteFrom, "dev")
+				config.Doit.Set(config.NS, doctl.ArgServerlessPromoteTo, "fn-prod")
+				config.Doit.Set(config.NS, doctl.ArgServerlessPromoteOnly, []string{"api"})
+				config.Doit.Set(config.NS, doctl.ArgDryRun, tt.dryRun)
+				config.Doit.Set(config.NS, doctl.ArgForce, true)
+
+				ctx := context.TODO()
+				list := do.NamespaceListResponse{Namespaces: []do.OutputNamespace{
+					{Namespace: "fn-dev", Label: "dev"},
+					{Namespace: "fn-prod", Label: "prod"},
+				}}
+				tm.serverless.EXPECT().CheckServerlessStatus().Return(nil)
+				tm.serverless.EXPECT().ListNamespaces(ctx).Return(list, nil).Times(2)
+				tm.serverless.EXPECT().GetNamespace(ctx, "fn-dev").Return(creds("fn-dev"), nil)
+				tm.serverless.EXPECT().GetNamespace(ctx, "fn-prod").Return(creds("fn-prod"), nil)
+
+				gomock.InOrder(
+					tm.serverless.EXPECT().SetEffectiveCredentials("fn-dev-auth", "https://fn-dev.example.com"),
+					tm.serverless.EXPECT().ListFunctions("", 0, 200).Return([]whisk.Action{
+						{Name: "hello", Namespace: "fn-dev/api"},
+						{Name: "bye", Namespace: "fn-dev/api"},
+						{Name: "other", Namespace: "fn-dev"},
+					}, nil),
+					tm.serverless.EXPECT().GetFunction("api/hello", true).Return(fn("hello", "fn-dev/api", newCode), nil, nil),
+					tm.serverless.EXPECT().GetPackage("api").Return(whisk.Package{Name: "api"}, nil),
+					tm.serverless.EXPECT().GetFunction("api/bye", true).Return(fn("bye", "fn-dev/api", newCode), nil, nil),
+					tm.serverless.EXPECT().SetEffectiveCredentials("fn-prod-auth", "https://fn-prod.example.com"),
+					tm.serverless.EXPECT().GetPackage("api").Return(whisk.Package{}, errors.New("not found")),
+					tm.serverless.EXPECT().GetFunction("api/hello", true).Return(whisk.Action{}, nil, errors.New("not found")),
+					tm.serverless.EXPECT().GetFunction("api/bye", true).Return(fn("bye", "fn-prod/api", oldCode), nil, nil),
+				)
+				if !tt.dryRun {
+					tm.serverless.EXPECT().PutPackage(whisk.Package{Name: "api"}).Return(whisk.Package{}, nil)
+					tm.serverless.EXPECT().PutFunction(gomock.Any()).Times(2).DoAndReturn(func(a whisk.Action) (whisk.Action, error) {
+						assert.Equal(t, newCode, *a.Exec.Code)
+						return a, nil
+					})
+				}
+
+				err := RunServerlessPromote(config)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedOutput, buf.String())
+			})
+		})
+	}
+}
+
+func TestServerlessPromoteSameNamespace(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Doit.Set(config.NS, doctl.ArgServerlessPromoteFrom, "dev")
+		config.Doit.Set(config.NS, doctl.ArgServerlessPromoteTo, "fn-dev")
+
+		ctx := context.TODO()
+		list := do.NamespaceListResponse{Namespaces: []do.OutputNamespace{{Namespace: "fn-dev", Label: "dev"}}}
+		tm.serverless.EXPECT().CheckServerlessStatus().Return(nil)
+		tm.serverless.EXPECT().ListNamespaces(ctx).Return(list, nil).Times(2)
+		tm.serverless.EXPECT().GetNamespace(ctx, "fn-dev").Return(do.ServerlessCredentials{Namespace: "fn-dev"}, nil).Times(2)
+
+		err := RunServerlessPromote(config)
+		assert.EqualError(t, err, "the '--from' and '--to' namespaces must be different")
+	})
+}
@@ -0,0 +1,435 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apache/openwhisk-client-go/whisk"
+	"github.com/digitalocean/doctl"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// provenanceAnnotation is the function annotation recording the artifact a function was deployed from.
+	provenanceAnnotation = "provenance"
+
+	// provenanceFile is the file of an artifact holding the provenance recorded by `doctl serverless pack`.
+	provenanceFile = ".provenance.json"
+)
+
+// artifactProvenance describes where an artifact came from and, once deployed, which artifact a function was
+// deployed from.
+type artifactProvenance struct {
+	// Artifact is the SHA-256 digest of the artifact, recorded when it is deployed.
+	Artifact string `json:"artifact,omitempty"`
+	// Signer is the fingerprint of the public key the artifact's signature was verified with when it was deployed.
+	Signer string `json:"signer,omitempty"`
+	// GitSHA is the commit the project was packed from, if it was packed from a git checkout.
+	GitSHA string `json:"gitSHA,omitempty"`
+	// Builder identifies what packed the artifact.
+	Builder string `json:"builder,omitempty"`
+}
+
+// newArtifactProvenance returns the provenance of an artifact packed from project by builder, which defaults to
+// this version of doctl.
+func newArtifactProvenance(project, builder string) artifactProvenance {
+	if builder == "" {
+		builder = "doctl/" + doctl.DoitVersion.String()
+	}
+	p := artifactProvenance{Builder: builder}
+	if out, err := exec.Command("git", "-C", project, "rev-parse", "HEAD").Output(); err == nil {
+		p.GitSHA = strings.TrimSpace(string(out))
+	}
+	return p
+}
+
+// readArtifactProvenance reads the provenance of an extracted artifact. Artifacts without one have an empty
+// provenance.
+func readArtifactProvenance(dir string) (artifactProvenance, error) {
+	var p artifactProvenance
+	b, err := os.ReadFile(filepath.Join(dir, provenanceFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(b, &p); err != nil {
+		return p, fmt.Errorf("reading the provenance of the artifact: %w", err)
+	}
+	return p, nil
+}
+
+// signArtifact signs the SHA-256 digest of an artifact with the ECDSA private key in keyFile and writes the
+// base64-encoded signature to sigFile, as `cosign sign-blob` does, so that `cosign verify-blob` can check it. The
+// fingerprint of the key's public key is returned.
+func signArtifact(artifact, keyFile, sigFile string) (string, error) {
+	key, err := readSigningKey(keyFile)
+	if err != nil {
+		return "", err
+	}
+	digest, err := artifactDigest(artifact)
+	if err != nil {
+		return "", err
+	}
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(sigFile, []byte(base64.StdEncoding.EncodeToString(sig)), 0644); err != nil {
+		return "", err
+	}
+	return keyFingerprint(&key.PublicKey)
+}
+
+// verifyArtifactSignature checks the signature in sigFile of an artifact against the ECDSA public key in keyFile,
+// and returns the key's fingerprint.
+func verifyArtifactSignature(artifact, keyFile, sigFile string) (string, error) {
+	key, err := readVerificationKey(keyFile)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := os.ReadFile(sigFile)
+	if err != nil {
+		return "", err
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return "", fmt.Errorf("%s is not a base64-encoded signature: %w", sigFile, err)
+	}
+	digest, err := artifactDigest(artifact)
+	if err != nil {
+		return "", err
+	}
+	if !ecdsa.VerifyASN1(key, digest, sig) {
+		return "", fmt.Errorf("the signature %s of %s does not match the key %s", sigFile, artifact, keyFile)
+	}
+	return keyFingerprint(key)
+}
+
+// readSigningKey reads an unencrypted PEM-encoded ECDSA private key.
+func readSigningKey(path string) (*ecdsa.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if key, ok := key.(*ecdsa.PrivateKey); ok {
+			return key, nil
+		}
+	case "ENCRYPTED COSIGN PRIVATE KEY", "ENCRYPTED SIGSTORE PRIVATE KEY", "ENCRYPTED PRIVATE KEY":
+		return nil, fmt.Errorf("%s is encrypted; doctl signs with unencrypted ECDSA keys", path)
+	}
+	return nil, fmt.Errorf("%s is not an ECDSA private key", path)
+}
+
+// readVerificationKey reads a PEM-encoded ECDSA public key, such as a cosign.pub file.
+func readVerificationKey(path string) (*ecdsa.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a public key: %w", path, err)
+	}
+	ecKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ECDSA public key", path)
+	}
+	return ecKey, nil
+}
+
+// readPEM reads the first PEM block of a file.
+func readPEM(path string) (*pem.Block, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not PEM-encoded", path)
+	}
+	return block, nil
+}
+
+// keyFingerprint returns the SHA-256 digest of the DER encoding of a public key.
+func keyFingerprint(key *ecdsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// artifactDigest returns the SHA-256 digest of a file.
+func artifactDigest(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// annotateProvenance adds the provenance annotation to the functions of an extracted artifact by declaring it in
+// the artifact's project.yml, which the deployer applies to the functions it deploys.
+func annotateProvenance(dir string, p artifactProvenance) error {
+	functions, err := artifactFunctions(dir)
+	if err != nil {
+		return err
+	}
+	configPath := filepath.Join(dir, "project.yml")
+	config := map[string]any{}
+	b, err := os.ReadFile(configPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := yaml.Unmarshal(b, &config); err != nil {
+		return fmt.Errorf("reading the project.yml of the artifact: %w", err)
+	}
+	if config == nil {
+		config = map[string]any{}
+	}
+
+	// The annotation is a JSON object, as the deployer would read it from project.yml.
+	var annotation map[string]any
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, &annotation); err != nil {
+		return err
+	}
+
+	packages, _ := config["packages"].([]any)
+	for _, fn := range functions {
+		pkgName, fnName, _ := strings.Cut(fn, "/")
+		pkg := findNamed(&packages, pkgName)
+		fns, _ := pkg["functions"].([]any)
+		f := findNamed(&fns, fnName)
+		pkg["functions"] = fns
+		annotations, _ := f["annotations"].(map[string]any)
+		if annotations == nil {
+			annotations = map[string]any{}
+		}
+		annotations[provenanceAnnotation] = annotation
+		f["annotations"] = annotations
+	}
+	config["packages"] = packages
+
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configPath, out, 0644)
+}
+
+// findNamed returns the element of a project.yml list whose name is name, appending one if there is none.
+func findNamed(list *[]any, name string) map[string]any {
+	for _, v := range *list {
+		if m, ok := v.(map[string]any); ok && m["name"] == name {
+			return m
+		}
+	}
+	m := map[string]any{"name": name}
+	*list = append(*list, m)
+	return m
+}
+
+// artifactFunctions returns the functions of an extracted artifact in `pkgName/fnName` form.
+func artifactFunctions(dir string) ([]string, error) {
+	packages, err := os.ReadDir(filepath.Join(dir, "packages"))
+	if err != nil {
+		return nil, err
+	}
+	var functions []string
+	for _, pkg := range packages {
+		if !pkg.IsDir() || strings.HasPrefix(pkg.Name(), ".") {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(dir, "packages", pkg.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !strings.HasPrefix(e.Name(), ".") {
+				functions = append(functions, packFunctionName(pkg.Name(), e.Name(), e.IsDir()))
+			}
+		}
+	}
+	return functions, nil
+}
+
+// RunServerlessVerify supports the 'serverless verify' command
+func RunServerlessVerify(c *CmdConfig) error {
+	if err := ensureOneArg(c); err != nil {
+		return err
+	}
+	artifact, _ := c.Doit.GetString(c.NS, doctl.ArgServerlessArtifact)
+	keyFile, _ := c.Doit.GetString(c.NS, doctl.ArgServerlessKey)
+	sigFile, _ := c.Doit.GetString(c.NS, doctl.ArgServerlessSignature)
+	if sigFile == "" {
+		sigFile = artifact + ".sig"
+	}
+	name := c.Args[0]
+
+	signer, err := verifyArtifactSignature(artifact, keyFile, sigFile)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "Signature: verified with key %s\n", signer)
+
+	dir, digest, err := extractArtifact(artifact)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	ss := c.Serverless()
+	if err := ss.CheckServerlessStatus(); err != nil {
+		return err
+	}
+	action, _, err := ss.GetFunction(name, true)
+	if err != nil {
+		return err
+	}
+
+	var p artifactProvenance
+	if v := action.Annotations.GetValue(provenanceAnnotation); v != nil {
+		raw, _ := json.Marshal(v)
+		_ = json.Unmarshal(raw, &p)
+	}
+	if p.Artifact == "" {
+		return fmt.Errorf("function '%s' has no provenance; it was not deployed with `doctl serverless deploy --%s`", name, doctl.ArgServerlessFromArtifact)
+	}
+	if p.Artifact != "sha256:"+digest {
+		return fmt.Errorf("function '%s' was deployed from artifact %s, not %s (sha256:%s)", name, p.Artifact, artifact, digest)
+	}
+	fmt.Fprintf(c.Out, "Artifact: %s\n", p.Artifact)
+	if p.GitSHA != "" {
+		fmt.Fprintf(c.Out, "Git SHA: %s\n", p.GitSHA)
+	}
+	if p.Builder != "" {
+		fmt.Fprintf(c.Out, "Builder: %s\n", p.Builder)
+	}
+
+	files, err := deployedCodeMatches(dir, name, action)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.Out, "Code: matches the artifact (%d files)\n", files)
+	return nil
+}
+
+// deployedCodeMatches checks that the code of a deployed function is the code of the function in an extracted
+// artifact, and returns the number of files compared. Every file the deployer zipped must be in the artifact with
+// the same contents.
+func deployedCodeMatches(dir, name string, action whisk.Action) (int, error) {
+	if action.Exec == nil || action.Exec.Code == nil {
+		return 0, fmt.Errorf("the code of function '%s' could not be retrieved", name)
+	}
+	pkg, fn, ok := strings.Cut(name, "/")
+	if !ok {
+		pkg, fn = "default", name
+	}
+	pkgDir := filepath.Join(dir, "packages", pkg)
+	mismatch := fmt.Errorf("the deployed code of function '%s' does not match the artifact", name)
+
+	// Functions deployed from a directory are zipped.
+	if info, err := os.Stat(filepath.Join(pkgDir, fn)); err == nil && info.IsDir() {
+		zipped, err := base64.StdEncoding.DecodeString(*action.Exec.Code)
+		if err != nil {
+			return 0, mismatch
+		}
+		zr, err := zip.NewReader(bytes.NewReader(zipped), int64(len(zipped)))
+		if err != nil {
+			return 0, mismatch
+		}
+		files := 0
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			want, err := os.ReadFile(filepath.Join(pkgDir, fn, filepath.FromSlash(f.Name)))
+			if err != nil {
+				return 0, fmt.Errorf("%w: %s is not in the artifact", mismatch, f.Name)
+			}
+			r, err := f.Open()
+			if err != nil {
+				return 0, err
+			}
+			got, err := io.ReadAll(r)
+			r.Close()
+			if err != nil {
+				return 0, err
+			}
+			if !bytes.Equal(got, want) {
+				return 0, fmt.Errorf("%w: %s differs", mismatch, f.Name)
+			}
+			files++
+		}
+		return files, nil
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(pkgDir, fn+".*"))
+	sort.Strings(matches)
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("function '%s' is not in the artifact", name)
+	}
+	want, err := os.ReadFile(matches[0])
+	if err != nil {
+		return 0, err
+	}
+	got := []byte(*action.Exec.Code)
+	if action.Exec.Binary != nil && *action.Exec.Binary {
+		if got, err = base64.StdEncoding.DecodeString(*action.Exec.Code); err != nil {
+			return 0, mismatch
+		}
+	}
+	if !bytes.Equal(got, want) {
+		return 0, mismatch
+	}
+	return 1, nil
+}
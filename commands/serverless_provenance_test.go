@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/openwhisk-client-go/whisk"
+	"github.com/digitalocean/doctl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+// writeSigningKeys writes a new ECDSA key pair as PEM files and returns their paths.
+func writeSigningKeys(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	private, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	public, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	privatePath, publicPath := filepath.Join(dir, "cosign.key"), filepath.Join(dir, "cosign.pub")
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: private}), 0600))
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}), 0644))
+	return privatePath, publicPath
+}
+
+func TestSignAndVerifyArtifact(t *testing.T) {
+	privateKey, publicKey := writeSigningKeys(t)
+	dir := t.TempDir()
+	artifact := filepath.Join(dir, "bundle.tar.gz")
+	require.NoError(t, os.WriteFile(artifact, []byte("artifact"), 0644))
+
+	signer, err := signArtifact(artifact, privateKey, artifact+".sig")
+	require.NoError(t, err)
+	verified, err := verifyArtifactSignature(artifact, publicKey, artifact+".sig")
+	require.NoError(t, err)
+	assert.Equal(t, signer, verified)
+
+	_, otherKey := writeSigningKeys(t)
+	_, err = verifyArtifactSignature(artifact, otherKey, artifact+".sig")
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(artifact, []byte("tampered"), 0644))
+	_, err = verifyArtifactSignature(artifact, publicKey, artifact+".sig")
+	assert.Error(t, err)
+}
+
+func TestAnnotateProvenance(t *testing.T) {
+	dir := writePackProject(t, map[string]string{
+		"project.yml": `packages:
+  - name: sample
+    functions:
+      - name: hello
+        web: true
+        annotations:
+          owner: team
+`,
+		"packages/sample/hello/index.js": "exports.main = () => ({})",
+		"packages/default/bye.py":        "def main(args): return {}",
+	})
+
+	p := artifactProvenance{Artifact: "sha256:abc", GitSHA: "0123456789abcdef", Builder: "ci"}
+	require.NoError(t, annotateProvenance(dir, p))
+
+	b, err := os.ReadFile(filepath.Join(dir, "project.yml"))
+	require.NoError(t, err)
+	var config struct {
+		Packages []struct {
+			Name      string `json:"name"`
+			Functions []struct {
+				Name        string                 `json:"name"`
+				Web         bool                   `json:"web"`
+				Annotations map[string]interface{} `json:"annotations"`
+			} `json:"functions"`
+		} `json:"packages"`
+	}
+	require.NoError(t, yaml.Unmarshal(b, &config))
+
+	want := map[string]interface{}{"artifact": "sha256:abc", "gitSHA": "0123456789abcdef", "builder": "ci"}
+	require.Len(t, config.Packages, 2)
+	assert.Equal(t, "default", config.Packages[1].Name)
+	assert.Equal(t, "bye", config.Packages[1].Functions[0].Name)
+	assert.Equal(t, want, config.Packages[1].Functions[0].Annotations[provenanceAnnotation])
+
+	hello := config.Packages[0].Functions[0]
+	assert.True(t, hello.Web)
+	assert.Equal(t, "team", hello.Annotations["owner"])
+	assert.Equal(t, want, hello.Annotations[provenanceAnnotation])
+}
+
+func TestDeployedCodeMatches(t *testing.T) {
+	dir := writePackProject(t, map[string]string{
+		"packages/sample/hello/index.js":     "exports.main = () => ({})",
+		"packages/sample/hello/package.json": "{}",
+		"packages/default/bye.py":            "def main(args): return {}",
+	})
+
+	zipped := func(files map[string]string) *string {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for name, content := range files {
+			w, err := zw.Create(name)
+			require.NoError(t, err)
+			_, err = w.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, zw.Close())
+		code := base64.StdEncoding.EncodeToString(buf.Bytes())
+		return &code
+	}
+	source := func(code string) *string { return &code }
+
+	tests := []struct {
+		name  string
+		fn    string
+		code  *string
+		files int
+		err   bool
+	}{
+		{name: "zipped", fn: "sample/hello", code: zipped(map[string]string{"index.js": "exports.main = () => ({})"}), files: 1},
+		{name: "zipped changed", fn: "sample/hello", code: zipped(map[string]string{"index.js": "exports.main = () => ({evil: true})"}), err: true},
+		{name: "zipped extra file", fn: "sample/hello", code: zipped(map[string]string{"extra.js": ""}), err: true},
+		{name: "single file", fn: "bye", code: source("def main(args): return {}"), files: 1},
+		{name: "single file changed", fn: "bye", code: source("def main(args): return {'evil': True}"), err: true},
+		{name: "not in the artifact", fn: "sample/other", code: source(""), err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := deployedCodeMatches(dir, tt.fn, whisk.Action{Exec: &whisk.Exec{Code: tt.code}})
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.files, files)
+		})
+	}
+}
+
+func TestRunServerlessVerify(t *testing.T) {
+	privateKey, publicKey := writeSigningKeys(t)
+	project := writePackProject(t, map[string]string{
+		"packages/sample/hello.js": "exports.main = () => ({})",
+	})
+	artifact := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	digest, err := writeArtifact(project, artifact)
+	require.NoError(t, err)
+	_, err = signArtifact(artifact, privateKey, artifact+".sig")
+	require.NoError(t, err)
+
+	code := "exports.main = () => ({})"
+	action := whisk.Action{
+		Exec: &whisk.Exec{Kind: "nodejs:18", Code: &code},
+		Annotations: whisk.KeyValueArr{{Key: provenanceAnnotation, Value: map[string]interface{}{
+			"artifact": "sha256:" + digest, "gitSHA": "0123456789abcdef", "builder": "ci",
+		}}},
+	}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var out bytes.Buffer
+		config.Out = &out
+		config.Args = []string{"sample/hello"}
+		config.Doit.Set(config.NS, doctl.ArgServerlessArtifact, artifact)
+		config.Doit.Set(config.NS, doctl.ArgServerlessKey, publicKey)
+
+		tm.serverless.EXPECT().CheckServerlessStatus().Return(nil)
+		tm.serverless.EXPECT().GetFunction("sample/hello", true).Return(action, nil, nil)
+
+		require.NoError(t, RunServerlessVerify(config))
+		assert.Contains(t, out.String(), "Artifact: sha256:"+digest)
+		assert.Contains(t, out.String(), "Git SHA: 0123456789abcdef")
+		assert.Contains(t, out.String(), "Code: matches the artifact (1 files)")
+	})
+
+	t.Run("different artifact", func(t *testing.T) {
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			config.Args = []string{"sample/hello"}
+			config.Doit.Set(config.NS, doctl.ArgServerlessArtifact, artifact)
+			config.Doit.Set(config.NS, doctl.ArgServerlessKey, publicKey)
+
+			other := action
+			other.Annotations = whisk.KeyValueArr{{Key: provenanceAnnotation, Value: map[string]interface{}{"artifact": "sha256:other"}}}
+			tm.serverless.EXPECT().CheckServerlessStatus().Return(nil)
+			tm.serverless.EXPECT().GetFunction("sample/hello", true).Return(other, nil, nil)
+
+			err := RunServerlessVerify(config)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "was deployed from artifact sha256:other")
+		})
+	})
+}
@@ -0,0 +1,396 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/google/uuid"
+)
+
+// serverlessDeploysDir returns the directory where deploy records are kept. It is replaced for testing.
+var serverlessDeploysDir = func() string {
+	return filepath.Join(defaultConfigHome(), "serverless-deploys")
+}
+
+// sbomSkipDirs are directories that never hold dependency manifests of a project's own code.
+var sbomSkipDirs = map[string]bool{
+	".git": true, ".deployed": true, ".nimbella": true, "node_modules": true,
+	"__pycache__": true, "virtualenv": true, ".venv": true,
+}
+
+var (
+	exactVersion          = regexp.MustCompile(`^v?[0-9][0-9A-Za-z.+-]*$`)
+	requirementSeparators = regexp.MustCompile(`[<>=!~;\[ @]`)
+)
+
+// deployRecord describes a deployment made with 'serverless deploy --sbom'.
+type deployRecord struct {
+	ID         string          `json:"id"`
+	Namespace  string          `json:"namespace,omitempty"`
+	Project    string          `json:"project"`
+	DeployedAt time.Time       `json:"deployed_at"`
+	Components []sbomComponent `json:"components"`
+}
+
+// sbomComponent is a dependency declared by a project.
+type sbomComponent struct {
+	Name      string `json:"name"`
+	Version   string `json:"version,omitempty"`
+	Ecosystem string `json:"ecosystem"`
+	Source    string `json:"source"`
+}
+
+// purl returns the package URL of a component. Version ranges are left out.
+func (sc sbomComponent) purl() string {
+	name := sc.Name
+	if sc.Ecosystem == "npm" {
+		name = strings.Replace(name, "@", "%40", 1)
+	}
+	purl := fmt.Sprintf("pkg:%s/%s", sc.Ecosystem, name)
+	if exactVersion.MatchString(sc.Version) {
+		purl += "@" + url.PathEscape(sc.Version)
+	}
+	return purl
+}
+
+// RunServerlessSBOM supports the 'serverless sbom' command
+func RunServerlessSBOM(c *CmdConfig) error {
+	if err := ensureOneArg(c); err != nil {
+		return err
+	}
+	format, _ := c.Doit.GetString(c.NS, doctl.ArgServerlessSBOMFormat)
+	record, err := readDeployRecord(c.Args[0])
+	if err != nil {
+		return err
+	}
+	var doc any
+	switch format {
+	case "cyclonedx":
+		doc = cycloneDXDocument(record)
+	case "spdx":
+		doc = spdxDocument(record)
+	default:
+		return fmt.Errorf("unsupported SBOM format '%s'; use 'cyclonedx' or 'spdx'", format)
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(c.Out, string(out))
+	return err
+}
+
+// newDeployRecord scans a project for dependencies before it is deployed.
+func newDeployRecord(project string) (*deployRecord, error) {
+	components, err := scanProjectDependencies(project)
+	if err != nil {
+		return nil, err
+	}
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	abs, err := filepath.Abs(project)
+	if err != nil {
+		return nil, err
+	}
+	return &deployRecord{
+		ID:         now.Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix),
+		Project:    abs,
+		DeployedAt: now,
+		Components: components,
+	}, nil
+}
+
+// saveDeployRecord stores a deploy record for later retrieval by 'serverless sbom'.
+func saveDeployRecord(record *deployRecord) error {
+	dir := serverlessDeploysDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, record.ID+".json"), append(data, '\n'), 0600)
+}
+
+// readDeployRecord reads a stored deploy record.
+func readDeployRecord(id string) (*deployRecord, error) {
+	if strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid deploy id '%s'", id)
+	}
+	data, err := os.ReadFile(filepath.Join(serverlessDeploysDir(), id+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no SBOM was recorded for deploy '%s' (use `doctl serverless deploy --sbom`)", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	record := &deployRecord{}
+	return record, json.Unmarshal(data, record)
+}
+
+// scanProjectDependencies lists the dependencies declared in the package.json (or
+// package-lock.json), go.mod, and requirements.txt files of a project.
+func scanProjectDependencies(project string) ([]sbomComponent, error) {
+	seen := map[string]bool{}
+	components := []sbomComponent{}
+	err := filepath.WalkDir(project, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != project && sbomSkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		var found []sbomComponent
+		switch d.Name() {
+		case "package.json":
+			found, err = npmDependencies(path)
+		case "go.mod":
+			found, err = goDependencies(path)
+		case "requirements.txt":
+			found, err = pythonDependencies(path)
+		default:
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		rel, _ := filepath.Rel(project, path)
+		for _, sc := range found {
+			sc.Source = filepath.ToSlash(rel)
+			key := sc.Ecosystem + "/" + sc.Name + "@" + sc.Version
+			if !seen[key] {
+				seen[key] = true
+				components = append(components, sc)
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(components, func(i, j int) bool {
+		if components[i].Ecosystem != components[j].Ecosystem {
+			return components[i].Ecosystem < components[j].Ecosystem
+		}
+		return components[i].Name < components[j].Name
+	})
+	return components, err
+}
+
+// npmDependencies returns the runtime dependencies of a node project, with exact versions
+// from package-lock.json if there is one, or as declared in package.json otherwise.
+func npmDependencies(path string) ([]sbomComponent, error) {
+	components := []sbomComponent{}
+	lock, err := os.ReadFile(filepath.Join(filepath.Dir(path), "package-lock.json"))
+	if err == nil {
+		var parsed struct {
+			Packages map[string]struct {
+				Version string `json:"version"`
+				Dev     bool   `json:"dev"`
+			} `json:"packages"`
+			Dependencies map[string]struct {
+				Version string `json:"version"`
+				Dev     bool   `json:"dev"`
+			} `json:"dependencies"`
+		}
+		if err := json.Unmarshal(lock, &parsed); err != nil {
+			return nil, err
+		}
+		for key, p := range parsed.Packages {
+			i := strings.LastIndex(key, "node_modules/")
+			if i < 0 || p.Dev {
+				continue
+			}
+			components = append(components, sbomComponent{Name: key[i+len("node_modules/"):], Version: p.Version, Ecosystem: "npm"})
+		}
+		if len(parsed.Packages) == 0 {
+			for name, p := range parsed.Dependencies {
+				if !p.Dev {
+					components = append(components, sbomComponent{Name: name, Version: p.Version, Ecosystem: "npm"})
+				}
+			}
+		}
+		return components, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var parsed struct {
+		Dependencies map[string]string `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, err
+	}
+	for name, version := range parsed.Dependencies {
+		components = append(components, sbomComponent{Name: name, Version: version, Ecosystem: "npm"})
+	}
+	return components, nil
+}
+
+// goDependencies returns the modules required by a go.mod file.
+func goDependencies(path string) ([]sbomComponent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	components := []sbomComponent{}
+	inRequire := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inRequire && fields[0] == ")":
+			inRequire = false
+			continue
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inRequire = true
+			continue
+		case fields[0] == "require":
+			fields = fields[1:]
+		case !inRequire:
+			continue
+		}
+		if len(fields) >= 2 {
+			components = append(components, sbomComponent{Name: fields[0], Version: fields[1], Ecosystem: "golang"})
+		}
+	}
+	return components, scanner.Err()
+}
+
+// pythonDependencies returns the requirements of a requirements.txt file. Only '==' pins
+// are recorded as versions.
+func pythonDependencies(path string) ([]sbomComponent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	components := []sbomComponent{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue
+		}
+		name := line
+		if i := requirementSeparators.FindStringIndex(line); i != nil {
+			name = line[:i[0]]
+		}
+		version := ""
+		if i := strings.Index(line, "=="); i >= 0 {
+			version = strings.TrimSpace(strings.SplitN(line[i+2:], ";", 2)[0])
+		}
+		components = append(components, sbomComponent{Name: strings.ToLower(name), Version: version, Ecosystem: "pypi"})
+	}
+	return components, scanner.Err()
+}
+
+// cycloneDXDocument renders a deploy record as a CycloneDX 1.5 JSON document.
+func cycloneDXDocument(record *deployRecord) map[string]any {
+	components := []map[string]any{}
+	for _, sc := range record.Components {
+		component := map[string]any{
+			"type":       "library",
+			"bom-ref":    sc.purl(),
+			"name":       sc.Name,
+			"purl":       sc.purl(),
+			"properties": []map[string]string{{"name": "doctl:source", "value": sc.Source}},
+		}
+		if sc.Version != "" {
+			component["version"] = sc.Version
+		}
+		components = append(components, component)
+	}
+	return map[string]any{
+		"bomFormat":    "CycloneDX",
+		"specVersion":  "1.5",
+		"serialNumber": "urn:uuid:" + uuid.NewSHA1(uuid.NameSpaceURL, []byte(record.ID)).String(),
+		"version":      1,
+		"metadata": map[string]any{
+			"timestamp": record.DeployedAt.Format(time.RFC3339),
+			"tools":     []map[string]string{{"vendor": "DigitalOcean", "name": "doctl", "version": doctl.DoitVersion.String()}},
+			"component": map[string]string{"type": "application", "name": filepath.Base(record.Project)},
+			"properties": []map[string]string{
+				{"name": "doctl:deploy-id", "value": record.ID},
+				{"name": "doctl:namespace", "value": record.Namespace},
+			},
+		},
+		"components": components,
+	}
+}
+
+// spdxDocument renders a deploy record as an SPDX 2.3 JSON document.
+func spdxDocument(record *deployRecord) map[string]any {
+	packages := []map[string]any{}
+	for i, sc := range record.Components {
+		pkg := map[string]any{
+			"name":             sc.Name,
+			"SPDXID":           fmt.Sprintf("SPDXRef-Package-%d", i+1),
+			"downloadLocation": "NOASSERTION",
+			"filesAnalyzed":    false,
+			"sourceInfo":       "declared in " + sc.Source,
+			"externalRefs": []map[string]string{{
+				"referenceCategory": "PACKAGE-MANAGER",
+				"referenceType":     "purl",
+				"referenceLocator":  sc.purl(),
+			}},
+		}
+		if sc.Version != "" {
+			pkg["versionInfo"] = sc.Version
+		}
+		packages = append(packages, pkg)
+	}
+	return map[string]any{
+		"spdxVersion":       "SPDX-2.3",
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            "SPDXRef-DOCUMENT",
+		"name":              filepath.Base(record.Project) + "-" + record.ID,
+		"documentNamespace": "urn:uuid:" + uuid.NewSHA1(uuid.NameSpaceURL, []byte(record.ID)).String(),
+		"creationInfo": map[string]any{
+			"created":  record.DeployedAt.Format(time.RFC3339),
+			"creators": []string{"Tool: doctl-" + doctl.DoitVersion.String()},
+		},
+		"packages": packages,
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSBOMProject(t *testing.T) string {
+	project := t.TempDir()
+	files := map[string]string{
+		"packages/api/hello/package.json":                     `{"dependencies": {"@scope/lib": "^1.0.0"}}`,
+		"packages/api/hello/package-lock.json":                `{"lockfileVersion": 3, "packages": {"": {}, "node_modules/@scope/lib": {"version": "1.2.0"}, "node_modules/jest": {"version": "29.0.0", "dev": true}}}`,
+		"packages/api/bye/package.json":                       `{"dependencies": {"left-pad": "^1.3.0"}}`,
+		"packages/api/bye/node_modules/left-pad/package.json": `{"dependencies": {"ignored": "1.0.0"}}`,
+		"packages/api/go/go.mod":                              "module example.com/fn\n\ngo 1.20\n\nrequire github.com/pkg/errors v0.9.1\n\nrequire (\n\tgolang.org/x/text v0.9.0 // indirect\n)\n\nreplace (\n\tfoo.com/bar v1.0.0 => ./bar\n)\n",
+		"packages/api/py/requirements.txt":                    "# pinned\nRequests==2.31.0 ; python_version > '3'\nflask>=2.0\n-r other.txt\n",
+	}
+	for name, contents := range files {
+		path := filepath.Join(project, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+	}
+	return project
+}
+
+func TestScanProjectDependencies(t *testing.T) {
+	components, err := scanProjectDependencies(writeSBOMProject(t))
+	require.NoError(t, err)
+	assert.Equal(t, []sbomComponent{
+		{Name: "github.com/pkg/errors", Version: "v0.9.1", Ecosystem: "golang", Source: "packages/api/go/go.mod"},
+		{Name: "golang.org/x/text", Version: "v0.9.0", Ecosystem: "golang", Source: "packages/api/go/go.mod"},
+		{Name: "@scope/lib", Version: "1.2.0", Ecosystem: "npm", Source: "packages/api/hello/package.json"},
+		{Name: "left-pad", Version: "^1.3.0", Ecosystem: "npm", Source: "packages/api/bye/package.json"},
+		{Name: "flask", Ecosystem: "pypi", Source: "packages/api/py/requirements.txt"},
+		{Name: "requests", Version: "2.31.0", Ecosystem: "pypi", Source: "packages/api/py/requirements.txt"},
+	}, components)
+}
+
+func TestSBOMComponentPurl(t *testing.T) {
+	assert.Equal(t, "pkg:npm/%40scope/lib@1.2.0", sbomComponent{Name: "@scope/lib", Version: "1.2.0", Ecosystem: "npm"}.purl())
+	assert.Equal(t, "pkg:npm/left-pad", sbomComponent{Name: "left-pad", Version: "^1.3.0", Ecosystem: "npm"}.purl())
+	assert.Equal(t, "pkg:golang/github.com/pkg/errors@v0.9.1", sbomComponent{Name: "github.com/pkg/errors", Version: "v0.9.1", Ecosystem: "golang"}.purl())
+}
+
+func TestServerlessSBOM(t *testing.T) {
+	dir := t.TempDir()
+	origDir := serverlessDeploysDir
+	serverlessDeploysDir = func() string { return dir }
+	defer func() { serverlessDeploysDir = origDir }()
+
+	record := &deployRecord{
+		ID:         "20240102T030405Z-a1b2c3",
+		Namespace:  "fn-ns",
+		Project:    "/home/sammy/project",
+		DeployedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Components: []sbomComponent{{Name: "left-pad", Version: "1.3.0", Ecosystem: "npm", Source: "packages/api/bye/package.json"}},
+	}
+	require.NoError(t, saveDeployRecord(record))
+
+	tests := []struct {
+		format   string
+		expected map[string]any
+	}{
+		{format: "cyclonedx", expected: map[string]any{"bomFormat": "CycloneDX", "specVersion": "1.5"}},
+		{format: "spdx", expected: map[string]any{"spdxVersion": "SPDX-2.3", "name": "project-20240102T030405Z-a1b2c3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+				buf := &bytes.Buffer{}
+				config.Out = buf
+				config.Args = []string{record.ID}
+				config.Doit.Set(config.NS, doctl.ArgServerlessSBOMFormat, tt.format)
+
+				require.NoError(t, RunServerlessSBOM(config))
+				var doc map[string]any
+				require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+				for k, v := range tt.expected {
+					assert.Equal(t, v, doc[k], k)
+				}
+				assert.Contains(t, buf.String(), "pkg:npm/left-pad@1.3.0")
+			})
+		})
+	}
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = []string{"unknown"}
+		config.Doit.Set(config.NS, doctl.ArgServerlessSBOMFormat, "cyclonedx")
+		assert.ErrorContains(t, RunServerlessSBOM(config), "no SBOM was recorded for deploy 'unknown'")
+	})
+}
+
+func TestServerlessDeploySBOM(t *testing.T) {
+	dir := t.TempDir()
+	origDir := serverlessDeploysDir
+	serverlessDeploysDir = func() string { return dir }
+	defer func() { serverlessDeploysDir = origDir }()
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		buf := &bytes.Buffer{}
+		config.Out = buf
+		project := writeSBOMProject(t)
+		config.Args = []string{project}
+		config.Doit.Set(config.NS, flagSBOM, true)
+
+		fakeCmd := &exec.Cmd{Stdout: config.Out}
+		tm.serverless.EXPECT().CheckServerlessStatus().MinTimes(1).Return(nil)
+		tm.serverless.EXPECT().Cmd("deploy", []string{project, "--exclude", "web"}).Return(fakeCmd, nil)
+		tm.serverless.EXPECT().Exec(fakeCmd).Return(do.ServerlessOutput{}, nil)
+		tm.serverless.EXPECT().ReadCredentials().Return(do.ServerlessCredentials{Namespace: "fn-ns"}, nil)
+
+		require.NoError(t, RunServerlessExtraDeploy(config))
+		assert.Contains(t, buf.String(), "Recorded an SBOM of 6 dependencies for deploy ")
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		record, err := readDeployRecord(strings.TrimSuffix(entries[0].Name(), ".json"))
+		require.NoError(t, err)
+		assert.Equal(t, "fn-ns", record.Namespace)
+		assert.Len(t, record.Components, 6)
+	})
+}
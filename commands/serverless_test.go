@@ -18,12 +18,16 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/apache/openwhisk-client-go/whisk"
 	"github.com/digitalocean/doctl/do"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -501,6 +505,66 @@ func TestServerlessDeploy(t *testing.T) {
 	}
 }
 
+func TestServerlessDeployHooks(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var hookBody string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			hookBody = string(b)
+		}))
+		defer srv.Close()
+
+		project := t.TempDir()
+		projectYml := `preDeploy:
+  - run: echo "$DOCTL_FUNCTIONS_NAMESPACE" > pre.txt
+postDeploy:
+  - run: echo "$DOCTL_FUNCTION_URL_SAMPLE_HELLO" > post.txt
+  - http:
+      url: ` + srv.URL + `
+      body: '{{index .Functions "sample/hello"}}'
+`
+		require.NoError(t, os.WriteFile(filepath.Join(project, "project.yml"), []byte(projectYml), 0644))
+
+		config.Args = append(config.Args, project)
+		fakeCmd := &exec.Cmd{Stdout: config.Out}
+		creds := do.ServerlessCredentials{APIHost: "https://api.example.com", Namespace: "fn-ns"}
+		hello := whisk.Action{
+			Name:        "hello",
+			Namespace:   "fn-ns/sample",
+			Annotations: whisk.KeyValueArr{{Key: "web-export", Value: true}},
+		}
+
+		tm.serverless.EXPECT().ReadCredentials().Times(2).Return(creds, nil)
+		tm.serverless.EXPECT().CheckServerlessStatus().MinTimes(1).Return(nil)
+		tm.serverless.EXPECT().Cmd("deploy", []string{project, "--exclude", "web"}).Return(fakeCmd, nil)
+		tm.serverless.EXPECT().Exec(fakeCmd).Return(do.ServerlessOutput{}, nil)
+		tm.serverless.EXPECT().ListFunctions("", 0, 200).Return([]whisk.Action{hello}, nil)
+
+		err := RunServerlessExtraDeploy(config)
+		require.NoError(t, err)
+
+		url := "https://api.example.com/api/v1/web/fn-ns/sample/hello"
+		pre, _ := os.ReadFile(filepath.Join(project, "pre.txt"))
+		assert.Equal(t, "fn-ns\n", string(pre))
+		post, _ := os.ReadFile(filepath.Join(project, "post.txt"))
+		assert.Equal(t, url+"\n", string(post))
+		assert.Equal(t, url, hookBody)
+	})
+}
+
+func TestServerlessDeployPreDeployHookFailure(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		project := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(project, "project.yml"), []byte("preDeploy:\n  - run: exit 3\n"), 0644))
+		config.Args = append(config.Args, project)
+
+		tm.serverless.EXPECT().ReadCredentials().Return(do.ServerlessCredentials{}, nil)
+
+		err := RunServerlessExtraDeploy(config)
+		assert.ErrorContains(t, err, "preDeploy hook 1 failed")
+	})
+}
+
 func TestServerlessUndeploy(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -15,12 +15,16 @@ package commands
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/do"
+	"github.com/spf13/viper"
 )
 
 const (
@@ -44,7 +48,8 @@ func serverlessExecNoCheck(serverless do.ServerlessService, command string, args
 	if err != nil {
 		return do.ServerlessOutput{}, err
 	}
-	return serverless.Exec(cmd)
+	output, err := serverless.Exec(cmd)
+	return output, explainServerlessError(err)
 }
 
 // RunServerlessExec is a variant of ServerlessExec convenient for calling from stylized command runners
@@ -62,7 +67,21 @@ func RunServerlessExec(command string, c *CmdConfig, booleanFlags []string, stri
 		return do.ServerlessOutput{}, err
 	}
 
-	return serverless.Exec(cmd)
+	output, err := serverless.Exec(cmd)
+	return output, explainServerlessError(err)
+}
+
+// explainServerlessError adds remediation to errors of known kinds reported by the serverless plugin.
+func explainServerlessError(err error) error {
+	switch {
+	case errors.Is(err, do.ErrServerlessAuth):
+		return fmt.Errorf("%w\nThe functions namespace rejected the credentials; reconnect with `doctl serverless connect`", err)
+	case errors.Is(err, do.ErrServerlessQuota):
+		return fmt.Errorf("%w\nA limit of the functions namespace was exceeded; wait and retry, or reduce the number or size of the functions deployed at once", err)
+	case errors.Is(err, do.ErrServerlessProjectConfig):
+		return fmt.Errorf("%w\nFix the project's project.yml; `doctl serverless get-metadata <directory>` checks it without deploying", err)
+	}
+	return err
 }
 
 // RunServerlessExecStreaming is like RunServerlessExec but assumes that output will not be captured and can be streamed.
@@ -109,6 +128,11 @@ func (c *CmdConfig) PrintServerlessTextOutput(output do.ServerlessOutput) error
 	return err
 }
 
+// serverlessPluginTimeout returns the --plugin-timeout, which may also be set in the config file or environment.
+func serverlessPluginTimeout() time.Duration {
+	return viper.GetDuration(doctl.ArgServerlessPluginTimeout)
+}
+
 func hashAccessToken(c *CmdConfig) string {
 	return do.HashAccessToken(c.getContextAccessToken())
 }
@@ -183,7 +183,7 @@ func RunSnapshotDelete(c *CmdConfig) error {
 	ss := c.Snapshots()
 	ids := c.Args
 
-	if force || AskForConfirmDelete("snapshot", len(ids)) == nil {
+	if force || AskForConfirmDelete("snapshot", len(ids), ids...) == nil {
 		for _, id := range ids {
 			err := ss.Delete(id)
 			if err != nil {
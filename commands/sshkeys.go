@@ -14,16 +14,34 @@ limitations under the License.
 package commands
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
 	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
 
 	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/commands/displayers"
 	"github.com/digitalocean/doctl/do"
+	sshopts "github.com/digitalocean/doctl/pkg/ssh"
 	"github.com/digitalocean/godo"
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh"
 )
 
+// addKeyToAgent adds a private key to the running ssh-agent. It is replaced for testing.
+var addKeyToAgent = func(keyPath string) error {
+	cmd := exec.Command("ssh-add", keyPath)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
 // SSHKeys creates the ssh key commands hierarchy.
 func SSHKeys() *Command {
 	cmd := &Command{
@@ -67,6 +85,26 @@ Note that this does not delete an SSH key from any Droplets.`, Writer,
 		aliasOpt("u"), displayerType(&displayers.Key{}))
 	AddStringFlag(cmdSSHKeysUpdate, doctl.ArgKeyName, "", "", "Key name", requiredOpt())
 
+	cmdSSHKeysGenerate := CmdBuilder(cmd, RunKeyGenerate, "generate <key-name>", "Generate a new SSH key pair locally", `Use this command to generate a new ed25519 SSH key pair on your computer.
+
+The private key is written to `+"`"+`--key-path`+"`"+` (by default `+"`"+`~/.ssh/id_ed25519_<key-name>`+"`"+`) and the public key to the same path with a `+"`"+`.pub`+"`"+` suffix. Set `+"`"+`--upload`+"`"+` to also add the public key to your account, and `+"`"+`--add-agent`+"`"+` to load the private key into your running ssh-agent.`, Writer,
+		displayerType(&displayers.Key{}))
+	AddStringFlag(cmdSSHKeysGenerate, doctl.ArgKeyPath, "", "", "Path to write the private key to")
+	AddBoolFlag(cmdSSHKeysGenerate, doctl.ArgKeyUpload, "", false, "Add the public key to your account")
+	AddBoolFlag(cmdSSHKeysGenerate, doctl.ArgKeyAddAgent, "", false, "Add the private key to the running ssh-agent")
+	cmdSSHKeysGenerate.Example = `The following example generates a key pair named ` + "`" + `deploy` + "`" + `, uploads the public key, and adds the private key to ssh-agent: doctl compute ssh-key generate deploy --upload --add-agent`
+
+	cmdSSHKeysRotate := CmdBuilder(cmd, RunKeyRotate, "rotate <key-id|key-fingerprint>", "Replace an SSH key on your account with a newly generated one", `Use this command to replace an SSH key on your account with a newly generated ed25519 key of the same name.
+
+Keys are only embedded into Droplets when they are created, so rotating a key on your account does not affect existing Droplets. To also replace the key on existing Droplets, pass the Droplets' tag with `+"`"+`--tag`+"`"+`. doctl then connects to each tagged Droplet over SSH and swaps the old key for the new one in the user's `+"`"+`authorized_keys`+"`"+` file. The old key is removed from your account only after every Droplet has been updated.`, Writer,
+		displayerType(&displayers.Key{}))
+	AddStringFlag(cmdSSHKeysRotate, doctl.ArgKeyPath, "", "", "Path to write the new private key to")
+	AddBoolFlag(cmdSSHKeysRotate, doctl.ArgKeyAddAgent, "", false, "Add the new private key to the running ssh-agent")
+	AddStringSliceFlag(cmdSSHKeysRotate, doctl.ArgTag, "", []string{}, "Replace the key on Droplets with this tag")
+	AddStringFlag(cmdSSHKeysRotate, doctl.ArgSSHUser, "", "root", "SSH user for connecting to Droplets")
+	AddStringFlag(cmdSSHKeysRotate, doctl.ArgsSSHKeyPath, "", defaultSSHKeyPath("id_rsa"), "Path to the SSH private key used to connect to Droplets")
+	cmdSSHKeysRotate.Example = `The following example rotates the key with ID ` + "`" + `512189` + "`" + ` and updates it on all Droplets tagged ` + "`" + `web` + "`" + `: doctl compute ssh-key rotate 512189 --tag web --ssh-key-path ~/.ssh/id_ed25519_deploy`
+
 	return cmd
 }
 
@@ -191,7 +229,7 @@ func RunKeyDelete(c *CmdConfig) error {
 		return nil
 	}
 
-	if force || AskForConfirmDelete("SSH key", 1) == nil {
+	if force || AskForConfirmDelete("SSH key", 1, c.Args[0]) == nil {
 		rawKey := c.Args[0]
 		return ks.Delete(rawKey)
 	}
@@ -227,3 +265,268 @@ func RunKeyUpdate(c *CmdConfig) error {
 	item := &displayers.Key{Keys: do.SSHKeys{*k}}
 	return c.Display(item)
 }
+
+// RunKeyGenerate generates a new SSH key pair locally and optionally uploads it.
+func RunKeyGenerate(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+
+	name := c.Args[0]
+
+	keyPath, err := c.Doit.GetString(c.NS, doctl.ArgKeyPath)
+	if err != nil {
+		return err
+	}
+	if keyPath == "" {
+		keyPath, err = generatedSSHKeyPath(name)
+		if err != nil {
+			return err
+		}
+	}
+
+	upload, err := c.Doit.GetBool(c.NS, doctl.ArgKeyUpload)
+	if err != nil {
+		return err
+	}
+
+	addAgent, err := c.Doit.GetBool(c.NS, doctl.ArgKeyAddAgent)
+	if err != nil {
+		return err
+	}
+
+	publicKey, err := generateSSHKeyPair(keyPath, name)
+	if err != nil {
+		return err
+	}
+	notice("Wrote private key to %s and public key to %s.pub", keyPath, keyPath)
+
+	if addAgent {
+		if err := addKeyToAgent(keyPath); err != nil {
+			return fmt.Errorf("adding key to ssh-agent: %w", err)
+		}
+	}
+
+	if !upload {
+		return nil
+	}
+
+	r, err := c.Keys().Create(&godo.KeyCreateRequest{
+		Name:      name,
+		PublicKey: publicKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	item := &displayers.Key{Keys: do.SSHKeys{*r}}
+	return c.Display(item)
+}
+
+// RunKeyRotate replaces an SSH key on the account, and optionally on tagged droplets,
+// with a newly generated key.
+func RunKeyRotate(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+
+	keyPath, err := c.Doit.GetString(c.NS, doctl.ArgKeyPath)
+	if err != nil {
+		return err
+	}
+
+	addAgent, err := c.Doit.GetBool(c.NS, doctl.ArgKeyAddAgent)
+	if err != nil {
+		return err
+	}
+
+	tags, err := c.Doit.GetStringSlice(c.NS, doctl.ArgTag)
+	if err != nil {
+		return err
+	}
+
+	sshUser, err := c.Doit.GetString(c.NS, doctl.ArgSSHUser)
+	if err != nil {
+		return err
+	}
+
+	sshKeyPath, err := c.Doit.GetString(c.NS, doctl.ArgsSSHKeyPath)
+	if err != nil {
+		return err
+	}
+
+	ks := c.Keys()
+	old, err := ks.Get(c.Args[0])
+	if err != nil {
+		return err
+	}
+
+	// A key that can't be found in authorized_keys can't be replaced there, so
+	// refuse before anything is generated or uploaded.
+	if len(tags) > 0 {
+		if _, err := authorizedKeyFields(old.PublicKey); err != nil {
+			return fmt.Errorf("can't replace key %s on Droplets: %w", old.Name, err)
+		}
+	}
+
+	if keyPath == "" {
+		keyPath, err = generatedSSHKeyPath(old.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	publicKey, err := generateSSHKeyPair(keyPath, old.Name)
+	if err != nil {
+		return err
+	}
+
+	var replaceCmd string
+	if len(tags) > 0 {
+		replaceCmd, err = replaceAuthorizedKeyCommand(old.PublicKey, publicKey)
+		if err != nil {
+			return err
+		}
+	}
+	notice("Wrote private key to %s and public key to %s.pub", keyPath, keyPath)
+
+	if addAgent {
+		if err := addKeyToAgent(keyPath); err != nil {
+			return fmt.Errorf("adding key to ssh-agent: %w", err)
+		}
+	}
+
+	created, err := ks.Create(&godo.KeyCreateRequest{
+		Name:      old.Name,
+		PublicKey: publicKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, tag := range tags {
+		droplets, err := c.Droplets().ListByTag(tag)
+		if err != nil {
+			return err
+		}
+
+		for _, d := range droplets {
+			ip, err := d.PublicIPv4()
+			if err == nil && ip == "" {
+				err = errors.New("no public IPv4 address")
+			}
+			if err == nil {
+				opts := sshopts.Options{
+					doctl.ArgsSSHAgentForwarding: false,
+					doctl.ArgSSHCommand:          replaceCmd,
+					doctl.ArgSSHRetryMax:         0,
+				}
+				err = c.Doit.SSH(sshUser, ip, sshKeyPath, 22, opts).Run()
+			}
+			if err != nil {
+				warn("Could not replace the key on Droplet %s (%d): %v", d.Name, d.ID, err)
+				failed = append(failed, d.Name)
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("the new key was added to your account, but the old key was kept because it could not be replaced on these Droplets: %s", strings.Join(failed, ", "))
+	}
+
+	if err := ks.Delete(fmt.Sprint(old.ID)); err != nil {
+		return err
+	}
+
+	item := &displayers.Key{Keys: do.SSHKeys{*created}}
+	return c.Display(item)
+}
+
+// generateSSHKeyPair creates an ed25519 key pair, writes the private key to
+// keyPath and the public key to keyPath.pub, and returns the public key in
+// authorized_keys format.
+func generateSSHKeyPair(keyPath, comment string) (string, error) {
+	if _, err := os.Stat(keyPath); err == nil {
+		return "", fmt.Errorf("%s already exists; choose another path with --%s", keyPath, doctl.ArgKeyPath)
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	block, err := ssh.MarshalPrivateKey(priv, comment)
+	if err != nil {
+		return "", err
+	}
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	authorizedKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))) + " " + comment
+
+	if err := os.MkdirAll(filepath.Dir(keyPath), 0700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(keyPath+".pub", []byte(authorizedKey+"\n"), 0644); err != nil {
+		return "", err
+	}
+
+	return authorizedKey, nil
+}
+
+// replaceAuthorizedKeyCommand builds a shell command that swaps one public key
+// for another in the remote user's authorized_keys file.
+func replaceAuthorizedKeyCommand(oldKey, newKey string) (string, error) {
+	oldFields, err := authorizedKeyFields(oldKey)
+	if err != nil {
+		return "", err
+	}
+	newFields, err := authorizedKeyFields(newKey)
+	if err != nil {
+		return "", err
+	}
+
+	// Only the key type and base64 body are used; comments may contain characters
+	// that would need shell quoting.
+	return fmt.Sprintf("f=~/.ssh/authorized_keys; grep -vF '%s' $f > $f.doctl; echo '%s %s' >> $f.doctl && chmod 600 $f.doctl && mv $f.doctl $f",
+		oldFields[1], newFields[0], newFields[1]), nil
+}
+
+// authorizedKeyFields returns the fields of a public key in authorized_keys
+// format, making sure the key type and body are there and are safe to quote.
+func authorizedKeyFields(key string) ([]string, error) {
+	fields := strings.Fields(key)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("malformed public key %q: expected a key type and a key", key)
+	}
+	if strings.ContainsRune(fields[0]+fields[1], '\'') {
+		return nil, fmt.Errorf("malformed public key %q: unexpected quote", key)
+	}
+	return fields, nil
+}
+
+// generatedSSHKeyPath returns the default path of a key generated for the
+// named SSH key. The name becomes part of a file name, so it can't contain
+// path separators or "..".
+func generatedSSHKeyPath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return "", fmt.Errorf("%q can't be used in a file name; choose a path with --%s", name, doctl.ArgKeyPath)
+	}
+	return defaultSSHKeyPath("id_ed25519_" + name), nil
+}
+
+func defaultSSHKeyPath(name string) string {
+	usr, err := user.Current()
+	if err != nil {
+		return filepath.Join(".ssh", name)
+	}
+	return filepath.Join(usr.HomeDir, ".ssh", name)
+}
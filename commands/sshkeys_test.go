@@ -14,14 +14,20 @@ limitations under the License.
 package commands
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/doctl/pkg/runner"
+	"github.com/digitalocean/doctl/pkg/ssh"
 	"github.com/digitalocean/godo"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 var (
@@ -32,7 +38,7 @@ var (
 func TestSSHKeysCommand(t *testing.T) {
 	cmd := SSHKeys()
 	assert.NotNil(t, cmd)
-	assertCommandNames(t, cmd, "create", "delete", "get", "import", "list", "update")
+	assertCommandNames(t, cmd, "create", "delete", "generate", "get", "import", "list", "rotate", "update")
 }
 
 func TestKeysList(t *testing.T) {
@@ -156,3 +162,144 @@ func TestSSHPublicKeyImportWithName(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestKeysGenerate(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		keyPath := filepath.Join(t.TempDir(), "id_ed25519_deploy")
+
+		var agentPath string
+		origAdd := addKeyToAgent
+		addKeyToAgent = func(p string) error {
+			agentPath = p
+			return nil
+		}
+		defer func() { addKeyToAgent = origAdd }()
+
+		tm.keys.EXPECT().Create(gomock.Any()).DoAndReturn(func(kcr *godo.KeyCreateRequest) (*do.SSHKey, error) {
+			assert.Equal(t, "deploy", kcr.Name)
+			assert.True(t, strings.HasPrefix(kcr.PublicKey, "ssh-ed25519 "))
+			return &testKey, nil
+		})
+
+		config.Args = append(config.Args, "deploy")
+		config.Doit.Set(config.NS, doctl.ArgKeyPath, keyPath)
+		config.Doit.Set(config.NS, doctl.ArgKeyUpload, true)
+		config.Doit.Set(config.NS, doctl.ArgKeyAddAgent, true)
+
+		err := RunKeyGenerate(config)
+		require.NoError(t, err)
+		assert.Equal(t, keyPath, agentPath)
+
+		info, err := os.Stat(keyPath)
+		require.NoError(t, err)
+		if os.PathSeparator == '/' {
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		}
+		assert.FileExists(t, keyPath+".pub")
+
+		// An existing key is never overwritten.
+		err = RunKeyGenerate(config)
+		assert.ErrorContains(t, err, "already exists")
+	})
+}
+
+func TestKeysRotate(t *testing.T) {
+	oldKey := do.SSHKey{Key: &godo.Key{ID: 1, Name: "deploy", PublicKey: "ssh-rsa AAAAold deploy"}}
+	newKey := do.SSHKey{Key: &godo.Key{ID: 2, Name: "deploy"}}
+
+	t.Run("account only", func(t *testing.T) {
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			tm.keys.EXPECT().Get("1").Return(&oldKey, nil)
+			tm.keys.EXPECT().Create(gomock.Any()).Return(&newKey, nil)
+			tm.keys.EXPECT().Delete("1").Return(nil)
+
+			config.Args = append(config.Args, "1")
+			config.Doit.Set(config.NS, doctl.ArgKeyPath, filepath.Join(t.TempDir(), "id_ed25519"))
+			config.Doit.Set(config.NS, doctl.ArgKeyAddAgent, false)
+			config.Doit.Set(config.NS, doctl.ArgTag, []string{})
+
+			err := RunKeyRotate(config)
+			assert.NoError(t, err)
+		})
+	})
+
+	t.Run("keeps old key when a droplet fails", func(t *testing.T) {
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			tm.keys.EXPECT().Get("1").Return(&oldKey, nil)
+			tm.keys.EXPECT().Create(gomock.Any()).Return(&newKey, nil)
+			tm.droplets.EXPECT().ListByTag("web").Return(do.Droplets{testDroplet}, nil)
+			tm.sshRunner.EXPECT().Run().Return(errors.New("connection refused"))
+
+			tc := config.Doit.(*doctl.TestConfig)
+			tc.SSHFn = func(user, host, keyPath string, port int, opts ssh.Options) runner.Runner {
+				assert.Equal(t, "root", user)
+				assert.Contains(t, opts[doctl.ArgSSHCommand], "AAAAold")
+				return tm.sshRunner
+			}
+
+			config.Args = append(config.Args, "1")
+			config.Doit.Set(config.NS, doctl.ArgKeyPath, filepath.Join(t.TempDir(), "id_ed25519"))
+			config.Doit.Set(config.NS, doctl.ArgKeyAddAgent, false)
+			config.Doit.Set(config.NS, doctl.ArgTag, []string{"web"})
+			config.Doit.Set(config.NS, doctl.ArgSSHUser, "root")
+
+			err := RunKeyRotate(config)
+			assert.ErrorContains(t, err, "old key was kept")
+		})
+	})
+
+	t.Run("refuses a malformed old key before rotating", func(t *testing.T) {
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			malformed := do.SSHKey{Key: &godo.Key{ID: 1, Name: "deploy", PublicKey: "AAAAold"}}
+			tm.keys.EXPECT().Get("1").Return(&malformed, nil)
+
+			keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+			config.Args = append(config.Args, "1")
+			config.Doit.Set(config.NS, doctl.ArgKeyPath, keyPath)
+			config.Doit.Set(config.NS, doctl.ArgKeyAddAgent, false)
+			config.Doit.Set(config.NS, doctl.ArgTag, []string{"web"})
+
+			err := RunKeyRotate(config)
+			assert.ErrorContains(t, err, "malformed public key")
+			assert.NoFileExists(t, keyPath)
+		})
+	})
+
+	t.Run("refuses a key name that isn't a safe file name", func(t *testing.T) {
+		withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+			unsafe := do.SSHKey{Key: &godo.Key{ID: 1, Name: "../../.bashrc", PublicKey: "ssh-rsa AAAAold"}}
+			tm.keys.EXPECT().Get("1").Return(&unsafe, nil)
+
+			config.Args = append(config.Args, "1")
+			config.Doit.Set(config.NS, doctl.ArgKeyAddAgent, false)
+			config.Doit.Set(config.NS, doctl.ArgTag, []string{})
+
+			err := RunKeyRotate(config)
+			assert.ErrorContains(t, err, "can't be used in a file name")
+		})
+	})
+}
+
+func TestReplaceAuthorizedKeyCommand(t *testing.T) {
+	cmd, err := replaceAuthorizedKeyCommand("ssh-rsa AAAAold old comment", "ssh-ed25519 AAAAnew new")
+	require.NoError(t, err)
+	assert.Contains(t, cmd, "grep -vF 'AAAAold'")
+	assert.Contains(t, cmd, "echo 'ssh-ed25519 AAAAnew'")
+	assert.NotContains(t, cmd, "comment")
+
+	_, err = replaceAuthorizedKeyCommand("AAAAold", "ssh-ed25519 AAAAnew new")
+	assert.ErrorContains(t, err, "malformed public key")
+	_, err = replaceAuthorizedKeyCommand("ssh-rsa AAAA'old", "ssh-ed25519 AAAAnew new")
+	assert.ErrorContains(t, err, "malformed public key")
+}
+
+func TestGeneratedSSHKeyPath(t *testing.T) {
+	p, err := generatedSSHKeyPath("deploy")
+	require.NoError(t, err)
+	assert.Equal(t, "id_ed25519_deploy", filepath.Base(p))
+
+	for _, name := range []string{"", "../deploy", "a/b", `a\b`, ".."} {
+		_, err := generatedSSHKeyPath(name)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/digitalocean/doctl"
+)
+
+// printSummary writes the API usage summary collected during the command to w
+// when the --summary flag is set.
+func printSummary(w io.Writer) {
+	if !Summary {
+		return
+	}
+	writeSummary(w, doctl.Stats.Summary())
+}
+
+func writeSummary(w io.Writer, s doctl.APIStatsSummary) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "API usage summary:")
+	fmt.Fprintf(tw, "  API calls:\t%d\n", s.Requests)
+	fmt.Fprintf(tw, "  Retries:\t%d\n", s.Retries)
+	fmt.Fprintf(tw, "  Total latency:\t%s\n", s.Latency.Round(time.Millisecond))
+	if s.RateLimit > 0 {
+		fmt.Fprintf(tw, "  Rate limit:\t%d of %d remaining (%d used)\n", s.RateLimitRemaining, s.RateLimit, s.RateLimitUsed)
+	} else {
+		fmt.Fprintf(tw, "  Rate limit:\tunknown\n")
+	}
+	tw.Flush()
+}
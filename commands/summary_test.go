@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteSummary(t *testing.T) {
+	var buf bytes.Buffer
+	writeSummary(&buf, doctl.APIStatsSummary{
+		Requests:           3,
+		Retries:            1,
+		Latency:            1500 * time.Millisecond,
+		RateLimit:          5000,
+		RateLimitRemaining: 4996,
+		RateLimitUsed:      4,
+	})
+
+	expected := `API usage summary:
+  API calls:      3
+  Retries:        1
+  Total latency:  1.5s
+  Rate limit:     4996 of 5000 remaining (4 used)
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestPrintSummaryDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	printSummary(&buf)
+	assert.Empty(t, buf.String())
+}
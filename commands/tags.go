@@ -14,6 +14,8 @@ limitations under the License.
 package commands
 
 import (
+	"context"
+
 	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/doctl/commands/displayers"
 	"github.com/digitalocean/doctl/do"
@@ -58,12 +60,16 @@ Deleting a tag also removes the tag from all the resources that had been tagged
 
 	cmdApplyTag := CmdBuilder(cmd, RunCmdApplyTag, "apply <tag-name> --resource=<urn> [--resource=<urn> ...]", "Apply a tag to resources", `Tag one or more resources. You can tag Droplets, images, volumes, volume snapshots, and database clusters.
 	
-Resources must be specified as Uniform Resource Names (URNs) and has the following syntax: `+"`"+`do:<resource_type>:<identifier>`+"`"+`.`, Writer)
+Resources must be specified as Uniform Resource Names (URNs) and has the following syntax: `+"`"+`do:<resource_type>:<identifier>`+"`"+`.
+
+Resources are tagged 50 at a time, and progress is checkpointed. If the command is interrupted or a request fails, it prints a checkpoint file to run it again with, using `+"`"+`--resume`+"`"+`, which tags only the resources that remain.`, Writer)
 	AddStringSliceFlag(cmdApplyTag, doctl.ArgResourceType, "", []string{}, "The resource to tag in URN format", requiredOpt())
+	AddStringFlag(cmdApplyTag, doctl.ArgResume, "", "", "Resume an interrupted tagging from its checkpoint file. The resources are those of the interrupted run")
 	cmdApplyTag.Example = `The following example tags two Droplet with the tag named ` + "`" + `web` + "`" + `: doctl compute tag apply web --resource=do:droplet:386734086,do:droplet:191669331`
 
-	cmdRemoveTag := CmdBuilder(cmd, RunCmdRemoveTag, "remove <tag-name> --resource=<urn> [--resource=<urn> ...]", "Remove a tag from resources", `Removes a tag from one or more resources. Resources must be specified as Uniform Resource Names (URNs) and has the following syntax: `+"`"+`do:<resource_type>:<identifier>`+"`"+`.`, Writer)
+	cmdRemoveTag := CmdBuilder(cmd, RunCmdRemoveTag, "remove <tag-name> --resource=<urn> [--resource=<urn> ...]", "Remove a tag from resources", `Removes a tag from one or more resources. Resources must be specified as Uniform Resource Names (URNs) and has the following syntax: `+"`"+`do:<resource_type>:<identifier>`+"`"+`. Resources are untagged 50 at a time, and progress is checkpointed, so an interrupted run can be continued with `+"`"+`--resume`+"`"+`.`, Writer)
 	AddStringSliceFlag(cmdRemoveTag, doctl.ArgResourceType, "", []string{}, "The resource to untag in URN format", requiredOpt())
+	AddStringFlag(cmdRemoveTag, doctl.ArgResume, "", "", "Resume an interrupted untagging from its checkpoint file. The resources are those of the interrupted run")
 	cmdRemoveTag.Example = `The following example removes the tag named ` + "`" + `web` + "`" + ` from two Droplets: doctl compute tag remove web --resource=do:droplet:386734086,do:droplet:191669331`
 
 	return cmd
@@ -127,7 +133,7 @@ func RunCmdTagDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if force || AskForConfirmDelete("tag", len(c.Args)) == nil {
+	if force || AskForConfirmDelete("tag", len(c.Args), c.Args...) == nil {
 		for id := range c.Args {
 			name := c.Args[id]
 			ts := c.Tags()
@@ -142,6 +148,9 @@ func RunCmdTagDelete(c *CmdConfig) error {
 	return nil
 }
 
+// tagBatchSize is the number of resources tagged or untagged by each request.
+const tagBatchSize = 50
+
 // RunCmdApplyTag applies a tag to one or more resources.
 func RunCmdApplyTag(c *CmdConfig) error {
 	err := ensureOneArg(c)
@@ -150,19 +159,9 @@ func RunCmdApplyTag(c *CmdConfig) error {
 	}
 	tagName := c.Args[0]
 
-	urns, err := c.Doit.GetStringSlice(c.NS, doctl.ArgResourceType)
-	if err != nil {
-		return err
-	}
-
-	resourceReq, err := buildTagResources(urns)
-	if err != nil {
-		return err
-	}
-
-	tagReq := &godo.TagResourcesRequest{Resources: resourceReq}
-
-	return c.Tags().TagResources(tagName, tagReq)
+	return tagInBatches(c, tagApplyOperation+" "+tagName, func(resources []godo.Resource) error {
+		return c.Tags().TagResources(tagName, &godo.TagResourcesRequest{Resources: resources})
+	})
 }
 
 // RunCmdRemoveTag removes a tag from one or more resources.
@@ -173,19 +172,37 @@ func RunCmdRemoveTag(c *CmdConfig) error {
 	}
 	tagName := c.Args[0]
 
+	return tagInBatches(c, tagRemoveOperation+" "+tagName, func(resources []godo.Resource) error {
+		return c.Tags().UntagResources(tagName, &godo.UntagResourcesRequest{Resources: resources})
+	})
+}
+
+// tagInBatches calls fn with the resources given with --resource, or those of
+// the interrupted run given with --resume, tagBatchSize at a time.
+func tagInBatches(c *CmdConfig, operation string, fn func([]godo.Resource) error) error {
 	urns, err := c.Doit.GetStringSlice(c.NS, doctl.ArgResourceType)
 	if err != nil {
 		return err
 	}
 
-	resourceReq, err := buildTagResources(urns)
-	if err != nil {
+	// All the URNs are checked before any resource is changed.
+	if _, err := buildTagResources(urns); err != nil {
 		return err
 	}
 
-	tagReq := &godo.UntagResourcesRequest{Resources: resourceReq}
-
-	return c.Tags().UntagResources(tagName, tagReq)
+	e, err := newBulkExecutor(c, operation, urns)
+	if err != nil {
+		return err
+	}
+	return runBulk(e, func(ctx context.Context) error {
+		return e.RunBatches(ctx, tagBatchSize, func(urns []string) error {
+			resources, err := buildTagResources(urns)
+			if err != nil {
+				return err
+			}
+			return fn(resources)
+		})
+	})
 }
 
 func buildTagResources(urns []string) ([]godo.Resource, error) {
@@ -23,6 +23,7 @@ import (
 	"github.com/digitalocean/godo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 var (
@@ -161,6 +162,26 @@ func TestTagApplyWithDBaaS(t *testing.T) {
 	})
 }
 
+func TestTagApplyInBatches(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		var urns []string
+		for i := 0; i < tagBatchSize+10; i++ {
+			urns = append(urns, fmt.Sprintf("do:droplet:%d", i))
+		}
+		var sizes []int
+		tm.tags.EXPECT().TagResources("my-tag", gomock.Any()).DoAndReturn(func(tag string, req *godo.TagResourcesRequest) error {
+			sizes = append(sizes, len(req.Resources))
+			return nil
+		}).Times(2)
+		config.Args = append(config.Args, "my-tag")
+		config.Doit.Set(config.NS, doctl.ArgResourceType, urns)
+
+		err := RunCmdApplyTag(config)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{tagBatchSize, 10}, sizes)
+	})
+}
+
 func TestTagApplyWithInvalidURNErrors(t *testing.T) {
 	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
 		config.Args = append(config.Args, "my-tag")
@@ -0,0 +1,249 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// teamAccountService returns an account service authenticated with the given token.
+// It is replaced for testing.
+var teamAccountService = func(c *CmdConfig, token string) (do.AccountService, error) {
+	godoClient, err := c.Doit.GetGodoClient(Trace, true, token)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to initialize DigitalOcean API client: %w", err)
+	}
+	return do.NewAccountService(godoClient), nil
+}
+
+// Teams creates the teams command.
+func Teams() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "teams",
+			Short: "Display commands for working with your teams",
+			Long: `The subcommands of ` + "`" + `doctl teams` + "`" + ` retrieve information about the DigitalOcean teams you can access and manage their members.
+
+API tokens are scoped to a single team, so doctl sees a team through each authentication context configured with ` + "`" + `doctl auth init` + "`" + `. The ` + "`" + `members` + "`" + ` subcommands act on the team of the current context; use ` + "`" + `--context` + "`" + ` to pick another one.`,
+			GroupID: manageResourcesGroup,
+		},
+	}
+
+	cmdTeamsList := cmdBuilderWithInit(cmd, RunTeamsList, "list", "List the teams of your authentication contexts", `Lists the team each of your authentication contexts belongs to, along with the context's user email and whether it is the current context.
+
+Contexts whose token is no longer valid are reported as a warning and skipped.`, Writer, false,
+		aliasOpt("ls"), displayerType(&displayers.Teams{}))
+	cmdTeamsList.Example = `The following example lists the team names of all authentication contexts: doctl teams list --format Context,Team`
+
+	cmd.AddCommand(teamMembers())
+
+	return cmd
+}
+
+// teamMembers creates the teams members commands.
+func teamMembers() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "members",
+			Short: "Display commands for managing the members of a team",
+			Long: `The subcommands of ` + "`" + `doctl teams members` + "`" + ` list, invite, and remove the members of the current context's team and change their roles, so that onboarding and offboarding can be scripted.
+
+Members can be given by UUID or by email address. The token used must belong to a team owner.`,
+		},
+	}
+
+	cmdMembersList := CmdBuilder(cmd, RunTeamMembersList, "list", "List the members of the team", `Lists the members of the team, including invitations that have not been accepted yet, with each member's UUID, email address, name, role, and status.`, Writer,
+		aliasOpt("ls"), displayerType(&displayers.TeamMembers{}))
+	cmdMembersList.Example = `The following example lists the email addresses and roles of the team's members: doctl teams members list --format Email,Role`
+
+	cmdMembersInvite := CmdBuilder(cmd, RunTeamMembersInvite, "invite <email>...", "Invite people to the team", `Sends an invitation to join the team to each of the given email addresses. Invited people show up in `+"`"+`doctl teams members list`+"`"+` with the `+"`"+`pending`+"`"+` status until they accept.`, Writer,
+		displayerType(&displayers.TeamMembers{}))
+	AddStringFlag(cmdMembersInvite, doctl.ArgTeamMemberRole, "", "member", "The role to give the invited people, for example `member`, `biller`, or `owner`")
+	cmdMembersInvite.Example = `The following example invites two people as billers: doctl teams members invite alice@example.com bob@example.com --role biller`
+
+	cmdMembersRemove := CmdBuilder(cmd, RunTeamMembersRemove, "remove <member>...", "Remove members from the team", `Removes the given members from the team, or withdraws their invitations if they have not joined yet. Members can be given by UUID or email address.`, Writer,
+		aliasOpt("rm"))
+	AddBoolFlag(cmdMembersRemove, doctl.ArgForce, doctl.ArgShortForce, false, "Remove the members without a confirmation prompt")
+	cmdMembersRemove.Example = `The following example removes a member without prompting for confirmation: doctl teams members remove alice@example.com --force`
+
+	cmdMembersSetRole := CmdBuilder(cmd, RunTeamMembersSetRole, "set-role <member>", "Change the role of a team member", `Changes the role of the given member, who can be given by UUID or email address.`, Writer,
+		displayerType(&displayers.TeamMembers{}))
+	AddStringFlag(cmdMembersSetRole, doctl.ArgTeamMemberRole, "", "", "The new role, for example `member`, `biller`, or `owner`", requiredOpt())
+	cmdMembersSetRole.Example = `The following example makes a member an owner of the team: doctl teams members set-role alice@example.com --role owner`
+
+	return cmd
+}
+
+// RunTeamsList lists the teams reachable through the configured auth contexts.
+func RunTeamsList(c *CmdConfig) error {
+	current := Context
+	if current == "" {
+		current = viper.GetString("context")
+	}
+	if current == "" {
+		current = doctl.ArgDefaultContext
+	}
+
+	tokens := viper.GetStringMapString("auth-contexts")
+	if token := viper.GetString(doctl.ArgAccessToken); token != "" {
+		tokens[doctl.ArgDefaultContext] = token
+	}
+
+	names := make([]string, 0, len(tokens))
+	for name := range tokens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var teams []displayers.TeamContext
+	for _, name := range names {
+		if tokens[name] == "" {
+			continue
+		}
+
+		as, err := teamAccountService(c, tokens[name])
+		if err != nil {
+			return err
+		}
+		a, err := as.Get()
+		if err != nil {
+			warn("Skipping context %s: %v", name, err)
+			continue
+		}
+
+		teams = append(teams, displayers.TeamContext{
+			Context: name,
+			Current: name == current,
+			Account: a,
+		})
+	}
+
+	return c.Display(&displayers.Teams{Teams: teams})
+}
+
+// RunTeamMembersList lists the members of the current context's team.
+func RunTeamMembersList(c *CmdConfig) error {
+	members, err := c.Teams().ListMembers()
+	if err != nil {
+		return err
+	}
+	return c.Display(&displayers.TeamMembers{TeamMembers: members})
+}
+
+// RunTeamMembersInvite invites people to the current context's team.
+func RunTeamMembersInvite(c *CmdConfig) error {
+	if len(c.Args) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	role, err := c.Doit.GetString(c.NS, doctl.ArgTeamMemberRole)
+	if err != nil {
+		return err
+	}
+
+	ts := c.Teams()
+	invited := make([]do.TeamMember, 0, len(c.Args))
+	for _, email := range c.Args {
+		m, err := ts.InviteMember(&do.TeamInviteRequest{Email: email, Role: role})
+		if err != nil {
+			return fmt.Errorf("inviting %s: %w", email, err)
+		}
+		invited = append(invited, *m)
+	}
+
+	return c.Display(&displayers.TeamMembers{TeamMembers: invited})
+}
+
+// RunTeamMembersRemove removes members from the current context's team.
+func RunTeamMembersRemove(c *CmdConfig) error {
+	if len(c.Args) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	force, err := c.Doit.GetBool(c.NS, doctl.ArgForce)
+	if err != nil {
+		return err
+	}
+
+	ts := c.Teams()
+	members, err := findTeamMembers(ts, c.Args)
+	if err != nil {
+		return err
+	}
+
+	if !force && AskForConfirm(fmt.Sprintf("remove %d team member(s) (%s)", len(c.Args), strings.Join(c.Args, ", "))) != nil {
+		return errOperationAborted
+	}
+
+	for i, m := range members {
+		if err := ts.RemoveMember(m.UUID); err != nil {
+			return fmt.Errorf("removing %s: %w", c.Args[i], err)
+		}
+	}
+	return nil
+}
+
+// RunTeamMembersSetRole changes the role of a member of the current context's team.
+func RunTeamMembersSetRole(c *CmdConfig) error {
+	err := ensureOneArg(c)
+	if err != nil {
+		return err
+	}
+	role, err := c.Doit.GetString(c.NS, doctl.ArgTeamMemberRole)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return doctl.NewMissingArgsErr(fmt.Sprintf("%s.%s", c.NS, doctl.ArgTeamMemberRole))
+	}
+
+	ts := c.Teams()
+	members, err := findTeamMembers(ts, c.Args)
+	if err != nil {
+		return err
+	}
+
+	m, err := ts.SetMemberRole(members[0].UUID, role)
+	if err != nil {
+		return err
+	}
+	return c.Display(&displayers.TeamMembers{TeamMembers: []do.TeamMember{*m}})
+}
+
+// findTeamMembers looks up team members by UUID or email address, in the order given.
+func findTeamMembers(ts do.TeamsService, ids []string) ([]do.TeamMember, error) {
+	all, err := ts.ListMembers()
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]do.TeamMember, 0, len(ids))
+	for _, id := range ids {
+		i := slices.IndexFunc(all, func(m do.TeamMember) bool {
+			return m.UUID == id || strings.EqualFold(m.Email, id)
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("%s is not a member of the team", id)
+		}
+		found = append(found, all[i])
+	}
+	return found, nil
+}
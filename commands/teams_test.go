@@ -0,0 +1,163 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/doctl/do/mocks"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTeamsCommand(t *testing.T) {
+	cmd := Teams()
+	assert.NotNil(t, cmd)
+	assertCommandNames(t, cmd, "list", "members")
+}
+
+var testTeamMembers = []do.TeamMember{
+	{UUID: "member-1", Email: "alice@example.com", Name: "Alice", Role: "owner", Status: "active"},
+	{UUID: "member-2", Email: "bob@example.com", Name: "Bob", Role: "member", Status: "active"},
+}
+
+func TestTeamMembersCommand(t *testing.T) {
+	cmd := teamMembers()
+	assert.NotNil(t, cmd)
+	assertCommandNames(t, cmd, "list", "invite", "remove", "set-role")
+}
+
+func TestTeamMembersList(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.teams.EXPECT().ListMembers().Return(testTeamMembers, nil)
+
+		err := RunTeamMembersList(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestTeamMembersInvite(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.teams.EXPECT().InviteMember(&do.TeamInviteRequest{Email: "carol@example.com", Role: "biller"}).
+			Return(&do.TeamMember{UUID: "member-3", Email: "carol@example.com", Role: "biller", Status: "pending"}, nil)
+		tm.teams.EXPECT().InviteMember(&do.TeamInviteRequest{Email: "dave@example.com", Role: "biller"}).
+			Return(&do.TeamMember{UUID: "member-4", Email: "dave@example.com", Role: "biller", Status: "pending"}, nil)
+
+		config.Args = append(config.Args, "carol@example.com", "dave@example.com")
+		config.Doit.Set(config.NS, doctl.ArgTeamMemberRole, "biller")
+
+		err := RunTeamMembersInvite(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestTeamMembersRemove(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.teams.EXPECT().ListMembers().Return(testTeamMembers, nil)
+		tm.teams.EXPECT().RemoveMember("member-2").Return(nil)
+		tm.teams.EXPECT().RemoveMember("member-1").Return(nil)
+
+		config.Args = append(config.Args, "Bob@example.com", "member-1")
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+
+		err := RunTeamMembersRemove(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestTeamMembersRemoveUnknown(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.teams.EXPECT().ListMembers().Return(testTeamMembers, nil)
+
+		config.Args = append(config.Args, "member-1", "mallory@example.com")
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+
+		err := RunTeamMembersRemove(config)
+		assert.EqualError(t, err, "mallory@example.com is not a member of the team")
+	})
+}
+
+func TestTeamMembersSetRole(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.teams.EXPECT().ListMembers().Return(testTeamMembers, nil)
+		tm.teams.EXPECT().SetMemberRole("member-2", "owner").
+			Return(&do.TeamMember{UUID: "member-2", Email: "bob@example.com", Role: "owner", Status: "active"}, nil)
+
+		config.Args = append(config.Args, "bob@example.com")
+		config.Doit.Set(config.NS, doctl.ArgTeamMemberRole, "owner")
+
+		err := RunTeamMembersSetRole(config)
+		assert.NoError(t, err)
+	})
+}
+
+func TestTeamMembersSetRoleMissingRole(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = append(config.Args, "bob@example.com")
+
+		err := RunTeamMembersSetRole(config)
+		assert.Error(t, err)
+	})
+}
+
+func TestTeamsList(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		ctrl := gomock.NewController(t)
+		accounts := map[string]*mocks.MockAccountService{
+			"default-token": mocks.NewMockAccountService(ctrl),
+			"work-token":    mocks.NewMockAccountService(ctrl),
+			"stale-token":   mocks.NewMockAccountService(ctrl),
+		}
+		accounts["default-token"].EXPECT().Get().Return(&do.Account{Account: &godo.Account{
+			Email: "me@example.com", Team: &godo.TeamInfo{Name: "My Team", UUID: "team-1"},
+		}}, nil)
+		accounts["work-token"].EXPECT().Get().Return(&do.Account{Account: &godo.Account{
+			Email: "me@example.com", Team: &godo.TeamInfo{Name: "Work", UUID: "team-2"},
+		}}, nil)
+		accounts["stale-token"].EXPECT().Get().Return(nil, errors.New("unauthorized"))
+
+		origService := teamAccountService
+		teamAccountService = func(c *CmdConfig, token string) (do.AccountService, error) {
+			return accounts[token], nil
+		}
+		defer func() { teamAccountService = origService }()
+
+		viper.Set(doctl.ArgAccessToken, "default-token")
+		viper.Set("auth-contexts", map[string]string{"work": "work-token", "old": "stale-token"})
+		viper.Set("context", "work")
+		defer func() {
+			viper.Set(doctl.ArgAccessToken, "")
+			viper.Set("auth-contexts", map[string]string{})
+			viper.Set("context", "")
+		}()
+
+		var buf bytes.Buffer
+		config.Out = &buf
+
+		err := RunTeamsList(config)
+		require.NoError(t, err)
+
+		out := buf.String()
+		assert.Contains(t, out, "My Team")
+		assert.Contains(t, out, "Work")
+		assert.NotContains(t, out, "old")
+	})
+}
@@ -0,0 +1,634 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/digitalocean/doctl/commands/charm"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+)
+
+// dashboardLogTail is the number of log lines shown for an app.
+const dashboardLogTail = 100
+
+// dashboardLogClient downloads app logs for the dashboard.
+var dashboardLogClient = &http.Client{Timeout: 30 * time.Second}
+
+var (
+	dashboardTabStyle       = lipgloss.NewStyle().Padding(0, 1).Foreground(charm.Colors.Muted)
+	dashboardActiveTabStyle = lipgloss.NewStyle().Padding(0, 1).Bold(true).Foreground(charm.Colors.Highlight)
+	dashboardHeaderStyle    = lipgloss.NewStyle().Bold(true)
+	dashboardSelectedStyle  = lipgloss.NewStyle().Reverse(true)
+	dashboardMutedStyle     = lipgloss.NewStyle().Foreground(charm.Colors.Muted)
+	dashboardErrorStyle     = lipgloss.NewStyle().Foreground(charm.Colors.Error)
+	dashboardPromptStyle    = lipgloss.NewStyle().Bold(true).Foreground(charm.Colors.Warning)
+)
+
+// TUI creates the tui command.
+func TUI() *Command {
+	cmd := CmdBuilder(nil, RunTUI, "tui", "Browse your resources in an interactive dashboard",
+		`Use this command to open an interactive terminal dashboard of your Droplets, apps, database clusters, and domains.
+
+Switch between resource types with `+"`"+`tab`+"`"+` and `+"`"+`shift+tab`+"`"+` or the number keys, move with the arrow keys or `+"`"+`j`+"`"+` and `+"`"+`k`+"`"+`, and press `+"`"+`?`+"`"+` to list the actions available for the selected resource:
+
+- `+"`"+`r`+"`"+` reboots a Droplet
+- `+"`"+`l`+"`"+` shows the latest run logs of an app
+- `+"`"+`d`+"`"+` deletes the selected resource, after asking for confirmation. As with their delete commands, database clusters and domains are only deleted once their name is typed
+
+Press `+"`"+`ctrl+r`+"`"+` to refresh the list and `+"`"+`q`+"`"+` to quit.`, Writer)
+	cmd.GroupID = manageResourcesGroup
+
+	return cmd
+}
+
+// RunTUI runs the interactive dashboard.
+func RunTUI(c *CmdConfig) error {
+	if !Interactive {
+		return errors.New("doctl tui requires an interactive terminal")
+	}
+	m := newDashboard(dashboardTabs(c))
+	defer m.stop()
+	return tea.NewProgram(m, tea.WithAltScreen()).Start()
+}
+
+// dashboardRow is a resource listed by the dashboard.
+type dashboardRow struct {
+	id    string
+	name  string
+	cells []string
+}
+
+// dashboardAction is an action on the selected resource, triggered by key.
+type dashboardAction struct {
+	key  string
+	help string
+	// confirm, if set, is the prompt shown before the action runs. It is
+	// formatted with the resource's name.
+	confirm string
+	// typeToConfirm, if set, is the type of resource whose name must be typed
+	// to confirm the action, as its delete command requires, instead of
+	// answering confirm.
+	typeToConfirm string
+	// run performs the action. Output, if any, is shown in a scrollable view;
+	// otherwise the list is refreshed. ctx is canceled when the dashboard quits.
+	run func(ctx context.Context, row dashboardRow) (output string, err error)
+}
+
+// dashboardTab is a list of resources of one type.
+type dashboardTab struct {
+	name    string
+	columns []string
+	fetch   func() ([]dashboardRow, error)
+	actions []dashboardAction
+
+	rows    []dashboardRow
+	cursor  int
+	loading bool
+	err     error
+}
+
+func (t *dashboardTab) selected() (dashboardRow, bool) {
+	if t.cursor < 0 || t.cursor >= len(t.rows) {
+		return dashboardRow{}, false
+	}
+	return t.rows[t.cursor], true
+}
+
+func (t *dashboardTab) action(key string) (dashboardAction, bool) {
+	for _, a := range t.actions {
+		if a.key == key {
+			return a, true
+		}
+	}
+	return dashboardAction{}, false
+}
+
+// dashboardTabs returns the dashboard's tabs, backed by c's services.
+func dashboardTabs(c *CmdConfig) []*dashboardTab {
+	return []*dashboardTab{
+		{
+			name:    "Droplets",
+			columns: []string{"ID", "Name", "Status", "Region", "Public IPv4"},
+			fetch: func() ([]dashboardRow, error) {
+				droplets, err := c.Droplets().List()
+				if err != nil {
+					return nil, err
+				}
+				rows := make([]dashboardRow, len(droplets))
+				for i, d := range droplets {
+					ip, _ := d.PublicIPv4()
+					region := ""
+					if d.Region != nil {
+						region = d.Region.Slug
+					}
+					id := strconv.Itoa(d.ID)
+					rows[i] = dashboardRow{id: id, name: d.Name, cells: []string{id, d.Name, d.Status, region, ip}}
+				}
+				return rows, nil
+			},
+			actions: []dashboardAction{
+				{key: "r", help: "reboot", confirm: "Reboot Droplet %s?", run: func(_ context.Context, row dashboardRow) (string, error) {
+					id, err := strconv.Atoi(row.id)
+					if err != nil {
+						return "", err
+					}
+					_, err = c.DropletActions().Reboot(id)
+					return "", err
+				}},
+				{key: "d", help: "delete", confirm: "Delete Droplet %s?", run: func(_ context.Context, row dashboardRow) (string, error) {
+					id, err := strconv.Atoi(row.id)
+					if err != nil {
+						return "", err
+					}
+					return "", c.Droplets().Delete(id)
+				}},
+			},
+		},
+		{
+			name:    "Apps",
+			columns: []string{"ID", "Name", "Default Ingress", "Updated At"},
+			fetch: func() ([]dashboardRow, error) {
+				apps, err := c.Apps().List(false)
+				if err != nil {
+					return nil, err
+				}
+				rows := make([]dashboardRow, len(apps))
+				for i, a := range apps {
+					name := ""
+					if a.Spec != nil {
+						name = a.Spec.Name
+					}
+					rows[i] = dashboardRow{id: a.ID, name: name, cells: []string{a.ID, name, a.DefaultIngress, a.UpdatedAt.Format("2006-01-02 15:04:05")}}
+				}
+				return rows, nil
+			},
+			actions: []dashboardAction{
+				{key: "l", help: "logs", run: func(ctx context.Context, row dashboardRow) (string, error) {
+					return appRunLogs(ctx, c.Apps(), row.id, dashboardLogTail)
+				}},
+				{key: "d", help: "delete", confirm: "Delete app %s?", run: func(_ context.Context, row dashboardRow) (string, error) {
+					return "", c.Apps().Delete(row.id)
+				}},
+			},
+		},
+		{
+			name:    "Databases",
+			columns: []string{"ID", "Name", "Engine", "Status", "Region", "Nodes"},
+			fetch: func() ([]dashboardRow, error) {
+				dbs, err := c.Databases().List()
+				if err != nil {
+					return nil, err
+				}
+				rows := make([]dashboardRow, len(dbs))
+				for i, db := range dbs {
+					rows[i] = dashboardRow{id: db.ID, name: db.Name, cells: []string{db.ID, db.Name, db.EngineSlug, db.Status, db.RegionSlug, strconv.Itoa(db.NumNodes)}}
+				}
+				return rows, nil
+			},
+			actions: []dashboardAction{
+				{key: "d", help: "delete", typeToConfirm: "database cluster", run: func(_ context.Context, row dashboardRow) (string, error) {
+					return "", c.Databases().Delete(row.id)
+				}},
+			},
+		},
+		{
+			name:    "Domains",
+			columns: []string{"Domain", "TTL"},
+			fetch: func() ([]dashboardRow, error) {
+				domains, err := c.Domains().List()
+				if err != nil {
+					return nil, err
+				}
+				rows := make([]dashboardRow, len(domains))
+				for i, d := range domains {
+					rows[i] = dashboardRow{id: d.Name, name: d.Name, cells: []string{d.Name, strconv.Itoa(d.TTL)}}
+				}
+				return rows, nil
+			},
+			actions: []dashboardAction{
+				{key: "d", help: "delete", typeToConfirm: "domain", run: func(_ context.Context, row dashboardRow) (string, error) {
+					return "", c.Domains().Delete(row.id)
+				}},
+			},
+		},
+	}
+}
+
+// appRunLogs returns the latest run logs of an app's current deployment.
+func appRunLogs(ctx context.Context, apps do.AppsService, appID string, tail int) (string, error) {
+	app, err := apps.Get(appID)
+	if err != nil {
+		return "", err
+	}
+	var deploymentID string
+	switch {
+	case app.ActiveDeployment != nil:
+		deploymentID = app.ActiveDeployment.ID
+	case app.InProgressDeployment != nil:
+		deploymentID = app.InProgressDeployment.ID
+	default:
+		return "", fmt.Errorf("unable to retrieve logs; no deployment found for app %s", appID)
+	}
+
+	logs, err := apps.GetLogs(appID, deploymentID, "", godo.AppLogTypeRun, false, tail)
+	if err != nil {
+		return "", err
+	}
+	if len(logs.HistoricURLs) == 0 {
+		return "No logs found for app", nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, logs.HistoricURLs[0], nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := dashboardLogClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+type dashboardRowsMsg struct {
+	tab  int
+	rows []dashboardRow
+	err  error
+}
+
+type dashboardActionMsg struct {
+	tab    int
+	title  string
+	output string
+	err    error
+}
+
+// dashboardPending is an action waiting for confirmation.
+type dashboardPending struct {
+	action dashboardAction
+	row    dashboardRow
+	// typed is what has been typed so far for an action with typeToConfirm.
+	typed string
+}
+
+// dashboardModel implements the bubbletea.Model interface.
+type dashboardModel struct {
+	tabs   []*dashboardTab
+	active int
+
+	width, height int
+
+	pending  *dashboardPending
+	status   string
+	showHelp bool
+
+	// logs is shown instead of the list while it is set.
+	logs      *viewport.Model
+	logsTitle string
+
+	// ctx is passed to actions, and stop cancels it when the dashboard quits.
+	ctx  context.Context
+	stop context.CancelFunc
+}
+
+func newDashboard(tabs []*dashboardTab) *dashboardModel {
+	ctx, stop := context.WithCancel(context.Background())
+	return &dashboardModel{tabs: tabs, ctx: ctx, stop: stop}
+}
+
+// quit cancels running actions and ends the dashboard.
+func (m *dashboardModel) quit() (tea.Model, tea.Cmd) {
+	m.stop()
+	return m, tea.Quit
+}
+
+func (m *dashboardModel) tab() *dashboardTab {
+	return m.tabs[m.active]
+}
+
+// load fetches the rows of a tab.
+func (m *dashboardModel) load(i int) tea.Cmd {
+	t := m.tabs[i]
+	t.loading = true
+	return func() tea.Msg {
+		rows, err := t.fetch()
+		return dashboardRowsMsg{tab: i, rows: rows, err: err}
+	}
+}
+
+func (m *dashboardModel) runAction(a dashboardAction, row dashboardRow) tea.Cmd {
+	m.status = fmt.Sprintf("Running %s on %s...", a.help, row.name)
+	tab, ctx := m.active, m.ctx
+	return func() tea.Msg {
+		output, err := a.run(ctx, row)
+		return dashboardActionMsg{tab: tab, title: fmt.Sprintf("%s: %s", a.help, row.name), output: output, err: err}
+	}
+}
+
+// Init implements bubbletea.Model.
+func (m *dashboardModel) Init() tea.Cmd {
+	return m.load(m.active)
+}
+
+// Update implements bubbletea.Model.
+func (m *dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		if m.logs != nil {
+			m.logs.Width, m.logs.Height = m.width, m.bodyHeight()
+		}
+		return m, nil
+
+	case dashboardRowsMsg:
+		t := m.tabs[msg.tab]
+		t.loading = false
+		t.err = msg.err
+		if msg.err == nil {
+			t.rows = msg.rows
+			if t.cursor >= len(t.rows) {
+				t.cursor = len(t.rows) - 1
+			}
+			if t.cursor < 0 {
+				t.cursor = 0
+			}
+		}
+		return m, nil
+
+	case dashboardActionMsg:
+		if msg.err != nil {
+			m.status = dashboardErrorStyle.Render(fmt.Sprintf("%s failed: %v", msg.title, msg.err))
+			return m, nil
+		}
+		if msg.output != "" {
+			vp := viewport.New(m.width, m.bodyHeight())
+			vp.SetContent(msg.output)
+			vp.GotoBottom()
+			m.logs, m.logsTitle = &vp, msg.title
+			m.status = ""
+			return m, nil
+		}
+		m.status = fmt.Sprintf("%s: done", msg.title)
+		return m, m.load(msg.tab)
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m *dashboardModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key == "ctrl+c" {
+		return m.quit()
+	}
+
+	if m.pending != nil && m.pending.action.typeToConfirm != "" {
+		return m.handleTypedConfirm(msg)
+	}
+	if m.pending != nil {
+		p := m.pending
+		m.pending = nil
+		if key == "y" || key == "Y" {
+			return m, m.runAction(p.action, p.row)
+		}
+		m.status = "Canceled"
+		return m, nil
+	}
+
+	if m.logs != nil {
+		switch key {
+		case "esc", "q":
+			m.logs = nil
+			return m, nil
+		}
+		vp, cmd := m.logs.Update(msg)
+		m.logs = &vp
+		return m, cmd
+	}
+
+	t := m.tab()
+	switch key {
+	case "q", "esc":
+		return m.quit()
+	case "?":
+		m.showHelp = !m.showHelp
+	case "tab", "shift+tab":
+		delta := 1
+		if key == "shift+tab" {
+			delta = len(m.tabs) - 1
+		}
+		return m, m.switchTab((m.active + delta) % len(m.tabs))
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		if i := int(key[0] - '1'); i < len(m.tabs) {
+			return m, m.switchTab(i)
+		}
+	case "up", "k":
+		if t.cursor > 0 {
+			t.cursor--
+		}
+	case "down", "j":
+		if t.cursor < len(t.rows)-1 {
+			t.cursor++
+		}
+	case "home", "g":
+		t.cursor = 0
+	case "end", "G":
+		if len(t.rows) > 0 {
+			t.cursor = len(t.rows) - 1
+		}
+	case "ctrl+r":
+		m.status = ""
+		return m, m.load(m.active)
+	default:
+		a, ok := t.action(key)
+		if !ok {
+			return m, nil
+		}
+		row, ok := t.selected()
+		if !ok {
+			return m, nil
+		}
+		if a.confirm != "" || a.typeToConfirm != "" {
+			m.pending = &dashboardPending{action: a, row: row}
+			return m, nil
+		}
+		return m, m.runAction(a, row)
+	}
+	return m, nil
+}
+
+// handleTypedConfirm collects the name typed to confirm the pending action,
+// which runs on enter if the name matches.
+func (m *dashboardModel) handleTypedConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.pending
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.pending = nil
+		m.status = "Canceled"
+	case tea.KeyEnter:
+		m.pending = nil
+		if err := checkTypedConfirm(p.typed, p.action.typeToConfirm, p.row.name); err != nil {
+			m.status = dashboardErrorStyle.Render(fmt.Sprintf("Canceled: %v", err))
+			return m, nil
+		}
+		return m, m.runAction(p.action, p.row)
+	case tea.KeyBackspace:
+		if r := []rune(p.typed); len(r) > 0 {
+			p.typed = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		p.typed += string(msg.Runes)
+	}
+	return m, nil
+}
+
+func (m *dashboardModel) switchTab(i int) tea.Cmd {
+	m.active = i
+	m.status = ""
+	if t := m.tab(); t.rows == nil && t.err == nil && !t.loading {
+		return m.load(i)
+	}
+	return nil
+}
+
+// bodyHeight is the number of lines available between the tabs and the footer.
+func (m *dashboardModel) bodyHeight() int {
+	h := m.height - 4
+	if h < 1 {
+		h = 1
+	}
+	return h
+}
+
+// View implements bubbletea.Model.
+func (m *dashboardModel) View() string {
+	var b strings.Builder
+
+	tabs := make([]string, len(m.tabs))
+	for i, t := range m.tabs {
+		label := fmt.Sprintf("%d %s", i+1, t.name)
+		if i == m.active {
+			tabs[i] = dashboardActiveTabStyle.Render(label)
+		} else {
+			tabs[i] = dashboardTabStyle.Render(label)
+		}
+	}
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, tabs...))
+	b.WriteString("\n\n")
+
+	if m.logs != nil {
+		b.WriteString(m.logs.View())
+		b.WriteString("\n")
+		b.WriteString(dashboardMutedStyle.Render(m.logsTitle + " · ↑/↓ scroll · esc back"))
+		return b.String()
+	}
+
+	b.WriteString(m.viewTable())
+	b.WriteString("\n")
+	b.WriteString(m.viewFooter())
+	return b.String()
+}
+
+func (m *dashboardModel) viewTable() string {
+	t := m.tab()
+	switch {
+	case t.err != nil:
+		return dashboardErrorStyle.Render(fmt.Sprintf("Error: %v", t.err))
+	case t.rows == nil:
+		return dashboardMutedStyle.Render("Loading...")
+	case len(t.rows) == 0:
+		return dashboardMutedStyle.Render(fmt.Sprintf("No %s found", strings.ToLower(t.name)))
+	}
+
+	widths := make([]int, len(t.columns))
+	for i, col := range t.columns {
+		widths[i] = len(col)
+	}
+	for _, row := range t.rows {
+		for i, cell := range row.cells {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	format := func(cells []string) string {
+		padded := make([]string, len(cells))
+		for i, cell := range cells {
+			padded[i] = cell + strings.Repeat(" ", widths[i]-len(cell))
+		}
+		line := strings.Join(padded, "  ")
+		if m.width > 0 && len(line) > m.width {
+			line = line[:m.width]
+		}
+		return line
+	}
+
+	// Show the window of rows that contains the cursor.
+	visible := m.bodyHeight() - 1
+	start := 0
+	if t.cursor >= visible {
+		start = t.cursor - visible + 1
+	}
+	end := start + visible
+	if end > len(t.rows) {
+		end = len(t.rows)
+	}
+
+	lines := []string{dashboardHeaderStyle.Render(format(t.columns))}
+	for i := start; i < end; i++ {
+		line := format(t.rows[i].cells)
+		if i == t.cursor {
+			line = dashboardSelectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *dashboardModel) viewFooter() string {
+	if p := m.pending; p != nil && p.action.typeToConfirm != "" {
+		return dashboardPromptStyle.Render(typedConfirmPrompt(p.action.typeToConfirm, p.row.name)) + p.typed
+	}
+	if m.pending != nil {
+		return dashboardPromptStyle.Render(fmt.Sprintf(m.pending.action.confirm, m.pending.row.name) + " (y/N)")
+	}
+
+	help := []string{"tab switch", "↑/↓ move", "ctrl+r refresh", "? help", "q quit"}
+	if m.showHelp {
+		for _, a := range m.tab().actions {
+			help = append(help, a.key+" "+a.help)
+		}
+	}
+	footer := dashboardMutedStyle.Render(strings.Join(help, " · "))
+	if m.status != "" {
+		footer = m.status + "\n" + footer
+	}
+	return footer
+}
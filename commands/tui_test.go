@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dashboardKey sends a key press to m and runs the commands that follow from it.
+func dashboardKey(t *testing.T, m *dashboardModel, key string) {
+	t.Helper()
+	var msg tea.KeyMsg
+	switch key {
+	case "tab":
+		msg = tea.KeyMsg{Type: tea.KeyTab}
+	case "esc":
+		msg = tea.KeyMsg{Type: tea.KeyEsc}
+	case "ctrl+r":
+		msg = tea.KeyMsg{Type: tea.KeyCtrlR}
+	case "enter":
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	case "backspace":
+		msg = tea.KeyMsg{Type: tea.KeyBackspace}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+	_, cmd := m.Update(msg)
+	dashboardRun(m, cmd)
+}
+
+// dashboardRun runs cmd and any commands that follow from it.
+func dashboardRun(m *dashboardModel, cmd tea.Cmd) {
+	for cmd != nil {
+		_, cmd = m.Update(cmd())
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.droplets.EXPECT().List().Return(testDropletList, nil).Times(2)
+
+		m := newDashboard(dashboardTabs(config))
+		m.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+		dashboardRun(m, m.Init())
+
+		view := m.View()
+		assert.Contains(t, view, "1 Droplets")
+		assert.Contains(t, view, "a-droplet")
+		assert.Contains(t, view, "another-droplet")
+		assert.Contains(t, view, "8.8.8.9")
+
+		t.Run("move", func(t *testing.T) {
+			dashboardKey(t, m, "j")
+			row, ok := m.tab().selected()
+			require.True(t, ok)
+			assert.Equal(t, "3", row.id)
+
+			dashboardKey(t, m, "j")
+			row, _ = m.tab().selected()
+			assert.Equal(t, "3", row.id)
+
+			dashboardKey(t, m, "k")
+			row, _ = m.tab().selected()
+			assert.Equal(t, "1", row.id)
+		})
+
+		t.Run("cancel delete", func(t *testing.T) {
+			dashboardKey(t, m, "d")
+			assert.Contains(t, m.View(), "Delete Droplet a-droplet? (y/N)")
+			dashboardKey(t, m, "n")
+			assert.Nil(t, m.pending)
+			assert.Contains(t, m.View(), "Canceled")
+		})
+
+		t.Run("reboot", func(t *testing.T) {
+			tm.dropletActions.EXPECT().Reboot(1).Return(&do.Action{Action: &godo.Action{ID: 1}}, nil)
+
+			dashboardKey(t, m, "r")
+			assert.Contains(t, m.View(), "Reboot Droplet a-droplet? (y/N)")
+			dashboardKey(t, m, "y")
+			assert.Contains(t, m.View(), "reboot: a-droplet: done")
+		})
+	})
+}
+
+func TestDashboardTabs(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.droplets.EXPECT().List().Return(do.Droplets{}, nil)
+		tm.apps.EXPECT().List(false).Return([]*godo.App{{ID: "app-id", Spec: &godo.AppSpec{Name: "web"}}}, nil)
+		tm.databases.EXPECT().List().Return(nil, errors.New("boom"))
+		tm.domains.EXPECT().List().Return(do.Domains{{Domain: &godo.Domain{Name: "example.com", TTL: 1800}}}, nil)
+		tm.domains.EXPECT().Delete("example.com").Return(nil)
+		tm.domains.EXPECT().List().Return(do.Domains{}, nil)
+
+		m := newDashboard(dashboardTabs(config))
+		m.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+		dashboardRun(m, m.Init())
+		assert.Contains(t, m.View(), "No droplets found")
+
+		dashboardKey(t, m, "tab")
+		assert.Contains(t, m.View(), "web")
+
+		dashboardKey(t, m, "3")
+		assert.Contains(t, m.View(), "Error: boom")
+
+		dashboardKey(t, m, "4")
+		assert.Contains(t, m.View(), "example.com")
+
+		// Actions of other tabs aren't available.
+		dashboardKey(t, m, "r")
+		assert.Nil(t, m.pending)
+
+		// Domains, like their delete command, need their name typed.
+		dashboardKey(t, m, "d")
+		assert.Contains(t, m.View(), "Type the name of the domain, example.com, to delete it:")
+		dashboardKey(t, m, "y")
+		dashboardKey(t, m, "enter")
+		assert.Nil(t, m.pending)
+		assert.Contains(t, m.View(), `"y" does not match the name of the domain`)
+
+		dashboardKey(t, m, "d")
+		dashboardKey(t, m, "example.comm")
+		dashboardKey(t, m, "backspace")
+		assert.Equal(t, "example.com", m.pending.typed)
+		dashboardKey(t, m, "enter")
+		assert.Contains(t, m.View(), "No domains found")
+	})
+}
+
+func TestDashboardLogs(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		app := &godo.App{ID: "app-id", Spec: &godo.AppSpec{Name: "web"}}
+		tm.apps.EXPECT().List(false).Return([]*godo.App{app}, nil)
+		tm.apps.EXPECT().Get("app-id").Return(app, nil)
+
+		m := newDashboard(dashboardTabs(config))
+		m.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+		m.active = 1
+		dashboardRun(m, m.Init())
+
+		dashboardKey(t, m, "l")
+		assert.Nil(t, m.logs)
+		assert.Contains(t, m.View(), "logs: web failed: unable to retrieve logs; no deployment found for app app-id")
+	})
+}
@@ -0,0 +1,429 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/doctl/internal/apps"
+	"github.com/digitalocean/doctl/pkg/specschema"
+	"github.com/digitalocean/godo"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	specTypeApp      = "app"
+	specTypeFirewall = "firewall"
+	specTypeStack    = "stack"
+)
+
+// Validate creates the validate command.
+func Validate() *Command {
+	cmd := cmdBuilderWithInit(nil, RunValidate, "validate", "Validate app specs, firewall specs, and stack files",
+		`Use this command to validate one or more spec files before applying them.
+
+App specs, firewall specs (in the format accepted by the firewalls API), and stack files are supported, in either YAML or JSON. A stack file describes several resources at once:
+
+    stack: production
+    apps:
+      - <app spec>
+    firewalls:
+      - <firewall spec>
+    droplets:
+      - name: web-1
+        region: nyc3
+        size: s-1vcpu-1gb
+        image: ubuntu-22-04-x64
+
+The spec type is detected from the file's contents unless `+"`"+`--type`+"`"+` is set.
+
+Specs are checked against JSON Schemas embedded in doctl, and the regions, sizes, and images they name are checked against catalogs of the API cached by earlier runs. With `+"`"+`--offline`+"`"+`, no access token or network access is needed, which makes the command suitable for gating changes in CI; copy the catalogs into the job and pass them with `+"`"+`--catalogs`+"`"+` to check slugs too. Without `+"`"+`--offline`+"`"+`, the catalogs are refreshed from the API first, and app specs are additionally validated by the App Platform API.`,
+		Writer, false, displayerType(&displayers.SpecValidations{}))
+	cmd.GroupID = configureDoctlGroup
+
+	AddStringSliceFlag(cmd, doctl.ArgValidateFile, "f", []string{}, `Path to a spec file to validate. Repeat the flag to validate several files. Set to "-" to read from stdin.`, requiredOpt())
+	AddBoolFlag(cmd, doctl.ArgValidateOffline, "", false, "Validate using only the embedded schemas and cached catalogs, without contacting the API")
+	AddStringFlag(cmd, doctl.ArgValidateType, "", "", "The type of spec being validated. Possible values: `app`, `firewall`, or `stack`. Detected automatically if omitted")
+	AddStringFlag(cmd, doctl.ArgValidateCatalogs, "", "", "Path of the cached catalogs of regions, sizes, and images. Defaults to `catalogs.json` in doctl's cache directory")
+	cmd.Example = `The following example validates an app spec and a firewall spec without API access: doctl validate -f app.yaml -f firewall.yaml --offline`
+
+	return cmd
+}
+
+// RunValidate validates each of the given spec files and reports the results.
+func RunValidate(c *CmdConfig) error {
+	files, err := c.Doit.GetStringSlice(c.NS, doctl.ArgValidateFile)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+
+	offline, err := c.Doit.GetBool(c.NS, doctl.ArgValidateOffline)
+	if err != nil {
+		return err
+	}
+
+	specType, err := c.Doit.GetString(c.NS, doctl.ArgValidateType)
+	if err != nil {
+		return err
+	}
+	switch specType {
+	case "", specTypeApp, specTypeFirewall, specTypeStack:
+	default:
+		return fmt.Errorf("invalid spec type %q, must be one of: %s, %s, %s", specType, specTypeApp, specTypeFirewall, specTypeStack)
+	}
+
+	catalogsPath, err := c.Doit.GetString(c.NS, doctl.ArgValidateCatalogs)
+	if err != nil {
+		return err
+	}
+	if catalogsPath == "" {
+		catalogsPath = defaultSpecCatalogsPath()
+	}
+
+	var catalogs *specCatalogs
+	if offline {
+		catalogs, err = loadSpecCatalogs(catalogsPath)
+		if err != nil {
+			return err
+		}
+		if catalogs == nil {
+			notice("No cached catalogs were found at %s, so regions, sizes, and images aren't checked. Run doctl validate without --offline to cache them.", catalogsPath)
+		}
+	} else {
+		if err := c.initServices(c); err != nil {
+			return err
+		}
+		catalogs, err = fetchSpecCatalogs(c)
+		if err != nil {
+			return fmt.Errorf("fetching catalogs: %w", err)
+		}
+		if err := saveSpecCatalogs(catalogsPath, catalogs); err != nil {
+			warn("Could not cache the catalogs: %v", err)
+		}
+	}
+
+	v := &specValidator{c: c, offline: offline}
+	if catalogs != nil {
+		v.catalogs = catalogs.Catalogs
+	}
+
+	results := make([]displayers.SpecValidation, 0, len(files))
+	failed := false
+	for _, file := range files {
+		res := v.validateFile(os.Stdin, file, specType)
+		if len(res.Errors) > 0 {
+			failed = true
+		}
+		results = append(results, res)
+	}
+
+	if err := c.Display(&displayers.SpecValidations{Results: results}); err != nil {
+		return err
+	}
+
+	if failed {
+		return ErrExitSilently
+	}
+	return nil
+}
+
+// specValidator validates spec files against the embedded schemas and catalogs.
+type specValidator struct {
+	c        *CmdConfig
+	offline  bool
+	catalogs map[string][]string
+}
+
+func (v *specValidator) validateFile(stdin io.Reader, path, specType string) displayers.SpecValidation {
+	res := displayers.SpecValidation{File: path, Type: specType}
+
+	byt, err := readSpecFile(stdin, path)
+	if err != nil {
+		res.Errors = []string{err.Error()}
+		return res
+	}
+
+	doc, err := parseSpecDocument(byt)
+	if err != nil {
+		res.Errors = []string{fmt.Sprintf("parsing spec: %v", err)}
+		return res
+	}
+
+	if res.Type == "" {
+		res.Type = detectSpecType(doc)
+	}
+	res.Errors = v.validate(res.Type, doc)
+	return res
+}
+
+func (v *specValidator) validate(specType string, doc any) []string {
+	switch specType {
+	case specTypeApp:
+		return v.validateApp(doc)
+	case specTypeFirewall:
+		return v.validateFirewall(doc)
+	case specTypeStack:
+		return v.validateStack(doc)
+	}
+	return nil
+}
+
+func readSpecFile(stdin io.Reader, path string) ([]byte, error) {
+	if path == "-" && stdin != nil {
+		return io.ReadAll(stdin)
+	}
+
+	byt, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("opening spec: %s does not exist", path)
+		}
+		return nil, fmt.Errorf("opening spec: %w", err)
+	}
+	return byt, nil
+}
+
+// parseSpecDocument parses a YAML or JSON spec into the values JSON decodes to.
+func parseSpecDocument(spec []byte) (any, error) {
+	jsonSpec, err := yaml.YAMLToJSON(spec)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if err := json.Unmarshal(jsonSpec, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// detectSpecType guesses the kind of a spec from its top-level keys.
+func detectSpecType(doc any) string {
+	fields, _ := doc.(map[string]any)
+	if _, ok := fields["stack"]; ok {
+		return specTypeStack
+	}
+	for _, key := range []string{"inbound_rules", "outbound_rules"} {
+		if _, ok := fields[key]; ok {
+			return specTypeFirewall
+		}
+	}
+	return specTypeApp
+}
+
+// validateSchema checks doc against the named embedded schema.
+func (v *specValidator) validateSchema(name string, doc any) []string {
+	schema, err := specschema.Load(name)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	return schema.Validate(doc, v.catalogs)
+}
+
+func (v *specValidator) validateApp(doc any) []string {
+	errs := v.validateSchema(specschema.App, doc)
+
+	// The schema leaves out the fields App Platform doesn't constrain, so
+	// unknown fields are found by parsing the spec strictly.
+	spec, _ := json.Marshal(doc)
+	appSpec, err := apps.ParseAppSpec(spec)
+	if err != nil {
+		return append(errs, fmt.Sprintf("parsing app spec: %v", err))
+	}
+
+	seen := map[string]bool{}
+	appSpec.ForEachAppComponentSpec(func(component godo.AppComponentSpec) error {
+		name := component.GetName()
+		if name != "" && seen[name] {
+			errs = append(errs, fmt.Sprintf("component name %q is used more than once", name))
+		}
+		seen[name] = true
+		return nil
+	})
+
+	if len(errs) > 0 || v.offline {
+		return errs
+	}
+
+	if _, err := v.c.Apps().Propose(&godo.AppProposeRequest{Spec: appSpec}); err != nil {
+		return []string{err.Error()}
+	}
+	return nil
+}
+
+func (v *specValidator) validateFirewall(doc any) []string {
+	errs := v.validateSchema(specschema.Firewall, doc)
+
+	// Port ranges and ICMP rules need checks the schema can't express.
+	fields, _ := doc.(map[string]any)
+	for _, key := range []string{"inbound_rules", "outbound_rules"} {
+		rules, _ := fields[key].([]any)
+		for i, r := range rules {
+			rule, _ := r.(map[string]any)
+			protocol, _ := rule["protocol"].(string)
+			ports, _ := rule["ports"].(string)
+			if msg := checkFirewallPorts(protocol, ports); msg != "" {
+				errs = append(errs, fmt.Sprintf("%s[%d].ports: %s", key, i, msg))
+			}
+		}
+	}
+	return errs
+}
+
+// checkFirewallPorts returns what's wrong with the ports of a firewall rule, if anything.
+func checkFirewallPorts(protocol, ports string) string {
+	if protocol == "icmp" {
+		if ports != "" {
+			return "ports may not be set for icmp rules"
+		}
+		return ""
+	}
+
+	if ports == "" || ports == "all" || ports == "0" {
+		return ""
+	}
+
+	bounds := strings.SplitN(ports, "-", 2)
+	var nums []int
+	for _, b := range bounds {
+		p, err := strconv.Atoi(b)
+		if err != nil || p < 1 || p > 65535 {
+			return fmt.Sprintf("invalid port range %q", ports)
+		}
+		nums = append(nums, p)
+	}
+	if len(nums) == 2 && nums[0] > nums[1] {
+		return fmt.Sprintf("invalid port range %q", ports)
+	}
+	return ""
+}
+
+func (v *specValidator) validateStack(doc any) []string {
+	errs := v.validateSchema(specschema.Stack, doc)
+
+	fields, _ := doc.(map[string]any)
+	for _, kind := range []struct {
+		key, specType string
+	}{{"apps", specTypeApp}, {"firewalls", specTypeFirewall}} {
+		specs, _ := fields[kind.key].([]any)
+		for i, spec := range specs {
+			for _, err := range v.validate(kind.specType, spec) {
+				errs = append(errs, fmt.Sprintf("%s[%d]: %s", kind.key, i, err))
+			}
+		}
+	}
+	return errs
+}
+
+// specCatalogs are the catalogs of the API that specs are checked against,
+// cached so that specs can be checked offline.
+type specCatalogs struct {
+	UpdatedAt time.Time           `json:"updated_at"`
+	Catalogs  map[string][]string `json:"catalogs"`
+}
+
+func defaultSpecCatalogsPath() string {
+	return filepath.Join(defaultConfigHome(), "cache", "catalogs.json")
+}
+
+// loadSpecCatalogs reads the cached catalogs at path. It returns nil if there are none.
+func loadSpecCatalogs(path string) (*specCatalogs, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading catalogs: %w", err)
+	}
+	var catalogs specCatalogs
+	if err := json.Unmarshal(b, &catalogs); err != nil {
+		return nil, fmt.Errorf("reading catalogs %s: %w", path, err)
+	}
+	return &catalogs, nil
+}
+
+func saveSpecCatalogs(path string, catalogs *specCatalogs) error {
+	b, err := json.MarshalIndent(catalogs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(b, '\n'), 0644)
+}
+
+// fetchSpecCatalogs lists the regions, sizes, and images that specs may name.
+func fetchSpecCatalogs(c *CmdConfig) (*specCatalogs, error) {
+	catalogs := map[string][]string{}
+
+	regions, err := c.Regions().List()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range regions {
+		catalogs[specschema.CatalogRegions] = append(catalogs[specschema.CatalogRegions], r.Slug)
+	}
+
+	sizes, err := c.Sizes().List()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sizes {
+		catalogs[specschema.CatalogSizes] = append(catalogs[specschema.CatalogSizes], s.Slug)
+	}
+
+	for _, list := range []func(bool) (do.Images, error){c.Images().ListDistribution, c.Images().ListApplication} {
+		images, err := list(true)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range images {
+			if i.Slug != "" {
+				catalogs[specschema.CatalogImages] = append(catalogs[specschema.CatalogImages], i.Slug)
+			}
+		}
+	}
+
+	appRegions, err := c.Apps().ListRegions()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range appRegions {
+		catalogs[specschema.CatalogAppRegions] = append(catalogs[specschema.CatalogAppRegions], r.Slug)
+	}
+
+	instanceSizes, err := c.Apps().ListInstanceSizes()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range instanceSizes {
+		catalogs[specschema.CatalogAppInstanceSizes] = append(catalogs[specschema.CatalogAppInstanceSizes], s.Slug)
+	}
+
+	return &specCatalogs{UpdatedAt: time.Now().UTC(), Catalogs: catalogs}, nil
+}
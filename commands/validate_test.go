@@ -0,0 +1,241 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/doctl/pkg/specschema"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validFirewallSpec = `name: web
+inbound_rules:
+- protocol: tcp
+  ports: "443"
+  sources:
+    addresses: ["0.0.0.0/0", "::/0"]
+outbound_rules:
+- protocol: icmp
+  destinations:
+    addresses: ["0.0.0.0/0"]
+`
+
+const invalidFirewallSpec = `name: web
+inbound_rules:
+- protocol: tcp
+  ports: "90-80"
+  sources:
+    addresses: ["not-an-ip"]
+- protocol: sctp
+  sources:
+    addresses: ["10.0.0.0/8"]
+`
+
+func TestValidateCommand(t *testing.T) {
+	cmd := Validate()
+	assert.NotNil(t, cmd)
+	assert.NotNil(t, cmd.Flags().Lookup(doctl.ArgValidateOffline))
+}
+
+func writeSpecFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+const validStackFile = `stack: production
+apps:
+- name: web
+  region: nyc
+  services:
+  - name: api
+    instance_size_slug: apps-s-1vcpu-0.5gb
+firewalls:
+- name: web
+  inbound_rules:
+  - protocol: tcp
+    ports: "443"
+    sources:
+      addresses: ["0.0.0.0/0"]
+droplets:
+- name: web-1
+  region: nyc3
+  size: s-1vcpu-1gb
+  image: ubuntu-22-04-x64
+`
+
+var testSpecCatalogs = map[string][]string{
+	specschema.CatalogRegions:          {"nyc3", "sfo3"},
+	specschema.CatalogSizes:            {"s-1vcpu-1gb"},
+	specschema.CatalogImages:           {"ubuntu-22-04-x64"},
+	specschema.CatalogAppRegions:       {"nyc", "ams"},
+	specschema.CatalogAppInstanceSizes: {"apps-s-1vcpu-0.5gb"},
+}
+
+func TestRunValidateOffline(t *testing.T) {
+	tcs := []struct {
+		name     string
+		spec     string
+		catalogs map[string][]string
+		wantType string
+		wantErrs []string
+	}{
+		{
+			name:     "valid app",
+			spec:     validYAMLSpec,
+			wantType: specTypeApp,
+		},
+		{
+			name:     "duplicate component names",
+			spec:     "name: test\nservices:\n- name: web\n- name: web\n",
+			wantType: specTypeApp,
+			wantErrs: []string{`component name "web" is used more than once`},
+		},
+		{
+			name:     "invalid component name",
+			spec:     "name: test\nservices:\n- name: Web\n- instance_count: 0\n",
+			wantType: specTypeApp,
+			wantErrs: []string{
+				`services[0].name: "Web" must be 2-32 lowercase alphanumeric characters or dashes, starting with a letter`,
+				`services[1].name: is required`,
+				`services[1].instance_count: must be at least 1`,
+			},
+		},
+		{
+			name:     "unknown app field",
+			spec:     "name: test\nbogus: true\n",
+			wantType: specTypeApp,
+			wantErrs: []string{`parsing app spec: json: unknown field "bogus"`},
+		},
+		{
+			name:     "app slugs not in the catalogs",
+			spec:     "name: test\nregion: mars\nservices:\n- name: web\n  instance_size_slug: huge\n",
+			catalogs: testSpecCatalogs,
+			wantType: specTypeApp,
+			wantErrs: []string{
+				`region: "mars" is not one of the known app-regions`,
+				`services[0].instance_size_slug: "huge" is not one of the known app-instance-sizes`,
+			},
+		},
+		{
+			name:     "valid firewall",
+			spec:     validFirewallSpec,
+			wantType: specTypeFirewall,
+		},
+		{
+			name:     "invalid firewall",
+			spec:     invalidFirewallSpec,
+			wantType: specTypeFirewall,
+			wantErrs: []string{
+				`inbound_rules[0].sources.addresses[0]: "not-an-ip" is not an IP address or CIDR block`,
+				`inbound_rules[1].protocol: must be one of: tcp, udp, icmp`,
+				`inbound_rules[0].ports: invalid port range "90-80"`,
+			},
+		},
+		{
+			name:     "valid stack",
+			spec:     validStackFile,
+			catalogs: testSpecCatalogs,
+			wantType: specTypeStack,
+		},
+		{
+			name:     "invalid stack",
+			spec:     "stack: production\napps:\n- services: []\nfirewalls:\n- name: web\n  inbound_rules:\n  - protocol: icmp\n    ports: \"80\"\n    sources: {}\ndroplets:\n- name: web-1\n  region: nyc9\n  size: s-1vcpu-1gb\n  image: centos-6\n  bogus: true\n",
+			catalogs: testSpecCatalogs,
+			wantType: specTypeStack,
+			wantErrs: []string{
+				`droplets[0].bogus: is not a known field`,
+				`droplets[0].image: "centos-6" is not one of the known images`,
+				`droplets[0].region: "nyc9" is not one of the known regions`,
+				`apps[0]: name: is required`,
+				`firewalls[0]: inbound_rules[0].ports: ports may not be set for icmp rules`,
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+				path := writeSpecFile(t, "spec.yaml", tc.spec)
+
+				v := &specValidator{c: config, offline: true, catalogs: tc.catalogs}
+				res := v.validateFile(nil, path, "")
+				assert.Equal(t, tc.wantType, res.Type)
+				assert.Equal(t, tc.wantErrs, res.Errors)
+			})
+		})
+	}
+}
+
+func TestRunValidateOnline(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		path := writeSpecFile(t, "app.json", validJSONSpec)
+		catalogsPath := filepath.Join(t.TempDir(), "catalogs.json")
+
+		tm.regions.EXPECT().List().Return(do.Regions{{Region: &godo.Region{Slug: "nyc3"}}}, nil)
+		tm.sizes.EXPECT().List().Return(do.Sizes{{Size: &godo.Size{Slug: "s-1vcpu-1gb"}}}, nil)
+		tm.images.EXPECT().ListDistribution(true).Return(do.Images{{Image: &godo.Image{Slug: "ubuntu-22-04-x64"}}}, nil)
+		tm.images.EXPECT().ListApplication(true).Return(do.Images{}, nil)
+		tm.apps.EXPECT().ListRegions().Return([]*godo.AppRegion{{Slug: "nyc"}}, nil)
+		tm.apps.EXPECT().ListInstanceSizes().Return([]*godo.AppInstanceSize{{Slug: "apps-s-1vcpu-0.5gb"}}, nil)
+		tm.apps.EXPECT().Propose(&godo.AppProposeRequest{Spec: validAppSpec}).Return(&godo.AppProposeResponse{}, nil)
+
+		var buf bytes.Buffer
+		config.Out = &buf
+		config.Doit.Set(config.NS, doctl.ArgValidateFile, []string{path})
+		config.Doit.Set(config.NS, doctl.ArgValidateOffline, false)
+		config.Doit.Set(config.NS, doctl.ArgValidateCatalogs, catalogsPath)
+
+		err := RunValidate(config)
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), path)
+
+		// The catalogs are cached for offline runs.
+		catalogs, err := loadSpecCatalogs(catalogsPath)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"nyc3"}, catalogs.Catalogs[specschema.CatalogRegions])
+		assert.Equal(t, []string{"apps-s-1vcpu-0.5gb"}, catalogs.Catalogs[specschema.CatalogAppInstanceSizes])
+
+		config.Doit.Set(config.NS, doctl.ArgValidateFile, []string{writeSpecFile(t, "app.yaml", "name: test\nregion: mars\n")})
+		config.Doit.Set(config.NS, doctl.ArgValidateOffline, true)
+		assert.ErrorIs(t, RunValidate(config), ErrExitSilently)
+		assert.Contains(t, buf.String(), `region: "mars" is not one of the known app-regions`)
+	})
+}
+
+func TestRunValidateFailure(t *testing.T) {
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		path := writeSpecFile(t, "firewall.yaml", invalidFirewallSpec)
+
+		config.Doit.Set(config.NS, doctl.ArgValidateFile, []string{path})
+		config.Doit.Set(config.NS, doctl.ArgValidateOffline, true)
+		config.Doit.Set(config.NS, doctl.ArgValidateCatalogs, filepath.Join(t.TempDir(), "catalogs.json"))
+
+		err := RunValidate(config)
+		assert.ErrorIs(t, err, ErrExitSilently)
+	})
+}
+
+func TestValidateFlags(t *testing.T) {
+	// validate is built without a parent, so its flags must be read from the
+	// namespace they were bound under.
+	cmd := childCommand(newRootCommand(), "validate")
+	require.NoError(t, cmd.ParseFlags([]string{"-f", "stack.yaml", "--offline", "--type", "stack"}))
+
+	config := &doctl.LiveConfig{}
+	files, err := config.GetStringSlice(cmdNS(cmd), doctl.ArgValidateFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stack.yaml"}, files)
+	offline, err := config.GetBool(cmdNS(cmd), doctl.ArgValidateOffline)
+	require.NoError(t, err)
+	assert.True(t, offline)
+	specType, err := config.GetString(cmdNS(cmd), doctl.ArgValidateType)
+	require.NoError(t, err)
+	assert.Equal(t, specTypeStack, specType)
+}
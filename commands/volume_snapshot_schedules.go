@@ -0,0 +1,342 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/commands/displayers"
+	"github.com/digitalocean/godo"
+	"github.com/spf13/cobra"
+)
+
+// snapshotSchedulesPath returns the file volume snapshot schedules are saved
+// in. It is replaced for testing.
+var snapshotSchedulesPath = func() string {
+	return filepath.Join(configHome(), "volume-snapshot-schedules.json")
+}
+
+// scheduleNow returns the current time. It is replaced for testing.
+var scheduleNow = time.Now
+
+// VolumeSnapshotSchedule creates the volume snapshot-schedule commands.
+func VolumeSnapshotSchedule() *Command {
+	cmd := &Command{
+		Command: &cobra.Command{
+			Use:   "snapshot-schedule",
+			Short: "Display commands to snapshot volumes on a schedule",
+			Long: `The subcommands of ` + "`" + `doctl compute volume snapshot-schedule` + "`" + ` snapshot block storage volumes on a cron schedule and delete old scheduled snapshots.
+
+Schedules are saved on this machine, in ` + "`" + `volume-snapshot-schedules.json` + "`" + ` in the doctl configuration directory, and are carried out by ` + "`" + `doctl compute volume snapshot-schedule run` + "`" + `, which must be kept running or run regularly from cron or a systemd timer. Times are in the time zone of that machine.
+
+Volume snapshots are stored in the volume's region and cannot be transferred to another region, so they do not protect against the loss of a region.`,
+		},
+	}
+
+	cmdCreate := CmdBuilder(cmd, RunVolumeSnapshotScheduleCreate, "create <volume-id>", "Snapshot a volume on a schedule", `Saves a schedule for snapshotting a volume. Each snapshot is named after the volume, the schedule's ID, and the time it was taken, and once there are more than `+"`"+`--keep`+"`"+` of them, the oldest are deleted.`, Writer,
+		aliasOpt("c"), displayerType(&displayers.VolumeSnapshotSchedules{}))
+	AddStringFlag(cmdCreate, doctl.ArgSnapshotScheduleCron, "", "", "When to take snapshots, as a five-field cron expression such as `0 2 * * *`", requiredOpt())
+	AddIntFlag(cmdCreate, doctl.ArgSnapshotScheduleKeep, "", 7, "The number of snapshots to keep")
+	AddStringSliceFlag(cmdCreate, doctl.ArgTag, "", []string{}, "Tags to apply to each snapshot")
+	cmdCreate.Example = `The following example snapshots a volume every night at 2 AM and keeps the last 14 snapshots: doctl compute volume snapshot-schedule create f81d4fae-7dec-11d0-a765-00a0c91e6bf6 --cron '0 2 * * *' --keep 14`
+
+	CmdBuilder(cmd, RunVolumeSnapshotScheduleList, "list", "List volume snapshot schedules", `Lists the volume snapshot schedules saved on this machine, with when each last ran and will next run.`, Writer,
+		aliasOpt("ls"), displayerType(&displayers.VolumeSnapshotSchedules{}))
+
+	cmdDelete := CmdBuilder(cmd, RunVolumeSnapshotScheduleDelete, "delete <schedule-id>...", "Delete volume snapshot schedules", `Deletes volume snapshot schedules. Snapshots already taken are kept.`, Writer,
+		aliasOpt("rm"))
+	AddBoolFlag(cmdDelete, doctl.ArgForce, doctl.ArgShortForce, false, "Delete the schedules without a confirmation prompt")
+
+	cmdRun := CmdBuilder(cmd, RunVolumeSnapshotScheduleRun, "run", "Take scheduled volume snapshots", `Takes the snapshots that are due and deletes the scheduled snapshots beyond each schedule's retention, then waits for the next ones until interrupted.
+
+With `+"`"+`--once`+"`"+`, it takes the snapshots that are due, including any missed since the schedule last ran, and exits. Run it that way from cron or a systemd timer, as often as the most frequent schedule.`, Writer)
+	AddBoolFlag(cmdRun, doctl.ArgOnce, "", false, "Take the snapshots that are due and exit")
+	cmdRun.Example = `The following example takes due snapshots every 15 minutes from cron: */15 * * * * doctl compute volume snapshot-schedule run --once`
+
+	return cmd
+}
+
+// volumeSnapshotSchedule is a saved schedule for snapshotting a volume.
+type volumeSnapshotSchedule struct {
+	ID         string    `json:"id"`
+	VolumeID   string    `json:"volume_id"`
+	VolumeName string    `json:"volume_name"`
+	Region     string    `json:"region"`
+	Cron       string    `json:"cron"`
+	Keep       int       `json:"keep"`
+	Tags       []string  `json:"tags,omitempty"`
+	Created    time.Time `json:"created_at"`
+	LastRun    time.Time `json:"last_run,omitempty"`
+}
+
+// snapshotPrefix is the start of the names of the schedule's snapshots.
+func (s *volumeSnapshotSchedule) snapshotPrefix() string {
+	return s.VolumeName + "-" + s.ID + "-"
+}
+
+// due reports whether the schedule should have fired since it last ran.
+func (s *volumeSnapshotSchedule) due(now time.Time) (bool, error) {
+	cron, err := parseCron(s.Cron)
+	if err != nil {
+		return false, err
+	}
+	since := s.LastRun
+	if since.IsZero() {
+		since = s.Created
+	}
+	next := cron.next(since)
+	return !next.IsZero() && !next.After(now), nil
+}
+
+func readSnapshotSchedules() ([]volumeSnapshotSchedule, error) {
+	b, err := os.ReadFile(snapshotSchedulesPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var schedules []volumeSnapshotSchedule
+	if err := json.Unmarshal(b, &schedules); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", snapshotSchedulesPath(), err)
+	}
+	return schedules, nil
+}
+
+func writeSnapshotSchedules(schedules []volumeSnapshotSchedule) error {
+	b, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(snapshotSchedulesPath(), append(b, '\n'), 0600)
+}
+
+func displaySnapshotSchedules(c *CmdConfig, schedules []volumeSnapshotSchedule) error {
+	item := &displayers.VolumeSnapshotSchedules{Schedules: make([]displayers.VolumeSnapshotSchedule, 0, len(schedules))}
+	for _, s := range schedules {
+		ds := displayers.VolumeSnapshotSchedule{
+			ID: s.ID, VolumeID: s.VolumeID, VolumeName: s.VolumeName, Region: s.Region,
+			Cron: s.Cron, Keep: s.Keep, LastRun: s.LastRun,
+		}
+		if cron, err := parseCron(s.Cron); err == nil {
+			ds.NextRun = cron.next(scheduleNow())
+		}
+		item.Schedules = append(item.Schedules, ds)
+	}
+	return c.Display(item)
+}
+
+// RunVolumeSnapshotScheduleCreate saves a volume snapshot schedule.
+func RunVolumeSnapshotScheduleCreate(c *CmdConfig) error {
+	if len(c.Args) != 1 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	expr, err := c.Doit.GetString(c.NS, doctl.ArgSnapshotScheduleCron)
+	if err != nil {
+		return err
+	}
+	if _, err := parseCron(expr); err != nil {
+		return err
+	}
+	keep, err := c.Doit.GetInt(c.NS, doctl.ArgSnapshotScheduleKeep)
+	if err != nil {
+		return err
+	}
+	if keep < 1 {
+		return fmt.Errorf("`--%s` must be at least 1", doctl.ArgSnapshotScheduleKeep)
+	}
+	tags, err := c.Doit.GetStringSlice(c.NS, doctl.ArgTag)
+	if err != nil {
+		return err
+	}
+
+	v, err := c.Volumes().Get(c.Args[0])
+	if err != nil {
+		return err
+	}
+
+	schedules, err := readSnapshotSchedules()
+	if err != nil {
+		return err
+	}
+	s := volumeSnapshotSchedule{
+		ID:         newDNSChangeID(),
+		VolumeID:   v.ID,
+		VolumeName: v.Name,
+		Region:     regionSlug(v.Region),
+		Cron:       expr,
+		Keep:       keep,
+		Tags:       tags,
+		Created:    scheduleNow().UTC(),
+	}
+	schedules = append(schedules, s)
+	if err := writeSnapshotSchedules(schedules); err != nil {
+		return err
+	}
+
+	notice("Schedule saved. Snapshots are only taken while `doctl compute volume snapshot-schedule run` is running or run regularly.")
+	return displaySnapshotSchedules(c, []volumeSnapshotSchedule{s})
+}
+
+// RunVolumeSnapshotScheduleList lists the saved volume snapshot schedules.
+func RunVolumeSnapshotScheduleList(c *CmdConfig) error {
+	schedules, err := readSnapshotSchedules()
+	if err != nil {
+		return err
+	}
+	return displaySnapshotSchedules(c, schedules)
+}
+
+// RunVolumeSnapshotScheduleDelete deletes saved volume snapshot schedules.
+func RunVolumeSnapshotScheduleDelete(c *CmdConfig) error {
+	if len(c.Args) == 0 {
+		return doctl.NewMissingArgsErr(c.NS)
+	}
+	force, err := c.Doit.GetBool(c.NS, doctl.ArgForce)
+	if err != nil {
+		return err
+	}
+
+	schedules, err := readSnapshotSchedules()
+	if err != nil {
+		return err
+	}
+	for _, id := range c.Args {
+		found := false
+		for _, s := range schedules {
+			found = found || s.ID == id
+		}
+		if !found {
+			return withExitCode(ExitNotFound, fmt.Errorf("no volume snapshot schedule %q", id))
+		}
+	}
+
+	if !force && AskForConfirmDelete("volume snapshot schedule", len(c.Args), c.Args...) != nil {
+		return errOperationAborted
+	}
+
+	kept := schedules[:0]
+	for _, s := range schedules {
+		if !contains(c.Args, s.ID) {
+			kept = append(kept, s)
+		}
+	}
+	return writeSnapshotSchedules(kept)
+}
+
+// RunVolumeSnapshotScheduleRun takes scheduled volume snapshots.
+func RunVolumeSnapshotScheduleRun(c *CmdConfig) error {
+	once, err := c.Doit.GetBool(c.NS, doctl.ArgOnce)
+	if err != nil {
+		return err
+	}
+
+	for {
+		now := scheduleNow()
+		if err := runDueSnapshotSchedules(c, now); err != nil {
+			if once {
+				return err
+			}
+			warn("%v", err)
+		}
+		if once {
+			return nil
+		}
+		time.Sleep(time.Until(now.Truncate(time.Minute).Add(time.Minute)))
+	}
+}
+
+// runDueSnapshotSchedules snapshots the volumes whose schedules are due and
+// prunes their old snapshots. A failing schedule does not stop the others.
+func runDueSnapshotSchedules(c *CmdConfig, now time.Time) error {
+	// The file is reread every time so that changes to it are picked up.
+	schedules, err := readSnapshotSchedules()
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for i := range schedules {
+		s := &schedules[i]
+		due, err := s.due(now)
+		if err != nil {
+			warn("Schedule %s: %v", s.ID, err)
+			failed++
+			continue
+		}
+		if !due {
+			continue
+		}
+
+		if err := takeScheduledSnapshot(c, s, now); err != nil {
+			warn("Schedule %s: %v", s.ID, err)
+			failed++
+			continue
+		}
+		s.LastRun = now.UTC()
+		if err := writeSnapshotSchedules(schedules); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d volume snapshot schedule(s) failed", failed)
+	}
+	return nil
+}
+
+// takeScheduledSnapshot snapshots the schedule's volume and deletes the
+// oldest of its snapshots beyond the number to keep.
+func takeScheduledSnapshot(c *CmdConfig, s *volumeSnapshotSchedule, now time.Time) error {
+	vs := c.Volumes()
+	name := s.snapshotPrefix() + now.UTC().Format("20060102-1504")
+	snap, err := vs.CreateSnapshot(&godo.SnapshotCreateRequest{
+		VolumeID:    s.VolumeID,
+		Name:        name,
+		Description: fmt.Sprintf("Scheduled snapshot (%s)", s.Cron),
+		Tags:        s.Tags,
+	})
+	if err != nil {
+		return fmt.Errorf("snapshotting volume %s: %w", s.VolumeName, err)
+	}
+	notice("Created snapshot %s (%s) of volume %s", snap.Name, snap.ID, s.VolumeName)
+
+	snapshots, err := vs.ListSnapshots(s.VolumeID, nil)
+	if err != nil {
+		return err
+	}
+	var ours []godo.Snapshot
+	for _, sn := range snapshots {
+		if strings.HasPrefix(sn.Name, s.snapshotPrefix()) {
+			ours = append(ours, *sn.Snapshot)
+		}
+	}
+	// The names sort by the time the snapshots were taken.
+	sort.Slice(ours, func(i, j int) bool { return ours[i].Name > ours[j].Name })
+	for _, old := range ours[min(s.Keep, len(ours)):] {
+		if err := vs.DeleteSnapshot(old.ID); err != nil {
+			return fmt.Errorf("deleting old snapshot %s: %w", old.Name, err)
+		}
+		notice("Deleted snapshot %s (%s), which is beyond the %d to keep", old.Name, old.ID, s.Keep)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/digitalocean/doctl/do"
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withSnapshotSchedules(t *testing.T, now time.Time) {
+	path := filepath.Join(t.TempDir(), "volume-snapshot-schedules.json")
+	origPath, origNow := snapshotSchedulesPath, scheduleNow
+	snapshotSchedulesPath = func() string { return path }
+	scheduleNow = func() time.Time { return now }
+	t.Cleanup(func() {
+		snapshotSchedulesPath, scheduleNow = origPath, origNow
+	})
+}
+
+func TestVolumeSnapshotScheduleCreate(t *testing.T) {
+	withSnapshotSchedules(t, time.Date(2024, 5, 10, 14, 30, 0, 0, time.UTC))
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.volumes.EXPECT().Get(testVolume.ID).Return(&testVolume, nil)
+
+		config.Args = []string{testVolume.ID}
+		config.Doit.Set(config.NS, doctl.ArgSnapshotScheduleCron, "0 2 * * *")
+		config.Doit.Set(config.NS, doctl.ArgSnapshotScheduleKeep, 3)
+
+		require.NoError(t, RunVolumeSnapshotScheduleCreate(config))
+
+		schedules, err := readSnapshotSchedules()
+		require.NoError(t, err)
+		require.Len(t, schedules, 1)
+		assert.Equal(t, testVolume.ID, schedules[0].VolumeID)
+		assert.Equal(t, "test-volume", schedules[0].VolumeName)
+		assert.Equal(t, "atlantis", schedules[0].Region)
+		assert.Equal(t, 3, schedules[0].Keep)
+
+		config.Doit.Set(config.NS, doctl.ArgSnapshotScheduleCron, "0 2 * *")
+		assert.EqualError(t, RunVolumeSnapshotScheduleCreate(config), `invalid cron expression "0 2 * *": expected 5 fields, got 4`)
+	})
+}
+
+func TestVolumeSnapshotScheduleRun(t *testing.T) {
+	now := time.Date(2024, 5, 11, 2, 0, 10, 0, time.UTC)
+	withSnapshotSchedules(t, now)
+
+	require.NoError(t, writeSnapshotSchedules([]volumeSnapshotSchedule{
+		{ID: "abc", VolumeID: "vol-1", VolumeName: "data", Cron: "0 2 * * *", Keep: 2, Created: now.Add(-48 * time.Hour), LastRun: now.Add(-24 * time.Hour)},
+		// Not due until tomorrow.
+		{ID: "def", VolumeID: "vol-2", VolumeName: "logs", Cron: "0 3 * * *", Keep: 2, Created: now.Add(-time.Hour)},
+	}))
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		tm.volumes.EXPECT().CreateSnapshot(&godo.SnapshotCreateRequest{
+			VolumeID: "vol-1", Name: "data-abc-20240511-0200", Description: "Scheduled snapshot (0 2 * * *)",
+		}).Return(&do.Snapshot{Snapshot: &godo.Snapshot{ID: "s3", Name: "data-abc-20240511-0200"}}, nil)
+		tm.volumes.EXPECT().ListSnapshots("vol-1", nil).Return([]do.Snapshot{
+			{Snapshot: &godo.Snapshot{ID: "s1", Name: "data-abc-20240509-0200"}},
+			{Snapshot: &godo.Snapshot{ID: "s2", Name: "data-abc-20240510-0200"}},
+			{Snapshot: &godo.Snapshot{ID: "s3", Name: "data-abc-20240511-0200"}},
+			{Snapshot: &godo.Snapshot{ID: "manual", Name: "before-upgrade"}},
+		}, nil)
+		tm.volumes.EXPECT().DeleteSnapshot("s1").Return(nil)
+
+		config.Doit.Set(config.NS, doctl.ArgOnce, true)
+		require.NoError(t, RunVolumeSnapshotScheduleRun(config))
+
+		schedules, err := readSnapshotSchedules()
+		require.NoError(t, err)
+		assert.Equal(t, now, schedules[0].LastRun)
+		assert.True(t, schedules[1].LastRun.IsZero())
+
+		// Running again in the same minute does nothing.
+		require.NoError(t, RunVolumeSnapshotScheduleRun(config))
+	})
+}
+
+func TestVolumeSnapshotScheduleDelete(t *testing.T) {
+	withSnapshotSchedules(t, time.Now())
+	require.NoError(t, writeSnapshotSchedules([]volumeSnapshotSchedule{{ID: "abc"}, {ID: "def"}}))
+
+	withTestClient(t, func(config *CmdConfig, tm *tcMocks) {
+		config.Args = []string{"abc"}
+		config.Doit.Set(config.NS, doctl.ArgForce, true)
+		require.NoError(t, RunVolumeSnapshotScheduleDelete(config))
+
+		schedules, err := readSnapshotSchedules()
+		require.NoError(t, err)
+		assert.Equal(t, []volumeSnapshotSchedule{{ID: "def"}}, schedules)
+
+		config.Args = []string{"xyz"}
+		assert.EqualError(t, RunVolumeSnapshotScheduleDelete(config), `no volume snapshot schedule "xyz"`)
+	})
+}
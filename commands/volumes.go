@@ -58,6 +58,7 @@ Use the `+"`"+`doctl compute volume-action attach <volume-id> <droplet-id>`+"`"+
 	AddStringFlag(cmdVolumeCreate, doctl.ArgVolumeFilesystemType, "", "", "The volume's filesystem type: ext4 or xfs. If not specified, the volume is left unformatted")
 	AddStringFlag(cmdVolumeCreate, doctl.ArgVolumeFilesystemLabel, "", "", "The volume's filesystem label")
 	AddStringSliceFlag(cmdVolumeCreate, doctl.ArgTag, "", []string{}, "A comma-separated list of tags to apply to the volume. For example, `--tag frontend` or `--tag frontend,backend`")
+	AddStringFlag(cmdVolumeCreate, doctl.ArgIdempotencyKey, "", "", "A key that identifies this request, such as a CI job ID. A volume already created with the key, for example by an earlier run that failed, is returned instead of created again. The volume is tagged with `doctl-idempotency:` followed by the key.")
 	cmdVolumeCreate.Example = `The following example creates a 4TiB volume named ` + "`" + `example-volume` + "`" + ` in the ` + "`" + `nyc1` + "`" + ` region. The command also applies two tags to the volume: doctl compute volume create example-volume --region nyc1 --size 4TiB --tag frontend,backend`
 
 	cmdRunVolumeDelete := CmdBuilder(cmd, RunVolumeDelete, "delete <volume-id>", "Delete a block storage volume", `Deletes a block storage volume by ID, destroying all of its data and removing it from your account. This is irreversible.`, Writer,
@@ -78,6 +79,8 @@ You can use a block storage volume snapshot ID as a flag with `+"`"+`doctl volum
 	AddStringSliceFlag(cmdRunVolumeSnapshot, doctl.ArgTag, "", []string{}, "A comma-separate list of tags to apply to the snapshot. For example, `--tag frontend` or `--tag frontend,backend`")
 	cmdRunVolumeSnapshot.Example = `The following example creates a snapshot of a volume with the UUID ` + "`" + `f81d4fae-7dec-11d0-a765-00a0c91e6bf6` + "`" + `: doctl compute volume snapshot f81d4fae-7dec-11d0-a765-00a0c91e6bf6 --snapshot-name example-snapshot --tag frontend,backend`
 
+	cmd.AddCommand(VolumeSnapshotSchedule())
+
 	return cmd
 
 }
@@ -169,6 +172,17 @@ func RunVolumeCreate(c *CmdConfig) error {
 		return err
 	}
 
+	if region == "" && snapshotID == "" {
+		region, err = defaultRegion(c)
+		if err != nil {
+			return err
+		}
+	}
+	region, err = resolveRegionAlias(c, region)
+	if err != nil {
+		return err
+	}
+
 	if region == "" && snapshotID == "" {
 		errorMsg := fmt.Sprintf("%s.%s || %s.%s", c.NS, doctl.ArgVolumeRegion, c.NS, doctl.ArgVolumeSnapshot)
 		return doctl.NewMissingArgsErr(errorMsg)
@@ -188,6 +202,11 @@ func RunVolumeCreate(c *CmdConfig) error {
 		return err
 	}
 
+	idem, err := getIdempotency(c)
+	if err != nil {
+		return err
+	}
+
 	var createVolume godo.VolumeCreateRequest
 
 	createVolume.Name = name
@@ -197,11 +216,19 @@ func RunVolumeCreate(c *CmdConfig) error {
 	createVolume.SnapshotID = snapshotID
 	createVolume.FilesystemType = fsType
 	createVolume.FilesystemLabel = fsLabel
-	createVolume.Tags = tags
+	createVolume.Tags = idem.tags(tags)
+
+	withoutAutomaticRetries(c, idem, func(godoClient *godo.Client) {
+		c.Volumes = func() do.VolumesService { return do.NewVolumesService(godoClient) }
+	})
 
 	al := c.Volumes()
 
-	d, err := al.CreateVolume(&createVolume)
+	d, err := idempotentCreate(idem, func() (*do.Volume, error) {
+		return findCreatedVolume(al, idem, &createVolume)
+	}, func() (*do.Volume, error) {
+		return al.CreateVolume(&createVolume)
+	})
 	if err != nil {
 		return err
 	}
@@ -210,6 +237,25 @@ func RunVolumeCreate(c *CmdConfig) error {
 
 }
 
+// findCreatedVolume looks for a volume already created for r with the
+// idempotency key, such as by a create that failed after the volume was made.
+func findCreatedVolume(vs do.VolumesService, idem idempotency, r *godo.VolumeCreateRequest) (*do.Volume, error) {
+	list, err := vs.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range list {
+		if v.Name != r.Name || (r.Region != "" && v.Region != nil && v.Region.Slug != r.Region) {
+			continue
+		}
+		if idem.owns(v.Tags) {
+			notice("Volume %s was already created, with ID %s", v.Name, v.ID)
+			return &v, nil
+		}
+	}
+	return nil, nil
+}
+
 // RunVolumeDelete deletes a volume.
 func RunVolumeDelete(c *CmdConfig) error {
 	if len(c.Args) == 0 {
@@ -222,7 +268,7 @@ func RunVolumeDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if force || AskForConfirmDelete("volume", 1) == nil {
+	if force || AskForConfirmDelete("volume", 1, c.Args[0]) == nil {
 		id := c.Args[0]
 		return c.Volumes().DeleteVolume(id)
 	}
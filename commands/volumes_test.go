@@ -40,7 +40,7 @@ var (
 func TestVolumeCommand(t *testing.T) {
 	cmd := Volume()
 	assert.NotNil(t, cmd)
-	assertCommandNames(t, cmd, "create", "delete", "get", "list", "snapshot")
+	assertCommandNames(t, cmd, "create", "delete", "get", "list", "snapshot", "snapshot-schedule")
 }
 
 func TestVolumesGet(t *testing.T) {
@@ -60,7 +60,7 @@ With the VPC commands, you can list, create, or delete VPCs, and manage their co
 	AddStringFlag(cmdRecordCreate, doctl.ArgVPCDescription, "", "", "A description of the VPC network")
 	AddStringFlag(cmdRecordCreate, doctl.ArgVPCIPRange, "", "",
 		"The range of IP addresses in the VPC network, in CIDR notation, such as `10.116.0.0/20`. If not specified, we generate a range for you.")
-	AddStringFlag(cmdRecordCreate, doctl.ArgRegionSlug, "", "", "The VPC network's region slug, such as `nyc1`", requiredOpt())
+	AddStringFlag(cmdRecordCreate, doctl.ArgRegionSlug, "", "", "The VPC network's region slug, such as `nyc1`. Defaults to the configured `default-region`.")
 	cmdRecordCreate.Example = `The following example creates a VPC network named ` + "`" + `example-vpc` + "`" + ` in the ` + "`" + `nyc1` + "`" + ` region: doctl vpcs create --name example-vpc --region nyc1`
 
 	cmdRecordUpdate := CmdBuilder(cmd, RunVPCUpdate, "update <id>",
@@ -139,10 +139,13 @@ func RunVPCCreate(c *CmdConfig) error {
 	}
 	r.IPRange = ipRange
 
-	rSlug, err := c.Doit.GetString(c.NS, doctl.ArgRegionSlug)
+	rSlug, err := regionFromArgs(c, doctl.ArgRegionSlug)
 	if err != nil {
 		return err
 	}
+	if rSlug == "" {
+		return doctl.NewMissingArgsErr(c.NS + "." + doctl.ArgRegionSlug)
+	}
 	r.RegionSlug = rSlug
 
 	vpcs := c.VPCs()
@@ -215,7 +218,7 @@ func RunVPCDelete(c *CmdConfig) error {
 		return err
 	}
 
-	if force || AskForConfirmDelete("VPC", 1) == nil {
+	if force || AskForConfirmDelete("VPC", 1, vpcUUID) == nil {
 		vpcs := c.VPCs()
 		if err := vpcs.Delete(vpcUUID); err != nil {
 			return err
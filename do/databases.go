@@ -61,6 +61,11 @@ type DatabasePool struct {
 // DatabasePools is a slice of DatabasePool
 type DatabasePools []DatabasePool
 
+// DatabaseMetricsCredentials is a wrapper for godo.DatabaseMetricsCredentials
+type DatabaseMetricsCredentials struct {
+	*godo.DatabaseMetricsCredentials
+}
+
 // DatabaseReplica is a wrapper for godo.DatabaseReplica
 type DatabaseReplica struct {
 	*godo.DatabaseReplica
@@ -146,12 +151,14 @@ type DatabasesService interface {
 
 	GetMaintenance(string) (*DatabaseMaintenanceWindow, error)
 	UpdateMaintenance(string, *godo.DatabaseUpdateMaintenanceRequest) error
+	UpgradeMajorVersion(string, *godo.UpgradeVersionRequest) error
 
 	GetUser(string, string) (*DatabaseUser, error)
 	ListUsers(string) (DatabaseUsers, error)
 	CreateUser(string, *godo.DatabaseCreateUserRequest) (*DatabaseUser, error)
 	DeleteUser(string, string) error
 	ResetUserAuth(string, string, *godo.DatabaseResetUserAuthRequest) (*DatabaseUser, error)
+	UpdateUser(string, string, *godo.DatabaseUpdateUserRequest) (*DatabaseUser, error)
 
 	ListDBs(string) (DatabaseDBs, error)
 	CreateDB(string, *godo.DatabaseCreateDBRequest) (*DatabaseDB, error)
@@ -161,6 +168,7 @@ type DatabasesService interface {
 	ListPools(string) (DatabasePools, error)
 	CreatePool(string, *godo.DatabaseCreatePoolRequest) (*DatabasePool, error)
 	GetPool(string, string) (*DatabasePool, error)
+	UpdatePool(string, string, *godo.DatabaseUpdatePoolRequest) error
 	DeletePool(string, string) error
 
 	GetReplica(string, string) (*DatabaseReplica, error)
@@ -193,6 +201,9 @@ type DatabasesService interface {
 	DeleteTopic(string, string) error
 
 	ListDatabaseEvents(string) (DatabaseEvents, error)
+
+	GetCA(string) ([]byte, error)
+	GetMetricsCredentials() (*DatabaseMetricsCredentials, error)
 }
 
 type databasesService struct {
@@ -306,6 +317,12 @@ func (ds *databasesService) UpdateMaintenance(databaseID string, req *godo.Datab
 	return err
 }
 
+func (ds *databasesService) UpgradeMajorVersion(databaseID string, req *godo.UpgradeVersionRequest) error {
+	_, err := ds.client.Databases.UpgradeMajorVersion(context.TODO(), databaseID, req)
+
+	return err
+}
+
 func (ds *databasesService) ListBackups(databaseID string) (DatabaseBackups, error) {
 	f := func(opt *godo.ListOptions) ([]any, *godo.Response, error) {
 		list, resp, err := ds.client.Databases.ListBackups(context.TODO(), databaseID, opt)
@@ -394,6 +411,14 @@ func (ds *databasesService) ResetUserAuth(databaseID, userID string, req *godo.D
 	return &DatabaseUser{DatabaseUser: u}, nil
 }
 
+func (ds *databasesService) UpdateUser(databaseID, userID string, req *godo.DatabaseUpdateUserRequest) (*DatabaseUser, error) {
+	u, _, err := ds.client.Databases.UpdateUser(context.TODO(), databaseID, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	return &DatabaseUser{DatabaseUser: u}, nil
+}
+
 func (ds *databasesService) ListDBs(databaseID string) (DatabaseDBs, error) {
 	f := func(opt *godo.ListOptions) ([]any, *godo.Response, error) {
 		list, resp, err := ds.client.Databases.ListDBs(context.TODO(), databaseID, opt)
@@ -492,6 +517,12 @@ func (ds *databasesService) GetPool(databaseID, poolName string) (*DatabasePool,
 	return &DatabasePool{DatabasePool: p}, nil
 }
 
+func (ds *databasesService) UpdatePool(databaseID, poolName string, req *godo.DatabaseUpdatePoolRequest) error {
+	_, err := ds.client.Databases.UpdatePool(context.TODO(), databaseID, poolName, req)
+
+	return err
+}
+
 func (ds *databasesService) DeletePool(databaseID, poolName string) error {
 	_, err := ds.client.Databases.DeletePool(context.TODO(), databaseID, poolName)
 
@@ -783,3 +814,20 @@ func (ds *databasesService) ListDatabaseEvents(databaseID string) (DatabaseEvent
 	}
 	return list, nil
 }
+
+// GetCA returns the PEM-encoded CA certificate of a database cluster.
+func (ds *databasesService) GetCA(databaseID string) ([]byte, error) {
+	ca, _, err := ds.client.Databases.GetCA(context.TODO(), databaseID)
+	if err != nil {
+		return nil, err
+	}
+	return ca.Certificate, nil
+}
+
+func (ds *databasesService) GetMetricsCredentials() (*DatabaseMetricsCredentials, error) {
+	creds, _, err := ds.client.Databases.GetMetricsCredentials(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	return &DatabaseMetricsCredentials{DatabaseMetricsCredentials: creds}, nil
+}
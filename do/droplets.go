@@ -53,6 +53,7 @@ type Kernels []Kernel
 type DropletsService interface {
 	List() (Droplets, error)
 	ListByTag(string) (Droplets, error)
+	ListPages(string, int, func(Droplets) error) error
 	Get(int) (*Droplet, error)
 	Create(*godo.DropletCreateRequest, bool) (*Droplet, error)
 	CreateMultiple(*godo.DropletMultiCreateRequest) (Droplets, error)
@@ -136,6 +137,50 @@ func (ds *dropletsService) ListByTag(tagName string) (Droplets, error) {
 	return list, nil
 }
 
+// ListPages lists the Droplets with a tag, or all Droplets if the tag is
+// empty, starting with the Droplet at offset in the list, and passes each page
+// of them to fn as soon as it is fetched. The slice passed to fn is reused for
+// the next page, so fn must not keep it. fn may return ErrStopPaging to stop
+// listing.
+func (ds *dropletsService) ListPages(tagName string, offset int, fn func(Droplets) error) error {
+	f := func(opt *godo.ListOptions) ([]any, *godo.Response, error) {
+		var (
+			list []godo.Droplet
+			resp *godo.Response
+			err  error
+		)
+		if tagName == "" {
+			list, resp, err = ds.client.Droplets.List(context.TODO(), opt)
+		} else {
+			list, resp, err = ds.client.Droplets.ListByTag(context.TODO(), tagName, opt)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		si := make([]any, len(list))
+		for i := range list {
+			si[i] = &list[i]
+		}
+
+		return si, resp, err
+	}
+
+	skip := offset % perPage
+	list := make(Droplets, 0, perPage)
+	return PaginateRespPagesFrom(f, offset/perPage+1, func(si []any) error {
+		if skip > len(si) {
+			skip = len(si)
+		}
+		list = list[:0]
+		for _, d := range si[skip:] {
+			list = append(list, Droplet{Droplet: d.(*godo.Droplet)})
+		}
+		skip = 0
+		return fn(list)
+	})
+}
+
 func (ds *dropletsService) Get(id int) (*Droplet, error) {
 	d, _, err := ds.client.Droplets.Get(context.TODO(), id)
 	if err != nil {
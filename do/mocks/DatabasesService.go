@@ -229,6 +229,21 @@ func (mr *MockDatabasesServiceMockRecorder) Get(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockDatabasesService)(nil).Get), arg0)
 }
 
+// GetCA mocks base method.
+func (m *MockDatabasesService) GetCA(arg0 string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCA", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCA indicates an expected call of GetCA.
+func (mr *MockDatabasesServiceMockRecorder) GetCA(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCA", reflect.TypeOf((*MockDatabasesService)(nil).GetCA), arg0)
+}
+
 // GetConnection mocks base method.
 func (m *MockDatabasesService) GetConnection(arg0 string, arg1 bool) (*do.DatabaseConnection, error) {
 	m.ctrl.T.Helper()
@@ -289,6 +304,21 @@ func (mr *MockDatabasesServiceMockRecorder) GetMaintenance(arg0 any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaintenance", reflect.TypeOf((*MockDatabasesService)(nil).GetMaintenance), arg0)
 }
 
+// GetMetricsCredentials mocks base method.
+func (m *MockDatabasesService) GetMetricsCredentials() (*do.DatabaseMetricsCredentials, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMetricsCredentials")
+	ret0, _ := ret[0].(*do.DatabaseMetricsCredentials)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMetricsCredentials indicates an expected call of GetMetricsCredentials.
+func (mr *MockDatabasesServiceMockRecorder) GetMetricsCredentials() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetricsCredentials", reflect.TypeOf((*MockDatabasesService)(nil).GetMetricsCredentials))
+}
+
 // GetMySQLConfiguration mocks base method.
 func (m *MockDatabasesService) GetMySQLConfiguration(databaseID string) (*do.MySQLConfig, error) {
 	m.ctrl.T.Helper()
@@ -677,6 +707,20 @@ func (mr *MockDatabasesServiceMockRecorder) UpdateMySQLConfiguration(databaseID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMySQLConfiguration", reflect.TypeOf((*MockDatabasesService)(nil).UpdateMySQLConfiguration), databaseID, confString)
 }
 
+// UpdatePool mocks base method.
+func (m *MockDatabasesService) UpdatePool(arg0, arg1 string, arg2 *godo.DatabaseUpdatePoolRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePool", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePool indicates an expected call of UpdatePool.
+func (mr *MockDatabasesServiceMockRecorder) UpdatePool(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePool", reflect.TypeOf((*MockDatabasesService)(nil).UpdatePool), arg0, arg1, arg2)
+}
+
 // UpdatePostgreSQLConfiguration mocks base method.
 func (m *MockDatabasesService) UpdatePostgreSQLConfiguration(databaseID, confString string) error {
 	m.ctrl.T.Helper()
@@ -718,3 +762,32 @@ func (mr *MockDatabasesServiceMockRecorder) UpdateTopic(arg0, arg1, arg2 any) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTopic", reflect.TypeOf((*MockDatabasesService)(nil).UpdateTopic), arg0, arg1, arg2)
 }
+
+// UpdateUser mocks base method.
+func (m *MockDatabasesService) UpdateUser(arg0, arg1 string, arg2 *godo.DatabaseUpdateUserRequest) (*do.DatabaseUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*do.DatabaseUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockDatabasesServiceMockRecorder) UpdateUser(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockDatabasesService)(nil).UpdateUser), arg0, arg1, arg2)
+}
+
+// UpgradeMajorVersion mocks base method.
+func (m *MockDatabasesService) UpgradeMajorVersion(arg0 string, arg1 *godo.UpgradeVersionRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpgradeMajorVersion", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpgradeMajorVersion indicates an expected call of UpgradeMajorVersion.
+func (mr *MockDatabasesServiceMockRecorder) UpgradeMajorVersion(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpgradeMajorVersion", reflect.TypeOf((*MockDatabasesService)(nil).UpgradeMajorVersion), arg0, arg1)
+}
@@ -188,6 +188,20 @@ func (mr *MockDropletsServiceMockRecorder) ListByTag(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByTag", reflect.TypeOf((*MockDropletsService)(nil).ListByTag), arg0)
 }
 
+// ListPages mocks base method.
+func (m *MockDropletsService) ListPages(arg0 string, arg1 int, arg2 func(do.Droplets) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPages", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListPages indicates an expected call of ListPages.
+func (mr *MockDropletsServiceMockRecorder) ListPages(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPages", reflect.TypeOf((*MockDropletsService)(nil).ListPages), arg0, arg1, arg2)
+}
+
 // Neighbors mocks base method.
 func (m *MockDropletsService) Neighbors(arg0 int) (do.Droplets, error) {
 	m.ctrl.T.Helper()
@@ -84,6 +84,96 @@ func (mr *MockMonitoringServiceMockRecorder) GetAlertPolicy(arg0 any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlertPolicy", reflect.TypeOf((*MockMonitoringService)(nil).GetAlertPolicy), arg0)
 }
 
+// GetDropletAvailableMemory mocks base method.
+func (m *MockMonitoringService) GetDropletAvailableMemory(arg0 *godo.DropletMetricsRequest) (*godo.MetricsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDropletAvailableMemory", arg0)
+	ret0, _ := ret[0].(*godo.MetricsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDropletAvailableMemory indicates an expected call of GetDropletAvailableMemory.
+func (mr *MockMonitoringServiceMockRecorder) GetDropletAvailableMemory(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDropletAvailableMemory", reflect.TypeOf((*MockMonitoringService)(nil).GetDropletAvailableMemory), arg0)
+}
+
+// GetDropletBandwidth mocks base method.
+func (m *MockMonitoringService) GetDropletBandwidth(arg0 *godo.DropletBandwidthMetricsRequest) (*godo.MetricsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDropletBandwidth", arg0)
+	ret0, _ := ret[0].(*godo.MetricsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDropletBandwidth indicates an expected call of GetDropletBandwidth.
+func (mr *MockMonitoringServiceMockRecorder) GetDropletBandwidth(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDropletBandwidth", reflect.TypeOf((*MockMonitoringService)(nil).GetDropletBandwidth), arg0)
+}
+
+// GetDropletCPU mocks base method.
+func (m *MockMonitoringService) GetDropletCPU(arg0 *godo.DropletMetricsRequest) (*godo.MetricsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDropletCPU", arg0)
+	ret0, _ := ret[0].(*godo.MetricsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDropletCPU indicates an expected call of GetDropletCPU.
+func (mr *MockMonitoringServiceMockRecorder) GetDropletCPU(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDropletCPU", reflect.TypeOf((*MockMonitoringService)(nil).GetDropletCPU), arg0)
+}
+
+// GetDropletFilesystemFree mocks base method.
+func (m *MockMonitoringService) GetDropletFilesystemFree(arg0 *godo.DropletMetricsRequest) (*godo.MetricsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDropletFilesystemFree", arg0)
+	ret0, _ := ret[0].(*godo.MetricsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDropletFilesystemFree indicates an expected call of GetDropletFilesystemFree.
+func (mr *MockMonitoringServiceMockRecorder) GetDropletFilesystemFree(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDropletFilesystemFree", reflect.TypeOf((*MockMonitoringService)(nil).GetDropletFilesystemFree), arg0)
+}
+
+// GetDropletFilesystemSize mocks base method.
+func (m *MockMonitoringService) GetDropletFilesystemSize(arg0 *godo.DropletMetricsRequest) (*godo.MetricsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDropletFilesystemSize", arg0)
+	ret0, _ := ret[0].(*godo.MetricsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDropletFilesystemSize indicates an expected call of GetDropletFilesystemSize.
+func (mr *MockMonitoringServiceMockRecorder) GetDropletFilesystemSize(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDropletFilesystemSize", reflect.TypeOf((*MockMonitoringService)(nil).GetDropletFilesystemSize), arg0)
+}
+
+// GetDropletTotalMemory mocks base method.
+func (m *MockMonitoringService) GetDropletTotalMemory(arg0 *godo.DropletMetricsRequest) (*godo.MetricsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDropletTotalMemory", arg0)
+	ret0, _ := ret[0].(*godo.MetricsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDropletTotalMemory indicates an expected call of GetDropletTotalMemory.
+func (mr *MockMonitoringServiceMockRecorder) GetDropletTotalMemory(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDropletTotalMemory", reflect.TypeOf((*MockMonitoringService)(nil).GetDropletTotalMemory), arg0)
+}
+
 // ListAlertPolicies mocks base method.
 func (m *MockMonitoringService) ListAlertPolicies() (do.AlertPolicies, error) {
 	m.ctrl.T.Helper()
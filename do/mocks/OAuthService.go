@@ -39,6 +39,50 @@ func (m *MockOAuthService) EXPECT() *MockOAuthServiceMockRecorder {
 	return m.recorder
 }
 
+// CreateToken mocks base method.
+func (m *MockOAuthService) CreateToken(arg0 *do.TokenCreateRequest) (*do.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateToken", arg0)
+	ret0, _ := ret[0].(*do.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateToken indicates an expected call of CreateToken.
+func (mr *MockOAuthServiceMockRecorder) CreateToken(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockOAuthService)(nil).CreateToken), arg0)
+}
+
+// ListTokens mocks base method.
+func (m *MockOAuthService) ListTokens() ([]do.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTokens")
+	ret0, _ := ret[0].([]do.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTokens indicates an expected call of ListTokens.
+func (mr *MockOAuthServiceMockRecorder) ListTokens() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTokens", reflect.TypeOf((*MockOAuthService)(nil).ListTokens))
+}
+
+// RevokeToken mocks base method.
+func (m *MockOAuthService) RevokeToken(token, server string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", token, server)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockOAuthServiceMockRecorder) RevokeToken(token, server any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockOAuthService)(nil).RevokeToken), token, server)
+}
+
 // TokenInfo mocks base method.
 func (m *MockOAuthService) TokenInfo(arg0 string) (*do.OAuthTokenInfo, error) {
 	m.ctrl.T.Helper()
@@ -100,6 +100,21 @@ func (mr *MockServerlessServiceMockRecorder) CreateNamespace(arg0, arg1, arg2 an
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNamespace", reflect.TypeOf((*MockServerlessService)(nil).CreateNamespace), arg0, arg1, arg2)
 }
 
+// CreateTrigger mocks base method.
+func (m *MockServerlessService) CreateTrigger(arg0 context.Context, arg1 *do.CreateTriggerRequest) (do.ServerlessTrigger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTrigger", arg0, arg1)
+	ret0, _ := ret[0].(do.ServerlessTrigger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTrigger indicates an expected call of CreateTrigger.
+func (mr *MockServerlessServiceMockRecorder) CreateTrigger(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTrigger", reflect.TypeOf((*MockServerlessService)(nil).CreateTrigger), arg0, arg1)
+}
+
 // CredentialsPath mocks base method.
 func (m *MockServerlessService) CredentialsPath() string {
 	m.ctrl.T.Helper()
@@ -321,6 +336,21 @@ func (mr *MockServerlessServiceMockRecorder) GetNamespaceFromCluster(arg0, arg1
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNamespaceFromCluster", reflect.TypeOf((*MockServerlessService)(nil).GetNamespaceFromCluster), arg0, arg1)
 }
 
+// GetPackage mocks base method.
+func (m *MockServerlessService) GetPackage(arg0 string) (whisk.Package, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPackage", arg0)
+	ret0, _ := ret[0].(whisk.Package)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPackage indicates an expected call of GetPackage.
+func (mr *MockServerlessServiceMockRecorder) GetPackage(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPackage", reflect.TypeOf((*MockServerlessService)(nil).GetPackage), arg0)
+}
+
 // GetServerlessNamespace mocks base method.
 func (m *MockServerlessService) GetServerlessNamespace(arg0 context.Context) (do.ServerlessCredentials, error) {
 	m.ctrl.T.Helper()
@@ -454,6 +484,21 @@ func (mr *MockServerlessServiceMockRecorder) ListPackages() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPackages", reflect.TypeOf((*MockServerlessService)(nil).ListPackages))
 }
 
+// ListRules mocks base method.
+func (m *MockServerlessService) ListRules() ([]whisk.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRules")
+	ret0, _ := ret[0].([]whisk.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRules indicates an expected call of ListRules.
+func (mr *MockServerlessServiceMockRecorder) ListRules() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRules", reflect.TypeOf((*MockServerlessService)(nil).ListRules))
+}
+
 // ListTriggers mocks base method.
 func (m *MockServerlessService) ListTriggers(arg0 context.Context, arg1 string) ([]do.ServerlessTrigger, error) {
 	m.ctrl.T.Helper()
@@ -469,6 +514,51 @@ func (mr *MockServerlessServiceMockRecorder) ListTriggers(arg0, arg1 any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTriggers", reflect.TypeOf((*MockServerlessService)(nil).ListTriggers), arg0, arg1)
 }
 
+// PutFunction mocks base method.
+func (m *MockServerlessService) PutFunction(arg0 whisk.Action) (whisk.Action, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutFunction", arg0)
+	ret0, _ := ret[0].(whisk.Action)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutFunction indicates an expected call of PutFunction.
+func (mr *MockServerlessServiceMockRecorder) PutFunction(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutFunction", reflect.TypeOf((*MockServerlessService)(nil).PutFunction), arg0)
+}
+
+// PutPackage mocks base method.
+func (m *MockServerlessService) PutPackage(arg0 whisk.Package) (whisk.Package, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutPackage", arg0)
+	ret0, _ := ret[0].(whisk.Package)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutPackage indicates an expected call of PutPackage.
+func (mr *MockServerlessServiceMockRecorder) PutPackage(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutPackage", reflect.TypeOf((*MockServerlessService)(nil).PutPackage), arg0)
+}
+
+// PutRule mocks base method.
+func (m *MockServerlessService) PutRule(arg0 whisk.Rule) (whisk.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutRule", arg0)
+	ret0, _ := ret[0].(whisk.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutRule indicates an expected call of PutRule.
+func (mr *MockServerlessServiceMockRecorder) PutRule(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutRule", reflect.TypeOf((*MockServerlessService)(nil).PutRule), arg0)
+}
+
 // ReadCredentials mocks base method.
 func (m *MockServerlessService) ReadCredentials() (do.ServerlessCredentials, error) {
 	m.ctrl.T.Helper()
@@ -34,6 +34,12 @@ type MonitoringService interface {
 	CreateAlertPolicy(request *godo.AlertPolicyCreateRequest) (*AlertPolicy, error)
 	UpdateAlertPolicy(uuid string, request *godo.AlertPolicyUpdateRequest) (*AlertPolicy, error)
 	DeleteAlertPolicy(string) error
+	GetDropletCPU(*godo.DropletMetricsRequest) (*godo.MetricsResponse, error)
+	GetDropletTotalMemory(*godo.DropletMetricsRequest) (*godo.MetricsResponse, error)
+	GetDropletAvailableMemory(*godo.DropletMetricsRequest) (*godo.MetricsResponse, error)
+	GetDropletFilesystemSize(*godo.DropletMetricsRequest) (*godo.MetricsResponse, error)
+	GetDropletFilesystemFree(*godo.DropletMetricsRequest) (*godo.MetricsResponse, error)
+	GetDropletBandwidth(*godo.DropletBandwidthMetricsRequest) (*godo.MetricsResponse, error)
 }
 
 type monitoringService struct {
@@ -109,3 +115,57 @@ func (ms *monitoringService) DeleteAlertPolicy(uuid string) error {
 	_, err := ms.client.Monitoring.DeleteAlertPolicy(context.TODO(), uuid)
 	return err
 }
+
+func (ms *monitoringService) GetDropletCPU(req *godo.DropletMetricsRequest) (*godo.MetricsResponse, error) {
+	m, _, err := ms.client.Monitoring.GetDropletCPU(context.TODO(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (ms *monitoringService) GetDropletTotalMemory(req *godo.DropletMetricsRequest) (*godo.MetricsResponse, error) {
+	m, _, err := ms.client.Monitoring.GetDropletTotalMemory(context.TODO(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (ms *monitoringService) GetDropletAvailableMemory(req *godo.DropletMetricsRequest) (*godo.MetricsResponse, error) {
+	m, _, err := ms.client.Monitoring.GetDropletAvailableMemory(context.TODO(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (ms *monitoringService) GetDropletFilesystemSize(req *godo.DropletMetricsRequest) (*godo.MetricsResponse, error) {
+	m, _, err := ms.client.Monitoring.GetDropletFilesystemSize(context.TODO(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (ms *monitoringService) GetDropletFilesystemFree(req *godo.DropletMetricsRequest) (*godo.MetricsResponse, error) {
+	m, _, err := ms.client.Monitoring.GetDropletFilesystemFree(context.TODO(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (ms *monitoringService) GetDropletBandwidth(req *godo.DropletBandwidthMetricsRequest) (*godo.MetricsResponse, error) {
+	m, _, err := ms.client.Monitoring.GetDropletBandwidth(context.TODO(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -15,14 +15,20 @@ package do
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/digitalocean/godo"
 )
 
 const (
-	oauthBaseURL  = "https://cloud.digitalocean.com"
-	tokenInfoPath = "/v1/oauth/token/info"
+	oauthBaseURL    = "https://cloud.digitalocean.com"
+	tokenInfoPath   = "/v1/oauth/token/info"
+	tokenRevokePath = "/v1/oauth/revoke"
+	tokensPath      = "v2/tokens"
 )
 
 // OAuthTokenInfo contains information about an OAuth token
@@ -39,9 +45,40 @@ type OAuthApplication struct {
 	UID string `json:"uid"`
 }
 
+// TokenCreateRequest describes a personal access token to create.
+type TokenCreateRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpirySeconds *int     `json:"expiry_seconds,omitempty"`
+}
+
+// Token is a personal access token. AccessToken is only populated when the
+// token is created.
+type Token struct {
+	ID            int       `json:"id"`
+	Name          string    `json:"name"`
+	Scopes        []string  `json:"scopes"`
+	ExpirySeconds *int      `json:"expiry_seconds,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	AccessToken   string    `json:"access_token,omitempty"`
+}
+
+type tokenRoot struct {
+	Token *Token `json:"token"`
+}
+
+type tokensRoot struct {
+	Tokens []Token     `json:"tokens"`
+	Links  *godo.Links `json:"links"`
+	Meta   *godo.Meta  `json:"meta"`
+}
+
 // OAuthService is an interface for interacting with DigitalOcean's account api.
 type OAuthService interface {
 	TokenInfo(string) (*OAuthTokenInfo, error)
+	CreateToken(*TokenCreateRequest) (*Token, error)
+	ListTokens() ([]Token, error)
+	RevokeToken(token string, server string) error
 }
 
 type oauthService struct {
@@ -78,3 +115,75 @@ func (oa *oauthService) TokenInfo(server string) (*OAuthTokenInfo, error) {
 
 	return info, nil
 }
+
+func (oa *oauthService) CreateToken(tcr *TokenCreateRequest) (*Token, error) {
+	ctx := context.TODO()
+	req, err := oa.client.NewRequest(ctx, http.MethodPost, tokensPath, tcr)
+	if err != nil {
+		return nil, err
+	}
+
+	root := new(tokenRoot)
+	_, err = oa.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, err
+	}
+
+	return root.Token, nil
+}
+
+func (oa *oauthService) ListTokens() ([]Token, error) {
+	f := func(opt *godo.ListOptions) ([]any, *godo.Response, error) {
+		ctx := context.TODO()
+		path := fmt.Sprintf("%s?page=%d&per_page=%d", tokensPath, opt.Page, opt.PerPage)
+		req, err := oa.client.NewRequest(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		root := new(tokensRoot)
+		resp, err := oa.client.Do(ctx, req, root)
+		if err != nil {
+			return nil, nil, err
+		}
+		resp.Links, resp.Meta = root.Links, root.Meta
+
+		si := make([]any, len(root.Tokens))
+		for i := range root.Tokens {
+			si[i] = root.Tokens[i]
+		}
+		return si, resp, nil
+	}
+
+	si, err := PaginateResp(f)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Token, len(si))
+	for i := range si {
+		list[i] = si[i].(Token)
+	}
+	return list, nil
+}
+
+func (oa *oauthService) RevokeToken(token string, server string) error {
+	revokeURI := oauthBaseURL + tokenRevokePath
+	if server != "" {
+		revokeURI = server + tokenRevokePath
+	}
+
+	data := url.Values{}
+	data.Set("token", token)
+
+	ctx := context.TODO()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, revokeURI, strings.NewReader(data.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	_, err = oa.client.Do(ctx, req, nil)
+	return err
+}
@@ -14,6 +14,7 @@ limitations under the License.
 package do
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -107,6 +108,66 @@ func PaginateResp(gen Generator) ([]any, error) {
 	return items, nil
 }
 
+// PageFunc receives the items of a page of a paginated list. It may return
+// ErrStopPaging to stop before the last page.
+type PageFunc func(items []any) error
+
+// ErrStopPaging is returned by a PageFunc to stop paging without an error.
+var ErrStopPaging = errors.New("stop paging")
+
+// PaginateRespPages fetches the pages of a Response one after another, in
+// order, and passes the items of each to fn as soon as it is fetched, so that
+// long lists can be used before they are complete.
+func PaginateRespPages(gen Generator, fn PageFunc) error {
+	return PaginateRespPagesFrom(gen, 1, fn)
+}
+
+// PaginateRespPagesFrom is PaginateRespPages starting at a page other than
+// the first. The next page is fetched while fn handles the current one.
+func PaginateRespPagesFrom(gen Generator, page int, fn PageFunc) error {
+	type result struct {
+		items []any
+		resp  *godo.Response
+		err   error
+	}
+	fetch := func(page int) <-chan result {
+		ch := make(chan result, 1)
+		go func() {
+			items, resp, err := gen(&godo.ListOptions{Page: page, PerPage: perPage})
+			ch <- result{items, resp, err}
+		}()
+		return ch
+	}
+
+	next := fetch(page)
+	for {
+		r := <-next
+		if r.err != nil {
+			return r.err
+		}
+
+		// The last page has no link to the last page.
+		lp, err := lastPage(r.resp)
+		if err != nil {
+			return err
+		}
+		if page < lp {
+			next = fetch(page + 1)
+		}
+
+		if err := fn(r.items); err != nil {
+			if errors.Is(err, ErrStopPaging) {
+				return nil
+			}
+			return err
+		}
+		if page >= lp {
+			return nil
+		}
+		page++
+	}
+}
+
 func fetchPage(gen Generator, page int) ([]any, error) {
 	opt := &godo.ListOptions{Page: page, PerPage: perPage}
 	items, _, err := gen(opt)
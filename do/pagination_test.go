@@ -14,6 +14,7 @@ limitations under the License.
 package do
 
 import (
+	"errors"
 	"sync"
 	"testing"
 
@@ -39,6 +40,59 @@ func Test_PaginateResp(t *testing.T) {
 	assert.Len(t, list, 5)
 }
 
+func Test_PaginateRespPages(t *testing.T) {
+	gen := func(opt *godo.ListOptions) ([]any, *godo.Response, error) {
+		resp := &godo.Response{}
+		if opt.Page < 3 {
+			resp.Links = &godo.Links{Pages: &godo.Pages{Last: "http://example.com/?page=3"}}
+		}
+		return []any{opt.Page * 10, opt.Page*10 + 1}, resp, nil
+	}
+
+	var pages [][]any
+	err := PaginateRespPages(gen, func(items []any) error {
+		pages = append(pages, items)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]any{{10, 11}, {20, 21}, {30, 31}}, pages)
+
+	stop := errors.New("stop")
+	calls := 0
+	err = PaginateRespPages(gen, func(items []any) error {
+		calls++
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, calls)
+}
+
+func Test_PaginateRespPagesFrom(t *testing.T) {
+	gen := func(opt *godo.ListOptions) ([]any, *godo.Response, error) {
+		resp := &godo.Response{}
+		if opt.Page < 3 {
+			resp.Links = &godo.Links{Pages: &godo.Pages{Last: "http://example.com/?page=3"}}
+		}
+		return []any{opt.Page}, resp, nil
+	}
+
+	var pages []any
+	err := PaginateRespPagesFrom(gen, 2, func(items []any) error {
+		pages = append(pages, items...)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{2, 3}, pages)
+
+	pages = nil
+	err = PaginateRespPagesFrom(gen, 1, func(items []any) error {
+		pages = append(pages, items...)
+		return ErrStopPaging
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{1}, pages)
+}
+
 func Test_Pagination_fetchPage(t *testing.T) {
 	gen := func(opt *godo.ListOptions) ([]any, *godo.Response, error) {
 		items := []any{}
@@ -21,6 +21,7 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/digitalocean/doctl"
 	"github.com/digitalocean/godo"
 )
 
@@ -361,9 +362,7 @@ func (rs *registryService) RevokeOAuthToken(token string, endpoint string) error
 	}
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	client := http.Client{}
-
-	resp, err := client.Do(req)
+	resp, err := doctl.ReadOnlyClient(&http.Client{}).Do(req)
 	if err != nil {
 		return err
 	}
@@ -14,6 +14,7 @@ limitations under the License.
 package do
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha1"
 	"encoding/hex"
@@ -176,6 +177,15 @@ type ServerlessTriggerGetResponse struct {
 	Trigger ServerlessTrigger `json:"Trigger,omitempty"`
 }
 
+// CreateTriggerRequest is the form of the POST body used to create a trigger
+type CreateTriggerRequest struct {
+	Name             string                   `json:"name"`
+	Type             string                   `json:"type"`
+	Function         string                   `json:"function"`
+	IsEnabled        bool                     `json:"is_enabled"`
+	ScheduledDetails *TriggerScheduledDetails `json:"scheduled_details,omitempty"`
+}
+
 type UpdateTriggerRequest struct {
 	IsEnabled        bool                     `json:"is_enabled"`
 	ScheduledDetails *TriggerScheduledDetails `json:"scheduled_details,omitempty"`
@@ -219,6 +229,7 @@ type ServerlessService interface {
 	CleanNamespace() error
 	ListTriggers(context.Context, string) ([]ServerlessTrigger, error)
 	GetTrigger(context.Context, string) (ServerlessTrigger, error)
+	CreateTrigger(context.Context, *CreateTriggerRequest) (ServerlessTrigger, error)
 	UpdateTrigger(context.Context, string, *UpdateTriggerRequest) (ServerlessTrigger, error)
 	DeleteTrigger(context.Context, string) error
 	WriteCredentials(ServerlessCredentials) error
@@ -227,10 +238,15 @@ type ServerlessService interface {
 	CheckServerlessStatus() error
 	InstallServerless(string, bool) error
 	ListPackages() ([]whisk.Package, error)
+	GetPackage(string) (whisk.Package, error)
+	PutPackage(whisk.Package) (whisk.Package, error)
 	DeletePackage(string, bool) error
 	GetFunction(string, bool) (whisk.Action, []FunctionParameter, error)
 	ListFunctions(string, int, int) ([]whisk.Action, error)
+	PutFunction(whisk.Action) (whisk.Action, error)
 	DeleteFunction(string, bool) error
+	ListRules() ([]whisk.Rule, error)
+	PutRule(whisk.Rule) (whisk.Rule, error)
 	InvokeFunction(string, any, bool, bool) (any, error)
 	InvokeFunctionViaWeb(string, any) error
 	ListActivations(whisk.ActivationListOptions) ([]whisk.Activation, error)
@@ -255,6 +271,10 @@ type serverlessService struct {
 	client        *godo.Client
 	owClient      *whisk.Client
 	owConfig      *whisk.Config
+	// pluginVerified records that verifyPlugin passed, so it runs once per process.
+	pluginVerified bool
+	// timeout bounds plugin invocations run by Exec. Zero means no limit.
+	timeout time.Duration
 }
 
 const (
@@ -311,6 +331,15 @@ var (
 
 	// ErrServerlessNotConnected is the error returned to users when the sandbox is not connected to a namespace
 	ErrServerlessNotConnected = errors.New("serverless support is installed but not connected to a functions namespace (use `doctl serverless connect`)")
+
+	// ErrServerlessAuth is wrapped by plugin errors caused by namespace credentials that are invalid or not permitted.
+	ErrServerlessAuth = errors.New("serverless credentials rejected")
+
+	// ErrServerlessQuota is wrapped by plugin errors caused by exceeding a namespace's limits.
+	ErrServerlessQuota = errors.New("serverless limit exceeded")
+
+	// ErrServerlessProjectConfig is wrapped by plugin errors caused by an invalid project.yml.
+	ErrServerlessProjectConfig = errors.New("invalid serverless project configuration")
 )
 
 // ServerlessOutput contains the output returned from calls to the sandbox plugin.
@@ -322,12 +351,9 @@ type ServerlessOutput struct {
 	Error     string           `json:"error,omitempty"`
 }
 
-// NewServerlessService returns a configured ServerlessService.
-func NewServerlessService(client *godo.Client, usualServerlessDir string, accessToken string) ServerlessService {
-	nodeBin := "node"
-	if runtime.GOOS == "windows" {
-		nodeBin = "node.exe"
-	}
+// NewServerlessService returns a configured ServerlessService. Plugin invocations whose output is captured are stopped
+// after timeout, unless it is zero.
+func NewServerlessService(client *godo.Client, usualServerlessDir string, accessToken string, timeout time.Duration) ServerlessService {
 	// The following is needed to support snap installation.  For snap, the installation directory
 	// is relocated to a snap-managed area.  That area is not user-writable, so, the credsDir location
 	// is always computed relative to the normal installation area (usualServerlessDir).
@@ -340,11 +366,12 @@ func NewServerlessService(client *godo.Client, usualServerlessDir string, access
 		serverlessJs:  filepath.Join(serverlessDir, "sandbox.js"),
 		serverlessDir: serverlessDir,
 		credsDir:      GetCredentialDirectory(credsToken, usualServerlessDir),
-		node:          filepath.Join(serverlessDir, nodeBin),
+		node:          findNode(serverlessDir),
 		userAgent:     fmt.Sprintf("doctl/%s serverless/%s", doctl.DoitVersion.String(), minServerlessVersion),
 		client:        client,
 		owClient:      nil,
 		accessToken:   accessToken,
+		timeout:       timeout,
 	}
 }
 
@@ -378,7 +405,7 @@ func initWhisk(s *serverlessService) error {
 		credential := creds.Credentials[creds.APIHost][creds.Namespace]
 		config = &whisk.Config{Host: creds.APIHost, AuthToken: credential.Auth}
 	}
-	client, err := whisk.NewClient(http.DefaultClient, config)
+	client, err := whisk.NewClient(doctl.ReadOnlyClient(http.DefaultClient), config)
 	if err != nil {
 		return err
 	}
@@ -427,7 +454,7 @@ func (s *serverlessService) InstallServerless(leafCredsDir string, upgrading boo
 
 	goos := runtime.GOOS
 	arch := runtime.GOARCH
-	nodeBin := "node"
+	nodeBin := nodeBinary()
 	if arch == "amd64" {
 		arch = "x64"
 	}
@@ -439,7 +466,6 @@ func (s *serverlessService) InstallServerless(leafCredsDir string, upgrading boo
 	}
 	if goos == "windows" {
 		goos = "win"
-		nodeBin = "node.exe"
 	}
 
 	var (
@@ -544,6 +570,10 @@ func (s *serverlessService) InstallServerless(leafCredsDir string, upgrading boo
 			return err
 		}
 	}
+	err = recordChecksums(serverlessDir)
+	if err != nil {
+		return err
+	}
 	// Clean up temp directory
 	fmt.Print("Cleaning up...")
 	os.RemoveAll(tmp) // Best effort, ignore error
@@ -551,11 +581,27 @@ func (s *serverlessService) InstallServerless(leafCredsDir string, upgrading boo
 	return nil
 }
 
+// readOnlyPluginCommands are the plugin commands that don't change anything,
+// which are the only ones run in read-only mode.
+var readOnlyPluginCommands = map[string]bool{"get-metadata": true}
+
 // Cmd builds an *exec.Cmd for calling into the sandbox plugin.
 func (s *serverlessService) Cmd(command string, args []string) (*exec.Cmd, error) {
+	// The plugin makes its own requests, which doctl can't inspect, so in
+	// read-only mode the commands that may change something aren't run.
+	pluginCommand := command
+	if command == "nocapture" && len(args) > 0 {
+		pluginCommand = args[0]
+	}
+	if doctl.ReadOnly() && !readOnlyPluginCommands[pluginCommand] {
+		return nil, &doctl.ReadOnlyErr{Operation: "run the serverless plugin's " + pluginCommand + " command"}
+	}
+	if err := s.verifyPlugin(); err != nil {
+		return nil, err
+	}
 	args = append([]string{s.serverlessJs, command}, args...)
 	cmd := exec.Command(s.node, args...)
-	cmd.Env = append(os.Environ(), "NIMBELLA_DIR="+s.credsDir, "NIM_USER_AGENT="+s.userAgent, "DO_API_KEY="+s.accessToken)
+	cmd.Env = s.pluginEnv(os.Environ())
 	// If DEBUG is specified, we need to open up stderr for that stream.  The stdout stream
 	// will continue to work for returning structured results.
 	if os.Getenv("DEBUG") != "" {
@@ -566,13 +612,20 @@ func (s *serverlessService) Cmd(command string, args []string) (*exec.Cmd, error
 
 // Exec executes an *exec.Cmd and captures its output in a ServerlessOutput.
 func (s *serverlessService) Exec(cmd *exec.Cmd) (ServerlessOutput, error) {
-	output, err := cmd.Output()
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	err := s.runPlugin(cmd, s.timeout)
+	output := stdout.Bytes()
 	if err != nil {
 		// Ignore "errors" that are just non-zero exit.  The
 		// serverless uses this as a secondary indicator but the output
 		// is still trustworthy (and includes error information inline)
 		if _, ok := err.(*exec.ExitError); !ok {
-			// Real error of some sort
+			// Real error of some sort.  If the plugin was stopped, what it printed so far
+			// may show how far it got.
+			if partial := strings.TrimSpace(string(output)); partial != "" && isStopped(err) {
+				return ServerlessOutput{Captured: strings.Split(partial, "\n")}, fmt.Errorf("%w; output so far:\n%s", err, partial)
+			}
 			return ServerlessOutput{}, err
 		}
 	}
@@ -586,16 +639,16 @@ func (s *serverlessService) Exec(cmd *exec.Cmd) (ServerlessOutput, error) {
 	// error return.  Most callers will process only the error, which is fine.  Sometimes,
 	// however, there is other information that can be useful as part of the error report.
 	if len(result.Error) > 0 {
-		return result, errors.New(result.Error)
+		return result, pluginError(result.Error)
 	}
 	// Result is both sound and error free
 	return result, nil
 }
 
 // Stream is like Exec but assumes that output will not be captured and can be streamed.
+// Streaming commands, such as watch, run until they are interrupted, so no timeout applies.
 func (s *serverlessService) Stream(cmd *exec.Cmd) error {
-
-	return cmd.Run()
+	return s.runPlugin(cmd, 0)
 }
 
 // GetServerlessNamespace returns the credentials of the one serverless namespace assigned to
@@ -680,7 +733,7 @@ func (s *serverlessService) GetNamespaceFromCluster(APIhost string, auth string)
 	// We do not use the shared client in serverlessService for this because it uses the stored
 	// credentials, not the passed ones.
 	config := whisk.Config{Host: APIhost, AuthToken: auth}
-	client, err := whisk.NewClient(http.DefaultClient, &config)
+	client, err := whisk.NewClient(doctl.ReadOnlyClient(http.DefaultClient), &config)
 	if err != nil {
 		return "", err
 	}
@@ -828,6 +881,19 @@ func (s *serverlessService) ListFunctions(pkg string, skip int, limit int) ([]wh
 	return list, err
 }
 
+// PutFunction creates a function or replaces an existing one
+func (s *serverlessService) PutFunction(action whisk.Action) (whisk.Action, error) {
+	err := initWhisk(s)
+	if err != nil {
+		return whisk.Action{}, err
+	}
+	result, _, err := s.owClient.Actions.Insert(&action, true)
+	if err != nil {
+		return whisk.Action{}, err
+	}
+	return *result, nil
+}
+
 // DeleteFunction removes a function from the namespace
 func (s *serverlessService) DeleteFunction(name string, deleteTriggers bool) error {
 	err := initWhisk(s)
@@ -867,6 +933,32 @@ func (s *serverlessService) ListPackages() ([]whisk.Package, error) {
 	return list, err
 }
 
+// GetPackage returns the metadata of a package, including its parameters and annotations
+func (s *serverlessService) GetPackage(name string) (whisk.Package, error) {
+	err := initWhisk(s)
+	if err != nil {
+		return whisk.Package{}, err
+	}
+	pkg, _, err := s.owClient.Packages.Get(name)
+	if err != nil {
+		return whisk.Package{}, err
+	}
+	return *pkg, nil
+}
+
+// PutPackage creates a package or replaces an existing one
+func (s *serverlessService) PutPackage(pkg whisk.Package) (whisk.Package, error) {
+	err := initWhisk(s)
+	if err != nil {
+		return whisk.Package{}, err
+	}
+	result, _, err := s.owClient.Packages.Insert(&pkg, true)
+	if err != nil {
+		return whisk.Package{}, err
+	}
+	return *result, nil
+}
+
 // DeletePackage removes a package from the namespace.
 // If recursive is set to true, it will remove all functions in the package.
 func (s *serverlessService) DeletePackage(name string, recursive bool) error {
@@ -897,6 +989,30 @@ func (s *serverlessService) DeletePackage(name string, recursive bool) error {
 	return err
 }
 
+// ListRules lists the rules of the namespace
+func (s *serverlessService) ListRules() ([]whisk.Rule, error) {
+	err := initWhisk(s)
+	if err != nil {
+		return []whisk.Rule{}, err
+	}
+	options := whisk.RuleListOptions{Limit: 200}
+	list, _, err := s.owClient.Rules.List(&options)
+	return list, err
+}
+
+// PutRule creates a rule or replaces an existing one
+func (s *serverlessService) PutRule(rule whisk.Rule) (whisk.Rule, error) {
+	err := initWhisk(s)
+	if err != nil {
+		return whisk.Rule{}, err
+	}
+	result, _, err := s.owClient.Rules.Insert(&rule, true)
+	if err != nil {
+		return whisk.Rule{}, err
+	}
+	return *result, nil
+}
+
 // InvokeFunction invokes a function via POST with authentication
 func (s *serverlessService) InvokeFunction(name string, params any, blocking bool, result bool) (any, error) {
 	var empty map[string]any
@@ -1116,6 +1232,30 @@ func (s *serverlessService) GetTrigger(ctx context.Context, name string) (Server
 	return decoded.Trigger, nil
 }
 
+// CreateTrigger creates a trigger in the connected namespace
+func (s *serverlessService) CreateTrigger(ctx context.Context, opts *CreateTriggerRequest) (ServerlessTrigger, error) {
+	empty := ServerlessTrigger{}
+	err := s.CheckServerlessStatus()
+	if err != nil {
+		return empty, err
+	}
+	creds, err := s.ReadCredentials()
+	if err != nil {
+		return empty, err
+	}
+	path := fmt.Sprintf("v2/functions/namespaces/%s/triggers", creds.Namespace)
+	req, err := s.client.NewRequest(ctx, http.MethodPost, path, opts)
+	if err != nil {
+		return empty, err
+	}
+	decoded := new(ServerlessTriggerGetResponse)
+	_, err = s.client.Do(ctx, req, decoded)
+	if err != nil {
+		return empty, err
+	}
+	return decoded.Trigger, nil
+}
+
 func (s *serverlessService) UpdateTrigger(ctx context.Context, trigger string, opts *UpdateTriggerRequest) (ServerlessTrigger, error) {
 	empty := ServerlessTrigger{}
 	err := s.CheckServerlessStatus()
@@ -1169,16 +1309,17 @@ func readTopLevel(project *ServerlessProject) error {
 		return err
 	}
 	for _, f := range files {
+		path := filepath.Join(project.ProjectPath, f.Name())
 		if f.Name() == Config && !f.IsDir() {
-			project.ConfigPath = project.ProjectPath + "/" + f.Name()
+			project.ConfigPath = path
 		} else if f.Name() == Packages && f.IsDir() {
-			project.Packages = project.ProjectPath + "/" + f.Name()
+			project.Packages = path
 		} else if f.Name() == ".nimbella" || f.Name() == ".deployed" {
 			// Ignore
 		} else if f.Name() == ".env" && !f.IsDir() {
-			project.Env = project.ProjectPath + "/" + f.Name()
+			project.Env = path
 		} else {
-			project.Strays = append(project.Strays, project.ProjectPath+"/"+f.Name())
+			project.Strays = append(project.Strays, path)
 		}
 	}
 	return nil
@@ -1293,7 +1434,7 @@ func isServerlessConnected(credsDir string) bool {
 // serverlessUptodate answers whether the installed version of the serverless support is at least
 // what is required by doctl
 func serverlessUptodate(serverlessDir string) bool {
-	return GetCurrentServerlessVersion(serverlessDir) >= GetMinServerlessVersion()
+	return versionAtLeast(GetCurrentServerlessVersion(serverlessDir), GetMinServerlessVersion())
 }
 
 // GetCurrentServerlessVersion gets the version of the current plugin.
@@ -1325,6 +1466,29 @@ func GetCredentialDirectory(leafDir string, serverlessDir string) string {
 	return filepath.Join(serverlessDir, credsDir, leafDir)
 }
 
+// nodeBinary returns the file name of the node executable on the current platform.
+func nodeBinary() string {
+	if runtime.GOOS == "windows" {
+		return "node.exe"
+	}
+	return "node"
+}
+
+// findNode locates the node executable used to run the plugin.  The copy installed into
+// the serverless directory is preferred.  If that is absent (e.g. a package manager installed
+// doctl without the bundled node), a node found on the PATH is used instead.  On Windows,
+// exec.LookPath takes care of PATHEXT resolution so "node" finds "node.exe".
+func findNode(serverlessDir string) string {
+	bundled := filepath.Join(serverlessDir, nodeBinary())
+	if _, err := os.Stat(bundled); err == nil {
+		return bundled
+	}
+	if onPath, err := exec.LookPath("node"); err == nil {
+		return onPath
+	}
+	return bundled
+}
+
 // Gets the version of the node binary in the serverless.  Determine if it is
 // usable or whether it has to be upgraded.
 func canReuseNode(serverlessDir string, nodeBin string) bool {
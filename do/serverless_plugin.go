@@ -0,0 +1,266 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package do
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/digitalocean/doctl"
+)
+
+const (
+	// checksumsFile is the file in the serverless directory holding the SHA-256 checksums of the plugin and node
+	// executable, recorded when they are installed.
+	checksumsFile = "SHA256SUMS"
+
+	// minNodeMajorVersion is the oldest major version of nodejs the plugin runs on.
+	minNodeMajorVersion = 18
+)
+
+// versionAtLeast reports whether version is at least min. Versions that don't parse are treated as 0.0.0.
+func versionAtLeast(version, min string) bool {
+	v, err := semver.ParseTolerant(strings.TrimSpace(version))
+	if err != nil {
+		v = semver.Version{}
+	}
+	m, err := semver.ParseTolerant(strings.TrimSpace(min))
+	if err != nil {
+		return true
+	}
+	return v.GTE(m)
+}
+
+// verifyPlugin checks that the plugin and the node executable that runs it are present and usable before running
+// them. The node executable must be executable and recent enough, and the files must match the checksums recorded
+// when they were installed, if any were.
+func (s *serverlessService) verifyPlugin() error {
+	if s.pluginVerified {
+		return nil
+	}
+
+	if _, err := os.Stat(s.serverlessJs); err != nil {
+		return fmt.Errorf("%w: %s is missing", ErrServerlessNotInstalled, s.serverlessJs)
+	}
+	if version := GetCurrentServerlessVersion(s.serverlessDir); !versionAtLeast(version, GetMinServerlessVersion()) {
+		return fmt.Errorf("%w: version %s is installed but doctl requires %s or later", ErrServerlessNeedsUpgrade, strings.TrimSpace(version), GetMinServerlessVersion())
+	}
+
+	info, err := os.Stat(s.node)
+	if err != nil {
+		return fmt.Errorf("the node executable %s is missing; reinstall it with `doctl serverless upgrade`, or install nodejs %d or later on your PATH", s.node, minNodeMajorVersion)
+	}
+	if runtime.GOOS != "windows" && info.Mode()&0111 == 0 {
+		return fmt.Errorf("the node executable %s is not executable; run `chmod +x %s` or reinstall it with `doctl serverless upgrade`", s.node, s.node)
+	}
+	out, err := exec.Command(s.node, "--version").Output()
+	if err != nil {
+		return fmt.Errorf("the node executable %s does not run (%v); reinstall it with `doctl serverless upgrade`", s.node, err)
+	}
+	version := strings.TrimSpace(string(out))
+	if v, err := semver.ParseTolerant(version); err != nil || v.Major < minNodeMajorVersion {
+		return fmt.Errorf("the node executable %s is version %s but serverless support requires nodejs %d or later; reinstall it with `doctl serverless upgrade`", s.node, version, minNodeMajorVersion)
+	}
+
+	if err := verifyChecksums(s.serverlessDir); err != nil {
+		return err
+	}
+
+	s.pluginVerified = true
+	return nil
+}
+
+// pluginEnvNames are the variables of doctl's environment passed on to the plugin, in upper case. They are what node
+// and the builds the plugin runs need to find programs, write temporary files, and reach the network.
+var pluginEnvNames = map[string]bool{
+	"PATH": true, "PATHEXT": true, "HOME": true, "USER": true, "LOGNAME": true, "SHELL": true, "TERM": true,
+	"TMPDIR": true, "TEMP": true, "TMP": true, "LANG": true, "TZ": true, "DEBUG": true,
+	"SYSTEMROOT": true, "COMSPEC": true, "USERPROFILE": true, "APPDATA": true, "LOCALAPPDATA": true, "PROGRAMDATA": true,
+	"HTTP_PROXY": true, "HTTPS_PROXY": true, "NO_PROXY": true,
+	"SSL_CERT_FILE": true, "SSL_CERT_DIR": true, "NODE_EXTRA_CA_CERTS": true,
+}
+
+// pluginEnvPrefixes are the prefixes, in upper case, of other variables passed on to the plugin: locale settings and
+// the settings of the package managers and toolchains used by local builds.
+var pluginEnvPrefixes = []string{"LC_", "NPM_CONFIG_", "YARN_", "GO", "PIP_", "XDG_"}
+
+// pluginEnv returns the environment the plugin runs in: the variables of environ it needs, and the ones that point it
+// at doctl's credentials and configuration. Other variables, including any NIM_ and NIMBELLA_ settings of an
+// interactive nim install, are left out so that deploys don't depend on them.
+func (s *serverlessService) pluginEnv(environ []string) []string {
+	var env []string
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		upper := strings.ToUpper(name)
+		keep := pluginEnvNames[upper]
+		for _, prefix := range pluginEnvPrefixes {
+			keep = keep || strings.HasPrefix(upper, prefix)
+		}
+		if keep {
+			env = append(env, kv)
+		}
+	}
+	return append(env,
+		"NIMBELLA_DIR="+s.credsDir,
+		"NIM_CONFIG_DIR="+filepath.Join(s.credsDir, "nim"),
+		"NIM_USER_AGENT="+s.userAgent,
+		"DO_API_KEY="+s.accessToken,
+	)
+}
+
+// runPlugin runs cmd, stopping it and the processes it started when the timeout, if any, expires or doctl is
+// interrupted. The plugin runs in its own process group so that the node processes it spawns for builds are stopped
+// with it.
+func (s *serverlessService) runPlugin(cmd *exec.Cmd, timeout time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	setProcessGroup(cmd)
+	// Don't wait forever for output from processes that survive being stopped.
+	cmd.WaitDelay = 5 * time.Second
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		killProcessGroup(cmd)
+		<-done
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("serverless operation stopped after %s (raise --%s to allow more time): %w", timeout, doctl.ArgServerlessPluginTimeout, ctx.Err())
+		}
+		return fmt.Errorf("serverless operation interrupted: %w", ctx.Err())
+	}
+}
+
+// isStopped reports whether runPlugin stopped the plugin.
+func isStopped(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+// ServerlessPluginError is an error reported by the serverless plugin. Errors of known kinds wrap ErrServerlessAuth,
+// ErrServerlessQuota, or ErrServerlessProjectConfig.
+type ServerlessPluginError struct {
+	// Message is the error reported by the plugin.
+	Message string
+	// Kind is the error the message was recognized as, if any.
+	Kind error
+}
+
+func (e *ServerlessPluginError) Error() string { return e.Message }
+func (e *ServerlessPluginError) Unwrap() error { return e.Kind }
+
+// pluginErrorPatterns recognize the kinds of errors reported by the plugin, in the order they are tried.
+var pluginErrorPatterns = []struct {
+	re   *regexp.Regexp
+	kind error
+}{
+	{regexp.MustCompile(`(?i)yamlexception|project\.yml|project configuration|duplicated mapping key|bad indentation`), ErrServerlessProjectConfig},
+	{regexp.MustCompile(`(?i)too many (concurrent )?requests|quota|exceeds? (the |allowed )?limit|\b429\b`), ErrServerlessQuota},
+	{regexp.MustCompile(`(?i)supplied authentication is (invalid|not authorized)|unauthorized|forbidden|invalid (auth|api) key|\b40[13]\b`), ErrServerlessAuth},
+}
+
+// pluginError returns the error for a message reported by the plugin.
+func pluginError(msg string) error {
+	for _, p := range pluginErrorPatterns {
+		if p.re.MatchString(msg) {
+			return &ServerlessPluginError{Message: msg, Kind: p.kind}
+		}
+	}
+	return &ServerlessPluginError{Message: msg}
+}
+
+// recordChecksums writes the checksums of the installed plugin and node executable to the serverless directory.
+func recordChecksums(serverlessDir string) error {
+	var b strings.Builder
+	for _, name := range []string{"sandbox.js", nodeBinary()} {
+		sum, err := fileChecksum(filepath.Join(serverlessDir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "%s  %s\n", sum, name)
+	}
+	return os.WriteFile(filepath.Join(serverlessDir, checksumsFile), []byte(b.String()), 0644)
+}
+
+// verifyChecksums checks the files listed in the checksums file of the serverless directory. Installs that predate
+// the checksums file are not checked.
+func verifyChecksums(serverlessDir string) error {
+	f, err := os.Open(filepath.Join(serverlessDir, checksumsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		want, name, ok := strings.Cut(scanner.Text(), "  ")
+		if !ok {
+			continue
+		}
+		path := filepath.Join(serverlessDir, name)
+		got, err := fileChecksum(path)
+		if err != nil {
+			return fmt.Errorf("checking %s: %w; reinstall serverless support with `doctl serverless upgrade`", path, err)
+		}
+		if got != want {
+			return fmt.Errorf("%s has changed since it was installed (its checksum doesn't match); reinstall serverless support with `doctl serverless upgrade`", path)
+		}
+	}
+	return scanner.Err()
+}
+
+// fileChecksum returns the hex-encoded SHA-256 checksum of a file.
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package do
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/doctl"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionAtLeast(t *testing.T) {
+	assert.True(t, versionAtLeast("5.0.19", "5.0.19"))
+	assert.True(t, versionAtLeast("5.1.0\n", "5.0.19"))
+	assert.False(t, versionAtLeast("5.0.9", "5.0.19"))
+	assert.False(t, versionAtLeast("0", "5.0.19"))
+}
+
+// fakeServerless installs a plugin and a node executable reporting nodeVersion in a temporary directory.
+func fakeServerless(t *testing.T, nodeVersion string) *serverlessService {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake node executable is a shell script")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sandbox.js"), []byte("// plugin\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "version"), []byte(minServerlessVersion), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "node"), []byte("#!/bin/sh\necho "+nodeVersion+"\n"), 0755))
+	return &serverlessService{
+		serverlessDir: dir,
+		serverlessJs:  filepath.Join(dir, "sandbox.js"),
+		node:          filepath.Join(dir, "node"),
+	}
+}
+
+func TestVerifyPlugin(t *testing.T) {
+	t.Run("passes", func(t *testing.T) {
+		s := fakeServerless(t, "v18.17.1")
+		require.NoError(t, recordChecksums(s.serverlessDir))
+		require.NoError(t, s.verifyPlugin())
+		assert.True(t, s.pluginVerified)
+	})
+
+	t.Run("missing plugin", func(t *testing.T) {
+		s := fakeServerless(t, "v18.17.1")
+		require.NoError(t, os.Remove(s.serverlessJs))
+		assert.ErrorIs(t, s.verifyPlugin(), ErrServerlessNotInstalled)
+	})
+
+	t.Run("outdated plugin", func(t *testing.T) {
+		s := fakeServerless(t, "v18.17.1")
+		require.NoError(t, os.WriteFile(filepath.Join(s.serverlessDir, "version"), []byte("5.0.9"), 0644))
+		assert.ErrorIs(t, s.verifyPlugin(), ErrServerlessNeedsUpgrade)
+	})
+
+	t.Run("node not executable", func(t *testing.T) {
+		s := fakeServerless(t, "v18.17.1")
+		require.NoError(t, os.Chmod(s.node, 0644))
+		assert.ErrorContains(t, s.verifyPlugin(), "is not executable")
+	})
+
+	t.Run("old node", func(t *testing.T) {
+		s := fakeServerless(t, "v16.20.0")
+		assert.ErrorContains(t, s.verifyPlugin(), "is version v16.20.0 but serverless support requires nodejs 18 or later")
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		s := fakeServerless(t, "v18.17.1")
+		require.NoError(t, recordChecksums(s.serverlessDir))
+		require.NoError(t, os.WriteFile(s.serverlessJs, []byte("// tampered\n"), 0644))
+		assert.ErrorContains(t, s.verifyPlugin(), "has changed since it was installed")
+	})
+}
+
+func TestCmdReadOnly(t *testing.T) {
+	s := fakeServerless(t, "v18.17.1")
+	require.NoError(t, recordChecksums(s.serverlessDir))
+	viper.Set(doctl.ArgReadOnly, true)
+	defer viper.Set(doctl.ArgReadOnly, false)
+
+	_, err := s.Cmd("deploy", []string{"project"})
+	var roErr *doctl.ReadOnlyErr
+	assert.ErrorAs(t, err, &roErr)
+	_, err = s.Cmd("nocapture", []string{"watch", "project"})
+	assert.ErrorContains(t, err, "refusing to run the serverless plugin's watch command")
+
+	_, err = s.Cmd("get-metadata", []string{"project"})
+	assert.NoError(t, err)
+}
+
+func TestExecTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the plugin is faked with a shell command")
+	}
+	s := &serverlessService{timeout: 200 * time.Millisecond}
+
+	start := time.Now()
+	output, err := s.Exec(exec.Command("sh", "-c", "echo building; sleep 30 & wait"))
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "output so far:\nbuilding")
+	assert.Equal(t, []string{"building"}, output.Captured)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestExecWithinTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the plugin is faked with a shell command")
+	}
+	s := &serverlessService{timeout: 10 * time.Second}
+
+	output, err := s.Exec(exec.Command("sh", "-c", `echo '{"captured": ["done"]}'; exit 1`))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"done"}, output.Captured)
+}
+
+func TestPluginError(t *testing.T) {
+	tests := []struct {
+		msg  string
+		kind error
+	}{
+		{"The supplied authentication is invalid", ErrServerlessAuth},
+		{"The supplied authentication is not authorized to access 'fn-123'.", ErrServerlessAuth},
+		{"Too many requests in the last minute (count: 601, allowed: 600).", ErrServerlessQuota},
+		{"Too many concurrent requests in flight (count: 121, allowed: 120).", ErrServerlessQuota},
+		{"Error reading project.yml: YAMLException: bad indentation of a mapping entry (4:7)", ErrServerlessProjectConfig},
+		{"Invalid project configuration: 'packages' must be an array", ErrServerlessProjectConfig},
+		{"The requested resource does not exist.", nil},
+	}
+	for _, tt := range tests {
+		err := pluginError(tt.msg)
+		assert.EqualError(t, err, tt.msg)
+		if tt.kind == nil {
+			for _, kind := range []error{ErrServerlessAuth, ErrServerlessQuota, ErrServerlessProjectConfig} {
+				assert.NotErrorIs(t, err, kind, tt.msg)
+			}
+			continue
+		}
+		assert.ErrorIs(t, err, tt.kind, tt.msg)
+	}
+}
+
+func TestExecPluginError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the plugin is faked with a shell command")
+	}
+	s := &serverlessService{}
+
+	output, err := s.Exec(exec.Command("sh", "-c", `echo '{"error": "The supplied authentication is invalid", "captured": ["Deploying project"]}'; exit 1`))
+
+	assert.ErrorIs(t, err, ErrServerlessAuth)
+	assert.Equal(t, []string{"Deploying project"}, output.Captured)
+}
+
+func TestPluginEnv(t *testing.T) {
+	s := &serverlessService{credsDir: filepath.Join("config", "sandbox", "creds", "abcd"), userAgent: "doctl/1.0 serverless/5.0.19", accessToken: "dop_v1_token"}
+
+	env := s.pluginEnv([]string{
+		"PATH=/usr/bin",
+		"HOME=/home/sammy",
+		"https_proxy=http://proxy:3128",
+		"LC_ALL=C.UTF-8",
+		"npm_config_registry=https://registry.example.com",
+		"GOPROXY=https://proxy.golang.org",
+		"AWS_SECRET_ACCESS_KEY=secret",
+		"NIMBELLA_DIR=/home/sammy/.nimbella",
+		"NIM_USER_AGENT=nim",
+		"DIGITALOCEAN_ACCESS_TOKEN=dop_v1_other",
+	})
+
+	assert.Equal(t, []string{
+		"PATH=/usr/bin",
+		"HOME=/home/sammy",
+		"https_proxy=http://proxy:3128",
+		"LC_ALL=C.UTF-8",
+		"npm_config_registry=https://registry.example.com",
+		"GOPROXY=https://proxy.golang.org",
+		"NIMBELLA_DIR=" + s.credsDir,
+		"NIM_CONFIG_DIR=" + filepath.Join(s.credsDir, "nim"),
+		"NIM_USER_AGENT=doctl/1.0 serverless/5.0.19",
+		"DO_API_KEY=dop_v1_token",
+	}, env)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package do
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTopLevelUsesNativePaths(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "project.yml"), []byte("packages: []\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "packages"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte(""), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte(""), 0644))
+
+	project := &ServerlessProject{ProjectPath: dir}
+	require.NoError(t, readTopLevel(project))
+
+	assert.Equal(t, filepath.Join(dir, "project.yml"), project.ConfigPath)
+	assert.Equal(t, filepath.Join(dir, "packages"), project.Packages)
+	assert.Equal(t, filepath.Join(dir, ".env"), project.Env)
+	assert.Equal(t, []string{filepath.Join(dir, "README.md")}, project.Strays)
+}
+
+func TestFindNodePrefersBundledBinary(t *testing.T) {
+	dir := t.TempDir()
+	bundled := filepath.Join(dir, nodeBinary())
+	require.NoError(t, os.WriteFile(bundled, []byte(""), 0755))
+
+	assert.Equal(t, bundled, findNode(dir))
+}
+
+func TestFindNodeFallsBackToPath(t *testing.T) {
+	serverlessDir := t.TempDir()
+	binDir := t.TempDir()
+	onPath := filepath.Join(binDir, nodeBinary())
+	require.NoError(t, os.WriteFile(onPath, []byte(""), 0755))
+	t.Setenv("PATH", binDir)
+
+	assert.Equal(t, onPath, findNode(serverlessDir))
+}
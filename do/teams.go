@@ -0,0 +1,156 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package do
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/digitalocean/godo"
+)
+
+// teamMembersPath is the members of the team the API token belongs to.
+const teamMembersPath = "v2/team/members"
+
+// TeamMember is a member of a team, or an invitation to join it that has not
+// been accepted yet.
+type TeamMember struct {
+	UUID      string    `json:"uuid"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TeamInviteRequest describes an invitation to join a team.
+type TeamInviteRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type teamMemberRoot struct {
+	Member *TeamMember `json:"member"`
+}
+
+type teamMembersRoot struct {
+	Members []TeamMember `json:"members"`
+	Links   *godo.Links  `json:"links"`
+	Meta    *godo.Meta   `json:"meta"`
+}
+
+// TeamsService is an interface for managing the members of the team an API
+// token belongs to.
+type TeamsService interface {
+	ListMembers() ([]TeamMember, error)
+	InviteMember(*TeamInviteRequest) (*TeamMember, error)
+	RemoveMember(uuid string) error
+	SetMemberRole(uuid string, role string) (*TeamMember, error)
+}
+
+type teamsService struct {
+	client *godo.Client
+}
+
+var _ TeamsService = &teamsService{}
+
+// NewTeamsService builds a TeamsService instance.
+func NewTeamsService(godoClient *godo.Client) TeamsService {
+	return &teamsService{
+		client: godoClient,
+	}
+}
+
+func (ts *teamsService) ListMembers() ([]TeamMember, error) {
+	f := func(opt *godo.ListOptions) ([]any, *godo.Response, error) {
+		ctx := context.TODO()
+		path := fmt.Sprintf("%s?page=%d&per_page=%d", teamMembersPath, opt.Page, opt.PerPage)
+		req, err := ts.client.NewRequest(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		root := new(teamMembersRoot)
+		resp, err := ts.client.Do(ctx, req, root)
+		if err != nil {
+			return nil, nil, err
+		}
+		resp.Links, resp.Meta = root.Links, root.Meta
+
+		si := make([]any, len(root.Members))
+		for i := range root.Members {
+			si[i] = root.Members[i]
+		}
+		return si, resp, nil
+	}
+
+	si, err := PaginateResp(f)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]TeamMember, len(si))
+	for i := range si {
+		list[i] = si[i].(TeamMember)
+	}
+	return list, nil
+}
+
+func (ts *teamsService) InviteMember(tir *TeamInviteRequest) (*TeamMember, error) {
+	ctx := context.TODO()
+	req, err := ts.client.NewRequest(ctx, http.MethodPost, teamMembersPath, tir)
+	if err != nil {
+		return nil, err
+	}
+
+	root := new(teamMemberRoot)
+	_, err = ts.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, err
+	}
+
+	return root.Member, nil
+}
+
+func (ts *teamsService) RemoveMember(uuid string) error {
+	ctx := context.TODO()
+	req, err := ts.client.NewRequest(ctx, http.MethodDelete, teamMembersPath+"/"+uuid, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = ts.client.Do(ctx, req, nil)
+	return err
+}
+
+func (ts *teamsService) SetMemberRole(uuid string, role string) (*TeamMember, error) {
+	ctx := context.TODO()
+	body := struct {
+		Role string `json:"role"`
+	}{Role: role}
+	req, err := ts.client.NewRequest(ctx, http.MethodPatch, teamMembersPath+"/"+uuid, body)
+	if err != nil {
+		return nil, err
+	}
+
+	root := new(teamMemberRoot)
+	_, err = ts.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, err
+	}
+
+	return root.Member, nil
+}
@@ -74,6 +74,10 @@ var (
 	// version of doctl, ie, the "dev" in v1.0.0-dev.
 	Label string
 
+	// ReleaseSigningKey is set at build time. It is the base64-encoded ed25519
+	// public key that the checksums of doctl releases are signed with.
+	ReleaseSigningKey string
+
 	// DoitVersion is doctl's version.
 	DoitVersion Version
 
@@ -210,6 +214,7 @@ type Config interface {
 	GetGodoClient(trace, allowRetries bool, accessToken string) (*godo.Client, error)
 	GetDockerEngineClient() (builder.DockerEngineClient, error)
 	SSH(user, host, keyPath string, port int, opts ssh.Options) runner.Runner
+	SCP(user, host, keyPath string, port int, opts ssh.Options) runner.Runner
 	Listen(url *url.URL, token string, schemaFunc listen.SchemaFunc, out io.Writer) listen.ListenerService
 	Set(ns, key string, val any)
 	IsSet(key string) bool
@@ -233,12 +238,24 @@ var _ Config = &LiveConfig{}
 
 // GetGodoClient returns a GodoClient.
 func (c *LiveConfig) GetGodoClient(trace, allowRetries bool, accessToken string) (*godo.Client, error) {
+	replayDir := viper.GetString(ArgReplay)
+	recordDir := viper.GetString(ArgRecord)
+	if replayDir != "" && recordDir != "" {
+		return nil, fmt.Errorf("--%s and --%s cannot be used together", ArgRecord, ArgReplay)
+	}
+	if accessToken == "" && replayDir != "" {
+		// Replayed calls never reach the API, so any token will do.
+		accessToken = redacted
+	}
+
 	if accessToken == "" {
-		return nil, fmt.Errorf("access token is required. (hint: run 'doctl auth init')")
+		return nil, ErrMissingAccessToken
 	}
 
+	transport := sharedTransport(viper.GetInt(ArgHTTPMaxIdleConns), viper.GetInt(ArgHTTPMaxConnsPerHost))
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: transport})
 	tokenSource := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken})
-	oauthClient := oauth2.NewClient(context.Background(), tokenSource)
+	oauthClient := oauth2.NewClient(ctx, tokenSource)
 
 	args := []godo.ClientOpt{
 		godo.SetUserAgent(userAgent()),
@@ -278,6 +295,19 @@ func (c *LiveConfig) GetGodoClient(trace, allowRetries bool, accessToken string)
 	if err != nil {
 		return nil, err
 	}
+	useTransport(client.HTTPClient, transport)
+	honorNoRetries(client.HTTPClient)
+
+	if viper.GetBool(ArgSummary) {
+		instrumentClient(client.HTTPClient, Stats)
+	}
+
+	switch {
+	case replayDir != "":
+		client.HTTPClient.Transport = newReplayTransport(replayDir)
+	case recordDir != "":
+		client.HTTPClient.Transport = newRecordTransport(recordDir, accessToken, transportOrDefault(client.HTTPClient.Transport))
+	}
 
 	if trace {
 		r := newRecorder(client.HTTPClient.Transport)
@@ -296,6 +326,9 @@ func (c *LiveConfig) GetGodoClient(trace, allowRetries bool, accessToken string)
 		client.HTTPClient.Transport = r
 	}
 
+	// Installed last, so rejected calls are neither traced nor counted.
+	client.HTTPClient = ReadOnlyClient(client.HTTPClient)
+
 	return client, nil
 }
 
@@ -314,7 +347,7 @@ func userAgent() string {
 
 // SSH creates a ssh connection to a host.
 func (c *LiveConfig) SSH(user, host, keyPath string, port int, opts ssh.Options) runner.Runner {
-	return &ssh.Runner{
+	r := &ssh.Runner{
 		User:            user,
 		Host:            host,
 		KeyPath:         keyPath,
@@ -323,6 +356,27 @@ func (c *LiveConfig) SSH(user, host, keyPath string, port int, opts ssh.Options)
 		Command:         opts[ArgSSHCommand].(string),
 		RetriesMax:      opts[ArgSSHRetryMax].(int),
 	}
+	r.Stdin, _ = opts[ArgSSHStdin].(io.Reader)
+	r.Stdout, _ = opts[ArgSSHStdout].(io.Writer)
+	r.Stderr, _ = opts[ArgSSHStderr].(io.Writer)
+	return r
+}
+
+// SCP creates a runner copying files to or from a host.
+func (c *LiveConfig) SCP(user, host, keyPath string, port int, opts ssh.Options) runner.Runner {
+	r := &ssh.SCPRunner{
+		User:       user,
+		Host:       host,
+		KeyPath:    keyPath,
+		Port:       port,
+		Upload:     opts[ArgSCPUpload].(bool),
+		LocalPaths: opts[ArgSCPLocalPaths].([]string),
+		RemotePath: opts[ArgSCPRemotePath].(string),
+		Recursive:  opts[ArgSCPRecursive].(bool),
+	}
+	r.Stdout, _ = opts[ArgSSHStdout].(io.Writer)
+	r.Stderr, _ = opts[ArgSSHStderr].(io.Writer)
+	return r
 }
 
 // Listen creates a websocket connection
@@ -483,6 +537,7 @@ func isRequired(key string) bool {
 // TestConfig is an implementation of Config for testing.
 type TestConfig struct {
 	SSHFn              func(user, host, keyPath string, port int, opts ssh.Options) runner.Runner
+	SCPFn              func(user, host, keyPath string, port int, opts ssh.Options) runner.Runner
 	ListenFn           func(url *url.URL, token string, schemaFunc listen.SchemaFunc, out io.Writer) listen.ListenerService
 	v                  *viper.Viper
 	IsSetMap           map[string]bool
@@ -497,6 +552,9 @@ func NewTestConfig() *TestConfig {
 		SSHFn: func(u, h, kp string, p int, opts ssh.Options) runner.Runner {
 			return &MockRunner{}
 		},
+		SCPFn: func(u, h, kp string, p int, opts ssh.Options) runner.Runner {
+			return &MockRunner{}
+		},
 		ListenFn: func(url *url.URL, token string, schemaFunc listen.SchemaFunc, out io.Writer) listen.ListenerService {
 			return &MockListener{}
 		},
@@ -522,6 +580,11 @@ func (c *TestConfig) SSH(user, host, keyPath string, port int, opts ssh.Options)
 	return c.SSHFn(user, host, keyPath, port, opts)
 }
 
+// SCP returns a mock SCP runner.
+func (c *TestConfig) SCP(user, host, keyPath string, port int, opts ssh.Options) runner.Runner {
+	return c.SCPFn(user, host, keyPath, port, opts)
+}
+
 // Listen returns a mock websocket listener
 func (c *TestConfig) Listen(url *url.URL, token string, schemaFunc listen.SchemaFunc, out io.Writer) listen.ListenerService {
 	return c.ListenFn(url, token, schemaFunc, out)
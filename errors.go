@@ -13,7 +13,13 @@ limitations under the License.
 
 package doctl
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrMissingAccessToken is returned when no access token has been configured.
+var ErrMissingAccessToken = errors.New("access token is required. (hint: run 'doctl auth init')")
 
 // MissingArgsErr is returned when there are too few arguments for a command.
 type MissingArgsErr struct {
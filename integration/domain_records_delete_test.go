@@ -32,6 +32,12 @@ var _ = suite("compute/domain/records/delete", func(t *testing.T, when spec.G, i
 					return
 				}
 
+				// The record is fetched first for the DNS journal.
+				if req.Method == http.MethodGet {
+					w.Write([]byte(`{"domain_record": {"id": 1337, "type": "A", "name": "www", "data": "192.0.2.1", "ttl": 1800}}`))
+					return
+				}
+
 				if req.Method != http.MethodDelete {
 					w.WriteHeader(http.StatusMethodNotAllowed)
 					return
@@ -50,6 +56,12 @@ var _ = suite("compute/domain/records/delete", func(t *testing.T, when spec.G, i
 					return
 				}
 
+				// The record is fetched first for the DNS journal.
+				if req.Method == http.MethodGet {
+					w.Write([]byte(`{"domain_record": {"id": 7331, "type": "A", "name": "www", "data": "192.0.2.1", "ttl": 1800}}`))
+					return
+				}
+
 				if req.Method != http.MethodDelete {
 					w.WriteHeader(http.StatusMethodNotAllowed)
 					return
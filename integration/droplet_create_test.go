@@ -62,6 +62,9 @@ var _ = suite("compute/droplet/create", func(t *testing.T, when spec.G, it spec.
 				}
 
 				w.Write([]byte(dropletCreateResponse))
+			case "/v2/vpcs/00000000-0000-4000-8000-000000000000":
+				// Looked up to check that the VPC is in the Droplet's region.
+				w.Write([]byte(`{"vpc": {"id": "00000000-0000-4000-8000-000000000000", "region": "a-test-region"}}`))
 			case "/poll-for-droplet":
 				w.Write([]byte(actionCompletedResponse))
 			case "/v2/droplets/777":
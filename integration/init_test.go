@@ -21,7 +21,7 @@ var (
 )
 
 const (
-	confirmNonInteractiveOutput = "Warning: Requires confirmation. Use the `--force` flag to continue without confirmation.\nError: Operation aborted."
+	confirmNonInteractiveOutput = "Warning: Requires confirmation. Use the `--force` (or `--yes`) flag to continue without confirmation.\nError: Operation aborted."
 )
 
 func TestRun(t *testing.T) {
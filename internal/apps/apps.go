@@ -12,6 +12,21 @@ import (
 )
 
 func ReadAppSpec(stdin io.Reader, path string) (*godo.AppSpec, error) {
+	byt, err := readAppSpecBytes(stdin, path)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := ParseAppSpec(byt)
+	if err != nil {
+		return nil, fmt.Errorf("parsing app spec: %w", err)
+	}
+
+	return s, nil
+}
+
+// readAppSpecBytes reads an app spec from path, or from stdin if path is "-".
+func readAppSpecBytes(stdin io.Reader, path string) ([]byte, error) {
 	var spec io.Reader
 	if path == "-" && stdin != nil {
 		spec = stdin
@@ -31,13 +46,7 @@ func ReadAppSpec(stdin io.Reader, path string) (*godo.AppSpec, error) {
 	if err != nil {
 		return nil, fmt.Errorf("reading app spec: %w", err)
 	}
-
-	s, err := ParseAppSpec(byt)
-	if err != nil {
-		return nil, fmt.Errorf("parsing app spec: %w", err)
-	}
-
-	return s, nil
+	return byt, nil
 }
 
 func ParseAppSpec(spec []byte) (*godo.AppSpec, error) {
@@ -0,0 +1,84 @@
+package apps
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/digitalocean/godo"
+	"sigs.k8s.io/yaml"
+)
+
+// specVariable matches a ${NAME} reference in an app spec, or its escaped form $${NAME}.
+// Names containing dots, such as ${db.DATABASE_URL}, are never matched because they refer
+// to App Platform's bindable variables.
+var specVariable = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// InterpolateAppSpec replaces each ${NAME} reference in an app spec with the value of NAME in
+// vars. References to names that are not in vars are left alone, since App Platform resolves
+// references to app-level environment variables and to APP_URL, APP_DOMAIN, and APP_ID itself.
+// $${NAME} is replaced by a literal ${NAME}.
+func InterpolateAppSpec(spec []byte, vars map[string]string) []byte {
+	return specVariable.ReplaceAllFunc(spec, func(ref []byte) []byte {
+		if ref[1] == '$' {
+			return ref[1:]
+		}
+		name := string(ref[2 : len(ref)-1])
+		if value, ok := vars[name]; ok {
+			return []byte(value)
+		}
+		return ref
+	})
+}
+
+// ReadAppSpecValues reads a values file, a YAML or JSON object whose members are the values of
+// app spec variables.
+func ReadAppSpecValues(path string) (map[string]string, error) {
+	byt, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading values file: %w", err)
+	}
+	var raw map[string]any
+	useNumber := func(d *json.Decoder) *json.Decoder {
+		d.UseNumber()
+		return d
+	}
+	if err := yaml.Unmarshal(byt, &raw, useNumber); err != nil {
+		return nil, fmt.Errorf("parsing values file %s: %w", path, err)
+	}
+	values := make(map[string]string, len(raw))
+	for name, value := range raw {
+		switch v := value.(type) {
+		case string:
+			values[name] = v
+		case json.Number, bool:
+			values[name] = fmt.Sprint(v)
+		case nil:
+			values[name] = ""
+		default:
+			return nil, fmt.Errorf("parsing values file %s: the value of %s is not a string, number, or boolean", path, name)
+		}
+	}
+	return values, nil
+}
+
+// ReadAppSpecWithVars reads an app spec like ReadAppSpec, interpolating vars into it before it
+// is parsed. A nil vars disables interpolation, leaving $${NAME} as is.
+func ReadAppSpecWithVars(stdin io.Reader, path string, vars map[string]string) (*godo.AppSpec, error) {
+	byt, err := readAppSpecBytes(stdin, path)
+	if err != nil {
+		return nil, err
+	}
+	if vars != nil {
+		byt = InterpolateAppSpec(byt, vars)
+	}
+
+	s, err := ParseAppSpec(byt)
+	if err != nil {
+		return nil, fmt.Errorf("parsing app spec: %w", err)
+	}
+
+	return s, nil
+}
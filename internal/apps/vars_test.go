@@ -0,0 +1,90 @@
+package apps
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolateAppSpec(t *testing.T) {
+	vars := map[string]string{
+		"NAME":   "test",
+		"BRANCH": "main",
+	}
+
+	tcs := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{
+			name: "replaces set variables",
+			spec: "name: ${NAME}\nbranch: ${BRANCH}",
+			want: "name: test\nbranch: main",
+		},
+		{
+			name: "leaves unset variables",
+			spec: "value: ${APP_URL}/api",
+			want: "value: ${APP_URL}/api",
+		},
+		{
+			name: "leaves bindable variables",
+			spec: "value: ${db.DATABASE_URL}",
+			want: "value: ${db.DATABASE_URL}",
+		},
+		{
+			name: "unescapes escaped references",
+			spec: "value: $${NAME}",
+			want: "value: ${NAME}",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, string(InterpolateAppSpec([]byte(tc.spec), vars)))
+		})
+	}
+}
+
+func TestReadAppSpecValues(t *testing.T) {
+	t.Run("scalars", func(t *testing.T) {
+		path := testTempFile(t, []byte("NAME: test\nINSTANCES: 2\nDEBUG: true\nEMPTY:\n"))
+		values, err := ReadAppSpecValues(path)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"NAME":      "test",
+			"INSTANCES": "2",
+			"DEBUG":     "true",
+			"EMPTY":     "",
+		}, values)
+	})
+
+	t.Run("nested value", func(t *testing.T) {
+		path := testTempFile(t, []byte(`{"NAME": {"first": "test"}}`))
+		_, err := ReadAppSpecValues(path)
+		require.ErrorContains(t, err, "the value of NAME is not a string, number, or boolean")
+	})
+}
+
+func TestReadAppSpecWithVars(t *testing.T) {
+	spec := "name: ${NAME}\nservices:\n- name: web\n  github:\n    repo: digitalocean/sample-golang\n    branch: ${BRANCH}\n"
+
+	got, err := ReadAppSpecWithVars(bytes.NewBufferString(spec), "-", map[string]string{
+		"NAME":   "test",
+		"BRANCH": "main",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &godo.AppSpec{
+		Name: "test",
+		Services: []*godo.AppServiceSpec{{
+			Name: "web",
+			GitHub: &godo.GitHubSourceSpec{
+				Repo:   "digitalocean/sample-golang",
+				Branch: "main",
+			},
+		}},
+	}, got)
+}
@@ -144,6 +144,11 @@ type AppDevConfig struct {
 	NoCache         bool
 	CNBBuilderImage string
 
+	// ServerlessURLPath is appended to the serverless namespace URL injected into static sites.
+	ServerlessURLPath string
+	// ServerlessURLTrailingSlash ensures the injected serverless URL ends with a slash.
+	ServerlessURLTrailingSlash bool
+
 	// Components contains component-specific configuration keyed by component name.
 	Components map[string]*AppDevConfigComponent
 
@@ -157,6 +162,8 @@ type AppDevConfigComponent struct {
 	EnvFile      string
 	Envs         map[string]string
 	BuildCommand string
+	// NoServerlessURL leaves SERVERLESS_URL out of the component's envs.
+	NoServerlessURL bool
 }
 
 // NewAppDevConfig populates an AppDevConfig instance with values sourced from *config.AppDev and doctl.Config.
@@ -201,6 +208,8 @@ func (c *AppDevConfig) Load() error {
 	c.Registry = ws.GetString(doctl.ArgRegistry)
 	c.NoCache = ws.GetBool(doctl.ArgNoCache)
 	c.CNBBuilderImage = ws.GetString("cnb_builder_image")
+	c.ServerlessURLPath = ws.GetString(doctl.ArgServerlessURLPath)
+	c.ServerlessURLTrailingSlash = ws.GetBool(doctl.ArgServerlessURLTrailingSlash)
 
 	err := c.loadAppSpec()
 	if err != nil {
@@ -214,8 +223,9 @@ func (c *AppDevConfig) Load() error {
 		// componentWS - component config w/ workspace and CLI overrides
 		component, componentWS := c.component(name, true)
 		cc := &AppDevConfigComponent{
-			Spec:         spec,
-			BuildCommand: component.GetString(doctl.ArgBuildCommand),
+			Spec:            spec,
+			BuildCommand:    component.GetString(doctl.ArgBuildCommand),
+			NoServerlessURL: component.GetBool(doctl.ArgNoServerlessURL),
 		}
 		cc.LoadEnvFile(componentWS.GetString(doctl.ArgEnvFile))
 
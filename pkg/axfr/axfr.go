@@ -0,0 +1,439 @@
+// Package axfr transfers DNS zones from their nameservers with AXFR (RFC
+// 5936), optionally authenticating the transfer with TSIG (RFC 8945).
+package axfr
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record types and classes used by zone transfers.
+const (
+	TypeA      = 1
+	TypeNS     = 2
+	TypeCNAME  = 5
+	TypeSOA    = 6
+	TypePTR    = 12
+	TypeMX     = 15
+	TypeTXT    = 16
+	TypeAAAA   = 28
+	TypeSRV    = 33
+	TypeSPF    = 99
+	TypeTSIG   = 250
+	TypeAXFR   = 252
+	TypeCAA    = 257
+	ClassIN    = 1
+	classANY   = 255
+	headerSize = 12
+)
+
+// typeNames are the names of the record types a zone commonly holds.
+var typeNames = map[uint16]string{
+	TypeA: "A", TypeNS: "NS", TypeCNAME: "CNAME", TypeSOA: "SOA", TypePTR: "PTR",
+	TypeMX: "MX", TypeTXT: "TXT", 17: "RP", 18: "AFSDB", 29: "LOC", TypeAAAA: "AAAA",
+	TypeSRV: "SRV", 35: "NAPTR", 39: "DNAME", 43: "DS", 44: "SSHFP", 46: "RRSIG",
+	47: "NSEC", 48: "DNSKEY", 50: "NSEC3", 51: "NSEC3PARAM", 52: "TLSA", 53: "SMIMEA",
+	59: "CDS", 60: "CDNSKEY", 61: "OPENPGPKEY", 64: "SVCB", 65: "HTTPS", TypeSPF: "SPF",
+	108: "EUI48", 109: "EUI64", 256: "URI", TypeCAA: "CAA",
+}
+
+// TypeName returns the name of a record type, or TYPE followed by its number
+// for types without one (RFC 3597).
+func TypeName(t uint16) string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return "TYPE" + strconv.Itoa(int(t))
+}
+
+// rcodeNames are the names of the response codes a transfer can fail with.
+var rcodeNames = map[int]string{
+	1: "FORMERR", 2: "SERVFAIL", 3: "NXDOMAIN", 4: "NOTIMP", 5: "REFUSED", 9: "NOTAUTH",
+}
+
+// Record is a resource record of a transferred zone. The fields other than
+// Name, Type, Class, and TTL are decoded from the record data of the types
+// that have them; for other types, only Raw is set.
+type Record struct {
+	// Name is the owner name of the record, in lower case, with a trailing dot.
+	Name  string
+	Type  uint16
+	Class uint16
+	TTL   uint32
+	// Data is the address of A and AAAA records; the host name, with a
+	// trailing dot, of NS, CNAME, PTR, MX, and SRV records and the primary
+	// nameserver of SOA records; the concatenated strings of TXT and SPF
+	// records; and the value of CAA records.
+	Data string
+	// Strings are the character strings of TXT and SPF records.
+	Strings []string
+	// Priority is the preference of MX records and the priority of SRV records.
+	Priority int
+	Weight   int
+	Port     int
+	// Flags and Tag are the flags and property tag of CAA records.
+	Flags int
+	Tag   string
+	// Raw is the undecoded record data.
+	Raw []byte
+}
+
+// Options configure a zone transfer.
+type Options struct {
+	// Key signs the request and verifies the responses, if set.
+	Key *TSIGKey
+	// Timeout limits the whole transfer. It defaults to one minute.
+	Timeout time.Duration
+}
+
+// Transfer requests the zone from the nameserver at server, which is a host
+// with an optional port, and returns its records. The zone's SOA record is
+// first, and is not repeated at the end.
+func Transfer(ctx context.Context, server, zone string, opts Options) ([]Record, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(strings.Trim(server, "[]"), "53")
+	}
+	zone = canonicalName(zone)
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	id := uint16(rand.Intn(1 << 16))
+	query := buildQuery(id, zone)
+	var v *verifier
+	if opts.Key != nil {
+		var mac []byte
+		query, mac, err = opts.Key.sign(query, now())
+		if err != nil {
+			return nil, err
+		}
+		v = &verifier{key: opts.Key, priorMAC: mac}
+	}
+	if err := writeMessage(conn, query); err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	soas := 0
+	for first := true; soas < 2; first = false {
+		msg, err := readMessage(conn)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("%s closed the connection before the end of the transfer", server)
+			}
+			return nil, err
+		}
+		m, err := parseMessage(msg)
+		if err != nil {
+			return nil, err
+		}
+		if m.id != id {
+			return nil, fmt.Errorf("response ID %d does not match query ID %d", m.id, id)
+		}
+		// Errors are reported even if unsigned, since servers don't sign
+		// the errors of requests signed with keys they don't know.
+		if rcode := int(m.flags & 0xf); rcode != 0 {
+			if err := tsigError(m.tsig); err != nil {
+				return nil, err
+			}
+			return nil, rcodeError(rcode, zone, server)
+		}
+		if v != nil {
+			if err := v.verify(msg, m, first); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, rr := range m.answers {
+			if first && len(records) == 0 && rr.Type != TypeSOA {
+				return nil, fmt.Errorf("the transfer of %s does not start with an SOA record", strings.TrimSuffix(zone, "."))
+			}
+			if rr.Type == TypeSOA {
+				soas++
+				if soas == 2 {
+					break
+				}
+			}
+			records = append(records, rr)
+		}
+		if first && len(records) == 0 {
+			return nil, fmt.Errorf("%s returned no records for %s", server, strings.TrimSuffix(zone, "."))
+		}
+	}
+	if v != nil && v.unsigned > 0 {
+		return nil, errors.New("the last message of the transfer is not signed")
+	}
+	return records, nil
+}
+
+// rcodeError explains the error response code of a transfer.
+func rcodeError(rcode int, zone, server string) error {
+	zone = strings.TrimSuffix(zone, ".")
+	name, ok := rcodeNames[rcode]
+	if !ok {
+		name = "RCODE" + strconv.Itoa(rcode)
+	}
+	switch rcode {
+	case 5:
+		return fmt.Errorf("%s refused the transfer of %s (%s); it must allow transfers to this address, or with a TSIG key", server, zone, name)
+	case 9:
+		return fmt.Errorf("%s is not authoritative for %s or rejected the TSIG key (%s)", server, zone, name)
+	}
+	return fmt.Errorf("%s could not transfer %s (%s)", server, zone, name)
+}
+
+// now returns the current time. It is replaced for testing.
+var now = time.Now
+
+// canonicalName returns name in lower case with a trailing dot.
+func canonicalName(name string) string {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
+
+// appendName appends the uncompressed wire form of a domain name.
+func appendName(b []byte, name string) []byte {
+	name = strings.TrimSuffix(name, ".")
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			b = append(b, byte(len(label)))
+			b = append(b, label...)
+		}
+	}
+	return append(b, 0)
+}
+
+// buildQuery returns an AXFR query for zone.
+func buildQuery(id uint16, zone string) []byte {
+	b := make([]byte, headerSize)
+	binary.BigEndian.PutUint16(b[0:], id)
+	binary.BigEndian.PutUint16(b[4:], 1)
+	b = appendName(b, zone)
+	b = binary.BigEndian.AppendUint16(b, TypeAXFR)
+	return binary.BigEndian.AppendUint16(b, ClassIN)
+}
+
+// writeMessage writes a message with the two byte length prefix of DNS over TCP.
+func writeMessage(w io.Writer, msg []byte) error {
+	if len(msg) > 0xffff {
+		return fmt.Errorf("message of %d bytes is too long", len(msg))
+	}
+	_, err := w.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(msg))), msg...))
+	return err
+}
+
+// readMessage reads a message written by writeMessage.
+func readMessage(r io.Reader) ([]byte, error) {
+	var size [2]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(size[:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return msg, nil
+}
+
+// message is a parsed response.
+type message struct {
+	id, flags uint16
+	answers   []Record
+	// tsig is the TSIG record at the end of the additional section, if any,
+	// and tsigStart is its offset in the message.
+	tsig      *Record
+	tsigStart int
+}
+
+var errTruncated = errors.New("malformed DNS message: truncated")
+
+// parseMessage parses the header, answers, and TSIG record of a response.
+func parseMessage(msg []byte) (*message, error) {
+	if len(msg) < headerSize {
+		return nil, errTruncated
+	}
+	m := &message{
+		id:    binary.BigEndian.Uint16(msg[0:]),
+		flags: binary.BigEndian.Uint16(msg[2:]),
+	}
+	qd := int(binary.BigEndian.Uint16(msg[4:]))
+	an := int(binary.BigEndian.Uint16(msg[6:]))
+	ns := int(binary.BigEndian.Uint16(msg[8:]))
+	ar := int(binary.BigEndian.Uint16(msg[10:]))
+
+	off := headerSize
+	for i := 0; i < qd; i++ {
+		_, n, err := readName(msg, off)
+		if err != nil {
+			return nil, err
+		}
+		off = n + 4
+		if off > len(msg) {
+			return nil, errTruncated
+		}
+	}
+	for i := 0; i < an+ns+ar; i++ {
+		start := off
+		rr, n, err := readRecord(msg, off)
+		if err != nil {
+			return nil, err
+		}
+		off = n
+		switch {
+		case i < an:
+			m.answers = append(m.answers, rr)
+		case rr.Type == TypeTSIG:
+			if i != an+ns+ar-1 {
+				return nil, errors.New("malformed DNS message: TSIG record is not the last record")
+			}
+			m.tsig = &rr
+			m.tsigStart = start
+		}
+	}
+	return m, nil
+}
+
+// readName reads the possibly compressed domain name at off, and returns it
+// in lower case with a trailing dot, and the offset following it.
+func readName(msg []byte, off int) (string, int, error) {
+	var b strings.Builder
+	end := -1
+	for jumps := 0; ; {
+		if off >= len(msg) {
+			return "", 0, errTruncated
+		}
+		n := int(msg[off])
+		switch {
+		case n == 0:
+			if end < 0 {
+				end = off + 1
+			}
+			if b.Len() == 0 {
+				return ".", end, nil
+			}
+			return strings.ToLower(b.String()), end, nil
+		case n&0xc0 == 0xc0:
+			if off+1 >= len(msg) {
+				return "", 0, errTruncated
+			}
+			if end < 0 {
+				end = off + 2
+			}
+			if jumps++; jumps > 64 {
+				return "", 0, errors.New("malformed DNS message: compression loop")
+			}
+			off = int(binary.BigEndian.Uint16(msg[off:]) & 0x3fff)
+		case n&0xc0 != 0:
+			return "", 0, errors.New("malformed DNS message: unsupported label type")
+		default:
+			if off+1+n > len(msg) {
+				return "", 0, errTruncated
+			}
+			b.Write(msg[off+1 : off+1+n])
+			b.WriteByte('.')
+			off += 1 + n
+		}
+	}
+}
+
+// readRecord reads the resource record at off and decodes its data.
+func readRecord(msg []byte, off int) (Record, int, error) {
+	name, off, err := readName(msg, off)
+	if err != nil {
+		return Record{}, 0, err
+	}
+	if off+10 > len(msg) {
+		return Record{}, 0, errTruncated
+	}
+	rr := Record{
+		Name:  name,
+		Type:  binary.BigEndian.Uint16(msg[off:]),
+		Class: binary.BigEndian.Uint16(msg[off+2:]),
+		TTL:   binary.BigEndian.Uint32(msg[off+4:]),
+	}
+	size := int(binary.BigEndian.Uint16(msg[off+8:]))
+	off += 10
+	if off+size > len(msg) {
+		return Record{}, 0, errTruncated
+	}
+	rr.Raw = msg[off : off+size]
+	if err := decodeData(&rr, msg, off); err != nil {
+		return Record{}, 0, fmt.Errorf("malformed %s record %s: %w", TypeName(rr.Type), rr.Name, err)
+	}
+	return rr, off + size, nil
+}
+
+// decodeData sets the fields of rr from its data, which starts at off in msg
+// so that compressed names can be read.
+func decodeData(rr *Record, msg []byte, off int) error {
+	data := rr.Raw
+	var err error
+	switch rr.Type {
+	case TypeA, TypeAAAA:
+		if (rr.Type == TypeA && len(data) != net.IPv4len) || (rr.Type == TypeAAAA && len(data) != net.IPv6len) {
+			return errTruncated
+		}
+		rr.Data = net.IP(data).String()
+	case TypeNS, TypeCNAME, TypePTR, TypeSOA:
+		rr.Data, _, err = readName(msg, off)
+	case TypeMX:
+		if len(data) < 3 {
+			return errTruncated
+		}
+		rr.Priority = int(binary.BigEndian.Uint16(data))
+		rr.Data, _, err = readName(msg, off+2)
+	case TypeSRV:
+		if len(data) < 7 {
+			return errTruncated
+		}
+		rr.Priority = int(binary.BigEndian.Uint16(data))
+		rr.Weight = int(binary.BigEndian.Uint16(data[2:]))
+		rr.Port = int(binary.BigEndian.Uint16(data[4:]))
+		rr.Data, _, err = readName(msg, off+6)
+	case TypeTXT, TypeSPF:
+		for i := 0; i < len(data); {
+			n := int(data[i])
+			if i+1+n > len(data) {
+				return errTruncated
+			}
+			rr.Strings = append(rr.Strings, string(data[i+1:i+1+n]))
+			i += 1 + n
+		}
+		rr.Data = strings.Join(rr.Strings, "")
+	case TypeCAA:
+		if len(data) < 2 || len(data) < 2+int(data[1]) {
+			return errTruncated
+		}
+		rr.Flags = int(data[0])
+		rr.Tag = string(data[2 : 2+int(data[1])])
+		rr.Data = string(data[2+int(data[1]):])
+	}
+	return err
+}
@@ -0,0 +1,237 @@
+package axfr
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = &TSIGKey{Name: "transfer.", Algorithm: "hmac-sha256", Secret: []byte("0123456789abcdef")}
+
+// testRR is a record served by the test server; data is its record data.
+type testRR struct {
+	name string
+	typ  uint16
+	ttl  uint32
+	data []byte
+}
+
+// appendRR appends a record to a message, compressing its name to point at
+// the question when it is the zone itself.
+func appendRR(b []byte, rr testRR) []byte {
+	if rr.name == "example.com." {
+		b = append(b, 0xc0, headerSize)
+	} else {
+		b = appendName(b, rr.name)
+	}
+	b = binary.BigEndian.AppendUint16(b, rr.typ)
+	b = binary.BigEndian.AppendUint16(b, ClassIN)
+	b = binary.BigEndian.AppendUint32(b, rr.ttl)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(rr.data)))
+	return append(b, rr.data...)
+}
+
+func buildResponse(id uint16, rcode uint16, rrs ...testRR) []byte {
+	b := make([]byte, headerSize)
+	binary.BigEndian.PutUint16(b[0:], id)
+	binary.BigEndian.PutUint16(b[2:], 0x8400|rcode)
+	binary.BigEndian.PutUint16(b[4:], 1)
+	binary.BigEndian.PutUint16(b[6:], uint16(len(rrs)))
+	b = appendName(b, "example.com.")
+	b = binary.BigEndian.AppendUint16(b, TypeAXFR)
+	b = binary.BigEndian.AppendUint16(b, ClassIN)
+	for _, rr := range rrs {
+		b = appendRR(b, rr)
+	}
+	return b
+}
+
+// signResponse signs a response the way a server does: the first with the
+// request MAC and the TSIG variables, the others with the previous MAC and
+// the timers.
+func signResponse(key *TSIGKey, msg, priorMAC []byte, first bool) ([]byte, []byte) {
+	signed := uint64(now().Unix())
+	data := binary.BigEndian.AppendUint16(nil, uint16(len(priorMAC)))
+	data = append(data, priorMAC...)
+	data = append(data, msg...)
+	if first {
+		data = key.appendVariables(data, signed, defaultFudge, 0, nil)
+	} else {
+		data = appendTimers(data, signed, defaultFudge)
+	}
+	mac := key.mac(data)
+
+	var rdata []byte
+	rdata = appendName(rdata, algorithms[key.Algorithm].wireName)
+	rdata = appendTimers(rdata, signed, defaultFudge)
+	rdata = binary.BigEndian.AppendUint16(rdata, uint16(len(mac)))
+	rdata = append(rdata, mac...)
+	rdata = append(rdata, msg[0:2]...)
+	rdata = append(rdata, 0, 0, 0, 0)
+
+	out := append([]byte{}, msg...)
+	binary.BigEndian.PutUint16(out[10:], 1)
+	out = appendName(out, key.Name)
+	out = binary.BigEndian.AppendUint16(out, TypeTSIG)
+	out = binary.BigEndian.AppendUint16(out, classANY)
+	out = binary.BigEndian.AppendUint32(out, 0)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(rdata)))
+	return append(out, rdata...), mac
+}
+
+var (
+	soaData = append(appendName(appendName(nil, "ns1.example.com."), "hostmaster.example.com."),
+		0, 0, 0, 1, 0, 0, 0x1c, 0x20, 0, 0, 0x0e, 0x10, 0, 0x12, 0x75, 0, 0, 0, 1, 0x2c)
+	testZone = [][]testRR{
+		{
+			{"example.com.", TypeSOA, 3600, soaData},
+			{"example.com.", TypeNS, 3600, appendName(nil, "ns1.example.com.")},
+			{"www.example.com.", TypeA, 300, []byte{192, 0, 2, 1}},
+			{"example.com.", TypeMX, 3600, append([]byte{0, 10}, appendName(nil, "mail.example.com.")...)},
+		},
+		{
+			{"example.com.", TypeTXT, 300, []byte("\x0ev=spf1 mx -all\x03abc")},
+			{"_sip._tcp.example.com.", TypeSRV, 300, append([]byte{0, 10, 0, 5, 0x13, 0xc4}, appendName(nil, "sip.example.com.")...)},
+			{"example.com.", TypeCAA, 300, append([]byte{0, 5}, "issueletsencrypt.org"...)},
+			{"example.com.", 52, 300, []byte{3, 1, 1, 0xab}},
+			{"example.com.", TypeSOA, 3600, soaData},
+		},
+	}
+)
+
+// serve starts a server that answers one transfer request with the messages
+// of zone, signed with key if it isn't nil, or with rcode if it isn't zero.
+func serve(t *testing.T, key *TSIGKey, rcode uint16, zone [][]testRR) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		query, err := readMessage(conn)
+		if err != nil {
+			return
+		}
+		id := binary.BigEndian.Uint16(query)
+		if rcode != 0 {
+			writeMessage(conn, buildResponse(id, rcode))
+			return
+		}
+
+		var mac []byte
+		if key != nil {
+			m, err := parseMessage(query)
+			if err != nil || m.tsig == nil {
+				return
+			}
+			q, err := parseTSIG(m.tsig)
+			if err != nil {
+				return
+			}
+			mac = q.mac
+		}
+		for i, rrs := range zone {
+			msg := buildResponse(id, 0, rrs...)
+			if key != nil {
+				msg, mac = signResponse(key, msg, mac, i == 0)
+			}
+			writeMessage(conn, msg)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestTransfer(t *testing.T) {
+	server := serve(t, nil, 0, testZone)
+
+	records, err := Transfer(context.Background(), server, "Example.com", Options{Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	require.Len(t, records, 8)
+
+	assert.Equal(t, "example.com.", records[0].Name)
+	assert.Equal(t, uint16(TypeSOA), records[0].Type)
+	assert.Equal(t, "ns1.example.com.", records[1].Data)
+	assert.Equal(t, "www.example.com.", records[2].Name)
+	assert.Equal(t, "192.0.2.1", records[2].Data)
+	assert.Equal(t, 10, records[3].Priority)
+	assert.Equal(t, "mail.example.com.", records[3].Data)
+	assert.Equal(t, []string{"v=spf1 mx -all", "abc"}, records[4].Strings)
+	assert.Equal(t, "v=spf1 mx -allabc", records[4].Data)
+	assert.Equal(t, Record{Name: "_sip._tcp.example.com.", Type: TypeSRV, Class: ClassIN, TTL: 300,
+		Data: "sip.example.com.", Priority: 10, Weight: 5, Port: 5060, Raw: records[5].Raw}, records[5])
+	assert.Equal(t, "issue", records[6].Tag)
+	assert.Equal(t, "letsencrypt.org", records[6].Data)
+	assert.Equal(t, "TLSA", TypeName(records[7].Type))
+	assert.Equal(t, []byte{3, 1, 1, 0xab}, records[7].Raw)
+}
+
+func TestTransferTSIG(t *testing.T) {
+	server := serve(t, testKey, 0, testZone)
+
+	records, err := Transfer(context.Background(), server, "example.com", Options{Key: testKey, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	assert.Len(t, records, 8)
+}
+
+func TestTransferTSIGWrongSecret(t *testing.T) {
+	server := serve(t, testKey, 0, testZone)
+
+	key := *testKey
+	key.Secret = []byte("not the secret")
+	_, err := Transfer(context.Background(), server, "example.com", Options{Key: &key, Timeout: 5 * time.Second})
+	assert.ErrorContains(t, err, "TSIG signature of the response is not valid")
+}
+
+func TestTransferTSIGUnsigned(t *testing.T) {
+	server := serve(t, nil, 0, testZone)
+
+	_, err := Transfer(context.Background(), server, "example.com", Options{Key: testKey, Timeout: 5 * time.Second})
+	assert.ErrorContains(t, err, "not signed")
+}
+
+func TestTransferRefused(t *testing.T) {
+	server := serve(t, nil, 5, nil)
+
+	_, err := Transfer(context.Background(), server, "example.com", Options{Timeout: 5 * time.Second})
+	assert.ErrorContains(t, err, "refused the transfer of example.com ")
+}
+
+func TestTransferIncomplete(t *testing.T) {
+	server := serve(t, nil, 0, testZone[:1])
+
+	_, err := Transfer(context.Background(), server, "example.com", Options{Timeout: 5 * time.Second})
+	assert.ErrorContains(t, err, "before the end of the transfer")
+}
+
+func TestReadNameCompressionLoop(t *testing.T) {
+	msg := make([]byte, headerSize)
+	msg = append(msg, 0xc0, headerSize)
+
+	_, _, err := readName(msg, headerSize)
+	assert.ErrorContains(t, err, "compression loop")
+}
+
+func TestParseTSIGKey(t *testing.T) {
+	key, err := ParseTSIGKey("transfer:MDEyMzQ1Njc4OWFiY2RlZg==")
+	require.NoError(t, err)
+	assert.Equal(t, testKey, key)
+
+	key, err = ParseTSIGKey("HMAC-MD5.SIG-ALG.REG.INT:Transfer.Example.:MDEyMzQ1Njc4OWFiY2RlZg==")
+	require.NoError(t, err)
+	assert.Equal(t, "hmac-md5", key.Algorithm)
+	assert.Equal(t, "transfer.example.", key.Name)
+
+	for _, s := range []string{"transfer", "hmac-sha3:transfer:MDEy", ":MDEy", "transfer:not base64", "a:b:c:d"} {
+		_, err := ParseTSIGKey(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -0,0 +1,279 @@
+package axfr
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultAlgorithm is the TSIG algorithm of keys that don't name one.
+const DefaultAlgorithm = "hmac-sha256"
+
+// defaultFudge is the number of seconds the clocks of the client and server
+// may differ by.
+const defaultFudge = 300
+
+// maxUnsigned is the number of consecutive unsigned messages a signed
+// transfer may have.
+const maxUnsigned = 99
+
+// algorithms are the TSIG algorithms, by the name in their key files, with
+// the name used on the wire and their hash function.
+var algorithms = map[string]struct {
+	wireName string
+	hash     func() hash.Hash
+}{
+	"hmac-md5":    {"hmac-md5.sig-alg.reg.int.", md5.New},
+	"hmac-sha1":   {"hmac-sha1.", sha1.New},
+	"hmac-sha224": {"hmac-sha224.", sha256.New224},
+	"hmac-sha256": {"hmac-sha256.", sha256.New},
+	"hmac-sha384": {"hmac-sha384.", sha512.New384},
+	"hmac-sha512": {"hmac-sha512.", sha512.New},
+}
+
+// tsigErrors are the names of the TSIG error codes.
+var tsigErrors = map[int]string{16: "BADSIG", 17: "BADKEY", 18: "BADTIME", 22: "BADTRUNC"}
+
+// TSIGKey is a shared secret that authenticates a transfer.
+type TSIGKey struct {
+	// Name is the name of the key, which must match the name the server has
+	// for it.
+	Name string
+	// Algorithm is the name of the HMAC algorithm, such as hmac-sha256.
+	Algorithm string
+	Secret    []byte
+}
+
+// ParseTSIGKey parses a key in the form used by dig -y:
+// [algorithm:]name:secret, where the secret is base64 encoded.
+func ParseTSIGKey(s string) (*TSIGKey, error) {
+	parts := strings.Split(s, ":")
+	key := &TSIGKey{Algorithm: DefaultAlgorithm}
+	switch len(parts) {
+	case 2:
+	case 3:
+		key.Algorithm = strings.ToLower(parts[0])
+		parts = parts[1:]
+	default:
+		return nil, fmt.Errorf("invalid TSIG key %q; it must be [algorithm:]name:secret", s)
+	}
+	key.Algorithm = strings.TrimSuffix(strings.TrimSuffix(key.Algorithm, "."), ".sig-alg.reg.int")
+	if _, ok := algorithms[key.Algorithm]; !ok {
+		names := make([]string, 0, len(algorithms))
+		for name := range algorithms {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unsupported TSIG algorithm %q; supported algorithms are %s", key.Algorithm, strings.Join(names, ", "))
+	}
+	if parts[0] == "" {
+		return nil, errors.New("the TSIG key has no name")
+	}
+	key.Name = canonicalName(parts[0])
+	secret, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil || len(secret) == 0 {
+		return nil, errors.New("the TSIG key secret is not valid base64")
+	}
+	key.Secret = secret
+	return key, nil
+}
+
+// mac returns the HMAC of data with the key.
+func (k *TSIGKey) mac(data []byte) []byte {
+	h := hmac.New(algorithms[k.Algorithm].hash, k.Secret)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// appendVariables appends the TSIG variables that are signed along with the
+// first message of a request or response (RFC 8945, section 4.3.3).
+func (k *TSIGKey) appendVariables(b []byte, signed uint64, fudge, errCode uint16, other []byte) []byte {
+	b = appendName(b, k.Name)
+	b = binary.BigEndian.AppendUint16(b, classANY)
+	b = binary.BigEndian.AppendUint32(b, 0)
+	b = appendName(b, algorithms[k.Algorithm].wireName)
+	b = appendTimers(b, signed, fudge)
+	b = binary.BigEndian.AppendUint16(b, errCode)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(other)))
+	return append(b, other...)
+}
+
+// appendTimers appends the time signed, as 48 bits, and the fudge.
+func appendTimers(b []byte, signed uint64, fudge uint16) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(signed>>32))
+	b = binary.BigEndian.AppendUint32(b, uint32(signed))
+	return binary.BigEndian.AppendUint16(b, fudge)
+}
+
+// sign appends a TSIG record to msg, and returns the signed message and its
+// MAC.
+func (k *TSIGKey) sign(msg []byte, t time.Time) ([]byte, []byte, error) {
+	if len(msg) < headerSize {
+		return nil, nil, errTruncated
+	}
+	signed := uint64(t.Unix())
+	mac := k.mac(k.appendVariables(append([]byte{}, msg...), signed, defaultFudge, 0, nil))
+
+	var rdata []byte
+	rdata = appendName(rdata, algorithms[k.Algorithm].wireName)
+	rdata = appendTimers(rdata, signed, defaultFudge)
+	rdata = binary.BigEndian.AppendUint16(rdata, uint16(len(mac)))
+	rdata = append(rdata, mac...)
+	rdata = append(rdata, msg[0:2]...)
+	rdata = binary.BigEndian.AppendUint16(rdata, 0)
+	rdata = binary.BigEndian.AppendUint16(rdata, 0)
+
+	out := append([]byte{}, msg...)
+	binary.BigEndian.PutUint16(out[10:], binary.BigEndian.Uint16(out[10:])+1)
+	out = appendName(out, k.Name)
+	out = binary.BigEndian.AppendUint16(out, TypeTSIG)
+	out = binary.BigEndian.AppendUint16(out, classANY)
+	out = binary.BigEndian.AppendUint32(out, 0)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(rdata)))
+	return append(out, rdata...), mac, nil
+}
+
+// tsigData is the decoded data of a TSIG record.
+type tsigData struct {
+	algorithm  string
+	signed     uint64
+	fudge      uint16
+	mac        []byte
+	originalID uint16
+	errCode    uint16
+	other      []byte
+}
+
+// parseTSIG decodes the data of a TSIG record.
+func parseTSIG(rr *Record) (*tsigData, error) {
+	errMalformed := errors.New("malformed TSIG record")
+	alg, off, err := readName(rr.Raw, 0)
+	if err != nil {
+		return nil, errMalformed
+	}
+	b := rr.Raw[off:]
+	if len(b) < 10 {
+		return nil, errMalformed
+	}
+	t := &tsigData{
+		algorithm: alg,
+		signed:    uint64(binary.BigEndian.Uint16(b))<<32 | uint64(binary.BigEndian.Uint32(b[2:])),
+		fudge:     binary.BigEndian.Uint16(b[6:]),
+	}
+	size := int(binary.BigEndian.Uint16(b[8:]))
+	b = b[10:]
+	if len(b) < size+6 {
+		return nil, errMalformed
+	}
+	t.mac = b[:size]
+	t.originalID = binary.BigEndian.Uint16(b[size:])
+	t.errCode = binary.BigEndian.Uint16(b[size+2:])
+	other := int(binary.BigEndian.Uint16(b[size+4:]))
+	if len(b) != size+6+other {
+		return nil, errMalformed
+	}
+	t.other = b[size+6:]
+	return t, nil
+}
+
+// tsigError returns the error reported by a TSIG record, if it has one.
+func tsigError(rr *Record) error {
+	if rr == nil {
+		return nil
+	}
+	t, err := parseTSIG(rr)
+	if err != nil || t.errCode == 0 {
+		return nil
+	}
+	name, ok := tsigErrors[int(t.errCode)]
+	if !ok {
+		name = fmt.Sprintf("error %d", t.errCode)
+	}
+	switch t.errCode {
+	case 17:
+		return fmt.Errorf("the server does not know the TSIG key %s (%s)", strings.TrimSuffix(rr.Name, "."), name)
+	case 18:
+		return fmt.Errorf("the server rejected the TSIG signature because the clocks differ by more than %d seconds (%s)", defaultFudge, name)
+	}
+	return fmt.Errorf("the server rejected the TSIG signature; check the key's secret and algorithm (%s)", name)
+}
+
+// verifier checks the signatures of the messages of a transfer (RFC 8945,
+// section 5.3.1). Every message is signed along with the MAC of the previous
+// signed message, and the messages in between, if any, are not signed.
+type verifier struct {
+	key *TSIGKey
+	// priorMAC is the MAC of the request until the first response is
+	// verified, and then the MAC of the last signed response.
+	priorMAC []byte
+	// pending are the unsigned messages since the last signed one.
+	pending  []byte
+	unsigned int
+}
+
+// verify checks the signature of a message of the transfer, or records it to
+// be checked with the next signed one.
+func (v *verifier) verify(msg []byte, m *message, first bool) error {
+	if m.tsig == nil {
+		if first {
+			return errors.New("the response is not signed with the TSIG key")
+		}
+		if v.unsigned++; v.unsigned > maxUnsigned {
+			return fmt.Errorf("more than %d consecutive messages of the transfer are not signed", maxUnsigned)
+		}
+		v.pending = append(v.pending, msg...)
+		return nil
+	}
+
+	t, err := parseTSIG(m.tsig)
+	if err != nil {
+		return err
+	}
+	if m.tsig.Name != v.key.Name || t.algorithm != algorithms[v.key.Algorithm].wireName {
+		return fmt.Errorf("the response is signed with the key %s (%s), not %s", strings.TrimSuffix(m.tsig.Name, "."), strings.TrimSuffix(t.algorithm, "."), strings.TrimSuffix(v.key.Name, "."))
+	}
+	if err := tsigError(m.tsig); err != nil {
+		return err
+	}
+
+	// The message is signed as it was before the TSIG record was added.
+	stripped := append([]byte{}, msg[:m.tsigStart]...)
+	binary.BigEndian.PutUint16(stripped[0:], t.originalID)
+	binary.BigEndian.PutUint16(stripped[10:], binary.BigEndian.Uint16(stripped[10:])-1)
+
+	data := binary.BigEndian.AppendUint16(nil, uint16(len(v.priorMAC)))
+	data = append(data, v.priorMAC...)
+	data = append(data, v.pending...)
+	data = append(data, stripped...)
+	if first {
+		data = v.key.appendVariables(data, t.signed, t.fudge, t.errCode, t.other)
+	} else {
+		data = appendTimers(data, t.signed, t.fudge)
+	}
+	if !hmac.Equal(t.mac, v.key.mac(data)) {
+		return errors.New("the TSIG signature of the response is not valid; check the key's secret and algorithm")
+	}
+
+	skew := now().Unix() - int64(t.signed)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > int64(t.fudge) {
+		return fmt.Errorf("the response was signed %d seconds from the local time, more than the %d seconds allowed", skew, t.fudge)
+	}
+
+	v.priorMAC = t.mac
+	v.pending = nil
+	v.unsigned = 0
+	return nil
+}
@@ -0,0 +1,268 @@
+// Package bulk runs an operation on many items, saving its progress to a
+// checkpoint file after each one so that an interrupted run can be resumed
+// where it left off instead of starting over.
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrInterrupted is returned by Run when its context is done before every
+// item is.
+var ErrInterrupted = errors.New("the operation was interrupted")
+
+// Checkpoint is the progress of an operation, as it is saved.
+type Checkpoint struct {
+	// Operation describes the operation, such as the command and the
+	// arguments that aren't items. A checkpoint only resumes the same one.
+	Operation string `json:"operation"`
+	// Items are all the items of the operation, in order.
+	Items []string `json:"items"`
+	// Done are the results of the items that are done, such as the IDs of
+	// created resources, by item.
+	Done map[string]string `json:"done"`
+	// Failed are the errors of the items that failed, which are tried again
+	// when the operation is resumed.
+	Failed  map[string]string `json:"failed,omitempty"`
+	Updated time.Time         `json:"updated"`
+}
+
+// Executor runs an operation on items and checkpoints its progress.
+type Executor struct {
+	// Concurrency is the number of items, or batches of them, that are run
+	// at the same time. It is 1 if it is less.
+	Concurrency int
+
+	path string
+	mu   sync.Mutex
+	cp   Checkpoint
+}
+
+// New returns an executor for an operation on items that saves its
+// checkpoints to path, or doesn't save them if path is empty. Nothing is
+// saved until the first item is run. An item given more than once is run
+// once.
+func New(path, operation string, items []string) (*Executor, error) {
+	seen := make(map[string]bool, len(items))
+	unique := make([]string, 0, len(items))
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			unique = append(unique, item)
+		}
+	}
+	return &Executor{path: path, cp: Checkpoint{
+		Operation: operation,
+		Items:     unique,
+		Done:      map[string]string{},
+	}}, nil
+}
+
+// Resume returns an executor that continues the operation checkpointed to
+// path.
+func Resume(path, operation string) (*Executor, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading the checkpoint: %w", err)
+	}
+	e := &Executor{path: path}
+	if err := json.Unmarshal(b, &e.cp); err != nil {
+		return nil, fmt.Errorf("reading the checkpoint %s: %w", path, err)
+	}
+	if e.cp.Operation != operation {
+		return nil, fmt.Errorf("the checkpoint %s is of %q, not %q", path, e.cp.Operation, operation)
+	}
+	if e.cp.Done == nil {
+		e.cp.Done = map[string]string{}
+	}
+	return e, nil
+}
+
+// Path returns the path of the checkpoint file.
+func (e *Executor) Path() string {
+	return e.path
+}
+
+// Saved reports whether the checkpoint file exists.
+func (e *Executor) Saved() bool {
+	if e.path == "" {
+		return false
+	}
+	_, err := os.Stat(e.path)
+	return err == nil
+}
+
+// Items returns all the items of the operation.
+func (e *Executor) Items() []string {
+	return e.cp.Items
+}
+
+// Result returns the result of an item that is done.
+func (e *Executor) Result(item string) (string, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	r, ok := e.cp.Done[item]
+	return r, ok
+}
+
+// Remaining returns the items that aren't done, in order.
+func (e *Executor) Remaining() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var remaining []string
+	for _, item := range e.cp.Items {
+		if _, ok := e.cp.Done[item]; !ok {
+			remaining = append(remaining, item)
+		}
+	}
+	return remaining
+}
+
+// Run calls fn with each item that isn't done and records its result. An
+// item that fails doesn't stop the others; the errors are returned together.
+// If ctx is done first, the items that have started are finished and Run
+// returns ErrInterrupted.
+func (e *Executor) Run(ctx context.Context, fn func(item string) (string, error)) error {
+	return e.run(ctx, 1, func(items []string) ([]string, error) {
+		result, err := fn(items[0])
+		return []string{result}, err
+	})
+}
+
+// RunBatches is like Run, but calls fn with up to size items at a time, for
+// APIs that take many items in one request. The items of a batch succeed or
+// fail together and have no results.
+func (e *Executor) RunBatches(ctx context.Context, size int, fn func(items []string) error) error {
+	return e.run(ctx, size, func(items []string) ([]string, error) {
+		return make([]string, len(items)), fn(items)
+	})
+}
+
+func (e *Executor) run(ctx context.Context, size int, fn func(items []string) ([]string, error)) error {
+	if size < 1 {
+		size = 1
+	}
+	concurrency := e.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	remaining := e.Remaining()
+	var batches [][]string
+	for start := 0; start < len(remaining); start += size {
+		batches = append(batches, remaining[start:min(start+size, len(remaining))])
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		failures = map[string]error{}
+		sem      = make(chan struct{}, concurrency)
+	)
+	interrupted := false
+	for _, batch := range batches {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+		}
+		// The context is checked again in case it was done while waiting.
+		if ctx.Err() != nil {
+			interrupted = true
+			break
+		}
+
+		wg.Add(1)
+		go func(batch []string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results, err := fn(batch)
+			if err == nil {
+				err = e.record(batch, results, nil)
+			} else {
+				e.record(batch, nil, err)
+			}
+			if err != nil {
+				errMu.Lock()
+				failures[batch[0]] = err
+				errMu.Unlock()
+			}
+		}(batch)
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, batch := range batches {
+		if err, ok := failures[batch[0]]; ok {
+			errs = append(errs, err)
+		}
+	}
+	if interrupted {
+		errs = append(errs, ErrInterrupted)
+	}
+	return errors.Join(errs...)
+}
+
+// record records the results of a batch, or its failure, and saves the
+// checkpoint.
+func (e *Executor) record(batch, results []string, err error) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i, item := range batch {
+		if err != nil {
+			if e.cp.Failed == nil {
+				e.cp.Failed = map[string]string{}
+			}
+			e.cp.Failed[item] = err.Error()
+			continue
+		}
+		e.cp.Done[item] = results[i]
+		delete(e.cp.Failed, item)
+	}
+	if err := e.saveLocked(); err != nil {
+		return fmt.Errorf("saving the checkpoint: %w", err)
+	}
+	return nil
+}
+
+// saveLocked writes the checkpoint to a temporary file that replaces the
+// checkpoint file, so an interruption never leaves a partial checkpoint.
+func (e *Executor) saveLocked() error {
+	if e.path == "" {
+		return nil
+	}
+	e.cp.Updated = time.Now().UTC()
+	b, err := json.MarshalIndent(e.cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(e.path), 0700); err != nil {
+		return err
+	}
+	tmp := e.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, e.path)
+}
+
+// Remove removes the checkpoint file of an operation that is done.
+func (e *Executor) Remove() error {
+	if e.path == "" {
+		return nil
+	}
+	err := os.Remove(e.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunResumesAfterInterruption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	items := []string{"a", "b", "c", "d", "a"}
+
+	e, err := New(path, "delete", items)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, e.Items())
+	assert.False(t, e.Saved())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = e.Run(ctx, func(item string) (string, error) {
+		if item == "b" {
+			cancel()
+		}
+		return "id-" + item, nil
+	})
+	assert.ErrorIs(t, err, ErrInterrupted)
+	assert.True(t, e.Saved())
+
+	e, err = Resume(path, "delete")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "d"}, e.Remaining())
+
+	var ran []string
+	err = e.Run(context.Background(), func(item string) (string, error) {
+		ran = append(ran, item)
+		return "id-" + item, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "d"}, ran)
+	result, ok := e.Result("a")
+	assert.True(t, ok)
+	assert.Equal(t, "id-a", result)
+
+	require.NoError(t, e.Remove())
+	assert.False(t, e.Saved())
+}
+
+func TestRunRetriesFailures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	e, err := New(path, "create", []string{"a", "b", "c"})
+	require.NoError(t, err)
+	e.Concurrency = 3
+
+	var mu sync.Mutex
+	var ran []string
+	err = e.Run(context.Background(), func(item string) (string, error) {
+		mu.Lock()
+		ran = append(ran, item)
+		mu.Unlock()
+		if item != "b" {
+			return "", errors.New(item + " failed")
+		}
+		return "ok", nil
+	})
+	assert.EqualError(t, err, "a failed\nc failed")
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, ran)
+
+	e, err = Resume(path, "create")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, e.Remaining())
+	assert.Equal(t, map[string]string{"a": "a failed", "c": "c failed"}, e.cp.Failed)
+}
+
+func TestRunBatches(t *testing.T) {
+	e, err := New("", "tag", []string{"a", "b", "c", "d", "e"})
+	require.NoError(t, err)
+
+	var batches [][]string
+	err = e.RunBatches(context.Background(), 2, func(items []string) error {
+		batches = append(batches, items)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, batches)
+	assert.Empty(t, e.Remaining())
+	assert.False(t, e.Saved())
+}
+
+func TestResumeOtherOperation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	e, err := New(path, "tag apply web", []string{"a", "b"})
+	require.NoError(t, err)
+	require.NoError(t, e.Run(context.Background(), func(string) (string, error) { return "", nil }))
+
+	_, err = Resume(path, "tag apply db")
+	assert.ErrorContains(t, err, `is of "tag apply web", not "tag apply db"`)
+
+	_, err = Resume(filepath.Join(t.TempDir(), "missing.json"), "tag apply web")
+	assert.ErrorContains(t, err, "reading the checkpoint")
+}
@@ -0,0 +1,429 @@
+// Package cacheproxy is a caching proxy for the read-only endpoints of the
+// DigitalOcean API. Many clients, such as parallel CI jobs, can share one
+// proxy so that repeated reads of the same resources don't each count toward
+// the account's rate limit.
+//
+// The proxy never adds credentials. Each client's Authorization is passed to
+// the API, and responses are only shared between requests with the same one.
+package cacheproxy
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StatsPath is the path of the proxy's statistics.
+const StatsPath = "/-/stats"
+
+// CacheHeader is the response header that says how the proxy answered a
+// request: HIT, MISS, REVALIDATED, STALE, or BYPASS.
+const CacheHeader = "X-Doctl-Cache"
+
+// The values of CacheHeader.
+const (
+	Hit         = "HIT"
+	Miss        = "MISS"
+	Revalidated = "REVALIDATED"
+	Stale       = "STALE"
+	Bypass      = "BYPASS"
+)
+
+// Options configure a Proxy.
+type Options struct {
+	// Upstream is the API the proxy forwards requests to.
+	Upstream *url.URL
+	// TTL is how long a response is served from the cache before it is
+	// revalidated with the API.
+	TTL time.Duration
+	// MaxEntries is the number of responses cached. The least recently used
+	// ones are evicted first.
+	MaxEntries int
+	// MaxBodySize is the size of the largest response that is cached.
+	MaxBodySize int64
+	// Reserve is the number of requests left in a token's rate limit below
+	// which stale responses are served instead of revalidated.
+	Reserve int
+	// Transport sends the requests to the API. It defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+// Stats count how the proxy answered requests.
+type Stats struct {
+	Hits        int64 `json:"hits"`
+	Misses      int64 `json:"misses"`
+	Revalidated int64 `json:"revalidated"`
+	Stale       int64 `json:"stale"`
+	Bypassed    int64 `json:"bypassed"`
+	Entries     int   `json:"entries"`
+}
+
+// entry is a cached response.
+type entry struct {
+	key    string
+	token  string
+	status int
+	header http.Header
+	body   []byte
+	etag   string
+	stored time.Time
+}
+
+// rateLimit is the state of a token's rate limit, from the API's headers.
+type rateLimit struct {
+	remaining int
+	reset     time.Time
+}
+
+// Proxy is a caching proxy for the API. It is an http.Handler.
+type Proxy struct {
+	opts    Options
+	reverse *httputil.ReverseProxy
+
+	mu       sync.Mutex
+	entries  map[string]*list.Element
+	lru      *list.List
+	inflight map[string]chan struct{}
+	limits   map[string]rateLimit
+	stats    Stats
+}
+
+// New returns a proxy of opts.Upstream.
+func New(opts Options) *Proxy {
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultTransport
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 10000
+	}
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = 10 << 20
+	}
+
+	p := &Proxy{
+		opts:     opts,
+		entries:  map[string]*list.Element{},
+		lru:      list.New(),
+		inflight: map[string]chan struct{}{},
+		limits:   map[string]rateLimit{},
+	}
+	p.reverse = &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(opts.Upstream)
+			r.Out.Host = opts.Upstream.Host
+		},
+		Transport: opts.Transport,
+		ModifyResponse: func(resp *http.Response) error {
+			p.observe(tokenOf(resp.Request), resp.Header)
+			resp.Header.Set(CacheHeader, Bypass)
+			return nil
+		},
+	}
+	return p
+}
+
+// Stats returns how the proxy has answered requests so far.
+func (p *Proxy) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.stats
+	s.Entries = p.lru.Len()
+	return s
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == StatsPath && r.Method == http.MethodGet {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p.Stats())
+		return
+	}
+
+	if r.Method != http.MethodGet || r.Header.Get("Range") != "" {
+		p.bypass(w, r)
+		if r.Method != http.MethodHead && r.Method != http.MethodOptions {
+			// A change may make any of the token's responses out of date.
+			p.invalidate(tokenOf(r))
+		}
+		return
+	}
+
+	token := tokenOf(r)
+	key := token + " " + r.URL.RequestURI()
+	for {
+		p.mu.Lock()
+		e := p.lookup(key)
+		if e != nil && time.Since(e.stored) < p.opts.TTL {
+			p.stats.Hits++
+			p.mu.Unlock()
+			p.serve(w, r, e, Hit)
+			return
+		}
+		if e != nil && p.limited(token) {
+			p.stats.Stale++
+			p.mu.Unlock()
+			p.serve(w, r, e, Stale)
+			return
+		}
+		// Only one request fetches a response; the same requests made
+		// meanwhile wait for it.
+		if wait, ok := p.inflight[key]; ok {
+			p.mu.Unlock()
+			<-wait
+			if p.cached(key) {
+				continue
+			}
+			p.bypass(w, r)
+			return
+		}
+		done := make(chan struct{})
+		p.inflight[key] = done
+		p.mu.Unlock()
+
+		p.fetch(w, r, key, token, e)
+
+		p.mu.Lock()
+		delete(p.inflight, key)
+		p.mu.Unlock()
+		close(done)
+		return
+	}
+}
+
+// fetch gets a response from the API, revalidating the stale entry e if
+// there is one, and caches it.
+func (p *Proxy) fetch(w http.ResponseWriter, r *http.Request, key, token string, e *entry) {
+	out := r.Clone(r.Context())
+	out.URL = p.upstreamURL(r.URL)
+	out.Host = p.opts.Upstream.Host
+	out.RequestURI = ""
+	removeHopHeaders(out.Header)
+	// The transport decompresses responses, so that they can be served to
+	// any client.
+	out.Header.Del("Accept-Encoding")
+	out.Header.Del("If-None-Match")
+	out.Header.Del("If-Modified-Since")
+	if e != nil && e.etag != "" {
+		out.Header.Set("If-None-Match", e.etag)
+	}
+
+	resp, err := p.opts.Transport.RoundTrip(out)
+	if err != nil {
+		if e != nil {
+			p.count(&p.stats.Stale)
+			p.serve(w, r, e, Stale)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	p.observe(token, resp.Header)
+
+	if e != nil {
+		switch {
+		case resp.StatusCode == http.StatusNotModified:
+			// Entries are never changed, since they may be being served.
+			renewed := *e
+			renewed.header = e.header.Clone()
+			copyRateLimitHeaders(renewed.header, resp.Header)
+			renewed.stored = time.Now()
+			p.mu.Lock()
+			p.store(&renewed)
+			p.stats.Revalidated++
+			p.mu.Unlock()
+			p.serve(w, r, &renewed, Revalidated)
+			return
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			p.count(&p.stats.Stale)
+			p.serve(w, r, e, Stale)
+			return
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, p.opts.MaxBodySize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	fresh := &entry{
+		key:    key,
+		token:  token,
+		status: resp.StatusCode,
+		header: resp.Header.Clone(),
+		body:   body,
+		etag:   resp.Header.Get("ETag"),
+		stored: time.Now(),
+	}
+	removeHopHeaders(fresh.header)
+	fresh.header.Del("Content-Length")
+
+	if int64(len(body)) > p.opts.MaxBodySize {
+		// Too large to cache, so the rest of the body is streamed.
+		p.count(&p.stats.Bypassed)
+		writeHeader(w, fresh.header, Bypass, resp.StatusCode)
+		w.Write(body)
+		io.Copy(w, resp.Body)
+		return
+	}
+
+	p.mu.Lock()
+	p.stats.Misses++
+	if resp.StatusCode == http.StatusOK && !strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		p.store(fresh)
+	} else {
+		p.remove(key)
+	}
+	p.mu.Unlock()
+	p.serve(w, r, fresh, Miss)
+}
+
+// serve writes a cached response, or Not Modified if the client has it.
+func (p *Proxy) serve(w http.ResponseWriter, r *http.Request, e *entry, how string) {
+	if e.etag != "" && r.Header.Get("If-None-Match") == e.etag {
+		writeHeader(w, e.header, how, http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Age", strconv.Itoa(int(time.Since(e.stored).Seconds())))
+	w.Header().Set("Content-Length", strconv.Itoa(len(e.body)))
+	writeHeader(w, e.header, how, e.status)
+	w.Write(e.body)
+}
+
+func writeHeader(w http.ResponseWriter, header http.Header, how string, status int) {
+	for k, v := range header {
+		w.Header()[k] = v
+	}
+	w.Header().Set(CacheHeader, how)
+	w.WriteHeader(status)
+}
+
+// bypass forwards a request that isn't cached.
+func (p *Proxy) bypass(w http.ResponseWriter, r *http.Request) {
+	p.count(&p.stats.Bypassed)
+	p.reverse.ServeHTTP(w, r)
+}
+
+func (p *Proxy) count(n *int64) {
+	p.mu.Lock()
+	*n++
+	p.mu.Unlock()
+}
+
+// lookup returns the entry of key, if there is one, as the most recently
+// used. p.mu must be held.
+func (p *Proxy) lookup(key string) *entry {
+	el, ok := p.entries[key]
+	if !ok {
+		return nil
+	}
+	p.lru.MoveToFront(el)
+	return el.Value.(*entry)
+}
+
+func (p *Proxy) cached(key string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.entries[key]
+	return ok
+}
+
+// store caches e, evicting the least recently used entry if the cache is
+// full. p.mu must be held.
+func (p *Proxy) store(e *entry) {
+	p.remove(e.key)
+	p.entries[e.key] = p.lru.PushFront(e)
+	for p.lru.Len() > p.opts.MaxEntries {
+		p.remove(p.lru.Back().Value.(*entry).key)
+	}
+}
+
+// remove removes the entry of key. p.mu must be held.
+func (p *Proxy) remove(key string) {
+	if el, ok := p.entries[key]; ok {
+		p.lru.Remove(el)
+		delete(p.entries, key)
+	}
+}
+
+// invalidate removes the entries of a token.
+func (p *Proxy) invalidate(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, el := range p.entries {
+		if el.Value.(*entry).token == token {
+			p.lru.Remove(el)
+			delete(p.entries, key)
+		}
+	}
+}
+
+// observe records the state of a token's rate limit from the headers of a
+// response.
+func (p *Proxy) observe(token string, h http.Header) {
+	remaining, err := strconv.Atoi(h.Get("RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	limit := rateLimit{remaining: remaining}
+	if reset, err := strconv.ParseInt(h.Get("RateLimit-Reset"), 10, 64); err == nil {
+		limit.reset = time.Unix(reset, 0)
+	}
+	p.mu.Lock()
+	p.limits[token] = limit
+	p.mu.Unlock()
+}
+
+// limited reports whether a token has too few requests left to spend them
+// on revalidating. p.mu must be held.
+func (p *Proxy) limited(token string) bool {
+	limit, ok := p.limits[token]
+	if !ok || limit.remaining > p.opts.Reserve {
+		return false
+	}
+	return limit.reset.IsZero() || time.Now().Before(limit.reset)
+}
+
+func (p *Proxy) upstreamURL(u *url.URL) *url.URL {
+	out := *p.opts.Upstream
+	out.Path = strings.TrimSuffix(out.Path, "/") + u.Path
+	out.RawPath = ""
+	out.RawQuery = u.RawQuery
+	return &out
+}
+
+// tokenOf identifies the credentials of a request without keeping them.
+func tokenOf(r *http.Request) string {
+	sum := sha256.Sum256([]byte(r.Header.Get("Authorization")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// hopHeaders are the headers of a connection rather than of a request or
+// response, which a proxy doesn't forward.
+var hopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization",
+	"Proxy-Connection", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+func removeHopHeaders(h http.Header) {
+	for _, k := range hopHeaders {
+		h.Del(k)
+	}
+}
+
+func copyRateLimitHeaders(dst, src http.Header) {
+	for k, v := range src {
+		if strings.HasPrefix(http.CanonicalHeaderKey(k), "Ratelimit-") {
+			dst[k] = v
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package cacheproxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upstream is a fake API that counts its requests and answers conditional
+// requests for its ETag with Not Modified.
+type upstream struct {
+	requests  atomic.Int32
+	remaining atomic.Int32
+	status    atomic.Int32
+	delay     time.Duration
+}
+
+func (u *upstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u.requests.Add(1)
+	time.Sleep(u.delay)
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(int(u.remaining.Load())))
+	w.Header().Set("RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+	if s := u.status.Load(); s != 0 {
+		w.WriteHeader(int(s))
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("ETag", `"v1"`)
+	if r.Header.Get("If-None-Match") == `"v1"` {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Write([]byte(`{"path":"` + r.URL.Path + `","token":"` + r.Header.Get("Authorization") + `"}`))
+}
+
+func newProxy(t *testing.T, api *upstream, opts Options) *httptest.Server {
+	if api.remaining.Load() == 0 {
+		api.remaining.Store(1000)
+	}
+	up := httptest.NewServer(api)
+	t.Cleanup(up.Close)
+	opts.Upstream, _ = url.Parse(up.URL)
+	proxy := httptest.NewServer(New(opts))
+	t.Cleanup(proxy.Close)
+	return proxy
+}
+
+func get(t *testing.T, url, token string, header ...string) (*http.Response, string) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func TestProxyCachesPerToken(t *testing.T) {
+	api := &upstream{}
+	proxy := newProxy(t, api, Options{TTL: time.Minute})
+
+	resp, body := get(t, proxy.URL+"/v2/droplets?page=1", "a")
+	assert.Equal(t, Miss, resp.Header.Get(CacheHeader))
+	assert.Equal(t, `{"path":"/v2/droplets","token":"Bearer a"}`, body)
+
+	resp, body = get(t, proxy.URL+"/v2/droplets?page=1", "a")
+	assert.Equal(t, Hit, resp.Header.Get(CacheHeader))
+	assert.Equal(t, `{"path":"/v2/droplets","token":"Bearer a"}`, body)
+	assert.Equal(t, "1000", resp.Header.Get("RateLimit-Remaining"))
+
+	resp, body = get(t, proxy.URL+"/v2/droplets?page=1", "b")
+	assert.Equal(t, Miss, resp.Header.Get(CacheHeader))
+	assert.Equal(t, `{"path":"/v2/droplets","token":"Bearer b"}`, body)
+
+	resp, _ = get(t, proxy.URL+"/v2/droplets?page=1", "a", "If-None-Match", `"v1"`)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.Equal(t, int32(2), api.requests.Load())
+}
+
+func TestProxyRevalidatesWithETag(t *testing.T) {
+	api := &upstream{}
+	proxy := newProxy(t, api, Options{TTL: time.Nanosecond})
+
+	get(t, proxy.URL+"/v2/account", "a")
+	resp, body := get(t, proxy.URL+"/v2/account", "a")
+	assert.Equal(t, Revalidated, resp.Header.Get(CacheHeader))
+	assert.Equal(t, `{"path":"/v2/account","token":"Bearer a"}`, body)
+	assert.Equal(t, int32(2), api.requests.Load())
+}
+
+func TestProxyServesStaleWhenRateLimited(t *testing.T) {
+	api := &upstream{}
+	api.remaining.Store(5)
+	proxy := newProxy(t, api, Options{TTL: time.Nanosecond, Reserve: 10})
+
+	get(t, proxy.URL+"/v2/account", "a")
+	resp, _ := get(t, proxy.URL+"/v2/account", "a")
+	assert.Equal(t, Stale, resp.Header.Get(CacheHeader))
+	assert.Equal(t, int32(1), api.requests.Load())
+
+	// Other tokens have their own rate limits.
+	resp, _ = get(t, proxy.URL+"/v2/account", "b")
+	assert.Equal(t, Miss, resp.Header.Get(CacheHeader))
+}
+
+func TestProxyServesStaleOnTooManyRequests(t *testing.T) {
+	api := &upstream{}
+	proxy := newProxy(t, api, Options{TTL: time.Nanosecond})
+
+	get(t, proxy.URL+"/v2/account", "a")
+	api.status.Store(http.StatusTooManyRequests)
+	resp, body := get(t, proxy.URL+"/v2/account", "a")
+	assert.Equal(t, Stale, resp.Header.Get(CacheHeader))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, "/v2/account")
+}
+
+func TestProxyBypassesAndInvalidatesOnWrites(t *testing.T) {
+	api := &upstream{}
+	proxy := newProxy(t, api, Options{TTL: time.Minute})
+
+	get(t, proxy.URL+"/v2/droplets", "a")
+	get(t, proxy.URL+"/v2/droplets", "b")
+
+	req, _ := http.NewRequest(http.MethodDelete, proxy.URL+"/v2/droplets/1", nil)
+	req.Header.Set("Authorization", "Bearer a")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, Bypass, resp.Header.Get(CacheHeader))
+
+	resp, _ = get(t, proxy.URL+"/v2/droplets", "a")
+	assert.Equal(t, Miss, resp.Header.Get(CacheHeader))
+	resp, _ = get(t, proxy.URL+"/v2/droplets", "b")
+	assert.Equal(t, Hit, resp.Header.Get(CacheHeader))
+}
+
+func TestProxyCoalescesRequests(t *testing.T) {
+	api := &upstream{delay: 50 * time.Millisecond}
+	proxy := newProxy(t, api, Options{TTL: time.Minute})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, body := get(t, proxy.URL+"/v2/sizes", "a")
+			assert.Contains(t, body, "/v2/sizes")
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), api.requests.Load())
+}
+
+func TestProxyDoesNotCacheErrors(t *testing.T) {
+	api := &upstream{}
+	api.status.Store(http.StatusNotFound)
+	proxy := newProxy(t, api, Options{TTL: time.Minute})
+
+	resp, _ := get(t, proxy.URL+"/v2/droplets/1", "a")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	get(t, proxy.URL+"/v2/droplets/1", "a")
+	assert.Equal(t, int32(2), api.requests.Load())
+}
+
+func TestProxyEvictsLeastRecentlyUsed(t *testing.T) {
+	api := &upstream{}
+	up := httptest.NewServer(api)
+	defer up.Close()
+	u, _ := url.Parse(up.URL)
+	p := New(Options{Upstream: u, TTL: time.Minute, MaxEntries: 2})
+	proxy := httptest.NewServer(p)
+	defer proxy.Close()
+
+	get(t, proxy.URL+"/v2/a", "a")
+	get(t, proxy.URL+"/v2/b", "a")
+	get(t, proxy.URL+"/v2/a", "a")
+	get(t, proxy.URL+"/v2/c", "a")
+
+	resp, _ := get(t, proxy.URL+"/v2/a", "a")
+	assert.Equal(t, Hit, resp.Header.Get(CacheHeader))
+	resp, _ = get(t, proxy.URL+"/v2/b", "a")
+	assert.Equal(t, Miss, resp.Header.Get(CacheHeader))
+
+	stats := p.Stats()
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, int64(2), stats.Hits)
+	assert.Equal(t, int64(4), stats.Misses)
+}
@@ -0,0 +1,204 @@
+package inventory
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Change is a resource whose fields differ between two documents.
+type Change struct {
+	Old, New Resource
+	// Fields are the names of the fields that differ, sorted. Attributes are
+	// named by their keys, prefixed with "attributes.".
+	Fields []string
+}
+
+// Diff is the difference between two documents.
+type Diff struct {
+	Added   []Resource
+	Removed []Resource
+	Changed []Change
+
+	AddedRelationships   []Relationship
+	RemovedRelationships []Relationship
+
+	// Skipped are the types of resources that are left out of the diff,
+	// because they could not be listed for one of the documents.
+	Skipped []string
+}
+
+// Empty reports whether the documents are the same.
+func (d *Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 &&
+		len(d.AddedRelationships) == 0 && len(d.RemovedRelationships) == 0
+}
+
+// Compare returns the difference from old to new. Both are normalized first.
+// Types of resources that one of the documents has an error for are skipped,
+// so that a failure to list them isn't taken for their removal.
+func Compare(old, new *Document) *Diff {
+	old.Normalize()
+	new.Normalize()
+
+	diff := &Diff{}
+	skip := map[string]bool{}
+	for _, doc := range []*Document{old, new} {
+		for _, e := range doc.Errors {
+			skip[e.Type] = true
+		}
+	}
+	for _, t := range Types {
+		if skip[t] {
+			diff.Skipped = append(diff.Skipped, t)
+		}
+	}
+
+	// Documents are sorted the same way, so they are merged in order.
+	less := func(a, b Resource) bool {
+		if a.Type != b.Type {
+			return typeOrder(a.Type) < typeOrder(b.Type)
+		}
+		return a.URN < b.URN
+	}
+	i, j := 0, 0
+	for i < len(old.Resources) || j < len(new.Resources) {
+		switch {
+		case j == len(new.Resources) || i < len(old.Resources) && less(old.Resources[i], new.Resources[j]):
+			if r := old.Resources[i]; !skip[r.Type] {
+				diff.Removed = append(diff.Removed, r)
+			}
+			i++
+		case i == len(old.Resources) || less(new.Resources[j], old.Resources[i]):
+			if r := new.Resources[j]; !skip[r.Type] {
+				diff.Added = append(diff.Added, r)
+			}
+			j++
+		default:
+			o, n := old.Resources[i], new.Resources[j]
+			if fields := changedFields(o, n); len(fields) > 0 && !skip[o.Type] {
+				diff.Changed = append(diff.Changed, Change{Old: o, New: n, Fields: fields})
+			}
+			i++
+			j++
+		}
+	}
+
+	// Relationships of skipped resources are skipped with them.
+	skipped := map[string]bool{}
+	for _, doc := range []*Document{old, new} {
+		for _, r := range doc.Resources {
+			if skip[r.Type] {
+				skipped[r.URN] = true
+			}
+		}
+	}
+	in := func(rels []Relationship) map[Relationship]bool {
+		m := make(map[Relationship]bool, len(rels))
+		for _, r := range rels {
+			m[r] = true
+		}
+		return m
+	}
+	oldRels, newRels := in(old.Relationships), in(new.Relationships)
+	for _, r := range old.Relationships {
+		if !newRels[r] && !skipped[r.From] && !skipped[r.To] {
+			diff.RemovedRelationships = append(diff.RemovedRelationships, r)
+		}
+	}
+	for _, r := range new.Relationships {
+		if !oldRels[r] && !skipped[r.From] && !skipped[r.To] {
+			diff.AddedRelationships = append(diff.AddedRelationships, r)
+		}
+	}
+	return diff
+}
+
+// changedFields returns the names of the fields that differ between a and b.
+// Values are compared as JSON, since numbers read from a document are
+// float64s and those of a new one may be ints.
+func changedFields(a, b Resource) []string {
+	var fields []string
+	for _, f := range []struct {
+		name string
+		a, b any
+	}{
+		{"name", a.Name, b.Name},
+		{"region", a.Region, b.Region},
+		{"tags", a.Tags, b.Tags},
+		{"created_at", a.CreatedAt, b.CreatedAt},
+	} {
+		if !sameJSON(f.a, f.b) {
+			fields = append(fields, f.name)
+		}
+	}
+
+	var attrs []string
+	for k, v := range a.Attributes {
+		if w, ok := b.Attributes[k]; !ok || !sameJSON(v, w) {
+			attrs = append(attrs, "attributes."+k)
+		}
+	}
+	for k := range b.Attributes {
+		if _, ok := a.Attributes[k]; !ok {
+			attrs = append(attrs, "attributes."+k)
+		}
+	}
+	sort.Strings(attrs)
+	return append(fields, attrs...)
+}
+
+func sameJSON(a, b any) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+	if errX != nil || errY != nil {
+		return false
+	}
+	// An empty list and no list at all are the same.
+	empty := func(s []byte) bool { return string(s) == "null" || string(s) == "[]" }
+	return string(x) == string(y) || empty(x) && empty(y)
+}
+
+// typeOrder is the position of a type in Types, or after all of them if it
+// isn't one.
+func typeOrder(t string) int {
+	for i, typ := range Types {
+		if typ == t {
+			return i
+		}
+	}
+	return len(Types)
+}
+
+// Fill copies the resources of the types d has errors for from old, with
+// their relationships, and removes the errors. It is used to keep comparing
+// against the last known resources of a type while it can't be listed.
+func (d *Document) Fill(old *Document) {
+	failed := map[string]bool{}
+	for _, e := range d.Errors {
+		failed[e.Type] = true
+	}
+	if len(failed) == 0 {
+		return
+	}
+	copied := map[string]bool{}
+	for _, r := range old.Resources {
+		if failed[r.Type] {
+			d.Add(r)
+			copied[r.URN] = true
+		}
+	}
+	for _, r := range old.Relationships {
+		if copied[r.From] || copied[r.To] {
+			d.Relationships = append(d.Relationships, r)
+		}
+	}
+	var errs []Error
+	for _, e := range old.Errors {
+		// Types old couldn't list either stay unknown.
+		if failed[e.Type] {
+			errs = append(errs, e)
+		}
+	}
+	d.Errors = errs
+	d.Normalize()
+}
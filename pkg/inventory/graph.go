@@ -0,0 +1,104 @@
+package inventory
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The formats graphs are written in.
+const (
+	// FormatDOT is the language of Graphviz.
+	FormatDOT = "dot"
+	// FormatMermaid is the flowchart syntax of Mermaid, which GitHub and
+	// GitLab render in Markdown.
+	FormatMermaid = "mermaid"
+)
+
+// GraphFormats are the formats graphs are written in.
+var GraphFormats = []string{FormatDOT, FormatMermaid}
+
+// dotShapes are the shapes of the nodes of types of resources in DOT.
+var dotShapes = map[string]string{
+	TypeDroplet:            "box",
+	TypeVolume:             "cylinder",
+	TypeDatabase:           "cylinder",
+	TypeLoadBalancer:       "hexagon",
+	TypeDomain:             "folder",
+	TypeDomainRecord:       "note",
+	TypeReservedIP:         "diamond",
+	TypeCertificate:        "component",
+	TypeApp:                "box3d",
+	TypeFunctionsNamespace: "tab",
+}
+
+// WriteGraph writes the relationships of the document as a graph in format.
+// Only resources that are related to others are in the graph.
+func (d *Document) WriteGraph(w io.Writer, format string) error {
+	d.Normalize()
+	related := map[string]bool{}
+	for _, r := range d.Relationships {
+		related[r.From] = true
+		related[r.To] = true
+	}
+	var nodes []Resource
+	for _, r := range d.Resources {
+		if related[r.URN] {
+			nodes = append(nodes, r)
+		}
+	}
+
+	var b strings.Builder
+	switch format {
+	case FormatDOT:
+		b.WriteString("digraph inventory {\n\trankdir=LR;\n\tnode [fontname=\"Helvetica\"];\n")
+		for _, n := range nodes {
+			shape := dotShapes[n.Type]
+			if shape == "" {
+				shape = "ellipse"
+			}
+			fmt.Fprintf(&b, "\t%s [label=%s, shape=%s];\n", dotQuote(n.URN), dotQuote(nodeLabel(n, "\n")), shape)
+		}
+		for _, r := range d.Relationships {
+			fmt.Fprintf(&b, "\t%s -> %s [label=%s];\n", dotQuote(r.From), dotQuote(r.To), dotQuote(r.Type))
+		}
+		b.WriteString("}\n")
+	case FormatMermaid:
+		// Mermaid node IDs can't have colons, so nodes are numbered.
+		ids := make(map[string]string, len(nodes))
+		b.WriteString("flowchart LR\n")
+		for i, n := range nodes {
+			ids[n.URN] = fmt.Sprintf("n%d", i)
+			fmt.Fprintf(&b, "    %s[\"%s\"]\n", ids[n.URN], mermaidEscape(nodeLabel(n, "<br/>")))
+		}
+		for _, r := range d.Relationships {
+			fmt.Fprintf(&b, "    %s -->|%s| %s\n", ids[r.From], r.Type, ids[r.To])
+		}
+	default:
+		return fmt.Errorf("unknown graph format %q; it must be one of %s", format, strings.Join(GraphFormats, ", "))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// nodeLabel is the label of a resource in a graph: its name, or ID, and
+// its type, separated by sep.
+func nodeLabel(r Resource, sep string) string {
+	name := r.Name
+	if name == "" {
+		name = r.ID
+	}
+	return name + sep + r.Type
+}
+
+// dotQuote quotes s as a DOT string.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + strings.ReplaceAll(s, "\n", `\n`) + `"`
+}
+
+// mermaidEscape escapes the characters of s that end a Mermaid label.
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
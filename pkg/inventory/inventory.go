@@ -0,0 +1,185 @@
+// Package inventory is the document that doctl inventory export writes: the
+// resources of an account and the relationships between them.
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Version is the version of the document format. It is increased when a
+// change would break readers of older documents.
+const Version = 1
+
+// The types of resources.
+const (
+	TypeDroplet            = "droplet"
+	TypeVolume             = "volume"
+	TypeDomain             = "domain"
+	TypeDomainRecord       = "domain_record"
+	TypeApp                = "app"
+	TypeDatabase           = "database"
+	TypeLoadBalancer       = "load_balancer"
+	TypeCertificate        = "certificate"
+	TypeSSHKey             = "ssh_key"
+	TypeReservedIP         = "reserved_ip"
+	TypeFunctionsNamespace = "functions_namespace"
+)
+
+// Types are the types of resources, in the order they are listed.
+var Types = []string{
+	TypeDroplet, TypeVolume, TypeReservedIP, TypeLoadBalancer, TypeCertificate, TypeDomain,
+	TypeDomainRecord, TypeDatabase, TypeApp, TypeFunctionsNamespace, TypeSSHKey,
+}
+
+// The types of relationships. A relationship reads from its source to its
+// target, such as "droplet attaches volume".
+const (
+	// Attaches relates a droplet to a volume attached to it.
+	Attaches = "attaches"
+	// Balances relates a load balancer to a droplet it sends traffic to.
+	Balances = "balances"
+	// Contains relates a domain to one of its records.
+	Contains = "contains"
+	// ResolvesTo relates an A or AAAA record to the droplet, load balancer
+	// or reserved IP with its address.
+	ResolvesTo = "resolves_to"
+	// AssignedTo relates a reserved IP to the droplet it is assigned to.
+	AssignedTo = "assigned_to"
+	// Uses relates a load balancer to its certificates, and an app to its
+	// databases and the namespace of its functions.
+	Uses = "uses"
+	// Serves relates an app to the domains it serves.
+	Serves = "serves"
+)
+
+// Resource is a resource of the account.
+type Resource struct {
+	// URN identifies the resource across types, such as do:droplet:1234.
+	URN       string   `json:"urn"`
+	Type      string   `json:"type"`
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Region    string   `json:"region,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	CreatedAt string   `json:"created_at,omitempty"`
+	// Attributes are the properties of the resource that are specific to
+	// its type. Secrets, such as database passwords, are never included.
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// Relationship relates two resources by their URNs.
+type Relationship struct {
+	From string `json:"from"`
+	Type string `json:"type"`
+	To   string `json:"to"`
+}
+
+// Error records a type of resource that could not be listed, so that
+// readers know the document is incomplete.
+type Error struct {
+	Type  string `json:"type"`
+	Error string `json:"error"`
+}
+
+// Document is an inventory of an account.
+type Document struct {
+	Version       int            `json:"version"`
+	GeneratedAt   time.Time      `json:"generated_at"`
+	Resources     []Resource     `json:"resources"`
+	Relationships []Relationship `json:"relationships"`
+	Errors        []Error        `json:"errors,omitempty"`
+}
+
+// New returns an empty document generated at t.
+func New(t time.Time) *Document {
+	return &Document{
+		Version:       Version,
+		GeneratedAt:   t.UTC().Truncate(time.Second),
+		Resources:     []Resource{},
+		Relationships: []Relationship{},
+	}
+}
+
+// Add adds resources to the document.
+func (d *Document) Add(r ...Resource) {
+	d.Resources = append(d.Resources, r...)
+}
+
+// Relate adds a relationship to the document.
+func (d *Document) Relate(from, typ, to string) {
+	d.Relationships = append(d.Relationships, Relationship{From: from, Type: typ, To: to})
+}
+
+// Resource returns the resource with urn, if the document has it.
+func (d *Document) Resource(urn string) (Resource, bool) {
+	for _, r := range d.Resources {
+		if r.URN == urn {
+			return r, true
+		}
+	}
+	return Resource{}, false
+}
+
+// Normalize sorts the resources by type and URN, and the relationships, and
+// removes duplicate relationships and those whose resources aren't in the
+// document, so that documents of the same account compare equal.
+func (d *Document) Normalize() {
+	sort.SliceStable(d.Resources, func(i, j int) bool {
+		a, b := d.Resources[i], d.Resources[j]
+		if a.Type != b.Type {
+			return typeOrder(a.Type) < typeOrder(b.Type)
+		}
+		return a.URN < b.URN
+	})
+
+	urns := map[string]bool{}
+	for _, r := range d.Resources {
+		urns[r.URN] = true
+	}
+	seen := map[Relationship]bool{}
+	rels := make([]Relationship, 0, len(d.Relationships))
+	for _, r := range d.Relationships {
+		if seen[r] || !urns[r.From] || !urns[r.To] {
+			continue
+		}
+		seen[r] = true
+		rels = append(rels, r)
+	}
+	sort.Slice(rels, func(i, j int) bool {
+		a, b := rels[i], rels[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.To < b.To
+	})
+	d.Relationships = rels
+}
+
+// Write writes the document as indented JSON.
+func (d *Document) Write(w io.Writer) error {
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// Read reads a document.
+func Read(r io.Reader) (*Document, error) {
+	var d Document
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return nil, fmt.Errorf("reading the inventory: %v", err)
+	}
+	if d.Version != Version {
+		return nil, fmt.Errorf("the inventory is version %d of the format; this doctl reads version %d", d.Version, Version)
+	}
+	return &d, nil
+}
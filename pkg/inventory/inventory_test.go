@@ -0,0 +1,133 @@
+package inventory
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	d := New(time.Date(2026, 1, 2, 3, 4, 5, 6, time.FixedZone("EST", -5*3600)))
+	d.Add(
+		Resource{URN: "do:volume:b", Type: TypeVolume},
+		Resource{URN: "do:droplet:2", Type: TypeDroplet},
+		Resource{URN: "do:volume:a", Type: TypeVolume},
+		Resource{URN: "do:droplet:10", Type: TypeDroplet},
+	)
+	d.Relate("do:droplet:2", Attaches, "do:volume:b")
+	d.Relate("do:droplet:10", Attaches, "do:volume:a")
+	d.Relate("do:droplet:2", Attaches, "do:volume:b")
+	d.Relate("do:droplet:2", Attaches, "do:volume:gone")
+	d.Normalize()
+
+	var urns []string
+	for _, r := range d.Resources {
+		urns = append(urns, r.URN)
+	}
+	assert.Equal(t, []string{"do:droplet:10", "do:droplet:2", "do:volume:a", "do:volume:b"}, urns)
+	assert.Equal(t, []Relationship{
+		{From: "do:droplet:10", Type: Attaches, To: "do:volume:a"},
+		{From: "do:droplet:2", Type: Attaches, To: "do:volume:b"},
+	}, d.Relationships)
+	assert.Equal(t, "2026-01-02T08:04:05Z", d.GeneratedAt.Format(time.RFC3339Nano))
+}
+
+func TestWriteRead(t *testing.T) {
+	d := New(time.Now())
+	d.Add(Resource{URN: "do:droplet:1", Type: TypeDroplet, ID: "1", Name: "web", Attributes: map[string]any{"size": "s-1vcpu-1gb"}})
+	d.Errors = []Error{{Type: TypeApp, Error: "forbidden"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, d.Write(&buf))
+	got, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, d, got)
+
+	_, err = Read(strings.NewReader(`{"version": 2}`))
+	assert.ErrorContains(t, err, "the inventory is version 2 of the format")
+	_, err = Read(strings.NewReader(`[]`))
+	assert.ErrorContains(t, err, "reading the inventory")
+}
+
+func TestCompare(t *testing.T) {
+	old := New(time.Now())
+	old.Add(
+		Resource{URN: "do:droplet:1", Type: TypeDroplet, Name: "web", Attributes: map[string]any{"size": "s-1vcpu-1gb", "vcpus": float64(1)}},
+		Resource{URN: "do:droplet:2", Type: TypeDroplet, Name: "old"},
+		Resource{URN: "do:volume:a", Type: TypeVolume},
+		Resource{URN: "do:app:x", Type: TypeApp},
+	)
+	old.Relate("do:droplet:1", Attaches, "do:volume:a")
+
+	new := New(time.Now())
+	new.Add(
+		Resource{URN: "do:droplet:1", Type: TypeDroplet, Name: "web", Tags: []string{}, Attributes: map[string]any{"size": "s-2vcpu-2gb", "vcpus": 1}},
+		Resource{URN: "do:droplet:3", Type: TypeDroplet, Name: "new"},
+		Resource{URN: "do:volume:a", Type: TypeVolume},
+	)
+	new.Relate("do:droplet:3", Attaches, "do:volume:a")
+	new.Errors = []Error{{Type: TypeApp, Error: "forbidden"}}
+
+	d := Compare(old, new)
+	assert.False(t, d.Empty())
+	assert.Equal(t, []Resource{{URN: "do:droplet:3", Type: TypeDroplet, Name: "new"}}, d.Added)
+	assert.Equal(t, []Resource{{URN: "do:droplet:2", Type: TypeDroplet, Name: "old"}}, d.Removed)
+	require.Len(t, d.Changed, 1)
+	assert.Equal(t, []string{"attributes.size"}, d.Changed[0].Fields)
+	assert.Equal(t, []Relationship{{From: "do:droplet:3", Type: Attaches, To: "do:volume:a"}}, d.AddedRelationships)
+	assert.Equal(t, []Relationship{{From: "do:droplet:1", Type: Attaches, To: "do:volume:a"}}, d.RemovedRelationships)
+	assert.Equal(t, []string{TypeApp}, d.Skipped)
+
+	assert.True(t, Compare(old, old).Empty())
+}
+
+func TestFill(t *testing.T) {
+	old := New(time.Now())
+	old.Add(Resource{URN: "do:droplet:1", Type: TypeDroplet}, Resource{URN: "do:volume:a", Type: TypeVolume})
+	old.Relate("do:droplet:1", Attaches, "do:volume:a")
+	old.Errors = []Error{{Type: TypeApp, Error: "forbidden"}}
+
+	new := New(time.Now())
+	new.Add(Resource{URN: "do:droplet:1", Type: TypeDroplet})
+	new.Errors = []Error{{Type: TypeVolume, Error: "timeout"}, {Type: TypeApp, Error: "forbidden"}}
+	new.Fill(old)
+
+	assert.Len(t, new.Resources, 2)
+	assert.Equal(t, old.Relationships, new.Relationships)
+	assert.Equal(t, []Error{{Type: TypeApp, Error: "forbidden"}}, new.Errors)
+}
+
+func TestWriteGraph(t *testing.T) {
+	d := New(time.Now())
+	d.Add(
+		Resource{URN: "do:loadbalancer:lb", Type: TypeLoadBalancer, Name: `front "prod"`},
+		Resource{URN: "do:droplet:1", Type: TypeDroplet, Name: "web"},
+		Resource{URN: "do:sshkey:7", Type: TypeSSHKey, Name: "laptop"},
+	)
+	d.Relate("do:loadbalancer:lb", Balances, "do:droplet:1")
+
+	var buf bytes.Buffer
+	require.NoError(t, d.WriteGraph(&buf, FormatDOT))
+	assert.Equal(t, `digraph inventory {
+	rankdir=LR;
+	node [fontname="Helvetica"];
+	"do:droplet:1" [label="web\ndroplet", shape=box];
+	"do:loadbalancer:lb" [label="front \"prod\"\nload_balancer", shape=hexagon];
+	"do:loadbalancer:lb" -> "do:droplet:1" [label="balances"];
+}
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, d.WriteGraph(&buf, FormatMermaid))
+	assert.Equal(t, `flowchart LR
+    n0["web<br/>droplet"]
+    n1["front #quot;prod#quot;<br/>load_balancer"]
+    n1 -->|balances| n0
+`, buf.String())
+
+	assert.ErrorContains(t, d.WriteGraph(&buf, "png"), "unknown graph format")
+}
@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// desktopCommand returns the command that shows a desktop notification on
+// goos. It is replaced for testing.
+var desktopCommand = func(ctx context.Context, goos, title, body string) (*exec.Cmd, error) {
+	switch goos {
+	case "darwin":
+		// The text is passed as arguments of the script, so that it needn't
+		// be quoted.
+		return exec.CommandContext(ctx, "osascript",
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			title, body), nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return exec.CommandContext(ctx, "notify-send", "--app-name", "doctl", title, body), nil
+	}
+	return nil, fmt.Errorf("desktop notifications aren't supported on %s", goos)
+}
+
+// desktop shows events as desktop notifications.
+type desktop struct{}
+
+// newDesktop creates the notifier for desktop.
+func newDesktop(u *url.URL) (Notifier, error) {
+	if u.Host != "" || (u.Path != "" && u.Path != "desktop") || u.Opaque != "" {
+		return nil, fmt.Errorf("the desktop notifier takes no options")
+	}
+	return desktop{}, nil
+}
+
+// Notify implements Notifier.
+func (desktop) Notify(ctx context.Context, e Event) error {
+	cmd, err := desktopCommand(ctx, runtime.GOOS, e.Title(), e.Summary())
+	if err != nil {
+		return err
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("showing the desktop notification: %s", msg)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Package notify sends notifications when long-running operations end.
+//
+// A notifier is named by a URL-like spec, such as
+// slack://hooks.slack.com/services/T000/B000/XXXX, webhook://example.com/hook
+// or desktop. Other kinds of notifiers can be added with Register.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Field is a detail of an operation, such as the ID of the resource it
+// created.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Event describes an operation that ended.
+type Event struct {
+	// Command is the command line of the operation, such as
+	// "doctl apps create-deployment 1234".
+	Command  string
+	Started  time.Time
+	Duration time.Duration
+	// Err is the error the operation failed with, or nil if it succeeded.
+	Err     error
+	Details []Field
+}
+
+// Succeeded reports whether the operation succeeded.
+func (e Event) Succeeded() bool {
+	return e.Err == nil
+}
+
+// Status is "succeeded" or "failed".
+func (e Event) Status() string {
+	if e.Succeeded() {
+		return "succeeded"
+	}
+	return "failed"
+}
+
+// Title is a one-line summary of the event.
+func (e Event) Title() string {
+	return fmt.Sprintf("%s %s", e.Command, e.Status())
+}
+
+// Summary is the details of the event, one per line.
+func (e Event) Summary() string {
+	lines := []string{fmt.Sprintf("Duration: %s", e.Duration.Round(time.Second))}
+	for _, f := range e.Details {
+		lines = append(lines, fmt.Sprintf("%s: %s", f.Name, f.Value))
+	}
+	if e.Err != nil {
+		lines = append(lines, fmt.Sprintf("Error: %v", e.Err))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// A Notifier sends notifications of events.
+type Notifier interface {
+	Notify(ctx context.Context, e Event) error
+}
+
+// Factory creates the notifier of a spec. Specs without a scheme, such as
+// desktop, have a URL with only Opaque or Path set.
+type Factory func(u *url.URL) (Notifier, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+// Register makes a kind of notifier available to Parse under scheme.
+func Register(scheme string, f Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[scheme] = f
+}
+
+// Schemes returns the registered schemes, sorted.
+func Schemes() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	schemes := make([]string, 0, len(factories))
+	for s := range factories {
+		schemes = append(schemes, s)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Parse returns the notifier named by spec.
+func Parse(spec string) (Notifier, error) {
+	scheme := spec
+	if i := strings.Index(spec, "://"); i >= 0 {
+		scheme = spec[:i]
+	}
+	mu.RLock()
+	f, ok := factories[strings.ToLower(scheme)]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown notifier %q; it must be one of %s", spec, strings.Join(Schemes(), ", "))
+	}
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notifier %q: %v", spec, err)
+	}
+	return f(u)
+}
+
+// Multi sends each event to all of the notifiers, and returns their errors
+// joined.
+type Multi []Notifier
+
+// Notify implements Notifier.
+func (m Multi) Notify(ctx context.Context, e Event) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HTTPClient is the client webhook notifications are posted with.
+var HTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+func init() {
+	Register("slack", newSlack)
+	Register("webhook", newWebhook)
+	Register("webhook+http", newWebhook)
+	Register("desktop", newDesktop)
+}
@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEvent = Event{
+	Command:  "doctl apps create-deployment 1234",
+	Started:  time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+	Duration: 95 * time.Second,
+	Details:  []Field{{Name: "Deployment", Value: "abcd"}},
+}
+
+// hookServer records the bodies posted to it, and responds with status.
+func hookServer(t *testing.T, status int) (*httptest.Server, *[]string) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &bodies
+}
+
+func TestParse(t *testing.T) {
+	n, err := Parse("slack://hooks.slack.com/services/T000/B000/XXXX")
+	require.NoError(t, err)
+	assert.Equal(t, "https://hooks.slack.com/services/T000/B000/XXXX", n.(*webhook).url)
+
+	n, err = Parse("webhook://example.com/hook?token=abc")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/hook?token=abc", n.(*webhook).url)
+
+	n, err = Parse("webhook+http://127.0.0.1:8080/hook")
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1:8080/hook", n.(*webhook).url)
+
+	n, err = Parse("desktop")
+	require.NoError(t, err)
+	assert.Equal(t, desktop{}, n)
+
+	_, err = Parse("email://me@example.com")
+	assert.ErrorContains(t, err, "it must be one of desktop, slack, webhook, webhook+http")
+	_, err = Parse("slack:///services/T000")
+	assert.ErrorContains(t, err, "must be in the form slack://host/path")
+	_, err = Parse("desktop://loud")
+	assert.ErrorContains(t, err, "takes no options")
+}
+
+func TestWebhook(t *testing.T) {
+	srv, bodies := hookServer(t, http.StatusNoContent)
+	n, err := Parse("webhook+http://" + strings.TrimPrefix(srv.URL, "http://") + "/hook")
+	require.NoError(t, err)
+
+	e := testEvent
+	e.Err = errors.New("deployment failed")
+	require.NoError(t, n.Notify(context.Background(), e))
+
+	require.Len(t, *bodies, 1)
+	var p map[string]any
+	require.NoError(t, json.Unmarshal([]byte((*bodies)[0]), &p))
+	assert.Equal(t, map[string]any{
+		"command":          "doctl apps create-deployment 1234",
+		"status":           "failed",
+		"error":            "deployment failed",
+		"started":          "2026-10-16T12:00:00Z",
+		"duration_seconds": 95.0,
+		"details":          map[string]any{"Deployment": "abcd"},
+	}, p)
+}
+
+func TestSlack(t *testing.T) {
+	srv, bodies := hookServer(t, http.StatusOK)
+	n, err := Parse("slack://hooks.slack.com/services/T000/B000/XXXX")
+	require.NoError(t, err)
+	n.(*webhook).url = srv.URL
+
+	require.NoError(t, n.Notify(context.Background(), testEvent))
+
+	require.Len(t, *bodies, 1)
+	var m slackMessage
+	require.NoError(t, json.Unmarshal([]byte((*bodies)[0]), &m))
+	assert.Equal(t, "`doctl apps create-deployment 1234` succeeded", m.Text)
+	assert.Equal(t, []slackAttachment{{Color: "good", Fields: []slackField{
+		{Title: "Duration", Value: "1m35s", Short: true},
+		{Title: "Deployment", Value: "abcd", Short: true},
+	}}}, m.Attachments)
+}
+
+func TestWebhookErrors(t *testing.T) {
+	srv, _ := hookServer(t, http.StatusForbidden)
+	n, err := Parse("webhook+http://" + strings.TrimPrefix(srv.URL, "http://") + "/secret-token")
+	require.NoError(t, err)
+	err = n.Notify(context.Background(), testEvent)
+	assert.EqualError(t, err, "sending the webhook notification: the server responded 403 Forbidden")
+
+	srv.Close()
+	err = n.Notify(context.Background(), testEvent)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-token")
+}
+
+func TestDesktop(t *testing.T) {
+	prev := desktopCommand
+	t.Cleanup(func() { desktopCommand = prev })
+
+	var got []string
+	desktopCommand = func(ctx context.Context, goos, title, body string) (*exec.Cmd, error) {
+		got = []string{title, body}
+		return exec.CommandContext(ctx, "true"), nil
+	}
+	e := testEvent
+	e.Err = errors.New("deployment failed")
+	require.NoError(t, desktop{}.Notify(context.Background(), e))
+	assert.Equal(t, []string{
+		"doctl apps create-deployment 1234 failed",
+		"Duration: 1m35s\nDeployment: abcd\nError: deployment failed",
+	}, got)
+
+	cmd, err := prev(context.Background(), "darwin", "title", "body")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"title", "body"}, cmd.Args[len(cmd.Args)-2:])
+	_, err = prev(context.Background(), "windows", "title", "body")
+	assert.EqualError(t, err, "desktop notifications aren't supported on windows")
+}
+
+func TestMulti(t *testing.T) {
+	ok, okBodies := hookServer(t, http.StatusOK)
+	bad, _ := hookServer(t, http.StatusInternalServerError)
+	m := Multi{
+		&webhook{kind: "webhook", url: bad.URL, body: func(Event) any { return nil }},
+		&webhook{kind: "webhook", url: ok.URL, body: func(Event) any { return nil }},
+	}
+	err := m.Notify(context.Background(), testEvent)
+	assert.ErrorContains(t, err, "500 Internal Server Error")
+	assert.Len(t, *okBodies, 1)
+}
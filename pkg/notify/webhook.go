@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// webhookPayload is the JSON body of webhook notifications.
+type webhookPayload struct {
+	Command         string            `json:"command"`
+	Status          string            `json:"status"`
+	Error           string            `json:"error,omitempty"`
+	Started         time.Time         `json:"started"`
+	DurationSeconds float64           `json:"duration_seconds"`
+	Details         map[string]string `json:"details,omitempty"`
+}
+
+// webhook posts events as JSON to a URL.
+type webhook struct {
+	kind string
+	url  string
+	body func(Event) any
+}
+
+// newWebhook creates a notifier for webhook://host/path, which posts to
+// https://host/path, or webhook+http://host/path for endpoints without TLS.
+func newWebhook(u *url.URL) (Notifier, error) {
+	target, err := hookURL(u, "https")
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "webhook+http" {
+		target.Scheme = "http"
+	}
+	return &webhook{kind: "webhook", url: target.String(), body: func(e Event) any {
+		p := webhookPayload{
+			Command:         e.Command,
+			Status:          e.Status(),
+			Started:         e.Started.UTC(),
+			DurationSeconds: e.Duration.Seconds(),
+		}
+		if e.Err != nil {
+			p.Error = e.Err.Error()
+		}
+		if len(e.Details) > 0 {
+			p.Details = map[string]string{}
+			for _, f := range e.Details {
+				p.Details[f.Name] = f.Value
+			}
+		}
+		return p
+	}}, nil
+}
+
+// slackField and slackAttachment are the parts of a Slack incoming webhook
+// message that are used.
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Fields []slackField `json:"fields"`
+}
+
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+// newSlack creates a notifier for slack://hooks.slack.com/services/..., the
+// URL of a Slack incoming webhook without its https scheme.
+func newSlack(u *url.URL) (Notifier, error) {
+	target, err := hookURL(u, "https")
+	if err != nil {
+		return nil, err
+	}
+	return &webhook{kind: "Slack", url: target.String(), body: func(e Event) any {
+		a := slackAttachment{Color: "good", Fields: []slackField{
+			{Title: "Duration", Value: e.Duration.Round(time.Second).String(), Short: true},
+		}}
+		for _, f := range e.Details {
+			a.Fields = append(a.Fields, slackField{Title: f.Name, Value: f.Value, Short: true})
+		}
+		if e.Err != nil {
+			a.Color = "danger"
+			a.Fields = append(a.Fields, slackField{Title: "Error", Value: e.Err.Error()})
+		}
+		return slackMessage{Text: fmt.Sprintf("`%s` %s", e.Command, e.Status()), Attachments: []slackAttachment{a}}
+	}}, nil
+}
+
+// hookURL returns the URL that spec u names, with its scheme replaced.
+func hookURL(u *url.URL, scheme string) (*url.URL, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("the %s notifier must be in the form %s://host/path", u.Scheme, u.Scheme)
+	}
+	target := *u
+	target.Scheme = scheme
+	return &target, nil
+}
+
+// Notify implements Notifier.
+func (w *webhook) Notify(ctx context.Context, e Event) error {
+	body, err := json.Marshal(w.body(e))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		// The URL of a webhook is a secret, so it is left out of the error.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("sending the %s notification: %v", w.kind, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("sending the %s notification: the server responded %s", w.kind, resp.Status)
+	}
+	return nil
+}
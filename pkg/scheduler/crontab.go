@@ -0,0 +1,101 @@
+package scheduler
+
+import (
+	"strings"
+)
+
+// crontabMarker precedes the line of each job in the crontab, and is
+// followed by the job's name.
+const crontabMarker = "# doctl cron job: "
+
+// crontab installs jobs in the user's crontab.
+type crontab struct {
+	exec Exec
+}
+
+// read returns the lines of the crontab.
+func (c *crontab) read() ([]string, error) {
+	out, err := c.exec("", "crontab", "-l")
+	if err != nil {
+		// A user without a crontab has no jobs.
+		if strings.Contains(strings.ToLower(string(out)), "no crontab") {
+			return nil, nil
+		}
+		return nil, commandError(out, err, "crontab", "-l")
+	}
+	text := strings.TrimRight(string(out), "\n")
+	if text == "" {
+		return nil, nil
+	}
+	return strings.Split(text, "\n"), nil
+}
+
+// write replaces the crontab with lines.
+func (c *crontab) write(lines []string) error {
+	_, err := run(c.exec, strings.Join(lines, "\n")+"\n", "crontab", "-")
+	return err
+}
+
+// without returns lines without the job with name.
+func without(lines []string, name string) []string {
+	out := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		if lines[i] == crontabMarker+name {
+			i++
+			continue
+		}
+		out = append(out, lines[i])
+	}
+	return out
+}
+
+// Install implements Scheduler.
+func (c *crontab) Install(j Job) error {
+	lines, err := c.read()
+	if err != nil {
+		return err
+	}
+	args := make([]string, len(j.Command))
+	for i, a := range j.Command {
+		args[i] = shellQuote(a)
+	}
+	// cron runs the line with sh, after turning unescaped % into newlines.
+	line := j.Schedule.Cron() + " " + strings.ReplaceAll(strings.Join(args, " "), "%", `\%`)
+	return c.write(append(without(lines, j.Name), crontabMarker+j.Name, line))
+}
+
+// Remove implements Scheduler.
+func (c *crontab) Remove(name string) error {
+	lines, err := c.read()
+	if err != nil {
+		return err
+	}
+	kept := without(lines, name)
+	if len(kept) == len(lines) {
+		return nil
+	}
+	return c.write(kept)
+}
+
+// Installed implements Scheduler.
+func (c *crontab) Installed() ([]string, error) {
+	lines, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, l := range lines {
+		if name, ok := strings.CutPrefix(l, crontabMarker); ok {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// shellQuote quotes s for sh.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,@") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// launchdPrefix is the prefix of the labels of jobs.
+const launchdPrefix = "com.digitalocean.doctl.cron."
+
+// launchd installs jobs as launch agents of the user.
+type launchd struct {
+	dir  string
+	exec Exec
+}
+
+func (l *launchd) plistPath(name string) string {
+	return filepath.Join(l.dir, launchdPrefix+name+".plist")
+}
+
+// Install implements Scheduler.
+func (l *launchd) Install(j Job) error {
+	intervals, err := j.Schedule.CalendarIntervals()
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	str := func(indent, s string) {
+		b.WriteString(indent + "<string>")
+		xml.EscapeText(&b, []byte(s))
+		b.WriteString("</string>\n")
+	}
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<!-- Created by doctl cron install. -->
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+`)
+	str("\t", launchdPrefix+j.Name)
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, a := range j.Command {
+		str("\t\t", a)
+	}
+	b.WriteString("\t</array>\n\t<key>StartCalendarInterval</key>\n\t<array>\n")
+	for _, interval := range intervals {
+		keys := make([]string, 0, len(interval))
+		for k := range interval {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteString("\t\t<dict>\n")
+		for _, k := range keys {
+			fmt.Fprintf(&b, "\t\t\t<key>%s</key>\n\t\t\t<integer>%d</integer>\n", k, interval[k])
+		}
+		b.WriteString("\t\t</dict>\n")
+	}
+	b.WriteString("\t</array>\n</dict>\n</plist>\n")
+
+	path := l.plistPath(j.Name)
+	if _, err := os.Stat(path); err == nil {
+		// The agent must be unloaded for the new one to be loaded.
+		l.exec("", "launchctl", "unload", path)
+	}
+	if err := os.MkdirAll(l.dir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+		return err
+	}
+	_, err = run(l.exec, "", "launchctl", "load", "-w", path)
+	return err
+}
+
+// Remove implements Scheduler.
+func (l *launchd) Remove(name string) error {
+	path := l.plistPath(name)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if _, err := run(l.exec, "", "launchctl", "unload", "-w", path); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Installed implements Scheduler.
+func (l *launchd) Installed() ([]string, error) {
+	plists, err := filepath.Glob(filepath.Join(l.dir, launchdPrefix+"*.plist"))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(plists))
+	for _, p := range plists {
+		names = append(names, strings.TrimSuffix(strings.TrimPrefix(filepath.Base(p), launchdPrefix), ".plist"))
+	}
+	return names, nil
+}
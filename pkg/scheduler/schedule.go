@@ -0,0 +1,310 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxCalendarIntervals is the most launchd calendar intervals a schedule may
+// expand to.
+const maxCalendarIntervals = 1000
+
+// macros are the schedules that can be named instead of written in full.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames   = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// field is one of the five fields of a cron schedule.
+type field struct {
+	raw    string
+	values []int
+	// all is set for *, which matches every value.
+	all bool
+	// step is n for */n, which matches every nth value from the minimum.
+	step int
+}
+
+// String returns the values of the field as a comma-separated list.
+func (f field) String() string {
+	parts := make([]string, len(f.values))
+	for i, v := range f.values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Schedule is a cron schedule: minute, hour, day of month, month and day of
+// week.
+type Schedule struct {
+	expr                              string
+	minute, hour, day, month, weekday field
+}
+
+// Parse parses a cron schedule of five fields, such as "30 2 * * 1-5", or one
+// of the macros @hourly, @daily, @weekly, @monthly and @yearly.
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	full := expr
+	if strings.HasPrefix(expr, "@") {
+		var ok bool
+		if full, ok = macros[strings.ToLower(expr)]; !ok {
+			return nil, fmt.Errorf("unknown schedule %q; the supported macros are @hourly, @daily, @weekly, @monthly and @yearly", expr)
+		}
+	}
+	parts := strings.Fields(full)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q; it must have five fields: minute, hour, day of month, month and day of week", expr)
+	}
+
+	s := &Schedule{expr: expr}
+	var err error
+	if s.minute, err = parseField(parts[0], "minute", 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(parts[1], "hour", 0, 23, nil); err != nil {
+		return nil, err
+	}
+	if s.day, err = parseField(parts[2], "day of month", 1, 31, nil); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(parts[3], "month", 1, 12, monthNames); err != nil {
+		return nil, err
+	}
+	if s.weekday, err = parseField(parts[4], "day of week", 0, 7, weekdayNames); err != nil {
+		return nil, err
+	}
+	// Sunday is both 0 and 7.
+	if n := len(s.weekday.values); n > 0 && s.weekday.values[n-1] == 7 {
+		s.weekday.values = s.weekday.values[:n-1]
+		if len(s.weekday.values) == 0 || s.weekday.values[0] != 0 {
+			s.weekday.values = append([]int{0}, s.weekday.values...)
+		}
+	}
+	return s, nil
+}
+
+// parseField parses a comma-separated list of values, ranges (a-b) and steps
+// (*/n or a-b/n). names, if given, are the names of the values from min.
+func parseField(raw, name string, min, max int, names []string) (field, error) {
+	f := field{raw: raw}
+	invalid := func() (field, error) {
+		return field{}, fmt.Errorf("invalid %s %q in schedule; it must be between %d and %d", name, raw, min, max)
+	}
+	value := func(s string) (int, bool) {
+		for i, n := range names {
+			if strings.EqualFold(s, n) {
+				return i + min, true
+			}
+		}
+		v, err := strconv.Atoi(s)
+		return v, err == nil && v >= min && v <= max
+	}
+
+	seen := map[int]bool{}
+	for _, item := range strings.Split(raw, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step < 1 {
+				return invalid()
+			}
+		}
+
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+			if !hasStep {
+				f.all = true
+			} else if len(strings.Split(raw, ",")) == 1 {
+				f.step = step
+			}
+			// Sunday is matched once, as 0.
+			if max == 7 {
+				hi = 6
+			}
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var ok1, ok2 bool
+			lo, ok1 = value(a)
+			hi, ok2 = value(b)
+			if !ok1 || !ok2 || lo > hi {
+				return invalid()
+			}
+		default:
+			var ok bool
+			if lo, ok = value(rng); !ok {
+				return invalid()
+			}
+			if hasStep {
+				hi = max
+			} else {
+				hi = lo
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			seen[v] = true
+		}
+	}
+	for v := range seen {
+		f.values = append(f.values, v)
+	}
+	sort.Ints(f.values)
+	return f, nil
+}
+
+// String returns the schedule as it was written.
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Cron returns the schedule as the five fields of a crontab line.
+func (s *Schedule) Cron() string {
+	if full, ok := macros[strings.ToLower(s.expr)]; ok {
+		return full
+	}
+	return strings.Join(strings.Fields(s.expr), " ")
+}
+
+// bothDays reports whether the schedule restricts both the day of month and
+// the day of week, which cron matches when either does. As in cron, a field
+// that starts with * doesn't restrict the day.
+func (s *Schedule) bothDays() bool {
+	return !strings.HasPrefix(s.day.raw, "*") && !strings.HasPrefix(s.weekday.raw, "*")
+}
+
+// OnCalendar returns the schedule as a systemd calendar event.
+func (s *Schedule) OnCalendar() (string, error) {
+	if s.bothDays() {
+		return "", fmt.Errorf("systemd timers can't run on a day of the month or a day of the week, as %q does; use two jobs instead", s.expr)
+	}
+	list := func(f field) string {
+		if f.all {
+			return "*"
+		}
+		return f.String()
+	}
+	event := fmt.Sprintf("*-%s-%s %s:%s:00", list(s.month), list(s.day), list(s.hour), list(s.minute))
+	if !s.weekday.all {
+		days := make([]string, len(s.weekday.values))
+		for i, d := range s.weekday.values {
+			days[i] = strings.ToUpper(weekdayNames[d][:1]) + weekdayNames[d][1:]
+		}
+		event = strings.Join(days, ",") + " " + event
+	}
+	return event, nil
+}
+
+// CalendarIntervals returns the schedule as launchd StartCalendarInterval
+// entries, each of which maps Minute, Hour, Day, Month and Weekday to a
+// value. A key that is missing matches every value.
+func (s *Schedule) CalendarIntervals() ([]map[string]int, error) {
+	type keyed struct {
+		key string
+		f   field
+	}
+	// The number of entries is counted first, so that schedules such as
+	// "* * * * 1,3" aren't expanded to too many.
+	count := func(fields ...keyed) int {
+		n := 1
+		for _, f := range fields {
+			if !f.f.all {
+				n *= len(f.f.values)
+			}
+		}
+		return n
+	}
+	expand := func(fields ...keyed) []map[string]int {
+		out := []map[string]int{{}}
+		for _, f := range fields {
+			if f.f.all {
+				continue
+			}
+			var next []map[string]int
+			for _, m := range out {
+				for _, v := range f.f.values {
+					c := map[string]int{f.key: v}
+					for k, v := range m {
+						c[k] = v
+					}
+					next = append(next, c)
+				}
+			}
+			out = next
+		}
+		return out
+	}
+	minute, hour, month := keyed{"Minute", s.minute}, keyed{"Hour", s.hour}, keyed{"Month", s.month}
+	day, weekday := keyed{"Day", s.day}, keyed{"Weekday", s.weekday}
+
+	// launchd runs a job when all of the keys of an entry match, so
+	// schedules on either a day of the month or a day of the week need the
+	// entries of both.
+	var n int
+	if s.bothDays() {
+		n = count(minute, hour, day, month) + count(minute, hour, weekday, month)
+	} else {
+		n = count(minute, hour, day, weekday, month)
+	}
+	if n > maxCalendarIntervals {
+		return nil, fmt.Errorf("the schedule %q needs %d launchd calendar intervals, more than the %d allowed", s.expr, n, maxCalendarIntervals)
+	}
+	if s.bothDays() {
+		return append(expand(minute, hour, day, month), expand(minute, hour, weekday, month)...), nil
+	}
+	return expand(minute, hour, day, weekday, month), nil
+}
+
+// TaskSchedulerArgs returns the schtasks arguments that set the schedule.
+// Task Scheduler supports fewer schedules than cron: every n minutes or
+// hours, where n divides an hour or a day, or once at a time of day on every
+// day, some days of the week, or a day of the month.
+func (s *Schedule) TaskSchedulerArgs() ([]string, error) {
+	unsupported := fmt.Errorf("the schedule %q can't be set with Task Scheduler; use a schedule such as */15 * * * *, 0 */2 * * *, 30 2 * * *, 30 2 * * 1,3 or 30 2 1 * *", s.expr)
+	everyDay := s.day.all && s.month.all && s.weekday.all
+	single := func(f field) bool {
+		return !f.all && f.step == 0 && len(f.values) == 1
+	}
+
+	switch {
+	case everyDay && s.minute.step > 0 && 60%s.minute.step == 0 && s.hour.all:
+		return []string{"/SC", "MINUTE", "/MO", strconv.Itoa(s.minute.step), "/ST", "00:00"}, nil
+	case everyDay && single(s.minute) && (s.hour.all || (s.hour.step > 0 && 24%s.hour.step == 0)):
+		step := 1
+		if s.hour.step > 0 {
+			step = s.hour.step
+		}
+		return []string{"/SC", "HOURLY", "/MO", strconv.Itoa(step), "/ST", fmt.Sprintf("00:%02d", s.minute.values[0])}, nil
+	}
+
+	if !single(s.minute) || !single(s.hour) || !s.month.all || (!s.day.all && !s.weekday.all) {
+		return nil, unsupported
+	}
+	at := fmt.Sprintf("%02d:%02d", s.hour.values[0], s.minute.values[0])
+	switch {
+	case s.day.all && s.weekday.all:
+		return []string{"/SC", "DAILY", "/ST", at}, nil
+	case !s.weekday.all && s.weekday.step == 0:
+		days := make([]string, len(s.weekday.values))
+		for i, d := range s.weekday.values {
+			days[i] = strings.ToUpper(weekdayNames[d])
+		}
+		return []string{"/SC", "WEEKLY", "/D", strings.Join(days, ","), "/ST", at}, nil
+	case single(s.day):
+		return []string{"/SC", "MONTHLY", "/D", s.day.String(), "/ST", at}, nil
+	}
+	return nil, unsupported
+}
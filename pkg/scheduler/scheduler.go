@@ -0,0 +1,151 @@
+// Package scheduler installs jobs in the scheduler of the operating system:
+// cron, systemd timers, launchd or Windows Task Scheduler.
+package scheduler
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// The kinds of schedulers.
+const (
+	Cron          = "cron"
+	Systemd       = "systemd"
+	Launchd       = "launchd"
+	TaskScheduler = "schtasks"
+)
+
+// Kinds are the kinds of schedulers that New supports.
+var Kinds = []string{Cron, Systemd, Launchd, TaskScheduler}
+
+// validName is the form of job names, which are used in file names, unit
+// names and task names.
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// ValidName checks that name can be the name of a job.
+func ValidName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid job name %q; it must be up to 63 lowercase letters, digits and hyphens, starting with a letter or digit", name)
+	}
+	return nil
+}
+
+// A Job is a command that is run on a schedule.
+type Job struct {
+	// Name identifies the job among those installed by this package.
+	Name     string
+	Schedule *Schedule
+	// Command is the program and its arguments.
+	Command []string
+}
+
+// A Scheduler installs jobs in a scheduler of the operating system. Only
+// the jobs it installed are seen by it.
+type Scheduler interface {
+	// Install installs a job, replacing the job of the same name if there
+	// is one.
+	Install(j Job) error
+	// Remove removes the job with name. Removing a job that isn't installed
+	// isn't an error.
+	Remove(name string) error
+	// Installed returns the names of the installed jobs.
+	Installed() ([]string, error)
+}
+
+// Exec runs a program with input as its standard input, and returns its
+// combined output.
+type Exec func(input string, name string, args ...string) ([]byte, error)
+
+// Options configure a Scheduler.
+type Options struct {
+	// Dir is the directory that systemd units or launchd agents are
+	// written to. It defaults to ~/.config/systemd/user and
+	// ~/Library/LaunchAgents.
+	Dir string
+	// Exec runs crontab, systemctl, launchctl and schtasks. It defaults to
+	// running them with os/exec.
+	Exec Exec
+}
+
+// Default returns the kind of scheduler used on goos.
+func Default(goos string) string {
+	switch goos {
+	case "darwin":
+		return Launchd
+	case "windows":
+		return TaskScheduler
+	}
+	return Cron
+}
+
+// New returns the scheduler of kind.
+func New(kind string, opts Options) (Scheduler, error) {
+	if opts.Exec == nil {
+		opts.Exec = execCommand
+	}
+	home := func(parts ...string) (string, error) {
+		if opts.Dir != "" {
+			return opts.Dir, nil
+		}
+		h, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(append([]string{h}, parts...), string(os.PathSeparator)), nil
+	}
+
+	switch kind {
+	case Cron:
+		return &crontab{exec: opts.Exec}, nil
+	case Systemd:
+		dir, err := home(".config", "systemd", "user")
+		if err != nil {
+			return nil, err
+		}
+		return &systemd{dir: dir, exec: opts.Exec}, nil
+	case Launchd:
+		dir, err := home("Library", "LaunchAgents")
+		if err != nil {
+			return nil, err
+		}
+		return &launchd{dir: dir, exec: opts.Exec}, nil
+	case TaskScheduler:
+		return &schtasks{exec: opts.Exec}, nil
+	}
+	return nil, fmt.Errorf("unknown scheduler %q; it must be one of %s", kind, strings.Join(Kinds, ", "))
+}
+
+// execCommand runs a program with os/exec.
+func execCommand(input string, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	return out.Bytes(), err
+}
+
+// run runs a program with exec, and includes its output in the error if it
+// fails.
+func run(exec Exec, input string, name string, args ...string) ([]byte, error) {
+	out, err := exec(input, name, args...)
+	if err != nil {
+		return out, commandError(out, err, name, args...)
+	}
+	return out, nil
+}
+
+// commandError is the error of a program that failed with output.
+func commandError(out []byte, err error, name string, args ...string) error {
+	if msg := strings.TrimSpace(string(out)); msg != "" {
+		return fmt.Errorf("%s %s: %s", name, strings.Join(args, " "), msg)
+	}
+	return fmt.Errorf("%s %s: %v", name, strings.Join(args, " "), err)
+}
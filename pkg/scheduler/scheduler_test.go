@@ -0,0 +1,305 @@
+package scheduler
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustParse(t *testing.T, expr string) *Schedule {
+	s, err := Parse(expr)
+	require.NoError(t, err)
+	return s
+}
+
+func TestParse(t *testing.T) {
+	s := mustParse(t, "*/15 2,14 1-5 jan-mar 5-7")
+	assert.Equal(t, []int{0, 15, 30, 45}, s.minute.values)
+	assert.Equal(t, 15, s.minute.step)
+	assert.Equal(t, []int{2, 14}, s.hour.values)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, s.day.values)
+	assert.Equal(t, []int{1, 2, 3}, s.month.values)
+	assert.Equal(t, []int{0, 5, 6}, s.weekday.values)
+
+	s = mustParse(t, "@Daily")
+	assert.Equal(t, "0 0 * * *", s.Cron())
+	assert.Equal(t, "@Daily", s.String())
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "@reboot"} {
+		_, err := Parse(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestOnCalendar(t *testing.T) {
+	for expr, want := range map[string]string{
+		"30 2 * * *":       "*-*-* 2:30:00",
+		"*/15 * * * *":     "*-*-* *:0,15,30,45:00",
+		"0 9 * * 1-5":      "Mon,Tue,Wed,Thu,Fri *-*-* 9:0:00",
+		"0 0 1 */3 *":      "*-1,4,7,10-1 0:0:00",
+		"0 0 */2 * mon":    "Mon *-*-1,3,5,7,9,11,13,15,17,19,21,23,25,27,29,31 0:0:00",
+		"@weekly":          "Sun *-*-* 0:0:00",
+		"15 3 1,15 * *":    "*-*-1,15 3:15:00",
+		"0 12 * 2 sun,sat": "Sun,Sat *-2-* 12:0:00",
+	} {
+		got, err := mustParse(t, expr).OnCalendar()
+		require.NoError(t, err, expr)
+		assert.Equal(t, want, got, expr)
+	}
+
+	_, err := mustParse(t, "0 0 1 * 1").OnCalendar()
+	assert.ErrorContains(t, err, "use two jobs instead")
+}
+
+func TestCalendarIntervals(t *testing.T) {
+	got, err := mustParse(t, "30 2 * * 1,3").CalendarIntervals()
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]int{
+		{"Minute": 30, "Hour": 2, "Weekday": 1},
+		{"Minute": 30, "Hour": 2, "Weekday": 3},
+	}, got)
+
+	// Either day matches, as in cron.
+	got, err = mustParse(t, "0 0 1 * 0").CalendarIntervals()
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]int{
+		{"Minute": 0, "Hour": 0, "Day": 1},
+		{"Minute": 0, "Hour": 0, "Weekday": 0},
+	}, got)
+
+	got, err = mustParse(t, "* * * * *").CalendarIntervals()
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]int{{}}, got)
+
+	_, err = mustParse(t, "* 1-23 * * 1-5").CalendarIntervals()
+	require.NoError(t, err)
+	_, err = mustParse(t, "*/2 */2 */2 * *").CalendarIntervals()
+	assert.ErrorContains(t, err, "more than the 1000 allowed")
+}
+
+func TestTaskSchedulerArgs(t *testing.T) {
+	for expr, want := range map[string]string{
+		"*/15 * * * *": "/SC MINUTE /MO 15 /ST 00:00",
+		"10 * * * *":   "/SC HOURLY /MO 1 /ST 00:10",
+		"0 */6 * * *":  "/SC HOURLY /MO 6 /ST 00:00",
+		"30 2 * * *":   "/SC DAILY /ST 02:30",
+		"30 2 * * 1,3": "/SC WEEKLY /D MON,WED /ST 02:30",
+		"30 2 15 * *":  "/SC MONTHLY /D 15 /ST 02:30",
+		"@daily":       "/SC DAILY /ST 00:00",
+	} {
+		got, err := mustParse(t, expr).TaskSchedulerArgs()
+		require.NoError(t, err, expr)
+		assert.Equal(t, want, strings.Join(got, " "), expr)
+	}
+
+	for _, expr := range []string{"*/7 * * * *", "0 */5 * * *", "0 2,14 * * *", "0 2 1,15 * *", "0 2 * 6 *", "0 2 1 * 1", "0-30 2 * * *"} {
+		_, err := mustParse(t, expr).TaskSchedulerArgs()
+		assert.ErrorContains(t, err, "can't be set with Task Scheduler", expr)
+	}
+}
+
+// fakeExec records the commands it runs, and answers them with respond.
+type fakeExec struct {
+	calls   []string
+	inputs  []string
+	respond func(cmd string) (string, error)
+}
+
+func (f *fakeExec) exec(input string, name string, args ...string) ([]byte, error) {
+	cmd := strings.Join(append([]string{name}, args...), " ")
+	f.calls = append(f.calls, cmd)
+	f.inputs = append(f.inputs, input)
+	if f.respond != nil {
+		out, err := f.respond(cmd)
+		return []byte(out), err
+	}
+	return nil, nil
+}
+
+var testCommand = []string{"/usr/local/bin/doctl", "cron", "run", "nightly"}
+
+func TestCrontab(t *testing.T) {
+	crontab := "MAILTO=me@example.com\n0 * * * * /usr/bin/backup\n"
+	f := &fakeExec{respond: func(cmd string) (string, error) {
+		if cmd == "crontab -l" {
+			return crontab, nil
+		}
+		return "", nil
+	}}
+	s, err := New(Cron, Options{Exec: f.exec})
+	require.NoError(t, err)
+
+	job := Job{Name: "nightly", Schedule: mustParse(t, "@daily"), Command: append(testCommand, "50% off's")}
+	require.NoError(t, s.Install(job))
+	require.Equal(t, []string{"crontab -l", "crontab -"}, f.calls)
+	assert.Equal(t, "MAILTO=me@example.com\n0 * * * * /usr/bin/backup\n"+
+		"# doctl cron job: nightly\n0 0 * * * /usr/local/bin/doctl cron run nightly '50\\% off'\\''s'\n", f.inputs[1])
+
+	// Installing the job again replaces it.
+	crontab = f.inputs[1]
+	job.Schedule = mustParse(t, "0 3 * * *")
+	job.Command = testCommand
+	require.NoError(t, s.Install(job))
+	assert.Equal(t, "MAILTO=me@example.com\n0 * * * * /usr/bin/backup\n"+
+		"# doctl cron job: nightly\n0 3 * * * /usr/local/bin/doctl cron run nightly\n", f.inputs[3])
+
+	crontab = f.inputs[3]
+	names, err := s.Installed()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nightly"}, names)
+
+	require.NoError(t, s.Remove("nightly"))
+	assert.Equal(t, "MAILTO=me@example.com\n0 * * * * /usr/bin/backup\n", f.inputs[len(f.inputs)-1])
+
+	// Removing a job that isn't installed doesn't write the crontab.
+	crontab = "no crontab for me\n"
+	f.calls = nil
+	f.respond = func(cmd string) (string, error) { return crontab, errors.New("exit status 1") }
+	require.NoError(t, s.Remove("nightly"))
+	assert.Equal(t, []string{"crontab -l"}, f.calls)
+}
+
+func TestSystemd(t *testing.T) {
+	dir := t.TempDir()
+	f := &fakeExec{}
+	s, err := New(Systemd, Options{Dir: dir, Exec: f.exec})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Install(Job{Name: "nightly", Schedule: mustParse(t, "30 2 * * 1-5"), Command: append(testCommand, "100% $HOME")}))
+	assert.Equal(t, []string{"systemctl --user daemon-reload", "systemctl --user enable --now doctl-nightly.timer"}, f.calls)
+
+	service, err := os.ReadFile(filepath.Join(dir, "doctl-nightly.service"))
+	require.NoError(t, err)
+	assert.Contains(t, string(service), `ExecStart="/usr/local/bin/doctl" "cron" "run" "nightly" "100%% $$HOME"`)
+	timer, err := os.ReadFile(filepath.Join(dir, "doctl-nightly.timer"))
+	require.NoError(t, err)
+	assert.Contains(t, string(timer), "OnCalendar=Mon,Tue,Wed,Thu,Fri *-*-* 2:30:00\n")
+
+	names, err := s.Installed()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nightly"}, names)
+
+	f.calls = nil
+	require.NoError(t, s.Remove("nightly"))
+	assert.Equal(t, []string{"systemctl --user disable --now doctl-nightly.timer", "systemctl --user daemon-reload"}, f.calls)
+	assert.NoFileExists(t, filepath.Join(dir, "doctl-nightly.service"))
+	assert.NoFileExists(t, filepath.Join(dir, "doctl-nightly.timer"))
+
+	f.calls = nil
+	require.NoError(t, s.Remove("nightly"))
+	assert.Empty(t, f.calls)
+}
+
+func TestSystemdError(t *testing.T) {
+	f := &fakeExec{respond: func(cmd string) (string, error) {
+		return "Failed to connect to bus: No medium found\n", errors.New("exit status 1")
+	}}
+	s, err := New(Systemd, Options{Dir: t.TempDir(), Exec: f.exec})
+	require.NoError(t, err)
+
+	err = s.Install(Job{Name: "nightly", Schedule: mustParse(t, "@daily"), Command: testCommand})
+	assert.EqualError(t, err, "systemctl --user daemon-reload: Failed to connect to bus: No medium found")
+}
+
+func TestLaunchd(t *testing.T) {
+	dir := t.TempDir()
+	f := &fakeExec{}
+	s, err := New(Launchd, Options{Dir: dir, Exec: f.exec})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Install(Job{Name: "nightly", Schedule: mustParse(t, "30 2 * * 1,3"), Command: append(testCommand, "<&>")}))
+	path := filepath.Join(dir, "com.digitalocean.doctl.cron.nightly.plist")
+	assert.Equal(t, []string{"launchctl load -w " + path}, f.calls)
+
+	plist, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(plist), "\t\t<string>&lt;&amp;&gt;</string>\n\t</array>\n")
+	assert.Contains(t, string(plist), `	<key>StartCalendarInterval</key>
+	<array>
+		<dict>
+			<key>Hour</key>
+			<integer>2</integer>
+			<key>Minute</key>
+			<integer>30</integer>
+			<key>Weekday</key>
+			<integer>1</integer>
+		</dict>
+		<dict>`)
+
+	f.calls = nil
+	require.NoError(t, s.Install(Job{Name: "nightly", Schedule: mustParse(t, "@daily"), Command: testCommand}))
+	assert.Equal(t, []string{"launchctl unload " + path, "launchctl load -w " + path}, f.calls)
+
+	names, err := s.Installed()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nightly"}, names)
+
+	f.calls = nil
+	require.NoError(t, s.Remove("nightly"))
+	assert.Equal(t, []string{"launchctl unload -w " + path}, f.calls)
+	assert.NoFileExists(t, path)
+}
+
+func TestSchtasks(t *testing.T) {
+	f := &fakeExec{respond: func(cmd string) (string, error) {
+		if strings.HasPrefix(cmd, "schtasks /Query") {
+			return "\"\\doctl\\nightly\",\"N/A\",\"Ready\"\n\"\\doctl\\nightly\",\"N/A\",\"Ready\"\n\"\\Other\\task\",\"N/A\",\"Ready\"\n", nil
+		}
+		return "", nil
+	}}
+	s, err := New(TaskScheduler, Options{Exec: f.exec})
+	require.NoError(t, err)
+
+	err = s.Install(Job{Name: "nightly", Schedule: mustParse(t, "30 2 * * *"), Command: []string{`C:\Program Files\doctl\doctl.exe`, "cron", "run", "nightly"}})
+	require.NoError(t, err)
+	assert.Equal(t, `schtasks /Create /F /TN \doctl\nightly /TR "C:\Program Files\doctl\doctl.exe" cron run nightly /SC DAILY /ST 02:30`, f.calls[0])
+
+	names, err := s.Installed()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nightly"}, names)
+
+	f.calls = nil
+	require.NoError(t, s.Remove("nightly"))
+	require.NoError(t, s.Remove("weekly"))
+	assert.Equal(t, []string{
+		"schtasks /Query /FO CSV /NH", `schtasks /Delete /F /TN \doctl\nightly`, "schtasks /Query /FO CSV /NH",
+	}, f.calls)
+
+	_, err = mustParse(t, "0 2,14 * * *").TaskSchedulerArgs()
+	assert.Error(t, err)
+}
+
+func TestWindowsQuote(t *testing.T) {
+	for in, want := range map[string]string{
+		"run":          "run",
+		"":             `""`,
+		"a b":          `"a b"`,
+		`say "hi"`:     `"say \"hi\""`,
+		`C:\dir\`:      `C:\dir\`,
+		`C:\my dir\`:   `"C:\my dir\\"`,
+		`a\"b`:         `"a\\\"b"`,
+		`x\\y with sp`: `"x\\y with sp"`,
+	} {
+		assert.Equal(t, want, windowsQuote(in), in)
+	}
+}
+
+func TestValidName(t *testing.T) {
+	assert.NoError(t, ValidName("nightly-snapshots"))
+	for _, name := range []string{"", "-x", "Nightly", "a b", "a/b", strings.Repeat("a", 64)} {
+		assert.Error(t, ValidName(name), name)
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("at", Options{})
+	assert.EqualError(t, err, `unknown scheduler "at"; it must be one of cron, systemd, launchd, schtasks`)
+	assert.Equal(t, Launchd, Default("darwin"))
+	assert.Equal(t, TaskScheduler, Default("windows"))
+	assert.Equal(t, Cron, Default("linux"))
+}
@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"encoding/csv"
+	"strings"
+)
+
+// schtasksFolder is the Task Scheduler folder of jobs.
+const schtasksFolder = `\doctl\`
+
+// schtasks installs jobs as tasks of Windows Task Scheduler.
+type schtasks struct {
+	exec Exec
+}
+
+// Install implements Scheduler.
+func (s *schtasks) Install(j Job) error {
+	schedule, err := j.Schedule.TaskSchedulerArgs()
+	if err != nil {
+		return err
+	}
+	args := make([]string, len(j.Command))
+	for i, a := range j.Command {
+		args[i] = windowsQuote(a)
+	}
+	_, err = run(s.exec, "", "schtasks", append([]string{
+		"/Create", "/F", "/TN", schtasksFolder + j.Name, "/TR", strings.Join(args, " "),
+	}, schedule...)...)
+	return err
+}
+
+// Remove implements Scheduler.
+func (s *schtasks) Remove(name string) error {
+	names, err := s.Installed()
+	if err != nil {
+		return err
+	}
+	for _, n := range names {
+		if n == name {
+			_, err := run(s.exec, "", "schtasks", "/Delete", "/F", "/TN", schtasksFolder+name)
+			return err
+		}
+	}
+	return nil
+}
+
+// Installed implements Scheduler.
+func (s *schtasks) Installed() ([]string, error) {
+	out, err := run(s.exec, "", "schtasks", "/Query", "/FO", "CSV", "/NH")
+	if err != nil {
+		return nil, err
+	}
+	r := csv.NewReader(strings.NewReader(string(out)))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	seen := map[string]bool{}
+	for _, rec := range records {
+		// Tasks are listed once for each of their triggers.
+		if name, ok := strings.CutPrefix(rec[0], schtasksFolder); ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// windowsQuote quotes s as an argument of a Windows command line.
+func windowsQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"") {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	backslashes := 0
+	for _, r := range s {
+		switch r {
+		case '\\':
+			backslashes++
+			continue
+		case '"':
+			// Backslashes before a quote, and the quote, are escaped.
+			b.WriteString(strings.Repeat(`\`, 2*backslashes+1))
+		default:
+			b.WriteString(strings.Repeat(`\`, backslashes))
+		}
+		backslashes = 0
+		b.WriteRune(r)
+	}
+	b.WriteString(strings.Repeat(`\`, 2*backslashes))
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,113 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// systemdPrefix is the prefix of the unit names of jobs.
+const systemdPrefix = "doctl-"
+
+// systemd installs jobs as a service and timer of the user's systemd
+// instance.
+type systemd struct {
+	dir  string
+	exec Exec
+}
+
+func (s *systemd) unitPath(name, suffix string) string {
+	return filepath.Join(s.dir, systemdPrefix+name+suffix)
+}
+
+func (s *systemd) systemctl(args ...string) error {
+	_, err := run(s.exec, "", "systemctl", append([]string{"--user"}, args...)...)
+	return err
+}
+
+// Install implements Scheduler.
+func (s *systemd) Install(j Job) error {
+	onCalendar, err := j.Schedule.OnCalendar()
+	if err != nil {
+		return err
+	}
+	args := make([]string, len(j.Command))
+	for i, a := range j.Command {
+		args[i] = systemdQuote(a)
+	}
+
+	service := fmt.Sprintf(`# Created by doctl cron install.
+[Unit]
+Description=doctl cron job %s
+
+[Service]
+Type=oneshot
+ExecStart=%s
+`, j.Name, strings.Join(args, " "))
+	timer := fmt.Sprintf(`# Created by doctl cron install.
+[Unit]
+Description=Schedule of doctl cron job %s
+
+[Timer]
+# %s
+OnCalendar=%s
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`, j.Name, j.Schedule, onCalendar)
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.unitPath(j.Name, ".service"), []byte(service), 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.unitPath(j.Name, ".timer"), []byte(timer), 0644); err != nil {
+		return err
+	}
+	if err := s.systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	return s.systemctl("enable", "--now", systemdPrefix+j.Name+".timer")
+}
+
+// Remove implements Scheduler.
+func (s *systemd) Remove(name string) error {
+	timer := s.unitPath(name, ".timer")
+	if _, err := os.Stat(timer); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err := s.systemctl("disable", "--now", systemdPrefix+name+".timer"); err != nil {
+		return err
+	}
+	for _, p := range []string{timer, s.unitPath(name, ".service")} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return s.systemctl("daemon-reload")
+}
+
+// Installed implements Scheduler.
+func (s *systemd) Installed() ([]string, error) {
+	timers, err := filepath.Glob(filepath.Join(s.dir, systemdPrefix+"*.timer"))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(timers))
+	for _, t := range timers {
+		names = append(names, strings.TrimSuffix(strings.TrimPrefix(filepath.Base(t), systemdPrefix), ".timer"))
+	}
+	return names, nil
+}
+
+// systemdQuote quotes s for the command line of a unit, in which % starts a
+// specifier and $ a variable.
+func systemdQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$").Replace(s)
+	return `"` + s + `"`
+}
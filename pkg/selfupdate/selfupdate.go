@@ -0,0 +1,366 @@
+package selfupdate
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/digitalocean/doctl/pkg/extract"
+)
+
+const (
+	// ChannelStable selects the most recent non-prerelease release.
+	ChannelStable = "stable"
+	// ChannelBeta selects the most recent release, including prereleases.
+	ChannelBeta = "beta"
+
+	// DefaultReleasesURL is the GitHub API endpoint listing doctl releases.
+	DefaultReleasesURL = "https://api.github.com/repos/digitalocean/doctl/releases"
+)
+
+// ErrNoRelease is returned when the release feed contains no release for the requested channel.
+var ErrNoRelease = errors.New("no release found for channel")
+
+// ErrNoSigningKey is returned by Download when the updater has no key to verify releases with.
+var ErrNoSigningKey = errors.New("this doctl binary was built without a release signing key, so releases can't be verified; download the release manually or use your package manager")
+
+// Asset is a downloadable file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Release is a single entry of the release feed.
+type Release struct {
+	TagName    string  `json:"tag_name"`
+	Prerelease bool    `json:"prerelease"`
+	Draft      bool    `json:"draft"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Version returns the release's version without the leading "v".
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// Updater finds, downloads, and installs doctl releases.
+type Updater struct {
+	ReleasesURL string
+	Client      *http.Client
+	GOOS        string
+	GOARCH      string
+
+	// PublicKey is the key the checksums of releases must be signed with.
+	PublicKey ed25519.PublicKey
+}
+
+// ParsePublicKey parses a base64-encoded ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid release signing key: %w", err)
+	}
+	if len(b) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid release signing key: expected %d bytes, got %d", ed25519.PublicKeySize, len(b))
+	}
+	return ed25519.PublicKey(b), nil
+}
+
+// New returns an Updater for the current platform that reads the public release feed.
+func New() *Updater {
+	return &Updater{
+		ReleasesURL: DefaultReleasesURL,
+		Client:      http.DefaultClient,
+		GOOS:        runtime.GOOS,
+		GOARCH:      runtime.GOARCH,
+	}
+}
+
+// Latest returns the newest release published on the given channel.
+func (u *Updater) Latest(channel string) (*Release, error) {
+	if channel != ChannelStable && channel != ChannelBeta {
+		return nil, fmt.Errorf("invalid channel %q, must be one of: %s, %s", channel, ChannelStable, ChannelBeta)
+	}
+
+	res, err := u.Client.Get(u.ReleasesURL)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received status code %d fetching %s", res.StatusCode, u.ReleasesURL)
+	}
+
+	var releases []Release
+	if err := json.NewDecoder(res.Body).Decode(&releases); err != nil {
+		return nil, err
+	}
+
+	// The feed is ordered newest first.
+	for i := range releases {
+		r := releases[i]
+		if r.Draft || (r.Prerelease && channel == ChannelStable) {
+			continue
+		}
+		return &r, nil
+	}
+
+	return nil, fmt.Errorf("%w %s", ErrNoRelease, channel)
+}
+
+// archiveName returns the name of the release archive for the updater's platform.
+func (u *Updater) archiveName(version string) string {
+	ext := "tar.gz"
+	if u.GOOS == "windows" {
+		ext = "zip"
+	}
+	return fmt.Sprintf("doctl-%s-%s-%s.%s", version, u.GOOS, u.GOARCH, ext)
+}
+
+func binaryName(goos string) string {
+	if goos == "windows" {
+		return "doctl.exe"
+	}
+	return "doctl"
+}
+
+func findAsset(r *Release, name string) (Asset, error) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, nil
+		}
+	}
+	return Asset{}, fmt.Errorf("release %s has no asset named %s", r.TagName, name)
+}
+
+// Download fetches the release archive for the updater's platform into dir, verifies
+// it against the release's published SHA-256 checksums, and extracts it.  The checksums
+// must carry a detached signature made with the updater's public key.  The path of
+// the extracted doctl binary is returned.
+func (u *Updater) Download(r *Release, dir string) (string, error) {
+	if len(u.PublicKey) == 0 {
+		return "", ErrNoSigningKey
+	}
+
+	version := r.Version()
+	archive, err := findAsset(r, u.archiveName(version))
+	if err != nil {
+		return "", err
+	}
+	checksumsName := fmt.Sprintf("doctl-%s-checksums.sha256", version)
+	checksums, err := findAsset(r, checksumsName)
+	if err != nil {
+		return "", err
+	}
+	signature, err := findAsset(r, checksumsName+".sig")
+	if err != nil {
+		return "", err
+	}
+
+	sums, err := u.fetchChecksums(checksums.URL, signature.URL)
+	if err != nil {
+		return "", err
+	}
+	want, ok := sums[archive.Name]
+	if !ok {
+		return "", fmt.Errorf("no checksum published for %s", archive.Name)
+	}
+
+	archivePath := filepath.Join(dir, archive.Name)
+	got, err := u.fetchFile(archive.URL, archivePath)
+	if err != nil {
+		return "", err
+	}
+	if got != want {
+		return "", fmt.Errorf("checksum mismatch for %s: expected %s, got %s", archive.Name, want, got)
+	}
+
+	if err := extract.Extract(archivePath, dir); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, binaryName(u.GOOS)), nil
+}
+
+// fetchFile downloads a URL into a file and returns the hex SHA-256 of its contents.
+func (u *Updater) fetchFile(url, path string) (string, error) {
+	res, err := u.Client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("received status code %d downloading %s", res.StatusCode, url)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), res.Body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fetchChecksums downloads a checksum file in sha256sum format, verifies its
+// signature, and maps file names to sums.
+func (u *Updater) fetchChecksums(url, signatureURL string) (map[string]string, error) {
+	body, err := u.fetch(url)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := u.fetch(signatureURL)
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(u.PublicKey, body, decodeSignature(sig)) {
+		return nil, fmt.Errorf("the signature of %s is not valid; the release may have been tampered with", url)
+	}
+
+	sums := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 {
+			sums[fields[1]] = fields[0]
+		}
+	}
+	return sums, scanner.Err()
+}
+
+// decodeSignature accepts a raw signature, as written by openssl pkeyutl, or a
+// base64-encoded one.
+func decodeSignature(sig []byte) []byte {
+	if len(sig) == ed25519.SignatureSize {
+		return sig
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return sig
+	}
+	return decoded
+}
+
+// fetch downloads a small file.
+func (u *Updater) fetch(url string) ([]byte, error) {
+	res, err := u.Client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received status code %d downloading %s", res.StatusCode, url)
+	}
+	return io.ReadAll(io.LimitReader(res.Body, 1<<20))
+}
+
+// SelfTest runs the given binary and reports an error if it doesn't work.
+type SelfTest func(binary string) error
+
+// VersionSelfTest checks that a binary can at least report its version.
+func VersionSelfTest(binary string) error {
+	out, err := exec.Command(binary, "version").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("running %s version: %v: %s", binary, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Replace swaps the binary at target for the one at replacement.  The current binary is
+// kept alongside as target+".old" until the new one passes the self test; if it fails,
+// the original binary is restored.  Both renames happen within target's directory so each
+// step is atomic on the file systems doctl supports.
+func Replace(target, replacement string, test SelfTest) error {
+	info, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+
+	// Stage the new binary next to the target so the final rename does not cross file systems.
+	staged := target + ".new"
+	if err := copyFile(replacement, staged, info.Mode()); err != nil {
+		return err
+	}
+
+	backup := target + ".old"
+	os.Remove(backup)
+	if err := os.Rename(target, backup); err != nil {
+		os.Remove(staged)
+		return err
+	}
+	if err := os.Rename(staged, target); err != nil {
+		os.Rename(backup, target)
+		return err
+	}
+
+	if test != nil {
+		if err := test(target); err != nil {
+			if rerr := restore(target, backup); rerr != nil {
+				return fmt.Errorf("new binary failed self test (%v) and rollback failed: %v", err, rerr)
+			}
+			return fmt.Errorf("new binary failed self test, rolled back: %w", err)
+		}
+	}
+
+	// A running executable can't be removed on Windows; the backup is cleaned up by the next update.
+	os.Remove(backup)
+	return nil
+}
+
+// restore puts the backup back at target. Like the forward path, it moves the
+// file at target aside before renaming the backup into its place, since
+// Windows can't rename over an executable that may still be in use.
+func restore(target, backup string) error {
+	rejected := target + ".new"
+	os.Remove(rejected)
+	if err := os.Rename(target, rejected); err != nil {
+		return err
+	}
+	if err := os.Rename(backup, target); err != nil {
+		os.Rename(rejected, target)
+		return err
+	}
+	// Like the backup, the rejected binary is cleaned up by the next update
+	// if it can't be removed now.
+	os.Remove(rejected)
+	return nil
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
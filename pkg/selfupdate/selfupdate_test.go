@@ -0,0 +1,186 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tarGz(t *testing.T, name string, contents []byte) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(contents)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write(contents)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func testServer(t *testing.T, archive []byte, checksum string) (*httptest.Server, *Updater) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	checksums := []byte(fmt.Sprintf("%s  doctl-1.2.0-linux-amd64.tar.gz\n", checksum))
+	signature := ed25519.Sign(priv, checksums)
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	releases := []Release{
+		{TagName: "v1.3.0-beta", Prerelease: true},
+		{TagName: "v1.2.0", Assets: []Asset{
+			{Name: "doctl-1.2.0-linux-amd64.tar.gz", URL: srv.URL + "/archive"},
+			{Name: "doctl-1.2.0-checksums.sha256", URL: srv.URL + "/checksums"},
+			{Name: "doctl-1.2.0-checksums.sha256.sig", URL: srv.URL + "/signature"},
+		}},
+	}
+	mux.HandleFunc("/releases", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(releases)
+	})
+	mux.HandleFunc("/archive", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	})
+	mux.HandleFunc("/checksums", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(checksums)
+	})
+	mux.HandleFunc("/signature", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(signature)
+	})
+
+	return srv, &Updater{
+		ReleasesURL: srv.URL + "/releases",
+		Client:      srv.Client(),
+		GOOS:        "linux",
+		GOARCH:      "amd64",
+		PublicKey:   pub,
+	}
+}
+
+func TestLatest(t *testing.T) {
+	_, u := testServer(t, nil, "")
+
+	r, err := u.Latest(ChannelStable)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.0", r.Version())
+
+	r, err = u.Latest(ChannelBeta)
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0-beta", r.Version())
+
+	_, err = u.Latest("nightly")
+	assert.Error(t, err)
+}
+
+func TestDownload(t *testing.T) {
+	archive := tarGz(t, "doctl", []byte("new binary"))
+	sum := sha256.Sum256(archive)
+
+	t.Run("verified", func(t *testing.T) {
+		_, u := testServer(t, archive, hex.EncodeToString(sum[:]))
+		r, err := u.Latest(ChannelStable)
+		require.NoError(t, err)
+
+		bin, err := u.Download(r, t.TempDir())
+		require.NoError(t, err)
+
+		got, err := os.ReadFile(bin)
+		require.NoError(t, err)
+		assert.Equal(t, "new binary", string(got))
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		_, u := testServer(t, archive, "deadbeef")
+		r, err := u.Latest(ChannelStable)
+		require.NoError(t, err)
+
+		_, err = u.Download(r, t.TempDir())
+		assert.ErrorContains(t, err, "checksum mismatch")
+	})
+
+	t.Run("wrong signing key", func(t *testing.T) {
+		_, u := testServer(t, archive, hex.EncodeToString(sum[:]))
+		u.PublicKey, _, _ = ed25519.GenerateKey(rand.Reader)
+		r, err := u.Latest(ChannelStable)
+		require.NoError(t, err)
+
+		_, err = u.Download(r, t.TempDir())
+		assert.ErrorContains(t, err, "signature")
+	})
+
+	t.Run("no signing key", func(t *testing.T) {
+		_, u := testServer(t, archive, hex.EncodeToString(sum[:]))
+		u.PublicKey = nil
+		r, err := u.Latest(ChannelStable)
+		require.NoError(t, err)
+
+		_, err = u.Download(r, t.TempDir())
+		assert.Equal(t, ErrNoSigningKey, err)
+	})
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	got, err := ParsePublicKey(base64.StdEncoding.EncodeToString(pub))
+	require.NoError(t, err)
+	assert.Equal(t, pub, got)
+
+	_, err = ParsePublicKey("c2hvcnQ=")
+	assert.Error(t, err)
+	_, err = ParsePublicKey("not base64!")
+	assert.Error(t, err)
+}
+
+func TestReplace(t *testing.T) {
+	setup := func(t *testing.T) (string, string) {
+		dir := t.TempDir()
+		target := filepath.Join(dir, "doctl")
+		replacement := filepath.Join(dir, "replacement")
+		require.NoError(t, os.WriteFile(target, []byte("old"), 0755))
+		require.NoError(t, os.WriteFile(replacement, []byte("new"), 0644))
+		return target, replacement
+	}
+
+	t.Run("success", func(t *testing.T) {
+		target, replacement := setup(t)
+
+		require.NoError(t, Replace(target, replacement, func(string) error { return nil }))
+
+		got, _ := os.ReadFile(target)
+		assert.Equal(t, "new", string(got))
+		info, _ := os.Stat(target)
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+		assert.NoFileExists(t, target+".old")
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		target, replacement := setup(t)
+
+		err := Replace(target, replacement, func(string) error { return errors.New("boom") })
+		assert.ErrorContains(t, err, "rolled back")
+
+		got, _ := os.ReadFile(target)
+		assert.Equal(t, "old", string(got))
+		assert.NoFileExists(t, target+".old")
+		assert.NoFileExists(t, target+".new")
+	})
+}
@@ -0,0 +1,344 @@
+package spaces
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPartSize is the size of each part of a multipart upload.
+	DefaultPartSize = 64 << 20
+
+	// minPartSize is the smallest part size accepted by Spaces for all but the last part.
+	minPartSize = 5 << 20
+
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	timeFormat      = "20060102T150405Z"
+	dateFormat      = "20060102"
+)
+
+// Client is a minimal client for the S3-compatible Spaces API. It supports only
+// what doctl needs to stage files: multipart uploads, presigned downloads, and deletes.
+type Client struct {
+	// Endpoint is the base URL of the Spaces API, e.g. https://nyc3.digitaloceanspaces.com.
+	Endpoint  string
+	Region    string
+	AccessKey string
+	SecretKey string
+	PartSize  int64
+	HTTP      *http.Client
+
+	// now is replaced for testing.
+	now func() time.Time
+}
+
+// New returns a Client for the Spaces API in the given region.
+func New(region, accessKey, secretKey string) *Client {
+	return &Client{
+		Endpoint:  fmt.Sprintf("https://%s.digitaloceanspaces.com", region),
+		Region:    region,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		PartSize:  DefaultPartSize,
+		HTTP:      http.DefaultClient,
+	}
+}
+
+// Upload stores size bytes read from r as bucket/key using a multipart upload.
+// If any part fails, the upload is aborted so no partial object is left behind.
+func (c *Client) Upload(bucket, key string, r io.ReaderAt, size int64) error {
+	partSize := c.PartSize
+	if partSize < minPartSize {
+		partSize = minPartSize
+	}
+
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	res, err := c.do(http.MethodPost, bucket, key, url.Values{"uploads": {""}}, nil, 0)
+	if err != nil {
+		return err
+	}
+	err = decodeXML(res, &initiated)
+	if err != nil {
+		return fmt.Errorf("starting upload of %s: %w", key, err)
+	}
+	uploadID := initiated.UploadID
+
+	type part struct {
+		PartNumber int
+		ETag       string
+	}
+	complete := struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []part   `xml:"Part"`
+	}{}
+
+	for n, offset := 1, int64(0); offset < size || n == 1; n, offset = n+1, offset+partSize {
+		length := partSize
+		if offset+length > size {
+			length = size - offset
+		}
+
+		q := url.Values{
+			"partNumber": {strconv.Itoa(n)},
+			"uploadId":   {uploadID},
+		}
+		res, err := c.do(http.MethodPut, bucket, key, q, io.NewSectionReader(r, offset, length), length)
+		if err == nil {
+			err = checkStatus(res)
+		}
+		if err != nil {
+			c.abort(bucket, key, uploadID)
+			return fmt.Errorf("uploading part %d of %s: %w", n, key, err)
+		}
+		complete.Parts = append(complete.Parts, part{PartNumber: n, ETag: res.Header.Get("ETag")})
+	}
+
+	body, err := xml.Marshal(complete)
+	if err != nil {
+		c.abort(bucket, key, uploadID)
+		return err
+	}
+	res, err = c.do(http.MethodPost, bucket, key, url.Values{"uploadId": {uploadID}}, bytes.NewReader(body), int64(len(body)))
+	if err == nil {
+		err = checkStatus(res)
+	}
+	if err != nil {
+		c.abort(bucket, key, uploadID)
+		return fmt.Errorf("completing upload of %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes bucket/key.
+func (c *Client) Delete(bucket, key string) error {
+	res, err := c.do(http.MethodDelete, bucket, key, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	return checkStatus(res)
+}
+
+// PresignGet returns a URL that allows anyone holding it to download bucket/key
+// until it expires.
+func (c *Client) PresignGet(bucket, key string, expires time.Duration) (string, error) {
+	u, err := c.objectURL(bucket, key)
+	if err != nil {
+		return "", err
+	}
+
+	now := c.timeNow()
+	q := url.Values{
+		"X-Amz-Algorithm":     {"AWS4-HMAC-SHA256"},
+		"X-Amz-Credential":    {c.AccessKey + "/" + c.scope(now)},
+		"X-Amz-Date":          {now.Format(timeFormat)},
+		"X-Amz-Expires":       {strconv.Itoa(int(expires.Seconds()))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	u.RawQuery = canonicalQuery(q)
+
+	headers := http.Header{}
+	headers.Set("Host", u.Host)
+	sig := c.signature(now, http.MethodGet, u, headers, unsignedPayload)
+
+	u.RawQuery += "&X-Amz-Signature=" + sig
+	return u.String(), nil
+}
+
+func (c *Client) abort(bucket, key, uploadID string) {
+	res, err := c.do(http.MethodDelete, bucket, key, url.Values{"uploadId": {uploadID}}, nil, 0)
+	if err == nil {
+		res.Body.Close()
+	}
+}
+
+// do sends a request signed with the client's credentials.
+func (c *Client) do(method, bucket, key string, q url.Values, body io.Reader, length int64) (*http.Response, error) {
+	u, err := c.objectURL(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = canonicalQuery(q)
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = length
+
+	now := c.timeNow()
+	req.Header.Set("Host", u.Host)
+	req.Header.Set("X-Amz-Date", now.Format(timeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	sig := c.signature(now, method, u, req.Header, unsignedPayload)
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.AccessKey, c.scope(now), signedHeaders(req.Header), sig))
+	req.Header.Del("Host")
+
+	return c.HTTP.Do(req)
+}
+
+func (c *Client) objectURL(bucket, key string) (*url.URL, error) {
+	u, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = "/" + bucket + "/" + strings.TrimPrefix(key, "/")
+	// Keep the encoding of the request path identical to the one that is signed.
+	u.RawPath = escapePath(u.Path)
+	return u, nil
+}
+
+func (c *Client) timeNow() time.Time {
+	if c.now != nil {
+		return c.now().UTC()
+	}
+	return time.Now().UTC()
+}
+
+func (c *Client) scope(t time.Time) string {
+	return credentialScope(t, c.Region, "s3")
+}
+
+func credentialScope(t time.Time, region, service string) string {
+	return fmt.Sprintf("%s/%s/%s/aws4_request", t.Format(dateFormat), region, service)
+}
+
+// signature computes the AWS Signature Version 4 signature of a request to Spaces.
+func (c *Client) signature(t time.Time, method string, u *url.URL, headers http.Header, payloadHash string) string {
+	return signV4(c.SecretKey, c.Region, "s3", t, method, u, headers, payloadHash)
+}
+
+// signV4 computes an AWS Signature Version 4 signature for a request to a
+// service, signing every header in headers. u.RawQuery must already be in
+// canonical form.
+func signV4(secretKey, region, service string, t time.Time, method string, u *url.URL, headers http.Header, payloadHash string) string {
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(signedHeaders(headers), ";") {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, strings.TrimSpace(headers.Get(name)))
+	}
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		escapePath(u.Path),
+		u.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders(headers),
+		payloadHash,
+	}, "\n")
+
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		t.Format(timeFormat),
+		credentialScope(t, region, service),
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), t.Format(dateFormat))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func signedHeaders(h http.Header) string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ";")
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range q[k] {
+			parts = append(parts, escape(k)+"="+escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = escape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// escape percent-encodes everything but the unreserved characters of RFC 3986,
+// as required by Signature Version 4.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ('A' <= ch && ch <= 'Z') || ('a' <= ch && ch <= 'z') || ('0' <= ch && ch <= '9') ||
+			ch == '-' || ch == '_' || ch == '.' || ch == '~' {
+			b.WriteByte(ch)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func checkStatus(res *http.Response) error {
+	defer res.Body.Close()
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		io.Copy(io.Discard, res.Body)
+		return nil
+	}
+	return statusError(res)
+}
+
+func decodeXML(res *http.Response, v any) error {
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return statusError(res)
+	}
+	return xml.NewDecoder(res.Body).Decode(v)
+}
+
+func statusError(res *http.Response) error {
+	var e struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+	if xml.Unmarshal(body, &e) == nil && e.Code != "" {
+		if e.Message != "" {
+			return fmt.Errorf("spaces returned %d %s: %s", res.StatusCode, e.Code, e.Message)
+		}
+		return fmt.Errorf("spaces returned %d %s", res.StatusCode, e.Code)
+	}
+	return fmt.Errorf("spaces returned status code %d", res.StatusCode)
+}
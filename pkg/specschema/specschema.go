@@ -0,0 +1,347 @@
+// Package specschema validates spec files against the JSON Schemas embedded in
+// doctl, so that they can be checked without access to the API.
+//
+// The schemas use a subset of JSON Schema: type, properties, required,
+// additionalProperties, items, enum, pattern, format, minimum, maximum,
+// minLength, maxLength, minItems, anyOf, and local $refs. The x-catalog
+// keyword names a catalog, such as the regions or sizes of the API, that a
+// string value must be in. Catalogs are passed to Validate, and the keyword is
+// ignored for those that aren't.
+package specschema
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The schemas embedded in doctl.
+const (
+	App      = "app"
+	Firewall = "firewall"
+	Stack    = "stack"
+)
+
+// The catalogs that schemas refer to with x-catalog.
+const (
+	CatalogRegions          = "regions"
+	CatalogSizes            = "sizes"
+	CatalogImages           = "images"
+	CatalogAppRegions       = "app-regions"
+	CatalogAppInstanceSizes = "app-instance-sizes"
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// Schema is a parsed JSON Schema.
+type Schema struct {
+	root map[string]any
+}
+
+// Load returns the embedded schema with the given name.
+func Load(name string) (*Schema, error) {
+	b, err := schemaFiles.ReadFile("schemas/" + name + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("unknown schema %q", name)
+	}
+	var root map[string]any
+	if err := json.Unmarshal(b, &root); err != nil {
+		return nil, fmt.Errorf("parsing schema %s: %w", name, err)
+	}
+	return &Schema{root: root}, nil
+}
+
+// Validate checks doc, a document decoded from JSON, against the schema and
+// returns a message for each problem found. Each message starts with the path
+// of the value it is about, such as services[0].name.
+func (s *Schema) Validate(doc any, catalogs map[string][]string) []string {
+	v := &validator{root: s.root, catalogs: catalogs}
+	v.validate(s.root, doc, "")
+	return v.errs
+}
+
+type validator struct {
+	root     map[string]any
+	catalogs map[string][]string
+	errs     []string
+}
+
+func (v *validator) errorf(path, format string, a ...any) {
+	if path == "" {
+		path = "spec"
+	}
+	v.errs = append(v.errs, path+": "+fmt.Sprintf(format, a...))
+}
+
+func (v *validator) validate(schema map[string]any, value any, path string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := v.resolve(ref)
+		if err != nil {
+			v.errorf(path, "%v", err)
+			return
+		}
+		schema = target
+	}
+
+	if types, ok := schema["type"]; ok && !matchesType(types, value) {
+		v.errorf(path, "must be %s", describeType(types))
+		return
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !containsValue(enum, value) {
+		v.errorf(path, "must be one of: %s", joinValues(enum))
+	}
+
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		v.validateAnyOf(anyOf, value, path)
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		v.validateObject(schema, value, path)
+	case []any:
+		v.validateArray(schema, value, path)
+	case string:
+		v.validateString(schema, value, path)
+	case float64:
+		if min, ok := schema["minimum"].(float64); ok && value < min {
+			v.errorf(path, "must be at least %v", min)
+		}
+		if max, ok := schema["maximum"].(float64); ok && value > max {
+			v.errorf(path, "must be at most %v", max)
+		}
+	}
+}
+
+func (v *validator) validateObject(schema map[string]any, obj map[string]any, path string) {
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			if _, ok := obj[r.(string)]; !ok {
+				v.errorf(joinPath(path, r.(string)), "is required")
+			}
+		}
+	}
+
+	props, _ := schema["properties"].(map[string]any)
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if prop, ok := props[k].(map[string]any); ok {
+			v.validate(prop, obj[k], joinPath(path, k))
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.errorf(joinPath(path, k), "is not a known field")
+			}
+		case map[string]any:
+			v.validate(additional, obj[k], joinPath(path, k))
+		}
+	}
+}
+
+func (v *validator) validateArray(schema map[string]any, arr []any, path string) {
+	if min, ok := schema["minItems"].(float64); ok && float64(len(arr)) < min {
+		v.errorf(path, "must have at least %v items", min)
+	}
+	if items, ok := schema["items"].(map[string]any); ok {
+		for i, item := range arr {
+			v.validate(items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+func (v *validator) validateString(schema map[string]any, s string, path string) {
+	if min, ok := schema["minLength"].(float64); ok && float64(len(s)) < min {
+		v.errorf(path, "must be at least %v characters long", min)
+	}
+	if max, ok := schema["maxLength"].(float64); ok && float64(len(s)) > max {
+		v.errorf(path, "must be at most %v characters long", max)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			v.errorf(path, "invalid pattern %q in schema", pattern)
+		} else if !re.MatchString(s) {
+			msg, _ := schema["x-pattern-message"].(string)
+			if msg == "" {
+				msg = fmt.Sprintf("must match %s", pattern)
+			}
+			v.errorf(path, "%q %s", s, msg)
+		}
+	}
+	if format, ok := schema["format"].(string); ok && !matchesFormat(format, s) {
+		v.errorf(path, "%q is not %s", s, formatDescriptions[format])
+	}
+	if name, ok := schema["x-catalog"].(string); ok {
+		if catalog := v.catalogs[name]; len(catalog) > 0 && !containsString(catalog, s) {
+			v.errorf(path, "%q is not one of the known %s", s, name)
+		}
+	}
+}
+
+// validateAnyOf checks that value matches one of the alternatives. If it
+// doesn't, the problems with the first alternative of its type are reported.
+func (v *validator) validateAnyOf(alternatives []any, value any, path string) {
+	var closest []string
+	for _, a := range alternatives {
+		alt := a.(map[string]any)
+		sub := &validator{root: v.root, catalogs: v.catalogs}
+		sub.validate(alt, value, path)
+		if len(sub.errs) == 0 {
+			return
+		}
+		if closest == nil && (alt["type"] == nil || matchesType(alt["type"], value)) {
+			closest = sub.errs
+		}
+	}
+	if closest == nil {
+		v.errorf(path, "does not match any of the allowed forms")
+		return
+	}
+	v.errs = append(v.errs, closest...)
+}
+
+// resolve returns the schema a local $ref, such as #/$defs/component, points to.
+func (v *validator) resolve(ref string) (map[string]any, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q in schema", ref)
+	}
+	var node any = v.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid $ref %q in schema", ref)
+		}
+		node = m[part]
+	}
+	schema, ok := node.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid $ref %q in schema", ref)
+	}
+	return schema, nil
+}
+
+func matchesType(types any, value any) bool {
+	switch t := types.(type) {
+	case string:
+		return isType(t, value)
+	case []any:
+		for _, name := range t {
+			if isType(name.(string), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isType(name string, value any) bool {
+	switch name {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "null":
+		return value == nil
+	}
+	return false
+}
+
+func describeType(types any) string {
+	switch t := types.(type) {
+	case string:
+		return article(t)
+	case []any:
+		names := make([]string, len(t))
+		for i, name := range t {
+			names[i] = article(name.(string))
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(types)
+}
+
+func article(typ string) string {
+	if typ == "object" || typ == "array" || typ == "integer" {
+		return "an " + typ
+	}
+	return "a " + typ
+}
+
+// formatDescriptions describe the formats used by the embedded schemas.
+var formatDescriptions = map[string]string{
+	"ip-or-cidr": "an IP address or CIDR block",
+}
+
+// matchesFormat checks the formats used by the embedded schemas. Unknown
+// formats are not checked, as JSON Schema allows.
+func matchesFormat(format, s string) bool {
+	switch format {
+	case "ip-or-cidr":
+		if net.ParseIP(s) != nil {
+			return true
+		}
+		_, _, err := net.ParseCIDR(s)
+		return err == nil
+	}
+	return true
+}
+
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func joinValues(values []any) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = fmt.Sprint(v)
+	}
+	return strings.Join(s, ", ")
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
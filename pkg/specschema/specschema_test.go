@@ -0,0 +1,63 @@
+package specschema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	for _, name := range []string{App, Firewall, Stack} {
+		_, err := Load(name)
+		assert.NoError(t, err, name)
+	}
+
+	_, err := Load("droplet")
+	assert.EqualError(t, err, `unknown schema "droplet"`)
+}
+
+func TestValidate(t *testing.T) {
+	schema := &Schema{root: map[string]any{}}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"additionalProperties": false,
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string", "pattern": "^[a-z]+$", "maxLength": 5},
+			"count": {"type": "integer", "minimum": 1},
+			"region": {"type": "string", "x-catalog": "regions"},
+			"items": {"type": "array", "items": {"$ref": "#/$defs/item"}}
+		},
+		"$defs": {
+			"item": {"anyOf": [{"type": "integer"}, {"type": "string", "enum": ["a", "b"]}]}
+		}
+	}`), &schema.root))
+
+	doc := func(s string) any {
+		var v any
+		require.NoError(t, json.Unmarshal([]byte(s), &v))
+		return v
+	}
+
+	assert.Empty(t, schema.Validate(doc(`{"name": "web", "count": 2, "region": "nyc3", "items": [1, "a"]}`), nil))
+	assert.Equal(t, []string{"spec: must be an object"}, schema.Validate(doc(`[]`), nil))
+	assert.Equal(t, []string{
+		"name: is required",
+		"count: must be an integer",
+		"extra: is not a known field",
+		"items[1]: must be one of: a, b",
+		"items[2]: does not match any of the allowed forms",
+	}, schema.Validate(doc(`{"count": 1.5, "extra": true, "items": [1, "c", true]}`), nil))
+	assert.Equal(t, []string{
+		"name: must be at most 5 characters long",
+		`name: "Webserver" must match ^[a-z]+$`,
+	}, schema.Validate(doc(`{"name": "Webserver"}`), nil))
+
+	// Catalogs are only checked when they are given.
+	catalogs := map[string][]string{"regions": {"nyc3"}}
+	assert.Empty(t, schema.Validate(doc(`{"name": "web", "region": "mars"}`), nil))
+	assert.Equal(t, []string{`region: "mars" is not one of the known regions`},
+		schema.Validate(doc(`{"name": "web", "region": "mars"}`), catalogs))
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/digitalocean/doctl/pkg/runner"
+)
+
+// SCPRunner copies files to or from a host with scp.
+type SCPRunner struct {
+	User    string
+	Host    string
+	KeyPath string
+	Port    int
+	// Upload copies LocalPaths to RemotePath; otherwise RemotePath is copied to LocalPaths[0].
+	Upload     bool
+	LocalPaths []string
+	RemotePath string
+	Recursive  bool
+
+	// Stdout and Stderr default to those of doctl.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+var _ runner.Runner = &SCPRunner{}
+
+// Run scp.
+func (r *SCPRunner) Run() error {
+	cmd := exec.Command("scp", r.args()...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if r.Stdout != nil {
+		cmd.Stdout = r.Stdout
+	}
+	if r.Stderr != nil {
+		cmd.Stderr = r.Stderr
+	}
+	return cmd.Run()
+}
+
+func (r *SCPRunner) args() []string {
+	// BatchMode keeps scp from prompting, since several copies may run at once.
+	args := []string{"-o", "BatchMode=yes"}
+	if r.KeyPath != "" {
+		args = append(args, "-i", r.KeyPath)
+	}
+	if r.Port > 0 {
+		args = append(args, "-P", strconv.Itoa(r.Port))
+	}
+	if r.Recursive {
+		args = append(args, "-r")
+	}
+
+	host := r.Host
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if r.User != "" {
+		host = r.User + "@" + host
+	}
+	remote := host + ":" + r.RemotePath
+
+	if r.Upload {
+		return append(append(args, r.LocalPaths...), remote)
+	}
+	return append(args, remote, r.LocalPaths[0])
+}
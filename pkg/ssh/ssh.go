@@ -15,6 +15,7 @@ package ssh
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strconv"
@@ -35,6 +36,11 @@ type Runner struct {
 	AgentForwarding bool
 	Command         string
 	RetriesMax      int
+
+	// Stdin, Stdout, and Stderr default to those of doctl.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
 }
 
 var _ runner.Runner = &Runner{}
@@ -74,6 +80,15 @@ func (r *Runner) Run() error {
 	cmd.Stderr = os.Stderr
 	cmd.Stdout = os.Stdout
 	cmd.Stdin = os.Stdin
+	if r.Stderr != nil {
+		cmd.Stderr = r.Stderr
+	}
+	if r.Stdout != nil {
+		cmd.Stdout = r.Stdout
+	}
+	if r.Stdin != nil {
+		cmd.Stdin = r.Stdin
+	}
 
 	err := cmd.Run()
 	if err != nil {
@@ -0,0 +1,75 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package doctl
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/viper"
+)
+
+// ReadOnlyErr is returned for API calls that would change something while
+// doctl is in read-only mode.
+type ReadOnlyErr struct {
+	Method string
+	Path   string
+	// Operation describes a change that isn't an HTTP request, such as a
+	// deploy by the serverless plugin. Method and Path are empty then.
+	Operation string
+}
+
+var _ error = &ReadOnlyErr{}
+
+func (e *ReadOnlyErr) Error() string {
+	if e.Operation != "" {
+		return fmt.Sprintf("read-only mode: refusing to %s (unset --%s to allow changes)", e.Operation, ArgReadOnly)
+	}
+	return fmt.Sprintf("read-only mode: refusing to send %s %s (unset --%s to allow changes)", e.Method, e.Path, ArgReadOnly)
+}
+
+// ReadOnly reports whether doctl is in read-only mode.
+func ReadOnly() bool {
+	return viper.GetBool(ArgReadOnly)
+}
+
+// ReadOnlyClient returns client, or in read-only mode, a copy of it that
+// refuses requests that could change something. Every HTTP client that calls
+// DigitalOcean, or the resources it hosts, such as clusters and functions,
+// must go through it.
+func ReadOnlyClient(client *http.Client) *http.Client {
+	if !ReadOnly() {
+		return client
+	}
+	c := *client
+	c.Transport = &readOnlyTransport{wrap: transportOrDefault(c.Transport)}
+	return &c
+}
+
+// readOnlyTransport rejects every request that could change something before
+// it is sent.
+type readOnlyTransport struct {
+	wrap http.RoundTripper
+}
+
+func (t *readOnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.wrap.RoundTrip(req)
+	}
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, &ReadOnlyErr{Method: req.Method, Path: req.URL.Path}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package doctl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/digitalocean/godo"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnlyClient(t *testing.T) {
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		w.Write([]byte(`{"account":{}}`))
+	}))
+	defer srv.Close()
+
+	viper.Set("api-url", srv.URL)
+	viper.Set(ArgReadOnly, true)
+	defer func() {
+		viper.Set("api-url", "")
+		viper.Set(ArgReadOnly, false)
+	}()
+
+	client, err := (&LiveConfig{}).GetGodoClient(false, true, "token")
+	require.NoError(t, err)
+
+	_, _, err = client.Account.Get(context.Background())
+	require.NoError(t, err)
+
+	_, err = client.Droplets.Delete(context.Background(), 1)
+	var roErr *ReadOnlyErr
+	require.True(t, errors.As(err, &roErr), "unexpected error: %v", err)
+	assert.Equal(t, &ReadOnlyErr{Method: http.MethodDelete, Path: "/v2/droplets/1"}, roErr)
+	assert.ErrorContains(t, err, "read-only mode: refusing to send DELETE /v2/droplets/1")
+
+	_, _, err = client.Droplets.Create(context.Background(), &godo.DropletCreateRequest{Name: "web"})
+	assert.ErrorContains(t, err, "read-only mode: refusing to send POST /v2/droplets")
+
+	assert.Equal(t, []string{http.MethodGet}, methods)
+}
+
+func TestReadOnlyHTTPClient(t *testing.T) {
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+	}))
+	defer srv.Close()
+
+	base := &http.Client{}
+	assert.Same(t, base, ReadOnlyClient(base))
+
+	viper.Set(ArgReadOnly, true)
+	defer viper.Set(ArgReadOnly, false)
+	client := ReadOnlyClient(base)
+	assert.Nil(t, base.Transport, "the client given must not be changed")
+
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	_, err = client.Post(srv.URL+"/indices", "application/json", nil)
+	assert.ErrorContains(t, err, "read-only mode: refusing to send POST /indices")
+	assert.Equal(t, []string{http.MethodGet}, methods)
+
+	assert.EqualError(t, &ReadOnlyErr{Operation: "run the serverless plugin's deploy command"},
+		"read-only mode: refusing to run the serverless plugin's deploy command (unset --read-only to allow changes)")
+}
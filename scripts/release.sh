@@ -7,9 +7,15 @@ if [[ ! -x $(command -v goreleaser) ]] ; then
   exit 1
 fi
 
+skip_sign=""
+if [[ -z "${DOCTL_RELEASE_SIGNING_KEY:-}" || -z "${DOCTL_RELEASE_SIGNING_KEY_FILE:-}" ]] ; then
+  echo "warning: DOCTL_RELEASE_SIGNING_KEY and DOCTL_RELEASE_SIGNING_KEY_FILE are not set, so the release won't be signed and self-update won't install it" >&2
+  skip_sign="--skip=sign"
+fi
+
 echo "generating changelog"
 release_notes="$(make _changelog)"
 
-goreleaser --clean --release-notes="${release_notes}"
+goreleaser --clean ${skip_sign:+"$skip_sign"} --release-notes="${release_notes}"
 
 rm -f "$release_notes"
@@ -0,0 +1,22 @@
+#!/usr/bin/env bash
+#
+# Signs a release checksum file with the ed25519 release signing key, whose
+# PEM-encoded private key is read from DOCTL_RELEASE_SIGNING_KEY_FILE. The
+# matching public key is built into doctl and checked by `doctl self-update`.
+
+set -euo pipefail
+
+if [[ $# -ne 2 ]]; then
+  echo "usage: $0 <checksums> <signature>" >&2
+  exit 1
+fi
+
+if [[ -z "${DOCTL_RELEASE_SIGNING_KEY_FILE:-}" ]]; then
+  echo "DOCTL_RELEASE_SIGNING_KEY_FILE must be set" >&2
+  exit 1
+fi
+
+openssl pkeyutl -sign -rawin \
+  -inkey "$DOCTL_RELEASE_SIGNING_KEY_FILE" \
+  -in "$1" \
+  -out "$2"
@@ -0,0 +1,175 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package doctl
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"golang.org/x/oauth2"
+)
+
+// APIStats accumulates statistics about the API calls made during a single
+// doctl invocation. Nothing is ever sent anywhere; the statistics exist only
+// so they can be printed locally.
+type APIStats struct {
+	mu sync.Mutex
+
+	requests      int
+	attempts      int
+	latency       time.Duration
+	limit         int
+	lastRemaining int
+
+	// The rate limit is tracked in windows, which end when the reset time
+	// moves on and requests are freed. Within a window, the requests used are
+	// the first remaining count less the lowest, since concurrent responses
+	// may arrive out of order.
+	windowReset    int64
+	windowFirst    int
+	windowMin      int
+	usedInPrevious int
+}
+
+// APIStatsSummary is a point-in-time copy of APIStats.
+type APIStatsSummary struct {
+	// Requests is the number of API calls made, not counting retries.
+	Requests int `json:"requests"`
+	// Retries is the number of additional attempts made because a call failed.
+	Retries int `json:"retries"`
+	// Latency is the total time spent waiting for API calls, including retries.
+	Latency time.Duration `json:"latency"`
+	// RateLimit is the account's request limit as reported by the API, or 0 if unknown.
+	RateLimit int `json:"rate_limit"`
+	// RateLimitRemaining is the last reported number of requests remaining.
+	RateLimitRemaining int `json:"rate_limit_remaining"`
+	// RateLimitUsed is how much of the rate limit was consumed while the command ran.
+	RateLimitUsed int `json:"rate_limit_used"`
+}
+
+// Stats collects the API statistics of the running command.
+var Stats = NewAPIStats()
+
+// NewAPIStats creates an empty APIStats.
+func NewAPIStats() *APIStats {
+	return &APIStats{lastRemaining: -1, windowFirst: -1}
+}
+
+// Summary returns the statistics gathered so far.
+func (s *APIStats) Summary() APIStatsSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sum := APIStatsSummary{
+		Requests:           s.requests,
+		Latency:            s.latency,
+		RateLimit:          s.limit,
+		RateLimitRemaining: s.lastRemaining,
+	}
+	if s.attempts > s.requests {
+		sum.Retries = s.attempts - s.requests
+	}
+	if s.windowFirst >= 0 {
+		// The first response of a window has already been counted against it.
+		sum.RateLimitUsed = s.usedInPrevious + s.windowFirst - s.windowMin + 1
+	}
+	return sum
+}
+
+func (s *APIStats) recordRequest(elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	s.latency += elapsed
+}
+
+func (s *APIStats) recordAttempt(resp *http.Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts++
+	if resp == nil {
+		return
+	}
+	if limit, err := strconv.Atoi(resp.Header.Get("RateLimit-Limit")); err == nil {
+		s.limit = limit
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get("RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("RateLimit-Reset"), 10, 64)
+	if err != nil {
+		reset = 0
+	}
+	s.lastRemaining = remaining
+
+	switch {
+	case s.windowFirst < 0:
+		s.windowFirst, s.windowMin, s.windowReset = remaining, remaining, reset
+	case remaining > s.windowMin && (reset > s.windowReset || reset == 0):
+		// Requests were freed, so the window rolled over. This response is
+		// the first counted against the new one.
+		s.usedInPrevious += s.windowFirst - s.windowMin + 1
+		s.windowFirst, s.windowMin, s.windowReset = remaining, remaining, reset
+	case remaining < s.windowMin:
+		s.windowMin = remaining
+	}
+}
+
+// statsTransport records API statistics for the requests passing through it.
+// Two of these are installed around the retrying client: the outer one sees
+// each logical request once, the inner one sees every attempt.
+type statsTransport struct {
+	wrap    http.RoundTripper
+	stats   *APIStats
+	attempt bool
+}
+
+func (t *statsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.wrap.RoundTrip(req)
+
+	if t.attempt {
+		t.stats.recordAttempt(resp)
+	} else {
+		t.stats.recordRequest(time.Since(start))
+	}
+	return resp, err
+}
+
+// instrumentClient installs statsTransports on a godo HTTP client: one around
+// the whole client and one beneath the retry layer, if there is one.
+func instrumentClient(c *http.Client, stats *APIStats) {
+	if t, ok := c.Transport.(*oauth2.Transport); ok {
+		if rt, ok := t.Base.(*retryablehttp.RoundTripper); ok && rt.Client != nil {
+			hc := rt.Client.HTTPClient
+			hc.Transport = &statsTransport{wrap: transportOrDefault(hc.Transport), stats: stats, attempt: true}
+		} else {
+			t.Base = &statsTransport{wrap: transportOrDefault(t.Base), stats: stats, attempt: true}
+		}
+	}
+	c.Transport = &statsTransport{wrap: transportOrDefault(c.Transport), stats: stats}
+}
+
+func transportOrDefault(t http.RoundTripper) http.RoundTripper {
+	if t == nil {
+		return http.DefaultTransport
+	}
+	return t
+}
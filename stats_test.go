@@ -0,0 +1,115 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package doctl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/digitalocean/godo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestInstrumentClientCountsRetries(t *testing.T) {
+	calls := 0
+	remaining := 100
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		remaining--
+		w.Header().Set("RateLimit-Limit", "5000")
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"account":{}}`))
+	}))
+	defer srv.Close()
+
+	oauthClient := oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}))
+	client, err := godo.New(oauthClient,
+		godo.SetBaseURL(srv.URL),
+		godo.WithRetryAndBackoffs(godo.RetryConfig{RetryMax: 2, RetryWaitMin: godo.PtrTo(0.001), RetryWaitMax: godo.PtrTo(0.001)}),
+	)
+	require.NoError(t, err)
+
+	stats := NewAPIStats()
+	instrumentClient(client.HTTPClient, stats)
+
+	_, _, err = client.Account.Get(context.Background())
+	require.NoError(t, err)
+
+	sum := stats.Summary()
+	assert.Equal(t, 1, sum.Requests)
+	assert.Equal(t, 1, sum.Retries)
+	assert.Equal(t, 5000, sum.RateLimit)
+	assert.Equal(t, 98, sum.RateLimitRemaining)
+	assert.Equal(t, 2, sum.RateLimitUsed)
+	assert.Greater(t, sum.Latency.Nanoseconds(), int64(0))
+}
+
+func TestAPIStatsRateLimitUsed(t *testing.T) {
+	response := func(remaining int, reset string) *http.Response {
+		h := http.Header{}
+		h.Set("RateLimit-Limit", "5000")
+		h.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		if reset != "" {
+			h.Set("RateLimit-Reset", reset)
+		}
+		return &http.Response{Header: h}
+	}
+
+	tests := []struct {
+		name      string
+		responses []*http.Response
+		want      int
+	}{
+		{
+			name:      "one window",
+			responses: []*http.Response{response(100, "1700000000"), response(99, "1700000000"), response(98, "1700000000")},
+			want:      3,
+		},
+		{
+			name:      "out of order",
+			responses: []*http.Response{response(100, "1700000000"), response(98, "1700000000"), response(99, "1700000000")},
+			want:      3,
+		},
+		{
+			name:      "window rolls over",
+			responses: []*http.Response{response(3, "1700000000"), response(2, "1700000000"), response(4999, "1700003600"), response(4998, "1700003600")},
+			want:      4,
+		},
+		{
+			name:      "without reset times",
+			responses: []*http.Response{response(2, ""), response(1, ""), response(4999, "")},
+			want:      3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := NewAPIStats()
+			for _, resp := range tt.responses {
+				stats.recordAttempt(resp)
+			}
+			sum := stats.Summary()
+			assert.Equal(t, tt.want, sum.RateLimitUsed)
+			assert.GreaterOrEqual(t, sum.RateLimitUsed, 0)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2018 The Doctl Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package doctl
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/digitalocean/godo"
+	"github.com/hashicorp/go-retryablehttp"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// DefaultMaxIdleConns is the default number of idle API connections kept
+	// open for reuse.
+	DefaultMaxIdleConns = 100
+	// DefaultMaxConnsPerHost is the default number of connections opened to
+	// each API host. Zero is no limit.
+	DefaultMaxConnsPerHost = 0
+)
+
+// shared is the transport every API client of the process uses, along with
+// the pool settings it was built with.
+var shared struct {
+	sync.Mutex
+	transport       *http.Transport
+	maxIdleConns    int
+	maxConnsPerHost int
+}
+
+// sharedTransport returns the transport every API client of the process uses,
+// so that the clients of a command, or of the requests to `doctl serve`, reuse
+// connections instead of each paying for its own TLS handshakes. The transport
+// negotiates HTTP/2 where the API offers it, which multiplexes concurrent
+// requests over one connection. It is only rebuilt if the pool settings change.
+func sharedTransport(maxIdleConns, maxConnsPerHost int) *http.Transport {
+	shared.Lock()
+	defer shared.Unlock()
+
+	if shared.transport != nil && shared.maxIdleConns == maxIdleConns && shared.maxConnsPerHost == maxConnsPerHost {
+		return shared.transport
+	}
+	if shared.transport != nil {
+		shared.transport.CloseIdleConnections()
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.ForceAttemptHTTP2 = true
+	t.MaxIdleConns = maxIdleConns
+	t.MaxConnsPerHost = maxConnsPerHost
+	// The API is a single host, so it may keep every idle connection, where
+	// the default keeps only two and closes the rest of a burst of requests.
+	t.MaxIdleConnsPerHost = maxIdleConns
+	if maxConnsPerHost > 0 && maxConnsPerHost < maxIdleConns {
+		t.MaxIdleConnsPerHost = maxConnsPerHost
+	}
+
+	shared.transport = t
+	shared.maxIdleConns = maxIdleConns
+	shared.maxConnsPerHost = maxConnsPerHost
+	return t
+}
+
+// useTransport makes a godo HTTP client send its requests through t, beneath
+// the retry layer if there is one, which otherwise has its own transport.
+func useTransport(c *http.Client, t http.RoundTripper) {
+	if ot, ok := c.Transport.(*oauth2.Transport); ok {
+		if rt, ok := ot.Base.(*retryablehttp.RoundTripper); ok && rt.Client != nil {
+			rt.Client.HTTPClient.Transport = t
+		} else {
+			ot.Base = t
+		}
+		return
+	}
+	c.Transport = t
+}
+
+// noRetriesKey marks the context of a request that the retry layer must not
+// retry.
+type noRetriesKey struct{}
+
+// honorNoRetries makes the retry layer of a godo HTTP client, if there is
+// one, give up on the requests sent by a client from WithoutRetries.
+func honorNoRetries(c *http.Client) {
+	ot, ok := c.Transport.(*oauth2.Transport)
+	if !ok {
+		return
+	}
+	rt, ok := ot.Base.(*retryablehttp.RoundTripper)
+	if !ok || rt.Client == nil {
+		return
+	}
+	check := rt.Client.CheckRetry
+	rt.Client.CheckRetry = func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		if ctx.Value(noRetriesKey{}) != nil {
+			return false, nil
+		}
+		return check(ctx, resp, err)
+	}
+}
+
+// WithoutRetries returns a client that sends its requests through client,
+// with its transport, tracing, recording and other options, but whose failed
+// requests are not retried automatically.
+func WithoutRetries(client *godo.Client) *godo.Client {
+	hc := *client.HTTPClient
+	hc.Transport = &noRetriesTransport{wrap: transportOrDefault(hc.Transport)}
+	c := godo.NewClient(&hc)
+	c.BaseURL = client.BaseURL
+	c.UserAgent = client.UserAgent
+	return c
+}
+
+// noRetriesTransport marks its requests so that they are not retried.
+type noRetriesTransport struct {
+	wrap http.RoundTripper
+}
+
+func (t *noRetriesTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.wrap.RoundTrip(req.WithContext(context.WithValue(req.Context(), noRetriesKey{}, true)))
+}
@@ -0,0 +1,82 @@
+package doctl
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGodoClientsShareConnections(t *testing.T) {
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"account":{}}`))
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	for k, v := range map[string]any{"api-url": srv.URL, "http-retry-max": 2, ArgHTTPMaxIdleConns: 10, ArgHTTPMaxConnsPerHost: 4} {
+		prev := viper.Get(k)
+		viper.Set(k, v)
+		t.Cleanup(func() { viper.Set(k, prev) })
+	}
+
+	c := &LiveConfig{}
+	for _, allowRetries := range []bool{true, false, true} {
+		client, err := c.GetGodoClient(false, allowRetries, "token")
+		require.NoError(t, err)
+		for i := 0; i < 2; i++ {
+			_, _, err = client.Account.Get(context.Background())
+			require.NoError(t, err)
+		}
+	}
+	assert.Equal(t, int32(1), conns.Load())
+
+	transport := sharedTransport(10, 4)
+	assert.True(t, transport.ForceAttemptHTTP2)
+	assert.Equal(t, 10, transport.MaxIdleConns)
+	assert.Equal(t, 4, transport.MaxConnsPerHost)
+	assert.Equal(t, 4, transport.MaxIdleConnsPerHost)
+
+	assert.NotSame(t, transport, sharedTransport(20, 0))
+	assert.Equal(t, 20, sharedTransport(20, 0).MaxIdleConnsPerHost)
+}
+
+func TestWithoutRetries(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	for k, v := range map[string]any{"api-url": srv.URL, "http-retry-max": 2, "http-retry-wait-max": 0, "http-retry-wait-min": 0} {
+		prev := viper.Get(k)
+		viper.Set(k, v)
+		t.Cleanup(func() { viper.Set(k, prev) })
+	}
+
+	client, err := (&LiveConfig{}).GetGodoClient(false, true, "token")
+	require.NoError(t, err)
+
+	_, _, err = WithoutRetries(client).Account.Get(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+
+	requests.Store(0)
+	_, _, err = client.Account.Get(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+}